@@ -0,0 +1,564 @@
+// Package accounting assembles a normalized ledger of the on-chain and
+// off-chain activity of the node, which can be used to reconstruct its
+// finances.
+package accounting
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/invoices"
+	"github.com/lightningnetwork/lnd/labels"
+	"github.com/lightningnetwork/lnd/lntypes"
+	"github.com/lightningnetwork/lnd/lnwallet"
+)
+
+// EntryType is the type of a ledger entry.
+type EntryType uint8
+
+const (
+	// EntryChannelOpen moves funds from the wallet into a channel that we
+	// funded.
+	EntryChannelOpen EntryType = 1
+
+	// EntryChannelOpenFee is the on-chain fee of a funding transaction.
+	EntryChannelOpenFee EntryType = 2
+
+	// EntryChannelClose moves our balance of a closed channel back into
+	// the wallet.
+	EntryChannelClose EntryType = 3
+
+	// EntrySweep moves the outputs of a force closed channel into the
+	// wallet.
+	EntrySweep EntryType = 4
+
+	// EntrySweepFee is the on-chain fee of a sweep transaction.
+	EntrySweepFee EntryType = 5
+
+	// EntryOnChainReceive is an on-chain receive to the wallet.
+	EntryOnChainReceive EntryType = 6
+
+	// EntryOnChainSend is an on-chain send from the wallet.
+	EntryOnChainSend EntryType = 7
+
+	// EntryOnChainFee is the fee of an on-chain send.
+	EntryOnChainFee EntryType = 8
+
+	// EntryInvoiceReceipt is the amount received for a settled invoice.
+	EntryInvoiceReceipt EntryType = 9
+
+	// EntryPayment is the amount delivered to the receiver of a payment.
+	EntryPayment EntryType = 10
+
+	// EntryPaymentFee is the routing fee paid for a payment.
+	EntryPaymentFee EntryType = 11
+
+	// EntryForwardFee is the fee earned by forwarding a payment.
+	EntryForwardFee EntryType = 12
+
+	// EntryRebalanceFee is the routing fee paid for a circular payment
+	// that moved funds between our own channels.
+	EntryRebalanceFee EntryType = 13
+)
+
+// entryTypeNames maps each entry type to its name.
+var entryTypeNames = map[EntryType]string{
+	EntryChannelOpen:    "channel_open",
+	EntryChannelOpenFee: "channel_open_fee",
+	EntryChannelClose:   "channel_close",
+	EntrySweep:          "sweep",
+	EntrySweepFee:       "sweep_fee",
+	EntryOnChainReceive: "onchain_receive",
+	EntryOnChainSend:    "onchain_send",
+	EntryOnChainFee:     "onchain_fee",
+	EntryInvoiceReceipt: "invoice_receipt",
+	EntryPayment:        "payment",
+	EntryPaymentFee:     "payment_fee",
+	EntryForwardFee:     "forward_fee",
+	EntryRebalanceFee:   "rebalance_fee",
+}
+
+// String returns the name of the entry type.
+func (t EntryType) String() string {
+	name, ok := entryTypeNames[t]
+	if !ok {
+		return fmt.Sprintf("unknown(%d)", uint8(t))
+	}
+
+	return name
+}
+
+// Entry is a single entry of the ledger.
+type Entry struct {
+	// Timestamp is the time the entry was recorded at. On-chain entries
+	// use the time of the transaction, payments the time they were
+	// settled.
+	Timestamp time.Time
+
+	// Type is the type of the entry.
+	Type EntryType
+
+	// AmountMsat is the amount credited to the node if positive, or
+	// debited from it if negative.
+	AmountMsat int64
+
+	// Txid is the transaction the entry originates from, if any.
+	Txid string
+
+	// PaymentHash is the payment hash of the payment or invoice the entry
+	// originates from, if any.
+	PaymentHash string
+
+	// ChannelPoint is the channel the entry relates to, if known.
+	ChannelPoint string
+
+	// ChanID is the short channel ID of the channel the entry relates to,
+	// if known. For forwards, it is the outgoing channel.
+	ChanID uint64
+
+	// Note describes the entry.
+	Note string
+}
+
+// Total is the sum of the entries of one type.
+type Total struct {
+	// Type is the entry type.
+	Type EntryType
+
+	// AmountMsat is the sum of the amounts of the entries.
+	AmountMsat int64
+
+	// Count is the number of entries.
+	Count uint64
+}
+
+// Report is a ledger of the activity of the node within a time range.
+type Report struct {
+	// Entries are the ledger entries, ordered by time.
+	Entries []*Entry
+
+	// Totals are the sums of the entries by type, ordered by type.
+	Totals []*Total
+}
+
+// Config holds the data sources the ledger is assembled from.
+type Config struct {
+	// Transactions returns all wallet transactions.
+	Transactions func() ([]*lnwallet.TransactionDetail, error)
+
+	// Payments returns all succeeded payments.
+	Payments func() ([]*channeldb.MPPayment, error)
+
+	// SettledInvoices returns all settled invoices.
+	SettledInvoices func() ([]invoices.Invoice, error)
+
+	// Forwards returns the forwarding events within the time range.
+	Forwards func(start, end time.Time) ([]channeldb.ForwardingEvent,
+		error)
+
+	// OpenChannels returns our open channels.
+	OpenChannels func() ([]*channeldb.OpenChannel, error)
+
+	// ClosedChannels returns our closed channels.
+	ClosedChannels func() ([]*channeldb.ChannelCloseSummary, error)
+
+	// ResolverReports returns the reports of the contract resolutions of
+	// a closed channel.
+	ResolverReports func(chanPoint *wire.OutPoint) (
+		[]*channeldb.ResolverReport, error)
+
+	// HistoricalChannel returns the final state of a closed channel, or
+	// nil if it isn't known.
+	HistoricalChannel func(chanPoint *wire.OutPoint) (
+		*channeldb.OpenChannel, error)
+}
+
+// channelInfo identifies a channel in the ledger entries.
+type channelInfo struct {
+	chanPoint string
+	chanID    uint64
+
+	// coopCloseFee is set for the cooperative closing transaction of a
+	// channel we funded, as we paid its fee out of the capacity.
+	coopCloseFee bool
+
+	// capacity is the capacity of the channel.
+	capacity btcutil.Amount
+}
+
+// txIndex classifies the on-chain transactions that relate to channels.
+type txIndex struct {
+	// funding maps funding transactions to their channel.
+	funding map[chainhash.Hash]channelInfo
+
+	// closing maps closing transactions to their channel.
+	closing map[chainhash.Hash]channelInfo
+
+	// sweeps maps the sweep transactions of contract resolutions to
+	// their channel.
+	sweeps map[chainhash.Hash]channelInfo
+
+	// swept holds the value of the channel outputs spent by each sweep
+	// transaction, which may sweep the outputs of several channels.
+	swept map[chainhash.Hash]btcutil.Amount
+}
+
+// NewReport assembles the ledger of the activity within the time range from
+// start, inclusive, to end, exclusive. A zero end time means no upper bound.
+func NewReport(cfg *Config, start, end time.Time) (*Report, error) {
+	inRange := func(t time.Time) bool {
+		if t.Before(start) {
+			return false
+		}
+
+		return end.IsZero() || t.Before(end)
+	}
+
+	index, err := newTxIndex(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*Entry
+
+	txns, err := cfg.Transactions()
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch transactions: %w", err)
+	}
+	for _, tx := range txns {
+		if !inRange(time.Unix(tx.Timestamp, 0)) {
+			continue
+		}
+
+		entries = append(entries, onChainEntries(tx, index)...)
+	}
+
+	payments, err := cfg.Payments()
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch payments: %w", err)
+	}
+
+	// The invoices paid by rebalances are our own, so only the fees of
+	// rebalances show up in the ledger.
+	rebalances := make(map[lntypes.Hash]struct{})
+	for _, payment := range payments {
+		if payment.Info.Rebalance {
+			rebalances[payment.Info.PaymentIdentifier] = struct{}{}
+		}
+
+		settle, _ := payment.TerminalInfo()
+		if settle == nil || !inRange(settle.SettleTime) {
+			continue
+		}
+
+		entries = append(entries, paymentEntries(payment, settle)...)
+	}
+
+	settled, err := cfg.SettledInvoices()
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch invoices: %w", err)
+	}
+	for i := range settled {
+		invoice := &settled[i]
+		if !inRange(invoice.SettleDate) {
+			continue
+		}
+
+		if invoice.Terms.PaymentPreimage != nil {
+			hash := invoice.Terms.PaymentPreimage.Hash()
+			if _, ok := rebalances[hash]; ok {
+				continue
+			}
+		}
+
+		entries = append(entries, invoiceEntry(invoice))
+	}
+
+	forwards, err := cfg.Forwards(start, end)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch forwards: %w", err)
+	}
+	for _, forward := range forwards {
+		if !inRange(forward.Timestamp) {
+			continue
+		}
+
+		entries = append(entries, &Entry{
+			Timestamp: forward.Timestamp,
+			Type:      EntryForwardFee,
+			AmountMsat: int64(forward.AmtIn) -
+				int64(forward.AmtOut),
+			ChanID: forward.OutgoingChanID.ToUint64(),
+			Note: fmt.Sprintf("forward %v -> %v",
+				forward.IncomingChanID, forward.OutgoingChanID),
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+
+	return &Report{
+		Entries: entries,
+		Totals:  totals(entries),
+	}, nil
+}
+
+// newTxIndex indexes the funding, closing and sweep transactions of our
+// channels.
+func newTxIndex(cfg *Config) (*txIndex, error) {
+	index := &txIndex{
+		funding: make(map[chainhash.Hash]channelInfo),
+		closing: make(map[chainhash.Hash]channelInfo),
+		sweeps:  make(map[chainhash.Hash]channelInfo),
+		swept:   make(map[chainhash.Hash]btcutil.Amount),
+	}
+
+	openChannels, err := cfg.OpenChannels()
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch open channels: %w", err)
+	}
+	for _, channel := range openChannels {
+		index.funding[channel.FundingOutpoint.Hash] = channelInfo{
+			chanPoint: channel.FundingOutpoint.String(),
+			chanID:    channel.ShortChanID().ToUint64(),
+		}
+	}
+
+	closedChannels, err := cfg.ClosedChannels()
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch closed channels: %w",
+			err)
+	}
+	for _, summary := range closedChannels {
+		info := channelInfo{
+			chanPoint: summary.ChanPoint.String(),
+			chanID:    summary.ShortChanID.ToUint64(),
+		}
+		index.funding[summary.ChanPoint.Hash] = info
+
+		closeInfo := info
+		if summary.CloseType == channeldb.CooperativeClose {
+			historical, err := cfg.HistoricalChannel(
+				&summary.ChanPoint,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("unable to fetch "+
+					"historical channel %v: %w",
+					summary.ChanPoint, err)
+			}
+
+			if historical != nil && historical.IsInitiator {
+				closeInfo.coopCloseFee = true
+				closeInfo.capacity = summary.Capacity
+			}
+		}
+		index.closing[summary.ClosingTXID] = closeInfo
+
+		reports, err := cfg.ResolverReports(&summary.ChanPoint)
+		if err != nil {
+			return nil, fmt.Errorf("unable to fetch resolver "+
+				"reports of %v: %w", summary.ChanPoint, err)
+		}
+		for _, report := range reports {
+			if report.SpendTxID == nil {
+				continue
+			}
+
+			// The closing transaction itself may resolve outputs,
+			// it is reported as a close.
+			if *report.SpendTxID == summary.ClosingTXID {
+				continue
+			}
+
+			index.sweeps[*report.SpendTxID] = info
+			index.swept[*report.SpendTxID] += report.Amount
+		}
+	}
+
+	return index, nil
+}
+
+// onChainEntries returns the entries of a wallet transaction. Funds moved
+// into channels or swept from them are told apart from plain sends and
+// receives, and the fees we paid are reported as separate entries.
+func onChainEntries(tx *lnwallet.TransactionDetail,
+	index *txIndex) []*Entry {
+
+	labelType, _ := labels.ParseLabelType(tx.Label)
+
+	var (
+		amountType EntryType
+		feeType    EntryType
+		info       channelInfo
+	)
+	fundingInfo, isFunding := index.funding[tx.Hash]
+	closingInfo, isClosing := index.closing[tx.Hash]
+	sweepInfo, isSweep := index.sweeps[tx.Hash]
+	switch {
+	case isClosing:
+		amountType, feeType = EntryChannelClose, EntryOnChainFee
+		info = closingInfo
+
+	case isFunding && tx.Value < 0:
+		amountType, feeType = EntryChannelOpen, EntryChannelOpenFee
+		info = fundingInfo
+
+	case isSweep:
+		amountType, feeType = EntrySweep, EntrySweepFee
+		info = sweepInfo
+
+	case labelType == labels.LabelTypeChannelClose:
+		amountType, feeType = EntryChannelClose, EntryOnChainFee
+
+	case labelType == labels.LabelTypeChannelOpen:
+		amountType, feeType = EntryChannelOpen, EntryChannelOpenFee
+
+	case labelType == labels.LabelTypeSweepTransaction,
+		labelType == labels.LabelTypeJusticeTransaction:
+
+		amountType, feeType = EntrySweep, EntrySweepFee
+
+	case tx.Value >= 0:
+		amountType, feeType = EntryOnChainReceive, EntryOnChainFee
+
+	default:
+		amountType, feeType = EntryOnChainSend, EntryOnChainFee
+	}
+
+	// The value of a transaction includes the fee we paid for it, which
+	// we report separately. The wallet only knows the fee of transactions
+	// that spend its own outputs, so the fees of transactions spending
+	// channel outputs are derived from the value of the channel outputs.
+	fee := tx.TotalFees
+	switch {
+	case isClosing && info.coopCloseFee:
+		fee = int64(info.capacity)
+		for _, output := range tx.OutputDetails {
+			fee -= int64(output.Value)
+		}
+
+	case isSweep:
+		fee = int64(index.swept[tx.Hash] - tx.Value)
+	}
+	if fee < 0 {
+		fee = 0
+	}
+	amount := int64(tx.Value) + fee
+
+	newEntry := func(entryType EntryType, amountSat int64) *Entry {
+		return &Entry{
+			Timestamp:    time.Unix(tx.Timestamp, 0),
+			Type:         entryType,
+			AmountMsat:   amountSat * 1000,
+			Txid:         tx.Hash.String(),
+			ChannelPoint: info.chanPoint,
+			ChanID:       info.chanID,
+			Note:         tx.Label,
+		}
+	}
+
+	var entries []*Entry
+	if amount != 0 || fee == 0 {
+		entries = append(entries, newEntry(amountType, amount))
+	}
+	if fee != 0 {
+		entries = append(entries, newEntry(feeType, -fee))
+	}
+
+	return entries
+}
+
+// paymentEntries returns the entries of a succeeded payment.
+func paymentEntries(payment *channeldb.MPPayment,
+	settle *channeldb.HTLCSettleInfo) []*Entry {
+
+	sent, fees := payment.SentAmt()
+	hash := payment.Info.PaymentIdentifier.String()
+
+	newEntry := func(entryType EntryType, amount int64) *Entry {
+		return &Entry{
+			Timestamp:   settle.SettleTime,
+			Type:        entryType,
+			AmountMsat:  amount,
+			PaymentHash: hash,
+		}
+	}
+
+	// A rebalance moves funds between our own channels, so it only costs
+	// the fees.
+	if payment.Info.Rebalance {
+		entry := newEntry(EntryRebalanceFee, -int64(fees))
+		entry.Note = "rebalance"
+
+		return []*Entry{entry}
+	}
+
+	entries := []*Entry{newEntry(EntryPayment, -int64(sent))}
+	if fees != 0 {
+		entries = append(
+			entries, newEntry(EntryPaymentFee, -int64(fees)),
+		)
+	}
+
+	return entries
+}
+
+// invoiceEntry returns the entry of a settled invoice.
+func invoiceEntry(invoice *invoices.Invoice) *Entry {
+	entry := &Entry{
+		Timestamp:  invoice.SettleDate,
+		Type:       EntryInvoiceReceipt,
+		AmountMsat: int64(invoice.AmtPaid),
+		Note:       string(invoice.Memo),
+	}
+
+	switch {
+	case invoice.Terms.PaymentPreimage != nil:
+		hash := invoice.Terms.PaymentPreimage.Hash()
+		entry.PaymentHash = hash.String()
+
+	// AMP invoices don't have a single payment hash, so they are
+	// referenced by their payment address.
+	case invoice.IsAMP():
+		entry.Note = fmt.Sprintf("amp invoice %v",
+			hex.EncodeToString(invoice.Terms.PaymentAddr[:]))
+	}
+
+	if invoice.IsKeysend() && entry.Note == "" {
+		entry.Note = "keysend"
+	}
+
+	return entry
+}
+
+// totals sums up the entries by type.
+func totals(entries []*Entry) []*Total {
+	byType := make(map[EntryType]*Total)
+	for _, entry := range entries {
+		total, ok := byType[entry.Type]
+		if !ok {
+			total = &Total{Type: entry.Type}
+			byType[entry.Type] = total
+		}
+
+		total.AmountMsat += entry.AmountMsat
+		total.Count++
+	}
+
+	result := make([]*Total, 0, len(byType))
+	for _, total := range byType {
+		result = append(result, total)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Type < result[j].Type
+	})
+
+	return result
+}
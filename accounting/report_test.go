@@ -0,0 +1,321 @@
+package accounting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/invoices"
+	"github.com/lightningnetwork/lnd/labels"
+	"github.com/lightningnetwork/lnd/lntypes"
+	"github.com/lightningnetwork/lnd/lnwallet"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/routing/route"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	// testStart is the start of the time range of the test report.
+	testStart = time.Unix(1_700_000_000, 0)
+
+	// testEnd is the end of the time range of the test report.
+	testEnd = testStart.Add(24 * time.Hour)
+)
+
+// at returns the time the given number of minutes after the test start.
+func at(minutes int) time.Time {
+	return testStart.Add(time.Duration(minutes) * time.Minute)
+}
+
+// testHash returns a hash filled with the given byte.
+func testHash(b byte) chainhash.Hash {
+	var hash chainhash.Hash
+	for i := range hash {
+		hash[i] = b
+	}
+
+	return hash
+}
+
+// testTx returns a wallet transaction.
+func testTx(hash chainhash.Hash, minutes int, value, fee int64,
+	label string) *lnwallet.TransactionDetail {
+
+	return &lnwallet.TransactionDetail{
+		Hash:      hash,
+		Value:     btcutil.Amount(value),
+		Timestamp: at(minutes).Unix(),
+		TotalFees: fee,
+		Label:     label,
+	}
+}
+
+// testPayment returns a succeeded payment that delivered amt to the receiver
+// and paid the given fee.
+func testPayment(preimage lntypes.Preimage, minutes int, amt,
+	fee lnwire.MilliSatoshi, rebalance bool) *channeldb.MPPayment {
+
+	return &channeldb.MPPayment{
+		Info: &channeldb.PaymentCreationInfo{
+			PaymentIdentifier: preimage.Hash(),
+			Value:             amt,
+			Rebalance:         rebalance,
+		},
+		HTLCs: []channeldb.HTLCAttempt{{
+			HTLCAttemptInfo: channeldb.HTLCAttemptInfo{
+				Route: route.Route{
+					TotalAmount: amt + fee,
+					Hops: []*route.Hop{{
+						AmtToForward: amt,
+					}},
+				},
+			},
+			Settle: &channeldb.HTLCSettleInfo{
+				Preimage:   preimage,
+				SettleTime: at(minutes),
+			},
+		}},
+		Status: channeldb.StatusSucceeded,
+	}
+}
+
+// testInvoice returns a settled invoice.
+func testInvoice(preimage lntypes.Preimage, minutes int,
+	amt lnwire.MilliSatoshi) invoices.Invoice {
+
+	return invoices.Invoice{
+		Terms: invoices.ContractTerm{
+			PaymentPreimage: &preimage,
+			Value:           amt,
+		},
+		SettleDate: at(minutes),
+		AmtPaid:    amt,
+		State:      invoices.ContractSettled,
+	}
+}
+
+// TestReport tests that the ledger classifies the node activity and reports
+// it in the requested time range.
+func TestReport(t *testing.T) {
+	t.Parallel()
+
+	openPoint := wire.OutPoint{Hash: testHash(1), Index: 0}
+	closedPoint := wire.OutPoint{Hash: testHash(2), Index: 1}
+	closingTxid := testHash(3)
+	sweepTxid := testHash(4)
+
+	openChanID := lnwire.NewShortChanIDFromInt(100)
+	closedChanID := lnwire.NewShortChanIDFromInt(200)
+
+	sweepLabel := labels.MakeLabel(labels.LabelTypeSweepTransaction, nil)
+
+	paymentPreimage := lntypes.Preimage{1}
+	rebalancePreimage := lntypes.Preimage{2}
+	invoicePreimage := lntypes.Preimage{3}
+
+	// We funded the closed channel, so we paid the fee of its cooperative
+	// close.
+	closingTx := testTx(closingTxid, 4, 30_000, 0, "")
+	closingTx.OutputDetails = []lnwallet.OutputDetail{{
+		Value:        30_000,
+		IsOurAddress: true,
+	}, {
+		Value: 19_500,
+	}}
+
+	cfg := &Config{
+		Transactions: func() ([]*lnwallet.TransactionDetail, error) {
+			return []*lnwallet.TransactionDetail{
+				// A receive before the time range.
+				testTx(testHash(9), -10, 50_000, 0, ""),
+
+				// A receive and a send.
+				testTx(testHash(5), 1, 100_000, 0, ""),
+				testTx(testHash(6), 2, -20_300, 300, "rent"),
+
+				// The funding transaction of the open
+				// channel, and the closing and sweep
+				// transactions of the closed channel. The
+				// wallet doesn't know the fees of the
+				// transactions spending channel outputs.
+				testTx(openPoint.Hash, 3, -50_500, 500, ""),
+				closingTx,
+				testTx(sweepTxid, 5, 9_800, 0, ""),
+
+				// A sweep that is only known by its label.
+				testTx(testHash(7), 6, 4_900, 100, sweepLabel),
+			}, nil
+		},
+		Payments: func() ([]*channeldb.MPPayment, error) {
+			return []*channeldb.MPPayment{
+				testPayment(
+					paymentPreimage, 10, 10_000, 15, false,
+				),
+				testPayment(
+					rebalancePreimage, 11, 500_000, 40,
+					true,
+				),
+			}, nil
+		},
+		SettledInvoices: func() ([]invoices.Invoice, error) {
+			return []invoices.Invoice{
+				testInvoice(invoicePreimage, 12, 25_000),
+
+				// The invoice paid by the rebalance.
+				testInvoice(rebalancePreimage, 11, 500_000),
+
+				// An invoice settled after the time range.
+				testInvoice(lntypes.Preimage{4}, 2000, 1_000),
+			}, nil
+		},
+		Forwards: func(start,
+			end time.Time) ([]channeldb.ForwardingEvent, error) {
+
+			require.Equal(t, testStart, start)
+			require.Equal(t, testEnd, end)
+
+			return []channeldb.ForwardingEvent{{
+				Timestamp:      at(13),
+				IncomingChanID: openChanID,
+				OutgoingChanID: closedChanID,
+				AmtIn:          100_021,
+				AmtOut:         100_000,
+			}}, nil
+		},
+		OpenChannels: func() ([]*channeldb.OpenChannel, error) {
+			return []*channeldb.OpenChannel{{
+				FundingOutpoint: openPoint,
+				ShortChannelID:  openChanID,
+			}}, nil
+		},
+		ClosedChannels: func() ([]*channeldb.ChannelCloseSummary,
+			error) {
+
+			return []*channeldb.ChannelCloseSummary{{
+				ChanPoint:   closedPoint,
+				ShortChanID: closedChanID,
+				ClosingTXID: closingTxid,
+				Capacity:    50_000,
+				CloseType:   channeldb.CooperativeClose,
+			}}, nil
+		},
+		ResolverReports: func(chanPoint *wire.OutPoint) (
+			[]*channeldb.ResolverReport, error) {
+
+			require.Equal(t, closedPoint, *chanPoint)
+
+			outcome := channeldb.ResolverOutcomeClaimed
+
+			return []*channeldb.ResolverReport{{
+				ResolverType:    channeldb.ResolverTypeCommit,
+				ResolverOutcome: outcome,
+				Amount:          10_000,
+				SpendTxID:       &sweepTxid,
+			}}, nil
+		},
+		HistoricalChannel: func(chanPoint *wire.OutPoint) (
+			*channeldb.OpenChannel, error) {
+
+			require.Equal(t, closedPoint, *chanPoint)
+
+			return &channeldb.OpenChannel{IsInitiator: true}, nil
+		},
+	}
+
+	report, err := NewReport(cfg, testStart, testEnd)
+	require.NoError(t, err)
+
+	type entry struct {
+		minutes      int
+		entryType    EntryType
+		amount       int64
+		ref          string
+		channelPoint string
+	}
+	expected := []entry{
+		{1, EntryOnChainReceive, 100_000_000, testHash(5).String(), ""},
+		{2, EntryOnChainSend, -20_000_000, testHash(6).String(), ""},
+		{2, EntryOnChainFee, -300_000, testHash(6).String(), ""},
+		{
+			3, EntryChannelOpen, -50_000_000,
+			openPoint.Hash.String(), openPoint.String(),
+		},
+		{
+			3, EntryChannelOpenFee, -500_000,
+			openPoint.Hash.String(), openPoint.String(),
+		},
+		{
+			4, EntryChannelClose, 30_500_000,
+			closingTxid.String(), closedPoint.String(),
+		},
+		{
+			4, EntryOnChainFee, -500_000,
+			closingTxid.String(), closedPoint.String(),
+		},
+		{
+			5, EntrySweep, 10_000_000, sweepTxid.String(),
+			closedPoint.String(),
+		},
+		{
+			5, EntrySweepFee, -200_000, sweepTxid.String(),
+			closedPoint.String(),
+		},
+		{6, EntrySweep, 5_000_000, testHash(7).String(), ""},
+		{6, EntrySweepFee, -100_000, testHash(7).String(), ""},
+		{
+			10, EntryPayment, -10_000,
+			paymentPreimage.Hash().String(), "",
+		},
+		{
+			10, EntryPaymentFee, -15,
+			paymentPreimage.Hash().String(), "",
+		},
+		{
+			11, EntryRebalanceFee, -40,
+			rebalancePreimage.Hash().String(), "",
+		},
+		{
+			12, EntryInvoiceReceipt, 25_000,
+			invoicePreimage.Hash().String(), "",
+		},
+		{13, EntryForwardFee, 21, "", ""},
+	}
+
+	require.Len(t, report.Entries, len(expected))
+	for i, e := range expected {
+		actual := report.Entries[i]
+		require.Equal(t, at(e.minutes), actual.Timestamp, "entry %d", i)
+		require.Equal(t, e.entryType, actual.Type, "entry %d", i)
+		require.Equal(t, e.amount, actual.AmountMsat, "entry %d", i)
+		require.Equal(
+			t, e.channelPoint, actual.ChannelPoint, "entry %d", i,
+		)
+
+		ref := actual.Txid
+		if ref == "" {
+			ref = actual.PaymentHash
+		}
+		require.Equal(t, e.ref, ref, "entry %d", i)
+	}
+
+	// The forward references its outgoing channel.
+	require.Equal(
+		t, closedChanID.ToUint64(), report.Entries[15].ChanID,
+	)
+
+	// The totals are ordered by type.
+	require.Equal(t, &Total{
+		Type:       EntryChannelOpen,
+		AmountMsat: -50_000_000,
+		Count:      1,
+	}, report.Totals[0])
+	require.Equal(t, &Total{
+		Type:       EntrySweep,
+		AmountMsat: 15_000_000,
+		Count:      2,
+	}, report.Totals[3])
+	require.Len(t, report.Totals, 13)
+}
@@ -0,0 +1,635 @@
+package accounts
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/lightningnetwork/lnd/lnrpc"
+	"github.com/lightningnetwork/lnd/lnrpc/invoicesrpc"
+	"github.com/lightningnetwork/lnd/lnrpc/routerrpc"
+	"github.com/lightningnetwork/lnd/lntypes"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/zpay32"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	// ErrMethodNotAllowed is returned if a macaroon bound to an account is
+	// used to call a method that accounts can't use.
+	ErrMethodNotAllowed = errors.New("method not allowed for accounts")
+
+	// errInvoiceNotFound is returned if an account accesses an invoice
+	// that doesn't belong to it. It is indistinguishable from the error lnd
+	// returns for invoices that don't exist.
+	errInvoiceNotFound = status.Error(
+		codes.NotFound, "unable to locate invoice",
+	)
+
+	// errPaymentNotFound is returned if an account tracks a payment that
+	// doesn't belong to it.
+	errPaymentNotFound = status.Error(
+		codes.NotFound, "payment isn't initiated",
+	)
+)
+
+// requestChecker checks a request made with an account bound macaroon.
+type requestChecker func(ctx context.Context, c *call,
+	req proto.Message) error
+
+// responseChecker checks, and possibly replaces, a response to a call made
+// with an account bound macaroon.
+type responseChecker func(ctx context.Context, c *call,
+	resp proto.Message) (proto.Message, error)
+
+// checker holds the request and response checkers of a method. A nil checker
+// lets the messages pass unchanged.
+type checker struct {
+	request  requestChecker
+	response responseChecker
+}
+
+// checkRequest returns a request checker for requests of type T.
+func checkRequest[T proto.Message](f func(context.Context, *call,
+	T) error) requestChecker {
+
+	return func(ctx context.Context, c *call, msg proto.Message) error {
+		req, ok := msg.(T)
+		if !ok {
+			return fmt.Errorf("unexpected request type %T", msg)
+		}
+
+		return f(ctx, c, req)
+	}
+}
+
+// checkResponse returns a response checker for responses of type T.
+func checkResponse[T proto.Message](f func(context.Context, *call,
+	T) (proto.Message, error)) responseChecker {
+
+	return func(ctx context.Context, c *call,
+		msg proto.Message) (proto.Message, error) {
+
+		resp, ok := msg.(T)
+		if !ok {
+			return nil, fmt.Errorf("unexpected response type %T",
+				msg)
+		}
+
+		return f(ctx, c, resp)
+	}
+}
+
+// callCheckers are the checkers of all methods that can be called with an
+// account bound macaroon. All other methods are rejected.
+var callCheckers = map[string]checker{
+	"/lnrpc.Lightning/GetInfo":           {},
+	"/lnrpc.Lightning/DecodePayReq":      {},
+	"/routerrpc.Router/EstimateRouteFee": {},
+	"/lnrpc.Lightning/ChannelBalance": {
+		response: checkResponse(channelBalance),
+	},
+
+	// Invoices.
+	"/lnrpc.Lightning/AddInvoice": {
+		request:  checkRequest(addInvoiceRequest),
+		response: checkResponse(addInvoiceResponse),
+	},
+	"/invoicesrpc.Invoices/AddHoldInvoice": {
+		request:  checkRequest(addHoldInvoiceRequest),
+		response: checkResponse(addHoldInvoiceResponse),
+	},
+	"/lnrpc.Lightning/LookupInvoice": {
+		request: checkRequest(lookupInvoice),
+	},
+	"/invoicesrpc.Invoices/LookupInvoiceV2": {
+		request: checkRequest(lookupInvoiceV2),
+	},
+	"/invoicesrpc.Invoices/CancelInvoice": {
+		request: checkRequest(cancelInvoice),
+	},
+	"/invoicesrpc.Invoices/SettleInvoice": {
+		request: checkRequest(settleInvoice),
+	},
+	"/invoicesrpc.Invoices/SubscribeSingleInvoice": {
+		request: checkRequest(subscribeSingleInvoice),
+	},
+	"/lnrpc.Lightning/ListInvoices": {
+		response: checkResponse(listInvoices),
+	},
+	"/lnrpc.Lightning/SubscribeInvoices": {
+		response: checkResponse(subscribeInvoices),
+	},
+
+	// Payments.
+	"/lnrpc.Lightning/SendPaymentSync": {
+		request: checkRequest(sendPaymentSync),
+	},
+	"/routerrpc.Router/SendPaymentV2": {
+		request: checkRequest(sendPaymentV2),
+	},
+	"/lnrpc.Lightning/SendToRouteSync": {
+		request: checkRequest(sendToRouteSync),
+	},
+	"/routerrpc.Router/SendToRouteV2": {
+		request: checkRequest(sendToRouteV2),
+	},
+	"/routerrpc.Router/TrackPaymentV2": {
+		request: checkRequest(trackPaymentV2),
+	},
+	"/lnrpc.Lightning/ListPayments": {
+		response: checkResponse(listPayments),
+	},
+}
+
+// call is a single call made with an account bound macaroon.
+type call struct {
+	service   *InterceptorService
+	accountID AccountID
+	checker   checker
+
+	// mu protects the fields below, as the requests and responses of a
+	// stream can be handled concurrently.
+	mu sync.Mutex
+
+	// payments are the payment hashes the call reserved balance for.
+	payments []lntypes.Hash
+
+	// holdInvoice is the payment hash of the hold invoice the call
+	// creates, if any.
+	holdInvoice *lntypes.Hash
+}
+
+// HandleRequest checks a request of the call before it is passed to lnd.
+//
+// NOTE: This is part of the rpcperms.AccountCall interface.
+func (c *call) HandleRequest(ctx context.Context, req proto.Message) error {
+	if c.checker.request == nil {
+		return nil
+	}
+
+	return c.checker.request(ctx, c, req)
+}
+
+// HandleResponse checks a response of the call before it is passed to the
+// client.
+//
+// NOTE: This is part of the rpcperms.AccountCall interface.
+func (c *call) HandleResponse(ctx context.Context,
+	resp proto.Message) (proto.Message, error) {
+
+	if c.checker.response == nil {
+		return resp, nil
+	}
+
+	return c.checker.response(ctx, c, resp)
+}
+
+// Done starts tracking all payments the call reserved balance for. Whether
+// lnd returned an error or not, the payments might have been started, so
+// their final state is always determined by lnd's payment database.
+//
+// NOTE: This is part of the rpcperms.AccountCall interface.
+func (c *call) Done(error) {
+	c.mu.Lock()
+	payments := c.payments
+	c.payments = nil
+	c.mu.Unlock()
+
+	for _, hash := range payments {
+		c.service.trackPayment(hash)
+	}
+}
+
+// account returns the current state of the account of the call.
+func (c *call) account(ctx context.Context) (*Account, error) {
+	return c.service.cfg.Store.Account(ctx, c.accountID)
+}
+
+// checkInvoice makes sure the invoice with the given payment hash belongs to
+// the account of the call.
+func (c *call) checkInvoice(ctx context.Context, hashBytes []byte) error {
+	hash, err := lntypes.MakeHash(hashBytes)
+	if err != nil {
+		return err
+	}
+
+	id, err := c.service.cfg.Store.InvoiceAccount(ctx, hash)
+	switch {
+	case errors.Is(err, ErrAccountNotFound):
+		return errInvoiceNotFound
+
+	case err != nil:
+		return err
+
+	case id != c.accountID:
+		return errInvoiceNotFound
+	}
+
+	return nil
+}
+
+// ownsInvoice returns true if the invoice with the given payment hash belongs
+// to the account of the call.
+func (c *call) ownsInvoice(account *Account, hashBytes []byte) bool {
+	hash, err := lntypes.MakeHash(hashBytes)
+	if err != nil {
+		return false
+	}
+
+	_, ok := account.Invoices[hash]
+	return ok
+}
+
+// addInvoice assigns the invoice with the given payment hash to the account
+// of the call.
+func (c *call) addInvoice(ctx context.Context, hashBytes []byte) error {
+	hash, err := lntypes.MakeHash(hashBytes)
+	if err != nil {
+		return err
+	}
+
+	return c.service.cfg.Store.AddInvoice(ctx, c.accountID, hash)
+}
+
+// reservePayment reserves the full amount of a payment, including the
+// maximum fee, from the balance of the account of the call.
+func (c *call) reservePayment(ctx context.Context, hashBytes []byte,
+	amt, feeLimit lnwire.MilliSatoshi) error {
+
+	if len(hashBytes) == 0 {
+		return fmt.Errorf("accounts can only send payments with a " +
+			"known payment hash")
+	}
+
+	hash, err := lntypes.MakeHash(hashBytes)
+	if err != nil {
+		return err
+	}
+
+	err = c.service.reservePayment(ctx, c.accountID, hash, amt+feeLimit)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.payments = append(c.payments, hash)
+	c.mu.Unlock()
+
+	return nil
+}
+
+// channelBalance replaces the channel balance of the node with the balance of
+// the account.
+func channelBalance(ctx context.Context, c *call,
+	_ *lnrpc.ChannelBalanceResponse) (proto.Message, error) {
+
+	account, err := c.account(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	available := account.AvailableBalance()
+	reserved := account.ReservedBalance()
+
+	return &lnrpc.ChannelBalanceResponse{
+		Balance: int64(available.ToSatoshis()),
+		LocalBalance: &lnrpc.Amount{
+			Sat:  uint64(available.ToSatoshis()),
+			Msat: uint64(available),
+		},
+		RemoteBalance: &lnrpc.Amount{},
+		UnsettledLocalBalance: &lnrpc.Amount{
+			Sat:  uint64(reserved.ToSatoshis()),
+			Msat: uint64(reserved),
+		},
+		UnsettledRemoteBalance:   &lnrpc.Amount{},
+		PendingOpenLocalBalance:  &lnrpc.Amount{},
+		PendingOpenRemoteBalance: &lnrpc.Amount{},
+	}, nil
+}
+
+// addInvoiceRequest rejects AMP invoices, as their payments can't be
+// attributed to the account reliably.
+func addInvoiceRequest(_ context.Context, _ *call, req *lnrpc.Invoice) error {
+	if req.IsAmp {
+		return fmt.Errorf("accounts can't create AMP invoices")
+	}
+
+	return nil
+}
+
+// addInvoiceResponse assigns a new invoice to the account.
+func addInvoiceResponse(ctx context.Context, c *call,
+	resp *lnrpc.AddInvoiceResponse) (proto.Message, error) {
+
+	if err := c.addInvoice(ctx, resp.RHash); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// addHoldInvoiceRequest remembers the payment hash of a new hold invoice, as
+// the response doesn't contain it.
+func addHoldInvoiceRequest(_ context.Context, c *call,
+	req *invoicesrpc.AddHoldInvoiceRequest) error {
+
+	hash, err := lntypes.MakeHash(req.Hash)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.holdInvoice = &hash
+	c.mu.Unlock()
+
+	return nil
+}
+
+// addHoldInvoiceResponse assigns a new hold invoice to the account.
+func addHoldInvoiceResponse(ctx context.Context, c *call,
+	resp *invoicesrpc.AddHoldInvoiceResp) (proto.Message, error) {
+
+	c.mu.Lock()
+	hash := c.holdInvoice
+	c.mu.Unlock()
+
+	if hash == nil {
+		return nil, fmt.Errorf("unknown hold invoice")
+	}
+
+	if err := c.addInvoice(ctx, hash[:]); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// lookupInvoice only allows looking up invoices of the account.
+func lookupInvoice(ctx context.Context, c *call, req *lnrpc.PaymentHash) error {
+	hash := req.RHash
+	if req.RHashStr != "" {
+		var err error
+		hash, err = hex.DecodeString(req.RHashStr)
+		if err != nil {
+			return err
+		}
+	}
+
+	return c.checkInvoice(ctx, hash)
+}
+
+// lookupInvoiceV2 only allows looking up invoices of the account by their
+// payment hash.
+func lookupInvoiceV2(ctx context.Context, c *call,
+	req *invoicesrpc.LookupInvoiceMsg) error {
+
+	if req.GetPaymentHash() == nil {
+		return fmt.Errorf("accounts can only look up invoices by " +
+			"payment hash")
+	}
+
+	return c.checkInvoice(ctx, req.GetPaymentHash())
+}
+
+// cancelInvoice only allows canceling invoices of the account.
+func cancelInvoice(ctx context.Context, c *call,
+	req *invoicesrpc.CancelInvoiceMsg) error {
+
+	return c.checkInvoice(ctx, req.PaymentHash)
+}
+
+// settleInvoice only allows settling hold invoices of the account.
+func settleInvoice(ctx context.Context, c *call,
+	req *invoicesrpc.SettleInvoiceMsg) error {
+
+	hash := sha256.Sum256(req.Preimage)
+
+	return c.checkInvoice(ctx, hash[:])
+}
+
+// subscribeSingleInvoice only allows subscribing to invoices of the account.
+func subscribeSingleInvoice(ctx context.Context, c *call,
+	req *invoicesrpc.SubscribeSingleInvoiceRequest) error {
+
+	return c.checkInvoice(ctx, req.RHash)
+}
+
+// listInvoices removes all invoices that don't belong to the account.
+func listInvoices(ctx context.Context, c *call,
+	resp *lnrpc.ListInvoiceResponse) (proto.Message, error) {
+
+	account, err := c.account(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	invoices := make([]*lnrpc.Invoice, 0, len(resp.Invoices))
+	for _, invoice := range resp.Invoices {
+		if c.ownsInvoice(account, invoice.RHash) {
+			invoices = append(invoices, invoice)
+		}
+	}
+	resp.Invoices = invoices
+
+	return resp, nil
+}
+
+// subscribeInvoices drops all invoice updates that don't belong to the
+// account.
+func subscribeInvoices(ctx context.Context, c *call,
+	resp *lnrpc.Invoice) (proto.Message, error) {
+
+	account, err := c.account(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !c.ownsInvoice(account, resp.RHash) {
+		return nil, nil
+	}
+
+	return resp, nil
+}
+
+// sendPaymentSync reserves the amount of a payment sent over the main RPC
+// server.
+func sendPaymentSync(ctx context.Context, c *call,
+	req *lnrpc.SendRequest) error {
+
+	amt, err := lnrpc.UnmarshallAmt(req.Amt, req.AmtMsat)
+	if err != nil {
+		return err
+	}
+
+	hash := req.PaymentHash
+	switch {
+	case req.PaymentRequest != "":
+		invoice, err := zpay32.Decode(
+			req.PaymentRequest, c.service.cfg.ChainParams,
+		)
+		if err != nil {
+			return err
+		}
+
+		if invoice.MilliSat != nil {
+			amt = *invoice.MilliSat
+		}
+		if invoice.PaymentHash != nil {
+			hash = invoice.PaymentHash[:]
+		}
+
+	case req.PaymentHashString != "":
+		hash, err = hex.DecodeString(req.PaymentHashString)
+		if err != nil {
+			return err
+		}
+	}
+
+	feeLimit := lnrpc.CalculateFeeLimit(req.FeeLimit, amt)
+
+	return c.reservePayment(ctx, hash, amt, feeLimit)
+}
+
+// sendPaymentV2 reserves the amount of a payment sent over the router RPC
+// server.
+func sendPaymentV2(ctx context.Context, c *call,
+	req *routerrpc.SendPaymentRequest) error {
+
+	if req.Amp {
+		return fmt.Errorf("accounts can't send AMP payments")
+	}
+
+	amt, err := lnrpc.UnmarshallAmt(req.Amt, req.AmtMsat)
+	if err != nil {
+		return err
+	}
+
+	hash := req.PaymentHash
+	if req.PaymentRequest != "" {
+		invoice, err := zpay32.Decode(
+			req.PaymentRequest, c.service.cfg.ChainParams,
+		)
+		if err != nil {
+			return err
+		}
+
+		if invoice.MilliSat != nil {
+			amt = *invoice.MilliSat
+		}
+		if invoice.PaymentHash != nil {
+			hash = invoice.PaymentHash[:]
+		}
+	}
+
+	feeLimit, err := lnrpc.UnmarshallAmt(req.FeeLimitSat, req.FeeLimitMsat)
+	if err != nil {
+		return err
+	}
+
+	return c.reservePayment(ctx, hash, amt, feeLimit)
+}
+
+// routeAmount returns the full amount of a route, including fees.
+func routeAmount(route *lnrpc.Route) (lnwire.MilliSatoshi, error) {
+	if route == nil {
+		return 0, fmt.Errorf("missing route")
+	}
+
+	return lnrpc.UnmarshallAmt(0, route.TotalAmtMsat)
+}
+
+// sendToRouteSync reserves the full amount of a route sent to over the main
+// RPC server.
+func sendToRouteSync(ctx context.Context, c *call,
+	req *lnrpc.SendToRouteRequest) error {
+
+	amt, err := routeAmount(req.Route)
+	if err != nil {
+		return err
+	}
+
+	hash := req.PaymentHash
+	if req.PaymentHashString != "" {
+		hash, err = hex.DecodeString(req.PaymentHashString)
+		if err != nil {
+			return err
+		}
+	}
+
+	return c.reservePayment(ctx, hash, amt, 0)
+}
+
+// sendToRouteV2 reserves the full amount of a route sent to over the router
+// RPC server.
+func sendToRouteV2(ctx context.Context, c *call,
+	req *routerrpc.SendToRouteRequest) error {
+
+	amt, err := routeAmount(req.Route)
+	if err != nil {
+		return err
+	}
+
+	return c.reservePayment(ctx, req.PaymentHash, amt, 0)
+}
+
+// trackPaymentV2 only allows tracking payments of the account.
+func trackPaymentV2(ctx context.Context, c *call,
+	req *routerrpc.TrackPaymentRequest) error {
+
+	hash, err := lntypes.MakeHash(req.PaymentHash)
+	if err != nil {
+		return err
+	}
+
+	id, _, err := c.service.cfg.Store.PaymentAccount(ctx, hash)
+	switch {
+	case errors.Is(err, ErrAccountNotFound):
+		return errPaymentNotFound
+
+	case err != nil:
+		return err
+
+	case id != c.accountID:
+		return errPaymentNotFound
+	}
+
+	return nil
+}
+
+// listPayments removes all payments that don't belong to the account.
+func listPayments(ctx context.Context, c *call,
+	resp *lnrpc.ListPaymentsResponse) (proto.Message, error) {
+
+	account, err := c.account(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	payments := make([]*lnrpc.Payment, 0, len(resp.Payments))
+	for _, payment := range resp.Payments {
+		hash, err := lntypes.MakeHashFromStr(payment.PaymentHash)
+		if err != nil {
+			continue
+		}
+
+		if _, ok := account.Payments[hash]; ok {
+			payments = append(payments, payment)
+		}
+	}
+	resp.Payments = payments
+
+	// The total number of payments of the node would leak how many
+	// payments were sent by others.
+	if resp.TotalNumPayments != 0 {
+		resp.TotalNumPayments = uint64(len(payments))
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,317 @@
+package accounts
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lightningnetwork/lnd/lntypes"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/macaroons"
+)
+
+var (
+	// ErrAccountNotFound is returned if an account can't be found.
+	ErrAccountNotFound = errors.New("account not found")
+
+	// ErrLabelAlreadyExists is returned if an account is created or
+	// updated with a label that is already used by another account.
+	ErrLabelAlreadyExists = errors.New("account label already exists")
+
+	// ErrAccountExpired is returned if an expired account is used.
+	ErrAccountExpired = errors.New("account has expired")
+
+	// ErrInsufficientBalance is returned if a payment exceeds the
+	// available balance of an account.
+	ErrInsufficientBalance = errors.New("insufficient account balance")
+
+	// ErrBalanceReserved is returned if the balance of an account is set
+	// below the amount that is reserved for its payments in flight.
+	ErrBalanceReserved = errors.New("balance is lower than the amount " +
+		"reserved for payments in flight")
+
+	// ErrHashInUse is returned if an account tries to use a payment hash
+	// that belongs to another account or to a payment that was sent
+	// without the account.
+	ErrHashInUse = errors.New("payment hash is already in use")
+
+	// ErrPaymentExists is returned if an account tries to pay a payment
+	// hash that it already paid or is paying.
+	ErrPaymentExists = errors.New("payment already succeeded or is in " +
+		"flight")
+)
+
+// AccountID is the unique ID of an account. It is also the value of the
+// account caveat of the macaroons bound to the account.
+type AccountID [macaroons.AccountIDLen]byte
+
+// String returns the hex encoded account ID.
+func (a AccountID) String() string {
+	return hex.EncodeToString(a[:])
+}
+
+// ParseAccountID parses a hex encoded account ID.
+func ParseAccountID(s string) (AccountID, error) {
+	var id AccountID
+
+	idBytes, err := hex.DecodeString(s)
+	if err != nil {
+		return id, fmt.Errorf("invalid account ID: %w", err)
+	}
+	if len(idBytes) != len(id) {
+		return id, fmt.Errorf("invalid account ID length: %v",
+			len(idBytes))
+	}
+	copy(id[:], idBytes)
+
+	return id, nil
+}
+
+// PaymentStatus is the status of a payment sent by an account.
+type PaymentStatus uint8
+
+const (
+	// PaymentInFlight means the payment might still succeed. Its full
+	// amount is reserved from the balance of the account.
+	PaymentInFlight PaymentStatus = 1
+
+	// PaymentSucceeded means the payment succeeded and its full amount
+	// was debited from the balance of the account.
+	PaymentSucceeded PaymentStatus = 2
+
+	// PaymentFailed means the payment failed and no longer reserves any
+	// balance.
+	PaymentFailed PaymentStatus = 3
+)
+
+// String returns a human readable representation of the payment status.
+func (s PaymentStatus) String() string {
+	switch s {
+	case PaymentInFlight:
+		return "in_flight"
+
+	case PaymentSucceeded:
+		return "succeeded"
+
+	case PaymentFailed:
+		return "failed"
+
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(s))
+	}
+}
+
+// PaymentEntry is a payment sent by an account.
+type PaymentEntry struct {
+	// Status is the status of the payment.
+	Status PaymentStatus
+
+	// FullAmount is the amount reserved for the payment, including the
+	// maximum fee, while it is in flight. Once the payment succeeded, it
+	// is the amount that was actually paid, including fees.
+	FullAmount lnwire.MilliSatoshi
+}
+
+// Account is a virtual account with its own balance. All calls made with a
+// macaroon bound to the account can only use the balance of the account and
+// only see the invoices and payments of the account.
+type Account struct {
+	// ID is the unique ID of the account.
+	ID AccountID
+
+	// Label is an optional, unique human readable label of the account.
+	Label string
+
+	// InitialBalance is the balance the account was created with.
+	InitialBalance lnwire.MilliSatoshi
+
+	// CurrentBalance is the current balance of the account. Amounts
+	// reserved for payments in flight are not yet deducted from it.
+	CurrentBalance lnwire.MilliSatoshi
+
+	// LastUpdate is the time the account was last changed.
+	LastUpdate time.Time
+
+	// ExpirationDate is the time after which the account can no longer be
+	// used. The zero time means the account never expires.
+	ExpirationDate time.Time
+
+	// CreatedAt is the time the account was created.
+	CreatedAt time.Time
+
+	// Invoices is the set of the payment hashes of the invoices created
+	// by the account.
+	Invoices map[lntypes.Hash]struct{}
+
+	// Payments are the payments sent by the account, keyed by their
+	// payment hash.
+	Payments map[lntypes.Hash]*PaymentEntry
+}
+
+// HasExpired returns true if the account has an expiration date that lies in
+// the past.
+func (a *Account) HasExpired(now time.Time) bool {
+	return !a.ExpirationDate.IsZero() && !now.Before(a.ExpirationDate)
+}
+
+// ReservedBalance returns the amount that is reserved for the payments of the
+// account that are in flight.
+func (a *Account) ReservedBalance() lnwire.MilliSatoshi {
+	var reserved lnwire.MilliSatoshi
+	for _, payment := range a.Payments {
+		if payment.Status == PaymentInFlight {
+			reserved += payment.FullAmount
+		}
+	}
+
+	return reserved
+}
+
+// AvailableBalance returns the balance the account can still spend, which is
+// the current balance minus the amount reserved for payments in flight.
+func (a *Account) AvailableBalance() lnwire.MilliSatoshi {
+	reserved := a.ReservedBalance()
+	if reserved >= a.CurrentBalance {
+		return 0
+	}
+
+	return a.CurrentBalance - reserved
+}
+
+// LedgerEntryType is the type of a balance change of an account.
+type LedgerEntryType uint8
+
+const (
+	// LedgerOpen is the initial balance of a new account.
+	LedgerOpen LedgerEntryType = 0
+
+	// LedgerCredit is the amount paid to an invoice of the account.
+	LedgerCredit LedgerEntryType = 1
+
+	// LedgerDebit is the full amount of a successful payment of the
+	// account, including fees.
+	LedgerDebit LedgerEntryType = 2
+
+	// LedgerAdjustment is a manual change of the balance of the account.
+	LedgerAdjustment LedgerEntryType = 3
+)
+
+// String returns a human readable representation of the entry type.
+func (t LedgerEntryType) String() string {
+	switch t {
+	case LedgerOpen:
+		return "open"
+
+	case LedgerCredit:
+		return "credit"
+
+	case LedgerDebit:
+		return "debit"
+
+	case LedgerAdjustment:
+		return "adjustment"
+
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(t))
+	}
+}
+
+// LedgerEntry is a single change of the balance of an account.
+type LedgerEntry struct {
+	// Type is the type of the balance change.
+	Type LedgerEntryType
+
+	// Amount is the amount in millisatoshis the balance changed by. It is
+	// negative for debits.
+	Amount int64
+
+	// Balance is the balance of the account after the change.
+	Balance lnwire.MilliSatoshi
+
+	// Hash is the payment hash of the invoice or payment that changed the
+	// balance. It is nil for openings and adjustments.
+	Hash *lntypes.Hash
+
+	// Timestamp is the time of the balance change.
+	Timestamp time.Time
+}
+
+// Store is the persistent storage of accounts and their ledger.
+type Store interface {
+	// NewAccount creates a new account with the given balance, expiration
+	// date and label. A zero expiration date means the account never
+	// expires and an empty label means the account has no label.
+	NewAccount(ctx context.Context, balance lnwire.MilliSatoshi,
+		expirationDate time.Time, label string) (*Account, error)
+
+	// UpdateAccount sets the balance and/or the expiration date of an
+	// account. A nil value leaves the respective field unchanged, a zero
+	// expiration date removes the expiration of the account.
+	UpdateAccount(ctx context.Context, id AccountID,
+		balance *lnwire.MilliSatoshi,
+		expirationDate *time.Time) (*Account, error)
+
+	// Account returns the account with the given ID.
+	Account(ctx context.Context, id AccountID) (*Account, error)
+
+	// AccountByLabel returns the account with the given label.
+	AccountByLabel(ctx context.Context, label string) (*Account, error)
+
+	// Accounts returns all accounts.
+	Accounts(ctx context.Context) ([]*Account, error)
+
+	// RemoveAccount removes an account together with its ledger.
+	RemoveAccount(ctx context.Context, id AccountID) error
+
+	// Ledger returns all balance changes of an account, oldest first.
+	Ledger(ctx context.Context, id AccountID) ([]*LedgerEntry, error)
+
+	// AddInvoice assigns the invoice with the given payment hash to an
+	// account.
+	AddInvoice(ctx context.Context, id AccountID, hash lntypes.Hash) error
+
+	// InvoiceAccount returns the ID of the account the invoice with the
+	// given payment hash belongs to.
+	InvoiceAccount(ctx context.Context, hash lntypes.Hash) (AccountID,
+		error)
+
+	// CreditInvoice adds the given amount paid to the invoice with the
+	// given payment hash to the balance of its account. Crediting an
+	// invoice more than once has no effect. False is returned if the
+	// invoice doesn't belong to any account or was already credited.
+	CreditInvoice(ctx context.Context, hash lntypes.Hash,
+		amt lnwire.MilliSatoshi) (bool, error)
+
+	// UncreditedInvoices returns the payment hashes of all invoices that
+	// haven't been credited to their account yet.
+	UncreditedInvoices(ctx context.Context) ([]lntypes.Hash, error)
+
+	// ReservePayment reserves the given amount from the available balance
+	// of an account for a payment with the given payment hash. If the
+	// account already has a payment in flight for the hash, the amount is
+	// added to its reservation.
+	ReservePayment(ctx context.Context, id AccountID, hash lntypes.Hash,
+		amt lnwire.MilliSatoshi) error
+
+	// PaymentAccount returns the ID of the account the payment with the
+	// given payment hash belongs to, along with the payment itself.
+	PaymentAccount(ctx context.Context, hash lntypes.Hash) (AccountID,
+		*PaymentEntry, error)
+
+	// SettlePayment marks the payment with the given payment hash as
+	// succeeded and debits the given full amount, including fees, from
+	// the balance of its account. Settling a payment more than once has
+	// no effect.
+	SettlePayment(ctx context.Context, hash lntypes.Hash,
+		fullAmt lnwire.MilliSatoshi) error
+
+	// FailPayment marks the payment with the given payment hash as failed,
+	// which releases its reservation.
+	FailPayment(ctx context.Context, hash lntypes.Hash) error
+
+	// InFlightPayments returns the payment hashes of all payments that
+	// are in flight.
+	InFlightPayments(ctx context.Context) ([]lntypes.Hash, error)
+}
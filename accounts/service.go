@@ -0,0 +1,474 @@
+package accounts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/clock"
+	"github.com/lightningnetwork/lnd/invoices"
+	"github.com/lightningnetwork/lnd/lntypes"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/macaroons"
+	"github.com/lightningnetwork/lnd/rpcperms"
+	"github.com/lightningnetwork/lnd/sqldb"
+)
+
+// errSubscriptionClosed is returned if a payment subscription is closed
+// before the payment reached its final state.
+var errSubscriptionClosed = errors.New("payment subscription closed")
+
+// PaymentSubscriber receives the updates of a single payment.
+type PaymentSubscriber interface {
+	// Updates is the channel over which *channeldb.MPPayment updates are
+	// received. It is closed once the payment reached a final state.
+	Updates() <-chan interface{}
+
+	// Close signals that the subscriber is no longer interested in
+	// updates.
+	Close()
+}
+
+// Config holds the dependencies of the account service.
+type Config struct {
+	// Store is the persistent storage of the accounts.
+	Store Store
+
+	// ChainParams are the parameters of the chain lnd is running on,
+	// which are needed to decode payment requests.
+	ChainParams *chaincfg.Params
+
+	// SubscribeSettledInvoices returns a channel over which all invoices
+	// that are settled from now on are sent, together with a function
+	// that cancels the subscription.
+	SubscribeSettledInvoices func(ctx context.Context) (
+		<-chan *invoices.Invoice, func(), error)
+
+	// LookupInvoice returns the invoice with the given payment hash.
+	LookupInvoice func(ctx context.Context,
+		hash lntypes.Hash) (invoices.Invoice, error)
+
+	// SubscribePayment subscribes to the updates of the payment with the
+	// given payment hash. The current state of the payment is always sent
+	// first. If lnd doesn't know about the payment,
+	// channeldb.ErrPaymentNotInitiated is returned.
+	SubscribePayment func(hash lntypes.Hash) (PaymentSubscriber, error)
+
+	// Clock is the clock used to check the expiration of accounts.
+	Clock clock.Clock
+}
+
+// tracker is the state of a goroutine that waits for a payment of an account
+// to reach its final state.
+type tracker struct {
+	// retrack is set if another call sent the payment while it was
+	// tracked. Once the tracked payment reached its final state, the
+	// payment is tracked again, since the new attempt might not have been
+	// seen by the tracker.
+	retrack bool
+}
+
+// InterceptorService enforces the balances of all accounts. It debits the
+// payments and credits the invoices of every account and restricts the calls
+// made with account bound macaroons to the account.
+type InterceptorService struct {
+	started sync.Once
+	stopped sync.Once
+
+	cfg *Config
+
+	// mu serializes reserving payments and applying their final state,
+	// and protects the trackers.
+	mu       sync.Mutex
+	trackers map[lntypes.Hash]*tracker
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// A compile time check to ensure that InterceptorService fully implements the
+// rpcperms.AccountInterceptor interface.
+var _ rpcperms.AccountInterceptor = (*InterceptorService)(nil)
+
+// NewInterceptorService creates a new account service.
+func NewInterceptorService(cfg *Config) *InterceptorService {
+	return &InterceptorService{
+		cfg:      cfg,
+		trackers: make(map[lntypes.Hash]*tracker),
+		quit:     make(chan struct{}),
+	}
+}
+
+// Start subscribes to settled invoices, credits all invoices that were
+// settled while lnd was offline and resumes tracking the payments that are in
+// flight.
+func (s *InterceptorService) Start() error {
+	var startErr error
+	s.started.Do(func() {
+		log.Info("Account service starting")
+		startErr = s.start()
+	})
+
+	return startErr
+}
+
+// start does the actual work of Start.
+func (s *InterceptorService) start() error {
+	ctx, cancel := context.WithTimeout(
+		context.Background(), sqldb.DefaultStoreTimeout,
+	)
+	defer cancel()
+
+	// We subscribe before crediting the settled invoices, so we can't miss
+	// an invoice that is settled in between. Crediting an invoice twice
+	// has no effect.
+	settled, cancelSub, err := s.cfg.SubscribeSettledInvoices(
+		context.Background(),
+	)
+	if err != nil {
+		return fmt.Errorf("unable to subscribe to invoices: %w", err)
+	}
+
+	s.wg.Add(1)
+	go s.invoiceLoop(settled, cancelSub)
+
+	hashes, err := s.cfg.Store.UncreditedInvoices(ctx)
+	if err != nil {
+		return err
+	}
+	for _, hash := range hashes {
+		invoice, err := s.cfg.LookupInvoice(ctx, hash)
+		if errors.Is(err, invoices.ErrInvoiceNotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("unable to look up invoice %v: %w",
+				hash, err)
+		}
+
+		if invoice.State != invoices.ContractSettled {
+			continue
+		}
+
+		err = s.creditInvoice(ctx, hash, invoice.AmtPaid)
+		if err != nil {
+			return err
+		}
+	}
+
+	inFlight, err := s.cfg.Store.InFlightPayments(ctx)
+	if err != nil {
+		return err
+	}
+	for _, hash := range inFlight {
+		s.trackPayment(hash)
+	}
+
+	return nil
+}
+
+// Stop stops tracking invoices and payments.
+func (s *InterceptorService) Stop() error {
+	s.stopped.Do(func() {
+		log.Info("Account service shutting down...")
+		defer log.Debug("Account service shutdown complete")
+
+		s.mu.Lock()
+		close(s.quit)
+		s.mu.Unlock()
+
+		s.wg.Wait()
+	})
+
+	return nil
+}
+
+// NewCall returns the handler of a single call of the given method made with
+// a macaroon bound to the given account.
+//
+// NOTE: This is part of the rpcperms.AccountInterceptor interface.
+func (s *InterceptorService) NewCall(ctx context.Context,
+	accountID [macaroons.AccountIDLen]byte,
+	fullMethod string) (rpcperms.AccountCall, error) {
+
+	checker, ok := callCheckers[fullMethod]
+	if !ok {
+		return nil, fmt.Errorf("%w: %v", ErrMethodNotAllowed,
+			fullMethod)
+	}
+
+	account, err := s.cfg.Store.Account(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch account %v: %w",
+			AccountID(accountID), err)
+	}
+	if account.HasExpired(s.cfg.Clock.Now()) {
+		return nil, ErrAccountExpired
+	}
+
+	return &call{
+		service:   s,
+		accountID: account.ID,
+		checker:   checker,
+	}, nil
+}
+
+// invoiceLoop credits every settled invoice to its account.
+func (s *InterceptorService) invoiceLoop(settled <-chan *invoices.Invoice,
+	cancelSub func()) {
+
+	defer s.wg.Done()
+	defer cancelSub()
+
+	for {
+		select {
+		case invoice, ok := <-settled:
+			if !ok {
+				return
+			}
+
+			// Only invoices with a preimage can be created by an
+			// account, AMP invoices are not supported.
+			if invoice.Terms.PaymentPreimage == nil {
+				continue
+			}
+			hash := invoice.Terms.PaymentPreimage.Hash()
+
+			ctx, cancel := context.WithTimeout(
+				context.Background(), sqldb.DefaultStoreTimeout,
+			)
+			err := s.creditInvoice(ctx, hash, invoice.AmtPaid)
+			cancel()
+			if err != nil {
+				log.Errorf("Unable to credit invoice %v: %v",
+					hash, err)
+			}
+
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// creditInvoice credits the given amount paid to the invoice with the given
+// payment hash to its account, if it belongs to one.
+func (s *InterceptorService) creditInvoice(ctx context.Context,
+	hash lntypes.Hash, amt lnwire.MilliSatoshi) error {
+
+	credited, err := s.cfg.Store.CreditInvoice(ctx, hash, amt)
+	if err != nil {
+		return err
+	}
+
+	if credited {
+		log.Debugf("Credited %v paid to invoice %v", amt, hash)
+	}
+
+	return nil
+}
+
+// reservePayment reserves the given amount from the balance of an account for
+// a payment with the given payment hash.
+func (s *InterceptorService) reservePayment(ctx context.Context,
+	id AccountID, hash lntypes.Hash, amt lnwire.MilliSatoshi) error {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ownerID, payment, err := s.cfg.Store.PaymentAccount(ctx, hash)
+	switch {
+	// The account hasn't used the hash yet, so we need to make sure lnd
+	// doesn't know about a payment with this hash that might succeed, as
+	// it would be debited from the account otherwise.
+	case errors.Is(err, ErrAccountNotFound):
+		if err := s.checkUnknownPayment(ctx, hash); err != nil {
+			return err
+		}
+
+	case err != nil:
+		return err
+
+	case ownerID != id:
+		return ErrHashInUse
+
+	case payment.Status == PaymentSucceeded:
+		return ErrPaymentExists
+
+	// A failed payment of the account can be retried, as long as no one
+	// else paid the hash in the meantime.
+	case payment.Status == PaymentFailed:
+		if err := s.checkUnknownPayment(ctx, hash); err != nil {
+			return err
+		}
+	}
+
+	// Another part of a payment in flight is added to its reservation.
+	return s.cfg.Store.ReservePayment(ctx, id, hash, amt)
+}
+
+// checkUnknownPayment makes sure lnd doesn't know about a payment with the
+// given payment hash that has succeeded or might still succeed.
+func (s *InterceptorService) checkUnknownPayment(ctx context.Context,
+	hash lntypes.Hash) error {
+
+	sub, err := s.cfg.SubscribePayment(hash)
+	if errors.Is(err, channeldb.ErrPaymentNotInitiated) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer sub.Close()
+
+	select {
+	case update, ok := <-sub.Updates():
+		if !ok {
+			return ErrHashInUse
+		}
+
+		payment, ok := update.(*channeldb.MPPayment)
+		if !ok || payment.Status != channeldb.StatusFailed {
+			return ErrHashInUse
+		}
+
+		return nil
+
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// trackPayment starts tracking the payment with the given payment hash until
+// it reaches its final state, unless it is tracked already.
+func (s *InterceptorService) trackPayment(hash lntypes.Hash) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	select {
+	case <-s.quit:
+		return
+	default:
+	}
+
+	if t, ok := s.trackers[hash]; ok {
+		t.retrack = true
+		return
+	}
+
+	s.trackers[hash] = &tracker{}
+
+	s.wg.Add(1)
+	go s.trackLoop(hash)
+}
+
+// trackLoop waits for the payment with the given payment hash to reach its
+// final state and debits or releases its amount accordingly.
+func (s *InterceptorService) trackLoop(hash lntypes.Hash) {
+	defer s.wg.Done()
+
+	for {
+		status, fullAmt, err := s.waitForFinalState(hash)
+		if err != nil {
+			log.Errorf("Unable to track payment %v: %v", hash, err)
+		}
+
+		s.mu.Lock()
+		if status != PaymentInFlight {
+			err := s.applyFinalState(hash, status, fullAmt)
+			if err != nil {
+				log.Errorf("Unable to update payment %v: %v",
+					hash, err)
+			}
+		}
+
+		t := s.trackers[hash]
+		if status != PaymentInFlight && t.retrack {
+			t.retrack = false
+			s.mu.Unlock()
+
+			continue
+		}
+
+		delete(s.trackers, hash)
+		s.mu.Unlock()
+
+		return
+	}
+}
+
+// waitForFinalState subscribes to the payment with the given payment hash and
+// returns its status and full amount once it reached its final state. If the
+// service is stopped before, PaymentInFlight is returned.
+func (s *InterceptorService) waitForFinalState(hash lntypes.Hash) (
+	PaymentStatus, lnwire.MilliSatoshi, error) {
+
+	sub, err := s.cfg.SubscribePayment(hash)
+	if errors.Is(err, channeldb.ErrPaymentNotInitiated) {
+		// lnd rejected the payment before it was started.
+		return PaymentFailed, 0, nil
+	}
+	if err != nil {
+		return PaymentInFlight, 0, err
+	}
+	defer sub.Close()
+
+	for {
+		var update interface{}
+		select {
+		case u, ok := <-sub.Updates():
+			if !ok {
+				return PaymentInFlight, 0, errSubscriptionClosed
+			}
+			update = u
+
+		case <-s.quit:
+			return PaymentInFlight, 0, nil
+		}
+
+		payment, ok := update.(*channeldb.MPPayment)
+		if !ok {
+			return PaymentInFlight, 0, fmt.Errorf("unexpected "+
+				"payment update %T", update)
+		}
+
+		switch payment.Status {
+		case channeldb.StatusSucceeded:
+			amt, fees := payment.SentAmt()
+			return PaymentSucceeded, amt + fees, nil
+
+		case channeldb.StatusFailed:
+			return PaymentFailed, 0, nil
+		}
+	}
+}
+
+// applyFinalState debits or releases the amount of the payment with the given
+// payment hash depending on its final status.
+//
+// NOTE: The caller must hold s.mu.
+func (s *InterceptorService) applyFinalState(hash lntypes.Hash,
+	status PaymentStatus, fullAmt lnwire.MilliSatoshi) error {
+
+	ctx, cancel := context.WithTimeout(
+		context.Background(), sqldb.DefaultStoreTimeout,
+	)
+	defer cancel()
+
+	switch status {
+	case PaymentSucceeded:
+		log.Debugf("Payment %v succeeded, debiting %v", hash, fullAmt)
+
+		return s.cfg.Store.SettlePayment(ctx, hash, fullAmt)
+
+	case PaymentFailed:
+		log.Debugf("Payment %v failed, releasing its reservation", hash)
+
+		return s.cfg.Store.FailPayment(ctx, hash)
+
+	default:
+		return fmt.Errorf("unexpected final payment status %v", status)
+	}
+}
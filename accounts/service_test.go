@@ -0,0 +1,351 @@
+package accounts
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/clock"
+	"github.com/lightningnetwork/lnd/invoices"
+	"github.com/lightningnetwork/lnd/lnrpc"
+	"github.com/lightningnetwork/lnd/lnrpc/routerrpc"
+	"github.com/lightningnetwork/lnd/lntypes"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/routing/route"
+	"github.com/stretchr/testify/require"
+)
+
+// mockSubscriber is a payment subscription that sends a single update.
+type mockSubscriber struct {
+	updates chan interface{}
+}
+
+func (m *mockSubscriber) Updates() <-chan interface{} {
+	return m.updates
+}
+
+func (m *mockSubscriber) Close() {}
+
+// mockLnd provides the invoices and payments of a fake lnd node.
+type mockLnd struct {
+	sync.Mutex
+
+	settled  chan *invoices.Invoice
+	invoices map[lntypes.Hash]invoices.Invoice
+	payments map[lntypes.Hash]*channeldb.MPPayment
+}
+
+func newMockLnd() *mockLnd {
+	return &mockLnd{
+		settled:  make(chan *invoices.Invoice),
+		invoices: make(map[lntypes.Hash]invoices.Invoice),
+		payments: make(map[lntypes.Hash]*channeldb.MPPayment),
+	}
+}
+
+func (m *mockLnd) subscribeSettledInvoices(context.Context) (
+	<-chan *invoices.Invoice, func(), error) {
+
+	return m.settled, func() {}, nil
+}
+
+func (m *mockLnd) lookupInvoice(_ context.Context,
+	hash lntypes.Hash) (invoices.Invoice, error) {
+
+	m.Lock()
+	defer m.Unlock()
+
+	invoice, ok := m.invoices[hash]
+	if !ok {
+		return invoices.Invoice{}, invoices.ErrInvoiceNotFound
+	}
+
+	return invoice, nil
+}
+
+func (m *mockLnd) subscribePayment(hash lntypes.Hash) (PaymentSubscriber,
+	error) {
+
+	m.Lock()
+	defer m.Unlock()
+
+	payment, ok := m.payments[hash]
+	if !ok {
+		return nil, channeldb.ErrPaymentNotInitiated
+	}
+
+	sub := &mockSubscriber{updates: make(chan interface{}, 1)}
+	sub.updates <- payment
+
+	return sub, nil
+}
+
+func (m *mockLnd) setPayment(hash lntypes.Hash,
+	status channeldb.PaymentStatus, amt, fee lnwire.MilliSatoshi) {
+
+	m.Lock()
+	defer m.Unlock()
+
+	m.payments[hash] = &channeldb.MPPayment{
+		Status: status,
+		HTLCs: []channeldb.HTLCAttempt{{
+			HTLCAttemptInfo: channeldb.HTLCAttemptInfo{
+				Route: route.Route{
+					TotalAmount: amt + fee,
+					Hops: []*route.Hop{{
+						AmtToForward: amt,
+					}},
+				},
+			},
+		}},
+	}
+}
+
+// newTestService creates an account service backed by a fresh store and a
+// fake lnd node.
+func newTestService(t *testing.T) (*InterceptorService, *mockLnd) {
+	lnd := newMockLnd()
+	testClock := clock.NewTestClock(testTime)
+
+	service := NewInterceptorService(&Config{
+		Store:                    newTestStore(t, testClock),
+		ChainParams:              &chaincfg.RegressionNetParams,
+		SubscribeSettledInvoices: lnd.subscribeSettledInvoices,
+		LookupInvoice:            lnd.lookupInvoice,
+		SubscribePayment:         lnd.subscribePayment,
+		Clock:                    testClock,
+	})
+	t.Cleanup(func() {
+		require.NoError(t, service.Stop())
+	})
+
+	return service, lnd
+}
+
+// requireBalance waits for the account to reach the given current and
+// available balance.
+func requireBalance(t *testing.T, s *InterceptorService, id AccountID,
+	current, available lnwire.MilliSatoshi) {
+
+	require.Eventually(t, func() bool {
+		account, err := s.cfg.Store.Account(context.Background(), id)
+		require.NoError(t, err)
+
+		return account.CurrentBalance == current &&
+			account.AvailableBalance() == available
+	}, time.Second*5, time.Millisecond*10)
+}
+
+// TestServicePayments tests that payments sent with an account are reserved
+// and debited once they succeed.
+func TestServicePayments(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	service, lnd := newTestService(t)
+	require.NoError(t, service.Start())
+
+	account, err := service.cfg.Store.NewAccount(
+		ctx, 1_200, time.Time{}, "",
+	)
+	require.NoError(t, err)
+
+	_, err = service.NewCall(ctx, account.ID, "/lnrpc.Lightning/SendCoins")
+	require.ErrorIs(t, err, ErrMethodNotAllowed)
+
+	sendPayment := func(hash lntypes.Hash, amt, fee int64) (*call, error) {
+		c, err := service.NewCall(
+			ctx, account.ID, "/routerrpc.Router/SendPaymentV2",
+		)
+		require.NoError(t, err)
+
+		return c.(*call), c.HandleRequest(
+			ctx, &routerrpc.SendPaymentRequest{
+				PaymentHash:  hash[:],
+				AmtMsat:      amt,
+				FeeLimitMsat: fee,
+			},
+		)
+	}
+
+	// The full amount including the fee limit is reserved.
+	hash1 := lntypes.Hash{1}
+	call1, err := sendPayment(hash1, 1_000, 100)
+	require.NoError(t, err)
+	requireBalance(t, service, account.ID, 1_200, 100)
+
+	_, err = sendPayment(lntypes.Hash{2}, 100, 1)
+	require.ErrorIs(t, err, ErrInsufficientBalance)
+
+	// Hashes of payments that lnd sent without the account can't be used.
+	hash3 := lntypes.Hash{3}
+	lnd.setPayment(hash3, channeldb.StatusInFlight, 10, 0)
+	_, err = sendPayment(hash3, 10, 0)
+	require.ErrorIs(t, err, ErrHashInUse)
+
+	// Once the payment succeeded, the amount actually paid is debited.
+	lnd.setPayment(hash1, channeldb.StatusSucceeded, 1_000, 20)
+	call1.Done(nil)
+	requireBalance(t, service, account.ID, 180, 180)
+
+	_, err = sendPayment(hash1, 10, 0)
+	require.ErrorIs(t, err, ErrPaymentExists)
+
+	// Payments lnd never started release their reservation.
+	call4, err := sendPayment(lntypes.Hash{4}, 100, 0)
+	require.NoError(t, err)
+	requireBalance(t, service, account.ID, 180, 80)
+
+	call4.Done(nil)
+	requireBalance(t, service, account.ID, 180, 180)
+
+	// Payments can only be tracked by their account.
+	c, err := service.NewCall(
+		ctx, account.ID, "/routerrpc.Router/TrackPaymentV2",
+	)
+	require.NoError(t, err)
+	require.NoError(t, c.HandleRequest(ctx, &routerrpc.TrackPaymentRequest{
+		PaymentHash: hash1[:],
+	}))
+	require.ErrorIs(t, c.HandleRequest(
+		ctx, &routerrpc.TrackPaymentRequest{PaymentHash: hash3[:]},
+	), errPaymentNotFound)
+
+	// The channel balance is the balance of the account.
+	c, err = service.NewCall(
+		ctx, account.ID, "/lnrpc.Lightning/ChannelBalance",
+	)
+	require.NoError(t, err)
+	resp, err := c.HandleResponse(ctx, &lnrpc.ChannelBalanceResponse{
+		LocalBalance: &lnrpc.Amount{Sat: 1_000_000},
+	})
+	require.NoError(t, err)
+	require.EqualValues(
+		t, 180, resp.(*lnrpc.ChannelBalanceResponse).LocalBalance.Msat,
+	)
+}
+
+// TestServiceResumePayments tests that payments in flight are tracked again
+// after a restart.
+func TestServiceResumePayments(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	service, lnd := newTestService(t)
+
+	account, err := service.cfg.Store.NewAccount(
+		ctx, 1_000, time.Time{}, "",
+	)
+	require.NoError(t, err)
+
+	hash := lntypes.Hash{1}
+	err = service.cfg.Store.ReservePayment(ctx, account.ID, hash, 500)
+	require.NoError(t, err)
+
+	lnd.setPayment(hash, channeldb.StatusFailed, 500, 0)
+	require.NoError(t, service.Start())
+	requireBalance(t, service, account.ID, 1_000, 1_000)
+}
+
+// TestServiceInvoices tests that invoices created with an account are
+// credited to it and hidden from other accounts.
+func TestServiceInvoices(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	service, lnd := newTestService(t)
+
+	account, err := service.cfg.Store.NewAccount(
+		ctx, 0, time.Time{}, "",
+	)
+	require.NoError(t, err)
+	other, err := service.cfg.Store.NewAccount(ctx, 0, time.Time{}, "")
+	require.NoError(t, err)
+
+	// An invoice that was settled while lnd was offline is credited on
+	// startup.
+	preimage1 := lntypes.Preimage{1}
+	hash1 := preimage1.Hash()
+	err = service.cfg.Store.AddInvoice(ctx, account.ID, hash1)
+	require.NoError(t, err)
+
+	lnd.invoices[hash1] = invoices.Invoice{
+		State:   invoices.ContractSettled,
+		AmtPaid: 300,
+	}
+	require.NoError(t, service.Start())
+	requireBalance(t, service, account.ID, 300, 300)
+
+	// Invoices created with the account are assigned to it.
+	preimage2 := lntypes.Preimage{2}
+	hash2 := preimage2.Hash()
+	c, err := service.NewCall(
+		ctx, account.ID, "/lnrpc.Lightning/AddInvoice",
+	)
+	require.NoError(t, err)
+	require.NoError(t, c.HandleRequest(ctx, &lnrpc.Invoice{}))
+	_, err = c.HandleResponse(ctx, &lnrpc.AddInvoiceResponse{
+		RHash: hash2[:],
+	})
+	require.NoError(t, err)
+
+	err = c.HandleRequest(ctx, &lnrpc.Invoice{IsAmp: true})
+	require.Error(t, err)
+
+	// Settled invoices are credited to their account.
+	lnd.settled <- &invoices.Invoice{
+		Terms: invoices.ContractTerm{
+			PaymentPreimage: &preimage2,
+		},
+		AmtPaid: 700,
+	}
+	requireBalance(t, service, account.ID, 1_000, 1_000)
+
+	// Other accounts can neither see nor look up the invoices.
+	c, err = service.NewCall(ctx, other.ID, "/lnrpc.Lightning/ListInvoices")
+	require.NoError(t, err)
+	resp, err := c.HandleResponse(ctx, &lnrpc.ListInvoiceResponse{
+		Invoices: []*lnrpc.Invoice{
+			{RHash: hash1[:]}, {RHash: hash2[:]},
+		},
+	})
+	require.NoError(t, err)
+	require.Empty(t, resp.(*lnrpc.ListInvoiceResponse).Invoices)
+
+	c, err = service.NewCall(
+		ctx, other.ID, "/lnrpc.Lightning/LookupInvoice",
+	)
+	require.NoError(t, err)
+	err = c.HandleRequest(ctx, &lnrpc.PaymentHash{RHash: hash1[:]})
+	require.ErrorIs(t, err, errInvoiceNotFound)
+
+	c, err = service.NewCall(
+		ctx, account.ID, "/lnrpc.Lightning/LookupInvoice",
+	)
+	require.NoError(t, err)
+	err = c.HandleRequest(ctx, &lnrpc.PaymentHash{RHash: hash1[:]})
+	require.NoError(t, err)
+}
+
+// TestServiceExpiredAccount tests that expired accounts can't be used.
+func TestServiceExpiredAccount(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	service, _ := newTestService(t)
+
+	account, err := service.cfg.Store.NewAccount(
+		ctx, 1_000, testTime.Add(time.Hour), "",
+	)
+	require.NoError(t, err)
+
+	_, err = service.NewCall(ctx, account.ID, "/lnrpc.Lightning/GetInfo")
+	require.NoError(t, err)
+
+	service.cfg.Clock.(*clock.TestClock).SetTime(testTime.Add(time.Hour))
+	_, err = service.NewCall(ctx, account.ID, "/lnrpc.Lightning/GetInfo")
+	require.ErrorIs(t, err, ErrAccountExpired)
+}
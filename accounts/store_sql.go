@@ -0,0 +1,984 @@
+package accounts
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lightningnetwork/lnd/clock"
+	"github.com/lightningnetwork/lnd/lntypes"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/sqldb"
+	"github.com/lightningnetwork/lnd/sqldb/sqlc"
+)
+
+// SQLQueries is the set of queries the SQL account store needs.
+type SQLQueries interface {
+	InsertAccount(ctx context.Context,
+		arg sqlc.InsertAccountParams) (int32, error)
+
+	GetAccount(ctx context.Context, id int32) (sqlc.Account, error)
+
+	GetAccountByAlias(ctx context.Context, alias int64) (sqlc.Account,
+		error)
+
+	GetAccountByLabel(ctx context.Context,
+		label sql.NullString) (sqlc.Account, error)
+
+	ListAccounts(ctx context.Context) ([]sqlc.Account, error)
+
+	UpdateAccountBalance(ctx context.Context,
+		arg sqlc.UpdateAccountBalanceParams) error
+
+	UpdateAccountExpiration(ctx context.Context,
+		arg sqlc.UpdateAccountExpirationParams) error
+
+	DeleteAccount(ctx context.Context, id int32) error
+
+	InsertAccountInvoice(ctx context.Context,
+		arg sqlc.InsertAccountInvoiceParams) error
+
+	GetAccountInvoice(ctx context.Context,
+		hash []byte) (sqlc.AccountInvoice, error)
+
+	ListAccountInvoices(ctx context.Context,
+		accountID int32) ([]sqlc.AccountInvoice, error)
+
+	ListUncreditedAccountInvoices(
+		ctx context.Context) ([]sqlc.AccountInvoice, error)
+
+	SetAccountInvoiceCredited(ctx context.Context, hash []byte) error
+
+	UpsertAccountPayment(ctx context.Context,
+		arg sqlc.UpsertAccountPaymentParams) error
+
+	GetAccountPayment(ctx context.Context,
+		hash []byte) (sqlc.AccountPayment, error)
+
+	ListAccountPayments(ctx context.Context,
+		accountID int32) ([]sqlc.AccountPayment, error)
+
+	ListAccountPaymentsByStatus(ctx context.Context,
+		status int16) ([]sqlc.AccountPayment, error)
+
+	InsertAccountLedgerEntry(ctx context.Context,
+		arg sqlc.InsertAccountLedgerEntryParams) error
+
+	ListAccountLedgerEntries(ctx context.Context,
+		accountID int32) ([]sqlc.AccountLedger, error)
+}
+
+// SQLQueriesTxOptions defines the set of db txn options the SQLQueries
+// understands.
+type SQLQueriesTxOptions struct {
+	// readOnly governs if a read only transaction is needed or not.
+	readOnly bool
+}
+
+// ReadOnly returns true if the transaction should be read only.
+//
+// NOTE: This implements the TxOptions.
+func (a *SQLQueriesTxOptions) ReadOnly() bool {
+	return a.readOnly
+}
+
+// NewSQLQueriesReadTx creates a new read transaction option set.
+func NewSQLQueriesReadTx() SQLQueriesTxOptions {
+	return SQLQueriesTxOptions{
+		readOnly: true,
+	}
+}
+
+// BatchedSQLQueries is a version of the SQLQueries that's capable of batched
+// database operations.
+type BatchedSQLQueries interface {
+	SQLQueries
+
+	sqldb.BatchedTx[SQLQueries]
+}
+
+// SQLStore is an account store that is backed by the native SQL tables of
+// lnd.
+type SQLStore struct {
+	db    BatchedSQLQueries
+	clock clock.Clock
+}
+
+// A compile time check to ensure that SQLStore fully implements the Store
+// interface.
+var _ Store = (*SQLStore)(nil)
+
+// NewSQLStore creates a new SQLStore instance given an open BatchedSQLQueries
+// storage backend.
+func NewSQLStore(db BatchedSQLQueries, clock clock.Clock) *SQLStore {
+	return &SQLStore{
+		db:    db,
+		clock: clock,
+	}
+}
+
+// NewAccount creates a new account with the given balance, expiration date
+// and label. A zero expiration date means the account never expires and an
+// empty label means the account has no label.
+//
+// NOTE: This is part of the Store interface.
+func (s *SQLStore) NewAccount(ctx context.Context,
+	balance lnwire.MilliSatoshi, expirationDate time.Time,
+	label string) (*Account, error) {
+
+	// Labels are used to look up accounts in place of their ID, so a
+	// label must not be a valid account ID itself.
+	if _, err := ParseAccountID(label); err == nil {
+		return nil, fmt.Errorf("the label %v is a valid account ID, "+
+			"which isn't allowed", label)
+	}
+
+	var (
+		id          AccountID
+		writeTxOpts SQLQueriesTxOptions
+		now         = s.clock.Now().UTC()
+	)
+	err := s.db.ExecTx(ctx, &writeTxOpts, func(db SQLQueries) error {
+		if label != "" {
+			_, err := db.GetAccountByLabel(ctx, sqlString(label))
+			switch {
+			case err == nil:
+				return ErrLabelAlreadyExists
+
+			case !errors.Is(err, sql.ErrNoRows):
+				return err
+			}
+		}
+
+		var err error
+		id, err = uniqueAccountID(ctx, db)
+		if err != nil {
+			return err
+		}
+
+		dbID, err := db.InsertAccount(ctx, sqlc.InsertAccountParams{
+			Alias:              accountAlias(id),
+			Label:              sqlString(label),
+			InitialBalanceMsat: int64(balance),
+			CurrentBalanceMsat: int64(balance),
+			LastUpdated:        now,
+			Expiration:         sqlTime(expirationDate),
+			CreatedAt:          now,
+		})
+		if err != nil {
+			return err
+		}
+
+		return db.InsertAccountLedgerEntry(
+			ctx, sqlc.InsertAccountLedgerEntryParams{
+				AccountID:   dbID,
+				EntryType:   int16(LedgerOpen),
+				AmountMsat:  int64(balance),
+				BalanceMsat: int64(balance),
+				CreatedAt:   now,
+			},
+		)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create account: %w", err)
+	}
+
+	return s.Account(ctx, id)
+}
+
+// UpdateAccount sets the balance and/or the expiration date of an account. A
+// nil value leaves the respective field unchanged, a zero expiration date
+// removes the expiration of the account.
+//
+// NOTE: This is part of the Store interface.
+func (s *SQLStore) UpdateAccount(ctx context.Context, id AccountID,
+	balance *lnwire.MilliSatoshi, expirationDate *time.Time) (*Account,
+	error) {
+
+	var (
+		writeTxOpts SQLQueriesTxOptions
+		now         = s.clock.Now().UTC()
+	)
+	err := s.db.ExecTx(ctx, &writeTxOpts, func(db SQLQueries) error {
+		account, err := fetchAccount(ctx, db, id)
+		if err != nil {
+			return err
+		}
+		dbID := account.dbID
+
+		if balance != nil {
+			// The payments in flight might still succeed, so the
+			// balance must be able to cover them.
+			if *balance < account.ReservedBalance() {
+				return ErrBalanceReserved
+			}
+
+			err := db.UpdateAccountBalance(
+				ctx, sqlc.UpdateAccountBalanceParams{
+					CurrentBalanceMsat: int64(*balance),
+					LastUpdated:        now,
+					ID:                 dbID,
+				},
+			)
+			if err != nil {
+				return err
+			}
+
+			diff := int64(*balance) - int64(account.CurrentBalance)
+			err = db.InsertAccountLedgerEntry(
+				ctx, sqlc.InsertAccountLedgerEntryParams{
+					AccountID:   dbID,
+					EntryType:   int16(LedgerAdjustment),
+					AmountMsat:  diff,
+					BalanceMsat: int64(*balance),
+					CreatedAt:   now,
+				},
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		if expirationDate != nil {
+			err := db.UpdateAccountExpiration(
+				ctx, sqlc.UpdateAccountExpirationParams{
+					Expiration:  sqlTime(*expirationDate),
+					LastUpdated: now,
+					ID:          dbID,
+				},
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to update account: %w", err)
+	}
+
+	return s.Account(ctx, id)
+}
+
+// Account returns the account with the given ID.
+//
+// NOTE: This is part of the Store interface.
+func (s *SQLStore) Account(ctx context.Context, id AccountID) (*Account,
+	error) {
+
+	var (
+		account  *Account
+		readOpts = NewSQLQueriesReadTx()
+	)
+	err := s.db.ExecTx(ctx, &readOpts, func(db SQLQueries) error {
+		dbAccount, err := fetchAccount(ctx, db, id)
+		if err != nil {
+			return err
+		}
+		account = dbAccount.Account
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+// AccountByLabel returns the account with the given label.
+//
+// NOTE: This is part of the Store interface.
+func (s *SQLStore) AccountByLabel(ctx context.Context,
+	label string) (*Account, error) {
+
+	var (
+		account  *Account
+		readOpts = NewSQLQueriesReadTx()
+	)
+	err := s.db.ExecTx(ctx, &readOpts, func(db SQLQueries) error {
+		row, err := db.GetAccountByLabel(ctx, sqlString(label))
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrAccountNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		dbAccount, err := unmarshalAccount(ctx, db, row)
+		if err != nil {
+			return err
+		}
+		account = dbAccount.Account
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+// Accounts returns all accounts.
+//
+// NOTE: This is part of the Store interface.
+func (s *SQLStore) Accounts(ctx context.Context) ([]*Account, error) {
+	var (
+		accounts []*Account
+		readOpts = NewSQLQueriesReadTx()
+	)
+	err := s.db.ExecTx(ctx, &readOpts, func(db SQLQueries) error {
+		accounts = nil
+
+		rows, err := db.ListAccounts(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			dbAccount, err := unmarshalAccount(ctx, db, row)
+			if err != nil {
+				return err
+			}
+			accounts = append(accounts, dbAccount.Account)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return accounts, nil
+}
+
+// RemoveAccount removes an account together with its ledger.
+//
+// NOTE: This is part of the Store interface.
+func (s *SQLStore) RemoveAccount(ctx context.Context, id AccountID) error {
+	var writeTxOpts SQLQueriesTxOptions
+	return s.db.ExecTx(ctx, &writeTxOpts, func(db SQLQueries) error {
+		row, err := fetchAccountRow(ctx, db, id)
+		if err != nil {
+			return err
+		}
+
+		return db.DeleteAccount(ctx, row.ID)
+	})
+}
+
+// Ledger returns all balance changes of an account, oldest first.
+//
+// NOTE: This is part of the Store interface.
+func (s *SQLStore) Ledger(ctx context.Context,
+	id AccountID) ([]*LedgerEntry, error) {
+
+	var (
+		entries  []*LedgerEntry
+		readOpts = NewSQLQueriesReadTx()
+	)
+	err := s.db.ExecTx(ctx, &readOpts, func(db SQLQueries) error {
+		entries = nil
+
+		row, err := fetchAccountRow(ctx, db, id)
+		if err != nil {
+			return err
+		}
+
+		rows, err := db.ListAccountLedgerEntries(ctx, row.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			entry := &LedgerEntry{
+				Type:   LedgerEntryType(row.EntryType),
+				Amount: row.AmountMsat,
+				Balance: lnwire.MilliSatoshi(
+					row.BalanceMsat,
+				),
+				Timestamp: row.CreatedAt,
+			}
+			if len(row.Hash) != 0 {
+				hash, err := lntypes.MakeHash(row.Hash)
+				if err != nil {
+					return err
+				}
+				entry.Hash = &hash
+			}
+
+			entries = append(entries, entry)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// AddInvoice assigns the invoice with the given payment hash to an account.
+//
+// NOTE: This is part of the Store interface.
+func (s *SQLStore) AddInvoice(ctx context.Context, id AccountID,
+	hash lntypes.Hash) error {
+
+	var writeTxOpts SQLQueriesTxOptions
+	return s.db.ExecTx(ctx, &writeTxOpts, func(db SQLQueries) error {
+		row, err := fetchAccountRow(ctx, db, id)
+		if err != nil {
+			return err
+		}
+
+		_, err = db.GetAccountInvoice(ctx, hash[:])
+		switch {
+		case err == nil:
+			return ErrHashInUse
+
+		case !errors.Is(err, sql.ErrNoRows):
+			return err
+		}
+
+		return db.InsertAccountInvoice(
+			ctx, sqlc.InsertAccountInvoiceParams{
+				AccountID: row.ID,
+				Hash:      hash[:],
+			},
+		)
+	})
+}
+
+// InvoiceAccount returns the ID of the account the invoice with the given
+// payment hash belongs to.
+//
+// NOTE: This is part of the Store interface.
+func (s *SQLStore) InvoiceAccount(ctx context.Context,
+	hash lntypes.Hash) (AccountID, error) {
+
+	var (
+		id       AccountID
+		readOpts = NewSQLQueriesReadTx()
+	)
+	err := s.db.ExecTx(ctx, &readOpts, func(db SQLQueries) error {
+		invoice, err := db.GetAccountInvoice(ctx, hash[:])
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrAccountNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		id, err = accountIDByDBID(ctx, db, invoice.AccountID)
+
+		return err
+	})
+
+	return id, err
+}
+
+// CreditInvoice adds the given amount paid to the invoice with the given
+// payment hash to the balance of its account. Crediting an invoice more than
+// once has no effect. False is returned if the invoice doesn't belong to any
+// account or was already credited.
+//
+// NOTE: This is part of the Store interface.
+func (s *SQLStore) CreditInvoice(ctx context.Context, hash lntypes.Hash,
+	amt lnwire.MilliSatoshi) (bool, error) {
+
+	var (
+		credited    bool
+		writeTxOpts SQLQueriesTxOptions
+		now         = s.clock.Now().UTC()
+	)
+	err := s.db.ExecTx(ctx, &writeTxOpts, func(db SQLQueries) error {
+		credited = false
+
+		invoice, err := db.GetAccountInvoice(ctx, hash[:])
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if invoice.Credited {
+			return nil
+		}
+
+		err = db.SetAccountInvoiceCredited(ctx, hash[:])
+		if err != nil {
+			return err
+		}
+
+		row, err := db.GetAccount(ctx, invoice.AccountID)
+		if err != nil {
+			return err
+		}
+
+		balance := row.CurrentBalanceMsat + int64(amt)
+		err = db.UpdateAccountBalance(
+			ctx, sqlc.UpdateAccountBalanceParams{
+				CurrentBalanceMsat: balance,
+				LastUpdated:        now,
+				ID:                 row.ID,
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		credited = true
+
+		return db.InsertAccountLedgerEntry(
+			ctx, sqlc.InsertAccountLedgerEntryParams{
+				AccountID:   row.ID,
+				EntryType:   int16(LedgerCredit),
+				AmountMsat:  int64(amt),
+				BalanceMsat: balance,
+				Hash:        hash[:],
+				CreatedAt:   now,
+			},
+		)
+	})
+	if err != nil {
+		return false, fmt.Errorf("unable to credit invoice: %w", err)
+	}
+
+	return credited, nil
+}
+
+// UncreditedInvoices returns the payment hashes of all invoices that haven't
+// been credited to their account yet.
+//
+// NOTE: This is part of the Store interface.
+func (s *SQLStore) UncreditedInvoices(
+	ctx context.Context) ([]lntypes.Hash, error) {
+
+	var (
+		hashes   []lntypes.Hash
+		readOpts = NewSQLQueriesReadTx()
+	)
+	err := s.db.ExecTx(ctx, &readOpts, func(db SQLQueries) error {
+		hashes = nil
+
+		invoices, err := db.ListUncreditedAccountInvoices(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, invoice := range invoices {
+			hash, err := lntypes.MakeHash(invoice.Hash)
+			if err != nil {
+				return err
+			}
+			hashes = append(hashes, hash)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return hashes, nil
+}
+
+// ReservePayment reserves the given amount from the available balance of an
+// account for a payment with the given payment hash. If the account already
+// has a payment in flight for the hash, the amount is added to its
+// reservation.
+//
+// NOTE: This is part of the Store interface.
+func (s *SQLStore) ReservePayment(ctx context.Context, id AccountID,
+	hash lntypes.Hash, amt lnwire.MilliSatoshi) error {
+
+	var writeTxOpts SQLQueriesTxOptions
+	return s.db.ExecTx(ctx, &writeTxOpts, func(db SQLQueries) error {
+		account, err := fetchAccount(ctx, db, id)
+		if err != nil {
+			return err
+		}
+
+		if account.HasExpired(s.clock.Now()) {
+			return ErrAccountExpired
+		}
+
+		fullAmt := amt
+		payment, err := db.GetAccountPayment(ctx, hash[:])
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+
+		case err != nil:
+			return err
+
+		case payment.AccountID != account.dbID:
+			return ErrHashInUse
+
+		case PaymentStatus(payment.Status) == PaymentInFlight:
+			fullAmt += lnwire.MilliSatoshi(payment.FullAmountMsat)
+
+		case PaymentStatus(payment.Status) == PaymentSucceeded:
+			return ErrPaymentExists
+		}
+
+		if amt > account.AvailableBalance() {
+			return fmt.Errorf("%w: %v available, %v required",
+				ErrInsufficientBalance,
+				account.AvailableBalance(), amt)
+		}
+
+		return db.UpsertAccountPayment(
+			ctx, sqlc.UpsertAccountPaymentParams{
+				AccountID:      account.dbID,
+				Hash:           hash[:],
+				Status:         int16(PaymentInFlight),
+				FullAmountMsat: int64(fullAmt),
+			},
+		)
+	})
+}
+
+// PaymentAccount returns the ID of the account the payment with the given
+// payment hash belongs to, along with the payment itself.
+//
+// NOTE: This is part of the Store interface.
+func (s *SQLStore) PaymentAccount(ctx context.Context,
+	hash lntypes.Hash) (AccountID, *PaymentEntry, error) {
+
+	var (
+		id       AccountID
+		entry    *PaymentEntry
+		readOpts = NewSQLQueriesReadTx()
+	)
+	err := s.db.ExecTx(ctx, &readOpts, func(db SQLQueries) error {
+		payment, err := db.GetAccountPayment(ctx, hash[:])
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrAccountNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		entry = &PaymentEntry{
+			Status: PaymentStatus(payment.Status),
+			FullAmount: lnwire.MilliSatoshi(
+				payment.FullAmountMsat,
+			),
+		}
+		id, err = accountIDByDBID(ctx, db, payment.AccountID)
+
+		return err
+	})
+	if err != nil {
+		return id, nil, err
+	}
+
+	return id, entry, nil
+}
+
+// SettlePayment marks the payment with the given payment hash as succeeded
+// and debits the given full amount, including fees, from the balance of its
+// account. Settling a payment more than once has no effect.
+//
+// NOTE: This is part of the Store interface.
+func (s *SQLStore) SettlePayment(ctx context.Context, hash lntypes.Hash,
+	fullAmt lnwire.MilliSatoshi) error {
+
+	var (
+		writeTxOpts SQLQueriesTxOptions
+		now         = s.clock.Now().UTC()
+	)
+	return s.db.ExecTx(ctx, &writeTxOpts, func(db SQLQueries) error {
+		payment, err := db.GetAccountPayment(ctx, hash[:])
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrAccountNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if PaymentStatus(payment.Status) == PaymentSucceeded {
+			return nil
+		}
+
+		err = db.UpsertAccountPayment(
+			ctx, sqlc.UpsertAccountPaymentParams{
+				AccountID:      payment.AccountID,
+				Hash:           hash[:],
+				Status:         int16(PaymentSucceeded),
+				FullAmountMsat: int64(fullAmt),
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		row, err := db.GetAccount(ctx, payment.AccountID)
+		if err != nil {
+			return err
+		}
+
+		// The full amount of a payment never exceeds its reservation
+		// and the balance can't be set below the reserved amount, so
+		// this can only go negative if lnd paid more than it was
+		// allowed to. We don't let the balance go negative in that
+		// case, the ledger still records the full amount.
+		balance := row.CurrentBalanceMsat - int64(fullAmt)
+		if balance < 0 {
+			balance = 0
+		}
+
+		err = db.UpdateAccountBalance(
+			ctx, sqlc.UpdateAccountBalanceParams{
+				CurrentBalanceMsat: balance,
+				LastUpdated:        now,
+				ID:                 row.ID,
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		return db.InsertAccountLedgerEntry(
+			ctx, sqlc.InsertAccountLedgerEntryParams{
+				AccountID:   row.ID,
+				EntryType:   int16(LedgerDebit),
+				AmountMsat:  -int64(fullAmt),
+				BalanceMsat: balance,
+				Hash:        hash[:],
+				CreatedAt:   now,
+			},
+		)
+	})
+}
+
+// FailPayment marks the payment with the given payment hash as failed, which
+// releases its reservation.
+//
+// NOTE: This is part of the Store interface.
+func (s *SQLStore) FailPayment(ctx context.Context, hash lntypes.Hash) error {
+	var writeTxOpts SQLQueriesTxOptions
+	return s.db.ExecTx(ctx, &writeTxOpts, func(db SQLQueries) error {
+		payment, err := db.GetAccountPayment(ctx, hash[:])
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrAccountNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		// Only payments in flight can fail, a succeeded payment stays
+		// succeeded.
+		if PaymentStatus(payment.Status) != PaymentInFlight {
+			return nil
+		}
+
+		return db.UpsertAccountPayment(
+			ctx, sqlc.UpsertAccountPaymentParams{
+				AccountID:      payment.AccountID,
+				Hash:           hash[:],
+				Status:         int16(PaymentFailed),
+				FullAmountMsat: 0,
+			},
+		)
+	})
+}
+
+// InFlightPayments returns the payment hashes of all payments that are in
+// flight.
+//
+// NOTE: This is part of the Store interface.
+func (s *SQLStore) InFlightPayments(
+	ctx context.Context) ([]lntypes.Hash, error) {
+
+	var (
+		hashes   []lntypes.Hash
+		readOpts = NewSQLQueriesReadTx()
+	)
+	err := s.db.ExecTx(ctx, &readOpts, func(db SQLQueries) error {
+		hashes = nil
+
+		payments, err := db.ListAccountPaymentsByStatus(
+			ctx, int16(PaymentInFlight),
+		)
+		if err != nil {
+			return err
+		}
+
+		for _, payment := range payments {
+			hash, err := lntypes.MakeHash(payment.Hash)
+			if err != nil {
+				return err
+			}
+			hashes = append(hashes, hash)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return hashes, nil
+}
+
+// dbAccount is an account together with its database ID.
+type dbAccount struct {
+	*Account
+
+	dbID int32
+}
+
+// fetchAccountRow returns the database row of the account with the given ID.
+func fetchAccountRow(ctx context.Context, db SQLQueries,
+	id AccountID) (sqlc.Account, error) {
+
+	row, err := db.GetAccountByAlias(ctx, accountAlias(id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return row, ErrAccountNotFound
+	}
+
+	return row, err
+}
+
+// fetchAccount returns the account with the given ID, including its invoices
+// and payments.
+func fetchAccount(ctx context.Context, db SQLQueries,
+	id AccountID) (*dbAccount, error) {
+
+	row, err := fetchAccountRow(ctx, db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return unmarshalAccount(ctx, db, row)
+}
+
+// accountIDByDBID returns the ID of the account with the given database ID.
+func accountIDByDBID(ctx context.Context, db SQLQueries,
+	dbID int32) (AccountID, error) {
+
+	row, err := db.GetAccount(ctx, dbID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return AccountID{}, ErrAccountNotFound
+	}
+	if err != nil {
+		return AccountID{}, err
+	}
+
+	return aliasToAccountID(row.Alias), nil
+}
+
+// unmarshalAccount converts the given database row to an account and loads
+// its invoices and payments.
+func unmarshalAccount(ctx context.Context, db SQLQueries,
+	row sqlc.Account) (*dbAccount, error) {
+
+	account := &Account{
+		ID:             aliasToAccountID(row.Alias),
+		Label:          row.Label.String,
+		InitialBalance: lnwire.MilliSatoshi(row.InitialBalanceMsat),
+		CurrentBalance: lnwire.MilliSatoshi(row.CurrentBalanceMsat),
+		LastUpdate:     row.LastUpdated,
+		CreatedAt:      row.CreatedAt,
+		Invoices:       make(map[lntypes.Hash]struct{}),
+		Payments:       make(map[lntypes.Hash]*PaymentEntry),
+	}
+	if row.Expiration.Valid {
+		account.ExpirationDate = row.Expiration.Time
+	}
+
+	invoices, err := db.ListAccountInvoices(ctx, row.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, invoice := range invoices {
+		hash, err := lntypes.MakeHash(invoice.Hash)
+		if err != nil {
+			return nil, err
+		}
+		account.Invoices[hash] = struct{}{}
+	}
+
+	payments, err := db.ListAccountPayments(ctx, row.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, payment := range payments {
+		hash, err := lntypes.MakeHash(payment.Hash)
+		if err != nil {
+			return nil, err
+		}
+		account.Payments[hash] = &PaymentEntry{
+			Status: PaymentStatus(payment.Status),
+			FullAmount: lnwire.MilliSatoshi(
+				payment.FullAmountMsat,
+			),
+		}
+	}
+
+	return &dbAccount{
+		Account: account,
+		dbID:    row.ID,
+	}, nil
+}
+
+// uniqueAccountID returns a random account ID that isn't used yet.
+func uniqueAccountID(ctx context.Context, db SQLQueries) (AccountID, error) {
+	for {
+		var id AccountID
+		if _, err := rand.Read(id[:]); err != nil {
+			return id, err
+		}
+
+		_, err := db.GetAccountByAlias(ctx, accountAlias(id))
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return id, nil
+
+		case err != nil:
+			return id, err
+		}
+	}
+}
+
+// accountAlias returns the database alias of the given account ID.
+func accountAlias(id AccountID) int64 {
+	return int64(binary.BigEndian.Uint64(id[:]))
+}
+
+// aliasToAccountID returns the account ID of the given database alias.
+func aliasToAccountID(alias int64) AccountID {
+	var id AccountID
+	binary.BigEndian.PutUint64(id[:], uint64(alias))
+
+	return id
+}
+
+// sqlString returns the given string as nullable SQL string, where the empty
+// string is NULL.
+func sqlString(s string) sql.NullString {
+	return sql.NullString{
+		String: s,
+		Valid:  s != "",
+	}
+}
+
+// sqlTime returns the given time as nullable SQL time, where the zero time is
+// NULL.
+func sqlTime(t time.Time) sql.NullTime {
+	if t.IsZero() {
+		return sql.NullTime{}
+	}
+
+	return sql.NullTime{
+		Time:  t.UTC(),
+		Valid: true,
+	}
+}
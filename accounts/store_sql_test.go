@@ -0,0 +1,246 @@
+package accounts
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/lightningnetwork/lnd/clock"
+	"github.com/lightningnetwork/lnd/lntypes"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/sqldb"
+	"github.com/stretchr/testify/require"
+)
+
+var testTime = time.Unix(1_700_000_000, 0)
+
+// newTestStore creates a new SQL account store backed by a fresh SQLite
+// database.
+func newTestStore(t *testing.T, clock clock.Clock) *SQLStore {
+	db := sqldb.NewTestSqliteDB(t).BaseDB
+
+	executor := sqldb.NewTransactionExecutor(
+		db, func(tx *sql.Tx) SQLQueries {
+			return db.WithTx(tx)
+		},
+	)
+
+	return NewSQLStore(executor, clock)
+}
+
+// TestAccountStore tests creating, updating, listing and removing accounts.
+func TestAccountStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	testClock := clock.NewTestClock(testTime)
+	store := newTestStore(t, testClock)
+
+	expiry := testTime.Add(time.Hour)
+	account, err := store.NewAccount(ctx, 10_000, expiry, "alice")
+	require.NoError(t, err)
+	require.Equal(t, "alice", account.Label)
+	require.EqualValues(t, 10_000, account.InitialBalance)
+	require.EqualValues(t, 10_000, account.CurrentBalance)
+	require.True(t, expiry.Equal(account.ExpirationDate))
+	require.False(t, account.HasExpired(testClock.Now()))
+	require.True(t, account.HasExpired(expiry))
+
+	// Labels are unique and can't be mistaken for account IDs.
+	_, err = store.NewAccount(ctx, 1, time.Time{}, "alice")
+	require.ErrorIs(t, err, ErrLabelAlreadyExists)
+
+	_, err = store.NewAccount(ctx, 1, time.Time{}, account.ID.String())
+	require.Error(t, err)
+
+	// Accounts can be fetched by ID and label.
+	fetched, err := store.Account(ctx, account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.ID, fetched.ID)
+
+	fetched, err = store.AccountByLabel(ctx, "alice")
+	require.NoError(t, err)
+	require.Equal(t, account.ID, fetched.ID)
+
+	_, err = store.AccountByLabel(ctx, "bob")
+	require.ErrorIs(t, err, ErrAccountNotFound)
+
+	bob, err := store.NewAccount(ctx, 500, time.Time{}, "")
+	require.NoError(t, err)
+	require.True(t, bob.ExpirationDate.IsZero())
+
+	accounts, err := store.Accounts(ctx)
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+
+	// Updating the balance and removing the expiration is recorded in the
+	// ledger.
+	testClock.SetTime(testTime.Add(time.Minute))
+	balance := lnwire.MilliSatoshi(20_000)
+	noExpiry := time.Time{}
+	account, err = store.UpdateAccount(ctx, account.ID, &balance, &noExpiry)
+	require.NoError(t, err)
+	require.EqualValues(t, 20_000, account.CurrentBalance)
+	require.EqualValues(t, 10_000, account.InitialBalance)
+	require.True(t, account.ExpirationDate.IsZero())
+
+	ledger, err := store.Ledger(ctx, account.ID)
+	require.NoError(t, err)
+	require.Len(t, ledger, 2)
+	require.Equal(t, LedgerOpen, ledger[0].Type)
+	require.EqualValues(t, 10_000, ledger[0].Amount)
+	require.Equal(t, LedgerAdjustment, ledger[1].Type)
+	require.EqualValues(t, 10_000, ledger[1].Amount)
+	require.EqualValues(t, 20_000, ledger[1].Balance)
+
+	// Removing an account removes it entirely.
+	require.NoError(t, store.RemoveAccount(ctx, bob.ID))
+	_, err = store.Account(ctx, bob.ID)
+	require.ErrorIs(t, err, ErrAccountNotFound)
+	require.ErrorIs(t, store.RemoveAccount(ctx, bob.ID), ErrAccountNotFound)
+}
+
+// TestAccountStoreInvoices tests crediting invoices to their accounts.
+func TestAccountStoreInvoices(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := newTestStore(t, clock.NewTestClock(testTime))
+
+	account, err := store.NewAccount(ctx, 1_000, time.Time{}, "")
+	require.NoError(t, err)
+	other, err := store.NewAccount(ctx, 1_000, time.Time{}, "")
+	require.NoError(t, err)
+
+	hash := lntypes.Hash{1}
+	require.NoError(t, store.AddInvoice(ctx, account.ID, hash))
+	require.ErrorIs(t, store.AddInvoice(ctx, other.ID, hash), ErrHashInUse)
+
+	id, err := store.InvoiceAccount(ctx, hash)
+	require.NoError(t, err)
+	require.Equal(t, account.ID, id)
+
+	_, err = store.InvoiceAccount(ctx, lntypes.Hash{2})
+	require.ErrorIs(t, err, ErrAccountNotFound)
+
+	uncredited, err := store.UncreditedInvoices(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []lntypes.Hash{hash}, uncredited)
+
+	// Invoices are only credited once and only if they belong to an
+	// account.
+	credited, err := store.CreditInvoice(ctx, hash, 500)
+	require.NoError(t, err)
+	require.True(t, credited)
+
+	credited, err = store.CreditInvoice(ctx, hash, 500)
+	require.NoError(t, err)
+	require.False(t, credited)
+
+	credited, err = store.CreditInvoice(ctx, lntypes.Hash{2}, 500)
+	require.NoError(t, err)
+	require.False(t, credited)
+
+	account, err = store.Account(ctx, account.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 1_500, account.CurrentBalance)
+	require.Contains(t, account.Invoices, hash)
+
+	uncredited, err = store.UncreditedInvoices(ctx)
+	require.NoError(t, err)
+	require.Empty(t, uncredited)
+
+	ledger, err := store.Ledger(ctx, account.ID)
+	require.NoError(t, err)
+	require.Len(t, ledger, 2)
+	require.Equal(t, LedgerCredit, ledger[1].Type)
+	require.EqualValues(t, 500, ledger[1].Amount)
+	require.Equal(t, &hash, ledger[1].Hash)
+}
+
+// TestAccountStorePayments tests reserving, settling and failing payments.
+func TestAccountStorePayments(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := newTestStore(t, clock.NewTestClock(testTime))
+
+	account, err := store.NewAccount(ctx, 1_000, time.Time{}, "")
+	require.NoError(t, err)
+	other, err := store.NewAccount(ctx, 1_000, time.Time{}, "")
+	require.NoError(t, err)
+
+	// Payments can't exceed the available balance.
+	hash1, hash2 := lntypes.Hash{1}, lntypes.Hash{2}
+	err = store.ReservePayment(ctx, account.ID, hash1, 1_001)
+	require.ErrorIs(t, err, ErrInsufficientBalance)
+
+	require.NoError(t, store.ReservePayment(ctx, account.ID, hash1, 600))
+	err = store.ReservePayment(ctx, account.ID, hash2, 600)
+	require.ErrorIs(t, err, ErrInsufficientBalance)
+
+	// Another shard of the same payment is added to its reservation.
+	require.NoError(t, store.ReservePayment(ctx, account.ID, hash1, 100))
+	err = store.ReservePayment(ctx, other.ID, hash1, 100)
+	require.ErrorIs(t, err, ErrHashInUse)
+
+	account, err = store.Account(ctx, account.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 700, account.ReservedBalance())
+	require.EqualValues(t, 300, account.AvailableBalance())
+
+	// The balance can't be set below the reserved amount.
+	balance := lnwire.MilliSatoshi(500)
+	_, err = store.UpdateAccount(ctx, account.ID, &balance, nil)
+	require.ErrorIs(t, err, ErrBalanceReserved)
+
+	inFlight, err := store.InFlightPayments(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []lntypes.Hash{hash1}, inFlight)
+
+	// Settling debits the amount actually paid.
+	require.NoError(t, store.SettlePayment(ctx, hash1, 650))
+	require.NoError(t, store.SettlePayment(ctx, hash1, 650))
+
+	id, payment, err := store.PaymentAccount(ctx, hash1)
+	require.NoError(t, err)
+	require.Equal(t, account.ID, id)
+	require.Equal(t, PaymentSucceeded, payment.Status)
+	require.EqualValues(t, 650, payment.FullAmount)
+
+	account, err = store.Account(ctx, account.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 350, account.CurrentBalance)
+	require.EqualValues(t, 350, account.AvailableBalance())
+
+	err = store.ReservePayment(ctx, account.ID, hash1, 100)
+	require.ErrorIs(t, err, ErrPaymentExists)
+
+	// Failing releases the reservation.
+	require.NoError(t, store.ReservePayment(ctx, account.ID, hash2, 300))
+	require.NoError(t, store.FailPayment(ctx, hash2))
+
+	account, err = store.Account(ctx, account.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 350, account.AvailableBalance())
+
+	inFlight, err = store.InFlightPayments(ctx)
+	require.NoError(t, err)
+	require.Empty(t, inFlight)
+
+	ledger, err := store.Ledger(ctx, account.ID)
+	require.NoError(t, err)
+	require.Len(t, ledger, 2)
+	require.Equal(t, LedgerDebit, ledger[1].Type)
+	require.EqualValues(t, -650, ledger[1].Amount)
+	require.EqualValues(t, 350, ledger[1].Balance)
+
+	// Expired accounts can't send payments.
+	expired, err := store.NewAccount(
+		ctx, 1_000, testTime.Add(-time.Second), "",
+	)
+	require.NoError(t, err)
+	err = store.ReservePayment(ctx, expired.ID, lntypes.Hash{3}, 1)
+	require.ErrorIs(t, err, ErrAccountExpired)
+}
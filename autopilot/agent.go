@@ -58,6 +58,14 @@ type Config struct {
 	// when opening channels.
 	Constraints AgentConstraints
 
+	// Policies determines how the agent manages its existing channels.
+	Policies ChannelPolicies
+
+	// ChannelStatus returns the current status of our channels that can
+	// be managed by the channel policies. It is only required if the
+	// policies are enabled.
+	ChannelStatus func() ([]ChannelStatus, error)
+
 	// TODO(roasbeef): add additional signals from fee rates and revenue of
 	// currently opened channels
 }
@@ -166,6 +174,25 @@ type Agent struct {
 	pendingOpens map[NodeID]LocalChannel
 	pendingMtx   sync.Mutex
 
+	// inactiveSince and unbalancedSince track since when our channels
+	// have been inactive or unbalanced. They are only accessed by the
+	// policy loop.
+	inactiveSince   map[lnwire.ShortChannelID]time.Time
+	unbalancedSince map[lnwire.ShortChannelID]time.Time
+
+	// closing tracks the channels the agent requested to be closed. It is
+	// only accessed by the policy loop.
+	closing map[lnwire.ShortChannelID]struct{}
+
+	// rebalanced tracks the channels the agent attempted to move funds
+	// into or out of. It is only accessed by the policy loop.
+	rebalanced map[lnwire.ShortChannelID]struct{}
+
+	// actions holds the most recent actions taken to enforce the channel
+	// policies.
+	actions    []PolicyAction
+	actionsMtx sync.Mutex
+
 	quit chan struct{}
 	wg   sync.WaitGroup
 }
@@ -188,6 +215,10 @@ func New(cfg Config, initialState []LocalChannel) (*Agent, error) {
 		failedNodes:        make(map[NodeID]struct{}),
 		pendingConns:       make(map[NodeID]struct{}),
 		pendingOpens:       make(map[NodeID]LocalChannel),
+		inactiveSince:      make(map[lnwire.ShortChannelID]time.Time),
+		unbalancedSince:    make(map[lnwire.ShortChannelID]time.Time),
+		closing:            make(map[lnwire.ShortChannelID]struct{}),
+		rebalanced:         make(map[lnwire.ShortChannelID]struct{}),
 	}
 
 	for _, c := range initialState {
@@ -214,6 +245,11 @@ func (a *Agent) start() error {
 	a.wg.Add(1)
 	go a.controller()
 
+	if a.cfg.Policies.Enabled() {
+		a.wg.Add(1)
+		go a.policyLoop()
+	}
+
 	return nil
 }
 
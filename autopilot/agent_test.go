@@ -11,6 +11,7 @@ import (
 	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/wire"
+	"github.com/lightningnetwork/lnd/lnwire"
 	"github.com/stretchr/testify/require"
 )
 
@@ -137,7 +138,15 @@ func (m *mockChanController) OpenChannel(target *btcec.PublicKey,
 	return nil
 }
 
-func (m *mockChanController) CloseChannel(chanPoint *wire.OutPoint) error {
+func (m *mockChanController) CloseChannel(chanPoint *wire.OutPoint,
+	force bool) error {
+
+	return nil
+}
+
+func (m *mockChanController) Rebalance(outgoing, incoming lnwire.ShortChannelID,
+	amt btcutil.Amount, maxFee btcutil.Amount) error {
+
 	return nil
 }
 
@@ -373,7 +382,15 @@ func (m *mockFailingChanController) OpenChannel(target *btcec.PublicKey,
 	return errors.New("failure")
 }
 
-func (m *mockFailingChanController) CloseChannel(chanPoint *wire.OutPoint) error {
+func (m *mockFailingChanController) CloseChannel(chanPoint *wire.OutPoint,
+	force bool) error {
+
+	return nil
+}
+
+func (m *mockFailingChanController) Rebalance(outgoing, incoming lnwire.ShortChannelID,
+	amt btcutil.Amount, maxFee btcutil.Amount) error {
+
 	return nil
 }
 
@@ -184,6 +184,7 @@ var (
 		NewPrefAttachment(),
 		NewExternalScoreAttachment(),
 		NewTopCentrality(),
+		NewReliabilityAttachment(&ReliabilityConfig{}),
 	}
 
 	// AvailableHeuristics is a map that holds the name of available
@@ -211,8 +212,15 @@ type ChannelController interface {
 	// open has been broadcast.
 	OpenChannel(target *btcec.PublicKey, amt btcutil.Amount) error
 
-	// CloseChannel attempts to close out the target channel.
-	//
-	// TODO(roasbeef): add force option?
-	CloseChannel(chanPoint *wire.OutPoint) error
+	// CloseChannel attempts to close out the target channel. If force is
+	// true, the channel is closed unilaterally. Otherwise a cooperative
+	// close is negotiated with the peer.
+	CloseChannel(chanPoint *wire.OutPoint, force bool) error
+
+	// Rebalance moves amt from the outgoing channel to the incoming
+	// channel by sending a circular payment, paying at most maxFee in
+	// routing fees. This function should block until the payment has
+	// either succeeded or failed.
+	Rebalance(outgoing, incoming lnwire.ShortChannelID,
+		amt btcutil.Amount, maxFee btcutil.Amount) error
 }
@@ -37,6 +37,11 @@ type ManagerCfg struct {
 	// SubscribeTopology is used to get a subscription for topology changes
 	// on the network.
 	SubscribeTopology func() (*routing.TopologyClient, error)
+
+	// Heuristics are the heuristics that are queried for node scores in
+	// addition to the heuristic of the agent. If nil, all available
+	// heuristics are queried.
+	Heuristics []AttachmentHeuristic
 }
 
 // Manager is struct that manages an autopilot agent, making it possible to
@@ -94,6 +99,25 @@ func (m *Manager) IsActive() bool {
 	return m.pilot != nil
 }
 
+// Policies returns the channel policies of the autopilot agent.
+func (m *Manager) Policies() ChannelPolicies {
+	return m.cfg.PilotCfg.Policies
+}
+
+// PolicyActions returns the most recent actions the active autopilot agent
+// took to enforce its channel policies. If the agent is not active, no actions
+// are returned.
+func (m *Manager) PolicyActions() []PolicyAction {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.pilot == nil {
+		return nil
+	}
+
+	return m.pilot.PolicyActions()
+}
+
 // StartAgent creates and starts an autopilot agent from the Manager's
 // config.
 func (m *Manager) StartAgent() error {
@@ -322,8 +346,13 @@ func (m *Manager) queryHeuristics(nodes map[NodeID]struct{}, localState bool) (
 
 	// We'll start by getting the scores from each available sub-heuristic,
 	// in addition the current agent heuristic.
+	available := m.cfg.Heuristics
+	if available == nil {
+		available = availableHeuristics
+	}
+
 	var heuristics []AttachmentHeuristic
-	heuristics = append(heuristics, availableHeuristics...)
+	heuristics = append(heuristics, available...)
 	heuristics = append(heuristics, m.cfg.PilotCfg.Heuristic)
 
 	report := make(HeuristicScores)
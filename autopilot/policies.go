@@ -0,0 +1,465 @@
+package autopilot
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightningnetwork/lnd/lnwire"
+)
+
+const (
+	// maxPolicyActions is the number of most recent policy actions that
+	// the agent keeps around for inspection.
+	maxPolicyActions = 100
+
+	// minRebalanceAmt is the smallest amount the agent will attempt to
+	// move between two of its channels.
+	minRebalanceAmt = btcutil.Amount(10_000)
+)
+
+// ChannelPolicies configures how the agent manages the channels it already
+// has. Channels can be closed once they have been inactive or unbalanced for
+// too long, and funds can be moved between channels to keep their local
+// balance above a given ratio.
+type ChannelPolicies struct {
+	// Interval is how often the policies are evaluated. If zero, the
+	// agent doesn't manage its existing channels.
+	Interval time.Duration
+
+	// InactiveTimeout is the duration after which a channel whose peer
+	// stayed offline is force closed. If zero, inactive channels are
+	// never closed.
+	InactiveTimeout time.Duration
+
+	// UnbalancedTimeout is the duration after which a channel that stayed
+	// unbalanced is cooperatively closed. If zero, unbalanced channels
+	// are never closed.
+	UnbalancedTimeout time.Duration
+
+	// UnbalancedRatio is the fraction of the capacity below which the
+	// local or remote balance of a channel is considered unbalanced.
+	UnbalancedRatio float64
+
+	// MaxCloses is the maximum number of channels closed per evaluation.
+	// Channels to peers with the lowest heuristic scores are closed
+	// first.
+	MaxCloses uint32
+
+	// RebalanceRatio is the fraction of the capacity below which the
+	// local balance of a channel is refilled by a circular payment from
+	// another channel. If zero, no rebalancing is done.
+	RebalanceRatio float64
+
+	// RebalanceMaxFeeRate is the maximum fee rate in parts per million
+	// paid for moving funds between channels.
+	RebalanceMaxFeeRate uint32
+}
+
+// Enabled returns true if any of the policies is active.
+func (p *ChannelPolicies) Enabled() bool {
+	if p.Interval == 0 {
+		return false
+	}
+
+	return p.InactiveTimeout > 0 || p.UnbalancedTimeout > 0 ||
+		p.RebalanceRatio > 0
+}
+
+// ChannelStatus describes the current state of one of our channels as far as
+// the channel policies are concerned.
+type ChannelStatus struct {
+	// ChanID is the short channel ID of the channel.
+	ChanID lnwire.ShortChannelID
+
+	// ChanPoint is the funding outpoint of the channel.
+	ChanPoint wire.OutPoint
+
+	// Node is the peer of the channel.
+	Node NodeID
+
+	// Capacity is the total capacity of the channel.
+	Capacity btcutil.Amount
+
+	// LocalBalance is our current balance in the channel.
+	LocalBalance btcutil.Amount
+
+	// Active indicates whether the channel can currently be used, which
+	// requires its peer to be online.
+	Active bool
+
+	// NumUpdates is the number of updates applied to the channel's
+	// commitment, which is zero until the first HTLC is sent or received
+	// over it.
+	NumUpdates uint64
+}
+
+// localRatio returns the fraction of the channel's capacity that is on our
+// side.
+func (c *ChannelStatus) localRatio() float64 {
+	if c.Capacity == 0 {
+		return 0
+	}
+
+	return float64(c.LocalBalance) / float64(c.Capacity)
+}
+
+// PolicyActionType is the type of action taken by a channel policy.
+type PolicyActionType uint8
+
+const (
+	// PolicyActionClose is the cooperative close of a channel.
+	PolicyActionClose PolicyActionType = iota
+
+	// PolicyActionForceClose is the force close of a channel.
+	PolicyActionForceClose
+
+	// PolicyActionRebalance is a circular payment moving funds from one
+	// channel to another.
+	PolicyActionRebalance
+)
+
+// String returns a human readable representation of the action type.
+func (t PolicyActionType) String() string {
+	switch t {
+	case PolicyActionClose:
+		return "close"
+
+	case PolicyActionForceClose:
+		return "force_close"
+
+	case PolicyActionRebalance:
+		return "rebalance"
+
+	default:
+		return fmt.Sprintf("unknown(%d)", t)
+	}
+}
+
+// PolicyAction records an action the agent took to enforce its channel
+// policies.
+type PolicyAction struct {
+	// Type is the type of the action.
+	Type PolicyActionType
+
+	// Timestamp is the time the action was taken.
+	Timestamp time.Time
+
+	// ChanID is the channel that was closed, or the channel funds were
+	// moved out of for a rebalance.
+	ChanID lnwire.ShortChannelID
+
+	// TargetChanID is the channel funds were moved into for a rebalance.
+	TargetChanID lnwire.ShortChannelID
+
+	// Amount is the amount moved for a rebalance.
+	Amount btcutil.Amount
+
+	// Reason describes why the action was taken.
+	Reason string
+
+	// Err is set if the action failed.
+	Err error
+}
+
+// closeCandidate is a channel that violates a channel policy and may be
+// closed.
+type closeCandidate struct {
+	status *ChannelStatus
+	force  bool
+	reason string
+	score  float64
+}
+
+// policyLoop periodically evaluates the channel policies until the agent is
+// stopped.
+//
+// NOTE: This MUST be run as a goroutine.
+func (a *Agent) policyLoop() {
+	defer a.wg.Done()
+
+	ticker := time.NewTicker(a.cfg.Policies.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			if err := a.evaluatePolicies(now); err != nil {
+				log.Errorf("Unable to evaluate channel "+
+					"policies: %v", err)
+			}
+
+		case <-a.quit:
+			return
+		}
+	}
+}
+
+// evaluatePolicies checks our channels against the channel policies, closing
+// the channels that have been inactive or unbalanced for too long and
+// refilling the local balance of depleted channels.
+func (a *Agent) evaluatePolicies(now time.Time) error {
+	policies := a.cfg.Policies
+
+	channels, err := a.cfg.ChannelStatus()
+	if err != nil {
+		return fmt.Errorf("unable to fetch channel status: %w", err)
+	}
+
+	// First, we update how long each channel has been in violation of
+	// the policies and gather the ones that should be closed.
+	var (
+		candidates []*closeCandidate
+		usable     []*ChannelStatus
+		seen       = make(map[lnwire.ShortChannelID]struct{})
+	)
+	for i := range channels {
+		c := &channels[i]
+		seen[c.ChanID] = struct{}{}
+
+		if _, ok := a.closing[c.ChanID]; ok {
+			continue
+		}
+
+		inactiveFor := trackSince(
+			a.inactiveSince, c.ChanID, !c.Active, now,
+		)
+
+		// A freshly funded channel has all of its capacity on one
+		// side, so it is only considered unbalanced once it has seen
+		// traffic or we attempted to rebalance it.
+		_, rebalanced := a.rebalanced[c.ChanID]
+		used := c.NumUpdates > 0 || rebalanced
+
+		ratio := c.localRatio()
+		unbalanced := used && (ratio < policies.UnbalancedRatio ||
+			ratio > 1-policies.UnbalancedRatio)
+		unbalancedFor := trackSince(
+			a.unbalancedSince, c.ChanID, unbalanced, now,
+		)
+
+		switch {
+		case policies.InactiveTimeout > 0 && !c.Active &&
+			inactiveFor >= policies.InactiveTimeout:
+
+			candidates = append(candidates, &closeCandidate{
+				status: c,
+				force:  true,
+				reason: fmt.Sprintf("peer offline for %v",
+					inactiveFor),
+			})
+
+		case policies.UnbalancedTimeout > 0 && c.Active && unbalanced &&
+			unbalancedFor >= policies.UnbalancedTimeout:
+
+			candidates = append(candidates, &closeCandidate{
+				status: c,
+				reason: fmt.Sprintf("local balance ratio %.2f "+
+					"for %v", ratio, unbalancedFor),
+			})
+
+		case c.Active:
+			usable = append(usable, c)
+		}
+	}
+
+	// Forget about the channels that are gone.
+	for _, tracked := range []map[lnwire.ShortChannelID]time.Time{
+		a.inactiveSince, a.unbalancedSince,
+	} {
+		for chanID := range tracked {
+			if _, ok := seen[chanID]; !ok {
+				delete(tracked, chanID)
+			}
+		}
+	}
+	for _, tracked := range []map[lnwire.ShortChannelID]struct{}{
+		a.closing, a.rebalanced,
+	} {
+		for chanID := range tracked {
+			if _, ok := seen[chanID]; !ok {
+				delete(tracked, chanID)
+			}
+		}
+	}
+
+	if err := a.closeChans(candidates, now); err != nil {
+		return err
+	}
+
+	if policies.RebalanceRatio > 0 {
+		a.rebalance(usable, now)
+	}
+
+	return nil
+}
+
+// trackSince records the time a channel started to meet a condition and
+// returns for how long it has been meeting it.
+func trackSince(since map[lnwire.ShortChannelID]time.Time,
+	chanID lnwire.ShortChannelID, condition bool,
+	now time.Time) time.Duration {
+
+	if !condition {
+		delete(since, chanID)
+		return 0
+	}
+
+	start, ok := since[chanID]
+	if !ok {
+		since[chanID] = now
+		return 0
+	}
+
+	return now.Sub(start)
+}
+
+// closeChans closes up to the configured maximum number of the candidate
+// channels, starting with the ones whose peers are given the lowest scores by
+// the agent's heuristic.
+func (a *Agent) closeChans(candidates []*closeCandidate, now time.Time) error {
+	if len(candidates) == 0 || a.cfg.Policies.MaxCloses == 0 {
+		return nil
+	}
+
+	// We don't pass our channels to the heuristic, since it would skip
+	// our existing peers, which are exactly the nodes we want scored.
+	nodes := make(map[NodeID]struct{})
+	for _, c := range candidates {
+		nodes[c.status.Node] = struct{}{}
+	}
+	scores, err := a.cfg.Heuristic.NodeScores(
+		a.cfg.Graph, nil, a.cfg.Constraints.MaxChanSize(), nodes,
+	)
+	if err != nil {
+		return fmt.Errorf("unable to score channel peers: %w", err)
+	}
+
+	for _, c := range candidates {
+		if score, ok := scores[c.status.Node]; ok {
+			c.score = score.Score
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score < candidates[j].score
+	})
+
+	if uint32(len(candidates)) > a.cfg.Policies.MaxCloses {
+		candidates = candidates[:a.cfg.Policies.MaxCloses]
+	}
+
+	for _, c := range candidates {
+		log.Infof("Closing channel %v with peer %x (force=%v, "+
+			"score=%v): %v", c.status.ChanID, c.status.Node[:],
+			c.force, c.score, c.reason)
+
+		err := a.cfg.ChanController.CloseChannel(
+			&c.status.ChanPoint, c.force,
+		)
+		if err != nil {
+			log.Errorf("Unable to close channel %v: %v",
+				c.status.ChanID, err)
+		} else {
+			a.closing[c.status.ChanID] = struct{}{}
+		}
+
+		actionType := PolicyActionClose
+		if c.force {
+			actionType = PolicyActionForceClose
+		}
+		a.recordAction(PolicyAction{
+			Type:      actionType,
+			Timestamp: now,
+			ChanID:    c.status.ChanID,
+			Reason:    c.reason,
+			Err:       err,
+		})
+	}
+
+	return nil
+}
+
+// rebalance refills the channel with the lowest local balance ratio below the
+// rebalance ratio from the channel with the highest local balance ratio, such
+// that neither ends up with more than half of its capacity on our side.
+func (a *Agent) rebalance(channels []*ChannelStatus, now time.Time) {
+	if len(channels) < 2 {
+		return
+	}
+
+	sort.Slice(channels, func(i, j int) bool {
+		return channels[i].localRatio() < channels[j].localRatio()
+	})
+
+	target, source := channels[0], channels[len(channels)-1]
+	if target.localRatio() >= a.cfg.Policies.RebalanceRatio {
+		return
+	}
+
+	needed := target.Capacity/2 - target.LocalBalance
+	excess := source.LocalBalance - source.Capacity/2
+	amt := needed
+	if excess < amt {
+		amt = excess
+	}
+	if amt < minRebalanceAmt {
+		log.Debugf("Not rebalancing channel %v, only %v available "+
+			"in channel %v", target.ChanID, amt, source.ChanID)
+
+		return
+	}
+
+	maxFee := amt * btcutil.Amount(a.cfg.Policies.RebalanceMaxFeeRate) /
+		1_000_000
+	reason := fmt.Sprintf("local balance ratio %.2f below %.2f",
+		target.localRatio(), a.cfg.Policies.RebalanceRatio)
+
+	log.Infof("Moving %v from channel %v to channel %v with max fee "+
+		"%v: %v", amt, source.ChanID, target.ChanID, maxFee, reason)
+
+	a.rebalanced[source.ChanID] = struct{}{}
+	a.rebalanced[target.ChanID] = struct{}{}
+
+	err := a.cfg.ChanController.Rebalance(
+		source.ChanID, target.ChanID, amt, maxFee,
+	)
+	if err != nil {
+		log.Errorf("Unable to rebalance channel %v: %v", target.ChanID,
+			err)
+	}
+
+	a.recordAction(PolicyAction{
+		Type:         PolicyActionRebalance,
+		Timestamp:    now,
+		ChanID:       source.ChanID,
+		TargetChanID: target.ChanID,
+		Amount:       amt,
+		Reason:       reason,
+		Err:          err,
+	})
+}
+
+// recordAction adds an action to the list of recent policy actions.
+func (a *Agent) recordAction(action PolicyAction) {
+	a.actionsMtx.Lock()
+	defer a.actionsMtx.Unlock()
+
+	a.actions = append(a.actions, action)
+	if len(a.actions) > maxPolicyActions {
+		a.actions = a.actions[len(a.actions)-maxPolicyActions:]
+	}
+}
+
+// PolicyActions returns the most recent actions taken to enforce the channel
+// policies, oldest first.
+func (a *Agent) PolicyActions() []PolicyAction {
+	a.actionsMtx.Lock()
+	defer a.actionsMtx.Unlock()
+
+	actions := make([]PolicyAction, len(a.actions))
+	copy(actions, a.actions)
+
+	return actions
+}
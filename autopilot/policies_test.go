@@ -0,0 +1,187 @@
+package autopilot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/stretchr/testify/require"
+)
+
+// policyChanController records the channels closed and rebalanced by the
+// channel policies.
+type policyChanController struct {
+	closed     map[wire.OutPoint]bool
+	rebalances []PolicyAction
+}
+
+func (p *policyChanController) OpenChannel(*btcec.PublicKey,
+	btcutil.Amount) error {
+
+	return nil
+}
+
+func (p *policyChanController) CloseChannel(chanPoint *wire.OutPoint,
+	force bool) error {
+
+	p.closed[*chanPoint] = force
+
+	return nil
+}
+
+func (p *policyChanController) Rebalance(outgoing,
+	incoming lnwire.ShortChannelID, amt btcutil.Amount,
+	maxFee btcutil.Amount) error {
+
+	p.rebalances = append(p.rebalances, PolicyAction{
+		ChanID:       outgoing,
+		TargetChanID: incoming,
+		Amount:       amt,
+	})
+
+	return nil
+}
+
+var _ ChannelController = (*policyChanController)(nil)
+
+// staticHeuristic gives fixed scores to nodes.
+type staticHeuristic map[NodeID]float64
+
+func (s staticHeuristic) Name() string {
+	return "static"
+}
+
+func (s staticHeuristic) NodeScores(_ ChannelGraph, _ []LocalChannel,
+	_ btcutil.Amount, nodes map[NodeID]struct{}) (map[NodeID]*NodeScore,
+	error) {
+
+	scores := make(map[NodeID]*NodeScore)
+	for nID := range nodes {
+		if score, ok := s[nID]; ok {
+			scores[nID] = &NodeScore{NodeID: nID, Score: score}
+		}
+	}
+
+	return scores, nil
+}
+
+// TestChannelPolicies tests that channels that stay inactive or unbalanced
+// are closed in the order of their heuristic scores, that depleted channels
+// are refilled and that freshly funded channels aren't considered unbalanced.
+func TestChannelPolicies(t *testing.T) {
+	t.Parallel()
+
+	newStatus := func(i byte, local btcutil.Amount,
+		active bool) ChannelStatus {
+
+		return ChannelStatus{
+			ChanID:       lnwire.NewShortChanIDFromInt(uint64(i)),
+			ChanPoint:    wire.OutPoint{Index: uint32(i)},
+			Node:         NodeID{i},
+			Capacity:     1_000_000,
+			LocalBalance: local,
+			Active:       active,
+			NumUpdates:   1,
+		}
+	}
+
+	channels := []ChannelStatus{
+		// An inactive channel with a well scored peer.
+		newStatus(1, 500_000, false),
+
+		// An inactive channel with a badly scored peer.
+		newStatus(2, 500_000, false),
+
+		// A depleted channel.
+		newStatus(3, 20_000, true),
+
+		// A channel with most of the funds on our side.
+		newStatus(4, 900_000, true),
+	}
+
+	controller := &policyChanController{
+		closed: make(map[wire.OutPoint]bool),
+	}
+	agent, err := New(Config{
+		Heuristic: staticHeuristic{
+			{1}: 0.9,
+			{2}: 0.1,
+		},
+		ChanController: controller,
+		Graph:          newMemChannelGraph(),
+		Constraints: NewConstraints(
+			20_000, 1_000_000, 10, 10, 0.5,
+		),
+		Policies: ChannelPolicies{
+			Interval:            time.Minute,
+			InactiveTimeout:     time.Hour,
+			UnbalancedTimeout:   3 * time.Hour,
+			UnbalancedRatio:     0.05,
+			MaxCloses:           1,
+			RebalanceRatio:      0.2,
+			RebalanceMaxFeeRate: 1_000,
+		},
+		ChannelStatus: func() ([]ChannelStatus, error) {
+			return channels, nil
+		},
+	}, nil)
+	require.NoError(t, err)
+	require.True(t, agent.cfg.Policies.Enabled())
+
+	// On the first evaluation nothing is closed yet, but the depleted
+	// channel is refilled from the channel with the most local balance.
+	start := time.Unix(1_700_000_000, 0)
+	require.NoError(t, agent.evaluatePolicies(start))
+	require.Empty(t, controller.closed)
+	require.Equal(t, []PolicyAction{{
+		ChanID:       channels[3].ChanID,
+		TargetChanID: channels[2].ChanID,
+		Amount:       400_000,
+	}}, controller.rebalances)
+
+	// Once the channels have been inactive for long enough, only the one
+	// with the lowest scored peer is closed.
+	require.NoError(t, agent.evaluatePolicies(start.Add(time.Hour)))
+	require.Equal(t, map[wire.OutPoint]bool{
+		channels[1].ChanPoint: true,
+	}, controller.closed)
+
+	// The next evaluation closes the other one.
+	require.NoError(t, agent.evaluatePolicies(start.Add(2*time.Hour)))
+	require.Equal(t, map[wire.OutPoint]bool{
+		channels[0].ChanPoint: true,
+		channels[1].ChanPoint: true,
+	}, controller.closed)
+
+	// A channel that stays unbalanced is closed cooperatively, once the
+	// channels being closed are gone. A freshly funded channel without
+	// any traffic isn't considered unbalanced until we attempt to
+	// rebalance it.
+	fresh := newStatus(7, 1_000_000, true)
+	fresh.NumUpdates = 0
+	channels = []ChannelStatus{
+		newStatus(5, 10_000, true),
+		newStatus(6, 10_000, true),
+		fresh,
+	}
+	require.NoError(t, agent.evaluatePolicies(start.Add(3*time.Hour)))
+	require.NotContains(t, agent.unbalancedSince, fresh.ChanID)
+	require.Contains(t, agent.rebalanced, fresh.ChanID)
+
+	require.NoError(t, agent.evaluatePolicies(start.Add(6*time.Hour)))
+	require.Len(t, controller.closed, 3)
+	require.Contains(t, controller.closed, channels[0].ChanPoint)
+	require.False(t, controller.closed[channels[0].ChanPoint])
+	require.NotContains(t, controller.closed, fresh.ChanPoint)
+
+	actions := agent.PolicyActions()
+	require.Len(t, actions, 7)
+	require.Equal(t, PolicyActionRebalance, actions[0].Type)
+	require.Equal(t, PolicyActionForceClose, actions[1].Type)
+	require.Equal(t, PolicyActionRebalance, actions[5].Type)
+	require.Equal(t, fresh.ChanID, actions[5].ChanID)
+	require.Equal(t, PolicyActionClose, actions[6].Type)
+}
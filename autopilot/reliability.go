@@ -0,0 +1,252 @@
+package autopilot
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/routing/route"
+)
+
+const (
+	// DefaultReliabilityPaymentAmt is the payment amount for which the
+	// reliability heuristic queries the success probability of a node's
+	// channels if no other amount is configured.
+	DefaultReliabilityPaymentAmt = lnwire.MilliSatoshi(50_000_000)
+
+	// matureNodeAge is the age in blocks, roughly six months, from which
+	// on a node is considered to be fully established.
+	matureNodeAge = 26_280
+
+	// referenceFeeRate is the proportional fee rate in ppm that is given
+	// half of the fee score. Nodes charging less are scored higher, nodes
+	// charging more are scored lower.
+	referenceFeeRate = 500
+
+	// neutralScore is the score given to a node for a signal we don't have
+	// any information about.
+	neutralScore = 0.5
+
+	// The weights of the signals the reliability score is made up of.
+	// They sum to 1.0, such that the combined score stays within [0, 1].
+	probabilityWeight = 0.4
+	uptimeWeight      = 0.2
+	feeWeight         = 0.2
+	ageWeight         = 0.2
+)
+
+// ReliabilityConfig holds the sources of information the reliability
+// heuristic uses to score nodes. Sources that are nil are treated as having
+// no information about any node.
+type ReliabilityConfig struct {
+	// SuccessProbability returns the probability, as estimated by mission
+	// control, that a payment of the given amount can be forwarded from
+	// one node to another over a channel of the given capacity.
+	SuccessProbability func(from, to route.Vertex,
+		amt lnwire.MilliSatoshi, capacity btcutil.Amount) float64
+
+	// PaymentAmt is the payment amount that success probabilities are
+	// queried for. If zero, DefaultReliabilityPaymentAmt is used.
+	PaymentAmt lnwire.MilliSatoshi
+
+	// PeerUptime returns the fraction of time in [0, 1] that the node was
+	// observed online while we had channels with it. The boolean is false
+	// if no uptime is known for the node.
+	PeerUptime func(route.Vertex) (float64, bool)
+
+	// FeeRates returns the median proportional fee rate in ppm that each
+	// node advertises on its channels.
+	FeeRates func() (map[NodeID]uint32, error)
+
+	// BestHeight returns the height of the best block known to the chain
+	// backend, which is used to determine the age of nodes.
+	BestHeight func() (uint32, error)
+}
+
+// ReliabilityAttachment is an implementation of the AttachmentHeuristic
+// interface that favours reliable, well established nodes. Nodes are scored
+// by the success probability mission control assigns to their channels, the
+// uptime we observed, the fees they advertise and the age of their oldest
+// channel. This makes it a good fit for nodes that can't monitor their
+// channels closely, such as mobile wallets.
+type ReliabilityAttachment struct {
+	cfg *ReliabilityConfig
+}
+
+// NewReliabilityAttachment creates a new instance of a ReliabilityAttachment
+// heuristic that uses the given sources of information to score nodes.
+func NewReliabilityAttachment(cfg *ReliabilityConfig) *ReliabilityAttachment {
+	return &ReliabilityAttachment{
+		cfg: cfg,
+	}
+}
+
+// A compile time assertion to ensure ReliabilityAttachment meets the
+// AttachmentHeuristic interface.
+var _ AttachmentHeuristic = (*ReliabilityAttachment)(nil)
+
+// Name returns the name of this heuristic.
+//
+// NOTE: This is a part of the AttachmentHeuristic interface.
+func (r *ReliabilityAttachment) Name() string {
+	return "reliability"
+}
+
+// nodeStats holds the information gathered about a node from the graph.
+type nodeStats struct {
+	// probability is the sum of the success probabilities of the node's
+	// channels.
+	probability float64
+
+	// numChans is the number of channels the node has.
+	numChans int
+
+	// firstHeight is the block height of the node's oldest channel.
+	firstHeight uint32
+}
+
+// NodeScores is a method that given the current channel graph and current set
+// of local channels, scores the given nodes according to the preference of
+// opening a channel of the given size with them. The returned channel
+// candidates maps the NodeID to a NodeScore for the node.
+//
+// The score of a node is a weighted sum of the average success probability of
+// its channels, its observed uptime, a score decreasing with its advertised
+// fee rate and a score increasing with its age up to about six months. Signals
+// we have no information about for a node are given a neutral score.
+//
+// The returned scores will be in the range [0.0, 1.0], where higher scores are
+// given to nodes that are more likely to reliably forward our payments.
+//
+// NOTE: This is a part of the AttachmentHeuristic interface.
+func (r *ReliabilityAttachment) NodeScores(g ChannelGraph,
+	chans []LocalChannel, chanSize btcutil.Amount,
+	nodes map[NodeID]struct{}) (map[NodeID]*NodeScore, error) {
+
+	cfg := r.cfg
+
+	amt := cfg.PaymentAmt
+	if amt == 0 {
+		amt = DefaultReliabilityPaymentAmt
+	}
+
+	// We first gather the channel based information about the nodes we
+	// are asked to score.
+	stats := make(map[NodeID]*nodeStats)
+	err := g.ForEachNode(func(n Node) error {
+		nID := NodeID(n.PubKey())
+		if _, ok := nodes[nID]; !ok {
+			return nil
+		}
+
+		s := &nodeStats{
+			firstHeight: math.MaxUint32,
+		}
+		err := n.ForEachChannel(func(e ChannelEdge) error {
+			s.numChans++
+
+			if e.ChanID.BlockHeight < s.firstHeight {
+				s.firstHeight = e.ChanID.BlockHeight
+			}
+
+			if cfg.SuccessProbability != nil {
+				s.probability += cfg.SuccessProbability(
+					route.Vertex(nID),
+					route.Vertex(e.Peer.PubKey()), amt,
+					e.Capacity,
+				)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if s.numChans > 0 {
+			stats[nID] = s
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var feeRates map[NodeID]uint32
+	if cfg.FeeRates != nil {
+		feeRates, err = cfg.FeeRates()
+		if err != nil {
+			return nil, fmt.Errorf("unable to fetch fee rates: %w",
+				err)
+		}
+	}
+
+	var bestHeight uint32
+	if cfg.BestHeight != nil {
+		bestHeight, err = cfg.BestHeight()
+		if err != nil {
+			return nil, fmt.Errorf("unable to fetch best height: "+
+				"%w", err)
+		}
+	}
+
+	existingPeers := make(map[NodeID]struct{})
+	for _, c := range chans {
+		existingPeers[c.Node] = struct{}{}
+	}
+
+	candidates := make(map[NodeID]*NodeScore)
+	for nID, s := range stats {
+		// If the node is among our existing channel peers, we don't
+		// need another channel.
+		if _, ok := existingPeers[nID]; ok {
+			log.Tracef("Node %x among existing peers for "+
+				"reliability heuristic, giving zero score",
+				nID[:])
+			continue
+		}
+
+		probability := neutralScore
+		if cfg.SuccessProbability != nil {
+			probability = s.probability / float64(s.numChans)
+		}
+
+		uptime := neutralScore
+		if cfg.PeerUptime != nil {
+			if u, ok := cfg.PeerUptime(route.Vertex(nID)); ok {
+				uptime = u
+			}
+		}
+
+		fee := neutralScore
+		if feeRate, ok := feeRates[nID]; ok {
+			fee = referenceFeeRate /
+				(referenceFeeRate + float64(feeRate))
+		}
+
+		age := neutralScore
+		if bestHeight != 0 {
+			var blocks float64
+			if bestHeight > s.firstHeight {
+				blocks = float64(bestHeight - s.firstHeight)
+			}
+			age = math.Min(blocks/matureNodeAge, 1.0)
+		}
+
+		score := probabilityWeight*probability + uptimeWeight*uptime +
+			feeWeight*fee + ageWeight*age
+
+		log.Tracef("Giving node %x a reliability score of %v "+
+			"(probability=%v, uptime=%v, fee=%v, age=%v)", nID[:],
+			score, probability, uptime, fee, age)
+
+		candidates[nID] = &NodeScore{
+			NodeID: nID,
+			Score:  score,
+		}
+	}
+
+	return candidates, nil
+}
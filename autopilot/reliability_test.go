@@ -0,0 +1,92 @@
+package autopilot
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/routing/route"
+	"github.com/stretchr/testify/require"
+)
+
+// TestReliabilityAttachment tests that the reliability heuristic combines
+// mission control, uptime, fee and age information into node scores.
+func TestReliabilityAttachment(t *testing.T) {
+	t.Parallel()
+
+	graph := newMemChannelGraph()
+
+	// Create a hub node with a channel to each of three other nodes.
+	hub, err := graph.addRandNode()
+	require.NoError(t, err)
+
+	var nodes []NodeID
+	for i := 0; i < 3; i++ {
+		_, edge, err := graph.addRandChannel(
+			nil, hub, btcutil.SatoshiPerBitcoin,
+		)
+		require.NoError(t, err)
+
+		nodes = append(nodes, NodeID(edge.Peer.PubKey()))
+	}
+	reliable, unreliable, peer := nodes[0], nodes[1], nodes[2]
+
+	toScore := map[NodeID]struct{}{
+		reliable:   {},
+		unreliable: {},
+		peer:       {},
+	}
+	localChans := []LocalChannel{{Node: peer}}
+
+	// Without any configured sources, all nodes get a neutral score.
+	heuristic := NewReliabilityAttachment(&ReliabilityConfig{})
+	scores, err := heuristic.NodeScores(
+		graph, localChans, btcutil.SatoshiPerBitcoin, toScore,
+	)
+	require.NoError(t, err)
+	require.Len(t, scores, 2)
+	require.NotContains(t, scores, peer)
+	require.InDelta(t, neutralScore, scores[reliable].Score, 1e-9)
+	require.InDelta(t, neutralScore, scores[unreliable].Score, 1e-9)
+
+	// Once configured, the reliable node is given a higher score.
+	heuristic = NewReliabilityAttachment(&ReliabilityConfig{
+		SuccessProbability: func(from, _ route.Vertex,
+			amt lnwire.MilliSatoshi, _ btcutil.Amount) float64 {
+
+			require.Equal(t, DefaultReliabilityPaymentAmt, amt)
+			if NodeID(from) == reliable {
+				return 0.9
+			}
+
+			return 0.1
+		},
+		PeerUptime: func(node route.Vertex) (float64, bool) {
+			return 1.0, NodeID(node) == reliable
+		},
+		FeeRates: func() (map[NodeID]uint32, error) {
+			return map[NodeID]uint32{
+				reliable:   0,
+				unreliable: 4_500,
+			}, nil
+		},
+		BestHeight: func() (uint32, error) {
+			return matureNodeAge, nil
+		},
+	})
+
+	scores, err = heuristic.NodeScores(
+		graph, localChans, btcutil.SatoshiPerBitcoin, toScore,
+	)
+	require.NoError(t, err)
+	require.Len(t, scores, 2)
+
+	// The reliable node scores high on every signal.
+	require.InDelta(t, 0.4*0.9+0.2+0.2+0.2, scores[reliable].Score, 1e-9)
+
+	// The unreliable node has an unknown uptime and high fees.
+	require.InDelta(
+		t, 0.4*0.1+0.2*neutralScore+0.2*0.1+0.2,
+		scores[unreliable].Score, 1e-9,
+	)
+}
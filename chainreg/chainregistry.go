@@ -15,6 +15,7 @@ import (
 
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/rpcclient"
+	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcwallet/chain"
 	"github.com/lightninglabs/neutrino"
 	"github.com/lightningnetwork/lnd/blockcache"
@@ -30,6 +31,7 @@ import (
 	"github.com/lightningnetwork/lnd/lncfg"
 	"github.com/lightningnetwork/lnd/lnwallet"
 	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
+	"github.com/lightningnetwork/lnd/lnwallet/txtracker"
 	"github.com/lightningnetwork/lnd/lnwire"
 	"github.com/lightningnetwork/lnd/routing/chainview"
 	"github.com/lightningnetwork/lnd/walletunlocker"
@@ -166,6 +168,11 @@ type PartialChainControl struct {
 	// ChannelConstraints is the set of default constraints that will be
 	// used for any incoming or outgoing channel reservation requests.
 	ChannelConstraints channeldb.ChannelConstraints
+
+	// PublishPackage submits a parent and child transaction as a package
+	// to the backend. It is nil if the backend doesn't support package
+	// relay.
+	PublishPackage func(parent, child *wire.MsgTx) error
 }
 
 // ChainControl couples the three primary interfaces lnd utilizes for a
@@ -198,6 +205,11 @@ type ChainControl struct {
 	// Wallet is our LightningWallet that also contains the abstract Wc
 	// above. This wallet handles all of the lightning operations.
 	Wallet *lnwallet.LightningWallet
+
+	// TxTracker records the transactions published through the wallet
+	// and follows what happens to them afterwards. It is nil if the
+	// wallet doesn't track its publications.
+	TxTracker *txtracker.Tracker
 }
 
 // GenDefaultBtcConstraints generates the default set of channel constraints
@@ -520,6 +532,15 @@ func NewPartialChainControl(cfg *Config) (*PartialChainControl, func(), error) {
 			return err
 		}
 
+		// Zero-fee commitments can only be relayed as a package
+		// together with a child paying for them, which requires
+		// bitcoind's submitpackage call.
+		if ver >= minBitcoindPackageRelayVersion {
+			cc.PublishPackage = newBitcoindPackagePublisher(
+				chainConn,
+			)
+		}
+
 	case "btcd":
 		// Otherwise, we'll be speaking directly via RPC to a node.
 		//
@@ -0,0 +1,77 @@
+package chainreg
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/btcsuite/btcd/rpcclient"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// minBitcoindPackageRelayVersion is the first bitcoind version that accepts
+// packages of a zero-fee TRUC parent and its child through the submitpackage
+// call.
+const minBitcoindPackageRelayVersion = 280000
+
+// submitPackageResult is the part of the submitpackage response we're
+// interested in.
+type submitPackageResult struct {
+	// PackageMsg is "success" if the whole package was accepted.
+	PackageMsg string `json:"package_msg"`
+
+	// TxResults contains the per transaction results keyed by wtxid.
+	TxResults map[string]struct {
+		Txid  string `json:"txid"`
+		Error string `json:"error"`
+	} `json:"tx-results"`
+}
+
+// newBitcoindPackagePublisher returns a function that submits a parent and a
+// child transaction as a package to bitcoind.
+func newBitcoindPackagePublisher(
+	client *rpcclient.Client) func(parent, child *wire.MsgTx) error {
+
+	return func(parent, child *wire.MsgTx) error {
+		var txns []string
+		for _, tx := range []*wire.MsgTx{parent, child} {
+			var buf bytes.Buffer
+			if err := tx.Serialize(&buf); err != nil {
+				return err
+			}
+			txns = append(txns, hex.EncodeToString(buf.Bytes()))
+		}
+
+		rawTxns, err := json.Marshal(txns)
+		if err != nil {
+			return err
+		}
+
+		resp, err := client.RawRequest(
+			"submitpackage", []json.RawMessage{rawTxns},
+		)
+		if err != nil {
+			return fmt.Errorf("submitpackage failed: %w", err)
+		}
+
+		var result submitPackageResult
+		if err := json.Unmarshal(resp, &result); err != nil {
+			return err
+		}
+
+		for _, txResult := range result.TxResults {
+			if txResult.Error != "" {
+				return fmt.Errorf("package tx %v rejected: %v",
+					txResult.Txid, txResult.Error)
+			}
+		}
+
+		if result.PackageMsg != "" && result.PackageMsg != "success" {
+			return fmt.Errorf("package rejected: %v",
+				result.PackageMsg)
+		}
+
+		return nil
+	}
+}
@@ -272,6 +272,14 @@ func (r *RPCAcceptor) sendAcceptRequests(errChan chan error,
 					*req.OpenChanMsg.ChannelType,
 				)
 				switch {
+				// Zero-fee commitments can be combined with the
+				// zero-conf and scid-alias bits, which are
+				// extracted below.
+				case channelFeatures.IsSet(
+					lnwire.ZeroFeeCommitmentsRequired,
+				):
+					commitmentType = lnrpc.CommitmentType_ZERO_FEE_COMMITMENTS
+
 				case channelFeatures.OnlyContains(
 					lnwire.ZeroConfRequired,
 					lnwire.ScidAliasRequired,
@@ -52,6 +52,10 @@ const (
 	// SimpleTaprootVersion is a version that denotes this channel is using
 	// the musig2 based taproot commitment format.
 	SimpleTaprootVersion = 5
+
+	// ZeroFeeCommitmentVersion is a version that denotes this channel is
+	// using zero-fee commitments with a single ephemeral anchor.
+	ZeroFeeCommitmentVersion = 6
 )
 
 // Single is a static description of an existing channel that can be used for
@@ -220,6 +224,9 @@ func NewSingle(channel *channeldb.OpenChannel,
 	case channel.ChanType.IsTaproot():
 		single.Version = SimpleTaprootVersion
 
+	case channel.ChanType.HasZeroFeeCommitment():
+		single.Version = ZeroFeeCommitmentVersion
+
 	case channel.ChanType.HasLeaseExpiration():
 		single.Version = ScriptEnforcedLeaseVersion
 		single.LeaseExpiry = channel.ThawHeight
@@ -252,6 +259,7 @@ func (s *Single) Serialize(w io.Writer) error {
 	case AnchorsZeroFeeHtlcTxCommitVersion:
 	case ScriptEnforcedLeaseVersion:
 	case SimpleTaprootVersion:
+	case ZeroFeeCommitmentVersion:
 	default:
 		return fmt.Errorf("unable to serialize w/ unknown "+
 			"version: %v", s.Version)
@@ -429,6 +437,7 @@ func (s *Single) Deserialize(r io.Reader) error {
 	case AnchorsZeroFeeHtlcTxCommitVersion:
 	case ScriptEnforcedLeaseVersion:
 	case SimpleTaprootVersion:
+	case ZeroFeeCommitmentVersion:
 	default:
 		return fmt.Errorf("unable to de-serialize w/ unknown "+
 			"version: %v", s.Version)
@@ -309,6 +309,12 @@ const (
 	// SimpleTaprootFeatureBit indicates that the simple-taproot-chans
 	// feature bit was negotiated during the lifetime of the channel.
 	SimpleTaprootFeatureBit ChannelType = 1 << 10
+
+	// ZeroFeeCommitmentBit indicates that the channel uses zero-fee
+	// commitment transactions with a single ephemeral pay-to-anchor
+	// output. The commitment is a v3 (TRUC) transaction that needs to be
+	// relayed as a package together with a child spending the anchor.
+	ZeroFeeCommitmentBit ChannelType = 1 << 11
 )
 
 // IsSingleFunder returns true if the channel type if one of the known single
@@ -379,6 +385,12 @@ func (c ChannelType) IsTaproot() bool {
 	return c&SimpleTaprootFeatureBit == SimpleTaprootFeatureBit
 }
 
+// HasZeroFeeCommitment returns true if the channel uses zero-fee commitment
+// transactions with an ephemeral pay-to-anchor output.
+func (c ChannelType) HasZeroFeeCommitment() bool {
+	return c&ZeroFeeCommitmentBit == ZeroFeeCommitmentBit
+}
+
 // ChannelConstraints represents a set of constraints meant to allow a node to
 // limit their exposure, enact flow control and ensure that all HTLCs are
 // economically relevant. This struct will be mirrored for both sides of the
@@ -837,6 +849,11 @@ type OpenChannel struct {
 	// default ShortChannelID. This is only set for zero-conf channels.
 	confirmedScid lnwire.ShortChannelID
 
+	// latestDynCommit is the most recent change of the channel parameters
+	// that was agreed upon through a dynamic commitment negotiation. It is
+	// nil if the parameters were never changed.
+	latestDynCommit *DynCommitment
+
 	// Memo is any arbitrary information we wish to store locally about the
 	// channel that will be useful to our future selves.
 	Memo []byte
@@ -1829,6 +1846,18 @@ func fetchOpenChannel(chanBucket kvdb.RBucket,
 		return nil, fmt.Errorf("unable to fetch chan revocations: %v", err)
 	}
 
+	// If the channel parameters were changed through a dynamic commitment
+	// negotiation, we'll also load the latest change, as it determines
+	// which commitments still use the previous parameters.
+	dynHistory, err := fetchDynCommitHistory(chanBucket)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch dyn commit history: "+
+			"%v", err)
+	}
+	if len(dynHistory) > 0 {
+		channel.latestDynCommit = &dynHistory[len(dynHistory)-1]
+	}
+
 	channel.Packager = NewChannelPackager(channel.ShortChannelID)
 
 	return channel, nil
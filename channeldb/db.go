@@ -398,11 +398,16 @@ func CreateWithBackend(backend kvdb.Backend,
 	// Set the parent pointer (only used in tests).
 	chanDB.channelStateDB.parent = chanDB
 
+	// If the graph is stored in SQL, there's no need to populate the
+	// in-memory graph cache, as the channels of the nodes are loaded on
+	// demand instead.
+	useGraphCache := opts.UseGraphCache && opts.graphSQLDB == nil
+
 	var err error
 	chanDB.graph, err = NewChannelGraph(
 		backend, opts.RejectCacheSize, opts.ChannelCacheSize,
 		opts.BatchCommitInterval, opts.PreAllocCacheNumNodes,
-		opts.UseGraphCache, opts.NoMigration,
+		useGraphCache, opts.NoMigration,
 	)
 	if err != nil {
 		return nil, err
@@ -423,6 +428,26 @@ func CreateWithBackend(backend kvdb.Backend,
 		}
 	}
 
+	if opts.graphSQLDB != nil {
+		// Move the graph over to the SQL tables if that hasn't been
+		// done yet.
+		if !opts.NoMigration {
+			err := migrateGraphToSQL(
+				opts.graphSQLCtx, chanDB.graph, opts.graphSQLDB,
+			)
+			if err != nil {
+				backend.Close()
+				return nil, err
+			}
+		}
+
+		chanDB.graph.sqlStore = newSQLGraphStore(opts.graphSQLDB)
+	} else if err := ensureNoGraphTombstone(backend); err != nil {
+		// Once migrated, the graph of the kv backend is outdated.
+		backend.Close()
+		return nil, err
+	}
+
 	return chanDB, nil
 }
 
@@ -0,0 +1,444 @@
+package channeldb
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/lightningnetwork/lnd/kvdb"
+	"github.com/lightningnetwork/lnd/lnwire"
+)
+
+var (
+	// dynCommitHistoryKey is an entry in the channel bucket that stores
+	// the channel parameters that were replaced through dynamic commitment
+	// negotiations, together with the commitment heights from which on
+	// the new parameters are used.
+	dynCommitHistoryKey = []byte("dyn-commit-history-key")
+
+	// dynProposalKey is an entry in the channel bucket that stores the
+	// dynamic commitment proposal we've sent to the remote party, but
+	// haven't received a response for yet.
+	dynProposalKey = []byte("dyn-proposal-key")
+
+	// ErrNoDynProposal is returned when no pending dynamic commitment
+	// proposal is stored for a channel.
+	ErrNoDynProposal = errors.New("no pending dynamic commitment proposal")
+)
+
+// DynCommitment records a change of the channel parameters that was agreed
+// upon through a dynamic commitment negotiation. Since revoked commitments
+// that were created before the change can still be broadcast, the parameters
+// that were in effect for them are kept around.
+type DynCommitment struct {
+	// LocalCommitHeight is the height of the first local commitment that
+	// is created with the new parameters.
+	LocalCommitHeight uint64
+
+	// RemoteCommitHeight is the height of the first remote commitment
+	// that is created with the new parameters.
+	RemoteCommitHeight uint64
+
+	// Initiator is true if we proposed the change.
+	Initiator bool
+
+	// PrevChanType is the channel type that was used before the change.
+	PrevChanType ChannelType
+
+	// PrevLocalConstraints are the constraints of our channel config
+	// before the change.
+	PrevLocalConstraints ChannelConstraints
+
+	// PrevRemoteConstraints are the constraints of the remote party's
+	// channel config before the change.
+	PrevRemoteConstraints ChannelConstraints
+}
+
+// serializeChanConstraints writes the given channel constraints to w.
+func serializeChanConstraints(w io.Writer, c *ChannelConstraints) error {
+	return WriteElements(w,
+		c.DustLimit, c.MaxPendingAmount, c.ChanReserve, c.MinHTLC,
+		c.MaxAcceptedHtlcs, c.CsvDelay,
+	)
+}
+
+// deserializeChanConstraints reads channel constraints from r.
+func deserializeChanConstraints(r io.Reader, c *ChannelConstraints) error {
+	return ReadElements(r,
+		&c.DustLimit, &c.MaxPendingAmount, &c.ChanReserve, &c.MinHTLC,
+		&c.MaxAcceptedHtlcs, &c.CsvDelay,
+	)
+}
+
+// serializeDynCommitHistory writes the given dynamic commitment records to w.
+func serializeDynCommitHistory(w io.Writer, history []DynCommitment) error {
+	if err := WriteElement(w, uint32(len(history))); err != nil {
+		return err
+	}
+
+	for i := range history {
+		d := &history[i]
+		err := WriteElements(w,
+			d.LocalCommitHeight, d.RemoteCommitHeight, d.Initiator,
+			d.PrevChanType,
+		)
+		if err != nil {
+			return err
+		}
+
+		err = serializeChanConstraints(w, &d.PrevLocalConstraints)
+		if err != nil {
+			return err
+		}
+		err = serializeChanConstraints(w, &d.PrevRemoteConstraints)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deserializeDynCommitHistory reads a list of dynamic commitment records from
+// r.
+func deserializeDynCommitHistory(r io.Reader) ([]DynCommitment, error) {
+	var numRecords uint32
+	if err := ReadElement(r, &numRecords); err != nil {
+		return nil, err
+	}
+
+	history := make([]DynCommitment, numRecords)
+	for i := range history {
+		d := &history[i]
+		err := ReadElements(r,
+			&d.LocalCommitHeight, &d.RemoteCommitHeight,
+			&d.Initiator, &d.PrevChanType,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		err = deserializeChanConstraints(r, &d.PrevLocalConstraints)
+		if err != nil {
+			return nil, err
+		}
+		err = deserializeChanConstraints(r, &d.PrevRemoteConstraints)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return history, nil
+}
+
+// fetchDynCommitHistory reads the dynamic commitment history from the given
+// channel bucket. A nil slice is returned if the channel parameters were never
+// changed.
+func fetchDynCommitHistory(chanBucket kvdb.RBucket) ([]DynCommitment, error) {
+	historyBytes := chanBucket.Get(dynCommitHistoryKey)
+	if historyBytes == nil {
+		return nil, nil
+	}
+
+	return deserializeDynCommitHistory(bytes.NewReader(historyBytes))
+}
+
+// LatestDynCommitment returns the most recent change of the channel
+// parameters that was agreed upon through a dynamic commitment negotiation, or
+// nil if the parameters were never changed.
+func (c *OpenChannel) LatestDynCommitment() *DynCommitment {
+	c.RLock()
+	defer c.RUnlock()
+
+	return c.latestDynCommit
+}
+
+// DynCommitHistory returns all changes of the channel parameters that were
+// agreed upon through dynamic commitment negotiations, oldest first.
+func (c *OpenChannel) DynCommitHistory() ([]DynCommitment, error) {
+	c.RLock()
+	defer c.RUnlock()
+
+	var history []DynCommitment
+	err := kvdb.View(c.Db.backend, func(tx kvdb.RTx) error {
+		chanBucket, err := fetchChanBucket(
+			tx, c.IdentityPub, &c.FundingOutpoint, c.ChainHash,
+		)
+		if err != nil {
+			return err
+		}
+
+		history, err = fetchDynCommitHistory(chanBucket)
+
+		return err
+	}, func() {
+		history = nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
+// ApplyDynCommitment replaces the channel type and the constraints of both
+// channel configs with the passed values, both in memory and on disk. The
+// previous values are kept in the dynamic commitment history, as they are
+// still needed for commitments that were created before the change. The new
+// parameters are used starting with the next local and remote commitment. If
+// initiator is true, any pending dynamic commitment proposal is removed as
+// well.
+func (c *OpenChannel) ApplyDynCommitment(chanType ChannelType,
+	localConstraints, remoteConstraints ChannelConstraints,
+	initiator bool) error {
+
+	c.Lock()
+	defer c.Unlock()
+
+	var latest DynCommitment
+	err := kvdb.Update(c.Db.backend, func(tx kvdb.RwTx) error {
+		chanBucket, err := fetchChanBucketRw(
+			tx, c.IdentityPub, &c.FundingOutpoint, c.ChainHash,
+		)
+		if err != nil {
+			return err
+		}
+
+		channel, err := fetchOpenChannel(
+			chanBucket, &c.FundingOutpoint,
+		)
+		if err != nil {
+			return err
+		}
+
+		history, err := fetchDynCommitHistory(chanBucket)
+		if err != nil {
+			return err
+		}
+
+		latest = DynCommitment{
+			LocalCommitHeight: channel.LocalCommitment.CommitHeight +
+				1,
+			RemoteCommitHeight: channel.RemoteCommitment.CommitHeight +
+				1,
+			Initiator:             initiator,
+			PrevChanType:          channel.ChanType,
+			PrevLocalConstraints:  channel.LocalChanCfg.ChannelConstraints,
+			PrevRemoteConstraints: channel.RemoteChanCfg.ChannelConstraints,
+		}
+		history = append(history, latest)
+
+		var b bytes.Buffer
+		if err := serializeDynCommitHistory(&b, history); err != nil {
+			return err
+		}
+		err = chanBucket.Put(dynCommitHistoryKey, b.Bytes())
+		if err != nil {
+			return err
+		}
+
+		if initiator {
+			err := chanBucket.Delete(dynProposalKey)
+			if err != nil {
+				return err
+			}
+		}
+
+		channel.ChanType = chanType
+		channel.LocalChanCfg.ChannelConstraints = localConstraints
+		channel.RemoteChanCfg.ChannelConstraints = remoteConstraints
+
+		return putChanInfo(chanBucket, channel)
+	}, func() {})
+	if err != nil {
+		return err
+	}
+
+	c.ChanType = chanType
+	c.LocalChanCfg.ChannelConstraints = localConstraints
+	c.RemoteChanCfg.ChannelConstraints = remoteConstraints
+	c.latestDynCommit = &latest
+
+	return nil
+}
+
+// ParamsAtHeight returns a view of the channel that carries the channel type
+// and constraints that were in effect for the local or remote commitment at
+// the given height. If the parameters were never changed afterwards, the
+// channel itself is returned. The returned view must only be used to inspect
+// the commitment, as it isn't backed by the database.
+func (c *OpenChannel) ParamsAtHeight(local bool,
+	height uint64) (*OpenChannel, error) {
+
+	// The parameters can only have changed if there is at least one
+	// dynamic commitment record.
+	if c.LatestDynCommitment() == nil {
+		return c, nil
+	}
+
+	history, err := c.DynCommitHistory()
+	if err != nil {
+		return nil, err
+	}
+
+	c.RLock()
+	defer c.RUnlock()
+
+	var (
+		chanType          = c.ChanType
+		localConstraints  = c.LocalChanCfg.ChannelConstraints
+		remoteConstraints = c.RemoteChanCfg.ChannelConstraints
+		changed           bool
+	)
+
+	// Walk back through the history and undo every change that happened
+	// after the commitment at the given height was created.
+	for i := len(history) - 1; i >= 0; i-- {
+		d := history[i]
+
+		activationHeight := d.RemoteCommitHeight
+		if local {
+			activationHeight = d.LocalCommitHeight
+		}
+		if height >= activationHeight {
+			break
+		}
+
+		chanType = d.PrevChanType
+		localConstraints = d.PrevLocalConstraints
+		remoteConstraints = d.PrevRemoteConstraints
+		changed = true
+	}
+
+	if !changed {
+		return c, nil
+	}
+
+	view := c.copyChannel()
+	view.ChanType = chanType
+	view.LocalChanCfg.ChannelConstraints = localConstraints
+	view.RemoteChanCfg.ChannelConstraints = remoteConstraints
+
+	return view, nil
+}
+
+// copyChannel returns a shallow copy of the channel. The caller must hold the
+// channel's read lock.
+func (c *OpenChannel) copyChannel() *OpenChannel {
+	return &OpenChannel{
+		ChanType:                c.ChanType,
+		ChainHash:               c.ChainHash,
+		FundingOutpoint:         c.FundingOutpoint,
+		ShortChannelID:          c.ShortChannelID,
+		IsPending:               c.IsPending,
+		IsInitiator:             c.IsInitiator,
+		chanStatus:              c.chanStatus,
+		FundingBroadcastHeight:  c.FundingBroadcastHeight,
+		NumConfsRequired:        c.NumConfsRequired,
+		ChannelFlags:            c.ChannelFlags,
+		IdentityPub:             c.IdentityPub,
+		Capacity:                c.Capacity,
+		TotalMSatSent:           c.TotalMSatSent,
+		TotalMSatReceived:       c.TotalMSatReceived,
+		InitialLocalBalance:     c.InitialLocalBalance,
+		InitialRemoteBalance:    c.InitialRemoteBalance,
+		LocalChanCfg:            c.LocalChanCfg,
+		RemoteChanCfg:           c.RemoteChanCfg,
+		LocalCommitment:         c.LocalCommitment,
+		RemoteCommitment:        c.RemoteCommitment,
+		RemoteCurrentRevocation: c.RemoteCurrentRevocation,
+		RemoteNextRevocation:    c.RemoteNextRevocation,
+		RevocationProducer:      c.RevocationProducer,
+		RevocationStore:         c.RevocationStore,
+		Packager:                c.Packager,
+		FundingTxn:              c.FundingTxn,
+		LocalShutdownScript:     c.LocalShutdownScript,
+		RemoteShutdownScript:    c.RemoteShutdownScript,
+		ThawHeight:              c.ThawHeight,
+		LastWasRevoke:           c.LastWasRevoke,
+		RevocationKeyLocator:    c.RevocationKeyLocator,
+		confirmedScid:           c.confirmedScid,
+		Memo:                    c.Memo,
+		latestDynCommit:         c.latestDynCommit,
+		Db:                      c.Db,
+	}
+}
+
+// PutDynProposal stores the dynamic commitment proposal we've sent to the
+// remote party, so it can be retransmitted if we don't receive a response
+// before the connection is lost.
+func (c *OpenChannel) PutDynProposal(proposal *lnwire.DynPropose) error {
+	c.Lock()
+	defer c.Unlock()
+
+	var b bytes.Buffer
+	if err := proposal.Encode(&b, 0); err != nil {
+		return err
+	}
+
+	return kvdb.Update(c.Db.backend, func(tx kvdb.RwTx) error {
+		chanBucket, err := fetchChanBucketRw(
+			tx, c.IdentityPub, &c.FundingOutpoint, c.ChainHash,
+		)
+		if err != nil {
+			return err
+		}
+
+		return chanBucket.Put(dynProposalKey, b.Bytes())
+	}, func() {})
+}
+
+// FetchDynProposal returns the pending dynamic commitment proposal we've sent
+// to the remote party. If there is none, ErrNoDynProposal is returned.
+func (c *OpenChannel) FetchDynProposal() (*lnwire.DynPropose, error) {
+	c.RLock()
+	defer c.RUnlock()
+
+	var proposal *lnwire.DynPropose
+	err := kvdb.View(c.Db.backend, func(tx kvdb.RTx) error {
+		chanBucket, err := fetchChanBucket(
+			tx, c.IdentityPub, &c.FundingOutpoint, c.ChainHash,
+		)
+		switch err {
+		case nil:
+		case ErrNoChanDBExists, ErrNoActiveChannels, ErrChannelNotFound:
+			return ErrNoDynProposal
+		default:
+			return err
+		}
+
+		proposalBytes := chanBucket.Get(dynProposalKey)
+		if proposalBytes == nil {
+			return ErrNoDynProposal
+		}
+
+		proposal = &lnwire.DynPropose{}
+
+		return proposal.Decode(bytes.NewReader(proposalBytes), 0)
+	}, func() {
+		proposal = nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return proposal, nil
+}
+
+// DeleteDynProposal removes the pending dynamic commitment proposal of the
+// channel, if any.
+func (c *OpenChannel) DeleteDynProposal() error {
+	c.Lock()
+	defer c.Unlock()
+
+	return kvdb.Update(c.Db.backend, func(tx kvdb.RwTx) error {
+		chanBucket, err := fetchChanBucketRw(
+			tx, c.IdentityPub, &c.FundingOutpoint, c.ChainHash,
+		)
+		if err != nil {
+			return err
+		}
+
+		return chanBucket.Delete(dynProposalKey)
+	}, func() {})
+}
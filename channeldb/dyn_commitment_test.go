@@ -0,0 +1,114 @@
+package channeldb
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDynCommitment asserts that the channel parameters changed through a
+// dynamic commitment negotiation are persisted, and that the previous
+// parameters can still be retrieved for commitments created before the
+// change.
+func TestDynCommitment(t *testing.T) {
+	t.Parallel()
+
+	fullDB, err := MakeTestDB(t)
+	require.NoError(t, err, "unable to make test database")
+
+	cdb := fullDB.ChannelStateDB()
+	state := createTestChannel(t, cdb, openChannelOption())
+
+	// Without any change, there is no dyn commitment record and the
+	// channel itself is returned for any height.
+	require.Nil(t, state.LatestDynCommitment())
+	view, err := state.ParamsAtHeight(true, 0)
+	require.NoError(t, err)
+	require.Same(t, state, view)
+
+	_, err = state.FetchDynProposal()
+	require.ErrorIs(t, err, ErrNoDynProposal)
+
+	// Store a proposal and make sure we can read it back.
+	dustLimit := btcutil.Amount(500)
+	proposal := &lnwire.DynPropose{
+		ChanID:    lnwire.NewChanIDFromOutPoint(&state.FundingOutpoint),
+		DustLimit: &dustLimit,
+	}
+	require.NoError(t, state.PutDynProposal(proposal))
+
+	dbProposal, err := state.FetchDynProposal()
+	require.NoError(t, err)
+	require.Equal(t, proposal.ChanID, dbProposal.ChanID)
+	require.Equal(t, dustLimit, *dbProposal.DustLimit)
+
+	// Now apply a new set of parameters as the initiator of the change.
+	prevType := state.ChanType
+	prevLocal := state.LocalChanCfg.ChannelConstraints
+	prevRemote := state.RemoteChanCfg.ChannelConstraints
+	localHeight := state.LocalCommitment.CommitHeight
+	remoteHeight := state.RemoteCommitment.CommitHeight
+
+	newType := prevType | SingleFunderTweaklessBit | AnchorOutputsBit |
+		ZeroHtlcTxFeeBit
+	newLocal := prevLocal
+	newLocal.DustLimit = dustLimit
+	newRemote := prevRemote
+	newRemote.CsvDelay = prevRemote.CsvDelay + 10
+
+	err = state.ApplyDynCommitment(newType, newLocal, newRemote, true)
+	require.NoError(t, err)
+
+	// The pending proposal should have been removed.
+	_, err = state.FetchDynProposal()
+	require.ErrorIs(t, err, ErrNoDynProposal)
+
+	expectedRecord := &DynCommitment{
+		LocalCommitHeight:     localHeight + 1,
+		RemoteCommitHeight:    remoteHeight + 1,
+		Initiator:             true,
+		PrevChanType:          prevType,
+		PrevLocalConstraints:  prevLocal,
+		PrevRemoteConstraints: prevRemote,
+	}
+	require.Equal(t, expectedRecord, state.LatestDynCommitment())
+
+	// The new parameters and the record should also be found when reading
+	// the channel from disk.
+	openChannels, err := cdb.FetchOpenChannels(state.IdentityPub)
+	require.NoError(t, err)
+	require.Len(t, openChannels, 1)
+
+	dbChannel := openChannels[0]
+	require.Equal(t, newType, dbChannel.ChanType)
+	require.Equal(t, newLocal, dbChannel.LocalChanCfg.ChannelConstraints)
+	require.Equal(t, newRemote, dbChannel.RemoteChanCfg.ChannelConstraints)
+	require.Equal(t, expectedRecord, dbChannel.LatestDynCommitment())
+
+	// The current commitments were created with the previous parameters.
+	view, err = dbChannel.ParamsAtHeight(true, localHeight)
+	require.NoError(t, err)
+	require.Equal(t, prevType, view.ChanType)
+	require.Equal(t, prevLocal, view.LocalChanCfg.ChannelConstraints)
+	require.Equal(t, prevRemote, view.RemoteChanCfg.ChannelConstraints)
+	require.Equal(t, dbChannel.FundingOutpoint, view.FundingOutpoint)
+
+	view, err = dbChannel.ParamsAtHeight(false, remoteHeight)
+	require.NoError(t, err)
+	require.Equal(t, prevType, view.ChanType)
+
+	// The next commitments use the new ones.
+	view, err = dbChannel.ParamsAtHeight(true, localHeight+1)
+	require.NoError(t, err)
+	require.Same(t, dbChannel, view)
+
+	view, err = dbChannel.ParamsAtHeight(false, remoteHeight+1)
+	require.NoError(t, err)
+	require.Same(t, dbChannel, view)
+
+	history, err := dbChannel.DynCommitHistory()
+	require.NoError(t, err)
+	require.Equal(t, []DynCommitment{*expectedRecord}, history)
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/lightningnetwork/lnd/kvdb"
 	"github.com/lightningnetwork/lnd/lnwire"
 	"github.com/lightningnetwork/lnd/routing/route"
+	"github.com/lightningnetwork/lnd/tlv"
 )
 
 var (
@@ -179,6 +180,10 @@ const (
 type ChannelGraph struct {
 	db kvdb.Backend
 
+	// sqlStore is set if the graph is stored in the native SQL tables
+	// instead of the kv backend.
+	sqlStore *sqlGraphStore
+
 	cacheMu     sync.RWMutex
 	rejectCache *rejectCache
 	chanCache   *channelCache
@@ -393,9 +398,10 @@ func initChannelGraph(db kvdb.Backend) error {
 }
 
 // NewPathFindTx returns a new read transaction that can be used for a single
-// path finding session. Will return nil if the graph cache is enabled.
+// path finding session. Will return nil if the graph cache is enabled or the
+// graph is stored in SQL.
 func (c *ChannelGraph) NewPathFindTx() (kvdb.RTx, error) {
-	if c.graphCache != nil {
+	if c.graphCache != nil || c.sqlStore != nil {
 		return nil, nil
 	}
 
@@ -414,6 +420,10 @@ func (c *ChannelGraph) NewPathFindTx() (kvdb.RTx, error) {
 func (c *ChannelGraph) ForEachChannel(cb func(*ChannelEdgeInfo,
 	*ChannelEdgePolicy, *ChannelEdgePolicy) error) error {
 
+	if c.sqlStore != nil {
+		return c.sqlStore.forEachChannel(cb)
+	}
+
 	return c.db.View(func(tx kvdb.RTx) error {
 		edges := tx.ReadBucket(edgeBucket)
 		if edges == nil {
@@ -470,6 +480,10 @@ func (c *ChannelGraph) ForEachChannel(cb func(*ChannelEdgeInfo,
 func (c *ChannelGraph) ForEachNodeChannel(tx kvdb.RTx, node route.Vertex,
 	cb func(channel *DirectedChannel) error) error {
 
+	if c.sqlStore != nil {
+		return c.sqlStore.forEachNodeDirectedChannel(node, cb)
+	}
+
 	if c.graphCache != nil {
 		return c.graphCache.ForEachChannel(node, cb)
 	}
@@ -493,6 +507,13 @@ func (c *ChannelGraph) ForEachNodeChannel(tx kvdb.RTx, node route.Vertex,
 			cachedInPolicy.ToNodeFeatures = toNodeFeatures
 		}
 
+		var inboundFee lnwire.Fee
+		if p1 != nil {
+			// Extract the inbound fee. A malformed fee record is
+			// treated as a zero inbound fee.
+			inboundFee, _ = p1.InboundFee()
+		}
+
 		directedChannel := &DirectedChannel{
 			ChannelID:    e.ChannelID,
 			IsNode1:      node == e.NodeKey1Bytes,
@@ -500,6 +521,7 @@ func (c *ChannelGraph) ForEachNodeChannel(tx kvdb.RTx, node route.Vertex,
 			Capacity:     e.Capacity,
 			OutPolicySet: p1 != nil,
 			InPolicy:     cachedInPolicy,
+			InboundFee:   inboundFee,
 		}
 
 		if node == e.NodeKey2Bytes {
@@ -516,6 +538,10 @@ func (c *ChannelGraph) ForEachNodeChannel(tx kvdb.RTx, node route.Vertex,
 func (c *ChannelGraph) FetchNodeFeatures(
 	node route.Vertex) (*lnwire.FeatureVector, error) {
 
+	if c.sqlStore != nil {
+		return c.sqlStore.fetchNodeFeatures(node)
+	}
+
 	if c.graphCache != nil {
 		return c.graphCache.GetFeatures(node), nil
 	}
@@ -546,6 +572,10 @@ func (c *ChannelGraph) FetchNodeFeatures(
 func (c *ChannelGraph) ForEachNodeCached(cb func(node route.Vertex,
 	chans map[uint64]*DirectedChannel) error) error {
 
+	if c.sqlStore != nil {
+		return c.sqlStore.forEachNodeCached(cb)
+	}
+
 	if c.graphCache != nil {
 		return c.graphCache.ForEachNode(cb)
 	}
@@ -578,6 +608,13 @@ func (c *ChannelGraph) ForEachNodeCached(cb func(node route.Vertex,
 				cachedInPolicy.ToNodeFeatures = toNodeFeatures
 			}
 
+			var inboundFee lnwire.Fee
+			if p1 != nil {
+				// Extract the inbound fee. A malformed fee
+				// record is treated as a zero inbound fee.
+				inboundFee, _ = p1.InboundFee()
+			}
+
 			directedChannel := &DirectedChannel{
 				ChannelID:    e.ChannelID,
 				IsNode1:      node.PubKeyBytes == e.NodeKey1Bytes,
@@ -585,6 +622,7 @@ func (c *ChannelGraph) ForEachNodeCached(cb func(node route.Vertex,
 				Capacity:     e.Capacity,
 				OutPolicySet: p1 != nil,
 				InPolicy:     cachedInPolicy,
+				InboundFee:   inboundFee,
 			}
 
 			if node.PubKeyBytes == e.NodeKey2Bytes {
@@ -607,6 +645,10 @@ func (c *ChannelGraph) ForEachNodeCached(cb func(node route.Vertex,
 // A channel is disabled when two of the associated ChanelEdgePolicies
 // have their disabled bit on.
 func (c *ChannelGraph) DisabledChannelIDs() ([]uint64, error) {
+	if c.sqlStore != nil {
+		return c.sqlStore.disabledChannelIDs()
+	}
+
 	var disabledChanIDs []uint64
 	var chanEdgeFound map[uint64]struct{}
 
@@ -658,6 +700,10 @@ func (c *ChannelGraph) DisabledChannelIDs() ([]uint64, error) {
 func (c *ChannelGraph) ForEachNode(
 	cb func(kvdb.RTx, *LightningNode) error) error {
 
+	if c.sqlStore != nil {
+		return c.sqlStore.forEachNode(cb)
+	}
+
 	traversal := func(tx kvdb.RTx) error {
 		// First grab the nodes bucket which stores the mapping from
 		// pubKey to node information.
@@ -697,6 +743,10 @@ func (c *ChannelGraph) ForEachNode(
 func (c *ChannelGraph) ForEachNodeCacheable(cb func(kvdb.RTx,
 	GraphCacheNode) error) error {
 
+	if c.sqlStore != nil {
+		return c.sqlStore.forEachNodeCacheable(cb)
+	}
+
 	traversal := func(tx kvdb.RTx) error {
 		// First grab the nodes bucket which stores the mapping from
 		// pubKey to node information.
@@ -735,6 +785,10 @@ func (c *ChannelGraph) ForEachNodeCacheable(cb func(kvdb.RTx,
 // a path finding algorithm in order to explore the reachability of another
 // node based off the source node.
 func (c *ChannelGraph) SourceNode() (*LightningNode, error) {
+	if c.sqlStore != nil {
+		return c.sqlStore.sourceNode()
+	}
+
 	var source *LightningNode
 	err := kvdb.View(c.db, func(tx kvdb.RTx) error {
 		// First grab the nodes bucket which stores the mapping from
@@ -786,6 +840,10 @@ func (c *ChannelGraph) sourceNode(nodes kvdb.RBucket) (*LightningNode, error) {
 // node is to be used as the center of a star-graph within path finding
 // algorithms.
 func (c *ChannelGraph) SetSourceNode(node *LightningNode) error {
+	if c.sqlStore != nil {
+		return c.sqlStore.setSourceNode(node)
+	}
+
 	nodePubBytes := node.PubKeyBytes[:]
 
 	return kvdb.Update(c.db, func(tx kvdb.RwTx) error {
@@ -819,6 +877,10 @@ func (c *ChannelGraph) SetSourceNode(node *LightningNode) error {
 func (c *ChannelGraph) AddLightningNode(node *LightningNode,
 	op ...batch.SchedulerOption) error {
 
+	if c.sqlStore != nil {
+		return c.sqlStore.addLightningNode(node)
+	}
+
 	r := &batch.Request{
 		Update: func(tx kvdb.RwTx) error {
 			if c.graphCache != nil {
@@ -866,6 +928,10 @@ func addLightningNode(tx kvdb.RwTx, node *LightningNode) error {
 // LookupAlias attempts to return the alias as advertised by the target node.
 // TODO(roasbeef): currently assumes that aliases are unique...
 func (c *ChannelGraph) LookupAlias(pub *btcec.PublicKey) (string, error) {
+	if c.sqlStore != nil {
+		return c.sqlStore.lookupAlias(pub.SerializeCompressed())
+	}
+
 	var alias string
 
 	err := kvdb.View(c.db, func(tx kvdb.RTx) error {
@@ -902,6 +968,10 @@ func (c *ChannelGraph) LookupAlias(pub *btcec.PublicKey) (string, error) {
 // DeleteLightningNode starts a new database transaction to remove a vertex/node
 // from the database according to the node's public key.
 func (c *ChannelGraph) DeleteLightningNode(nodePub route.Vertex) error {
+	if c.sqlStore != nil {
+		return c.sqlStore.deleteLightningNode(nodePub)
+	}
+
 	// TODO(roasbeef): ensure dangling edges are removed...
 	return kvdb.Update(c.db, func(tx kvdb.RwTx) error {
 		nodes := tx.ReadWriteBucket(nodeBucket)
@@ -970,6 +1040,10 @@ func (c *ChannelGraph) deleteLightningNode(nodes kvdb.RwBucket,
 func (c *ChannelGraph) AddChannelEdge(edge *ChannelEdgeInfo,
 	op ...batch.SchedulerOption) error {
 
+	if c.sqlStore != nil {
+		return c.sqlStore.addChannelEdge(edge)
+	}
+
 	var alreadyExists bool
 	r := &batch.Request{
 		Reset: func() {
@@ -1116,6 +1190,10 @@ func (c *ChannelGraph) addChannelEdge(tx kvdb.RwTx, edge *ChannelEdgeInfo) error
 func (c *ChannelGraph) HasChannelEdge(
 	chanID uint64) (time.Time, time.Time, bool, bool, error) {
 
+	if c.sqlStore != nil {
+		return c.sqlStore.hasChannelEdge(chanID)
+	}
+
 	var (
 		upd1Time time.Time
 		upd2Time time.Time
@@ -1221,6 +1299,10 @@ func (c *ChannelGraph) HasChannelEdge(
 // that an edge info hasn't yet been created yet, but someone attempts to update
 // it.
 func (c *ChannelGraph) UpdateChannelEdge(edge *ChannelEdgeInfo) error {
+	if c.sqlStore != nil {
+		return c.sqlStore.updateChannelEdge(edge)
+	}
+
 	// Construct the channel's primary key which is the 8-byte channel ID.
 	var chanKey [8]byte
 	binary.BigEndian.PutUint64(chanKey[:], edge.ChannelID)
@@ -1267,6 +1349,10 @@ const (
 func (c *ChannelGraph) PruneGraph(spentOutputs []*wire.OutPoint,
 	blockHash *chainhash.Hash, blockHeight uint32) ([]*ChannelEdgeInfo, error) {
 
+	if c.sqlStore != nil {
+		return c.sqlStore.pruneGraph(spentOutputs, blockHash, blockHeight)
+	}
+
 	c.cacheMu.Lock()
 	defer c.cacheMu.Unlock()
 
@@ -1393,6 +1479,10 @@ func (c *ChannelGraph) PruneGraph(spentOutputs []*wire.OutPoint,
 // that we only maintain a graph of reachable nodes. In the event that a pruned
 // node gains more channels, it will be re-added back to the graph.
 func (c *ChannelGraph) PruneGraphNodes() error {
+	if c.sqlStore != nil {
+		return c.sqlStore.pruneGraphNodes()
+	}
+
 	return kvdb.Update(c.db, func(tx kvdb.RwTx) error {
 		nodes := tx.ReadWriteBucket(nodeBucket)
 		if nodes == nil {
@@ -1521,6 +1611,10 @@ func (c *ChannelGraph) pruneGraphNodes(nodes kvdb.RwBucket,
 func (c *ChannelGraph) DisconnectBlockAtHeight(height uint32) ([]*ChannelEdgeInfo,
 	error) {
 
+	if c.sqlStore != nil {
+		return c.sqlStore.disconnectBlockAtHeight(height)
+	}
+
 	// Every channel having a ShortChannelID starting at 'height'
 	// will no longer be confirmed.
 	startShortChanID := lnwire.ShortChannelID{
@@ -1651,6 +1745,10 @@ func (c *ChannelGraph) DisconnectBlockAtHeight(height uint32) ([]*ChannelEdgeInf
 // to tell if the graph is currently in sync with the current best known UTXO
 // state.
 func (c *ChannelGraph) PruneTip() (*chainhash.Hash, uint32, error) {
+	if c.sqlStore != nil {
+		return c.sqlStore.pruneTip()
+	}
+
 	var (
 		tipHash   chainhash.Hash
 		tipHeight uint32
@@ -1700,6 +1798,12 @@ func (c *ChannelGraph) PruneTip() (*chainhash.Hash, uint32, error) {
 func (c *ChannelGraph) DeleteChannelEdges(strictZombiePruning, markZombie bool,
 	chanIDs ...uint64) error {
 
+	if c.sqlStore != nil {
+		return c.sqlStore.deleteChannelEdges(
+			strictZombiePruning, markZombie, chanIDs...,
+		)
+	}
+
 	// TODO(roasbeef): possibly delete from node bucket if node has no more
 	// channels
 	// TODO(roasbeef): don't delete both edges?
@@ -1759,6 +1863,10 @@ func (c *ChannelGraph) DeleteChannelEdges(strictZombiePruning, markZombie bool,
 // passed channel point (outpoint). If the passed channel doesn't exist within
 // the database, then ErrEdgeNotFound is returned.
 func (c *ChannelGraph) ChannelID(chanPoint *wire.OutPoint) (uint64, error) {
+	if c.sqlStore != nil {
+		return c.sqlStore.channelID(chanPoint)
+	}
+
 	var chanID uint64
 	if err := kvdb.View(c.db, func(tx kvdb.RTx) error {
 		var err error
@@ -1805,6 +1913,10 @@ func getChanID(tx kvdb.RTx, chanPoint *wire.OutPoint) (uint64, error) {
 // This represents the "newest" channel from the PoV of the chain. This method
 // can be used by peers to quickly determine if they're graphs are in sync.
 func (c *ChannelGraph) HighestChanID() (uint64, error) {
+	if c.sqlStore != nil {
+		return c.sqlStore.highestChanID()
+	}
+
 	var cid uint64
 
 	err := kvdb.View(c.db, func(tx kvdb.RTx) error {
@@ -1864,6 +1976,10 @@ type ChannelEdge struct {
 func (c *ChannelGraph) ChanUpdatesInHorizon(startTime,
 	endTime time.Time) ([]ChannelEdge, error) {
 
+	if c.sqlStore != nil {
+		return c.sqlStore.chanUpdatesInHorizon(startTime, endTime)
+	}
+
 	// To ensure we don't return duplicate ChannelEdges, we'll use an
 	// additional map to keep track of the edges already seen to prevent
 	// re-adding it.
@@ -1999,6 +2115,10 @@ func (c *ChannelGraph) ChanUpdatesInHorizon(startTime,
 func (c *ChannelGraph) NodeUpdatesInHorizon(startTime,
 	endTime time.Time) ([]LightningNode, error) {
 
+	if c.sqlStore != nil {
+		return c.sqlStore.nodeUpdatesInHorizon(startTime, endTime)
+	}
+
 	var nodesInHorizon []LightningNode
 
 	err := kvdb.View(c.db, func(tx kvdb.RTx) error {
@@ -2063,6 +2183,10 @@ func (c *ChannelGraph) NodeUpdatesInHorizon(startTime,
 // passed in. This method can be used by callers to determine the set of
 // channels another peer knows of that we don't.
 func (c *ChannelGraph) FilterKnownChanIDs(chanIDs []uint64) ([]uint64, error) {
+	if c.sqlStore != nil {
+		return c.sqlStore.filterKnownChanIDs(chanIDs)
+	}
+
 	var newChanIDs []uint64
 
 	err := kvdb.View(c.db, func(tx kvdb.RTx) error {
@@ -2139,6 +2263,10 @@ type BlockChannelRange struct {
 func (c *ChannelGraph) FilterChannelRange(startHeight,
 	endHeight uint32) ([]BlockChannelRange, error) {
 
+	if c.sqlStore != nil {
+		return c.sqlStore.filterChannelRange(startHeight, endHeight)
+	}
+
 	startChanID := &lnwire.ShortChannelID{
 		BlockHeight: startHeight,
 	}
@@ -2234,6 +2362,10 @@ func (c *ChannelGraph) FilterChannelRange(startHeight,
 // of the query. This can be used to respond to peer queries that are seeking to
 // fill in gaps in their view of the channel graph.
 func (c *ChannelGraph) FetchChanInfos(chanIDs []uint64) ([]ChannelEdge, error) {
+	if c.sqlStore != nil {
+		return c.sqlStore.fetchChanInfos(chanIDs)
+	}
+
 	// TODO(roasbeef): sort cids?
 
 	var (
@@ -2472,6 +2604,10 @@ func makeZombiePubkeys(info *ChannelEdgeInfo,
 func (c *ChannelGraph) UpdateEdgePolicy(edge *ChannelEdgePolicy,
 	op ...batch.SchedulerOption) error {
 
+	if c.sqlStore != nil {
+		return c.sqlStore.updateEdgePolicy(edge)
+	}
+
 	var (
 		isUpdate1    bool
 		edgeNotFound bool
@@ -2659,6 +2795,8 @@ type LightningNode struct {
 
 	db kvdb.Backend
 
+	sqlStore *sqlGraphStore
+
 	// TODO(roasbeef): discovery will need storage to keep it's last IP
 	// address and re-announce if interface changes?
 
@@ -2784,6 +2922,10 @@ func (l *LightningNode) isPublic(tx kvdb.RTx, sourcePubKey []byte) (bool, error)
 func (c *ChannelGraph) FetchLightningNode(nodePub route.Vertex) (
 	*LightningNode, error) {
 
+	if c.sqlStore != nil {
+		return c.sqlStore.fetchLightningNode(nodePub)
+	}
+
 	var node *LightningNode
 	err := kvdb.View(c.db, func(tx kvdb.RTx) error {
 		// First grab the nodes bucket which stores the mapping from
@@ -2827,6 +2969,8 @@ func (c *ChannelGraph) FetchLightningNode(nodePub route.Vertex) (
 type graphCacheNode struct {
 	pubKeyBytes route.Vertex
 	features    *lnwire.FeatureVector
+
+	sqlStore *sqlGraphStore
 }
 
 // newGraphCacheNode returns a new cache optimized node.
@@ -2861,6 +3005,10 @@ func (n *graphCacheNode) ForEachChannel(tx kvdb.RTx,
 	cb func(kvdb.RTx, *ChannelEdgeInfo, *ChannelEdgePolicy,
 		*ChannelEdgePolicy) error) error {
 
+	if n.sqlStore != nil {
+		return n.sqlStore.forEachNodeChannel(n.pubKeyBytes, cb)
+	}
+
 	return nodeTraversal(tx, n.pubKeyBytes[:], nil, cb)
 }
 
@@ -2872,6 +3020,10 @@ var _ GraphCacheNode = (*graphCacheNode)(nil)
 // with a true boolean. Otherwise, an empty time.Time is returned with a false
 // boolean.
 func (c *ChannelGraph) HasLightningNode(nodePub [33]byte) (time.Time, bool, error) {
+	if c.sqlStore != nil {
+		return c.sqlStore.hasLightningNode(nodePub)
+	}
+
 	var (
 		updateTime time.Time
 		exists     bool
@@ -3020,6 +3172,10 @@ func (l *LightningNode) ForEachChannel(tx kvdb.RTx,
 	cb func(kvdb.RTx, *ChannelEdgeInfo, *ChannelEdgePolicy,
 		*ChannelEdgePolicy) error) error {
 
+	if l.sqlStore != nil {
+		return l.sqlStore.forEachNodeChannel(l.PubKeyBytes, cb)
+	}
+
 	nodePub := l.PubKeyBytes[:]
 	db := l.db
 
@@ -3087,6 +3243,8 @@ type ChannelEdgeInfo struct {
 	ExtraOpaqueData []byte
 
 	db kvdb.Backend
+
+	sqlStore *sqlGraphStore
 }
 
 // AddNodeKeys is a setter-like method that can be used to replace the set of
@@ -3223,6 +3381,10 @@ func (c *ChannelEdgeInfo) FetchOtherNode(tx kvdb.RTx,
 		return nil, fmt.Errorf("node not participating in this channel")
 	}
 
+	if c.sqlStore != nil {
+		return c.sqlStore.fetchLightningNode(targetNodeBytes)
+	}
+
 	var targetNode *LightningNode
 	fetchNodeFunc := func(tx kvdb.RTx) error {
 		// First grab the nodes bucket which stores the mapping from
@@ -3492,6 +3654,72 @@ func (c *ChannelEdgePolicy) ComputeFee(
 	return c.FeeBaseMSat + (amt*c.FeeProportionalMillionths)/feeRateParts
 }
 
+// InboundFee returns the inbound fee that the node advertising this policy
+// charges for payments arriving through the channel. It is carried in the
+// extra opaque data of the policy. A policy without an inbound fee record
+// results in a zero fee.
+func (c *ChannelEdgePolicy) InboundFee() (lnwire.Fee, error) {
+	var (
+		inboundFee lnwire.Fee
+		extraData  = lnwire.ExtraOpaqueData(c.ExtraOpaqueData)
+	)
+	typeMap, err := extraData.ExtractRecords(&inboundFee)
+	if err != nil {
+		return lnwire.Fee{}, err
+	}
+
+	if _, ok := typeMap[lnwire.FeeRecordType]; !ok {
+		return lnwire.Fee{}, nil
+	}
+
+	return inboundFee, nil
+}
+
+// SetInboundFee sets the inbound fee that is advertised with this policy. The
+// inbound fee record of the extra opaque data is replaced, while all other
+// records are kept. A zero fee removes the inbound fee record.
+func (c *ChannelEdgePolicy) SetInboundFee(fee lnwire.Fee) error {
+	extraData := lnwire.ExtraOpaqueData(c.ExtraOpaqueData)
+	typeMap, err := extraData.ExtractRecords()
+	if err != nil {
+		return err
+	}
+
+	tlvMap := make(map[uint64][]byte, len(typeMap))
+	for typ, value := range typeMap {
+		if typ == lnwire.FeeRecordType {
+			continue
+		}
+
+		tlvMap[uint64(typ)] = value
+	}
+
+	records := tlv.MapToRecords(tlvMap)
+	if fee != (lnwire.Fee{}) {
+		records = append(records, fee.Record())
+		tlv.SortRecords(records)
+	}
+
+	if len(records) == 0 {
+		c.ExtraOpaqueData = nil
+
+		return nil
+	}
+
+	tlvStream, err := tlv.NewStream(records...)
+	if err != nil {
+		return err
+	}
+
+	var b bytes.Buffer
+	if err := tlvStream.Encode(&b); err != nil {
+		return err
+	}
+	c.ExtraOpaqueData = b.Bytes()
+
+	return nil
+}
+
 // divideCeil divides dividend by factor and rounds the result up.
 func divideCeil(dividend, factor lnwire.MilliSatoshi) lnwire.MilliSatoshi {
 	return (dividend + factor - 1) / factor
@@ -3516,6 +3744,10 @@ func (c *ChannelEdgePolicy) ComputeFeeFromIncoming(
 func (c *ChannelGraph) FetchChannelEdgesByOutpoint(op *wire.OutPoint,
 ) (*ChannelEdgeInfo, *ChannelEdgePolicy, *ChannelEdgePolicy, error) {
 
+	if c.sqlStore != nil {
+		return c.sqlStore.fetchChannelEdgesByOutpoint(op)
+	}
+
 	var (
 		edgeInfo *ChannelEdgeInfo
 		policy1  *ChannelEdgePolicy
@@ -3603,6 +3835,10 @@ func (c *ChannelGraph) FetchChannelEdgesByOutpoint(op *wire.OutPoint,
 func (c *ChannelGraph) FetchChannelEdgesByID(chanID uint64,
 ) (*ChannelEdgeInfo, *ChannelEdgePolicy, *ChannelEdgePolicy, error) {
 
+	if c.sqlStore != nil {
+		return c.sqlStore.fetchChannelEdgesByID(chanID)
+	}
+
 	var (
 		edgeInfo  *ChannelEdgeInfo
 		policy1   *ChannelEdgePolicy
@@ -3703,6 +3939,10 @@ func (c *ChannelGraph) FetchChannelEdgesByID(chanID uint64,
 // given public key is seen as a public node in the graph from the graph's
 // source node's point of view.
 func (c *ChannelGraph) IsPublicNode(pubKey [33]byte) (bool, error) {
+	if c.sqlStore != nil {
+		return c.sqlStore.isPublicNode(pubKey)
+	}
+
 	var nodeIsPublic bool
 	err := kvdb.View(c.db, func(tx kvdb.RTx) error {
 		nodes := tx.ReadBucket(nodeBucket)
@@ -3773,6 +4013,10 @@ func (e *EdgePoint) String() string {
 // returned are the ones that need to be watched on chain to detect channel
 // closes on the resident blockchain.
 func (c *ChannelGraph) ChannelView() ([]EdgePoint, error) {
+	if c.sqlStore != nil {
+		return c.sqlStore.channelView()
+	}
+
 	var edgePoints []EdgePoint
 	if err := kvdb.View(c.db, func(tx kvdb.RTx) error {
 		// We're going to iterate over the entire channel index, so
@@ -3845,6 +4089,10 @@ func (c *ChannelGraph) NewChannelEdgePolicy() *ChannelEdgePolicy {
 func (c *ChannelGraph) MarkEdgeZombie(chanID uint64,
 	pubKey1, pubKey2 [33]byte) error {
 
+	if c.sqlStore != nil {
+		return c.sqlStore.markEdgeZombie(chanID, pubKey1, pubKey2)
+	}
+
 	c.cacheMu.Lock()
 	defer c.cacheMu.Unlock()
 
@@ -3893,6 +4141,10 @@ func markEdgeZombie(zombieIndex kvdb.RwBucket, chanID uint64, pubKey1,
 
 // MarkEdgeLive clears an edge from our zombie index, deeming it as live.
 func (c *ChannelGraph) MarkEdgeLive(chanID uint64) error {
+	if c.sqlStore != nil {
+		return c.sqlStore.markEdgeLive(chanID)
+	}
+
 	c.cacheMu.Lock()
 	defer c.cacheMu.Unlock()
 
@@ -3939,6 +4191,10 @@ func (c *ChannelGraph) MarkEdgeLive(chanID uint64) error {
 // zombie, then the two node public keys corresponding to this edge are also
 // returned.
 func (c *ChannelGraph) IsZombieEdge(chanID uint64) (bool, [33]byte, [33]byte) {
+	if c.sqlStore != nil {
+		return c.sqlStore.isZombieEdge(chanID)
+	}
+
 	var (
 		isZombie         bool
 		pubKey1, pubKey2 [33]byte
@@ -3991,6 +4247,10 @@ func isZombieEdge(zombieIndex kvdb.RBucket,
 
 // NumZombies returns the current number of zombie channels in the graph.
 func (c *ChannelGraph) NumZombies() (uint64, error) {
+	if c.sqlStore != nil {
+		return c.sqlStore.numZombies()
+	}
+
 	var numZombies uint64
 	err := kvdb.View(c.db, func(tx kvdb.RTx) error {
 		edges := tx.ReadBucket(edgeBucket)
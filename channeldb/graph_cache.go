@@ -143,6 +143,11 @@ type DirectedChannel struct {
 	// source, so we're always interested in the edge that arrives to us
 	// from the other node.
 	InPolicy *CachedEdgePolicy
+
+	// InboundFee is the fee that this node charges for payments that
+	// arrive through this channel. It is advertised in the extra opaque
+	// data of the node's outgoing policy.
+	InboundFee lnwire.Fee
 }
 
 // DeepCopy creates a deep copy of the channel, including the incoming policy.
@@ -304,6 +309,15 @@ func (c *GraphCache) updateOrAddEdge(node route.Vertex, edge *DirectedChannel) {
 func (c *GraphCache) UpdatePolicy(policy *ChannelEdgePolicy, fromNode,
 	toNode route.Vertex, edge1 bool) {
 
+	// The inbound fee is only of interest for pathfinding, so a policy
+	// with malformed extra data is still cached, just without an inbound
+	// fee.
+	inboundFee, err := policy.InboundFee()
+	if err != nil {
+		log.Debugf("Unable to extract inbound fee of channel %v: %v",
+			policy.ChannelID, err)
+	}
+
 	c.mtx.Lock()
 	defer c.mtx.Unlock()
 
@@ -324,11 +338,13 @@ func (c *GraphCache) UpdatePolicy(policy *ChannelEdgePolicy, fromNode,
 		// policy for node 1.
 		case channel.IsNode1 && edge1:
 			channel.OutPolicySet = true
+			channel.InboundFee = inboundFee
 
 		// This is node 2, and it is edge 2, so this is the outgoing
 		// policy for node 2.
 		case !channel.IsNode1 && !edge1:
 			channel.OutPolicySet = true
+			channel.InboundFee = inboundFee
 
 		// The other two cases left mean it's the inbound policy for the
 		// node.
@@ -70,9 +70,17 @@ func TestGraphCacheAddNode(t *testing.T) {
 			channelFlagA, channelFlagB = 1, 0
 		}
 
+		inboundFee := lnwire.Fee{
+			BaseFee: 10,
+			FeeRate: -20,
+		}
+		var extraData lnwire.ExtraOpaqueData
+		require.NoError(t, extraData.PackRecords(&inboundFee))
+
 		outPolicy1 := &ChannelEdgePolicy{
-			ChannelID:    1000,
-			ChannelFlags: lnwire.ChanUpdateChanFlags(channelFlagA),
+			ChannelID:       1000,
+			ChannelFlags:    lnwire.ChanUpdateChanFlags(channelFlagA),
+			ExtraOpaqueData: extraData,
 			Node: &LightningNode{
 				PubKeyBytes: nodeB,
 				Features:    lnwire.EmptyFeatureVector(),
@@ -118,8 +126,13 @@ func TestGraphCacheAddNode(t *testing.T) {
 		require.Equal(t, outPolicy1 != nil, fromChannels[0].OutPolicySet)
 		assertCachedPolicyEqual(t, inPolicy1, fromChannels[0].InPolicy)
 
+		// The inbound fee advertised by node A in its outgoing policy
+		// applies to payments that arrive at node A.
+		require.Equal(t, inboundFee, fromChannels[0].InboundFee)
+
 		require.Equal(t, inPolicy1 != nil, toChannels[0].OutPolicySet)
 		assertCachedPolicyEqual(t, outPolicy1, toChannels[0].InPolicy)
+		require.Equal(t, lnwire.Fee{}, toChannels[0].InboundFee)
 
 		// Now that we've inserted two nodes into the graph, check that
 		// we'll recover the same set of channels during ForEachNode.
@@ -0,0 +1,2253 @@
+package channeldb
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"image/color"
+	"net"
+	"sort"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightningnetwork/lnd/aliasmgr"
+	"github.com/lightningnetwork/lnd/kvdb"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/routing/route"
+	"github.com/lightningnetwork/lnd/sqldb"
+	"github.com/lightningnetwork/lnd/sqldb/sqlc"
+)
+
+const (
+	// sqlGraphPageSize is the number of nodes or channels that are read
+	// in a single transaction when iterating over the whole graph, so
+	// that no transaction is held open while the callers process them.
+	sqlGraphPageSize = 1000
+
+	// sqlNodeChannelCacheSize is the maximum number of nodes whose
+	// channels are kept in memory for path finding.
+	sqlNodeChannelCacheSize = 20000
+)
+
+// SQLGraphQueries is the set of queries the SQL channel graph store needs.
+type SQLGraphQueries interface {
+	UpsertGraphNode(ctx context.Context,
+		arg sqlc.UpsertGraphNodeParams) error
+
+	InsertGraphNodeShell(ctx context.Context, pubKey []byte) error
+
+	GetGraphNode(ctx context.Context, pubKey []byte) (sqlc.GraphNode,
+		error)
+
+	DeleteGraphNode(ctx context.Context, pubKey []byte) (int64, error)
+
+	ListGraphNodes(ctx context.Context,
+		arg sqlc.ListGraphNodesParams) ([]sqlc.GraphNode, error)
+
+	ListGraphNodesInHorizon(ctx context.Context,
+		arg sqlc.ListGraphNodesInHorizonParams) ([]sqlc.GraphNode,
+		error)
+
+	DeleteUnconnectedGraphNodes(ctx context.Context) ([][]byte, error)
+
+	SetGraphSourceNode(ctx context.Context, pubKey []byte) error
+
+	GetGraphSourceNode(ctx context.Context) ([]byte, error)
+
+	InsertGraphChannel(ctx context.Context,
+		arg sqlc.InsertGraphChannelParams) (int32, error)
+
+	UpdateGraphChannel(ctx context.Context,
+		arg sqlc.UpdateGraphChannelParams) (int64, error)
+
+	GetGraphChannelBySCID(ctx context.Context,
+		scid []byte) (sqlc.GraphChannel, error)
+
+	GetGraphChannelByOutpoint(ctx context.Context,
+		outpoint []byte) (sqlc.GraphChannel, error)
+
+	DeleteGraphChannel(ctx context.Context, id int32) error
+
+	GetHighestGraphChannelSCID(ctx context.Context) ([]byte, error)
+
+	ListGraphChannels(ctx context.Context,
+		arg sqlc.ListGraphChannelsParams) ([]sqlc.GraphChannel, error)
+
+	ListGraphChannelsInRange(ctx context.Context,
+		arg sqlc.ListGraphChannelsInRangeParams) ([]sqlc.GraphChannel,
+		error)
+
+	ListGraphNodeChannels(ctx context.Context,
+		nodeKey []byte) ([]sqlc.GraphChannel, error)
+
+	ListGraphChannelsUpdatedInHorizon(ctx context.Context,
+		arg sqlc.ListGraphChannelsUpdatedInHorizonParams) (
+		[]sqlc.GraphChannel, error)
+
+	UpsertGraphChannelPolicy(ctx context.Context,
+		arg sqlc.UpsertGraphChannelPolicyParams) error
+
+	ListGraphChannelPolicies(ctx context.Context,
+		channelID int32) ([]sqlc.GraphChannelPolicy, error)
+
+	ListDisabledGraphChannels(ctx context.Context) ([][]byte, error)
+
+	UpsertGraphZombieChannel(ctx context.Context,
+		arg sqlc.UpsertGraphZombieChannelParams) error
+
+	GetGraphZombieChannel(ctx context.Context,
+		scid []byte) (sqlc.GraphZombieChannel, error)
+
+	DeleteGraphZombieChannel(ctx context.Context, scid []byte) error
+
+	CountGraphZombieChannels(ctx context.Context) (int64, error)
+
+	UpsertGraphPruneLogEntry(ctx context.Context,
+		arg sqlc.UpsertGraphPruneLogEntryParams) error
+
+	GetGraphPruneTip(ctx context.Context) (sqlc.GraphPruneLog, error)
+
+	DeleteGraphPruneLogEntries(ctx context.Context,
+		blockHeight int64) error
+
+	InsertKVMigration(ctx context.Context,
+		arg sqlc.InsertKVMigrationParams) error
+
+	GetKVMigration(ctx context.Context, name string) (sqlc.KvMigration,
+		error)
+}
+
+// SQLGraphQueriesTxOptions defines the set of db txn options the
+// SQLGraphQueries understands.
+type SQLGraphQueriesTxOptions struct {
+	// readOnly governs if a read only transaction is needed or not.
+	readOnly bool
+}
+
+// ReadOnly returns true if the transaction should be read only.
+//
+// NOTE: This implements the TxOptions.
+func (a *SQLGraphQueriesTxOptions) ReadOnly() bool {
+	return a.readOnly
+}
+
+// NewSQLGraphQueriesReadTx creates a new read transaction option set.
+func NewSQLGraphQueriesReadTx() SQLGraphQueriesTxOptions {
+	return SQLGraphQueriesTxOptions{
+		readOnly: true,
+	}
+}
+
+// BatchedSQLGraphQueries is a version of the SQLGraphQueries that's capable
+// of batched database operations.
+type BatchedSQLGraphQueries interface {
+	SQLGraphQueries
+
+	sqldb.BatchedTx[SQLGraphQueries]
+}
+
+// sqlGraphStore serves the queries of the ChannelGraph from the native SQL
+// tables. Instead of an in-memory graph cache that is populated on startup,
+// the path finding information of a node is loaded the first time it's
+// needed and kept in a bounded cache.
+type sqlGraphStore struct {
+	db BatchedSQLGraphQueries
+
+	nodeCache *nodeChannelCache
+}
+
+// newSQLGraphStore creates a new sqlGraphStore given an open
+// BatchedSQLGraphQueries storage backend.
+func newSQLGraphStore(db BatchedSQLGraphQueries) *sqlGraphStore {
+	return &sqlGraphStore{
+		db:        db,
+		nodeCache: newNodeChannelCache(sqlNodeChannelCacheSize),
+	}
+}
+
+// scidBytes encodes a channel ID the way it is stored in the SQL tables. The
+// big endian encoding makes sure the channels are sorted by block height.
+func scidBytes(chanID uint64) []byte {
+	var scid [8]byte
+	byteOrder.PutUint64(scid[:], chanID)
+
+	return scid[:]
+}
+
+// unixOrZero returns the unix timestamp of the passed time, or zero if it's
+// not set.
+func unixOrZero(t time.Time) int64 {
+	if t.Unix() > 0 {
+		return t.Unix()
+	}
+
+	return 0
+}
+
+// sqlNodeParams converts a node to the parameters of the upsert query.
+func sqlNodeParams(node *LightningNode) (sqlc.UpsertGraphNodeParams, error) {
+	pub, err := node.PubKey()
+	if err != nil {
+		return sqlc.UpsertGraphNodeParams{}, err
+	}
+
+	params := sqlc.UpsertGraphNodeParams{
+		PubKey:           pub.SerializeCompressed(),
+		HaveAnnouncement: node.HaveNodeAnnouncement,
+		LastUpdate:       unixOrZero(node.LastUpdate),
+	}
+
+	// The rest of the data is only known if we got a node announcement
+	// for this node.
+	if !node.HaveNodeAnnouncement {
+		return params, nil
+	}
+
+	sigLen := len(node.AuthSigBytes)
+	if sigLen > 80 {
+		return params, fmt.Errorf("max sig len allowed is 80, had %v",
+			sigLen)
+	}
+
+	if len(node.ExtraOpaqueData) > MaxAllowedExtraOpaqueBytes {
+		return params, ErrTooManyExtraOpaqueBytes(
+			len(node.ExtraOpaqueData),
+		)
+	}
+
+	var features bytes.Buffer
+	if err := node.Features.Encode(&features); err != nil {
+		return params, err
+	}
+
+	var (
+		scratch   [2]byte
+		addresses bytes.Buffer
+	)
+	byteOrder.PutUint16(scratch[:], uint16(len(node.Addresses)))
+	if _, err := addresses.Write(scratch[:]); err != nil {
+		return params, err
+	}
+	for _, address := range node.Addresses {
+		if err := serializeAddr(&addresses, address); err != nil {
+			return params, err
+		}
+	}
+
+	params.Color = []byte{node.Color.R, node.Color.G, node.Color.B}
+	params.Alias = sql.NullString{
+		String: node.Alias,
+		Valid:  true,
+	}
+	params.Features = features.Bytes()
+	params.Addresses = addresses.Bytes()
+	params.AuthSig = node.AuthSigBytes
+	params.ExtraOpaqueData = node.ExtraOpaqueData
+
+	return params, nil
+}
+
+// buildNode converts a node row to a LightningNode.
+func (s *sqlGraphStore) buildNode(row sqlc.GraphNode) (*LightningNode,
+	error) {
+
+	node := &LightningNode{
+		HaveNodeAnnouncement: row.HaveAnnouncement,
+		LastUpdate:           time.Unix(row.LastUpdate, 0),
+		Features:             lnwire.EmptyFeatureVector(),
+		sqlStore:             s,
+	}
+	copy(node.PubKeyBytes[:], row.PubKey)
+
+	if !row.HaveAnnouncement {
+		return node, nil
+	}
+
+	if len(row.Color) == 3 {
+		node.Color = color.RGBA{
+			R: row.Color[0],
+			G: row.Color[1],
+			B: row.Color[2],
+		}
+	}
+	node.Alias = row.Alias.String
+
+	err := node.Features.Decode(bytes.NewReader(row.Features))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(row.Addresses) >= 2 {
+		r := bytes.NewReader(row.Addresses[2:])
+		numAddresses := int(byteOrder.Uint16(row.Addresses[:2]))
+
+		var addresses []net.Addr
+		for i := 0; i < numAddresses; i++ {
+			address, err := deserializeAddr(r)
+			if err != nil {
+				return nil, err
+			}
+			addresses = append(addresses, address)
+		}
+		node.Addresses = addresses
+	}
+
+	node.AuthSigBytes = row.AuthSig
+	node.ExtraOpaqueData = row.ExtraOpaqueData
+
+	return node, nil
+}
+
+// sqlChannelParams converts a channel to the parameters of the insert query.
+func sqlChannelParams(edge *ChannelEdgeInfo) (sqlc.InsertGraphChannelParams,
+	error) {
+
+	if len(edge.ExtraOpaqueData) > MaxAllowedExtraOpaqueBytes {
+		return sqlc.InsertGraphChannelParams{},
+			ErrTooManyExtraOpaqueBytes(len(edge.ExtraOpaqueData))
+	}
+
+	var outpoint bytes.Buffer
+	if err := writeOutpoint(&outpoint, &edge.ChannelPoint); err != nil {
+		return sqlc.InsertGraphChannelParams{}, err
+	}
+
+	params := sqlc.InsertGraphChannelParams{
+		Scid:            scidBytes(edge.ChannelID),
+		ChainHash:       edge.ChainHash[:],
+		NodeKey1:        edge.NodeKey1Bytes[:],
+		NodeKey2:        edge.NodeKey2Bytes[:],
+		BitcoinKey1:     edge.BitcoinKey1Bytes[:],
+		BitcoinKey2:     edge.BitcoinKey2Bytes[:],
+		Features:        edge.Features,
+		Outpoint:        outpoint.Bytes(),
+		Capacity:        int64(edge.Capacity),
+		ExtraOpaqueData: edge.ExtraOpaqueData,
+	}
+
+	if proof := edge.AuthProof; proof != nil {
+		params.NodeSig1 = proof.NodeSig1Bytes
+		params.NodeSig2 = proof.NodeSig2Bytes
+		params.BitcoinSig1 = proof.BitcoinSig1Bytes
+		params.BitcoinSig2 = proof.BitcoinSig2Bytes
+	}
+
+	return params, nil
+}
+
+// buildChannel converts a channel row to a ChannelEdgeInfo.
+func (s *sqlGraphStore) buildChannel(row sqlc.GraphChannel) (
+	*ChannelEdgeInfo, error) {
+
+	edge := &ChannelEdgeInfo{
+		ChannelID:       byteOrder.Uint64(row.Scid),
+		Features:        row.Features,
+		Capacity:        btcutil.Amount(row.Capacity),
+		ExtraOpaqueData: row.ExtraOpaqueData,
+		sqlStore:        s,
+	}
+	copy(edge.ChainHash[:], row.ChainHash)
+	copy(edge.NodeKey1Bytes[:], row.NodeKey1)
+	copy(edge.NodeKey2Bytes[:], row.NodeKey2)
+	copy(edge.BitcoinKey1Bytes[:], row.BitcoinKey1)
+	copy(edge.BitcoinKey2Bytes[:], row.BitcoinKey2)
+
+	err := readOutpoint(bytes.NewReader(row.Outpoint), &edge.ChannelPoint)
+	if err != nil {
+		return nil, err
+	}
+
+	proof := &ChannelAuthProof{
+		NodeSig1Bytes:    row.NodeSig1,
+		NodeSig2Bytes:    row.NodeSig2,
+		BitcoinSig1Bytes: row.BitcoinSig1,
+		BitcoinSig2Bytes: row.BitcoinSig2,
+	}
+	if !proof.IsEmpty() {
+		edge.AuthProof = proof
+	}
+
+	return edge, nil
+}
+
+// sqlPolicyParams converts a policy of the channel with the given row ID to
+// the parameters of the upsert query.
+func sqlPolicyParams(channelID int32, edge *ChannelEdgePolicy) (
+	sqlc.UpsertGraphChannelPolicyParams, error) {
+
+	if len(edge.ExtraOpaqueData) > MaxAllowedExtraOpaqueBytes {
+		return sqlc.UpsertGraphChannelPolicyParams{},
+			ErrTooManyExtraOpaqueBytes(len(edge.ExtraOpaqueData))
+	}
+
+	var direction int16
+	if edge.ChannelFlags&lnwire.ChanUpdateDirection != 0 {
+		direction = 1
+	}
+
+	params := sqlc.UpsertGraphChannelPolicyParams{
+		ChannelID:       channelID,
+		Direction:       direction,
+		LastUpdate:      edge.LastUpdate.Unix(),
+		MessageFlags:    int16(edge.MessageFlags),
+		ChannelFlags:    int16(edge.ChannelFlags),
+		Disabled:        edge.IsDisabled(),
+		TimelockDelta:   int32(edge.TimeLockDelta),
+		MinHtlcMsat:     int64(edge.MinHTLC),
+		FeeBaseMsat:     int64(edge.FeeBaseMSat),
+		FeePpm:          int64(edge.FeeProportionalMillionths),
+		Signature:       edge.SigBytes,
+		ExtraOpaqueData: edge.ExtraOpaqueData,
+	}
+
+	if edge.MessageFlags.HasMaxHtlc() {
+		params.MaxHtlcMsat = sql.NullInt64{
+			Int64: int64(edge.MaxHTLC),
+			Valid: true,
+		}
+	}
+
+	return params, nil
+}
+
+// buildPolicy converts a policy row to a ChannelEdgePolicy. Nil is returned
+// if the policy signals a max_htlc value that isn't known, as such a policy
+// can't be used.
+func buildPolicy(chanID uint64, row sqlc.GraphChannelPolicy,
+	toNode *LightningNode) *ChannelEdgePolicy {
+
+	edge := &ChannelEdgePolicy{
+		SigBytes:                  row.Signature,
+		ChannelID:                 chanID,
+		LastUpdate:                time.Unix(row.LastUpdate, 0),
+		MessageFlags:              lnwire.ChanUpdateMsgFlags(row.MessageFlags),
+		ChannelFlags:              lnwire.ChanUpdateChanFlags(row.ChannelFlags),
+		TimeLockDelta:             uint16(row.TimelockDelta),
+		MinHTLC:                   lnwire.MilliSatoshi(row.MinHtlcMsat),
+		FeeBaseMSat:               lnwire.MilliSatoshi(row.FeeBaseMsat),
+		FeeProportionalMillionths: lnwire.MilliSatoshi(row.FeePpm),
+		Node:                      toNode,
+		ExtraOpaqueData:           row.ExtraOpaqueData,
+	}
+
+	if edge.MessageFlags.HasMaxHtlc() {
+		if !row.MaxHtlcMsat.Valid {
+			return nil
+		}
+		edge.MaxHTLC = lnwire.MilliSatoshi(row.MaxHtlcMsat.Int64)
+	}
+
+	return edge
+}
+
+// fetchPolicies returns the two policies of the given channel. If fullNodes
+// is set, the Node of each policy is fully populated, otherwise it only
+// contains the public key.
+func (s *sqlGraphStore) fetchPolicies(ctx context.Context, db SQLGraphQueries,
+	row sqlc.GraphChannel, fullNodes bool) (*ChannelEdgePolicy,
+	*ChannelEdgePolicy, error) {
+
+	rows, err := db.ListGraphChannelPolicies(ctx, row.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	chanID := byteOrder.Uint64(row.Scid)
+
+	var policies [2]*ChannelEdgePolicy
+	for _, policyRow := range rows {
+		// The policy of the first node points to the second node and
+		// vice versa.
+		toKey := row.NodeKey2
+		if policyRow.Direction == 1 {
+			toKey = row.NodeKey1
+		}
+
+		toNode := &LightningNode{}
+		copy(toNode.PubKeyBytes[:], toKey)
+
+		if fullNodes {
+			nodeRow, err := db.GetGraphNode(ctx, toKey)
+			if err != nil {
+				return nil, nil, fmt.Errorf("unable to fetch "+
+					"node %x: %w", toKey, err)
+			}
+
+			toNode, err = s.buildNode(nodeRow)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		policies[policyRow.Direction&1] = buildPolicy(
+			chanID, policyRow, toNode,
+		)
+	}
+
+	return policies[0], policies[1], nil
+}
+
+// fetchChannelEdge returns the channel and its policies for the given row.
+func (s *sqlGraphStore) fetchChannelEdge(ctx context.Context,
+	db SQLGraphQueries, row sqlc.GraphChannel) (ChannelEdge, error) {
+
+	info, err := s.buildChannel(row)
+	if err != nil {
+		return ChannelEdge{}, err
+	}
+
+	policy1, policy2, err := s.fetchPolicies(ctx, db, row, true)
+	if err != nil {
+		return ChannelEdge{}, err
+	}
+
+	return ChannelEdge{
+		Info:    info,
+		Policy1: policy1,
+		Policy2: policy2,
+	}, nil
+}
+
+// forEachChannel iterates through all channels of the graph. The Node of the
+// policies only contains the public key.
+func (s *sqlGraphStore) forEachChannel(cb func(*ChannelEdgeInfo,
+	*ChannelEdgePolicy, *ChannelEdgePolicy) error) error {
+
+	var (
+		ctx      = context.TODO()
+		readOpts = NewSQLGraphQueriesReadTx()
+		lastID   int32
+	)
+	for {
+		var channels []ChannelEdge
+		err := s.db.ExecTx(ctx, &readOpts, func(db SQLGraphQueries) error {
+			channels = nil
+
+			rows, err := db.ListGraphChannels(
+				ctx, sqlc.ListGraphChannelsParams{
+					ID:    lastID,
+					Limit: sqlGraphPageSize,
+				},
+			)
+			if err != nil {
+				return err
+			}
+
+			for _, row := range rows {
+				info, err := s.buildChannel(row)
+				if err != nil {
+					return err
+				}
+
+				policy1, policy2, err := s.fetchPolicies(
+					ctx, db, row, false,
+				)
+				if err != nil {
+					return err
+				}
+
+				channels = append(channels, ChannelEdge{
+					Info:    info,
+					Policy1: policy1,
+					Policy2: policy2,
+				})
+				lastID = row.ID
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, channel := range channels {
+			err := cb(channel.Info, channel.Policy1, channel.Policy2)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(channels) < sqlGraphPageSize {
+			return nil
+		}
+	}
+}
+
+// forEachNodeChannel iterates through all channels of the given node. The
+// first policy is the outgoing policy of the node, the second one the
+// incoming policy.
+func (s *sqlGraphStore) forEachNodeChannel(node route.Vertex,
+	cb func(kvdb.RTx, *ChannelEdgeInfo, *ChannelEdgePolicy,
+		*ChannelEdgePolicy) error) error {
+
+	var (
+		ctx      = context.TODO()
+		readOpts = NewSQLGraphQueriesReadTx()
+		channels []ChannelEdge
+	)
+	err := s.db.ExecTx(ctx, &readOpts, func(db SQLGraphQueries) error {
+		channels = nil
+
+		rows, err := db.ListGraphNodeChannels(ctx, node[:])
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			channel, err := s.fetchChannelEdge(ctx, db, row)
+			if err != nil {
+				return err
+			}
+
+			if node != channel.Info.NodeKey1Bytes {
+				channel.Policy1, channel.Policy2 =
+					channel.Policy2, channel.Policy1
+			}
+			channels = append(channels, channel)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, channel := range channels {
+		err := cb(nil, channel.Info, channel.Policy1, channel.Policy2)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fetchNodeChannels returns the path finding information of the given node,
+// loading it from the database if it isn't cached yet.
+func (s *sqlGraphStore) fetchNodeChannels(node route.Vertex) (*nodeChannels,
+	error) {
+
+	entry, version, ok := s.nodeCache.get(node)
+	if ok {
+		return entry, nil
+	}
+
+	entry, err := s.loadNodeChannels(node)
+	if err != nil {
+		return nil, err
+	}
+
+	s.nodeCache.insert(node, entry, version)
+
+	return entry, nil
+}
+
+// loadNodeChannels reads the path finding information of the given node from
+// the database.
+func (s *sqlGraphStore) loadNodeChannels(node route.Vertex) (*nodeChannels,
+	error) {
+
+	var (
+		ctx      = context.TODO()
+		readOpts = NewSQLGraphQueriesReadTx()
+		entry    *nodeChannels
+	)
+	err := s.db.ExecTx(ctx, &readOpts, func(db SQLGraphQueries) error {
+		// If we didn't yet get a node announcement, we use an empty
+		// feature vector, just like the graph cache does.
+		entry = &nodeChannels{
+			features: lnwire.EmptyFeatureVector(),
+		}
+
+		nodeRow, err := db.GetGraphNode(ctx, node[:])
+		switch {
+		case err == nil:
+			lightningNode, err := s.buildNode(nodeRow)
+			if err != nil {
+				return err
+			}
+			entry.features = lightningNode.Features
+
+		case !errors.Is(err, sql.ErrNoRows):
+			return err
+		}
+
+		rows, err := db.ListGraphNodeChannels(ctx, node[:])
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			// Zombie channels aren't used for path finding until
+			// they are marked as live again.
+			_, err := db.GetGraphZombieChannel(ctx, row.Scid)
+			switch {
+			case err == nil:
+				continue
+
+			case !errors.Is(err, sql.ErrNoRows):
+				return err
+			}
+
+			channel, err := s.buildDirectedChannel(
+				ctx, db, node, row,
+			)
+			if err != nil {
+				return err
+			}
+			if channel == nil {
+				continue
+			}
+
+			entry.channels = append(entry.channels, channel)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// buildDirectedChannel converts a channel row to a DirectedChannel as seen
+// from the given node. Nil is returned if both sides disabled the channel.
+func (s *sqlGraphStore) buildDirectedChannel(ctx context.Context,
+	db SQLGraphQueries, node route.Vertex,
+	row sqlc.GraphChannel) (*DirectedChannel, error) {
+
+	info, err := s.buildChannel(row)
+	if err != nil {
+		return nil, err
+	}
+
+	policy1, policy2, err := s.fetchPolicies(ctx, db, row, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if policy1 != nil && policy1.IsDisabled() &&
+		policy2 != nil && policy2.IsDisabled() {
+
+		return nil, nil
+	}
+
+	channel := &DirectedChannel{
+		ChannelID: info.ChannelID,
+		IsNode1:   node == info.NodeKey1Bytes,
+		OtherNode: info.NodeKey2Bytes,
+		Capacity:  info.Capacity,
+	}
+
+	outPolicy, inPolicy := policy1, policy2
+	if !channel.IsNode1 {
+		channel.OtherNode = info.NodeKey1Bytes
+		outPolicy, inPolicy = policy2, policy1
+	}
+
+	if outPolicy != nil {
+		channel.OutPolicySet = true
+
+		// A malformed fee record is treated as a zero inbound fee.
+		channel.InboundFee, _ = outPolicy.InboundFee()
+	}
+	if inPolicy != nil {
+		channel.InPolicy = NewCachedPolicy(inPolicy)
+	}
+
+	return channel, nil
+}
+
+// forEachNodeDirectedChannel invokes the given callback for each channel of
+// the given node. The channels are copies, so the callback may modify them.
+func (s *sqlGraphStore) forEachNodeDirectedChannel(node route.Vertex,
+	cb func(channel *DirectedChannel) error) error {
+
+	entry, err := s.fetchNodeChannels(node)
+	if err != nil {
+		return err
+	}
+
+	toNodeCallback := func() route.Vertex {
+		return node
+	}
+
+	for _, channel := range entry.channels {
+		channelCopy := channel.DeepCopy()
+		if channelCopy.InPolicy != nil {
+			channelCopy.InPolicy.ToNodePubKey = toNodeCallback
+			channelCopy.InPolicy.ToNodeFeatures = entry.features
+		}
+
+		if err := cb(channelCopy); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fetchNodeFeatures returns the features of the given node. If no features
+// are known for the node, an empty feature vector is returned.
+func (s *sqlGraphStore) fetchNodeFeatures(
+	node route.Vertex) (*lnwire.FeatureVector, error) {
+
+	if entry, _, ok := s.nodeCache.get(node); ok {
+		return entry.features, nil
+	}
+
+	targetNode, err := s.fetchLightningNode(node)
+	switch err {
+	case nil:
+		return targetNode.Features, nil
+
+	case ErrGraphNodeNotFound:
+		return lnwire.EmptyFeatureVector(), nil
+
+	default:
+		return nil, err
+	}
+}
+
+// forEachNodeCached iterates over all nodes of the graph together with their
+// channels.
+func (s *sqlGraphStore) forEachNodeCached(cb func(node route.Vertex,
+	chans map[uint64]*DirectedChannel) error) error {
+
+	return s.forEachNode(func(_ kvdb.RTx, node *LightningNode) error {
+		// We don't add the nodes to the cache here, as iterating over
+		// the whole graph would evict the nodes path finding needs.
+		entry, _, ok := s.nodeCache.get(node.PubKeyBytes)
+		if !ok {
+			var err error
+			entry, err = s.loadNodeChannels(node.PubKeyBytes)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Just like the graph cache, we only report nodes that have
+		// channels.
+		if len(entry.channels) == 0 {
+			return nil
+		}
+
+		channels := make(map[uint64]*DirectedChannel)
+		for _, channel := range entry.channels {
+			channels[channel.ChannelID] = channel
+		}
+
+		return cb(node.PubKeyBytes, channels)
+	})
+}
+
+// disabledChannelIDs returns the channel IDs of the channels that are
+// disabled in both directions.
+func (s *sqlGraphStore) disabledChannelIDs() ([]uint64, error) {
+	var (
+		ctx      = context.TODO()
+		readOpts = NewSQLGraphQueriesReadTx()
+		chanIDs  []uint64
+	)
+	err := s.db.ExecTx(ctx, &readOpts, func(db SQLGraphQueries) error {
+		chanIDs = nil
+
+		scids, err := db.ListDisabledGraphChannels(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, scid := range scids {
+			chanIDs = append(chanIDs, byteOrder.Uint64(scid))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return chanIDs, nil
+}
+
+// forEachNode iterates through all the nodes of the graph. The transaction
+// passed to the callback is always nil.
+func (s *sqlGraphStore) forEachNode(
+	cb func(kvdb.RTx, *LightningNode) error) error {
+
+	var (
+		ctx      = context.TODO()
+		readOpts = NewSQLGraphQueriesReadTx()
+		lastID   int32
+	)
+	for {
+		var nodes []*LightningNode
+		err := s.db.ExecTx(ctx, &readOpts, func(db SQLGraphQueries) error {
+			nodes = nil
+
+			rows, err := db.ListGraphNodes(
+				ctx, sqlc.ListGraphNodesParams{
+					ID:    lastID,
+					Limit: sqlGraphPageSize,
+				},
+			)
+			if err != nil {
+				return err
+			}
+
+			for _, row := range rows {
+				node, err := s.buildNode(row)
+				if err != nil {
+					return err
+				}
+
+				nodes = append(nodes, node)
+				lastID = row.ID
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, node := range nodes {
+			if err := cb(nil, node); err != nil {
+				return err
+			}
+		}
+
+		if len(nodes) < sqlGraphPageSize {
+			return nil
+		}
+	}
+}
+
+// forEachNodeCacheable iterates through all the nodes of the graph in the
+// form the graph cache expects them.
+func (s *sqlGraphStore) forEachNodeCacheable(cb func(kvdb.RTx,
+	GraphCacheNode) error) error {
+
+	return s.forEachNode(func(tx kvdb.RTx, node *LightningNode) error {
+		cacheableNode := newGraphCacheNode(
+			node.PubKeyBytes, node.Features,
+		)
+		cacheableNode.sqlStore = s
+
+		return cb(tx, cacheableNode)
+	})
+}
+
+// sourceNode returns the source node of the graph.
+func (s *sqlGraphStore) sourceNode() (*LightningNode, error) {
+	var (
+		ctx      = context.TODO()
+		readOpts = NewSQLGraphQueriesReadTx()
+		source   *LightningNode
+	)
+	err := s.db.ExecTx(ctx, &readOpts, func(db SQLGraphQueries) error {
+		pubKey, err := db.GetGraphSourceNode(ctx)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrSourceNodeNotSet
+
+		case err != nil:
+			return err
+		}
+
+		source, err = s.getNode(ctx, db, pubKey)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return source, nil
+}
+
+// getNode returns the node with the given public key, or
+// ErrGraphNodeNotFound if it isn't known.
+func (s *sqlGraphStore) getNode(ctx context.Context, db SQLGraphQueries,
+	pubKey []byte) (*LightningNode, error) {
+
+	row, err := db.GetGraphNode(ctx, pubKey)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, ErrGraphNodeNotFound
+
+	case err != nil:
+		return nil, err
+	}
+
+	return s.buildNode(row)
+}
+
+// setSourceNode adds the node to the graph and marks it as the source node.
+func (s *sqlGraphStore) setSourceNode(node *LightningNode) error {
+	params, err := sqlNodeParams(node)
+	if err != nil {
+		return err
+	}
+
+	var (
+		ctx         = context.TODO()
+		writeTxOpts SQLGraphQueriesTxOptions
+	)
+	err = s.db.ExecTx(ctx, &writeTxOpts, func(db SQLGraphQueries) error {
+		if err := db.UpsertGraphNode(ctx, params); err != nil {
+			return err
+		}
+
+		return db.SetGraphSourceNode(ctx, params.PubKey)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.nodeCache.remove(node.PubKeyBytes)
+
+	return nil
+}
+
+// addLightningNode adds the node to the graph, or updates it if it's already
+// known.
+func (s *sqlGraphStore) addLightningNode(node *LightningNode) error {
+	params, err := sqlNodeParams(node)
+	if err != nil {
+		return err
+	}
+
+	var (
+		ctx         = context.TODO()
+		writeTxOpts SQLGraphQueriesTxOptions
+	)
+	err = s.db.ExecTx(ctx, &writeTxOpts, func(db SQLGraphQueries) error {
+		return db.UpsertGraphNode(ctx, params)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.nodeCache.remove(node.PubKeyBytes)
+
+	return nil
+}
+
+// lookupAlias returns the alias the node with the given public key
+// announced.
+func (s *sqlGraphStore) lookupAlias(pub []byte) (string, error) {
+	var (
+		ctx      = context.TODO()
+		readOpts = NewSQLGraphQueriesReadTx()
+		alias    string
+	)
+	err := s.db.ExecTx(ctx, &readOpts, func(db SQLGraphQueries) error {
+		row, err := db.GetGraphNode(ctx, pub)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrNodeAliasNotFound
+
+		case err != nil:
+			return err
+
+		case !row.Alias.Valid:
+			return ErrNodeAliasNotFound
+		}
+
+		alias = row.Alias.String
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return alias, nil
+}
+
+// deleteLightningNode removes the node with the given public key from the
+// graph.
+func (s *sqlGraphStore) deleteLightningNode(nodePub route.Vertex) error {
+	var (
+		ctx         = context.TODO()
+		writeTxOpts SQLGraphQueriesTxOptions
+	)
+	err := s.db.ExecTx(ctx, &writeTxOpts, func(db SQLGraphQueries) error {
+		numRows, err := db.DeleteGraphNode(ctx, nodePub[:])
+		if err != nil {
+			return err
+		}
+		if numRows == 0 {
+			return ErrGraphNodeNotFound
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.nodeCache.remove(nodePub)
+
+	return nil
+}
+
+// addChannelEdge adds the channel to the graph. Shell nodes are added for
+// the nodes of the channel that aren't known yet.
+func (s *sqlGraphStore) addChannelEdge(edge *ChannelEdgeInfo) error {
+	params, err := sqlChannelParams(edge)
+	if err != nil {
+		return err
+	}
+
+	var (
+		ctx         = context.TODO()
+		writeTxOpts SQLGraphQueriesTxOptions
+	)
+	err = s.db.ExecTx(ctx, &writeTxOpts, func(db SQLGraphQueries) error {
+		_, err := db.GetGraphChannelBySCID(ctx, params.Scid)
+		switch {
+		case err == nil:
+			return ErrEdgeAlreadyExist
+
+		case !errors.Is(err, sql.ErrNoRows):
+			return err
+		}
+
+		err = db.InsertGraphNodeShell(ctx, edge.NodeKey1Bytes[:])
+		if err != nil {
+			return fmt.Errorf("unable to create shell node "+
+				"for: %x", edge.NodeKey1Bytes)
+		}
+		err = db.InsertGraphNodeShell(ctx, edge.NodeKey2Bytes[:])
+		if err != nil {
+			return fmt.Errorf("unable to create shell node "+
+				"for: %x", edge.NodeKey2Bytes)
+		}
+
+		_, err = db.InsertGraphChannel(ctx, params)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.nodeCache.remove(edge.NodeKey1Bytes, edge.NodeKey2Bytes)
+
+	return nil
+}
+
+// hasChannelEdge returns the update times of the policies of the channel,
+// whether it exists and whether it's a zombie.
+func (s *sqlGraphStore) hasChannelEdge(chanID uint64) (time.Time, time.Time,
+	bool, bool, error) {
+
+	var (
+		ctx      = context.TODO()
+		readOpts = NewSQLGraphQueriesReadTx()
+
+		upd1Time, upd2Time time.Time
+		exists, isZombie   bool
+	)
+	err := s.db.ExecTx(ctx, &readOpts, func(db SQLGraphQueries) error {
+		upd1Time, upd2Time = time.Time{}, time.Time{}
+		exists, isZombie = false, false
+
+		row, err := db.GetGraphChannelBySCID(ctx, scidBytes(chanID))
+		switch {
+		// If the edge doesn't exist, then we'll also check our zombie
+		// index.
+		case errors.Is(err, sql.ErrNoRows):
+			isZombie, _, _, err = isSQLZombieEdge(ctx, db, chanID)
+
+			return err
+
+		case err != nil:
+			return err
+		}
+
+		exists = true
+
+		policy1, policy2, err := s.fetchPolicies(ctx, db, row, false)
+		if err != nil {
+			return err
+		}
+		if policy1 != nil {
+			upd1Time = policy1.LastUpdate
+		}
+		if policy2 != nil {
+			upd2Time = policy2.LastUpdate
+		}
+
+		return nil
+	})
+	if err != nil {
+		return time.Time{}, time.Time{}, exists, isZombie, err
+	}
+
+	return upd1Time, upd2Time, exists, isZombie, nil
+}
+
+// updateChannelEdge updates the static information of a known channel.
+func (s *sqlGraphStore) updateChannelEdge(edge *ChannelEdgeInfo) error {
+	params, err := sqlChannelParams(edge)
+	if err != nil {
+		return err
+	}
+
+	var (
+		ctx         = context.TODO()
+		writeTxOpts SQLGraphQueriesTxOptions
+	)
+	err = s.db.ExecTx(ctx, &writeTxOpts, func(db SQLGraphQueries) error {
+		numRows, err := db.UpdateGraphChannel(
+			ctx, sqlc.UpdateGraphChannelParams{
+				ChainHash:       params.ChainHash,
+				NodeKey1:        params.NodeKey1,
+				NodeKey2:        params.NodeKey2,
+				BitcoinKey1:     params.BitcoinKey1,
+				BitcoinKey2:     params.BitcoinKey2,
+				Features:        params.Features,
+				NodeSig1:        params.NodeSig1,
+				NodeSig2:        params.NodeSig2,
+				BitcoinSig1:     params.BitcoinSig1,
+				BitcoinSig2:     params.BitcoinSig2,
+				Outpoint:        params.Outpoint,
+				Capacity:        params.Capacity,
+				ExtraOpaqueData: params.ExtraOpaqueData,
+				Scid:            params.Scid,
+			},
+		)
+		if err != nil {
+			return err
+		}
+		if numRows == 0 {
+			return ErrEdgeNotFound
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.nodeCache.remove(edge.NodeKey1Bytes, edge.NodeKey2Bytes)
+
+	return nil
+}
+
+// deleteChannel removes the channel of the given row from the graph. If
+// markZombie is set, the channel is added to the zombie index.
+func (s *sqlGraphStore) deleteChannel(ctx context.Context, db SQLGraphQueries,
+	row sqlc.GraphChannel, markZombie,
+	strictZombie bool) (*ChannelEdgeInfo, error) {
+
+	info, err := s.buildChannel(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if markZombie {
+		nodeKey1, nodeKey2 := info.NodeKey1Bytes, info.NodeKey2Bytes
+		if strictZombie {
+			policy1, policy2, err := s.fetchPolicies(
+				ctx, db, row, false,
+			)
+			if err != nil {
+				return nil, err
+			}
+
+			nodeKey1, nodeKey2 = makeZombiePubkeys(
+				info, policy1, policy2,
+			)
+		}
+
+		err := db.UpsertGraphZombieChannel(
+			ctx, sqlc.UpsertGraphZombieChannelParams{
+				Scid:     row.Scid,
+				NodeKey1: nodeKey1[:],
+				NodeKey2: nodeKey2[:],
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The policies of the channel are removed by the database.
+	if err := db.DeleteGraphChannel(ctx, row.ID); err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// pruneSQLGraphNodes removes all nodes without channels from the graph,
+// except for the source node, and returns their public keys.
+func pruneSQLGraphNodes(ctx context.Context,
+	db SQLGraphQueries) ([]route.Vertex, error) {
+
+	log.Trace("Pruning nodes from graph with no open channels")
+
+	// We'll make sure the source node is known, as it's never pruned.
+	_, err := db.GetGraphSourceNode(ctx)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, ErrSourceNodeNotSet
+
+	case err != nil:
+		return nil, err
+	}
+
+	pubKeys, err := db.DeleteUnconnectedGraphNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pruned := make([]route.Vertex, 0, len(pubKeys))
+	for _, pubKey := range pubKeys {
+		log.Infof("Pruned unconnected node %x from channel graph",
+			pubKey)
+
+		var vertex route.Vertex
+		copy(vertex[:], pubKey)
+		pruned = append(pruned, vertex)
+	}
+
+	if len(pruned) > 0 {
+		log.Infof("Pruned %v unconnected nodes from the channel graph",
+			len(pruned))
+	}
+
+	return pruned, nil
+}
+
+// pruneGraph removes the channels of the spent outputs, records the block in
+// the prune log and removes the nodes without channels.
+func (s *sqlGraphStore) pruneGraph(spentOutputs []*wire.OutPoint,
+	blockHash *chainhash.Hash, blockHeight uint32) ([]*ChannelEdgeInfo,
+	error) {
+
+	var (
+		ctx         = context.TODO()
+		writeTxOpts SQLGraphQueriesTxOptions
+		chansClosed []*ChannelEdgeInfo
+		nodesPruned []route.Vertex
+	)
+	err := s.db.ExecTx(ctx, &writeTxOpts, func(db SQLGraphQueries) error {
+		chansClosed = nil
+
+		for _, chanPoint := range spentOutputs {
+			var opBytes bytes.Buffer
+			err := writeOutpoint(&opBytes, chanPoint)
+			if err != nil {
+				return err
+			}
+
+			row, err := db.GetGraphChannelByOutpoint(
+				ctx, opBytes.Bytes(),
+			)
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				continue
+
+			case err != nil:
+				return err
+			}
+
+			info, err := s.deleteChannel(ctx, db, row, false, false)
+			if err != nil {
+				return err
+			}
+
+			chansClosed = append(chansClosed, info)
+		}
+
+		err := db.UpsertGraphPruneLogEntry(
+			ctx, sqlc.UpsertGraphPruneLogEntryParams{
+				BlockHeight: int64(blockHeight),
+				BlockHash:   blockHash[:],
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		nodesPruned, err = pruneSQLGraphNodes(ctx, db)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, channel := range chansClosed {
+		s.nodeCache.remove(channel.NodeKey1Bytes, channel.NodeKey2Bytes)
+	}
+	s.nodeCache.remove(nodesPruned...)
+
+	return chansClosed, nil
+}
+
+// pruneGraphNodes removes all nodes without channels from the graph.
+func (s *sqlGraphStore) pruneGraphNodes() error {
+	var (
+		ctx         = context.TODO()
+		writeTxOpts SQLGraphQueriesTxOptions
+		nodesPruned []route.Vertex
+	)
+	err := s.db.ExecTx(ctx, &writeTxOpts, func(db SQLGraphQueries) error {
+		var err error
+		nodesPruned, err = pruneSQLGraphNodes(ctx, db)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.nodeCache.remove(nodesPruned...)
+
+	return nil
+}
+
+// disconnectBlockAtHeight removes all channels that were confirmed at or
+// above the given height, and the prune log entries of those heights.
+func (s *sqlGraphStore) disconnectBlockAtHeight(height uint32) (
+	[]*ChannelEdgeInfo, error) {
+
+	// Every channel having a ShortChannelID starting at 'height' will no
+	// longer be confirmed. The alias SCIDs aren't removed.
+	startShortChanID := lnwire.ShortChannelID{
+		BlockHeight: height,
+	}
+	endChanID := aliasmgr.StartingAlias.ToUint64() - 1
+
+	var (
+		ctx          = context.TODO()
+		writeTxOpts  SQLGraphQueriesTxOptions
+		removedChans []*ChannelEdgeInfo
+	)
+	err := s.db.ExecTx(ctx, &writeTxOpts, func(db SQLGraphQueries) error {
+		removedChans = nil
+
+		rows, err := db.ListGraphChannelsInRange(
+			ctx, sqlc.ListGraphChannelsInRangeParams{
+				StartScid: scidBytes(startShortChanID.ToUint64()),
+				EndScid:   scidBytes(endChanID),
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			info, err := s.deleteChannel(ctx, db, row, false, false)
+			if err != nil {
+				return err
+			}
+
+			removedChans = append(removedChans, info)
+		}
+
+		return db.DeleteGraphPruneLogEntries(ctx, int64(height))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, channel := range removedChans {
+		s.nodeCache.remove(channel.NodeKey1Bytes, channel.NodeKey2Bytes)
+	}
+
+	return removedChans, nil
+}
+
+// pruneTip returns the block of the most recent prune log entry.
+func (s *sqlGraphStore) pruneTip() (*chainhash.Hash, uint32, error) {
+	var (
+		ctx      = context.TODO()
+		readOpts = NewSQLGraphQueriesReadTx()
+		tip      sqlc.GraphPruneLog
+	)
+	err := s.db.ExecTx(ctx, &readOpts, func(db SQLGraphQueries) error {
+		var err error
+		tip, err = db.GetGraphPruneTip(ctx)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrGraphNeverPruned
+		}
+
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	tipHash, err := chainhash.NewHash(tip.BlockHash)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return tipHash, uint32(tip.BlockHeight), nil
+}
+
+// deleteChannelEdges removes the given channels from the graph, optionally
+// marking them as zombies.
+func (s *sqlGraphStore) deleteChannelEdges(strictZombiePruning,
+	markZombie bool, chanIDs ...uint64) error {
+
+	var (
+		ctx          = context.TODO()
+		writeTxOpts  SQLGraphQueriesTxOptions
+		removedChans []*ChannelEdgeInfo
+	)
+	err := s.db.ExecTx(ctx, &writeTxOpts, func(db SQLGraphQueries) error {
+		removedChans = nil
+
+		for _, chanID := range chanIDs {
+			row, err := db.GetGraphChannelBySCID(
+				ctx, scidBytes(chanID),
+			)
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				return ErrEdgeNotFound
+
+			case err != nil:
+				return err
+			}
+
+			info, err := s.deleteChannel(
+				ctx, db, row, markZombie, strictZombiePruning,
+			)
+			if err != nil {
+				return err
+			}
+
+			removedChans = append(removedChans, info)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, channel := range removedChans {
+		s.nodeCache.remove(channel.NodeKey1Bytes, channel.NodeKey2Bytes)
+	}
+
+	return nil
+}
+
+// channelID returns the channel ID of the channel with the given funding
+// outpoint.
+func (s *sqlGraphStore) channelID(chanPoint *wire.OutPoint) (uint64, error) {
+	var opBytes bytes.Buffer
+	if err := writeOutpoint(&opBytes, chanPoint); err != nil {
+		return 0, err
+	}
+
+	var (
+		ctx      = context.TODO()
+		readOpts = NewSQLGraphQueriesReadTx()
+		chanID   uint64
+	)
+	err := s.db.ExecTx(ctx, &readOpts, func(db SQLGraphQueries) error {
+		row, err := db.GetGraphChannelByOutpoint(ctx, opBytes.Bytes())
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEdgeNotFound
+
+		case err != nil:
+			return err
+		}
+
+		chanID = byteOrder.Uint64(row.Scid)
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return chanID, nil
+}
+
+// highestChanID returns the highest channel ID of the graph, or zero if there
+// are no channels.
+func (s *sqlGraphStore) highestChanID() (uint64, error) {
+	var (
+		ctx      = context.TODO()
+		readOpts = NewSQLGraphQueriesReadTx()
+		chanID   uint64
+	)
+	err := s.db.ExecTx(ctx, &readOpts, func(db SQLGraphQueries) error {
+		scid, err := db.GetHighestGraphChannelSCID(ctx)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			chanID = 0
+
+			return nil
+
+		case err != nil:
+			return err
+		}
+
+		chanID = byteOrder.Uint64(scid)
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return chanID, nil
+}
+
+// chanUpdatesInHorizon returns all channels with a policy that was updated
+// within the given horizon.
+func (s *sqlGraphStore) chanUpdatesInHorizon(startTime,
+	endTime time.Time) ([]ChannelEdge, error) {
+
+	var (
+		ctx      = context.TODO()
+		readOpts = NewSQLGraphQueriesReadTx()
+		channels []ChannelEdge
+	)
+	err := s.db.ExecTx(ctx, &readOpts, func(db SQLGraphQueries) error {
+		channels = nil
+
+		rows, err := db.ListGraphChannelsUpdatedInHorizon(
+			ctx, sqlc.ListGraphChannelsUpdatedInHorizonParams{
+				StartTime: startTime.Unix(),
+				EndTime:   endTime.Unix(),
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			channel, err := s.fetchChannelEdge(ctx, db, row)
+			if err != nil {
+				return fmt.Errorf("unable to fetch channel "+
+					"with chan_id=%v: %w",
+					byteOrder.Uint64(row.Scid), err)
+			}
+
+			channels = append(channels, channel)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return channels, nil
+}
+
+// nodeUpdatesInHorizon returns all nodes with an announcement within the
+// given horizon.
+func (s *sqlGraphStore) nodeUpdatesInHorizon(startTime,
+	endTime time.Time) ([]LightningNode, error) {
+
+	var (
+		ctx      = context.TODO()
+		readOpts = NewSQLGraphQueriesReadTx()
+		nodes    []LightningNode
+	)
+	err := s.db.ExecTx(ctx, &readOpts, func(db SQLGraphQueries) error {
+		nodes = nil
+
+		rows, err := db.ListGraphNodesInHorizon(
+			ctx, sqlc.ListGraphNodesInHorizonParams{
+				StartTime: startTime.Unix(),
+				EndTime:   endTime.Unix(),
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			node, err := s.buildNode(row)
+			if err != nil {
+				return err
+			}
+
+			nodes = append(nodes, *node)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return nodes, nil
+}
+
+// filterKnownChanIDs returns the channel IDs that are neither known channels
+// nor zombies.
+func (s *sqlGraphStore) filterKnownChanIDs(chanIDs []uint64) ([]uint64,
+	error) {
+
+	var (
+		ctx        = context.TODO()
+		readOpts   = NewSQLGraphQueriesReadTx()
+		newChanIDs []uint64
+	)
+	err := s.db.ExecTx(ctx, &readOpts, func(db SQLGraphQueries) error {
+		newChanIDs = nil
+
+		for _, chanID := range chanIDs {
+			_, err := db.GetGraphChannelBySCID(ctx, scidBytes(chanID))
+			switch {
+			case err == nil:
+				continue
+
+			case !errors.Is(err, sql.ErrNoRows):
+				return err
+			}
+
+			isZombie, _, _, err := isSQLZombieEdge(ctx, db, chanID)
+			if err != nil {
+				return err
+			}
+			if isZombie {
+				continue
+			}
+
+			newChanIDs = append(newChanIDs, chanID)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newChanIDs, nil
+}
+
+// filterChannelRange returns the announced channels that were confirmed
+// within the given block range, grouped by block height.
+func (s *sqlGraphStore) filterChannelRange(startHeight,
+	endHeight uint32) ([]BlockChannelRange, error) {
+
+	startChanID := lnwire.ShortChannelID{
+		BlockHeight: startHeight,
+	}
+	endChanID := lnwire.ShortChannelID{
+		BlockHeight: endHeight,
+		TxIndex:     0x00ffffff,
+		TxPosition:  0xffff,
+	}
+
+	var (
+		ctx              = context.TODO()
+		readOpts         = NewSQLGraphQueriesReadTx()
+		channelsPerBlock map[uint32][]lnwire.ShortChannelID
+	)
+	err := s.db.ExecTx(ctx, &readOpts, func(db SQLGraphQueries) error {
+		channelsPerBlock = make(map[uint32][]lnwire.ShortChannelID)
+
+		rows, err := db.ListGraphChannelsInRange(
+			ctx, sqlc.ListGraphChannelsInRangeParams{
+				StartScid: scidBytes(startChanID.ToUint64()),
+				EndScid:   scidBytes(endChanID.ToUint64()),
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			// Channels that weren't announced, like those with
+			// an alias SCID, aren't part of the gossip sync.
+			info, err := s.buildChannel(row)
+			if err != nil {
+				return err
+			}
+			if info.AuthProof == nil {
+				continue
+			}
+
+			cid := lnwire.NewShortChanIDFromInt(info.ChannelID)
+			channelsPerBlock[cid.BlockHeight] = append(
+				channelsPerBlock[cid.BlockHeight], cid,
+			)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(channelsPerBlock) == 0 {
+		return nil, nil
+	}
+
+	// Return the channel ranges in ascending block height order.
+	blocks := make([]uint32, 0, len(channelsPerBlock))
+	for block := range channelsPerBlock {
+		blocks = append(blocks, block)
+	}
+	sort.Slice(blocks, func(i, j int) bool {
+		return blocks[i] < blocks[j]
+	})
+
+	channelRanges := make([]BlockChannelRange, 0, len(channelsPerBlock))
+	for _, block := range blocks {
+		channelRanges = append(channelRanges, BlockChannelRange{
+			Height:   block,
+			Channels: channelsPerBlock[block],
+		})
+	}
+
+	return channelRanges, nil
+}
+
+// fetchChanInfos returns the known channels of the given channel IDs.
+func (s *sqlGraphStore) fetchChanInfos(chanIDs []uint64) ([]ChannelEdge,
+	error) {
+
+	var (
+		ctx       = context.TODO()
+		readOpts  = NewSQLGraphQueriesReadTx()
+		chanEdges []ChannelEdge
+	)
+	err := s.db.ExecTx(ctx, &readOpts, func(db SQLGraphQueries) error {
+		chanEdges = nil
+
+		for _, chanID := range chanIDs {
+			row, err := db.GetGraphChannelBySCID(
+				ctx, scidBytes(chanID),
+			)
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				continue
+
+			case err != nil:
+				return err
+			}
+
+			channel, err := s.fetchChannelEdge(ctx, db, row)
+			if err != nil {
+				return err
+			}
+
+			chanEdges = append(chanEdges, channel)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return chanEdges, nil
+}
+
+// updateEdgePolicy adds or updates the policy of a known channel. The
+// direction of the policy is taken from its channel flags.
+func (s *sqlGraphStore) updateEdgePolicy(edge *ChannelEdgePolicy) error {
+	var (
+		ctx         = context.TODO()
+		writeTxOpts SQLGraphQueriesTxOptions
+		info        *ChannelEdgeInfo
+	)
+	err := s.db.ExecTx(ctx, &writeTxOpts, func(db SQLGraphQueries) error {
+		row, err := db.GetGraphChannelBySCID(
+			ctx, scidBytes(edge.ChannelID),
+		)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEdgeNotFound
+
+		case err != nil:
+			return err
+		}
+
+		info, err = s.buildChannel(row)
+		if err != nil {
+			return err
+		}
+
+		params, err := sqlPolicyParams(row.ID, edge)
+		if err != nil {
+			return err
+		}
+
+		return db.UpsertGraphChannelPolicy(ctx, params)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.nodeCache.remove(info.NodeKey1Bytes, info.NodeKey2Bytes)
+
+	return nil
+}
+
+// fetchLightningNode returns the node with the given public key.
+func (s *sqlGraphStore) fetchLightningNode(nodePub route.Vertex) (
+	*LightningNode, error) {
+
+	var (
+		ctx      = context.TODO()
+		readOpts = NewSQLGraphQueriesReadTx()
+		node     *LightningNode
+	)
+	err := s.db.ExecTx(ctx, &readOpts, func(db SQLGraphQueries) error {
+		var err error
+		node, err = s.getNode(ctx, db, nodePub[:])
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return node, nil
+}
+
+// hasLightningNode returns the last update time of the node with the given
+// public key and whether it's known.
+func (s *sqlGraphStore) hasLightningNode(nodePub [33]byte) (time.Time, bool,
+	error) {
+
+	node, err := s.fetchLightningNode(nodePub)
+	switch {
+	case errors.Is(err, ErrGraphNodeNotFound):
+		return time.Time{}, false, nil
+
+	case err != nil:
+		return time.Time{}, false, err
+	}
+
+	return node.LastUpdate, true, nil
+}
+
+// fetchChannelEdgesByOutpoint returns the channel with the given funding
+// outpoint and its policies.
+func (s *sqlGraphStore) fetchChannelEdgesByOutpoint(op *wire.OutPoint) (
+	*ChannelEdgeInfo, *ChannelEdgePolicy, *ChannelEdgePolicy, error) {
+
+	var opBytes bytes.Buffer
+	if err := writeOutpoint(&opBytes, op); err != nil {
+		return nil, nil, nil, err
+	}
+
+	var (
+		ctx      = context.TODO()
+		readOpts = NewSQLGraphQueriesReadTx()
+		channel  ChannelEdge
+	)
+	err := s.db.ExecTx(ctx, &readOpts, func(db SQLGraphQueries) error {
+		row, err := db.GetGraphChannelByOutpoint(ctx, opBytes.Bytes())
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEdgeNotFound
+
+		case err != nil:
+			return err
+		}
+
+		channel, err = s.fetchChannelEdge(ctx, db, row)
+
+		return err
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return channel.Info, channel.Policy1, channel.Policy2, nil
+}
+
+// fetchChannelEdgesByID returns the channel with the given ID and its
+// policies. If the channel is a zombie, ErrZombieEdge is returned along with
+// an info that only contains the node keys.
+func (s *sqlGraphStore) fetchChannelEdgesByID(chanID uint64) (
+	*ChannelEdgeInfo, *ChannelEdgePolicy, *ChannelEdgePolicy, error) {
+
+	var (
+		ctx      = context.TODO()
+		readOpts = NewSQLGraphQueriesReadTx()
+		channel  ChannelEdge
+	)
+	err := s.db.ExecTx(ctx, &readOpts, func(db SQLGraphQueries) error {
+		channel = ChannelEdge{}
+
+		row, err := db.GetGraphChannelBySCID(ctx, scidBytes(chanID))
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			isZombie, pubKey1, pubKey2, err := isSQLZombieEdge(
+				ctx, db, chanID,
+			)
+			if err != nil {
+				return err
+			}
+			if !isZombie {
+				return ErrEdgeNotFound
+			}
+
+			channel.Info = &ChannelEdgeInfo{
+				NodeKey1Bytes: pubKey1,
+				NodeKey2Bytes: pubKey2,
+			}
+
+			return ErrZombieEdge
+
+		case err != nil:
+			return err
+		}
+
+		channel, err = s.fetchChannelEdge(ctx, db, row)
+
+		return err
+	})
+	switch {
+	case errors.Is(err, ErrZombieEdge):
+		return channel.Info, nil, nil, err
+
+	case err != nil:
+		return nil, nil, nil, err
+	}
+
+	return channel.Info, channel.Policy1, channel.Policy2, nil
+}
+
+// isPublicNode determines whether the node is public from the source node's
+// point of view.
+func (s *sqlGraphStore) isPublicNode(pubKey [33]byte) (bool, error) {
+	var (
+		ctx          = context.TODO()
+		readOpts     = NewSQLGraphQueriesReadTx()
+		nodeIsPublic bool
+	)
+	err := s.db.ExecTx(ctx, &readOpts, func(db SQLGraphQueries) error {
+		nodeIsPublic = false
+
+		sourcePubKey, err := db.GetGraphSourceNode(ctx)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrSourceNodeNotSet
+
+		case err != nil:
+			return err
+		}
+
+		if _, err := s.getNode(ctx, db, pubKey[:]); err != nil {
+			return err
+		}
+
+		rows, err := db.ListGraphNodeChannels(ctx, pubKey[:])
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			// A channel that doesn't extend to the source node
+			// makes the node public, as does an announced channel
+			// with the source node.
+			if !bytes.Equal(row.NodeKey1, sourcePubKey) &&
+				!bytes.Equal(row.NodeKey2, sourcePubKey) {
+
+				nodeIsPublic = true
+
+				return nil
+			}
+
+			info, err := s.buildChannel(row)
+			if err != nil {
+				return err
+			}
+			if info.AuthProof != nil {
+				nodeIsPublic = true
+
+				return nil
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return nodeIsPublic, nil
+}
+
+// channelView returns the funding outpoint and script of every channel of the
+// graph.
+func (s *sqlGraphStore) channelView() ([]EdgePoint, error) {
+	var edgePoints []EdgePoint
+	err := s.forEachChannel(func(info *ChannelEdgeInfo, _,
+		_ *ChannelEdgePolicy) error {
+
+		pkScript, err := genMultiSigP2WSH(
+			info.BitcoinKey1Bytes[:], info.BitcoinKey2Bytes[:],
+		)
+		if err != nil {
+			return err
+		}
+
+		edgePoints = append(edgePoints, EdgePoint{
+			FundingPkScript: pkScript,
+			OutPoint:        info.ChannelPoint,
+		})
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return edgePoints, nil
+}
+
+// markEdgeZombie adds the channel to the zombie index.
+func (s *sqlGraphStore) markEdgeZombie(chanID uint64,
+	pubKey1, pubKey2 [33]byte) error {
+
+	var (
+		ctx         = context.TODO()
+		writeTxOpts SQLGraphQueriesTxOptions
+	)
+	err := s.db.ExecTx(ctx, &writeTxOpts, func(db SQLGraphQueries) error {
+		return db.UpsertGraphZombieChannel(
+			ctx, sqlc.UpsertGraphZombieChannelParams{
+				Scid:     scidBytes(chanID),
+				NodeKey1: pubKey1[:],
+				NodeKey2: pubKey2[:],
+			},
+		)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.nodeCache.remove(pubKey1, pubKey2)
+
+	return nil
+}
+
+// markEdgeLive removes the channel from the zombie index.
+func (s *sqlGraphStore) markEdgeLive(chanID uint64) error {
+	var (
+		ctx         = context.TODO()
+		writeTxOpts SQLGraphQueriesTxOptions
+		nodes       []route.Vertex
+	)
+	err := s.db.ExecTx(ctx, &writeTxOpts, func(db SQLGraphQueries) error {
+		nodes = nil
+
+		scid := scidBytes(chanID)
+		if err := db.DeleteGraphZombieChannel(ctx, scid); err != nil {
+			return err
+		}
+
+		// If the channel is still known, it can be used for path
+		// finding again.
+		row, err := db.GetGraphChannelBySCID(ctx, scid)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil
+
+		case err != nil:
+			return err
+		}
+
+		var node1, node2 route.Vertex
+		copy(node1[:], row.NodeKey1)
+		copy(node2[:], row.NodeKey2)
+		nodes = append(nodes, node1, node2)
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.nodeCache.remove(nodes...)
+
+	return nil
+}
+
+// isSQLZombieEdge returns whether the channel is a zombie, and if so, the
+// node keys stored with it.
+func isSQLZombieEdge(ctx context.Context, db SQLGraphQueries,
+	chanID uint64) (bool, [33]byte, [33]byte, error) {
+
+	var pubKey1, pubKey2 [33]byte
+
+	row, err := db.GetGraphZombieChannel(ctx, scidBytes(chanID))
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return false, pubKey1, pubKey2, nil
+
+	case err != nil:
+		return false, pubKey1, pubKey2, err
+	}
+
+	copy(pubKey1[:], row.NodeKey1)
+	copy(pubKey2[:], row.NodeKey2)
+
+	return true, pubKey1, pubKey2, nil
+}
+
+// isZombieEdge returns whether the channel is a zombie, and if so, the node
+// keys stored with it.
+func (s *sqlGraphStore) isZombieEdge(chanID uint64) (bool, [33]byte,
+	[33]byte) {
+
+	var (
+		ctx              = context.TODO()
+		readOpts         = NewSQLGraphQueriesReadTx()
+		isZombie         bool
+		pubKey1, pubKey2 [33]byte
+	)
+	err := s.db.ExecTx(ctx, &readOpts, func(db SQLGraphQueries) error {
+		var err error
+		isZombie, pubKey1, pubKey2, err = isSQLZombieEdge(
+			ctx, db, chanID,
+		)
+
+		return err
+	})
+	if err != nil {
+		return false, [33]byte{}, [33]byte{}
+	}
+
+	return isZombie, pubKey1, pubKey2
+}
+
+// numZombies returns the number of zombie channels.
+func (s *sqlGraphStore) numZombies() (uint64, error) {
+	var (
+		ctx        = context.TODO()
+		readOpts   = NewSQLGraphQueriesReadTx()
+		numZombies int64
+	)
+	err := s.db.ExecTx(ctx, &readOpts, func(db SQLGraphQueries) error {
+		var err error
+		numZombies, err = db.CountGraphZombieChannels(ctx)
+
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(numZombies), nil
+}
@@ -0,0 +1,314 @@
+package channeldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lightningnetwork/lnd/kvdb"
+	"github.com/lightningnetwork/lnd/sqldb/sqlc"
+)
+
+const (
+	// graphKVMigrationName is the name under which the migration of the
+	// channel graph from the key-value database is recorded.
+	graphKVMigrationName = "graph"
+
+	// graphTombstoneReason is the reason stored in the tombstone of the
+	// key-value channel graph after it was migrated.
+	graphTombstoneReason = "channel graph migrated to native SQL tables"
+)
+
+var (
+	// graphTombstoneKey is the key of the marker that is added to the
+	// key-value database once the channel graph was migrated to the native
+	// SQL tables. From then on, the key-value graph is outdated and must no
+	// longer be used.
+	graphTombstoneKey = []byte("graph-migration-tombstone")
+
+	// ErrGraphMigratedToSQL is returned if the key-value channel graph is
+	// opened after it was migrated to the native SQL tables.
+	ErrGraphMigratedToSQL = errors.New("refusing to use channel graph, it " +
+		"was migrated to native SQL tables")
+)
+
+// migrateGraphToSQL copies the nodes, channels, policies, zombies and prune
+// log of the key-value channel graph to the native SQL tables. The migration
+// is recorded in the SQL database, so it only runs once. The graph is kept in
+// the key-value database, but is marked with a tombstone, as it is no longer
+// updated after the migration.
+func migrateGraphToSQL(ctx context.Context, graph *ChannelGraph,
+	db BatchedSQLGraphQueries) error {
+
+	readOpts := NewSQLGraphQueriesReadTx()
+
+	var migrated bool
+	err := db.ExecTx(ctx, &readOpts, func(db SQLGraphQueries) error {
+		_, err := db.GetKVMigration(ctx, graphKVMigrationName)
+		switch {
+		case err == nil:
+			migrated = true
+
+		case errors.Is(err, sql.ErrNoRows):
+			migrated = false
+
+		default:
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// The tombstone might be missing if we were shut down right after
+	// the migration.
+	if migrated {
+		return tombstoneKVGraph(graph.db)
+	}
+
+	log.Infof("Migrating channel graph from the key-value database to " +
+		"SQL, this might take a while...")
+
+	var (
+		writeTxOpts          SQLGraphQueriesTxOptions
+		numNodes, numChans   int
+		numZombies, numPrune int
+	)
+	err = db.ExecTx(ctx, &writeTxOpts, func(db SQLGraphQueries) error {
+		var err error
+		numNodes, err = migrateGraphNodes(ctx, graph, db)
+		if err != nil {
+			return err
+		}
+
+		numChans, err = migrateGraphChannels(ctx, graph, db)
+		if err != nil {
+			return err
+		}
+
+		numZombies, numPrune, err = migrateGraphMeta(ctx, graph, db)
+		if err != nil {
+			return err
+		}
+
+		return db.InsertKVMigration(ctx, sqlc.InsertKVMigrationParams{
+			Name:       graphKVMigrationName,
+			MigratedAt: time.Now().UTC(),
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Migrated %d nodes, %d channels, %d zombie channels and %d "+
+		"prune log entries of the channel graph to SQL", numNodes,
+		numChans, numZombies, numPrune)
+
+	return tombstoneKVGraph(graph.db)
+}
+
+// tombstoneKVGraph marks the channel graph of the key-value database as
+// migrated to the native SQL tables, so that it is no longer used. Adding the
+// tombstone more than once is a no-op.
+func tombstoneKVGraph(db kvdb.Backend) error {
+	return kvdb.Update(db, func(tx kvdb.RwTx) error {
+		_, err := CheckMarkerPresent(tx, graphTombstoneKey)
+		switch {
+		case err == nil:
+			return nil
+
+		case !errors.Is(err, ErrMarkerNotPresent):
+			return err
+		}
+
+		return AddMarker(
+			tx, graphTombstoneKey, []byte(graphTombstoneReason),
+		)
+	}, func() {})
+}
+
+// ensureNoGraphTombstone returns ErrGraphMigratedToSQL if the channel graph
+// of the key-value database was migrated to the native SQL tables.
+func ensureNoGraphTombstone(db kvdb.Backend) error {
+	return kvdb.View(db, func(tx kvdb.RTx) error {
+		marker, err := CheckMarkerPresent(tx, graphTombstoneKey)
+		switch {
+		case errors.Is(err, ErrMarkerNotPresent):
+			return nil
+
+		case err != nil:
+			return err
+		}
+
+		return fmt.Errorf("%w; tombstone reads: %s",
+			ErrGraphMigratedToSQL, string(marker))
+	}, func() {})
+}
+
+// migrateGraphNodes inserts all nodes of the key-value graph, including the
+// source node.
+func migrateGraphNodes(ctx context.Context, graph *ChannelGraph,
+	db SQLGraphQueries) (int, error) {
+
+	var numNodes int
+	err := graph.ForEachNode(func(_ kvdb.RTx, node *LightningNode) error {
+		params, err := sqlNodeParams(node)
+		if err != nil {
+			return fmt.Errorf("unable to migrate node %x: %w",
+				node.PubKeyBytes, err)
+		}
+
+		if err := db.UpsertGraphNode(ctx, params); err != nil {
+			return err
+		}
+		numNodes++
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	source, err := graph.SourceNode()
+	switch {
+	case errors.Is(err, ErrSourceNodeNotSet):
+		return numNodes, nil
+
+	case err != nil:
+		return 0, err
+	}
+
+	return numNodes, db.SetGraphSourceNode(ctx, source.PubKeyBytes[:])
+}
+
+// migrateGraphChannels inserts all channels of the key-value graph along with
+// their policies.
+func migrateGraphChannels(ctx context.Context, graph *ChannelGraph,
+	db SQLGraphQueries) (int, error) {
+
+	var numChans int
+	err := graph.ForEachChannel(func(info *ChannelEdgeInfo, policy1,
+		policy2 *ChannelEdgePolicy) error {
+
+		params, err := sqlChannelParams(info)
+		if err != nil {
+			return fmt.Errorf("unable to migrate channel %v: %w",
+				info.ChannelID, err)
+		}
+
+		// The nodes of a channel are always known in the key-value
+		// graph, but we make sure the rows exist anyway.
+		err = db.InsertGraphNodeShell(ctx, info.NodeKey1Bytes[:])
+		if err != nil {
+			return err
+		}
+		err = db.InsertGraphNodeShell(ctx, info.NodeKey2Bytes[:])
+		if err != nil {
+			return err
+		}
+
+		channelID, err := db.InsertGraphChannel(ctx, params)
+		if err != nil {
+			return err
+		}
+
+		for _, policy := range []*ChannelEdgePolicy{policy1, policy2} {
+			if policy == nil {
+				continue
+			}
+
+			params, err := sqlPolicyParams(channelID, policy)
+			if err != nil {
+				return fmt.Errorf("unable to migrate policy of "+
+					"channel %v: %w", info.ChannelID, err)
+			}
+
+			err = db.UpsertGraphChannelPolicy(ctx, params)
+			if err != nil {
+				return err
+			}
+		}
+		numChans++
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return numChans, nil
+}
+
+// migrateGraphMeta inserts the zombie index and the prune log of the
+// key-value graph.
+func migrateGraphMeta(ctx context.Context, graph *ChannelGraph,
+	db SQLGraphQueries) (int, int, error) {
+
+	var numZombies, numPrune int
+	err := kvdb.View(graph.db, func(tx kvdb.RTx) error {
+		edges := tx.ReadBucket(edgeBucket)
+		if edges == nil {
+			return ErrGraphNoEdgesFound
+		}
+
+		zombieIndex := edges.NestedReadBucket(zombieBucket)
+		if zombieIndex != nil {
+			err := zombieIndex.ForEach(func(k, v []byte) error {
+				if len(k) != 8 || len(v) != 66 {
+					return fmt.Errorf("invalid zombie "+
+						"index entry %x", k)
+				}
+
+				numZombies++
+
+				return db.UpsertGraphZombieChannel(
+					ctx, sqlc.UpsertGraphZombieChannelParams{
+						Scid:     k,
+						NodeKey1: v[:33],
+						NodeKey2: v[33:],
+					},
+				)
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		metaBucket := tx.ReadBucket(graphMetaBucket)
+		if metaBucket == nil {
+			return nil
+		}
+
+		pruneBucket := metaBucket.NestedReadBucket(pruneLogBucket)
+		if pruneBucket == nil {
+			return nil
+		}
+
+		return pruneBucket.ForEach(func(k, v []byte) error {
+			if len(k) != 4 || len(v) != 32 {
+				return fmt.Errorf("invalid prune log entry %x",
+					k)
+			}
+
+			numPrune++
+
+			return db.UpsertGraphPruneLogEntry(
+				ctx, sqlc.UpsertGraphPruneLogEntryParams{
+					BlockHeight: int64(byteOrder.Uint32(k)),
+					BlockHash:   v,
+				},
+			)
+		})
+	}, func() {
+		numZombies, numPrune = 0, 0
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return numZombies, numPrune, nil
+}
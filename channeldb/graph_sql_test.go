@@ -0,0 +1,499 @@
+package channeldb
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightningnetwork/lnd/kvdb"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/sqldb"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestGraphSQLDB creates a new SQL graph store backed by a fresh SQLite
+// database.
+func newTestGraphSQLDB(t *testing.T) BatchedSQLGraphQueries {
+	db := sqldb.NewTestSqliteDB(t).BaseDB
+
+	return sqldb.NewTransactionExecutor(
+		db, func(tx *sql.Tx) SQLGraphQueries {
+			return db.WithTx(tx)
+		},
+	)
+}
+
+// makeSQLTestGraph creates a new channel graph that is stored in a fresh
+// SQLite database.
+func makeSQLTestGraph(t *testing.T) *ChannelGraph {
+	backend, backendCleanup, err := kvdb.GetTestBackend(t.TempDir(), "cgr")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = backend.Close()
+		backendCleanup()
+	})
+
+	opts := DefaultOptions()
+	graph, err := NewChannelGraph(
+		backend, opts.RejectCacheSize, opts.ChannelCacheSize,
+		opts.BatchCommitInterval, opts.PreAllocCacheNumNodes,
+		false, false,
+	)
+	require.NoError(t, err)
+
+	graph.sqlStore = newSQLGraphStore(newTestGraphSQLDB(t))
+
+	return graph
+}
+
+// TestSQLGraphNodes tests that nodes are stored in and read from the SQL
+// tables.
+func TestSQLGraphNodes(t *testing.T) {
+	t.Parallel()
+
+	graph := makeSQLTestGraph(t)
+
+	_, err := graph.SourceNode()
+	require.ErrorIs(t, err, ErrSourceNodeNotSet)
+
+	node, err := createTestVertex(nil)
+	require.NoError(t, err)
+	node.LastUpdate = time.Unix(1000, 0)
+	node.ExtraOpaqueData = []byte("extra new data")
+	require.NoError(t, graph.AddLightningNode(node))
+
+	dbNode, err := graph.FetchLightningNode(node.PubKeyBytes)
+	require.NoError(t, err)
+	require.NoError(t, compareNodes(node, dbNode))
+	require.True(t, dbNode.Features.HasFeature(
+		lnwire.GossipQueriesRequired,
+	))
+
+	pub, err := node.PubKey()
+	require.NoError(t, err)
+	alias, err := graph.LookupAlias(pub)
+	require.NoError(t, err)
+	require.Equal(t, node.Alias, alias)
+
+	updateTime, exists, err := graph.HasLightningNode(node.PubKeyBytes)
+	require.NoError(t, err)
+	require.True(t, exists)
+	require.Equal(t, node.LastUpdate, updateTime)
+
+	source, err := createTestVertex(nil)
+	require.NoError(t, err)
+	source.LastUpdate = time.Unix(2000, 0)
+	require.NoError(t, graph.SetSourceNode(source))
+
+	dbSource, err := graph.SourceNode()
+	require.NoError(t, err)
+	require.NoError(t, compareNodes(source, dbSource))
+
+	// A node we only know from a channel is added as a shell node without
+	// an alias.
+	shellNode, err := createTestVertex(nil)
+	require.NoError(t, err)
+	edgeInfo, _ := createEdge(100, 0, 0, 0, node, shellNode)
+	require.NoError(t, graph.AddChannelEdge(&edgeInfo))
+
+	dbShellNode, err := graph.FetchLightningNode(shellNode.PubKeyBytes)
+	require.NoError(t, err)
+	require.False(t, dbShellNode.HaveNodeAnnouncement)
+
+	shellPub, err := shellNode.PubKey()
+	require.NoError(t, err)
+	_, err = graph.LookupAlias(shellPub)
+	require.ErrorIs(t, err, ErrNodeAliasNotFound)
+
+	// Only announced nodes are returned within a horizon.
+	nodes, err := graph.NodeUpdatesInHorizon(
+		time.Unix(500, 0), time.Unix(1500, 0),
+	)
+	require.NoError(t, err)
+	require.Len(t, nodes, 1)
+	require.Equal(t, node.PubKeyBytes, nodes[0].PubKeyBytes)
+
+	var numNodes int
+	err = graph.ForEachNode(func(_ kvdb.RTx, _ *LightningNode) error {
+		numNodes++
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, numNodes)
+
+	require.NoError(t, graph.DeleteLightningNode(node.PubKeyBytes))
+	_, err = graph.FetchLightningNode(node.PubKeyBytes)
+	require.ErrorIs(t, err, ErrGraphNodeNotFound)
+	err = graph.DeleteLightningNode(node.PubKeyBytes)
+	require.ErrorIs(t, err, ErrGraphNodeNotFound)
+}
+
+// TestSQLGraphChannels tests that channels and their policies are stored in
+// and read from the SQL tables.
+func TestSQLGraphChannels(t *testing.T) {
+	t.Parallel()
+
+	graph := makeSQLTestGraph(t)
+
+	node1, err := createTestVertex(nil)
+	require.NoError(t, err)
+	require.NoError(t, graph.AddLightningNode(node1))
+	node2, err := createTestVertex(nil)
+	require.NoError(t, err)
+	require.NoError(t, graph.AddLightningNode(node2))
+
+	edgeInfo, edge1, edge2 := createChannelEdge(nil, node1, node2)
+	require.NoError(t, graph.AddChannelEdge(edgeInfo))
+	err = graph.AddChannelEdge(edgeInfo)
+	require.ErrorIs(t, err, ErrEdgeAlreadyExist)
+
+	// Without any policies, the channel is known but hasn't been
+	// updated.
+	upd1, upd2, exists, isZombie, err := graph.HasChannelEdge(
+		edgeInfo.ChannelID,
+	)
+	require.NoError(t, err)
+	require.True(t, exists)
+	require.False(t, isZombie)
+	require.True(t, upd1.IsZero())
+	require.True(t, upd2.IsZero())
+
+	require.NoError(t, graph.UpdateEdgePolicy(edge1))
+	require.NoError(t, graph.UpdateEdgePolicy(edge2))
+
+	dbInfo, dbEdge1, dbEdge2, err := graph.FetchChannelEdgesByID(
+		edgeInfo.ChannelID,
+	)
+	require.NoError(t, err)
+	assertEdgeInfoEqual(t, edgeInfo, dbInfo)
+	require.NoError(t, compareEdgePolicies(edge1, dbEdge1))
+	require.NoError(t, compareEdgePolicies(edge2, dbEdge2))
+
+	dbInfo, _, _, err = graph.FetchChannelEdgesByOutpoint(
+		&edgeInfo.ChannelPoint,
+	)
+	require.NoError(t, err)
+	assertEdgeInfoEqual(t, edgeInfo, dbInfo)
+
+	chanID, err := graph.ChannelID(&edgeInfo.ChannelPoint)
+	require.NoError(t, err)
+	require.Equal(t, edgeInfo.ChannelID, chanID)
+
+	highestChanID, err := graph.HighestChanID()
+	require.NoError(t, err)
+	require.Equal(t, edgeInfo.ChannelID, highestChanID)
+
+	edges, err := graph.ChanUpdatesInHorizon(
+		time.Unix(100000, 0), time.Unix(200000, 0),
+	)
+	require.NoError(t, err)
+	require.Len(t, edges, 1)
+	require.Equal(t, edgeInfo.ChannelID, edges[0].Info.ChannelID)
+
+	// Deleting the channel while marking it as a zombie makes sure it
+	// isn't reported as a new channel anymore.
+	unknownChanID := edgeInfo.ChannelID + 1
+	err = graph.DeleteChannelEdges(false, true, edgeInfo.ChannelID)
+	require.NoError(t, err)
+	err = graph.DeleteChannelEdges(false, true, edgeInfo.ChannelID)
+	require.ErrorIs(t, err, ErrEdgeNotFound)
+
+	dbInfo, _, _, err = graph.FetchChannelEdgesByID(edgeInfo.ChannelID)
+	require.ErrorIs(t, err, ErrZombieEdge)
+	require.Equal(t, edgeInfo.NodeKey1Bytes, dbInfo.NodeKey1Bytes)
+	require.Equal(t, edgeInfo.NodeKey2Bytes, dbInfo.NodeKey2Bytes)
+
+	newChanIDs, err := graph.FilterKnownChanIDs(
+		[]uint64{edgeInfo.ChannelID, unknownChanID},
+	)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{unknownChanID}, newChanIDs)
+
+	numZombies, err := graph.NumZombies()
+	require.NoError(t, err)
+	require.EqualValues(t, 1, numZombies)
+
+	require.NoError(t, graph.MarkEdgeLive(edgeInfo.ChannelID))
+	isZombie, _, _ = graph.IsZombieEdge(edgeInfo.ChannelID)
+	require.False(t, isZombie)
+}
+
+// TestSQLGraphChannelRange tests that only announced channels within the
+// range are returned, grouped by block height.
+func TestSQLGraphChannelRange(t *testing.T) {
+	t.Parallel()
+
+	graph := makeSQLTestGraph(t)
+
+	node1, err := createTestVertex(nil)
+	require.NoError(t, err)
+	node2, err := createTestVertex(nil)
+	require.NoError(t, err)
+
+	chan1, scid1 := createEdge(100, 0, 0, 0, node1, node2)
+	chan2, scid2 := createEdge(100, 1, 0, 1, node1, node2)
+	chan3, scid3 := createEdge(200, 0, 0, 2, node1, node2)
+	chan4, _ := createEdge(300, 0, 0, 3, node1, node2)
+	private, _ := createEdge(200, 1, 0, 4, node1, node2)
+	private.AuthProof = nil
+
+	for _, edge := range []ChannelEdgeInfo{
+		chan1, chan2, chan3, chan4, private,
+	} {
+		edge := edge
+		require.NoError(t, graph.AddChannelEdge(&edge))
+	}
+
+	ranges, err := graph.FilterChannelRange(100, 200)
+	require.NoError(t, err)
+	require.Equal(t, []BlockChannelRange{
+		{
+			Height:   100,
+			Channels: []lnwire.ShortChannelID{scid1, scid2},
+		},
+		{
+			Height:   200,
+			Channels: []lnwire.ShortChannelID{scid3},
+		},
+	}, ranges)
+
+	ranges, err = graph.FilterChannelRange(400, 500)
+	require.NoError(t, err)
+	require.Empty(t, ranges)
+
+	infos, err := graph.FetchChanInfos(
+		[]uint64{scid1.ToUint64(), scid1.ToUint64() + 1},
+	)
+	require.NoError(t, err)
+	require.Len(t, infos, 1)
+	require.Equal(t, scid1.ToUint64(), infos[0].Info.ChannelID)
+}
+
+// TestSQLGraphPathFinding tests that the channels of a node are loaded for
+// path finding on demand and are reloaded once they change.
+func TestSQLGraphPathFinding(t *testing.T) {
+	t.Parallel()
+
+	graph := makeSQLTestGraph(t)
+
+	node1, err := createTestVertex(nil)
+	require.NoError(t, err)
+	require.NoError(t, graph.AddLightningNode(node1))
+	node2, err := createTestVertex(nil)
+	require.NoError(t, err)
+	require.NoError(t, graph.AddLightningNode(node2))
+
+	edgeInfo, edge1, edge2 := createChannelEdge(nil, node1, node2)
+	require.NoError(t, graph.AddChannelEdge(edgeInfo))
+	require.NoError(t, graph.UpdateEdgePolicy(edge1))
+	require.NoError(t, graph.UpdateEdgePolicy(edge2))
+
+	// Nothing is loaded before the first query.
+	require.Zero(t, graph.sqlStore.nodeCache.len())
+
+	fetchChannels := func() []*DirectedChannel {
+		var channels []*DirectedChannel
+		err := graph.ForEachNodeChannel(
+			nil, edgeInfo.NodeKey1Bytes,
+			func(channel *DirectedChannel) error {
+				channels = append(channels, channel)
+				return nil
+			},
+		)
+		require.NoError(t, err)
+
+		return channels
+	}
+
+	channels := fetchChannels()
+	require.Len(t, channels, 1)
+	require.Equal(t, 1, graph.sqlStore.nodeCache.len())
+
+	channel := channels[0]
+	require.Equal(t, edgeInfo.ChannelID, channel.ChannelID)
+	require.True(t, channel.IsNode1)
+	require.Equal(t, edgeInfo.NodeKey2Bytes, [33]byte(channel.OtherNode))
+	require.True(t, channel.OutPolicySet)
+	require.NotNil(t, channel.InPolicy)
+	require.Equal(t, edge2.FeeBaseMSat, channel.InPolicy.FeeBaseMSat)
+	require.Equal(
+		t, edgeInfo.NodeKey1Bytes,
+		[33]byte(channel.InPolicy.ToNodePubKey()),
+	)
+
+	// An updated policy must be visible to the next query.
+	edge2.FeeBaseMSat++
+	require.NoError(t, graph.UpdateEdgePolicy(edge2))
+	channels = fetchChannels()
+	require.Len(t, channels, 1)
+	require.Equal(t, edge2.FeeBaseMSat, channels[0].InPolicy.FeeBaseMSat)
+
+	// Zombie channels aren't used for path finding until they are marked
+	// as live again.
+	err = graph.MarkEdgeZombie(
+		edgeInfo.ChannelID, edgeInfo.NodeKey1Bytes,
+		edgeInfo.NodeKey2Bytes,
+	)
+	require.NoError(t, err)
+	require.Empty(t, fetchChannels())
+
+	require.NoError(t, graph.MarkEdgeLive(edgeInfo.ChannelID))
+	require.Len(t, fetchChannels(), 1)
+
+	features, err := graph.FetchNodeFeatures(edgeInfo.NodeKey1Bytes)
+	require.NoError(t, err)
+	require.True(t, features.HasFeature(lnwire.GossipQueriesRequired))
+
+	// Path finding doesn't need a kv transaction.
+	tx, err := graph.NewPathFindTx()
+	require.NoError(t, err)
+	require.Nil(t, tx)
+}
+
+// TestSQLGraphPruning tests that the graph is pruned and rewound on block
+// connects and disconnects.
+func TestSQLGraphPruning(t *testing.T) {
+	t.Parallel()
+
+	graph := makeSQLTestGraph(t)
+
+	source, err := createTestVertex(nil)
+	require.NoError(t, err)
+	require.NoError(t, graph.SetSourceNode(source))
+	node1, err := createTestVertex(nil)
+	require.NoError(t, err)
+	require.NoError(t, graph.AddLightningNode(node1))
+	node2, err := createTestVertex(nil)
+	require.NoError(t, err)
+	require.NoError(t, graph.AddLightningNode(node2))
+
+	_, _, err = graph.PruneTip()
+	require.ErrorIs(t, err, ErrGraphNeverPruned)
+
+	chan1, _ := createEdge(100, 0, 0, 0, source, node1)
+	require.NoError(t, graph.AddChannelEdge(&chan1))
+	chan2, _ := createEdge(150, 0, 0, 1, node1, node2)
+	require.NoError(t, graph.AddChannelEdge(&chan2))
+
+	// Spending the funding output of the second channel removes it along
+	// with the node that no longer has any channels.
+	blockHash := chainhash.Hash{1}
+	closed, err := graph.PruneGraph(
+		[]*wire.OutPoint{&chan2.ChannelPoint}, &blockHash, 200,
+	)
+	require.NoError(t, err)
+	require.Len(t, closed, 1)
+	require.Equal(t, chan2.ChannelID, closed[0].ChannelID)
+
+	_, err = graph.FetchLightningNode(node2.PubKeyBytes)
+	require.ErrorIs(t, err, ErrGraphNodeNotFound)
+	_, err = graph.FetchLightningNode(node1.PubKeyBytes)
+	require.NoError(t, err)
+
+	tipHash, tipHeight, err := graph.PruneTip()
+	require.NoError(t, err)
+	require.Equal(t, blockHash, *tipHash)
+	require.EqualValues(t, 200, tipHeight)
+
+	chan3, _ := createEdge(300, 0, 0, 2, source, node1)
+	require.NoError(t, graph.AddChannelEdge(&chan3))
+	nextHash := chainhash.Hash{2}
+	_, err = graph.PruneGraph(nil, &nextHash, 300)
+	require.NoError(t, err)
+
+	// Disconnecting the block removes the channels confirmed in it and
+	// rewinds the prune tip.
+	removed, err := graph.DisconnectBlockAtHeight(300)
+	require.NoError(t, err)
+	require.Len(t, removed, 1)
+	require.Equal(t, chan3.ChannelID, removed[0].ChannelID)
+
+	tipHash, tipHeight, err = graph.PruneTip()
+	require.NoError(t, err)
+	require.Equal(t, blockHash, *tipHash)
+	require.EqualValues(t, 200, tipHeight)
+
+	isPublic, err := graph.IsPublicNode(node1.PubKeyBytes)
+	require.NoError(t, err)
+	require.True(t, isPublic)
+}
+
+// TestMigrateGraphToSQL tests that the channel graph of the key-value
+// database is copied to the SQL tables exactly once.
+func TestMigrateGraphToSQL(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	graph, err := MakeTestGraph(t)
+	require.NoError(t, err)
+
+	source, err := createTestVertex(nil)
+	require.NoError(t, err)
+	require.NoError(t, graph.SetSourceNode(source))
+	node1, err := createTestVertex(nil)
+	require.NoError(t, err)
+	require.NoError(t, graph.AddLightningNode(node1))
+	node2, err := createTestVertex(nil)
+	require.NoError(t, err)
+	require.NoError(t, graph.AddLightningNode(node2))
+
+	edgeInfo, edge1, edge2 := createChannelEdge(nil, node1, node2)
+	require.NoError(t, graph.AddChannelEdge(edgeInfo))
+	require.NoError(t, graph.UpdateEdgePolicy(edge1))
+	require.NoError(t, graph.UpdateEdgePolicy(edge2))
+
+	zombie, _ := createEdge(100, 0, 0, 1, node1, node2)
+	err = graph.MarkEdgeZombie(
+		zombie.ChannelID, zombie.NodeKey1Bytes, zombie.NodeKey2Bytes,
+	)
+	require.NoError(t, err)
+
+	blockHash := chainhash.Hash{1}
+	_, err = graph.PruneGraph(nil, &blockHash, 100)
+	require.NoError(t, err)
+
+	db := newTestGraphSQLDB(t)
+	require.NoError(t, ensureNoGraphTombstone(graph.db))
+	require.NoError(t, migrateGraphToSQL(ctx, graph, db))
+
+	// The key-value graph must no longer be used after the migration.
+	err = ensureNoGraphTombstone(graph.db)
+	require.ErrorIs(t, err, ErrGraphMigratedToSQL)
+
+	// A second run must not attempt to insert the graph again.
+	require.NoError(t, migrateGraphToSQL(ctx, graph, db))
+
+	graph.sqlStore = newSQLGraphStore(db)
+
+	dbSource, err := graph.SourceNode()
+	require.NoError(t, err)
+	require.NoError(t, compareNodes(source, dbSource))
+
+	for _, node := range []*LightningNode{node1, node2} {
+		dbNode, err := graph.FetchLightningNode(node.PubKeyBytes)
+		require.NoError(t, err)
+		require.NoError(t, compareNodes(node, dbNode))
+	}
+
+	dbInfo, dbEdge1, dbEdge2, err := graph.FetchChannelEdgesByID(
+		edgeInfo.ChannelID,
+	)
+	require.NoError(t, err)
+	assertEdgeInfoEqual(t, edgeInfo, dbInfo)
+	require.NoError(t, compareEdgePolicies(edge1, dbEdge1))
+	require.NoError(t, compareEdgePolicies(edge2, dbEdge2))
+
+	isZombie, pubKey1, pubKey2 := graph.IsZombieEdge(zombie.ChannelID)
+	require.True(t, isZombie)
+	require.Equal(t, zombie.NodeKey1Bytes, pubKey1)
+	require.Equal(t, zombie.NodeKey2Bytes, pubKey2)
+
+	tipHash, tipHeight, err := graph.PruneTip()
+	require.NoError(t, err)
+	require.Equal(t, blockHash, *tipHash)
+	require.EqualValues(t, 100, tipHeight)
+}
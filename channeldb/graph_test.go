@@ -3271,6 +3271,47 @@ func TestComputeFee(t *testing.T) {
 	}
 }
 
+// TestSetInboundFee tests that setting the inbound fee of a policy only
+// replaces the inbound fee record and keeps all other records of the extra
+// opaque data.
+func TestSetInboundFee(t *testing.T) {
+	// The extra opaque data holds the records with types 1 and 60001,
+	// which come before and after the inbound fee record.
+	otherRecords := []byte{0x01, 0x02, 0xab, 0xcd, 0xfd, 0xea, 0x61, 0x01,
+		0x01}
+	policy := ChannelEdgePolicy{
+		ExtraOpaqueData: otherRecords,
+	}
+
+	assertRecords := func(fee lnwire.Fee) {
+		t.Helper()
+
+		inboundFee, err := policy.InboundFee()
+		require.NoError(t, err)
+		require.Equal(t, fee, inboundFee)
+
+		extraData := lnwire.ExtraOpaqueData(policy.ExtraOpaqueData)
+		typeMap, err := extraData.ExtractRecords()
+		require.NoError(t, err)
+		require.Equal(t, []byte{0xab, 0xcd}, typeMap[1])
+		require.Equal(t, []byte{0x01}, typeMap[60001])
+	}
+
+	fee := lnwire.Fee{BaseFee: -100, FeeRate: -200}
+	require.NoError(t, policy.SetInboundFee(fee))
+	assertRecords(fee)
+
+	// Updating the fee replaces the existing record.
+	fee = lnwire.Fee{BaseFee: 10, FeeRate: 20}
+	require.NoError(t, policy.SetInboundFee(fee))
+	assertRecords(fee)
+
+	// A zero fee only removes the inbound fee record.
+	require.NoError(t, policy.SetInboundFee(lnwire.Fee{}))
+	assertRecords(lnwire.Fee{})
+	require.Equal(t, otherRecords, []byte(policy.ExtraOpaqueData))
+}
+
 // TestBatchedAddChannelEdge asserts that BatchedAddChannelEdge properly
 // executes multiple AddChannelEdge requests in a single txn.
 func TestBatchedAddChannelEdge(t *testing.T) {
@@ -126,5 +126,10 @@ type ForwardingPolicy struct {
 	// per-hop payload of the incoming HTLC's onion packet.
 	TimeLockDelta uint32
 
+	// InboundFee is the fee that must be paid for incoming HTLCs on this
+	// channel, on top of the outbound fee of the outgoing channel. It may
+	// be negative to grant a discount.
+	InboundFee InboundFee
+
 	// TODO(roasbeef): add fee module inside of switch
 }
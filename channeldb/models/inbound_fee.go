@@ -0,0 +1,51 @@
+package models
+
+import "github.com/lightningnetwork/lnd/lnwire"
+
+const (
+	// maxFeeRate is the maximum fee rate in parts per million.
+	maxFeeRate = 1_000_000
+)
+
+// InboundFee is the fee charged for payments that enter a node through a
+// channel. Both the base fee and the fee rate may be negative, in which case
+// the inbound fee acts as a discount on the outbound fee of the outgoing
+// channel.
+type InboundFee struct {
+	// Base is the fixed inbound fee in milli-satoshis.
+	Base int32
+
+	// Rate is the proportional inbound fee in parts per million.
+	Rate int32
+}
+
+// NewInboundFeeFromWire constructs an inbound fee from its wire
+// representation.
+func NewInboundFeeFromWire(fee lnwire.Fee) InboundFee {
+	return InboundFee{
+		Base: fee.BaseFee,
+		Rate: fee.FeeRate,
+	}
+}
+
+// ToWire converts the inbound fee to its wire representation.
+func (i InboundFee) ToWire() lnwire.Fee {
+	return lnwire.Fee{
+		BaseFee: i.Base,
+		FeeRate: i.Rate,
+	}
+}
+
+// CalcFee calculates the inbound fee for the given amount, which is the
+// amount forwarded out of the node including the outbound fee. The returned
+// fee may be negative.
+func (i InboundFee) CalcFee(amt lnwire.MilliSatoshi) int64 {
+	fee := int64(i.Base)
+	rate := int64(i.Rate)
+
+	// Calculate the proportional fee. The division is rounded towards
+	// zero, so that a discount never exceeds the advertised rate.
+	fee += rate * int64(amt) / maxFeeRate
+
+	return fee
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/stretchr/testify/require"
+)
+
+// TestInboundFee tests the calculation of positive and negative inbound fees.
+func TestInboundFee(t *testing.T) {
+	t.Parallel()
+
+	// A positive inbound fee is added to the outbound fee.
+	fee := InboundFee{Base: 5, Rate: 500_000}
+	require.Equal(t, int64(5+500), fee.CalcFee(1000))
+
+	// A negative inbound fee is a discount.
+	fee = InboundFee{Base: -5, Rate: -500_000}
+	require.Equal(t, int64(-5-500), fee.CalcFee(1000))
+
+	// The proportional part is rounded towards zero.
+	fee = InboundFee{Rate: -1}
+	require.Equal(t, int64(0), fee.CalcFee(999_999))
+
+	// The fee survives a round trip through its wire representation.
+	wireFee := lnwire.Fee{BaseFee: -5, FeeRate: 20}
+	require.Equal(t, wireFee, NewInboundFeeFromWire(wireFee).ToWire())
+}
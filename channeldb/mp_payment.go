@@ -213,7 +213,7 @@ type MPPayment struct {
 // state.
 func (m *MPPayment) Terminated() bool {
 	// If the payment is in terminal state, it cannot be updated.
-	return m.Status.updatable() != nil
+	return m.Status.Updatable() != nil
 }
 
 // TerminalInfo returns any HTLC settle info recorded. If no settle info is
@@ -282,7 +282,7 @@ func (m *MPPayment) Registrable() error {
 	// If updating the payment is not allowed, we can't register new HTLCs.
 	// Otherwise, the status must be either `StatusInitiated` or
 	// `StatusInFlight`.
-	if err := m.Status.updatable(); err != nil {
+	if err := m.Status.Updatable(); err != nil {
 		return err
 	}
 
@@ -307,6 +307,62 @@ func (m *MPPayment) Registrable() error {
 	return nil
 }
 
+// ValidateAttempt checks that the given HTLC attempt can be registered for
+// the payment. The payment must accept new HTLCs, the MPP options of the
+// attempt must match those of the in-flight HTLCs and the attempt must not
+// take the total sent amount above the payment amount.
+func (m *MPPayment) ValidateAttempt(attempt *HTLCAttemptInfo) error {
+	// Check if registering a new attempt is allowed.
+	if err := m.Registrable(); err != nil {
+		return err
+	}
+
+	// Make sure any existing shards match the new one with regards to MPP
+	// options.
+	mpp := attempt.Route.FinalHop().MPP
+	for _, h := range m.InFlightHTLCs() {
+		hMpp := h.Route.FinalHop().MPP
+
+		switch {
+		// We tried to register a non-MPP attempt for a MPP payment.
+		case mpp == nil && hMpp != nil:
+			return ErrMPPayment
+
+		// We tried to register a MPP shard for a non-MPP payment.
+		case mpp != nil && hMpp == nil:
+			return ErrNonMPPayment
+
+		// Non-MPP payment, nothing more to validate.
+		case mpp == nil:
+			continue
+		}
+
+		// Check that MPP options match.
+		if mpp.PaymentAddr() != hMpp.PaymentAddr() {
+			return ErrMPPPaymentAddrMismatch
+		}
+
+		if mpp.TotalMsat() != hMpp.TotalMsat() {
+			return ErrMPPTotalAmountMismatch
+		}
+	}
+
+	// If this is a non-MPP attempt, it must match the total amount
+	// exactly.
+	amt := attempt.Route.ReceiverAmt()
+	if mpp == nil && amt != m.Info.Value {
+		return ErrValueMismatch
+	}
+
+	// Ensure we aren't sending more than the total payment amount.
+	sentAmt, _ := m.SentAmt()
+	if sentAmt+amt > m.Info.Value {
+		return ErrValueExceedsAmt
+	}
+
+	return nil
+}
+
 // setState creates and attaches a new MPPaymentState to the payment. It also
 // updates the payment's status based on its current state.
 func (m *MPPayment) setState() error {
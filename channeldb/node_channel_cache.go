@@ -0,0 +1,101 @@
+package channeldb
+
+import (
+	"sync"
+
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/routing/route"
+)
+
+// nodeChannels holds the information of a single node that path finding
+// needs: the node's features and its channels as seen from the node.
+type nodeChannels struct {
+	features *lnwire.FeatureVector
+	channels []*DirectedChannel
+}
+
+// nodeChannelCache is an in-memory cache of the path finding information of
+// the nodes of the channel graph. Unlike the GraphCache, it isn't populated
+// on startup. Instead, the channels of a node are loaded the first time they
+// are requested, and are dropped again whenever the node or one of its
+// channels changes.
+type nodeChannelCache struct {
+	mtx sync.RWMutex
+
+	n     int
+	nodes map[route.Vertex]*nodeChannels
+
+	// version is increased every time an entry is removed. It allows a
+	// caller to detect that the database was modified while it was
+	// loading an entry, in which case the loaded entry must not be
+	// inserted.
+	version uint64
+}
+
+// newNodeChannelCache creates a new nodeChannelCache with maximum capacity of
+// n nodes.
+func newNodeChannelCache(n int) *nodeChannelCache {
+	return &nodeChannelCache{
+		n:     n,
+		nodes: make(map[route.Vertex]*nodeChannels),
+	}
+}
+
+// get returns the entry of the node from the cache, if it exists, along with
+// the current version of the cache.
+func (c *nodeChannelCache) get(node route.Vertex) (*nodeChannels, uint64,
+	bool) {
+
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+
+	entry, ok := c.nodes[node]
+
+	return entry, c.version, ok
+}
+
+// insert adds the entry of the node to the cache, unless an entry was removed
+// since the passed version was obtained. If the cache is at capacity, a random
+// entry is evicted.
+func (c *nodeChannelCache) insert(node route.Vertex, entry *nodeChannels,
+	version uint64) {
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if version != c.version {
+		return
+	}
+
+	if _, ok := c.nodes[node]; ok {
+		c.nodes[node] = entry
+		return
+	}
+
+	if len(c.nodes) >= c.n {
+		for n := range c.nodes {
+			delete(c.nodes, n)
+			break
+		}
+	}
+	c.nodes[node] = entry
+}
+
+// remove deletes the entries of the given nodes from the cache.
+func (c *nodeChannelCache) remove(nodes ...route.Vertex) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	for _, node := range nodes {
+		delete(c.nodes, node)
+	}
+	c.version++
+}
+
+// len returns the number of nodes in the cache.
+func (c *nodeChannelCache) len() int {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+
+	return len(c.nodes)
+}
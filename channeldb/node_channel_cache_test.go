@@ -0,0 +1,56 @@
+package channeldb
+
+import (
+	"testing"
+
+	"github.com/lightningnetwork/lnd/routing/route"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNodeChannelCache checks the behavior of the nodeChannelCache with
+// respect to insertion, eviction, removal and stale inserts.
+func TestNodeChannelCache(t *testing.T) {
+	const cacheSize = 10
+
+	c := newNodeChannelCache(cacheSize)
+
+	vertex := func(i int) route.Vertex {
+		var v route.Vertex
+		v[0] = byte(i)
+
+		return v
+	}
+
+	// Querying the empty cache doesn't return an entry.
+	_, version, ok := c.get(vertex(0))
+	require.False(t, ok)
+
+	// Fill up the cache entirely, no entry should be evicted.
+	for i := 0; i < cacheSize; i++ {
+		c.insert(vertex(i), &nodeChannels{}, version)
+	}
+	require.Equal(t, cacheSize, c.len())
+
+	// Inserting another node evicts exactly one of the prior entries.
+	c.insert(vertex(cacheSize), &nodeChannels{}, version)
+	require.Equal(t, cacheSize, c.len())
+	_, _, ok = c.get(vertex(cacheSize))
+	require.True(t, ok)
+
+	// Removing a node drops its entry and bumps the version.
+	c.remove(vertex(cacheSize))
+	_, newVersion, ok := c.get(vertex(cacheSize))
+	require.False(t, ok)
+	require.NotEqual(t, version, newVersion)
+
+	// An entry that was loaded before the removal must not be inserted,
+	// as it may be stale.
+	c.insert(vertex(cacheSize), &nodeChannels{}, version)
+	_, _, ok = c.get(vertex(cacheSize))
+	require.False(t, ok)
+
+	// With the current version, the insert succeeds.
+	c.insert(vertex(cacheSize), &nodeChannels{}, newVersion)
+	_, _, ok = c.get(vertex(cacheSize))
+	require.True(t, ok)
+}
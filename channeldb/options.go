@@ -1,6 +1,7 @@
 package channeldb
 
 import (
+	"context"
 	"time"
 
 	"github.com/lightningnetwork/lnd/clock"
@@ -82,6 +83,15 @@ type Options struct {
 	// storeFinalHtlcResolutions determines whether to persistently store
 	// the final resolution of incoming htlcs.
 	storeFinalHtlcResolutions bool
+
+	// graphSQLDB is the SQL store of the channel graph. If set, the graph
+	// is migrated to and served from the SQL tables instead of the kv
+	// backend.
+	graphSQLDB BatchedSQLGraphQueries
+
+	// graphSQLCtx is the context the graph is migrated to the SQL tables
+	// with.
+	graphSQLCtx context.Context
 }
 
 // DefaultOptions returns an Options populated with default values.
@@ -220,3 +230,15 @@ func OptionPruneRevocationLog(prune bool) OptionModifier {
 		o.OptionalMiragtionConfig.PruneRevocationLog = prune
 	}
 }
+
+// OptionSetGraphSQLStore sets the SQL store the channel graph is kept in. The
+// graph of the kv backend is migrated to it with the given context when the
+// database is opened.
+func OptionSetGraphSQLStore(ctx context.Context,
+	db BatchedSQLGraphQueries) OptionModifier {
+
+	return func(o *Options) {
+		o.graphSQLDB = db
+		o.graphSQLCtx = ctx
+	}
+}
@@ -170,7 +170,7 @@ func (p *PaymentControl) InitPayment(paymentHash lntypes.Hash,
 		// payment. We'll check the status to decide whether we allow
 		// retrying the payment or return a specific error.
 		case err == nil:
-			if err := paymentStatus.initializable(); err != nil {
+			if err := paymentStatus.Initializable(); err != nil {
 				updateErr = err
 				return nil
 			}
@@ -216,6 +216,17 @@ func (p *PaymentControl) InitPayment(paymentHash lntypes.Hash,
 			return err
 		}
 
+		// Rebalances are marked as such, while any mark left behind by
+		// an earlier attempt of a regular payment is removed.
+		if info.Rebalance {
+			err = bucket.Put(paymentRebalanceKey, []byte{1})
+		} else {
+			err = bucket.Delete(paymentRebalanceKey)
+		}
+		if err != nil {
+			return err
+		}
+
 		// We'll delete any lingering HTLCs to start with, in case we
 		// are initializing a payment that was attempted earlier, but
 		// left in a state where we could retry.
@@ -329,56 +340,11 @@ func (p *PaymentControl) RegisterAttempt(paymentHash lntypes.Hash,
 			return err
 		}
 
-		// Check if registering a new attempt is allowed.
-		if err := payment.Registrable(); err != nil {
+		// Check that the attempt can be added to the payment.
+		if err := payment.ValidateAttempt(attempt); err != nil {
 			return err
 		}
 
-		// Make sure any existing shards match the new one with regards
-		// to MPP options.
-		mpp := attempt.Route.FinalHop().MPP
-		for _, h := range payment.InFlightHTLCs() {
-			hMpp := h.Route.FinalHop().MPP
-
-			switch {
-			// We tried to register a non-MPP attempt for a MPP
-			// payment.
-			case mpp == nil && hMpp != nil:
-				return ErrMPPayment
-
-			// We tried to register a MPP shard for a non-MPP
-			// payment.
-			case mpp != nil && hMpp == nil:
-				return ErrNonMPPayment
-
-			// Non-MPP payment, nothing more to validate.
-			case mpp == nil:
-				continue
-			}
-
-			// Check that MPP options match.
-			if mpp.PaymentAddr() != hMpp.PaymentAddr() {
-				return ErrMPPPaymentAddrMismatch
-			}
-
-			if mpp.TotalMsat() != hMpp.TotalMsat() {
-				return ErrMPPTotalAmountMismatch
-			}
-		}
-
-		// If this is a non-MPP attempt, it must match the total amount
-		// exactly.
-		amt := attempt.Route.ReceiverAmt()
-		if mpp == nil && amt != payment.Info.Value {
-			return ErrValueMismatch
-		}
-
-		// Ensure we aren't sending more than the total payment amount.
-		sentAmt, _ := payment.SentAmt()
-		if sentAmt+amt > payment.Info.Value {
-			return ErrValueExceedsAmt
-		}
-
 		htlcsBucket, err := bucket.CreateBucketIfNotExists(
 			paymentHtlcsBucket,
 		)
@@ -462,7 +428,7 @@ func (p *PaymentControl) updateHtlcKey(paymentHash lntypes.Hash,
 		// We can only update keys of in-flight payments. We allow
 		// updating keys even if the payment has reached a terminal
 		// condition, since the HTLC outcomes must still be updated.
-		if err := p.Status.updatable(); err != nil {
+		if err := p.Status.Updatable(); err != nil {
 			return err
 		}
 
@@ -540,11 +506,15 @@ func (p *PaymentControl) Fail(paymentHash lntypes.Hash,
 			return err
 		}
 
-		// Put the failure reason in the bucket for record keeping.
-		v := []byte{byte(reason)}
-		err = bucket.Put(paymentFailInfoKey, v)
-		if err != nil {
-			return err
+		// Put the failure reason in the bucket for record keeping. A
+		// payment canceled by the user keeps its reason, even if the
+		// payment lifecycle fails it for another reason afterwards.
+		if !isCanceled(bucket) {
+			v := []byte{byte(reason)}
+			err = bucket.Put(paymentFailInfoKey, v)
+			if err != nil {
+				return err
+			}
 		}
 
 		// Retrieve attempt info for the notification, if available.
@@ -562,6 +532,14 @@ func (p *PaymentControl) Fail(paymentHash lntypes.Hash,
 	return payment, updateErr
 }
 
+// isCanceled returns true if the payment in the given bucket was canceled by
+// the user.
+func isCanceled(bucket kvdb.RBucket) bool {
+	b := bucket.Get(paymentFailInfoKey)
+
+	return len(b) == 1 && FailureReason(b[0]) == FailureReasonCanceled
+}
+
 // FetchPayment returns information about a payment from the database.
 func (p *PaymentControl) FetchPayment(paymentHash lntypes.Hash) (
 	*MPPayment, error) {
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"reflect"
 	"testing"
 	"time"
@@ -16,6 +17,7 @@ import (
 	"github.com/lightningnetwork/lnd/kvdb"
 	"github.com/lightningnetwork/lnd/lntypes"
 	"github.com/lightningnetwork/lnd/record"
+	"github.com/lightningnetwork/lnd/routing/route"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -1371,3 +1373,151 @@ func assertPayments(t *testing.T, db *DB, payments []*payment) {
 	// Check that each payment we want to assert exists in the database.
 	require.Equal(t, payments, p)
 }
+
+// TestPaymentControlRebalance checks that rebalances are marked as such, and
+// that the mark is removed if the payment is retried as a regular payment.
+func TestPaymentControlRebalance(t *testing.T) {
+	t.Parallel()
+
+	db, err := MakeTestDB(t)
+	require.NoError(t, err, "unable to init db")
+
+	pControl := NewPaymentControl(db)
+
+	info, _, _, err := genInfo()
+	require.NoError(t, err, "unable to generate htlc message")
+
+	info.Rebalance = true
+	err = pControl.InitPayment(info.PaymentIdentifier, info)
+	require.NoError(t, err)
+
+	payment, err := pControl.FetchPayment(info.PaymentIdentifier)
+	require.NoError(t, err)
+	require.True(t, payment.Info.Rebalance)
+
+	// A failed rebalance may be retried as a regular payment.
+	_, err = pControl.Fail(info.PaymentIdentifier, FailureReasonNoRoute)
+	require.NoError(t, err)
+
+	info.Rebalance = false
+	err = pControl.InitPayment(info.PaymentIdentifier, info)
+	require.NoError(t, err)
+
+	payment, err = pControl.FetchPayment(info.PaymentIdentifier)
+	require.NoError(t, err)
+	require.False(t, payment.Info.Rebalance)
+}
+
+// TestPaymentControlCanceledReason checks that the reason of a payment that
+// was canceled by the user isn't overwritten when the payment is failed again.
+func TestPaymentControlCanceledReason(t *testing.T) {
+	t.Parallel()
+
+	db, err := MakeTestDB(t)
+	require.NoError(t, err, "unable to init db")
+
+	pControl := NewPaymentControl(db)
+
+	info, _, _, err := genInfo()
+	require.NoError(t, err, "unable to generate htlc message")
+
+	err = pControl.InitPayment(info.PaymentIdentifier, info)
+	require.NoError(t, err)
+
+	_, err = pControl.Fail(info.PaymentIdentifier, FailureReasonCanceled)
+	require.NoError(t, err)
+
+	payment, err := pControl.Fail(
+		info.PaymentIdentifier, FailureReasonTimeout,
+	)
+	require.NoError(t, err)
+	require.Equal(t, FailureReasonCanceled, *payment.FailureReason)
+}
+
+// TestPaymentControlQueryFilters checks that payments can be filtered by
+// status, destination, amount and payment hash prefix, and that the payment
+// statistics only count the settled HTLCs of succeeded payments.
+func TestPaymentControlQueryFilters(t *testing.T) {
+	t.Parallel()
+
+	db, err := MakeTestDB(t)
+	require.NoError(t, err)
+
+	pControl := NewPaymentControl(db)
+
+	payments := []*payment{
+		{status: StatusFailed},
+		{status: StatusSucceeded},
+		{status: StatusInFlight},
+	}
+	createTestPayments(t, pControl, payments)
+
+	query := func(q PaymentsQuery) []lntypes.Hash {
+		t.Helper()
+
+		q.MaxPayments = math.MaxUint64
+		resp, err := db.QueryPayments(q)
+		require.NoError(t, err)
+
+		var hashes []lntypes.Hash
+		for _, p := range resp.Payments {
+			hashes = append(hashes, p.Info.PaymentIdentifier)
+		}
+
+		return hashes
+	}
+
+	// Explicit statuses take precedence over IncludeIncomplete.
+	require.Equal(t, []lntypes.Hash{payments[0].id, payments[2].id}, query(
+		PaymentsQuery{
+			Statuses: []PaymentStatus{StatusFailed, StatusInFlight},
+		},
+	))
+
+	// All payments were sent to the same destination.
+	other := route.Vertex{1}
+	require.Len(t, query(PaymentsQuery{
+		IncludeIncomplete: true,
+		Destination:       &vertex,
+	}), 3)
+	require.Empty(t, query(PaymentsQuery{
+		IncludeIncomplete: true,
+		Destination:       &other,
+	}))
+
+	amt := testRoute.ReceiverAmt()
+	require.Len(t, query(PaymentsQuery{
+		IncludeIncomplete: true,
+		MinAmount:         amt,
+		MaxAmount:         amt,
+	}), 3)
+	require.Empty(t, query(PaymentsQuery{
+		IncludeIncomplete: true,
+		MinAmount:         amt + 1,
+	}))
+	require.Empty(t, query(PaymentsQuery{
+		IncludeIncomplete: true,
+		MaxAmount:         amt - 1,
+	}))
+
+	require.Equal(t, []lntypes.Hash{payments[1].id}, query(PaymentsQuery{
+		IncludeIncomplete: true,
+		PaymentHashPrefix: payments[1].id[:4],
+	}))
+
+	stats, err := db.PaymentStats(time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Equal(t, &PaymentStats{
+		NumPayments:  3,
+		NumSucceeded: 1,
+		NumFailed:    1,
+		NumInFlight:  1,
+		TotalSent:    amt,
+		TotalFees:    testRoute.TotalFees(),
+	}, stats)
+
+	// No payments were created in the future.
+	stats, err = db.PaymentStats(time.Now().Add(time.Hour), time.Time{})
+	require.NoError(t, err)
+	require.Equal(t, &PaymentStats{}, stats)
+}
@@ -93,6 +93,11 @@ var (
 	// store information about the reason a payment failed.
 	paymentFailInfoKey = []byte("payment-fail-info")
 
+	// paymentRebalanceKey is a key used in the payment's sub-bucket to
+	// mark the payment as a rebalance between our own channels. It is
+	// only present for rebalances.
+	paymentRebalanceKey = []byte("payment-rebalance")
+
 	// paymentsIndexBucket is the name of the top-level bucket within the
 	// database that stores an index of payment sequence numbers to its
 	// payment hash.
@@ -101,6 +106,12 @@ var (
 	// 	|--...
 	// 	|--<sequence-number>: <payment hash>
 	paymentsIndexBucket = []byte("payments-index-bucket")
+
+	// paymentsTombstoneKey is the key of the marker that is added once
+	// the payments were migrated to the native SQL tables. From then on,
+	// the payments of the key-value database are outdated and must no
+	// longer be used.
+	paymentsTombstoneKey = []byte("payments-migration-tombstone")
 )
 
 var (
@@ -147,7 +158,9 @@ const (
 	// balance to complete the payment.
 	FailureReasonInsufficientBalance FailureReason = 4
 
-	// TODO(halseth): cancel state.
+	// FailureReasonCanceled indicates that the payment was canceled by the
+	// user before it completed.
+	FailureReasonCanceled FailureReason = 5
 
 	// TODO(joostjager): Add failure reasons for:
 	// LocalLiquidityInsufficient, RemoteCapacityInsufficient.
@@ -171,6 +184,8 @@ func (r FailureReason) String() string {
 		return "incorrect_payment_details"
 	case FailureReasonInsufficientBalance:
 		return "insufficient_balance"
+	case FailureReasonCanceled:
+		return "canceled"
 	}
 
 	return "unknown"
@@ -191,6 +206,10 @@ type PaymentCreationInfo struct {
 
 	// PaymentRequest is the full payment request, if any.
 	PaymentRequest []byte
+
+	// Rebalance indicates that this is a circular payment to ourselves
+	// that moves funds between our own channels.
+	Rebalance bool
 }
 
 // htlcBucketKey creates a composite key from prefix and id where the result is
@@ -264,7 +283,14 @@ func fetchCreationInfo(bucket kvdb.RBucket) (*PaymentCreationInfo, error) {
 	}
 
 	r := bytes.NewReader(b)
-	return deserializePaymentCreationInfo(r)
+	info, err := deserializePaymentCreationInfo(r)
+	if err != nil {
+		return nil, err
+	}
+
+	info.Rebalance = bucket.Get(paymentRebalanceKey) != nil
+
+	return info, nil
 }
 
 func fetchPayment(bucket kvdb.RBucket) (*MPPayment, error) {
@@ -478,6 +504,91 @@ type PaymentsQuery struct {
 	// CreationDateEnd, if set, filters out all payments with a creation
 	// date less than or euqal to it.
 	CreationDateEnd time.Time
+
+	// Destination, if set, filters out all payments that don't have an
+	// HTLC attempt to the given node. Payments without any attempts have
+	// no known destination and are filtered out as well.
+	Destination *route.Vertex
+
+	// Statuses, if set, filters out all payments that don't have one of
+	// the given statuses. IncludeIncomplete is ignored if it is set.
+	Statuses []PaymentStatus
+
+	// MinAmount, if set, filters out all payments with an amount less
+	// than it. Fees are not included in the amount of a payment.
+	MinAmount lnwire.MilliSatoshi
+
+	// MaxAmount, if set, filters out all payments with an amount greater
+	// than it. Fees are not included in the amount of a payment.
+	MaxAmount lnwire.MilliSatoshi
+
+	// PaymentHashPrefix, if set, filters out all payments with a payment
+	// hash that doesn't start with it.
+	PaymentHashPrefix []byte
+}
+
+// Matches returns true if the payment passes the status, date, destination,
+// amount and payment hash filters of the query.
+func (q *PaymentsQuery) Matches(payment *MPPayment) bool {
+	// To keep compatibility with the old API, we only return
+	// non-succeeded payments if requested, unless explicit statuses are
+	// requested.
+	switch {
+	case len(q.Statuses) > 0:
+		found := false
+		for _, status := range q.Statuses {
+			if payment.Status == status {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+
+	case payment.Status != StatusSucceeded && !q.IncludeIncomplete:
+		return false
+	}
+
+	// Skip any payments that were created before the specified time.
+	creationTime := payment.Info.CreationTime
+	if !q.CreationDateStart.IsZero() &&
+		creationTime.Before(q.CreationDateStart) {
+
+		return false
+	}
+
+	// Skip any payments that were created after the specified time.
+	if !q.CreationDateEnd.IsZero() &&
+		creationTime.After(q.CreationDateEnd) {
+
+		return false
+	}
+
+	amt := payment.Info.Value
+	if amt < q.MinAmount || (q.MaxAmount != 0 && amt > q.MaxAmount) {
+		return false
+	}
+
+	hash := payment.Info.PaymentIdentifier
+	if !bytes.HasPrefix(hash[:], q.PaymentHashPrefix) {
+		return false
+	}
+
+	if q.Destination == nil {
+		return true
+	}
+
+	for _, htlc := range payment.HTLCs {
+		hops := htlc.Route.Hops
+		if len(hops) > 0 &&
+			hops[len(hops)-1].PubKeyBytes == *q.Destination {
+
+			return true
+		}
+	}
+
+	return false
 }
 
 // PaymentsResponse contains the result of a query to the payments database.
@@ -512,11 +623,7 @@ type PaymentsResponse struct {
 // to a subset of payments by the payments query, containing an offset
 // index and a maximum number of returned payments.
 func (d *DB) QueryPayments(query PaymentsQuery) (PaymentsResponse, error) {
-	var (
-		resp         PaymentsResponse
-		startDateSet = !query.CreationDateStart.IsZero()
-		endDateSet   = !query.CreationDateEnd.IsZero()
-	)
+	var resp PaymentsResponse
 
 	if err := kvdb.View(d, func(tx kvdb.RTx) error {
 		// Get the root payments bucket.
@@ -553,29 +660,9 @@ func (d *DB) QueryPayments(query PaymentsQuery) (PaymentsResponse, error) {
 				return false, err
 			}
 
-			// To keep compatibility with the old API, we only
-			// return non-succeeded payments if requested.
-			if payment.Status != StatusSucceeded &&
-				!query.IncludeIncomplete {
-
-				return false, err
-			}
-
-			// Skip any payments that were created before the
-			// specified time.
-			if startDateSet && payment.Info.CreationTime.Before(
-				query.CreationDateStart,
-			) {
-
-				return false, nil
-			}
-
-			// Skip any payments that were created after the
-			// specified time.
-			if endDateSet && payment.Info.CreationTime.After(
-				query.CreationDateEnd,
-			) {
-
+			// Skip any payments that don't pass the filters of
+			// the query.
+			if !query.Matches(payment) {
 				return false, nil
 			}
 
@@ -652,6 +739,106 @@ func (d *DB) QueryPayments(query PaymentsQuery) (PaymentsResponse, error) {
 	return resp, nil
 }
 
+// PaymentStats holds aggregate statistics of the payments that were created
+// within a time window.
+type PaymentStats struct {
+	// NumPayments is the number of payments in the window, regardless of
+	// their status.
+	NumPayments uint64
+
+	// NumSucceeded is the number of succeeded payments in the window.
+	NumSucceeded uint64
+
+	// NumFailed is the number of failed payments in the window.
+	NumFailed uint64
+
+	// NumInFlight is the number of payments in the window that haven't
+	// reached a terminal state yet.
+	NumInFlight uint64
+
+	// TotalSent is the amount that was sent by the succeeded payments in
+	// the window, excluding fees.
+	TotalSent lnwire.MilliSatoshi
+
+	// TotalFees is the amount of fees that was paid by the succeeded
+	// payments in the window.
+	TotalFees lnwire.MilliSatoshi
+}
+
+// AddPayment adds the given payment to the statistics.
+func (s *PaymentStats) AddPayment(payment *MPPayment) {
+	s.NumPayments++
+
+	switch payment.Status {
+	case StatusSucceeded:
+		s.NumSucceeded++
+
+	case StatusFailed:
+		s.NumFailed++
+		return
+
+	default:
+		s.NumInFlight++
+		return
+	}
+
+	// Only the settled HTLCs of a succeeded payment are counted, as they
+	// are the ones that actually delivered the amount.
+	for _, htlc := range payment.HTLCs {
+		if htlc.Settle == nil {
+			continue
+		}
+
+		s.TotalSent += htlc.Route.ReceiverAmt()
+		s.TotalFees += htlc.Route.TotalFees()
+	}
+}
+
+// PaymentStats returns aggregate statistics of the payments that were created
+// within the given time window. A zero start or end time leaves the window
+// open on that side.
+func (d *DB) PaymentStats(start, end time.Time) (*PaymentStats, error) {
+	query := PaymentsQuery{
+		IncludeIncomplete: true,
+		CreationDateStart: start,
+		CreationDateEnd:   end,
+	}
+
+	stats := &PaymentStats{}
+	err := kvdb.View(d, func(tx kvdb.RTx) error {
+		payments := tx.ReadBucket(paymentsRootBucket)
+		if payments == nil {
+			return nil
+		}
+
+		return payments.ForEach(func(k, _ []byte) error {
+			bucket := payments.NestedReadBucket(k)
+			if bucket == nil {
+				return fmt.Errorf("non bucket element in " +
+					"payments bucket")
+			}
+
+			payment, err := fetchPayment(bucket)
+			if err != nil {
+				return err
+			}
+
+			if query.Matches(payment) {
+				stats.AddPayment(payment)
+			}
+
+			return nil
+		})
+	}, func() {
+		stats = &PaymentStats{}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
 // fetchPaymentWithSequenceNumber get the payment which matches the payment hash
 // *and* sequence number provided from the database. This is required because
 // we previously had more than one payment per hash, so we have multiple indexes
@@ -756,7 +943,7 @@ func (d *DB) DeletePayment(paymentHash lntypes.Hash,
 
 		// If the payment has inflight HTLCs, we cannot safely delete
 		// the payment information, so we return an error.
-		if err := paymentStatus.removable(); err != nil {
+		if err := paymentStatus.Removable(); err != nil {
 			return fmt.Errorf("payment '%v' has inflight HTLCs"+
 				"and therefore cannot be deleted: %w",
 				paymentHash.String(), err)
@@ -862,7 +1049,7 @@ func (d *DB) DeletePayments(failedOnly, failedHtlcsOnly bool) error {
 			// If the payment has inflight HTLCs, we cannot safely
 			// delete the payment information, so we return an nil
 			// to skip it.
-			if err := paymentStatus.removable(); err != nil {
+			if err := paymentStatus.Removable(); err != nil {
 				return nil
 			}
 
@@ -1388,3 +1575,40 @@ func DeserializeRoute(r io.Reader) (route.Route, error) {
 
 	return rt, nil
 }
+
+// TombstonePayments marks the payments of the database as migrated to the
+// native SQL tables, so that they are no longer used. Adding the tombstone
+// more than once is a no-op.
+func (d *DB) TombstonePayments(reason string) error {
+	return kvdb.Update(d, func(tx kvdb.RwTx) error {
+		_, err := CheckMarkerPresent(tx, paymentsTombstoneKey)
+		switch {
+		case err == nil:
+			return nil
+
+		case !errors.Is(err, ErrMarkerNotPresent):
+			return err
+		}
+
+		return AddMarker(tx, paymentsTombstoneKey, []byte(reason))
+	}, func() {})
+}
+
+// EnsureNoPaymentsTombstone returns an error if the payments of the database
+// were migrated to the native SQL tables.
+func (d *DB) EnsureNoPaymentsTombstone() error {
+	return kvdb.View(d, func(tx kvdb.RTx) error {
+		marker, err := CheckMarkerPresent(tx, paymentsTombstoneKey)
+		switch {
+		case errors.Is(err, ErrMarkerNotPresent):
+			return nil
+
+		case err != nil:
+			return err
+		}
+
+		return fmt.Errorf("refusing to use payments, they were "+
+			"migrated to native SQL tables; tombstone reads: %s",
+			string(marker))
+	}, func() {})
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/btcsuite/btcd/wire"
 	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/eventlog"
 	"github.com/lightningnetwork/lnd/subscribe"
 )
 
@@ -17,6 +18,9 @@ type ChannelNotifier struct {
 
 	ntfnServer *subscribe.Server
 
+	// recorder is an optional recorder that persists the events.
+	recorder eventlog.Recorder
+
 	chanDB *channeldb.ChannelStateDB
 }
 
@@ -122,6 +126,20 @@ func (c *ChannelNotifier) SubscribeChannelEvents() (*subscribe.Client, error) {
 	return c.ntfnServer.Subscribe()
 }
 
+// SetEventRecorder sets a recorder that all channel events are passed to, in
+// addition to the subscribers. It must be called before the notifier is
+// started.
+func (c *ChannelNotifier) SetEventRecorder(recorder eventlog.Recorder) {
+	c.recorder = recorder
+}
+
+// record passes the event to the event recorder, if one is set.
+func (c *ChannelNotifier) record(event interface{}) {
+	if c.recorder != nil {
+		c.recorder.Record(event)
+	}
+}
+
 // NotifyPendingOpenChannelEvent notifies the channelEventNotifier goroutine
 // that a new channel is pending. The pending channel is passed as a parameter
 // instead of read from the database because it might not yet have been
@@ -135,6 +153,8 @@ func (c *ChannelNotifier) NotifyPendingOpenChannelEvent(chanPoint wire.OutPoint,
 		PendingChannel: pendingChan,
 	}
 
+	c.record(event)
+
 	if err := c.ntfnServer.SendUpdate(event); err != nil {
 		log.Warnf("Unable to send pending open channel update: %v", err)
 	}
@@ -151,6 +171,8 @@ func (c *ChannelNotifier) NotifyOpenChannelEvent(chanPoint wire.OutPoint) {
 
 	// Send the open event to all channel event subscribers.
 	event := OpenChannelEvent{Channel: channel}
+	c.record(event)
+
 	if err := c.ntfnServer.SendUpdate(event); err != nil {
 		log.Warnf("Unable to send open channel update: %v", err)
 	}
@@ -167,6 +189,8 @@ func (c *ChannelNotifier) NotifyClosedChannelEvent(chanPoint wire.OutPoint) {
 
 	// Send the closed event to all channel event subscribers.
 	event := ClosedChannelEvent{CloseSummary: closeSummary}
+	c.record(event)
+
 	if err := c.ntfnServer.SendUpdate(event); err != nil {
 		log.Warnf("Unable to send closed channel update: %v", err)
 	}
@@ -179,6 +203,8 @@ func (c *ChannelNotifier) NotifyFullyResolvedChannelEvent(
 
 	// Send the resolved event to all channel event subscribers.
 	event := FullyResolvedChannelEvent{ChannelPoint: &chanPoint}
+	c.record(event)
+
 	if err := c.ntfnServer.SendUpdate(event); err != nil {
 		log.Warnf("Unable to send resolved channel update: %v", err)
 	}
@@ -188,6 +214,8 @@ func (c *ChannelNotifier) NotifyFullyResolvedChannelEvent(
 // link has been added to the switch.
 func (c *ChannelNotifier) NotifyActiveLinkEvent(chanPoint wire.OutPoint) {
 	event := ActiveLinkEvent{ChannelPoint: &chanPoint}
+	c.record(event)
+
 	if err := c.ntfnServer.SendUpdate(event); err != nil {
 		log.Warnf("Unable to send active link update: %v", err)
 	}
@@ -197,6 +225,8 @@ func (c *ChannelNotifier) NotifyActiveLinkEvent(chanPoint wire.OutPoint) {
 // channel is active.
 func (c *ChannelNotifier) NotifyActiveChannelEvent(chanPoint wire.OutPoint) {
 	event := ActiveChannelEvent{ChannelPoint: &chanPoint}
+	c.record(event)
+
 	if err := c.ntfnServer.SendUpdate(event); err != nil {
 		log.Warnf("Unable to send active channel update: %v", err)
 	}
@@ -206,6 +236,8 @@ func (c *ChannelNotifier) NotifyActiveChannelEvent(chanPoint wire.OutPoint) {
 // link has been removed from the switch.
 func (c *ChannelNotifier) NotifyInactiveLinkEvent(chanPoint wire.OutPoint) {
 	event := InactiveLinkEvent{ChannelPoint: &chanPoint}
+	c.record(event)
+
 	if err := c.ntfnServer.SendUpdate(event); err != nil {
 		log.Warnf("Unable to send inactive link update: %v", err)
 	}
@@ -215,6 +247,8 @@ func (c *ChannelNotifier) NotifyInactiveLinkEvent(chanPoint wire.OutPoint) {
 // channel is inactive.
 func (c *ChannelNotifier) NotifyInactiveChannelEvent(chanPoint wire.OutPoint) {
 	event := InactiveChannelEvent{ChannelPoint: &chanPoint}
+	c.record(event)
+
 	if err := c.ntfnServer.SendUpdate(event); err != nil {
 		log.Warnf("Unable to send inactive channel update: %v", err)
 	}
@@ -160,6 +160,12 @@ func (c *chanDBRestorer) openChannelShell(backup chanbackup.Single) (
 		chanType |= channeldb.SingleFunderTweaklessBit
 		chanType |= channeldb.SimpleTaprootFeatureBit
 
+	case chanbackup.ZeroFeeCommitmentVersion:
+		chanType = channeldb.ZeroHtlcTxFeeBit
+		chanType |= channeldb.AnchorOutputsBit
+		chanType |= channeldb.SingleFunderTweaklessBit
+		chanType |= channeldb.ZeroFeeCommitmentBit
+
 	default:
 		return nil, fmt.Errorf("unknown Single version: %v", err)
 	}
@@ -0,0 +1,274 @@
+//go:build accountsrpc
+// +build accountsrpc
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/lightningnetwork/lnd/lnrpc/accountsrpc"
+	"github.com/urfave/cli"
+)
+
+// accountsCommands will return the set of commands to enable for accountsrpc
+// builds.
+func accountsCommands() []cli.Command {
+	return []cli.Command{
+		{
+			Name:     "accounts",
+			Category: "Accounts",
+			Usage: "Manage virtual accounts with their own " +
+				"balance and macaroon",
+			Subcommands: []cli.Command{
+				createVirtualAccountCommand,
+				updateVirtualAccountCommand,
+				listVirtualAccountsCommand,
+				virtualAccountInfoCommand,
+				removeVirtualAccountCommand,
+			},
+		},
+	}
+}
+
+func getAccountsClient(ctx *cli.Context) (accountsrpc.AccountsClient,
+	func()) {
+
+	conn := getClientConn(ctx, false)
+	cleanUp := func() {
+		conn.Close()
+	}
+	return accountsrpc.NewAccountsClient(conn), cleanUp
+}
+
+// virtualAccountFlags are the flags that select an account by its ID or label.
+var virtualAccountFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "id",
+		Usage: "the hex encoded ID of the account",
+	},
+	cli.StringFlag{
+		Name:  "label",
+		Usage: "the label of the account, if no ID is given",
+	},
+}
+
+var createVirtualAccountCommand = cli.Command{
+	Name:     "create",
+	Category: "Accounts",
+	Usage:    "Create a new account and bake a macaroon bound to it.",
+	Description: `
+	Create a new virtual account with the given balance and an optional
+	expiration date and label. A macaroon bound to the account is returned.
+
+	Calls made with the macaroon can only spend the balance of the account
+	and only see the invoices and payments of the account. Payments are
+	debited from the balance, including fees, and invoices created with the
+	macaroon are credited to it once they are settled.`,
+	ArgsUsage: "balance_msat",
+	Flags: []cli.Flag{
+		cli.Int64Flag{
+			Name: "expiration_date",
+			Usage: "the unix timestamp in seconds after which " +
+				"the account can no longer be used; 0 means " +
+				"the account never expires",
+		},
+		cli.DurationFlag{
+			Name: "expires_in",
+			Usage: "the duration after which the account " +
+				"expires, e.g. 720h; can't be combined with " +
+				"expiration_date",
+		},
+		cli.StringFlag{
+			Name:  "label",
+			Usage: "an optional, unique label of the account",
+		},
+		cli.StringFlag{
+			Name: "save_to",
+			Usage: "save the macaroon to the given file " +
+				"instead of printing it hex encoded",
+		},
+	},
+	Action: actionDecorator(createVirtualAccount),
+}
+
+func createVirtualAccount(ctx *cli.Context) error {
+	ctxc := getContext()
+
+	if ctx.NArg() != 1 {
+		return cli.ShowCommandHelp(ctx, "create")
+	}
+
+	balance, err := strconv.ParseUint(ctx.Args().First(), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid balance: %w", err)
+	}
+
+	expirationDate := ctx.Int64("expiration_date")
+	if ctx.IsSet("expires_in") {
+		if ctx.IsSet("expiration_date") {
+			return fmt.Errorf("expiration_date and expires_in " +
+				"can't be combined")
+		}
+
+		expirationDate = time.Now().Add(
+			ctx.Duration("expires_in"),
+		).Unix()
+	}
+
+	client, cleanUp := getAccountsClient(ctx)
+	defer cleanUp()
+
+	req := &accountsrpc.CreateAccountRequest{
+		AccountBalanceMsat: balance,
+		ExpirationDate:     expirationDate,
+		Label:              ctx.String("label"),
+	}
+	resp, err := client.CreateAccount(ctxc, req)
+	if err != nil {
+		return err
+	}
+
+	if ctx.IsSet("save_to") {
+		err := os.WriteFile(ctx.String("save_to"), resp.Macaroon, 0644)
+		if err != nil {
+			return fmt.Errorf("unable to save macaroon: %w", err)
+		}
+
+		printRespJSON(resp.Account)
+		fmt.Printf("Macaroon saved to %s\n", ctx.String("save_to"))
+
+		return nil
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
+var updateVirtualAccountCommand = cli.Command{
+	Name:     "update",
+	Category: "Accounts",
+	Usage:    "Update the balance or expiration date of an account.",
+	Flags: append([]cli.Flag{
+		cli.Int64Flag{
+			Name: "balance_msat",
+			Usage: "the new balance of the account in " +
+				"millisatoshis",
+			Value: -1,
+		},
+		cli.Int64Flag{
+			Name: "expiration_date",
+			Usage: "the new unix timestamp in seconds after " +
+				"which the account can no longer be used; 0 " +
+				"removes the expiration date",
+			Value: -1,
+		},
+	}, virtualAccountFlags...),
+	Action: actionDecorator(updateVirtualAccount),
+}
+
+func updateVirtualAccount(ctx *cli.Context) error {
+	ctxc := getContext()
+	client, cleanUp := getAccountsClient(ctx)
+	defer cleanUp()
+
+	req := &accountsrpc.UpdateAccountRequest{
+		Id:                 ctx.String("id"),
+		Label:              ctx.String("label"),
+		AccountBalanceMsat: ctx.Int64("balance_msat"),
+		ExpirationDate:     ctx.Int64("expiration_date"),
+	}
+	resp, err := client.UpdateAccount(ctxc, req)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
+var listVirtualAccountsCommand = cli.Command{
+	Name:     "list",
+	Category: "Accounts",
+	Usage:    "List all accounts.",
+	Action:   actionDecorator(listVirtualAccounts),
+}
+
+func listVirtualAccounts(ctx *cli.Context) error {
+	ctxc := getContext()
+	client, cleanUp := getAccountsClient(ctx)
+	defer cleanUp()
+
+	resp, err := client.ListAccounts(
+		ctxc, &accountsrpc.ListAccountsRequest{},
+	)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
+var virtualAccountInfoCommand = cli.Command{
+	Name:     "info",
+	Category: "Accounts",
+	Usage:    "Show an account together with its ledger.",
+	Flags:    virtualAccountFlags,
+	Action:   actionDecorator(virtualAccountInfo),
+}
+
+func virtualAccountInfo(ctx *cli.Context) error {
+	ctxc := getContext()
+	client, cleanUp := getAccountsClient(ctx)
+	defer cleanUp()
+
+	req := &accountsrpc.AccountInfoRequest{
+		Id:    ctx.String("id"),
+		Label: ctx.String("label"),
+	}
+	resp, err := client.AccountInfo(ctxc, req)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
+var removeVirtualAccountCommand = cli.Command{
+	Name:     "remove",
+	Category: "Accounts",
+	Usage:    "Remove an account.",
+	Description: `
+	Remove an account together with its ledger. Macaroons bound to the
+	account can no longer be used afterwards.`,
+	Flags:  virtualAccountFlags,
+	Action: actionDecorator(removeVirtualAccount),
+}
+
+func removeVirtualAccount(ctx *cli.Context) error {
+	ctxc := getContext()
+	client, cleanUp := getAccountsClient(ctx)
+	defer cleanUp()
+
+	resp, err := client.RemoveAccount(
+		ctxc, &accountsrpc.RemoveAccountRequest{
+			Id:    ctx.String("id"),
+			Label: ctx.String("label"),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
@@ -0,0 +1,11 @@
+//go:build !accountsrpc
+// +build !accountsrpc
+
+package main
+
+import "github.com/urfave/cli"
+
+// accountsCommands will return nil for non-accountsrpc builds.
+func accountsCommands() []cli.Command {
+	return nil
+}
@@ -20,7 +20,7 @@ func getAutopilotClient(ctx *cli.Context) (autopilotrpc.AutopilotClient, func())
 
 var getStatusCommand = cli.Command{
 	Name:        "status",
-	Usage:       "Get the active status of autopilot.",
+	Usage:       "Get the status of autopilot and its channel policies.",
 	Description: "",
 	Action:      actionDecorator(getStatus),
 }
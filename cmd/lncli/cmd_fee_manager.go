@@ -0,0 +1,112 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/lightningnetwork/lnd/lnrpc"
+	"github.com/urfave/cli"
+)
+
+var feeManagerCommand = cli.Command{
+	Name:     "feemanager",
+	Category: "Channels",
+	Usage:    "Interact with the automatic channel fee manager.",
+	Subcommands: []cli.Command{
+		feeManagerStatusCommand,
+		updateFeeManagerCommand,
+	},
+}
+
+var feeManagerStatusCommand = cli.Command{
+	Name:  "status",
+	Usage: "Show the fee manager configuration and its recent updates.",
+	Description: `
+	Show whether the fee manager periodically adjusts the fees of our
+	channels, the strategy and bounds it uses, and the most recent fee
+	updates it made together with the reason for each of them.
+	`,
+	Action: actionDecorator(feeManagerStatus),
+}
+
+func feeManagerStatus(ctx *cli.Context) error {
+	ctxc := getContext()
+	client, cleanUp := getClient(ctx)
+	defer cleanUp()
+
+	resp, err := client.FeeManagerStatus(
+		ctxc, &lnrpc.FeeManagerStatusRequest{},
+	)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
+var updateFeeManagerCommand = cli.Command{
+	Name:  "update",
+	Usage: "Control the fee manager.",
+	Description: `
+	Enable or disable the periodic fee adjustments of the fee manager,
+	switch the strategy it uses, or run a round of fee adjustments right
+	away.
+
+	Example:
+	lncli feemanager update --enable --strategy velocity --run
+	`,
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "enable",
+			Usage: "enable the periodic fee adjustments",
+		},
+		cli.BoolFlag{
+			Name:  "disable",
+			Usage: "disable the periodic fee adjustments",
+		},
+		cli.StringFlag{
+			Name: "strategy",
+			Usage: "the strategy to adjust fees with, either " +
+				"'liquidity' or 'velocity'",
+		},
+		cli.BoolFlag{
+			Name: "run",
+			Usage: "run a round of fee adjustments immediately " +
+				"and show the updates made",
+		},
+	},
+	Action: actionDecorator(updateFeeManager),
+}
+
+func updateFeeManager(ctx *cli.Context) error {
+	ctxc := getContext()
+
+	if ctx.NumFlags() == 0 {
+		_ = cli.ShowCommandHelp(ctx, "update")
+		return nil
+	}
+
+	if ctx.Bool("enable") && ctx.Bool("disable") {
+		return errors.New("cannot set both --enable and --disable")
+	}
+
+	client, cleanUp := getClient(ctx)
+	defer cleanUp()
+
+	resp, err := client.UpdateFeeManager(
+		ctxc, &lnrpc.UpdateFeeManagerRequest{
+			Enable:   ctx.Bool("enable"),
+			Disable:  ctx.Bool("disable"),
+			Strategy: ctx.String("strategy"),
+			Run:      ctx.Bool("run"),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
@@ -5,14 +5,18 @@ import (
 	"encoding/hex"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"net"
 	"strconv"
 	"strings"
+	"time"
 	"unicode"
 
 	"github.com/lightningnetwork/lnd/lncfg"
 	"github.com/lightningnetwork/lnd/lnrpc"
+	"github.com/lightningnetwork/lnd/lnwire"
 	"github.com/lightningnetwork/lnd/macaroons"
+	"github.com/lightningnetwork/lnd/routing/route"
 	"github.com/urfave/cli"
 	"google.golang.org/protobuf/proto"
 	"gopkg.in/macaroon-bakery.v2/bakery"
@@ -38,6 +42,32 @@ var (
 		Usage: "the condition of the custom caveat to add, can be " +
 			"empty if custom caveat doesn't need a value",
 	}
+	macSpendLimitFlag = cli.Uint64Flag{
+		Name: "spend_limit_msat",
+		Usage: "the total amount in millisatoshis the macaroon can " +
+			"send within the spend window",
+	}
+	macSpendWindowFlag = cli.DurationFlag{
+		Name: "spend_window",
+		Usage: "the time window the spend limit applies to, for " +
+			"example 24h",
+		Value: 24 * time.Hour,
+	}
+	macMaxPaymentFlag = cli.Uint64Flag{
+		Name: "max_payment_msat",
+		Usage: "the maximum amount in millisatoshis of a single " +
+			"payment made with the macaroon",
+	}
+	macDestinationsFlag = cli.StringSliceFlag{
+		Name: "allowed_destination",
+		Usage: "the public key of a node the macaroon can send " +
+			"payments to, can be specified multiple times",
+	}
+	macRateLimitFlag = cli.Uint64Flag{
+		Name: "rate_limit",
+		Usage: "the maximum number of calls per minute the macaroon " +
+			"can make",
+	}
 )
 
 var bakeMacaroonCommand = cli.Command{
@@ -47,12 +77,21 @@ var bakeMacaroonCommand = cli.Command{
 		"and restrictions.",
 	ArgsUsage: "[--save_to=] [--timeout=] [--ip_address=] " +
 		"[--custom_caveat_name= [--custom_caveat_condition=]] " +
-		"[--root_key_id=] [--allow_external_permissions] " +
-		"permissions...",
+		"[--spend_limit_msat= [--spend_window=]] " +
+		"[--max_payment_msat=] [--allowed_destination=] " +
+		"[--rate_limit=] [--root_key_id=] " +
+		"[--allow_external_permissions] permissions...",
 	Description: `
 	Bake a new macaroon that grants the provided permissions and
 	optionally adds restrictions (timeout, IP address) to it.
 
+	The spending and rate limits (--spend_limit_msat, --max_payment_msat,
+	--allowed_destination and --rate_limit) are enforced by lnd itself. Their
+	usage is tracked per root key ID, so each macaroon with such limits
+	should be baked with its own --root_key_id. The spending limits apply to
+	the amount of payments and on-chain sends, excluding fees. The usage can
+	be inspected with "lncli getmacaroonusage".
+
 	The new macaroon can either be shown on command line in hex serialized
 	format or it can be saved directly to a file using the --save_to
 	argument.
@@ -85,6 +124,11 @@ var bakeMacaroonCommand = cli.Command{
 		macIPAddressFlag,
 		macCustomCaveatNameFlag,
 		macCustomCaveatConditionFlag,
+		macSpendLimitFlag,
+		macSpendWindowFlag,
+		macMaxPaymentFlag,
+		macDestinationsFlag,
+		macRateLimitFlag,
 		cli.Uint64Flag{
 			Name: "root_key_id",
 			Usage: "the numerical root key ID used to create the " +
@@ -284,6 +328,49 @@ func deleteMacaroonID(ctx *cli.Context) error {
 	return nil
 }
 
+var getMacaroonUsageCommand = cli.Command{
+	Name:      "getmacaroonusage",
+	Category:  "Macaroons",
+	Usage:     "Show the usage of macaroons with spending or rate limits.",
+	ArgsUsage: "root_key_id",
+	Description: `
+	Show how much the macaroons derived from the specified root key ID have
+	spent and how many calls they made. The usage is only tracked for
+	macaroons that carry spending or rate limit caveats. For example:
+
+	lncli getmacaroonusage 1
+	`,
+	Action: actionDecorator(getMacaroonUsage),
+}
+
+func getMacaroonUsage(ctx *cli.Context) error {
+	ctxc := getContext()
+	client, cleanUp := getClient(ctx)
+	defer cleanUp()
+
+	// Validate args length. Only one argument is allowed.
+	if ctx.NArg() != 1 {
+		return cli.ShowCommandHelp(ctx, "getmacaroonusage")
+	}
+
+	rootKeyID, err := strconv.ParseUint(ctx.Args().First(), 10, 64)
+	if err != nil {
+		return fmt.Errorf("root key ID must be a positive integer")
+	}
+
+	resp, err := client.GetMacaroonUsage(
+		ctxc, &lnrpc.GetMacaroonUsageRequest{
+			RootKeyId: rootKeyID,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+	return nil
+}
+
 var listPermissionsCommand = cli.Command{
 	Name:     "listpermissions",
 	Category: "Macaroons",
@@ -427,6 +514,11 @@ var constrainMacaroonCommand = cli.Command{
 		macIPAddressFlag,
 		macCustomCaveatNameFlag,
 		macCustomCaveatConditionFlag,
+		macSpendLimitFlag,
+		macSpendWindowFlag,
+		macMaxPaymentFlag,
+		macDestinationsFlag,
+		macRateLimitFlag,
 	},
 	Action: actionDecorator(constrainMacaroon),
 }
@@ -548,6 +640,59 @@ func applyMacaroonConstraints(ctx *cli.Context,
 		)
 	}
 
+	if ctx.IsSet(macSpendLimitFlag.Name) {
+		macConstraints = append(
+			macConstraints, macaroons.SpendLimitConstraint(
+				lnwire.MilliSatoshi(
+					ctx.Uint64(macSpendLimitFlag.Name),
+				),
+				ctx.Duration(macSpendWindowFlag.Name),
+			),
+		)
+	}
+
+	if ctx.IsSet(macMaxPaymentFlag.Name) {
+		macConstraints = append(
+			macConstraints, macaroons.MaxPaymentConstraint(
+				lnwire.MilliSatoshi(
+					ctx.Uint64(macMaxPaymentFlag.Name),
+				),
+			),
+		)
+	}
+
+	if ctx.IsSet(macDestinationsFlag.Name) {
+		var dests []route.Vertex
+		for _, dest := range ctx.StringSlice(macDestinationsFlag.Name) {
+			vertex, err := route.NewVertexFromStr(dest)
+			if err != nil {
+				return nil, fmt.Errorf("invalid destination "+
+					"%s: %v", dest, err)
+			}
+			dests = append(dests, vertex)
+		}
+
+		macConstraints = append(
+			macConstraints, macaroons.DestinationsConstraint(
+				dests...,
+			),
+		)
+	}
+
+	if ctx.IsSet(macRateLimitFlag.Name) {
+		rateLimit := ctx.Uint64(macRateLimitFlag.Name)
+		if rateLimit == 0 || rateLimit > math.MaxUint32 {
+			return nil, fmt.Errorf("invalid rate limit: %d",
+				rateLimit)
+		}
+
+		macConstraints = append(
+			macConstraints, macaroons.RateLimitConstraint(
+				uint32(rateLimit),
+			),
+		)
+	}
+
 	constrainedMac, err := macaroons.AddConstraints(mac, macConstraints...)
 	if err != nil {
 		return nil, fmt.Errorf("error adding constraints: %v", err)
@@ -100,6 +100,14 @@ var (
 		Usage: "(optional) expresses time preference (range -1 to 1)",
 	}
 
+	splitModeFlag = cli.StringFlag{
+		Name: "split_mode",
+		Usage: "(optional) the strategy used to split the payment " +
+			"into multiple parts, either 'halving' or " +
+			"'mincostflow'",
+		Value: "halving",
+	}
+
 	introductionNodeFlag = cli.StringFlag{
 		Name: "introduction_node",
 		Usage: "(blinded paths) the hex encoded, cleartext node ID " +
@@ -185,7 +193,7 @@ func paymentFlags() []cli.Flag {
 		},
 		dataFlag, inflightUpdatesFlag, maxPartsFlag, jsonFlag,
 		maxShardSizeSatFlag, maxShardSizeMsatFlag, ampFlag,
-		timePrefFlag,
+		timePrefFlag, splitModeFlag,
 	}
 }
 
@@ -577,6 +585,20 @@ func sendPaymentRequest(ctx *cli.Context,
 	// Set time pref.
 	req.TimePref = ctx.Float64(timePrefFlag.Name)
 
+	// Set the split mode.
+	switch ctx.String(splitModeFlag.Name) {
+	case "halving":
+		req.SplitMode = routerrpc.PaymentSplitMode_SPLIT_HALVING
+
+	case "mincostflow":
+		req.SplitMode = routerrpc.PaymentSplitMode_SPLIT_MIN_COST_FLOW
+
+	default:
+		return fmt.Errorf("invalid split mode %v, must be either "+
+			"'halving' or 'mincostflow'",
+			ctx.String(splitModeFlag.Name))
+	}
+
 	// Always print in-flight updates for the table output.
 	printJSON := ctx.Bool(jsonFlag.Name)
 	req.NoInflightUpdates = !ctx.Bool(inflightUpdatesFlag.Name) && printJSON
@@ -650,6 +672,52 @@ func trackPayment(ctx *cli.Context) error {
 	return err
 }
 
+var cancelPaymentCommand = cli.Command{
+	Name:     "cancelpayment",
+	Category: "Payments",
+	Usage:    "Cancel an in-flight payment.",
+	Description: `
+	Stop an in-flight payment specified by the hash argument. No new HTLC
+	attempts are launched for the payment, while the attempts in flight are
+	allowed to resolve. Once they are failed, the payment fails as
+	canceled. Use trackpayment to follow the payment to its final state.
+	`,
+	ArgsUsage: "hash",
+	Action:    actionDecorator(cancelPayment),
+}
+
+func cancelPayment(ctx *cli.Context) error {
+	ctxc := getContext()
+	args := ctx.Args()
+
+	conn := getClientConn(ctx, false)
+	defer conn.Close()
+
+	routerClient := routerrpc.NewRouterClient(conn)
+
+	if !args.Present() {
+		return fmt.Errorf("hash argument missing")
+	}
+
+	hash, err := hex.DecodeString(args.First())
+	if err != nil {
+		return err
+	}
+
+	req := &routerrpc.CancelPaymentRequest{
+		PaymentHash: hash,
+	}
+
+	resp, err := routerClient.CancelPayment(ctxc, req)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
 // printLivePayment receives payment updates from the given stream and either
 // outputs them as json or as a more user-friendly formatted table. The table
 // option uses terminal control codes to rewrite the output. This call
@@ -704,7 +772,8 @@ func printLivePayment(ctxc context.Context,
 
 		// Terminate loop if payments state is final.
 		if payment.Status != lnrpc.Payment_IN_FLIGHT &&
-			payment.Status != lnrpc.Payment_INITIATED {
+			payment.Status != lnrpc.Payment_INITIATED &&
+			payment.Status != lnrpc.Payment_CANCELLING {
 
 			return payment, nil
 		}
@@ -1382,6 +1451,39 @@ var listPaymentsCommand = cli.Command{
 				"payments with creation date less than or " +
 				"equal to it",
 		},
+		cli.StringFlag{
+			Name: "destination",
+			Usage: "if set, only return payments to the node " +
+				"with this hex encoded public key",
+		},
+		cli.StringSliceFlag{
+			Name: "status",
+			Usage: "if set, only return payments with this " +
+				"status (initiated, in_flight, succeeded or " +
+				"failed), can be specified multiple times",
+		},
+		cli.Int64Flag{
+			Name: "min_amt_msat",
+			Usage: "if set, only return payments with an " +
+				"amount greater than or equal to it",
+		},
+		cli.Int64Flag{
+			Name: "max_amt_msat",
+			Usage: "if set, only return payments with an " +
+				"amount less than or equal to it",
+		},
+		cli.StringFlag{
+			Name: "payment_hash_prefix",
+			Usage: "if set, only return payments with a " +
+				"payment hash that starts with this hex " +
+				"encoded prefix",
+		},
+		cli.BoolFlag{
+			Name: "include_stats",
+			Usage: "if set, the number of payments and the " +
+				"amounts sent and paid in fees between the " +
+				"creation dates are returned as well",
+		},
 	},
 	Action: actionDecorator(listPayments),
 }
@@ -1399,6 +1501,40 @@ func listPayments(ctx *cli.Context) error {
 		CountTotalPayments: ctx.Bool("count_total_payments"),
 		CreationDateStart:  ctx.Uint64("creation_date_start"),
 		CreationDateEnd:    ctx.Uint64("creation_date_end"),
+		MinAmountMsat:      ctx.Int64("min_amt_msat"),
+		MaxAmountMsat:      ctx.Int64("max_amt_msat"),
+		IncludeStats:       ctx.Bool("include_stats"),
+	}
+
+	if ctx.IsSet("destination") {
+		dest, err := hex.DecodeString(ctx.String("destination"))
+		if err != nil {
+			return fmt.Errorf("unable to decode destination: %w",
+				err)
+		}
+		req.Destination = dest
+	}
+
+	if ctx.IsSet("payment_hash_prefix") {
+		prefix, err := hex.DecodeString(
+			ctx.String("payment_hash_prefix"),
+		)
+		if err != nil {
+			return fmt.Errorf("unable to decode payment hash "+
+				"prefix: %w", err)
+		}
+		req.PaymentHashPrefix = prefix
+	}
+
+	for _, status := range ctx.StringSlice("status") {
+		name := strings.ToUpper(status)
+		rpcStatus, ok := lnrpc.Payment_PaymentStatus_value[name]
+		if !ok {
+			return fmt.Errorf("unknown payment status %v", status)
+		}
+		req.Statuses = append(
+			req.Statuses, lnrpc.Payment_PaymentStatus(rpcStatus),
+		)
 	}
 
 	payments, err := client.ListPayments(ctxc, req)
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"time"
+
+	"github.com/lightningnetwork/lnd/lnrpc"
+	"github.com/lightningnetwork/lnd/lnrpc/routerrpc"
+	"github.com/urfave/cli"
+)
+
+var rebalanceCommand = cli.Command{
+	Name:     "rebalance",
+	Category: "Payments",
+	Usage: "Move funds between two of our channels using a circular " +
+		"payment.",
+	Description: `
+	Send a payment to ourselves that leaves through one of the given
+	outgoing channels and returns through the incoming channel. This
+	shifts local balance from the outgoing channels to the incoming one,
+	paying routing fees of at most max_fee_ppm of the amount.
+
+	Example:
+	lncli rebalance --outgoing_chan_id 123 --outgoing_chan_id 456 \
+		--incoming_chan_id 789 --amt 100000 --max_fee_ppm 500
+	`,
+	Flags: []cli.Flag{
+		cli.Int64SliceFlag{
+			Name: "outgoing_chan_id",
+			Usage: "short channel id of a channel the funds may " +
+				"leave through. Can be specified multiple " +
+				"times",
+		},
+		cli.Uint64Flag{
+			Name: "incoming_chan_id",
+			Usage: "short channel id of the channel the funds " +
+				"should arrive through",
+		},
+		cli.Int64Flag{
+			Name:  "amt",
+			Usage: "the number of satoshis to move",
+		},
+		cli.Uint64Flag{
+			Name: "max_fee_ppm",
+			Usage: "the maximum routing fee to pay, in parts per " +
+				"million of the amount",
+		},
+		cli.DurationFlag{
+			Name: "timeout",
+			Usage: "the maximum amount of time we should spend " +
+				"trying to rebalance",
+			Value: time.Second * 60,
+		},
+		maxPartsFlag,
+		jsonFlag,
+		inflightUpdatesFlag,
+	},
+	Action: actionDecorator(rebalance),
+}
+
+func rebalance(ctx *cli.Context) error {
+	ctxc := getContext()
+
+	if ctx.NumFlags() == 0 {
+		_ = cli.ShowCommandHelp(ctx, "rebalance")
+		return nil
+	}
+
+	conn := getClientConn(ctx, false)
+	defer conn.Close()
+
+	client := lnrpc.NewLightningClient(conn)
+	routerClient := routerrpc.NewRouterClient(conn)
+
+	timeout := ctx.Duration("timeout")
+	if timeout <= 0 {
+		return errors.New("rebalance timeout must be greater than " +
+			"zero")
+	}
+
+	var outgoing []uint64
+	for _, chanID := range ctx.Int64Slice("outgoing_chan_id") {
+		outgoing = append(outgoing, uint64(chanID))
+	}
+
+	printJSON := ctx.Bool(jsonFlag.Name)
+	req := &routerrpc.RebalanceRequest{
+		OutgoingChanIds: outgoing,
+		IncomingChanId:  ctx.Uint64("incoming_chan_id"),
+		Amt:             ctx.Int64("amt"),
+		MaxFeePpm:       uint32(ctx.Uint64("max_fee_ppm")),
+		TimeoutSeconds:  int32(timeout.Seconds()),
+		MaxParts:        uint32(ctx.Uint(maxPartsFlag.Name)),
+		NoInflightUpdates: !ctx.Bool(inflightUpdatesFlag.Name) &&
+			printJSON,
+	}
+
+	stream, err := routerClient.Rebalance(ctxc, req)
+	if err != nil {
+		return err
+	}
+
+	finalState, err := printLivePayment(ctxc, stream, client, printJSON)
+	if err != nil {
+		return err
+	}
+
+	if finalState.Status != lnrpc.Payment_SUCCEEDED {
+		return errors.New(finalState.Status.String())
+	}
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lightningnetwork/lnd/lnrpc"
+	"github.com/urfave/cli"
+)
+
+// ledgerEntryPrefix is the prefix of the ledger entry type enum values, which
+// is omitted in the CSV export.
+const ledgerEntryPrefix = "LEDGER_ENTRY_"
+
+// ledgerCSVHeader is the header row of the CSV export of the ledger.
+var ledgerCSVHeader = []string{
+	"timestamp", "type", "amount_msat", "txid", "payment_hash",
+	"channel_point", "chan_id", "note",
+}
+
+var reportCommand = cli.Command{
+	Name:     "report",
+	Category: "Payments",
+	Usage:    "Create a ledger of the node activity in a time range.",
+	Description: `
+	Create a ledger of the activity of the node in a time range. The ledger
+	covers channel opens and closes, sweeps, on-chain sends and receives,
+	invoice receipts, payments, rebalances and the forwarding fees earned.
+
+	Every entry carries its amount in millisatoshis, positive if the
+	balance of the node grew and negative if it shrank, and references the
+	transaction or payment hash it stems from.
+
+	The start and end times are expressed in seconds since the Unix epoch.
+	Alternatively negative time ranges can be used, e.g. "-3d". Supports
+	s(seconds), m(minutes), h(ours), d(ays), w(eeks), M(onths), y(ears).
+	Month equals 30.44 days, year equals 365.25 days.
+
+	If --start_time isn't provided, the ledger starts with the first
+	activity of the node. If --end_time isn't provided, the current time is
+	used.
+
+	Example:
+	lncli report --start_time -1M --csv > ledger.csv
+	`,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name: "start_time",
+			Usage: "the start of the time range as unix " +
+				`timestamp or relative e.g. "-1w"`,
+		},
+		cli.StringFlag{
+			Name: "end_time",
+			Usage: "the end of the time range as unix " +
+				`timestamp or relative e.g. "-1d"`,
+		},
+		cli.BoolFlag{
+			Name: "csv",
+			Usage: "print the entries as CSV instead of the JSON " +
+				"report",
+		},
+	},
+	Action: actionDecorator(report),
+}
+
+func report(ctx *cli.Context) error {
+	ctxc := getContext()
+
+	var (
+		startTime, endTime uint64
+		err                error
+	)
+	now := time.Now()
+
+	if ctx.IsSet("start_time") {
+		startTime, err = parseTime(ctx.String("start_time"), now)
+		if err != nil {
+			return fmt.Errorf("unable to decode start_time: %w",
+				err)
+		}
+	}
+
+	if ctx.IsSet("end_time") {
+		endTime, err = parseTime(ctx.String("end_time"), now)
+		if err != nil {
+			return fmt.Errorf("unable to decode end_time: %w", err)
+		}
+	}
+
+	client, cleanUp := getClient(ctx)
+	defer cleanUp()
+
+	resp, err := client.AccountingReport(
+		ctxc, &lnrpc.AccountingReportRequest{
+			StartTime: int64(startTime),
+			EndTime:   int64(endTime),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	if ctx.Bool("csv") {
+		return writeLedgerCSV(os.Stdout, resp.Entries)
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
+// writeLedgerCSV writes the ledger entries to w as CSV, with the timestamps
+// formatted as RFC 3339 in UTC.
+func writeLedgerCSV(w io.Writer, entries []*lnrpc.LedgerEntry) error {
+	csvWriter := csv.NewWriter(w)
+	if err := csvWriter.Write(ledgerCSVHeader); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		entryType := strings.TrimPrefix(
+			entry.Type.String(), ledgerEntryPrefix,
+		)
+		timestamp := time.Unix(entry.Timestamp, 0).UTC()
+
+		var chanID string
+		if entry.ChanId != 0 {
+			chanID = strconv.FormatUint(entry.ChanId, 10)
+		}
+
+		err := csvWriter.Write([]string{
+			timestamp.Format(time.RFC3339),
+			strings.ToLower(entryType),
+			strconv.FormatInt(entry.AmountMsat, 10),
+			entry.Txid,
+			entry.PaymentHash,
+			entry.ChannelPoint,
+			chanID,
+			entry.Note,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+
+	return csvWriter.Error()
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/lightningnetwork/lnd/lnrpc"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWriteLedgerCSV tests that the ledger entries are exported as CSV.
+func TestWriteLedgerCSV(t *testing.T) {
+	t.Parallel()
+
+	entries := []*lnrpc.LedgerEntry{{
+		Timestamp:    1_700_000_000,
+		Type:         lnrpc.LedgerEntryType_LEDGER_ENTRY_SWEEP_FEE,
+		AmountMsat:   -200_000,
+		Txid:         "abcd",
+		ChannelPoint: "abcd:1",
+		Note:         "sweep, anchor",
+	}, {
+		Timestamp:  1_700_000_060,
+		Type:       lnrpc.LedgerEntryType_LEDGER_ENTRY_FORWARD_FEE,
+		AmountMsat: 21,
+		ChanId:     123,
+	}}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeLedgerCSV(&buf, entries))
+
+	expected := "timestamp,type,amount_msat,txid,payment_hash," +
+		"channel_point,chan_id,note\n" +
+		"2023-11-14T22:13:20Z,sweep_fee,-200000,abcd,,abcd:1,," +
+		"\"sweep, anchor\"\n" +
+		"2023-11-14T22:14:20Z,forward_fee,21,,,,123,\n"
+	require.Equal(t, expected, buf.String())
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lightningnetwork/lnd/lnrpc"
+	"github.com/urfave/cli"
+)
+
+// webhookEventPrefix is the prefix of the webhook event type enum values,
+// which is omitted on the command line.
+const webhookEventPrefix = "WEBHOOK_EVENT_"
+
+// parseWebhookEvents parses a comma separated list of webhook event names
+// such as invoice_settled,payment_failed.
+func parseWebhookEvents(events string) ([]lnrpc.WebhookEventType, error) {
+	if events == "" {
+		return nil, nil
+	}
+
+	var eventTypes []lnrpc.WebhookEventType
+	for _, name := range strings.Split(events, ",") {
+		name = strings.TrimSpace(name)
+		key := webhookEventPrefix + strings.ToUpper(name)
+
+		eventType, ok := lnrpc.WebhookEventType_value[key]
+		if !ok || eventType == 0 {
+			return nil, fmt.Errorf("unknown webhook event %v", name)
+		}
+
+		eventTypes = append(
+			eventTypes, lnrpc.WebhookEventType(eventType),
+		)
+	}
+
+	return eventTypes, nil
+}
+
+var addWebhookCommand = cli.Command{
+	Name:      "addwebhook",
+	Category:  "Webhooks",
+	Usage:     "Register an HTTP endpoint that node events are posted to.",
+	ArgsUsage: "url",
+	Description: `
+	Register an HTTP endpoint that node events are delivered to. Every
+	event is posted as a JSON document. The X-Lnd-Webhook-Signature header
+	holds sha256=<hex encoded HMAC-SHA256 of <timestamp>.<payload>> keyed
+	with the secret of the endpoint, where timestamp is the value of the
+	X-Lnd-Webhook-Timestamp header.
+
+	Failed deliveries are retried with an exponential backoff and moved to
+	the dead-letter queue once they failed too often.
+
+	Valid events are invoice_settled, payment_succeeded, payment_failed,
+	channel_opened, channel_closed, htlc_failed and backup_changed.
+
+	Example:
+	lncli addwebhook --events invoice_settled,payment_succeeded https://example.com/hook
+	`,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name: "events",
+			Usage: "a comma separated list of the events to " +
+				"deliver; all events are delivered if not set",
+		},
+		cli.StringFlag{
+			Name: "secret",
+			Usage: "the hex encoded secret to sign the payloads " +
+				"with; a random secret is generated if not set",
+		},
+	},
+	Action: actionDecorator(addWebhook),
+}
+
+func addWebhook(ctx *cli.Context) error {
+	ctxc := getContext()
+
+	if ctx.NArg() != 1 {
+		return cli.ShowCommandHelp(ctx, "addwebhook")
+	}
+
+	events, err := parseWebhookEvents(ctx.String("events"))
+	if err != nil {
+		return err
+	}
+
+	secret, err := hex.DecodeString(ctx.String("secret"))
+	if err != nil {
+		return fmt.Errorf("unable to decode secret: %w", err)
+	}
+
+	client, cleanUp := getClient(ctx)
+	defer cleanUp()
+
+	resp, err := client.AddWebhook(ctxc, &lnrpc.AddWebhookRequest{
+		Url:    ctx.Args().First(),
+		Events: events,
+		Secret: secret,
+	})
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
+var removeWebhookCommand = cli.Command{
+	Name:      "removewebhook",
+	Category:  "Webhooks",
+	Usage:     "Remove a webhook endpoint.",
+	ArgsUsage: "id",
+	Description: `
+	Remove a webhook endpoint together with its pending and failed
+	deliveries. Endpoints configured in lnd.conf can only be removed from
+	the configuration.
+	`,
+	Action: actionDecorator(removeWebhook),
+}
+
+func removeWebhook(ctx *cli.Context) error {
+	ctxc := getContext()
+
+	if ctx.NArg() != 1 {
+		return cli.ShowCommandHelp(ctx, "removewebhook")
+	}
+
+	id, err := strconv.ParseUint(ctx.Args().First(), 10, 64)
+	if err != nil {
+		return fmt.Errorf("unable to parse id: %w", err)
+	}
+
+	client, cleanUp := getClient(ctx)
+	defer cleanUp()
+
+	resp, err := client.RemoveWebhook(ctxc, &lnrpc.RemoveWebhookRequest{
+		Id: id,
+	})
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
+var listWebhooksCommand = cli.Command{
+	Name:     "listwebhooks",
+	Category: "Webhooks",
+	Usage:    "List the registered webhook endpoints.",
+	Action:   actionDecorator(listWebhooks),
+}
+
+func listWebhooks(ctx *cli.Context) error {
+	ctxc := getContext()
+	client, cleanUp := getClient(ctx)
+	defer cleanUp()
+
+	resp, err := client.ListWebhooks(ctxc, &lnrpc.ListWebhooksRequest{})
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
+var listWebhookDeliveriesCommand = cli.Command{
+	Name:     "listwebhookdeliveries",
+	Category: "Webhooks",
+	Usage:    "List the deliveries of node events to webhook endpoints.",
+	Description: `
+	List the pending, successful or failed deliveries of node events to
+	the webhook endpoints. Failed deliveries are in the dead-letter queue
+	and can be retried with retrywebhookdelivery.
+	`,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name: "state",
+			Usage: "the state of the deliveries to list, either " +
+				"'pending', 'succeeded' or 'failed'",
+			Value: "failed",
+		},
+		cli.Uint64Flag{
+			Name:  "webhook_id",
+			Usage: "only list the deliveries to this endpoint",
+		},
+	},
+	Action: actionDecorator(listWebhookDeliveries),
+}
+
+func listWebhookDeliveries(ctx *cli.Context) error {
+	ctxc := getContext()
+
+	var state lnrpc.WebhookDeliveryState
+	switch ctx.String("state") {
+	case "pending":
+		state = lnrpc.WebhookDeliveryState_WEBHOOK_DELIVERY_PENDING
+
+	case "succeeded":
+		state = lnrpc.WebhookDeliveryState_WEBHOOK_DELIVERY_SUCCEEDED
+
+	case "failed":
+		state = lnrpc.WebhookDeliveryState_WEBHOOK_DELIVERY_FAILED
+
+	default:
+		return fmt.Errorf("unknown delivery state %v",
+			ctx.String("state"))
+	}
+
+	client, cleanUp := getClient(ctx)
+	defer cleanUp()
+
+	resp, err := client.ListWebhookDeliveries(
+		ctxc, &lnrpc.ListWebhookDeliveriesRequest{
+			State:     state,
+			WebhookId: ctx.Uint64("webhook_id"),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
+var retryWebhookDeliveryCommand = cli.Command{
+	Name:      "retrywebhookdelivery",
+	Category:  "Webhooks",
+	Usage:     "Retry a delivery from the dead-letter queue.",
+	ArgsUsage: "delivery_id",
+	Description: `
+	Move a failed delivery from the dead-letter queue back to the pending
+	deliveries, to be attempted again right away.
+	`,
+	Action: actionDecorator(retryWebhookDelivery),
+}
+
+func retryWebhookDelivery(ctx *cli.Context) error {
+	ctxc := getContext()
+
+	if ctx.NArg() != 1 {
+		return cli.ShowCommandHelp(ctx, "retrywebhookdelivery")
+	}
+
+	id, err := strconv.ParseUint(ctx.Args().First(), 10, 64)
+	if err != nil {
+		return fmt.Errorf("unable to parse delivery_id: %w", err)
+	}
+
+	client, cleanUp := getClient(ctx)
+	defer cleanUp()
+
+	resp, err := client.RetryWebhookDelivery(
+		ctxc, &lnrpc.RetryWebhookDeliveryRequest{
+			DeliveryId: id,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
@@ -2092,6 +2092,21 @@ var updateChannelPolicyCommand = cli.Command{
 				"applied to all forwarded HTLCs. If unset, " +
 				"the max HTLC is left unchanged",
 		},
+		cli.Int64Flag{
+			Name: "inbound_base_fee_msat",
+			Usage: "the base fee in milli-satoshis that will be " +
+				"charged for each HTLC coming in through " +
+				"the channel, negative values are discounts. " +
+				"If neither inbound flag is set, the inbound " +
+				"fee is left unchanged",
+		},
+		cli.Int64Flag{
+			Name: "inbound_fee_rate_ppm",
+			Usage: "the fee rate in parts per million that will " +
+				"be charged proportionally based on the value " +
+				"of each HTLC coming in through the channel, " +
+				"negative values are discounts",
+		},
 		cli.StringFlag{
 			Name: "chan_point",
 			Usage: "the channel which this policy update should " +
@@ -2237,6 +2252,29 @@ func updateChannelPolicy(ctx *cli.Context) error {
 		req.MinHtlcMsatSpecified = true
 	}
 
+	if ctx.IsSet("inbound_base_fee_msat") ||
+		ctx.IsSet("inbound_fee_rate_ppm") {
+
+		inboundBaseFee := ctx.Int64("inbound_base_fee_msat")
+		if inboundBaseFee < math.MinInt32 ||
+			inboundBaseFee > math.MaxInt32 {
+
+			return errors.New("inbound_base_fee_msat out of range")
+		}
+
+		inboundFeeRate := ctx.Int64("inbound_fee_rate_ppm")
+		if inboundFeeRate < math.MinInt32 ||
+			inboundFeeRate > math.MaxInt32 {
+
+			return errors.New("inbound_fee_rate_ppm out of range")
+		}
+
+		req.InboundFee = &lnrpc.InboundFee{
+			BaseFeeMsat: int32(inboundBaseFee),
+			FeeRatePpm:  int32(inboundFeeRate),
+		}
+	}
+
 	if chanPoint != nil {
 		req.Scope = &lnrpc.PolicyUpdateRequest_ChanPoint{
 			ChanPoint: chanPoint,
@@ -2277,6 +2315,117 @@ func updateChannelPolicy(ctx *cli.Context) error {
 	return nil
 }
 
+var updateChanParamsCommand = cli.Command{
+	Name:      "updatechanparams",
+	Category:  "Channels",
+	Usage:     "Change the parameters of an existing channel.",
+	ArgsUsage: "chan_point",
+	Description: `
+	Changes the parameters of an existing channel through a dynamic
+	commitment negotiation with the remote peer, without closing and
+	reopening the channel. Both peers must support the dynamic-commitments
+	feature. The channel is made quiescent first: no new HTLCs are added
+	and the pending ones must be resolved within a minute, otherwise the
+	command fails.
+
+	Only the parameters that are set are changed. The commitment type can
+	only be upgraded, from legacy to tweakless, or from legacy or tweakless
+	to anchors. Upgrades to taproot channels are not supported, as they
+	require a new funding output. Channel points are encoded as:
+	funding_txid:output_index
+	`,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name: "chan_point",
+			Usage: "the channel whose parameters should be " +
+				"changed. Takes the form of txid:output_index",
+		},
+		cli.Uint64Flag{
+			Name: "dust_limit_sat",
+			Usage: "the new dust limit of our commitment " +
+				"transaction in satoshis",
+		},
+		cli.Uint64Flag{
+			Name: "max_accepted_htlcs",
+			Usage: "the new maximum number of HTLCs the remote " +
+				"peer may offer to us",
+		},
+		cli.Uint64Flag{
+			Name: "csv_delay",
+			Usage: "the new number of blocks the remote peer's " +
+				"funds are delayed by if it force closes the " +
+				"channel",
+		},
+		cli.StringFlag{
+			Name: "channel_type",
+			Usage: fmt.Sprintf("the new commitment type of the "+
+				"channel, one of: %s | %s", channelTypeTweakless,
+				channelTypeAnchors),
+		},
+	},
+	Action: actionDecorator(updateChanParams),
+}
+
+func updateChanParams(ctx *cli.Context) error {
+	ctxc := getContext()
+	args := ctx.Args()
+
+	var chanPointStr string
+	switch {
+	case ctx.IsSet("chan_point"):
+		chanPointStr = ctx.String("chan_point")
+	case args.Present():
+		chanPointStr = args.First()
+	default:
+		return fmt.Errorf("chan_point argument missing")
+	}
+
+	chanPoint, err := parseChanPoint(chanPointStr)
+	if err != nil {
+		return fmt.Errorf("unable to parse chan_point: %v", err)
+	}
+
+	req := &lnrpc.UpdateChanParamsRequest{
+		ChanPoint:    chanPoint,
+		DustLimitSat: ctx.Uint64("dust_limit_sat"),
+	}
+
+	maxHtlcs := ctx.Uint64("max_accepted_htlcs")
+	if maxHtlcs > math.MaxUint16 {
+		return fmt.Errorf("max_accepted_htlcs is too large")
+	}
+	req.MaxAcceptedHtlcs = uint32(maxHtlcs)
+
+	csvDelay := ctx.Uint64("csv_delay")
+	if csvDelay > math.MaxUint16 {
+		return fmt.Errorf("csv_delay is too large")
+	}
+	req.CsvDelay = uint32(csvDelay)
+
+	switch ctx.String("channel_type") {
+	case "":
+	case channelTypeTweakless:
+		req.CommitmentType = lnrpc.CommitmentType_STATIC_REMOTE_KEY
+	case channelTypeAnchors:
+		req.CommitmentType = lnrpc.CommitmentType_ANCHORS
+	default:
+		return fmt.Errorf("unsupported channel type %v",
+			ctx.String("channel_type"))
+	}
+
+	client, cleanUp := getClient(ctx)
+	defer cleanUp()
+
+	resp, err := client.UpdateChanParams(ctxc, req)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
 var fishCompletionCommand = cli.Command{
 	Name:   "fish-completion",
 	Hidden: true,
@@ -484,6 +484,14 @@ func main() {
 		verifyMessageCommand,
 		feeReportCommand,
 		updateChannelPolicyCommand,
+		updateChanParamsCommand,
+		feeManagerCommand,
+		reportCommand,
+		addWebhookCommand,
+		removeWebhookCommand,
+		listWebhooksCommand,
+		listWebhookDeliveriesCommand,
+		retryWebhookDeliveryCommand,
 		forwardingHistoryCommand,
 		exportChanBackupCommand,
 		verifyChanBackupCommand,
@@ -491,10 +499,13 @@ func main() {
 		bakeMacaroonCommand,
 		listMacaroonIDsCommand,
 		deleteMacaroonIDCommand,
+		getMacaroonUsageCommand,
 		listPermissionsCommand,
 		printMacaroonCommand,
 		constrainMacaroonCommand,
 		trackPaymentCommand,
+		cancelPaymentCommand,
+		rebalanceCommand,
 		versionCommand,
 		profileSubCommand,
 		getStateCommand,
@@ -515,6 +526,8 @@ func main() {
 	app.Commands = append(app.Commands, wtclientCommands()...)
 	app.Commands = append(app.Commands, devCommands()...)
 	app.Commands = append(app.Commands, peersCommands()...)
+	app.Commands = append(app.Commands, nwcCommands()...)
+	app.Commands = append(app.Commands, accountsCommands()...)
 	app.Commands = append(app.Commands, chainCommands()...)
 
 	if err := app.Run(os.Args); err != nil {
@@ -0,0 +1,184 @@
+//go:build nwcrpc
+// +build nwcrpc
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/lightningnetwork/lnd/lnrpc/nwcrpc"
+	"github.com/urfave/cli"
+)
+
+// nwcCommands will return the set of commands to enable for nwcrpc builds.
+func nwcCommands() []cli.Command {
+	return []cli.Command{
+		{
+			Name:     "nwc",
+			Category: "Nostr Wallet Connect",
+			Usage: "Manage the Nostr Wallet Connect connections of " +
+				"client apps",
+			Subcommands: []cli.Command{
+				addNWCConnectionCommand,
+				listNWCConnectionsCommand,
+				removeNWCConnectionCommand,
+			},
+		},
+	}
+}
+
+func getNWCClient(ctx *cli.Context) (nwcrpc.NostrWalletConnectClient,
+	func()) {
+
+	conn := getClientConn(ctx, false)
+	cleanUp := func() {
+		conn.Close()
+	}
+	return nwcrpc.NewNostrWalletConnectClient(conn), cleanUp
+}
+
+var addNWCConnectionCommand = cli.Command{
+	Name:     "addconnection",
+	Category: "Nostr Wallet Connect",
+	Usage:    "Add a new connection for a client app.",
+	Description: `
+	Create a new Nostr Wallet Connect connection and print the connection
+	URI that needs to be passed to the client app.
+
+	The requests of the client app are executed with a macaroon that only
+	grants the permissions the allowed methods need and that enforces the
+	budget and rate limits of the connection.
+
+	The connection URI contains the secret of the client app and is only
+	shown once. Anyone who knows it can use the connection, so it must be
+	treated like a macaroon.`,
+	ArgsUsage: "name",
+	Flags: []cli.Flag{
+		cli.StringSliceFlag{
+			Name: "method",
+			Usage: "a NIP-47 method the client app may call, one " +
+				"of pay_invoice, make_invoice, " +
+				"lookup_invoice, get_balance or " +
+				"list_transactions. Can be set multiple " +
+				"times, if not set all methods are allowed",
+		},
+		cli.Uint64Flag{
+			Name: "budget_msat",
+			Usage: "the amount in millisatoshis the client app " +
+				"may spend within the budget renewal period; " +
+				"0 means no limit",
+		},
+		cli.DurationFlag{
+			Name: "budget_renewal",
+			Usage: "the period after which the budget renews, " +
+				"e.g. 24h or 720h",
+		},
+		cli.Uint64Flag{
+			Name: "max_payment_msat",
+			Usage: "the maximum amount in millisatoshis of a " +
+				"single payment; 0 means no limit",
+		},
+		cli.Uint64Flag{
+			Name: "max_requests_per_minute",
+			Usage: "the maximum number of requests per minute; " +
+				"0 means no limit",
+		},
+	},
+	Action: actionDecorator(addNWCConnection),
+}
+
+func addNWCConnection(ctx *cli.Context) error {
+	ctxc := getContext()
+
+	if ctx.NArg() != 1 {
+		return cli.ShowCommandHelp(ctx, "addconnection")
+	}
+
+	client, cleanUp := getNWCClient(ctx)
+	defer cleanUp()
+
+	req := &nwcrpc.AddConnectionRequest{
+		Name:       ctx.Args().First(),
+		Methods:    ctx.StringSlice("method"),
+		BudgetMsat: ctx.Uint64("budget_msat"),
+		BudgetRenewalSecs: uint64(
+			ctx.Duration("budget_renewal").Seconds(),
+		),
+		MaxPaymentMsat: ctx.Uint64("max_payment_msat"),
+	}
+
+	maxRequests := ctx.Uint64("max_requests_per_minute")
+	if maxRequests > uint64(^uint32(0)) {
+		return fmt.Errorf("max_requests_per_minute too large")
+	}
+	req.MaxRequestsPerMinute = uint32(maxRequests)
+
+	resp, err := client.AddConnection(ctxc, req)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
+var listNWCConnectionsCommand = cli.Command{
+	Name:     "listconnections",
+	Category: "Nostr Wallet Connect",
+	Usage:    "List all connections.",
+	Action:   actionDecorator(listNWCConnections),
+}
+
+func listNWCConnections(ctx *cli.Context) error {
+	ctxc := getContext()
+	client, cleanUp := getNWCClient(ctx)
+	defer cleanUp()
+
+	resp, err := client.ListConnections(
+		ctxc, &nwcrpc.ListConnectionsRequest{},
+	)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
+var removeNWCConnectionCommand = cli.Command{
+	Name:     "removeconnection",
+	Category: "Nostr Wallet Connect",
+	Usage:    "Remove a connection and revoke its macaroon.",
+	Description: `
+	Remove the connection of the client app with the given public key.
+	The macaroon of the connection is revoked, so the client app can't
+	make any further requests.`,
+	ArgsUsage: "client_pubkey",
+	Action:    actionDecorator(removeNWCConnection),
+}
+
+func removeNWCConnection(ctx *cli.Context) error {
+	ctxc := getContext()
+
+	if ctx.NArg() != 1 {
+		return cli.ShowCommandHelp(ctx, "removeconnection")
+	}
+
+	client, cleanUp := getNWCClient(ctx)
+	defer cleanUp()
+
+	resp, err := client.RemoveConnection(
+		ctxc, &nwcrpc.RemoveConnectionRequest{
+			ClientPubkey: ctx.Args().First(),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
@@ -0,0 +1,11 @@
+//go:build !nwcrpc
+// +build !nwcrpc
+
+package main
+
+import "github.com/urfave/cli"
+
+// nwcCommands will return nil for non-nwcrpc builds.
+func nwcCommands() []cli.Command {
+	return nil
+}
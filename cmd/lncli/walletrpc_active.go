@@ -11,10 +11,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
 	"sort"
 
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/wire"
+	"github.com/lightningnetwork/lnd/lncfg"
 	"github.com/lightningnetwork/lnd/lnrpc"
 	"github.com/lightningnetwork/lnd/lnrpc/walletrpc"
 	"github.com/urfave/cli"
@@ -56,6 +58,17 @@ var (
 			verifyMessageWithAddrCommand,
 		},
 	}
+
+	// labelsCommand is a wallet subcommand that is responsible for the
+	// import and export of wallet labels.
+	labelsCommand = cli.Command{
+		Name:  "labels",
+		Usage: "Import and export wallet labels as BIP-329 documents.",
+		Subcommands: []cli.Command{
+			exportLabelsCommand,
+			importLabelsCommand,
+		},
+	}
 )
 
 // walletCommands will return the set of commands to enable for walletrpc
@@ -71,7 +84,9 @@ func walletCommands() []cli.Command {
 				pendingSweepsCommand,
 				bumpFeeCommand,
 				bumpCloseFeeCommand,
+				bumpFundingFeeCommand,
 				listSweepsCommand,
+				listPublishedCommand,
 				labelTxCommand,
 				publishTxCommand,
 				releaseOutputCommand,
@@ -81,6 +96,7 @@ func walletCommands() []cli.Command {
 				accountsCommand,
 				requiredReserveCommand,
 				addressesCommand,
+				labelsCommand,
 			},
 		},
 	}
@@ -384,6 +400,77 @@ func bumpCloseFee(ctx *cli.Context) error {
 	return nil
 }
 
+var bumpFundingFeeCommand = cli.Command{
+	Name:      "bumpfundingfee",
+	Usage:     "Bumps the fee of a pending channel's funding transaction.",
+	ArgsUsage: "channel_point",
+	Description: `
+	This command allows the fee of the funding transaction of a pending
+	channel we opened to be increased by using the child-pays-for-parent
+	mechanism. It will instruct the sweeper to sweep the change output of
+	the funding transaction that is under the control of the wallet at the
+	requested fee rate or confirmation target.
+
+	The progress of the fee bump is shown by lncli pendingchannels.
+
+	Only channels whose funding transaction was created by the lnd wallet
+	are supported. For channels funded through a PSBT, use lncli wallet
+	bumpfee with a wallet output of the funding transaction instead.
+	`,
+	Flags: []cli.Flag{
+		cli.Uint64Flag{
+			Name: "conf_target",
+			Usage: "the number of blocks that the funding " +
+				"transaction should confirm within",
+		},
+		cli.Uint64Flag{
+			Name: "sat_per_vbyte",
+			Usage: "a manual fee expressed in sat/vbyte that " +
+				"should be used when sweeping the change output",
+		},
+		cli.BoolFlag{
+			Name:  "force",
+			Usage: "sweep even if the yield is negative",
+		},
+	},
+	Action: actionDecorator(bumpFundingFee),
+}
+
+func bumpFundingFee(ctx *cli.Context) error {
+	ctxc := getContext()
+
+	// Display the command's help message if we do not have the expected
+	// number of arguments/flags.
+	if ctx.NArg() != 1 {
+		return cli.ShowCommandHelp(ctx, "bumpfundingfee")
+	}
+
+	// Validate the channel point.
+	chanPoint, err := parseChanPoint(ctx.Args().Get(0))
+	if err != nil {
+		return err
+	}
+
+	client, cleanUp := getWalletClient(ctx)
+	defer cleanUp()
+
+	resp, err := client.BumpFundingFee(
+		ctxc, &walletrpc.BumpFundingFeeRequest{
+			ChanPoint:   chanPoint,
+			TargetConf:  uint32(ctx.Uint64("conf_target")),
+			SatPerVbyte: ctx.Uint64("sat_per_vbyte"),
+			Force:       ctx.Bool("force"),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
 func getWaitingCloseCommitments(ctxc context.Context,
 	client lnrpc.LightningClient, channelPoint string) (
 	*lnrpc.PendingChannelsResponse_Commitments, error) {
@@ -443,6 +530,45 @@ func listSweeps(ctx *cli.Context) error {
 	return nil
 }
 
+var listPublishedCommand = cli.Command{
+	Name:  "listpublished",
+	Usage: "Lists all transactions that have been published by our node.",
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name: "active_only",
+			Usage: "only list transactions that are still waiting " +
+				"to be confirmed",
+		},
+	},
+	Description: `
+	Get a list of the transactions our node has published, such as funding,
+	sweep, close and user initiated transactions. For each transaction its
+	purpose, fee rate, replacement chain and current mempool status is
+	returned, including the reason it was rejected or why it is no longer
+	in the mempool.
+	`,
+	Action: actionDecorator(listPublished),
+}
+
+func listPublished(ctx *cli.Context) error {
+	ctxc := getContext()
+	client, cleanUp := getWalletClient(ctx)
+	defer cleanUp()
+
+	resp, err := client.ListPublishedTransactions(
+		ctxc, &walletrpc.ListPublishedTransactionsRequest{
+			ActiveOnly: ctx.Bool("active_only"),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
 var labelTxCommand = cli.Command{
 	Name:      "labeltx",
 	Usage:     "Adds a label to a transaction.",
@@ -499,6 +625,123 @@ func labelTransaction(ctx *cli.Context) error {
 	return nil
 }
 
+var exportLabelsCommand = cli.Command{
+	Name:  "export",
+	Usage: "Export the wallet labels as a BIP-329 document.",
+	Description: `
+	Export the labels of the transactions, addresses and outputs of the
+	wallet as a BIP-329 JSON Lines document. The labels lnd adds to the
+	transactions it publishes reference the channel point of their channel
+	or the purpose of the sweep.
+
+	If --encrypt is set, the document is encrypted with a key derived from
+	the wallet seed, so that it can only be imported again by this wallet.
+
+	The document is written to stdout unless --output_file is set.
+	`,
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "encrypt",
+			Usage: "encrypt the document with a key of the wallet",
+		},
+		cli.StringFlag{
+			Name:  "output_file",
+			Usage: "the file to write the document to",
+		},
+	},
+	Action: actionDecorator(exportLabels),
+}
+
+func exportLabels(ctx *cli.Context) error {
+	ctxc := getContext()
+
+	if ctx.NArg() != 0 {
+		return cli.ShowCommandHelp(ctx, "export")
+	}
+
+	walletClient, cleanUp := getWalletClient(ctx)
+	defer cleanUp()
+
+	resp, err := walletClient.ExportLabels(
+		ctxc, &walletrpc.ExportLabelsRequest{
+			Encrypt: ctx.Bool("encrypt"),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	if !ctx.IsSet("output_file") {
+		_, err := os.Stdout.Write(resp.Labels)
+		return err
+	}
+
+	outputFile := lncfg.CleanAndExpandPath(ctx.String("output_file"))
+	if err := os.WriteFile(outputFile, resp.Labels, 0600); err != nil {
+		return fmt.Errorf("unable to write labels: %w", err)
+	}
+
+	fmt.Printf("Exported %d labels to %v\n", resp.NumLabels, outputFile)
+
+	return nil
+}
+
+var importLabelsCommand = cli.Command{
+	Name:      "import",
+	Usage:     "Import wallet labels from a BIP-329 document.",
+	ArgsUsage: "file",
+	Description: `
+	Import the labels of a BIP-329 JSON Lines document, as created by
+	'lncli wallet labels export' or another wallet. Transaction labels are
+	also set on the transactions known to the wallet. Records of types lnd
+	doesn't support are skipped, as are labels that already exist unless
+	--overwrite is set.
+	`,
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name: "encrypted",
+			Usage: "set if the document was exported with " +
+				"--encrypt",
+		},
+		cli.BoolFlag{
+			Name:  "overwrite",
+			Usage: "set to overwrite existing labels",
+		},
+	},
+	Action: actionDecorator(importLabels),
+}
+
+func importLabels(ctx *cli.Context) error {
+	ctxc := getContext()
+
+	if ctx.NArg() != 1 {
+		return cli.ShowCommandHelp(ctx, "import")
+	}
+
+	doc, err := os.ReadFile(lncfg.CleanAndExpandPath(ctx.Args().First()))
+	if err != nil {
+		return fmt.Errorf("unable to read labels: %w", err)
+	}
+
+	walletClient, cleanUp := getWalletClient(ctx)
+	defer cleanUp()
+
+	resp, err := walletClient.ImportLabels(
+		ctxc, &walletrpc.ImportLabelsRequest{
+			Labels:    doc,
+			Encrypted: ctx.Bool("encrypted"),
+			Overwrite: ctx.Bool("overwrite"),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
 var publishTxCommand = cli.Command{
 	Name:      "publishtx",
 	Usage:     "Attempts to publish the passed transaction to the network.",
@@ -397,6 +397,8 @@ type Config struct {
 
 	StoreFinalHtlcResolutions bool `long:"store-final-htlc-resolutions" description:"Persistently store the final resolution of incoming htlcs."`
 
+	EventLogSize uint64 `long:"event-log-size" description:"The maximum number of events kept per event stream (channel, htlc, peer and transaction events) in the persistent event log, which lets subscriptions replay the events they missed. Set to 0 to disable the event log."`
+
 	DefaultRemoteMaxHtlcs uint16 `long:"default-remote-max-htlcs" description:"The default max_htlc applied when opening or accepting channels. This value limits the number of concurrent HTLCs that the remote party can add to the commitment. The maximum possible value is 483."`
 
 	NumGraphSyncPeers      int           `long:"numgraphsyncpeers" description:"The number of peers that we should receive new graph updates from. This option can be tuned to save bandwidth for light clients or routing nodes."`
@@ -436,7 +438,11 @@ type Config struct {
 
 	GcCanceledInvoicesOnTheFly bool `long:"gc-canceled-invoices-on-the-fly" description:"If true, we'll delete newly canceled invoices on the fly."`
 
-	DustThreshold uint64 `long:"dust-threshold" description:"Sets the dust sum threshold in satoshis for a channel after which dust HTLC's will be failed."`
+	DustThreshold uint64 `long:"dust-threshold" description:"Deprecated, use channel-max-fee-exposure instead. Sets the dust sum threshold in satoshis for a channel after which dust HTLC's will be failed."`
+
+	ChannelMaxFeeExposure uint64 `long:"channel-max-fee-exposure" description:"The maximum amount in satoshis of a channel that may be lost to fees on force close, consisting of the HTLCs that are trimmed to dust and, if we opened the channel, the commitment fee. HTLCs and fee updates that would exceed it are failed."`
+
+	ChannelMaxFeeExposureMultiplier float64 `long:"channel-max-fee-exposure-multiplier" description:"If non-zero, raises channel-max-fee-exposure to the given multiple of the commitment fee, so that the limit grows with on-chain fees."`
 
 	Invoices *lncfg.Invoices `group:"invoices" namespace:"invoices"`
 
@@ -470,6 +476,10 @@ type Config struct {
 
 	Sweeper *lncfg.Sweeper `group:"sweeper" namespace:"sweeper"`
 
+	FeeManager *lncfg.FeeManager `group:"feemanager" namespace:"feemanager"`
+
+	Webhook *lncfg.Webhook `group:"webhook" namespace:"webhook"`
+
 	Htlcswitch *lncfg.Htlcswitch `group:"htlcswitch" namespace:"htlcswitch"`
 
 	GRPC *GRPCConfig `group:"grpc" namespace:"grpc"`
@@ -590,6 +600,10 @@ func DefaultConfig() Config {
 			Heuristic: map[string]float64{
 				"top_centrality": 1.0,
 			},
+			PolicyInterval:      lncfg.DefaultAutopilotPolicyInterval,
+			UnbalancedRatio:     lncfg.DefaultAutopilotUnbalancedRatio,
+			MaxCloses:           lncfg.DefaultAutopilotMaxCloses,
+			RebalanceMaxFeeRate: lncfg.DefaultAutopilotRebalanceMaxFeeRate,
 		},
 		PaymentsExpirationGracePeriod: defaultPaymentsExpirationGracePeriod,
 		TrickleDelay:                  defaultTrickleDelay,
@@ -668,7 +682,7 @@ func DefaultConfig() Config {
 		MaxOutgoingCltvExpiry:     htlcswitch.DefaultMaxOutgoingCltvExpiry,
 		MaxChannelFeeAllocation:   htlcswitch.DefaultMaxLinkFeeAllocation,
 		MaxCommitFeeRateAnchors:   lnwallet.DefaultAnchorsCommitMaxFeeRateSatPerVByte,
-		DustThreshold:             uint64(htlcswitch.DefaultDustThreshold.ToSatoshis()),
+		ChannelMaxFeeExposure:     uint64(htlcswitch.DefaultMaxFeeExposure.ToSatoshis()),
 		LogWriter:                 build.NewRotatingLogWriter(),
 		DB:                        lncfg.DefaultDB(),
 		Cluster:                   lncfg.DefaultCluster(),
@@ -686,8 +700,33 @@ func DefaultConfig() Config {
 			BatchWindowDuration: sweep.DefaultBatchWindowDuration,
 			MaxFeeRate:          sweep.DefaultMaxFeeRate,
 		},
+		FeeManager: &lncfg.FeeManager{
+			Strategy:          lncfg.DefaultFeeManagerStrategy,
+			Interval:          lncfg.DefaultFeeManagerInterval,
+			MinUpdateInterval: lncfg.DefaultFeeManagerMinUpdateInterval,
+			MinChange:         lncfg.DefaultFeeManagerMinChange,
+			MinFeeRate:        lncfg.DefaultFeeManagerMinFeeRate,
+			MaxFeeRate:        lncfg.DefaultFeeManagerMaxFeeRate,
+			MaxBaseFee:        lncfg.DefaultFeeManagerMaxBaseFee,
+			VelocityWindow:    lncfg.DefaultFeeManagerVelocityWindow,
+			VelocityTarget:    lncfg.DefaultFeeManagerVelocityTarget,
+		},
+		Webhook: &lncfg.Webhook{
+			MaxAttempts:     lncfg.DefaultWebhookMaxAttempts,
+			InitialBackoff:  lncfg.DefaultWebhookInitialBackoff,
+			MaxBackoff:      lncfg.DefaultWebhookMaxBackoff,
+			Timeout:         lncfg.DefaultWebhookTimeout,
+			DeliveryHistory: lncfg.DefaultWebhookDeliveryHistory,
+		},
 		Htlcswitch: &lncfg.Htlcswitch{
 			MailboxDeliveryTimeout: htlcswitch.DefaultMailboxDeliveryTimeout,
+			Endorsement:            lncfg.EndorsementOff,
+			RevenueWindow:          lncfg.DefaultRevenueWindow,
+			ReputationMultiplier:   lncfg.DefaultReputationMultiplier,
+			ResolutionPeriod:       lncfg.DefaultResolutionPeriod,
+			ProtectedShare:         lncfg.DefaultProtectedShare,
+			FailRatioWindow:        lncfg.DefaultFailRatioWindow,
+			FailRatioMinHTLCs:      lncfg.DefaultFailRatioMinHTLCs,
 		},
 		GRPC: &GRPCConfig{
 			ServerPingTime:    defaultGrpcServerPingTime,
@@ -1036,6 +1075,21 @@ func ValidateConfig(cfg Config, interceptor signal.Interceptor, fileParser,
 			cfg.MaxCommitFeeRateAnchors)
 	}
 
+	if cfg.ChannelMaxFeeExposureMultiplier < 0 {
+		return nil, mkErr("invalid channel max fee exposure "+
+			"multiplier: %v, must not be negative",
+			cfg.ChannelMaxFeeExposureMultiplier)
+	}
+
+	// The deprecated dust threshold is still honored if set, as it is the
+	// predecessor of the max fee exposure.
+	if cfg.DustThreshold != 0 {
+		ltndLog.Warnf("Config option dust-threshold is deprecated, " +
+			"use channel-max-fee-exposure instead")
+
+		cfg.ChannelMaxFeeExposure = cfg.DustThreshold
+	}
+
 	// Validate the Tor config parameters.
 	socks, err := lncfg.ParseAddressString(
 		cfg.Tor.SOCKS, strconv.Itoa(defaultTorSOCKSPort),
@@ -1227,6 +1281,15 @@ func ValidateConfig(cfg Config, interceptor signal.Interceptor, fileParser,
 		return nil, mkErr("error validating bitcoin params: %v", err)
 	}
 
+	// Zero-fee commitments need to be relayed as a package together with
+	// their anchor sweep, which only bitcoind supports.
+	if cfg.ProtocolOptions.ZeroFeeCommitments &&
+		cfg.Bitcoin.Node != bitcoindBackendName {
+
+		return nil, mkErr("protocol.zero-fee-commitments requires " +
+			"the bitcoind backend")
+	}
+
 	switch cfg.Bitcoin.Node {
 	case btcdBackendName:
 		err := parseRPCParams(
@@ -1671,7 +1734,10 @@ func ValidateConfig(cfg Config, interceptor signal.Interceptor, fileParser,
 		cfg.RPCMiddleware,
 		cfg.RemoteSigner,
 		cfg.Sweeper,
+		cfg.FeeManager,
+		cfg.Webhook,
 		cfg.Htlcswitch,
+		cfg.Autopilot,
 	)
 	if err != nil {
 		return nil, err
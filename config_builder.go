@@ -3,6 +3,7 @@ package lnd
 import (
 	"bytes"
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -15,6 +16,8 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/wire"
@@ -31,6 +34,7 @@ import (
 	"github.com/lightningnetwork/lnd/chainntnfs"
 	"github.com/lightningnetwork/lnd/chainreg"
 	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/clock"
 	"github.com/lightningnetwork/lnd/invoices"
 	"github.com/lightningnetwork/lnd/keychain"
 	"github.com/lightningnetwork/lnd/kvdb"
@@ -39,9 +43,11 @@ import (
 	"github.com/lightningnetwork/lnd/lnwallet"
 	"github.com/lightningnetwork/lnd/lnwallet/btcwallet"
 	"github.com/lightningnetwork/lnd/lnwallet/rpcwallet"
+	"github.com/lightningnetwork/lnd/lnwallet/txtracker"
 	"github.com/lightningnetwork/lnd/macaroons"
 	"github.com/lightningnetwork/lnd/rpcperms"
 	"github.com/lightningnetwork/lnd/signal"
+	"github.com/lightningnetwork/lnd/sqldb"
 	"github.com/lightningnetwork/lnd/walletunlocker"
 	"github.com/lightningnetwork/lnd/watchtower"
 	"github.com/lightningnetwork/lnd/watchtower/wtclient"
@@ -406,6 +412,11 @@ func (d *DefaultWalletImpl) BuildWalletConfig(ctx context.Context,
 			rootKeyStore, "lnd", walletInitParams.StatelessInit,
 			macaroons.IPLockChecker,
 			macaroons.CustomChecker(interceptorChain),
+			macaroons.SpendLimitChecker,
+			macaroons.MaxPaymentChecker,
+			macaroons.DestinationsChecker,
+			macaroons.RateLimitChecker,
+			macaroons.AccountChecker,
 		)
 		if err != nil {
 			err := fmt.Errorf("unable to set up macaroon "+
@@ -509,6 +520,15 @@ func (d *DefaultWalletImpl) BuildWalletConfig(ctx context.Context,
 		// will start checking macaroons against permissions on every
 		// RPC invocation.
 		interceptorChain.AddMacaroonService(macaroonService)
+
+		// The usage of macaroons with spending or rate limit caveats is
+		// tracked in the macaroon DB as well, so their budgets survive
+		// restarts.
+		usageStore, err := macaroons.NewUsageStore(dbs.MacaroonDB)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		interceptorChain.AddMacaroonUsageStore(usageStore)
 	}
 
 	// Now that the wallet password has been provided, transition the RPC
@@ -724,6 +744,17 @@ func (d *DefaultWalletImpl) BuildChainControl(
 		)
 	}
 
+	txTracker, err := newTxTracker(
+		partialChainControl, walletController,
+		lnWalletConfig.Rebroadcaster,
+	)
+	if err != nil {
+		err := fmt.Errorf("unable to create tx tracker: %v", err)
+		d.logger.Error(err)
+		return nil, nil, err
+	}
+	lnWalletConfig.PublishTracker = txTracker
+
 	// We've created the wallet configuration now, so we can finish
 	// initializing the main chain control.
 	activeChainControl, cleanUp, err := chainreg.NewChainControl(
@@ -734,6 +765,7 @@ func (d *DefaultWalletImpl) BuildChainControl(
 		d.logger.Error(err)
 		return nil, nil, err
 	}
+	activeChainControl.TxTracker = txTracker
 
 	return activeChainControl, cleanUp, nil
 }
@@ -812,6 +844,16 @@ func (d *RPCSignerWalletImpl) BuildChainControl(
 		NetParams:          *walletConfig.NetParams,
 	}
 
+	txTracker, err := newTxTracker(
+		partialChainControl, walletController, nil,
+	)
+	if err != nil {
+		err := fmt.Errorf("unable to create tx tracker: %v", err)
+		d.logger.Error(err)
+		return nil, nil, err
+	}
+	lnWalletConfig.PublishTracker = txTracker
+
 	// We've created the wallet configuration now, so we can finish
 	// initializing the main chain control.
 	activeChainControl, cleanUp, err := chainreg.NewChainControl(
@@ -822,10 +864,98 @@ func (d *RPCSignerWalletImpl) BuildChainControl(
 		d.logger.Error(err)
 		return nil, nil, err
 	}
+	activeChainControl.TxTracker = txTracker
 
 	return activeChainControl, cleanUp, nil
 }
 
+// rawTxFetcher is implemented by the chain backends that can look up
+// transactions in their mempool.
+type rawTxFetcher interface {
+	// GetRawTransaction returns the transaction with the given hash.
+	GetRawTransaction(txHash *chainhash.Hash) (*btcutil.Tx, error)
+}
+
+// newTxTracker creates the tracker that records all transactions published
+// through the wallet.
+func newTxTracker(partialChainControl *chainreg.PartialChainControl,
+	walletController *btcwallet.BtcWallet,
+	rebroadcaster lnwallet.Rebroadcaster) (*txtracker.Tracker, error) {
+
+	chanStateDB := partialChainControl.Cfg.ChanStateDB
+	store, err := txtracker.NewStore(chanStateDB.GetParentDB())
+	if err != nil {
+		return nil, err
+	}
+
+	// The inputs of the published transactions are either wallet outputs
+	// or, for commitment transactions, channel funding outputs.
+	fetchPrevOutput := func(op wire.OutPoint) (*wire.TxOut, error) {
+		utxo, err := walletController.FetchInputInfo(&op)
+		if err == nil {
+			return &wire.TxOut{
+				Value:    int64(utxo.Value),
+				PkScript: utxo.PkScript,
+			}, nil
+		}
+
+		channel, chanErr := chanStateDB.FetchChannel(nil, op)
+		if chanErr != nil {
+			return nil, err
+		}
+
+		return lnwallet.FundingTxOut(channel)
+	}
+
+	// Neutrino has no mempool, so evictions can only be tracked for the
+	// full node backends.
+	var inMempool func(chainhash.Hash) (bool, error)
+	chainSource := partialChainControl.ChainSource
+	if fetcher, ok := chainSource.(rawTxFetcher); ok {
+		inMempool = func(txid chainhash.Hash) (bool, error) {
+			_, err := fetcher.GetRawTransaction(&txid)
+
+			var rpcErr *btcjson.RPCError
+			switch {
+			case err == nil:
+				return true, nil
+
+			case errors.As(err, &rpcErr) &&
+				rpcErr.Code == btcjson.ErrRPCNoTxInfo:
+
+				return false, nil
+
+			default:
+				return false, err
+			}
+		}
+	}
+
+	// Replaced and double spent transactions shouldn't be rebroadcast
+	// anymore.
+	cancelRebroadcast := func(txid chainhash.Hash) {
+		if rebroadcaster == nil || !rebroadcaster.Started() {
+			return
+		}
+
+		go rebroadcaster.MarkAsConfirmed(txid)
+	}
+
+	return txtracker.New(&txtracker.Config{
+		Store:    store,
+		Notifier: partialChainControl.ChainNotifier,
+		BestHeight: func() (uint32, error) {
+			_, height, err := walletController.GetBestBlock()
+			return uint32(height), err
+		},
+		FetchPrevOutput:   fetchPrevOutput,
+		InMempool:         inMempool,
+		CancelRebroadcast: cancelRebroadcast,
+		Clock:             clock.NewDefaultClock(),
+		PruneAfter:        txtracker.DefaultPruneAfter,
+	}), nil
+}
+
 // DatabaseInstances is a struct that holds all instances to the actual
 // databases that are used in lnd.
 type DatabaseInstances struct {
@@ -869,6 +999,10 @@ type DatabaseInstances struct {
 	// WalletDB is the configuration for loading the wallet database using
 	// the btcwallet's loader.
 	WalletDB btcwallet.LoaderOption
+
+	// NativeSQLStore is the database that holds the native SQL tables. It
+	// is nil if native SQL tables aren't used.
+	NativeSQLStore *sqldb.BaseDB
 }
 
 // DefaultDatabaseBuilder is a type that builds the default database backends
@@ -923,10 +1057,11 @@ func (d *DefaultDatabaseBuilder) BuildDatabase(
 	// state DB point to the same local or remote DB and the same namespace
 	// within that DB.
 	dbs := &DatabaseInstances{
-		HeightHintDB: databaseBackends.HeightHintDB,
-		MacaroonDB:   databaseBackends.MacaroonDB,
-		DecayedLogDB: databaseBackends.DecayedLogDB,
-		WalletDB:     databaseBackends.WalletDB,
+		HeightHintDB:   databaseBackends.HeightHintDB,
+		MacaroonDB:     databaseBackends.MacaroonDB,
+		DecayedLogDB:   databaseBackends.DecayedLogDB,
+		WalletDB:       databaseBackends.WalletDB,
+		NativeSQLStore: databaseBackends.NativeSQLStore,
 	}
 	cleanUp := func() {
 		// We can just close the returned close functions directly. Even
@@ -977,6 +1112,24 @@ func (d *DefaultDatabaseBuilder) BuildDatabase(
 		)
 	}
 
+	// If its migration was requested, the channel graph is stored in the
+	// native SQL tables so that it doesn't need to be loaded into memory
+	// on startup. The migration can't be undone.
+	if dbs.NativeSQLStore != nil && cfg.DB.MigrateGraphToSQL {
+		sqlStore := dbs.NativeSQLStore
+		executor := sqldb.NewTransactionExecutor(
+			sqlStore, func(tx *sql.Tx) channeldb.SQLGraphQueries {
+				return sqlStore.WithTx(tx)
+			},
+		)
+
+		dbOptions = append(
+			dbOptions, channeldb.OptionSetGraphSQLStore(
+				ctx, executor,
+			),
+		)
+	}
+
 	// Otherwise, we'll open two instances, one for the state we only need
 	// locally, and the other for things we want to ensure are replicated.
 	dbs.GraphDB, err = channeldb.CreateWithBackend(
@@ -990,6 +1143,14 @@ func (d *DefaultDatabaseBuilder) BuildDatabase(
 		d.logger.Infof("Graph DB dry run migration successful")
 		return nil, nil, err
 
+	case errors.Is(err, channeldb.ErrGraphMigratedToSQL):
+		cleanUp()
+
+		err := fmt.Errorf("unable to open graph DB: %w, "+
+			"db.migrate-graph-to-sql must stay enabled", err)
+		d.logger.Error(err)
+		return nil, nil, err
+
 	case err != nil:
 		cleanUp()
 
@@ -176,9 +176,8 @@ func (c *anchorResolver) Resolve() (ContractResolver, error) {
 	report := c.currentReport.resolverReport(
 		spendTx, channeldb.ResolverTypeAnchor, outcome,
 	)
-	c.reportLock.Unlock()
-
 	c.resolved = true
+	c.reportLock.Unlock()
 	return nil, c.PutResolverReport(nil, report)
 }
 
@@ -215,6 +214,30 @@ func (c *anchorResolver) report() *ContractReport {
 	return &reportCopy
 }
 
+// status returns a snapshot of the current state of the resolver.
+//
+// NOTE: Part of the statusContractResolver interface.
+func (c *anchorResolver) status(_ uint32) *ResolverStatus {
+	c.reportLock.Lock()
+	report := c.currentReport
+	resolved := c.resolved
+	c.reportLock.Unlock()
+
+	s := &ResolverStatus{
+		ChanPoint: c.chanPoint,
+		Kind:      ResolverKindAnchor,
+		Outpoint:  c.anchor,
+		Amount:    report.Amount,
+		Stage:     1,
+		Resolved:  resolved,
+	}
+	if !resolved {
+		s.WaitingFor = ResolverWaitSweepConf
+	}
+
+	return s
+}
+
 func (c *anchorResolver) Encode(w io.Writer) error {
 	return errors.New("serialization not supported")
 }
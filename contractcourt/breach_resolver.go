@@ -3,6 +3,7 @@ package contractcourt
 import (
 	"encoding/binary"
 	"io"
+	"sync"
 
 	"github.com/lightningnetwork/lnd/channeldb"
 )
@@ -13,6 +14,10 @@ type breachResolver struct {
 	// resolved reflects if the contract has been fully resolved or not.
 	resolved bool
 
+	// resolvedLock prevents concurrent access to resolved, which is read
+	// when reporting the status of the resolver.
+	resolvedLock sync.Mutex
+
 	// subscribed denotes whether or not the breach resolver has subscribed
 	// to the breacharbiter for breach resolution.
 	subscribed bool
@@ -56,7 +61,10 @@ func (b *breachResolver) Resolve() (ContractResolver, error) {
 		// If the breach resolution process is already complete, then
 		// we can cleanup and checkpoint the resolved state.
 		if complete {
+			b.resolvedLock.Lock()
 			b.resolved = true
+			b.resolvedLock.Unlock()
+
 			return nil, b.Checkpoint(b)
 		}
 
@@ -69,7 +77,10 @@ func (b *breachResolver) Resolve() (ContractResolver, error) {
 		// The replyChan has been closed, signalling that the breach
 		// has been fully resolved. Checkpoint the resolved state and
 		// exit.
+		b.resolvedLock.Lock()
 		b.resolved = true
+		b.resolvedLock.Unlock()
+
 		return nil, b.Checkpoint(b)
 	case <-b.quit:
 	}
@@ -88,6 +99,28 @@ func (b *breachResolver) IsResolved() bool {
 	return b.resolved
 }
 
+// status returns a snapshot of the current state of the resolver.
+//
+// NOTE: Part of the statusContractResolver interface.
+func (b *breachResolver) status(_ uint32) *ResolverStatus {
+	b.resolvedLock.Lock()
+	resolved := b.resolved
+	b.resolvedLock.Unlock()
+
+	s := &ResolverStatus{
+		ChanPoint: b.ChanPoint,
+		Kind:      ResolverKindBreach,
+		Outpoint:  b.ChanPoint,
+		Stage:     1,
+		Resolved:  resolved,
+	}
+	if !resolved {
+		s.WaitingFor = ResolverWaitJustice
+	}
+
+	return s
+}
+
 // SupplementState adds additional state to the breachResolver.
 func (b *breachResolver) SupplementState(_ *channeldb.OpenChannel) {
 }
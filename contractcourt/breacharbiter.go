@@ -733,7 +733,9 @@ justiceTxBroadcast:
 
 	// We'll now attempt to broadcast the transaction which finalized the
 	// channel's retribution against the cheating counter party.
-	label := labels.MakeLabel(labels.LabelTypeJusticeTransaction, nil)
+	label := labels.MakeChannelLabel(
+		labels.LabelTypeJusticeTransaction, nil, breachInfo.chanPoint,
+	)
 	err = b.cfg.PublishTransaction(finalTx, label)
 	if err != nil {
 		brarLog.Errorf("Unable to broadcast justice tx: %v", err)
@@ -844,8 +846,9 @@ Loop:
 			// mitigate the case where our "spend all" justice TX
 			// doesn't propagate because the HTLC outputs have been
 			// pinned by low fee HTLC txs.
-			label := labels.MakeLabel(
+			label := labels.MakeChannelLabel(
 				labels.LabelTypeJusticeTransaction, nil,
+				breachInfo.chanPoint,
 			)
 			if justiceTxs.spendCommitOuts != nil {
 				tx := justiceTxs.spendCommitOuts
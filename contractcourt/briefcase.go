@@ -1164,7 +1164,7 @@ func (b *boltArbitratorLog) WipeHistory() error {
 func (b *boltArbitratorLog) checkpointContract(c ContractResolver,
 	reports ...*channeldb.ResolverReport) error {
 
-	return kvdb.Update(b.db, func(tx kvdb.RwTx) error {
+	err := kvdb.Update(b.db, func(tx kvdb.RwTx) error {
 		contractBucket, err := fetchContractWriteBucket(tx, b.scopeKey[:])
 		if err != nil {
 			return err
@@ -1182,6 +1182,15 @@ func (b *boltArbitratorLog) checkpointContract(c ContractResolver,
 
 		return nil
 	}, func() {})
+	if err != nil {
+		return err
+	}
+
+	if b.cfg.NotifyResolverStatus != nil {
+		b.cfg.NotifyResolverStatus(c)
+	}
+
+	return nil
 }
 
 // encodeSignDetails encodes the given SignDetails struct to the writer.
@@ -20,6 +20,7 @@ import (
 	"github.com/lightningnetwork/lnd/lnwallet"
 	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
 	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/subscribe"
 )
 
 // ErrChainArbExiting signals that the chain arbitrator is shutting down.
@@ -145,6 +146,12 @@ type ChainArbitratorConfig struct {
 	// ChainIO allows us to query the state of the current main chain.
 	ChainIO lnwallet.BlockChainIO
 
+	// PackageRelay is true if the chain backend is able to relay a parent
+	// and child transaction as a package. Zero-fee commitments are only
+	// published together with their anchor sweep if it is set, otherwise
+	// they are broadcast like any other commitment transaction.
+	PackageRelay bool
+
 	// DisableChannel disables a channel, resulting in it not being able to
 	// forward payments.
 	DisableChannel func(wire.OutPoint) error
@@ -232,6 +239,10 @@ type ChainArbitrator struct {
 	// active channels that it must still watch over.
 	chanSource *channeldb.DB
 
+	// resolutionNotifier is used to notify subscribers of changes to the
+	// state of any of the active contract resolvers.
+	resolutionNotifier *resolutionNotifier
+
 	quit chan struct{}
 
 	wg sync.WaitGroup
@@ -243,11 +254,12 @@ func NewChainArbitrator(cfg ChainArbitratorConfig,
 	db *channeldb.DB) *ChainArbitrator {
 
 	return &ChainArbitrator{
-		cfg:            cfg,
-		activeChannels: make(map[wire.OutPoint]*ChannelArbitrator),
-		activeWatchers: make(map[wire.OutPoint]*chainWatcher),
-		chanSource:     db,
-		quit:           make(chan struct{}),
+		cfg:                cfg,
+		activeChannels:     make(map[wire.OutPoint]*ChannelArbitrator),
+		activeWatchers:     make(map[wire.OutPoint]*chainWatcher),
+		chanSource:         db,
+		resolutionNotifier: newResolutionNotifier(cfg.ChainIO),
+		quit:               make(chan struct{}),
 	}
 }
 
@@ -381,6 +393,7 @@ func newActiveChannelArbitrator(channel *channeldb.OpenChannel,
 			chanStateDB := c.chanSource.ChannelStateDB()
 			return chanStateDB.FetchHistoricalChannel(&chanPoint)
 		},
+		NotifyResolverStatus: c.resolutionNotifier.notify,
 	}
 
 	// The final component needed is an arbitrator log that the arbitrator
@@ -499,6 +512,12 @@ func (c *ChainArbitrator) Start() error {
 
 	log.Info("ChainArbitrator starting")
 
+	// Start the resolution notifier first, as the channel arbitrators
+	// will send resolver updates as soon as they're launched.
+	if err := c.resolutionNotifier.Start(); err != nil {
+		return err
+	}
+
 	// First, we'll fetch all the channels that are still open, in order to
 	// collect them within our set of active contracts.
 	openChannels, err := c.chanSource.ChannelStateDB().FetchAllChannels()
@@ -596,6 +615,7 @@ func (c *ChainArbitrator) Start() error {
 				chanStateDB := c.chanSource.ChannelStateDB()
 				return chanStateDB.FetchHistoricalChannel(&chanPoint)
 			},
+			NotifyResolverStatus: c.resolutionNotifier.notify,
 		}
 		chanLog, err := newBoltArbitratorLog(
 			c.chanSource.Backend, arbCfg, c.cfg.ChainHash, chanPoint,
@@ -896,11 +916,25 @@ func (c *ChainArbitrator) rebroadcast(channel *channeldb.OpenChannel,
 		return err
 	}
 
+	// Zero-fee commitments can't be relayed on their own. They are
+	// re-published as a package together with their anchor sweep by the
+	// channel arbitrator instead.
+	if c.cfg.PackageRelay &&
+		state == channeldb.ChanStatusCommitBroadcasted &&
+		channel.ChanType.HasZeroFeeCommitment() {
+
+		log.Infof("Skipping re-publish of zero-fee commitment tx(%v) "+
+			"for channel %v", closeTx.TxHash(), chanPoint)
+
+		return nil
+	}
+
 	log.Infof("Re-publishing %s close tx(%v) for channel %v",
 		kind, closeTx.TxHash(), chanPoint)
 
-	label := labels.MakeLabel(
+	label := labels.MakeChannelLabel(
 		labels.LabelTypeChannelClose, &channel.ShortChannelID,
+		channel.FundingOutpoint,
 	)
 	err = c.cfg.PublishTx(closeTx, label)
 	if err != nil && err != lnwallet.ErrDoubleSpend {
@@ -962,6 +996,10 @@ func (c *ChainArbitrator) Stop() error {
 
 	c.wg.Wait()
 
+	if err := c.resolutionNotifier.Stop(); err != nil {
+		log.Errorf("unable to stop resolution notifier: %v", err)
+	}
+
 	return nil
 }
 
@@ -1199,5 +1237,27 @@ func (c *ChainArbitrator) SubscribeChannelEvents(
 	return watcher.SubscribeChannelEvents(), nil
 }
 
-// TODO(roasbeef): arbitration reports
-//  * types: contested, waiting for success conf, etc
+// ResolverStatuses returns the detailed status of all active contract
+// resolvers across all channels that are in the process of being resolved.
+func (c *ChainArbitrator) ResolverStatuses() []*ResolverStatus {
+	c.Lock()
+	arbitrators := make([]*ChannelArbitrator, 0, len(c.activeChannels))
+	for _, arbitrator := range c.activeChannels {
+		arbitrators = append(arbitrators, arbitrator)
+	}
+	c.Unlock()
+
+	var statuses []*ResolverStatus
+	for _, arbitrator := range arbitrators {
+		statuses = append(statuses, arbitrator.ResolverStatuses()...)
+	}
+
+	return statuses
+}
+
+// SubscribeResolutions returns a new subscription client that will receive a
+// ResolutionUpdate each time the state of an active contract resolver
+// changes.
+func (c *ChainArbitrator) SubscribeResolutions() (*subscribe.Client, error) {
+	return c.resolutionNotifier.ntfnServer.Subscribe()
+}
@@ -170,6 +170,11 @@ type ChannelArbitratorConfig struct {
 	// additional information required for proper contract resolution.
 	FetchHistoricalChannel func() (*channeldb.OpenChannel, error)
 
+	// NotifyResolverStatus is called each time the state of one of the
+	// channel's resolvers changes, so the detailed status can be sent to
+	// subscribers. May be nil.
+	NotifyResolverStatus func(ContractResolver)
+
 	ChainArbitratorConfig
 }
 
@@ -805,6 +810,45 @@ func (c *ChannelArbitrator) Report() []*ContractReport {
 	return reports
 }
 
+// ResolverStatuses returns a detailed status for each of the active resolvers
+// that supports it.
+func (c *ChannelArbitrator) ResolverStatuses() []*ResolverStatus {
+	_, height, err := c.cfg.ChainIO.GetBestBlock()
+	if err != nil {
+		log.Errorf("ChannelArbitrator(%v): unable to fetch best "+
+			"block: %v", c.cfg.ChanPoint, err)
+		return nil
+	}
+
+	c.activeResolversLock.RLock()
+	defer c.activeResolversLock.RUnlock()
+
+	var statuses []*ResolverStatus
+	for _, resolver := range c.activeResolvers {
+		r, ok := resolver.(statusContractResolver)
+		if !ok {
+			continue
+		}
+
+		status := r.status(uint32(height))
+		if status == nil {
+			continue
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses
+}
+
+// notifyResolverStatus signals subscribers that the state of the given
+// resolver has changed.
+func (c *ChannelArbitrator) notifyResolverStatus(resolver ContractResolver) {
+	if c.cfg.NotifyResolverStatus != nil {
+		c.cfg.NotifyResolverStatus(resolver)
+	}
+}
+
 // Stop signals the ChannelArbitrator for a graceful shutdown.
 func (c *ChannelArbitrator) Stop() error {
 	if !atomic.CompareAndSwapInt32(&c.stopped, 0, 1) {
@@ -1091,10 +1135,27 @@ func (c *ChannelArbitrator) stateStep(
 				return spew.Sdump(closeTx)
 			}))
 
+		// A zero-fee commitment transaction can't be relayed on its
+		// own. Instead, it is published as a package together with
+		// the sweep of its ephemeral anchor once we offer the anchor
+		// to the sweeper below.
+		anchorRes := closeSummary.AnchorResolution
+		if c.cfg.PackageRelay && anchorRes != nil &&
+			anchorRes.CommitTx != nil {
+
+			log.Infof("ChannelArbitrator(%v): deferring broadcast "+
+				"of zero-fee commitment %v to anchor sweep",
+				c.cfg.ChanPoint, closeTx.TxHash())
+
+			nextState = StateCommitmentBroadcasted
+			break
+		}
+
 		// At this point, we'll now broadcast the commitment
 		// transaction itself.
-		label := labels.MakeLabel(
+		label := labels.MakeChannelLabel(
 			labels.LabelTypeChannelClose, &c.cfg.ShortChanID,
+			c.cfg.ChanPoint,
 		)
 		if err := c.cfg.PublishTx(closeTx, label); err != nil {
 			log.Errorf("ChannelArbitrator(%v): unable to broadcast "+
@@ -1324,19 +1385,34 @@ func (c *ChannelArbitrator) sweepAnchors(anchors *lnwallet.AnchorResolutions,
 			force = true
 		}
 
+		// A zero-fee commitment can't confirm without a child paying
+		// for it, so we always sweep its anchor and relay it together
+		// with the commitment as a package if the backend allows us
+		// to.
+		var packageParent *wire.MsgTx
+		if anchor.CommitTx != nil {
+			force = true
+
+			if c.cfg.PackageRelay {
+				packageParent = anchor.CommitTx
+			}
+		}
+
 		log.Debugf("ChannelArbitrator(%v): pre-confirmation sweep of "+
 			"anchor of %s commit tx %v, force=%v", c.cfg.ChanPoint,
 			anchorPath, anchor.CommitAnchor, force)
 
 		witnessType := input.CommitmentAnchor
 
-		// For taproot channels, we need to use the proper witness
-		// type.
-		if txscript.IsPayToTaproot(
-			anchor.AnchorSignDescriptor.Output.PkScript,
-		) {
-
+		// For taproot channels and the ephemeral anchor of zero-fee
+		// commitments, we need to use the proper witness type.
+		anchorPkScript := anchor.AnchorSignDescriptor.Output.PkScript
+		switch {
+		case txscript.IsPayToTaproot(anchorPkScript):
 			witnessType = input.TaprootAnchorSweepSpend
+
+		case input.IsPayToAnchorScript(anchorPkScript):
+			witnessType = input.PayToAnchorSpend
 		}
 
 		// Prepare anchor output for sweeping.
@@ -1364,6 +1440,7 @@ func (c *ChannelArbitrator) sweepAnchors(anchors *lnwallet.AnchorResolutions,
 				},
 				Force:          force,
 				ExclusiveGroup: &exclusiveGroup,
+				PackageParent:  packageParent,
 			},
 		)
 		if err != nil {
@@ -1513,6 +1590,8 @@ func (c *ChannelArbitrator) launchResolvers(resolvers []ContractResolver) {
 
 	c.activeResolvers = resolvers
 	for _, contract := range resolvers {
+		c.notifyResolverStatus(contract)
+
 		c.wg.Add(1)
 		go c.resolveContract(contract)
 	}
@@ -2214,7 +2293,14 @@ func (c *ChannelArbitrator) prepContractResolutions(
 		Checkpoint: func(res ContractResolver,
 			reports ...*channeldb.ResolverReport) error {
 
-			return c.log.InsertUnresolvedContracts(reports, res)
+			err := c.log.InsertUnresolvedContracts(reports, res)
+			if err != nil {
+				return err
+			}
+
+			c.notifyResolverStatus(res)
+
+			return nil
 		},
 	}
 
@@ -2555,6 +2641,8 @@ func (c *ChannelArbitrator) resolveContract(currentContract ContractResolver) {
 						"contract: %v", err)
 				}
 
+				c.notifyResolverStatus(nextContract)
+
 				// As this contract produced another, we'll
 				// re-assign, so we can continue our resolution
 				// loop.
@@ -2573,6 +2661,8 @@ func (c *ChannelArbitrator) resolveContract(currentContract ContractResolver) {
 						err)
 				}
 
+				c.notifyResolverStatus(currentContract)
+
 				// Now that the contract has been resolved,
 				// well signal to the main goroutine.
 				select {
@@ -217,6 +217,8 @@ func (c *commitSweepResolver) Resolve() (ContractResolver, error) {
 	c.currentReport.MaturityHeight = unlockHeight
 	c.reportLock.Unlock()
 
+	c.notifyStatus(c)
+
 	// If there is a csv/cltv lock, we'll wait for that.
 	if c.commitResolution.MaturityDelay > 0 || c.hasCLTV() {
 		// Determine what height we should wait until for the locks to
@@ -403,11 +405,11 @@ func (c *commitSweepResolver) Resolve() (ContractResolver, error) {
 		c.currentReport.RecoveredBalance = c.currentReport.LimboBalance
 	}
 	c.currentReport.LimboBalance = 0
-	c.reportLock.Unlock()
 	report := c.currentReport.resolverReport(
 		&sweepTxID, channeldb.ResolverTypeCommit, outcome,
 	)
 	c.resolved = true
+	c.reportLock.Unlock()
 
 	// Checkpoint the resolver with a closure that will write the outcome
 	// of the resolver and its sweep transaction to disk.
@@ -526,6 +528,42 @@ func (c *commitSweepResolver) report() *ContractReport {
 	return &cpy
 }
 
+// status returns a snapshot of the current state of the resolver.
+//
+// NOTE: Part of the statusContractResolver interface.
+func (c *commitSweepResolver) status(height uint32) *ResolverStatus {
+	c.reportLock.Lock()
+	report := c.currentReport
+	resolved := c.resolved
+	c.reportLock.Unlock()
+
+	s := &ResolverStatus{
+		ChanPoint:        c.ChanPoint,
+		Kind:             ResolverKindCommitSweep,
+		Outpoint:         report.Outpoint,
+		Amount:           report.Amount,
+		Stage:            1,
+		NextActionHeight: report.MaturityHeight,
+		Resolved:         resolved,
+	}
+
+	switch {
+	case resolved:
+		s.WaitingFor = ResolverWaitNone
+
+	// The maturity height stays unknown until the commitment confirmed.
+	// Once it is known, the output is handed to the sweeper after it has
+	// been reached.
+	case report.MaturityHeight == 0 || height < report.MaturityHeight:
+		s.WaitingFor = ResolverWaitCsvMaturity
+
+	default:
+		s.WaitingFor = ResolverWaitSweepConf
+	}
+
+	return s
+}
+
 // initReport initializes the pending channels report for this resolver.
 func (c *commitSweepResolver) initReport() {
 	amt := btcutil.Amount(
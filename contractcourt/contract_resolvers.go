@@ -125,6 +125,14 @@ func (r *contractResolverKit) initLogger(resolver ContractResolver) {
 	r.log = build.NewPrefixLog(logPrefix, log)
 }
 
+// notifyStatus signals that the status of the given resolver changed without
+// it being checkpointed.
+func (r *contractResolverKit) notifyStatus(resolver ContractResolver) {
+	if r.NotifyResolverStatus != nil {
+		r.NotifyResolverStatus(resolver)
+	}
+}
+
 var (
 	// errResolverShuttingDown is returned when the resolver stops
 	// progressing because it received the quit signal.
@@ -108,7 +108,9 @@ func (h *htlcIncomingContestResolver) Resolve() (ContractResolver, error) {
 		// will time it out and get their funds back. This situation
 		// can present itself when we crash before processRemoteAdds in
 		// the link has ran.
+		h.reportLock.Lock()
 		h.resolved = true
+		h.reportLock.Unlock()
 
 		if err := h.processFinalHtlcFail(); err != nil {
 			return nil, err
@@ -161,7 +163,9 @@ func (h *htlcIncomingContestResolver) Resolve() (ContractResolver, error) {
 		log.Infof("%T(%v): HTLC has timed out (expiry=%v, height=%v), "+
 			"abandoning", h, h.htlcResolution.ClaimOutpoint,
 			h.htlcExpiry, currentHeight)
+		h.reportLock.Lock()
 		h.resolved = true
+		h.reportLock.Unlock()
 
 		if err := h.processFinalHtlcFail(); err != nil {
 			return nil, err
@@ -261,7 +265,9 @@ func (h *htlcIncomingContestResolver) Resolve() (ContractResolver, error) {
 				h.htlcResolution.ClaimOutpoint,
 				h.htlcExpiry, currentHeight)
 
+			h.reportLock.Lock()
 			h.resolved = true
+			h.reportLock.Unlock()
 
 			if err := h.processFinalHtlcFail(); err != nil {
 				return nil, err
@@ -414,7 +420,9 @@ func (h *htlcIncomingContestResolver) Resolve() (ContractResolver, error) {
 					"(expiry=%v, height=%v), abandoning", h,
 					h.htlcResolution.ClaimOutpoint,
 					h.htlcExpiry, currentHeight)
+				h.reportLock.Lock()
 				h.resolved = true
+				h.reportLock.Unlock()
 
 				if err := h.processFinalHtlcFail(); err != nil {
 					return nil, err
@@ -455,6 +463,25 @@ func (h *htlcIncomingContestResolver) report() *ContractReport {
 	}
 }
 
+// status returns a snapshot of the current state of the resolver.
+//
+// NOTE: Part of the statusContractResolver interface.
+func (h *htlcIncomingContestResolver) status(_ uint32) *ResolverStatus {
+	h.reportLock.Lock()
+	resolved := h.resolved
+	h.reportLock.Unlock()
+
+	s := htlcResolverStatus(
+		h.ChanPoint, ResolverKindHtlcIncomingContest, *h.report(),
+		resolved,
+	)
+	if !s.Resolved {
+		s.WaitingFor = ResolverWaitPreimage
+	}
+
+	return s
+}
+
 // Stop signals the resolver to cancel any current resolution processes, and
 // suspend.
 //
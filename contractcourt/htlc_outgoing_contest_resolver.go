@@ -172,6 +172,24 @@ func (h *htlcOutgoingContestResolver) report() *ContractReport {
 	}
 }
 
+// status returns a snapshot of the current state of the resolver.
+//
+// NOTE: Part of the statusContractResolver interface.
+func (h *htlcOutgoingContestResolver) status(height uint32) *ResolverStatus {
+	h.reportLock.Lock()
+	resolved := h.resolved
+	h.reportLock.Unlock()
+
+	s := h.timeoutStatus(
+		ResolverKindHtlcOutgoingContest, *h.report(), resolved, height,
+	)
+	if !s.Resolved {
+		s.WaitingFor = ResolverWaitHtlcExpiry
+	}
+
+	return s
+}
+
 // Stop signals the resolver to cancel any current resolution processes, and
 // suspend.
 //
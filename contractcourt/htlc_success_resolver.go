@@ -157,9 +157,9 @@ func (h *htlcSuccessResolver) Resolve() (ContractResolver, error) {
 	h.reportLock.Lock()
 	h.currentReport.RecoveredBalance = h.currentReport.LimboBalance
 	h.currentReport.LimboBalance = 0
+	h.resolved = true
 	h.reportLock.Unlock()
 
-	h.resolved = true
 	return nil, h.checkpointClaim(
 		spend.SpenderTxHash, channeldb.ResolverOutcomeClaimed,
 	)
@@ -189,8 +189,8 @@ func (h *htlcSuccessResolver) broadcastSuccessTx() (*wire.OutPoint, error) {
 	// the claiming process.
 	//
 	// TODO(roasbeef): after changing sighashes send to tx bundler
-	label := labels.MakeLabel(
-		labels.LabelTypeChannelClose, &h.ShortChanID,
+	label := labels.MakeChannelLabel(
+		labels.LabelTypeChannelClose, &h.ShortChanID, h.ChanPoint,
 	)
 	err := h.PublishTx(h.htlcResolution.SignedSuccessTx, label)
 	if err != nil {
@@ -328,6 +328,8 @@ func (h *htlcSuccessResolver) broadcastReSignedSuccessTx() (
 	h.currentReport.MaturityHeight = waitHeight
 	h.reportLock.Unlock()
 
+	h.notifyStatus(h)
+
 	if h.hasCLTV() {
 		log.Infof("%T(%x): waiting for CSV and CLTV lock to "+
 			"expire at height %v", h, h.htlc.RHash[:],
@@ -469,8 +471,8 @@ func (h *htlcSuccessResolver) resolveRemoteCommitOutput() (
 
 	// Regardless of whether an existing transaction was found or newly
 	// constructed, we'll broadcast the sweep transaction to the network.
-	label := labels.MakeLabel(
-		labels.LabelTypeChannelClose, &h.ShortChanID,
+	label := labels.MakeChannelLabel(
+		labels.LabelTypeChannelClose, &h.ShortChanID, h.ChanPoint,
 	)
 	err = h.PublishTx(h.sweepTx, label)
 	if err != nil {
@@ -496,7 +498,9 @@ func (h *htlcSuccessResolver) resolveRemoteCommitOutput() (
 
 	// Once the transaction has received a sufficient number of
 	// confirmations, we'll mark ourselves as fully resolved and exit.
+	h.reportLock.Lock()
 	h.resolved = true
+	h.reportLock.Unlock()
 
 	// Checkpoint the resolver, and write the outcome to disk.
 	return nil, h.checkpointClaim(
@@ -596,6 +600,65 @@ func (h *htlcSuccessResolver) report() *ContractReport {
 	return &cpy
 }
 
+// status returns a snapshot of the current state of the resolver.
+//
+// NOTE: Part of the statusContractResolver interface.
+func (h *htlcSuccessResolver) status(height uint32) *ResolverStatus {
+	h.reportLock.Lock()
+	report := h.currentReport
+	resolved := h.resolved
+	h.reportLock.Unlock()
+
+	s := htlcResolverStatus(
+		h.ChanPoint, ResolverKindHtlcSuccess, report, resolved,
+	)
+
+	// Until the second-level transaction confirms, the maturity height of
+	// the report only holds the relative CSV delay.
+	if report.Stage < 2 {
+		s.NextActionHeight = 0
+	}
+
+	switch {
+	case resolved:
+		s.WaitingFor = ResolverWaitNone
+
+	// The second-level output matured and was handed to the sweeper.
+	case report.Stage == 2 && report.MaturityHeight != 0 &&
+		height >= report.MaturityHeight:
+
+		s.WaitingFor = ResolverWaitSweepConf
+
+	// The second-level transaction confirmed, we're waiting for its
+	// output to mature before it is swept.
+	case report.Stage == 2 || (h.outputIncubating &&
+		h.htlcResolution.SignDetails != nil):
+
+		s.Stage = 2
+		s.WaitingFor = ResolverWaitCsvMaturity
+
+	// On our own commitment we need to get the second-level success
+	// transaction confirmed. Without sign details it is already fully
+	// signed, so we know its txid.
+	case h.htlcResolution.SignedSuccessTx != nil:
+		s.WaitingFor = ResolverWaitSecondLevelConf
+		if h.htlcResolution.SignDetails == nil {
+			txid := h.htlcResolution.SignedSuccessTx.TxHash()
+			s.SweepTxid = &txid
+		}
+
+	// On the remote commitment the output is swept directly.
+	default:
+		s.WaitingFor = ResolverWaitSweepConf
+		if h.sweepTx != nil {
+			txid := h.sweepTx.TxHash()
+			s.SweepTxid = &txid
+		}
+	}
+
+	return s
+}
+
 func (h *htlcSuccessResolver) initReport() {
 	// We create the initial report. This will only be reported for
 	// resolvers not handled by the nursery.
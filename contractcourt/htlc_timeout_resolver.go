@@ -217,7 +217,9 @@ func (h *htlcTimeoutResolver) claimCleanUp(
 	}); err != nil {
 		return nil, err
 	}
+	h.reportLock.Lock()
 	h.resolved = true
+	h.reportLock.Unlock()
 
 	// Checkpoint our resolver with a report which reflects the preimage
 	// claim by the remote party.
@@ -631,6 +633,8 @@ func (h *htlcTimeoutResolver) handleCommitSpend(
 		h.currentReport.MaturityHeight = waitHeight
 		h.reportLock.Unlock()
 
+		h.notifyStatus(h)
+
 		if h.hasCLTV() {
 			log.Infof("%T(%x): waiting for CSV and CLTV lock to "+
 				"expire at height %v", h, h.htlc.RHash[:],
@@ -725,8 +729,8 @@ func (h *htlcTimeoutResolver) handleCommitSpend(
 	// With the clean up message sent, we'll now mark the contract
 	// resolved, update the recovered balance, record the timeout and the
 	// sweep txid on disk, and wait.
-	h.resolved = true
 	h.reportLock.Lock()
+	h.resolved = true
 	h.currentReport.RecoveredBalance = h.currentReport.LimboBalance
 	h.currentReport.LimboBalance = 0
 	h.reportLock.Unlock()
@@ -773,6 +777,62 @@ func (h *htlcTimeoutResolver) report() *ContractReport {
 	return &cpy
 }
 
+// status returns a snapshot of the current state of the resolver.
+//
+// NOTE: Part of the statusContractResolver interface.
+func (h *htlcTimeoutResolver) status(height uint32) *ResolverStatus {
+	h.reportLock.Lock()
+	report := h.currentReport
+	resolved := h.resolved
+	h.reportLock.Unlock()
+
+	return h.timeoutStatus(ResolverKindHtlcTimeout, report, resolved, height)
+}
+
+// timeoutStatus derives the status of the timeout resolution from the given
+// report at the given height. It is shared with the outgoing contest
+// resolver, which embeds the timeout resolver.
+func (h *htlcTimeoutResolver) timeoutStatus(kind ResolverKind,
+	report ContractReport, resolved bool, height uint32) *ResolverStatus {
+
+	s := htlcResolverStatus(h.ChanPoint, kind, report, resolved)
+
+	switch {
+	case resolved:
+		s.WaitingFor = ResolverWaitNone
+
+	// The second-level output matured and was handed to the sweeper.
+	case report.Stage == 2 && report.MaturityHeight != 0 &&
+		height >= report.MaturityHeight:
+
+		s.WaitingFor = ResolverWaitSweepConf
+
+	// The second-level transaction confirmed, we're waiting for its
+	// output to mature before it is swept.
+	case report.Stage == 2 || (h.outputIncubating &&
+		h.htlcResolution.SignDetails != nil):
+
+		s.Stage = 2
+		s.WaitingFor = ResolverWaitCsvMaturity
+
+	// On our own commitment we need to get the second-level timeout
+	// transaction confirmed. Without sign details it is already fully
+	// signed, so we know its txid.
+	case h.htlcResolution.SignedTimeoutTx != nil:
+		s.WaitingFor = ResolverWaitSecondLevelConf
+		if h.htlcResolution.SignDetails == nil {
+			txid := h.htlcResolution.SignedTimeoutTx.TxHash()
+			s.SweepTxid = &txid
+		}
+
+	// On the remote commitment the output is swept directly.
+	default:
+		s.WaitingFor = ResolverWaitSweepConf
+	}
+
+	return s
+}
+
 func (h *htlcTimeoutResolver) initReport() {
 	// We create the initial report. This will only be reported for
 	// resolvers not handled by the nursery.
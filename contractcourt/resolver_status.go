@@ -0,0 +1,338 @@
+package contractcourt
+
+import (
+	"sync"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightningnetwork/lnd/lnwallet"
+	"github.com/lightningnetwork/lnd/subscribe"
+)
+
+// ResolverKind identifies the concrete type of contract resolver that a
+// ResolverStatus was produced by.
+type ResolverKind uint8
+
+const (
+	// ResolverKindUnknown is used for resolvers that don't expose any
+	// detailed status.
+	ResolverKindUnknown ResolverKind = iota
+
+	// ResolverKindHtlcTimeout is an outgoing HTLC that has expired and is
+	// being swept back to us via the timeout path.
+	ResolverKindHtlcTimeout
+
+	// ResolverKindHtlcSuccess is an incoming HTLC that we know the
+	// preimage for and are sweeping via the success path.
+	ResolverKindHtlcSuccess
+
+	// ResolverKindHtlcOutgoingContest is an outgoing HTLC that hasn't yet
+	// expired, so the remote party may still claim it with the preimage.
+	ResolverKindHtlcOutgoingContest
+
+	// ResolverKindHtlcIncomingContest is an incoming HTLC for which we
+	// don't know the preimage yet.
+	ResolverKindHtlcIncomingContest
+
+	// ResolverKindCommitSweep is our own output on the commitment
+	// transaction.
+	ResolverKindCommitSweep
+
+	// ResolverKindAnchor is our anchor output on the commitment
+	// transaction.
+	ResolverKindAnchor
+
+	// ResolverKindBreach is a breached channel that is being handled by
+	// the breach arbitrator.
+	ResolverKindBreach
+)
+
+// String returns a human readable name for the resolver kind.
+func (k ResolverKind) String() string {
+	switch k {
+	case ResolverKindHtlcTimeout:
+		return "HtlcTimeout"
+
+	case ResolverKindHtlcSuccess:
+		return "HtlcSuccess"
+
+	case ResolverKindHtlcOutgoingContest:
+		return "HtlcOutgoingContest"
+
+	case ResolverKindHtlcIncomingContest:
+		return "HtlcIncomingContest"
+
+	case ResolverKindCommitSweep:
+		return "CommitSweep"
+
+	case ResolverKindAnchor:
+		return "Anchor"
+
+	case ResolverKindBreach:
+		return "Breach"
+
+	default:
+		return "Unknown"
+	}
+}
+
+// ResolverWaitEvent describes the on-chain (or off-chain) event a resolver is
+// currently blocked on before it can make further progress.
+type ResolverWaitEvent uint8
+
+const (
+	// ResolverWaitNone indicates that the resolver isn't waiting on
+	// anything, either because it is fully resolved or because it is
+	// actively working.
+	ResolverWaitNone ResolverWaitEvent = iota
+
+	// ResolverWaitHtlcExpiry means the resolver waits for the CLTV expiry
+	// of an outgoing HTLC, or for the remote party to claim it with the
+	// preimage before that.
+	ResolverWaitHtlcExpiry
+
+	// ResolverWaitPreimage means the resolver waits to learn the preimage
+	// of an incoming HTLC before it expires.
+	ResolverWaitPreimage
+
+	// ResolverWaitSecondLevelConf means the resolver waits for the
+	// second-level HTLC transaction to confirm.
+	ResolverWaitSecondLevelConf
+
+	// ResolverWaitCsvMaturity means the resolver waits for a relative
+	// (and possibly absolute lease) time lock to expire.
+	ResolverWaitCsvMaturity
+
+	// ResolverWaitSweepConf means the output has been handed to the
+	// sweeper and the resolver waits for the sweep to confirm.
+	ResolverWaitSweepConf
+
+	// ResolverWaitJustice means the resolver waits for the breach
+	// arbitrator to confirm the justice transaction.
+	ResolverWaitJustice
+)
+
+// String returns a human readable description of the wait event.
+func (w ResolverWaitEvent) String() string {
+	switch w {
+	case ResolverWaitNone:
+		return "None"
+
+	case ResolverWaitHtlcExpiry:
+		return "HtlcExpiry"
+
+	case ResolverWaitPreimage:
+		return "Preimage"
+
+	case ResolverWaitSecondLevelConf:
+		return "SecondLevelConf"
+
+	case ResolverWaitCsvMaturity:
+		return "CsvMaturity"
+
+	case ResolverWaitSweepConf:
+		return "SweepConf"
+
+	case ResolverWaitJustice:
+		return "Justice"
+
+	default:
+		return "Unknown"
+	}
+}
+
+// ResolverStatus is a detailed snapshot of the progress of a single contract
+// resolver. It is derived from the state that is persisted for the resolver
+// in the arbitrator log.
+type ResolverStatus struct {
+	// ChanPoint is the channel point of the channel the resolver belongs
+	// to.
+	ChanPoint wire.OutPoint
+
+	// Kind is the type of the resolver.
+	Kind ResolverKind
+
+	// Outpoint is the output that the resolver is trying to claim. For
+	// HTLC outputs in their second stage, this is the output of the
+	// second-level transaction.
+	Outpoint wire.OutPoint
+
+	// Amount is the value of the output being resolved.
+	Amount btcutil.Amount
+
+	// Stage is the stage of the resolution. HTLCs that go through a
+	// second-level transaction are in stage 1 until that transaction
+	// confirms and in stage 2 afterwards. All other outputs are always in
+	// stage 1.
+	Stage uint32
+
+	// WaitingFor is the event the resolver currently waits for.
+	WaitingFor ResolverWaitEvent
+
+	// SweepTxid is the txid of the transaction we expect to spend the
+	// output, if it is already known. This is nil if the output is swept
+	// by the sweeper, as the final transaction isn't known in advance.
+	SweepTxid *chainhash.Hash
+
+	// NextActionHeight is the block height at which the resolver will be
+	// able to take its next action, e.g. the CLTV expiry of an HTLC or
+	// the height a CSV lock expires. Zero if unknown or not applicable.
+	NextActionHeight uint32
+
+	// Resolved is true once the resolver has fully resolved its contract.
+	Resolved bool
+}
+
+// equal returns true if both statuses describe the same state.
+func (s *ResolverStatus) equal(other *ResolverStatus) bool {
+	sameTxid := s.SweepTxid == other.SweepTxid ||
+		(s.SweepTxid != nil && other.SweepTxid != nil &&
+			*s.SweepTxid == *other.SweepTxid)
+
+	return sameTxid && s.ChanPoint == other.ChanPoint &&
+		s.Kind == other.Kind && s.Outpoint == other.Outpoint &&
+		s.Amount == other.Amount && s.Stage == other.Stage &&
+		s.WaitingFor == other.WaitingFor &&
+		s.NextActionHeight == other.NextActionHeight &&
+		s.Resolved == other.Resolved
+}
+
+// statusContractResolver is a ContractResolver that is able to report a
+// detailed status of its progress.
+type statusContractResolver interface {
+	ContractResolver
+
+	// status returns a snapshot of the current state of the resolver at
+	// the given block height.
+	status(height uint32) *ResolverStatus
+}
+
+// htlcResolverStatus derives the common part of an HTLC resolver status from
+// its report.
+func htlcResolverStatus(chanPoint wire.OutPoint, kind ResolverKind,
+	report ContractReport, resolved bool) *ResolverStatus {
+
+	return &ResolverStatus{
+		ChanPoint:        chanPoint,
+		Kind:             kind,
+		Outpoint:         report.Outpoint,
+		Amount:           report.Amount,
+		Stage:            report.Stage,
+		NextActionHeight: report.MaturityHeight,
+		Resolved:         resolved,
+	}
+}
+
+// ResolutionUpdate is sent to subscribers of the ChainArbitrator whenever the
+// state of one of the active contract resolvers changes.
+type ResolutionUpdate struct {
+	// Status is the new status of the resolver.
+	Status *ResolverStatus
+}
+
+// resolutionNotifier fans out resolver status updates to all subscribers.
+type resolutionNotifier struct {
+	started sync.Once
+	stopped sync.Once
+
+	// chainIO is used to learn the current block height the status of
+	// the resolvers is derived at.
+	chainIO lnwallet.BlockChainIO
+
+	ntfnServer *subscribe.Server
+
+	// lastStatus holds the last status sent for each unresolved resolver.
+	// The same state change is reported by the resolver itself, by its
+	// checkpoint and by the channel arbitrator, so we use it to only send
+	// updates that actually changed the status.
+	lastStatus map[string]*ResolverStatus
+
+	mtx sync.Mutex
+}
+
+// newResolutionNotifier creates a new resolution notifier.
+func newResolutionNotifier(
+	chainIO lnwallet.BlockChainIO) *resolutionNotifier {
+
+	return &resolutionNotifier{
+		chainIO:    chainIO,
+		ntfnServer: subscribe.NewServer(),
+		lastStatus: make(map[string]*ResolverStatus),
+	}
+}
+
+// Start starts the notifier's subscription server.
+func (r *resolutionNotifier) Start() error {
+	var err error
+	r.started.Do(func() {
+		err = r.ntfnServer.Start()
+	})
+	return err
+}
+
+// Stop stops the notifier's subscription server.
+func (r *resolutionNotifier) Stop() error {
+	var err error
+	r.stopped.Do(func() {
+		err = r.ntfnServer.Stop()
+	})
+	return err
+}
+
+// notify sends the current status of the resolver to all subscribers. Any
+// resolver that doesn't implement statusContractResolver is ignored.
+func (r *resolutionNotifier) notify(resolver ContractResolver) {
+	s, ok := resolver.(statusContractResolver)
+	if !ok {
+		return
+	}
+
+	_, height, err := r.chainIO.GetBestBlock()
+	if err != nil {
+		log.Warnf("Unable to fetch best block for resolution update: "+
+			"%v", err)
+		return
+	}
+
+	status := s.status(uint32(height))
+	if status == nil {
+		return
+	}
+
+	// Skip the update if the status didn't change since the last one we
+	// sent for this resolver. Once resolved, no further updates follow,
+	// so we can forget about it.
+	key := status.ChanPoint.String() + string(resolver.ResolverKey())
+
+	r.mtx.Lock()
+	last, ok := r.lastStatus[key]
+	if ok && last.equal(status) {
+		r.mtx.Unlock()
+		return
+	}
+	if status.Resolved {
+		delete(r.lastStatus, key)
+	} else {
+		r.lastStatus[key] = status
+	}
+	r.mtx.Unlock()
+
+	err = r.ntfnServer.SendUpdate(&ResolutionUpdate{Status: status})
+	if err != nil {
+		log.Warnf("Unable to send resolution update for %v: %v",
+			status.ChanPoint, err)
+	}
+}
+
+// Compile time assertions to ensure all resolvers that can be tracked over the
+// rpc interface implement the statusContractResolver interface.
+var (
+	_ statusContractResolver = (*htlcTimeoutResolver)(nil)
+	_ statusContractResolver = (*htlcSuccessResolver)(nil)
+	_ statusContractResolver = (*htlcOutgoingContestResolver)(nil)
+	_ statusContractResolver = (*htlcIncomingContestResolver)(nil)
+	_ statusContractResolver = (*commitSweepResolver)(nil)
+	_ statusContractResolver = (*anchorResolver)(nil)
+	_ statusContractResolver = (*breachResolver)(nil)
+)
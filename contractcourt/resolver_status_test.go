@@ -0,0 +1,212 @@
+package contractcourt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/input"
+	"github.com/lightningnetwork/lnd/lnwallet"
+	"github.com/stretchr/testify/require"
+)
+
+// TestResolverStatus asserts that the contract resolvers derive the expected
+// detailed status from their state.
+func TestResolverStatus(t *testing.T) {
+	t.Parallel()
+
+	chanPoint := wire.OutPoint{Index: 1}
+	claimOutpoint := wire.OutPoint{Index: 2}
+	resCfg := ResolverConfig{
+		ChannelArbitratorConfig: ChannelArbitratorConfig{
+			ChanPoint: chanPoint,
+		},
+	}
+	htlc := channeldb.HTLC{Amt: 10_000_000}
+
+	timeoutTx := &wire.MsgTx{TxOut: []*wire.TxOut{{Value: 9_000}}}
+	timeoutTxid := timeoutTx.TxHash()
+
+	// An outgoing HTLC on our commitment that has yet to expire waits for
+	// its expiry.
+	outgoing := newOutgoingContestResolver(
+		lnwallet.OutgoingHtlcResolution{
+			Expiry:          100,
+			SignedTimeoutTx: timeoutTx,
+			ClaimOutpoint:   claimOutpoint,
+		}, 0, htlc, resCfg,
+	)
+	require.Equal(t, &ResolverStatus{
+		ChanPoint:        chanPoint,
+		Kind:             ResolverKindHtlcOutgoingContest,
+		Outpoint:         claimOutpoint,
+		Amount:           9_000,
+		Stage:            1,
+		WaitingFor:       ResolverWaitHtlcExpiry,
+		SweepTxid:        &timeoutTxid,
+		NextActionHeight: 100,
+	}, outgoing.status(90))
+
+	// Once expired, the fully signed timeout transaction needs to confirm.
+	timeout := outgoing.htlcTimeoutResolver
+	require.Equal(t, &ResolverStatus{
+		ChanPoint:        chanPoint,
+		Kind:             ResolverKindHtlcTimeout,
+		Outpoint:         claimOutpoint,
+		Amount:           9_000,
+		Stage:            1,
+		WaitingFor:       ResolverWaitSecondLevelConf,
+		SweepTxid:        &timeoutTxid,
+		NextActionHeight: 100,
+	}, timeout.status(100))
+
+	// After the second-level transaction confirmed, the output waits for
+	// its CSV lock to expire.
+	timeout.htlcResolution.SignDetails = &input.SignDetails{}
+	timeout.currentReport.Stage = 2
+	timeout.currentReport.MaturityHeight = 250
+	status := timeout.status(249)
+	require.Equal(t, uint32(2), status.Stage)
+	require.Equal(t, ResolverWaitCsvMaturity, status.WaitingFor)
+	require.Equal(t, uint32(250), status.NextActionHeight)
+	require.Nil(t, status.SweepTxid)
+
+	// Once the CSV lock expired, the output is swept.
+	status = timeout.status(250)
+	require.Equal(t, uint32(2), status.Stage)
+	require.Equal(t, ResolverWaitSweepConf, status.WaitingFor)
+
+	timeout.resolved = true
+	status = timeout.status(250)
+	require.True(t, status.Resolved)
+	require.Equal(t, ResolverWaitNone, status.WaitingFor)
+
+	// An incoming HTLC on the remote commitment first waits for the
+	// preimage, and is then swept directly.
+	incoming := newIncomingContestResolver(
+		lnwallet.IncomingHtlcResolution{
+			CsvDelay:      1,
+			ClaimOutpoint: claimOutpoint,
+		}, 0, htlc, resCfg,
+	)
+	incoming.htlcExpiry = 120
+	require.Equal(t, &ResolverStatus{
+		ChanPoint:        chanPoint,
+		Kind:             ResolverKindHtlcIncomingContest,
+		Outpoint:         claimOutpoint,
+		Amount:           10_000,
+		Stage:            1,
+		WaitingFor:       ResolverWaitPreimage,
+		NextActionHeight: 120,
+	}, incoming.status(100))
+
+	success := incoming.htlcSuccessResolver
+	require.Equal(t, &ResolverStatus{
+		ChanPoint:  chanPoint,
+		Kind:       ResolverKindHtlcSuccess,
+		Outpoint:   claimOutpoint,
+		Amount:     10_000,
+		Stage:      1,
+		WaitingFor: ResolverWaitSweepConf,
+	}, success.status(100))
+
+	// The commitment output waits for the CSV lock until it can be swept.
+	commit := newCommitSweepResolver(
+		lnwallet.CommitOutputResolution{
+			SelfOutPoint: claimOutpoint,
+			SelfOutputSignDesc: input.SignDescriptor{
+				Output: &wire.TxOut{Value: 50_000},
+			},
+			MaturityDelay: 144,
+		}, 0, chanPoint, resCfg,
+	)
+	require.Equal(t, &ResolverStatus{
+		ChanPoint:  chanPoint,
+		Kind:       ResolverKindCommitSweep,
+		Outpoint:   claimOutpoint,
+		Amount:     50_000,
+		Stage:      1,
+		WaitingFor: ResolverWaitCsvMaturity,
+	}, commit.status(100))
+
+	// Once the commitment confirmed, the output waits until the CSV lock
+	// expired and is swept afterwards.
+	commit.currentReport.MaturityHeight = 244
+	status = commit.status(243)
+	require.Equal(t, ResolverWaitCsvMaturity, status.WaitingFor)
+	require.Equal(t, uint32(244), status.NextActionHeight)
+
+	status = commit.status(244)
+	require.Equal(t, ResolverWaitSweepConf, status.WaitingFor)
+
+	breach := newBreachResolver(resCfg)
+	require.Equal(t, &ResolverStatus{
+		ChanPoint:  chanPoint,
+		Kind:       ResolverKindBreach,
+		Outpoint:   chanPoint,
+		Stage:      1,
+		WaitingFor: ResolverWaitJustice,
+	}, breach.status(100))
+}
+
+// TestResolutionNotifier asserts that resolver updates are delivered to
+// subscribers, and that unchanged statuses are only delivered once.
+func TestResolutionNotifier(t *testing.T) {
+	t.Parallel()
+
+	notifier := newResolutionNotifier(&mockChainIO{})
+	require.NoError(t, notifier.Start())
+	t.Cleanup(func() {
+		require.NoError(t, notifier.Stop())
+	})
+
+	sub, err := notifier.ntfnServer.Subscribe()
+	require.NoError(t, err)
+	defer sub.Cancel()
+
+	chanPoint := wire.OutPoint{Index: 3}
+	breach := newBreachResolver(ResolverConfig{
+		ChannelArbitratorConfig: ChannelArbitratorConfig{
+			ChanPoint: chanPoint,
+		},
+	})
+
+	assertUpdate := func(resolved bool) {
+		t.Helper()
+
+		select {
+		case e := <-sub.Updates():
+			update, ok := e.(*ResolutionUpdate)
+			require.True(t, ok)
+			require.Equal(t, chanPoint, update.Status.ChanPoint)
+			require.Equal(t, ResolverKindBreach, update.Status.Kind)
+			require.Equal(t, resolved, update.Status.Resolved)
+
+		case <-time.After(defaultTimeout):
+			t.Fatal("no resolution update received")
+		}
+	}
+
+	assertNoUpdate := func() {
+		t.Helper()
+
+		select {
+		case <-sub.Updates():
+			t.Fatal("unexpected resolution update")
+
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+
+	notifier.notify(breach)
+	assertUpdate(false)
+
+	// Notifying the same status again must not result in another update.
+	notifier.notify(breach)
+	assertNoUpdate()
+
+	breach.resolved = true
+	notifier.notify(breach)
+	assertUpdate(true)
+}
@@ -918,7 +918,7 @@ func (u *UtxoNursery) sweepCribOutput(classHeight uint32, baby *babyOutput) erro
 
 	// We'll now broadcast the HTLC transaction, then wait for it to be
 	// confirmed before transitioning it to kindergarten.
-	label := labels.MakeLabel(labels.LabelTypeSweepTransaction, nil)
+	label := labels.MakeSweepLabel(baby.WitnessType().String())
 	err := u.cfg.PublishTransaction(baby.timeoutTx, label)
 
 	// In case the tx does not meet mempool fee requirements we continue
@@ -0,0 +1,571 @@
+package eventlog
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lightningnetwork/lnd/kvdb"
+	"github.com/lightningnetwork/lnd/queue"
+	"github.com/lightningnetwork/lnd/subscribe"
+)
+
+// replayBatchSize is the number of events that are read from the database at
+// once when replaying events to a subscriber. Reading in batches avoids keeping
+// a database transaction open while waiting for a slow subscriber.
+const replayBatchSize = 100
+
+const (
+	// maxStoreBatchSize is the maximum number of events that are stored
+	// in a single database transaction.
+	maxStoreBatchSize = 100
+
+	// storeBatchInterval is the maximum time a recorded event waits to be
+	// stored together with the events recorded after it. Batching keeps
+	// the database writes off the hot paths that record events, like the
+	// forwarding of HTLCs.
+	storeBatchInterval = 100 * time.Millisecond
+)
+
+var (
+	// eventLogBucket is the top level bucket of the event log. It holds a
+	// sub-bucket for each event stream.
+	//
+	// maps: stream -> sequence number -> encoded event
+	eventLogBucket = []byte("event-log")
+
+	// ErrLogStopped is returned when the log is shutting down.
+	ErrLogStopped = errors.New("event log shutting down")
+
+	// ErrLogDisabled is returned when events are to be replayed while the
+	// event log is disabled.
+	ErrLogDisabled = errors.New("event log disabled, replaying events " +
+		"requires a non-zero event-log-size")
+
+	// errNoStreamBucket is returned when the bucket of an event stream
+	// doesn't exist.
+	errNoStreamBucket = errors.New("event stream bucket does not exist")
+)
+
+// Recorder records the events of a notifier.
+type Recorder interface {
+	// Record adds the given event to the log. It must not block the
+	// caller on the database.
+	Record(event interface{})
+}
+
+// Encoder converts an event into the payload stored in the log. A nil
+// payload means that the event isn't part of the stream and is skipped.
+type Encoder func(event interface{}) ([]byte, error)
+
+// Event is an event stored in the log.
+type Event struct {
+	// Sequence is the sequence number of the event. Sequence numbers start
+	// at one and grow monotonically without gaps, also across restarts.
+	Sequence uint64
+
+	// Payload is the encoded event.
+	Payload []byte
+}
+
+// Config holds the configuration of an event log.
+type Config struct {
+	// DB is the database the events are stored in.
+	DB kvdb.Backend
+
+	// Stream is the name of the event stream. Each stream has its own
+	// sequence numbers.
+	Stream string
+
+	// MaxEvents is the maximum number of events kept in the log. Once
+	// the log is full, the oldest events are pruned.
+	MaxEvents uint64
+
+	// Encode encodes the recorded events.
+	Encode Encoder
+}
+
+// Log is a bounded persistent log of the events of a single stream. Events
+// are recorded asynchronously, numbered and stored in the database in batches.
+// Clients can subscribe to the log to receive new events, optionally after
+// replaying the stored events they missed.
+type Log struct {
+	started sync.Once
+	stopped sync.Once
+
+	cfg *Config
+
+	// events queues the recorded events until they are stored.
+	events *queue.ConcurrentQueue
+
+	// ntfnServer notifies subscribers of stored events.
+	ntfnServer *subscribe.Server
+
+	// lastSeq is the sequence number of the last stored event.
+	lastSeq uint64
+	seqMtx  sync.Mutex
+
+	wg   sync.WaitGroup
+	quit chan struct{}
+}
+
+// A compile-time check to ensure Log implements the Recorder interface.
+var _ Recorder = (*Log)(nil)
+
+// New creates a new event log, creating the bucket of its stream if it
+// doesn't exist yet.
+func New(cfg *Config) (*Log, error) {
+	if cfg.MaxEvents == 0 {
+		return nil, fmt.Errorf("event log %v must hold at least one "+
+			"event", cfg.Stream)
+	}
+
+	var lastSeq uint64
+	err := kvdb.Update(cfg.DB, func(tx kvdb.RwTx) error {
+		logBucket, err := tx.CreateTopLevelBucket(eventLogBucket)
+		if err != nil {
+			return err
+		}
+
+		bucket, err := logBucket.CreateBucketIfNotExists(
+			[]byte(cfg.Stream),
+		)
+		if err != nil {
+			return err
+		}
+
+		// Continue the sequence of the events stored before, if any.
+		k, _ := bucket.ReadWriteCursor().Last()
+		if k != nil {
+			lastSeq = binary.BigEndian.Uint64(k)
+		}
+
+		return nil
+	}, func() {
+		lastSeq = 0
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Log{
+		cfg:        cfg,
+		events:     queue.NewConcurrentQueue(20),
+		ntfnServer: subscribe.NewServer(),
+		lastSeq:    lastSeq,
+		quit:       make(chan struct{}),
+	}, nil
+}
+
+// Start starts the goroutine that stores the recorded events.
+func (l *Log) Start() error {
+	var err error
+	l.started.Do(func() {
+		log.Infof("Event log %v starting at sequence %d", l.cfg.Stream,
+			l.LastSequence())
+
+		if err = l.ntfnServer.Start(); err != nil {
+			return
+		}
+		l.events.Start()
+
+		l.wg.Add(1)
+		go l.storeEvents()
+	})
+
+	return err
+}
+
+// Stop stores the events that are still queued and shuts the log down.
+func (l *Log) Stop() error {
+	var err error
+	l.stopped.Do(func() {
+		log.Infof("Event log %v shutting down...", l.cfg.Stream)
+		defer log.Debugf("Event log %v shutdown complete",
+			l.cfg.Stream)
+
+		close(l.quit)
+		l.wg.Wait()
+
+		l.events.Stop()
+		err = l.ntfnServer.Stop()
+	})
+
+	return err
+}
+
+// Record queues the given event to be stored in the log.
+//
+// NOTE: Part of the Recorder interface.
+func (l *Log) Record(event interface{}) {
+	select {
+	case l.events.ChanIn() <- event:
+	case <-l.quit:
+		log.Warnf("Event log %v stopped, dropping event %T",
+			l.cfg.Stream, event)
+	}
+}
+
+// LastSequence returns the sequence number of the last stored event, or zero
+// if no event has been stored yet.
+func (l *Log) LastSequence() uint64 {
+	l.seqMtx.Lock()
+	defer l.seqMtx.Unlock()
+
+	return l.lastSeq
+}
+
+// storeEvents encodes the recorded events and stores them in batches, either
+// once a batch is full or once its first event waited for the batch interval.
+// The subscribers of the log are notified after each batch.
+//
+// NOTE: MUST be run as a goroutine.
+func (l *Log) storeEvents() {
+	defer l.wg.Done()
+
+	var (
+		batch      [][]byte
+		batchTimer <-chan time.Time
+	)
+
+	addEvent := func(event interface{}) {
+		payload := l.encodeEvent(event)
+		if payload == nil {
+			return
+		}
+		batch = append(batch, payload)
+
+		switch {
+		case len(batch) >= maxStoreBatchSize:
+			l.storeBatch(batch)
+			batch, batchTimer = nil, nil
+
+		case batchTimer == nil:
+			batchTimer = time.After(storeBatchInterval)
+		}
+	}
+
+	for {
+		select {
+		case event := <-l.events.ChanOut():
+			addEvent(event)
+
+		case <-batchTimer:
+			l.storeBatch(batch)
+			batch, batchTimer = nil, nil
+
+		case <-l.quit:
+			// Store the events that were recorded right before
+			// the shutdown, so they aren't lost.
+			for {
+				select {
+				case event := <-l.events.ChanOut():
+					addEvent(event)
+
+				default:
+					l.storeBatch(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+// encodeEvent encodes the given event. Nil is returned if the event isn't part
+// of the stream or can't be encoded.
+func (l *Log) encodeEvent(event interface{}) []byte {
+	payload, err := l.cfg.Encode(event)
+	if err != nil {
+		log.Errorf("Unable to encode %T for event log %v: %v", event,
+			l.cfg.Stream, err)
+
+		return nil
+	}
+
+	return payload
+}
+
+// storeBatch stores the given encoded events in a single database
+// transaction and notifies the subscribers of the log.
+func (l *Log) storeBatch(batch [][]byte) {
+	if len(batch) == 0 {
+		return
+	}
+
+	l.seqMtx.Lock()
+	first := l.lastSeq + 1
+	err := l.appendEvents(first, batch)
+	if err == nil {
+		l.lastSeq += uint64(len(batch))
+	}
+	l.seqMtx.Unlock()
+
+	if err != nil {
+		log.Errorf("Unable to store %d events in event log %v: %v",
+			len(batch), l.cfg.Stream, err)
+
+		return
+	}
+
+	for i, payload := range batch {
+		err := l.ntfnServer.SendUpdate(&Event{
+			Sequence: first + uint64(i),
+			Payload:  payload,
+		})
+		if err != nil {
+			log.Warnf("Unable to send event log %v update: %v",
+				l.cfg.Stream, err)
+		}
+	}
+}
+
+// appendEvents stores the events, numbered from the given sequence number on,
+// and prunes the oldest events if the log exceeds its maximum size.
+func (l *Log) appendEvents(first uint64, payloads [][]byte) error {
+	return kvdb.Update(l.cfg.DB, func(tx kvdb.RwTx) error {
+		bucket, err := l.streamBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		seq := first
+		for _, payload := range payloads {
+			err := bucket.Put(sequenceKey(seq), payload)
+			if err != nil {
+				return err
+			}
+			seq++
+		}
+		last := seq - 1
+
+		// Sequence numbers have no gaps, so the number of stored
+		// events follows from the first and last one.
+		k, _ := bucket.ReadWriteCursor().First()
+		if k == nil {
+			return nil
+		}
+
+		oldest := binary.BigEndian.Uint64(k)
+		for ; last-oldest >= l.cfg.MaxEvents; oldest++ {
+			err := bucket.Delete(sequenceKey(oldest))
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, func() {})
+}
+
+// streamBucket returns the bucket of the event stream of the log.
+func (l *Log) streamBucket(tx kvdb.RwTx) (kvdb.RwBucket, error) {
+	logBucket := tx.ReadWriteBucket(eventLogBucket)
+	if logBucket == nil {
+		return nil, errNoStreamBucket
+	}
+
+	bucket := logBucket.NestedReadWriteBucket([]byte(l.cfg.Stream))
+	if bucket == nil {
+		return nil, errNoStreamBucket
+	}
+
+	return bucket, nil
+}
+
+// FetchEvents returns up to maxEvents stored events with a sequence number
+// greater than the given one, in order. Events that were pruned from the log
+// are skipped, which the caller can detect by a gap in the sequence numbers.
+func (l *Log) FetchEvents(after uint64, maxEvents uint32) ([]*Event, error) {
+	var events []*Event
+	err := kvdb.View(l.cfg.DB, func(tx kvdb.RTx) error {
+		logBucket := tx.ReadBucket(eventLogBucket)
+		if logBucket == nil {
+			return errNoStreamBucket
+		}
+
+		bucket := logBucket.NestedReadBucket([]byte(l.cfg.Stream))
+		if bucket == nil {
+			return errNoStreamBucket
+		}
+
+		cursor := bucket.ReadCursor()
+		k, v := cursor.Seek(sequenceKey(after + 1))
+		for ; k != nil; k, v = cursor.Next() {
+			if uint32(len(events)) >= maxEvents {
+				break
+			}
+
+			events = append(events, &Event{
+				Sequence: binary.BigEndian.Uint64(k),
+				Payload:  append([]byte(nil), v...),
+			})
+		}
+
+		return nil
+	}, func() {
+		events = nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// Subscribe returns a subscription that delivers all events stored after it
+// was created. If replay is set, the stored events with a sequence number
+// greater than the given one are delivered first.
+func (l *Log) Subscribe(replay bool, after uint64) (*Subscription, error) {
+	// Subscribe to new events before reading the stored ones, so that no
+	// event is missed in between.
+	client, err := l.ntfnServer.Subscribe()
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &Subscription{
+		log:     l,
+		client:  client,
+		updates: make(chan *Event),
+		done:    make(chan struct{}),
+		quit:    make(chan struct{}),
+	}
+
+	sub.wg.Add(1)
+	go sub.deliverEvents(replay, after)
+
+	return sub, nil
+}
+
+// Subscription delivers the events of a log to a client.
+type Subscription struct {
+	log    *Log
+	client *subscribe.Client
+
+	updates chan *Event
+
+	// done is closed once the subscription doesn't deliver any more
+	// events, err holds the reason.
+	done chan struct{}
+	err  error
+
+	cancel sync.Once
+	wg     sync.WaitGroup
+	quit   chan struct{}
+}
+
+// Updates returns the channel the events are delivered on.
+func (s *Subscription) Updates() <-chan *Event {
+	return s.updates
+}
+
+// Done returns a channel that is closed once the subscription stops
+// delivering events, either because the log is shutting down or because the
+// stored events couldn't be read. Err returns the reason afterwards.
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns the reason the subscription stopped. It must only be called
+// after the Done channel was closed.
+func (s *Subscription) Err() error {
+	return s.err
+}
+
+// Cancel ends the subscription.
+func (s *Subscription) Cancel() {
+	s.cancel.Do(func() {
+		close(s.quit)
+		s.wg.Wait()
+
+		s.client.Cancel()
+	})
+}
+
+// deliverEvents replays the requested stored events and then forwards new
+// events to the client.
+//
+// NOTE: MUST be run as a goroutine.
+func (s *Subscription) deliverEvents(replay bool, after uint64) {
+	defer s.wg.Done()
+	defer close(s.done)
+
+	// last is the sequence number of the last delivered event. Events
+	// that were stored while replaying are also received from the
+	// subscription, so anything up to this number is skipped.
+	var last uint64
+	if replay {
+		last = after
+		for {
+			events, err := s.log.FetchEvents(last, replayBatchSize)
+			if err != nil {
+				s.err = err
+				return
+			}
+
+			for _, event := range events {
+				if !s.deliver(event) {
+					return
+				}
+				last = event.Sequence
+			}
+
+			if len(events) < replayBatchSize {
+				break
+			}
+		}
+	}
+
+	for {
+		select {
+		case update := <-s.client.Updates():
+			event, ok := update.(*Event)
+			if !ok || event.Sequence <= last {
+				continue
+			}
+
+			if !s.deliver(event) {
+				return
+			}
+			last = event.Sequence
+
+		case <-s.client.Quit():
+			s.err = ErrLogStopped
+			return
+
+		case <-s.log.quit:
+			s.err = ErrLogStopped
+			return
+
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// deliver sends an event to the client. False is returned if the
+// subscription was canceled or the log is shutting down.
+func (s *Subscription) deliver(event *Event) bool {
+	select {
+	case s.updates <- event:
+		return true
+
+	case <-s.log.quit:
+		s.err = ErrLogStopped
+		return false
+
+	case <-s.quit:
+		return false
+	}
+}
+
+// sequenceKey returns the database key of the given sequence number. Keys are
+// big endian, so they are ordered by sequence number.
+func sequenceKey(seq uint64) []byte {
+	var key [8]byte
+	binary.BigEndian.PutUint64(key[:], seq)
+
+	return key[:]
+}
@@ -0,0 +1,201 @@
+package eventlog
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lightningnetwork/lnd/kvdb"
+	"github.com/stretchr/testify/require"
+)
+
+const timeout = 5 * time.Second
+
+// testEvent is an event recorded in the tests. Events with an empty payload
+// are skipped by the test encoder.
+type testEvent string
+
+// encodeTestEvent encodes the events recorded in the tests.
+func encodeTestEvent(event interface{}) ([]byte, error) {
+	e, ok := event.(testEvent)
+	if !ok {
+		return nil, fmt.Errorf("unexpected event %T", event)
+	}
+
+	if e == "" {
+		return nil, nil
+	}
+
+	return []byte(e), nil
+}
+
+// newTestLog creates and starts an event log on the given database.
+func newTestLog(t *testing.T, db kvdb.Backend, maxEvents uint64) *Log {
+	eventLog, err := New(&Config{
+		DB:        db,
+		Stream:    "test",
+		MaxEvents: maxEvents,
+		Encode:    encodeTestEvent,
+	})
+	require.NoError(t, err)
+	require.NoError(t, eventLog.Start())
+
+	return eventLog
+}
+
+// newTestDB returns a database for the tests.
+func newTestDB(t *testing.T) kvdb.Backend {
+	db, cleanUp, err := kvdb.GetTestBackend(t.TempDir(), "eventlog")
+	require.NoError(t, err)
+	t.Cleanup(cleanUp)
+
+	return db
+}
+
+// receiveEvent waits for the next event of the subscription.
+func receiveEvent(t *testing.T, sub *Subscription) *Event {
+	select {
+	case event := <-sub.Updates():
+		return event
+
+	case <-sub.Done():
+		t.Fatalf("subscription ended: %v", sub.Err())
+
+	case <-time.After(timeout):
+		t.Fatalf("no event received")
+	}
+
+	return nil
+}
+
+// waitForSequence waits until the log stored the event with the given
+// sequence number.
+func waitForSequence(t *testing.T, eventLog *Log, seq uint64) {
+	require.Eventually(t, func() bool {
+		return eventLog.LastSequence() == seq
+	}, timeout, 10*time.Millisecond)
+}
+
+// TestEventLogReplay tests that events are numbered across restarts, that the
+// oldest events are pruned and that subscriptions replay the stored events
+// before delivering new ones.
+func TestEventLogReplay(t *testing.T) {
+	t.Parallel()
+
+	db := newTestDB(t)
+	eventLog := newTestLog(t, db, 3)
+
+	eventLog.Record(testEvent("a"))
+	eventLog.Record(testEvent(""))
+	eventLog.Record(testEvent("b"))
+	waitForSequence(t, eventLog, 2)
+	require.NoError(t, eventLog.Stop())
+
+	// The sequence continues after a restart, and the first event is
+	// pruned once the log is full.
+	eventLog = newTestLog(t, db, 3)
+	t.Cleanup(func() {
+		require.NoError(t, eventLog.Stop())
+	})
+	require.EqualValues(t, 2, eventLog.LastSequence())
+
+	eventLog.Record(testEvent("c"))
+	eventLog.Record(testEvent("d"))
+	waitForSequence(t, eventLog, 4)
+
+	events, err := eventLog.FetchEvents(0, 10)
+	require.NoError(t, err)
+	require.Equal(t, []*Event{
+		{Sequence: 2, Payload: []byte("b")},
+		{Sequence: 3, Payload: []byte("c")},
+		{Sequence: 4, Payload: []byte("d")},
+	}, events)
+
+	events, err = eventLog.FetchEvents(2, 1)
+	require.NoError(t, err)
+	require.Equal(t, []*Event{
+		{Sequence: 3, Payload: []byte("c")},
+	}, events)
+
+	// A replaying subscription receives the stored events after the
+	// cursor, followed by new ones.
+	sub, err := eventLog.Subscribe(true, 2)
+	require.NoError(t, err)
+	defer sub.Cancel()
+
+	// A live subscription only receives new events.
+	liveSub, err := eventLog.Subscribe(false, 0)
+	require.NoError(t, err)
+	defer liveSub.Cancel()
+
+	require.EqualValues(t, 3, receiveEvent(t, sub).Sequence)
+	require.EqualValues(t, 4, receiveEvent(t, sub).Sequence)
+
+	eventLog.Record(testEvent("e"))
+
+	event := receiveEvent(t, sub)
+	require.EqualValues(t, 5, event.Sequence)
+	require.Equal(t, []byte("e"), event.Payload)
+
+	event = receiveEvent(t, liveSub)
+	require.EqualValues(t, 5, event.Sequence)
+}
+
+// TestEventLogBatches tests that events recorded in quick succession are
+// stored in batches, that they are delivered in order and that the log is
+// pruned when a batch exceeds its maximum size.
+func TestEventLogBatches(t *testing.T) {
+	t.Parallel()
+
+	const maxEvents = 10
+
+	eventLog := newTestLog(t, newTestDB(t), maxEvents)
+	t.Cleanup(func() {
+		require.NoError(t, eventLog.Stop())
+	})
+
+	sub, err := eventLog.Subscribe(false, 0)
+	require.NoError(t, err)
+	defer sub.Cancel()
+
+	numEvents := maxStoreBatchSize + 5
+	for i := 0; i < numEvents; i++ {
+		eventLog.Record(testEvent(fmt.Sprintf("%d", i)))
+	}
+
+	for i := 1; i <= numEvents; i++ {
+		require.EqualValues(t, i, receiveEvent(t, sub).Sequence)
+	}
+	waitForSequence(t, eventLog, uint64(numEvents))
+
+	events, err := eventLog.FetchEvents(0, 100)
+	require.NoError(t, err)
+	require.Len(t, events, maxEvents)
+	require.EqualValues(t, numEvents-maxEvents+1, events[0].Sequence)
+	require.Equal(
+		t, []byte(fmt.Sprintf("%d", numEvents-1)),
+		events[maxEvents-1].Payload,
+	)
+}
+
+// TestEventLogSubscriptionStop tests that subscriptions end when the log is
+// stopped.
+func TestEventLogSubscriptionStop(t *testing.T) {
+	t.Parallel()
+
+	eventLog := newTestLog(t, newTestDB(t), 10)
+
+	sub, err := eventLog.Subscribe(true, 0)
+	require.NoError(t, err)
+	defer sub.Cancel()
+
+	require.NoError(t, eventLog.Stop())
+
+	select {
+	case <-sub.Done():
+		require.ErrorIs(t, sub.Err(), ErrLogStopped)
+
+	case <-time.After(timeout):
+		t.Fatalf("subscription not ended")
+	}
+}
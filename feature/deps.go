@@ -79,6 +79,13 @@ var deps = depDesc{
 		lnwire.AnchorsZeroFeeHtlcTxOptional: {},
 		lnwire.ExplicitChannelTypeOptional:  {},
 	},
+	lnwire.ZeroFeeCommitmentsOptional: {
+		lnwire.AnchorsZeroFeeHtlcTxOptional: {},
+		lnwire.ExplicitChannelTypeOptional:  {},
+	},
+	lnwire.DynamicCommitmentsOptional: {
+		lnwire.ExplicitChannelTypeOptional: {},
+	},
 }
 
 // ValidateDeps asserts that a feature vector sets all features and their
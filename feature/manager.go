@@ -39,6 +39,14 @@ type Config struct {
 	// channels.
 	NoTaprootChans bool
 
+	// NoZeroFeeCommitments unsets any bits signaling support for zero-fee
+	// commitment channels.
+	NoZeroFeeCommitments bool
+
+	// NoDynamicCommitments unsets any bits signaling support for dynamic
+	// commitment negotiations.
+	NoDynamicCommitments bool
+
 	// NoScriptEnforcementLease unsets any bits signaling support for script
 	// enforced leases.
 	NoScriptEnforcementLease bool
@@ -179,6 +187,14 @@ func newManager(cfg Config, desc setDesc) (*Manager, error) {
 			raw.Unset(lnwire.SimpleTaprootChannelsOptionalStaging)
 			raw.Unset(lnwire.SimpleTaprootChannelsRequiredStaging)
 		}
+		if cfg.NoZeroFeeCommitments {
+			raw.Unset(lnwire.ZeroFeeCommitmentsOptional)
+			raw.Unset(lnwire.ZeroFeeCommitmentsRequired)
+		}
+		if cfg.NoDynamicCommitments {
+			raw.Unset(lnwire.DynamicCommitmentsOptional)
+			raw.Unset(lnwire.DynamicCommitmentsRequired)
+		}
 
 		for _, custom := range cfg.CustomFeatures[set] {
 			if custom > set.Maximum() {
@@ -0,0 +1,466 @@
+// Package feemanager periodically adjusts the forwarding fees of our channels
+// according to a configurable strategy. The fees are kept within a floor and
+// ceiling, and the updates are rate limited per channel to avoid spamming the
+// network with channel updates.
+package feemanager
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/clock"
+	"github.com/lightningnetwork/lnd/lnrpc"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/routing"
+	"github.com/lightningnetwork/lnd/ticker"
+)
+
+const (
+	// maxRecentUpdates is the number of recent fee updates that are kept
+	// in memory.
+	maxRecentUpdates = 100
+)
+
+var (
+	// ErrUnknownStrategy is returned when a strategy is selected that
+	// the fee manager doesn't know of.
+	ErrUnknownStrategy = errors.New("unknown fee strategy")
+)
+
+// Channel describes one of our channels whose fees are managed.
+type Channel struct {
+	// ChanPoint is the funding outpoint of the channel.
+	ChanPoint wire.OutPoint
+
+	// ChanID is the short channel ID of the channel.
+	ChanID lnwire.ShortChannelID
+
+	// Capacity is the capacity of the channel.
+	Capacity btcutil.Amount
+
+	// LocalBalance is our balance in the channel.
+	LocalBalance btcutil.Amount
+
+	// Fee is the fee currently charged for forwarding payments out
+	// through the channel.
+	Fee routing.FeeSchema
+
+	// TimeLockDelta is the currently advertised time lock delta of the
+	// channel. It is left unchanged by fee updates.
+	TimeLockDelta uint32
+}
+
+// FeeUpdate records a fee change made by the fee manager.
+type FeeUpdate struct {
+	// Timestamp is the time the fee was changed at.
+	Timestamp time.Time
+
+	// ChanPoint is the funding outpoint of the channel.
+	ChanPoint wire.OutPoint
+
+	// ChanID is the short channel ID of the channel.
+	ChanID lnwire.ShortChannelID
+
+	// OldFee is the fee charged before the update.
+	OldFee routing.FeeSchema
+
+	// NewFee is the fee charged after the update.
+	NewFee routing.FeeSchema
+
+	// Strategy is the name of the strategy that decided on the new fee.
+	Strategy string
+
+	// Reason describes why the strategy decided on the new fee.
+	Reason string
+}
+
+// Config holds the configuration and dependencies of the fee manager.
+type Config struct {
+	// Active determines whether the fee manager adjusts fees when it is
+	// started.
+	Active bool
+
+	// Strategies are the strategies that can be selected.
+	Strategies []Strategy
+
+	// Strategy is the name of the strategy initially selected.
+	Strategy string
+
+	// Interval is the interval at which fees are adjusted.
+	Interval time.Duration
+
+	// MinUpdateInterval is the minimum time between two updates of the
+	// fees of the same channel.
+	MinUpdateInterval time.Duration
+
+	// MinChange is the minimum relative change of the fee rate or base
+	// fee of a channel for its fees to be updated.
+	MinChange float64
+
+	// Bounds are the floor and ceiling the fees are kept within.
+	Bounds Bounds
+
+	// VelocityWindow is the period of the forwarding history the
+	// strategies base their decisions on.
+	VelocityWindow time.Duration
+
+	// Channels returns our open channels together with their current
+	// fees.
+	Channels func() ([]Channel, error)
+
+	// ForwardingEvents returns the payments forwarded during the given
+	// time range.
+	ForwardingEvents func(start, end time.Time) (
+		[]channeldb.ForwardingEvent, error)
+
+	// UpdatePolicy updates the forwarding policy of the given channels.
+	UpdatePolicy func(policy routing.ChannelPolicy,
+		chanPoints ...wire.OutPoint) ([]*lnrpc.FailedUpdate, error)
+
+	// Clock is used to determine the current time.
+	Clock clock.Clock
+}
+
+// Status describes the current configuration of the fee manager together
+// with its recent fee updates.
+type Status struct {
+	// Active is true if the fee manager periodically adjusts fees.
+	Active bool
+
+	// Strategy is the name of the selected strategy.
+	Strategy string
+
+	// Strategies are the names of the strategies that can be selected.
+	Strategies []string
+
+	// Interval is the interval at which fees are adjusted.
+	Interval time.Duration
+
+	// MinUpdateInterval is the minimum time between two updates of the
+	// fees of the same channel.
+	MinUpdateInterval time.Duration
+
+	// Bounds are the floor and ceiling the fees are kept within.
+	Bounds Bounds
+
+	// RecentUpdates are the most recent fee updates, oldest first.
+	RecentUpdates []FeeUpdate
+}
+
+// Manager periodically adjusts the fees of our channels.
+type Manager struct {
+	started sync.Once
+	stopped sync.Once
+
+	cfg *Config
+
+	strategies map[string]Strategy
+
+	// mtx guards the fields below it.
+	mtx sync.Mutex
+
+	active   bool
+	strategy Strategy
+	updates  []FeeUpdate
+
+	// evalMtx ensures only one adjustment round runs at a time.
+	evalMtx sync.Mutex
+
+	// lastUpdate holds the time the fees of each channel were last
+	// updated at. It is guarded by evalMtx.
+	lastUpdate map[wire.OutPoint]time.Time
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates a new fee manager.
+func New(cfg *Config) (*Manager, error) {
+	strategies := make(map[string]Strategy, len(cfg.Strategies))
+	for _, strategy := range cfg.Strategies {
+		strategies[strategy.Name()] = strategy
+	}
+
+	strategy, ok := strategies[cfg.Strategy]
+	if !ok {
+		return nil, fmt.Errorf("%w: %v", ErrUnknownStrategy,
+			cfg.Strategy)
+	}
+
+	if cfg.Bounds.MinFeeRate > cfg.Bounds.MaxFeeRate ||
+		cfg.Bounds.MinBaseFee > cfg.Bounds.MaxBaseFee {
+
+		return nil, errors.New("fee floor exceeds fee ceiling")
+	}
+
+	return &Manager{
+		cfg:        cfg,
+		strategies: strategies,
+		active:     cfg.Active,
+		strategy:   strategy,
+		lastUpdate: make(map[wire.OutPoint]time.Time),
+		quit:       make(chan struct{}),
+	}, nil
+}
+
+// Start starts the fee manager.
+func (m *Manager) Start() error {
+	m.started.Do(func() {
+		log.Infof("Fee manager starting with strategy=%v, active=%v",
+			m.cfg.Strategy, m.cfg.Active)
+
+		m.wg.Add(1)
+		go m.adjustLoop(ticker.New(m.cfg.Interval))
+	})
+
+	return nil
+}
+
+// Stop stops the fee manager.
+func (m *Manager) Stop() error {
+	m.stopped.Do(func() {
+		log.Info("Fee manager shutting down...")
+		defer log.Debug("Fee manager shutdown complete")
+
+		close(m.quit)
+		m.wg.Wait()
+	})
+
+	return nil
+}
+
+// adjustLoop adjusts the fees of our channels each time the ticker fires
+// while the fee manager is active.
+//
+// NOTE: This MUST be run as a goroutine.
+func (m *Manager) adjustLoop(t ticker.Ticker) {
+	defer m.wg.Done()
+
+	t.Resume()
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.Ticks():
+			m.mtx.Lock()
+			active := m.active
+			m.mtx.Unlock()
+
+			if !active {
+				continue
+			}
+
+			_, err := m.adjustFees(m.cfg.Clock.Now())
+			if err != nil {
+				log.Errorf("Unable to adjust fees: %v", err)
+			}
+
+		case <-m.quit:
+			return
+		}
+	}
+}
+
+// SetActive enables or disables the periodic fee adjustments.
+func (m *Manager) SetActive(active bool) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	log.Infof("Setting fee manager active=%v", active)
+
+	m.active = active
+}
+
+// SetStrategy selects the strategy with the given name.
+func (m *Manager) SetStrategy(name string) error {
+	strategy, ok := m.strategies[name]
+	if !ok {
+		return fmt.Errorf("%w: %v", ErrUnknownStrategy, name)
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	log.Infof("Setting fee manager strategy=%v", name)
+
+	m.strategy = strategy
+
+	return nil
+}
+
+// Run immediately adjusts the fees of our channels, regardless of whether
+// the fee manager is active. The fee updates made are returned.
+func (m *Manager) Run() ([]FeeUpdate, error) {
+	return m.adjustFees(m.cfg.Clock.Now())
+}
+
+// Status returns the current configuration of the fee manager together with
+// its recent fee updates.
+func (m *Manager) Status() *Status {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	strategies := make([]string, 0, len(m.cfg.Strategies))
+	for _, strategy := range m.cfg.Strategies {
+		strategies = append(strategies, strategy.Name())
+	}
+
+	updates := make([]FeeUpdate, len(m.updates))
+	copy(updates, m.updates)
+
+	return &Status{
+		Active:            m.active,
+		Strategy:          m.strategy.Name(),
+		Strategies:        strategies,
+		Interval:          m.cfg.Interval,
+		MinUpdateInterval: m.cfg.MinUpdateInterval,
+		Bounds:            m.cfg.Bounds,
+		RecentUpdates:     updates,
+	}
+}
+
+// adjustFees runs a single round of fee adjustments at the given time and
+// returns the fee updates made.
+func (m *Manager) adjustFees(now time.Time) ([]FeeUpdate, error) {
+	m.evalMtx.Lock()
+	defer m.evalMtx.Unlock()
+
+	m.mtx.Lock()
+	strategy := m.strategy
+	m.mtx.Unlock()
+
+	channels, err := m.cfg.Channels()
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch channels: %w", err)
+	}
+
+	events, err := m.cfg.ForwardingEvents(
+		now.Add(-m.cfg.VelocityWindow), now,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch forwarding events: %w",
+			err)
+	}
+
+	// Sum up the amounts forwarded out through each channel.
+	volumes := make(map[lnwire.ShortChannelID]lnwire.MilliSatoshi)
+	forwards := make(map[lnwire.ShortChannelID]int)
+	for _, event := range events {
+		volumes[event.OutgoingChanID] += event.AmtOut
+		forwards[event.OutgoingChanID]++
+	}
+
+	var (
+		updates   []FeeUpdate
+		openChans = make(map[wire.OutPoint]struct{}, len(channels))
+	)
+	for _, channel := range channels {
+		openChans[channel.ChanPoint] = struct{}{}
+
+		// Don't update the fees of a channel again before the minimum
+		// update interval has passed.
+		last, ok := m.lastUpdate[channel.ChanPoint]
+		if ok && now.Sub(last) < m.cfg.MinUpdateInterval {
+			continue
+		}
+
+		state := &ChannelState{
+			Channel:        channel,
+			OutgoingVolume: volumes[channel.ChanID],
+			Forwards:       forwards[channel.ChanID],
+		}
+		fee, reason := strategy.FeeSchema(state, m.cfg.Bounds)
+		fee = m.cfg.Bounds.clamp(fee)
+
+		if !significantChange(channel.Fee, fee, m.cfg.MinChange) {
+			continue
+		}
+
+		// Only the fees are changed, the time lock delta is kept and
+		// the htlc limits are left as they are.
+		policy := routing.ChannelPolicy{
+			FeeSchema:     fee,
+			TimeLockDelta: channel.TimeLockDelta,
+		}
+		failed, err := m.cfg.UpdatePolicy(policy, channel.ChanPoint)
+		if err != nil {
+			return updates, fmt.Errorf("unable to update fees of "+
+				"channel %v: %w", channel.ChanPoint, err)
+		}
+		if len(failed) > 0 {
+			log.Warnf("Unable to update fees of channel %v: %v",
+				channel.ChanPoint, failed[0].UpdateError)
+
+			continue
+		}
+
+		update := FeeUpdate{
+			Timestamp: now,
+			ChanPoint: channel.ChanPoint,
+			ChanID:    channel.ChanID,
+			OldFee:    channel.Fee,
+			NewFee:    fee,
+			Strategy:  strategy.Name(),
+			Reason:    reason,
+		}
+		m.lastUpdate[channel.ChanPoint] = now
+		m.recordUpdate(update)
+		updates = append(updates, update)
+
+		log.Infof("Updated fees of channel %v from base_fee=%v, "+
+			"fee_rate=%v to base_fee=%v, fee_rate=%v (%v: %v)",
+			channel.ChanPoint, channel.Fee.BaseFee,
+			channel.Fee.FeeRate, fee.BaseFee, fee.FeeRate,
+			strategy.Name(), reason)
+	}
+
+	// Forget about channels that have been closed.
+	for chanPoint := range m.lastUpdate {
+		if _, ok := openChans[chanPoint]; !ok {
+			delete(m.lastUpdate, chanPoint)
+		}
+	}
+
+	return updates, nil
+}
+
+// recordUpdate adds the given update to the recent fee updates.
+func (m *Manager) recordUpdate(update FeeUpdate) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.updates = append(m.updates, update)
+	if len(m.updates) > maxRecentUpdates {
+		m.updates = m.updates[len(m.updates)-maxRecentUpdates:]
+	}
+}
+
+// significantChange returns true if the fee rate or base fee changed by at
+// least the given fraction of their old value.
+func significantChange(old, new routing.FeeSchema, minChange float64) bool {
+	changed := func(old, new float64) bool {
+		if old == new {
+			return false
+		}
+
+		// Any change from zero is significant.
+		if old == 0 {
+			return true
+		}
+
+		diff := new - old
+		if diff < 0 {
+			diff = -diff
+		}
+
+		return diff/old >= minChange
+	}
+
+	return changed(float64(old.FeeRate), float64(new.FeeRate)) ||
+		changed(float64(old.BaseFee), float64(new.BaseFee))
+}
@@ -0,0 +1,249 @@
+package feemanager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/clock"
+	"github.com/lightningnetwork/lnd/lnrpc"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/routing"
+	"github.com/stretchr/testify/require"
+)
+
+// testBounds are the fee bounds used by the tests.
+var testBounds = Bounds{
+	MinFeeRate: 10,
+	MaxFeeRate: 1010,
+	MinBaseFee: 0,
+	MaxBaseFee: 1000,
+}
+
+// feeManagerHarness holds a fee manager together with the channels and
+// forwarding events it is given.
+type feeManagerHarness struct {
+	*Manager
+
+	channels []Channel
+	events   []channeldb.ForwardingEvent
+	policies map[wire.OutPoint]routing.ChannelPolicy
+}
+
+func newFeeManagerHarness(t *testing.T, strategy string) *feeManagerHarness {
+	h := &feeManagerHarness{
+		policies: make(map[wire.OutPoint]routing.ChannelPolicy),
+	}
+
+	manager, err := New(&Config{
+		Active: true,
+		Strategies: []Strategy{
+			&LiquidityStrategy{Exponent: 1},
+			&VelocityStrategy{TargetVolume: 0.1, Step: 0.5},
+		},
+		Strategy:          strategy,
+		Interval:          time.Hour,
+		MinUpdateInterval: 6 * time.Hour,
+		MinChange:         0.1,
+		Bounds:            testBounds,
+		VelocityWindow:    24 * time.Hour,
+		Channels: func() ([]Channel, error) {
+			return h.channels, nil
+		},
+		ForwardingEvents: func(start,
+			end time.Time) ([]channeldb.ForwardingEvent, error) {
+
+			var events []channeldb.ForwardingEvent
+			for _, event := range h.events {
+				if event.Timestamp.Before(start) ||
+					event.Timestamp.After(end) {
+
+					continue
+				}
+				events = append(events, event)
+			}
+
+			return events, nil
+		},
+		UpdatePolicy: func(policy routing.ChannelPolicy,
+			chanPoints ...wire.OutPoint) ([]*lnrpc.FailedUpdate,
+			error) {
+
+			require.Len(t, chanPoints, 1)
+			h.policies[chanPoints[0]] = policy
+
+			return nil, nil
+		},
+		Clock: clock.NewTestClock(time.Unix(1_700_000_000, 0)),
+	})
+	require.NoError(t, err)
+	h.Manager = manager
+
+	return h
+}
+
+func newTestChannel(i uint32, local int64, fee routing.FeeSchema) Channel {
+	return Channel{
+		ChanPoint:     wire.OutPoint{Index: i},
+		ChanID:        lnwire.NewShortChanIDFromInt(uint64(i)),
+		Capacity:      1_000_000,
+		LocalBalance:  btcutil.Amount(local),
+		Fee:           fee,
+		TimeLockDelta: 80,
+	}
+}
+
+// TestLiquidityStrategy tests that the liquidity strategy sets fees on a
+// curve of the local balance ratio, and that small changes and too frequent
+// updates are skipped.
+func TestLiquidityStrategy(t *testing.T) {
+	t.Parallel()
+
+	h := newFeeManagerHarness(t, StrategyLiquidity)
+
+	oldFee := routing.FeeSchema{BaseFee: 1000, FeeRate: 1}
+	h.channels = []Channel{
+		// A depleted channel gets fees close to the ceiling.
+		newTestChannel(1, 0, oldFee),
+
+		// A channel with most funds on our side gets fees close to
+		// the floor.
+		newTestChannel(2, 900_000, oldFee),
+
+		// A channel whose fees are already close to the curve is left
+		// alone.
+		newTestChannel(3, 500_000, routing.FeeSchema{
+			BaseFee: 490, FeeRate: 500,
+		}),
+	}
+
+	now := time.Unix(1_700_000_000, 0)
+	updates, err := h.adjustFees(now)
+	require.NoError(t, err)
+	require.Len(t, updates, 2)
+
+	require.Equal(t, routing.ChannelPolicy{
+		FeeSchema: routing.FeeSchema{
+			BaseFee: 1000, FeeRate: 1010,
+		},
+		TimeLockDelta: 80,
+	}, h.policies[h.channels[0].ChanPoint])
+	require.Equal(t, routing.FeeSchema{
+		BaseFee: 100, FeeRate: 110,
+	}, h.policies[h.channels[1].ChanPoint].FeeSchema)
+	require.NotContains(t, h.policies, h.channels[2].ChanPoint)
+
+	require.Equal(t, oldFee, updates[0].OldFee)
+	require.Equal(t, StrategyLiquidity, updates[0].Strategy)
+	require.Equal(t, "local balance ratio 0.00", updates[0].Reason)
+
+	// Once the balance of the first channel changed, its fees aren't
+	// updated again before the minimum update interval has passed.
+	h.channels[0] = newTestChannel(1, 1_000_000, updates[0].NewFee)
+	h.channels[1] = newTestChannel(2, 900_000, updates[1].NewFee)
+	h.policies = make(map[wire.OutPoint]routing.ChannelPolicy)
+
+	updates, err = h.adjustFees(now.Add(time.Hour))
+	require.NoError(t, err)
+	require.Empty(t, updates)
+
+	updates, err = h.adjustFees(now.Add(6 * time.Hour))
+	require.NoError(t, err)
+	require.Len(t, updates, 1)
+	require.Equal(t, routing.FeeSchema{
+		BaseFee: 0, FeeRate: 10,
+	}, h.policies[h.channels[0].ChanPoint].FeeSchema)
+
+	status := h.Status()
+	require.True(t, status.Active)
+	require.Equal(t, StrategyLiquidity, status.Strategy)
+	require.Equal(t, []string{StrategyLiquidity, StrategyVelocity},
+		status.Strategies)
+	require.Len(t, status.RecentUpdates, 3)
+}
+
+// TestVelocityStrategy tests that the velocity strategy raises the fees of
+// channels in demand and lowers the fees of idle channels.
+func TestVelocityStrategy(t *testing.T) {
+	t.Parallel()
+
+	h := newFeeManagerHarness(t, StrategyVelocity)
+
+	fee := routing.FeeSchema{BaseFee: 100, FeeRate: 100}
+	h.channels = []Channel{
+		newTestChannel(1, 500_000, fee),
+		newTestChannel(2, 500_000, fee),
+		newTestChannel(3, 500_000, fee),
+	}
+
+	now := time.Unix(1_700_000_000, 0)
+	forward := func(chanID uint32, amt lnwire.MilliSatoshi,
+		age time.Duration) channeldb.ForwardingEvent {
+
+		return channeldb.ForwardingEvent{
+			Timestamp: now.Add(-age),
+			OutgoingChanID: lnwire.NewShortChanIDFromInt(
+				uint64(chanID),
+			),
+			AmtOut: amt,
+		}
+	}
+	h.events = []channeldb.ForwardingEvent{
+		// The first channel forwarded more than the target volume.
+		forward(1, 80_000_000, time.Hour),
+		forward(1, 80_000_000, 2*time.Hour),
+
+		// The second channel forwarded close to the target volume.
+		forward(2, 80_000_000, time.Hour),
+
+		// The third channel only forwarded outside of the window.
+		forward(3, 200_000_000, 48*time.Hour),
+	}
+
+	updates, err := h.adjustFees(now)
+	require.NoError(t, err)
+	require.Len(t, updates, 2)
+
+	require.Equal(t, routing.FeeSchema{
+		BaseFee: 150, FeeRate: 150,
+	}, h.policies[h.channels[0].ChanPoint].FeeSchema)
+	require.Equal(t, "forwarded 160000000 mSAT in 2 payments, above "+
+		"target of 0.10 of capacity", updates[0].Reason)
+
+	require.NotContains(t, h.policies, h.channels[1].ChanPoint)
+
+	require.Equal(t, routing.FeeSchema{
+		BaseFee: 50, FeeRate: 50,
+	}, h.policies[h.channels[2].ChanPoint].FeeSchema)
+}
+
+// TestFeeManagerConfig tests that strategies can only be switched to known
+// strategies and that invalid bounds are rejected.
+func TestFeeManagerConfig(t *testing.T) {
+	t.Parallel()
+
+	h := newFeeManagerHarness(t, StrategyLiquidity)
+
+	require.ErrorIs(t, h.SetStrategy("unknown"), ErrUnknownStrategy)
+	require.NoError(t, h.SetStrategy(StrategyVelocity))
+
+	h.SetActive(false)
+	status := h.Status()
+	require.False(t, status.Active)
+	require.Equal(t, StrategyVelocity, status.Strategy)
+
+	_, err := New(&Config{
+		Strategies: []Strategy{&LiquidityStrategy{}},
+		Strategy:   StrategyVelocity,
+	})
+	require.ErrorIs(t, err, ErrUnknownStrategy)
+
+	_, err = New(&Config{
+		Strategies: []Strategy{&LiquidityStrategy{}},
+		Strategy:   StrategyLiquidity,
+		Bounds:     Bounds{MinFeeRate: 10},
+	})
+	require.ErrorContains(t, err, "fee floor exceeds fee ceiling")
+}
@@ -0,0 +1,233 @@
+package feemanager
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/routing"
+)
+
+const (
+	// StrategyLiquidity is the name of the liquidity ratio strategy.
+	StrategyLiquidity = "liquidity"
+
+	// StrategyVelocity is the name of the forwarding velocity strategy.
+	StrategyVelocity = "velocity"
+
+	// DefaultLiquidityExponent is the default exponent of the liquidity
+	// ratio curve.
+	DefaultLiquidityExponent = 2.0
+
+	// DefaultVelocityStep is the default fraction by which the velocity
+	// strategy raises or lowers the fees of a channel.
+	DefaultVelocityStep = 0.1
+)
+
+// Bounds holds the floor and ceiling the fees set by the fee manager are
+// kept within.
+type Bounds struct {
+	// MinFeeRate is the lowest fee rate in parts per million that is set
+	// on a channel.
+	MinFeeRate uint32
+
+	// MaxFeeRate is the highest fee rate in parts per million that is set
+	// on a channel.
+	MaxFeeRate uint32
+
+	// MinBaseFee is the lowest base fee that is set on a channel.
+	MinBaseFee lnwire.MilliSatoshi
+
+	// MaxBaseFee is the highest base fee that is set on a channel.
+	MaxBaseFee lnwire.MilliSatoshi
+}
+
+// clamp restricts the given fee schema to the bounds.
+func (b Bounds) clamp(fee routing.FeeSchema) routing.FeeSchema {
+	switch {
+	case fee.FeeRate < b.MinFeeRate:
+		fee.FeeRate = b.MinFeeRate
+
+	case fee.FeeRate > b.MaxFeeRate:
+		fee.FeeRate = b.MaxFeeRate
+	}
+
+	switch {
+	case fee.BaseFee < b.MinBaseFee:
+		fee.BaseFee = b.MinBaseFee
+
+	case fee.BaseFee > b.MaxBaseFee:
+		fee.BaseFee = b.MaxBaseFee
+	}
+
+	return fee
+}
+
+// ChannelState is the information a strategy bases the fees of a channel on.
+type ChannelState struct {
+	Channel
+
+	// OutgoingVolume is the amount forwarded out through the channel
+	// during the velocity window.
+	OutgoingVolume lnwire.MilliSatoshi
+
+	// Forwards is the number of payments forwarded out through the
+	// channel during the velocity window.
+	Forwards int
+}
+
+// localRatio returns the fraction of the channel capacity that is on our
+// side of the channel.
+func (c *ChannelState) localRatio() float64 {
+	if c.Capacity == 0 {
+		return 0
+	}
+
+	return float64(c.LocalBalance) / float64(c.Capacity)
+}
+
+// volumeRatio returns the amount forwarded out through the channel during
+// the velocity window as a fraction of its capacity.
+func (c *ChannelState) volumeRatio() float64 {
+	if c.Capacity == 0 {
+		return 0
+	}
+
+	capacity := lnwire.NewMSatFromSatoshis(c.Capacity)
+
+	return float64(c.OutgoingVolume) / float64(capacity)
+}
+
+// Strategy decides on the fees charged for forwarding payments out through a
+// channel.
+type Strategy interface {
+	// Name returns the name of the strategy.
+	Name() string
+
+	// FeeSchema returns the fees the channel should charge within the
+	// given bounds, together with a human readable reason for them.
+	FeeSchema(state *ChannelState, bounds Bounds) (routing.FeeSchema,
+		string)
+}
+
+// LiquidityStrategy sets the fees of a channel on a curve of its local
+// balance ratio. Channels with little local balance charge fees close to the
+// ceiling to preserve their outbound liquidity, while channels holding most
+// of their funds on our side charge fees close to the floor to attract
+// payments that drain them.
+type LiquidityStrategy struct {
+	// Exponent shapes the curve. An exponent of 1 scales the fees
+	// linearly with the remote balance, larger values keep fees low until
+	// the local balance is nearly depleted.
+	Exponent float64
+}
+
+// A compile-time check to ensure LiquidityStrategy implements Strategy.
+var _ Strategy = (*LiquidityStrategy)(nil)
+
+// Name returns the name of the strategy.
+//
+// NOTE: This is part of the Strategy interface.
+func (l *LiquidityStrategy) Name() string {
+	return StrategyLiquidity
+}
+
+// FeeSchema returns the fees the channel should charge within the given
+// bounds, together with a human readable reason for them.
+//
+// NOTE: This is part of the Strategy interface.
+func (l *LiquidityStrategy) FeeSchema(state *ChannelState,
+	bounds Bounds) (routing.FeeSchema, string) {
+
+	ratio := state.localRatio()
+	scale := math.Pow(1-ratio, l.Exponent)
+
+	feeRate := float64(bounds.MinFeeRate) +
+		scale*float64(bounds.MaxFeeRate-bounds.MinFeeRate)
+	baseFee := float64(bounds.MinBaseFee) +
+		scale*float64(bounds.MaxBaseFee-bounds.MinBaseFee)
+
+	fee := routing.FeeSchema{
+		BaseFee: lnwire.MilliSatoshi(math.Round(baseFee)),
+		FeeRate: uint32(math.Round(feeRate)),
+	}
+	reason := fmt.Sprintf("local balance ratio %.2f", ratio)
+
+	return fee, reason
+}
+
+// VelocityStrategy adjusts the fees of a channel to the demand for its
+// outbound liquidity. Fees are raised while the amount forwarded out through
+// the channel exceeds the target volume and lowered while it stays well
+// below it.
+type VelocityStrategy struct {
+	// TargetVolume is the fraction of the channel capacity that is
+	// expected to be forwarded out through the channel during the
+	// velocity window.
+	TargetVolume float64
+
+	// Step is the fraction by which the fees are raised or lowered.
+	Step float64
+}
+
+// A compile-time check to ensure VelocityStrategy implements Strategy.
+var _ Strategy = (*VelocityStrategy)(nil)
+
+// Name returns the name of the strategy.
+//
+// NOTE: This is part of the Strategy interface.
+func (v *VelocityStrategy) Name() string {
+	return StrategyVelocity
+}
+
+// FeeSchema returns the fees the channel should charge within the given
+// bounds, together with a human readable reason for them.
+//
+// NOTE: This is part of the Strategy interface.
+func (v *VelocityStrategy) FeeSchema(state *ChannelState,
+	_ Bounds) (routing.FeeSchema, string) {
+
+	volume := state.volumeRatio()
+	fee := state.Fee
+
+	switch {
+	// The channel is in demand, so we raise its fees. We raise them by at
+	// least one unit to make progress from fees close to zero.
+	case volume > v.TargetVolume:
+		feeRate := uint32(float64(fee.FeeRate) * (1 + v.Step))
+		if feeRate <= fee.FeeRate {
+			feeRate = fee.FeeRate + 1
+		}
+		baseFee := lnwire.MilliSatoshi(
+			float64(fee.BaseFee) * (1 + v.Step),
+		)
+		if baseFee <= fee.BaseFee {
+			baseFee = fee.BaseFee + 1
+		}
+		fee.FeeRate, fee.BaseFee = feeRate, baseFee
+
+		return fee, fmt.Sprintf("forwarded %v in %d payments, "+
+			"above target of %.2f of capacity",
+			state.OutgoingVolume, state.Forwards,
+			v.TargetVolume)
+
+	// The channel is barely used, so we lower its fees to attract
+	// payments.
+	case volume < v.TargetVolume/2:
+		fee.FeeRate = uint32(float64(fee.FeeRate) * (1 - v.Step))
+		fee.BaseFee = lnwire.MilliSatoshi(
+			float64(fee.BaseFee) * (1 - v.Step),
+		)
+
+		return fee, fmt.Sprintf("forwarded %v in %d payments, "+
+			"below target of %.2f of capacity",
+			state.OutgoingVolume, state.Forwards,
+			v.TargetVolume)
+
+	default:
+		return fee, fmt.Sprintf("forwarded %v in %d payments, "+
+			"close to target of %.2f of capacity",
+			state.OutgoingVolume, state.Forwards,
+			v.TargetVolume)
+	}
+}
@@ -774,7 +774,9 @@ func (f *Manager) rebroadcastFundingTx(c *channeldb.OpenChannel) {
 
 	// Set a nil short channel ID at this stage because we do not know it
 	// until our funding tx confirms.
-	label := labels.MakeLabel(labels.LabelTypeChannelOpen, nil)
+	label := labels.MakeChannelLabel(
+		labels.LabelTypeChannelOpen, nil, c.FundingOutpoint,
+	)
 
 	err = f.cfg.PublishTransaction(c.FundingTxn, label)
 	if err != nil {
@@ -1561,6 +1563,15 @@ func (f *Manager) fundeeProcessOpenChannel(peer lnpeer.Peer,
 		log.Error(err)
 		f.failFundingFlow(peer, cid, err)
 
+		return
+
+	// Zero-fee commitments must not carry a commitment fee rate.
+	case commitType.IsZeroFeeCommitment() && msg.FeePerKiloWeight != 0:
+		err = fmt.Errorf("non-zero commitment fee rate %v for "+
+			"zero-fee commitment channel", msg.FeePerKiloWeight)
+		log.Error(err)
+		f.failFundingFlow(peer, cid, err)
+
 		return
 	}
 
@@ -2616,8 +2627,9 @@ func (f *Manager) funderProcessFundingSigned(peer lnpeer.Peer,
 
 		// Set a nil short channel ID at this stage because we do not
 		// know it until our funding tx confirms.
-		label := labels.MakeLabel(
+		label := labels.MakeChannelLabel(
 			labels.LabelTypeChannelOpen, nil,
+			completeChan.FundingOutpoint,
 		)
 
 		err = f.cfg.PublishTransaction(fundingTx, label)
@@ -3014,8 +3026,9 @@ func (f *Manager) makeLabelForTx(c *channeldb.OpenChannel) {
 			shortChanID = c.ZeroConfRealScid()
 		}
 
-		label := labels.MakeLabel(
+		label := labels.MakeChannelLabel(
 			labels.LabelTypeChannelOpen, &shortChanID,
+			c.FundingOutpoint,
 		)
 
 		err := f.cfg.UpdateLabel(c.FundingOutpoint.Hash, label)
@@ -4532,6 +4545,12 @@ func (f *Manager) handleInitFundingMsg(msg *InitFundingMsg) {
 		commitFeePerKw = f.cfg.MaxAnchorsCommitFeeRate
 	}
 
+	// Zero-fee commitments never pay any fees themselves, they are bumped
+	// through their ephemeral anchor instead.
+	if commitType.IsZeroFeeCommitment() {
+		commitFeePerKw = 0
+	}
+
 	var scidFeatureVal bool
 	if hasFeatures(
 		msg.Peer.LocalFeatures(), msg.Peer.RemoteFeatures(),
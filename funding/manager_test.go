@@ -4654,6 +4654,7 @@ func TestCommitmentTypeFundmaxSanityCheck(t *testing.T) {
 		"ANCHORS":                 3,
 		"SCRIPT_ENFORCED_LEASE":   4,
 		"SIMPLE_TAPROOT":          5,
+		"ZERO_FEE_COMMITMENTS":    6,
 	}
 
 	for commitmentType := range lnrpc.CommitmentType_value {
@@ -3,6 +3,7 @@ package htlcswitch
 import (
 	"bytes"
 	"fmt"
+	"time"
 
 	sphinx "github.com/lightningnetwork/lightning-onion"
 	"github.com/lightningnetwork/lnd/htlcswitch/hop"
@@ -92,6 +93,12 @@ type ForwardingError struct {
 	// be nil in the case where we fail to decode failure message sent by
 	// a peer.
 	msg lnwire.FailureMessage
+
+	// HoldTimes are the times the hops of the route reported to have held
+	// the HTLC, starting at the first hop. It only covers the hops that
+	// attributed the failure, and is empty if the failure wasn't
+	// attributed at all.
+	HoldTimes []time.Duration
 }
 
 // WireMessage extracts a valid wire failure message from an internal
@@ -133,14 +140,38 @@ func NewUnknownForwardingError(index int) *ForwardingError {
 	}
 }
 
+// UnreadableFailureError is returned when the failure message of a payment
+// can't be decrypted, but some of the hops of the route attributed the failure
+// correctly. The hop after the last one that attributed the failure either
+// garbled the failure, or doesn't support attributable failures.
+type UnreadableFailureError struct {
+	// HoldTimes are the times the hops that attributed the failure
+	// reported to have held the HTLC, starting at the first hop.
+	HoldTimes []time.Duration
+}
+
+// Error returns the string representation of the error.
+func (u *UnreadableFailureError) Error() string {
+	return fmt.Sprintf("%v, attributed by %v hops",
+		ErrUnreadableFailureMessage, len(u.HoldTimes))
+}
+
+// Unwrap returns ErrUnreadableFailureMessage, so that the error can be handled
+// like any other unreadable failure.
+func (u *UnreadableFailureError) Unwrap() error {
+	return ErrUnreadableFailureMessage
+}
+
 // ErrorDecrypter is an interface that is used to decrypt the onion encrypted
 // failure reason an extra out a well formed error.
 type ErrorDecrypter interface {
 	// DecryptError peels off each layer of onion encryption from the first
 	// hop, to the source of the error. A fully populated
 	// lnwire.FailureMessage is returned along with the source of the
-	// error.
-	DecryptError(lnwire.OpaqueReason) (*ForwardingError, error)
+	// error. The attribution data of the failure, which may be nil, is
+	// verified to determine the hold times of the hops.
+	DecryptError(lnwire.OpaqueReason,
+		lnwire.AttributionData) (*ForwardingError, error)
 }
 
 // UnknownEncrypterType is an error message used to signal that an unexpected
@@ -167,30 +198,74 @@ type OnionErrorDecrypter interface {
 // returned errors to concrete lnwire.FailureMessage instances.
 type SphinxErrorDecrypter struct {
 	OnionErrorDecrypter
+
+	// Circuit is the circuit of the payment attempt, which is used to
+	// verify the attribution data of failures. If it is nil, failures
+	// aren't attributed.
+	Circuit *sphinx.Circuit
 }
 
 // DecryptError peels off each layer of onion encryption from the first hop, to
 // the source of the error. A fully populated lnwire.FailureMessage is returned
-// along with the source of the error.
+// along with the source of the error. If the failure can't be decrypted, but
+// some hops attributed it, an UnreadableFailureError is returned.
 //
 // NOTE: Part of the ErrorDecrypter interface.
-func (s *SphinxErrorDecrypter) DecryptError(reason lnwire.OpaqueReason) (
-	*ForwardingError, error) {
+func (s *SphinxErrorDecrypter) DecryptError(reason lnwire.OpaqueReason,
+	attrData lnwire.AttributionData) (*ForwardingError, error) {
+
+	holdTimes := s.verifyAttribution(reason, attrData)
 
 	failure, err := s.OnionErrorDecrypter.DecryptError(reason)
 	if err != nil {
+		if len(holdTimes) > 0 {
+			return nil, &UnreadableFailureError{
+				HoldTimes: holdTimes,
+			}
+		}
+
 		return nil, err
 	}
 
 	// Decode the failure. If an error occurs, we leave the failure message
 	// field nil.
+	var fwdErr *ForwardingError
 	r := bytes.NewReader(failure.Message)
 	failureMsg, err := lnwire.DecodeFailure(r, 0)
 	if err != nil {
-		return NewUnknownForwardingError(failure.SenderIdx), nil
+		fwdErr = NewUnknownForwardingError(failure.SenderIdx)
+	} else {
+		fwdErr = NewForwardingError(failureMsg, failure.SenderIdx)
+	}
+
+	// Only the hops up to the source of the failure can have attributed
+	// it.
+	if len(holdTimes) > failure.SenderIdx {
+		holdTimes = holdTimes[:failure.SenderIdx]
+	}
+	fwdErr.HoldTimes = holdTimes
+
+	return fwdErr, nil
+}
+
+// verifyAttribution verifies the attribution data of a failure and returns the
+// hold times of the hops that attributed it.
+func (s *SphinxErrorDecrypter) verifyAttribution(reason lnwire.OpaqueReason,
+	attrData lnwire.AttributionData) []time.Duration {
+
+	if s.Circuit == nil || len(attrData) == 0 {
+		return nil
+	}
+
+	sharedSecrets, err := hop.CircuitSharedSecrets(s.Circuit)
+	if err != nil {
+		log.Debugf("Unable to derive shared secrets to verify "+
+			"attribution data: %v", err)
+
+		return nil
 	}
 
-	return NewForwardingError(failureMsg, failure.SenderIdx), nil
+	return hop.VerifyAttribution(sharedSecrets, reason, attrData)
 }
 
 // A compile time check to ensure ErrorDecrypter implements the Deobfuscator
@@ -54,6 +54,23 @@ const (
 	// OutgoingFailureForwardsDisabled is returned when the switch is
 	// configured to disallow forwards.
 	OutgoingFailureForwardsDisabled
+
+	// OutgoingFailureNoResources is returned when the HTLC is not
+	// endorsed by a peer with a good reputation and the general resources
+	// of the outgoing channel are exhausted.
+	OutgoingFailureNoResources
+
+	// OutgoingFailureAddRateLimit is returned when the incoming peer or
+	// channel exceeds its limit of HTLCs added per second.
+	OutgoingFailureAddRateLimit
+
+	// OutgoingFailureInFlightLimit is returned when the incoming peer or
+	// channel exceeds its limit of the value of HTLCs in flight.
+	OutgoingFailureInFlightLimit
+
+	// OutgoingFailureFailRatioLimit is returned when too many of the
+	// HTLCs forwarded from the incoming peer or channel failed.
+	OutgoingFailureFailRatioLimit
 )
 
 // FailureString returns the string representation of a failure detail.
@@ -91,6 +108,18 @@ func (fd OutgoingFailure) FailureString() string {
 	case OutgoingFailureForwardsDisabled:
 		return "node configured to disallow forwards"
 
+	case OutgoingFailureNoResources:
+		return "no resources available for htlc"
+
+	case OutgoingFailureAddRateLimit:
+		return "htlc add rate limit exceeded"
+
+	case OutgoingFailureInFlightLimit:
+		return "htlc in-flight limit exceeded"
+
+	case OutgoingFailureFailRatioLimit:
+		return "htlc fail ratio limit exceeded"
+
 	default:
 		return "unknown failure detail"
 	}
@@ -1,13 +1,16 @@
 package htlcswitch
 
 import (
+	"bytes"
 	"encoding/hex"
 	"encoding/json"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/btcsuite/btcd/btcec/v2"
 	sphinx "github.com/lightningnetwork/lightning-onion"
+	"github.com/lightningnetwork/lnd/htlcswitch/hop"
 	"github.com/lightningnetwork/lnd/lnwire"
 	"github.com/lightningnetwork/lnd/tlv"
 	"github.com/stretchr/testify/require"
@@ -56,7 +59,7 @@ func TestLongFailureMessage(t *testing.T) {
 	}
 
 	// Assert that the failure message can still be extracted.
-	failure, err := errorDecryptor.DecryptError(reason)
+	failure, err := errorDecryptor.DecryptError(reason, nil)
 	require.NoError(t, err)
 
 	incorrectDetails, ok := failure.msg.(*lnwire.FailIncorrectDetails)
@@ -84,3 +87,98 @@ type varBytesRecordProducer struct {
 func (v *varBytesRecordProducer) Record() tlv.Record {
 	return tlv.MakePrimitiveRecord(34001, &v.data)
 }
+
+// TestSphinxErrorDecrypterAttribution tests that the error decrypter reports
+// the hold times of the hops that attributed a failure, and that it returns an
+// UnreadableFailureError if the failure was garbled after some hops attributed
+// it.
+func TestSphinxErrorDecrypterAttribution(t *testing.T) {
+	t.Parallel()
+
+	sessionKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	circuit := &sphinx.Circuit{
+		SessionKey: sessionKey,
+	}
+	for i := 0; i < 3; i++ {
+		hopKey, err := btcec.NewPrivateKey()
+		require.NoError(t, err)
+
+		circuit.PaymentPath = append(
+			circuit.PaymentPath, hopKey.PubKey(),
+		)
+	}
+
+	sharedSecrets, err := hop.CircuitSharedSecrets(circuit)
+	require.NoError(t, err)
+
+	encrypters := make([]*hop.SphinxErrorEncrypter, len(sharedSecrets))
+	for i, sharedSecret := range sharedSecrets {
+		encrypter := &sphinx.OnionErrorEncrypter{}
+		err := encrypter.Decode(bytes.NewReader(sharedSecret[:]))
+		require.NoError(t, err)
+
+		encrypters[i] = &hop.SphinxErrorEncrypter{
+			OnionErrorEncrypter: encrypter,
+		}
+	}
+
+	// failBack fails the HTLC at the last hop and relays the failure
+	// back, garbling it before the given hop relays it.
+	failBack := func(garbleHop int) (lnwire.OpaqueReason,
+		lnwire.AttributionData) {
+
+		last := len(encrypters) - 1
+		reason, err := encrypters[last].EncryptFirstHop(
+			&lnwire.FailTemporaryNodeFailure{},
+		)
+		require.NoError(t, err)
+
+		attrData, err := encrypters[last].AddAttribution(
+			reason, nil, time.Second,
+		)
+		require.NoError(t, err)
+
+		for i := last - 1; i >= 0; i-- {
+			if i == garbleHop {
+				reason[0] ^= 1
+			}
+
+			reason = encrypters[i].IntermediateEncrypt(reason)
+			attrData, err = encrypters[i].AddAttribution(
+				reason, attrData, 2*time.Second,
+			)
+			require.NoError(t, err)
+		}
+
+		return reason, attrData
+	}
+
+	decrypter := &SphinxErrorDecrypter{
+		OnionErrorDecrypter: sphinx.NewOnionErrorDecrypter(circuit),
+		Circuit:             circuit,
+	}
+
+	// A correctly relayed failure carries the hold times of all hops.
+	reason, attrData := failBack(-1)
+	fwdErr, err := decrypter.DecryptError(reason, attrData)
+	require.NoError(t, err)
+	require.Equal(t, 3, fwdErr.FailureSourceIdx)
+	require.Equal(t, []time.Duration{
+		2 * time.Second, 2 * time.Second, time.Second,
+	}, fwdErr.HoldTimes)
+
+	// Without attribution data, the failure is still decrypted.
+	fwdErr, err = decrypter.DecryptError(reason, nil)
+	require.NoError(t, err)
+	require.Empty(t, fwdErr.HoldTimes)
+
+	// If the failure is garbled before the first hop relays it, only the
+	// first hop attributes it.
+	reason, attrData = failBack(0)
+	_, err = decrypter.DecryptError(reason, attrData)
+	require.Equal(t, &UnreadableFailureError{
+		HoldTimes: []time.Duration{2 * time.Second},
+	}, err)
+}
@@ -0,0 +1,278 @@
+package hop
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	sphinx "github.com/lightningnetwork/lightning-onion"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"golang.org/x/crypto/chacha20"
+)
+
+const (
+	// AttributionMaxHops is the maximum number of hops that can attribute
+	// a failure. It matches the maximum number of hops of an onion.
+	AttributionMaxHops = sphinx.NumMaxHops
+
+	// holdTimeLen is the length of the hold time of a single hop.
+	holdTimeLen = 4
+
+	// attrHmacLen is the length of the truncated HMACs of the hops.
+	attrHmacLen = 4
+
+	// holdTimesLen is the length of the hold times of all hops.
+	holdTimesLen = AttributionMaxHops * holdTimeLen
+
+	// numAttrHmacs is the number of HMACs in the attribution data. As a
+	// hop doesn't know its position in the route, it adds an HMAC for
+	// every position it could have. Each hop that relays the failure
+	// further back removes the HMAC for the lowest position from all
+	// blocks, as the hops behind it can't have that position anymore.
+	numAttrHmacs = AttributionMaxHops * (AttributionMaxHops + 1) / 2
+
+	// AttributionDataLen is the length of the attribution data.
+	AttributionDataLen = holdTimesLen + numAttrHmacs*attrHmacLen
+
+	// HoldTimeUnit is the unit in which hold times are reported.
+	HoldTimeUnit = 100 * time.Millisecond
+)
+
+// hmacBlockOffset returns the offset of the block of HMACs of the hop at the
+// given distance from the hop that added the last block. The block at
+// distance d holds the HMACs for the positions d to AttributionMaxHops-1.
+func hmacBlockOffset(distance int) int {
+	hmacs := distance*AttributionMaxHops - distance*(distance-1)/2
+
+	return holdTimesLen + hmacs*attrHmacLen
+}
+
+// hmacOffset returns the offset of the HMAC at the given index of the block
+// of the hop at the given distance.
+func hmacOffset(distance, index int) int {
+	return hmacBlockOffset(distance) + index*attrHmacLen
+}
+
+// shiftAttribution makes room for the hold time and HMACs of another hop at
+// the start of the attribution data. The last hold time is dropped, as well
+// as the HMAC for the lowest position of every block.
+func shiftAttribution(data []byte) []byte {
+	shifted := make([]byte, AttributionDataLen)
+	copy(
+		shifted[holdTimeLen:holdTimesLen],
+		data[:holdTimesLen-holdTimeLen],
+	)
+
+	for d := 0; d < AttributionMaxHops-1; d++ {
+		copy(
+			shifted[hmacBlockOffset(d+1):hmacBlockOffset(d+2)],
+			data[hmacOffset(d, 1):hmacBlockOffset(d+1)],
+		)
+	}
+
+	return shifted
+}
+
+// unshiftAttribution reverses shiftAttribution, leaving the hold time and
+// HMACs that were dropped empty.
+func unshiftAttribution(data []byte) []byte {
+	unshifted := make([]byte, AttributionDataLen)
+	copy(
+		unshifted[:holdTimesLen-holdTimeLen],
+		data[holdTimeLen:holdTimesLen],
+	)
+
+	for d := 0; d < AttributionMaxHops-1; d++ {
+		copy(
+			unshifted[hmacOffset(d, 1):hmacBlockOffset(d+1)],
+			data[hmacBlockOffset(d+1):hmacBlockOffset(d+2)],
+		)
+	}
+
+	return unshifted
+}
+
+// attributionHmac computes the HMAC of a hop at the given position over the
+// failure reason it sent back, the hold times of itself and the hops after it
+// and their HMACs for their respective positions.
+func attributionHmac(key [32]byte, reason, data []byte, position int) []byte {
+	mac := hmac.New(sha256.New, key[:])
+	mac.Write(reason)
+
+	// Only the hold times and HMACs of hops that can follow a hop at the
+	// given position are covered, as the others are dropped before the
+	// failure reaches the sender.
+	numHops := AttributionMaxHops - position
+	mac.Write(data[:numHops*holdTimeLen])
+
+	for d := 1; d < numHops; d++ {
+		offset := hmacOffset(d, position)
+		mac.Write(data[offset : offset+attrHmacLen])
+	}
+
+	return mac.Sum(nil)[:attrHmacLen]
+}
+
+// generateKey derives a key of the given type from a shared secret, as is
+// done for the other keys of the onion.
+func generateKey(keyType string, sharedSecret *sphinx.Hash256) [32]byte {
+	mac := hmac.New(sha256.New, []byte(keyType))
+	mac.Write(sharedSecret[:])
+
+	var key [32]byte
+	copy(key[:], mac.Sum(nil))
+
+	return key
+}
+
+// xorStream encrypts or decrypts the data in place with the cipher stream of
+// the given key type derived from a shared secret.
+func xorStream(keyType string, sharedSecret *sphinx.Hash256, data []byte) {
+	key := generateKey(keyType, sharedSecret)
+
+	var nonce [chacha20.NonceSize]byte
+	cipher, err := chacha20.NewUnauthenticatedCipher(key[:], nonce[:])
+	if err != nil {
+		// This can only happen with an invalid key or nonce size.
+		panic(err)
+	}
+	cipher.XORKeyStream(data, data)
+}
+
+// AddAttribution adds the hold time and HMACs of a hop to the attribution
+// data of a failure that it sends back towards the sender. The HMACs commit
+// to the failure reason as sent by the hop, so it must already be encrypted.
+// If the attribution data received from the next hop is missing or invalid,
+// new attribution data is started, so that the sender can still verify the
+// hops up to this one.
+func AddAttribution(sharedSecret sphinx.Hash256, reason lnwire.OpaqueReason,
+	attrData lnwire.AttributionData,
+	holdTime time.Duration) lnwire.AttributionData {
+
+	var data []byte
+	if len(attrData) == AttributionDataLen {
+		data = shiftAttribution(attrData)
+	} else {
+		data = make([]byte, AttributionDataLen)
+	}
+
+	units := holdTime / HoldTimeUnit
+	if units > math.MaxUint32 {
+		units = math.MaxUint32
+	}
+	binary.BigEndian.PutUint32(data[:holdTimeLen], uint32(units))
+
+	key := generateKey("um", &sharedSecret)
+	for position := 0; position < AttributionMaxHops; position++ {
+		copy(
+			data[hmacOffset(0, position):],
+			attributionHmac(key, reason, data, position),
+		)
+	}
+
+	xorStream("ammagext", &sharedSecret, data)
+
+	return data
+}
+
+// VerifyAttribution verifies the attribution data of a failure with the
+// shared secrets of the hops of the route, starting at the first hop. It
+// returns the hold times reported by the hops that attributed the failure
+// correctly. The hop after the last one returned is the first whose HMAC
+// doesn't match, either because it didn't relay the failure correctly or
+// because it doesn't support attributable failures.
+func VerifyAttribution(sharedSecrets []sphinx.Hash256,
+	reason lnwire.OpaqueReason,
+	attrData lnwire.AttributionData) []time.Duration {
+
+	if len(attrData) != AttributionDataLen {
+		return nil
+	}
+
+	data := make([]byte, AttributionDataLen)
+	copy(data, attrData)
+
+	sentReason := make([]byte, len(reason))
+	copy(sentReason, reason)
+
+	var holdTimes []time.Duration
+	for position, sharedSecret := range sharedSecrets {
+		if position >= AttributionMaxHops {
+			break
+		}
+
+		// Decrypt the attribution data as it was seen by the hop at
+		// this position when it added its HMACs.
+		xorStream("ammagext", &sharedSecret, data)
+
+		key := generateKey("um", &sharedSecret)
+		expected := attributionHmac(key, sentReason, data, position)
+		offset := hmacOffset(0, position)
+		if !hmac.Equal(expected, data[offset:offset+attrHmacLen]) {
+			break
+		}
+
+		holdTime := binary.BigEndian.Uint32(data[:holdTimeLen])
+		holdTimes = append(
+			holdTimes, time.Duration(holdTime)*HoldTimeUnit,
+		)
+
+		// Remove the layer of encryption this hop added to the reason
+		// and restore the attribution data as the next hop sent it.
+		xorStream("ammag", &sharedSecret, sentReason)
+		data = unshiftAttribution(data)
+	}
+
+	return holdTimes
+}
+
+// CircuitSharedSecrets derives the shared secrets with the hops of the route
+// of a circuit, in the same way as they are derived when the onion is
+// created.
+func CircuitSharedSecrets(circuit *sphinx.Circuit) ([]sphinx.Hash256, error) {
+	if circuit == nil || circuit.SessionKey == nil {
+		return nil, errors.New("circuit without session key")
+	}
+
+	sharedSecrets := make([]sphinx.Hash256, len(circuit.PaymentPath))
+
+	// The scalar starts with the session key and is multiplied by the
+	// blinding factor of every hop we pass.
+	var scalar btcec.ModNScalar
+	scalar.Set(&circuit.SessionKey.Key)
+
+	for i, hopPubKey := range circuit.PaymentPath {
+		// The shared secret is the hash of the ECDH point of the hop's
+		// key and the blinded session key.
+		var point, result btcec.JacobianPoint
+		hopPubKey.AsJacobian(&point)
+		btcec.ScalarMultNonConst(&scalar, &point, &result)
+		result.ToAffine()
+		sharedPoint := btcec.NewPublicKey(&result.X, &result.Y)
+
+		sharedSecrets[i] = sha256.Sum256(
+			sharedPoint.SerializeCompressed(),
+		)
+
+		// The blinding factor is the hash of the ephemeral key the hop
+		// sees and the shared secret.
+		var ephemeral btcec.JacobianPoint
+		btcec.ScalarBaseMultNonConst(&scalar, &ephemeral)
+		ephemeral.ToAffine()
+		ephemeralKey := btcec.NewPublicKey(&ephemeral.X, &ephemeral.Y)
+
+		h := sha256.New()
+		h.Write(ephemeralKey.SerializeCompressed())
+		h.Write(sharedSecrets[i][:])
+
+		var blindingFactor btcec.ModNScalar
+		blindingFactor.SetByteSlice(h.Sum(nil))
+		scalar.Mul(&blindingFactor)
+	}
+
+	return sharedSecrets, nil
+}
@@ -0,0 +1,183 @@
+package hop
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	sphinx "github.com/lightningnetwork/lightning-onion"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/stretchr/testify/require"
+)
+
+// attributionTestRoute creates a circuit of the given number of hops and the
+// error encrypters of its hops.
+func attributionTestRoute(t *testing.T, numHops int) (*sphinx.Circuit,
+	[]*SphinxErrorEncrypter) {
+
+	sessionKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	circuit := &sphinx.Circuit{
+		SessionKey: sessionKey,
+	}
+	for i := 0; i < numHops; i++ {
+		hopKey, err := btcec.NewPrivateKey()
+		require.NoError(t, err)
+
+		circuit.PaymentPath = append(
+			circuit.PaymentPath, hopKey.PubKey(),
+		)
+	}
+
+	sharedSecrets, err := CircuitSharedSecrets(circuit)
+	require.NoError(t, err)
+	require.Len(t, sharedSecrets, numHops)
+
+	encrypters := make([]*SphinxErrorEncrypter, numHops)
+	for i, sharedSecret := range sharedSecrets {
+		encrypter := &sphinx.OnionErrorEncrypter{}
+		err := encrypter.Decode(bytes.NewReader(sharedSecret[:]))
+		require.NoError(t, err)
+
+		encrypters[i] = &SphinxErrorEncrypter{
+			OnionErrorEncrypter: encrypter,
+		}
+	}
+
+	return circuit, encrypters
+}
+
+// failBack fails an HTLC at the given hop and relays the failure back to the
+// sender. The tamper closure is called with the failure reason and the
+// attribution data before each hop relays them, and returns the attribution
+// data the hop receives.
+func failBack(t *testing.T, encrypters []*SphinxErrorEncrypter, erringHop int,
+	tamper tamperFunc) (lnwire.OpaqueReason, lnwire.AttributionData) {
+
+	reason, err := encrypters[erringHop].EncryptFirstHop(
+		&lnwire.FailTemporaryNodeFailure{},
+	)
+	require.NoError(t, err)
+
+	attrData, err := encrypters[erringHop].AddAttribution(
+		reason, nil, time.Duration(erringHop+1)*time.Second,
+	)
+	require.NoError(t, err)
+
+	for i := erringHop - 1; i >= 0; i-- {
+		attrData = tamper(i, reason, attrData)
+
+		reason = encrypters[i].IntermediateEncrypt(reason)
+		attrData, err = encrypters[i].AddAttribution(
+			reason, attrData, time.Duration(i+1)*time.Second,
+		)
+		require.NoError(t, err)
+	}
+
+	return reason, attrData
+}
+
+// tamperFunc modifies the failure reason or attribution data a hop receives.
+type tamperFunc func(hop int, reason lnwire.OpaqueReason,
+	attrData lnwire.AttributionData) lnwire.AttributionData
+
+// noTamper relays the failure unmodified.
+func noTamper(_ int, _ lnwire.OpaqueReason,
+	attrData lnwire.AttributionData) lnwire.AttributionData {
+
+	return attrData
+}
+
+// TestAttribution tests that the sender can verify the attribution data of a
+// failure, and that it identifies the hop that tampered with the failure.
+func TestAttribution(t *testing.T) {
+	t.Parallel()
+
+	circuit, encrypters := attributionTestRoute(t, 5)
+
+	// The shared secrets of the circuit must match the ones the onion
+	// error decrypter derives, otherwise it couldn't decrypt the failure.
+	reason, attrData := failBack(t, encrypters, 3, noTamper)
+	decrypter := sphinx.NewOnionErrorDecrypter(circuit)
+	decrypted, err := decrypter.DecryptError(reason)
+	require.NoError(t, err)
+	require.Equal(t, 4, decrypted.SenderIdx)
+
+	// All hops up to the erring one attribute the failure and report
+	// their hold times.
+	sharedSecrets, err := CircuitSharedSecrets(circuit)
+	require.NoError(t, err)
+
+	holdTimes := VerifyAttribution(sharedSecrets, reason, attrData)
+	require.Equal(t, []time.Duration{
+		time.Second, 2 * time.Second, 3 * time.Second, 4 * time.Second,
+	}, holdTimes)
+
+	// If the second hop garbles the reason before relaying it, the third
+	// hop's HMAC no longer matches.
+	garble := func(hop int, reason lnwire.OpaqueReason,
+		attrData lnwire.AttributionData) lnwire.AttributionData {
+
+		if hop == 1 {
+			reason[0] ^= 1
+		}
+
+		return attrData
+	}
+	reason, attrData = failBack(t, encrypters, 3, garble)
+	_, err = decrypter.DecryptError(reason)
+	require.Error(t, err)
+
+	holdTimes = VerifyAttribution(sharedSecrets, reason, attrData)
+	require.Equal(t, []time.Duration{
+		time.Second, 2 * time.Second,
+	}, holdTimes)
+
+	// If the third hop doesn't support attribution, it relays the failure
+	// without attribution data. The hops before it start new attribution
+	// data, so that they can still be verified.
+	legacy := func(hop int, _ lnwire.OpaqueReason,
+		attrData lnwire.AttributionData) lnwire.AttributionData {
+
+		if hop == 1 {
+			return nil
+		}
+
+		return attrData
+	}
+	reason, attrData = failBack(t, encrypters, 4, legacy)
+
+	decrypted, err = decrypter.DecryptError(reason)
+	require.NoError(t, err)
+	require.Equal(t, 5, decrypted.SenderIdx)
+
+	holdTimes = VerifyAttribution(sharedSecrets, reason, attrData)
+	require.Equal(t, []time.Duration{
+		time.Second, 2 * time.Second,
+	}, holdTimes)
+
+	// Missing attribution data can't be verified at all.
+	require.Empty(t, VerifyAttribution(sharedSecrets, reason, nil))
+}
+
+// TestAttributionMaxHops tests that the attribution data of a failure can be
+// verified on a route of the maximum length.
+func TestAttributionMaxHops(t *testing.T) {
+	t.Parallel()
+
+	circuit, encrypters := attributionTestRoute(t, AttributionMaxHops)
+	sharedSecrets, err := CircuitSharedSecrets(circuit)
+	require.NoError(t, err)
+
+	reason, attrData := failBack(
+		t, encrypters, AttributionMaxHops-1, noTamper,
+	)
+
+	holdTimes := VerifyAttribution(sharedSecrets, reason, attrData)
+	require.Len(t, holdTimes, AttributionMaxHops)
+	for i, holdTime := range holdTimes {
+		require.Equal(t, time.Duration(i+1)*time.Second, holdTime)
+	}
+}
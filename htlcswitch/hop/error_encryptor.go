@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/btcsuite/btcd/btcec/v2"
 	sphinx "github.com/lightningnetwork/lightning-onion"
@@ -54,6 +55,14 @@ type ErrorEncrypter interface {
 	// until the error arrives at the source of the payment.
 	IntermediateEncrypt(lnwire.OpaqueReason) lnwire.OpaqueReason
 
+	// AddAttribution adds our hold time and HMACs to the attribution data
+	// of a failure that we send back, which lets the sender verify which
+	// hops relayed the failure correctly. The passed reason must already
+	// be encrypted by us.
+	AddAttribution(reason lnwire.OpaqueReason,
+		attrData lnwire.AttributionData,
+		holdTime time.Duration) (lnwire.AttributionData, error)
+
 	// Type returns an enum indicating the underlying concrete instance
 	// backing this interface.
 	Type() EncrypterType
@@ -143,6 +152,32 @@ func (s *SphinxErrorEncrypter) IntermediateEncrypt(
 	return s.EncryptError(false, reason)
 }
 
+// AddAttribution adds our hold time and HMACs to the attribution data of a
+// failure that we send back, which lets the sender verify which hops relayed
+// the failure correctly. The passed reason must already be encrypted by us.
+//
+// NOTE: Part of the ErrorEncrypter interface.
+func (s *SphinxErrorEncrypter) AddAttribution(reason lnwire.OpaqueReason,
+	attrData lnwire.AttributionData,
+	holdTime time.Duration) (lnwire.AttributionData, error) {
+
+	if s.OnionErrorEncrypter == nil {
+		return nil, fmt.Errorf("error encrypter not initialized")
+	}
+
+	// The shared secret isn't exposed by the onion error encrypter other
+	// than through its encoding.
+	var b bytes.Buffer
+	if err := s.OnionErrorEncrypter.Encode(&b); err != nil {
+		return nil, err
+	}
+
+	var sharedSecret sphinx.Hash256
+	copy(sharedSecret[:], b.Bytes())
+
+	return AddAttribution(sharedSecret, reason, attrData, holdTime), nil
+}
+
 // Type returns the identifier for a sphinx error encrypter.
 func (s *SphinxErrorEncrypter) Type() EncrypterType {
 	return EncrypterTypeSphinx
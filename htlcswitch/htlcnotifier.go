@@ -8,6 +8,7 @@ import (
 
 	"github.com/lightningnetwork/lnd/channeldb"
 	"github.com/lightningnetwork/lnd/channeldb/models"
+	"github.com/lightningnetwork/lnd/eventlog"
 	"github.com/lightningnetwork/lnd/htlcswitch/hop"
 	"github.com/lightningnetwork/lnd/lntypes"
 	"github.com/lightningnetwork/lnd/lnwire"
@@ -65,6 +66,9 @@ type HtlcNotifier struct {
 	now func() time.Time
 
 	ntfnServer *subscribe.Server
+
+	// recorder is an optional recorder that persists the events.
+	recorder eventlog.Recorder
 }
 
 // NewHtlcNotifier creates a new HtlcNotifier which gets htlc forwarded,
@@ -108,6 +112,20 @@ func (h *HtlcNotifier) SubscribeHtlcEvents() (*subscribe.Client, error) {
 	return h.ntfnServer.Subscribe()
 }
 
+// SetEventRecorder sets a recorder that all htlc events are passed to, in
+// addition to the subscribers. It must be called before the notifier is
+// started.
+func (h *HtlcNotifier) SetEventRecorder(recorder eventlog.Recorder) {
+	h.recorder = recorder
+}
+
+// record passes the event to the event recorder, if one is set.
+func (h *HtlcNotifier) record(event interface{}) {
+	if h.recorder != nil {
+		h.recorder.Record(event)
+	}
+}
+
 // HtlcKey uniquely identifies the htlc.
 type HtlcKey struct {
 	// IncomingCircuit is the channel an htlc id of the incoming htlc.
@@ -309,6 +327,30 @@ type FinalHtlcEvent struct {
 	Timestamp time.Time
 }
 
+// RateLimitEvent represents an incoming htlc that was failed because its peer
+// or channel exceeded one of the rate limits of the switch.
+type RateLimitEvent struct {
+	// HtlcKey identifies the incoming htlc that was failed.
+	HtlcKey
+
+	// Peer is the public key of the peer the htlc arrived from.
+	Peer [33]byte
+
+	// Limit is the limit that was exceeded.
+	Limit RateLimit
+
+	// Scope indicates whether the limit of the peer or of the incoming
+	// channel was exceeded.
+	Scope RateLimitScope
+
+	// Count is the number of times the limit was triggered for the peer
+	// or channel since startup.
+	Count uint64
+
+	// Timestamp is the time when the htlc was failed.
+	Timestamp time.Time
+}
+
 // NotifyForwardingEvent notifies the HtlcNotifier than a htlc has been
 // forwarded.
 //
@@ -326,6 +368,8 @@ func (h *HtlcNotifier) NotifyForwardingEvent(key HtlcKey, info HtlcInfo,
 	log.Tracef("Notifying forward event: %v over %v, %v", eventType, key,
 		info)
 
+	h.record(event)
+
 	if err := h.ntfnServer.SendUpdate(event); err != nil {
 		log.Warnf("Unable to send forwarding event: %v", err)
 	}
@@ -350,6 +394,8 @@ func (h *HtlcNotifier) NotifyLinkFailEvent(key HtlcKey, info HtlcInfo,
 	log.Tracef("Notifying link failure event: %v over %v, %v", eventType,
 		key, info)
 
+	h.record(event)
+
 	if err := h.ntfnServer.SendUpdate(event); err != nil {
 		log.Warnf("Unable to send link fail event: %v", err)
 	}
@@ -371,6 +417,8 @@ func (h *HtlcNotifier) NotifyForwardingFailEvent(key HtlcKey,
 	log.Tracef("Notifying forwarding failure event: %v over %v", eventType,
 		key)
 
+	h.record(event)
+
 	if err := h.ntfnServer.SendUpdate(event); err != nil {
 		log.Warnf("Unable to send forwarding fail event: %v", err)
 	}
@@ -392,6 +440,8 @@ func (h *HtlcNotifier) NotifySettleEvent(key HtlcKey,
 
 	log.Tracef("Notifying settle event: %v over %v", eventType, key)
 
+	h.record(event)
+
 	if err := h.ntfnServer.SendUpdate(event); err != nil {
 		log.Warnf("Unable to send settle event: %v", err)
 	}
@@ -413,11 +463,39 @@ func (h *HtlcNotifier) NotifyFinalHtlcEvent(key models.CircuitKey,
 
 	log.Tracef("Notifying final settle event: %v", key)
 
+	h.record(event)
+
 	if err := h.ntfnServer.SendUpdate(event); err != nil {
 		log.Warnf("Unable to send settle event: %v", err)
 	}
 }
 
+// NotifyRateLimitEvent notifies the HtlcNotifier that an incoming htlc was
+// failed because its peer or channel exceeded a rate limit.
+//
+// Note this is part of the htlcNotifier interface.
+func (h *HtlcNotifier) NotifyRateLimitEvent(key HtlcKey, peer [33]byte,
+	limit RateLimit, scope RateLimitScope, count uint64) {
+
+	event := &RateLimitEvent{
+		HtlcKey:   key,
+		Peer:      peer,
+		Limit:     limit,
+		Scope:     scope,
+		Count:     count,
+		Timestamp: h.now(),
+	}
+
+	log.Tracef("Notifying rate limit event: %v %v limit over %v",
+		scope, limit, key)
+
+	h.record(event)
+
+	if err := h.ntfnServer.SendUpdate(event); err != nil {
+		log.Warnf("Unable to send rate limit event: %v", err)
+	}
+}
+
 // newHtlc key returns a htlc key for the packet provided. If the packet
 // has a zero incoming channel ID, the packet is for one of our own sends,
 // which has the payment id stashed in the incoming htlc id. If this is the
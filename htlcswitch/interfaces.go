@@ -6,9 +6,11 @@ import (
 	"github.com/btcsuite/btcd/wire"
 	"github.com/lightningnetwork/lnd/channeldb"
 	"github.com/lightningnetwork/lnd/channeldb/models"
+	"github.com/lightningnetwork/lnd/htlcswitch/reputation"
 	"github.com/lightningnetwork/lnd/invoices"
 	"github.com/lightningnetwork/lnd/lnpeer"
 	"github.com/lightningnetwork/lnd/lntypes"
+	"github.com/lightningnetwork/lnd/lnwallet"
 	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
 	"github.com/lightningnetwork/lnd/lnwire"
 	"github.com/lightningnetwork/lnd/record"
@@ -59,9 +61,9 @@ type packetHandler interface {
 // dustHandler is an interface used exclusively by the Switch to evaluate
 // whether a link has too much dust exposure.
 type dustHandler interface {
-	// getDustSum returns the dust sum on either the local or remote
-	// commitment.
-	getDustSum(remote bool) lnwire.MilliSatoshi
+	// getFeeExposure returns the fee exposure of either the local or
+	// remote commitment.
+	getFeeExposure(remote bool) lnwallet.FeeExposure
 
 	// getFeeRate returns the current channel feerate.
 	getFeeRate() chainfee.SatPerKWeight
@@ -138,6 +140,12 @@ type ChannelUpdateHandler interface {
 	// clean. This can be used with dynamic commitment negotiation or coop
 	// close negotiation which require a clean channel state.
 	ShutdownIfChannelClean() error
+
+	// ProposeDynCommit makes the channel quiescent and starts a dynamic
+	// commitment negotiation with the remote peer to change the given
+	// channel parameters. It blocks until the remote peer has accepted or
+	// rejected the proposal.
+	ProposeDynCommit(params *lnwallet.DynCommitParams) error
 }
 
 // ChannelLink is an interface which represents the subsystem for managing the
@@ -199,10 +207,12 @@ type ChannelLink interface {
 	// satisfy the current forwarding policy fo the target link. Otherwise,
 	// a LinkError with a valid protocol failure message should be returned
 	// in order to signal to the source of the HTLC, the policy consistency
-	// issue.
+	// issue. The inbound fee is the fee charged by the incoming link, which
+	// is required on top of the outbound fee of the target link.
 	CheckHtlcForward(payHash [32]byte, incomingAmt lnwire.MilliSatoshi,
 		amtToForward lnwire.MilliSatoshi,
 		incomingTimeout, outgoingTimeout uint32,
+		inboundFee models.InboundFee,
 		heightNow uint32, scid lnwire.ShortChannelID) *LinkError
 
 	// CheckHtlcTransit should return a nil error if the passed HTLC details
@@ -217,6 +227,10 @@ type ChannelLink interface {
 	// total sent/received milli-satoshis.
 	Stats() (uint64, lnwire.MilliSatoshi, lnwire.MilliSatoshi)
 
+	// resourceLimits returns the number of HTLC slots and the value of
+	// HTLCs that the remote peer allows us to add to the channel.
+	resourceLimits() reputation.ChannelLimits
+
 	// Peer returns the representation of remote peer with which we have
 	// the channel link opened.
 	Peer() lnpeer.Peer
@@ -373,4 +387,9 @@ type htlcNotifier interface {
 	// for an htlc has been determined.
 	NotifyFinalHtlcEvent(key models.CircuitKey,
 		info channeldb.FinalHtlcInfo)
+
+	// NotifyRateLimitEvent notifies the HtlcNotifier that an incoming
+	// htlc was failed because its peer or channel exceeded a rate limit.
+	NotifyRateLimitEvent(key HtlcKey, peer [33]byte, limit RateLimit,
+		scope RateLimitScope, count uint64)
 }
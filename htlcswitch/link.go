@@ -6,6 +6,7 @@ import (
 	"crypto/sha256"
 	"fmt"
 	prand "math/rand"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -21,6 +22,7 @@ import (
 	"github.com/lightningnetwork/lnd/contractcourt"
 	"github.com/lightningnetwork/lnd/htlcswitch/hodl"
 	"github.com/lightningnetwork/lnd/htlcswitch/hop"
+	"github.com/lightningnetwork/lnd/htlcswitch/reputation"
 	"github.com/lightningnetwork/lnd/invoices"
 	"github.com/lightningnetwork/lnd/lnpeer"
 	"github.com/lightningnetwork/lnd/lntypes"
@@ -62,6 +64,10 @@ const (
 	// a channel's commitment fee to be of its balance. This only applies to
 	// the initiator of the channel.
 	DefaultMaxLinkFeeAllocation float64 = 0.5
+
+	// quiescenceTimeout is the time we allow for the channel to become
+	// quiescent and for the dynamic commitment negotiation to complete.
+	quiescenceTimeout = time.Minute
 )
 
 // ExpectedFee computes the expected fee for a given htlc amount. The value
@@ -243,6 +249,20 @@ type ChannelLinkConfig struct {
 	// the initiator for channels of the anchor type.
 	MaxAnchorsCommitFeeRate chainfee.SatPerKWeight
 
+	// MaxFeeExposure is the limit on the fee exposure of the commitments
+	// of the channel. HTLCs and fee updates that would exceed it are
+	// rejected.
+	MaxFeeExposure lnwallet.FeeExposureLimit
+
+	// MaxLocalCSVDelay is the maximum csv delay we accept for our own
+	// outputs when the remote peer proposes new channel parameters.
+	MaxLocalCSVDelay uint16
+
+	// DynCommitments indicates whether both we and the remote peer
+	// support changing the channel parameters through a dynamic
+	// commitment negotiation.
+	DynCommitments bool
+
 	// NotifyActiveLink allows the link to tell the ChannelNotifier when a
 	// link is first started.
 	NotifyActiveLink func(wire.OutPoint)
@@ -280,6 +300,55 @@ type shutdownReq struct {
 	err chan error
 }
 
+// dynCommitReq contains the channel parameters of a dynamic commitment
+// proposal and an error channel that will be used by the channelLink to send
+// the outcome of the negotiation.
+type dynCommitReq struct {
+	params *lnwallet.DynCommitParams
+	err    chan error
+}
+
+// deferredAdds are HTLCs added by the remote peer that were locked in while
+// we couldn't send any updates to the channel.
+type deferredAdds struct {
+	fwdPkg *channeldb.FwdPkg
+	adds   []*lnwallet.PaymentDescriptor
+}
+
+// quiescenceState tracks the stfu exchange that makes a channel quiescent
+// before its parameters are changed through a dynamic commitment
+// negotiation. Once we sent stfu, we don't send any updates until the
+// negotiation is completed.
+type quiescenceState struct {
+	// sent is true if we sent stfu to the remote peer.
+	sent bool
+
+	// received is true if the remote peer sent stfu to us.
+	received bool
+
+	// remoteInitiator is true if the remote peer asked to make the
+	// channel quiescent, rather than replying to our stfu.
+	remoteInitiator bool
+
+	// proposed is true if we sent our dyn_propose after the channel
+	// became quiescent.
+	proposed bool
+
+	// timeout fires if the negotiation doesn't complete in time. It is
+	// nil while no negotiation is in progress.
+	timeout <-chan time.Time
+
+	// deferredAdds are the remote adds that were locked in after we sent
+	// stfu. Processing them might require us to send updates, so they
+	// are only processed once the channel is no longer quiescent.
+	deferredAdds []deferredAdds
+}
+
+// isQuiescent returns true once both parties sent stfu.
+func (q *quiescenceState) isQuiescent() bool {
+	return q.sent && q.received
+}
+
 // channelLink is the service which drives a channel's commitment update
 // state-machine. In the event that an HTLC needs to be propagated to another
 // link, the forward handler from config is used which sends HTLC to the
@@ -344,6 +413,18 @@ type channelLink struct {
 	// service shutdown requests from ShutdownIfChannelClean calls.
 	shutdownRequest chan *shutdownReq
 
+	// dynCommitRequest is a channel that the channelLink will listen on to
+	// service requests from ProposeDynCommit calls.
+	dynCommitRequest chan *dynCommitReq
+
+	// dynProposal is our dynamic commitment proposal that is waiting for
+	// a response from the remote peer, if any.
+	dynProposal *dynCommitReq
+
+	// quiescence is the state of the stfu exchange that precedes a
+	// dynamic commitment negotiation.
+	quiescence quiescenceState
+
 	// updateFeeTimer is the timer responsible for updating the link's
 	// commitment fee every time it fires.
 	updateFeeTimer *time.Timer
@@ -364,6 +445,11 @@ type channelLink struct {
 	// resolving those htlcs when we receive a message on hodlQueue.
 	hodlMap map[models.CircuitKey]hodlHtlc
 
+	// addTimes records when the incoming HTLCs were added by the remote
+	// peer, keyed by their HTLC index. It is used to report how long we
+	// held an HTLC when attributing its failure.
+	addTimes map[uint64]time.Time
+
 	// log is a link-specific logging instance.
 	log btclog.Logger
 
@@ -385,14 +471,16 @@ func NewChannelLink(cfg ChannelLinkConfig,
 	logPrefix := fmt.Sprintf("ChannelLink(%v):", channel.ChannelPoint())
 
 	return &channelLink{
-		cfg:             cfg,
-		channel:         channel,
-		shortChanID:     channel.ShortChanID(),
-		shutdownRequest: make(chan *shutdownReq),
-		hodlMap:         make(map[models.CircuitKey]hodlHtlc),
-		hodlQueue:       queue.NewConcurrentQueue(10),
-		log:             build.NewPrefixLog(logPrefix, log),
-		quit:            make(chan struct{}),
+		cfg:              cfg,
+		channel:          channel,
+		shortChanID:      channel.ShortChanID(),
+		shutdownRequest:  make(chan *shutdownReq),
+		dynCommitRequest: make(chan *dynCommitReq),
+		hodlMap:          make(map[models.CircuitKey]hodlHtlc),
+		addTimes:         make(map[uint64]time.Time),
+		hodlQueue:        queue.NewConcurrentQueue(10),
+		log:              build.NewPrefixLog(logPrefix, log),
+		quit:             make(chan struct{}),
 	}
 }
 
@@ -1060,6 +1148,11 @@ func (l *channelLink) htlcManager() {
 	// allow the switch to forward HTLCs in the outbound direction.
 	l.markReestablished()
 
+	// If we proposed new channel parameters before, but didn't receive a
+	// response yet, we'll make the channel quiescent again and retransmit
+	// the proposal.
+	l.restartDynProposal()
+
 	// Now that we've received both channel_ready and channel reestablish,
 	// we can go ahead and send the active channel notification. We'll also
 	// defer the inactive notification for when the link exits to ensure
@@ -1131,6 +1224,16 @@ func (l *channelLink) htlcManager() {
 				"PendingLocalUpdateCount")
 		}
 
+		// Once we sent stfu, we must not send any updates, so we stop
+		// reading the packets and resolutions that would lead to them
+		// until the channel is no longer quiescent.
+		downstream := l.downstream
+		hodlQueue := l.hodlQueue.ChanOut()
+		if l.quiescence.sent {
+			downstream = nil
+			hodlQueue = nil
+		}
+
 		select {
 		// Our update fee timer has fired, so we'll check the network
 		// fee to see if we should adjust our commitment fee.
@@ -1143,6 +1246,18 @@ func (l *channelLink) htlcManager() {
 				continue
 			}
 
+			// Zero-fee commitments never carry a fee rate, so
+			// there's nothing to update.
+			if l.channel.ChanType().HasZeroFeeCommitment() {
+				continue
+			}
+
+			// The fee can't be updated while the channel
+			// parameters are being renegotiated.
+			if l.dynCommitBlocked() {
+				continue
+			}
+
 			// If we are the initiator, then we'll sample the
 			// current fee rate to get into the chain within 3
 			// blocks.
@@ -1220,7 +1335,7 @@ func (l *channelLink) htlcManager() {
 		// A message from the switch was just received. This indicates
 		// that the link is an intermediate hop in a multi-hop HTLC
 		// circuit.
-		case pkt := <-l.downstream:
+		case pkt := <-downstream:
 			l.handleDownstreamPkt(pkt)
 
 		// A message from the connected peer was just received. This
@@ -1229,9 +1344,14 @@ func (l *channelLink) htlcManager() {
 		case msg := <-l.upstream:
 			l.handleUpstreamMsg(msg)
 
+			// The message might have locked in our last pending
+			// update, which allows us to make the channel
+			// quiescent.
+			l.maybeSendStfu()
+
 		// A htlc resolution is received. This means that we now have a
 		// resolution for a previously accepted htlc.
-		case hodlItem := <-l.hodlQueue.ChanOut():
+		case hodlItem := <-hodlQueue:
 			htlcResolution := hodlItem.(invoices.HtlcResolution)
 			err := l.processHodlQueue(htlcResolution)
 			switch err {
@@ -1271,6 +1391,12 @@ func (l *channelLink) htlcManager() {
 			// an error and continue.
 			req.err <- ErrLinkFailedShutdown
 
+		case req := <-l.dynCommitRequest:
+			l.handleDynCommitRequest(req)
+
+		case <-l.quiescence.timeout:
+			l.handleQuiescenceTimeout()
+
 		case <-l.quit:
 			return
 		}
@@ -1411,12 +1537,30 @@ func (l *channelLink) handleDownstreamUpdateAdd(pkt *htlcPacket) error {
 		return nil
 	}
 
+	// No new HTLCs can be added while the channel parameters are being
+	// renegotiated.
+	var err error
+	if l.dynCommitBlocked() {
+		err = errors.New("channel parameters are being changed")
+	}
+
+	// Neither can HTLCs that would make the fee exposure of the channel
+	// exceed our limit.
+	if err == nil {
+		err = l.channel.CheckHtlcFeeExposure(
+			l.cfg.MaxFeeExposure, htlc.Amount, false,
+		)
+	}
+
 	// A new payment has been initiated via the downstream channel,
 	// so we add the new HTLC to our local log, then update the
 	// commitment chains.
 	htlc.ChanID = l.ChanID()
 	openCircuitRef := pkt.inKey()
-	index, err := l.channel.AddHTLC(htlc, &openCircuitRef)
+	var index uint64
+	if err == nil {
+		index, err = l.channel.AddHTLC(htlc, &openCircuitRef)
+	}
 	if err != nil {
 		// The HTLC was unable to be added to the state machine,
 		// as a result, we'll signal the switch to cancel the
@@ -1501,6 +1645,8 @@ func (l *channelLink) handleDownstreamPkt(pkt *htlcPacket) {
 		// An HTLC we forward to the switch has just settled somewhere
 		// upstream. Therefore we settle the HTLC within the our local
 		// state machine.
+		delete(l.addTimes, pkt.incomingHTLCID)
+
 		inKey := pkt.inKey()
 		err := l.channel.SettleHTLC(
 			htlc.PaymentPreimage,
@@ -1565,12 +1711,33 @@ func (l *channelLink) handleDownstreamPkt(pkt *htlcPacket) {
 			return
 		}
 
+		// Add our hold time and HMACs to the attribution data of the
+		// failure, so that the sender can tell whether we relayed it
+		// correctly.
+		encrypter := pkt.obfuscator
+		if encrypter == nil && pkt.circuit != nil {
+			encrypter = pkt.circuit.ErrorEncrypter
+		}
+		downstreamAttrData, err := htlc.AttributionData()
+		if err != nil {
+			l.log.Debugf("unable to parse attribution data of "+
+				"failure: %v", err)
+		}
+		attrData := l.attributeFailure(
+			pkt.incomingHTLCID, encrypter, htlc.Reason,
+			downstreamAttrData,
+		)
+		if err := htlc.SetAttributionData(attrData); err != nil {
+			l.log.Errorf("unable to set attribution data: %v", err)
+		}
+
 		// An HTLC cancellation has been triggered somewhere upstream,
 		// we'll remove then HTLC from our local state machine.
 		inKey := pkt.inKey()
-		err := l.channel.FailHTLC(
+		err = l.channel.FailHTLC(
 			pkt.incomingHTLCID,
 			htlc.Reason,
+			attrData,
 			pkt.sourceRef,
 			pkt.destRef,
 			&inKey,
@@ -1712,9 +1879,32 @@ func (l *channelLink) cleanupSpuriousResponse(pkt *htlcPacket) {
 // updates from the upstream peer. The upstream peer is the peer whom we have a
 // direct channel with, updating our respective commitment chains.
 func (l *channelLink) handleUpstreamMsg(msg lnwire.Message) {
+	// Once the remote peer sent stfu, it must not send any updates until
+	// the dynamic commitment negotiation is completed.
+	if l.quiescence.received {
+		switch msg.(type) {
+		case *lnwire.UpdateAddHTLC, *lnwire.UpdateFulfillHTLC,
+			*lnwire.UpdateFailHTLC, *lnwire.UpdateFailMalformedHTLC,
+			*lnwire.UpdateFee:
+
+			l.fail(LinkFailureError{code: ErrInvalidUpdate},
+				"received %T after stfu", msg)
+			return
+		}
+	}
+
 	switch msg := msg.(type) {
 
 	case *lnwire.UpdateAddHTLC:
+		// The remote peer must not add HTLCs before new channel
+		// parameters are active on both commitments.
+		if l.channel.DynCommitPending() {
+			l.fail(LinkFailureError{code: ErrInvalidUpdate},
+				"received add HTLC while channel parameters "+
+					"are being changed")
+			return
+		}
+
 		// We just received an add request from an upstream peer, so we
 		// add it to our state machine, then add the HTLC to our
 		// "settle" list in the event that we know the preimage.
@@ -1724,6 +1914,7 @@ func (l *channelLink) handleUpstreamMsg(msg lnwire.Message) {
 				"unable to handle upstream add HTLC: %v", err)
 			return
 		}
+		l.addTimes[index] = time.Now()
 
 		l.log.Tracef("receive upstream htlc with payment hash(%x), "+
 			"assigning index: %v", msg.PaymentHash[:], index)
@@ -1828,7 +2019,7 @@ func (l *channelLink) handleUpstreamMsg(msg lnwire.Message) {
 		// If remote side have been unable to parse the onion blob we
 		// have sent to it, than we should transform the malformed HTLC
 		// message to the usual HTLC fail message.
-		err := l.channel.ReceiveFailHTLC(msg.ID, b.Bytes())
+		err := l.channel.ReceiveFailHTLC(msg.ID, b.Bytes(), nil)
 		if err != nil {
 			l.fail(LinkFailureError{code: ErrInvalidUpdate},
 				"unable to handle upstream fail HTLC: %v", err)
@@ -1864,9 +2055,17 @@ func (l *channelLink) handleUpstreamMsg(msg lnwire.Message) {
 			}
 		}
 
+		// Failures of nodes that don't support attribution don't carry
+		// any attribution data, and neither do malformed ones.
+		attrData, err := msg.AttributionData()
+		if err != nil {
+			l.log.Debugf("unable to parse attribution data of "+
+				"failure: %v", err)
+		}
+
 		// Add fail to the update log.
 		idx := msg.ID
-		err := l.channel.ReceiveFailHTLC(idx, msg.Reason[:])
+		err = l.channel.ReceiveFailHTLC(idx, msg.Reason[:], attrData)
 		if err != nil {
 			l.fail(LinkFailureError{code: ErrInvalidUpdate},
 				"unable to handle upstream fail HTLC: %v", err)
@@ -2074,7 +2273,20 @@ func (l *channelLink) handleUpstreamMsg(msg lnwire.Message) {
 		}
 
 		l.processRemoteSettleFails(fwdPkg, settleFails)
-		l.processRemoteAdds(fwdPkg, adds)
+
+		// Processing the adds might make us send updates, which we
+		// mustn't do after sending stfu. In that case, they are
+		// processed once the channel is no longer quiescent.
+		if l.quiescence.sent && len(adds) > 0 {
+			l.quiescence.deferredAdds = append(
+				l.quiescence.deferredAdds, deferredAdds{
+					fwdPkg: fwdPkg,
+					adds:   adds,
+				},
+			)
+		} else {
+			l.processRemoteAdds(fwdPkg, adds)
+		}
 
 		// If the link failed during processing the adds, we must
 		// return to ensure we won't attempted to update the state
@@ -2100,6 +2312,28 @@ func (l *channelLink) handleUpstreamMsg(msg lnwire.Message) {
 		// We received fee update from peer. If we are the initiator we
 		// will fail the channel, if not we will apply the update.
 		fee := chainfee.SatPerKWeight(msg.FeePerKw)
+
+		// A fee update that makes the fee exposure of the channel
+		// exceed our limit can't be accepted. We don't force close
+		// the channel though, as that would burn the exposure right
+		// away. Instead, we only take the link down and send a warning
+		// rather than an error, such that the peer neither closes the
+		// channel nor drops the connection and retransmits the same
+		// update right after reconnecting.
+		err := l.channel.CheckFeeExposure(l.cfg.MaxFeeExposure, fee)
+		if err != nil {
+			l.fail(
+				LinkFailureError{
+					code:    ErrInvalidUpdate,
+					Warning: true,
+					SendData: []byte("fee update exceeds " +
+						"max fee exposure"),
+				},
+				"error receiving fee update: %v", err,
+			)
+			return
+		}
+
 		if err := l.channel.ReceiveUpdateFee(fee); err != nil {
 			l.fail(LinkFailureError{code: ErrInvalidUpdate},
 				"error receiving fee update: %v", err)
@@ -2109,6 +2343,18 @@ func (l *channelLink) handleUpstreamMsg(msg lnwire.Message) {
 		// Update the mailbox's feerate as well.
 		l.mailBox.SetFeeRate(fee)
 
+	case *lnwire.Stfu:
+		l.handleStfu(msg)
+
+	case *lnwire.DynPropose:
+		l.handleDynPropose(msg)
+
+	case *lnwire.DynAck:
+		l.handleDynAck()
+
+	case *lnwire.DynReject:
+		l.handleDynReject(msg)
+
 	// In the case where we receive a warning message from our peer, just
 	// log it and move on. We choose not to disconnect from our peer,
 	// although we "MAY" do so according to the specification.
@@ -2384,12 +2630,25 @@ func (l *channelLink) MayAddOutgoingHtlc(amt lnwire.MilliSatoshi) error {
 	return l.channel.MayAddOutgoingHtlc(amt)
 }
 
-// getDustSum is a wrapper method that calls the underlying channel's dust sum
-// method.
+// resourceLimits returns the number of HTLC slots and the value of HTLCs that
+// the remote peer allows us to add to the channel.
+//
+// NOTE: Part of the ChannelLink interface.
+func (l *channelLink) resourceLimits() reputation.ChannelLimits {
+	remoteCfg := l.channel.State().RemoteChanCfg
+
+	return reputation.ChannelLimits{
+		Slots:     remoteCfg.MaxAcceptedHtlcs,
+		Liquidity: remoteCfg.MaxPendingAmount,
+	}
+}
+
+// getFeeExposure is a wrapper method that calls the underlying channel's fee
+// exposure method.
 //
 // NOTE: Part of the dustHandler interface.
-func (l *channelLink) getDustSum(remote bool) lnwire.MilliSatoshi {
-	return l.channel.GetDustSum(remote)
+func (l *channelLink) getFeeExposure(remote bool) lnwallet.FeeExposure {
+	return l.channel.GetFeeExposure(remote, nil)
 }
 
 // getFeeRate is a wrapper method that retrieves the underlying channel's
@@ -2539,6 +2798,7 @@ func (l *channelLink) UpdateForwardingPolicy(
 func (l *channelLink) CheckHtlcForward(payHash [32]byte,
 	incomingHtlcAmt, amtToForward lnwire.MilliSatoshi,
 	incomingTimeout, outgoingTimeout uint32,
+	inboundFee models.InboundFee,
 	heightNow uint32, originalScid lnwire.ShortChannelID) *LinkError {
 
 	l.RLock()
@@ -2548,7 +2808,17 @@ func (l *channelLink) CheckHtlcForward(payHash [32]byte,
 	// Using the amount of the incoming HTLC, we'll calculate the expected
 	// fee this incoming HTLC must carry in order to satisfy the
 	// constraints of the outgoing link.
-	expectedFee := ExpectedFee(policy, amtToForward)
+	outFee := ExpectedFee(policy, amtToForward)
+
+	// Then calculate the inbound fee that the incoming link charges. It
+	// is based on the amount to forward including the outbound fee and
+	// may be negative to grant a discount.
+	inFee := inboundFee.CalcFee(amtToForward + outFee)
+
+	// Add up both fee components. It is important to calculate the total
+	// fee as a signed value, as a discount may exceed the outbound fee.
+	// A negative total fee then just means that no fee is required.
+	expectedFee := int64(outFee) + inFee
 
 	// If the actual fee is less than our expected fee, then we'll reject
 	// this HTLC as it didn't provide a sufficient amount of fees, or the
@@ -2556,11 +2826,11 @@ func (l *channelLink) CheckHtlcForward(payHash [32]byte,
 	// information to construct the forwarding information for this hop. In
 	// any case, we'll cancel this HTLC. We're checking for this case first
 	// to leak as little information as possible.
-	actualFee := incomingHtlcAmt - amtToForward
+	actualFee := int64(incomingHtlcAmt) - int64(amtToForward)
 	if incomingHtlcAmt < amtToForward || actualFee < expectedFee {
 		l.log.Warnf("outgoing htlc(%x) has insufficient fee: "+
 			"expected %v, got %v",
-			payHash[:], int64(expectedFee), int64(actualFee))
+			payHash[:], expectedFee, actualFee)
 
 		// As part of the returned error, we'll send our latest routing
 		// policy so the sending node obtains the most up to date data.
@@ -2773,6 +3043,492 @@ func (l *channelLink) ShutdownIfChannelClean() error {
 	}
 }
 
+// ProposeDynCommit starts a dynamic commitment negotiation to change the
+// given channel parameters, once the channel has been made quiescent through
+// an stfu exchange. It blocks until the remote peer has accepted or rejected
+// the proposal.
+//
+// NOTE: Part of the ChannelUpdateHandler interface.
+func (l *channelLink) ProposeDynCommit(params *lnwallet.DynCommitParams) error {
+	errChan := make(chan error, 1)
+
+	select {
+	case l.dynCommitRequest <- &dynCommitReq{
+		params: params,
+		err:    errChan,
+	}:
+	case <-l.quit:
+		return ErrLinkShuttingDown
+	}
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-l.quit:
+		return ErrLinkShuttingDown
+	}
+}
+
+// dynCommitBlocked returns true if no new updates may be added to the channel
+// because its parameters are being changed through a dynamic commitment
+// negotiation.
+func (l *channelLink) dynCommitBlocked() bool {
+	return l.dynProposal != nil || l.quiescence.received ||
+		l.channel.DynCommitPending()
+}
+
+// handleDynCommitRequest validates our dynamic commitment proposal and starts
+// making the channel quiescent, after which the proposal is sent to the remote
+// peer. The proposal is persisted, so that it can be retransmitted after a
+// reconnection.
+func (l *channelLink) handleDynCommitRequest(req *dynCommitReq) {
+	switch {
+	case !l.cfg.DynCommitments:
+		req.err <- ErrDynCommitUnsupported
+		return
+
+	case l.dynProposal != nil, l.quiescence.received:
+		req.err <- ErrDynCommitInProgress
+		return
+
+	case l.channel.DynCommitParamsApplied(req.params, true):
+		req.err <- errors.New("channel parameters already in effect")
+		return
+	}
+
+	err := l.channel.ValidateDynCommitParams(
+		req.params, true, l.cfg.MaxLocalCSVDelay,
+	)
+	if err != nil {
+		req.err <- err
+		return
+	}
+
+	msg, err := lnwallet.NewDynPropose(l.ChanID(), req.params)
+	if err != nil {
+		req.err <- err
+		return
+	}
+
+	if err := l.channel.State().PutDynProposal(msg); err != nil {
+		req.err <- err
+		return
+	}
+
+	l.log.Infof("making channel quiescent to propose new channel " +
+		"parameters")
+
+	l.dynProposal = req
+	l.startQuiescence()
+	l.maybeSendStfu()
+}
+
+// restartDynProposal makes the channel quiescent again to retransmit our
+// stored dynamic commitment proposal, if the remote peer didn't respond to it
+// before.
+func (l *channelLink) restartDynProposal() {
+	msg, err := l.channel.State().FetchDynProposal()
+	switch {
+	case errors.Is(err, channeldb.ErrNoDynProposal):
+		return
+
+	case err != nil:
+		l.log.Errorf("unable to fetch dyn proposal: %v", err)
+		return
+	}
+
+	// If the peer no longer supports dynamic commitments, it won't be
+	// able to process the proposal, so we give up on it.
+	if !l.cfg.DynCommitments {
+		l.log.Warnf("peer no longer supports dynamic commitments, " +
+			"dropping channel parameter proposal")
+
+		if err := l.channel.State().DeleteDynProposal(); err != nil {
+			l.log.Errorf("unable to delete dyn proposal: %v", err)
+		}
+
+		return
+	}
+
+	params, err := lnwallet.DynCommitParamsFromMsg(msg)
+	if err != nil {
+		l.log.Errorf("invalid stored dyn proposal: %v", err)
+		return
+	}
+
+	l.log.Infof("retransmitting channel parameter proposal")
+
+	l.dynProposal = &dynCommitReq{
+		params: params,
+		err:    make(chan error, 1),
+	}
+	l.startQuiescence()
+	l.maybeSendStfu()
+}
+
+// abortDynProposal drops our dynamic commitment proposal and returns the given
+// error to its caller.
+func (l *channelLink) abortDynProposal(reason error) {
+	req := l.dynProposal
+	if req == nil {
+		return
+	}
+	l.dynProposal = nil
+
+	if err := l.channel.State().DeleteDynProposal(); err != nil {
+		l.log.Errorf("unable to delete dyn proposal: %v", err)
+	}
+
+	req.err <- reason
+}
+
+// startQuiescence starts the timeout of the stfu exchange and the dynamic
+// commitment negotiation that follows it, unless it is already running.
+func (l *channelLink) startQuiescence() {
+	if l.quiescence.timeout == nil {
+		l.quiescence.timeout = time.After(quiescenceTimeout)
+	}
+}
+
+// maybeSendStfu sends stfu to the remote peer once we want to make the channel
+// quiescent, or the remote peer asked us to, and none of our updates are
+// pending anymore. As the initiator, we additionally wait for all HTLCs to be
+// resolved, as the channel parameters can't be changed otherwise.
+func (l *channelLink) maybeSendStfu() {
+	q := &l.quiescence
+	if l.failed || q.sent {
+		return
+	}
+
+	initiator := l.dynProposal != nil
+	switch {
+	case !initiator && !q.received:
+		return
+
+	case initiator && !l.channel.IsChannelClean():
+		return
+
+	case !l.channel.CommitmentsSynced():
+		return
+	}
+
+	l.log.Debugf("sending stfu, initiator=%v", initiator)
+
+	err := l.cfg.Peer.SendMessage(false, &lnwire.Stfu{
+		ChanID:    l.ChanID(),
+		Initiator: initiator,
+	})
+	if err != nil {
+		l.log.Errorf("unable to send stfu: %v", err)
+		return
+	}
+	q.sent = true
+
+	l.maybeSendDynProposal()
+}
+
+// handleStfu processes the stfu of the remote peer. If both parties asked to
+// make the channel quiescent at the same time, the initiator of the channel
+// takes precedence.
+func (l *channelLink) handleStfu(msg *lnwire.Stfu) {
+	q := &l.quiescence
+
+	switch {
+	case !l.cfg.DynCommitments:
+		l.fail(LinkFailureError{code: ErrInvalidUpdate},
+			"received stfu without dynamic commitment support")
+		return
+
+	case q.received:
+		l.fail(LinkFailureError{code: ErrInvalidUpdate},
+			"received stfu twice")
+		return
+
+	case !msg.Initiator && !q.sent:
+		l.fail(LinkFailureError{code: ErrInvalidUpdate},
+			"received stfu reply without sending stfu")
+		return
+	}
+
+	q.received = true
+	q.remoteInitiator = msg.Initiator
+
+	if msg.Initiator && l.dynProposal != nil && !l.channel.IsInitiator() {
+		l.log.Infof("peer is changing channel parameters at the same " +
+			"time, dropping our proposal")
+
+		l.abortDynProposal(ErrDynCommitInProgress)
+	}
+
+	l.startQuiescence()
+	l.maybeSendStfu()
+	l.maybeSendDynProposal()
+}
+
+// maybeSendDynProposal sends our dynamic commitment proposal once the channel
+// is quiescent.
+func (l *channelLink) maybeSendDynProposal() {
+	q := &l.quiescence
+	if l.failed || !q.isQuiescent() || q.proposed || l.dynProposal == nil {
+		return
+	}
+
+	msg, err := lnwallet.NewDynPropose(l.ChanID(), l.dynProposal.params)
+	if err != nil {
+		l.abortDynProposal(err)
+		l.endQuiescence()
+		return
+	}
+
+	l.log.Infof("proposing new channel parameters to peer")
+
+	q.proposed = true
+	_ = l.cfg.Peer.SendMessage(false, msg)
+}
+
+// endQuiescence resets the quiescence state once the dynamic commitment
+// negotiation is completed and processes the remote adds that were deferred
+// while the channel was quiescent.
+func (l *channelLink) endQuiescence() {
+	deferred := l.quiescence.deferredAdds
+	l.quiescence = quiescenceState{}
+
+	for _, d := range deferred {
+		if l.failed {
+			return
+		}
+
+		l.processRemoteAdds(d.fwdPkg, d.adds)
+	}
+
+	if len(deferred) > 0 && !l.failed && l.channel.OweCommitment() {
+		l.updateCommitTxOrFail()
+	}
+}
+
+// handleQuiescenceTimeout is called if the channel didn't become quiescent or
+// the dynamic commitment negotiation wasn't completed in time. If we didn't
+// send stfu yet, we simply give up on our proposal. Otherwise, the only way to
+// leave the quiescent state is to disconnect from the peer.
+func (l *channelLink) handleQuiescenceTimeout() {
+	q := &l.quiescence
+	if !q.sent && !q.received {
+		l.log.Infof("channel didn't become quiescent in time, " +
+			"dropping channel parameter proposal")
+
+		l.abortDynProposal(ErrChannelNotQuiescent)
+		l.endQuiescence()
+
+		return
+	}
+
+	l.fail(
+		LinkFailureError{
+			code:          ErrRemoteUnresponsive,
+			FailureAction: LinkFailureDisconnect,
+		},
+		"dynamic commitment negotiation timed out",
+	)
+}
+
+// dynProposalFields returns a vector with a bit set for the TLV type of every
+// parameter contained in the given proposal.
+func dynProposalFields(msg *lnwire.DynPropose) *lnwire.RawFeatureVector {
+	fields := lnwire.NewRawFeatureVector()
+	if msg.DustLimit != nil {
+		fields.Set(lnwire.FeatureBit(lnwire.DPDustLimitSatoshis))
+	}
+	if msg.MaxAcceptedHTLCs != nil {
+		fields.Set(lnwire.FeatureBit(lnwire.DPMaxAcceptedHtlcs))
+	}
+	if msg.CsvDelay != nil {
+		fields.Set(lnwire.FeatureBit(lnwire.DPToSelfDelay))
+	}
+	if msg.ChannelType != nil {
+		fields.Set(lnwire.FeatureBit(lnwire.DPChannelType))
+	}
+
+	return fields
+}
+
+// dynRejectedFields returns the names of the parameters that were rejected by
+// the remote peer.
+func dynRejectedFields(rejections *lnwire.RawFeatureVector) []string {
+	params := []struct {
+		field lnwire.FeatureBit
+		name  string
+	}{
+		{lnwire.FeatureBit(lnwire.DPDustLimitSatoshis), "dust_limit"},
+		{lnwire.FeatureBit(lnwire.DPMaxAcceptedHtlcs), "max_htlcs"},
+		{lnwire.FeatureBit(lnwire.DPToSelfDelay), "csv_delay"},
+		{lnwire.FeatureBit(lnwire.DPChannelType), "channel_type"},
+	}
+
+	var fields []string
+	for _, param := range params {
+		if rejections.IsSet(param.field) {
+			fields = append(fields, param.name)
+		}
+	}
+
+	return fields
+}
+
+// handleDynPropose processes a dynamic commitment proposal of the remote
+// peer. If the channel is quiescent and the proposed parameters are
+// acceptable, they are applied and acknowledged. Otherwise, the proposal is
+// rejected.
+func (l *channelLink) handleDynPropose(msg *lnwire.DynPropose) {
+	reject := func(rejected *lnwire.RawFeatureVector, reason error) {
+		l.log.Infof("rejecting channel parameters proposed by peer: "+
+			"%v", reason)
+
+		_ = l.cfg.Peer.SendMessage(false, &lnwire.DynReject{
+			ChanID:           l.ChanID(),
+			UpdateRejections: *rejected,
+		})
+	}
+
+	if !l.cfg.DynCommitments {
+		reject(dynProposalFields(msg), ErrDynCommitUnsupported)
+		return
+	}
+
+	// The parameters can only be changed once the remote peer made the
+	// channel quiescent.
+	q := &l.quiescence
+	if !q.isQuiescent() || !q.remoteInitiator || l.dynProposal != nil {
+		reject(dynProposalFields(msg), ErrChannelNotQuiescent)
+		return
+	}
+
+	// Whatever our response, it completes the negotiation.
+	defer l.endQuiescence()
+
+	params, err := lnwallet.DynCommitParamsFromMsg(msg)
+	if err != nil {
+		reject(dynProposalFields(msg), err)
+		return
+	}
+
+	// If the proposed parameters are already in effect, we accepted the
+	// proposal before, but the peer didn't receive our acknowledgement.
+	if l.channel.DynCommitParamsApplied(params, false) {
+		_ = l.cfg.Peer.SendMessage(false, &lnwire.DynAck{
+			ChanID: l.ChanID(),
+		})
+
+		return
+	}
+
+	// HTLCs that were added by us before we received the stfu of the
+	// peer prevent the change.
+	if !l.channel.IsChannelClean() {
+		reject(dynProposalFields(msg), ErrChannelNotQuiescent)
+		return
+	}
+
+	err = l.channel.ValidateDynCommitParams(
+		params, false, l.cfg.MaxLocalCSVDelay,
+	)
+	switch err := err.(type) {
+	case nil:
+
+	case *lnwallet.DynCommitRejectError:
+		reject(err.Rejected, err)
+		return
+
+	default:
+		reject(dynProposalFields(msg), err)
+		return
+	}
+
+	if !l.applyDynCommit(params, false) {
+		return
+	}
+
+	_ = l.cfg.Peer.SendMessage(false, &lnwire.DynAck{
+		ChanID: l.ChanID(),
+	})
+}
+
+// handleDynAck applies our dynamic commitment proposal after the remote peer
+// accepted it, and signs the first commitment with the new parameters.
+func (l *channelLink) handleDynAck() {
+	req := l.dynProposal
+	if req == nil || !l.quiescence.proposed {
+		l.log.Warnf("received unexpected dyn_ack from peer")
+		return
+	}
+	l.dynProposal = nil
+
+	if !l.applyDynCommit(req.params, true) {
+		req.err <- errors.New("unable to apply channel parameters")
+		return
+	}
+	req.err <- nil
+
+	l.endQuiescence()
+	if l.failed {
+		return
+	}
+
+	// As the initiator of the change, we sign the first commitment with
+	// the new parameters.
+	l.updateCommitTxOrFail()
+}
+
+// handleDynReject drops our dynamic commitment proposal after the remote peer
+// rejected it.
+func (l *channelLink) handleDynReject(msg *lnwire.DynReject) {
+	if l.dynProposal == nil || !l.quiescence.proposed {
+		l.log.Warnf("received unexpected dyn_reject from peer")
+		return
+	}
+
+	fields := dynRejectedFields(&msg.UpdateRejections)
+	l.log.Infof("peer rejected channel parameters: %v", fields)
+
+	// HTLCs the peer added before it received our stfu prevent the
+	// change.
+	reason := fmt.Errorf("peer rejected channel parameters: %v",
+		strings.Join(fields, ", "))
+	if !l.channel.IsChannelClean() {
+		reason = ErrChannelNotQuiescent
+	}
+
+	l.abortDynProposal(reason)
+	l.endQuiescence()
+}
+
+// applyDynCommit applies the accepted channel parameters of a dynamic
+// commitment negotiation. It returns false if the link was failed.
+func (l *channelLink) applyDynCommit(params *lnwallet.DynCommitParams,
+	local bool) bool {
+
+	prevChanType := l.channel.ChanType()
+
+	if err := l.channel.ApplyDynCommitParams(params, local); err != nil {
+		l.fail(LinkFailureError{code: ErrInternalError},
+			"unable to apply channel parameters: %v", err)
+		return false
+	}
+
+	// The dust limits might have changed, so the mailbox needs to know.
+	l.mailBox.SetDustClosure(l.getDustClosure())
+
+	// The backups of the tower client only support a single commitment
+	// type, so we stop backing up the channel until the link is restarted
+	// and picks up the client for the new type.
+	if l.channel.ChanType() != prevChanType && l.cfg.TowerClient != nil {
+		l.log.Infof("commitment type changed, pausing watchtower " +
+			"backups until restart")
+		l.cfg.TowerClient = nil
+	}
+
+	return true
+}
+
 // updateChannelFee updates the commitment fee-per-kw on this channel by
 // committing to an update_fee message.
 func (l *channelLink) updateChannelFee(feePerKw chainfee.SatPerKWeight) error {
@@ -2785,6 +3541,14 @@ func (l *channelLink) updateChannelFee(feePerKw chainfee.SatPerKWeight) error {
 		return nil
 	}
 
+	// We also skip it if the new fee would make the fee exposure of the
+	// channel exceed our limit.
+	err := l.channel.CheckFeeExposure(l.cfg.MaxFeeExposure, feePerKw)
+	if err != nil {
+		l.log.Warnf("skipping fee update: %v", err)
+		return nil
+	}
+
 	// First, we'll update the local fee on our commitment.
 	if err := l.channel.UpdateFee(feePerKw); err != nil {
 		return err
@@ -2885,6 +3649,15 @@ func (l *channelLink) processRemoteSettleFails(fwdPkg *channeldb.FwdPkg,
 				},
 			}
 
+			// Pass on the attribution data of the failure, so
+			// that we can add ours when relaying it.
+			err := failPacket.htlc.(*lnwire.UpdateFailHTLC).
+				SetAttributionData(pd.AttributionData)
+			if err != nil {
+				l.log.Errorf("unable to set attribution data: "+
+					"%v", err)
+			}
+
 			l.log.Debugf("Failed to send %s", pd.Amount)
 
 			// If the failure message lacks an HMAC (but includes
@@ -2948,6 +3721,13 @@ func (l *channelLink) processRemoteAdds(fwdPkg *channeldb.FwdPkg,
 		}
 	}
 
+	// Fetch the inbound fee that we charge for htlcs arriving through this
+	// link. It is passed on to the switch with every forwarded htlc, so
+	// that the outgoing link can verify that it was paid.
+	l.RLock()
+	inboundFee := l.cfg.FwrdingPolicy.InboundFee
+	l.RUnlock()
+
 	// Atomically decode the incoming htlcs, simultaneously checking for
 	// replay attempts. A particular index in the returned, spare list of
 	// channel iterators should only be used if the failure code at the
@@ -3022,6 +3802,22 @@ func (l *channelLink) processRemoteAdds(fwdPkg *channeldb.FwdPkg,
 			continue
 		}
 
+		// HTLCs that are dust on one of the commitments and make the
+		// fee exposure of the channel exceed our limit are failed
+		// back, so that they don't end up burned on force close.
+		err := l.channel.CheckHtlcFeeExposure(
+			l.cfg.MaxFeeExposure, pd.Amount, true,
+		)
+		if err != nil {
+			failure := NewLinkError(
+				lnwire.NewTemporaryChannelFailure(nil),
+			)
+			l.sendHTLCError(pd, failure, obfuscator, false)
+
+			l.log.Errorf("unable to accept htlc: %v", err)
+			continue
+		}
+
 		heightNow := l.cfg.BestHeight()
 
 		pld, err := chanIterator.HopPayload()
@@ -3108,17 +3904,19 @@ func (l *channelLink) processRemoteAdds(fwdPkg *channeldb.FwdPkg,
 				chanIterator.EncodeNextHop(buf)
 
 				updatePacket := &htlcPacket{
-					incomingChanID:  l.ShortChanID(),
-					incomingHTLCID:  pd.HtlcIndex,
-					outgoingChanID:  fwdInfo.NextHop,
-					sourceRef:       pd.SourceRef,
-					incomingAmount:  pd.Amount,
-					amount:          addMsg.Amount,
-					htlc:            addMsg,
-					obfuscator:      obfuscator,
-					incomingTimeout: pd.Timeout,
-					outgoingTimeout: fwdInfo.OutgoingCTLV,
-					customRecords:   pld.CustomRecords(),
+					incomingChanID:      l.ShortChanID(),
+					incomingHTLCID:      pd.HtlcIndex,
+					outgoingChanID:      fwdInfo.NextHop,
+					sourceRef:           pd.SourceRef,
+					incomingAmount:      pd.Amount,
+					amount:              addMsg.Amount,
+					htlc:                addMsg,
+					obfuscator:          obfuscator,
+					incomingTimeout:     pd.Timeout,
+					outgoingTimeout:     fwdInfo.OutgoingCTLV,
+					customRecords:       pld.CustomRecords(),
+					inboundFee:          inboundFee,
+					incomingEndorsement: pd.Endorsement,
 				}
 				switchPackets = append(
 					switchPackets, updatePacket,
@@ -3172,17 +3970,19 @@ func (l *channelLink) processRemoteAdds(fwdPkg *channeldb.FwdPkg,
 			// section.
 			if fwdPkg.State == channeldb.FwdStateLockedIn {
 				updatePacket := &htlcPacket{
-					incomingChanID:  l.ShortChanID(),
-					incomingHTLCID:  pd.HtlcIndex,
-					outgoingChanID:  fwdInfo.NextHop,
-					sourceRef:       pd.SourceRef,
-					incomingAmount:  pd.Amount,
-					amount:          addMsg.Amount,
-					htlc:            addMsg,
-					obfuscator:      obfuscator,
-					incomingTimeout: pd.Timeout,
-					outgoingTimeout: fwdInfo.OutgoingCTLV,
-					customRecords:   pld.CustomRecords(),
+					incomingChanID:      l.ShortChanID(),
+					incomingHTLCID:      pd.HtlcIndex,
+					outgoingChanID:      fwdInfo.NextHop,
+					sourceRef:           pd.SourceRef,
+					incomingAmount:      pd.Amount,
+					amount:              addMsg.Amount,
+					htlc:                addMsg,
+					obfuscator:          obfuscator,
+					incomingTimeout:     pd.Timeout,
+					outgoingTimeout:     fwdInfo.OutgoingCTLV,
+					customRecords:       pld.CustomRecords(),
+					inboundFee:          inboundFee,
+					incomingEndorsement: pd.Endorsement,
 				}
 
 				fwdPkg.FwdFilter.Set(idx)
@@ -3309,6 +4109,8 @@ func (l *channelLink) settleHTLC(preimage lntypes.Preimage,
 
 	l.log.Infof("settling htlc %v as exit hop", hash)
 
+	delete(l.addTimes, pd.HtlcIndex)
+
 	err := l.channel.SettleHTLC(
 		preimage, pd.HtlcIndex, pd.SourceRef, nil, nil,
 	)
@@ -3347,6 +4149,39 @@ func (l *channelLink) settleHTLC(preimage lntypes.Preimage,
 	return nil
 }
 
+// attributeFailure adds our hold time and HMACs to the attribution data of a
+// failure of the incoming HTLC with the given index. The hold time is measured
+// from when the remote peer added the HTLC. Nil is returned if the failure
+// can't be attributed, in which case it is sent without attribution data.
+func (l *channelLink) attributeFailure(htlcIndex uint64, e hop.ErrorEncrypter,
+	reason lnwire.OpaqueReason,
+	attrData lnwire.AttributionData) lnwire.AttributionData {
+
+	addTime, ok := l.addTimes[htlcIndex]
+	delete(l.addTimes, htlcIndex)
+
+	if e == nil {
+		return nil
+	}
+
+	// If we don't know when the HTLC was added, for example because we
+	// restarted in the meantime, we report a hold time of zero.
+	var holdTime time.Duration
+	if ok {
+		holdTime = time.Since(addTime)
+	}
+
+	attrData, err := e.AddAttribution(reason, attrData, holdTime)
+	if err != nil {
+		l.log.Debugf("unable to attribute failure of htlc %v: %v",
+			htlcIndex, err)
+
+		return nil
+	}
+
+	return attrData
+}
+
 // forwardBatch forwards the given htlcPackets to the switch, and waits on the
 // err chan for the individual responses. This method is intended to be spawned
 // as a goroutine so the responses can be handled in the background.
@@ -3381,17 +4216,25 @@ func (l *channelLink) sendHTLCError(pd *lnwallet.PaymentDescriptor,
 		return
 	}
 
-	err = l.channel.FailHTLC(pd.HtlcIndex, reason, pd.SourceRef, nil, nil)
+	attrData := l.attributeFailure(pd.HtlcIndex, e, reason, nil)
+
+	err = l.channel.FailHTLC(
+		pd.HtlcIndex, reason, attrData, pd.SourceRef, nil, nil,
+	)
 	if err != nil {
 		l.log.Errorf("unable cancel htlc: %v", err)
 		return
 	}
 
-	l.cfg.Peer.SendMessage(false, &lnwire.UpdateFailHTLC{
+	failMsg := &lnwire.UpdateFailHTLC{
 		ChanID: l.ChanID(),
 		ID:     pd.HtlcIndex,
 		Reason: reason,
-	})
+	}
+	if err := failMsg.SetAttributionData(attrData); err != nil {
+		l.log.Errorf("unable to set attribution data: %v", err)
+	}
+	l.cfg.Peer.SendMessage(false, failMsg)
 
 	// Notify a link failure on our incoming link. Outgoing htlc information
 	// is not available at this point, because we have not decrypted the
@@ -3425,6 +4268,8 @@ func (l *channelLink) sendHTLCError(pd *lnwallet.PaymentDescriptor,
 func (l *channelLink) sendMalformedHTLCError(htlcIndex uint64,
 	code lnwire.FailCode, onionBlob []byte, sourceRef *channeldb.AddRef) {
 
+	delete(l.addTimes, htlcIndex)
+
 	shaOnionBlob := sha256.Sum256(onionBlob)
 	err := l.channel.MalformedFailHTLC(htlcIndex, code, shaOnionBlob, sourceRef)
 	if err != nil {
@@ -250,7 +250,7 @@ func (l *linkTestContext) receiveFailAliceToBob() {
 		l.t.Fatalf("expected UpdateFailHTLC, got %T", msg)
 	}
 
-	err := l.bobChannel.ReceiveFailHTLC(failMsg.ID, failMsg.Reason)
+	err := l.bobChannel.ReceiveFailHTLC(failMsg.ID, failMsg.Reason, nil)
 	if err != nil {
 		l.t.Fatalf("unable to apply received fail htlc: %v", err)
 	}
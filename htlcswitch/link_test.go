@@ -2387,7 +2387,7 @@ func TestChannelLinkBandwidthConsistency(t *testing.T) {
 	reason := make([]byte, 292)
 	copy(reason, []byte("nop"))
 
-	err = bobChannel.FailHTLC(bobIndex, reason, nil, nil, nil)
+	err = bobChannel.FailHTLC(bobIndex, reason, nil, nil, nil, nil)
 	require.NoError(t, err, "unable to fail htlc")
 	failMsg := &lnwire.UpdateFailHTLC{
 		ID:     1,
@@ -2588,7 +2588,7 @@ func TestChannelLinkBandwidthConsistency(t *testing.T) {
 	if !ok {
 		t.Fatalf("expected UpdateFailHTLC, got %T", msg)
 	}
-	err = bobChannel.ReceiveFailHTLC(failMsg.ID, []byte("fail"))
+	err = bobChannel.ReceiveFailHTLC(failMsg.ID, []byte("fail"), nil)
 	require.NoError(t, err, "failed receiving fail htlc")
 
 	// After failing an HTLC, the link will automatically trigger
@@ -5767,11 +5767,16 @@ func TestCheckHtlcForward(t *testing.T) {
 
 	link.attachFailAliasUpdate(failAliasUpdate)
 
-	var hash [32]byte
+	var (
+		hash      [32]byte
+		noInbound models.InboundFee
+	)
 
 	t.Run("satisfied", func(t *testing.T) {
 		result := link.CheckHtlcForward(hash, 1500, 1000,
-			200, 150, 0, lnwire.ShortChannelID{})
+			200, 150, noInbound, 0,
+			lnwire.ShortChannelID{},
+		)
 		if result != nil {
 			t.Fatalf("expected policy to be satisfied")
 		}
@@ -5779,7 +5784,9 @@ func TestCheckHtlcForward(t *testing.T) {
 
 	t.Run("below minhtlc", func(t *testing.T) {
 		result := link.CheckHtlcForward(hash, 100, 50,
-			200, 150, 0, lnwire.ShortChannelID{})
+			200, 150, noInbound, 0,
+			lnwire.ShortChannelID{},
+		)
 		if _, ok := result.WireMessage().(*lnwire.FailAmountBelowMinimum); !ok {
 			t.Fatalf("expected FailAmountBelowMinimum failure code")
 		}
@@ -5787,7 +5794,9 @@ func TestCheckHtlcForward(t *testing.T) {
 
 	t.Run("above maxhtlc", func(t *testing.T) {
 		result := link.CheckHtlcForward(hash, 1500, 1200,
-			200, 150, 0, lnwire.ShortChannelID{})
+			200, 150, noInbound, 0,
+			lnwire.ShortChannelID{},
+		)
 		if _, ok := result.WireMessage().(*lnwire.FailTemporaryChannelFailure); !ok {
 			t.Fatalf("expected FailTemporaryChannelFailure failure code")
 		}
@@ -5795,7 +5804,9 @@ func TestCheckHtlcForward(t *testing.T) {
 
 	t.Run("insufficient fee", func(t *testing.T) {
 		result := link.CheckHtlcForward(hash, 1005, 1000,
-			200, 150, 0, lnwire.ShortChannelID{})
+			200, 150, noInbound, 0,
+			lnwire.ShortChannelID{},
+		)
 		if _, ok := result.WireMessage().(*lnwire.FailFeeInsufficient); !ok {
 			t.Fatalf("expected FailFeeInsufficient failure code")
 		}
@@ -5808,7 +5819,52 @@ func TestCheckHtlcForward(t *testing.T) {
 
 		result := link.CheckHtlcForward(
 			hash, 100005, 100000, 200,
-			150, 0, lnwire.ShortChannelID{},
+			150, noInbound, 0, lnwire.ShortChannelID{},
+		)
+		_, ok := result.WireMessage().(*lnwire.FailFeeInsufficient)
+		require.True(t, ok, "expected FailFeeInsufficient failure code")
+	})
+
+	t.Run("inbound fee satisfied", func(t *testing.T) {
+		// The incoming link charges 10 msat plus 1% of the amount to
+		// forward including the outbound fee, which is 1010 msat.
+		inboundFee := models.InboundFee{Base: 10, Rate: 10_000}
+		result := link.CheckHtlcForward(hash, 1030, 1000,
+			200, 150, inboundFee, 0, lnwire.ShortChannelID{},
+		)
+		require.Nil(t, result)
+	})
+
+	t.Run("inbound fee insufficient", func(t *testing.T) {
+		inboundFee := models.InboundFee{Base: 10, Rate: 10_000}
+		result := link.CheckHtlcForward(hash, 1029, 1000,
+			200, 150, inboundFee, 0, lnwire.ShortChannelID{},
+		)
+		_, ok := result.WireMessage().(*lnwire.FailFeeInsufficient)
+		require.True(t, ok, "expected FailFeeInsufficient failure code")
+	})
+
+	t.Run("inbound discount", func(t *testing.T) {
+		// A discount of 5 msat lowers the required fee to 5 msat.
+		inboundFee := models.InboundFee{Base: -5}
+		result := link.CheckHtlcForward(hash, 1005, 1000,
+			200, 150, inboundFee, 0, lnwire.ShortChannelID{},
+		)
+		require.Nil(t, result)
+	})
+
+	t.Run("inbound discount exceeding outbound fee", func(t *testing.T) {
+		// A discount larger than the outbound fee doesn't require
+		// any fee, but the htlc still can't pay out more than it
+		// brought in.
+		inboundFee := models.InboundFee{Base: -50}
+		result := link.CheckHtlcForward(hash, 1000, 1000,
+			200, 150, inboundFee, 0, lnwire.ShortChannelID{},
+		)
+		require.Nil(t, result)
+
+		result = link.CheckHtlcForward(hash, 999, 1000,
+			200, 150, inboundFee, 0, lnwire.ShortChannelID{},
 		)
 		_, ok := result.WireMessage().(*lnwire.FailFeeInsufficient)
 		require.True(t, ok, "expected FailFeeInsufficient failure code")
@@ -5816,7 +5872,9 @@ func TestCheckHtlcForward(t *testing.T) {
 
 	t.Run("expiry too soon", func(t *testing.T) {
 		result := link.CheckHtlcForward(hash, 1500, 1000,
-			200, 150, 190, lnwire.ShortChannelID{})
+			200, 150, noInbound, 190,
+			lnwire.ShortChannelID{},
+		)
 		if _, ok := result.WireMessage().(*lnwire.FailExpiryTooSoon); !ok {
 			t.Fatalf("expected FailExpiryTooSoon failure code")
 		}
@@ -5824,7 +5882,9 @@ func TestCheckHtlcForward(t *testing.T) {
 
 	t.Run("incorrect cltv expiry", func(t *testing.T) {
 		result := link.CheckHtlcForward(hash, 1500, 1000,
-			200, 190, 0, lnwire.ShortChannelID{})
+			200, 190, noInbound, 0,
+			lnwire.ShortChannelID{},
+		)
 		if _, ok := result.WireMessage().(*lnwire.FailIncorrectCltvExpiry); !ok {
 			t.Fatalf("expected FailIncorrectCltvExpiry failure code")
 		}
@@ -5834,7 +5894,9 @@ func TestCheckHtlcForward(t *testing.T) {
 	t.Run("cltv expiry too far in the future", func(t *testing.T) {
 		// Check that expiry isn't too far in the future.
 		result := link.CheckHtlcForward(hash, 1500, 1000,
-			10200, 10100, 0, lnwire.ShortChannelID{})
+			10200, 10100, noInbound, 0,
+			lnwire.ShortChannelID{},
+		)
 		if _, ok := result.WireMessage().(*lnwire.FailExpiryTooFar); !ok {
 			t.Fatalf("expected FailExpiryTooFar failure code")
 		}
@@ -6854,7 +6916,7 @@ func TestChannelLinkShortFailureRelay(t *testing.T) {
 	// Return a short htlc failure from Bob to Alice and lock in.
 	shortReason := make([]byte, 260)
 
-	err = bobChannel.FailHTLC(0, shortReason, nil, nil, nil)
+	err = bobChannel.FailHTLC(0, shortReason, nil, nil, nil, nil)
 	require.NoError(t, err)
 
 	aliceLink.HandleChannelUpdate(&lnwire.UpdateFailHTLC{
@@ -6894,3 +6956,94 @@ func TestChannelLinkShortFailureRelay(t *testing.T) {
 	default:
 	}
 }
+
+// TestChannelLinkDynCommitment asserts that two links can upgrade their
+// channel to anchors through a dynamic commitment negotiation, that invalid
+// proposals are rejected, and that payments work with the new parameters.
+func TestChannelLinkDynCommitment(t *testing.T) {
+	t.Parallel()
+
+	alice, bob, err := createTwoClusterChannels(
+		t, btcutil.SatoshiPerBitcoin*3, btcutil.SatoshiPerBitcoin*5,
+	)
+	require.NoError(t, err, "unable to create channel")
+
+	n := newTwoHopNetwork(t, alice.channel, bob.channel, testStartingHeight)
+
+	// Bob doesn't accept a delay above his maximum for his outputs, so he
+	// should reject the proposal.
+	tooLarge := testMaxLocalCSVDelay + 1
+	err = n.aliceChannelLink.ProposeDynCommit(&lnwallet.DynCommitParams{
+		CsvDelay: &tooLarge,
+	})
+	require.ErrorContains(t, err, "csv_delay")
+
+	// Now upgrade the channel to anchors.
+	anchors := lnwallet.CommitmentType(
+		lnwallet.CommitmentTypeAnchorsZeroFeeHtlcTx,
+	)
+	err = n.aliceChannelLink.ProposeDynCommit(&lnwallet.DynCommitParams{
+		CommitmentType: &anchors,
+	})
+	require.NoError(t, err)
+
+	// Wait until both parties have switched to commitments with the new
+	// type.
+	require.Eventually(t, func() bool {
+		for _, c := range []*lnwallet.LightningChannel{
+			alice.channel, bob.channel,
+		} {
+
+			if c.DynCommitPending() || !c.IsChannelClean() {
+				return false
+			}
+		}
+
+		return true
+	}, 10*time.Second, 50*time.Millisecond)
+
+	for _, c := range []*lnwallet.LightningChannel{
+		alice.channel, bob.channel,
+	} {
+
+		require.True(t, c.ChanType().HasAnchors())
+		require.Len(t, c.State().LocalCommitment.CommitTx.TxOut, 4)
+	}
+
+	// A payment should go through with the new commitment type.
+	amount := lnwire.NewMSatFromSatoshis(btcutil.SatoshiPerBitcoin)
+	htlcAmt, totalTimelock, hops := generateHops(amount, testStartingHeight,
+		n.bobChannelLink)
+
+	_, err = makePayment(
+		n.aliceServer, n.bobServer, n.bobChannelLink.ShortChanID(),
+		hops, amount, htlcAmt, totalTimelock,
+	).Wait(30 * time.Second)
+	require.NoError(t, err, "unable to make the payment")
+
+	// If both parties propose new parameters at the same time, the
+	// proposal of the channel initiator takes precedence.
+	aliceDelay := testMaxLocalCSVDelay - 1
+	bobDelay := testMaxLocalCSVDelay - 2
+	aliceErr := make(chan error, 1)
+	bobErr := make(chan error, 1)
+	go func() {
+		aliceErr <- n.aliceChannelLink.ProposeDynCommit(
+			&lnwallet.DynCommitParams{CsvDelay: &aliceDelay},
+		)
+	}()
+	go func() {
+		bobErr <- n.bobChannelLink.ProposeDynCommit(
+			&lnwallet.DynCommitParams{CsvDelay: &bobDelay},
+		)
+	}()
+
+	require.NoError(t, <-aliceErr)
+
+	// Depending on the timing, Bob either dropped his proposal in favor
+	// of Alice's, or completed it before Alice proposed hers.
+	err = <-bobErr
+	if err != nil {
+		require.ErrorIs(t, err, ErrDynCommitInProgress)
+	}
+}
@@ -8,6 +8,23 @@ var (
 
 	// ErrLinkFailedShutdown signals that a requested shutdown failed.
 	ErrLinkFailedShutdown = errors.New("link failed to shutdown")
+
+	// ErrDynCommitUnsupported signals that the channel parameters can't be
+	// changed because the dynamic-commitments feature wasn't negotiated
+	// with the peer.
+	ErrDynCommitUnsupported = errors.New("peer doesn't support dynamic " +
+		"commitments")
+
+	// ErrDynCommitInProgress signals that a dynamic commitment negotiation
+	// is already in progress for the channel.
+	ErrDynCommitInProgress = errors.New("dynamic commitment negotiation " +
+		"already in progress")
+
+	// ErrChannelNotQuiescent signals that the channel couldn't be made
+	// quiescent in time, or that it still has HTLCs that prevent a dynamic
+	// commitment negotiation.
+	ErrChannelNotQuiescent = errors.New("channel has pending HTLCs or " +
+		"updates")
 )
 
 // errorCode encodes the possible types of errors that will make us fail the
@@ -4,6 +4,7 @@ import (
 	"github.com/btcsuite/btclog"
 	"github.com/lightningnetwork/lnd/build"
 	"github.com/lightningnetwork/lnd/htlcswitch/hop"
+	"github.com/lightningnetwork/lnd/htlcswitch/reputation"
 )
 
 // log is a logger that is initialized with no output filters.  This
@@ -30,6 +31,7 @@ func DisableLog() {
 func UseLogger(logger btclog.Logger) {
 	log = logger
 	hop.UseLogger(logger)
+	reputation.UseLogger(logger)
 }
 
 // logClosure is used to provide a closure over expensive logging operations so
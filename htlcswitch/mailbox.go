@@ -735,6 +735,7 @@ func (m *memoryMailBox) FailAdd(pkt *htlcPacket) {
 		incomingChanID: pkt.incomingChanID,
 		incomingHTLCID: pkt.incomingHTLCID,
 		circuit:        pkt.circuit,
+		obfuscator:     pkt.obfuscator,
 		sourceRef:      pkt.sourceRef,
 		hasSource:      true,
 		localFailure:   localFailure,
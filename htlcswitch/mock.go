@@ -28,10 +28,13 @@ import (
 	"github.com/lightningnetwork/lnd/clock"
 	"github.com/lightningnetwork/lnd/contractcourt"
 	"github.com/lightningnetwork/lnd/htlcswitch/hop"
+	"github.com/lightningnetwork/lnd/htlcswitch/reputation"
+	"github.com/lightningnetwork/lnd/input"
 	"github.com/lightningnetwork/lnd/invoices"
 	"github.com/lightningnetwork/lnd/lnpeer"
 	"github.com/lightningnetwork/lnd/lntest/mock"
 	"github.com/lightningnetwork/lnd/lntypes"
+	"github.com/lightningnetwork/lnd/lnwallet"
 	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
 	"github.com/lightningnetwork/lnd/lnwire"
 	"github.com/lightningnetwork/lnd/ticker"
@@ -200,9 +203,11 @@ func initSwitchWithDB(startingHeight uint32, db *channeldb.DB) (*Switch, error)
 		HtlcNotifier:           &mockHTLCNotifier{},
 		Clock:                  clock.NewDefaultClock(),
 		MailboxDeliveryTimeout: time.Hour,
-		DustThreshold:          DefaultDustThreshold,
-		SignAliasUpdate:        signAliasUpdate,
-		IsAlias:                isAlias,
+		MaxFeeExposure: lnwallet.FeeExposureLimit{
+			Max: DefaultMaxFeeExposure,
+		},
+		SignAliasUpdate: signAliasUpdate,
+		IsAlias:         isAlias,
 	}
 
 	return New(cfg, startingHeight)
@@ -437,6 +442,13 @@ func (o *mockObfuscator) IntermediateEncrypt(reason lnwire.OpaqueReason) lnwire.
 	return reason
 }
 
+func (o *mockObfuscator) AddAttribution(_ lnwire.OpaqueReason,
+	attrData lnwire.AttributionData, _ time.Duration) (
+	lnwire.AttributionData, error) {
+
+	return attrData, nil
+}
+
 func (o *mockObfuscator) EncryptMalformedError(reason lnwire.OpaqueReason) lnwire.OpaqueReason {
 	var b bytes.Buffer
 	b.Write(fakeHmac)
@@ -454,8 +466,8 @@ func newMockDeobfuscator() ErrorDecrypter {
 	return &mockDeobfuscator{}
 }
 
-func (o *mockDeobfuscator) DecryptError(reason lnwire.OpaqueReason) (
-	*ForwardingError, error) {
+func (o *mockDeobfuscator) DecryptError(reason lnwire.OpaqueReason,
+	_ lnwire.AttributionData) (*ForwardingError, error) {
 
 	if !bytes.Equal(reason[:32], fakeHmac) {
 		return nil, errors.New("fake decryption error")
@@ -625,6 +637,14 @@ func (s *mockServer) readHandler(message lnwire.Message) error {
 		targetChan = msg.ChanID
 	case *lnwire.UpdateFee:
 		targetChan = msg.ChanID
+	case *lnwire.Stfu:
+		targetChan = msg.ChanID
+	case *lnwire.DynPropose:
+		targetChan = msg.ChanID
+	case *lnwire.DynAck:
+		targetChan = msg.ChanID
+	case *lnwire.DynReject:
+		targetChan = msg.ChanID
 	default:
 		return fmt.Errorf("unknown message type: %T", msg)
 	}
@@ -730,6 +750,8 @@ type mockChannelLink struct {
 
 	checkHtlcForwardResult *LinkError
 
+	limits reputation.ChannelLimits
+
 	failAliasUpdate func(sid lnwire.ShortChannelID,
 		incoming bool) *lnwire.ChannelUpdate
 
@@ -800,6 +822,12 @@ func newMockChannelLink(htlcSwitch *Switch, chanID lnwire.ChannelID,
 		optionFeature: optionFeature,
 		aliases:       aliases,
 		confirmedZC:   realConfirmed,
+		limits: reputation.ChannelLimits{
+			Slots: input.MaxHTLCNumber / 2,
+			Liquidity: lnwire.NewMSatFromSatoshis(
+				btcutil.SatoshiPerBitcoin,
+			),
+		},
 	}
 }
 
@@ -813,8 +841,12 @@ func (f *mockChannelLink) handleSwitchPacket(pkt *htlcPacket) error {
 	return nil
 }
 
-func (f *mockChannelLink) getDustSum(remote bool) lnwire.MilliSatoshi {
-	return 0
+func (f *mockChannelLink) resourceLimits() reputation.ChannelLimits {
+	return f.limits
+}
+
+func (f *mockChannelLink) getFeeExposure(remote bool) lnwallet.FeeExposure {
+	return lnwallet.FeeExposure{}
 }
 
 func (f *mockChannelLink) getFeeRate() chainfee.SatPerKWeight {
@@ -834,7 +866,7 @@ func (f *mockChannelLink) HandleChannelUpdate(lnwire.Message) {
 func (f *mockChannelLink) UpdateForwardingPolicy(_ models.ForwardingPolicy) {
 }
 func (f *mockChannelLink) CheckHtlcForward([32]byte, lnwire.MilliSatoshi,
-	lnwire.MilliSatoshi, uint32, uint32, uint32,
+	lnwire.MilliSatoshi, uint32, uint32, models.InboundFee, uint32,
 	lnwire.ShortChannelID) *LinkError {
 
 	return f.checkHtlcForwardResult
@@ -900,6 +932,9 @@ func (f *mockChannelLink) Stop()                                        {}
 func (f *mockChannelLink) EligibleToForward() bool                      { return f.eligible }
 func (f *mockChannelLink) MayAddOutgoingHtlc(lnwire.MilliSatoshi) error { return nil }
 func (f *mockChannelLink) ShutdownIfChannelClean() error                { return nil }
+func (f *mockChannelLink) ProposeDynCommit(*lnwallet.DynCommitParams) error {
+	return nil
+}
 func (f *mockChannelLink) setLiveShortChanID(sid lnwire.ShortChannelID) { f.shortChanID = sid }
 func (f *mockChannelLink) IsUnadvertised() bool                         { return f.unadvertised }
 func (f *mockChannelLink) UpdateShortChanID() (lnwire.ShortChannelID, error) {
@@ -1128,3 +1163,7 @@ func (h *mockHTLCNotifier) NotifySettleEvent(key HtlcKey,
 func (h *mockHTLCNotifier) NotifyFinalHtlcEvent(key models.CircuitKey,
 	info channeldb.FinalHtlcInfo) { //nolint:whitespace
 }
+
+func (h *mockHTLCNotifier) NotifyRateLimitEvent(key HtlcKey, peer [33]byte,
+	limit RateLimit, scope RateLimitScope, count uint64) {
+}
@@ -2,6 +2,7 @@ package htlcswitch
 
 import (
 	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/channeldb/models"
 	"github.com/lightningnetwork/lnd/htlcswitch/hop"
 	"github.com/lightningnetwork/lnd/lnwire"
 	"github.com/lightningnetwork/lnd/record"
@@ -40,6 +41,15 @@ type htlcPacket struct {
 	// incoming link.
 	incomingAmount lnwire.MilliSatoshi
 
+	// inboundFee is the inbound fee that the incoming link charges for
+	// forwarding this HTLC. It is checked by the outgoing link together
+	// with its outbound fee.
+	inboundFee models.InboundFee
+
+	// incomingEndorsement is the experimental endorsement signal of the
+	// incoming HTLC.
+	incomingEndorsement lnwire.Endorsement
+
 	// amount is the value of the HTLC that is being created or modified.
 	amount lnwire.MilliSatoshi
 
@@ -0,0 +1,358 @@
+package htlcswitch
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/lightningnetwork/lnd/clock"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"golang.org/x/time/rate"
+)
+
+// RateLimit identifies one of the limits that are applied to the HTLCs a
+// peer or channel forwards through our node.
+type RateLimit uint8
+
+const (
+	// RateLimitAddRate limits the number of HTLCs added per second.
+	RateLimitAddRate RateLimit = iota
+
+	// RateLimitInFlight limits the value of the HTLCs in flight.
+	RateLimitInFlight
+
+	// RateLimitFailRatio limits the ratio of forwarded HTLCs that fail.
+	RateLimitFailRatio
+)
+
+// String returns a human-readable version of the limit.
+func (r RateLimit) String() string {
+	switch r {
+	case RateLimitAddRate:
+		return "add_rate"
+
+	case RateLimitInFlight:
+		return "in_flight"
+
+	case RateLimitFailRatio:
+		return "fail_ratio"
+
+	default:
+		return "unknown"
+	}
+}
+
+// failureDetail returns the failure detail of HTLCs that are failed because
+// the limit is exceeded.
+func (r RateLimit) failureDetail() OutgoingFailure {
+	switch r {
+	case RateLimitAddRate:
+		return OutgoingFailureAddRateLimit
+
+	case RateLimitInFlight:
+		return OutgoingFailureInFlightLimit
+
+	default:
+		return OutgoingFailureFailRatioLimit
+	}
+}
+
+// RateLimitScope is the scope a limit is applied to.
+type RateLimitScope uint8
+
+const (
+	// RateLimitScopePeer applies a limit to all channels with a peer.
+	RateLimitScopePeer RateLimitScope = iota
+
+	// RateLimitScopeChannel applies a limit to a single channel.
+	RateLimitScopeChannel
+)
+
+// String returns a human-readable version of the scope.
+func (r RateLimitScope) String() string {
+	switch r {
+	case RateLimitScopePeer:
+		return "peer"
+
+	case RateLimitScopeChannel:
+		return "channel"
+
+	default:
+		return "unknown"
+	}
+}
+
+// RateLimits holds the limits applied to the incoming HTLCs of either a peer
+// or a channel. A zero value disables the respective limit.
+type RateLimits struct {
+	// MaxAddRate is the maximum number of HTLCs that may be added per
+	// second. Short bursts of up to one second worth of HTLCs are allowed.
+	MaxAddRate float64
+
+	// MaxInFlight is the maximum value of the incoming HTLCs that may be
+	// in flight at the same time.
+	MaxInFlight lnwire.MilliSatoshi
+
+	// MaxFailRatio is the maximum ratio of forwarded HTLCs that may fail
+	// before further HTLCs are rejected.
+	MaxFailRatio float64
+}
+
+// RateLimiterConfig holds the configuration of the per-peer and per-channel
+// HTLC rate limits of the switch.
+type RateLimiterConfig struct {
+	// Peer are the limits applied to each peer across all its channels.
+	Peer RateLimits
+
+	// Channel are the limits applied to each channel.
+	Channel RateLimits
+
+	// FailRatioWindow is the period over which the fail ratio is
+	// measured. Older HTLCs count exponentially less.
+	FailRatioWindow time.Duration
+
+	// FailRatioMinHTLCs is the number of HTLCs that must have been
+	// resolved within the window before the fail ratio is enforced.
+	FailRatioMinHTLCs int
+
+	// Clock is the time source of the rate limiter.
+	Clock clock.Clock
+}
+
+// rateLimitTrigger describes a limit that was exceeded by an HTLC.
+type rateLimitTrigger struct {
+	limit RateLimit
+	scope RateLimitScope
+
+	// count is the number of times the limit was triggered for the peer
+	// or channel so far.
+	count uint64
+}
+
+// rateLimitState is the state tracked for a single peer or channel.
+type rateLimitState struct {
+	addLimiter *rate.Limiter
+
+	inFlight lnwire.MilliSatoshi
+
+	// failures and resolved are the number of failed and resolved HTLCs,
+	// decayed exponentially over the fail ratio window.
+	failures   float64
+	resolved   float64
+	lastDecay  time.Time
+	triggerCnt [3]uint64
+}
+
+// decay decays the resolved HTLC counts to the given time.
+func (s *rateLimitState) decay(now time.Time, window time.Duration) {
+	if !s.lastDecay.IsZero() && now.After(s.lastDecay) {
+		elapsed := now.Sub(s.lastDecay)
+		factor := math.Exp(-float64(elapsed) / float64(window))
+		s.failures *= factor
+		s.resolved *= factor
+	}
+
+	if now.After(s.lastDecay) {
+		s.lastDecay = now
+	}
+}
+
+// limitedHTLC is an admitted HTLC that is still in flight.
+type limitedHTLC struct {
+	peer   [33]byte
+	amount lnwire.MilliSatoshi
+}
+
+// rateLimiter enforces the per-peer and per-channel limits on the HTLCs that
+// are forwarded through our node.
+type rateLimiter struct {
+	cfg *RateLimiterConfig
+
+	mu       sync.Mutex
+	peers    map[[33]byte]*rateLimitState
+	channels map[lnwire.ShortChannelID]*rateLimitState
+	inFlight map[CircuitKey]*limitedHTLC
+}
+
+// newRateLimiter creates a new rate limiter with the given configuration.
+func newRateLimiter(cfg *RateLimiterConfig) *rateLimiter {
+	return &rateLimiter{
+		cfg:      cfg,
+		peers:    make(map[[33]byte]*rateLimitState),
+		channels: make(map[lnwire.ShortChannelID]*rateLimitState),
+		inFlight: make(map[CircuitKey]*limitedHTLC),
+	}
+}
+
+// newRateLimitState creates the state for a peer or channel with the given
+// limits.
+func newRateLimitState(limits *RateLimits) *rateLimitState {
+	state := &rateLimitState{}
+	if limits.MaxAddRate > 0 {
+		burst := int(math.Max(math.Ceil(limits.MaxAddRate), 1))
+		state.addLimiter = rate.NewLimiter(
+			rate.Limit(limits.MaxAddRate), burst,
+		)
+	}
+
+	return state
+}
+
+// peerState returns the state of the given peer, creating it if needed.
+//
+// NOTE: Must be called with the mutex held.
+func (r *rateLimiter) peerState(peer [33]byte) *rateLimitState {
+	state, ok := r.peers[peer]
+	if !ok {
+		state = newRateLimitState(&r.cfg.Peer)
+		r.peers[peer] = state
+	}
+
+	return state
+}
+
+// channelState returns the state of the given channel, creating it if
+// needed.
+//
+// NOTE: Must be called with the mutex held.
+func (r *rateLimiter) channelState(
+	scid lnwire.ShortChannelID) *rateLimitState {
+
+	state, ok := r.channels[scid]
+	if !ok {
+		state = newRateLimitState(&r.cfg.Channel)
+		r.channels[scid] = state
+	}
+
+	return state
+}
+
+// exceeded returns the first limit that an HTLC of the given amount would
+// exceed for the given state, if any. If no limit is exceeded, the add token
+// taken for the HTLC is returned, such that it can be handed back if the HTLC
+// is rejected for another reason.
+func (r *rateLimiter) exceeded(state *rateLimitState, limits *RateLimits,
+	amt lnwire.MilliSatoshi, now time.Time) (RateLimit, *rate.Reservation,
+	bool) {
+
+	var token *rate.Reservation
+	if state.addLimiter != nil {
+		token = state.addLimiter.ReserveN(now, 1)
+		if !token.OK() || token.DelayFrom(now) > 0 {
+			token.CancelAt(now)
+			return RateLimitAddRate, nil, true
+		}
+	}
+
+	// refund hands back the add token of an HTLC that is rejected.
+	refund := func() {
+		if token != nil {
+			token.CancelAt(now)
+		}
+	}
+
+	if limits.MaxInFlight > 0 && state.inFlight+amt > limits.MaxInFlight {
+		refund()
+		return RateLimitInFlight, nil, true
+	}
+
+	if limits.MaxFailRatio > 0 {
+		state.decay(now, r.cfg.FailRatioWindow)
+
+		minHTLCs := float64(r.cfg.FailRatioMinHTLCs)
+		if state.resolved >= minHTLCs && state.resolved > 0 &&
+			state.failures/state.resolved > limits.MaxFailRatio {
+
+			refund()
+			return RateLimitFailRatio, nil, true
+		}
+	}
+
+	return 0, token, false
+}
+
+// admit checks whether an incoming HTLC stays within the limits of its peer
+// and channel. If so, the HTLC is tracked until it is resolved through
+// resolve. Otherwise the limit that was exceeded is returned.
+func (r *rateLimiter) admit(key CircuitKey, peer [33]byte,
+	amt lnwire.MilliSatoshi) *rateLimitTrigger {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// HTLCs that are forwarded again, for example after the outgoing
+	// link went down, were already admitted.
+	if _, ok := r.inFlight[key]; ok {
+		return nil
+	}
+
+	now := r.cfg.Clock.Now()
+	peerState := r.peerState(peer)
+	chanState := r.channelState(key.ChanID)
+
+	// Check the channel limits first, as they are the more specific ones.
+	limit, chanToken, exceeded := r.exceeded(
+		chanState, &r.cfg.Channel, amt, now,
+	)
+	if exceeded {
+		chanState.triggerCnt[limit]++
+
+		return &rateLimitTrigger{
+			limit: limit,
+			scope: RateLimitScopeChannel,
+			count: chanState.triggerCnt[limit],
+		}
+	}
+
+	limit, _, exceeded = r.exceeded(peerState, &r.cfg.Peer, amt, now)
+	if exceeded {
+		// The HTLC isn't added to the channel, so it shouldn't count
+		// against the channel's add rate.
+		if chanToken != nil {
+			chanToken.CancelAt(now)
+		}
+
+		peerState.triggerCnt[limit]++
+
+		return &rateLimitTrigger{
+			limit: limit,
+			scope: RateLimitScopePeer,
+			count: peerState.triggerCnt[limit],
+		}
+	}
+
+	peerState.inFlight += amt
+	chanState.inFlight += amt
+	r.inFlight[key] = &limitedHTLC{
+		peer:   peer,
+		amount: amt,
+	}
+
+	return nil
+}
+
+// resolve releases the in-flight value of an admitted HTLC and records
+// whether it settled or failed. Unknown HTLCs are ignored.
+func (r *rateLimiter) resolve(key CircuitKey, settled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	htlc, ok := r.inFlight[key]
+	if !ok {
+		return
+	}
+	delete(r.inFlight, key)
+
+	now := r.cfg.Clock.Now()
+	for _, state := range []*rateLimitState{
+		r.peerState(htlc.peer), r.channelState(key.ChanID),
+	} {
+		state.inFlight -= htlc.amount
+
+		state.decay(now, r.cfg.FailRatioWindow)
+		state.resolved++
+		if !settled {
+			state.failures++
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package htlcswitch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lightningnetwork/lnd/clock"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	rateLimitPeer  = [33]byte{1}
+	rateLimitChan1 = lnwire.NewShortChanIDFromInt(1)
+	rateLimitChan2 = lnwire.NewShortChanIDFromInt(2)
+)
+
+// newTestRateLimiter creates a rate limiter with the given limits and a test
+// clock.
+func newTestRateLimiter(peer, channel RateLimits) (*rateLimiter,
+	*clock.TestClock) {
+
+	testClock := clock.NewTestClock(time.Unix(1700000000, 0))
+
+	return newRateLimiter(&RateLimiterConfig{
+		Peer:              peer,
+		Channel:           channel,
+		FailRatioWindow:   time.Minute,
+		FailRatioMinHTLCs: 4,
+		Clock:             testClock,
+	}), testClock
+}
+
+func circuitKey(scid lnwire.ShortChannelID, id uint64) CircuitKey {
+	return CircuitKey{
+		ChanID: scid,
+		HtlcID: id,
+	}
+}
+
+// TestRateLimiterAddRate tests that the number of HTLCs added per second is
+// limited per channel and per peer.
+func TestRateLimiterAddRate(t *testing.T) {
+	t.Parallel()
+
+	r, testClock := newTestRateLimiter(
+		RateLimits{MaxAddRate: 3}, RateLimits{MaxAddRate: 2},
+	)
+
+	// The channel limit is hit first.
+	require.Nil(t, r.admit(circuitKey(rateLimitChan1, 0), rateLimitPeer, 1))
+	require.Nil(t, r.admit(circuitKey(rateLimitChan1, 1), rateLimitPeer, 1))
+
+	trigger := r.admit(circuitKey(rateLimitChan1, 2), rateLimitPeer, 1)
+	require.Equal(t, &rateLimitTrigger{
+		limit: RateLimitAddRate,
+		scope: RateLimitScopeChannel,
+		count: 1,
+	}, trigger)
+
+	// Another channel with the same peer has its own channel limit, but
+	// shares the peer limit.
+	require.Nil(t, r.admit(circuitKey(rateLimitChan2, 0), rateLimitPeer, 1))
+
+	trigger = r.admit(circuitKey(rateLimitChan2, 1), rateLimitPeer, 1)
+	require.Equal(t, &rateLimitTrigger{
+		limit: RateLimitAddRate,
+		scope: RateLimitScopePeer,
+		count: 1,
+	}, trigger)
+
+	// The channel token of an HTLC rejected by the peer limit is handed
+	// back, so the channel limit isn't hit by the next HTLC either.
+	trigger = r.admit(circuitKey(rateLimitChan2, 2), rateLimitPeer, 1)
+	require.Equal(t, &rateLimitTrigger{
+		limit: RateLimitAddRate,
+		scope: RateLimitScopePeer,
+		count: 2,
+	}, trigger)
+
+	// HTLCs that were already admitted aren't limited again.
+	require.Nil(t, r.admit(circuitKey(rateLimitChan1, 0), rateLimitPeer, 1))
+
+	// After a second, the limits are replenished.
+	testClock.SetTime(testClock.Now().Add(time.Second))
+	require.Nil(t, r.admit(circuitKey(rateLimitChan2, 1), rateLimitPeer, 1))
+}
+
+// TestRateLimiterInFlight tests that the value of HTLCs in flight is limited,
+// and that resolved HTLCs no longer count towards the limit.
+func TestRateLimiterInFlight(t *testing.T) {
+	t.Parallel()
+
+	r, _ := newTestRateLimiter(
+		RateLimits{MaxInFlight: 1500}, RateLimits{MaxInFlight: 1000},
+	)
+
+	key := circuitKey(rateLimitChan1, 0)
+	require.Nil(t, r.admit(key, rateLimitPeer, 1000))
+
+	trigger := r.admit(circuitKey(rateLimitChan1, 1), rateLimitPeer, 1)
+	require.Equal(t, RateLimitInFlight, trigger.limit)
+	require.Equal(t, RateLimitScopeChannel, trigger.scope)
+
+	trigger = r.admit(circuitKey(rateLimitChan2, 0), rateLimitPeer, 501)
+	require.Equal(t, RateLimitInFlight, trigger.limit)
+	require.Equal(t, RateLimitScopePeer, trigger.scope)
+
+	r.resolve(key, true)
+	require.Nil(t, r.admit(circuitKey(rateLimitChan1, 1), rateLimitPeer, 1))
+	require.Nil(
+		t, r.admit(circuitKey(rateLimitChan2, 0), rateLimitPeer, 501),
+	)
+}
+
+// TestRateLimiterFailRatio tests that peers whose HTLCs fail too often are
+// limited once enough HTLCs were resolved, and that the limit is lifted again
+// as the failures decay.
+func TestRateLimiterFailRatio(t *testing.T) {
+	t.Parallel()
+
+	r, testClock := newTestRateLimiter(
+		RateLimits{MaxFailRatio: 0.5}, RateLimits{},
+	)
+
+	// Three failures aren't enough to enforce the limit yet.
+	for i := uint64(0); i < 3; i++ {
+		key := circuitKey(rateLimitChan1, i)
+		require.Nil(t, r.admit(key, rateLimitPeer, 1))
+		r.resolve(key, false)
+	}
+
+	// With the fourth failure, the limit is enforced.
+	key := circuitKey(rateLimitChan1, 3)
+	require.Nil(t, r.admit(key, rateLimitPeer, 1))
+	r.resolve(key, false)
+
+	for i := uint64(1); i <= 2; i++ {
+		trigger := r.admit(
+			circuitKey(rateLimitChan1, 4), rateLimitPeer, 1,
+		)
+		require.Equal(t, &rateLimitTrigger{
+			limit: RateLimitFailRatio,
+			scope: RateLimitScopePeer,
+			count: i,
+		}, trigger)
+	}
+
+	// Once the failures decayed below the minimum number of HTLCs, the
+	// peer may forward again.
+	testClock.SetTime(testClock.Now().Add(time.Minute))
+	require.Nil(t, r.admit(circuitKey(rateLimitChan1, 4), rateLimitPeer, 1))
+}
@@ -0,0 +1,48 @@
+package reputation
+
+import (
+	"math"
+	"time"
+)
+
+// decayingAverage tracks a sum of values that decays exponentially over
+// time, so that values added a full period ago only count for 1/e of their
+// original value.
+type decayingAverage struct {
+	value      float64
+	lastUpdate time.Time
+	period     time.Duration
+}
+
+// newDecayingAverage creates a decaying average over the given period.
+func newDecayingAverage(period time.Duration) *decayingAverage {
+	return &decayingAverage{
+		period: period,
+	}
+}
+
+// getValue returns the value of the average at the given time.
+func (d *decayingAverage) getValue(now time.Time) float64 {
+	// Nothing has been added yet, or time went backwards, in which case
+	// we don't decay the value.
+	if d.lastUpdate.IsZero() || !now.After(d.lastUpdate) {
+		return d.value
+	}
+
+	elapsed := now.Sub(d.lastUpdate)
+	d.value *= math.Exp(-float64(elapsed) / float64(d.period))
+	d.lastUpdate = now
+
+	return d.value
+}
+
+// add decays the average to the given time and adds the value to it. The
+// value may be negative.
+func (d *decayingAverage) add(value float64, now time.Time) {
+	d.getValue(now)
+	d.value += value
+
+	if now.After(d.lastUpdate) {
+		d.lastUpdate = now
+	}
+}
@@ -0,0 +1,402 @@
+package reputation
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/lightningnetwork/lnd/channeldb/models"
+	"github.com/lightningnetwork/lnd/clock"
+	"github.com/lightningnetwork/lnd/lnwire"
+)
+
+// blockTime is the expected time between two blocks, used to convert the
+// CLTV delta of an HTLC into the maximum time it can be held.
+const blockTime = 10 * time.Minute
+
+// Config holds the parameters of the reputation and resource bucketing
+// policy.
+type Config struct {
+	// RevenueWindow is the period over which the fees earned by an
+	// outgoing channel are tracked.
+	RevenueWindow time.Duration
+
+	// ReputationMultiplier is the number of revenue windows over which
+	// the reputation of an incoming channel is tracked.
+	ReputationMultiplier int
+
+	// ResolutionPeriod is the time within which an HTLC is expected to be
+	// resolved. HTLCs that are held longer are charged the fees they
+	// could have earned for every additional period they were held.
+	ResolutionPeriod time.Duration
+
+	// ProtectedShare is the fraction of the HTLC slots and liquidity of
+	// an outgoing channel that is reserved for endorsed HTLCs coming from
+	// incoming channels with a good reputation. All other HTLCs have to
+	// share the remaining general resources.
+	ProtectedShare float64
+
+	// Enforce indicates whether HTLCs that would exceed the general
+	// resources of the outgoing channel should be failed. If false, the
+	// decisions are only recorded.
+	Enforce bool
+
+	// Clock is the clock used to measure resolution times.
+	Clock clock.Clock
+}
+
+// Outcome is the decision of the resource manager for an HTLC that is about
+// to be forwarded.
+type Outcome uint8
+
+const (
+	// OutcomeEndorsed means that the HTLC was endorsed by a peer with a
+	// good reputation, so it is forwarded as endorsed and may use the
+	// protected resources of the outgoing channel.
+	OutcomeEndorsed Outcome = iota
+
+	// OutcomeUnendorsed means that the HTLC is forwarded unendorsed using
+	// the general resources of the outgoing channel.
+	OutcomeUnendorsed
+
+	// OutcomeNoResources means that the general resources of the outgoing
+	// channel are exhausted, so the HTLC should be failed.
+	OutcomeNoResources
+)
+
+// String returns a human-readable version of the outcome.
+func (o Outcome) String() string {
+	switch o {
+	case OutcomeEndorsed:
+		return "endorsed"
+
+	case OutcomeUnendorsed:
+		return "unendorsed"
+
+	case OutcomeNoResources:
+		return "no_resources"
+
+	default:
+		return "unknown"
+	}
+}
+
+// ProposedHTLC describes an HTLC that is about to be forwarded.
+type ProposedHTLC struct {
+	// IncomingCircuit identifies the incoming HTLC.
+	IncomingCircuit models.CircuitKey
+
+	// OutgoingChannel is the channel the HTLC leaves through.
+	OutgoingChannel lnwire.ShortChannelID
+
+	// Endorsement is the endorsement signal of the incoming HTLC.
+	Endorsement lnwire.Endorsement
+
+	// IncomingAmount is the amount of the incoming HTLC.
+	IncomingAmount lnwire.MilliSatoshi
+
+	// OutgoingAmount is the amount of the outgoing HTLC.
+	OutgoingAmount lnwire.MilliSatoshi
+
+	// CltvExpiryDelta is the number of blocks until the incoming HTLC
+	// expires.
+	CltvExpiryDelta uint32
+}
+
+// fee returns the fee earned by forwarding the HTLC.
+func (p *ProposedHTLC) fee() lnwire.MilliSatoshi {
+	if p.IncomingAmount < p.OutgoingAmount {
+		return 0
+	}
+
+	return p.IncomingAmount - p.OutgoingAmount
+}
+
+// ChannelLimits are the resources of an outgoing channel that are shared by
+// all HTLCs leaving through it.
+type ChannelLimits struct {
+	// Slots is the maximum number of HTLCs the channel can carry.
+	Slots uint16
+
+	// Liquidity is the maximum value of the HTLCs the channel can carry.
+	Liquidity lnwire.MilliSatoshi
+}
+
+// Decision is the result of evaluating an HTLC that is about to be
+// forwarded.
+type Decision struct {
+	// Outcome is the outcome of the evaluation.
+	Outcome Outcome
+
+	// Reputable indicates whether the incoming channel has a good enough
+	// reputation to use the protected resources of the outgoing channel.
+	Reputable bool
+
+	// Fail indicates whether the HTLC must be failed. This is only ever
+	// the case if the policy is enforced.
+	Fail bool
+}
+
+// ForwardEndorsed returns whether the HTLC should be forwarded with the
+// endorsement signal set.
+func (d Decision) ForwardEndorsed() bool {
+	return d.Outcome == OutcomeEndorsed
+}
+
+// channelState is the state the manager tracks for a single channel, both in
+// its role as incoming and as outgoing channel.
+type channelState struct {
+	// reputation is the decaying sum of the effective fees earned by
+	// HTLCs that arrived through the channel.
+	reputation *decayingAverage
+
+	// revenue is the decaying sum of the fees earned by HTLCs that left
+	// through the channel.
+	revenue *decayingAverage
+
+	// inFlightRisk is the total risk of the endorsed HTLCs that arrived
+	// through the channel and are still in flight.
+	inFlightRisk float64
+
+	// generalSlots is the number of HTLCs leaving through the channel
+	// that use its general resources.
+	generalSlots int
+
+	// generalLiquidity is the value of the HTLCs leaving through the
+	// channel that use its general resources.
+	generalLiquidity lnwire.MilliSatoshi
+}
+
+// inFlightHTLC is an HTLC that was forwarded and not resolved yet.
+type inFlightHTLC struct {
+	outgoing    lnwire.ShortChannelID
+	endorsement lnwire.Endorsement
+	outcome     Outcome
+	amount      lnwire.MilliSatoshi
+	fee         lnwire.MilliSatoshi
+	risk        float64
+	addedAt     time.Time
+}
+
+// Manager tracks the local reputation of incoming channels based on the
+// resolution times and fees of the HTLCs they forward to us, and decides
+// which resources of an outgoing channel an HTLC may use. HTLCs that are
+// endorsed by a channel with a good reputation may use all resources of the
+// outgoing channel, while all other HTLCs are limited to its general share.
+//
+// NOTE: The state of the manager is kept in memory only. HTLCs that are in
+// flight across a restart are not accounted for.
+type Manager struct {
+	cfg *Config
+
+	mu       sync.Mutex
+	channels map[lnwire.ShortChannelID]*channelState
+	inFlight map[models.CircuitKey]*inFlightHTLC
+}
+
+// NewManager creates a new resource manager.
+func NewManager(cfg *Config) *Manager {
+	return &Manager{
+		cfg:      cfg,
+		channels: make(map[lnwire.ShortChannelID]*channelState),
+		inFlight: make(map[models.CircuitKey]*inFlightHTLC),
+	}
+}
+
+// channel returns the state of the given channel, creating it if it doesn't
+// exist yet.
+//
+// NOTE: Must be called with the mutex held.
+func (m *Manager) channel(scid lnwire.ShortChannelID) *channelState {
+	state, ok := m.channels[scid]
+	if ok {
+		return state
+	}
+
+	reputationWindow := m.cfg.RevenueWindow *
+		time.Duration(m.cfg.ReputationMultiplier)
+
+	state = &channelState{
+		reputation: newDecayingAverage(reputationWindow),
+		revenue:    newDecayingAverage(m.cfg.RevenueWindow),
+	}
+	m.channels[scid] = state
+
+	return state
+}
+
+// htlcRisk returns the worst case opportunity cost of an HTLC, which is the
+// fee it pays for every resolution period it could be held until it expires.
+func (m *Manager) htlcRisk(fee lnwire.MilliSatoshi, cltvDelta uint32) float64 {
+	maxHold := time.Duration(cltvDelta) * blockTime
+	periods := math.Ceil(
+		float64(maxHold) / float64(m.cfg.ResolutionPeriod),
+	)
+
+	return float64(fee) * periods
+}
+
+// effectiveFees returns the contribution of a resolved HTLC to the
+// reputation of its incoming channel. Settled HTLCs earn their fee, and every
+// resolution period the HTLC was held beyond the first one costs the fee it
+// could have earned in the meantime.
+func (m *Manager) effectiveFees(htlc *inFlightHTLC, holdTime time.Duration,
+	settled bool) float64 {
+
+	var fees float64
+	if settled {
+		fees = float64(htlc.fee)
+	}
+
+	if holdTime > m.cfg.ResolutionPeriod {
+		periods := math.Ceil(
+			float64(holdTime-m.cfg.ResolutionPeriod) /
+				float64(m.cfg.ResolutionPeriod),
+		)
+		fees -= periods * float64(htlc.fee)
+	}
+
+	// Only endorsed HTLCs can hurt the reputation of the incoming channel,
+	// as the peer didn't vouch for unendorsed ones.
+	if htlc.endorsement != lnwire.ExperimentalEndorsed && fees < 0 {
+		return 0
+	}
+
+	return fees
+}
+
+// AddHTLC evaluates an HTLC that is about to be forwarded and, unless it must
+// be failed, tracks it until it is resolved through ResolveHTLC.
+func (m *Manager) AddHTLC(htlc *ProposedHTLC, limits ChannelLimits) Decision {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// If the HTLC is already in flight, it is being forwarded again after
+	// the outgoing link went down, so we keep the original decision.
+	if existing, ok := m.inFlight[htlc.IncomingCircuit]; ok {
+		return Decision{
+			Outcome:   existing.outcome,
+			Reputable: existing.outcome == OutcomeEndorsed,
+		}
+	}
+
+	now := m.cfg.Clock.Now()
+	fee := htlc.fee()
+	risk := m.htlcRisk(fee, htlc.CltvExpiryDelta)
+
+	incoming := m.channel(htlc.IncomingCircuit.ChanID)
+	outgoing := m.channel(htlc.OutgoingChannel)
+
+	// The incoming channel has a good reputation if the fees it earned us
+	// exceed the revenue of the outgoing channel, even after accounting
+	// for the risk of all its endorsed HTLCs in flight including this
+	// one.
+	reputation := incoming.reputation.getValue(now) -
+		incoming.inFlightRisk - risk
+	revenue := outgoing.revenue.getValue(now)
+	reputable := reputation > revenue
+
+	generalShare := 1 - m.cfg.ProtectedShare
+	generalSlots := int(float64(limits.Slots) * generalShare)
+	generalLiquidity := lnwire.MilliSatoshi(
+		float64(limits.Liquidity) * generalShare,
+	)
+
+	var outcome Outcome
+	switch {
+	case htlc.Endorsement == lnwire.ExperimentalEndorsed && reputable:
+		outcome = OutcomeEndorsed
+
+	case outgoing.generalSlots < generalSlots &&
+		outgoing.generalLiquidity+htlc.OutgoingAmount <=
+			generalLiquidity:
+
+		outcome = OutcomeUnendorsed
+
+	default:
+		outcome = OutcomeNoResources
+	}
+
+	decision := Decision{
+		Outcome:   outcome,
+		Reputable: reputable,
+		Fail:      outcome == OutcomeNoResources && m.cfg.Enforce,
+	}
+
+	log.Debugf("Resource decision for HTLC %v (%v) -> %v: %v, "+
+		"reputation=%.0f, revenue=%.0f, general_slots=%d/%d, "+
+		"general_liquidity=%v/%v, enforced=%v", htlc.IncomingCircuit,
+		htlc.Endorsement, htlc.OutgoingChannel, outcome, reputation,
+		revenue, outgoing.generalSlots, generalSlots,
+		outgoing.generalLiquidity, generalLiquidity, m.cfg.Enforce)
+
+	if decision.Fail {
+		return decision
+	}
+
+	// HTLCs without resources are only forwarded in record mode. We don't
+	// account for them in the general resources, so that the recorded
+	// decisions reflect what the enforced policy would have done.
+	switch outcome {
+	case OutcomeEndorsed:
+		incoming.inFlightRisk += risk
+
+	case OutcomeUnendorsed:
+		outgoing.generalSlots++
+		outgoing.generalLiquidity += htlc.OutgoingAmount
+	}
+
+	m.inFlight[htlc.IncomingCircuit] = &inFlightHTLC{
+		outgoing:    htlc.OutgoingChannel,
+		endorsement: htlc.Endorsement,
+		outcome:     outcome,
+		amount:      htlc.OutgoingAmount,
+		fee:         fee,
+		risk:        risk,
+		addedAt:     now,
+	}
+
+	return decision
+}
+
+// ResolveHTLC releases the resources of a forwarded HTLC and updates the
+// reputation of its incoming channel and the revenue of its outgoing channel.
+// Unknown HTLCs are ignored.
+func (m *Manager) ResolveHTLC(incomingCircuit models.CircuitKey,
+	settled bool) {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	htlc, ok := m.inFlight[incomingCircuit]
+	if !ok {
+		return
+	}
+	delete(m.inFlight, incomingCircuit)
+
+	now := m.cfg.Clock.Now()
+	incoming := m.channel(incomingCircuit.ChanID)
+	outgoing := m.channel(htlc.outgoing)
+
+	switch htlc.outcome {
+	case OutcomeEndorsed:
+		incoming.inFlightRisk = math.Max(
+			incoming.inFlightRisk-htlc.risk, 0,
+		)
+
+	case OutcomeUnendorsed:
+		outgoing.generalSlots--
+		outgoing.generalLiquidity -= htlc.amount
+	}
+
+	holdTime := now.Sub(htlc.addedAt)
+	effectiveFees := m.effectiveFees(htlc, holdTime, settled)
+	incoming.reputation.add(effectiveFees, now)
+
+	if settled {
+		outgoing.revenue.add(float64(htlc.fee), now)
+	}
+
+	log.Debugf("Resolved HTLC %v (settled=%v) after %v, effective "+
+		"fees=%.0f", incomingCircuit, settled, holdTime, effectiveFees)
+}
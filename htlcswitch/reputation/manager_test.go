@@ -0,0 +1,171 @@
+package reputation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btclog"
+	"github.com/lightningnetwork/lnd/channeldb/models"
+	"github.com/lightningnetwork/lnd/clock"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testTime = time.Unix(1700000000, 0)
+
+	chanIn  = lnwire.NewShortChanIDFromInt(1)
+	chanOut = lnwire.NewShortChanIDFromInt(2)
+
+	testLimits = ChannelLimits{
+		Slots:     4,
+		Liquidity: 100_000,
+	}
+)
+
+func init() {
+	UseLogger(btclog.Disabled)
+}
+
+func newTestManager(enforce bool) (*Manager, *clock.TestClock) {
+	testClock := clock.NewTestClock(testTime)
+
+	return NewManager(&Config{
+		RevenueWindow:        time.Hour * 24 * 14,
+		ReputationMultiplier: 12,
+		ResolutionPeriod:     90 * time.Second,
+		ProtectedShare:       0.5,
+		Enforce:              enforce,
+		Clock:                testClock,
+	}), testClock
+}
+
+func testHTLC(index uint64, endorsement lnwire.Endorsement) *ProposedHTLC {
+	return &ProposedHTLC{
+		IncomingCircuit: models.CircuitKey{
+			ChanID: chanIn,
+			HtlcID: index,
+		},
+		OutgoingChannel: chanOut,
+		Endorsement:     endorsement,
+		IncomingAmount:  10_100,
+		OutgoingAmount:  10_000,
+		CltvExpiryDelta: 40,
+	}
+}
+
+// TestGeneralResources tests that HTLCs without a good reputation are
+// limited to the general resources of the outgoing channel, and that the
+// limit is only enforced if configured.
+func TestGeneralResources(t *testing.T) {
+	t.Parallel()
+
+	for _, enforce := range []bool{false, true} {
+		m, _ := newTestManager(enforce)
+
+		// Half of the four slots are general resources. An endorsed
+		// HTLC from a channel without any reputation is treated like
+		// an unendorsed one.
+		for i := uint64(0); i < 2; i++ {
+			decision := m.AddHTLC(
+				testHTLC(i, lnwire.ExperimentalEndorsed),
+				testLimits,
+			)
+			require.Equal(t, OutcomeUnendorsed, decision.Outcome)
+			require.False(t, decision.Reputable)
+			require.False(t, decision.Fail)
+			require.False(t, decision.ForwardEndorsed())
+		}
+
+		decision := m.AddHTLC(
+			testHTLC(2, lnwire.ExperimentalUnendorsed), testLimits,
+		)
+		require.Equal(t, OutcomeNoResources, decision.Outcome)
+		require.Equal(t, enforce, decision.Fail)
+
+		// Resolving an HTLC frees up its slot again.
+		m.ResolveHTLC(testHTLC(0, 0).IncomingCircuit, false)
+
+		decision = m.AddHTLC(
+			testHTLC(3, lnwire.ExperimentalUnendorsed), testLimits,
+		)
+		require.Equal(t, OutcomeUnendorsed, decision.Outcome)
+	}
+}
+
+// TestReputation tests that an incoming channel gains reputation through
+// fast settles, loses it through slow resolutions, and that endorsed HTLCs
+// of a reputable channel may use the protected resources.
+func TestReputation(t *testing.T) {
+	t.Parallel()
+
+	m, testClock := newTestManager(true)
+
+	// Settle HTLCs quickly to give the outgoing channel some revenue.
+	for i := uint64(0); i < 100; i++ {
+		htlc := testHTLC(i, lnwire.ExperimentalUnendorsed)
+		decision := m.AddHTLC(htlc, testLimits)
+		require.Equal(t, OutcomeUnendorsed, decision.Outcome)
+
+		testClock.SetTime(testClock.Now().Add(time.Second))
+		m.ResolveHTLC(htlc.IncomingCircuit, true)
+	}
+
+	// The incoming channel has earned the same fees as the outgoing
+	// channel so far, which isn't enough to cover the risk of an HTLC
+	// with a CLTV delta of 40 blocks, which is 267 times its fee. Build
+	// up more reputation through another outgoing channel.
+	for i := uint64(100); i < 2100; i++ {
+		htlc := testHTLC(i, lnwire.ExperimentalUnendorsed)
+		htlc.OutgoingChannel = lnwire.NewShortChanIDFromInt(3)
+		m.AddHTLC(htlc, testLimits)
+		m.ResolveHTLC(htlc.IncomingCircuit, true)
+	}
+
+	// Endorsed HTLCs can now use the protected resources beyond the two
+	// general slots.
+	for i := uint64(3000); i < 3004; i++ {
+		decision := m.AddHTLC(
+			testHTLC(i, lnwire.ExperimentalEndorsed), testLimits,
+		)
+		require.Equal(t, OutcomeEndorsed, decision.Outcome, i)
+		require.True(t, decision.ForwardEndorsed())
+	}
+
+	// Unendorsed HTLCs still only get the general resources, which are
+	// untouched by the endorsed HTLCs.
+	decision := m.AddHTLC(
+		testHTLC(3004, lnwire.ExperimentalUnendorsed), testLimits,
+	)
+	require.Equal(t, OutcomeUnendorsed, decision.Outcome)
+	require.True(t, decision.Reputable)
+
+	// Holding the endorsed HTLCs for a day costs the incoming channel its
+	// reputation.
+	testClock.SetTime(testClock.Now().Add(24 * time.Hour))
+	for i := uint64(3000); i < 3004; i++ {
+		m.ResolveHTLC(testHTLC(i, 0).IncomingCircuit, false)
+	}
+
+	decision = m.AddHTLC(
+		testHTLC(3005, lnwire.ExperimentalEndorsed), testLimits,
+	)
+	require.False(t, decision.Reputable)
+	require.Equal(t, OutcomeUnendorsed, decision.Outcome)
+}
+
+// TestDecayingAverage tests that values decay over the configured period.
+func TestDecayingAverage(t *testing.T) {
+	t.Parallel()
+
+	avg := newDecayingAverage(time.Hour)
+	require.Zero(t, avg.getValue(testTime))
+
+	avg.add(100, testTime)
+	require.Equal(t, 100.0, avg.getValue(testTime))
+
+	require.InDelta(t, 36.79, avg.getValue(testTime.Add(time.Hour)), 0.01)
+
+	avg.add(-10, testTime.Add(time.Hour))
+	require.InDelta(t, 26.79, avg.getValue(testTime.Add(time.Hour)), 0.01)
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/lightningnetwork/lnd/clock"
 	"github.com/lightningnetwork/lnd/contractcourt"
 	"github.com/lightningnetwork/lnd/htlcswitch/hop"
+	"github.com/lightningnetwork/lnd/htlcswitch/reputation"
 	"github.com/lightningnetwork/lnd/kvdb"
 	"github.com/lightningnetwork/lnd/lntypes"
 	"github.com/lightningnetwork/lnd/lnwallet"
@@ -75,13 +76,19 @@ var (
 	ErrLocalAddFailed = errors.New("local add HTLC failed")
 
 	// errDustThresholdExceeded is only surfaced to callers of SendHTLC and
-	// signals that sending the HTLC would exceed the outgoing link's dust
-	// threshold.
+	// signals that sending the HTLC would exceed the outgoing link's max
+	// fee exposure.
 	errDustThresholdExceeded = errors.New("dust threshold exceeded")
 
+	// DefaultMaxFeeExposure is the default fee exposure after which we'll
+	// fail dust payments. This is currently set to 500m msats.
+	DefaultMaxFeeExposure = lnwire.MilliSatoshi(500_000_000)
+
 	// DefaultDustThreshold is the default threshold after which we'll fail
-	// payments if they are dust. This is currently set to 500m msats.
-	DefaultDustThreshold = lnwire.MilliSatoshi(500_000_000)
+	// payments if they are dust.
+	//
+	// Deprecated: Use DefaultMaxFeeExposure instead.
+	DefaultDustThreshold = DefaultMaxFeeExposure
 )
 
 // plexPacket encapsulates switch packet and adds error channel to receive
@@ -209,9 +216,9 @@ type Config struct {
 	// a mailbox via AddPacket.
 	MailboxDeliveryTimeout time.Duration
 
-	// DustThreshold is the threshold in milli-satoshis after which we'll
-	// fail incoming or outgoing dust payments for a particular channel.
-	DustThreshold lnwire.MilliSatoshi
+	// MaxFeeExposure is the limit on the fee exposure of a channel after
+	// which we'll fail incoming or outgoing dust payments for it.
+	MaxFeeExposure lnwallet.FeeExposureLimit
 
 	// SignAliasUpdate is used when sending FailureMessages backwards for
 	// option_scid_alias channels. This avoids a potential privacy leak by
@@ -222,6 +229,16 @@ type Config struct {
 
 	// IsAlias returns whether or not a given SCID is an alias.
 	IsAlias func(scid lnwire.ShortChannelID) bool
+
+	// ResourceManager decides which resources of the outgoing channel a
+	// forwarded HTLC may use based on its endorsement signal and the
+	// reputation of the incoming channel. If nil, HTLCs are forwarded
+	// without endorsement.
+	ResourceManager *reputation.Manager
+
+	// RateLimits holds the per-peer and per-channel limits on incoming
+	// HTLCs. If nil, no limits are applied.
+	RateLimits *RateLimiterConfig
 }
 
 // Switch is the central messaging bus for all incoming/outgoing HTLCs.
@@ -264,6 +281,10 @@ type Switch struct {
 	// later come online.
 	mailOrchestrator *mailOrchestrator
 
+	// rateLimiter enforces the per-peer and per-channel limits on incoming
+	// HTLCs. It is nil if no limits are configured.
+	rateLimiter *rateLimiter
+
 	// indexMtx is a read/write mutex that protects the set of indexes
 	// below.
 	indexMtx sync.RWMutex
@@ -381,6 +402,10 @@ func New(cfg Config, currentHeight uint32) (*Switch, error) {
 		quit:              make(chan struct{}),
 	}
 
+	if cfg.RateLimits != nil {
+		s.rateLimiter = newRateLimiter(cfg.RateLimits)
+	}
+
 	s.aliasToReal = make(map[lnwire.ShortChannelID]lnwire.ShortChannelID)
 	s.baseIndex = make(map[lnwire.ShortChannelID]lnwire.ShortChannelID)
 
@@ -1076,12 +1101,28 @@ func (s *Switch) parseFailedPayment(deobfuscator ErrorDecrypter,
 	default:
 		// We'll attempt to fully decrypt the onion encrypted
 		// error. If we're unable to then we'll bail early.
-		failure, err := deobfuscator.DecryptError(htlc.Reason)
+		attrData, err := htlc.AttributionData()
+		if err != nil {
+			log.Debugf("Unable to parse attribution data of "+
+				"failure (hash=%v, pid=%d): %v", paymentHash,
+				attemptID, err)
+		}
+
+		failure, err := deobfuscator.DecryptError(
+			htlc.Reason, attrData,
+		)
 		if err != nil {
 			log.Errorf("unable to de-obfuscate onion failure "+
 				"(hash=%v, pid=%d): %v",
 				paymentHash, attemptID, err)
 
+			// If some hops attributed the failure, return the
+			// hold times they reported along with the error.
+			var unreadableErr *UnreadableFailureError
+			if errors.As(err, &unreadableErr) {
+				return unreadableErr
+			}
+
 			return ErrUnreadableFailureMessage
 		}
 
@@ -1127,6 +1168,12 @@ func (s *Switch) handlePacketForward(packet *htlcPacket) error {
 			return s.failAddPacket(packet, linkErr)
 		}
 
+		// Make sure that the peer and channel the HTLC arrived from
+		// stay within their rate limits.
+		if linkErr := s.checkRateLimits(packet); linkErr != nil {
+			return s.failAddPacket(packet, linkErr)
+		}
+
 		s.indexMtx.RLock()
 		targetLink, err := s.getLinkByMapping(packet)
 		if err != nil {
@@ -1175,7 +1222,8 @@ func (s *Switch) handlePacketForward(packet *htlcPacket) error {
 				failure = link.CheckHtlcForward(
 					htlc.PaymentHash, packet.incomingAmount,
 					packet.amount, packet.incomingTimeout,
-					packet.outgoingTimeout, currentHeight,
+					packet.outgoingTimeout,
+					packet.inboundFee, currentHeight,
 					packet.originalOutgoingChanID,
 				)
 			}
@@ -1271,10 +1319,29 @@ func (s *Switch) handlePacketForward(packet *htlcPacket) error {
 			return s.failAddPacket(packet, linkErr)
 		}
 
+		// Let the resource manager decide whether the HTLC may use
+		// the resources of the destination link, and whether it is
+		// forwarded as endorsed.
+		if linkErr := s.evaluateResources(
+			packet, htlc, destination,
+		); linkErr != nil {
+			return s.failAddPacket(packet, linkErr)
+		}
+
 		// Send the packet to the destination channel link which
 		// manages the channel.
 		packet.outgoingChanID = destination.ShortChanID()
-		return destination.handleSwitchPacket(packet)
+		err = destination.handleSwitchPacket(packet)
+		if err != nil && s.cfg.ResourceManager != nil {
+			s.cfg.ResourceManager.ResolveHTLC(
+				packet.inKey(), false,
+			)
+		}
+		if err != nil && s.rateLimiter != nil {
+			s.rateLimiter.resolve(packet.inKey(), false)
+		}
+
+		return err
 
 	case *lnwire.UpdateFailHTLC, *lnwire.UpdateFulfillHTLC:
 		// If the source of this packet has not been set, use the
@@ -1291,6 +1358,25 @@ func (s *Switch) handlePacketForward(packet *htlcPacket) error {
 		}
 
 		fail, isFail := htlc.(*lnwire.UpdateFailHTLC)
+
+		// Release the resources of forwarded HTLCs and update the
+		// reputation of their incoming channel.
+		if s.cfg.ResourceManager != nil &&
+			circuit.Incoming.ChanID != hop.Source {
+
+			s.cfg.ResourceManager.ResolveHTLC(
+				circuit.Incoming, !isFail,
+			)
+		}
+
+		// Also record the outcome for the rate limits of the incoming
+		// peer and channel.
+		if s.rateLimiter != nil &&
+			circuit.Incoming.ChanID != hop.Source {
+
+			s.rateLimiter.resolve(circuit.Incoming, !isFail)
+		}
+
 		if isFail && !packet.hasSource {
 			switch {
 			// No message to encrypt, locally sourced payment.
@@ -1379,6 +1465,94 @@ func (s *Switch) handlePacketForward(packet *htlcPacket) error {
 	}
 }
 
+// checkRateLimits checks whether an incoming HTLC stays within the rate limits
+// of the peer and channel it arrived from. If a limit is exceeded, a rate
+// limit event is sent and a link error is returned.
+func (s *Switch) checkRateLimits(packet *htlcPacket) *LinkError {
+	if s.rateLimiter == nil {
+		return nil
+	}
+
+	s.indexMtx.RLock()
+	incomingLink, err := s.getLinkByShortID(packet.incomingChanID)
+	s.indexMtx.RUnlock()
+	if err != nil {
+		// The HTLC is failed later on if its incoming link can't be
+		// found, so we don't need to handle this case here.
+		return nil
+	}
+
+	peer := incomingLink.Peer().PubKey()
+	trigger := s.rateLimiter.admit(
+		packet.inKey(), peer, packet.incomingAmount,
+	)
+	if trigger == nil {
+		return nil
+	}
+
+	log.Debugf("HTLC %v from peer %x exceeds %v %v limit (triggered "+
+		"%d times)", packet.inKey(), peer, trigger.scope,
+		trigger.limit, trigger.count)
+
+	s.cfg.HtlcNotifier.NotifyRateLimitEvent(
+		newHtlcKey(packet), peer, trigger.limit, trigger.scope,
+		trigger.count,
+	)
+
+	return NewDetailedLinkError(
+		&lnwire.FailTemporaryChannelFailure{},
+		trigger.limit.failureDetail(),
+	)
+}
+
+// evaluateResources asks the resource manager whether the HTLC may use the
+// resources of the destination link, and sets the endorsement signal of the
+// outgoing HTLC according to its decision. A link error is returned if the
+// HTLC must be failed.
+func (s *Switch) evaluateResources(packet *htlcPacket,
+	htlc *lnwire.UpdateAddHTLC, destination ChannelLink) *LinkError {
+
+	if s.cfg.ResourceManager == nil {
+		return nil
+	}
+
+	var cltvDelta uint32
+	currentHeight := atomic.LoadUint32(&s.bestHeight)
+	if packet.incomingTimeout > currentHeight {
+		cltvDelta = packet.incomingTimeout - currentHeight
+	}
+
+	decision := s.cfg.ResourceManager.AddHTLC(&reputation.ProposedHTLC{
+		IncomingCircuit: packet.inKey(),
+		OutgoingChannel: destination.ShortChanID(),
+		Endorsement:     packet.incomingEndorsement,
+		IncomingAmount:  packet.incomingAmount,
+		OutgoingAmount:  packet.amount,
+		CltvExpiryDelta: cltvDelta,
+	}, destination.resourceLimits())
+
+	if decision.Fail {
+		return NewDetailedLinkError(
+			&lnwire.FailTemporaryChannelFailure{},
+			OutgoingFailureNoResources,
+		)
+	}
+
+	endorsement := lnwire.ExperimentalUnendorsed
+	if decision.ForwardEndorsed() {
+		endorsement = lnwire.ExperimentalEndorsed
+	}
+
+	// Setting the signal can't fail for a single byte record, but if it
+	// does we just forward the HTLC unendorsed.
+	if err := htlc.SetEndorsement(endorsement); err != nil {
+		log.Warnf("Unable to set endorsement of HTLC %v: %v",
+			packet.inKey(), err)
+	}
+
+	return nil
+}
+
 // checkCircularForward checks whether a forward is circular (arrives and
 // departs on the same link) and returns a link error if the switch is
 // configured to disallow this behaviour.
@@ -1453,6 +1627,12 @@ func (s *Switch) checkCircularForward(incoming, outgoing lnwire.ShortChannelID,
 // The ciphertext will be derived from the failure message proivded by context.
 // This method returns the failErr if all other steps complete successfully.
 func (s *Switch) failAddPacket(packet *htlcPacket, failure *LinkError) error {
+	// The HTLC won't be forwarded, so it no longer counts towards the
+	// in-flight limits of its peer and channel.
+	if s.rateLimiter != nil {
+		s.rateLimiter.resolve(packet.inKey(), false)
+	}
+
 	// Encrypt the failure so that the sender will be able to read the error
 	// message. Since we failed this packet, we use EncryptFirstHop to
 	// obfuscate the failure for their eyes only.
@@ -1480,6 +1660,7 @@ func (s *Switch) failAddPacket(packet *htlcPacket, failure *LinkError) error {
 		incomingTimeout: packet.incomingTimeout,
 		outgoingTimeout: packet.outgoingTimeout,
 		circuit:         packet.circuit,
+		obfuscator:      packet.obfuscator,
 		linkFailure:     failure,
 		htlc: &lnwire.UpdateFailHTLC{
 			Reason: reason,
@@ -2753,12 +2934,12 @@ func (s *Switch) BestHeight() uint32 {
 }
 
 // evaluateDustThreshold takes in a ChannelLink, HTLC amount, and a boolean to
-// determine whether the default dust threshold has been exceeded. This
-// heuristic takes into account the trimmed-to-dust mechanism. The sum of the
-// commitment's dust with the mailbox's dust with the amount is checked against
-// the default threshold. If incoming is true, then the amount is not included
-// in the sum as it was already included in the commitment's dust. A boolean is
-// returned telling the caller whether the HTLC should be failed back.
+// determine whether the max fee exposure has been exceeded. This heuristic
+// takes into account the trimmed-to-dust mechanism. The fee exposure of the
+// commitment plus the mailbox's dust plus the amount is checked against the
+// limit. If incoming is true, then the amount is not included in the sum as it
+// was already included in the commitment's dust. A boolean is returned telling
+// the caller whether the HTLC should be failed back.
 func (s *Switch) evaluateDustThreshold(link ChannelLink,
 	amount lnwire.MilliSatoshi, incoming bool) bool {
 
@@ -2782,20 +2963,20 @@ func (s *Switch) evaluateDustThreshold(link ChannelLink,
 	mailbox := s.mailOrchestrator.GetOrCreateMailBox(cid, sid)
 	localMailDust, remoteMailDust := mailbox.DustPackets()
 
-	// If the htlc is dust on the local commitment, we'll obtain the dust
-	// sum for it.
+	// If the htlc is dust on the local commitment, we'll obtain the fee
+	// exposure of it.
 	if isLocalDust {
-		localSum := link.getDustSum(false)
-		localSum += localMailDust
+		exposure := link.getFeeExposure(false)
+		exposure.DustSum += localMailDust
 
 		// Optionally include the HTLC amount only for outgoing
 		// HTLCs.
 		if !incoming {
-			localSum += amount
+			exposure.DustSum += amount
 		}
 
-		// Finally check against the defined dust threshold.
-		if localSum > s.cfg.DustThreshold {
+		// Finally check against the max fee exposure.
+		if s.cfg.MaxFeeExposure.Exceeded(exposure) {
 			return true
 		}
 	}
@@ -2803,17 +2984,17 @@ func (s *Switch) evaluateDustThreshold(link ChannelLink,
 	// Also check if the htlc is dust on the remote commitment, if we've
 	// reached this point.
 	if isRemoteDust {
-		remoteSum := link.getDustSum(true)
-		remoteSum += remoteMailDust
+		exposure := link.getFeeExposure(true)
+		exposure.DustSum += remoteMailDust
 
 		// Optionally include the HTLC amount only for outgoing
 		// HTLCs.
 		if !incoming {
-			remoteSum += amount
+			exposure.DustSum += amount
 		}
 
-		// Finally check against the defined dust threshold.
-		if remoteSum > s.cfg.DustThreshold {
+		// Finally check against the max fee exposure.
+		if s.cfg.MaxFeeExposure.Exceeded(exposure) {
 			return true
 		}
 	}
@@ -17,9 +17,11 @@ import (
 	"github.com/lightningnetwork/lnd/chainntnfs"
 	"github.com/lightningnetwork/lnd/channeldb"
 	"github.com/lightningnetwork/lnd/channeldb/models"
+	"github.com/lightningnetwork/lnd/clock"
 	"github.com/lightningnetwork/lnd/contractcourt"
 	"github.com/lightningnetwork/lnd/htlcswitch/hodl"
 	"github.com/lightningnetwork/lnd/htlcswitch/hop"
+	"github.com/lightningnetwork/lnd/htlcswitch/reputation"
 	"github.com/lightningnetwork/lnd/lntest/mock"
 	"github.com/lightningnetwork/lnd/lntypes"
 	"github.com/lightningnetwork/lnd/lnwire"
@@ -981,6 +983,255 @@ func TestSwitchForward(t *testing.T) {
 	}
 }
 
+// TestSwitchForwardResourceManager tests that the switch consults the
+// resource manager before forwarding an HTLC, fails HTLCs exceeding the
+// general resources of the outgoing channel if the policy is enforced, and
+// releases the resources again once the HTLC is resolved.
+func TestSwitchForwardResourceManager(t *testing.T) {
+	t.Parallel()
+
+	alicePeer, err := newMockServer(
+		t, "alice", testStartingHeight, nil, testDefaultDelta,
+	)
+	require.NoError(t, err)
+	bobPeer, err := newMockServer(
+		t, "bob", testStartingHeight, nil, testDefaultDelta,
+	)
+	require.NoError(t, err)
+
+	s, err := initSwitchWithTempDB(t, testStartingHeight)
+	require.NoError(t, err)
+
+	// Only one of the two slots of bob's channel is available to HTLCs
+	// from peers without a good reputation.
+	s.cfg.ResourceManager = reputation.NewManager(&reputation.Config{
+		RevenueWindow:        time.Hour,
+		ReputationMultiplier: 12,
+		ResolutionPeriod:     time.Minute,
+		ProtectedShare:       0.5,
+		Enforce:              true,
+		Clock:                clock.NewDefaultClock(),
+	})
+
+	require.NoError(t, s.Start())
+	defer s.Stop()
+
+	chanID1, chanID2, aliceChanID, bobChanID := genIDs()
+
+	aliceChannelLink := newMockChannelLink(
+		s, chanID1, aliceChanID, emptyScid, alicePeer, true, false,
+		false, false,
+	)
+	bobChannelLink := newMockChannelLink(
+		s, chanID2, bobChanID, emptyScid, bobPeer, true, false, false,
+		false,
+	)
+	bobChannelLink.limits.Slots = 2
+	require.NoError(t, s.AddLink(aliceChannelLink))
+	require.NoError(t, s.AddLink(bobChannelLink))
+
+	preimage, err := genPreimage()
+	require.NoError(t, err)
+	rhash := sha256.Sum256(preimage[:])
+
+	newAddPacket := func(htlcID uint64) *htlcPacket {
+		return &htlcPacket{
+			incomingChanID:      aliceChannelLink.ShortChanID(),
+			incomingHTLCID:      htlcID,
+			outgoingChanID:      bobChannelLink.ShortChanID(),
+			incomingEndorsement: lnwire.ExperimentalEndorsed,
+			obfuscator:          NewMockObfuscator(),
+			htlc: &lnwire.UpdateAddHTLC{
+				PaymentHash: rhash,
+				Amount:      1,
+			},
+		}
+	}
+
+	// The first HTLC is forwarded, but without endorsement as alice
+	// hasn't built up any reputation yet.
+	packet := newAddPacket(0)
+	require.NoError(t, s.ForwardPackets(nil, packet))
+
+	select {
+	case pkt := <-bobChannelLink.packets:
+		htlc, ok := pkt.htlc.(*lnwire.UpdateAddHTLC)
+		require.True(t, ok)
+
+		endorsement, err := htlc.Endorsement()
+		require.NoError(t, err)
+		require.Equal(t, lnwire.ExperimentalUnendorsed, endorsement)
+
+		require.NoError(t, bobChannelLink.completeCircuit(pkt))
+
+	case <-time.After(time.Second):
+		t.Fatal("request was not propagated to destination")
+	}
+
+	// The second HTLC exceeds the general resources of bob's channel, so
+	// it is failed back to alice.
+	require.NoError(t, s.ForwardPackets(nil, newAddPacket(1)))
+
+	select {
+	case pkt := <-aliceChannelLink.packets:
+		_, ok := pkt.htlc.(*lnwire.UpdateFailHTLC)
+		require.True(t, ok)
+		require.Equal(
+			t, OutgoingFailureNoResources,
+			pkt.linkFailure.FailureDetail,
+		)
+
+	case <-time.After(time.Second):
+		t.Fatal("fail was not propagated to source")
+	}
+
+	// Settle the first HTLC, which frees up its slot.
+	settle := &htlcPacket{
+		outgoingChanID: bobChannelLink.ShortChanID(),
+		outgoingHTLCID: 0,
+		amount:         1,
+		htlc: &lnwire.UpdateFulfillHTLC{
+			PaymentPreimage: preimage,
+		},
+	}
+	require.NoError(t, s.ForwardPackets(nil, settle))
+
+	select {
+	case pkt := <-aliceChannelLink.packets:
+		require.NoError(t, aliceChannelLink.deleteCircuit(pkt))
+
+	case <-time.After(time.Second):
+		t.Fatal("settle was not propagated to source")
+	}
+
+	// A new HTLC can now be forwarded again.
+	require.NoError(t, s.ForwardPackets(nil, newAddPacket(2)))
+
+	select {
+	case <-bobChannelLink.packets:
+	case <-time.After(time.Second):
+		t.Fatal("request was not propagated to destination")
+	}
+}
+
+// TestSwitchForwardRateLimits tests that HTLCs exceeding the rate limits of
+// their incoming channel are failed back, and that the in-flight value of an
+// HTLC is released once it is settled.
+func TestSwitchForwardRateLimits(t *testing.T) {
+	t.Parallel()
+
+	alicePeer, err := newMockServer(
+		t, "alice", testStartingHeight, nil, testDefaultDelta,
+	)
+	require.NoError(t, err)
+	bobPeer, err := newMockServer(
+		t, "bob", testStartingHeight, nil, testDefaultDelta,
+	)
+	require.NoError(t, err)
+
+	s, err := initSwitchWithTempDB(t, testStartingHeight)
+	require.NoError(t, err)
+
+	// Only one HTLC fits into the in-flight limit of alice's channel.
+	s.rateLimiter = newRateLimiter(&RateLimiterConfig{
+		Channel: RateLimits{
+			MaxInFlight: 1000,
+		},
+		FailRatioWindow:   time.Minute,
+		FailRatioMinHTLCs: 1,
+		Clock:             clock.NewDefaultClock(),
+	})
+
+	require.NoError(t, s.Start())
+	defer s.Stop()
+
+	chanID1, chanID2, aliceChanID, bobChanID := genIDs()
+
+	aliceChannelLink := newMockChannelLink(
+		s, chanID1, aliceChanID, emptyScid, alicePeer, true, false,
+		false, false,
+	)
+	bobChannelLink := newMockChannelLink(
+		s, chanID2, bobChanID, emptyScid, bobPeer, true, false, false,
+		false,
+	)
+	require.NoError(t, s.AddLink(aliceChannelLink))
+	require.NoError(t, s.AddLink(bobChannelLink))
+
+	preimage, err := genPreimage()
+	require.NoError(t, err)
+	rhash := sha256.Sum256(preimage[:])
+
+	newAddPacket := func(htlcID uint64) *htlcPacket {
+		return &htlcPacket{
+			incomingChanID: aliceChannelLink.ShortChanID(),
+			incomingHTLCID: htlcID,
+			incomingAmount: 1000,
+			outgoingChanID: bobChannelLink.ShortChanID(),
+			obfuscator:     NewMockObfuscator(),
+			htlc: &lnwire.UpdateAddHTLC{
+				PaymentHash: rhash,
+				Amount:      1000,
+			},
+		}
+	}
+
+	require.NoError(t, s.ForwardPackets(nil, newAddPacket(0)))
+
+	select {
+	case pkt := <-bobChannelLink.packets:
+		require.NoError(t, bobChannelLink.completeCircuit(pkt))
+
+	case <-time.After(time.Second):
+		t.Fatal("request was not propagated to destination")
+	}
+
+	// The second HTLC exceeds the in-flight limit, so it is failed back
+	// to alice.
+	require.NoError(t, s.ForwardPackets(nil, newAddPacket(1)))
+
+	select {
+	case pkt := <-aliceChannelLink.packets:
+		_, ok := pkt.htlc.(*lnwire.UpdateFailHTLC)
+		require.True(t, ok)
+		require.Equal(
+			t, OutgoingFailureInFlightLimit,
+			pkt.linkFailure.FailureDetail,
+		)
+
+	case <-time.After(time.Second):
+		t.Fatal("fail was not propagated to source")
+	}
+
+	// Settle the first HTLC, which releases its in-flight value.
+	settle := &htlcPacket{
+		outgoingChanID: bobChannelLink.ShortChanID(),
+		outgoingHTLCID: 0,
+		amount:         1000,
+		htlc: &lnwire.UpdateFulfillHTLC{
+			PaymentPreimage: preimage,
+		},
+	}
+	require.NoError(t, s.ForwardPackets(nil, settle))
+
+	select {
+	case pkt := <-aliceChannelLink.packets:
+		require.NoError(t, aliceChannelLink.deleteCircuit(pkt))
+
+	case <-time.After(time.Second):
+		t.Fatal("settle was not propagated to source")
+	}
+
+	// A new HTLC can now be forwarded again.
+	require.NoError(t, s.ForwardPackets(nil, newAddPacket(2)))
+
+	select {
+	case <-bobChannelLink.packets:
+	case <-time.After(time.Second):
+		t.Fatal("request was not propagated to destination")
+	}
+}
+
 func TestSwitchForwardFailAfterFullAdd(t *testing.T) {
 	t.Parallel()
 
@@ -4091,7 +4342,9 @@ func TestSwitchHoldForward(t *testing.T) {
 		OnionSHA256: shaOnionBlob,
 	}
 
-	fwdErr, err := newMockDeobfuscator().DecryptError(failPacket.Reason)
+	fwdErr, err := newMockDeobfuscator().DecryptError(
+		failPacket.Reason, nil,
+	)
 	require.NoError(t, err)
 	require.Equal(t, expectedFailure, fwdErr.WireMessage())
 
@@ -4331,7 +4584,7 @@ func TestSwitchDustForwarding(t *testing.T) {
 			default:
 			}
 
-			linkDust := link.getDustSum(remote)
+			linkDust := link.getFeeExposure(remote).DustSum
 			localMailDust, remoteMailDust := mbox.DustPackets()
 
 			totalDust := linkDust
@@ -5519,7 +5772,7 @@ func testSwitchAliasInterceptFail(t *testing.T, zeroConf bool) {
 		require.True(t, ok)
 
 		fwdErr, err := newMockDeobfuscator().DecryptError(
-			failHtlc.Reason,
+			failHtlc.Reason, nil,
 		)
 		require.NoError(t, err)
 
@@ -59,6 +59,10 @@ var (
 	wireSig, _ = lnwire.NewSigFromSignature(testSig)
 
 	testBatchTimeout = 50 * time.Millisecond
+
+	// testMaxLocalCSVDelay is the maximum csv delay the test links accept
+	// for their own outputs.
+	testMaxLocalCSVDelay = uint16(2016)
 )
 
 var idSeqNum uint64
@@ -1163,6 +1167,8 @@ func (h *hopNetwork) createChannelLink(server, peer *mockServer,
 			MaxOutgoingCltvExpiry:   DefaultMaxOutgoingCltvExpiry,
 			MaxFeeAllocation:        DefaultMaxLinkFeeAllocation,
 			MaxAnchorsCommitFeeRate: chainfee.SatPerKVByte(10 * 1000).FeePerKWeight(),
+			MaxLocalCSVDelay:        testMaxLocalCSVDelay,
+			DynCommitments:          true,
 			NotifyActiveLink:        func(wire.OutPoint) {},
 			NotifyActiveChannel:     func(wire.OutPoint) {},
 			NotifyInactiveChannel:   func(wire.OutPoint) {},
@@ -2942,6 +2942,29 @@ func CommitSpendAnchorAnyone(script []byte) (wire.TxWitness, error) {
 	return witnessStack, nil
 }
 
+// PayToAnchorScript returns the pay-to-anchor (P2A) output script used as the
+// single ephemeral anchor of zero-fee commitment transactions. The output is a
+// segwit v1 program that can be spent by anyone with an empty witness.
+//
+// Output Script:
+//
+//	OP_1 <0x4e73>
+func PayToAnchorScript() []byte {
+	return []byte{txscript.OP_1, txscript.OP_DATA_2, 0x4e, 0x73}
+}
+
+// IsPayToAnchorScript returns true if the passed script is a pay-to-anchor
+// output script.
+func IsPayToAnchorScript(pkScript []byte) bool {
+	return bytes.Equal(pkScript, PayToAnchorScript())
+}
+
+// PayToAnchorWitness returns the witness needed to spend a pay-to-anchor
+// output, which is always empty.
+func PayToAnchorWitness() wire.TxWitness {
+	return wire.TxWitness{}
+}
+
 // SingleTweakBytes computes set of bytes we call the single tweak. The purpose
 // of the single tweak is to randomize all regular delay and payment base
 // points. To do this, we generate a hash that binds the commitment point to
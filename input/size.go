@@ -38,6 +38,12 @@ const (
 	//	- WitnessScriptSHA256: 32 bytes
 	P2WSHSize = 1 + 1 + 32
 
+	// P2ASize 4 bytes
+	//	- OP_1: 1 byte
+	//	- OP_DATA: 1 byte (witness program length)
+	//	- WitnessProgram: 2 bytes
+	P2ASize = 1 + 1 + 2
+
 	// NestedP2WSHSize 35 bytes
 	//      - OP_DATA: 1 byte (P2WSHSize)
 	//      - P2WSHWitnessProgram: 34 bytes
@@ -177,6 +183,12 @@ const (
 	//	- PkScript (P2TR)
 	TaprootCommitmentAnchorOutput = 8 + 1 + P2TRSize
 
+	// CommitmentP2AOutput 13 bytes
+	//	- Value: 8 bytes
+	//	- VarInt: 1 byte (PkScript length)
+	//	- PkScript (P2A)
+	CommitmentP2AOutput = 8 + 1 + P2ASize
+
 	// HTLCSize 43 bytes
 	//	- Value: 8 bytes
 	//	- VarInt: 1 byte (PkScript length)
@@ -250,12 +262,36 @@ const (
 		2*TaprootCommitmentOutput + 2*TaprootCommitmentAnchorOutput +
 		4) * witnessScaleFactor
 
+	// BaseZeroFeeCommitmentTxSize 152 + 43 * num-htlc-outputs bytes
+	//	- Version: 4 bytes
+	//	- WitnessHeader <---- part of the witness data
+	//	- CountTxIn: 1 byte
+	//	- TxIn: 41 bytes
+	//		FundingInput
+	//	- CountTxOut: 3 byte
+	//	- TxOut: 2*43 + 13 + 43 * num-htlc-outputs bytes
+	//		OutputPayingToThem,
+	//		OutputPayingToUs,
+	//		EphemeralAnchor,
+	//		....HTLCOutputs...
+	//	- LockTime: 4 bytes
+	BaseZeroFeeCommitmentTxSize = 4 + 1 + FundingInputSize + 3 +
+		2*CommitmentDelayOutput + CommitmentP2AOutput + 4
+
+	// BaseZeroFeeCommitmentTxWeight 608 weight.
+	BaseZeroFeeCommitmentTxWeight = witnessScaleFactor *
+		BaseZeroFeeCommitmentTxSize
+
 	// CommitWeight 724 weight.
 	CommitWeight = BaseCommitmentTxWeight + WitnessCommitmentTxWeight
 
 	// AnchorCommitWeight 1124 weight.
 	AnchorCommitWeight = BaseAnchorCommitmentTxWeight + WitnessCommitmentTxWeight
 
+	// ZeroFeeCommitWeight 832 weight.
+	ZeroFeeCommitWeight = BaseZeroFeeCommitmentTxWeight +
+		WitnessCommitmentTxWeight
+
 	// TaprootCommitWeight 968 weight.
 	TaprootCommitWeight = (BaseTaprootCommitmentTxWeight +
 		WitnessHeaderSize + TaprootKeyPathWitnessSize)
@@ -667,6 +703,12 @@ const (
 	// pessemistic estimate.
 	TaprootAnchorWitnessSize = TaprootKeyPathCustomSighashWitnessSize
 
+	// PayToAnchorWitnessSize: 1 byte
+	//	- NumberOfWitnessElements: 1 byte
+	//
+	// A pay-to-anchor output is spent with an empty witness.
+	PayToAnchorWitnessSize = 1
+
 	// TaprootSecondLevelHtlcScriptSize: 41 bytes
 	//      - OP_DATA: 1 byte (pub key len)
 	//      - local_key: 32 bytes
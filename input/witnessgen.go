@@ -254,6 +254,11 @@ const (
 	// settled output of a malicious counterparty's who broadcasts a
 	// revoked taproot commitment transaction.
 	TaprootCommitmentRevoke StandardWitnessType = 34
+
+	// PayToAnchorSpend is a witness that allows anyone to spend the
+	// ephemeral pay-to-anchor output of a zero-fee commitment transaction.
+	// The witness is always empty.
+	PayToAnchorSpend StandardWitnessType = 35
 )
 
 // String returns a human readable version of the target WitnessType.
@@ -366,6 +371,9 @@ func (wt StandardWitnessType) String() string {
 	case TaprootCommitmentRevoke:
 		return "TaprootCommitmentRevoke"
 
+	case PayToAnchorSpend:
+		return "PayToAnchorSpend"
+
 	default:
 		return fmt.Sprintf("Unknown WitnessType: %v", uint32(wt))
 	}
@@ -714,6 +722,11 @@ func (wt StandardWitnessType) WitnessGenerator(signer Signer,
 				Witness: witness,
 			}, nil
 
+		case PayToAnchorSpend:
+			return &Script{
+				Witness: PayToAnchorWitness(),
+			}, nil
+
 		default:
 			return nil, fmt.Errorf("unknown witness type: %v", wt)
 		}
@@ -862,6 +875,9 @@ func (wt StandardWitnessType) SizeUpperBound() (int, bool, error) {
 
 	case TaprootCommitmentRevoke:
 		return TaprootToLocalRevokeWitnessSize, false, nil
+
+	case PayToAnchorSpend:
+		return PayToAnchorWitnessSize, false, nil
 	}
 
 	return 0, false, fmt.Errorf("unexpected witness type: %v", wt)
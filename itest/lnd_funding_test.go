@@ -881,9 +881,14 @@ func testChannelFundingPersistence(ht *lntest.HarnessTest) {
 	// reached our required confirmations.
 	tx := ht.AssertTxAtHeight(alice, height, fundingTxID)
 
-	// At this stage, we expect the transaction to be labelled, but not with
-	// our channel ID because our transaction has not yet confirmed.
-	label := labels.MakeLabel(labels.LabelTypeChannelOpen, nil)
+	// At this stage, we expect the transaction to be labelled with our
+	// channel point, but not with our channel ID because our transaction
+	// has not yet confirmed.
+	chanPoint := lntest.ChanPointFromPendingUpdate(update)
+	fundingPoint := ht.OutPointFromChannelPoint(chanPoint)
+	label := labels.MakeChannelLabel(
+		labels.LabelTypeChannelOpen, nil, fundingPoint,
+	)
 	require.Equal(ht, label, tx.Label, "open channel label wrong")
 
 	// Both nodes should still show a single channel as pending.
@@ -898,10 +903,6 @@ func testChannelFundingPersistence(ht *lntest.HarnessTest) {
 	ht.AssertNumPendingOpenChannels(alice, 0)
 	ht.AssertNumPendingOpenChannels(carol, 0)
 
-	// The channel should be listed in the peer information returned by
-	// both peers.
-	chanPoint := lntest.ChanPointFromPendingUpdate(update)
-
 	// Re-lookup our transaction in the block that it confirmed in.
 	tx = ht.AssertTxAtHeight(alice, height, fundingTxID)
 
@@ -917,7 +918,9 @@ func testChannelFundingPersistence(ht *lntest.HarnessTest) {
 	// Create an additional check for our channel assertion that will
 	// check that our label is as expected.
 	shortChanID := lnwire.NewShortChanIDFromInt(chanAlice.ChanId)
-	label = labels.MakeLabel(labels.LabelTypeChannelOpen, &shortChanID)
+	label = labels.MakeChannelLabel(
+		labels.LabelTypeChannelOpen, &shortChanID, fundingPoint,
+	)
 	require.Equal(ht, label, tx.Label, "open channel label not updated")
 
 	// Finally, immediately close the channel. This function will also
@@ -468,9 +468,9 @@ func testBidirectionalAsyncPayments(ht *lntest.HarnessTest) {
 	// We use new nodes here as the benchmark test creates lots of data
 	// which can be costly to be carried on.
 	args := []string{
-		// Increase the dust threshold to avoid the payments fail due
-		// to threshold limit reached.
-		"--dust-threshold=5000000",
+		// Increase the max fee exposure to avoid the payments fail due
+		// to the limit being reached.
+		"--channel-max-fee-exposure=5000000",
 
 		// Increase the pending commit interval since there are lots of
 		// commitment dances.
@@ -0,0 +1,200 @@
+package labels
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcwallet/wtxmgr"
+)
+
+// RecordType is the type of the object a BIP-329 label record refers to.
+type RecordType string
+
+const (
+	// RecordTypeTx is used for records that label a transaction. The
+	// reference is the txid.
+	RecordTypeTx RecordType = "tx"
+
+	// RecordTypeAddr is used for records that label an address. The
+	// reference is the address.
+	RecordTypeAddr RecordType = "addr"
+
+	// RecordTypePubKey is used for records that label a public key. The
+	// reference is the hex encoded public key.
+	RecordTypePubKey RecordType = "pubkey"
+
+	// RecordTypeInput is used for records that label a transaction input.
+	// The reference is the outpoint it spends, as txid:index.
+	RecordTypeInput RecordType = "input"
+
+	// RecordTypeOutput is used for records that label a transaction
+	// output. The reference is the outpoint, as txid:index.
+	RecordTypeOutput RecordType = "output"
+
+	// RecordTypeXPub is used for records that label an extended public
+	// key. The reference is the xpub.
+	RecordTypeXPub RecordType = "xpub"
+)
+
+// maxRecordLine is the maximum length of a line of a BIP-329 export that we
+// read. It leaves ample room for the reference and origin of a record with a
+// label of the maximum length.
+const maxRecordLine = 64 * 1024
+
+var (
+	// ErrUnknownRecordType is returned when a record has a type that is
+	// not defined by BIP-329.
+	ErrUnknownRecordType = errors.New("unknown label record type")
+
+	// ErrInvalidRecordRef is returned when the reference of a record is
+	// malformed.
+	ErrInvalidRecordRef = errors.New("invalid label record reference")
+)
+
+// Record is a label record as defined by BIP-329. A set of records is
+// exchanged between wallets as a JSON Lines document, with one record per
+// line.
+type Record struct {
+	// Type is the type of the labelled object.
+	Type RecordType `json:"type"`
+
+	// Ref references the labelled object. Its format depends on the type.
+	Ref string `json:"ref"`
+
+	// Label is the label of the object.
+	Label string `json:"label,omitempty"`
+
+	// Origin is the optional key origin of an address or xpub, as a
+	// descriptor fragment such as wpkh([d34db33f/84'/0'/0']).
+	Origin string `json:"origin,omitempty"`
+
+	// Spendable optionally indicates whether an output may be spent by the
+	// wallet. It is only defined for outputs.
+	Spendable *bool `json:"spendable,omitempty"`
+}
+
+// Validate checks that the record has a known type, a well formed reference
+// and a label that doesn't exceed the length limit of our labels.
+func (r *Record) Validate() error {
+	switch r.Type {
+	case RecordTypeTx:
+		if _, err := chainhash.NewHashFromStr(r.Ref); err != nil ||
+			len(r.Ref) != chainhash.MaxHashStringSize {
+
+			return fmt.Errorf("%w: %v is not a txid",
+				ErrInvalidRecordRef, r.Ref)
+		}
+
+	case RecordTypeInput, RecordTypeOutput:
+		if err := validateOutPointRef(r.Ref); err != nil {
+			return err
+		}
+
+	case RecordTypeAddr, RecordTypePubKey, RecordTypeXPub:
+		if r.Ref == "" {
+			return fmt.Errorf("%w: empty %v reference",
+				ErrInvalidRecordRef, r.Type)
+		}
+
+	default:
+		return fmt.Errorf("%w: %v", ErrUnknownRecordType, r.Type)
+	}
+
+	if r.Spendable != nil && r.Type != RecordTypeOutput {
+		return fmt.Errorf("spendable is only defined for outputs, not "+
+			"for %v records", r.Type)
+	}
+
+	if len(r.Label) > wtxmgr.TxLabelLimit {
+		return fmt.Errorf("label length %v of %v %v exceeds limit of "+
+			"%v", len(r.Label), r.Type, r.Ref, wtxmgr.TxLabelLimit)
+	}
+
+	return nil
+}
+
+// validateOutPointRef checks that the reference is an outpoint formatted as
+// txid:index.
+func validateOutPointRef(ref string) error {
+	txid, index, ok := strings.Cut(ref, ":")
+	if !ok {
+		return fmt.Errorf("%w: %v is not an outpoint",
+			ErrInvalidRecordRef, ref)
+	}
+
+	_, err := chainhash.NewHashFromStr(txid)
+	if err != nil || len(txid) != chainhash.MaxHashStringSize {
+		return fmt.Errorf("%w: %v has an invalid txid",
+			ErrInvalidRecordRef, ref)
+	}
+
+	if _, err := strconv.ParseUint(index, 10, 32); err != nil {
+		return fmt.Errorf("%w: %v has an invalid output index",
+			ErrInvalidRecordRef, ref)
+	}
+
+	return nil
+}
+
+// WriteRecords writes the records to w as a BIP-329 JSON Lines document.
+func WriteRecords(w io.Writer, records []*Record) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReadRecords reads the records of a BIP-329 JSON Lines document from r.
+// Empty lines and records of a type that isn't defined by BIP-329 are skipped
+// as the BIP requires, while any other malformed record fails the whole
+// document.
+func ReadRecords(r io.Reader) ([]*Record, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxRecordLine)
+
+	var (
+		records []*Record
+		line    int
+	)
+	for scanner.Scan() {
+		line++
+
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		record := &Record{}
+		if err := json.Unmarshal([]byte(text), record); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		err := record.Validate()
+		switch {
+		case errors.Is(err, ErrUnknownRecordType):
+			continue
+
+		case err != nil:
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
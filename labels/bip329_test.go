@@ -0,0 +1,81 @@
+package labels
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testTxid is a txid used as reference in the tests.
+const testTxid = "f91d0a8a78462bc59398f2c5d7a84fcf" +
+	"f491c26ba54c4833478b202796c8aafd"
+
+// TestRecordsRoundTrip tests that records survive a round trip through a
+// BIP-329 document.
+func TestRecordsRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	spendable := false
+	records := []*Record{{
+		Type:  RecordTypeTx,
+		Ref:   testTxid,
+		Label: "rent <march>",
+	}, {
+		Type:   RecordTypeAddr,
+		Ref:    "bc1q34aq5drpuwy3wgl9lhup9892qp6svr8ldzyy7c",
+		Label:  "donations",
+		Origin: "wpkh([d34db33f/84'/0'/0'])",
+	}, {
+		Type:      RecordTypeOutput,
+		Ref:       testTxid + ":1",
+		Label:     "cold storage",
+		Spendable: &spendable,
+	}}
+
+	var b bytes.Buffer
+	require.NoError(t, WriteRecords(&b, records))
+
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	require.Len(t, lines, len(records))
+	require.Equal(
+		t, `{"type":"tx","ref":"`+testTxid+`","label":"rent <march>"}`,
+		lines[0],
+	)
+
+	decoded, err := ReadRecords(&b)
+	require.NoError(t, err)
+	require.Equal(t, records, decoded)
+}
+
+// TestReadRecords tests that empty lines and unknown record types are
+// skipped, while malformed records fail the document.
+func TestReadRecords(t *testing.T) {
+	t.Parallel()
+
+	doc := `{"type":"tx","ref":"` + testTxid + `","label":"a"}
+
+{"type":"unknown","ref":"x","label":"b"}
+{"type":"input","ref":"` + testTxid + `:0","label":"c","extra":1}
+`
+	records, err := ReadRecords(strings.NewReader(doc))
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	require.Equal(t, RecordTypeInput, records[1].Type)
+
+	malformed := []string{
+		`{"type":"tx","ref":"abcd","label":"a"}`,
+		`{"type":"output","ref":"` + testTxid + `","label":"a"}`,
+		`{"type":"output","ref":"` + testTxid + `:x","label":"a"}`,
+		`{"type":"addr","ref":"","label":"a"}`,
+		`{"type":"addr","ref":"x","spendable":true}`,
+		`{"type":"tx","ref":"` + testTxid + `","label":"` +
+			strings.Repeat("a", 501) + `"}`,
+		`not json`,
+	}
+	for _, doc := range malformed {
+		_, err := ReadRecords(strings.NewReader(doc))
+		require.Error(t, err, doc)
+	}
+}
@@ -12,11 +12,20 @@
 // For version 0 we have the following optional data fields defined:
 //   - shortchanid: the short channel ID that a transaction is associated with,
 //     with its value set to the uint64 short channel id.
+//   - chanpoint: the channel point of the channel that a transaction is
+//     associated with, with its value set to {txid}_{output index}.
+//   - purpose: the comma separated witness types of the inputs a sweep
+//     transaction spends.
 package labels
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcwallet/wtxmgr"
 	"github.com/lightningnetwork/lnd/lnwire"
 )
@@ -76,8 +85,47 @@ type LabelField string
 const (
 	// ShortChanID is used to tag short channel id values in our labels.
 	ShortChanID LabelField = "shortchanid"
+
+	// ChanPoint is used to tag channel point values in our labels.
+	ChanPoint LabelField = "chanpoint"
+
+	// Purpose is used to tag the witness types of the inputs of sweeps in
+	// our labels.
+	Purpose LabelField = "purpose"
 )
 
+const (
+	// fieldSeparator separates the version, type and fields of a label.
+	fieldSeparator = ":"
+
+	// valueSeparator separates the name of a field from its value.
+	valueSeparator = "-"
+
+	// outpointSeparator separates the txid from the output index in a
+	// chanpoint value, as the usual colon is our field separator.
+	outpointSeparator = "_"
+
+	// purposeSeparator separates the witness types in a purpose value.
+	purposeSeparator = ","
+)
+
+// Label is a label created by lnd, broken down into its type and fields.
+type Label struct {
+	// Type is the type of the labelled transaction.
+	Type LabelType
+
+	// ShortChanID is the short channel ID of the channel the transaction
+	// is associated with, if known.
+	ShortChanID *lnwire.ShortChannelID
+
+	// ChanPoint is the channel point of the channel the transaction is
+	// associated with, if known.
+	ChanPoint *wire.OutPoint
+
+	// Purposes are the witness types of the inputs of a sweep.
+	Purposes []string
+}
+
 // MakeLabel creates a label with the provided type and short channel id. If
 // our short channel ID is not known, we simply return version:label_type. If
 // we do have a short channel ID set, the label will also contain its value:
@@ -90,3 +138,123 @@ func MakeLabel(labelType LabelType, channelID *lnwire.ShortChannelID) string {
 	return fmt.Sprintf("%v:%v:%v-%v", LabelVersionZero, labelType,
 		ShortChanID, channelID.ToUint64())
 }
+
+// MakeChannelLabel creates a label with the provided type for a transaction
+// of the channel with the given channel point. The short channel ID is only
+// added if it is known.
+func MakeChannelLabel(labelType LabelType,
+	channelID *lnwire.ShortChannelID, chanPoint wire.OutPoint) string {
+
+	return fmt.Sprintf("%v:%v-%v%v%v", MakeLabel(labelType, channelID),
+		ChanPoint, chanPoint.Hash, outpointSeparator, chanPoint.Index)
+}
+
+// MakeSweepLabel creates a sweep label that lists the given witness types of
+// the swept inputs as its purpose. The witness types are deduplicated and
+// sorted, and the ones that don't fit into the label length limit are left
+// out.
+func MakeSweepLabel(purposes ...string) string {
+	label := MakeLabel(LabelTypeSweepTransaction, nil)
+
+	unique := make(map[string]struct{}, len(purposes))
+	for _, purpose := range purposes {
+		unique[purpose] = struct{}{}
+	}
+
+	sorted := make([]string, 0, len(unique))
+	for purpose := range unique {
+		sorted = append(sorted, purpose)
+	}
+	sort.Strings(sorted)
+
+	separator := fieldSeparator + string(Purpose) + valueSeparator
+	for _, purpose := range sorted {
+		if len(label)+len(separator)+len(purpose) >
+			wtxmgr.TxLabelLimit {
+
+			break
+		}
+
+		label += separator + purpose
+		separator = purposeSeparator
+	}
+
+	return label
+}
+
+// ParseLabelType returns the label type of a label that was created with
+// MakeLabel. False is returned if the label wasn't created by lnd, which is
+// the case for user provided labels.
+func ParseLabelType(label string) (LabelType, bool) {
+	parsed, ok := ParseLabel(label)
+	if !ok {
+		return "", false
+	}
+
+	return parsed.Type, true
+}
+
+// ParseLabel breaks down a label that was created by lnd into its type and
+// fields. False is returned if the label wasn't created by lnd, which is the
+// case for user provided labels. Unknown fields and fields with malformed
+// values are ignored.
+func ParseLabel(label string) (*Label, bool) {
+	parts := strings.Split(label, fieldSeparator)
+	if len(parts) < 2 {
+		return nil, false
+	}
+
+	if parts[0] != fmt.Sprintf("%v", LabelVersionZero) {
+		return nil, false
+	}
+
+	parsed := &Label{
+		Type: LabelType(parts[1]),
+	}
+	for _, field := range parts[2:] {
+		name, value, ok := strings.Cut(field, valueSeparator)
+		if !ok {
+			continue
+		}
+
+		switch LabelField(name) {
+		case ShortChanID:
+			chanID, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				continue
+			}
+
+			shortChanID := lnwire.NewShortChanIDFromInt(chanID)
+			parsed.ShortChanID = &shortChanID
+
+		case ChanPoint:
+			parsed.ChanPoint = parseChanPoint(value)
+
+		case Purpose:
+			parsed.Purposes = strings.Split(value, purposeSeparator)
+		}
+	}
+
+	return parsed, true
+}
+
+// parseChanPoint parses a chanpoint field value, returning nil if the value
+// is malformed.
+func parseChanPoint(value string) *wire.OutPoint {
+	txid, index, ok := strings.Cut(value, outpointSeparator)
+	if !ok {
+		return nil
+	}
+
+	hash, err := chainhash.NewHashFromStr(txid)
+	if err != nil {
+		return nil
+	}
+
+	outputIndex, err := strconv.ParseUint(index, 10, 32)
+	if err != nil {
+		return nil
+	}
+
+	return wire.NewOutPoint(hash, uint32(outputIndex))
+}
@@ -0,0 +1,117 @@
+package labels
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcwallet/wtxmgr"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseLabel tests that the labels created by lnd are broken down into
+// their type and fields.
+func TestParseLabel(t *testing.T) {
+	t.Parallel()
+
+	chanID := lnwire.NewShortChanIDFromInt(123)
+	chanPoint := wire.OutPoint{Hash: chainhash.Hash{1}, Index: 2}
+
+	tests := []struct {
+		name     string
+		label    string
+		expected *Label
+	}{{
+		name:  "plain label",
+		label: MakeLabel(LabelTypeJusticeTransaction, nil),
+		expected: &Label{
+			Type: LabelTypeJusticeTransaction,
+		},
+	}, {
+		name:  "short channel id",
+		label: MakeLabel(LabelTypeChannelClose, &chanID),
+		expected: &Label{
+			Type:        LabelTypeChannelClose,
+			ShortChanID: &chanID,
+		},
+	}, {
+		name: "channel point",
+		label: MakeChannelLabel(
+			LabelTypeChannelOpen, nil, chanPoint,
+		),
+		expected: &Label{
+			Type:      LabelTypeChannelOpen,
+			ChanPoint: &chanPoint,
+		},
+	}, {
+		name: "channel point and short channel id",
+		label: MakeChannelLabel(
+			LabelTypeChannelClose, &chanID, chanPoint,
+		),
+		expected: &Label{
+			Type:        LabelTypeChannelClose,
+			ShortChanID: &chanID,
+			ChanPoint:   &chanPoint,
+		},
+	}, {
+		name:  "sweep purposes",
+		label: MakeSweepLabel("HtlcOfferedRemoteTimeout", "Anchor"),
+		expected: &Label{
+			Type: LabelTypeSweepTransaction,
+			Purposes: []string{
+				"Anchor", "HtlcOfferedRemoteTimeout",
+			},
+		},
+	}, {
+		name:  "malformed fields are ignored",
+		label: "0:closechannel:shortchanid-x:chanpoint-y:color-red",
+		expected: &Label{
+			Type: LabelTypeChannelClose,
+		},
+	}}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			label, ok := ParseLabel(test.label)
+			require.True(t, ok)
+			require.Equal(t, test.expected, label)
+		})
+	}
+
+	// User provided labels aren't parsed.
+	_, ok := ParseLabel("coffee")
+	require.False(t, ok)
+
+	_, ok = ParseLabel("1:openchannel")
+	require.False(t, ok)
+}
+
+// TestMakeSweepLabel tests that the purposes of a sweep label are
+// deduplicated and that the label doesn't exceed the length limit.
+func TestMakeSweepLabel(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(
+		t, "0:sweep:purpose-CommitmentAnchor,CommitmentTimeLock",
+		MakeSweepLabel(
+			"CommitmentTimeLock", "CommitmentAnchor",
+			"CommitmentTimeLock",
+		),
+	)
+
+	require.Equal(t, "0:sweep", MakeSweepLabel())
+
+	purposes := make([]string, 100)
+	for i := range purposes {
+		purposes[i] = strings.Repeat(string(rune('a'+i%26)), i+1)
+	}
+	label := MakeSweepLabel(purposes...)
+	require.LessOrEqual(t, len(label), wtxmgr.TxLabelLimit)
+	require.True(t, strings.HasPrefix(label, "0:sweep:purpose-a,"))
+}
@@ -0,0 +1,279 @@
+package labels
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/lightningnetwork/lnd/kvdb"
+	"github.com/lightningnetwork/lnd/tlv"
+)
+
+var (
+	// walletLabelsBucket is the top level bucket of the wallet labels. It
+	// holds a sub-bucket for each record type.
+	//
+	// maps: record type -> ref -> TLV encoded Record
+	walletLabelsBucket = []byte("wallet-labels")
+
+	// ErrRecordNotFound is returned when a label record isn't known to
+	// the store.
+	ErrRecordNotFound = errors.New("label record not found")
+
+	// errNoWalletLabelsBucket is returned when the wallet labels bucket
+	// doesn't exist.
+	errNoWalletLabelsBucket = errors.New("wallet labels bucket does not " +
+		"exist")
+)
+
+const (
+	typeRecordLabel     tlv.Type = 0
+	typeRecordOrigin    tlv.Type = 1
+	typeRecordSpendable tlv.Type = 2
+)
+
+const (
+	// spendableUnset is the encoding of a record without a spendable
+	// flag.
+	spendableUnset uint8 = 0
+
+	// spendableTrue is the encoding of a spendable output.
+	spendableTrue uint8 = 1
+
+	// spendableFalse is the encoding of an output that shouldn't be
+	// spent.
+	spendableFalse uint8 = 2
+)
+
+// Store persists the labels of the addresses, outputs and transactions of the
+// wallet as BIP-329 records.
+type Store interface {
+	// PutRecord adds a record or replaces the record with the same type
+	// and reference.
+	PutRecord(r *Record) error
+
+	// FetchRecord returns the record with the given type and reference.
+	// ErrRecordNotFound is returned if there is no such record.
+	FetchRecord(recordType RecordType, ref string) (*Record, error)
+
+	// FetchRecords returns all records, ordered by type and reference.
+	FetchRecords() ([]*Record, error)
+
+	// DeleteRecord removes the record with the given type and reference.
+	// ErrRecordNotFound is returned if there is no such record.
+	DeleteRecord(recordType RecordType, ref string) error
+}
+
+// kvStore is a Store that is backed by a kvdb backend.
+type kvStore struct {
+	db kvdb.Backend
+}
+
+// A compile-time check to ensure kvStore implements the Store interface.
+var _ Store = (*kvStore)(nil)
+
+// NewStore returns a new Store backed by the given database.
+func NewStore(db kvdb.Backend) (Store, error) {
+	err := kvdb.Update(db, func(tx kvdb.RwTx) error {
+		_, err := tx.CreateTopLevelBucket(walletLabelsBucket)
+		return err
+	}, func() {})
+	if err != nil {
+		return nil, err
+	}
+
+	return &kvStore{
+		db: db,
+	}, nil
+}
+
+// PutRecord adds a record or replaces the record with the same type and
+// reference.
+//
+// NOTE: Part of the Store interface.
+func (s *kvStore) PutRecord(r *Record) error {
+	if err := r.Validate(); err != nil {
+		return err
+	}
+
+	var b bytes.Buffer
+	if err := serializeRecord(&b, r); err != nil {
+		return err
+	}
+
+	return kvdb.Update(s.db, func(tx kvdb.RwTx) error {
+		labels := tx.ReadWriteBucket(walletLabelsBucket)
+		if labels == nil {
+			return errNoWalletLabelsBucket
+		}
+
+		bucket, err := labels.CreateBucketIfNotExists([]byte(r.Type))
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put([]byte(r.Ref), b.Bytes())
+	}, func() {})
+}
+
+// FetchRecord returns the record with the given type and reference.
+//
+// NOTE: Part of the Store interface.
+func (s *kvStore) FetchRecord(recordType RecordType, ref string) (*Record,
+	error) {
+
+	var record *Record
+	err := kvdb.View(s.db, func(tx kvdb.RTx) error {
+		labels := tx.ReadBucket(walletLabelsBucket)
+		if labels == nil {
+			return errNoWalletLabelsBucket
+		}
+
+		bucket := labels.NestedReadBucket([]byte(recordType))
+		if bucket == nil {
+			return ErrRecordNotFound
+		}
+
+		v := bucket.Get([]byte(ref))
+		if v == nil {
+			return ErrRecordNotFound
+		}
+
+		var err error
+		record, err = deserializeRecord(bytes.NewReader(v))
+		if err != nil {
+			return err
+		}
+		record.Type = recordType
+		record.Ref = ref
+
+		return nil
+	}, func() {
+		record = nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// FetchRecords returns all records, ordered by type and reference.
+//
+// NOTE: Part of the Store interface.
+func (s *kvStore) FetchRecords() ([]*Record, error) {
+	var records []*Record
+	err := kvdb.View(s.db, func(tx kvdb.RTx) error {
+		labels := tx.ReadBucket(walletLabelsBucket)
+		if labels == nil {
+			return errNoWalletLabelsBucket
+		}
+
+		return labels.ForEach(func(recordType, _ []byte) error {
+			bucket := labels.NestedReadBucket(recordType)
+			if bucket == nil {
+				return nil
+			}
+
+			return bucket.ForEach(func(ref, v []byte) error {
+				r, err := deserializeRecord(bytes.NewReader(v))
+				if err != nil {
+					return err
+				}
+				r.Type = RecordType(recordType)
+				r.Ref = string(ref)
+
+				records = append(records, r)
+
+				return nil
+			})
+		})
+	}, func() {
+		records = nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// DeleteRecord removes the record with the given type and reference.
+//
+// NOTE: Part of the Store interface.
+func (s *kvStore) DeleteRecord(recordType RecordType, ref string) error {
+	return kvdb.Update(s.db, func(tx kvdb.RwTx) error {
+		labels := tx.ReadWriteBucket(walletLabelsBucket)
+		if labels == nil {
+			return errNoWalletLabelsBucket
+		}
+
+		bucket := labels.NestedReadWriteBucket([]byte(recordType))
+		if bucket == nil || bucket.Get([]byte(ref)) == nil {
+			return ErrRecordNotFound
+		}
+
+		return bucket.Delete([]byte(ref))
+	}, func() {})
+}
+
+// serializeRecord writes the TLV encoding of the label, origin and spendable
+// flag of the given record to w. The type and reference are the keys the
+// record is stored under.
+func serializeRecord(w io.Writer, r *Record) error {
+	var (
+		label     = []byte(r.Label)
+		origin    = []byte(r.Origin)
+		spendable = spendableUnset
+	)
+	if r.Spendable != nil {
+		spendable = spendableFalse
+		if *r.Spendable {
+			spendable = spendableTrue
+		}
+	}
+
+	stream, err := tlv.NewStream(
+		tlv.MakePrimitiveRecord(typeRecordLabel, &label),
+		tlv.MakePrimitiveRecord(typeRecordOrigin, &origin),
+		tlv.MakePrimitiveRecord(typeRecordSpendable, &spendable),
+	)
+	if err != nil {
+		return err
+	}
+
+	return stream.Encode(w)
+}
+
+// deserializeRecord reads the TLV encoding of a record from r.
+func deserializeRecord(r io.Reader) (*Record, error) {
+	var (
+		label, origin []byte
+		spendable     uint8
+	)
+
+	stream, err := tlv.NewStream(
+		tlv.MakePrimitiveRecord(typeRecordLabel, &label),
+		tlv.MakePrimitiveRecord(typeRecordOrigin, &origin),
+		tlv.MakePrimitiveRecord(typeRecordSpendable, &spendable),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := stream.Decode(r); err != nil {
+		return nil, err
+	}
+
+	record := &Record{
+		Label:  string(label),
+		Origin: string(origin),
+	}
+	switch spendable {
+	case spendableTrue, spendableFalse:
+		isSpendable := spendable == spendableTrue
+		record.Spendable = &isSpendable
+	}
+
+	return record, nil
+}
@@ -0,0 +1,74 @@
+package labels
+
+import (
+	"testing"
+
+	"github.com/lightningnetwork/lnd/kvdb"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStore tests that label records are stored, replaced and deleted.
+func TestStore(t *testing.T) {
+	t.Parallel()
+
+	db, cleanUp, err := kvdb.GetTestBackend(t.TempDir(), "labels")
+	require.NoError(t, err)
+	t.Cleanup(cleanUp)
+
+	store, err := NewStore(db)
+	require.NoError(t, err)
+
+	spendable := true
+	output := &Record{
+		Type:      RecordTypeOutput,
+		Ref:       testTxid + ":0",
+		Label:     "change",
+		Spendable: &spendable,
+	}
+	addr := &Record{
+		Type:  RecordTypeAddr,
+		Ref:   "bc1q34aq5drpuwy3wgl9lhup9892qp6svr8ldzyy7c",
+		Label: "donations",
+	}
+	tx := &Record{
+		Type:  RecordTypeTx,
+		Ref:   testTxid,
+		Label: "rent",
+	}
+	for _, r := range []*Record{output, addr, tx} {
+		require.NoError(t, store.PutRecord(r))
+	}
+
+	// Invalid records are rejected.
+	require.ErrorIs(t, store.PutRecord(&Record{
+		Type: RecordTypeTx,
+		Ref:  "abcd",
+	}), ErrInvalidRecordRef)
+
+	records, err := store.FetchRecords()
+	require.NoError(t, err)
+	require.Equal(t, []*Record{addr, output, tx}, records)
+
+	tx.Label = "rent march"
+	require.NoError(t, store.PutRecord(tx))
+
+	record, err := store.FetchRecord(RecordTypeTx, testTxid)
+	require.NoError(t, err)
+	require.Equal(t, tx, record)
+
+	require.NoError(t, store.DeleteRecord(RecordTypeAddr, addr.Ref))
+	require.ErrorIs(
+		t, store.DeleteRecord(RecordTypeAddr, addr.Ref),
+		ErrRecordNotFound,
+	)
+
+	_, err = store.FetchRecord(RecordTypeAddr, addr.Ref)
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	_, err = store.FetchRecord(RecordTypeXPub, "xpub")
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	records, err = store.FetchRecords()
+	require.NoError(t, err)
+	require.Equal(t, []*Record{output, tx}, records)
+}
@@ -1,5 +1,29 @@
 package lncfg
 
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultAutopilotPolicyInterval is the default interval at which the
+	// autopilot channel policies are evaluated.
+	DefaultAutopilotPolicyInterval = 10 * time.Minute
+
+	// DefaultAutopilotUnbalancedRatio is the default fraction of the
+	// capacity below which the local or remote balance of a channel is
+	// considered unbalanced.
+	DefaultAutopilotUnbalancedRatio = 0.1
+
+	// DefaultAutopilotMaxCloses is the default maximum number of channels
+	// closed per policy evaluation.
+	DefaultAutopilotMaxCloses = 1
+
+	// DefaultAutopilotRebalanceMaxFeeRate is the default maximum fee rate
+	// in ppm paid for rebalancing channels.
+	DefaultAutopilotRebalanceMaxFeeRate = 500
+)
+
 // AutoPilot holds the configuration options for the daemon's autopilot.
 //
 //nolint:lll
@@ -13,4 +37,38 @@ type AutoPilot struct {
 	Private        bool               `long:"private" description:"Whether the channels created by the autopilot agent should be private or not. Private channels won't be announced to the network."`
 	MinConfs       int32              `long:"minconfs" description:"The minimum number of confirmations each of your inputs in funding transactions created by the autopilot agent must have."`
 	ConfTarget     uint32             `long:"conftarget" description:"The confirmation target (in blocks) for channels opened by autopilot."`
+
+	PolicyInterval       time.Duration `long:"policy-interval" description:"How often the autopilot agent evaluates its channel closing and rebalancing policies."`
+	CloseInactiveAfter   time.Duration `long:"close-inactive-after" description:"Force close channels whose peer has been offline for this long. Set to 0 to never close inactive channels."`
+	CloseUnbalancedAfter time.Duration `long:"close-unbalanced-after" description:"Cooperatively close channels that have been unbalanced for this long. Set to 0 to never close unbalanced channels."`
+	UnbalancedRatio      float64       `long:"unbalanced-ratio" description:"A channel is considered unbalanced if its local or remote balance is below this fraction of its capacity."`
+	MaxCloses            uint32        `long:"max-closes" description:"The maximum number of channels closed per policy evaluation. Channels to the peers given the lowest scores by the heuristics are closed first."`
+	RebalanceRatio       float64       `long:"rebalance-ratio" description:"Refill the local balance of channels below this fraction of their capacity by a circular payment from the channel with the most local balance. Set to 0 to disable rebalancing."`
+	RebalanceMaxFeeRate  uint32        `long:"rebalance-max-fee-rate" description:"The maximum fee rate in parts per million paid for rebalancing channels."`
+}
+
+// Validate checks the values configured for the autopilot channel policies.
+func (a *AutoPilot) Validate() error {
+	if a.PolicyInterval < 0 {
+		return fmt.Errorf("policy-interval must be non-negative")
+	}
+
+	if a.CloseInactiveAfter < 0 {
+		return fmt.Errorf("close-inactive-after must be non-negative")
+	}
+
+	if a.CloseUnbalancedAfter < 0 {
+		return fmt.Errorf("close-unbalanced-after must be " +
+			"non-negative")
+	}
+
+	if a.UnbalancedRatio < 0 || a.UnbalancedRatio >= 0.5 {
+		return fmt.Errorf("unbalanced-ratio must be in [0, 0.5)")
+	}
+
+	if a.RebalanceRatio < 0 || a.RebalanceRatio >= 0.5 {
+		return fmt.Errorf("rebalance-ratio must be in [0, 0.5)")
+	}
+
+	return nil
 }
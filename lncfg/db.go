@@ -14,6 +14,7 @@ import (
 	"github.com/lightningnetwork/lnd/kvdb/sqlite"
 	"github.com/lightningnetwork/lnd/lnrpc"
 	"github.com/lightningnetwork/lnd/lnwallet/btcwallet"
+	"github.com/lightningnetwork/lnd/sqldb"
 )
 
 const (
@@ -28,6 +29,7 @@ const (
 	SqliteChainDBName    = "chain.sqlite"
 	SqliteNeutrinoDBName = "neutrino.sqlite"
 	SqliteTowerDBName    = "watchtower.sqlite"
+	SqliteNativeDBName   = "lnd.sqlite"
 
 	BoltBackend                = "bolt"
 	EtcdBackend                = "etcd"
@@ -64,6 +66,10 @@ const (
 
 	// NSNeutrinoDB is the namespace name that we use for the neutrino DB.
 	NSNeutrinoDB = "neutrinodb"
+
+	// NSNativeSQLDB is the name that we use for the database of the
+	// native SQL tables.
+	NSNativeSQLDB = "nativesqldb"
 )
 
 // DB holds database configuration for LND.
@@ -87,6 +93,12 @@ type DB struct {
 	PruneRevocation bool `long:"prune-revocation" description:"Run the optional migration that prunes the revocation logs to save disk space."`
 
 	NoRevLogAmtData bool `long:"no-rev-log-amt-data" description:"If set, the to-local and to-remote output amounts of revoked commitment transactions will not be stored in the revocation log. Note that once this data is lost, a watchtower client will not be able to back up the revoked state."`
+
+	UseNativeSQL bool `long:"use-native-sql" description:"Use native SQL tables for the subsystems that support it, for example accounts. Can only be used with the postgres or sqlite database backend."`
+
+	MigratePaymentsToSQL bool `long:"migrate-payments-to-sql" description:"Migrate the payments to the native SQL tables and store all new payments there. WARNING: this can't be undone, once the payments are migrated lnd refuses to start without this option. Requires use-native-sql."`
+
+	MigrateGraphToSQL bool `long:"migrate-graph-to-sql" description:"Migrate the channel graph to the native SQL tables and store all graph updates there. WARNING: this can't be undone, once the graph is migrated lnd refuses to start without this option. Requires use-native-sql."`
 }
 
 // DefaultDB creates and returns a new default DB config.
@@ -146,6 +158,25 @@ func (db *DB) Validate() error {
 			"backend '%v'", db.Backend)
 	}
 
+	// The native SQL tables live in the same database as the kvdb tables,
+	// so they require a SQL backend.
+	if db.UseNativeSQL && db.Backend != PostgresBackend &&
+		db.Backend != SqliteBackend {
+
+		return fmt.Errorf("cannot use native SQL with database "+
+			"backend '%v'", db.Backend)
+	}
+
+	if db.MigratePaymentsToSQL && !db.UseNativeSQL {
+		return fmt.Errorf("cannot migrate payments to SQL without " +
+			"use-native-sql")
+	}
+
+	if db.MigrateGraphToSQL && !db.UseNativeSQL {
+		return fmt.Errorf("cannot migrate graph to SQL without " +
+			"use-native-sql")
+	}
+
 	return nil
 }
 
@@ -214,6 +245,10 @@ type DatabaseBackends struct {
 	// the underlying wallet database from.
 	WalletDB btcwallet.LoaderOption
 
+	// NativeSQLStore is the database that holds the native SQL tables.
+	// This is nil if native SQL isn't enabled.
+	NativeSQLStore *sqldb.BaseDB
+
 	// Remote indicates whether the database backends are remote, possibly
 	// replicated instances or local bbolt or sqlite backed databases.
 	Remote bool
@@ -411,6 +446,23 @@ func (db *DB) GetBackends(ctx context.Context, chanDBPath,
 			logger, "postgres", chanDBPath, ChannelDBName,
 		)
 
+		var nativeSQLStore *sqldb.BaseDB
+		if db.UseNativeSQL {
+			nativePostgresStore, err := sqldb.NewPostgresStore(
+				&sqldb.PostgresConfig{
+					Dsn:                db.Postgres.Dsn,
+					MaxOpenConnections: db.Postgres.MaxConnections,
+				},
+			)
+			if err != nil {
+				return nil, fmt.Errorf("error opening native "+
+					"postgres store: %v", err)
+			}
+
+			nativeSQLStore = nativePostgresStore.BaseDB
+			closeFuncs[NSNativeSQLDB] = nativePostgresStore.Close
+		}
+
 		returnEarly = false
 
 		return &DatabaseBackends{
@@ -429,8 +481,9 @@ func (db *DB) GetBackends(ctx context.Context, chanDBPath,
 			WalletDB: btcwallet.LoaderWithExternalWalletDB(
 				postgresWalletBackend,
 			),
-			Remote:     true,
-			CloseFuncs: closeFuncs,
+			NativeSQLStore: nativeSQLStore,
+			Remote:         true,
+			CloseFuncs:     closeFuncs,
 		}, nil
 
 	case SqliteBackend:
@@ -513,6 +566,24 @@ func (db *DB) GetBackends(ctx context.Context, chanDBPath,
 			logger, "sqlite", chanDBPath, ChannelDBName,
 		)
 
+		var nativeSQLStore *sqldb.BaseDB
+		if db.UseNativeSQL {
+			nativeSQLiteStore, err := sqldb.NewSqliteStore(
+				&sqldb.SqliteConfig{
+					DatabaseFileName: filepath.Join(
+						chanDBPath, SqliteNativeDBName,
+					),
+				},
+			)
+			if err != nil {
+				return nil, fmt.Errorf("error opening native "+
+					"sqlite store: %v", err)
+			}
+
+			nativeSQLStore = nativeSQLiteStore.BaseDB
+			closeFuncs[NSNativeSQLDB] = nativeSQLiteStore.Close
+		}
+
 		returnEarly = false
 
 		return &DatabaseBackends{
@@ -531,7 +602,8 @@ func (db *DB) GetBackends(ctx context.Context, chanDBPath,
 			WalletDB: btcwallet.LoaderWithExternalWalletDB(
 				sqliteWalletBackend,
 			),
-			CloseFuncs: closeFuncs,
+			NativeSQLStore: nativeSQLStore,
+			CloseFuncs:     closeFuncs,
 		}, nil
 	}
 
@@ -22,3 +22,29 @@ func TestDBDefaultConfig(t *testing.T) {
 	require.False(t, defaultConfig.Bolt.AutoCompact)
 	require.True(t, defaultConfig.Bolt.NoFreelistSync)
 }
+
+// TestDBValidateMigratePayments tests that the payments can only be migrated
+// if native SQL tables are used.
+func TestDBValidateMigratePayments(t *testing.T) {
+	cfg := lncfg.DefaultDB()
+	cfg.Backend = lncfg.SqliteBackend
+	cfg.MigratePaymentsToSQL = true
+
+	require.ErrorContains(t, cfg.Validate(), "use-native-sql")
+
+	cfg.UseNativeSQL = true
+	require.NoError(t, cfg.Validate())
+}
+
+// TestDBValidateMigrateGraph tests that the channel graph can only be migrated
+// if native SQL tables are used.
+func TestDBValidateMigrateGraph(t *testing.T) {
+	cfg := lncfg.DefaultDB()
+	cfg.Backend = lncfg.SqliteBackend
+	cfg.MigrateGraphToSQL = true
+
+	require.ErrorContains(t, cfg.Validate(), "use-native-sql")
+
+	cfg.UseNativeSQL = true
+	require.NoError(t, cfg.Validate())
+}
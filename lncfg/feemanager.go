@@ -0,0 +1,97 @@
+package lncfg
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultFeeManagerStrategy is the default strategy used to adjust
+	// channel fees.
+	DefaultFeeManagerStrategy = "liquidity"
+
+	// DefaultFeeManagerInterval is the default interval at which channel
+	// fees are adjusted.
+	DefaultFeeManagerInterval = time.Hour
+
+	// DefaultFeeManagerMinUpdateInterval is the default minimum time
+	// between two fee updates of the same channel.
+	DefaultFeeManagerMinUpdateInterval = 6 * time.Hour
+
+	// DefaultFeeManagerMinChange is the default minimum relative fee
+	// change for a channel update to be sent.
+	DefaultFeeManagerMinChange = 0.1
+
+	// DefaultFeeManagerMinFeeRate is the default lowest fee rate in ppm
+	// set by the fee manager.
+	DefaultFeeManagerMinFeeRate = 1
+
+	// DefaultFeeManagerMaxFeeRate is the default highest fee rate in ppm
+	// set by the fee manager.
+	DefaultFeeManagerMaxFeeRate = 2000
+
+	// DefaultFeeManagerMaxBaseFee is the default highest base fee in msat
+	// set by the fee manager.
+	DefaultFeeManagerMaxBaseFee = 1000
+
+	// DefaultFeeManagerVelocityWindow is the default period of the
+	// forwarding history the fee strategies are based on.
+	DefaultFeeManagerVelocityWindow = 24 * time.Hour
+
+	// DefaultFeeManagerVelocityTarget is the default fraction of the
+	// channel capacity expected to be forwarded during the velocity
+	// window.
+	DefaultFeeManagerVelocityTarget = 0.1
+)
+
+// FeeManager holds the configuration options for the automatic adjustment of
+// channel fees.
+//
+//nolint:lll
+type FeeManager struct {
+	Active            bool          `long:"active" description:"If the fee manager should periodically adjust the forwarding fees of our channels."`
+	Strategy          string        `long:"strategy" description:"The strategy used to adjust fees. 'liquidity' sets fees on a curve of the local balance ratio of a channel, 'velocity' raises the fees of channels forwarding more than the target volume and lowers the fees of idle channels." choice:"liquidity" choice:"velocity"`
+	Interval          time.Duration `long:"interval" description:"How often channel fees are adjusted."`
+	MinUpdateInterval time.Duration `long:"min-update-interval" description:"The minimum time between two fee updates of the same channel, to avoid spamming the network with channel updates."`
+	MinChange         float64       `long:"min-change" description:"The minimum relative change of the fee rate or base fee of a channel for its fees to be updated."`
+	MinFeeRate        uint32        `long:"min-fee-rate" description:"The lowest fee rate in parts per million set by the fee manager."`
+	MaxFeeRate        uint32        `long:"max-fee-rate" description:"The highest fee rate in parts per million set by the fee manager."`
+	MinBaseFee        uint64        `long:"min-base-fee-msat" description:"The lowest base fee in milli-satoshis set by the fee manager."`
+	MaxBaseFee        uint64        `long:"max-base-fee-msat" description:"The highest base fee in milli-satoshis set by the fee manager."`
+	VelocityWindow    time.Duration `long:"velocity-window" description:"The period of the forwarding history the fee strategies are based on."`
+	VelocityTarget    float64       `long:"velocity-target" description:"The fraction of the capacity of a channel that the velocity strategy expects to be forwarded out through it during the velocity window."`
+}
+
+// Validate checks the values configured for the fee manager.
+func (f *FeeManager) Validate() error {
+	if f.Interval <= 0 {
+		return fmt.Errorf("interval must be positive")
+	}
+
+	if f.MinUpdateInterval < 0 {
+		return fmt.Errorf("min-update-interval must be non-negative")
+	}
+
+	if f.MinChange < 0 {
+		return fmt.Errorf("min-change must be non-negative")
+	}
+
+	if f.MinFeeRate > f.MaxFeeRate {
+		return fmt.Errorf("min-fee-rate must not exceed max-fee-rate")
+	}
+
+	if f.MinBaseFee > f.MaxBaseFee {
+		return fmt.Errorf("min-base-fee-msat must not exceed " +
+			"max-base-fee-msat")
+	}
+
+	if f.VelocityWindow <= 0 {
+		return fmt.Errorf("velocity-window must be positive")
+	}
+
+	if f.VelocityTarget <= 0 {
+		return fmt.Errorf("velocity-target must be positive")
+	}
+
+	return nil
+}
@@ -13,9 +13,70 @@ var (
 	MaxMailboxDeliveryTimeout = 2 * time.Minute
 )
 
+const (
+	// EndorsementOff disables the experimental HTLC endorsement.
+	EndorsementOff = "off"
+
+	// EndorsementRecord only records the decisions of the experimental
+	// HTLC endorsement without failing any HTLCs.
+	EndorsementRecord = "record"
+
+	// EndorsementEnforce fails HTLCs that exceed the general resources of
+	// the outgoing channel.
+	EndorsementEnforce = "enforce"
+
+	// DefaultRevenueWindow is the default period over which the fees
+	// earned by an outgoing channel are tracked.
+	DefaultRevenueWindow = 14 * 24 * time.Hour
+
+	// DefaultReputationMultiplier is the default number of revenue
+	// windows over which the reputation of an incoming channel is
+	// tracked.
+	DefaultReputationMultiplier = 12
+
+	// DefaultResolutionPeriod is the default time within which an HTLC is
+	// expected to be resolved.
+	DefaultResolutionPeriod = 90 * time.Second
+
+	// DefaultProtectedShare is the default fraction of the resources of a
+	// channel reserved for endorsed HTLCs from reputable peers.
+	DefaultProtectedShare = 0.5
+
+	// DefaultFailRatioWindow is the default period over which the fail
+	// ratio of a peer or channel is measured.
+	DefaultFailRatioWindow = 10 * time.Minute
+
+	// DefaultFailRatioMinHTLCs is the default number of HTLCs that must
+	// have been resolved before the fail ratio limit is enforced.
+	DefaultFailRatioMinHTLCs = 20
+)
+
 //nolint:lll
 type Htlcswitch struct {
 	MailboxDeliveryTimeout time.Duration `long:"mailboxdeliverytimeout" description:"The timeout value when delivering HTLCs to a channel link. Setting this value too small will result in local payment failures if large number of payments are sent over a short period."`
+
+	Endorsement          string        `long:"endorsement" description:"EXPERIMENTAL: The mode of the HTLC endorsement and local reputation policy to mitigate channel jamming. 'record' only records the decisions, 'enforce' fails HTLCs that are not endorsed by a reputable peer once the general resources of the outgoing channel are exhausted." choice:"off" choice:"record" choice:"enforce"`
+	RevenueWindow        time.Duration `long:"revenue-window" description:"The period over which the fees earned by an outgoing channel are tracked."`
+	ReputationMultiplier int           `long:"reputation-multiplier" description:"The number of revenue windows over which the reputation of an incoming channel is tracked."`
+	ResolutionPeriod     time.Duration `long:"resolution-period" description:"The time within which an HTLC is expected to be resolved. Every additional period an HTLC is held costs the incoming channel reputation."`
+	ProtectedShare       float64       `long:"protected-share" description:"The fraction of the HTLC slots and liquidity of a channel that is reserved for endorsed HTLCs from peers with a good reputation."`
+
+	PeerMaxAddRate         float64       `long:"peer-max-add-rate" description:"The maximum number of HTLCs per second a peer may add across all its channels before further HTLCs are failed. 0 disables the limit."`
+	PeerMaxInFlightMsat    uint64        `long:"peer-max-inflight-msat" description:"The maximum value of HTLCs a peer may have in flight through our node across all its channels. 0 disables the limit."`
+	PeerMaxFailRatio       float64       `long:"peer-max-fail-ratio" description:"The maximum ratio of HTLCs forwarded from a peer that may fail before further HTLCs are failed. 0 disables the limit."`
+	ChannelMaxAddRate      float64       `long:"channel-max-add-rate" description:"The maximum number of HTLCs per second that may be added on a channel before further HTLCs are failed. 0 disables the limit."`
+	ChannelMaxInFlightMsat uint64        `long:"channel-max-inflight-msat" description:"The maximum value of HTLCs arriving through a channel that may be in flight through our node. 0 disables the limit."`
+	ChannelMaxFailRatio    float64       `long:"channel-max-fail-ratio" description:"The maximum ratio of HTLCs forwarded from a channel that may fail before further HTLCs are failed. 0 disables the limit."`
+	FailRatioWindow        time.Duration `long:"fail-ratio-window" description:"The period over which the fail ratio of a peer or channel is measured."`
+	FailRatioMinHTLCs      int           `long:"fail-ratio-min-htlcs" description:"The number of HTLCs that must have been resolved within the fail ratio window before the fail ratio limits are enforced."`
+}
+
+// RateLimitsActive returns whether any of the per-peer or per-channel HTLC
+// limits is configured.
+func (h *Htlcswitch) RateLimitsActive() bool {
+	return h.PeerMaxAddRate > 0 || h.PeerMaxInFlightMsat > 0 ||
+		h.PeerMaxFailRatio > 0 || h.ChannelMaxAddRate > 0 ||
+		h.ChannelMaxInFlightMsat > 0 || h.ChannelMaxFailRatio > 0
 }
 
 // Validate checks the values configured for htlcswitch.
@@ -30,5 +91,46 @@ func (h *Htlcswitch) Validate() error {
 			MaxMailboxDeliveryTimeout)
 	}
 
+	if h.PeerMaxAddRate < 0 || h.ChannelMaxAddRate < 0 {
+		return fmt.Errorf("max-add-rate must be non-negative")
+	}
+
+	if h.PeerMaxFailRatio < 0 || h.PeerMaxFailRatio > 1 ||
+		h.ChannelMaxFailRatio < 0 || h.ChannelMaxFailRatio > 1 {
+
+		return fmt.Errorf("max-fail-ratio must be in [0, 1]")
+	}
+
+	if h.FailRatioWindow <= 0 {
+		return fmt.Errorf("fail-ratio-window must be positive")
+	}
+
+	// Without a minimum number of HTLCs, a peer or channel that exceeded
+	// the fail ratio would be limited forever, since the ratio doesn't
+	// change while its resolved HTLCs decay.
+	if h.FailRatioMinHTLCs < 1 {
+		return fmt.Errorf("fail-ratio-min-htlcs must be at least 1")
+	}
+
+	if h.Endorsement == EndorsementOff {
+		return nil
+	}
+
+	if h.RevenueWindow <= 0 {
+		return fmt.Errorf("revenue-window must be positive")
+	}
+
+	if h.ReputationMultiplier <= 0 {
+		return fmt.Errorf("reputation-multiplier must be positive")
+	}
+
+	if h.ResolutionPeriod <= 0 {
+		return fmt.Errorf("resolution-period must be positive")
+	}
+
+	if h.ProtectedShare < 0 || h.ProtectedShare >= 1 {
+		return fmt.Errorf("protected-share must be in [0, 1)")
+	}
+
 	return nil
 }
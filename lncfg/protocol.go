@@ -26,6 +26,16 @@ type ProtocolOptions struct {
 	// experimental simple taproot chans commitment type.
 	TaprootChans bool `long:"simple-taproot-chans" description:"if set, then lnd will create and accept requests for channels using the simple taproot commitment type"`
 
+	// ZeroFeeCommitments should be set if we want to enable support for
+	// the experimental zero-fee commitment type with ephemeral anchors.
+	// This requires a backend that supports package relay.
+	ZeroFeeCommitments bool `long:"zero-fee-commitments" description:"if set, then lnd will create and accept requests for channels using zero-fee commitments with a single ephemeral anchor, which requires a bitcoind backend with package relay support"`
+
+	// DynamicCommitments should be set if we want to enable support for
+	// the experimental dynamic commitment negotiation that allows changing
+	// the parameters and commitment type of a channel without closing it.
+	DynamicCommitments bool `long:"dynamic-commitments" description:"if set, then lnd will propose and accept changes to the parameters and commitment type of existing channels through dynamic commitment negotiations"`
+
 	// NoAnchors should be set if we don't want to support opening or accepting
 	// channels having the anchor commitment type.
 	NoAnchors bool `long:"no-anchors" description:"disable support for anchor commitments"`
@@ -0,0 +1,67 @@
+package lncfg
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultWebhookMaxAttempts is the default number of times the
+	// delivery of an event to a webhook endpoint is attempted before it is
+	// moved to the dead-letter queue.
+	DefaultWebhookMaxAttempts = 8
+
+	// DefaultWebhookInitialBackoff is the default time waited before the
+	// first retry of a failed delivery. The backoff doubles with every
+	// attempt.
+	DefaultWebhookInitialBackoff = 10 * time.Second
+
+	// DefaultWebhookMaxBackoff is the default upper bound of the time
+	// waited between two delivery attempts.
+	DefaultWebhookMaxBackoff = time.Hour
+
+	// DefaultWebhookTimeout is the default timeout of a single delivery
+	// attempt.
+	DefaultWebhookTimeout = 10 * time.Second
+
+	// DefaultWebhookDeliveryHistory is the default number of successful
+	// deliveries kept in the database for inspection.
+	DefaultWebhookDeliveryHistory = 1000
+)
+
+// Webhook holds the configuration options for the delivery of node events to
+// HTTP endpoints.
+//
+//nolint:lll
+type Webhook struct {
+	Active          bool          `long:"active" description:"If node events should be delivered to the registered webhook endpoints."`
+	Endpoints       []string      `long:"endpoint" description:"A webhook endpoint that node events are delivered to, in the form [<event>,<event>,...@]<url>. Without an event list, all events are delivered. Valid events are invoice_settled, payment_succeeded, payment_failed, channel_opened, channel_closed, htlc_failed and backup_changed. Can be specified multiple times."`
+	Secret          string        `long:"secret" description:"The secret used to sign the payloads delivered to the endpoints configured in lnd.conf. If not set, a random secret is generated for each endpoint, which can be looked up with lncli listwebhooks."`
+	MaxAttempts     uint32        `long:"max-attempts" description:"The number of times the delivery of an event is attempted before it is moved to the dead-letter queue."`
+	InitialBackoff  time.Duration `long:"initial-backoff" description:"The time waited before the first retry of a failed delivery. The backoff doubles with every attempt."`
+	MaxBackoff      time.Duration `long:"max-backoff" description:"The maximum time waited between two delivery attempts."`
+	Timeout         time.Duration `long:"timeout" description:"The timeout of a single delivery attempt."`
+	DeliveryHistory uint32        `long:"delivery-history" description:"The number of successful deliveries kept in the database for inspection."`
+}
+
+// Validate checks the values configured for the webhook deliveries.
+func (w *Webhook) Validate() error {
+	if w.MaxAttempts == 0 {
+		return fmt.Errorf("max-attempts must be positive")
+	}
+
+	if w.InitialBackoff <= 0 {
+		return fmt.Errorf("initial-backoff must be positive")
+	}
+
+	if w.MaxBackoff < w.InitialBackoff {
+		return fmt.Errorf("max-backoff must not be lower than " +
+			"initial-backoff")
+	}
+
+	if w.Timeout <= 0 {
+		return fmt.Errorf("timeout must be positive")
+	}
+
+	return nil
+}
@@ -0,0 +1,499 @@
+//go:build accountsrpc
+// +build accountsrpc
+
+package accountsrpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/lightningnetwork/lnd/accounts"
+	"github.com/lightningnetwork/lnd/lnrpc"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/macaroons"
+	"google.golang.org/grpc"
+	"gopkg.in/macaroon-bakery.v2/bakery"
+)
+
+const (
+	// subServerName is the name of the sub rpc server. We'll use this name
+	// to register ourselves, and we also require that the main
+	// SubServerConfigDispatcher instance recognize it as the name of our
+	// RPC service.
+	subServerName = "AccountsRPC"
+)
+
+var (
+	// macPermissions maps RPC calls to the permissions they require. Since
+	// creating an account bakes a new macaroon, the same permissions as
+	// for the macaroon RPCs of the main RPC server are required.
+	macPermissions = map[string][]bakery.Op{
+		"/accountsrpc.Accounts/CreateAccount": {{
+			Entity: "macaroon",
+			Action: "generate",
+		}},
+		"/accountsrpc.Accounts/UpdateAccount": {{
+			Entity: "macaroon",
+			Action: "write",
+		}},
+		"/accountsrpc.Accounts/ListAccounts": {{
+			Entity: "macaroon",
+			Action: "read",
+		}},
+		"/accountsrpc.Accounts/AccountInfo": {{
+			Entity: "macaroon",
+			Action: "read",
+		}},
+		"/accountsrpc.Accounts/RemoveAccount": {{
+			Entity: "macaroon",
+			Action: "write",
+		}},
+	}
+
+	// accountPermissions are the permissions of the macaroons that are
+	// bound to an account. The account interceptor further restricts the
+	// calls to the ones that only use the balance, invoices and payments
+	// of the account.
+	accountPermissions = []bakery.Op{
+		{
+			Entity: "info",
+			Action: "read",
+		},
+		{
+			Entity: "offchain",
+			Action: "read",
+		},
+		{
+			Entity: "offchain",
+			Action: "write",
+		},
+		{
+			Entity: "invoices",
+			Action: "read",
+		},
+		{
+			Entity: "invoices",
+			Action: "write",
+		},
+	}
+
+	// ErrAccountsInactive is returned when an account is managed while
+	// lnd doesn't use native SQL tables.
+	ErrAccountsInactive = errors.New("accounts are not available, set " +
+		"db.use-native-sql to enable them")
+)
+
+// ServerShell is a shell struct holding a reference to the actual sub-server.
+// It is used to register the gRPC sub-server with the root server before we
+// have the necessary dependencies to populate the actual sub-server.
+type ServerShell struct {
+	AccountsServer
+}
+
+// Server is a sub-server of the main RPC server: the accounts RPC. This sub
+// RPC server manages the virtual accounts of the node.
+type Server struct {
+	started  int32 // To be used atomically.
+	shutdown int32 // To be used atomically.
+
+	// Required by the grpc-gateway/v2 library for forward compatibility.
+	// Must be after the atomically used variables to not break struct
+	// alignment.
+	UnimplementedAccountsServer
+
+	cfg *Config
+}
+
+// A compile time check to ensure that Server fully implements the
+// AccountsServer gRPC service.
+var _ AccountsServer = (*Server)(nil)
+
+// New returns a new instance of the accountsrpc Accounts sub-server. We also
+// return the set of permissions for the macaroons that we may create within
+// this method.
+func New(cfg *Config) (*Server, lnrpc.MacaroonPerms, error) {
+	server := &Server{
+		cfg: cfg,
+	}
+
+	return server, macPermissions, nil
+}
+
+// Start launches any helper goroutines required for the Server to function.
+//
+// NOTE: This is part of the lnrpc.SubServer interface.
+func (s *Server) Start() error {
+	if atomic.AddInt32(&s.started, 1) != 1 {
+		return nil
+	}
+
+	if s.cfg.Store == nil {
+		log.Infof("Native SQL tables disabled, accounts not available")
+	}
+
+	return nil
+}
+
+// Stop signals any active goroutines for a graceful closure.
+//
+// NOTE: This is part of the lnrpc.SubServer interface.
+func (s *Server) Stop() error {
+	atomic.AddInt32(&s.shutdown, 1)
+
+	return nil
+}
+
+// Name returns a unique string representation of the sub-server. This can be
+// used to identify the sub-server and also de-duplicate them.
+//
+// NOTE: This is part of the lnrpc.SubServer interface.
+func (s *Server) Name() string {
+	return subServerName
+}
+
+// RegisterWithRootServer will be called by the root gRPC server to direct a
+// sub RPC server to register itself with the main gRPC root server. Until this
+// is called, each sub-server won't be able to have requests routed towards it.
+//
+// NOTE: This is part of the lnrpc.GrpcHandler interface.
+func (r *ServerShell) RegisterWithRootServer(grpcServer *grpc.Server) error {
+	// We make sure that we register it with the main gRPC server to ensure
+	// all our methods are routed properly.
+	RegisterAccountsServer(grpcServer, r)
+
+	log.Debugf("Accounts RPC server successfully registered with root " +
+		"gRPC server")
+
+	return nil
+}
+
+// RegisterWithRestServer will be called by the root REST mux to direct a sub
+// RPC server to register itself with the main REST mux server. Until this is
+// called, each sub-server won't be able to have requests routed towards it.
+//
+// NOTE: This is part of the lnrpc.GrpcHandler interface.
+func (r *ServerShell) RegisterWithRestServer(ctx context.Context,
+	mux *runtime.ServeMux, dest string, opts []grpc.DialOption) error {
+
+	// We make sure that we register it with the main REST server to ensure
+	// all our methods are routed properly.
+	err := RegisterAccountsHandlerFromEndpoint(ctx, mux, dest, opts)
+	if err != nil {
+		log.Errorf("Could not register Accounts REST server with root "+
+			"REST server: %v", err)
+		return err
+	}
+
+	log.Debugf("Accounts REST server successfully registered with root " +
+		"REST server")
+	return nil
+}
+
+// CreateSubServer populates the subserver's dependencies using the passed
+// SubServerConfigDispatcher. This method should fully initialize the
+// sub-server instance, making it ready for action. It returns the macaroon
+// permissions that the sub-server wishes to pass on to the root server for all
+// methods routed towards it.
+//
+// NOTE: This is part of the lnrpc.GrpcHandler interface.
+func (r *ServerShell) CreateSubServer(configRegistry lnrpc.SubServerConfigDispatcher) (
+	lnrpc.SubServer, lnrpc.MacaroonPerms, error) {
+
+	subServer, macPermissions, err := createNewSubServer(configRegistry)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r.AccountsServer = subServer
+	return subServer, macPermissions, nil
+}
+
+// CreateAccount creates a new account with the given balance and expiration
+// date and returns a macaroon that is bound to it.
+func (s *Server) CreateAccount(ctx context.Context,
+	req *CreateAccountRequest) (*CreateAccountResponse, error) {
+
+	if s.cfg.Store == nil {
+		return nil, ErrAccountsInactive
+	}
+
+	// Accounts are enforced by the macaroon interceptor, so they can't be
+	// used without macaroons.
+	if s.cfg.MacService == nil {
+		return nil, fmt.Errorf("accounts require macaroons to be " +
+			"enabled")
+	}
+
+	if req.ExpirationDate < 0 {
+		return nil, fmt.Errorf("invalid expiration date %v",
+			req.ExpirationDate)
+	}
+
+	var expirationDate time.Time
+	if req.ExpirationDate > 0 {
+		expirationDate = time.Unix(req.ExpirationDate, 0)
+	}
+
+	account, err := s.cfg.Store.NewAccount(
+		ctx, lnwire.MilliSatoshi(req.AccountBalanceMsat),
+		expirationDate, req.Label,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	mac, err := s.bakeAccountMacaroon(ctx, account.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Created account %v with a balance of %v", account.ID,
+		account.CurrentBalance)
+
+	return &CreateAccountResponse{
+		Account:  marshalAccount(account),
+		Macaroon: mac,
+	}, nil
+}
+
+// bakeAccountMacaroon bakes a macaroon that is bound to the given account.
+func (s *Server) bakeAccountMacaroon(ctx context.Context,
+	id accounts.AccountID) ([]byte, error) {
+
+	bakedMac, err := s.cfg.MacService.NewMacaroon(
+		ctx, macaroons.DefaultRootKeyID, accountPermissions...,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	mac, err := macaroons.AddConstraints(
+		bakedMac.M(), macaroons.AccountConstraint(id),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return mac.MarshalBinary()
+}
+
+// UpdateAccount sets the balance and/or the expiration date of an account.
+func (s *Server) UpdateAccount(ctx context.Context,
+	req *UpdateAccountRequest) (*Account, error) {
+
+	if s.cfg.Store == nil {
+		return nil, ErrAccountsInactive
+	}
+
+	account, err := s.findAccount(ctx, req.Id, req.Label)
+	if err != nil {
+		return nil, err
+	}
+
+	var balance *lnwire.MilliSatoshi
+	switch {
+	case req.AccountBalanceMsat >= 0:
+		amt := lnwire.MilliSatoshi(req.AccountBalanceMsat)
+		balance = &amt
+
+	case req.AccountBalanceMsat != -1:
+		return nil, fmt.Errorf("invalid account balance %v",
+			req.AccountBalanceMsat)
+	}
+
+	var expirationDate *time.Time
+	switch {
+	case req.ExpirationDate > 0:
+		date := time.Unix(req.ExpirationDate, 0)
+		expirationDate = &date
+
+	case req.ExpirationDate == 0:
+		expirationDate = &time.Time{}
+
+	case req.ExpirationDate != -1:
+		return nil, fmt.Errorf("invalid expiration date %v",
+			req.ExpirationDate)
+	}
+
+	account, err = s.cfg.Store.UpdateAccount(
+		ctx, account.ID, balance, expirationDate,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return marshalAccount(account), nil
+}
+
+// ListAccounts returns all accounts.
+func (s *Server) ListAccounts(ctx context.Context,
+	_ *ListAccountsRequest) (*ListAccountsResponse, error) {
+
+	if s.cfg.Store == nil {
+		return nil, ErrAccountsInactive
+	}
+
+	accts, err := s.cfg.Store.Accounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ListAccountsResponse{
+		Accounts: make([]*Account, 0, len(accts)),
+	}
+	for _, account := range accts {
+		resp.Accounts = append(resp.Accounts, marshalAccount(account))
+	}
+
+	return resp, nil
+}
+
+// AccountInfo returns a single account together with its ledger.
+func (s *Server) AccountInfo(ctx context.Context,
+	req *AccountInfoRequest) (*AccountInfoResponse, error) {
+
+	if s.cfg.Store == nil {
+		return nil, ErrAccountsInactive
+	}
+
+	account, err := s.findAccount(ctx, req.Id, req.Label)
+	if err != nil {
+		return nil, err
+	}
+
+	ledger, err := s.cfg.Store.Ledger(ctx, account.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &AccountInfoResponse{
+		Account: marshalAccount(account),
+		Ledger:  make([]*LedgerEntry, 0, len(ledger)),
+	}
+	for _, entry := range ledger {
+		rpcEntry := &LedgerEntry{
+			Type:        entry.Type.String(),
+			AmountMsat:  entry.Amount,
+			BalanceMsat: uint64(entry.Balance),
+			Timestamp:   entry.Timestamp.Unix(),
+		}
+		if entry.Hash != nil {
+			rpcEntry.Hash = entry.Hash[:]
+		}
+
+		resp.Ledger = append(resp.Ledger, rpcEntry)
+	}
+
+	return resp, nil
+}
+
+// RemoveAccount removes an account.
+func (s *Server) RemoveAccount(ctx context.Context,
+	req *RemoveAccountRequest) (*RemoveAccountResponse, error) {
+
+	if s.cfg.Store == nil {
+		return nil, ErrAccountsInactive
+	}
+
+	account, err := s.findAccount(ctx, req.Id, req.Label)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.cfg.Store.RemoveAccount(ctx, account.ID); err != nil {
+		return nil, err
+	}
+
+	log.Infof("Removed account %v", account.ID)
+
+	return &RemoveAccountResponse{}, nil
+}
+
+// findAccount returns the account with the given hex encoded ID or, if no ID
+// is given, with the given label.
+func (s *Server) findAccount(ctx context.Context, id,
+	label string) (*accounts.Account, error) {
+
+	switch {
+	case id != "" && label != "":
+		return nil, fmt.Errorf("either an account ID or a label must " +
+			"be given, not both")
+
+	case id != "":
+		accountID, err := accounts.ParseAccountID(id)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.cfg.Store.Account(ctx, accountID)
+
+	case label != "":
+		return s.cfg.Store.AccountByLabel(ctx, label)
+
+	default:
+		return nil, fmt.Errorf("account ID or label required")
+	}
+}
+
+// marshalAccount converts an account into its RPC representation.
+func marshalAccount(account *accounts.Account) *Account {
+	rpcAccount := &Account{
+		Id:                   account.ID.String(),
+		Label:                account.Label,
+		InitialBalanceMsat:   uint64(account.InitialBalance),
+		CurrentBalanceMsat:   uint64(account.CurrentBalance),
+		AvailableBalanceMsat: uint64(account.AvailableBalance()),
+		LastUpdate:           account.LastUpdate.Unix(),
+		CreatedAt:            account.CreatedAt.Unix(),
+		Invoices: make(
+			[]*AccountInvoice, 0, len(account.Invoices),
+		),
+		Payments: make(
+			[]*AccountPayment, 0, len(account.Payments),
+		),
+	}
+	if !account.ExpirationDate.IsZero() {
+		rpcAccount.ExpirationDate = account.ExpirationDate.Unix()
+	}
+
+	for hash := range account.Invoices {
+		hash := hash
+		rpcAccount.Invoices = append(
+			rpcAccount.Invoices, &AccountInvoice{Hash: hash[:]},
+		)
+	}
+
+	for hash, payment := range account.Payments {
+		hash := hash
+		rpcAccount.Payments = append(
+			rpcAccount.Payments, &AccountPayment{
+				Hash:           hash[:],
+				State:          payment.Status.String(),
+				FullAmountMsat: uint64(payment.FullAmount),
+			},
+		)
+	}
+
+	// Map iteration is random, so we sort the hashes to get a stable
+	// output.
+	sort.Slice(rpcAccount.Invoices, func(i, j int) bool {
+		return bytes.Compare(
+			rpcAccount.Invoices[i].Hash,
+			rpcAccount.Invoices[j].Hash,
+		) < 0
+	})
+	sort.Slice(rpcAccount.Payments, func(i, j int) bool {
+		return bytes.Compare(
+			rpcAccount.Payments[i].Hash,
+			rpcAccount.Payments[j].Hash,
+		) < 0
+	})
+
+	return rpcAccount
+}
@@ -0,0 +1,25 @@
+//go:build accountsrpc
+// +build accountsrpc
+
+package accountsrpc
+
+import (
+	"github.com/lightningnetwork/lnd/accounts"
+	"github.com/lightningnetwork/lnd/macaroons"
+)
+
+// Config is the primary configuration struct for the accounts RPC subserver.
+// It contains all the items required for the server to carry out its duties.
+// The fields with struct tags are meant to be parsed as normal configuration
+// options, while if able to be populated, the latter fields MUST also be
+// specified.
+type Config struct {
+	// Store is the persistent storage of the accounts. It is nil if lnd
+	// doesn't use native SQL tables, in which case accounts are not
+	// available.
+	Store accounts.Store
+
+	// MacService is the main macaroon service that we use to bake the
+	// macaroons that are bound to the accounts.
+	MacService *macaroons.Service
+}
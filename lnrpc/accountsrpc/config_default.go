@@ -0,0 +1,7 @@
+//go:build !accountsrpc
+// +build !accountsrpc
+
+package accountsrpc
+
+// Config is empty for non-accountsrpc builds.
+type Config struct{}
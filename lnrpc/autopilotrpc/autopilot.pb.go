@@ -20,6 +20,58 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type PolicyActionType int32
+
+const (
+	// A cooperative channel close.
+	PolicyActionType_CLOSE PolicyActionType = 0
+	// A unilateral channel close.
+	PolicyActionType_FORCE_CLOSE PolicyActionType = 1
+	// A circular payment moving funds between two channels.
+	PolicyActionType_REBALANCE PolicyActionType = 2
+)
+
+// Enum value maps for PolicyActionType.
+var (
+	PolicyActionType_name = map[int32]string{
+		0: "CLOSE",
+		1: "FORCE_CLOSE",
+		2: "REBALANCE",
+	}
+	PolicyActionType_value = map[string]int32{
+		"CLOSE":       0,
+		"FORCE_CLOSE": 1,
+		"REBALANCE":   2,
+	}
+)
+
+func (x PolicyActionType) Enum() *PolicyActionType {
+	p := new(PolicyActionType)
+	*p = x
+	return p
+}
+
+func (x PolicyActionType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PolicyActionType) Descriptor() protoreflect.EnumDescriptor {
+	return file_autopilotrpc_autopilot_proto_enumTypes[0].Descriptor()
+}
+
+func (PolicyActionType) Type() protoreflect.EnumType {
+	return &file_autopilotrpc_autopilot_proto_enumTypes[0]
+}
+
+func (x PolicyActionType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PolicyActionType.Descriptor instead.
+func (PolicyActionType) EnumDescriptor() ([]byte, []int) {
+	return file_autopilotrpc_autopilot_proto_rawDescGZIP(), []int{0}
+}
+
 type StatusRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -65,6 +117,11 @@ type StatusResponse struct {
 
 	// Indicates whether the autopilot is active or not.
 	Active bool `protobuf:"varint,1,opt,name=active,proto3" json:"active,omitempty"`
+	// The policies the autopilot uses to manage its existing channels.
+	Policies *ChannelPolicies `protobuf:"bytes,2,opt,name=policies,proto3" json:"policies,omitempty"`
+	// The most recent actions the active autopilot agent took to enforce its
+	// channel policies, oldest first.
+	RecentActions []*PolicyAction `protobuf:"bytes,3,rep,name=recent_actions,json=recentActions,proto3" json:"recent_actions,omitempty"`
 }
 
 func (x *StatusResponse) Reset() {
@@ -106,6 +163,230 @@ func (x *StatusResponse) GetActive() bool {
 	return false
 }
 
+func (x *StatusResponse) GetPolicies() *ChannelPolicies {
+	if x != nil {
+		return x.Policies
+	}
+	return nil
+}
+
+func (x *StatusResponse) GetRecentActions() []*PolicyAction {
+	if x != nil {
+		return x.RecentActions
+	}
+	return nil
+}
+
+type ChannelPolicies struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// How often the policies are evaluated, in seconds. If zero, the autopilot
+	// doesn't manage its existing channels.
+	IntervalSec uint64 `protobuf:"varint,1,opt,name=interval_sec,json=intervalSec,proto3" json:"interval_sec,omitempty"`
+	// The number of seconds after which a channel whose peer stayed offline is
+	// force closed. Zero if inactive channels are never closed.
+	CloseInactiveAfterSec uint64 `protobuf:"varint,2,opt,name=close_inactive_after_sec,json=closeInactiveAfterSec,proto3" json:"close_inactive_after_sec,omitempty"`
+	// The number of seconds after which a channel that stayed unbalanced is
+	// cooperatively closed. Zero if unbalanced channels are never closed.
+	CloseUnbalancedAfterSec uint64 `protobuf:"varint,3,opt,name=close_unbalanced_after_sec,json=closeUnbalancedAfterSec,proto3" json:"close_unbalanced_after_sec,omitempty"`
+	// The fraction of the capacity below which the local or remote balance of a
+	// channel is considered unbalanced.
+	UnbalancedRatio float64 `protobuf:"fixed64,4,opt,name=unbalanced_ratio,json=unbalancedRatio,proto3" json:"unbalanced_ratio,omitempty"`
+	// The maximum number of channels closed per evaluation.
+	MaxCloses uint32 `protobuf:"varint,5,opt,name=max_closes,json=maxCloses,proto3" json:"max_closes,omitempty"`
+	// The fraction of the capacity below which the local balance of a channel is
+	// refilled by a circular payment. Zero if rebalancing is disabled.
+	RebalanceRatio float64 `protobuf:"fixed64,6,opt,name=rebalance_ratio,json=rebalanceRatio,proto3" json:"rebalance_ratio,omitempty"`
+	// The maximum fee rate in parts per million paid for rebalancing.
+	RebalanceMaxFeeRate uint32 `protobuf:"varint,7,opt,name=rebalance_max_fee_rate,json=rebalanceMaxFeeRate,proto3" json:"rebalance_max_fee_rate,omitempty"`
+}
+
+func (x *ChannelPolicies) Reset() {
+	*x = ChannelPolicies{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_autopilotrpc_autopilot_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ChannelPolicies) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChannelPolicies) ProtoMessage() {}
+
+func (x *ChannelPolicies) ProtoReflect() protoreflect.Message {
+	mi := &file_autopilotrpc_autopilot_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChannelPolicies.ProtoReflect.Descriptor instead.
+func (*ChannelPolicies) Descriptor() ([]byte, []int) {
+	return file_autopilotrpc_autopilot_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ChannelPolicies) GetIntervalSec() uint64 {
+	if x != nil {
+		return x.IntervalSec
+	}
+	return 0
+}
+
+func (x *ChannelPolicies) GetCloseInactiveAfterSec() uint64 {
+	if x != nil {
+		return x.CloseInactiveAfterSec
+	}
+	return 0
+}
+
+func (x *ChannelPolicies) GetCloseUnbalancedAfterSec() uint64 {
+	if x != nil {
+		return x.CloseUnbalancedAfterSec
+	}
+	return 0
+}
+
+func (x *ChannelPolicies) GetUnbalancedRatio() float64 {
+	if x != nil {
+		return x.UnbalancedRatio
+	}
+	return 0
+}
+
+func (x *ChannelPolicies) GetMaxCloses() uint32 {
+	if x != nil {
+		return x.MaxCloses
+	}
+	return 0
+}
+
+func (x *ChannelPolicies) GetRebalanceRatio() float64 {
+	if x != nil {
+		return x.RebalanceRatio
+	}
+	return 0
+}
+
+func (x *ChannelPolicies) GetRebalanceMaxFeeRate() uint32 {
+	if x != nil {
+		return x.RebalanceMaxFeeRate
+	}
+	return 0
+}
+
+type PolicyAction struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The type of the action.
+	Type PolicyActionType `protobuf:"varint,1,opt,name=type,proto3,enum=autopilotrpc.PolicyActionType" json:"type,omitempty"`
+	// The unix timestamp in seconds at which the action was taken.
+	Timestamp int64 `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// The channel that was closed, or the channel funds were moved out of for a
+	// rebalance.
+	ChanId uint64 `protobuf:"varint,3,opt,name=chan_id,json=chanId,proto3" json:"chan_id,omitempty"`
+	// The channel funds were moved into for a rebalance.
+	TargetChanId uint64 `protobuf:"varint,4,opt,name=target_chan_id,json=targetChanId,proto3" json:"target_chan_id,omitempty"`
+	// The amount in satoshis moved for a rebalance.
+	AmtSat int64 `protobuf:"varint,5,opt,name=amt_sat,json=amtSat,proto3" json:"amt_sat,omitempty"`
+	// Why the action was taken.
+	Reason string `protobuf:"bytes,6,opt,name=reason,proto3" json:"reason,omitempty"`
+	// The error the action failed with, empty if it succeeded.
+	Error string `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (x *PolicyAction) Reset() {
+	*x = PolicyAction{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_autopilotrpc_autopilot_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PolicyAction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PolicyAction) ProtoMessage() {}
+
+func (x *PolicyAction) ProtoReflect() protoreflect.Message {
+	mi := &file_autopilotrpc_autopilot_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PolicyAction.ProtoReflect.Descriptor instead.
+func (*PolicyAction) Descriptor() ([]byte, []int) {
+	return file_autopilotrpc_autopilot_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PolicyAction) GetType() PolicyActionType {
+	if x != nil {
+		return x.Type
+	}
+	return PolicyActionType_CLOSE
+}
+
+func (x *PolicyAction) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *PolicyAction) GetChanId() uint64 {
+	if x != nil {
+		return x.ChanId
+	}
+	return 0
+}
+
+func (x *PolicyAction) GetTargetChanId() uint64 {
+	if x != nil {
+		return x.TargetChanId
+	}
+	return 0
+}
+
+func (x *PolicyAction) GetAmtSat() int64 {
+	if x != nil {
+		return x.AmtSat
+	}
+	return 0
+}
+
+func (x *PolicyAction) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *PolicyAction) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
 type ModifyStatusRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -118,7 +399,7 @@ type ModifyStatusRequest struct {
 func (x *ModifyStatusRequest) Reset() {
 	*x = ModifyStatusRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_autopilotrpc_autopilot_proto_msgTypes[2]
+		mi := &file_autopilotrpc_autopilot_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -131,7 +412,7 @@ func (x *ModifyStatusRequest) String() string {
 func (*ModifyStatusRequest) ProtoMessage() {}
 
 func (x *ModifyStatusRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_autopilotrpc_autopilot_proto_msgTypes[2]
+	mi := &file_autopilotrpc_autopilot_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -144,7 +425,7 @@ func (x *ModifyStatusRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ModifyStatusRequest.ProtoReflect.Descriptor instead.
 func (*ModifyStatusRequest) Descriptor() ([]byte, []int) {
-	return file_autopilotrpc_autopilot_proto_rawDescGZIP(), []int{2}
+	return file_autopilotrpc_autopilot_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *ModifyStatusRequest) GetEnable() bool {
@@ -163,7 +444,7 @@ type ModifyStatusResponse struct {
 func (x *ModifyStatusResponse) Reset() {
 	*x = ModifyStatusResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_autopilotrpc_autopilot_proto_msgTypes[3]
+		mi := &file_autopilotrpc_autopilot_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -176,7 +457,7 @@ func (x *ModifyStatusResponse) String() string {
 func (*ModifyStatusResponse) ProtoMessage() {}
 
 func (x *ModifyStatusResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_autopilotrpc_autopilot_proto_msgTypes[3]
+	mi := &file_autopilotrpc_autopilot_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -189,7 +470,7 @@ func (x *ModifyStatusResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ModifyStatusResponse.ProtoReflect.Descriptor instead.
 func (*ModifyStatusResponse) Descriptor() ([]byte, []int) {
-	return file_autopilotrpc_autopilot_proto_rawDescGZIP(), []int{3}
+	return file_autopilotrpc_autopilot_proto_rawDescGZIP(), []int{5}
 }
 
 type QueryScoresRequest struct {
@@ -205,7 +486,7 @@ type QueryScoresRequest struct {
 func (x *QueryScoresRequest) Reset() {
 	*x = QueryScoresRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_autopilotrpc_autopilot_proto_msgTypes[4]
+		mi := &file_autopilotrpc_autopilot_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -218,7 +499,7 @@ func (x *QueryScoresRequest) String() string {
 func (*QueryScoresRequest) ProtoMessage() {}
 
 func (x *QueryScoresRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_autopilotrpc_autopilot_proto_msgTypes[4]
+	mi := &file_autopilotrpc_autopilot_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -231,7 +512,7 @@ func (x *QueryScoresRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use QueryScoresRequest.ProtoReflect.Descriptor instead.
 func (*QueryScoresRequest) Descriptor() ([]byte, []int) {
-	return file_autopilotrpc_autopilot_proto_rawDescGZIP(), []int{4}
+	return file_autopilotrpc_autopilot_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *QueryScoresRequest) GetPubkeys() []string {
@@ -259,7 +540,7 @@ type QueryScoresResponse struct {
 func (x *QueryScoresResponse) Reset() {
 	*x = QueryScoresResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_autopilotrpc_autopilot_proto_msgTypes[5]
+		mi := &file_autopilotrpc_autopilot_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -272,7 +553,7 @@ func (x *QueryScoresResponse) String() string {
 func (*QueryScoresResponse) ProtoMessage() {}
 
 func (x *QueryScoresResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_autopilotrpc_autopilot_proto_msgTypes[5]
+	mi := &file_autopilotrpc_autopilot_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -285,7 +566,7 @@ func (x *QueryScoresResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use QueryScoresResponse.ProtoReflect.Descriptor instead.
 func (*QueryScoresResponse) Descriptor() ([]byte, []int) {
-	return file_autopilotrpc_autopilot_proto_rawDescGZIP(), []int{5}
+	return file_autopilotrpc_autopilot_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *QueryScoresResponse) GetResults() []*QueryScoresResponse_HeuristicResult {
@@ -310,7 +591,7 @@ type SetScoresRequest struct {
 func (x *SetScoresRequest) Reset() {
 	*x = SetScoresRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_autopilotrpc_autopilot_proto_msgTypes[6]
+		mi := &file_autopilotrpc_autopilot_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -323,7 +604,7 @@ func (x *SetScoresRequest) String() string {
 func (*SetScoresRequest) ProtoMessage() {}
 
 func (x *SetScoresRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_autopilotrpc_autopilot_proto_msgTypes[6]
+	mi := &file_autopilotrpc_autopilot_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -336,7 +617,7 @@ func (x *SetScoresRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SetScoresRequest.ProtoReflect.Descriptor instead.
 func (*SetScoresRequest) Descriptor() ([]byte, []int) {
-	return file_autopilotrpc_autopilot_proto_rawDescGZIP(), []int{6}
+	return file_autopilotrpc_autopilot_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *SetScoresRequest) GetHeuristic() string {
@@ -362,7 +643,7 @@ type SetScoresResponse struct {
 func (x *SetScoresResponse) Reset() {
 	*x = SetScoresResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_autopilotrpc_autopilot_proto_msgTypes[7]
+		mi := &file_autopilotrpc_autopilot_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -375,7 +656,7 @@ func (x *SetScoresResponse) String() string {
 func (*SetScoresResponse) ProtoMessage() {}
 
 func (x *SetScoresResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_autopilotrpc_autopilot_proto_msgTypes[7]
+	mi := &file_autopilotrpc_autopilot_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -388,7 +669,7 @@ func (x *SetScoresResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SetScoresResponse.ProtoReflect.Descriptor instead.
 func (*SetScoresResponse) Descriptor() ([]byte, []int) {
-	return file_autopilotrpc_autopilot_proto_rawDescGZIP(), []int{7}
+	return file_autopilotrpc_autopilot_proto_rawDescGZIP(), []int{9}
 }
 
 type QueryScoresResponse_HeuristicResult struct {
@@ -403,7 +684,7 @@ type QueryScoresResponse_HeuristicResult struct {
 func (x *QueryScoresResponse_HeuristicResult) Reset() {
 	*x = QueryScoresResponse_HeuristicResult{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_autopilotrpc_autopilot_proto_msgTypes[8]
+		mi := &file_autopilotrpc_autopilot_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -416,7 +697,7 @@ func (x *QueryScoresResponse_HeuristicResult) String() string {
 func (*QueryScoresResponse_HeuristicResult) ProtoMessage() {}
 
 func (x *QueryScoresResponse_HeuristicResult) ProtoReflect() protoreflect.Message {
-	mi := &file_autopilotrpc_autopilot_proto_msgTypes[8]
+	mi := &file_autopilotrpc_autopilot_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -429,7 +710,7 @@ func (x *QueryScoresResponse_HeuristicResult) ProtoReflect() protoreflect.Messag
 
 // Deprecated: Use QueryScoresResponse_HeuristicResult.ProtoReflect.Descriptor instead.
 func (*QueryScoresResponse_HeuristicResult) Descriptor() ([]byte, []int) {
-	return file_autopilotrpc_autopilot_proto_rawDescGZIP(), []int{5, 0}
+	return file_autopilotrpc_autopilot_proto_rawDescGZIP(), []int{7, 0}
 }
 
 func (x *QueryScoresResponse_HeuristicResult) GetHeuristic() string {
@@ -452,76 +733,124 @@ var file_autopilotrpc_autopilot_proto_rawDesc = []byte{
 	0x0a, 0x1c, 0x61, 0x75, 0x74, 0x6f, 0x70, 0x69, 0x6c, 0x6f, 0x74, 0x72, 0x70, 0x63, 0x2f, 0x61,
 	0x75, 0x74, 0x6f, 0x70, 0x69, 0x6c, 0x6f, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0c,
 	0x61, 0x75, 0x74, 0x6f, 0x70, 0x69, 0x6c, 0x6f, 0x74, 0x72, 0x70, 0x63, 0x22, 0x0f, 0x0a, 0x0d,
-	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x28, 0x0a,
-	0x0e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
-	0x16, 0x0a, 0x06, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52,
-	0x06, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65, 0x22, 0x2d, 0x0a, 0x13, 0x4d, 0x6f, 0x64, 0x69, 0x66,
-	0x79, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16,
-	0x0a, 0x06, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06,
-	0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x22, 0x16, 0x0a, 0x14, 0x4d, 0x6f, 0x64, 0x69, 0x66, 0x79,
-	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x5c,
-	0x0a, 0x12, 0x51, 0x75, 0x65, 0x72, 0x79, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x73, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x75, 0x62, 0x6b, 0x65, 0x79, 0x73, 0x18,
-	0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x70, 0x75, 0x62, 0x6b, 0x65, 0x79, 0x73, 0x12, 0x2c,
-	0x0a, 0x12, 0x69, 0x67, 0x6e, 0x6f, 0x72, 0x65, 0x5f, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x5f, 0x73,
-	0x74, 0x61, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x10, 0x69, 0x67, 0x6e, 0x6f,
-	0x72, 0x65, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x53, 0x74, 0x61, 0x74, 0x65, 0x22, 0xa6, 0x02, 0x0a,
-	0x13, 0x51, 0x75, 0x65, 0x72, 0x79, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4b, 0x0a, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x18,
-	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x31, 0x2e, 0x61, 0x75, 0x74, 0x6f, 0x70, 0x69, 0x6c, 0x6f,
-	0x74, 0x72, 0x70, 0x63, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x73,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x48, 0x65, 0x75, 0x72, 0x69, 0x73, 0x74,
-	0x69, 0x63, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74,
-	0x73, 0x1a, 0xc1, 0x01, 0x0a, 0x0f, 0x48, 0x65, 0x75, 0x72, 0x69, 0x73, 0x74, 0x69, 0x63, 0x52,
-	0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x68, 0x65, 0x75, 0x72, 0x69, 0x73, 0x74,
-	0x69, 0x63, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x68, 0x65, 0x75, 0x72, 0x69, 0x73,
-	0x74, 0x69, 0x63, 0x12, 0x55, 0x0a, 0x06, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x73, 0x18, 0x02, 0x20,
-	0x03, 0x28, 0x0b, 0x32, 0x3d, 0x2e, 0x61, 0x75, 0x74, 0x6f, 0x70, 0x69, 0x6c, 0x6f, 0x74, 0x72,
-	0x70, 0x63, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x73, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x48, 0x65, 0x75, 0x72, 0x69, 0x73, 0x74, 0x69, 0x63,
-	0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x2e, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x73, 0x45, 0x6e, 0x74,
-	0x72, 0x79, 0x52, 0x06, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x73, 0x1a, 0x39, 0x0a, 0x0b, 0x53, 0x63,
-	0x6f, 0x72, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76,
-	0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
-	0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0xaf, 0x01, 0x0a, 0x10, 0x53, 0x65, 0x74, 0x53, 0x63, 0x6f,
-	0x72, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x68, 0x65,
+	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0xa6, 0x01,
+	0x0a, 0x0e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x16, 0x0a, 0x06, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x06, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65, 0x12, 0x39, 0x0a, 0x08, 0x70, 0x6f, 0x6c, 0x69,
+	0x63, 0x69, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x61, 0x75, 0x74,
+	0x6f, 0x70, 0x69, 0x6c, 0x6f, 0x74, 0x72, 0x70, 0x63, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65,
+	0x6c, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x69, 0x65, 0x73, 0x52, 0x08, 0x70, 0x6f, 0x6c, 0x69, 0x63,
+	0x69, 0x65, 0x73, 0x12, 0x41, 0x0a, 0x0e, 0x72, 0x65, 0x63, 0x65, 0x6e, 0x74, 0x5f, 0x61, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x61, 0x75,
+	0x74, 0x6f, 0x70, 0x69, 0x6c, 0x6f, 0x74, 0x72, 0x70, 0x63, 0x2e, 0x50, 0x6f, 0x6c, 0x69, 0x63,
+	0x79, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0d, 0x72, 0x65, 0x63, 0x65, 0x6e, 0x74, 0x41,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0xd2, 0x02, 0x0a, 0x0f, 0x43, 0x68, 0x61, 0x6e, 0x6e,
+	0x65, 0x6c, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x69, 0x65, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x69, 0x6e,
+	0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x5f, 0x73, 0x65, 0x63, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x0b, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x53, 0x65, 0x63, 0x12, 0x37, 0x0a,
+	0x18, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x5f, 0x69, 0x6e, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65, 0x5f,
+	0x61, 0x66, 0x74, 0x65, 0x72, 0x5f, 0x73, 0x65, 0x63, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x15, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x49, 0x6e, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65, 0x41, 0x66,
+	0x74, 0x65, 0x72, 0x53, 0x65, 0x63, 0x12, 0x3b, 0x0a, 0x1a, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x5f,
+	0x75, 0x6e, 0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f, 0x61, 0x66, 0x74, 0x65, 0x72,
+	0x5f, 0x73, 0x65, 0x63, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x17, 0x63, 0x6c, 0x6f, 0x73,
+	0x65, 0x55, 0x6e, 0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x41, 0x66, 0x74, 0x65, 0x72,
+	0x53, 0x65, 0x63, 0x12, 0x29, 0x0a, 0x10, 0x75, 0x6e, 0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65,
+	0x64, 0x5f, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0f, 0x75,
+	0x6e, 0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x52, 0x61, 0x74, 0x69, 0x6f, 0x12, 0x1d,
+	0x0a, 0x0a, 0x6d, 0x61, 0x78, 0x5f, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x73, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x09, 0x6d, 0x61, 0x78, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x73, 0x12, 0x27, 0x0a,
+	0x0f, 0x72, 0x65, 0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0e, 0x72, 0x65, 0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63,
+	0x65, 0x52, 0x61, 0x74, 0x69, 0x6f, 0x12, 0x33, 0x0a, 0x16, 0x72, 0x65, 0x62, 0x61, 0x6c, 0x61,
+	0x6e, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x78, 0x5f, 0x66, 0x65, 0x65, 0x5f, 0x72, 0x61, 0x74, 0x65,
+	0x18, 0x07, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x13, 0x72, 0x65, 0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63,
+	0x65, 0x4d, 0x61, 0x78, 0x46, 0x65, 0x65, 0x52, 0x61, 0x74, 0x65, 0x22, 0xee, 0x01, 0x0a, 0x0c,
+	0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x32, 0x0a, 0x04,
+	0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1e, 0x2e, 0x61, 0x75, 0x74,
+	0x6f, 0x70, 0x69, 0x6c, 0x6f, 0x74, 0x72, 0x70, 0x63, 0x2e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79,
+	0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65,
+	0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x1b,
+	0x0a, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x42,
+	0x02, 0x30, 0x01, 0x52, 0x06, 0x63, 0x68, 0x61, 0x6e, 0x49, 0x64, 0x12, 0x28, 0x0a, 0x0e, 0x74,
+	0x61, 0x72, 0x67, 0x65, 0x74, 0x5f, 0x63, 0x68, 0x61, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x04, 0x42, 0x02, 0x30, 0x01, 0x52, 0x0c, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x43,
+	0x68, 0x61, 0x6e, 0x49, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x61, 0x6d, 0x74, 0x5f, 0x73, 0x61, 0x74,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x61, 0x6d, 0x74, 0x53, 0x61, 0x74, 0x12, 0x16,
+	0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x2d, 0x0a, 0x13,
+	0x4d, 0x6f, 0x64, 0x69, 0x66, 0x79, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x06, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x22, 0x16, 0x0a, 0x14, 0x4d,
+	0x6f, 0x64, 0x69, 0x66, 0x79, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x22, 0x5c, 0x0a, 0x12, 0x51, 0x75, 0x65, 0x72, 0x79, 0x53, 0x63, 0x6f, 0x72,
+	0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x75, 0x62,
+	0x6b, 0x65, 0x79, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x70, 0x75, 0x62, 0x6b,
+	0x65, 0x79, 0x73, 0x12, 0x2c, 0x0a, 0x12, 0x69, 0x67, 0x6e, 0x6f, 0x72, 0x65, 0x5f, 0x6c, 0x6f,
+	0x63, 0x61, 0x6c, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x10, 0x69, 0x67, 0x6e, 0x6f, 0x72, 0x65, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x53, 0x74, 0x61, 0x74,
+	0x65, 0x22, 0xa6, 0x02, 0x0a, 0x13, 0x51, 0x75, 0x65, 0x72, 0x79, 0x53, 0x63, 0x6f, 0x72, 0x65,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4b, 0x0a, 0x07, 0x72, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x31, 0x2e, 0x61, 0x75, 0x74,
+	0x6f, 0x70, 0x69, 0x6c, 0x6f, 0x74, 0x72, 0x70, 0x63, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x53,
+	0x63, 0x6f, 0x72, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x48, 0x65,
+	0x75, 0x72, 0x69, 0x73, 0x74, 0x69, 0x63, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x07, 0x72,
+	0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x1a, 0xc1, 0x01, 0x0a, 0x0f, 0x48, 0x65, 0x75, 0x72, 0x69,
+	0x73, 0x74, 0x69, 0x63, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x68, 0x65,
 	0x75, 0x72, 0x69, 0x73, 0x74, 0x69, 0x63, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x68,
-	0x65, 0x75, 0x72, 0x69, 0x73, 0x74, 0x69, 0x63, 0x12, 0x42, 0x0a, 0x06, 0x73, 0x63, 0x6f, 0x72,
-	0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2a, 0x2e, 0x61, 0x75, 0x74, 0x6f, 0x70,
-	0x69, 0x6c, 0x6f, 0x74, 0x72, 0x70, 0x63, 0x2e, 0x53, 0x65, 0x74, 0x53, 0x63, 0x6f, 0x72, 0x65,
-	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x73, 0x45,
-	0x6e, 0x74, 0x72, 0x79, 0x52, 0x06, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x73, 0x1a, 0x39, 0x0a, 0x0b,
-	0x53, 0x63, 0x6f, 0x72, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b,
-	0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a,
-	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x76, 0x61,
-	0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x13, 0x0a, 0x11, 0x53, 0x65, 0x74, 0x53, 0x63,
-	0x6f, 0x72, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x32, 0xc9, 0x02, 0x0a,
-	0x09, 0x41, 0x75, 0x74, 0x6f, 0x70, 0x69, 0x6c, 0x6f, 0x74, 0x12, 0x43, 0x0a, 0x06, 0x53, 0x74,
-	0x61, 0x74, 0x75, 0x73, 0x12, 0x1b, 0x2e, 0x61, 0x75, 0x74, 0x6f, 0x70, 0x69, 0x6c, 0x6f, 0x74,
-	0x72, 0x70, 0x63, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x1a, 0x1c, 0x2e, 0x61, 0x75, 0x74, 0x6f, 0x70, 0x69, 0x6c, 0x6f, 0x74, 0x72, 0x70, 0x63,
-	0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
-	0x55, 0x0a, 0x0c, 0x4d, 0x6f, 0x64, 0x69, 0x66, 0x79, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12,
-	0x21, 0x2e, 0x61, 0x75, 0x74, 0x6f, 0x70, 0x69, 0x6c, 0x6f, 0x74, 0x72, 0x70, 0x63, 0x2e, 0x4d,
-	0x6f, 0x64, 0x69, 0x66, 0x79, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x1a, 0x22, 0x2e, 0x61, 0x75, 0x74, 0x6f, 0x70, 0x69, 0x6c, 0x6f, 0x74, 0x72, 0x70,
-	0x63, 0x2e, 0x4d, 0x6f, 0x64, 0x69, 0x66, 0x79, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x52, 0x0a, 0x0b, 0x51, 0x75, 0x65, 0x72, 0x79, 0x53,
-	0x63, 0x6f, 0x72, 0x65, 0x73, 0x12, 0x20, 0x2e, 0x61, 0x75, 0x74, 0x6f, 0x70, 0x69, 0x6c, 0x6f,
-	0x74, 0x72, 0x70, 0x63, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x73,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x61, 0x75, 0x74, 0x6f, 0x70, 0x69,
-	0x6c, 0x6f, 0x74, 0x72, 0x70, 0x63, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x53, 0x63, 0x6f, 0x72,
-	0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4c, 0x0a, 0x09, 0x53, 0x65,
-	0x74, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x73, 0x12, 0x1e, 0x2e, 0x61, 0x75, 0x74, 0x6f, 0x70, 0x69,
-	0x6c, 0x6f, 0x74, 0x72, 0x70, 0x63, 0x2e, 0x53, 0x65, 0x74, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x73,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x61, 0x75, 0x74, 0x6f, 0x70, 0x69,
-	0x6c, 0x6f, 0x74, 0x72, 0x70, 0x63, 0x2e, 0x53, 0x65, 0x74, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x73,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x34, 0x5a, 0x32, 0x67, 0x69, 0x74, 0x68,
-	0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6c, 0x69, 0x67, 0x68, 0x74, 0x6e, 0x69, 0x6e, 0x67,
-	0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x2f, 0x6c, 0x6e, 0x64, 0x2f, 0x6c, 0x6e, 0x72, 0x70,
-	0x63, 0x2f, 0x61, 0x75, 0x74, 0x6f, 0x70, 0x69, 0x6c, 0x6f, 0x74, 0x72, 0x70, 0x63, 0x62, 0x06,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x65, 0x75, 0x72, 0x69, 0x73, 0x74, 0x69, 0x63, 0x12, 0x55, 0x0a, 0x06, 0x73, 0x63, 0x6f, 0x72,
+	0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x3d, 0x2e, 0x61, 0x75, 0x74, 0x6f, 0x70,
+	0x69, 0x6c, 0x6f, 0x74, 0x72, 0x70, 0x63, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x53, 0x63, 0x6f,
+	0x72, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x48, 0x65, 0x75, 0x72,
+	0x69, 0x73, 0x74, 0x69, 0x63, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x2e, 0x53, 0x63, 0x6f, 0x72,
+	0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x06, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x73, 0x1a,
+	0x39, 0x0a, 0x0b, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10,
+	0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79,
+	0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0xaf, 0x01, 0x0a, 0x10, 0x53,
+	0x65, 0x74, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x1c, 0x0a, 0x09, 0x68, 0x65, 0x75, 0x72, 0x69, 0x73, 0x74, 0x69, 0x63, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x68, 0x65, 0x75, 0x72, 0x69, 0x73, 0x74, 0x69, 0x63, 0x12, 0x42, 0x0a,
+	0x06, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2a, 0x2e,
+	0x61, 0x75, 0x74, 0x6f, 0x70, 0x69, 0x6c, 0x6f, 0x74, 0x72, 0x70, 0x63, 0x2e, 0x53, 0x65, 0x74,
+	0x53, 0x63, 0x6f, 0x72, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x53, 0x63,
+	0x6f, 0x72, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x06, 0x73, 0x63, 0x6f, 0x72, 0x65,
+	0x73, 0x1a, 0x39, 0x0a, 0x0b, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b,
+	0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x13, 0x0a, 0x11,
+	0x53, 0x65, 0x74, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x2a, 0x3d, 0x0a, 0x10, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x41, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x54, 0x79, 0x70, 0x65, 0x12, 0x09, 0x0a, 0x05, 0x43, 0x4c, 0x4f, 0x53, 0x45, 0x10, 0x00,
+	0x12, 0x0f, 0x0a, 0x0b, 0x46, 0x4f, 0x52, 0x43, 0x45, 0x5f, 0x43, 0x4c, 0x4f, 0x53, 0x45, 0x10,
+	0x01, 0x12, 0x0d, 0x0a, 0x09, 0x52, 0x45, 0x42, 0x41, 0x4c, 0x41, 0x4e, 0x43, 0x45, 0x10, 0x02,
+	0x32, 0xc9, 0x02, 0x0a, 0x09, 0x41, 0x75, 0x74, 0x6f, 0x70, 0x69, 0x6c, 0x6f, 0x74, 0x12, 0x43,
+	0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1b, 0x2e, 0x61, 0x75, 0x74, 0x6f, 0x70,
+	0x69, 0x6c, 0x6f, 0x74, 0x72, 0x70, 0x63, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x61, 0x75, 0x74, 0x6f, 0x70, 0x69, 0x6c, 0x6f,
+	0x74, 0x72, 0x70, 0x63, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x55, 0x0a, 0x0c, 0x4d, 0x6f, 0x64, 0x69, 0x66, 0x79, 0x53, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x12, 0x21, 0x2e, 0x61, 0x75, 0x74, 0x6f, 0x70, 0x69, 0x6c, 0x6f, 0x74, 0x72,
+	0x70, 0x63, 0x2e, 0x4d, 0x6f, 0x64, 0x69, 0x66, 0x79, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x22, 0x2e, 0x61, 0x75, 0x74, 0x6f, 0x70, 0x69, 0x6c,
+	0x6f, 0x74, 0x72, 0x70, 0x63, 0x2e, 0x4d, 0x6f, 0x64, 0x69, 0x66, 0x79, 0x53, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x52, 0x0a, 0x0b, 0x51, 0x75,
+	0x65, 0x72, 0x79, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x73, 0x12, 0x20, 0x2e, 0x61, 0x75, 0x74, 0x6f,
+	0x70, 0x69, 0x6c, 0x6f, 0x74, 0x72, 0x70, 0x63, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x53, 0x63,
+	0x6f, 0x72, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x61, 0x75,
+	0x74, 0x6f, 0x70, 0x69, 0x6c, 0x6f, 0x74, 0x72, 0x70, 0x63, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79,
+	0x53, 0x63, 0x6f, 0x72, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4c,
+	0x0a, 0x09, 0x53, 0x65, 0x74, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x73, 0x12, 0x1e, 0x2e, 0x61, 0x75,
+	0x74, 0x6f, 0x70, 0x69, 0x6c, 0x6f, 0x74, 0x72, 0x70, 0x63, 0x2e, 0x53, 0x65, 0x74, 0x53, 0x63,
+	0x6f, 0x72, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x61, 0x75,
+	0x74, 0x6f, 0x70, 0x69, 0x6c, 0x6f, 0x74, 0x72, 0x70, 0x63, 0x2e, 0x53, 0x65, 0x74, 0x53, 0x63,
+	0x6f, 0x72, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x34, 0x5a, 0x32,
+	0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6c, 0x69, 0x67, 0x68, 0x74,
+	0x6e, 0x69, 0x6e, 0x67, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x2f, 0x6c, 0x6e, 0x64, 0x2f,
+	0x6c, 0x6e, 0x72, 0x70, 0x63, 0x2f, 0x61, 0x75, 0x74, 0x6f, 0x70, 0x69, 0x6c, 0x6f, 0x74, 0x72,
+	0x70, 0x63, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -536,37 +865,44 @@ func file_autopilotrpc_autopilot_proto_rawDescGZIP() []byte {
 	return file_autopilotrpc_autopilot_proto_rawDescData
 }
 
-var file_autopilotrpc_autopilot_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_autopilotrpc_autopilot_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_autopilotrpc_autopilot_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
 var file_autopilotrpc_autopilot_proto_goTypes = []interface{}{
-	(*StatusRequest)(nil),                       // 0: autopilotrpc.StatusRequest
-	(*StatusResponse)(nil),                      // 1: autopilotrpc.StatusResponse
-	(*ModifyStatusRequest)(nil),                 // 2: autopilotrpc.ModifyStatusRequest
-	(*ModifyStatusResponse)(nil),                // 3: autopilotrpc.ModifyStatusResponse
-	(*QueryScoresRequest)(nil),                  // 4: autopilotrpc.QueryScoresRequest
-	(*QueryScoresResponse)(nil),                 // 5: autopilotrpc.QueryScoresResponse
-	(*SetScoresRequest)(nil),                    // 6: autopilotrpc.SetScoresRequest
-	(*SetScoresResponse)(nil),                   // 7: autopilotrpc.SetScoresResponse
-	(*QueryScoresResponse_HeuristicResult)(nil), // 8: autopilotrpc.QueryScoresResponse.HeuristicResult
-	nil, // 9: autopilotrpc.QueryScoresResponse.HeuristicResult.ScoresEntry
-	nil, // 10: autopilotrpc.SetScoresRequest.ScoresEntry
+	(PolicyActionType)(0),                       // 0: autopilotrpc.PolicyActionType
+	(*StatusRequest)(nil),                       // 1: autopilotrpc.StatusRequest
+	(*StatusResponse)(nil),                      // 2: autopilotrpc.StatusResponse
+	(*ChannelPolicies)(nil),                     // 3: autopilotrpc.ChannelPolicies
+	(*PolicyAction)(nil),                        // 4: autopilotrpc.PolicyAction
+	(*ModifyStatusRequest)(nil),                 // 5: autopilotrpc.ModifyStatusRequest
+	(*ModifyStatusResponse)(nil),                // 6: autopilotrpc.ModifyStatusResponse
+	(*QueryScoresRequest)(nil),                  // 7: autopilotrpc.QueryScoresRequest
+	(*QueryScoresResponse)(nil),                 // 8: autopilotrpc.QueryScoresResponse
+	(*SetScoresRequest)(nil),                    // 9: autopilotrpc.SetScoresRequest
+	(*SetScoresResponse)(nil),                   // 10: autopilotrpc.SetScoresResponse
+	(*QueryScoresResponse_HeuristicResult)(nil), // 11: autopilotrpc.QueryScoresResponse.HeuristicResult
+	nil, // 12: autopilotrpc.QueryScoresResponse.HeuristicResult.ScoresEntry
+	nil, // 13: autopilotrpc.SetScoresRequest.ScoresEntry
 }
 var file_autopilotrpc_autopilot_proto_depIdxs = []int32{
-	8,  // 0: autopilotrpc.QueryScoresResponse.results:type_name -> autopilotrpc.QueryScoresResponse.HeuristicResult
-	10, // 1: autopilotrpc.SetScoresRequest.scores:type_name -> autopilotrpc.SetScoresRequest.ScoresEntry
-	9,  // 2: autopilotrpc.QueryScoresResponse.HeuristicResult.scores:type_name -> autopilotrpc.QueryScoresResponse.HeuristicResult.ScoresEntry
-	0,  // 3: autopilotrpc.Autopilot.Status:input_type -> autopilotrpc.StatusRequest
-	2,  // 4: autopilotrpc.Autopilot.ModifyStatus:input_type -> autopilotrpc.ModifyStatusRequest
-	4,  // 5: autopilotrpc.Autopilot.QueryScores:input_type -> autopilotrpc.QueryScoresRequest
-	6,  // 6: autopilotrpc.Autopilot.SetScores:input_type -> autopilotrpc.SetScoresRequest
-	1,  // 7: autopilotrpc.Autopilot.Status:output_type -> autopilotrpc.StatusResponse
-	3,  // 8: autopilotrpc.Autopilot.ModifyStatus:output_type -> autopilotrpc.ModifyStatusResponse
-	5,  // 9: autopilotrpc.Autopilot.QueryScores:output_type -> autopilotrpc.QueryScoresResponse
-	7,  // 10: autopilotrpc.Autopilot.SetScores:output_type -> autopilotrpc.SetScoresResponse
-	7,  // [7:11] is the sub-list for method output_type
-	3,  // [3:7] is the sub-list for method input_type
-	3,  // [3:3] is the sub-list for extension type_name
-	3,  // [3:3] is the sub-list for extension extendee
-	0,  // [0:3] is the sub-list for field type_name
+	3,  // 0: autopilotrpc.StatusResponse.policies:type_name -> autopilotrpc.ChannelPolicies
+	4,  // 1: autopilotrpc.StatusResponse.recent_actions:type_name -> autopilotrpc.PolicyAction
+	0,  // 2: autopilotrpc.PolicyAction.type:type_name -> autopilotrpc.PolicyActionType
+	11, // 3: autopilotrpc.QueryScoresResponse.results:type_name -> autopilotrpc.QueryScoresResponse.HeuristicResult
+	13, // 4: autopilotrpc.SetScoresRequest.scores:type_name -> autopilotrpc.SetScoresRequest.ScoresEntry
+	12, // 5: autopilotrpc.QueryScoresResponse.HeuristicResult.scores:type_name -> autopilotrpc.QueryScoresResponse.HeuristicResult.ScoresEntry
+	1,  // 6: autopilotrpc.Autopilot.Status:input_type -> autopilotrpc.StatusRequest
+	5,  // 7: autopilotrpc.Autopilot.ModifyStatus:input_type -> autopilotrpc.ModifyStatusRequest
+	7,  // 8: autopilotrpc.Autopilot.QueryScores:input_type -> autopilotrpc.QueryScoresRequest
+	9,  // 9: autopilotrpc.Autopilot.SetScores:input_type -> autopilotrpc.SetScoresRequest
+	2,  // 10: autopilotrpc.Autopilot.Status:output_type -> autopilotrpc.StatusResponse
+	6,  // 11: autopilotrpc.Autopilot.ModifyStatus:output_type -> autopilotrpc.ModifyStatusResponse
+	8,  // 12: autopilotrpc.Autopilot.QueryScores:output_type -> autopilotrpc.QueryScoresResponse
+	10, // 13: autopilotrpc.Autopilot.SetScores:output_type -> autopilotrpc.SetScoresResponse
+	10, // [10:14] is the sub-list for method output_type
+	6,  // [6:10] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
 }
 
 func init() { file_autopilotrpc_autopilot_proto_init() }
@@ -600,7 +936,7 @@ func file_autopilotrpc_autopilot_proto_init() {
 			}
 		}
 		file_autopilotrpc_autopilot_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ModifyStatusRequest); i {
+			switch v := v.(*ChannelPolicies); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -612,7 +948,7 @@ func file_autopilotrpc_autopilot_proto_init() {
 			}
 		}
 		file_autopilotrpc_autopilot_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ModifyStatusResponse); i {
+			switch v := v.(*PolicyAction); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -624,7 +960,7 @@ func file_autopilotrpc_autopilot_proto_init() {
 			}
 		}
 		file_autopilotrpc_autopilot_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*QueryScoresRequest); i {
+			switch v := v.(*ModifyStatusRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -636,7 +972,7 @@ func file_autopilotrpc_autopilot_proto_init() {
 			}
 		}
 		file_autopilotrpc_autopilot_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*QueryScoresResponse); i {
+			switch v := v.(*ModifyStatusResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -648,7 +984,7 @@ func file_autopilotrpc_autopilot_proto_init() {
 			}
 		}
 		file_autopilotrpc_autopilot_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SetScoresRequest); i {
+			switch v := v.(*QueryScoresRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -660,7 +996,7 @@ func file_autopilotrpc_autopilot_proto_init() {
 			}
 		}
 		file_autopilotrpc_autopilot_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SetScoresResponse); i {
+			switch v := v.(*QueryScoresResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -672,6 +1008,30 @@ func file_autopilotrpc_autopilot_proto_init() {
 			}
 		}
 		file_autopilotrpc_autopilot_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetScoresRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_autopilotrpc_autopilot_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetScoresResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_autopilotrpc_autopilot_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*QueryScoresResponse_HeuristicResult); i {
 			case 0:
 				return &v.state
@@ -689,13 +1049,14 @@ func file_autopilotrpc_autopilot_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_autopilotrpc_autopilot_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   11,
+			NumEnums:      1,
+			NumMessages:   13,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_autopilotrpc_autopilot_proto_goTypes,
 		DependencyIndexes: file_autopilotrpc_autopilot_proto_depIdxs,
+		EnumInfos:         file_autopilotrpc_autopilot_proto_enumTypes,
 		MessageInfos:      file_autopilotrpc_autopilot_proto_msgTypes,
 	}.Build()
 	File_autopilotrpc_autopilot_proto = out.File
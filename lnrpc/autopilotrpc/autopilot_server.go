@@ -6,6 +6,7 @@ package autopilotrpc
 import (
 	"context"
 	"encoding/hex"
+	"fmt"
 	"sync/atomic"
 
 	"github.com/btcsuite/btcd/btcec/v2"
@@ -190,9 +191,70 @@ func (r *ServerShell) CreateSubServer(configRegistry lnrpc.SubServerConfigDispat
 func (s *Server) Status(ctx context.Context,
 	in *StatusRequest) (*StatusResponse, error) {
 
-	return &StatusResponse{
+	policies := s.manager.Policies()
+	resp := &StatusResponse{
 		Active: s.manager.IsActive(),
-	}, nil
+		Policies: &ChannelPolicies{
+			IntervalSec: uint64(policies.Interval.Seconds()),
+			CloseInactiveAfterSec: uint64(
+				policies.InactiveTimeout.Seconds(),
+			),
+			CloseUnbalancedAfterSec: uint64(
+				policies.UnbalancedTimeout.Seconds(),
+			),
+			UnbalancedRatio:     policies.UnbalancedRatio,
+			MaxCloses:           policies.MaxCloses,
+			RebalanceRatio:      policies.RebalanceRatio,
+			RebalanceMaxFeeRate: policies.RebalanceMaxFeeRate,
+		},
+	}
+
+	for _, action := range s.manager.PolicyActions() {
+		rpcAction, err := marshalPolicyAction(action)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.RecentActions = append(resp.RecentActions, rpcAction)
+	}
+
+	return resp, nil
+}
+
+// marshalPolicyAction converts a policy action of the autopilot agent to its
+// RPC counterpart.
+func marshalPolicyAction(action autopilot.PolicyAction) (*PolicyAction,
+	error) {
+
+	var actionType PolicyActionType
+	switch action.Type {
+	case autopilot.PolicyActionClose:
+		actionType = PolicyActionType_CLOSE
+
+	case autopilot.PolicyActionForceClose:
+		actionType = PolicyActionType_FORCE_CLOSE
+
+	case autopilot.PolicyActionRebalance:
+		actionType = PolicyActionType_REBALANCE
+
+	default:
+		return nil, fmt.Errorf("unknown policy action type %v",
+			action.Type)
+	}
+
+	rpcAction := &PolicyAction{
+		Type:         actionType,
+		Timestamp:    action.Timestamp.Unix(),
+		ChanId:       action.ChanID.ToUint64(),
+		TargetChanId: action.TargetChanID.ToUint64(),
+		AmtSat:       int64(action.Amount),
+		Reason:       action.Reason,
+	}
+	if action.Err != nil {
+		rpcAction.Error = action.Err.Error()
+	}
+
+	return rpcAction, nil
 }
 
 // ModifyStatus activates the current autopilot agent, if active.
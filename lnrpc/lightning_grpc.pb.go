@@ -122,6 +122,12 @@ type LightningClient interface {
 	// sent over. Events include new active channels, inactive channels, and closed
 	// channels.
 	SubscribeChannelEvents(ctx context.Context, in *ChannelEventSubscription, opts ...grpc.CallOption) (Lightning_SubscribeChannelEventsClient, error)
+	// SubscribeChannelResolutions creates a uni-directional stream from the
+	// server to the client in which the detailed status of the on-chain contract
+	// resolvers of force closed channels is sent over. The current status of all
+	// active resolvers is sent first, followed by an update each time a resolver
+	// makes progress.
+	SubscribeChannelResolutions(ctx context.Context, in *ChannelResolutionSubscription, opts ...grpc.CallOption) (Lightning_SubscribeChannelResolutionsClient, error)
 	// lncli: `closedchannels`
 	// ClosedChannels returns a description of all the closed channels that
 	// this node was a participant in.
@@ -310,6 +316,52 @@ type LightningClient interface {
 	// UpdateChannelPolicy allows the caller to update the fee schedule and
 	// channel policies for all channels globally, or a particular channel.
 	UpdateChannelPolicy(ctx context.Context, in *PolicyUpdateRequest, opts ...grpc.CallOption) (*PolicyUpdateResponse, error)
+	// lncli: `updatechanparams`
+	// UpdateChanParams changes the parameters of an existing channel, such as
+	// the dust limit, the maximum number of accepted HTLCs, the CSV delay or the
+	// commitment type, through a dynamic commitment negotiation with the remote
+	// peer. Both peers must support the dynamic-commitments feature. The
+	// channel is made quiescent first: no new HTLCs are added and the pending
+	// ones must be resolved within a minute, otherwise the call fails. The call
+	// returns once the remote peer accepted or rejected the new parameters.
+	UpdateChanParams(ctx context.Context, in *UpdateChanParamsRequest, opts ...grpc.CallOption) (*UpdateChanParamsResponse, error)
+	// lncli: `feemanager status`
+	// FeeManagerStatus returns the configuration of the automatic fee manager
+	// together with the most recent fee updates it made.
+	FeeManagerStatus(ctx context.Context, in *FeeManagerStatusRequest, opts ...grpc.CallOption) (*FeeManagerStatusResponse, error)
+	// lncli: `feemanager update`
+	// UpdateFeeManager enables or disables the periodic fee adjustments of the
+	// automatic fee manager, selects the strategy it uses and optionally runs a
+	// round of fee adjustments immediately.
+	UpdateFeeManager(ctx context.Context, in *UpdateFeeManagerRequest, opts ...grpc.CallOption) (*UpdateFeeManagerResponse, error)
+	// lncli: `addwebhook`
+	// AddWebhook registers an HTTP endpoint that node events are delivered to.
+	// Every event is posted as a JSON document signed with the secret of the
+	// endpoint. Failed deliveries are retried with an exponential backoff and
+	// moved to the dead-letter queue once they failed too often.
+	AddWebhook(ctx context.Context, in *AddWebhookRequest, opts ...grpc.CallOption) (*AddWebhookResponse, error)
+	// lncli: `removewebhook`
+	// RemoveWebhook removes a webhook endpoint together with its pending and
+	// failed deliveries. Endpoints configured in lnd.conf can't be removed.
+	RemoveWebhook(ctx context.Context, in *RemoveWebhookRequest, opts ...grpc.CallOption) (*RemoveWebhookResponse, error)
+	// lncli: `listwebhooks`
+	// ListWebhooks returns the registered webhook endpoints.
+	ListWebhooks(ctx context.Context, in *ListWebhooksRequest, opts ...grpc.CallOption) (*ListWebhooksResponse, error)
+	// lncli: `listwebhookdeliveries`
+	// ListWebhookDeliveries returns the pending, successful or failed deliveries
+	// of node events to the webhook endpoints.
+	ListWebhookDeliveries(ctx context.Context, in *ListWebhookDeliveriesRequest, opts ...grpc.CallOption) (*ListWebhookDeliveriesResponse, error)
+	// lncli: `retrywebhookdelivery`
+	// RetryWebhookDelivery moves a delivery from the dead-letter queue back to
+	// the pending deliveries, to be attempted again right away.
+	RetryWebhookDelivery(ctx context.Context, in *RetryWebhookDeliveryRequest, opts ...grpc.CallOption) (*RetryWebhookDeliveryResponse, error)
+	// lncli: `report`
+	// AccountingReport returns a ledger of the activity of the node in a time
+	// range. It covers channel opens and closes, sweeps, on-chain sends and
+	// receives, invoice receipts, payments, rebalances and the forwarding fees
+	// earned. Every entry carries its amount in millisatoshis and references the
+	// transaction or payment hash it stems from.
+	AccountingReport(ctx context.Context, in *AccountingReportRequest, opts ...grpc.CallOption) (*AccountingReportResponse, error)
 	// lncli: `fwdinghistory`
 	// ForwardingHistory allows the caller to query the htlcswitch for a record of
 	// all HTLCs forwarded within the target time range, and integer offset
@@ -367,6 +419,11 @@ type LightningClient interface {
 	// DeleteMacaroonID deletes the specified macaroon ID and invalidates all
 	// macaroons derived from that ID.
 	DeleteMacaroonID(ctx context.Context, in *DeleteMacaroonIDRequest, opts ...grpc.CallOption) (*DeleteMacaroonIDResponse, error)
+	// lncli: `getmacaroonusage`
+	// GetMacaroonUsage returns how much the macaroons derived from the specified
+	// root key ID have spent and how many calls they made. Usage is only tracked
+	// for macaroons that carry spending or rate limit caveats.
+	GetMacaroonUsage(ctx context.Context, in *GetMacaroonUsageRequest, opts ...grpc.CallOption) (*GetMacaroonUsageResponse, error)
 	// lncli: `listpermissions`
 	// ListPermissions lists all RPC method URIs and their required macaroon
 	// permissions to access them.
@@ -667,6 +724,38 @@ func (x *lightningSubscribeChannelEventsClient) Recv() (*ChannelEventUpdate, err
 	return m, nil
 }
 
+func (c *lightningClient) SubscribeChannelResolutions(ctx context.Context, in *ChannelResolutionSubscription, opts ...grpc.CallOption) (Lightning_SubscribeChannelResolutionsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Lightning_ServiceDesc.Streams[3], "/lnrpc.Lightning/SubscribeChannelResolutions", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &lightningSubscribeChannelResolutionsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Lightning_SubscribeChannelResolutionsClient interface {
+	Recv() (*ContractResolverStatus, error)
+	grpc.ClientStream
+}
+
+type lightningSubscribeChannelResolutionsClient struct {
+	grpc.ClientStream
+}
+
+func (x *lightningSubscribeChannelResolutionsClient) Recv() (*ContractResolverStatus, error) {
+	m := new(ContractResolverStatus)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *lightningClient) ClosedChannels(ctx context.Context, in *ClosedChannelsRequest, opts ...grpc.CallOption) (*ClosedChannelsResponse, error) {
 	out := new(ClosedChannelsResponse)
 	err := c.cc.Invoke(ctx, "/lnrpc.Lightning/ClosedChannels", in, out, opts...)
@@ -686,7 +775,7 @@ func (c *lightningClient) OpenChannelSync(ctx context.Context, in *OpenChannelRe
 }
 
 func (c *lightningClient) OpenChannel(ctx context.Context, in *OpenChannelRequest, opts ...grpc.CallOption) (Lightning_OpenChannelClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Lightning_ServiceDesc.Streams[3], "/lnrpc.Lightning/OpenChannel", opts...)
+	stream, err := c.cc.NewStream(ctx, &Lightning_ServiceDesc.Streams[4], "/lnrpc.Lightning/OpenChannel", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -736,7 +825,7 @@ func (c *lightningClient) FundingStateStep(ctx context.Context, in *FundingTrans
 }
 
 func (c *lightningClient) ChannelAcceptor(ctx context.Context, opts ...grpc.CallOption) (Lightning_ChannelAcceptorClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Lightning_ServiceDesc.Streams[4], "/lnrpc.Lightning/ChannelAcceptor", opts...)
+	stream, err := c.cc.NewStream(ctx, &Lightning_ServiceDesc.Streams[5], "/lnrpc.Lightning/ChannelAcceptor", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -767,7 +856,7 @@ func (x *lightningChannelAcceptorClient) Recv() (*ChannelAcceptRequest, error) {
 }
 
 func (c *lightningClient) CloseChannel(ctx context.Context, in *CloseChannelRequest, opts ...grpc.CallOption) (Lightning_CloseChannelClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Lightning_ServiceDesc.Streams[5], "/lnrpc.Lightning/CloseChannel", opts...)
+	stream, err := c.cc.NewStream(ctx, &Lightning_ServiceDesc.Streams[6], "/lnrpc.Lightning/CloseChannel", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -809,7 +898,7 @@ func (c *lightningClient) AbandonChannel(ctx context.Context, in *AbandonChannel
 
 // Deprecated: Do not use.
 func (c *lightningClient) SendPayment(ctx context.Context, opts ...grpc.CallOption) (Lightning_SendPaymentClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Lightning_ServiceDesc.Streams[6], "/lnrpc.Lightning/SendPayment", opts...)
+	stream, err := c.cc.NewStream(ctx, &Lightning_ServiceDesc.Streams[7], "/lnrpc.Lightning/SendPayment", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -850,7 +939,7 @@ func (c *lightningClient) SendPaymentSync(ctx context.Context, in *SendRequest,
 
 // Deprecated: Do not use.
 func (c *lightningClient) SendToRoute(ctx context.Context, opts ...grpc.CallOption) (Lightning_SendToRouteClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Lightning_ServiceDesc.Streams[7], "/lnrpc.Lightning/SendToRoute", opts...)
+	stream, err := c.cc.NewStream(ctx, &Lightning_ServiceDesc.Streams[8], "/lnrpc.Lightning/SendToRoute", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -917,7 +1006,7 @@ func (c *lightningClient) LookupInvoice(ctx context.Context, in *PaymentHash, op
 }
 
 func (c *lightningClient) SubscribeInvoices(ctx context.Context, in *InvoiceSubscription, opts ...grpc.CallOption) (Lightning_SubscribeInvoicesClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Lightning_ServiceDesc.Streams[8], "/lnrpc.Lightning/SubscribeInvoices", opts...)
+	stream, err := c.cc.NewStream(ctx, &Lightning_ServiceDesc.Streams[9], "/lnrpc.Lightning/SubscribeInvoices", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -1048,7 +1137,7 @@ func (c *lightningClient) StopDaemon(ctx context.Context, in *StopRequest, opts
 }
 
 func (c *lightningClient) SubscribeChannelGraph(ctx context.Context, in *GraphTopologySubscription, opts ...grpc.CallOption) (Lightning_SubscribeChannelGraphClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Lightning_ServiceDesc.Streams[9], "/lnrpc.Lightning/SubscribeChannelGraph", opts...)
+	stream, err := c.cc.NewStream(ctx, &Lightning_ServiceDesc.Streams[10], "/lnrpc.Lightning/SubscribeChannelGraph", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -1106,6 +1195,87 @@ func (c *lightningClient) UpdateChannelPolicy(ctx context.Context, in *PolicyUpd
 	return out, nil
 }
 
+func (c *lightningClient) UpdateChanParams(ctx context.Context, in *UpdateChanParamsRequest, opts ...grpc.CallOption) (*UpdateChanParamsResponse, error) {
+	out := new(UpdateChanParamsResponse)
+	err := c.cc.Invoke(ctx, "/lnrpc.Lightning/UpdateChanParams", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lightningClient) FeeManagerStatus(ctx context.Context, in *FeeManagerStatusRequest, opts ...grpc.CallOption) (*FeeManagerStatusResponse, error) {
+	out := new(FeeManagerStatusResponse)
+	err := c.cc.Invoke(ctx, "/lnrpc.Lightning/FeeManagerStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lightningClient) UpdateFeeManager(ctx context.Context, in *UpdateFeeManagerRequest, opts ...grpc.CallOption) (*UpdateFeeManagerResponse, error) {
+	out := new(UpdateFeeManagerResponse)
+	err := c.cc.Invoke(ctx, "/lnrpc.Lightning/UpdateFeeManager", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lightningClient) AddWebhook(ctx context.Context, in *AddWebhookRequest, opts ...grpc.CallOption) (*AddWebhookResponse, error) {
+	out := new(AddWebhookResponse)
+	err := c.cc.Invoke(ctx, "/lnrpc.Lightning/AddWebhook", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lightningClient) RemoveWebhook(ctx context.Context, in *RemoveWebhookRequest, opts ...grpc.CallOption) (*RemoveWebhookResponse, error) {
+	out := new(RemoveWebhookResponse)
+	err := c.cc.Invoke(ctx, "/lnrpc.Lightning/RemoveWebhook", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lightningClient) ListWebhooks(ctx context.Context, in *ListWebhooksRequest, opts ...grpc.CallOption) (*ListWebhooksResponse, error) {
+	out := new(ListWebhooksResponse)
+	err := c.cc.Invoke(ctx, "/lnrpc.Lightning/ListWebhooks", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lightningClient) ListWebhookDeliveries(ctx context.Context, in *ListWebhookDeliveriesRequest, opts ...grpc.CallOption) (*ListWebhookDeliveriesResponse, error) {
+	out := new(ListWebhookDeliveriesResponse)
+	err := c.cc.Invoke(ctx, "/lnrpc.Lightning/ListWebhookDeliveries", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lightningClient) RetryWebhookDelivery(ctx context.Context, in *RetryWebhookDeliveryRequest, opts ...grpc.CallOption) (*RetryWebhookDeliveryResponse, error) {
+	out := new(RetryWebhookDeliveryResponse)
+	err := c.cc.Invoke(ctx, "/lnrpc.Lightning/RetryWebhookDelivery", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lightningClient) AccountingReport(ctx context.Context, in *AccountingReportRequest, opts ...grpc.CallOption) (*AccountingReportResponse, error) {
+	out := new(AccountingReportResponse)
+	err := c.cc.Invoke(ctx, "/lnrpc.Lightning/AccountingReport", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *lightningClient) ForwardingHistory(ctx context.Context, in *ForwardingHistoryRequest, opts ...grpc.CallOption) (*ForwardingHistoryResponse, error) {
 	out := new(ForwardingHistoryResponse)
 	err := c.cc.Invoke(ctx, "/lnrpc.Lightning/ForwardingHistory", in, out, opts...)
@@ -1152,7 +1322,7 @@ func (c *lightningClient) RestoreChannelBackups(ctx context.Context, in *Restore
 }
 
 func (c *lightningClient) SubscribeChannelBackups(ctx context.Context, in *ChannelBackupSubscription, opts ...grpc.CallOption) (Lightning_SubscribeChannelBackupsClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Lightning_ServiceDesc.Streams[10], "/lnrpc.Lightning/SubscribeChannelBackups", opts...)
+	stream, err := c.cc.NewStream(ctx, &Lightning_ServiceDesc.Streams[11], "/lnrpc.Lightning/SubscribeChannelBackups", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -1210,6 +1380,15 @@ func (c *lightningClient) DeleteMacaroonID(ctx context.Context, in *DeleteMacaro
 	return out, nil
 }
 
+func (c *lightningClient) GetMacaroonUsage(ctx context.Context, in *GetMacaroonUsageRequest, opts ...grpc.CallOption) (*GetMacaroonUsageResponse, error) {
+	out := new(GetMacaroonUsageResponse)
+	err := c.cc.Invoke(ctx, "/lnrpc.Lightning/GetMacaroonUsage", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *lightningClient) ListPermissions(ctx context.Context, in *ListPermissionsRequest, opts ...grpc.CallOption) (*ListPermissionsResponse, error) {
 	out := new(ListPermissionsResponse)
 	err := c.cc.Invoke(ctx, "/lnrpc.Lightning/ListPermissions", in, out, opts...)
@@ -1229,7 +1408,7 @@ func (c *lightningClient) CheckMacaroonPermissions(ctx context.Context, in *Chec
 }
 
 func (c *lightningClient) RegisterRPCMiddleware(ctx context.Context, opts ...grpc.CallOption) (Lightning_RegisterRPCMiddlewareClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Lightning_ServiceDesc.Streams[11], "/lnrpc.Lightning/RegisterRPCMiddleware", opts...)
+	stream, err := c.cc.NewStream(ctx, &Lightning_ServiceDesc.Streams[12], "/lnrpc.Lightning/RegisterRPCMiddleware", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -1269,7 +1448,7 @@ func (c *lightningClient) SendCustomMessage(ctx context.Context, in *SendCustomM
 }
 
 func (c *lightningClient) SubscribeCustomMessages(ctx context.Context, in *SubscribeCustomMessagesRequest, opts ...grpc.CallOption) (Lightning_SubscribeCustomMessagesClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Lightning_ServiceDesc.Streams[12], "/lnrpc.Lightning/SubscribeCustomMessages", opts...)
+	stream, err := c.cc.NewStream(ctx, &Lightning_ServiceDesc.Streams[13], "/lnrpc.Lightning/SubscribeCustomMessages", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -1426,6 +1605,12 @@ type LightningServer interface {
 	// sent over. Events include new active channels, inactive channels, and closed
 	// channels.
 	SubscribeChannelEvents(*ChannelEventSubscription, Lightning_SubscribeChannelEventsServer) error
+	// SubscribeChannelResolutions creates a uni-directional stream from the
+	// server to the client in which the detailed status of the on-chain contract
+	// resolvers of force closed channels is sent over. The current status of all
+	// active resolvers is sent first, followed by an update each time a resolver
+	// makes progress.
+	SubscribeChannelResolutions(*ChannelResolutionSubscription, Lightning_SubscribeChannelResolutionsServer) error
 	// lncli: `closedchannels`
 	// ClosedChannels returns a description of all the closed channels that
 	// this node was a participant in.
@@ -1614,6 +1799,52 @@ type LightningServer interface {
 	// UpdateChannelPolicy allows the caller to update the fee schedule and
 	// channel policies for all channels globally, or a particular channel.
 	UpdateChannelPolicy(context.Context, *PolicyUpdateRequest) (*PolicyUpdateResponse, error)
+	// lncli: `updatechanparams`
+	// UpdateChanParams changes the parameters of an existing channel, such as
+	// the dust limit, the maximum number of accepted HTLCs, the CSV delay or the
+	// commitment type, through a dynamic commitment negotiation with the remote
+	// peer. Both peers must support the dynamic-commitments feature. The
+	// channel is made quiescent first: no new HTLCs are added and the pending
+	// ones must be resolved within a minute, otherwise the call fails. The call
+	// returns once the remote peer accepted or rejected the new parameters.
+	UpdateChanParams(context.Context, *UpdateChanParamsRequest) (*UpdateChanParamsResponse, error)
+	// lncli: `feemanager status`
+	// FeeManagerStatus returns the configuration of the automatic fee manager
+	// together with the most recent fee updates it made.
+	FeeManagerStatus(context.Context, *FeeManagerStatusRequest) (*FeeManagerStatusResponse, error)
+	// lncli: `feemanager update`
+	// UpdateFeeManager enables or disables the periodic fee adjustments of the
+	// automatic fee manager, selects the strategy it uses and optionally runs a
+	// round of fee adjustments immediately.
+	UpdateFeeManager(context.Context, *UpdateFeeManagerRequest) (*UpdateFeeManagerResponse, error)
+	// lncli: `addwebhook`
+	// AddWebhook registers an HTTP endpoint that node events are delivered to.
+	// Every event is posted as a JSON document signed with the secret of the
+	// endpoint. Failed deliveries are retried with an exponential backoff and
+	// moved to the dead-letter queue once they failed too often.
+	AddWebhook(context.Context, *AddWebhookRequest) (*AddWebhookResponse, error)
+	// lncli: `removewebhook`
+	// RemoveWebhook removes a webhook endpoint together with its pending and
+	// failed deliveries. Endpoints configured in lnd.conf can't be removed.
+	RemoveWebhook(context.Context, *RemoveWebhookRequest) (*RemoveWebhookResponse, error)
+	// lncli: `listwebhooks`
+	// ListWebhooks returns the registered webhook endpoints.
+	ListWebhooks(context.Context, *ListWebhooksRequest) (*ListWebhooksResponse, error)
+	// lncli: `listwebhookdeliveries`
+	// ListWebhookDeliveries returns the pending, successful or failed deliveries
+	// of node events to the webhook endpoints.
+	ListWebhookDeliveries(context.Context, *ListWebhookDeliveriesRequest) (*ListWebhookDeliveriesResponse, error)
+	// lncli: `retrywebhookdelivery`
+	// RetryWebhookDelivery moves a delivery from the dead-letter queue back to
+	// the pending deliveries, to be attempted again right away.
+	RetryWebhookDelivery(context.Context, *RetryWebhookDeliveryRequest) (*RetryWebhookDeliveryResponse, error)
+	// lncli: `report`
+	// AccountingReport returns a ledger of the activity of the node in a time
+	// range. It covers channel opens and closes, sweeps, on-chain sends and
+	// receives, invoice receipts, payments, rebalances and the forwarding fees
+	// earned. Every entry carries its amount in millisatoshis and references the
+	// transaction or payment hash it stems from.
+	AccountingReport(context.Context, *AccountingReportRequest) (*AccountingReportResponse, error)
 	// lncli: `fwdinghistory`
 	// ForwardingHistory allows the caller to query the htlcswitch for a record of
 	// all HTLCs forwarded within the target time range, and integer offset
@@ -1671,6 +1902,11 @@ type LightningServer interface {
 	// DeleteMacaroonID deletes the specified macaroon ID and invalidates all
 	// macaroons derived from that ID.
 	DeleteMacaroonID(context.Context, *DeleteMacaroonIDRequest) (*DeleteMacaroonIDResponse, error)
+	// lncli: `getmacaroonusage`
+	// GetMacaroonUsage returns how much the macaroons derived from the specified
+	// root key ID have spent and how many calls they made. Usage is only tracked
+	// for macaroons that carry spending or rate limit caveats.
+	GetMacaroonUsage(context.Context, *GetMacaroonUsageRequest) (*GetMacaroonUsageResponse, error)
 	// lncli: `listpermissions`
 	// ListPermissions lists all RPC method URIs and their required macaroon
 	// permissions to access them.
@@ -1779,6 +2015,9 @@ func (UnimplementedLightningServer) ListChannels(context.Context, *ListChannelsR
 func (UnimplementedLightningServer) SubscribeChannelEvents(*ChannelEventSubscription, Lightning_SubscribeChannelEventsServer) error {
 	return status.Errorf(codes.Unimplemented, "method SubscribeChannelEvents not implemented")
 }
+func (UnimplementedLightningServer) SubscribeChannelResolutions(*ChannelResolutionSubscription, Lightning_SubscribeChannelResolutionsServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeChannelResolutions not implemented")
+}
 func (UnimplementedLightningServer) ClosedChannels(context.Context, *ClosedChannelsRequest) (*ClosedChannelsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ClosedChannels not implemented")
 }
@@ -1872,6 +2111,33 @@ func (UnimplementedLightningServer) FeeReport(context.Context, *FeeReportRequest
 func (UnimplementedLightningServer) UpdateChannelPolicy(context.Context, *PolicyUpdateRequest) (*PolicyUpdateResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateChannelPolicy not implemented")
 }
+func (UnimplementedLightningServer) UpdateChanParams(context.Context, *UpdateChanParamsRequest) (*UpdateChanParamsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateChanParams not implemented")
+}
+func (UnimplementedLightningServer) FeeManagerStatus(context.Context, *FeeManagerStatusRequest) (*FeeManagerStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FeeManagerStatus not implemented")
+}
+func (UnimplementedLightningServer) UpdateFeeManager(context.Context, *UpdateFeeManagerRequest) (*UpdateFeeManagerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateFeeManager not implemented")
+}
+func (UnimplementedLightningServer) AddWebhook(context.Context, *AddWebhookRequest) (*AddWebhookResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddWebhook not implemented")
+}
+func (UnimplementedLightningServer) RemoveWebhook(context.Context, *RemoveWebhookRequest) (*RemoveWebhookResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveWebhook not implemented")
+}
+func (UnimplementedLightningServer) ListWebhooks(context.Context, *ListWebhooksRequest) (*ListWebhooksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWebhooks not implemented")
+}
+func (UnimplementedLightningServer) ListWebhookDeliveries(context.Context, *ListWebhookDeliveriesRequest) (*ListWebhookDeliveriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWebhookDeliveries not implemented")
+}
+func (UnimplementedLightningServer) RetryWebhookDelivery(context.Context, *RetryWebhookDeliveryRequest) (*RetryWebhookDeliveryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RetryWebhookDelivery not implemented")
+}
+func (UnimplementedLightningServer) AccountingReport(context.Context, *AccountingReportRequest) (*AccountingReportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AccountingReport not implemented")
+}
 func (UnimplementedLightningServer) ForwardingHistory(context.Context, *ForwardingHistoryRequest) (*ForwardingHistoryResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ForwardingHistory not implemented")
 }
@@ -1899,6 +2165,9 @@ func (UnimplementedLightningServer) ListMacaroonIDs(context.Context, *ListMacaro
 func (UnimplementedLightningServer) DeleteMacaroonID(context.Context, *DeleteMacaroonIDRequest) (*DeleteMacaroonIDResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteMacaroonID not implemented")
 }
+func (UnimplementedLightningServer) GetMacaroonUsage(context.Context, *GetMacaroonUsageRequest) (*GetMacaroonUsageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMacaroonUsage not implemented")
+}
 func (UnimplementedLightningServer) ListPermissions(context.Context, *ListPermissionsRequest) (*ListPermissionsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListPermissions not implemented")
 }
@@ -2302,6 +2571,27 @@ func (x *lightningSubscribeChannelEventsServer) Send(m *ChannelEventUpdate) erro
 	return x.ServerStream.SendMsg(m)
 }
 
+func _Lightning_SubscribeChannelResolutions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ChannelResolutionSubscription)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LightningServer).SubscribeChannelResolutions(m, &lightningSubscribeChannelResolutionsServer{stream})
+}
+
+type Lightning_SubscribeChannelResolutionsServer interface {
+	Send(*ContractResolverStatus) error
+	grpc.ServerStream
+}
+
+type lightningSubscribeChannelResolutionsServer struct {
+	grpc.ServerStream
+}
+
+func (x *lightningSubscribeChannelResolutionsServer) Send(m *ContractResolverStatus) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 func _Lightning_ClosedChannels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ClosedChannelsRequest)
 	if err := dec(in); err != nil {
@@ -2896,6 +3186,168 @@ func _Lightning_UpdateChannelPolicy_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Lightning_UpdateChanParams_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateChanParamsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).UpdateChanParams(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/UpdateChanParams",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).UpdateChanParams(ctx, req.(*UpdateChanParamsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lightning_FeeManagerStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FeeManagerStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).FeeManagerStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/FeeManagerStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).FeeManagerStatus(ctx, req.(*FeeManagerStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lightning_UpdateFeeManager_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateFeeManagerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).UpdateFeeManager(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/UpdateFeeManager",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).UpdateFeeManager(ctx, req.(*UpdateFeeManagerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lightning_AddWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddWebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).AddWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/AddWebhook",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).AddWebhook(ctx, req.(*AddWebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lightning_RemoveWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveWebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).RemoveWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/RemoveWebhook",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).RemoveWebhook(ctx, req.(*RemoveWebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lightning_ListWebhooks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListWebhooksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).ListWebhooks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/ListWebhooks",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).ListWebhooks(ctx, req.(*ListWebhooksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lightning_ListWebhookDeliveries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListWebhookDeliveriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).ListWebhookDeliveries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/ListWebhookDeliveries",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).ListWebhookDeliveries(ctx, req.(*ListWebhookDeliveriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lightning_RetryWebhookDelivery_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RetryWebhookDeliveryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).RetryWebhookDelivery(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/RetryWebhookDelivery",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).RetryWebhookDelivery(ctx, req.(*RetryWebhookDeliveryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lightning_AccountingReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AccountingReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).AccountingReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/AccountingReport",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).AccountingReport(ctx, req.(*AccountingReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Lightning_ForwardingHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ForwardingHistoryRequest)
 	if err := dec(in); err != nil {
@@ -3061,6 +3513,24 @@ func _Lightning_DeleteMacaroonID_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Lightning_GetMacaroonUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMacaroonUsageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).GetMacaroonUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/GetMacaroonUsage",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).GetMacaroonUsage(ctx, req.(*GetMacaroonUsageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Lightning_ListPermissions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ListPermissionsRequest)
 	if err := dec(in); err != nil {
@@ -3369,6 +3839,42 @@ var Lightning_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "UpdateChannelPolicy",
 			Handler:    _Lightning_UpdateChannelPolicy_Handler,
 		},
+		{
+			MethodName: "UpdateChanParams",
+			Handler:    _Lightning_UpdateChanParams_Handler,
+		},
+		{
+			MethodName: "FeeManagerStatus",
+			Handler:    _Lightning_FeeManagerStatus_Handler,
+		},
+		{
+			MethodName: "UpdateFeeManager",
+			Handler:    _Lightning_UpdateFeeManager_Handler,
+		},
+		{
+			MethodName: "AddWebhook",
+			Handler:    _Lightning_AddWebhook_Handler,
+		},
+		{
+			MethodName: "RemoveWebhook",
+			Handler:    _Lightning_RemoveWebhook_Handler,
+		},
+		{
+			MethodName: "ListWebhooks",
+			Handler:    _Lightning_ListWebhooks_Handler,
+		},
+		{
+			MethodName: "ListWebhookDeliveries",
+			Handler:    _Lightning_ListWebhookDeliveries_Handler,
+		},
+		{
+			MethodName: "RetryWebhookDelivery",
+			Handler:    _Lightning_RetryWebhookDelivery_Handler,
+		},
+		{
+			MethodName: "AccountingReport",
+			Handler:    _Lightning_AccountingReport_Handler,
+		},
 		{
 			MethodName: "ForwardingHistory",
 			Handler:    _Lightning_ForwardingHistory_Handler,
@@ -3401,6 +3907,10 @@ var Lightning_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteMacaroonID",
 			Handler:    _Lightning_DeleteMacaroonID_Handler,
 		},
+		{
+			MethodName: "GetMacaroonUsage",
+			Handler:    _Lightning_GetMacaroonUsage_Handler,
+		},
 		{
 			MethodName: "ListPermissions",
 			Handler:    _Lightning_ListPermissions_Handler,
@@ -3438,6 +3948,11 @@ var Lightning_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _Lightning_SubscribeChannelEvents_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "SubscribeChannelResolutions",
+			Handler:       _Lightning_SubscribeChannelResolutions_Handler,
+			ServerStreams: true,
+		},
 		{
 			StreamName:    "OpenChannel",
 			Handler:       _Lightning_OpenChannel_Handler,
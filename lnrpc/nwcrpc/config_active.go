@@ -0,0 +1,35 @@
+//go:build nwcrpc
+// +build nwcrpc
+
+package nwcrpc
+
+import (
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/lightningnetwork/lnd/macaroons"
+	"google.golang.org/grpc"
+)
+
+// Config is the primary configuration struct for the NWC RPC subserver. It
+// contains all the items required for the server to carry out its duties.
+// The fields with struct tags are meant to be parsed as normal configuration
+// options, while if able to be populated, the latter fields MUST also be
+// specified.
+type Config struct {
+	// Relay is the websocket URL of the nostr relay that is used to
+	// communicate with NWC clients. If it is empty, the NWC bridge isn't
+	// started.
+	Relay string `long:"relay" description:"The websocket URL of the nostr relay used to communicate with Nostr Wallet Connect clients, e.g. wss://relay.example.com. If not set, Nostr Wallet Connect is disabled."`
+
+	// MacService is the main macaroon service that we use to bake the
+	// scoped macaroons of the connections and to store their encrypted
+	// keys.
+	MacService *macaroons.Service
+
+	// ChainParams are the parameters of the chain lnd is running on.
+	ChainParams *chaincfg.Params
+
+	// DialLnd returns a connection to lnd's own gRPC server that is used
+	// to execute the requests of NWC clients, authenticated with the
+	// macaroon of their connection.
+	DialLnd func() (*grpc.ClientConn, error)
+}
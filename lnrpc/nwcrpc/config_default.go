@@ -0,0 +1,7 @@
+//go:build !nwcrpc
+// +build !nwcrpc
+
+package nwcrpc
+
+// Config is empty for non-nwcrpc builds.
+type Config struct{}
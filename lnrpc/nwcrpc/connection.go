@@ -0,0 +1,284 @@
+//go:build nwcrpc
+// +build nwcrpc
+
+package nwcrpc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/macaroons"
+	"github.com/lightningnetwork/lnd/tlv"
+)
+
+const (
+	// connectionSecretPrefix is the prefix of the IDs under which the
+	// connections are stored in the secret store of the macaroon
+	// database.
+	connectionSecretPrefix = "nwc/"
+
+	// uriScheme is the scheme of NWC connection URIs.
+	uriScheme = "nostr+walletconnect"
+
+	// defaultBudgetRenewal is the period after which the budget of a
+	// connection renews if none is given.
+	defaultBudgetRenewal = 24 * time.Hour
+
+	typeName          tlv.Type = 0
+	typeWalletKey     tlv.Type = 1
+	typeClientPubKey  tlv.Type = 2
+	typeMethods       tlv.Type = 3
+	typeBudget        tlv.Type = 4
+	typeBudgetRenewal tlv.Type = 5
+	typeMaxPayment    tlv.Type = 6
+	typeMaxRequests   tlv.Type = 7
+	typeRootKeyID     tlv.Type = 8
+	typeMacaroon      tlv.Type = 9
+	typeCreatedAt     tlv.Type = 10
+)
+
+// connection is a single NWC connection between a client app and lnd.
+type connection struct {
+	// name is the human readable name of the connection.
+	name string
+
+	// walletKey is the key of the wallet service of the connection. Every
+	// connection has its own wallet key, so requests of different
+	// connections can't be linked to each other.
+	walletKey *btcec.PrivateKey
+
+	// clientPubKey is the public key of the client app.
+	clientPubKey *btcec.PublicKey
+
+	// methods is the set of NIP-47 methods the connection may call.
+	methods map[string]struct{}
+
+	// budget is the amount the connection can spend within
+	// budgetRenewal. Zero means the spending isn't limited.
+	budget lnwire.MilliSatoshi
+
+	// budgetRenewal is the period after which the budget renews.
+	budgetRenewal time.Duration
+
+	// maxPayment is the maximum amount of a single payment. Zero means the
+	// amount isn't limited.
+	maxPayment lnwire.MilliSatoshi
+
+	// maxRequestsPerMinute is the rate limit of the connection. Zero
+	// means the connection isn't rate limited.
+	maxRequestsPerMinute uint32
+
+	// rootKeyID is the root key ID of the macaroon of the connection.
+	rootKeyID uint64
+
+	// macaroon is the serialized macaroon the requests of the connection
+	// are executed with.
+	macaroon []byte
+
+	// createdAt is the time the connection was created.
+	createdAt time.Time
+}
+
+// clientID returns the hex encoded x-only public key of the client, which
+// identifies the connection.
+func (c *connection) clientID() string {
+	return pubKeyHex(c.clientPubKey)
+}
+
+// walletID returns the hex encoded x-only public key of the wallet service.
+func (c *connection) walletID() string {
+	return pubKeyHex(c.walletKey.PubKey())
+}
+
+// sortedMethods returns the methods of the connection in alphabetical order.
+func (c *connection) sortedMethods() []string {
+	methods := make([]string, 0, len(c.methods))
+	for method := range c.methods {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+
+	return methods
+}
+
+// toRPC converts the connection to its RPC representation.
+func (c *connection) toRPC(relay string) *Connection {
+	return &Connection{
+		Name:                 c.name,
+		ClientPubkey:         c.clientID(),
+		WalletPubkey:         c.walletID(),
+		Methods:              c.sortedMethods(),
+		BudgetMsat:           uint64(c.budget),
+		BudgetRenewalSecs:    uint64(c.budgetRenewal / time.Second),
+		MaxPaymentMsat:       uint64(c.maxPayment),
+		MaxRequestsPerMinute: c.maxRequestsPerMinute,
+		RootKeyId:            c.rootKeyID,
+		CreatedAt:            c.createdAt.Unix(),
+		Relay:                relay,
+	}
+}
+
+// connectionURI returns the URI that needs to be passed to the client app to
+// set up the connection with the given secret of the client.
+func connectionURI(walletPubKey *btcec.PublicKey, relay string,
+	clientKey *btcec.PrivateKey) string {
+
+	query := url.Values{}
+	query.Set("relay", relay)
+	query.Set("secret", hex.EncodeToString(clientKey.Serialize()))
+
+	return fmt.Sprintf("%s://%s?%s", uriScheme, pubKeyHex(walletPubKey),
+		query.Encode())
+}
+
+// connectionSecretID returns the ID under which the connection of the given
+// client is stored in the secret store.
+func connectionSecretID(clientID string) []byte {
+	return []byte(connectionSecretPrefix + clientID)
+}
+
+// connectionStore persists connections encrypted in the macaroon database.
+type connectionStore struct {
+	secrets macaroons.SecretStore
+}
+
+// store persists the given connection, replacing any previous connection of
+// the same client.
+func (s *connectionStore) store(c *connection) error {
+	var b bytes.Buffer
+	if err := serializeConnection(&b, c); err != nil {
+		return err
+	}
+
+	return s.secrets.StoreSecret(
+		connectionSecretID(c.clientID()), b.Bytes(),
+	)
+}
+
+// delete removes the connection of the given client.
+func (s *connectionStore) delete(clientID string) error {
+	return s.secrets.DeleteSecret(connectionSecretID(clientID))
+}
+
+// fetchAll returns all stored connections.
+func (s *connectionStore) fetchAll() ([]*connection, error) {
+	secrets, err := s.secrets.ListSecrets([]byte(connectionSecretPrefix))
+	if err != nil {
+		return nil, err
+	}
+
+	connections := make([]*connection, 0, len(secrets))
+	for id, secret := range secrets {
+		c, err := deserializeConnection(bytes.NewReader(secret))
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode connection "+
+				"%v: %w", id, err)
+		}
+
+		connections = append(connections, c)
+	}
+
+	return connections, nil
+}
+
+// serializeConnection writes the TLV encoding of the given connection to w.
+func serializeConnection(w io.Writer, c *connection) error {
+	var (
+		name          = []byte(c.name)
+		walletKey     = c.walletKey.Serialize()
+		clientPubKey  = schnorr.SerializePubKey(c.clientPubKey)
+		methods       = []byte(strings.Join(c.sortedMethods(), ","))
+		budget        = uint64(c.budget)
+		budgetRenewal = uint64(c.budgetRenewal)
+		maxPayment    = uint64(c.maxPayment)
+		createdAt     = uint64(c.createdAt.UnixNano())
+	)
+
+	stream, err := tlv.NewStream(
+		tlv.MakePrimitiveRecord(typeName, &name),
+		tlv.MakePrimitiveRecord(typeWalletKey, &walletKey),
+		tlv.MakePrimitiveRecord(typeClientPubKey, &clientPubKey),
+		tlv.MakePrimitiveRecord(typeMethods, &methods),
+		tlv.MakePrimitiveRecord(typeBudget, &budget),
+		tlv.MakePrimitiveRecord(typeBudgetRenewal, &budgetRenewal),
+		tlv.MakePrimitiveRecord(typeMaxPayment, &maxPayment),
+		tlv.MakePrimitiveRecord(
+			typeMaxRequests, &c.maxRequestsPerMinute,
+		),
+		tlv.MakePrimitiveRecord(typeRootKeyID, &c.rootKeyID),
+		tlv.MakePrimitiveRecord(typeMacaroon, &c.macaroon),
+		tlv.MakePrimitiveRecord(typeCreatedAt, &createdAt),
+	)
+	if err != nil {
+		return err
+	}
+
+	return stream.Encode(w)
+}
+
+// deserializeConnection reads the TLV encoding of a connection from r.
+func deserializeConnection(r io.Reader) (*connection, error) {
+	var (
+		c                                 connection
+		name, walletKey, clientPubKey     []byte
+		methods                           []byte
+		budget, budgetRenewal, maxPayment uint64
+		createdAt                         uint64
+	)
+
+	stream, err := tlv.NewStream(
+		tlv.MakePrimitiveRecord(typeName, &name),
+		tlv.MakePrimitiveRecord(typeWalletKey, &walletKey),
+		tlv.MakePrimitiveRecord(typeClientPubKey, &clientPubKey),
+		tlv.MakePrimitiveRecord(typeMethods, &methods),
+		tlv.MakePrimitiveRecord(typeBudget, &budget),
+		tlv.MakePrimitiveRecord(typeBudgetRenewal, &budgetRenewal),
+		tlv.MakePrimitiveRecord(typeMaxPayment, &maxPayment),
+		tlv.MakePrimitiveRecord(
+			typeMaxRequests, &c.maxRequestsPerMinute,
+		),
+		tlv.MakePrimitiveRecord(typeRootKeyID, &c.rootKeyID),
+		tlv.MakePrimitiveRecord(typeMacaroon, &c.macaroon),
+		tlv.MakePrimitiveRecord(typeCreatedAt, &createdAt),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := stream.Decode(r); err != nil {
+		return nil, err
+	}
+
+	if len(walletKey) != btcec.PrivKeyBytesLen {
+		return nil, fmt.Errorf("invalid wallet key length: %v",
+			len(walletKey))
+	}
+	c.walletKey, _ = btcec.PrivKeyFromBytes(walletKey)
+	c.clientPubKey, err = schnorr.ParsePubKey(clientPubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	c.name = string(name)
+	c.methods = make(map[string]struct{})
+	for _, method := range strings.Split(string(methods), ",") {
+		if method != "" {
+			c.methods[method] = struct{}{}
+		}
+	}
+	c.budget = lnwire.MilliSatoshi(budget)
+	c.budgetRenewal = time.Duration(budgetRenewal)
+	c.maxPayment = lnwire.MilliSatoshi(maxPayment)
+	c.createdAt = time.Unix(0, int64(createdAt))
+
+	return &c, nil
+}
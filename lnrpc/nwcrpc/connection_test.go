@@ -0,0 +1,85 @@
+//go:build nwcrpc
+// +build nwcrpc
+
+package nwcrpc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// TestConnectionSerialization tests that a connection can be serialized and
+// deserialized without losing any information.
+func TestConnectionSerialization(t *testing.T) {
+	t.Parallel()
+
+	walletKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	clientKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	// The client key is x-only, so we parse it the same way as a
+	// deserialized key to be able to compare them.
+	clientPubKey, err := parsePubKey(pubKeyHex(clientKey.PubKey()))
+	require.NoError(t, err)
+
+	c := &connection{
+		name:         "app",
+		walletKey:    walletKey,
+		clientPubKey: clientPubKey,
+		methods: map[string]struct{}{
+			methodGetBalance: {},
+			methodPayInvoice: {},
+		},
+		budget:               100_000,
+		budgetRenewal:        time.Hour,
+		maxPayment:           10_000,
+		maxRequestsPerMinute: 30,
+		rootKeyID:            12345,
+		macaroon:             []byte{1, 2, 3},
+		createdAt:            time.Unix(1700000000, 123),
+	}
+
+	var b bytes.Buffer
+	require.NoError(t, serializeConnection(&b, c))
+
+	decoded, err := deserializeConnection(&b)
+	require.NoError(t, err)
+	require.Equal(t, c.clientID(), decoded.clientID())
+	require.Equal(t, c.walletID(), decoded.walletID())
+
+	// The keys are compared by their IDs above.
+	decoded.walletKey = c.walletKey
+	decoded.clientPubKey = c.clientPubKey
+	require.Equal(t, c, decoded)
+}
+
+// TestConnectionURI tests that the connection URI contains everything a
+// client app needs to connect.
+func TestConnectionURI(t *testing.T) {
+	t.Parallel()
+
+	walletKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	clientKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	relay := "wss://relay.example.com/nostr?x=1"
+	uri := connectionURI(walletKey.PubKey(), relay, clientKey)
+
+	u, err := url.Parse(uri)
+	require.NoError(t, err)
+	require.Equal(t, uriScheme, u.Scheme)
+	require.Equal(t, pubKeyHex(walletKey.PubKey()), u.Host)
+	require.Equal(t, relay, u.Query().Get("relay"))
+
+	secret, err := hex.DecodeString(u.Query().Get("secret"))
+	require.NoError(t, err)
+	require.Equal(t, clientKey.Serialize(), secret)
+}
@@ -0,0 +1,425 @@
+//go:build nwcrpc
+// +build nwcrpc
+
+package nwcrpc
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/lightningnetwork/lnd/lnrpc"
+	"github.com/lightningnetwork/lnd/lnrpc/routerrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// mockRelay is a minimal in-process nostr relay. It stores all events it
+// receives and forwards them to all subscriptions with a matching filter.
+type mockRelay struct {
+	server *httptest.Server
+
+	mu     sync.Mutex
+	events []*event
+	subs   map[*relayConn]map[string]*filter
+}
+
+// relayConn is a single client connection to the mock relay.
+type relayConn struct {
+	conn *websocket.Conn
+	mu   sync.Mutex
+}
+
+// send writes the given message to the client.
+func (c *relayConn) send(msg interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_ = c.conn.WriteJSON(msg)
+}
+
+// newMockRelay starts a new mock relay that is stopped when the test ends.
+func newMockRelay(t *testing.T) *mockRelay {
+	r := &mockRelay{
+		subs: make(map[*relayConn]map[string]*filter),
+	}
+	r.server = httptest.NewServer(http.HandlerFunc(r.serve))
+	t.Cleanup(r.server.Close)
+
+	return r
+}
+
+// url returns the websocket URL of the relay.
+func (r *mockRelay) url() string {
+	return "ws" + strings.TrimPrefix(r.server.URL, "http")
+}
+
+// numSubscriptions returns the number of active subscriptions.
+func (r *mockRelay) numSubscriptions() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var n int
+	for _, subs := range r.subs {
+		n += len(subs)
+	}
+
+	return n
+}
+
+// eventsOfKind returns all stored events of the given kind.
+func (r *mockRelay) eventsOfKind(kind int) []*event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var events []*event
+	for _, ev := range r.events {
+		if ev.Kind == kind {
+			events = append(events, ev)
+		}
+	}
+
+	return events
+}
+
+// publish stores the given event and forwards it to all matching
+// subscriptions, as if it was sent by a client.
+func (r *mockRelay) publish(ev *event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, ev)
+	for conn, subs := range r.subs {
+		for id, f := range subs {
+			if !matches(f, ev) {
+				continue
+			}
+
+			conn.send([]interface{}{"EVENT", id, ev})
+		}
+	}
+}
+
+// serve handles a single websocket client.
+func (r *mockRelay) serve(w http.ResponseWriter, req *http.Request) {
+	upgrader := websocket.Upgrader{}
+	wsConn, err := upgrader.Upgrade(w, req, nil)
+	if err != nil {
+		return
+	}
+	conn := &relayConn{conn: wsConn}
+
+	defer func() {
+		r.mu.Lock()
+		delete(r.subs, conn)
+		r.mu.Unlock()
+
+		_ = wsConn.Close()
+	}()
+
+	for {
+		var msg []json.RawMessage
+		if err := wsConn.ReadJSON(&msg); err != nil {
+			return
+		}
+		if len(msg) < 2 {
+			continue
+		}
+
+		var msgType string
+		_ = json.Unmarshal(msg[0], &msgType)
+
+		switch msgType {
+		case "EVENT":
+			var ev event
+			if err := json.Unmarshal(msg[1], &ev); err != nil {
+				continue
+			}
+			if err := ev.verify(); err != nil {
+				conn.send([]interface{}{
+					"OK", ev.ID, false, err.Error(),
+				})
+				continue
+			}
+
+			conn.send([]interface{}{"OK", ev.ID, true, ""})
+			r.publish(&ev)
+
+		case "REQ":
+			var (
+				id string
+				f  filter
+			)
+			_ = json.Unmarshal(msg[1], &id)
+			if len(msg) > 2 {
+				_ = json.Unmarshal(msg[2], &f)
+			}
+
+			r.mu.Lock()
+			if r.subs[conn] == nil {
+				r.subs[conn] = make(map[string]*filter)
+			}
+			r.subs[conn][id] = &f
+
+			for _, ev := range r.events {
+				if !matches(&f, ev) {
+					continue
+				}
+
+				conn.send([]interface{}{"EVENT", id, ev})
+			}
+			r.mu.Unlock()
+
+			conn.send([]interface{}{"EOSE", id})
+
+		case "CLOSE":
+			var id string
+			_ = json.Unmarshal(msg[1], &id)
+
+			r.mu.Lock()
+			delete(r.subs[conn], id)
+			r.mu.Unlock()
+		}
+	}
+}
+
+// matches returns true if the given event matches the given filter.
+func matches(f *filter, ev *event) bool {
+	if ev.CreatedAt < f.Since {
+		return false
+	}
+
+	if len(f.Kinds) > 0 {
+		var found bool
+		for _, kind := range f.Kinds {
+			found = found || kind == ev.Kind
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(f.PTags) > 0 {
+		var found bool
+		for _, p := range f.PTags {
+			found = found || p == ev.tag("p")
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// mockLightning is a mock of lnd's main RPC server client that records the
+// call options it is called with.
+type mockLightning struct {
+	lnrpc.LightningClient
+
+	mu       sync.Mutex
+	invoices []*lnrpc.Invoice
+	payments []*lnrpc.Payment
+	balance  uint64
+	lastOpts []grpc.CallOption
+}
+
+func (m *mockLightning) record(opts []grpc.CallOption) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.lastOpts = opts
+}
+
+func (m *mockLightning) AddInvoice(_ context.Context, in *lnrpc.Invoice,
+	opts ...grpc.CallOption) (*lnrpc.AddInvoiceResponse, error) {
+
+	m.record(opts)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	hash := make([]byte, 32)
+	hash[0] = byte(len(m.invoices) + 1)
+
+	invoice := &lnrpc.Invoice{
+		Memo:           in.Memo,
+		ValueMsat:      in.ValueMsat,
+		Expiry:         in.Expiry,
+		RHash:          hash,
+		PaymentRequest: "lnbcrt" + hex.EncodeToString(hash),
+		CreationDate:   1000,
+		AddIndex:       uint64(len(m.invoices) + 1),
+	}
+	m.invoices = append(m.invoices, invoice)
+
+	return &lnrpc.AddInvoiceResponse{
+		RHash:          hash,
+		PaymentRequest: invoice.PaymentRequest,
+	}, nil
+}
+
+func (m *mockLightning) LookupInvoice(_ context.Context,
+	in *lnrpc.PaymentHash, opts ...grpc.CallOption) (*lnrpc.Invoice,
+	error) {
+
+	m.record(opts)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, invoice := range m.invoices {
+		if string(invoice.RHash) == string(in.RHash) {
+			return invoice, nil
+		}
+	}
+
+	return nil, status.Error(codes.NotFound, "unable to locate invoice")
+}
+
+func (m *mockLightning) ChannelBalance(_ context.Context,
+	_ *lnrpc.ChannelBalanceRequest,
+	opts ...grpc.CallOption) (*lnrpc.ChannelBalanceResponse, error) {
+
+	m.record(opts)
+
+	return &lnrpc.ChannelBalanceResponse{
+		LocalBalance: &lnrpc.Amount{Msat: m.balance},
+	}, nil
+}
+
+func (m *mockLightning) ListInvoices(_ context.Context,
+	in *lnrpc.ListInvoiceRequest,
+	opts ...grpc.CallOption) (*lnrpc.ListInvoiceResponse, error) {
+
+	m.record(opts)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Return the invoices before the index offset, newest first.
+	resp := &lnrpc.ListInvoiceResponse{}
+	for i := len(m.invoices) - 1; i >= 0; i-- {
+		invoice := m.invoices[i]
+		offset := in.IndexOffset
+		if offset != 0 && invoice.AddIndex >= offset {
+			continue
+		}
+		if uint64(len(resp.Invoices)) == in.NumMaxInvoices {
+			break
+		}
+
+		resp.Invoices = append(
+			[]*lnrpc.Invoice{invoice}, resp.Invoices...,
+		)
+		resp.FirstIndexOffset = invoice.AddIndex
+	}
+
+	return resp, nil
+}
+
+func (m *mockLightning) ListPayments(_ context.Context,
+	in *lnrpc.ListPaymentsRequest,
+	opts ...grpc.CallOption) (*lnrpc.ListPaymentsResponse, error) {
+
+	m.record(opts)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	resp := &lnrpc.ListPaymentsResponse{}
+	for i := len(m.payments) - 1; i >= 0; i-- {
+		payment := m.payments[i]
+		offset := in.IndexOffset
+		if offset != 0 && payment.PaymentIndex >= offset {
+			continue
+		}
+		if !in.IncludeIncomplete &&
+			payment.Status != lnrpc.Payment_SUCCEEDED {
+
+			continue
+		}
+		if uint64(len(resp.Payments)) == in.MaxPayments {
+			break
+		}
+
+		resp.Payments = append(
+			[]*lnrpc.Payment{payment}, resp.Payments...,
+		)
+		resp.FirstIndexOffset = payment.PaymentIndex
+	}
+
+	return resp, nil
+}
+
+// mockRouter is a mock of lnd's router RPC server client.
+type mockRouter struct {
+	routerrpc.RouterClient
+
+	mu       sync.Mutex
+	result   *lnrpc.Payment
+	err      error
+	requests []*routerrpc.SendPaymentRequest
+}
+
+func (m *mockRouter) SendPaymentV2(_ context.Context,
+	in *routerrpc.SendPaymentRequest,
+	_ ...grpc.CallOption) (routerrpc.Router_SendPaymentV2Client, error) {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests = append(m.requests, in)
+
+	return &mockPaymentStream{
+		payment: m.result,
+		err:     m.err,
+	}, nil
+}
+
+func (m *mockRouter) TrackPaymentV2(_ context.Context,
+	in *routerrpc.TrackPaymentRequest,
+	_ ...grpc.CallOption) (routerrpc.Router_TrackPaymentV2Client, error) {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	hash := hex.EncodeToString(in.PaymentHash)
+	if m.result != nil && m.result.PaymentHash == hash {
+		return &mockPaymentStream{payment: m.result}, nil
+	}
+
+	return &mockPaymentStream{
+		err: status.Error(codes.NotFound, "payment isn't initiated"),
+	}, nil
+}
+
+// mockPaymentStream is a payment update stream that returns a single update
+// or error.
+type mockPaymentStream struct {
+	grpc.ClientStream
+
+	payment *lnrpc.Payment
+	err     error
+	done    bool
+}
+
+func (m *mockPaymentStream) Recv() (*lnrpc.Payment, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	if m.done {
+		return nil, io.EOF
+	}
+	m.done = true
+
+	return m.payment, nil
+}
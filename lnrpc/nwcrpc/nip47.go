@@ -0,0 +1,756 @@
+//go:build nwcrpc
+// +build nwcrpc
+
+package nwcrpc
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lightningnetwork/lnd/lnrpc"
+	"github.com/lightningnetwork/lnd/lnrpc/routerrpc"
+	"github.com/lightningnetwork/lnd/lnwallet"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/macaroons"
+	"github.com/lightningnetwork/lnd/zpay32"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gopkg.in/macaroon-bakery.v2/bakery"
+)
+
+const (
+	methodPayInvoice       = "pay_invoice"
+	methodMakeInvoice      = "make_invoice"
+	methodLookupInvoice    = "lookup_invoice"
+	methodGetBalance       = "get_balance"
+	methodListTransactions = "list_transactions"
+
+	// errCodeRateLimited means the client is sending requests too fast.
+	errCodeRateLimited = "RATE_LIMITED"
+
+	// errCodeNotImplemented means the method isn't known or implemented.
+	errCodeNotImplemented = "NOT_IMPLEMENTED"
+
+	// errCodeInsufficientBalance means the wallet doesn't have enough
+	// funds to complete the payment.
+	errCodeInsufficientBalance = "INSUFFICIENT_BALANCE"
+
+	// errCodeQuotaExceeded means the budget of the connection is used up.
+	errCodeQuotaExceeded = "QUOTA_EXCEEDED"
+
+	// errCodeRestricted means the connection isn't allowed to call the
+	// method.
+	errCodeRestricted = "RESTRICTED"
+
+	// errCodeInternal means an internal error occurred.
+	errCodeInternal = "INTERNAL"
+
+	// errCodePaymentFailed means the payment failed.
+	errCodePaymentFailed = "PAYMENT_FAILED"
+
+	// errCodeNotFound means the requested invoice or payment wasn't found.
+	errCodeNotFound = "NOT_FOUND"
+
+	// errCodeOther means any other error, e.g. an invalid request.
+	errCodeOther = "OTHER"
+
+	// paymentTimeout is the time lnd is given to complete a payment.
+	paymentTimeout = 60 * time.Second
+
+	// maxTransactions is the maximum number of transactions returned by
+	// a single list_transactions request.
+	maxTransactions = 1000
+)
+
+// insufficientBalance is the failure reason of payments that failed because
+// our channels don't have enough outbound liquidity.
+const insufficientBalance = lnrpc.
+	PaymentFailureReason_FAILURE_REASON_INSUFFICIENT_BALANCE
+
+// methodPermissions maps the supported NIP-47 methods to the permissions the
+// macaroon of a connection needs to execute them.
+var methodPermissions = map[string][]bakery.Op{
+	methodPayInvoice: {
+		{Entity: "offchain", Action: "read"},
+		{Entity: "offchain", Action: "write"},
+	},
+	methodMakeInvoice: {
+		{Entity: "invoices", Action: "read"},
+		{Entity: "invoices", Action: "write"},
+	},
+	methodLookupInvoice: {
+		{Entity: "invoices", Action: "read"},
+		{Entity: "offchain", Action: "read"},
+	},
+	methodGetBalance: {
+		{Entity: "offchain", Action: "read"},
+	},
+	methodListTransactions: {
+		{Entity: "invoices", Action: "read"},
+		{Entity: "offchain", Action: "read"},
+	},
+}
+
+// request is the decrypted content of a NIP-47 request event.
+type request struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+// response is the content of a NIP-47 response event before encryption.
+type response struct {
+	ResultType string      `json:"result_type"`
+	Error      *nip47Error `json:"error"`
+	Result     interface{} `json:"result"`
+}
+
+// nip47Error is an error returned to a NIP-47 client.
+type nip47Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// newError creates a new NIP-47 error with the given code and message.
+func newError(code, format string, args ...interface{}) *nip47Error {
+	return &nip47Error{
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// transaction is a NIP-47 transaction, which is either an invoice or a
+// payment.
+type transaction struct {
+	Type            string `json:"type"`
+	State           string `json:"state,omitempty"`
+	Invoice         string `json:"invoice,omitempty"`
+	Description     string `json:"description,omitempty"`
+	DescriptionHash string `json:"description_hash,omitempty"`
+	Preimage        string `json:"preimage,omitempty"`
+	PaymentHash     string `json:"payment_hash"`
+	Amount          int64  `json:"amount"`
+	FeesPaid        int64  `json:"fees_paid"`
+	CreatedAt       int64  `json:"created_at"`
+	ExpiresAt       int64  `json:"expires_at,omitempty"`
+	SettledAt       int64  `json:"settled_at,omitempty"`
+}
+
+type payInvoiceParams struct {
+	Invoice string  `json:"invoice"`
+	Amount  *uint64 `json:"amount"`
+}
+
+type payInvoiceResult struct {
+	Preimage string `json:"preimage"`
+	FeesPaid int64  `json:"fees_paid"`
+}
+
+type makeInvoiceParams struct {
+	Amount          uint64 `json:"amount"`
+	Description     string `json:"description"`
+	DescriptionHash string `json:"description_hash"`
+	Expiry          int64  `json:"expiry"`
+}
+
+type lookupInvoiceParams struct {
+	PaymentHash string `json:"payment_hash"`
+	Invoice     string `json:"invoice"`
+}
+
+type getBalanceResult struct {
+	Balance int64 `json:"balance"`
+}
+
+type listTransactionsParams struct {
+	From   uint64 `json:"from"`
+	Until  uint64 `json:"until"`
+	Limit  uint64 `json:"limit"`
+	Offset uint64 `json:"offset"`
+	Unpaid bool   `json:"unpaid"`
+	Type   string `json:"type"`
+}
+
+type listTransactionsResult struct {
+	Transactions []*transaction `json:"transactions"`
+}
+
+// methodHandler executes a single NIP-47 method for the given connection.
+type methodHandler func(ctx context.Context, c *connection,
+	params json.RawMessage) (interface{}, *nip47Error)
+
+// handlers returns the handlers of all supported NIP-47 methods.
+func (s *walletService) handlers() map[string]methodHandler {
+	return map[string]methodHandler{
+		methodPayInvoice:       s.payInvoice,
+		methodMakeInvoice:      s.makeInvoice,
+		methodLookupInvoice:    s.lookupInvoice,
+		methodGetBalance:       s.getBalance,
+		methodListTransactions: s.listTransactions,
+	}
+}
+
+// executeRequest executes the given decrypted request of the given
+// connection and returns the response that should be sent back to the client.
+func (s *walletService) executeRequest(ctx context.Context, c *connection,
+	content []byte) *response {
+
+	var req request
+	if err := json.Unmarshal(content, &req); err != nil {
+		return &response{
+			Error: newError(errCodeOther, "invalid request: %v",
+				err),
+		}
+	}
+
+	resp := &response{
+		ResultType: req.Method,
+	}
+
+	handler, ok := s.handlers()[req.Method]
+	if !ok {
+		resp.Error = newError(
+			errCodeNotImplemented, "unknown method %v", req.Method,
+		)
+		return resp
+	}
+
+	if _, ok := c.methods[req.Method]; !ok {
+		resp.Error = newError(
+			errCodeRestricted, "method %v not allowed", req.Method,
+		)
+		return resp
+	}
+
+	result, nip47Err := handler(ctx, c, req.Params)
+	if nip47Err != nil {
+		resp.Error = nip47Err
+		return resp
+	}
+	resp.Result = result
+
+	return resp
+}
+
+// callOptions returns the call options that authenticate an RPC call with the
+// macaroon of the given connection.
+func callOptions(c *connection) ([]grpc.CallOption, error) {
+	mac, err := macaroonFromBytes(c.macaroon)
+	if err != nil {
+		return nil, err
+	}
+
+	cred, err := macaroons.NewMacaroonCredential(mac)
+	if err != nil {
+		return nil, err
+	}
+
+	return []grpc.CallOption{grpc.PerRPCCredentials(cred)}, nil
+}
+
+// parseParams decodes the given request parameters into params.
+func parseParams(raw json.RawMessage, params interface{}) *nip47Error {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(raw, params); err != nil {
+		return newError(errCodeOther, "invalid params: %v", err)
+	}
+
+	return nil
+}
+
+// rpcError converts an error returned by an RPC call to a NIP-47 error.
+func rpcError(err error) *nip47Error {
+	msg := status.Convert(err).Message()
+
+	switch {
+	case strings.Contains(msg, macaroons.ErrSpendLimitExceeded.Error()),
+		strings.Contains(msg, macaroons.ErrMaxPaymentExceeded.Error()):
+
+		return newError(errCodeQuotaExceeded, "%v", msg)
+
+	case strings.Contains(msg, macaroons.ErrRateLimitExceeded.Error()):
+		return newError(errCodeRateLimited, "%v", msg)
+
+	case strings.Contains(msg, "permission denied"), strings.Contains(
+		msg, macaroons.ErrDestinationNotAllowed.Error(),
+	):
+
+		return newError(errCodeRestricted, "%v", msg)
+
+	case status.Code(err) == codes.NotFound:
+		return newError(errCodeNotFound, "%v", msg)
+
+	default:
+		return newError(errCodeInternal, "%v", msg)
+	}
+}
+
+// payInvoice pays a BOLT11 invoice.
+func (s *walletService) payInvoice(ctx context.Context, c *connection,
+	raw json.RawMessage) (interface{}, *nip47Error) {
+
+	var params payInvoiceParams
+	if err := parseParams(raw, &params); err != nil {
+		return nil, err
+	}
+
+	payReq, err := zpay32.Decode(params.Invoice, s.cfg.chainParams)
+	if err != nil {
+		return nil, newError(errCodeOther, "invalid invoice: %v", err)
+	}
+
+	req := &routerrpc.SendPaymentRequest{
+		PaymentRequest:    params.Invoice,
+		TimeoutSeconds:    int32(paymentTimeout / time.Second),
+		NoInflightUpdates: true,
+	}
+
+	var amt lnwire.MilliSatoshi
+	switch {
+	case payReq.MilliSat != nil:
+		amt = *payReq.MilliSat
+
+	case params.Amount != nil:
+		amt = lnwire.MilliSatoshi(*params.Amount)
+		req.AmtMsat = int64(amt)
+
+	default:
+		return nil, newError(errCodeOther, "amount required for "+
+			"invoice without amount")
+	}
+	req.FeeLimitMsat = int64(lnwallet.DefaultRoutingFeeLimitForAmount(amt))
+
+	opts, err := callOptions(c)
+	if err != nil {
+		return nil, newError(errCodeInternal, "%v", err)
+	}
+
+	stream, err := s.cfg.router.SendPaymentV2(ctx, req, opts...)
+	if err != nil {
+		return nil, rpcError(err)
+	}
+
+	for {
+		payment, err := stream.Recv()
+		if err != nil {
+			return nil, rpcError(err)
+		}
+
+		switch payment.Status {
+		case lnrpc.Payment_SUCCEEDED:
+			return &payInvoiceResult{
+				Preimage: payment.PaymentPreimage,
+				FeesPaid: payment.FeeMsat,
+			}, nil
+
+		case lnrpc.Payment_FAILED:
+			code := errCodePaymentFailed
+			if payment.FailureReason == insufficientBalance {
+				code = errCodeInsufficientBalance
+			}
+
+			return nil, newError(code, "payment failed: %v",
+				payment.FailureReason)
+		}
+	}
+}
+
+// makeInvoice creates a new invoice.
+func (s *walletService) makeInvoice(ctx context.Context, c *connection,
+	raw json.RawMessage) (interface{}, *nip47Error) {
+
+	var params makeInvoiceParams
+	if err := parseParams(raw, &params); err != nil {
+		return nil, err
+	}
+
+	descriptionHash, err := hex.DecodeString(params.DescriptionHash)
+	if err != nil {
+		return nil, newError(errCodeOther, "invalid description "+
+			"hash: %v", err)
+	}
+
+	opts, err := callOptions(c)
+	if err != nil {
+		return nil, newError(errCodeInternal, "%v", err)
+	}
+
+	addResp, err := s.cfg.lightning.AddInvoice(ctx, &lnrpc.Invoice{
+		Memo:            params.Description,
+		DescriptionHash: descriptionHash,
+		ValueMsat:       int64(params.Amount),
+		Expiry:          params.Expiry,
+	}, opts...)
+	if err != nil {
+		return nil, rpcError(err)
+	}
+
+	invoice, err := s.cfg.lightning.LookupInvoice(ctx, &lnrpc.PaymentHash{
+		RHash: addResp.RHash,
+	}, opts...)
+	if err != nil {
+		return nil, rpcError(err)
+	}
+
+	return invoiceTransaction(invoice, time.Now()), nil
+}
+
+// lookupInvoice looks up an invoice or a payment by its payment hash or
+// invoice.
+func (s *walletService) lookupInvoice(ctx context.Context, c *connection,
+	raw json.RawMessage) (interface{}, *nip47Error) {
+
+	var params lookupInvoiceParams
+	if err := parseParams(raw, &params); err != nil {
+		return nil, err
+	}
+
+	var paymentHash []byte
+	switch {
+	case params.PaymentHash != "":
+		hash, err := hex.DecodeString(params.PaymentHash)
+		if err != nil {
+			return nil, newError(errCodeOther, "invalid payment "+
+				"hash: %v", err)
+		}
+		paymentHash = hash
+
+	case params.Invoice != "":
+		payReq, err := zpay32.Decode(
+			params.Invoice, s.cfg.chainParams,
+		)
+		if err != nil {
+			return nil, newError(errCodeOther, "invalid "+
+				"invoice: %v", err)
+		}
+		if payReq.PaymentHash == nil {
+			return nil, newError(errCodeOther, "invoice "+
+				"without payment hash")
+		}
+		paymentHash = payReq.PaymentHash[:]
+
+	default:
+		return nil, newError(errCodeOther, "payment_hash or "+
+			"invoice required")
+	}
+
+	opts, err := callOptions(c)
+	if err != nil {
+		return nil, newError(errCodeInternal, "%v", err)
+	}
+
+	// We first look for an invoice we created and then for a payment we
+	// made with the given payment hash.
+	invoice, err := s.cfg.lightning.LookupInvoice(ctx, &lnrpc.PaymentHash{
+		RHash: paymentHash,
+	}, opts...)
+	switch {
+	case err == nil:
+		return invoiceTransaction(invoice, time.Now()), nil
+
+	case status.Code(err) != codes.NotFound:
+		return nil, rpcError(err)
+	}
+
+	trackCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := s.cfg.router.TrackPaymentV2(
+		trackCtx, &routerrpc.TrackPaymentRequest{
+			PaymentHash: paymentHash,
+		}, opts...,
+	)
+	if err != nil {
+		return nil, rpcError(err)
+	}
+
+	// The first update contains the current state of the payment.
+	payment, err := stream.Recv()
+	if err != nil {
+		return nil, rpcError(err)
+	}
+
+	return s.paymentTransaction(payment), nil
+}
+
+// getBalance returns the balance that can be spent over our channels.
+func (s *walletService) getBalance(ctx context.Context, c *connection,
+	_ json.RawMessage) (interface{}, *nip47Error) {
+
+	opts, err := callOptions(c)
+	if err != nil {
+		return nil, newError(errCodeInternal, "%v", err)
+	}
+
+	balance, err := s.cfg.lightning.ChannelBalance(
+		ctx, &lnrpc.ChannelBalanceRequest{}, opts...,
+	)
+	if err != nil {
+		return nil, rpcError(err)
+	}
+
+	var msat uint64
+	if balance.LocalBalance != nil {
+		msat = balance.LocalBalance.Msat
+	}
+
+	return &getBalanceResult{
+		Balance: int64(msat),
+	}, nil
+}
+
+// listTransactions returns the invoices and payments of the node, newest
+// first.
+func (s *walletService) listTransactions(ctx context.Context, c *connection,
+	raw json.RawMessage) (interface{}, *nip47Error) {
+
+	var params listTransactionsParams
+	if err := parseParams(raw, &params); err != nil {
+		return nil, err
+	}
+
+	switch params.Type {
+	case "", "incoming", "outgoing":
+	default:
+		return nil, newError(errCodeOther, "invalid type: %v",
+			params.Type)
+	}
+
+	limit := params.Limit
+	if limit == 0 || limit > maxTransactions {
+		limit = maxTransactions
+	}
+
+	// We need at most offset+limit transactions of each type to be able
+	// to return the requested page of the merged list.
+	count := params.Offset + limit
+
+	opts, err := callOptions(c)
+	if err != nil {
+		return nil, newError(errCodeInternal, "%v", err)
+	}
+
+	var txns []*transaction
+	if params.Type != "outgoing" {
+		incoming, err := s.incomingTransactions(
+			ctx, &params, count, opts,
+		)
+		if err != nil {
+			return nil, rpcError(err)
+		}
+		txns = append(txns, incoming...)
+	}
+
+	if params.Type != "incoming" {
+		outgoing, err := s.outgoingTransactions(
+			ctx, &params, count, opts,
+		)
+		if err != nil {
+			return nil, rpcError(err)
+		}
+		txns = append(txns, outgoing...)
+	}
+
+	sort.SliceStable(txns, func(i, j int) bool {
+		return txns[i].CreatedAt > txns[j].CreatedAt
+	})
+
+	if params.Offset >= uint64(len(txns)) {
+		txns = nil
+	} else {
+		txns = txns[params.Offset:]
+	}
+	if uint64(len(txns)) > limit {
+		txns = txns[:limit]
+	}
+
+	return &listTransactionsResult{
+		Transactions: append([]*transaction{}, txns...),
+	}, nil
+}
+
+// incomingTransactions returns up to count of the newest invoices that match
+// the given parameters.
+func (s *walletService) incomingTransactions(ctx context.Context,
+	params *listTransactionsParams, count uint64,
+	opts []grpc.CallOption) ([]*transaction, error) {
+
+	var (
+		txns      []*transaction
+		indexFrom uint64
+		now       = time.Now()
+	)
+	for uint64(len(txns)) < count {
+		resp, err := s.cfg.lightning.ListInvoices(
+			ctx, &lnrpc.ListInvoiceRequest{
+				IndexOffset:       indexFrom,
+				NumMaxInvoices:    count,
+				Reversed:          true,
+				CreationDateStart: params.From,
+				CreationDateEnd:   params.Until,
+			}, opts...,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, invoice := range resp.Invoices {
+			settled := invoice.State == lnrpc.Invoice_SETTLED
+			if !settled && !params.Unpaid {
+				continue
+			}
+
+			txns = append(txns, invoiceTransaction(invoice, now))
+		}
+
+		// The invoices are returned newest first, so we continue
+		// with the invoices before the oldest one of this page.
+		if len(resp.Invoices) == 0 || resp.FirstIndexOffset <= 1 {
+			break
+		}
+		indexFrom = resp.FirstIndexOffset
+	}
+
+	return txns, nil
+}
+
+// outgoingTransactions returns up to count of the newest payments that match
+// the given parameters.
+func (s *walletService) outgoingTransactions(ctx context.Context,
+	params *listTransactionsParams, count uint64,
+	opts []grpc.CallOption) ([]*transaction, error) {
+
+	var (
+		txns      []*transaction
+		indexFrom uint64
+	)
+	for uint64(len(txns)) < count {
+		resp, err := s.cfg.lightning.ListPayments(
+			ctx, &lnrpc.ListPaymentsRequest{
+				IncludeIncomplete: params.Unpaid,
+				IndexOffset:       indexFrom,
+				MaxPayments:       count,
+				Reversed:          true,
+				CreationDateStart: params.From,
+				CreationDateEnd:   params.Until,
+			}, opts...,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, payment := range resp.Payments {
+			txns = append(txns, s.paymentTransaction(payment))
+		}
+
+		// The payments are returned newest first, so we continue
+		// with the payments before the oldest one of this page.
+		if len(resp.Payments) == 0 || resp.FirstIndexOffset <= 1 {
+			break
+		}
+		indexFrom = resp.FirstIndexOffset
+	}
+
+	return txns, nil
+}
+
+// invoiceTransaction converts an invoice to a NIP-47 transaction.
+func invoiceTransaction(invoice *lnrpc.Invoice, now time.Time) *transaction {
+	txn := &transaction{
+		Type:            "incoming",
+		Invoice:         invoice.PaymentRequest,
+		Description:     invoice.Memo,
+		DescriptionHash: hex.EncodeToString(invoice.DescriptionHash),
+		PaymentHash:     hex.EncodeToString(invoice.RHash),
+		Amount:          invoice.ValueMsat,
+		CreatedAt:       invoice.CreationDate,
+		ExpiresAt:       invoice.CreationDate + invoice.Expiry,
+	}
+
+	switch {
+	case invoice.State == lnrpc.Invoice_SETTLED:
+		txn.State = "settled"
+		txn.Preimage = hex.EncodeToString(invoice.RPreimage)
+		txn.Amount = invoice.AmtPaidMsat
+		txn.SettledAt = invoice.SettleDate
+
+	case invoice.State == lnrpc.Invoice_CANCELED:
+		txn.State = "failed"
+
+	case now.Unix() > txn.ExpiresAt:
+		txn.State = "expired"
+
+	default:
+		txn.State = "pending"
+	}
+
+	return txn
+}
+
+// paymentTransaction converts a payment to a NIP-47 transaction.
+func (s *walletService) paymentTransaction(
+	payment *lnrpc.Payment) *transaction {
+
+	txn := &transaction{
+		Type:        "outgoing",
+		Invoice:     payment.PaymentRequest,
+		PaymentHash: payment.PaymentHash,
+		Amount:      payment.ValueMsat,
+		FeesPaid:    payment.FeeMsat,
+		CreatedAt:   payment.CreationTimeNs / int64(time.Second),
+	}
+
+	// The payment itself doesn't store the description of the invoice, so
+	// we take it from the invoice if there is one.
+	if payment.PaymentRequest != "" {
+		payReq, err := zpay32.Decode(
+			payment.PaymentRequest, s.cfg.chainParams,
+		)
+		if err == nil {
+			if payReq.Description != nil {
+				txn.Description = *payReq.Description
+			}
+			if payReq.DescriptionHash != nil {
+				txn.DescriptionHash = hex.EncodeToString(
+					payReq.DescriptionHash[:],
+				)
+			}
+			txn.ExpiresAt = payReq.Timestamp.Add(
+				payReq.Expiry(),
+			).Unix()
+		}
+	}
+
+	switch payment.Status {
+	case lnrpc.Payment_SUCCEEDED:
+		txn.State = "settled"
+		txn.Preimage = payment.PaymentPreimage
+
+		for _, htlc := range payment.Htlcs {
+			if htlc.Status != lnrpc.HTLCAttempt_SUCCEEDED {
+				continue
+			}
+
+			settledAt := htlc.ResolveTimeNs / int64(time.Second)
+			if settledAt > txn.SettledAt {
+				txn.SettledAt = settledAt
+			}
+		}
+
+	case lnrpc.Payment_FAILED:
+		txn.State = "failed"
+
+	default:
+		txn.State = "pending"
+	}
+
+	return txn
+}
@@ -0,0 +1,273 @@
+//go:build nwcrpc
+// +build nwcrpc
+
+package nwcrpc
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+)
+
+const (
+	// kindInfo is the kind of the replaceable event a wallet service
+	// publishes to announce the NIP-47 methods it supports.
+	kindInfo = 13194
+
+	// kindRequest is the kind of the events that carry NIP-47 requests.
+	kindRequest = 23194
+
+	// kindResponse is the kind of the events that carry NIP-47 responses.
+	kindResponse = 23195
+)
+
+var (
+	// errInvalidEventID is returned when the ID of an event doesn't match
+	// its content.
+	errInvalidEventID = errors.New("invalid event ID")
+
+	// errInvalidSignature is returned when the signature of an event is
+	// invalid.
+	errInvalidSignature = errors.New("invalid event signature")
+)
+
+// event is a nostr event as defined by NIP-01.
+type event struct {
+	ID        string     `json:"id"`
+	PubKey    string     `json:"pubkey"`
+	CreatedAt int64      `json:"created_at"`
+	Kind      int        `json:"kind"`
+	Tags      [][]string `json:"tags"`
+	Content   string     `json:"content"`
+	Sig       string     `json:"sig"`
+}
+
+// serialize returns the serialization of the event that is hashed to obtain
+// its ID.
+func (e *event) serialize() ([]byte, error) {
+	tags := e.Tags
+	if tags == nil {
+		tags = [][]string{}
+	}
+
+	// NIP-01 doesn't escape HTML characters, so we can't use
+	// json.Marshal directly.
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+
+	err := enc.Encode([]interface{}{
+		0, e.PubKey, e.CreatedAt, e.Kind, tags, e.Content,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(b.Bytes(), []byte("\n")), nil
+}
+
+// hash returns the hash of the event that is used as its ID.
+func (e *event) hash() ([32]byte, error) {
+	serialized, err := e.serialize()
+	if err != nil {
+		return [32]byte{}, err
+	}
+
+	return sha256.Sum256(serialized), nil
+}
+
+// sign sets the public key, ID and signature of the event using the given
+// private key.
+func (e *event) sign(key *btcec.PrivateKey) error {
+	if e.Tags == nil {
+		e.Tags = [][]string{}
+	}
+	e.PubKey = hex.EncodeToString(schnorr.SerializePubKey(key.PubKey()))
+
+	id, err := e.hash()
+	if err != nil {
+		return err
+	}
+
+	sig, err := schnorr.Sign(key, id[:])
+	if err != nil {
+		return err
+	}
+
+	e.ID = hex.EncodeToString(id[:])
+	e.Sig = hex.EncodeToString(sig.Serialize())
+
+	return nil
+}
+
+// verify makes sure the ID of the event matches its content and that it is
+// signed by its public key.
+func (e *event) verify() error {
+	id, err := e.hash()
+	if err != nil {
+		return err
+	}
+	if hex.EncodeToString(id[:]) != e.ID {
+		return errInvalidEventID
+	}
+
+	pubKey, err := parsePubKey(e.PubKey)
+	if err != nil {
+		return err
+	}
+
+	sigBytes, err := hex.DecodeString(e.Sig)
+	if err != nil {
+		return fmt.Errorf("invalid signature encoding: %w", err)
+	}
+	sig, err := schnorr.ParseSignature(sigBytes)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+
+	if !sig.Verify(id[:], pubKey) {
+		return errInvalidSignature
+	}
+
+	return nil
+}
+
+// tag returns the first value of the first tag with the given name, or an
+// empty string if the event doesn't have such a tag.
+func (e *event) tag(name string) string {
+	for _, tag := range e.Tags {
+		if len(tag) >= 2 && tag[0] == name {
+			return tag[1]
+		}
+	}
+
+	return ""
+}
+
+// expired returns true if the event carries a NIP-40 expiration tag that lies
+// before the given time.
+func (e *event) expired(now time.Time) bool {
+	expiration := e.tag("expiration")
+	if expiration == "" {
+		return false
+	}
+
+	ts, err := strconv.ParseInt(expiration, 10, 64)
+	if err != nil {
+		return false
+	}
+
+	return now.Unix() > ts
+}
+
+// parsePubKey parses a hex encoded x-only public key.
+func parsePubKey(pubKeyHex string) (*btcec.PublicKey, error) {
+	pubKeyBytes, err := hex.DecodeString(pubKeyHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key encoding: %w", err)
+	}
+
+	pubKey, err := schnorr.ParsePubKey(pubKeyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+
+	return pubKey, nil
+}
+
+// pubKeyHex returns the hex encoded x-only public key of the given key.
+func pubKeyHex(pubKey *btcec.PublicKey) string {
+	return hex.EncodeToString(schnorr.SerializePubKey(pubKey))
+}
+
+// nip04Encrypt encrypts the given plaintext for the given public key as
+// defined by NIP-04.
+func nip04Encrypt(key *btcec.PrivateKey, pubKey *btcec.PublicKey,
+	plaintext []byte) (string, error) {
+
+	block, err := aes.NewCipher(btcec.GenerateSharedSecret(key, pubKey))
+	if err != nil {
+		return "", err
+	}
+
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(iv); err != nil {
+		return "", err
+	}
+
+	// Pad the plaintext to a multiple of the block size as defined by
+	// PKCS#7.
+	padding := aes.BlockSize - len(plaintext)%aes.BlockSize
+	padded := make([]byte, len(plaintext)+padding)
+	copy(padded, plaintext)
+	for i := len(plaintext); i < len(padded); i++ {
+		padded[i] = byte(padding)
+	}
+
+	ciphertext := make([]byte, len(padded))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, padded)
+
+	return base64.StdEncoding.EncodeToString(ciphertext) + "?iv=" +
+		base64.StdEncoding.EncodeToString(iv), nil
+}
+
+// nip04Decrypt decrypts the given NIP-04 encrypted content that was encrypted
+// by the given public key.
+func nip04Decrypt(key *btcec.PrivateKey, pubKey *btcec.PublicKey,
+	content string) ([]byte, error) {
+
+	parts := strings.Split(content, "?iv=")
+	if len(parts) != 2 {
+		return nil, errors.New("invalid encrypted content")
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid ciphertext: %w", err)
+	}
+	iv, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid iv: %w", err)
+	}
+
+	if len(iv) != aes.BlockSize {
+		return nil, errors.New("invalid iv length")
+	}
+	if len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+		return nil, errors.New("invalid ciphertext length")
+	}
+
+	block, err := aes.NewCipher(btcec.GenerateSharedSecret(key, pubKey))
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ciphertext)
+
+	// Strip the PKCS#7 padding.
+	padding := int(plaintext[len(plaintext)-1])
+	if padding == 0 || padding > aes.BlockSize {
+		return nil, errors.New("invalid padding")
+	}
+	for _, b := range plaintext[len(plaintext)-padding:] {
+		if int(b) != padding {
+			return nil, errors.New("invalid padding")
+		}
+	}
+
+	return plaintext[:len(plaintext)-padding], nil
+}
@@ -0,0 +1,124 @@
+//go:build nwcrpc
+// +build nwcrpc
+
+package nwcrpc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEventSignVerify tests that signed events can be verified and that any
+// modification of a signed event is detected.
+func TestEventSignVerify(t *testing.T) {
+	t.Parallel()
+
+	key, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	ev := &event{
+		CreatedAt: time.Now().Unix(),
+		Kind:      kindRequest,
+		Tags:      [][]string{{"p", "abc"}},
+		Content:   "<content> & \"quotes\"",
+	}
+	require.NoError(t, ev.sign(key))
+	require.Equal(t, pubKeyHex(key.PubKey()), ev.PubKey)
+	require.NoError(t, ev.verify())
+
+	// Changing the content invalidates the ID.
+	tampered := *ev
+	tampered.Content = "other"
+	require.ErrorIs(t, tampered.verify(), errInvalidEventID)
+
+	// Replacing the signature of the event with the signature of another
+	// event invalidates the signature.
+	other := &event{
+		CreatedAt: ev.CreatedAt,
+		Kind:      kindRequest,
+	}
+	require.NoError(t, other.sign(key))
+
+	tampered = *ev
+	tampered.Sig = other.Sig
+	require.ErrorIs(t, tampered.verify(), errInvalidSignature)
+}
+
+// TestEventSerialize tests that events are serialized as defined by NIP-01,
+// without escaping HTML characters.
+func TestEventSerialize(t *testing.T) {
+	t.Parallel()
+
+	ev := &event{
+		PubKey:    "ab",
+		CreatedAt: 1700000000,
+		Kind:      1,
+		Content:   "a<b>&\n",
+	}
+
+	serialized, err := ev.serialize()
+	require.NoError(t, err)
+	require.Equal(
+		t, `[0,"ab",1700000000,1,[],"a<b>&\n"]`, string(serialized),
+	)
+}
+
+// TestEventExpired tests the handling of NIP-40 expiration tags.
+func TestEventExpired(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1000, 0)
+
+	ev := &event{}
+	require.False(t, ev.expired(now))
+
+	ev.Tags = [][]string{{"expiration", "999"}}
+	require.True(t, ev.expired(now))
+
+	ev.Tags = [][]string{{"expiration", "1001"}}
+	require.False(t, ev.expired(now))
+}
+
+// TestNip04 tests that content encrypted by one party can be decrypted by the
+// other one, but not by a third party.
+func TestNip04(t *testing.T) {
+	t.Parallel()
+
+	alice, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	bob, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	eve, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	// Nostr keys are x-only, so the public keys are always parsed with an
+	// even y coordinate.
+	alicePub, err := parsePubKey(pubKeyHex(alice.PubKey()))
+	require.NoError(t, err)
+	bobPub, err := parsePubKey(pubKeyHex(bob.PubKey()))
+	require.NoError(t, err)
+
+	for _, plaintext := range []string{
+		"", "a", "exactly 16 bytes", `{"method":"get_balance"}`,
+	} {
+		encrypted, err := nip04Encrypt(alice, bobPub, []byte(plaintext))
+		require.NoError(t, err)
+
+		decrypted, err := nip04Decrypt(bob, alicePub, encrypted)
+		require.NoError(t, err)
+		require.Equal(t, plaintext, string(decrypted))
+
+		// A third party derives a different shared secret, so it
+		// either fails to decrypt or gets garbage.
+		decrypted, err = nip04Decrypt(eve, alicePub, encrypted)
+		if err == nil {
+			require.NotEqual(t, plaintext, string(decrypted))
+		}
+	}
+
+	_, err = nip04Decrypt(bob, alicePub, "invalid")
+	require.Error(t, err)
+}
@@ -0,0 +1,191 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package nwcrpc
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// NostrWalletConnectClient is the client API for NostrWalletConnect service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type NostrWalletConnectClient interface {
+	// lncli: nwc addconnection
+	// AddConnection creates a new NWC connection and returns the connection URI
+	// that needs to be passed to the NWC client app. The secret of the client
+	// is only returned once and isn't stored by lnd.
+	AddConnection(ctx context.Context, in *AddConnectionRequest, opts ...grpc.CallOption) (*AddConnectionResponse, error)
+	// lncli: nwc listconnections
+	// ListConnections returns all NWC connections.
+	ListConnections(ctx context.Context, in *ListConnectionsRequest, opts ...grpc.CallOption) (*ListConnectionsResponse, error)
+	// lncli: nwc removeconnection
+	// RemoveConnection removes the NWC connection of the given client and
+	// revokes its macaroon.
+	RemoveConnection(ctx context.Context, in *RemoveConnectionRequest, opts ...grpc.CallOption) (*RemoveConnectionResponse, error)
+}
+
+type nostrWalletConnectClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewNostrWalletConnectClient(cc grpc.ClientConnInterface) NostrWalletConnectClient {
+	return &nostrWalletConnectClient{cc}
+}
+
+func (c *nostrWalletConnectClient) AddConnection(ctx context.Context, in *AddConnectionRequest, opts ...grpc.CallOption) (*AddConnectionResponse, error) {
+	out := new(AddConnectionResponse)
+	err := c.cc.Invoke(ctx, "/nwcrpc.NostrWalletConnect/AddConnection", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nostrWalletConnectClient) ListConnections(ctx context.Context, in *ListConnectionsRequest, opts ...grpc.CallOption) (*ListConnectionsResponse, error) {
+	out := new(ListConnectionsResponse)
+	err := c.cc.Invoke(ctx, "/nwcrpc.NostrWalletConnect/ListConnections", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nostrWalletConnectClient) RemoveConnection(ctx context.Context, in *RemoveConnectionRequest, opts ...grpc.CallOption) (*RemoveConnectionResponse, error) {
+	out := new(RemoveConnectionResponse)
+	err := c.cc.Invoke(ctx, "/nwcrpc.NostrWalletConnect/RemoveConnection", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// NostrWalletConnectServer is the server API for NostrWalletConnect service.
+// All implementations must embed UnimplementedNostrWalletConnectServer
+// for forward compatibility
+type NostrWalletConnectServer interface {
+	// lncli: nwc addconnection
+	// AddConnection creates a new NWC connection and returns the connection URI
+	// that needs to be passed to the NWC client app. The secret of the client
+	// is only returned once and isn't stored by lnd.
+	AddConnection(context.Context, *AddConnectionRequest) (*AddConnectionResponse, error)
+	// lncli: nwc listconnections
+	// ListConnections returns all NWC connections.
+	ListConnections(context.Context, *ListConnectionsRequest) (*ListConnectionsResponse, error)
+	// lncli: nwc removeconnection
+	// RemoveConnection removes the NWC connection of the given client and
+	// revokes its macaroon.
+	RemoveConnection(context.Context, *RemoveConnectionRequest) (*RemoveConnectionResponse, error)
+	mustEmbedUnimplementedNostrWalletConnectServer()
+}
+
+// UnimplementedNostrWalletConnectServer must be embedded to have forward compatible implementations.
+type UnimplementedNostrWalletConnectServer struct {
+}
+
+func (UnimplementedNostrWalletConnectServer) AddConnection(context.Context, *AddConnectionRequest) (*AddConnectionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddConnection not implemented")
+}
+func (UnimplementedNostrWalletConnectServer) ListConnections(context.Context, *ListConnectionsRequest) (*ListConnectionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListConnections not implemented")
+}
+func (UnimplementedNostrWalletConnectServer) RemoveConnection(context.Context, *RemoveConnectionRequest) (*RemoveConnectionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveConnection not implemented")
+}
+func (UnimplementedNostrWalletConnectServer) mustEmbedUnimplementedNostrWalletConnectServer() {}
+
+// UnsafeNostrWalletConnectServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to NostrWalletConnectServer will
+// result in compilation errors.
+type UnsafeNostrWalletConnectServer interface {
+	mustEmbedUnimplementedNostrWalletConnectServer()
+}
+
+func RegisterNostrWalletConnectServer(s grpc.ServiceRegistrar, srv NostrWalletConnectServer) {
+	s.RegisterService(&NostrWalletConnect_ServiceDesc, srv)
+}
+
+func _NostrWalletConnect_AddConnection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddConnectionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NostrWalletConnectServer).AddConnection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/nwcrpc.NostrWalletConnect/AddConnection",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NostrWalletConnectServer).AddConnection(ctx, req.(*AddConnectionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NostrWalletConnect_ListConnections_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListConnectionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NostrWalletConnectServer).ListConnections(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/nwcrpc.NostrWalletConnect/ListConnections",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NostrWalletConnectServer).ListConnections(ctx, req.(*ListConnectionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NostrWalletConnect_RemoveConnection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveConnectionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NostrWalletConnectServer).RemoveConnection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/nwcrpc.NostrWalletConnect/RemoveConnection",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NostrWalletConnectServer).RemoveConnection(ctx, req.(*RemoveConnectionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// NostrWalletConnect_ServiceDesc is the grpc.ServiceDesc for NostrWalletConnect service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var NostrWalletConnect_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "nwcrpc.NostrWalletConnect",
+	HandlerType: (*NostrWalletConnectServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AddConnection",
+			Handler:    _NostrWalletConnect_AddConnection_Handler,
+		},
+		{
+			MethodName: "ListConnections",
+			Handler:    _NostrWalletConnect_ListConnections_Handler,
+		},
+		{
+			MethodName: "RemoveConnection",
+			Handler:    _NostrWalletConnect_RemoveConnection_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "nwcrpc/nwc.proto",
+}
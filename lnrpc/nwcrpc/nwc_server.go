@@ -0,0 +1,290 @@
+//go:build nwcrpc
+// +build nwcrpc
+
+package nwcrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/lightningnetwork/lnd/lnrpc"
+	"github.com/lightningnetwork/lnd/lnrpc/routerrpc"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"google.golang.org/grpc"
+	"gopkg.in/macaroon-bakery.v2/bakery"
+)
+
+const (
+	// subServerName is the name of the sub rpc server. We'll use this name
+	// to register ourselves, and we also require that the main
+	// SubServerConfigDispatcher instance recognize it as the name of our
+	// RPC service.
+	subServerName = "NWCRPC"
+)
+
+var (
+	// macPermissions maps RPC calls to the permissions they require. Since
+	// adding a connection bakes a new macaroon, the same permissions as
+	// for the macaroon RPCs of the main RPC server are required.
+	macPermissions = map[string][]bakery.Op{
+		"/nwcrpc.NostrWalletConnect/AddConnection": {{
+			Entity: "macaroon",
+			Action: "generate",
+		}},
+		"/nwcrpc.NostrWalletConnect/ListConnections": {{
+			Entity: "macaroon",
+			Action: "read",
+		}},
+		"/nwcrpc.NostrWalletConnect/RemoveConnection": {{
+			Entity: "macaroon",
+			Action: "write",
+		}},
+	}
+
+	// ErrNWCInactive is returned when a connection is managed while the
+	// NWC wallet service isn't running.
+	ErrNWCInactive = errors.New("nostr wallet connect is not active, " +
+		"set nwcrpc.relay to enable it")
+)
+
+// ServerShell is a shell struct holding a reference to the actual sub-server.
+// It is used to register the gRPC sub-server with the root server before we
+// have the necessary dependencies to populate the actual sub-server.
+type ServerShell struct {
+	NostrWalletConnectServer
+}
+
+// Server is a sub-server of the main RPC server: the NWC RPC. This sub RPC
+// server manages the Nostr Wallet Connect connections of client apps.
+type Server struct {
+	started  int32 // To be used atomically.
+	shutdown int32 // To be used atomically.
+
+	// Required by the grpc-gateway/v2 library for forward compatibility.
+	// Must be after the atomically used variables to not break struct
+	// alignment.
+	UnimplementedNostrWalletConnectServer
+
+	cfg *Config
+
+	// lndConn is the connection to lnd's own gRPC server that is used to
+	// execute the requests of the NWC clients.
+	lndConn *grpc.ClientConn
+
+	// wallet is the NWC wallet service. It is nil if no relay is
+	// configured.
+	wallet *walletService
+}
+
+// A compile time check to ensure that Server fully implements the
+// NostrWalletConnectServer gRPC service.
+var _ NostrWalletConnectServer = (*Server)(nil)
+
+// New returns a new instance of the nwcrpc NostrWalletConnect sub-server. We
+// also return the set of permissions for the macaroons that we may create
+// within this method.
+func New(cfg *Config) (*Server, lnrpc.MacaroonPerms, error) {
+	server := &Server{
+		cfg: cfg,
+	}
+
+	return server, macPermissions, nil
+}
+
+// Start launches any helper goroutines required for the Server to function.
+//
+// NOTE: This is part of the lnrpc.SubServer interface.
+func (s *Server) Start() error {
+	if atomic.AddInt32(&s.started, 1) != 1 {
+		return nil
+	}
+
+	if s.cfg.Relay == "" {
+		log.Infof("No relay configured, nostr wallet connect disabled")
+		return nil
+	}
+
+	// The connections are stored encrypted in the macaroon database and
+	// the requests are executed with scoped macaroons, so we can't run
+	// without macaroons.
+	if s.cfg.MacService == nil {
+		return fmt.Errorf("nostr wallet connect requires macaroons " +
+			"to be enabled")
+	}
+
+	conn, err := s.cfg.DialLnd()
+	if err != nil {
+		return fmt.Errorf("unable to connect to lnd: %w", err)
+	}
+	s.lndConn = conn
+
+	s.wallet = newWalletService(&walletServiceConfig{
+		relay:       s.cfg.Relay,
+		macService:  s.cfg.MacService,
+		chainParams: s.cfg.ChainParams,
+		lightning:   lnrpc.NewLightningClient(conn),
+		router:      routerrpc.NewRouterClient(conn),
+	})
+
+	return s.wallet.start()
+}
+
+// Stop signals any active goroutines for a graceful closure.
+//
+// NOTE: This is part of the lnrpc.SubServer interface.
+func (s *Server) Stop() error {
+	if atomic.AddInt32(&s.shutdown, 1) != 1 {
+		return nil
+	}
+
+	if s.wallet != nil {
+		s.wallet.stop()
+	}
+
+	if s.lndConn != nil {
+		return s.lndConn.Close()
+	}
+
+	return nil
+}
+
+// Name returns a unique string representation of the sub-server. This can be
+// used to identify the sub-server and also de-duplicate them.
+//
+// NOTE: This is part of the lnrpc.SubServer interface.
+func (s *Server) Name() string {
+	return subServerName
+}
+
+// RegisterWithRootServer will be called by the root gRPC server to direct a
+// sub RPC server to register itself with the main gRPC root server. Until this
+// is called, each sub-server won't be able to have requests routed towards it.
+//
+// NOTE: This is part of the lnrpc.GrpcHandler interface.
+func (r *ServerShell) RegisterWithRootServer(grpcServer *grpc.Server) error {
+	// We make sure that we register it with the main gRPC server to ensure
+	// all our methods are routed properly.
+	RegisterNostrWalletConnectServer(grpcServer, r)
+
+	log.Debugf("NWC RPC server successfully registered with root gRPC " +
+		"server")
+
+	return nil
+}
+
+// RegisterWithRestServer will be called by the root REST mux to direct a sub
+// RPC server to register itself with the main REST mux server. Until this is
+// called, each sub-server won't be able to have requests routed towards it.
+//
+// NOTE: This is part of the lnrpc.GrpcHandler interface.
+func (r *ServerShell) RegisterWithRestServer(ctx context.Context,
+	mux *runtime.ServeMux, dest string, opts []grpc.DialOption) error {
+
+	// We make sure that we register it with the main REST server to ensure
+	// all our methods are routed properly.
+	err := RegisterNostrWalletConnectHandlerFromEndpoint(
+		ctx, mux, dest, opts,
+	)
+	if err != nil {
+		log.Errorf("Could not register NWC REST server with root REST "+
+			"server: %v", err)
+		return err
+	}
+
+	log.Debugf("NWC REST server successfully registered with root REST " +
+		"server")
+	return nil
+}
+
+// CreateSubServer populates the subserver's dependencies using the passed
+// SubServerConfigDispatcher. This method should fully initialize the
+// sub-server instance, making it ready for action. It returns the macaroon
+// permissions that the sub-server wishes to pass on to the root server for all
+// methods routed towards it.
+//
+// NOTE: This is part of the lnrpc.GrpcHandler interface.
+func (r *ServerShell) CreateSubServer(configRegistry lnrpc.SubServerConfigDispatcher) (
+	lnrpc.SubServer, lnrpc.MacaroonPerms, error) {
+
+	subServer, macPermissions, err := createNewSubServer(configRegistry)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r.NostrWalletConnectServer = subServer
+	return subServer, macPermissions, nil
+}
+
+// AddConnection creates a new NWC connection and returns the connection URI
+// that needs to be passed to the NWC client app.
+func (s *Server) AddConnection(ctx context.Context,
+	req *AddConnectionRequest) (*AddConnectionResponse, error) {
+
+	if s.wallet == nil {
+		return nil, ErrNWCInactive
+	}
+
+	if req.Name == "" {
+		return nil, fmt.Errorf("connection name required")
+	}
+
+	c, uri, err := s.wallet.addConnection(ctx, &addConnectionParams{
+		name:    req.Name,
+		methods: req.Methods,
+		budget:  lnwire.MilliSatoshi(req.BudgetMsat),
+		budgetRenewal: time.Duration(req.BudgetRenewalSecs) *
+			time.Second,
+		maxPayment:           lnwire.MilliSatoshi(req.MaxPaymentMsat),
+		maxRequestsPerMinute: req.MaxRequestsPerMinute,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &AddConnectionResponse{
+		Connection:    c.toRPC(s.cfg.Relay),
+		ConnectionUri: uri,
+	}, nil
+}
+
+// ListConnections returns all NWC connections.
+func (s *Server) ListConnections(_ context.Context,
+	_ *ListConnectionsRequest) (*ListConnectionsResponse, error) {
+
+	if s.wallet == nil {
+		return nil, ErrNWCInactive
+	}
+
+	connections := s.wallet.listConnections()
+	resp := &ListConnectionsResponse{
+		Connections: make([]*Connection, 0, len(connections)),
+	}
+	for _, c := range connections {
+		resp.Connections = append(
+			resp.Connections, c.toRPC(s.cfg.Relay),
+		)
+	}
+
+	return resp, nil
+}
+
+// RemoveConnection removes the NWC connection of the given client and revokes
+// its macaroon.
+func (s *Server) RemoveConnection(ctx context.Context,
+	req *RemoveConnectionRequest) (*RemoveConnectionResponse, error) {
+
+	if s.wallet == nil {
+		return nil, ErrNWCInactive
+	}
+
+	err := s.wallet.removeConnection(ctx, req.ClientPubkey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RemoveConnectionResponse{}, nil
+}
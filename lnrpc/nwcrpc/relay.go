@@ -0,0 +1,291 @@
+//go:build nwcrpc
+// +build nwcrpc
+
+package nwcrpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// subscriptionID is the ID of our subscription for NIP-47 requests.
+	subscriptionID = "nwc-requests"
+
+	// minReconnectBackoff is the initial time we wait before reconnecting
+	// to the relay after the connection was lost.
+	minReconnectBackoff = time.Second
+
+	// maxReconnectBackoff is the maximum time we wait before reconnecting
+	// to the relay.
+	maxReconnectBackoff = time.Minute
+
+	// writeTimeout is the maximum time a single message may take to be
+	// written to the relay.
+	writeTimeout = 10 * time.Second
+)
+
+var (
+	// errRelayNotConnected is returned when a message should be sent to
+	// the relay while we're not connected to it.
+	errRelayNotConnected = errors.New("not connected to relay")
+)
+
+// filter is a NIP-01 subscription filter.
+type filter struct {
+	Kinds []int    `json:"kinds,omitempty"`
+	PTags []string `json:"#p,omitempty"`
+	Since int64    `json:"since,omitempty"`
+}
+
+// relayConfig holds the configuration of a relay client.
+type relayConfig struct {
+	// url is the websocket URL of the relay.
+	url string
+
+	// filter returns the filter of our subscription. If it returns nil,
+	// we don't subscribe to any events.
+	filter func() *filter
+
+	// onConnect is called every time we (re)connected to the relay.
+	onConnect func()
+
+	// onEvent is called for every event the relay sends for our
+	// subscription.
+	onEvent func(*event)
+}
+
+// relayClient maintains a websocket connection to a nostr relay, reconnecting
+// whenever the connection is lost.
+type relayClient struct {
+	cfg *relayConfig
+
+	dialer *websocket.Dialer
+
+	// conn is the current connection to the relay. It is nil while we're
+	// not connected.
+	conn    *websocket.Conn
+	connMtx sync.Mutex
+
+	// writeMtx serializes the writes to the connection, since the
+	// websocket library doesn't allow concurrent writers.
+	writeMtx sync.Mutex
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// newRelayClient creates a new relay client with the given config.
+func newRelayClient(cfg *relayConfig) *relayClient {
+	return &relayClient{
+		cfg:    cfg,
+		dialer: websocket.DefaultDialer,
+		quit:   make(chan struct{}),
+	}
+}
+
+// start launches the goroutine that maintains the connection to the relay.
+func (r *relayClient) start() {
+	r.wg.Add(1)
+	go r.connectionLoop()
+}
+
+// stop closes the connection to the relay and waits for all goroutines to
+// exit.
+func (r *relayClient) stop() {
+	close(r.quit)
+
+	r.connMtx.Lock()
+	if r.conn != nil {
+		_ = r.conn.Close()
+	}
+	r.connMtx.Unlock()
+
+	r.wg.Wait()
+}
+
+// connectionLoop connects to the relay and reads its messages until the
+// connection is lost, in which case it reconnects with an exponential
+// backoff.
+//
+// NOTE: This MUST be run as a goroutine.
+func (r *relayClient) connectionLoop() {
+	defer r.wg.Done()
+
+	backoff := minReconnectBackoff
+	for {
+		connected, err := r.connectAndRead()
+
+		select {
+		case <-r.quit:
+			return
+		default:
+		}
+
+		// If we were connected successfully, the connection was lost
+		// and not refused, so we can reconnect quickly again.
+		if connected {
+			backoff = minReconnectBackoff
+		}
+
+		log.Warnf("Connection to relay %v lost: %v, reconnecting in %v",
+			r.cfg.url, err, backoff)
+
+		select {
+		case <-time.After(backoff):
+		case <-r.quit:
+			return
+		}
+
+		backoff *= 2
+		if backoff > maxReconnectBackoff {
+			backoff = maxReconnectBackoff
+		}
+	}
+}
+
+// connectAndRead connects to the relay, subscribes to our events and then
+// reads messages until the connection fails. The returned boolean indicates
+// whether the connection was established before it failed.
+func (r *relayClient) connectAndRead() (bool, error) {
+	conn, _, err := r.dialer.Dial(r.cfg.url, nil)
+	if err != nil {
+		return false, err
+	}
+
+	r.connMtx.Lock()
+	select {
+	case <-r.quit:
+		r.connMtx.Unlock()
+		_ = conn.Close()
+		return false, nil
+	default:
+	}
+	r.conn = conn
+	r.connMtx.Unlock()
+
+	defer func() {
+		r.connMtx.Lock()
+		r.conn = nil
+		r.connMtx.Unlock()
+
+		_ = conn.Close()
+	}()
+
+	log.Infof("Connected to relay %v", r.cfg.url)
+
+	if err := r.subscribe(); err != nil {
+		return true, err
+	}
+	r.cfg.onConnect()
+
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			return true, err
+		}
+
+		r.handleMessage(msg)
+	}
+}
+
+// handleMessage handles a single message sent by the relay.
+func (r *relayClient) handleMessage(msg []byte) {
+	var parts []json.RawMessage
+	if err := json.Unmarshal(msg, &parts); err != nil || len(parts) == 0 {
+		log.Debugf("Ignoring invalid relay message: %s", msg)
+		return
+	}
+
+	var msgType string
+	if err := json.Unmarshal(parts[0], &msgType); err != nil {
+		log.Debugf("Ignoring invalid relay message: %s", msg)
+		return
+	}
+
+	switch msgType {
+	case "EVENT":
+		if len(parts) != 3 {
+			log.Debugf("Ignoring invalid event message: %s", msg)
+			return
+		}
+
+		var ev event
+		if err := json.Unmarshal(parts[2], &ev); err != nil {
+			log.Debugf("Ignoring invalid event: %v", err)
+			return
+		}
+
+		r.cfg.onEvent(&ev)
+
+	case "OK":
+		var (
+			id       string
+			accepted bool
+			reason   string
+		)
+		if len(parts) >= 3 {
+			_ = json.Unmarshal(parts[1], &id)
+			_ = json.Unmarshal(parts[2], &accepted)
+		}
+		if len(parts) >= 4 {
+			_ = json.Unmarshal(parts[3], &reason)
+		}
+		if !accepted {
+			log.Warnf("Relay rejected event %v: %v", id, reason)
+		}
+
+	case "NOTICE", "CLOSED":
+		log.Infof("Relay %v sent %v: %s", r.cfg.url, msgType, msg)
+
+	default:
+		log.Tracef("Ignoring relay message: %s", msg)
+	}
+}
+
+// subscribe (re)sends our subscription to the relay. Sending a subscription
+// with the same ID replaces the previous one, so this can be used to update
+// the filter.
+func (r *relayClient) subscribe() error {
+	f := r.cfg.filter()
+	if f == nil {
+		return r.send([]interface{}{"CLOSE", subscriptionID})
+	}
+
+	return r.send([]interface{}{"REQ", subscriptionID, f})
+}
+
+// publish sends the given event to the relay.
+func (r *relayClient) publish(ev *event) error {
+	return r.send([]interface{}{"EVENT", ev})
+}
+
+// send writes the given message to the relay.
+func (r *relayClient) send(msg interface{}) error {
+	r.connMtx.Lock()
+	conn := r.conn
+	r.connMtx.Unlock()
+
+	if conn == nil {
+		return errRelayNotConnected
+	}
+
+	r.writeMtx.Lock()
+	defer r.writeMtx.Unlock()
+
+	err := conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if err != nil {
+		return err
+	}
+
+	if err := conn.WriteJSON(msg); err != nil {
+		return fmt.Errorf("unable to write to relay: %w", err)
+	}
+
+	return nil
+}
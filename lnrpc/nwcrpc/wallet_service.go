@@ -0,0 +1,573 @@
+//go:build nwcrpc
+// +build nwcrpc
+
+package nwcrpc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/lightningnetwork/lnd/lnrpc"
+	"github.com/lightningnetwork/lnd/lnrpc/routerrpc"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/macaroons"
+	"gopkg.in/macaroon-bakery.v2/bakery"
+	"gopkg.in/macaroon.v2"
+)
+
+const (
+	// requestMaxAge is the maximum age of a request we still execute.
+	// Older requests are ignored, so requests that were queued on the
+	// relay while we were offline aren't executed long after the client
+	// gave up on them.
+	requestMaxAge = 10 * time.Minute
+
+	// requestTimeout is the maximum time the execution of a single request
+	// may take.
+	requestTimeout = paymentTimeout + 30*time.Second
+)
+
+var (
+	// ErrConnectionNotFound is returned when a connection doesn't exist.
+	ErrConnectionNotFound = errors.New("connection not found")
+)
+
+// walletServiceConfig holds the dependencies of the wallet service.
+type walletServiceConfig struct {
+	// relay is the websocket URL of the relay.
+	relay string
+
+	// macService is used to bake the macaroons of the connections and to
+	// store them encrypted.
+	macService *macaroons.Service
+
+	// chainParams are the parameters of the chain lnd is running on.
+	chainParams *chaincfg.Params
+
+	// lightning is the client of lnd's main RPC server.
+	lightning lnrpc.LightningClient
+
+	// router is the client of lnd's router RPC server.
+	router routerrpc.RouterClient
+}
+
+// addConnectionParams are the parameters of a new connection.
+type addConnectionParams struct {
+	name                 string
+	methods              []string
+	budget               lnwire.MilliSatoshi
+	budgetRenewal        time.Duration
+	maxPayment           lnwire.MilliSatoshi
+	maxRequestsPerMinute uint32
+}
+
+// walletService is a NIP-47 wallet service. It listens for requests of the
+// NWC clients on the relay, executes them against lnd's RPC servers using the
+// macaroon of their connection and sends the responses back to the relay.
+type walletService struct {
+	cfg *walletServiceConfig
+
+	store *connectionStore
+	relay *relayClient
+
+	// startTime is the time the service was started. No requests that
+	// were created before it are executed.
+	startTime time.Time
+
+	// connections maps the hex encoded wallet public keys to their
+	// connection.
+	connections map[string]*connection
+
+	// handled maps the IDs of the request events that were already
+	// handled to their creation time, so requests that are sent to us
+	// more than once are only executed once.
+	handled map[string]int64
+
+	mu sync.Mutex
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// newWalletService creates a new wallet service with the given config.
+func newWalletService(cfg *walletServiceConfig) *walletService {
+	s := &walletService{
+		cfg: cfg,
+		store: &connectionStore{
+			secrets: cfg.macService,
+		},
+		connections: make(map[string]*connection),
+		handled:     make(map[string]int64),
+		quit:        make(chan struct{}),
+	}
+
+	s.relay = newRelayClient(&relayConfig{
+		url:       cfg.relay,
+		filter:    s.filter,
+		onConnect: s.publishInfoEvents,
+		onEvent:   s.handleEvent,
+	})
+
+	return s
+}
+
+// start loads all connections and connects to the relay.
+func (s *walletService) start() error {
+	connections, err := s.store.fetchAll()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.startTime = time.Now()
+	for _, c := range connections {
+		s.connections[c.walletID()] = c
+	}
+	s.mu.Unlock()
+
+	log.Infof("Starting NWC wallet service with %d connections on relay "+
+		"%v", len(connections), s.cfg.relay)
+
+	s.relay.start()
+
+	return nil
+}
+
+// stop disconnects from the relay and waits for all pending requests to
+// finish.
+func (s *walletService) stop() {
+	close(s.quit)
+	s.relay.stop()
+	s.wg.Wait()
+}
+
+// addConnection creates a new connection with the given parameters. It
+// returns the connection and the URI the client app needs to connect.
+func (s *walletService) addConnection(ctx context.Context,
+	params *addConnectionParams) (*connection, string, error) {
+
+	methods := make(map[string]struct{})
+	for _, method := range params.methods {
+		if _, ok := methodPermissions[method]; !ok {
+			return nil, "", fmt.Errorf("unsupported method: %v",
+				method)
+		}
+
+		methods[method] = struct{}{}
+	}
+
+	// If no methods were given, all supported methods are allowed.
+	if len(methods) == 0 {
+		for method := range methodPermissions {
+			methods[method] = struct{}{}
+		}
+	}
+
+	// The macaroon of the connection only grants the permissions the
+	// allowed methods need.
+	opSet := make(map[bakery.Op]struct{})
+	for method := range methods {
+		for _, op := range methodPermissions[method] {
+			opSet[op] = struct{}{}
+		}
+	}
+	ops := make([]bakery.Op, 0, len(opSet))
+	for op := range opSet {
+		ops = append(ops, op)
+	}
+
+	budgetRenewal := params.budgetRenewal
+	if budgetRenewal == 0 {
+		budgetRenewal = defaultBudgetRenewal
+	}
+	if params.budget != 0 && budgetRenewal < time.Second {
+		return nil, "", fmt.Errorf("budget renewal must be at least " +
+			"one second")
+	}
+
+	walletKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		return nil, "", err
+	}
+	clientKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		return nil, "", err
+	}
+
+	c := &connection{
+		name:                 params.name,
+		walletKey:            walletKey,
+		clientPubKey:         clientKey.PubKey(),
+		methods:              methods,
+		budget:               params.budget,
+		budgetRenewal:        budgetRenewal,
+		maxPayment:           params.maxPayment,
+		maxRequestsPerMinute: params.maxRequestsPerMinute,
+		createdAt:            time.Now(),
+	}
+
+	if err := s.bakeMacaroon(ctx, c, ops); err != nil {
+		return nil, "", err
+	}
+
+	if err := s.store.store(c); err != nil {
+		return nil, "", err
+	}
+
+	s.mu.Lock()
+	s.connections[c.walletID()] = c
+	s.mu.Unlock()
+
+	// Make sure we receive the requests of the new connection and that
+	// the client can find out which methods it may call.
+	if err := s.relay.subscribe(); err != nil {
+		log.Warnf("Unable to update relay subscription: %v", err)
+	}
+	s.publishInfoEvent(c)
+
+	log.Infof("Added NWC connection %v (client=%v)", c.name, c.clientID())
+
+	return c, connectionURI(walletKey.PubKey(), s.cfg.relay, clientKey),
+		nil
+}
+
+// bakeMacaroon bakes the macaroon of the given connection with a new root key
+// ID, so it can be revoked independently of all other macaroons.
+func (s *walletService) bakeMacaroon(ctx context.Context, c *connection,
+	ops []bakery.Op) error {
+
+	rootKeyID, err := s.newRootKeyID(ctx)
+	if err != nil {
+		return err
+	}
+
+	bakedMac, err := s.cfg.macService.NewMacaroon(
+		ctx, []byte(strconv.FormatUint(rootKeyID, 10)), ops...,
+	)
+	if err != nil {
+		return err
+	}
+
+	var constraints []macaroons.Constraint
+	if c.budget != 0 {
+		constraints = append(
+			constraints, macaroons.SpendLimitConstraint(
+				c.budget, c.budgetRenewal,
+			),
+		)
+	}
+	if c.maxPayment != 0 {
+		constraints = append(
+			constraints, macaroons.MaxPaymentConstraint(
+				c.maxPayment,
+			),
+		)
+	}
+	if c.maxRequestsPerMinute != 0 {
+		constraints = append(
+			constraints, macaroons.RateLimitConstraint(
+				c.maxRequestsPerMinute,
+			),
+		)
+	}
+
+	mac, err := macaroons.AddConstraints(bakedMac.M(), constraints...)
+	if err != nil {
+		return err
+	}
+
+	c.macaroon, err = mac.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	c.rootKeyID = rootKeyID
+
+	return nil
+}
+
+// newRootKeyID returns a random root key ID that isn't used by any macaroon
+// yet.
+func (s *walletService) newRootKeyID(ctx context.Context) (uint64, error) {
+	ids, err := s.cfg.macService.ListMacaroonIDs(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	used := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		used[string(id)] = struct{}{}
+	}
+
+	for {
+		var b [8]byte
+		if _, err := rand.Read(b[:]); err != nil {
+			return 0, err
+		}
+
+		// The default root key ID is 0, so we make sure to never use
+		// it.
+		id := binary.BigEndian.Uint64(b[:])
+		if id == 0 {
+			continue
+		}
+
+		if _, ok := used[strconv.FormatUint(id, 10)]; !ok {
+			return id, nil
+		}
+	}
+}
+
+// listConnections returns all connections, oldest first.
+func (s *walletService) listConnections() []*connection {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	connections := make([]*connection, 0, len(s.connections))
+	for _, c := range s.connections {
+		connections = append(connections, c)
+	}
+
+	sort.Slice(connections, func(i, j int) bool {
+		return connections[i].createdAt.Before(connections[j].createdAt)
+	})
+
+	return connections
+}
+
+// removeConnection removes the connection of the given client and revokes
+// its macaroon.
+func (s *walletService) removeConnection(ctx context.Context,
+	clientID string) error {
+
+	clientID = strings.ToLower(clientID)
+
+	s.mu.Lock()
+	var c *connection
+	for _, candidate := range s.connections {
+		if candidate.clientID() == clientID {
+			c = candidate
+			break
+		}
+	}
+	s.mu.Unlock()
+
+	if c == nil {
+		return ErrConnectionNotFound
+	}
+
+	if err := s.store.delete(clientID); err != nil {
+		return err
+	}
+
+	_, err := s.cfg.macService.DeleteMacaroonID(
+		ctx, []byte(strconv.FormatUint(c.rootKeyID, 10)),
+	)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	delete(s.connections, c.walletID())
+	s.mu.Unlock()
+
+	if err := s.relay.subscribe(); err != nil {
+		log.Warnf("Unable to update relay subscription: %v", err)
+	}
+
+	log.Infof("Removed NWC connection %v (client=%v)", c.name, clientID)
+
+	return nil
+}
+
+// filter returns the filter for the requests to all our wallet keys.
+func (s *walletService) filter() *filter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.connections) == 0 {
+		return nil
+	}
+
+	walletIDs := make([]string, 0, len(s.connections))
+	for walletID := range s.connections {
+		walletIDs = append(walletIDs, walletID)
+	}
+	sort.Strings(walletIDs)
+
+	since := time.Now().Add(-requestMaxAge)
+	if since.Before(s.startTime) {
+		since = s.startTime
+	}
+
+	return &filter{
+		Kinds: []int{kindRequest},
+		PTags: walletIDs,
+		Since: since.Unix(),
+	}
+}
+
+// publishInfoEvents publishes the info events of all connections.
+func (s *walletService) publishInfoEvents() {
+	for _, c := range s.listConnections() {
+		s.publishInfoEvent(c)
+	}
+}
+
+// publishInfoEvent publishes the info event that tells the client of the
+// given connection which methods it may call.
+func (s *walletService) publishInfoEvent(c *connection) {
+	ev := &event{
+		CreatedAt: time.Now().Unix(),
+		Kind:      kindInfo,
+		Content:   strings.Join(c.sortedMethods(), " "),
+	}
+	if err := ev.sign(c.walletKey); err != nil {
+		log.Errorf("Unable to sign info event: %v", err)
+		return
+	}
+
+	if err := s.relay.publish(ev); err != nil {
+		log.Debugf("Unable to publish info event: %v", err)
+	}
+}
+
+// handleEvent validates a request event received from the relay and executes
+// it in a new goroutine.
+func (s *walletService) handleEvent(ev *event) {
+	if ev.Kind != kindRequest {
+		return
+	}
+
+	if err := ev.verify(); err != nil {
+		log.Debugf("Ignoring invalid event %v: %v", ev.ID, err)
+		return
+	}
+
+	now := time.Now()
+	if ev.expired(now) {
+		log.Debugf("Ignoring expired request %v", ev.ID)
+		return
+	}
+
+	oldest := now.Add(-requestMaxAge).Unix()
+	if ev.CreatedAt < oldest {
+		log.Debugf("Ignoring outdated request %v", ev.ID)
+		return
+	}
+
+	s.mu.Lock()
+	c, ok := s.connections[ev.tag("p")]
+	if !ok || c.clientID() != ev.PubKey {
+		s.mu.Unlock()
+		log.Debugf("Ignoring request %v of unknown client %v", ev.ID,
+			ev.PubKey)
+		return
+	}
+
+	if _, ok := s.handled[ev.ID]; ok {
+		s.mu.Unlock()
+		return
+	}
+
+	// Requests that are older than requestMaxAge are ignored anyway, so
+	// we don't need to remember them.
+	for id, createdAt := range s.handled {
+		if createdAt < oldest {
+			delete(s.handled, id)
+		}
+	}
+	s.handled[ev.ID] = ev.CreatedAt
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		s.handleRequest(c, ev)
+	}()
+}
+
+// handleRequest executes the given request of the given connection and sends
+// the response back to the client.
+func (s *walletService) handleRequest(c *connection, ev *event) {
+	content, err := nip04Decrypt(c.walletKey, c.clientPubKey, ev.Content)
+	if err != nil {
+		log.Debugf("Unable to decrypt request %v: %v", ev.ID, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(
+		context.Background(), requestTimeout,
+	)
+	defer cancel()
+
+	go func() {
+		select {
+		case <-s.quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	resp := s.executeRequest(ctx, c, content)
+	if resp.Error != nil {
+		log.Debugf("NWC request %v of %v failed: %v: %v", ev.ID,
+			c.name, resp.Error.Code, resp.Error.Message)
+	}
+
+	if err := s.respond(c, ev, resp); err != nil {
+		log.Errorf("Unable to send response to %v: %v", ev.ID, err)
+	}
+}
+
+// respond encrypts the given response for the client of the given connection
+// and publishes it on the relay.
+func (s *walletService) respond(c *connection, req *event,
+	resp *response) error {
+
+	content, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+
+	encrypted, err := nip04Encrypt(c.walletKey, c.clientPubKey, content)
+	if err != nil {
+		return err
+	}
+
+	ev := &event{
+		CreatedAt: time.Now().Unix(),
+		Kind:      kindResponse,
+		Tags: [][]string{
+			{"p", c.clientID()},
+			{"e", req.ID},
+		},
+		Content: encrypted,
+	}
+	if err := ev.sign(c.walletKey); err != nil {
+		return err
+	}
+
+	return s.relay.publish(ev)
+}
+
+// macaroonFromBytes decodes a serialized macaroon.
+func macaroonFromBytes(macBytes []byte) (*macaroon.Macaroon, error) {
+	mac := &macaroon.Macaroon{}
+	if err := mac.UnmarshalBinary(macBytes); err != nil {
+		return nil, fmt.Errorf("unable to decode macaroon: %w", err)
+	}
+
+	return mac, nil
+}
@@ -0,0 +1,684 @@
+//go:build nwcrpc
+// +build nwcrpc
+
+package nwcrpc
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/lightningnetwork/lnd/kvdb"
+	"github.com/lightningnetwork/lnd/lnrpc"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/macaroons"
+	"github.com/lightningnetwork/lnd/zpay32"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"gopkg.in/macaroon-bakery.v2/bakery"
+	"gopkg.in/macaroon.v2"
+)
+
+var (
+	testPassword = []byte("password")
+
+	testChainParams = &chaincfg.RegressionNetParams
+)
+
+// testHarness bundles a wallet service with its mocked dependencies.
+type testHarness struct {
+	t *testing.T
+
+	relay      *mockRelay
+	macService *macaroons.Service
+	lightning  *mockLightning
+	router     *mockRouter
+	wallet     *walletService
+}
+
+// newTestHarness creates a new harness with a running wallet service that
+// is connected to a mock relay.
+func newTestHarness(t *testing.T) *testHarness {
+	db, err := kvdb.Create(
+		kvdb.BoltBackendName, filepath.Join(t.TempDir(), "mac.db"),
+		true, kvdb.DefaultDBTimeout,
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, db.Close())
+	})
+
+	rootKeyStore, err := macaroons.NewRootKeyStorage(db)
+	require.NoError(t, err)
+
+	macService, err := macaroons.NewService(rootKeyStore, "lnd", false)
+	require.NoError(t, err)
+	require.NoError(t, macService.CreateUnlock(&testPassword))
+
+	h := &testHarness{
+		t:          t,
+		relay:      newMockRelay(t),
+		macService: macService,
+		lightning:  &mockLightning{},
+		router:     &mockRouter{},
+	}
+	h.startWallet()
+
+	return h
+}
+
+// startWallet starts a new wallet service on the harness' dependencies.
+func (h *testHarness) startWallet() {
+	h.wallet = newWalletService(&walletServiceConfig{
+		relay:       h.relay.url(),
+		macService:  h.macService,
+		chainParams: testChainParams,
+		lightning:   h.lightning,
+		router:      h.router,
+	})
+	require.NoError(h.t, h.wallet.start())
+
+	wallet := h.wallet
+	h.t.Cleanup(func() {
+		select {
+		case <-wallet.quit:
+		default:
+			wallet.stop()
+		}
+	})
+}
+
+// restartWallet stops the wallet service and starts a new one.
+func (h *testHarness) restartWallet() {
+	h.wallet.stop()
+	h.startWallet()
+}
+
+// addConnection adds a new connection with the given parameters and returns
+// a client for it once the wallet service is subscribed to its requests.
+func (h *testHarness) addConnection(params *addConnectionParams) (*connection,
+	*testClient) {
+
+	c, uri, err := h.wallet.addConnection(context.Background(), params)
+	require.NoError(h.t, err)
+
+	client := parseConnectionURI(h.t, uri)
+	require.Equal(h.t, h.relay.url(), client.relay)
+	require.Equal(h.t, c.walletID(), client.walletID)
+
+	h.waitForSubscription(c.walletID())
+
+	return c, client
+}
+
+// waitForSubscription waits until the wallet service subscribed to the
+// requests for the given wallet key.
+func (h *testHarness) waitForSubscription(walletID string) {
+	require.Eventually(h.t, func() bool {
+		h.relay.mu.Lock()
+		defer h.relay.mu.Unlock()
+
+		for _, subs := range h.relay.subs {
+			f, ok := subs[subscriptionID]
+			if !ok {
+				continue
+			}
+
+			for _, p := range f.PTags {
+				if p == walletID {
+					return true
+				}
+			}
+		}
+
+		return false
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+// testClient is a NIP-47 client app.
+type testClient struct {
+	key       *btcec.PrivateKey
+	walletID  string
+	walletPub *btcec.PublicKey
+	relay     string
+}
+
+// parseConnectionURI parses a connection URI as a client app would.
+func parseConnectionURI(t *testing.T, uri string) *testClient {
+	u, err := url.Parse(uri)
+	require.NoError(t, err)
+	require.Equal(t, uriScheme, u.Scheme)
+
+	walletPub, err := parsePubKey(u.Host)
+	require.NoError(t, err)
+
+	secret, err := hex.DecodeString(u.Query().Get("secret"))
+	require.NoError(t, err)
+	key, _ := btcec.PrivKeyFromBytes(secret)
+
+	return &testClient{
+		key:       key,
+		walletID:  u.Host,
+		walletPub: walletPub,
+		relay:     u.Query().Get("relay"),
+	}
+}
+
+// newRequest creates a signed request event.
+func (c *testClient) newRequest(t *testing.T, method string,
+	params interface{}) *event {
+
+	content, err := json.Marshal(map[string]interface{}{
+		"method": method,
+		"params": params,
+	})
+	require.NoError(t, err)
+
+	encrypted, err := nip04Encrypt(c.key, c.walletPub, content)
+	require.NoError(t, err)
+
+	ev := &event{
+		CreatedAt: time.Now().Unix(),
+		Kind:      kindRequest,
+		Tags:      [][]string{{"p", c.walletID}},
+		Content:   encrypted,
+	}
+	require.NoError(t, ev.sign(c.key))
+
+	return ev
+}
+
+// testResponse is a decrypted response with the result left encoded.
+type testResponse struct {
+	ResultType string          `json:"result_type"`
+	Error      *nip47Error     `json:"error"`
+	Result     json.RawMessage `json:"result"`
+}
+
+// responses returns all decrypted responses to the given request.
+func (c *testClient) responses(t *testing.T, relay *mockRelay,
+	req *event) []*testResponse {
+
+	var responses []*testResponse
+	for _, ev := range relay.eventsOfKind(kindResponse) {
+		if ev.tag("e") != req.ID {
+			continue
+		}
+
+		require.Equal(t, c.walletID, ev.PubKey)
+		require.Equal(t, pubKeyHex(c.key.PubKey()), ev.tag("p"))
+
+		content, err := nip04Decrypt(c.key, c.walletPub, ev.Content)
+		require.NoError(t, err)
+
+		var resp testResponse
+		require.NoError(t, json.Unmarshal(content, &resp))
+		responses = append(responses, &resp)
+	}
+
+	return responses
+}
+
+// call sends a request to the relay and waits for the response of the wallet
+// service.
+func (c *testClient) call(t *testing.T, relay *mockRelay, method string,
+	params interface{}) *testResponse {
+
+	req := c.newRequest(t, method, params)
+	relay.publish(req)
+
+	var resp *testResponse
+	require.Eventually(t, func() bool {
+		responses := c.responses(t, relay, req)
+		if len(responses) == 0 {
+			return false
+		}
+
+		resp = responses[0]
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.Equal(t, method, resp.ResultType)
+
+	return resp
+}
+
+// newTestInvoice creates an encoded invoice over the given amount.
+func newTestInvoice(t *testing.T, amt lnwire.MilliSatoshi) (string,
+	[32]byte) {
+
+	key, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	var hash [32]byte
+	hash[31] = 0xff
+
+	invoice, err := zpay32.NewInvoice(
+		testChainParams, hash, time.Now(), zpay32.Amount(amt),
+		zpay32.Description("test payment"),
+	)
+	require.NoError(t, err)
+
+	encoded, err := invoice.Encode(zpay32.MessageSigner{
+		SignCompact: func(msg []byte) ([]byte, error) {
+			digest := chainhash.HashB(msg)
+			return ecdsa.SignCompact(key, digest, true)
+		},
+	})
+	require.NoError(t, err)
+
+	return encoded, hash
+}
+
+// requireLimits asserts that the given call options carry the macaroon of the
+// given connection with the given limits.
+func requireLimits(t *testing.T, c *connection, opts []grpc.CallOption,
+	expected *macaroons.Limits) {
+
+	require.Len(t, opts, 1)
+	credOpt, ok := opts[0].(grpc.PerRPCCredsCallOption)
+	require.True(t, ok)
+
+	md, err := credOpt.Creds.GetRequestMetadata(context.Background())
+	require.NoError(t, err)
+
+	macBytes, err := hex.DecodeString(md["macaroon"])
+	require.NoError(t, err)
+	require.Equal(t, c.macaroon, macBytes)
+
+	mac, err := macaroonFromBytes(macBytes)
+	require.NoError(t, err)
+
+	limits, err := macaroons.ParseLimits(mac)
+	require.NoError(t, err)
+	require.Equal(t, expected, limits)
+}
+
+// TestWalletService tests the execution of requests that are sent over the
+// relay.
+func TestWalletService(t *testing.T) {
+	t.Parallel()
+
+	h := newTestHarness(t)
+	h.lightning.balance = 123_000
+
+	c, client := h.addConnection(&addConnectionParams{
+		name:                 "app",
+		budget:               100_000,
+		maxPayment:           50_000,
+		maxRequestsPerMinute: 10,
+	})
+	require.Equal(t, defaultBudgetRenewal, c.budgetRenewal)
+	require.Len(t, c.methods, len(methodPermissions))
+
+	// The wallet service publishes the methods the client may call.
+	require.Eventually(t, func() bool {
+		for _, ev := range h.relay.eventsOfKind(kindInfo) {
+			if ev.PubKey == client.walletID {
+				return true
+			}
+		}
+
+		return false
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Query the balance and make sure the call was made with the macaroon
+	// of the connection that carries its limits.
+	resp := client.call(t, h.relay, methodGetBalance, nil)
+	require.Nil(t, resp.Error)
+	require.JSONEq(t, `{"balance":123000}`, string(resp.Result))
+
+	requireLimits(t, c, h.lightning.lastOpts, &macaroons.Limits{
+		SpendLimit:        100_000,
+		SpendWindow:       defaultBudgetRenewal,
+		MaxPayment:        50_000,
+		HasMaxPayment:     true,
+		MaxCallsPerMinute: 10,
+	})
+
+	// Create an invoice.
+	resp = client.call(t, h.relay, methodMakeInvoice, &makeInvoiceParams{
+		Amount:      21_000,
+		Description: "coffee",
+		Expiry:      3600,
+	})
+	require.Nil(t, resp.Error)
+
+	var txn transaction
+	require.NoError(t, json.Unmarshal(resp.Result, &txn))
+	require.Equal(t, "incoming", txn.Type)
+	require.Equal(t, "coffee", txn.Description)
+	require.EqualValues(t, 21_000, txn.Amount)
+	require.Equal(t, h.lightning.invoices[0].PaymentRequest, txn.Invoice)
+
+	// Pay an invoice.
+	invoice, hash := newTestInvoice(t, 10_000)
+	h.router.result = &lnrpc.Payment{
+		PaymentHash:     hex.EncodeToString(hash[:]),
+		PaymentRequest:  invoice,
+		Status:          lnrpc.Payment_SUCCEEDED,
+		PaymentPreimage: "00ff",
+		FeeMsat:         5,
+	}
+	resp = client.call(t, h.relay, methodPayInvoice, &payInvoiceParams{
+		Invoice: invoice,
+	})
+	require.Nil(t, resp.Error)
+	require.JSONEq(
+		t, `{"preimage":"00ff","fees_paid":5}`, string(resp.Result),
+	)
+	require.Len(t, h.router.requests, 1)
+	require.Equal(t, invoice, h.router.requests[0].PaymentRequest)
+	require.Positive(t, h.router.requests[0].FeeLimitMsat)
+
+	// The payment can be looked up, since there is no invoice with its
+	// payment hash.
+	resp = client.call(
+		t, h.relay, methodLookupInvoice, &lookupInvoiceParams{
+			Invoice: invoice,
+		},
+	)
+	require.Nil(t, resp.Error)
+
+	var paymentTxn transaction
+	require.NoError(t, json.Unmarshal(resp.Result, &paymentTxn))
+	require.Equal(t, "outgoing", paymentTxn.Type)
+	require.Equal(t, "settled", paymentTxn.State)
+	require.Equal(t, "test payment", paymentTxn.Description)
+
+	// An unknown payment hash isn't found.
+	resp = client.call(
+		t, h.relay, methodLookupInvoice, &lookupInvoiceParams{
+			PaymentHash: hex.EncodeToString(make([]byte, 32)),
+		},
+	)
+	require.Equal(t, errCodeNotFound, resp.Error.Code)
+
+	// A failed payment due to missing funds is reported as such.
+	h.router.result = &lnrpc.Payment{
+		Status:        lnrpc.Payment_FAILED,
+		FailureReason: insufficientBalance,
+	}
+	resp = client.call(t, h.relay, methodPayInvoice, &payInvoiceParams{
+		Invoice: invoice,
+	})
+	require.Equal(t, errCodeInsufficientBalance, resp.Error.Code)
+
+	// Unknown methods aren't implemented.
+	resp = client.call(t, h.relay, "pay_keysend", nil)
+	require.Equal(t, errCodeNotImplemented, resp.Error.Code)
+}
+
+// TestWalletServiceRestricted tests that a connection can only call the
+// methods it was created for and that the macaroon only grants the
+// permissions these methods need.
+func TestWalletServiceRestricted(t *testing.T) {
+	t.Parallel()
+
+	h := newTestHarness(t)
+
+	_, _, err := h.wallet.addConnection(
+		context.Background(), &addConnectionParams{
+			name:    "invalid",
+			methods: []string{"sign_message"},
+		},
+	)
+	require.ErrorContains(t, err, "unsupported method")
+
+	c, client := h.addConnection(&addConnectionParams{
+		name:    "read-only",
+		methods: []string{methodGetBalance, methodGetBalance},
+	})
+	require.Equal(t, []string{methodGetBalance}, c.sortedMethods())
+
+	resp := client.call(t, h.relay, methodGetBalance, nil)
+	require.Nil(t, resp.Error)
+
+	resp = client.call(t, h.relay, methodPayInvoice, &payInvoiceParams{
+		Invoice: "lnbcrt1",
+	})
+	require.Equal(t, errCodeRestricted, resp.Error.Code)
+	require.Empty(t, h.router.requests)
+
+	// The macaroon only allows reading off-chain data.
+	err = h.macService.CheckMacAuth(
+		context.Background(), c.macaroon, []bakery.Op{{
+			Entity: "offchain", Action: "read",
+		}}, "/lnrpc.Lightning/ChannelBalance",
+	)
+	require.NoError(t, err)
+
+	err = h.macService.CheckMacAuth(
+		context.Background(), c.macaroon, []bakery.Op{{
+			Entity: "offchain", Action: "write",
+		}}, "/routerrpc.Router/SendPaymentV2",
+	)
+	require.Error(t, err)
+}
+
+// TestWalletServiceIgnoredRequests tests that requests are only executed once
+// and that invalid requests are ignored.
+func TestWalletServiceIgnoredRequests(t *testing.T) {
+	t.Parallel()
+
+	h := newTestHarness(t)
+
+	_, client := h.addConnection(&addConnectionParams{name: "app"})
+
+	// A request that is delivered twice is only executed once.
+	req := client.newRequest(t, methodGetBalance, nil)
+	h.relay.publish(req)
+	h.relay.publish(req)
+
+	require.Eventually(t, func() bool {
+		return len(client.responses(t, h.relay, req)) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The requests are handled sequentially by the relay client, so the
+	// response to the duplicate would have been sent by now, if the
+	// request was executed twice.
+	final := client.call(t, h.relay, methodGetBalance, nil)
+	require.Nil(t, final.Error)
+	require.Len(t, client.responses(t, h.relay, req), 1)
+
+	// Requests signed by another key, outdated requests and requests with
+	// an invalid signature aren't answered.
+	stranger, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	strangerClient := *client
+	strangerClient.key = stranger
+	strangerReq := strangerClient.newRequest(t, methodGetBalance, nil)
+
+	oldReq := client.newRequest(t, methodGetBalance, nil)
+	oldReq.CreatedAt -= int64(2 * requestMaxAge / time.Second)
+	require.NoError(t, oldReq.sign(client.key))
+
+	invalidReq := client.newRequest(t, methodGetBalance, nil)
+	invalidReq.Sig = strangerReq.Sig
+
+	for _, ev := range []*event{strangerReq, oldReq, invalidReq} {
+		h.relay.publish(ev)
+	}
+
+	final = client.call(t, h.relay, methodGetBalance, nil)
+	require.Nil(t, final.Error)
+
+	for _, ev := range []*event{strangerReq, oldReq, invalidReq} {
+		require.Empty(t, client.responses(t, h.relay, ev))
+	}
+}
+
+// TestWalletServiceConnections tests that connections survive a restart and
+// that removing a connection revokes its macaroon.
+func TestWalletServiceConnections(t *testing.T) {
+	t.Parallel()
+
+	h := newTestHarness(t)
+	ctx := context.Background()
+
+	c1, client1 := h.addConnection(&addConnectionParams{
+		name:          "first",
+		budget:        1000,
+		budgetRenewal: time.Hour,
+	})
+	c2, client2 := h.addConnection(&addConnectionParams{
+		name: "second",
+	})
+
+	h.restartWallet()
+	h.waitForSubscription(c1.walletID())
+	h.waitForSubscription(c2.walletID())
+
+	connections := h.wallet.listConnections()
+	require.Len(t, connections, 2)
+	require.Equal(t, "first", connections[0].name)
+	require.Equal(t, "second", connections[1].name)
+	require.Equal(t, c1.macaroon, connections[0].macaroon)
+	require.Equal(t, time.Hour, connections[0].budgetRenewal)
+
+	resp := client1.call(t, h.relay, methodGetBalance, nil)
+	require.Nil(t, resp.Error)
+
+	// Removing the connection deletes its root key, which invalidates its
+	// macaroon.
+	rootKeyID := []byte(strconv.FormatUint(c1.rootKeyID, 10))
+	ids, err := h.macService.ListMacaroonIDs(ctx)
+	require.NoError(t, err)
+	require.Contains(t, ids, rootKeyID)
+
+	require.NoError(t, h.wallet.removeConnection(ctx, clientID(client1)))
+	require.ErrorIs(
+		t, h.wallet.removeConnection(ctx, clientID(client1)),
+		ErrConnectionNotFound,
+	)
+
+	ids, err = h.macService.ListMacaroonIDs(ctx)
+	require.NoError(t, err)
+	require.NotContains(t, ids, rootKeyID)
+
+	// The removed connection is gone after a restart, while the other one
+	// still works.
+	h.restartWallet()
+	h.waitForSubscription(c2.walletID())
+
+	connections = h.wallet.listConnections()
+	require.Len(t, connections, 1)
+	require.Equal(t, "second", connections[0].name)
+
+	resp = client2.call(t, h.relay, methodGetBalance, nil)
+	require.Nil(t, resp.Error)
+}
+
+// clientID returns the hex encoded public key of the given client.
+func clientID(c *testClient) string {
+	return pubKeyHex(c.key.PubKey())
+}
+
+// TestListTransactions tests that invoices and payments are merged and paged
+// correctly.
+func TestListTransactions(t *testing.T) {
+	t.Parallel()
+
+	lightning := &mockLightning{}
+	s := &walletService{
+		cfg: &walletServiceConfig{
+			chainParams: testChainParams,
+			lightning:   lightning,
+		},
+	}
+
+	// Create settled invoices at even and payments at odd timestamps.
+	for i := 1; i <= 5; i++ {
+		lightning.invoices = append(lightning.invoices, &lnrpc.Invoice{
+			RHash:        []byte{byte(i)},
+			CreationDate: int64(2 * i),
+			State:        lnrpc.Invoice_SETTLED,
+			AddIndex:     uint64(i),
+		})
+		lightning.payments = append(lightning.payments, &lnrpc.Payment{
+			PaymentHash: hex.EncodeToString([]byte{byte(i)}),
+			CreationTimeNs: int64(2*i-1) *
+				int64(time.Second),
+			Status:       lnrpc.Payment_SUCCEEDED,
+			PaymentIndex: uint64(i),
+		})
+	}
+
+	// An unpaid invoice is only listed if requested.
+	lightning.invoices = append(lightning.invoices, &lnrpc.Invoice{
+		RHash:        []byte{6},
+		CreationDate: 12,
+		Expiry:       3600,
+		State:        lnrpc.Invoice_OPEN,
+		AddIndex:     6,
+	})
+
+	c := &connection{
+		macaroon: testMacaroon(t),
+	}
+
+	list := func(params *listTransactionsParams) []int64 {
+		raw, err := json.Marshal(params)
+		require.NoError(t, err)
+
+		result, nip47Err := s.listTransactions(
+			context.Background(), c, raw,
+		)
+		require.Nil(t, nip47Err)
+
+		txns := result.(*listTransactionsResult).Transactions
+
+		var createdAt []int64
+		for _, txn := range txns {
+			createdAt = append(createdAt, txn.CreatedAt)
+		}
+
+		return createdAt
+	}
+
+	require.Equal(
+		t, []int64{10, 9, 8, 7, 6, 5, 4, 3, 2, 1},
+		list(&listTransactionsParams{}),
+	)
+	require.Equal(
+		t, []int64{12, 10, 9},
+		list(&listTransactionsParams{Unpaid: true, Limit: 3}),
+	)
+	require.Equal(
+		t, []int64{8, 7, 6},
+		list(&listTransactionsParams{Offset: 2, Limit: 3}),
+	)
+	require.Equal(
+		t, []int64{6, 4},
+		list(&listTransactionsParams{
+			Type: "incoming", Offset: 2, Limit: 2,
+		}),
+	)
+	require.Equal(
+		t, []int64{9, 7, 5, 3, 1},
+		list(&listTransactionsParams{Type: "outgoing"}),
+	)
+	require.Empty(t, list(&listTransactionsParams{Offset: 20}))
+}
+
+// testMacaroon returns a serialized dummy macaroon.
+func testMacaroon(t *testing.T) []byte {
+	mac, err := macaroon.New(
+		[]byte("root-key"), []byte("id"), "lnd", macaroon.LatestVersion,
+	)
+	require.NoError(t, err)
+
+	macBytes, err := mac.MarshalBinary()
+	require.NoError(t, err)
+
+	return macBytes
+}
@@ -15,8 +15,10 @@ import (
 	"github.com/btcsuite/btcd/wire"
 	sphinx "github.com/lightningnetwork/lightning-onion"
 	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/eventlog"
 	"github.com/lightningnetwork/lnd/feature"
 	"github.com/lightningnetwork/lnd/htlcswitch"
+	"github.com/lightningnetwork/lnd/invoices"
 	"github.com/lightningnetwork/lnd/lnrpc"
 	"github.com/lightningnetwork/lnd/lntypes"
 	"github.com/lightningnetwork/lnd/lnwire"
@@ -84,6 +86,10 @@ type RouterBackend struct {
 	// htlc events.
 	SubscribeHtlcEvents func() (*subscribe.Client, error)
 
+	// HtlcEventLog is the persistent log of the node's htlc events. It is
+	// nil if the event log is disabled.
+	HtlcEventLog *eventlog.Log
+
 	// InterceptableForwarder exposes the ability to intercept forward events
 	// by letting the router register a ForwardInterceptor.
 	InterceptableForwarder htlcswitch.InterceptableHtlcForwarder
@@ -97,6 +103,15 @@ type RouterBackend struct {
 	// SetChannelAuto exposes the ability to restore automatic channel state
 	// management after manually setting channel status.
 	SetChannelAuto func(wire.OutPoint) error
+
+	// AddInvoice adds an invoice to the invoice registry. It is used to
+	// receive the circular payments of rebalances.
+	AddInvoice func(context.Context, *invoices.Invoice,
+		lntypes.Hash) (uint64, error)
+
+	// GenInvoiceFeatures returns the feature vector advertised in our
+	// invoices.
+	GenInvoiceFeatures func() *lnwire.FeatureVector
 }
 
 // MissionControl defines the mission control dependencies of routerrpc.
@@ -820,6 +835,19 @@ func (r *RouterBackend) extractIntentFromSendRequest(
 		payIntent.MaxShardAmt = &shardAmtMsat
 	}
 
+	// Select the strategy for splitting the payment.
+	switch rpcPayReq.SplitMode {
+	case PaymentSplitMode_SPLIT_HALVING:
+		payIntent.SplitMode = routing.SplitModeHalving
+
+	case PaymentSplitMode_SPLIT_MIN_COST_FLOW:
+		payIntent.SplitMode = routing.SplitModeFlow
+
+	default:
+		return nil, fmt.Errorf("unknown split mode %v",
+			rpcPayReq.SplitMode)
+	}
+
 	// Take fee limit from request.
 	payIntent.FeeLimit, err = lnrpc.UnmarshallAmt(
 		rpcPayReq.FeeLimitSat, rpcPayReq.FeeLimitMsat,
@@ -1107,6 +1135,131 @@ func (r *RouterBackend) extractIntentFromSendRequest(
 	return payIntent, nil
 }
 
+// extractIntentFromRebalanceRequest creates the payment intent of a circular
+// payment that moves funds from the requested outgoing channels into the
+// incoming channel. The invoice the payment pays to is added to the invoice
+// registry.
+func (r *RouterBackend) extractIntentFromRebalanceRequest(ctx context.Context,
+	req *RebalanceRequest) (*routing.LightningPayment, error) {
+
+	if len(req.OutgoingChanIds) == 0 {
+		return nil, errors.New("at least one outgoing channel must " +
+			"be specified")
+	}
+
+	if req.TimeoutSeconds == 0 {
+		return nil, errors.New("timeout_seconds must be specified")
+	}
+
+	amt, err := lnrpc.UnmarshallAmt(req.Amt, req.AmtMsat)
+	if err != nil {
+		return nil, err
+	}
+	if amt == 0 {
+		return nil, errors.New("amount must be specified")
+	}
+
+	// All channels must be ours, and funds can't be moved from a channel
+	// into itself.
+	for _, chanID := range req.OutgoingChanIds {
+		if chanID == req.IncomingChanId {
+			return nil, fmt.Errorf("channel %v can't be both "+
+				"outgoing and incoming", chanID)
+		}
+
+		if _, err := r.fetchChannelPeer(chanID); err != nil {
+			return nil, err
+		}
+	}
+
+	// The route comes back to us through the peer of the incoming
+	// channel.
+	lastHop, err := r.fetchChannelPeer(req.IncomingChanId)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		preimage    lntypes.Preimage
+		paymentAddr [32]byte
+	)
+	if _, err := rand.Read(preimage[:]); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(paymentAddr[:]); err != nil {
+		return nil, err
+	}
+	hash := preimage.Hash()
+
+	features := r.GenInvoiceFeatures()
+	invoice := &invoices.Invoice{
+		CreationDate: time.Now(),
+		Memo:         []byte("rebalance"),
+		Terms: invoices.ContractTerm{
+			FinalCltvDelta:  int32(r.DefaultFinalCltvDelta),
+			Expiry:          zpay32.DefaultInvoiceExpiry,
+			Value:           amt,
+			PaymentPreimage: &preimage,
+			PaymentAddr:     paymentAddr,
+			Features:        features,
+		},
+	}
+	if _, err := r.AddInvoice(ctx, invoice, hash); err != nil {
+		return nil, fmt.Errorf("unable to add rebalance invoice: %w",
+			err)
+	}
+
+	maxParts := req.MaxParts
+	if maxParts == 0 {
+		maxParts = DefaultMaxParts
+	}
+
+	feeLimit := amt * lnwire.MilliSatoshi(req.MaxFeePpm) / 1_000_000
+	timeout := time.Second * time.Duration(req.TimeoutSeconds)
+
+	payIntent := &routing.LightningPayment{
+		Target:             r.SelfNode,
+		Amount:             amt,
+		FeeLimit:           feeLimit,
+		CltvLimit:          r.MaxTotalTimelock,
+		FinalCLTVDelta:     r.DefaultFinalCltvDelta,
+		PayAttemptTimeout:  timeout,
+		OutgoingChannelIDs: req.OutgoingChanIds,
+		LastHop:            &lastHop,
+		DestFeatures:       features,
+		PaymentAddr:        &paymentAddr,
+		MaxParts:           maxParts,
+		Rebalance:          true,
+	}
+	if err := payIntent.SetPaymentHash(hash); err != nil {
+		return nil, err
+	}
+
+	return payIntent, nil
+}
+
+// fetchChannelPeer returns the peer of one of our channels.
+func (r *RouterBackend) fetchChannelPeer(chanID uint64) (route.Vertex,
+	error) {
+
+	node1, node2, err := r.FetchChannelEndpoints(chanID)
+	if err != nil {
+		return route.Vertex{}, err
+	}
+
+	switch r.SelfNode {
+	case node1:
+		return node2, nil
+
+	case node2:
+		return node1, nil
+
+	default:
+		return route.Vertex{}, fmt.Errorf("channel %v is not one of "+
+			"our channels", chanID)
+	}
+}
+
 // unmarshallRouteHints unmarshalls a list of route hints.
 func unmarshallRouteHints(rpcRouteHints []*lnrpc.RouteHint) (
 	[][]zpay32.HopHint, error) {
@@ -1360,7 +1513,8 @@ func MarshalTimeNano(t time.Time) int64 {
 func marshallError(sendError error) (*lnrpc.Failure, error) {
 	response := &lnrpc.Failure{}
 
-	if sendError == htlcswitch.ErrUnreadableFailureMessage {
+	_, attributed := sendError.(*htlcswitch.UnreadableFailureError)
+	if attributed || sendError == htlcswitch.ErrUnreadableFailureMessage {
 		response.Code = lnrpc.Failure_UNREADABLE_FAILURE
 		return response, nil
 	}
@@ -1541,7 +1695,9 @@ func (r *RouterBackend) MarshallPayment(payment *channeldb.MPPayment) (
 	msatValue := int64(payment.Info.Value)
 	satValue := int64(payment.Info.Value.ToSatoshis())
 
-	status, err := convertPaymentStatus(payment.Status)
+	status, err := convertPaymentStatus(
+		payment.Status, payment.FailureReason,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -1583,19 +1739,25 @@ func (r *RouterBackend) MarshallPayment(payment *channeldb.MPPayment) (
 		Htlcs:           htlcs,
 		PaymentIndex:    payment.SequenceNum,
 		FailureReason:   failureReason,
+		IsRebalance:     payment.Info.Rebalance,
 	}, nil
 }
 
 // convertPaymentStatus converts a channeldb.PaymentStatus to the type expected
-// by the RPC.
-func convertPaymentStatus(dbStatus channeldb.PaymentStatus) (
-	lnrpc.Payment_PaymentStatus, error) {
+// by the RPC. An in-flight payment that was canceled by the user is reported
+// as cancelling until its HTLCs are resolved.
+func convertPaymentStatus(dbStatus channeldb.PaymentStatus,
+	reason *channeldb.FailureReason) (lnrpc.Payment_PaymentStatus, error) {
 
 	switch dbStatus {
 	case channeldb.StatusInitiated:
 		return lnrpc.Payment_INITIATED, nil
 
 	case channeldb.StatusInFlight:
+		if reason != nil && *reason == channeldb.FailureReasonCanceled {
+			return lnrpc.Payment_CANCELLING, nil
+		}
+
 		return lnrpc.Payment_IN_FLIGHT, nil
 
 	case channeldb.StatusSucceeded:
@@ -1633,6 +1795,9 @@ func marshallPaymentFailureReason(reason *channeldb.FailureReason) (
 
 	case channeldb.FailureReasonInsufficientBalance:
 		return lnrpc.PaymentFailureReason_FAILURE_REASON_INSUFFICIENT_BALANCE, nil
+
+	case channeldb.FailureReasonCanceled:
+		return lnrpc.PaymentFailureReason_FAILURE_REASON_CANCELED, nil
 	}
 
 	return 0, errors.New("unknown failure reason")
@@ -7,7 +7,10 @@ import (
 	"testing"
 
 	"github.com/btcsuite/btcd/btcutil"
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/invoices"
 	"github.com/lightningnetwork/lnd/lnrpc"
+	"github.com/lightningnetwork/lnd/lntypes"
 	"github.com/lightningnetwork/lnd/lnwire"
 	"github.com/lightningnetwork/lnd/routing"
 	"github.com/lightningnetwork/lnd/routing/route"
@@ -475,3 +478,107 @@ func testUnmarshalAMP(t *testing.T, test unmarshalAMPTest) {
 		t.Fatalf("test case has non-standard outcome")
 	}
 }
+
+// TestExtractIntentFromRebalanceRequest tests that rebalance requests are
+// turned into circular payments to an invoice of our own.
+func TestExtractIntentFromRebalanceRequest(t *testing.T) {
+	t.Parallel()
+
+	var added *invoices.Invoice
+	backend := &RouterBackend{
+		SelfNode:              sourceKey,
+		MaxTotalTimelock:      1000,
+		DefaultFinalCltvDelta: 40,
+		FetchChannelEndpoints: func(chanID uint64) (route.Vertex,
+			route.Vertex, error) {
+
+			switch chanID {
+			case 1:
+				return sourceKey, node1, nil
+
+			case 2:
+				return node2, sourceKey, nil
+
+			default:
+				return node1, node2, nil
+			}
+		},
+		AddInvoice: func(_ context.Context, invoice *invoices.Invoice,
+			hash lntypes.Hash) (uint64, error) {
+
+			require.Equal(
+				t, hash, invoice.Terms.PaymentPreimage.Hash(),
+			)
+			added = invoice
+
+			return 1, nil
+		},
+		GenInvoiceFeatures: func() *lnwire.FeatureVector {
+			return lnwire.EmptyFeatureVector()
+		},
+	}
+
+	req := &RebalanceRequest{
+		OutgoingChanIds: []uint64{1},
+		IncomingChanId:  2,
+		Amt:             100_000,
+		MaxFeePpm:       500,
+		TimeoutSeconds:  60,
+	}
+	payment, err := backend.extractIntentFromRebalanceRequest(
+		context.Background(), req,
+	)
+	require.NoError(t, err)
+
+	require.True(t, payment.Rebalance)
+	require.Equal(t, sourceKey, payment.Target)
+	require.Equal(t, node2, *payment.LastHop)
+	require.Equal(t, []uint64{1}, payment.OutgoingChannelIDs)
+	require.EqualValues(t, 100_000_000, payment.Amount)
+	require.EqualValues(t, 50_000, payment.FeeLimit)
+	require.EqualValues(t, DefaultMaxParts, payment.MaxParts)
+	require.Equal(t, added.Terms.PaymentAddr, *payment.PaymentAddr)
+	require.Equal(t, added.Terms.PaymentPreimage.Hash(),
+		lntypes.Hash(payment.Identifier()))
+	require.Equal(t, payment.Amount, added.Terms.Value)
+
+	// Funds can't be moved into the channel they come from.
+	req.OutgoingChanIds = []uint64{2}
+	_, err = backend.extractIntentFromRebalanceRequest(
+		context.Background(), req,
+	)
+	require.ErrorContains(t, err, "both outgoing and incoming")
+
+	// Only our own channels can be rebalanced.
+	req.OutgoingChanIds = []uint64{1}
+	req.IncomingChanId = 3
+	_, err = backend.extractIntentFromRebalanceRequest(
+		context.Background(), req,
+	)
+	require.ErrorContains(t, err, "not one of our channels")
+}
+
+// TestConvertPaymentStatus tests that in-flight payments canceled by the user
+// are reported as cancelling.
+func TestConvertPaymentStatus(t *testing.T) {
+	t.Parallel()
+
+	canceled := channeldb.FailureReasonCanceled
+	timeout := channeldb.FailureReasonTimeout
+
+	status, err := convertPaymentStatus(channeldb.StatusInFlight, nil)
+	require.NoError(t, err)
+	require.Equal(t, lnrpc.Payment_IN_FLIGHT, status)
+
+	status, err = convertPaymentStatus(channeldb.StatusInFlight, &timeout)
+	require.NoError(t, err)
+	require.Equal(t, lnrpc.Payment_IN_FLIGHT, status)
+
+	status, err = convertPaymentStatus(channeldb.StatusInFlight, &canceled)
+	require.NoError(t, err)
+	require.Equal(t, lnrpc.Payment_CANCELLING, status)
+
+	status, err = convertPaymentStatus(channeldb.StatusFailed, &canceled)
+	require.NoError(t, err)
+	require.Equal(t, lnrpc.Payment_FAILED, status)
+}
@@ -33,6 +33,13 @@ type RouterClient interface {
 	// payment attempt make sure to subscribe to this method before initiating any
 	// payments.
 	TrackPayments(ctx context.Context, in *TrackPaymentsRequest, opts ...grpc.CallOption) (Router_TrackPaymentsClient, error)
+	// lncli: `cancelpayment`
+	// CancelPayment stops an in-flight payment identified by the payment hash. No
+	// new HTLC attempts are launched for the payment, while the attempts in
+	// flight are allowed to resolve. Once they are failed, the payment fails with
+	// the FAILURE_REASON_CANCELED failure reason. The call fails if the payment
+	// already failed or one of its attempts was settled.
+	CancelPayment(ctx context.Context, in *CancelPaymentRequest, opts ...grpc.CallOption) (*CancelPaymentResponse, error)
 	// EstimateRouteFee allows callers to obtain a lower bound w.r.t how much it
 	// may cost to send an HTLC to the target end destination.
 	EstimateRouteFee(ctx context.Context, in *RouteFeeRequest, opts ...grpc.CallOption) (*RouteFeeResponse, error)
@@ -100,6 +107,13 @@ type RouterClient interface {
 	// channel to stay disabled until a subsequent manual request of either
 	// "enable" or "auto".
 	UpdateChanStatus(ctx context.Context, in *UpdateChanStatusRequest, opts ...grpc.CallOption) (*UpdateChanStatusResponse, error)
+	// Rebalance moves funds from one or more of our channels into another one of
+	// our channels by paying an invoice to ourselves over a circular route. The
+	// route leaves through one of the outgoing channels and comes back through
+	// the incoming channel. The payment is retried and split like a regular
+	// payment, but recorded as a rebalance. The call returns a stream of payment
+	// updates.
+	Rebalance(ctx context.Context, in *RebalanceRequest, opts ...grpc.CallOption) (Router_RebalanceClient, error)
 }
 
 type routerClient struct {
@@ -206,6 +220,15 @@ func (x *routerTrackPaymentsClient) Recv() (*lnrpc.Payment, error) {
 	return m, nil
 }
 
+func (c *routerClient) CancelPayment(ctx context.Context, in *CancelPaymentRequest, opts ...grpc.CallOption) (*CancelPaymentResponse, error) {
+	out := new(CancelPaymentResponse)
+	err := c.cc.Invoke(ctx, "/routerrpc.Router/CancelPayment", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *routerClient) EstimateRouteFee(ctx context.Context, in *RouteFeeRequest, opts ...grpc.CallOption) (*RouteFeeResponse, error) {
 	out := new(RouteFeeResponse)
 	err := c.cc.Invoke(ctx, "/routerrpc.Router/EstimateRouteFee", in, out, opts...)
@@ -435,6 +458,38 @@ func (c *routerClient) UpdateChanStatus(ctx context.Context, in *UpdateChanStatu
 	return out, nil
 }
 
+func (c *routerClient) Rebalance(ctx context.Context, in *RebalanceRequest, opts ...grpc.CallOption) (Router_RebalanceClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Router_ServiceDesc.Streams[7], "/routerrpc.Router/Rebalance", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &routerRebalanceClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Router_RebalanceClient interface {
+	Recv() (*lnrpc.Payment, error)
+	grpc.ClientStream
+}
+
+type routerRebalanceClient struct {
+	grpc.ClientStream
+}
+
+func (x *routerRebalanceClient) Recv() (*lnrpc.Payment, error) {
+	m := new(lnrpc.Payment)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // RouterServer is the server API for Router service.
 // All implementations must embed UnimplementedRouterServer
 // for forward compatibility
@@ -453,6 +508,13 @@ type RouterServer interface {
 	// payment attempt make sure to subscribe to this method before initiating any
 	// payments.
 	TrackPayments(*TrackPaymentsRequest, Router_TrackPaymentsServer) error
+	// lncli: `cancelpayment`
+	// CancelPayment stops an in-flight payment identified by the payment hash. No
+	// new HTLC attempts are launched for the payment, while the attempts in
+	// flight are allowed to resolve. Once they are failed, the payment fails with
+	// the FAILURE_REASON_CANCELED failure reason. The call fails if the payment
+	// already failed or one of its attempts was settled.
+	CancelPayment(context.Context, *CancelPaymentRequest) (*CancelPaymentResponse, error)
 	// EstimateRouteFee allows callers to obtain a lower bound w.r.t how much it
 	// may cost to send an HTLC to the target end destination.
 	EstimateRouteFee(context.Context, *RouteFeeRequest) (*RouteFeeResponse, error)
@@ -520,6 +582,13 @@ type RouterServer interface {
 	// channel to stay disabled until a subsequent manual request of either
 	// "enable" or "auto".
 	UpdateChanStatus(context.Context, *UpdateChanStatusRequest) (*UpdateChanStatusResponse, error)
+	// Rebalance moves funds from one or more of our channels into another one of
+	// our channels by paying an invoice to ourselves over a circular route. The
+	// route leaves through one of the outgoing channels and comes back through
+	// the incoming channel. The payment is retried and split like a regular
+	// payment, but recorded as a rebalance. The call returns a stream of payment
+	// updates.
+	Rebalance(*RebalanceRequest, Router_RebalanceServer) error
 	mustEmbedUnimplementedRouterServer()
 }
 
@@ -536,6 +605,9 @@ func (UnimplementedRouterServer) TrackPaymentV2(*TrackPaymentRequest, Router_Tra
 func (UnimplementedRouterServer) TrackPayments(*TrackPaymentsRequest, Router_TrackPaymentsServer) error {
 	return status.Errorf(codes.Unimplemented, "method TrackPayments not implemented")
 }
+func (UnimplementedRouterServer) CancelPayment(context.Context, *CancelPaymentRequest) (*CancelPaymentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelPayment not implemented")
+}
 func (UnimplementedRouterServer) EstimateRouteFee(context.Context, *RouteFeeRequest) (*RouteFeeResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method EstimateRouteFee not implemented")
 }
@@ -581,6 +653,9 @@ func (UnimplementedRouterServer) HtlcInterceptor(Router_HtlcInterceptorServer) e
 func (UnimplementedRouterServer) UpdateChanStatus(context.Context, *UpdateChanStatusRequest) (*UpdateChanStatusResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateChanStatus not implemented")
 }
+func (UnimplementedRouterServer) Rebalance(*RebalanceRequest, Router_RebalanceServer) error {
+	return status.Errorf(codes.Unimplemented, "method Rebalance not implemented")
+}
 func (UnimplementedRouterServer) mustEmbedUnimplementedRouterServer() {}
 
 // UnsafeRouterServer may be embedded to opt out of forward compatibility for this service.
@@ -657,6 +732,24 @@ func (x *routerTrackPaymentsServer) Send(m *lnrpc.Payment) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _Router_CancelPayment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelPaymentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RouterServer).CancelPayment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/routerrpc.Router/CancelPayment",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RouterServer).CancelPayment(ctx, req.(*CancelPaymentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Router_EstimateRouteFee_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(RouteFeeRequest)
 	if err := dec(in); err != nil {
@@ -944,6 +1037,27 @@ func _Router_UpdateChanStatus_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Router_Rebalance_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(RebalanceRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RouterServer).Rebalance(m, &routerRebalanceServer{stream})
+}
+
+type Router_RebalanceServer interface {
+	Send(*lnrpc.Payment) error
+	grpc.ServerStream
+}
+
+type routerRebalanceServer struct {
+	grpc.ServerStream
+}
+
+func (x *routerRebalanceServer) Send(m *lnrpc.Payment) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // Router_ServiceDesc is the grpc.ServiceDesc for Router service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -951,6 +1065,10 @@ var Router_ServiceDesc = grpc.ServiceDesc{
 	ServiceName: "routerrpc.Router",
 	HandlerType: (*RouterServer)(nil),
 	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CancelPayment",
+			Handler:    _Router_CancelPayment_Handler,
+		},
 		{
 			MethodName: "EstimateRouteFee",
 			Handler:    _Router_EstimateRouteFee_Handler,
@@ -1033,6 +1151,11 @@ var Router_ServiceDesc = grpc.ServiceDesc{
 			ServerStreams: true,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "Rebalance",
+			Handler:       _Router_Rebalance_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "routerrpc/router.proto",
 }
@@ -14,6 +14,7 @@ import (
 	"github.com/btcsuite/btcd/wire"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/eventlog"
 	"github.com/lightningnetwork/lnd/lnrpc"
 	"github.com/lightningnetwork/lnd/lntypes"
 	"github.com/lightningnetwork/lnd/lnwire"
@@ -23,6 +24,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 	"gopkg.in/macaroon-bakery.v2/bakery"
 )
 
@@ -60,6 +62,13 @@ var (
 			Entity: "offchain",
 			Action: "write",
 		}},
+		"/routerrpc.Router/Rebalance": {{
+			Entity: "offchain",
+			Action: "write",
+		}, {
+			Entity: "invoices",
+			Action: "write",
+		}},
 		"/routerrpc.Router/SendToRouteV2": {{
 			Entity: "offchain",
 			Action: "write",
@@ -76,6 +85,10 @@ var (
 			Entity: "offchain",
 			Action: "read",
 		}},
+		"/routerrpc.Router/CancelPayment": {{
+			Entity: "offchain",
+			Action: "write",
+		}},
 		"/routerrpc.Router/EstimateRouteFee": {{
 			Entity: "offchain",
 			Action: "read",
@@ -318,6 +331,31 @@ func (s *Server) SendPaymentV2(req *SendPaymentRequest,
 		return err
 	}
 
+	return s.sendPayment(payment, stream, req.NoInflightUpdates)
+}
+
+// Rebalance moves funds between our own channels with a circular payment and
+// streams back the payment updates.
+//
+// NOTE: Part of the RouterServer interface.
+func (s *Server) Rebalance(req *RebalanceRequest,
+	stream Router_RebalanceServer) error {
+
+	payment, err := s.cfg.RouterBackend.extractIntentFromRebalanceRequest(
+		stream.Context(), req,
+	)
+	if err != nil {
+		return err
+	}
+
+	return s.sendPayment(payment, stream, req.NoInflightUpdates)
+}
+
+// sendPayment initializes the payment in the database, sends it and streams
+// back its updates.
+func (s *Server) sendPayment(payment *routing.LightningPayment,
+	stream Router_TrackPaymentV2Server, noInflightUpdates bool) error {
+
 	// Get the payment hash.
 	payHash := payment.Identifier()
 
@@ -352,9 +390,7 @@ func (s *Server) SendPaymentV2(req *SendPaymentRequest,
 	if err == nil {
 		// If the payment was sent successfully, we can start tracking
 		// the events.
-		return s.trackPayment(
-			sub, payHash, stream, req.NoInflightUpdates,
-		)
+		return s.trackPayment(sub, payHash, stream, noInflightUpdates)
 	}
 
 	// Otherwise, transform user errors to grpc code.
@@ -860,6 +896,40 @@ func (s *Server) TrackPaymentV2(request *TrackPaymentRequest,
 	return s.trackPayment(sub, payHash, stream, request.NoInflightUpdates)
 }
 
+// CancelPayment stops an in-flight payment. No new attempts are launched for
+// it, while the attempts in flight are allowed to resolve, after which the
+// payment fails as canceled.
+func (s *Server) CancelPayment(_ context.Context,
+	req *CancelPaymentRequest) (*CancelPaymentResponse, error) {
+
+	payHash, err := lntypes.MakeHash(req.PaymentHash)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	log.Infof("CancelPayment called for payment %v", payHash)
+
+	err = s.cfg.RouterBackend.Tower.CancelPayment(payHash)
+	switch {
+	case err == channeldb.ErrPaymentNotInitiated:
+		return nil, status.Error(codes.NotFound, err.Error())
+
+	case err == channeldb.ErrPaymentAlreadySucceeded ||
+		err == channeldb.ErrPaymentAlreadyFailed ||
+		err == channeldb.ErrPaymentPendingSettled ||
+		err == channeldb.ErrPaymentPendingFailed:
+
+		return nil, status.Error(
+			codes.FailedPrecondition, err.Error(),
+		)
+
+	case err != nil:
+		return nil, err
+	}
+
+	return &CancelPaymentResponse{}, nil
+}
+
 // subscribePayment subscribes to the payment updates for the given payment
 // hash.
 func (s *Server) subscribePayment(identifier lntypes.Hash) (
@@ -1024,10 +1094,21 @@ func (s *Server) BuildRoute(ctx context.Context,
 }
 
 // SubscribeHtlcEvents creates a uni-directional stream from the server to
-// the client which delivers a stream of htlc events.
+// the client which delivers a stream of htlc events. If the event log is
+// enabled, the events are served from the log, which allows the client to
+// replay the events it missed.
 func (s *Server) SubscribeHtlcEvents(req *SubscribeHtlcEventsRequest,
 	stream Router_SubscribeHtlcEventsServer) error {
 
+	eventLog := s.cfg.RouterBackend.HtlcEventLog
+	if eventLog != nil {
+		return s.streamHtlcEventLog(eventLog, req, stream)
+	}
+
+	if req.Replay {
+		return eventlog.ErrLogDisabled
+	}
+
 	htlcClient, err := s.cfg.RouterBackend.SubscribeHtlcEvents()
 	if err != nil {
 		return err
@@ -1036,11 +1117,7 @@ func (s *Server) SubscribeHtlcEvents(req *SubscribeHtlcEventsRequest,
 
 	// Send out an initial subscribed event so that the caller knows the
 	// point from which new events will be transmitted.
-	if err := stream.Send(&HtlcEvent{
-		Event: &HtlcEvent_SubscribedEvent{
-			SubscribedEvent: &SubscribedEvent{},
-		},
-	}); err != nil {
+	if err := sendSubscribedEvent(stream); err != nil {
 		return err
 	}
 
@@ -1072,6 +1149,68 @@ func (s *Server) SubscribeHtlcEvents(req *SubscribeHtlcEventsRequest,
 	}
 }
 
+// streamHtlcEventLog sends the htlc events of the event log to the client. If
+// requested, the stored events the client missed are replayed first.
+func (s *Server) streamHtlcEventLog(eventLog *eventlog.Log,
+	req *SubscribeHtlcEventsRequest,
+	stream Router_SubscribeHtlcEventsServer) error {
+
+	sub, err := eventLog.Subscribe(req.Replay, req.StartAfterSequence)
+	if err != nil {
+		return err
+	}
+	defer sub.Cancel()
+
+	// Send out an initial subscribed event so that the caller knows the
+	// subscription is established. Replayed events follow it.
+	if err := sendSubscribedEvent(stream); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case event := <-sub.Updates():
+			rpcEvent := &HtlcEvent{}
+			err := proto.Unmarshal(event.Payload, rpcEvent)
+			if err != nil {
+				return err
+			}
+			rpcEvent.Sequence = event.Sequence
+
+			if err := stream.Send(rpcEvent); err != nil {
+				return err
+			}
+
+		// If the stream's context is cancelled, return an error.
+		case <-stream.Context().Done():
+			log.Debugf("htlc event stream cancelled")
+			return stream.Context().Err()
+
+		// If the event log subscription terminates, exit with the
+		// reason.
+		case <-sub.Done():
+			if errors.Is(sub.Err(), eventlog.ErrLogStopped) {
+				return errServerShuttingDown
+			}
+			return sub.Err()
+
+		// If the server has been signalled to shut down, exit.
+		case <-s.quit:
+			return errServerShuttingDown
+		}
+	}
+}
+
+// sendSubscribedEvent sends the event that confirms a htlc event
+// subscription.
+func sendSubscribedEvent(stream Router_SubscribeHtlcEventsServer) error {
+	return stream.Send(&HtlcEvent{
+		Event: &HtlcEvent_SubscribedEvent{
+			SubscribedEvent: &SubscribedEvent{},
+		},
+	})
+}
+
 // HtlcInterceptor is a bidirectional stream for streaming interception
 // requests to the caller.
 // Upon connection it does the following:
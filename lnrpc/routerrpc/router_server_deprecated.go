@@ -22,7 +22,7 @@ type legacyTrackPaymentServer struct {
 func (i *legacyTrackPaymentServer) Send(p *lnrpc.Payment) error {
 	var state PaymentState
 	switch p.Status {
-	case lnrpc.Payment_IN_FLIGHT:
+	case lnrpc.Payment_IN_FLIGHT, lnrpc.Payment_CANCELLING:
 		state = PaymentState_IN_FLIGHT
 	case lnrpc.Payment_SUCCEEDED:
 		state = PaymentState_SUCCEEDED
@@ -46,6 +46,11 @@ func (i *legacyTrackPaymentServer) Send(p *lnrpc.Payment) error {
 		case lnrpc.PaymentFailureReason_FAILURE_REASON_INSUFFICIENT_BALANCE:
 			state = PaymentState_FAILED_INSUFFICIENT_BALANCE
 
+		// The deprecated states predate cancellation, so a canceled
+		// payment is reported as a generic failure.
+		case lnrpc.PaymentFailureReason_FAILURE_REASON_CANCELED:
+			state = PaymentState_FAILED_ERROR
+
 		default:
 			return fmt.Errorf("unknown failure reason %v",
 				p.FailureReason)
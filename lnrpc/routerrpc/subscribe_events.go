@@ -7,8 +7,20 @@ import (
 	"github.com/lightningnetwork/lnd/htlcswitch"
 	"github.com/lightningnetwork/lnd/invoices"
 	"github.com/lightningnetwork/lnd/lnrpc"
+	"google.golang.org/protobuf/proto"
 )
 
+// MarshalHtlcEvent encodes a htlcswitch event as a serialized rpc htlc event,
+// to be stored in the event log.
+func MarshalHtlcEvent(htlcEvent interface{}) ([]byte, error) {
+	rpcEvent, err := rpcHtlcEvent(htlcEvent)
+	if err != nil {
+		return nil, err
+	}
+
+	return proto.Marshal(rpcEvent)
+}
+
 // rpcHtlcEvent returns a rpc htlc event from a htlcswitch event.
 func rpcHtlcEvent(htlcEvent interface{}) (*HtlcEvent, error) {
 	var (
@@ -84,6 +96,33 @@ func rpcHtlcEvent(htlcEvent interface{}) (*HtlcEvent, error) {
 		}
 		timestamp = e.Timestamp
 
+	case *htlcswitch.RateLimitEvent:
+		limit, err := rpcRateLimit(e.Limit)
+		if err != nil {
+			return nil, err
+		}
+
+		scope := RateLimitEvent_PEER
+		if e.Scope == htlcswitch.RateLimitScopeChannel {
+			scope = RateLimitEvent_CHANNEL
+		}
+
+		event = &HtlcEvent_RateLimitEvent{
+			RateLimitEvent: &RateLimitEvent{
+				Limit:        limit,
+				Scope:        scope,
+				Peer:         e.Peer[:],
+				TriggerCount: e.Count,
+			},
+		}
+
+		// Rate limits only apply to htlcs forwarded from our peers.
+		forward := htlcswitch.HtlcEventTypeForward
+
+		key = e.HtlcKey
+		eventType = &forward
+		timestamp = e.Timestamp
+
 	default:
 		return nil, fmt.Errorf("unknown event type: %T", e)
 	}
@@ -254,8 +293,37 @@ func rpcOutgoingFailure(failureDetail htlcswitch.OutgoingFailure) (
 	case htlcswitch.OutgoingFailureForwardsDisabled:
 		return FailureDetail_FORWARDS_DISABLED, nil
 
+	case htlcswitch.OutgoingFailureNoResources:
+		return FailureDetail_NO_RESOURCES, nil
+
+	case htlcswitch.OutgoingFailureAddRateLimit:
+		return FailureDetail_ADD_RATE_LIMIT, nil
+
+	case htlcswitch.OutgoingFailureInFlightLimit:
+		return FailureDetail_IN_FLIGHT_LIMIT, nil
+
+	case htlcswitch.OutgoingFailureFailRatioLimit:
+		return FailureDetail_FAIL_RATIO_LIMIT, nil
+
 	default:
 		return 0, fmt.Errorf("unknown outgoing failure "+
 			"detail: %v", failureDetail.FailureString())
 	}
 }
+
+// rpcRateLimit maps a rate limit of the switch to its rpc counterpart.
+func rpcRateLimit(limit htlcswitch.RateLimit) (RateLimitEvent_Limit, error) {
+	switch limit {
+	case htlcswitch.RateLimitAddRate:
+		return RateLimitEvent_ADD_RATE, nil
+
+	case htlcswitch.RateLimitInFlight:
+		return RateLimitEvent_IN_FLIGHT, nil
+
+	case htlcswitch.RateLimitFailRatio:
+		return RateLimitEvent_FAIL_RATIO, nil
+
+	default:
+		return 0, fmt.Errorf("unknown rate limit: %v", limit)
+	}
+}
@@ -5,9 +5,12 @@ package walletrpc
 
 import (
 	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/lightningnetwork/lnd/channeldb"
 	"github.com/lightningnetwork/lnd/keychain"
+	"github.com/lightningnetwork/lnd/labels"
 	"github.com/lightningnetwork/lnd/lnwallet"
 	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
+	"github.com/lightningnetwork/lnd/lnwallet/txtracker"
 	"github.com/lightningnetwork/lnd/macaroons"
 	"github.com/lightningnetwork/lnd/sweep"
 )
@@ -68,6 +71,19 @@ type Config struct {
 	// ChainParams are the parameters of the wallet's backing chain.
 	ChainParams *chaincfg.Params
 
+	// ChanStateDB is the channel state database the WalletKit will use to
+	// look up pending channels when bumping the fee of their funding
+	// transaction.
+	ChanStateDB *channeldb.ChannelStateDB
+
+	// TxTracker keeps track of all transactions published by lnd and their
+	// mempool status.
+	TxTracker *txtracker.Tracker
+
+	// LabelStore holds the BIP-329 labels of the transactions, addresses
+	// and outputs of the wallet.
+	LabelStore labels.Store
+
 	// CurrentNumAnchorChans returns the current number of non-private
 	// anchor channels the wallet should be ready to fee bump if needed.
 	CurrentNumAnchorChans func() (int, error)
@@ -14,4 +14,27 @@ var (
 	ErrInsufficientReserve = errors.New("the outputs to be sent " +
 		"would leave insufficient reserves for anchor channels in " +
 		"the wallet")
+
+	// ErrFundingNotInitiator is returned when an attempt is made to bump
+	// the fee of the funding transaction of a channel we didn't open.
+	ErrFundingNotInitiator = errors.New("only the initiator of a " +
+		"channel can bump the fee of its funding transaction")
+
+	// ErrFundingTxUnknown is returned when the funding transaction of a
+	// channel wasn't created by the wallet, for example when it was funded
+	// through a PSBT.
+	ErrFundingTxUnknown = errors.New("funding transaction was not " +
+		"created by the wallet, use BumpFee with a wallet output of " +
+		"the funding transaction instead")
+
+	// ErrNoFundingChange is returned when the funding transaction of a
+	// channel has no output under the control of the wallet that can be
+	// spent to bump its fee.
+	ErrNoFundingChange = errors.New("funding transaction has no change " +
+		"output under the control of the wallet")
+
+	// ErrTxTrackerUnavailable is returned when the published transactions
+	// are queried but lnd doesn't track them.
+	ErrTxTrackerUnavailable = errors.New("transaction tracker is not " +
+		"available")
 )
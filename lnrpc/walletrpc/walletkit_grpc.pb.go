@@ -184,15 +184,50 @@ type WalletKitClient interface {
 	// fee preference being provided. For now, the responsibility of ensuring that
 	// the new fee preference is sufficient is delegated to the user.
 	BumpFee(ctx context.Context, in *BumpFeeRequest, opts ...grpc.CallOption) (*BumpFeeResponse, error)
+	// BumpFundingFee bumps the fee of the funding transaction of a pending
+	// channel we opened. The wallet controlled change output of the funding
+	// transaction is handed to lnd's central batching engine, which sweeps it
+	// with the requested fee preference to perform a Child-Pays-For-Parent
+	// (CPFP). The progress of the fee bump is reported by the PendingChannels
+	// RPC of the main Lightning service.
+	//
+	// Only channels whose funding transaction was created by the lnd wallet can
+	// be bumped through this RPC. Replacing the funding transaction itself (RBF)
+	// is not supported, as that would change the funding outpoint agreed upon
+	// with the peer.
+	BumpFundingFee(ctx context.Context, in *BumpFundingFeeRequest, opts ...grpc.CallOption) (*BumpFundingFeeResponse, error)
 	// ListSweeps returns a list of the sweep transactions our node has produced.
 	// Note that these sweeps may not be confirmed yet, as we record sweeps on
 	// broadcast, not confirmation.
 	ListSweeps(ctx context.Context, in *ListSweepsRequest, opts ...grpc.CallOption) (*ListSweepsResponse, error)
+	// ListPublishedTransactions returns the transactions lnd has published to
+	// the network, such as funding, sweep, close and user initiated transactions,
+	// together with their purpose, fee rate, replacement chain and current
+	// mempool status.
+	ListPublishedTransactions(ctx context.Context, in *ListPublishedTransactionsRequest, opts ...grpc.CallOption) (*ListPublishedTransactionsResponse, error)
+	// SubscribePublishedTransactions returns a stream of updates for the
+	// transactions lnd publishes. An update is sent each time a transaction is
+	// published or its status changes, for example when it is replaced, evicted
+	// from the mempool, double spent or confirmed.
+	SubscribePublishedTransactions(ctx context.Context, in *SubscribePublishedTransactionsRequest, opts ...grpc.CallOption) (WalletKit_SubscribePublishedTransactionsClient, error)
 	// LabelTransaction adds a label to a transaction. If the transaction already
 	// has a label the call will fail unless the overwrite bool is set. This will
 	// overwrite the exiting transaction label. Labels must not be empty, and
 	// cannot exceed 500 characters.
 	LabelTransaction(ctx context.Context, in *LabelTransactionRequest, opts ...grpc.CallOption) (*LabelTransactionResponse, error)
+	// ExportLabels exports the labels of the transactions, addresses and outputs
+	// of the wallet as a BIP-329 JSON Lines document. The labels lnd adds to the
+	// transactions it publishes reference the channel point of their channel or
+	// the purpose of the sweep. The document can optionally be encrypted with a
+	// key derived from the wallet seed, so that only this wallet can import it
+	// again.
+	ExportLabels(ctx context.Context, in *ExportLabelsRequest, opts ...grpc.CallOption) (*ExportLabelsResponse, error)
+	// ImportLabels imports the labels of a BIP-329 JSON Lines document, as
+	// created by ExportLabels or another wallet. Transaction labels are also set
+	// on the transactions known to the wallet. Records of types lnd doesn't
+	// support are skipped, as are labels that already exist unless the overwrite
+	// bool is set.
+	ImportLabels(ctx context.Context, in *ImportLabelsRequest, opts ...grpc.CallOption) (*ImportLabelsResponse, error)
 	// FundPsbt creates a fully populated PSBT that contains enough inputs to fund
 	// the outputs specified in the template. There are two ways of specifying a
 	// template: Either by passing in a PSBT with at least one output declared or
@@ -426,6 +461,15 @@ func (c *walletKitClient) BumpFee(ctx context.Context, in *BumpFeeRequest, opts
 	return out, nil
 }
 
+func (c *walletKitClient) BumpFundingFee(ctx context.Context, in *BumpFundingFeeRequest, opts ...grpc.CallOption) (*BumpFundingFeeResponse, error) {
+	out := new(BumpFundingFeeResponse)
+	err := c.cc.Invoke(ctx, "/walletrpc.WalletKit/BumpFundingFee", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *walletKitClient) ListSweeps(ctx context.Context, in *ListSweepsRequest, opts ...grpc.CallOption) (*ListSweepsResponse, error) {
 	out := new(ListSweepsResponse)
 	err := c.cc.Invoke(ctx, "/walletrpc.WalletKit/ListSweeps", in, out, opts...)
@@ -435,6 +479,47 @@ func (c *walletKitClient) ListSweeps(ctx context.Context, in *ListSweepsRequest,
 	return out, nil
 }
 
+func (c *walletKitClient) ListPublishedTransactions(ctx context.Context, in *ListPublishedTransactionsRequest, opts ...grpc.CallOption) (*ListPublishedTransactionsResponse, error) {
+	out := new(ListPublishedTransactionsResponse)
+	err := c.cc.Invoke(ctx, "/walletrpc.WalletKit/ListPublishedTransactions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletKitClient) SubscribePublishedTransactions(ctx context.Context, in *SubscribePublishedTransactionsRequest, opts ...grpc.CallOption) (WalletKit_SubscribePublishedTransactionsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &WalletKit_ServiceDesc.Streams[0], "/walletrpc.WalletKit/SubscribePublishedTransactions", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &walletKitSubscribePublishedTransactionsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type WalletKit_SubscribePublishedTransactionsClient interface {
+	Recv() (*PublishedTransaction, error)
+	grpc.ClientStream
+}
+
+type walletKitSubscribePublishedTransactionsClient struct {
+	grpc.ClientStream
+}
+
+func (x *walletKitSubscribePublishedTransactionsClient) Recv() (*PublishedTransaction, error) {
+	m := new(PublishedTransaction)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *walletKitClient) LabelTransaction(ctx context.Context, in *LabelTransactionRequest, opts ...grpc.CallOption) (*LabelTransactionResponse, error) {
 	out := new(LabelTransactionResponse)
 	err := c.cc.Invoke(ctx, "/walletrpc.WalletKit/LabelTransaction", in, out, opts...)
@@ -444,6 +529,24 @@ func (c *walletKitClient) LabelTransaction(ctx context.Context, in *LabelTransac
 	return out, nil
 }
 
+func (c *walletKitClient) ExportLabels(ctx context.Context, in *ExportLabelsRequest, opts ...grpc.CallOption) (*ExportLabelsResponse, error) {
+	out := new(ExportLabelsResponse)
+	err := c.cc.Invoke(ctx, "/walletrpc.WalletKit/ExportLabels", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletKitClient) ImportLabels(ctx context.Context, in *ImportLabelsRequest, opts ...grpc.CallOption) (*ImportLabelsResponse, error) {
+	out := new(ImportLabelsResponse)
+	err := c.cc.Invoke(ctx, "/walletrpc.WalletKit/ImportLabels", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *walletKitClient) FundPsbt(ctx context.Context, in *FundPsbtRequest, opts ...grpc.CallOption) (*FundPsbtResponse, error) {
 	out := new(FundPsbtResponse)
 	err := c.cc.Invoke(ctx, "/walletrpc.WalletKit/FundPsbt", in, out, opts...)
@@ -640,15 +743,50 @@ type WalletKitServer interface {
 	// fee preference being provided. For now, the responsibility of ensuring that
 	// the new fee preference is sufficient is delegated to the user.
 	BumpFee(context.Context, *BumpFeeRequest) (*BumpFeeResponse, error)
+	// BumpFundingFee bumps the fee of the funding transaction of a pending
+	// channel we opened. The wallet controlled change output of the funding
+	// transaction is handed to lnd's central batching engine, which sweeps it
+	// with the requested fee preference to perform a Child-Pays-For-Parent
+	// (CPFP). The progress of the fee bump is reported by the PendingChannels
+	// RPC of the main Lightning service.
+	//
+	// Only channels whose funding transaction was created by the lnd wallet can
+	// be bumped through this RPC. Replacing the funding transaction itself (RBF)
+	// is not supported, as that would change the funding outpoint agreed upon
+	// with the peer.
+	BumpFundingFee(context.Context, *BumpFundingFeeRequest) (*BumpFundingFeeResponse, error)
 	// ListSweeps returns a list of the sweep transactions our node has produced.
 	// Note that these sweeps may not be confirmed yet, as we record sweeps on
 	// broadcast, not confirmation.
 	ListSweeps(context.Context, *ListSweepsRequest) (*ListSweepsResponse, error)
+	// ListPublishedTransactions returns the transactions lnd has published to
+	// the network, such as funding, sweep, close and user initiated transactions,
+	// together with their purpose, fee rate, replacement chain and current
+	// mempool status.
+	ListPublishedTransactions(context.Context, *ListPublishedTransactionsRequest) (*ListPublishedTransactionsResponse, error)
+	// SubscribePublishedTransactions returns a stream of updates for the
+	// transactions lnd publishes. An update is sent each time a transaction is
+	// published or its status changes, for example when it is replaced, evicted
+	// from the mempool, double spent or confirmed.
+	SubscribePublishedTransactions(*SubscribePublishedTransactionsRequest, WalletKit_SubscribePublishedTransactionsServer) error
 	// LabelTransaction adds a label to a transaction. If the transaction already
 	// has a label the call will fail unless the overwrite bool is set. This will
 	// overwrite the exiting transaction label. Labels must not be empty, and
 	// cannot exceed 500 characters.
 	LabelTransaction(context.Context, *LabelTransactionRequest) (*LabelTransactionResponse, error)
+	// ExportLabels exports the labels of the transactions, addresses and outputs
+	// of the wallet as a BIP-329 JSON Lines document. The labels lnd adds to the
+	// transactions it publishes reference the channel point of their channel or
+	// the purpose of the sweep. The document can optionally be encrypted with a
+	// key derived from the wallet seed, so that only this wallet can import it
+	// again.
+	ExportLabels(context.Context, *ExportLabelsRequest) (*ExportLabelsResponse, error)
+	// ImportLabels imports the labels of a BIP-329 JSON Lines document, as
+	// created by ExportLabels or another wallet. Transaction labels are also set
+	// on the transactions known to the wallet. Records of types lnd doesn't
+	// support are skipped, as are labels that already exist unless the overwrite
+	// bool is set.
+	ImportLabels(context.Context, *ImportLabelsRequest) (*ImportLabelsResponse, error)
 	// FundPsbt creates a fully populated PSBT that contains enough inputs to fund
 	// the outputs specified in the template. There are two ways of specifying a
 	// template: Either by passing in a PSBT with at least one output declared or
@@ -759,12 +897,27 @@ func (UnimplementedWalletKitServer) PendingSweeps(context.Context, *PendingSweep
 func (UnimplementedWalletKitServer) BumpFee(context.Context, *BumpFeeRequest) (*BumpFeeResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method BumpFee not implemented")
 }
+func (UnimplementedWalletKitServer) BumpFundingFee(context.Context, *BumpFundingFeeRequest) (*BumpFundingFeeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BumpFundingFee not implemented")
+}
 func (UnimplementedWalletKitServer) ListSweeps(context.Context, *ListSweepsRequest) (*ListSweepsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListSweeps not implemented")
 }
+func (UnimplementedWalletKitServer) ListPublishedTransactions(context.Context, *ListPublishedTransactionsRequest) (*ListPublishedTransactionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPublishedTransactions not implemented")
+}
+func (UnimplementedWalletKitServer) SubscribePublishedTransactions(*SubscribePublishedTransactionsRequest, WalletKit_SubscribePublishedTransactionsServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribePublishedTransactions not implemented")
+}
 func (UnimplementedWalletKitServer) LabelTransaction(context.Context, *LabelTransactionRequest) (*LabelTransactionResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method LabelTransaction not implemented")
 }
+func (UnimplementedWalletKitServer) ExportLabels(context.Context, *ExportLabelsRequest) (*ExportLabelsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportLabels not implemented")
+}
+func (UnimplementedWalletKitServer) ImportLabels(context.Context, *ImportLabelsRequest) (*ImportLabelsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ImportLabels not implemented")
+}
 func (UnimplementedWalletKitServer) FundPsbt(context.Context, *FundPsbtRequest) (*FundPsbtResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method FundPsbt not implemented")
 }
@@ -1147,6 +1300,24 @@ func _WalletKit_BumpFee_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _WalletKit_BumpFundingFee_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BumpFundingFeeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletKitServer).BumpFundingFee(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walletrpc.WalletKit/BumpFundingFee",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletKitServer).BumpFundingFee(ctx, req.(*BumpFundingFeeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _WalletKit_ListSweeps_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ListSweepsRequest)
 	if err := dec(in); err != nil {
@@ -1165,6 +1336,45 @@ func _WalletKit_ListSweeps_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _WalletKit_ListPublishedTransactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPublishedTransactionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletKitServer).ListPublishedTransactions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walletrpc.WalletKit/ListPublishedTransactions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletKitServer).ListPublishedTransactions(ctx, req.(*ListPublishedTransactionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletKit_SubscribePublishedTransactions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribePublishedTransactionsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(WalletKitServer).SubscribePublishedTransactions(m, &walletKitSubscribePublishedTransactionsServer{stream})
+}
+
+type WalletKit_SubscribePublishedTransactionsServer interface {
+	Send(*PublishedTransaction) error
+	grpc.ServerStream
+}
+
+type walletKitSubscribePublishedTransactionsServer struct {
+	grpc.ServerStream
+}
+
+func (x *walletKitSubscribePublishedTransactionsServer) Send(m *PublishedTransaction) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 func _WalletKit_LabelTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(LabelTransactionRequest)
 	if err := dec(in); err != nil {
@@ -1183,6 +1393,42 @@ func _WalletKit_LabelTransaction_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _WalletKit_ExportLabels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportLabelsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletKitServer).ExportLabels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walletrpc.WalletKit/ExportLabels",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletKitServer).ExportLabels(ctx, req.(*ExportLabelsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletKit_ImportLabels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportLabelsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletKitServer).ImportLabels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walletrpc.WalletKit/ImportLabels",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletKitServer).ImportLabels(ctx, req.(*ImportLabelsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _WalletKit_FundPsbt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(FundPsbtRequest)
 	if err := dec(in); err != nil {
@@ -1324,14 +1570,30 @@ var WalletKit_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "BumpFee",
 			Handler:    _WalletKit_BumpFee_Handler,
 		},
+		{
+			MethodName: "BumpFundingFee",
+			Handler:    _WalletKit_BumpFundingFee_Handler,
+		},
 		{
 			MethodName: "ListSweeps",
 			Handler:    _WalletKit_ListSweeps_Handler,
 		},
+		{
+			MethodName: "ListPublishedTransactions",
+			Handler:    _WalletKit_ListPublishedTransactions_Handler,
+		},
 		{
 			MethodName: "LabelTransaction",
 			Handler:    _WalletKit_LabelTransaction_Handler,
 		},
+		{
+			MethodName: "ExportLabels",
+			Handler:    _WalletKit_ExportLabels_Handler,
+		},
+		{
+			MethodName: "ImportLabels",
+			Handler:    _WalletKit_ImportLabels_Handler,
+		},
 		{
 			MethodName: "FundPsbt",
 			Handler:    _WalletKit_FundPsbt_Handler,
@@ -1345,6 +1607,12 @@ var WalletKit_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _WalletKit_FinalizePsbt_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribePublishedTransactions",
+			Handler:       _WalletKit_SubscribePublishedTransactions_Handler,
+			ServerStreams: true,
+		},
+	},
 	Metadata: "walletrpc/walletkit.proto",
 }
@@ -30,17 +30,22 @@ import (
 	base "github.com/btcsuite/btcwallet/wallet"
 	"github.com/btcsuite/btcwallet/wtxmgr"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/lightningnetwork/lnd/channeldb"
 	"github.com/lightningnetwork/lnd/input"
 	"github.com/lightningnetwork/lnd/keychain"
 	"github.com/lightningnetwork/lnd/labels"
+	"github.com/lightningnetwork/lnd/lnencrypt"
 	"github.com/lightningnetwork/lnd/lnrpc"
 	"github.com/lightningnetwork/lnd/lnrpc/signrpc"
 	"github.com/lightningnetwork/lnd/lnwallet"
 	"github.com/lightningnetwork/lnd/lnwallet/btcwallet"
 	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
+	"github.com/lightningnetwork/lnd/lnwallet/txtracker"
 	"github.com/lightningnetwork/lnd/macaroons"
 	"github.com/lightningnetwork/lnd/sweep"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"gopkg.in/macaroon-bakery.v2/bakery"
 )
 
@@ -100,14 +105,34 @@ var (
 			Entity: "onchain",
 			Action: "write",
 		}},
+		"/walletrpc.WalletKit/BumpFundingFee": {{
+			Entity: "onchain",
+			Action: "write",
+		}},
 		"/walletrpc.WalletKit/ListSweeps": {{
 			Entity: "onchain",
 			Action: "read",
 		}},
+		"/walletrpc.WalletKit/ListPublishedTransactions": {{
+			Entity: "onchain",
+			Action: "read",
+		}},
+		"/walletrpc.WalletKit/SubscribePublishedTransactions": {{
+			Entity: "onchain",
+			Action: "read",
+		}},
 		"/walletrpc.WalletKit/LabelTransaction": {{
 			Entity: "onchain",
 			Action: "write",
 		}},
+		"/walletrpc.WalletKit/ExportLabels": {{
+			Entity: "onchain",
+			Action: "read",
+		}},
+		"/walletrpc.WalletKit/ImportLabels": {{
+			Entity: "onchain",
+			Action: "write",
+		}},
 		"/walletrpc.WalletKit/LeaseOutput": {{
 			Entity: "onchain",
 			Action: "write",
@@ -219,6 +244,7 @@ var (
 		input.LeaseHtlcOfferedTimeoutSecondLevel:           WitnessType_LEASE_HTLC_OFFERED_TIMEOUT_SECOND_LEVEL,
 		input.LeaseHtlcAcceptedSuccessSecondLevel:          WitnessType_LEASE_HTLC_ACCEPTED_SUCCESS_SECOND_LEVEL,
 		input.TaprootPubKeySpend:                           WitnessType_TAPROOT_PUB_KEY_SPEND,
+		input.PayToAnchorSpend:                             WitnessType_PAY_TO_ANCHOR_SPEND,
 	}
 )
 
@@ -872,6 +898,19 @@ func (w *WalletKit) BumpFee(ctx context.Context,
 		FeeRate:    satPerKw,
 	}
 
+	if err := w.bumpFee(*op, feePreference, in.Force); err != nil {
+		return nil, err
+	}
+
+	return &BumpFeeResponse{}, nil
+}
+
+// bumpFee bumps the fee of the given input through the UtxoSweeper. If the
+// sweeper isn't aware of the input yet, the input is assumed to be a wallet
+// output of an unconfirmed transaction, which is then swept to perform a CPFP.
+func (w *WalletKit) bumpFee(op wire.OutPoint, feePreference sweep.FeePreference,
+	force bool) error {
+
 	// We'll attempt to bump the fee of the input through the UtxoSweeper.
 	// If it is currently attempting to sweep the input, then it'll simply
 	// bump its fee, which will result in a replacement transaction (RBF)
@@ -879,17 +918,17 @@ func (w *WalletKit) BumpFee(ctx context.Context,
 	// lnwallet.ErrNotMine is returned.
 	params := sweep.ParamsUpdate{
 		Fee:   feePreference,
-		Force: in.Force,
+		Force: force,
 	}
 
-	_, err = w.cfg.Sweeper.UpdateParams(*op, params)
+	_, err := w.cfg.Sweeper.UpdateParams(op, params)
 	switch err {
 	case nil:
-		return &BumpFeeResponse{}, nil
+		return nil
 	case lnwallet.ErrNotMine:
 		break
 	default:
-		return nil, err
+		return err
 	}
 
 	log.Debugf("Attempting to CPFP outpoint %s", op)
@@ -902,14 +941,14 @@ func (w *WalletKit) BumpFee(ctx context.Context,
 	//
 	// We'll gather all of the information required by the UtxoSweeper in
 	// order to sweep the output.
-	utxo, err := w.cfg.Wallet.FetchInputInfo(op)
+	utxo, err := w.cfg.Wallet.FetchInputInfo(&op)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	// We're only able to bump the fee of unconfirmed transactions.
 	if utxo.Confirmations > 0 {
-		return nil, errors.New("unable to bump fee of a confirmed " +
+		return errors.New("unable to bump fee of a confirmed " +
 			"transaction")
 	}
 
@@ -931,27 +970,91 @@ func (w *WalletKit) BumpFee(ctx context.Context,
 		witnessType = input.TaprootPubKeySpend
 		signDesc.HashType = txscript.SigHashDefault
 	default:
-		return nil, fmt.Errorf("unknown input witness %v", op)
+		return fmt.Errorf("unknown input witness %v", op)
 	}
 
 	// We'll use the current height as the height hint since we're dealing
 	// with an unconfirmed transaction.
 	_, currentHeight, err := w.cfg.Chain.GetBestBlock()
 	if err != nil {
-		return nil, fmt.Errorf("unable to retrieve current height: %v",
+		return fmt.Errorf("unable to retrieve current height: %v",
 			err)
 	}
 
 	inp := input.NewBaseInput(
-		op, witnessType, signDesc, uint32(currentHeight),
+		&op, witnessType, signDesc, uint32(currentHeight),
 	)
 
 	sweepParams := sweep.Params{Fee: feePreference}
 	if _, err = w.cfg.Sweeper.SweepInput(inp, sweepParams); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// BumpFundingFee bumps the fee of the funding transaction of a pending channel
+// we opened by sweeping its wallet controlled change output through the
+// UtxoSweeper, which results in a CPFP. The status of the fee bump can be
+// checked through the PendingChannels RPC.
+func (w *WalletKit) BumpFundingFee(ctx context.Context,
+	in *BumpFundingFeeRequest) (*BumpFundingFeeResponse, error) {
+
+	if in.ChanPoint == nil {
+		return nil, fmt.Errorf("channel point must be specified")
+	}
+	fundingTxid, err := lnrpc.GetChanPointFundingTxid(in.ChanPoint)
+	if err != nil {
+		return nil, err
+	}
+	chanPoint := wire.OutPoint{
+		Hash:  *fundingTxid,
+		Index: in.ChanPoint.OutputIndex,
+	}
+
+	// Only channels that are still pending can have their funding
+	// transaction bumped.
+	pendingChannels, err := w.cfg.ChanStateDB.FetchPendingChannels()
+	if err != nil {
 		return nil, err
 	}
 
-	return &BumpFeeResponse{}, nil
+	var channel *channeldb.OpenChannel
+	for _, pendingChan := range pendingChannels {
+		if pendingChan.FundingOutpoint == chanPoint {
+			channel = pendingChan
+			break
+		}
+	}
+	if channel == nil {
+		return nil, fmt.Errorf("pending channel %v not found",
+			chanPoint)
+	}
+
+	changeOutput, err := fundingChangeOutput(
+		channel, w.cfg.Wallet.FetchInputInfo,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("Attempting to bump fee of funding transaction of "+
+		"channel %v by spending output %v", chanPoint, changeOutput)
+
+	feePreference := sweep.FeePreference{
+		ConfTarget: in.TargetConf,
+		FeeRate: chainfee.SatPerKVByte(
+			in.SatPerVbyte * 1000,
+		).FeePerKWeight(),
+	}
+	err = w.bumpFee(*changeOutput, feePreference, in.Force)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BumpFundingFeeResponse{
+		Outpoint: lnrpc.MarshalOutPoint(changeOutput),
+	}, nil
 }
 
 // ListSweeps returns a list of the sweeps that our node has published.
@@ -1019,6 +1122,83 @@ func (w *WalletKit) ListSweeps(ctx context.Context,
 	}, nil
 }
 
+// ListPublishedTransactions returns the transactions lnd has published
+// together with their current status.
+func (w *WalletKit) ListPublishedTransactions(ctx context.Context,
+	req *ListPublishedTransactionsRequest) (
+	*ListPublishedTransactionsResponse, error) {
+
+	if w.cfg.TxTracker == nil {
+		return nil, ErrTxTrackerUnavailable
+	}
+
+	txs, err := w.cfg.TxTracker.PublishedTxs(req.ActiveOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	rpcTxs := make([]*PublishedTransaction, 0, len(txs))
+	for _, tx := range txs {
+		rpcTx, err := marshallPublishedTx(tx)
+		if err != nil {
+			return nil, err
+		}
+
+		rpcTxs = append(rpcTxs, rpcTx)
+	}
+
+	return &ListPublishedTransactionsResponse{
+		Transactions: rpcTxs,
+	}, nil
+}
+
+// SubscribePublishedTransactions streams an update each time a transaction is
+// published by lnd or the status of a published transaction changes.
+func (w *WalletKit) SubscribePublishedTransactions(
+	req *SubscribePublishedTransactionsRequest,
+	stream WalletKit_SubscribePublishedTransactionsServer) error {
+
+	if w.cfg.TxTracker == nil {
+		return ErrTxTrackerUnavailable
+	}
+
+	client, err := w.cfg.TxTracker.SubscribeTxUpdates()
+	if err != nil {
+		return err
+	}
+	defer client.Cancel()
+
+	for {
+		select {
+		case update := <-client.Updates():
+			tx, ok := update.(*txtracker.PublishedTx)
+			if !ok {
+				return fmt.Errorf("unexpected update type: %T",
+					update)
+			}
+
+			rpcTx, err := marshallPublishedTx(tx)
+			if err != nil {
+				return err
+			}
+
+			if err := stream.Send(rpcTx); err != nil {
+				return err
+			}
+
+		// If the stream's context is cancelled, return an error.
+		case <-stream.Context().Done():
+			log.Debugf("published transactions stream cancelled")
+			return stream.Context().Err()
+
+		// If the subscribe client terminates, exit with an error.
+		case <-client.Quit():
+			return errors.New("published transactions " +
+				"subscription terminated")
+		}
+	}
+}
+
 // LabelTransaction adds a label to a transaction.
 func (w *WalletKit) LabelTransaction(ctx context.Context,
 	req *LabelTransactionRequest) (*LabelTransactionResponse, error) {
@@ -1041,7 +1221,213 @@ func (w *WalletKit) LabelTransaction(ctx context.Context,
 	}
 
 	err = w.cfg.Wallet.LabelTransaction(*hash, req.Label, req.Overwrite)
-	return &LabelTransactionResponse{}, err
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the label together with the address and output labels, so that
+	// it is part of the label exports.
+	err = w.cfg.LabelStore.PutRecord(&labels.Record{
+		Type:  labels.RecordTypeTx,
+		Ref:   hash.String(),
+		Label: req.Label,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &LabelTransactionResponse{}, nil
+}
+
+// ExportLabels exports the labels of the transactions, addresses and outputs
+// of the wallet as a BIP-329 JSON Lines document, optionally encrypted with a
+// key derived from the wallet seed.
+func (w *WalletKit) ExportLabels(_ context.Context,
+	req *ExportLabelsRequest) (*ExportLabelsResponse, error) {
+
+	records, err := w.cfg.LabelStore.FetchRecords()
+	if err != nil {
+		return nil, err
+	}
+
+	// The wallet holds the labels of the transactions it knows, including
+	// the ones lnd adds to the transactions it publishes. They take
+	// precedence over the stored transaction labels, as lnd updates them
+	// directly in the wallet, for example once a funding transaction
+	// confirmed.
+	txs, err := w.cfg.Wallet.ListTransactionDetails(
+		0, btcwallet.UnconfirmedHeight, "",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	txLabels := make(map[string]string, len(txs))
+	for _, tx := range txs {
+		if tx.Label != "" {
+			txLabels[tx.Hash.String()] = tx.Label
+		}
+	}
+
+	for _, record := range records {
+		if record.Type != labels.RecordTypeTx {
+			continue
+		}
+
+		if label, ok := txLabels[record.Ref]; ok {
+			record.Label = label
+			delete(txLabels, record.Ref)
+		}
+	}
+	for txid, label := range txLabels {
+		records = append(records, &labels.Record{
+			Type:  labels.RecordTypeTx,
+			Ref:   txid,
+			Label: label,
+		})
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Type != records[j].Type {
+			return records[i].Type < records[j].Type
+		}
+
+		return records[i].Ref < records[j].Ref
+	})
+
+	var doc bytes.Buffer
+	if err := labels.WriteRecords(&doc, records); err != nil {
+		return nil, err
+	}
+
+	exported := doc.Bytes()
+	if req.Encrypt {
+		exported, err = w.encryptLabels(exported)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &ExportLabelsResponse{
+		Labels:    exported,
+		NumLabels: uint32(len(records)),
+	}, nil
+}
+
+// ImportLabels imports the labels of a BIP-329 JSON Lines document. Existing
+// labels are only replaced if the overwrite flag is set.
+func (w *WalletKit) ImportLabels(_ context.Context,
+	req *ImportLabelsRequest) (*ImportLabelsResponse, error) {
+
+	doc := req.Labels
+	if req.Encrypted {
+		var err error
+		doc, err = w.decryptLabels(doc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	records, err := labels.ReadRecords(bytes.NewReader(doc))
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "unable to "+
+			"read labels: %v", err)
+	}
+
+	resp := &ImportLabelsResponse{}
+	for _, record := range records {
+		imported, err := w.importLabel(record, req.Overwrite)
+		if err != nil {
+			return nil, fmt.Errorf("unable to import label of %v "+
+				"%v: %w", record.Type, record.Ref, err)
+		}
+
+		if imported {
+			resp.NumImported++
+		} else {
+			resp.NumSkipped++
+		}
+	}
+
+	return resp, nil
+}
+
+// importLabel stores the given label record and, for transactions known to
+// the wallet, sets the label of the transaction. False is returned if the
+// record was skipped because a label already exists and overwrite isn't set.
+func (w *WalletKit) importLabel(record *labels.Record,
+	overwrite bool) (bool, error) {
+
+	_, err := w.cfg.LabelStore.FetchRecord(record.Type, record.Ref)
+	switch {
+	case err == nil && !overwrite:
+		return false, nil
+
+	case err != nil && !errors.Is(err, labels.ErrRecordNotFound):
+		return false, err
+	}
+
+	if record.Type == labels.RecordTypeTx && record.Label != "" {
+		hash, err := chainhash.NewHashFromStr(record.Ref)
+		if err != nil {
+			return false, err
+		}
+
+		err = w.cfg.Wallet.LabelTransaction(
+			*hash, record.Label, overwrite,
+		)
+		switch {
+		// The transaction already has a label in the wallet.
+		case errors.Is(err, base.ErrTxLabelExists):
+			return false, nil
+
+		// We still keep the labels of transactions the wallet doesn't
+		// know, as they may be exported again.
+		case errors.Is(err, base.ErrUnknownTransaction):
+
+		case err != nil:
+			return false, err
+		}
+	}
+
+	return true, w.cfg.LabelStore.PutRecord(record)
+}
+
+// encryptLabels encrypts a label export with a key derived from the wallet
+// seed.
+func (w *WalletKit) encryptLabels(doc []byte) ([]byte, error) {
+	encrypter, err := lnencrypt.KeyRingEncrypter(w.cfg.KeyRing)
+	if err != nil {
+		return nil, fmt.Errorf("unable to derive encryption key: %w",
+			err)
+	}
+
+	var encrypted bytes.Buffer
+	err = encrypter.EncryptPayloadToWriter(doc, &encrypted)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encrypt labels: %w", err)
+	}
+
+	return encrypted.Bytes(), nil
+}
+
+// decryptLabels decrypts a label export that was encrypted by encryptLabels.
+func (w *WalletKit) decryptLabels(encrypted []byte) ([]byte, error) {
+	encrypter, err := lnencrypt.KeyRingEncrypter(w.cfg.KeyRing)
+	if err != nil {
+		return nil, fmt.Errorf("unable to derive encryption key: %w",
+			err)
+	}
+
+	doc, err := encrypter.DecryptPayloadFromReader(
+		bytes.NewReader(encrypted),
+	)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "unable to "+
+			"decrypt labels: %v", err)
+	}
+
+	return doc, nil
 }
 
 // FundPsbt creates a fully populated PSBT that contains enough inputs to fund
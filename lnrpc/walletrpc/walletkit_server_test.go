@@ -4,9 +4,18 @@
 package walletrpc
 
 import (
+	"bytes"
+	"context"
 	"strings"
 	"testing"
 
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	base "github.com/btcsuite/btcwallet/wallet"
+	"github.com/lightningnetwork/lnd/kvdb"
+	"github.com/lightningnetwork/lnd/labels"
+	"github.com/lightningnetwork/lnd/lnencrypt"
+	"github.com/lightningnetwork/lnd/lnwallet"
 	"github.com/stretchr/testify/require"
 )
 
@@ -48,3 +57,146 @@ func TestWitnessTypeMapping(t *testing.T) {
 		})
 	}
 }
+
+// labelWallet is a wallet that only supports the transaction label calls.
+type labelWallet struct {
+	lnwallet.WalletController
+
+	txs []*lnwallet.TransactionDetail
+}
+
+// ListTransactionDetails returns the transactions of the wallet.
+func (l *labelWallet) ListTransactionDetails(_, _ int32,
+	_ string) ([]*lnwallet.TransactionDetail, error) {
+
+	return l.txs, nil
+}
+
+// LabelTransaction labels a transaction of the wallet.
+func (l *labelWallet) LabelTransaction(hash chainhash.Hash, label string,
+	overwrite bool) error {
+
+	for _, tx := range l.txs {
+		if tx.Hash != hash {
+			continue
+		}
+
+		if tx.Label != "" && !overwrite {
+			return base.ErrTxLabelExists
+		}
+		tx.Label = label
+
+		return nil
+	}
+
+	return base.ErrUnknownTransaction
+}
+
+// TestExportImportLabels tests that the wallet labels are exported as an
+// encrypted BIP-329 document and imported again.
+func TestExportImportLabels(t *testing.T) {
+	t.Parallel()
+
+	db, cleanUp, err := kvdb.GetTestBackend(t.TempDir(), "labels")
+	require.NoError(t, err)
+	t.Cleanup(cleanUp)
+
+	store, err := labels.NewStore(db)
+	require.NoError(t, err)
+
+	chanPoint := wire.OutPoint{Hash: chainhash.Hash{9}}
+	openLabel := labels.MakeChannelLabel(
+		labels.LabelTypeChannelOpen, nil, chanPoint,
+	)
+	wallet := &labelWallet{
+		txs: []*lnwallet.TransactionDetail{{
+			Hash:  chainhash.Hash{1},
+			Label: openLabel,
+		}, {
+			Hash: chainhash.Hash{2},
+		}},
+	}
+	w := &WalletKit{
+		cfg: &Config{
+			Wallet:     wallet,
+			KeyRing:    &lnencrypt.MockKeyRing{},
+			LabelStore: store,
+		},
+	}
+
+	ctx := context.Background()
+	_, err = w.LabelTransaction(ctx, &LabelTransactionRequest{
+		Txid:  wallet.txs[1].Hash[:],
+		Label: "rent",
+	})
+	require.NoError(t, err)
+
+	spendable := false
+	addr := &labels.Record{
+		Type:  labels.RecordTypeAddr,
+		Ref:   "bc1q34aq5drpuwy3wgl9lhup9892qp6svr8ldzyy7c",
+		Label: "donations",
+	}
+	output := &labels.Record{
+		Type:      labels.RecordTypeOutput,
+		Ref:       chanPoint.String(),
+		Label:     "cold storage",
+		Spendable: &spendable,
+	}
+	require.NoError(t, store.PutRecord(addr))
+	require.NoError(t, store.PutRecord(output))
+
+	resp, err := w.ExportLabels(ctx, &ExportLabelsRequest{
+		Encrypt: true,
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, 4, resp.NumLabels)
+
+	// The encrypted export can't be read as a plain document.
+	_, err = w.ImportLabels(ctx, &ImportLabelsRequest{
+		Labels: resp.Labels,
+	})
+	require.Error(t, err)
+
+	doc, err := w.decryptLabels(resp.Labels)
+	require.NoError(t, err)
+	records, err := labels.ReadRecords(bytes.NewReader(doc))
+	require.NoError(t, err)
+	require.Equal(t, []*labels.Record{addr, output, {
+		Type:  labels.RecordTypeTx,
+		Ref:   wallet.txs[0].Hash.String(),
+		Label: openLabel,
+	}, {
+		Type:  labels.RecordTypeTx,
+		Ref:   wallet.txs[1].Hash.String(),
+		Label: "rent",
+	}}, records)
+
+	// Importing the export again skips all existing labels.
+	importResp, err := w.ImportLabels(ctx, &ImportLabelsRequest{
+		Labels:    resp.Labels,
+		Encrypted: true,
+	})
+	require.NoError(t, err)
+	require.Zero(t, importResp.NumImported)
+	require.EqualValues(t, 4, importResp.NumSkipped)
+
+	// A new label of a transaction the wallet doesn't know is stored,
+	// while the label of a wallet transaction is replaced if requested.
+	unknownTxid := chainhash.Hash{3}.String()
+	newDoc := `{"type":"tx","ref":"` + unknownTxid + `","label":"gift"}
+{"type":"tx","ref":"` + wallet.txs[1].Hash.String() + `","label":"rent 2"}
+{"type":"xpub","ref":"xpub661MyMwAqRbcF","label":"cold"}
+`
+	importResp, err = w.ImportLabels(ctx, &ImportLabelsRequest{
+		Labels:    []byte(newDoc),
+		Overwrite: true,
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, 3, importResp.NumImported)
+	require.Equal(t, "rent 2", wallet.txs[1].Label)
+
+	record, err := store.FetchRecord(labels.RecordTypeTx, unknownTxid)
+	require.NoError(t, err)
+	require.Equal(t, "gift", record.Label)
+}
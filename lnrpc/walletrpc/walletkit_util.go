@@ -6,9 +6,14 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/wire"
+	"github.com/lightningnetwork/lnd/channeldb"
 	"github.com/lightningnetwork/lnd/lnrpc"
+	"github.com/lightningnetwork/lnd/lnwallet"
+	"github.com/lightningnetwork/lnd/lnwallet/txtracker"
 )
 
 // AccountsToWatchOnly converts the accounts returned by the walletkit's
@@ -96,3 +101,147 @@ func doubleHashMessage(prefix string, msg string) ([]byte, error) {
 
 	return digest, nil
 }
+
+// fundingChangeOutput returns the output of the funding transaction of the
+// given pending channel that is under the control of the wallet and can
+// therefore be spent to bump the fee of the funding transaction through CPFP.
+func fundingChangeOutput(channel *channeldb.OpenChannel,
+	fetchInputInfo func(*wire.OutPoint) (*lnwallet.Utxo, error)) (
+	*wire.OutPoint, error) {
+
+	if !channel.IsInitiator {
+		return nil, ErrFundingNotInitiator
+	}
+
+	// Only channels funded by the wallet have their funding transaction
+	// stored with them.
+	fundingTx := channel.FundingTxn
+	if fundingTx == nil {
+		return nil, ErrFundingTxUnknown
+	}
+
+	fundingTxid := fundingTx.TxHash()
+	for i := range fundingTx.TxOut {
+		op := wire.OutPoint{
+			Hash:  fundingTxid,
+			Index: uint32(i),
+		}
+		if op == channel.FundingOutpoint {
+			continue
+		}
+
+		// The wallet reports different errors for outputs it doesn't
+		// control depending on how much it knows about the output, so
+		// we skip any output it fails to look up.
+		if _, err := fetchInputInfo(&op); err != nil {
+			log.Debugf("Skipping funding output %v: %v", op, err)
+			continue
+		}
+
+		return &op, nil
+	}
+
+	return nil, ErrNoFundingChange
+}
+
+// marshallTxStatus converts the status of a published transaction into its
+// RPC counterpart.
+func marshallTxStatus(status txtracker.TxStatus) (PublishedTxStatus, error) {
+	switch status {
+	case txtracker.TxStatusPending:
+		return PublishedTxStatus_TX_STATUS_PENDING, nil
+
+	case txtracker.TxStatusRejected:
+		return PublishedTxStatus_TX_STATUS_REJECTED, nil
+
+	case txtracker.TxStatusReplaced:
+		return PublishedTxStatus_TX_STATUS_REPLACED, nil
+
+	case txtracker.TxStatusDoubleSpent:
+		return PublishedTxStatus_TX_STATUS_DOUBLE_SPENT, nil
+
+	case txtracker.TxStatusEvicted:
+		return PublishedTxStatus_TX_STATUS_EVICTED, nil
+
+	case txtracker.TxStatusConfirmed:
+		return PublishedTxStatus_TX_STATUS_CONFIRMED, nil
+
+	default:
+		return 0, fmt.Errorf("unknown transaction status: %v", status)
+	}
+}
+
+// marshallTxPurpose converts the purpose of a published transaction into its
+// RPC counterpart.
+func marshallTxPurpose(purpose txtracker.TxPurpose) TxPurpose {
+	switch purpose {
+	case txtracker.TxPurposeUser:
+		return TxPurpose_TX_PURPOSE_USER
+
+	case txtracker.TxPurposeChannelOpen:
+		return TxPurpose_TX_PURPOSE_CHANNEL_OPEN
+
+	case txtracker.TxPurposeChannelClose:
+		return TxPurpose_TX_PURPOSE_CHANNEL_CLOSE
+
+	case txtracker.TxPurposeSweep:
+		return TxPurpose_TX_PURPOSE_SWEEP
+
+	case txtracker.TxPurposeJustice:
+		return TxPurpose_TX_PURPOSE_JUSTICE
+
+	default:
+		return TxPurpose_TX_PURPOSE_UNKNOWN
+	}
+}
+
+// marshallPublishedTx converts the record of a published transaction into its
+// RPC counterpart.
+func marshallPublishedTx(p *txtracker.PublishedTx) (*PublishedTransaction,
+	error) {
+
+	status, err := marshallTxStatus(p.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := p.Tx.Serialize(&buf); err != nil {
+		return nil, err
+	}
+
+	// The virtual size is the weight of the transaction divided by the
+	// witness scale factor, rounded up.
+	weight := blockchain.GetTransactionWeight(btcutil.NewTx(p.Tx))
+	vsize := (weight + blockchain.WitnessScaleFactor - 1) /
+		blockchain.WitnessScaleFactor
+
+	rpcTx := &PublishedTransaction{
+		Txid:            p.TxHash().String(),
+		RawTx:           buf.Bytes(),
+		Label:           p.Label,
+		Purpose:         marshallTxPurpose(p.Purpose()),
+		Status:          status,
+		Reason:          p.Reason,
+		FeeSat:          int64(p.Fee),
+		SatPerVbyte:     uint64(p.FeeRate.FeePerKVByte() / 1000),
+		Vsize:           vsize,
+		FirstPublished:  p.FirstPublished.Unix(),
+		LastPublished:   p.LastPublished.Unix(),
+		PublishAttempts: p.PublishAttempts,
+		PublishHeight:   p.PublishHeight,
+		ConfirmHeight:   p.ConfirmHeight,
+	}
+
+	if p.ReplacedBy != (chainhash.Hash{}) {
+		rpcTx.ReplacedBy = p.ReplacedBy.String()
+	}
+	if p.ConflictingTx != (chainhash.Hash{}) {
+		rpcTx.ConflictingTxid = p.ConflictingTx.String()
+	}
+	for _, txid := range p.Replaces {
+		rpcTx.Replaces = append(rpcTx.Replaces, txid.String())
+	}
+
+	return rpcTx, nil
+}
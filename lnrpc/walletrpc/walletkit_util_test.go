@@ -1,8 +1,18 @@
 package walletrpc
 
 import (
+	"bytes"
+	"errors"
 	"testing"
+	"time"
 
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/labels"
+	"github.com/lightningnetwork/lnd/lnwallet"
+	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
+	"github.com/lightningnetwork/lnd/lnwallet/txtracker"
 	"github.com/stretchr/testify/require"
 )
 
@@ -72,3 +82,137 @@ func TestParseDerivationPath(t *testing.T) {
 		})
 	}
 }
+
+// TestFundingChangeOutput tests that the wallet controlled change output of a
+// funding transaction is found correctly.
+func TestFundingChangeOutput(t *testing.T) {
+	t.Parallel()
+
+	fundingTx := wire.NewMsgTx(2)
+	fundingTx.AddTxOut(&wire.TxOut{Value: 1_000_000})
+	fundingTx.AddTxOut(&wire.TxOut{Value: 50_000})
+	fundingTx.AddTxOut(&wire.TxOut{Value: 20_000})
+
+	fundingTxid := fundingTx.TxHash()
+	fundingOutpoint := wire.OutPoint{Hash: fundingTxid, Index: 0}
+	changeOutpoint := wire.OutPoint{Hash: fundingTxid, Index: 2}
+
+	// The wallet only controls the last output of the funding
+	// transaction.
+	errLookup := errors.New("unable to look up output")
+	fetchInputInfo := func(op *wire.OutPoint) (*lnwallet.Utxo, error) {
+		switch *op {
+		case changeOutpoint:
+			return &lnwallet.Utxo{OutPoint: *op}, nil
+
+		case fundingOutpoint:
+			return nil, errLookup
+
+		default:
+			return nil, lnwallet.ErrNotMine
+		}
+	}
+
+	testCases := []struct {
+		name        string
+		channel     *channeldb.OpenChannel
+		expectedErr error
+		expectedOp  *wire.OutPoint
+	}{{
+		name: "not initiator",
+		channel: &channeldb.OpenChannel{
+			FundingOutpoint: fundingOutpoint,
+			FundingTxn:      fundingTx,
+		},
+		expectedErr: ErrFundingNotInitiator,
+	}, {
+		name: "psbt funded",
+		channel: &channeldb.OpenChannel{
+			FundingOutpoint: fundingOutpoint,
+			IsInitiator:     true,
+		},
+		expectedErr: ErrFundingTxUnknown,
+	}, {
+		name: "no change",
+		channel: &channeldb.OpenChannel{
+			FundingOutpoint: changeOutpoint,
+			FundingTxn:      fundingTx,
+			IsInitiator:     true,
+		},
+		expectedErr: ErrNoFundingChange,
+	}, {
+		name: "change output",
+		channel: &channeldb.OpenChannel{
+			FundingOutpoint: fundingOutpoint,
+			FundingTxn:      fundingTx,
+			IsInitiator:     true,
+		},
+		expectedOp: &changeOutpoint,
+	}}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			op, err := fundingChangeOutput(
+				tc.channel, fetchInputInfo,
+			)
+			require.ErrorIs(t, err, tc.expectedErr)
+			require.Equal(t, tc.expectedOp, op)
+		})
+	}
+}
+
+// TestMarshallPublishedTx tests that the record of a published transaction is
+// correctly converted into its RPC counterpart.
+func TestMarshallPublishedTx(t *testing.T) {
+	t.Parallel()
+
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Index: 1},
+	})
+	tx.AddTxOut(&wire.TxOut{Value: 1000, PkScript: []byte{0x00}})
+
+	label := labels.MakeLabel(labels.LabelTypeSweepTransaction, nil)
+	replaces := chainhash.Hash{1}
+	replacedBy := chainhash.Hash{2}
+	published := &txtracker.PublishedTx{
+		Tx:              tx,
+		Label:           label,
+		Status:          txtracker.TxStatusReplaced,
+		Reason:          "replaced by higher fee transaction",
+		Fee:             500,
+		FeeRate:         chainfee.SatPerKWeight(2500),
+		FirstPublished:  time.Unix(100, 0),
+		LastPublished:   time.Unix(200, 0),
+		PublishAttempts: 2,
+		PublishHeight:   10,
+		ReplacedBy:      replacedBy,
+		Replaces:        []chainhash.Hash{replaces},
+	}
+
+	rpcTx, err := marshallPublishedTx(published)
+	require.NoError(t, err)
+
+	require.Equal(t, tx.TxHash().String(), rpcTx.Txid)
+	require.Equal(t, TxPurpose_TX_PURPOSE_SWEEP, rpcTx.Purpose)
+	require.Equal(t, PublishedTxStatus_TX_STATUS_REPLACED, rpcTx.Status)
+	require.Equal(t, published.Reason, rpcTx.Reason)
+	require.EqualValues(t, 500, rpcTx.FeeSat)
+	require.EqualValues(t, 10, rpcTx.SatPerVbyte)
+	require.EqualValues(t, tx.SerializeSize(), rpcTx.Vsize)
+	require.EqualValues(t, 100, rpcTx.FirstPublished)
+	require.EqualValues(t, 200, rpcTx.LastPublished)
+	require.EqualValues(t, 2, rpcTx.PublishAttempts)
+	require.EqualValues(t, 10, rpcTx.PublishHeight)
+	require.Equal(t, replacedBy.String(), rpcTx.ReplacedBy)
+	require.Equal(t, []string{replaces.String()}, rpcTx.Replaces)
+	require.Empty(t, rpcTx.ConflictingTxid)
+
+	var buf bytes.Buffer
+	require.NoError(t, tx.Serialize(&buf))
+	require.Equal(t, buf.Bytes(), rpcTx.RawTx)
+}
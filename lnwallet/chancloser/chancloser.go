@@ -807,8 +807,8 @@ func (c *ChanCloser) ProcessCloseMsg(msg lnwire.Message) ([]lnwire.Message,
 
 		// Create a close channel label.
 		chanID := c.cfg.Channel.ShortChanID()
-		closeLabel := labels.MakeLabel(
-			labels.LabelTypeChannelClose, &chanID,
+		closeLabel := labels.MakeChannelLabel(
+			labels.LabelTypeChannelClose, &chanID, c.chanPoint,
 		)
 
 		if err := c.cfg.BroadcastTx(closeTx, closeLabel); err != nil {
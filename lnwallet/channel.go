@@ -65,6 +65,12 @@ var (
 	ErrBelowChanReserve = fmt.Errorf("commitment transaction dips peer " +
 		"below chan reserve")
 
+	// ErrFeeExposureExceeded is returned when a proposed HTLC or fee
+	// update would make the fee exposure of a commitment exceed the
+	// configured limit.
+	ErrFeeExposureExceeded = fmt.Errorf("commitment transaction exceeds " +
+		"max fee exposure")
+
 	// ErrBelowMinHTLC is returned when a proposed HTLC has a value that
 	// is below the minimum HTLC value constraint for either us or our
 	// peer depending on which flags are set.
@@ -117,6 +123,12 @@ var (
 	// in a revocation log entry is missing.
 	ErrRevLogDataMissing = errors.New("revocation log data missing")
 
+	// ErrZeroFeeCommitmentUpdateFee is returned when a fee update is sent
+	// or received for a zero-fee commitment channel, whose commitment
+	// transactions never pay any fees.
+	ErrZeroFeeCommitmentUpdateFee = errors.New("update_fee not allowed " +
+		"for zero-fee commitment channels")
+
 	// ErrForceCloseLocalDataLoss is returned in the case a user (or
 	// another sub-system) attempts to force close when we've detected that
 	// we've likely lost data ourselves.
@@ -353,6 +365,14 @@ type PaymentDescriptor struct {
 	// NOTE: Populated only on add payment descriptor entry types.
 	OnionBlob []byte
 
+	// Endorsement is the experimental endorsement signal the remote peer
+	// attached to the HTLC.
+	//
+	// NOTE: Populated only on add payment descriptor entry types of HTLCs
+	// received from the remote peer, and not restored after a restart
+	// before the HTLC was locked in.
+	Endorsement lnwire.Endorsement
+
 	// ShaOnionBlob is a sha of the onion blob.
 	//
 	// NOTE: Populated only in payment descriptor with MalformedFail type.
@@ -363,6 +383,13 @@ type PaymentDescriptor struct {
 	// NOTE: Populate only in fail payment descriptor entry types.
 	FailReason []byte
 
+	// AttributionData holds the hold times and HMACs of the hops that
+	// relayed the failure, which allow the sender to attribute it.
+	//
+	// NOTE: Populated only in fail payment descriptor entry types, if the
+	// failure carries attribution data.
+	AttributionData lnwire.AttributionData
+
 	// FailCode stores the code why a particular payment was canceled.
 	//
 	// NOTE: Populated only in payment descriptor with MalformedFail type.
@@ -393,6 +420,28 @@ type PaymentDescriptor struct {
 	isForwarded bool
 }
 
+// newFailMsg creates the wire message of a fail payment descriptor, including
+// the attribution data of the failure.
+func newFailMsg(chanID lnwire.ChannelID,
+	pd *PaymentDescriptor) *lnwire.UpdateFailHTLC {
+
+	fail := &lnwire.UpdateFailHTLC{
+		ChanID: chanID,
+		ID:     pd.ParentIndex,
+		Reason: pd.FailReason,
+	}
+
+	// Packing the attribution data can't fail in practice. If it did
+	// nonetheless, the failure is sent without attribution data, which
+	// the sender can handle.
+	if err := fail.SetAttributionData(pd.AttributionData); err != nil {
+		walletLog.Warnf("Unable to set attribution data of fail for "+
+			"htlc %d: %v", pd.ParentIndex, err)
+	}
+
+	return fail
+}
+
 // PayDescsFromRemoteLogUpdates converts a slice of LogUpdates received from the
 // remote peer into PaymentDescriptors to inform a link's forwarding decisions.
 //
@@ -435,6 +484,10 @@ func PayDescsFromRemoteLogUpdates(chanID lnwire.ShortChannelID, height uint64,
 			pd.OnionBlob = make([]byte, len(wireMsg.OnionBlob))
 			copy(pd.OnionBlob[:], wireMsg.OnionBlob[:])
 
+			// A malformed signal is treated as unendorsed, which
+			// is also how an absent signal is interpreted.
+			pd.Endorsement, _ = wireMsg.Endorsement()
+
 		case *lnwire.UpdateFulfillHTLC:
 			pd = PaymentDescriptor{
 				RPreimage:   wireMsg.PaymentPreimage,
@@ -459,6 +512,10 @@ func PayDescsFromRemoteLogUpdates(chanID lnwire.ShortChannelID, height uint64,
 				},
 			}
 
+			// Attribution data is optional, so a failure with
+			// malformed attribution data is relayed without it.
+			pd.AttributionData, _ = wireMsg.AttributionData()
+
 		case *lnwire.UpdateFailMalformedHTLC:
 			pd = PaymentDescriptor{
 				ParentIndex:  wireMsg.ID,
@@ -1470,39 +1527,61 @@ func NewLightningChannel(signer input.Signer,
 	return lc, nil
 }
 
-// createSignDesc derives the SignDescriptor for commitment transactions from
-// other fields on the LightningChannel.
-func (lc *LightningChannel) createSignDesc() error {
-
-	var (
-		fundingPkScript, multiSigScript []byte
-		err                             error
-	)
+// fundingScripts returns the pkScript of the funding output of the given
+// channel. For non-taproot channels, the witness script of the 2-of-2
+// multi-sig is returned as well.
+func fundingScripts(chanState *channeldb.OpenChannel) ([]byte, []byte,
+	error) {
 
-	chanState := lc.channelState
 	localKey := chanState.LocalChanCfg.MultiSigKey.PubKey
 	remoteKey := chanState.RemoteChanCfg.MultiSigKey.PubKey
 
 	if chanState.ChanType.IsTaproot() {
-		fundingPkScript, _, err = input.GenTaprootFundingScript(
-			localKey, remoteKey, int64(lc.channelState.Capacity),
+		fundingPkScript, _, err := input.GenTaprootFundingScript(
+			localKey, remoteKey, int64(chanState.Capacity),
 		)
 		if err != nil {
-			return err
-		}
-	} else {
-		multiSigScript, err = input.GenMultiSigScript(
-			localKey.SerializeCompressed(),
-			remoteKey.SerializeCompressed(),
-		)
-		if err != nil {
-			return err
+			return nil, nil, err
 		}
 
-		fundingPkScript, err = input.WitnessScriptHash(multiSigScript)
-		if err != nil {
-			return err
-		}
+		return fundingPkScript, nil, nil
+	}
+
+	multiSigScript, err := input.GenMultiSigScript(
+		localKey.SerializeCompressed(),
+		remoteKey.SerializeCompressed(),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fundingPkScript, err := input.WitnessScriptHash(multiSigScript)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return fundingPkScript, multiSigScript, nil
+}
+
+// FundingTxOut returns the funding output of the given channel.
+func FundingTxOut(chanState *channeldb.OpenChannel) (*wire.TxOut, error) {
+	fundingPkScript, _, err := fundingScripts(chanState)
+	if err != nil {
+		return nil, err
+	}
+
+	return &wire.TxOut{
+		PkScript: fundingPkScript,
+		Value:    int64(chanState.Capacity),
+	}, nil
+}
+
+// createSignDesc derives the SignDescriptor for commitment transactions from
+// other fields on the LightningChannel.
+func (lc *LightningChannel) createSignDesc() error {
+	fundingPkScript, multiSigScript, err := fundingScripts(lc.channelState)
+	if err != nil {
+		return err
 	}
 
 	lc.fundingOutput = wire.TxOut{
@@ -1618,6 +1697,7 @@ func (lc *LightningChannel) logUpdateToPayDesc(logUpdate *channeldb.LogUpdate,
 			FailReason:               wireMsg.Reason[:],
 			removeCommitHeightRemote: commitHeight,
 		}
+		pd.AttributionData, _ = wireMsg.AttributionData()
 
 	// HTLC fails due to malformed onion blobs are treated the exact same
 	// way as regular HTLC fails.
@@ -1695,7 +1775,7 @@ func (lc *LightningChannel) localLogUpdateToPayDesc(logUpdate *channeldb.LogUpda
 	case *lnwire.UpdateFailHTLC:
 		ogHTLC := remoteUpdateLog.lookupHtlc(wireMsg.ID)
 
-		return &PaymentDescriptor{
+		pd := &PaymentDescriptor{
 			Amount:                   ogHTLC.Amount,
 			RHash:                    ogHTLC.RHash,
 			ParentIndex:              ogHTLC.HtlcIndex,
@@ -1703,7 +1783,10 @@ func (lc *LightningChannel) localLogUpdateToPayDesc(logUpdate *channeldb.LogUpda
 			EntryType:                Fail,
 			FailReason:               wireMsg.Reason[:],
 			removeCommitHeightRemote: commitHeight,
-		}, nil
+		}
+		pd.AttributionData, _ = wireMsg.AttributionData()
+
+		return pd, nil
 
 	// HTLC fails due to malformed onion blocks are treated the exact same
 	// way as regular HTLC fails.
@@ -1792,7 +1875,7 @@ func (lc *LightningChannel) remoteLogUpdateToPayDesc(logUpdate *channeldb.LogUpd
 	case *lnwire.UpdateFailHTLC:
 		ogHTLC := localUpdateLog.lookupHtlc(wireMsg.ID)
 
-		return &PaymentDescriptor{
+		pd := &PaymentDescriptor{
 			Amount:                  ogHTLC.Amount,
 			RHash:                   ogHTLC.RHash,
 			ParentIndex:             ogHTLC.HtlcIndex,
@@ -1800,7 +1883,10 @@ func (lc *LightningChannel) remoteLogUpdateToPayDesc(logUpdate *channeldb.LogUpd
 			EntryType:               Fail,
 			FailReason:              wireMsg.Reason[:],
 			removeCommitHeightLocal: commitHeight,
-		}, nil
+		}
+		pd.AttributionData, _ = wireMsg.AttributionData()
+
+		return pd, nil
 
 	// HTLC fails due to malformed onion blobs are treated the exact same
 	// way as regular HTLC fails.
@@ -2417,6 +2503,14 @@ type BreachRetribution struct {
 func NewBreachRetribution(chanState *channeldb.OpenChannel, stateNum uint64,
 	breachHeight uint32, spendTx *wire.MsgTx) (*BreachRetribution, error) {
 
+	// The channel parameters may have been changed through a dynamic
+	// commitment negotiation after the breached state was created, so we
+	// use the ones that were in effect for it.
+	chanState, err := chanState.ParamsAtHeight(false, stateNum)
+	if err != nil {
+		return nil, err
+	}
+
 	// Query the on-disk revocation log for the snapshot which was recorded
 	// at this particular state num. Based on whether a legacy revocation
 	// log is returned or not, we will process them differently.
@@ -3020,7 +3114,8 @@ func (lc *LightningChannel) fetchCommitmentView(remoteChain bool,
 	}
 
 	// We'll assert that there hasn't been a mistake during fee calculation
-	// leading to a fee too low.
+	// leading to a fee too low. Zero-fee commitments intentionally don't
+	// pay any fee, so the check doesn't apply to them.
 	var totalOut btcutil.Amount
 	for _, txOut := range commitTx.txn.TxOut {
 		totalOut += btcutil.Amount(txOut.Value)
@@ -3041,7 +3136,9 @@ func (lc *LightningChannel) fetchCommitmentView(remoteChain bool,
 
 	effFeeRate := chainfee.SatPerKWeight(fee) * 1000 /
 		chainfee.SatPerKWeight(weight)
-	if effFeeRate < chainfee.AbsoluteFeePerKwFloor {
+	if effFeeRate < chainfee.AbsoluteFeePerKwFloor &&
+		!lc.channelState.ChanType.HasZeroFeeCommitment() {
+
 		return nil, fmt.Errorf("height=%v, for ChannelPoint(%v) "+
 			"attempts to create commitment with feerate %v: %v",
 			nextHeight, lc.channelState.FundingOutpoint,
@@ -3647,11 +3744,7 @@ func (lc *LightningChannel) createCommitDiff(
 			}
 
 		case Fail:
-			logUpdate.UpdateMsg = &lnwire.UpdateFailHTLC{
-				ChanID: chanID,
-				ID:     pd.ParentIndex,
-				Reason: pd.FailReason,
-			}
+			logUpdate.UpdateMsg = newFailMsg(chanID, pd)
 
 		case MalformedFail:
 			logUpdate.UpdateMsg = &lnwire.UpdateFailMalformedHTLC{
@@ -3770,11 +3863,7 @@ func (lc *LightningChannel) getUnsignedAckedUpdates() []channeldb.LogUpdate {
 			}
 
 		case Fail:
-			logUpdate.UpdateMsg = &lnwire.UpdateFailHTLC{
-				ChanID: chanID,
-				ID:     pd.ParentIndex,
-				Reason: pd.FailReason,
-			}
+			logUpdate.UpdateMsg = newFailMsg(chanID, pd)
 
 		case MalformedFail:
 			logUpdate.UpdateMsg = &lnwire.UpdateFailMalformedHTLC{
@@ -3862,8 +3951,11 @@ func (lc *LightningChannel) validateCommitmentSanity(theirLogCounter,
 	}
 
 	// Ensure that the fee being applied is enough to be relayed across the
-	// network in a reasonable time frame.
-	if feePerKw < chainfee.FeePerKwFloor {
+	// network in a reasonable time frame. Zero-fee commitments are relayed
+	// as a package with a child paying for them instead.
+	if feePerKw < chainfee.FeePerKwFloor &&
+		!lc.channelState.ChanType.HasZeroFeeCommitment() {
+
 		return fmt.Errorf("commitment fee per kw %v below fee floor %v",
 			feePerKw, chainfee.FeePerKwFloor)
 	}
@@ -4576,6 +4668,15 @@ func (lc *LightningChannel) computeView(view *htlcView, remoteChain bool,
 	}
 	nextHeight := commitChain.tip().height + 1
 
+	// If the next commitment is the first one with anchor outputs after a
+	// dynamic commitment negotiation, the initiator pays for them.
+	anchorCost := lc.dynCommitAnchorCost(remoteChain, nextHeight)
+	if lc.channelState.IsInitiator {
+		ourBalance -= anchorCost
+	} else {
+		theirBalance -= anchorCost
+	}
+
 	// Initiate feePerKw to the last committed fee for this chain as we'll
 	// need this to determine which HTLCs are dust, and also the final fee
 	// rate.
@@ -5229,6 +5330,18 @@ func (lc *LightningChannel) IsChannelClean() bool {
 	return true
 }
 
+// CommitmentsSynced returns true if both commitments sign the same updates and
+// neither party has a commitment that wasn't revoked yet. Unlike
+// IsChannelClean, the commitments may still carry HTLCs.
+func (lc *LightningChannel) CommitmentsSynced() bool {
+	lc.RLock()
+	defer lc.RUnlock()
+
+	return !lc.localCommitChain.hasUnackedCommitment() &&
+		!lc.remoteCommitChain.hasUnackedCommitment() &&
+		!lc.oweCommitment(true) && !lc.oweCommitment(false)
+}
+
 // OweCommitment returns a boolean value reflecting whether we need to send
 // out a commitment signature because there are outstanding local updates and/or
 // updates in the local commit tx that aren't reflected in the remote commit tx
@@ -5282,13 +5395,19 @@ func (lc *LightningChannel) oweCommitment(local bool) bool {
 			lastLocalCommit.ourMessageIndex
 	}
 
+	// A commitment is also owed if it activates new channel parameters
+	// that were negotiated through a dynamic commitment negotiation.
+	dynCommitPending := lc.dynCommitOwed(local)
+
 	// If any of the conditions above is true, we owe a commitment
 	// signature.
-	oweCommitment := localUpdatesPending || remoteUpdatesPending
+	oweCommitment := localUpdatesPending || remoteUpdatesPending ||
+		dynCommitPending
 
 	lc.log.Tracef("%v owes commit: %v (local updates: %v, "+
-		"remote updates %v)", perspective, oweCommitment,
-		localUpdatesPending, remoteUpdatesPending)
+		"remote updates %v, dyn commitment %v)", perspective,
+		oweCommitment, localUpdatesPending, remoteUpdatesPending,
+		dynCommitPending)
 
 	return oweCommitment
 }
@@ -5527,6 +5646,10 @@ func (lc *LightningChannel) ReceiveRevocation(revMsg *lnwire.RevokeAndAck) (
 				PaymentHash: pd.RHash,
 			}
 			copy(htlc.OnionBlob[:], pd.OnionBlob)
+			err := htlc.SetEndorsement(pd.Endorsement)
+			if err != nil {
+				return nil, nil, nil, nil, err
+			}
 			logUpdate.UpdateMsg = htlc
 			addUpdates = append(addUpdates, logUpdate)
 
@@ -5539,11 +5662,7 @@ func (lc *LightningChannel) ReceiveRevocation(revMsg *lnwire.RevokeAndAck) (
 			settleFailUpdates = append(settleFailUpdates, logUpdate)
 
 		case Fail:
-			logUpdate.UpdateMsg = &lnwire.UpdateFailHTLC{
-				ChanID: chanID,
-				ID:     pd.ParentIndex,
-				Reason: pd.FailReason,
-			}
+			logUpdate.UpdateMsg = newFailMsg(chanID, pd)
 			settleFailUpdates = append(settleFailUpdates, logUpdate)
 
 		case MalformedFail:
@@ -5726,6 +5845,62 @@ func (lc *LightningChannel) AddHTLC(htlc *lnwire.UpdateAddHTLC,
 	return pd.HtlcIndex, nil
 }
 
+// FeeExposure is the part of a commitment that would go to miners if the
+// commitment were broadcast, as it isn't paid out to either party.
+type FeeExposure struct {
+	// DustSum is the sum of the HTLCs that are trimmed from the
+	// commitment, because their outputs would be dust.
+	DustSum lnwire.MilliSatoshi
+
+	// CommitFee is the fee of the commitment transaction.
+	CommitFee lnwire.MilliSatoshi
+
+	// Initiator indicates whether we are the initiator of the channel and
+	// therefore pay the commitment fee.
+	Initiator bool
+}
+
+// Total returns the amount we would lose to fees if the commitment were
+// broadcast. The commitment fee is only included if we pay it.
+func (f FeeExposure) Total() lnwire.MilliSatoshi {
+	if !f.Initiator {
+		return f.DustSum
+	}
+
+	return f.DustSum + f.CommitFee
+}
+
+// FeeExposureLimit is the maximum fee exposure allowed on a commitment.
+type FeeExposureLimit struct {
+	// Max is the maximum fee exposure.
+	Max lnwire.MilliSatoshi
+
+	// CommitFeeMultiplier raises the maximum fee exposure to the given
+	// multiple of the commitment fee, so that the limit grows with
+	// on-chain fees. Zero disables the multiplier.
+	CommitFeeMultiplier float64
+}
+
+// Exceeded returns whether the given fee exposure exceeds the limit. A zero
+// limit is never exceeded.
+func (l FeeExposureLimit) Exceeded(f FeeExposure) bool {
+	limit := l.Max
+	if l.CommitFeeMultiplier > 0 {
+		relative := lnwire.MilliSatoshi(
+			l.CommitFeeMultiplier * float64(f.CommitFee),
+		)
+		if relative > limit {
+			limit = relative
+		}
+	}
+
+	if limit == 0 {
+		return false
+	}
+
+	return f.Total() > limit
+}
+
 // GetDustSum takes in a boolean that determines which commitment to evaluate
 // the dust sum on. The return value is the sum of dust on the desired
 // commitment tx.
@@ -5735,7 +5910,30 @@ func (lc *LightningChannel) GetDustSum(remote bool) lnwire.MilliSatoshi {
 	lc.RLock()
 	defer lc.RUnlock()
 
-	var dustSum lnwire.MilliSatoshi
+	return lc.feeExposure(remote, nil).DustSum
+}
+
+// GetFeeExposure returns the fee exposure of either the local or the remote
+// commitment, taking into account all HTLCs of the update logs. If a fee rate
+// is passed, the exposure is evaluated at that fee rate instead of the one of
+// the commitment, which allows checking a fee update before applying it.
+//
+// NOTE: This over-estimates the fee exposure.
+func (lc *LightningChannel) GetFeeExposure(remote bool,
+	feeRate *chainfee.SatPerKWeight) FeeExposure {
+
+	lc.RLock()
+	defer lc.RUnlock()
+
+	return lc.feeExposure(remote, feeRate)
+}
+
+// feeExposure computes the fee exposure of either the local or the remote
+// commitment.
+//
+// NOTE: The caller must hold the channel state lock.
+func (lc *LightningChannel) feeExposure(remote bool,
+	dryRunFee *chainfee.SatPerKWeight) FeeExposure {
 
 	dustLimit := lc.channelState.LocalChanCfg.DustLimit
 	commit := lc.channelState.LocalCommitment
@@ -5747,46 +5945,135 @@ func (lc *LightningChannel) GetDustSum(remote bool) lnwire.MilliSatoshi {
 
 	chanType := lc.channelState.ChanType
 	feeRate := chainfee.SatPerKWeight(commit.FeePerKw)
+	if dryRunFee != nil {
+		feeRate = *dryRunFee
+	}
 
-	// Grab all of our HTLCs and evaluate against the dust limit.
-	for e := lc.localUpdateLog.Front(); e != nil; e = e.Next() {
-		pd := e.Value.(*PaymentDescriptor)
-		if pd.EntryType != Add {
-			continue
+	exposure := FeeExposure{
+		Initiator: lc.channelState.IsInitiator,
+	}
+
+	// Sum up the HTLCs of both logs that are dust, and count the others
+	// as they add to the weight of the commitment.
+	var numHtlcs int64
+	addHtlcs := func(log *updateLog, incoming bool) {
+		for e := log.Front(); e != nil; e = e.Next() {
+			pd := e.Value.(*PaymentDescriptor)
+			if pd.EntryType != Add {
+				continue
+			}
+
+			amt := pd.Amount.ToSatoshis()
+
+			// If the satoshi amount is under the dust limit, add
+			// the msat amount to the dust sum.
+			if HtlcIsDust(
+				chanType, incoming, !remote, feeRate, amt,
+				dustLimit,
+			) {
+
+				exposure.DustSum += pd.Amount
+				continue
+			}
+
+			numHtlcs++
 		}
+	}
 
-		amt := pd.Amount.ToSatoshis()
+	// Grab all of our HTLCs and evaluate against the dust limit, then all
+	// of theirs.
+	addHtlcs(lc.localUpdateLog, false)
+	addHtlcs(lc.remoteUpdateLog, true)
 
-		// If the satoshi amount is under the dust limit, add the msat
-		// amount to the dust sum.
-		if HtlcIsDust(
-			chanType, false, !remote, feeRate, amt, dustLimit,
+	weight := CommitWeight(chanType) + numHtlcs*input.HTLCWeight
+	exposure.CommitFee = lnwire.NewMSatFromSatoshis(
+		feeRate.FeeForWeight(weight),
+	)
+
+	return exposure
+}
+
+// CheckHtlcFeeExposure returns ErrFeeExposureExceeded if an HTLC of the given
+// amount is dust on one of the commitments and would make the fee exposure of
+// that commitment exceed the limit. Incoming HTLCs must already be part of the
+// update logs, while the amount of outgoing ones is added to the exposure.
+func (lc *LightningChannel) CheckHtlcFeeExposure(limit FeeExposureLimit,
+	amt lnwire.MilliSatoshi, incoming bool) error {
+
+	lc.RLock()
+	defer lc.RUnlock()
+
+	chanType := lc.channelState.ChanType
+	for _, remote := range []bool{false, true} {
+		dustLimit := lc.channelState.LocalChanCfg.DustLimit
+		commit := lc.channelState.LocalCommitment
+		if remote {
+			dustLimit = lc.channelState.RemoteChanCfg.DustLimit
+			commit = lc.channelState.RemoteCommitment
+		}
+
+		feeRate := chainfee.SatPerKWeight(commit.FeePerKw)
+		if !HtlcIsDust(
+			chanType, incoming, !remote, feeRate,
+			amt.ToSatoshis(), dustLimit,
 		) {
 
-			dustSum += pd.Amount
+			continue
+		}
+
+		exposure := lc.feeExposure(remote, nil)
+		if !incoming {
+			exposure.DustSum += amt
+		}
+
+		if limit.Exceeded(exposure) {
+			return ErrFeeExposureExceeded
 		}
 	}
 
-	// Grab all of their HTLCs and evaluate against the dust limit.
-	for e := lc.remoteUpdateLog.Front(); e != nil; e = e.Next() {
-		pd := e.Value.(*PaymentDescriptor)
-		if pd.EntryType != Add {
-			continue
+	return nil
+}
+
+// CheckFeeExposure returns ErrFeeExposureExceeded if the fee exposure of one of
+// the commitments would exceed the limit at the given fee rate.
+func (lc *LightningChannel) CheckFeeExposure(limit FeeExposureLimit,
+	feeRate chainfee.SatPerKWeight) error {
+
+	lc.RLock()
+	defer lc.RUnlock()
+
+	for _, remote := range []bool{false, true} {
+		if limit.Exceeded(lc.feeExposure(remote, &feeRate)) {
+			return ErrFeeExposureExceeded
 		}
+	}
 
-		amt := pd.Amount.ToSatoshis()
+	return nil
+}
 
-		// If the satoshi amount is under the dust limit, add the msat
-		// amount to the dust sum.
-		if HtlcIsDust(
-			chanType, true, !remote, feeRate, amt, dustLimit,
-		) {
+// CommittedFeeExposure returns the fee exposure of the latest signed local or
+// remote commitment of a channel.
+func CommittedFeeExposure(chanState *channeldb.OpenChannel,
+	remote bool) FeeExposure {
+
+	commit := chanState.LocalCommitment
+	if remote {
+		commit = chanState.RemoteCommitment
+	}
+
+	exposure := FeeExposure{
+		CommitFee: lnwire.NewMSatFromSatoshis(commit.CommitFee),
+		Initiator: chanState.IsInitiator,
+	}
 
-			dustSum += pd.Amount
+	// Dust HTLCs don't have an output on the commitment.
+	for _, htlc := range commit.Htlcs {
+		if htlc.OutputIndex < 0 {
+			exposure.DustSum += htlc.Amt
 		}
 	}
 
-	return dustSum
+	return exposure
 }
 
 // MayAddOutgoingHtlc validates whether we can add an outgoing htlc to this
@@ -5906,6 +6193,12 @@ func (lc *LightningChannel) ReceiveHTLC(htlc *lnwire.UpdateAddHTLC) (uint64, err
 		OnionBlob: htlc.OnionBlob[:],
 	}
 
+	// The endorsement signal is experimental, so a malformed signal
+	// doesn't fail the HTLC but is treated as unendorsed.
+	if endorsement, err := htlc.Endorsement(); err == nil {
+		pd.Endorsement = endorsement
+	}
+
 	localACKedIndex := lc.remoteCommitChain.tail().ourMessageIndex
 
 	// Clamp down on the number of HTLC's we can receive by checking the
@@ -6040,6 +6333,9 @@ func (lc *LightningChannel) ReceiveHTLCSettle(preimage [32]byte, htlcIndex uint6
 //
 // The additional arguments correspond to:
 //
+//   - attrData: the attribution data of the failure, which may be nil if the
+//     failure doesn't carry any.
+//
 //   - sourceRef: specifies the location of the Add HTLC within a forwarding
 //     package that this HTLC is failing. Every Fail fails exactly one Add, so
 //     this should never be empty in practice.
@@ -6058,8 +6354,8 @@ func (lc *LightningChannel) ReceiveHTLCSettle(preimage [32]byte, htlcIndex uint6
 // NOTE: It is okay for sourceRef, destRef, and closeKey to be nil when unit
 // testing the wallet.
 func (lc *LightningChannel) FailHTLC(htlcIndex uint64, reason []byte,
-	sourceRef *channeldb.AddRef, destRef *channeldb.SettleFailRef,
-	closeKey *models.CircuitKey) error {
+	attrData lnwire.AttributionData, sourceRef *channeldb.AddRef,
+	destRef *channeldb.SettleFailRef, closeKey *models.CircuitKey) error {
 
 	lc.Lock()
 	defer lc.Unlock()
@@ -6082,6 +6378,7 @@ func (lc *LightningChannel) FailHTLC(htlcIndex uint64, reason []byte,
 		LogIndex:         lc.localUpdateLog.logIndex,
 		EntryType:        Fail,
 		FailReason:       reason,
+		AttributionData:  attrData,
 		SourceRef:        sourceRef,
 		DestRef:          destRef,
 		ClosedCircuitKey: closeKey,
@@ -6151,7 +6448,7 @@ func (lc *LightningChannel) MalformedFailHTLC(htlcIndex uint64,
 // commitment update. This method should be called in response to the upstream
 // party cancelling an outgoing HTLC.
 func (lc *LightningChannel) ReceiveFailHTLC(htlcIndex uint64, reason []byte,
-) error {
+	attrData lnwire.AttributionData) error {
 
 	lc.Lock()
 	defer lc.Unlock()
@@ -6168,12 +6465,13 @@ func (lc *LightningChannel) ReceiveFailHTLC(htlcIndex uint64, reason []byte,
 	}
 
 	pd := &PaymentDescriptor{
-		Amount:      htlc.Amount,
-		RHash:       htlc.RHash,
-		ParentIndex: htlc.HtlcIndex,
-		LogIndex:    lc.remoteUpdateLog.logIndex,
-		EntryType:   Fail,
-		FailReason:  reason,
+		Amount:          htlc.Amount,
+		RHash:           htlc.RHash,
+		ParentIndex:     htlc.HtlcIndex,
+		LogIndex:        lc.remoteUpdateLog.logIndex,
+		EntryType:       Fail,
+		FailReason:      reason,
+		AttributionData: attrData,
 	}
 
 	lc.remoteUpdateLog.appendUpdate(pd)
@@ -6407,6 +6705,19 @@ func NewUnilateralCloseSummary(chanState *channeldb.OpenChannel, signer input.Si
 	remoteCommit channeldb.ChannelCommitment,
 	commitPoint *btcec.PublicKey) (*UnilateralCloseSummary, error) {
 
+	// Use the channel parameters that were in effect when the remote
+	// commitment was created. If we don't know the commitment, we assume
+	// it is the latest one.
+	if remoteCommit.CommitTx != nil {
+		var err error
+		chanState, err = chanState.ParamsAtHeight(
+			false, remoteCommit.CommitHeight,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// First, we'll generate the commitment point and the revocation point
 	// so we can re-construct the HTLC state and also our payment key.
 	isOurCommit := false
@@ -7271,6 +7582,12 @@ type AnchorResolution struct {
 
 	// CommitWeight is the weight of the commit tx.
 	CommitWeight int64
+
+	// CommitTx is the commitment transaction the anchor is part of. It is
+	// only set for the local commitment of zero-fee commitment channels,
+	// where the anchor spend must be relayed together with its parent as
+	// a package.
+	CommitTx *wire.MsgTx
 }
 
 // LocalForceCloseSummary describes the final commitment state before the
@@ -7362,6 +7679,13 @@ func NewLocalForceCloseSummary(chanState *channeldb.OpenChannel,
 	signer input.Signer, commitTx *wire.MsgTx, stateNum uint64) (
 	*LocalForceCloseSummary, error) {
 
+	// Use the channel parameters that were in effect when our commitment
+	// was created.
+	chanState, err := chanState.ParamsAtHeight(true, stateNum)
+	if err != nil {
+		return nil, err
+	}
+
 	// Re-derive the original pkScript for to-self output within the
 	// commitment transaction. We'll need this to find the corresponding
 	// output in the commitment transaction and potentially for creating
@@ -7789,6 +8113,15 @@ func (lc *LightningChannel) NewAnchorResolutions() (*AnchorResolutions,
 	if err != nil {
 		return nil, err
 	}
+
+	// The ephemeral anchor of a zero-fee commitment can only be relayed
+	// together with its fully signed parent.
+	if localRes != nil && localRes.CommitTx != nil {
+		localRes.CommitTx, err = lc.getSignedCommitTx()
+		if err != nil {
+			return nil, err
+		}
+	}
 	resolutions.Local = localRes
 
 	// Add anchor for remote commitment tx, if any.
@@ -7832,6 +8165,62 @@ func (lc *LightningChannel) NewAnchorResolutions() (*AnchorResolutions,
 	return &resolutions, nil
 }
 
+// newEphemeralAnchorResolution returns the resolution for the pay-to-anchor
+// output of a zero-fee commitment transaction, or nil if the output can't be
+// found.
+func newEphemeralAnchorResolution(chanState *channeldb.OpenChannel,
+	commitTx *wire.MsgTx, isLocalCommit bool) *AnchorResolution {
+
+	found, index := input.FindScriptOutputIndex(
+		commitTx, input.PayToAnchorScript(),
+	)
+	if !found {
+		return nil
+	}
+
+	anchorOut := commitTx.TxOut[index]
+	signDesc := input.SignDescriptor{
+		Output: &wire.TxOut{
+			PkScript: anchorOut.PkScript,
+			Value:    anchorOut.Value,
+		},
+		HashType: txscript.SigHashAll,
+	}
+
+	// The commitment doesn't pay any fee itself, so the whole package fee
+	// has to be paid by the anchor spend. We still report the weight of
+	// the commitment, including the witness spending the funding output,
+	// so the sweeper can account for it.
+	utx := btcutil.NewTx(commitTx)
+	weight := blockchain.GetTransactionWeight(utx)
+	if len(commitTx.TxIn) > 0 && len(commitTx.TxIn[0].Witness) == 0 {
+		weight += input.WitnessCommitmentTxWeight
+	}
+
+	fee := chanState.Capacity
+	for _, out := range commitTx.TxOut {
+		fee -= btcutil.Amount(out.Value)
+	}
+
+	res := &AnchorResolution{
+		CommitAnchor: wire.OutPoint{
+			Hash:  commitTx.TxHash(),
+			Index: index,
+		},
+		AnchorSignDescriptor: signDesc,
+		CommitWeight:         weight,
+		CommitFee:            fee,
+	}
+
+	// Only our own commitment can be relayed by us, so we only attach the
+	// parent transaction in that case.
+	if isLocalCommit {
+		res.CommitTx = commitTx
+	}
+
+	return res
+}
+
 // NewAnchorResolution returns the information that is required to sweep the
 // local anchor.
 func NewAnchorResolution(chanState *channeldb.OpenChannel,
@@ -7843,6 +8232,14 @@ func NewAnchorResolution(chanState *channeldb.OpenChannel,
 		return nil, nil
 	}
 
+	// Zero-fee commitments have a single, keyless ephemeral anchor that
+	// either party can spend.
+	if chanState.ChanType.HasZeroFeeCommitment() {
+		return newEphemeralAnchorResolution(
+			chanState, commitTx, isLocalCommit,
+		), nil
+	}
+
 	// Derive our local anchor script. For taproot channels, rather than
 	// use the same multi-sig key for both commitments, the anchor script
 	// will differ depending on if this is our local or remote
@@ -8185,6 +8582,11 @@ func (lc *LightningChannel) UpdateFee(feePerKw chainfee.SatPerKWeight) error {
 		return fmt.Errorf("local fee update as non-initiator")
 	}
 
+	// Zero-fee commitments don't carry a fee rate at all.
+	if lc.channelState.ChanType.HasZeroFeeCommitment() {
+		return ErrZeroFeeCommitmentUpdateFee
+	}
+
 	// Ensure that the passed fee rate meets our current requirements.
 	if err := lc.validateFeeRate(feePerKw); err != nil {
 		return err
@@ -8213,6 +8615,11 @@ func (lc *LightningChannel) ReceiveUpdateFee(feePerKw chainfee.SatPerKWeight) er
 		return fmt.Errorf("received fee update as initiator")
 	}
 
+	// Zero-fee commitments don't carry a fee rate at all.
+	if lc.channelState.ChanType.HasZeroFeeCommitment() {
+		return ErrZeroFeeCommitmentUpdateFee
+	}
+
 	// TODO(roasbeef): or just modify to use the other balance?
 	pd := &PaymentDescriptor{
 		LogIndex:  lc.remoteUpdateLog.logIndex,
@@ -8370,7 +8777,10 @@ func (lc *LightningChannel) LocalBalanceDust() bool {
 	// If this is an anchor channel, and we're the initiator, then we'll
 	// regain the stats allocated to the anchor outputs with the co-op
 	// close transaction.
-	if chanState.ChanType.HasAnchors() && chanState.IsInitiator {
+	if chanState.ChanType.HasAnchors() &&
+		!chanState.ChanType.HasZeroFeeCommitment() &&
+		chanState.IsInitiator {
+
 		localBalance += 2 * anchorSize
 	}
 
@@ -8390,7 +8800,10 @@ func (lc *LightningChannel) RemoteBalanceDust() bool {
 	// If this is an anchor channel, and they're the initiator, then we'll
 	// regain the stats allocated to the anchor outputs with the co-op
 	// close transaction.
-	if chanState.ChanType.HasAnchors() && !chanState.IsInitiator {
+	if chanState.ChanType.HasAnchors() &&
+		!chanState.ChanType.HasZeroFeeCommitment() &&
+		!chanState.IsInitiator {
+
 		remoteBalance += 2 * anchorSize
 	}
 
@@ -8677,11 +9090,7 @@ func (lc *LightningChannel) unsignedLocalUpdates(remoteMessageIndex,
 					PaymentPreimage: pd.RPreimage,
 				}
 			case Fail:
-				logUpdate.UpdateMsg = &lnwire.UpdateFailHTLC{
-					ChanID: chanID,
-					ID:     pd.ParentIndex,
-					Reason: pd.FailReason,
-				}
+				logUpdate.UpdateMsg = newFailMsg(chanID, pd)
 			case MalformedFail:
 				logUpdate.UpdateMsg = &lnwire.UpdateFailMalformedHTLC{
 					ChanID:       chanID,
@@ -391,6 +391,62 @@ func TestSimpleAddSettleWorkflow(t *testing.T) {
 	})
 }
 
+// TestEndorsementForwarded tests that the endorsement signal of an HTLC
+// received from the remote peer is handed to the forwarding logic once the
+// HTLC is locked in, both directly and through the forwarding package.
+func TestEndorsementForwarded(t *testing.T) {
+	t.Parallel()
+
+	aliceChannel, bobChannel, err := CreateTestChannels(
+		t, channeldb.SingleFunderTweaklessBit,
+	)
+	require.NoError(t, err)
+
+	htlc, _ := createHTLC(0, lnwire.MilliSatoshi(500000))
+	require.NoError(t, htlc.SetEndorsement(lnwire.ExperimentalEndorsed))
+
+	_, err = aliceChannel.AddHTLC(htlc, nil)
+	require.NoError(t, err)
+	_, err = bobChannel.ReceiveHTLC(htlc)
+	require.NoError(t, err)
+
+	aliceNewCommit, err := aliceChannel.SignNextCommitment()
+	require.NoError(t, err)
+	err = bobChannel.ReceiveNewCommitment(aliceNewCommit.CommitSigs)
+	require.NoError(t, err)
+
+	bobRevocation, _, _, err := bobChannel.RevokeCurrentCommitment()
+	require.NoError(t, err)
+	bobNewCommit, err := bobChannel.SignNextCommitment()
+	require.NoError(t, err)
+
+	_, _, _, _, err = aliceChannel.ReceiveRevocation(bobRevocation)
+	require.NoError(t, err)
+	err = aliceChannel.ReceiveNewCommitment(bobNewCommit.CommitSigs)
+	require.NoError(t, err)
+
+	aliceRevocation, _, _, err := aliceChannel.RevokeCurrentCommitment()
+	require.NoError(t, err)
+
+	fwdPkg, adds, _, _, err := bobChannel.ReceiveRevocation(
+		aliceRevocation,
+	)
+	require.NoError(t, err)
+	require.Len(t, adds, 1)
+	require.Equal(t, lnwire.ExperimentalEndorsed, adds[0].Endorsement)
+
+	// The signal must also survive a restart, where the HTLCs to forward
+	// are restored from the forwarding package.
+	restored, err := PayDescsFromRemoteLogUpdates(
+		fwdPkg.Source, fwdPkg.Height, fwdPkg.Adds,
+	)
+	require.NoError(t, err)
+	require.Len(t, restored, 1)
+	require.Equal(
+		t, lnwire.ExperimentalEndorsed, restored[0].Endorsement,
+	)
+}
+
 // TestChannelZeroAddLocalHeight tests that we properly set the addCommitHeightLocal
 // field during state log restoration.
 //
@@ -441,9 +497,9 @@ func TestChannelZeroAddLocalHeight(t *testing.T) {
 
 	// Now Bob should fail the htlc back to Alice.
 	// <----fail-----
-	err = bobChannel.FailHTLC(0, []byte("failreason"), nil, nil, nil)
+	err = bobChannel.FailHTLC(0, []byte("failreason"), nil, nil, nil, nil)
 	require.NoError(t, err)
-	err = aliceChannel.ReceiveFailHTLC(0, []byte("bad"))
+	err = aliceChannel.ReceiveFailHTLC(0, []byte("bad"), nil)
 	require.NoError(t, err)
 
 	// Bob should send a commitment signature to Alice.
@@ -2057,9 +2113,11 @@ func TestCancelHTLC(t *testing.T) {
 
 	// Now, with the HTLC committed on both sides, trigger a cancellation
 	// from Bob to Alice, removing the HTLC.
-	err = bobChannel.FailHTLC(bobHtlcIndex, []byte("failreason"), nil, nil, nil)
+	err = bobChannel.FailHTLC(
+		bobHtlcIndex, []byte("failreason"), nil, nil, nil, nil,
+	)
 	require.NoError(t, err, "unable to cancel HTLC")
-	err = aliceChannel.ReceiveFailHTLC(aliceHtlcIndex, []byte("bad"))
+	err = aliceChannel.ReceiveFailHTLC(aliceHtlcIndex, []byte("bad"), nil)
 	require.NoError(t, err, "unable to recv htlc cancel")
 
 	// Now trigger another state transition, the HTLC should now be removed
@@ -4891,9 +4949,9 @@ func TestChanAvailableBandwidth(t *testing.T) {
 	}
 
 	htlcIndex := uint64((numHtlcs * 2) - 1)
-	err = bobChannel.FailHTLC(htlcIndex, []byte("f"), nil, nil, nil)
+	err = bobChannel.FailHTLC(htlcIndex, []byte("f"), nil, nil, nil, nil)
 	require.NoError(t, err, "unable to cancel HTLC")
-	err = aliceChannel.ReceiveFailHTLC(htlcIndex, []byte("bad"))
+	err = aliceChannel.ReceiveFailHTLC(htlcIndex, []byte("bad"), nil)
 	require.NoError(t, err, "unable to recv htlc cancel")
 
 	// We must do a state transition before the balance is available
@@ -5344,9 +5402,11 @@ func TestLockedInHtlcForwardingSkipAfterRestart(t *testing.T) {
 
 	// With both nodes restarted, Bob will now attempt to cancel one of
 	// Alice's HTLC's.
-	err = bobChannel.FailHTLC(htlc.ID, []byte("failreason"), nil, nil, nil)
+	err = bobChannel.FailHTLC(
+		htlc.ID, []byte("failreason"), nil, nil, nil, nil,
+	)
 	require.NoError(t, err, "unable to cancel HTLC")
-	err = aliceChannel.ReceiveFailHTLC(htlc.ID, []byte("bad"))
+	err = aliceChannel.ReceiveFailHTLC(htlc.ID, []byte("bad"), nil)
 	require.NoError(t, err, "unable to recv htlc cancel")
 
 	// We'll now initiate another state transition, but this time Bob will
@@ -5397,9 +5457,11 @@ func TestLockedInHtlcForwardingSkipAfterRestart(t *testing.T) {
 
 	// Failing the HTLC here will cause the update to be included in Alice's
 	// remote log, but it should not be committed by this transition.
-	err = bobChannel.FailHTLC(htlc2.ID, []byte("failreason"), nil, nil, nil)
+	err = bobChannel.FailHTLC(
+		htlc2.ID, []byte("failreason"), nil, nil, nil, nil,
+	)
 	require.NoError(t, err, "unable to cancel HTLC")
-	err = aliceChannel.ReceiveFailHTLC(htlc2.ID, []byte("bad"))
+	err = aliceChannel.ReceiveFailHTLC(htlc2.ID, []byte("bad"), nil)
 	require.NoError(t, err, "unable to recv htlc cancel")
 
 	bobRevocation, _, finalHtlcs, err := bobChannel.
@@ -5443,9 +5505,11 @@ func TestLockedInHtlcForwardingSkipAfterRestart(t *testing.T) {
 
 	// Re-add the Fail to both Alice and Bob's channels, as the non-committed
 	// update will not have survived the restart.
-	err = bobChannel.FailHTLC(htlc2.ID, []byte("failreason"), nil, nil, nil)
+	err = bobChannel.FailHTLC(
+		htlc2.ID, []byte("failreason"), nil, nil, nil, nil,
+	)
 	require.NoError(t, err, "unable to cancel HTLC")
-	err = aliceChannel.ReceiveFailHTLC(htlc2.ID, []byte("bad"))
+	err = aliceChannel.ReceiveFailHTLC(htlc2.ID, []byte("bad"), nil)
 	require.NoError(t, err, "unable to recv htlc cancel")
 
 	// Have Alice initiate a state transition, which does not include the
@@ -5895,9 +5959,13 @@ func TestDesyncHTLCs(t *testing.T) {
 	}
 
 	// Now let Bob fail this HTLC.
-	err = bobChannel.FailHTLC(bobIndex, []byte("failreason"), nil, nil, nil)
+	err = bobChannel.FailHTLC(
+		bobIndex, []byte("failreason"), nil, nil, nil, nil,
+	)
 	require.NoError(t, err, "unable to cancel HTLC")
-	if err := aliceChannel.ReceiveFailHTLC(aliceIndex, []byte("bad")); err != nil {
+	if err := aliceChannel.ReceiveFailHTLC(
+		aliceIndex, []byte("bad"), nil,
+	); err != nil {
 		t.Fatalf("unable to recv htlc cancel: %v", err)
 	}
 
@@ -5992,10 +6060,12 @@ func TestMaxAcceptedHTLCs(t *testing.T) {
 
 	// Bob will fail the htlc specified by htlcID and then force a state
 	// transition.
-	err = bobChannel.FailHTLC(htlcID, []byte{}, nil, nil, nil)
+	err = bobChannel.FailHTLC(htlcID, []byte{}, nil, nil, nil, nil)
 	require.NoError(t, err, "unable to fail htlc")
 
-	if err := aliceChannel.ReceiveFailHTLC(htlcID, []byte{}); err != nil {
+	if err := aliceChannel.ReceiveFailHTLC(
+		htlcID, []byte{}, nil,
+	); err != nil {
 		t.Fatalf("unable to receive fail htlc: %v", err)
 	}
 
@@ -6113,10 +6183,12 @@ func TestMaxAsynchronousHtlcs(t *testing.T) {
 	}
 
 	// Fail back an HTLC and sign a commitment as in steps 1 & 2.
-	err = bobChannel.FailHTLC(htlcID, []byte{}, nil, nil, nil)
+	err = bobChannel.FailHTLC(htlcID, []byte{}, nil, nil, nil, nil)
 	require.NoError(t, err, "unable to fail htlc")
 
-	if err := aliceChannel.ReceiveFailHTLC(htlcID, []byte{}); err != nil {
+	if err := aliceChannel.ReceiveFailHTLC(
+		htlcID, []byte{}, nil,
+	); err != nil {
 		t.Fatalf("unable to receive fail htlc: %v", err)
 	}
 
@@ -6971,10 +7043,10 @@ func TestChannelRestoreUpdateLogsFailedHTLC(t *testing.T) {
 	restoreAndAssert(t, aliceChannel, 1, 0, 0, 0)
 
 	// Now we make Bob fail this HTLC.
-	err = bobChannel.FailHTLC(0, []byte("failreason"), nil, nil, nil)
+	err = bobChannel.FailHTLC(0, []byte("failreason"), nil, nil, nil, nil)
 	require.NoError(t, err, "unable to cancel HTLC")
 
-	err = aliceChannel.ReceiveFailHTLC(0, []byte("failreason"))
+	err = aliceChannel.ReceiveFailHTLC(0, []byte("failreason"), nil)
 	require.NoError(t, err, "unable to recv htlc cancel")
 
 	// This Fail update should have been added to Alice's remote update log.
@@ -7061,19 +7133,23 @@ func TestDuplicateFailRejection(t *testing.T) {
 
 	// With the HTLC locked in, we'll now have Bob fail the HTLC back to
 	// Alice.
-	err = bobChannel.FailHTLC(0, []byte("failreason"), nil, nil, nil)
+	err = bobChannel.FailHTLC(0, []byte("failreason"), nil, nil, nil, nil)
 	require.NoError(t, err, "unable to cancel HTLC")
-	if err := aliceChannel.ReceiveFailHTLC(0, []byte("bad")); err != nil {
+	if err := aliceChannel.ReceiveFailHTLC(
+		0, []byte("bad"), nil,
+	); err != nil {
 		t.Fatalf("unable to recv htlc cancel: %v", err)
 	}
 
 	// If we attempt to fail it AGAIN, then both sides should reject this
 	// second failure attempt.
-	err = bobChannel.FailHTLC(0, []byte("failreason"), nil, nil, nil)
+	err = bobChannel.FailHTLC(0, []byte("failreason"), nil, nil, nil, nil)
 	if err == nil {
 		t.Fatalf("duplicate HTLC failure attempt should have failed")
 	}
-	if err := aliceChannel.ReceiveFailHTLC(0, []byte("bad")); err == nil {
+	if err := aliceChannel.ReceiveFailHTLC(
+		0, []byte("bad"), nil,
+	); err == nil {
 		t.Fatalf("duplicate HTLC failure attempt should have failed")
 	}
 
@@ -7090,14 +7166,16 @@ func TestDuplicateFailRejection(t *testing.T) {
 	require.NoError(t, err, "unable to restart channel")
 
 	// If we try to fail the same HTLC again, then we should get an error.
-	err = bobChannel.FailHTLC(0, []byte("failreason"), nil, nil, nil)
+	err = bobChannel.FailHTLC(0, []byte("failreason"), nil, nil, nil, nil)
 	if err == nil {
 		t.Fatalf("duplicate HTLC failure attempt should have failed")
 	}
 
 	// Alice on the other hand should accept the failure again, as she
 	// dropped all items in the logs which weren't committed.
-	if err := aliceChannel.ReceiveFailHTLC(0, []byte("bad")); err != nil {
+	if err := aliceChannel.ReceiveFailHTLC(
+		0, []byte("bad"), nil,
+	); err != nil {
 		t.Fatalf("unable to recv htlc cancel: %v", err)
 	}
 }
@@ -7370,9 +7448,9 @@ func TestChannelRestoreCommitHeight(t *testing.T) {
 	bobChannel = restoreAndAssertCommitHeights(t, bobChannel, true, 1, 2, 2)
 
 	// Bob now fails back the htlc that was just locked in.
-	err = bobChannel.FailHTLC(0, []byte("failreason"), nil, nil, nil)
+	err = bobChannel.FailHTLC(0, []byte("failreason"), nil, nil, nil, nil)
 	require.NoError(t, err, "unable to cancel HTLC")
-	err = aliceChannel.ReceiveFailHTLC(0, []byte("bad"))
+	err = aliceChannel.ReceiveFailHTLC(0, []byte("bad"), nil)
 	require.NoError(t, err, "unable to recv htlc cancel")
 
 	// Now Bob signs for the fail update.
@@ -9043,9 +9121,9 @@ func TestChannelUnsignedAckedFailure(t *testing.T) {
 
 	// Now Bob should fail the htlc back to Alice.
 	// <----fail-----
-	err = bobChannel.FailHTLC(0, []byte("failreason"), nil, nil, nil)
+	err = bobChannel.FailHTLC(0, []byte("failreason"), nil, nil, nil, nil)
 	require.NoError(t, err)
-	err = aliceChannel.ReceiveFailHTLC(0, []byte("bad"))
+	err = aliceChannel.ReceiveFailHTLC(0, []byte("bad"), nil)
 	require.NoError(t, err)
 
 	// Bob should send a commitment signature to Alice.
@@ -9153,9 +9231,9 @@ func TestChannelLocalUnsignedUpdatesFailure(t *testing.T) {
 
 	// Now Alice should fail the htlc back to Bob.
 	// -----fail--->
-	err = aliceChannel.FailHTLC(0, []byte("failreason"), nil, nil, nil)
+	err = aliceChannel.FailHTLC(0, []byte("failreason"), nil, nil, nil, nil)
 	require.NoError(t, err)
-	err = bobChannel.ReceiveFailHTLC(0, []byte("bad"))
+	err = bobChannel.ReceiveFailHTLC(0, []byte("bad"), nil)
 	require.NoError(t, err)
 
 	// Alice should send a commitment signature to Bob.
@@ -10112,3 +10190,241 @@ func testNewBreachRetribution(t *testing.T, chanType channeldb.ChannelType) {
 	)
 	require.ErrorIs(t, err, channeldb.ErrLogEntryNotFound)
 }
+
+// TestZeroFeeCommitment asserts that zero-fee commitment channels create v3
+// commitment transactions without fees and a single ephemeral anchor carrying
+// the trimmed HTLC value, and that fee updates are rejected.
+func TestZeroFeeCommitment(t *testing.T) {
+	t.Parallel()
+
+	chanType := channeldb.SingleFunderTweaklessBit |
+		channeldb.AnchorOutputsBit | channeldb.ZeroHtlcTxFeeBit |
+		channeldb.ZeroFeeCommitmentBit
+
+	aliceChannel, bobChannel, err := CreateTestChannels(t, chanType)
+	require.NoError(t, err, "unable to create test channels")
+
+	// Add two HTLCs below the dust limit of both parties, which together
+	// exceed the maximum value of the ephemeral anchor.
+	for i := 0; i < 2; i++ {
+		htlc, _ := createHTLC(i, lnwire.NewMSatFromSatoshis(150))
+		_, err := aliceChannel.AddHTLC(htlc, nil)
+		require.NoError(t, err)
+		_, err = bobChannel.ReceiveHTLC(htlc)
+		require.NoError(t, err)
+	}
+	require.NoError(t, ForceStateTransition(aliceChannel, bobChannel))
+
+	for _, c := range []*LightningChannel{aliceChannel, bobChannel} {
+		commit := c.channelState.LocalCommitment
+		require.Zero(t, commit.CommitFee)
+
+		commitTx := commit.CommitTx
+		require.EqualValues(t, 3, commitTx.Version)
+
+		// Besides the two balance outputs, there must only be the
+		// ephemeral anchor, capped at its maximum value.
+		require.Len(t, commitTx.TxOut, 3)
+		found, index := input.FindScriptOutputIndex(
+			commitTx, input.PayToAnchorScript(),
+		)
+		require.True(t, found)
+		require.EqualValues(
+			t, maxEphemeralAnchorValue, commitTx.TxOut[index].Value,
+		)
+	}
+
+	// Neither party can update the fee of a zero-fee commitment.
+	require.ErrorIs(
+		t, aliceChannel.UpdateFee(chainfee.FeePerKwFloor),
+		ErrZeroFeeCommitmentUpdateFee,
+	)
+	require.ErrorIs(
+		t, bobChannel.ReceiveUpdateFee(chainfee.FeePerKwFloor),
+		ErrZeroFeeCommitmentUpdateFee,
+	)
+
+	// The anchor resolution of the local commitment carries the signed
+	// commitment, so both can be relayed as a package.
+	anchors, err := aliceChannel.NewAnchorResolutions()
+	require.NoError(t, err)
+	require.NotNil(t, anchors.Local)
+	require.NotNil(t, anchors.Local.CommitTx)
+	require.NotEmpty(t, anchors.Local.CommitTx.TxIn[0].Witness)
+	require.Equal(
+		t, aliceChannel.channelState.LocalCommitment.CommitTx.TxHash(),
+		anchors.Local.CommitAnchor.Hash,
+	)
+	require.True(t, input.IsPayToAnchorScript(
+		anchors.Local.AnchorSignDescriptor.Output.PkScript,
+	))
+
+	// The remote commitment can't be relayed by us.
+	require.NotNil(t, anchors.Remote)
+	require.Nil(t, anchors.Remote.CommitTx)
+
+	closeSummary, err := aliceChannel.ForceClose()
+	require.NoError(t, err)
+	require.NotNil(t, closeSummary.AnchorResolution)
+	require.NotNil(t, closeSummary.AnchorResolution.CommitTx)
+}
+
+// TestFeeExposureLimit tests that the fee exposure limit takes the commitment
+// fee into account if we pay it, and that the multiplier raises the limit.
+func TestFeeExposureLimit(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		limit    FeeExposureLimit
+		exposure FeeExposure
+		exceeded bool
+	}{
+		{
+			name:  "zero limit",
+			limit: FeeExposureLimit{},
+			exposure: FeeExposure{
+				DustSum: 1_000_000,
+			},
+			exceeded: false,
+		},
+		{
+			name: "dust below limit",
+			limit: FeeExposureLimit{
+				Max: 1_000,
+			},
+			exposure: FeeExposure{
+				DustSum:   1_000,
+				CommitFee: 500,
+			},
+			exceeded: false,
+		},
+		{
+			name: "commit fee paid by initiator",
+			limit: FeeExposureLimit{
+				Max: 1_000,
+			},
+			exposure: FeeExposure{
+				DustSum:   1_000,
+				CommitFee: 500,
+				Initiator: true,
+			},
+			exceeded: true,
+		},
+		{
+			name: "multiplier raises limit",
+			limit: FeeExposureLimit{
+				Max:                 1_000,
+				CommitFeeMultiplier: 4,
+			},
+			exposure: FeeExposure{
+				DustSum:   1_000,
+				CommitFee: 500,
+				Initiator: true,
+			},
+			exceeded: false,
+		},
+		{
+			name: "multiplier doesn't lower limit",
+			limit: FeeExposureLimit{
+				Max:                 1_000,
+				CommitFeeMultiplier: 0.5,
+			},
+			exposure: FeeExposure{
+				DustSum:   1_001,
+				CommitFee: 500,
+			},
+			exceeded: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(
+				t, tc.exceeded, tc.limit.Exceeded(tc.exposure),
+			)
+		})
+	}
+}
+
+// TestCheckFeeExposure tests that HTLCs and fee updates that would make the
+// fee exposure of a channel exceed the limit are detected on both sides of
+// the channel.
+func TestCheckFeeExposure(t *testing.T) {
+	t.Parallel()
+
+	aliceChannel, bobChannel, err := CreateTestChannels(
+		t, channeldb.SingleFunderTweaklessBit,
+	)
+	require.NoError(t, err)
+
+	// Alice sends an HTLC to Bob that is dust on both commitments.
+	htlcAmt := lnwire.NewMSatFromSatoshis(1_000)
+	htlc, _ := createHTLC(0, htlcAmt)
+	_, err = aliceChannel.AddHTLC(htlc, nil)
+	require.NoError(t, err)
+	_, err = bobChannel.ReceiveHTLC(htlc)
+	require.NoError(t, err)
+
+	// Alice pays the commitment fee as the initiator, so it is part of
+	// her fee exposure, while Bob is only exposed to the dust HTLC.
+	aliceExposure := aliceChannel.GetFeeExposure(false, nil)
+	require.Equal(t, htlcAmt, aliceExposure.DustSum)
+	require.True(t, aliceExposure.Initiator)
+	require.NotZero(t, aliceExposure.CommitFee)
+	require.Equal(
+		t, htlcAmt+aliceExposure.CommitFee, aliceExposure.Total(),
+	)
+
+	bobExposure := bobChannel.GetFeeExposure(false, nil)
+	require.Equal(t, htlcAmt, bobExposure.Total())
+
+	// Another outgoing dust HTLC of Alice is added to her exposure.
+	limit := FeeExposureLimit{
+		Max: aliceExposure.Total() + htlcAmt - 1,
+	}
+	err = aliceChannel.CheckHtlcFeeExposure(limit, htlcAmt, false)
+	require.ErrorIs(t, err, ErrFeeExposureExceeded)
+
+	limit.Max++
+	require.NoError(t, aliceChannel.CheckHtlcFeeExposure(
+		limit, htlcAmt, false,
+	))
+
+	// Incoming HTLCs are already part of the exposure.
+	limit = FeeExposureLimit{
+		Max: htlcAmt - 1,
+	}
+	err = bobChannel.CheckHtlcFeeExposure(limit, htlcAmt, true)
+	require.ErrorIs(t, err, ErrFeeExposureExceeded)
+
+	limit.Max++
+	require.NoError(t, bobChannel.CheckHtlcFeeExposure(
+		limit, htlcAmt, true,
+	))
+
+	// HTLCs that aren't dust don't add to the exposure.
+	limit = FeeExposureLimit{
+		Max: 1,
+	}
+	nonDustAmt := lnwire.NewMSatFromSatoshis(100_000)
+	require.NoError(t, aliceChannel.CheckHtlcFeeExposure(
+		limit, nonDustAmt, false,
+	))
+
+	// A fee update raises Alice's exposure through the commitment fee.
+	feeRate := chainfee.SatPerKWeight(
+		aliceChannel.channelState.LocalCommitment.FeePerKw,
+	)
+	limit = FeeExposureLimit{
+		Max: aliceExposure.Total(),
+	}
+	require.NoError(t, aliceChannel.CheckFeeExposure(limit, feeRate))
+
+	err = aliceChannel.CheckFeeExposure(limit, feeRate*2)
+	require.ErrorIs(t, err, ErrFeeExposureExceeded)
+}
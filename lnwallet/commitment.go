@@ -19,6 +19,11 @@ import (
 // anchorSize is the constant anchor output size.
 const anchorSize = btcutil.Amount(330)
 
+// maxEphemeralAnchorValue is the maximum value of the ephemeral anchor output
+// of a zero-fee commitment transaction. Any trimmed HTLC value above it goes to
+// miner fees instead.
+const maxEphemeralAnchorValue = btcutil.Amount(240)
+
 // DefaultAnchorsCommitMaxFeeRateSatPerVByte is the default max fee rate in
 // sat/vbyte the initiator will use for anchor channels. This should be enough
 // to ensure propagation before anchoring down the commitment transaction.
@@ -476,6 +481,10 @@ func CommitWeight(chanType channeldb.ChannelType) int64 {
 	case chanType.IsTaproot():
 		return input.TaprootCommitWeight
 
+	// Zero-fee commitments only carry a single, small ephemeral anchor.
+	case chanType.HasZeroFeeCommitment():
+		return input.ZeroFeeCommitWeight
+
 	// If this commitment has anchors, it will be slightly heavier.
 	case chanType.HasAnchors():
 		return input.AnchorCommitWeight
@@ -728,7 +737,12 @@ func (cb *CommitmentBuilder) createUnsignedCommitmentTx(ourBalance,
 
 	// With the weight known, we can now calculate the commitment fee,
 	// ensuring that we account for any dust outputs trimmed above.
+	// Zero-fee commitments don't pay any fees themselves, they are
+	// bumped through their ephemeral anchor instead.
 	commitFee := feePerKw.FeeForWeight(totalCommitWeight)
+	if cb.chanState.ChanType.HasZeroFeeCommitment() {
+		commitFee = 0
+	}
 	commitFeeMSat := lnwire.NewMSatFromSatoshis(commitFee)
 
 	// Currently, within the protocol, the initiator always pays the fees.
@@ -781,6 +795,33 @@ func (cb *CommitmentBuilder) createUnsignedCommitmentTx(ourBalance,
 		return nil, err
 	}
 
+	// For zero-fee commitments, the value of any trimmed HTLCs is added to
+	// the ephemeral anchor, up to a maximum. Anything above that is left
+	// to miners.
+	if cb.chanState.ChanType.HasZeroFeeCommitment() {
+		var trimmed btcutil.Amount
+		for _, htlc := range filteredHTLCView.ourUpdates {
+			if HtlcIsDust(
+				cb.chanState.ChanType, false, isOurs, feePerKw,
+				htlc.Amount.ToSatoshis(), dustLimit,
+			) {
+
+				trimmed += htlc.Amount.ToSatoshis()
+			}
+		}
+		for _, htlc := range filteredHTLCView.theirUpdates {
+			if HtlcIsDust(
+				cb.chanState.ChanType, true, isOurs, feePerKw,
+				htlc.Amount.ToSatoshis(), dustLimit,
+			) {
+
+				trimmed += htlc.Amount.ToSatoshis()
+			}
+		}
+
+		setEphemeralAnchorValue(commitTx, trimmed)
+	}
+
 	// We'll now add all the HTLC outputs to the commitment transaction.
 	// Each output includes an off-chain 2-of-2 covenant clause, so we'll
 	// need the objective local/remote keys for this particular commitment
@@ -906,8 +947,14 @@ func CreateCommitTx(chanType channeldb.ChannelType,
 
 	// Now that both output scripts have been created, we can finally create
 	// the transaction itself. We use a transaction version of 2 since CSV
-	// will fail unless the tx version is >= 2.
-	commitTx := wire.NewMsgTx(2)
+	// will fail unless the tx version is >= 2. Zero-fee commitments use
+	// version 3 (TRUC) so they can be relayed as a package together with
+	// the child spending their ephemeral anchor.
+	txVersion := int32(2)
+	if chanType.HasZeroFeeCommitment() {
+		txVersion = 3
+	}
+	commitTx := wire.NewMsgTx(txVersion)
 	commitTx.AddTxIn(&fundingOutput)
 
 	// Avoid creating dust outputs within the commitment transaction.
@@ -927,6 +974,17 @@ func CreateCommitTx(chanType channeldb.ChannelType,
 		})
 	}
 
+	// Zero-fee commitments always carry a single, keyless pay-to-anchor
+	// output that either party can spend to bump the fee of the package.
+	if chanType.HasZeroFeeCommitment() {
+		commitTx.AddTxOut(&wire.TxOut{
+			PkScript: input.PayToAnchorScript(),
+			Value:    0,
+		})
+
+		return commitTx, nil
+	}
+
 	// If this channel type has anchors, we'll also add those.
 	if chanType.HasAnchors() {
 		localAnchor, remoteAnchor, err := CommitScriptAnchors(
@@ -958,6 +1016,22 @@ func CreateCommitTx(chanType channeldb.ChannelType,
 	return commitTx, nil
 }
 
+// setEphemeralAnchorValue sets the value of the pay-to-anchor output of a
+// zero-fee commitment transaction to the trimmed amount, capped at
+// maxEphemeralAnchorValue.
+func setEphemeralAnchorValue(commitTx *wire.MsgTx, trimmed btcutil.Amount) {
+	if trimmed > maxEphemeralAnchorValue {
+		trimmed = maxEphemeralAnchorValue
+	}
+
+	for _, txOut := range commitTx.TxOut {
+		if input.IsPayToAnchorScript(txOut.PkScript) {
+			txOut.Value = int64(trimmed)
+			return
+		}
+	}
+}
+
 // CoopCloseBalance returns the final balances that should be used to create
 // the cooperative close tx, given the channel type and transaction fee.
 func CoopCloseBalance(chanType channeldb.ChannelType, isInitiator bool,
@@ -974,7 +1048,9 @@ func CoopCloseBalance(chanType channeldb.ChannelType, isInitiator bool,
 
 	// Since the initiator's balance also is stored after subtracting the
 	// anchor values, add that back in case this was an anchor commitment.
-	if chanType.HasAnchors() {
+	// The ephemeral anchor of zero-fee commitments is not funded from the
+	// initiator's balance.
+	if chanType.HasAnchors() && !chanType.HasZeroFeeCommitment() {
 		initiatorDelta += 2 * anchorSize
 	}
 
@@ -61,4 +61,9 @@ type Config struct {
 	// passively rebroadcast transactions in the background until they're
 	// detected as being confirmed.
 	Rebroadcaster Rebroadcaster
+
+	// PublishTracker is an optional config param that is used to record
+	// the transactions published through the wallet, so their fate can be
+	// followed after they've been handed to the backend.
+	PublishTracker PublishTracker
 }
@@ -0,0 +1,553 @@
+package lnwallet
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/input"
+	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
+	"github.com/lightningnetwork/lnd/lnwire"
+)
+
+var (
+	// ErrDynCommitUnsupportedType is returned if a dynamic commitment
+	// negotiation attempts to change the commitment type from or to a
+	// type that can't be changed without a new funding output.
+	ErrDynCommitUnsupportedType = errors.New("commitment type can't be " +
+		"changed through a dynamic commitment negotiation")
+
+	// ErrDynCommitDowngrade is returned if a dynamic commitment negotiation
+	// attempts to move the channel to an older commitment type.
+	ErrDynCommitDowngrade = errors.New("commitment type can only be " +
+		"upgraded")
+
+	// ErrDynCommitNoParams is returned if a dynamic commitment proposal
+	// doesn't contain any parameters.
+	ErrDynCommitNoParams = errors.New("no channel parameters to change")
+
+	// ErrDynCommitPending is returned if a new dynamic commitment
+	// negotiation is attempted before the previous one is active on both
+	// commitments.
+	ErrDynCommitPending = errors.New("previous channel parameter change " +
+		"not yet active")
+)
+
+// dynCommitFormatBits are the channel type bits that can be changed through a
+// dynamic commitment negotiation.
+const dynCommitFormatBits = channeldb.SingleFunderTweaklessBit |
+	channeldb.AnchorOutputsBit | channeldb.ZeroHtlcTxFeeBit
+
+// DynCommitParams are the channel parameters that can be changed through a
+// dynamic commitment negotiation. Like in the open_channel message, they
+// describe the constraints of the party that proposes them. Parameters that
+// are nil are left unchanged.
+type DynCommitParams struct {
+	// DustLimit is the new dust limit of the proposer's commitment
+	// transaction.
+	DustLimit *btcutil.Amount
+
+	// MaxAcceptedHTLCs is the new maximum number of HTLCs the proposer
+	// accepts.
+	MaxAcceptedHTLCs *uint16
+
+	// CsvDelay is the new delay the receiver of the proposal must use for
+	// its outputs.
+	CsvDelay *uint16
+
+	// CommitmentType is the new commitment type of the channel.
+	CommitmentType *CommitmentType
+}
+
+// IsEmpty returns true if none of the parameters is set.
+func (p *DynCommitParams) IsEmpty() bool {
+	return p.DustLimit == nil && p.MaxAcceptedHTLCs == nil &&
+		p.CsvDelay == nil && p.CommitmentType == nil
+}
+
+// DynCommitRejectError is returned if some of the parameters of a dynamic
+// commitment proposal can't be accepted.
+type DynCommitRejectError struct {
+	// Rejected has a bit set for the TLV type of every rejected parameter
+	// of the dyn_propose message.
+	Rejected *lnwire.RawFeatureVector
+
+	// Reasons holds the reason for every rejected parameter.
+	Reasons []error
+}
+
+// Error returns a human readable string describing the error.
+//
+// NOTE: Part of the error interface.
+func (e *DynCommitRejectError) Error() string {
+	reasons := make([]string, 0, len(e.Reasons))
+	for _, reason := range e.Reasons {
+		reasons = append(reasons, reason.Error())
+	}
+
+	return fmt.Sprintf("channel parameters rejected: %v",
+		strings.Join(reasons, ", "))
+}
+
+// reject marks the parameter with the given TLV type as rejected.
+func (e *DynCommitRejectError) reject(param lnwire.FeatureBit, reason error) {
+	e.Rejected.Set(param)
+	e.Reasons = append(e.Reasons, reason)
+}
+
+// dynCommitmentType returns the commitment type of the given channel type,
+// or an error if it can't be changed through a dynamic commitment
+// negotiation.
+func dynCommitmentType(chanType channeldb.ChannelType) (CommitmentType,
+	error) {
+
+	switch {
+	// Taproot channels use a different funding output, so they can't be
+	// moved to or from through a dynamic commitment negotiation.
+	case chanType.IsTaproot(), chanType.HasLeaseExpiration(),
+		chanType.HasZeroFeeCommitment():
+
+		return 0, ErrDynCommitUnsupportedType
+
+	case chanType.HasAnchors() && chanType.ZeroHtlcTxFee():
+		return CommitmentTypeAnchorsZeroFeeHtlcTx, nil
+
+	// The outdated anchors type without zero-fee HTLC transactions is
+	// not supported.
+	case chanType.HasAnchors():
+		return 0, ErrDynCommitUnsupportedType
+
+	case chanType.IsTweakless():
+		return CommitmentTypeTweakless, nil
+
+	default:
+		return CommitmentTypeLegacy, nil
+	}
+}
+
+// dynCommitFormat returns the channel type bits of the given commitment type.
+func dynCommitFormat(commitType CommitmentType) (channeldb.ChannelType,
+	error) {
+
+	switch commitType {
+	case CommitmentTypeLegacy:
+		return 0, nil
+
+	case CommitmentTypeTweakless:
+		return channeldb.SingleFunderTweaklessBit, nil
+
+	case CommitmentTypeAnchorsZeroFeeHtlcTx:
+		return dynCommitFormatBits, nil
+
+	default:
+		return 0, ErrDynCommitUnsupportedType
+	}
+}
+
+// NewDynPropose creates the dyn_propose message for the given parameters.
+func NewDynPropose(chanID lnwire.ChannelID,
+	params *DynCommitParams) (*lnwire.DynPropose, error) {
+
+	msg := &lnwire.DynPropose{
+		ChanID:           chanID,
+		DustLimit:        params.DustLimit,
+		MaxAcceptedHTLCs: params.MaxAcceptedHTLCs,
+		CsvDelay:         params.CsvDelay,
+	}
+
+	if params.CommitmentType == nil {
+		return msg, nil
+	}
+
+	var chanType lnwire.ChannelType
+	switch *params.CommitmentType {
+	case CommitmentTypeTweakless:
+		chanType = lnwire.ChannelType(*lnwire.NewRawFeatureVector(
+			lnwire.StaticRemoteKeyRequired,
+		))
+
+	case CommitmentTypeAnchorsZeroFeeHtlcTx:
+		chanType = lnwire.ChannelType(*lnwire.NewRawFeatureVector(
+			lnwire.StaticRemoteKeyRequired,
+			lnwire.AnchorsZeroFeeHtlcTxRequired,
+		))
+
+	default:
+		return nil, ErrDynCommitUnsupportedType
+	}
+	msg.ChannelType = &chanType
+
+	return msg, nil
+}
+
+// DynCommitParamsFromMsg extracts the parameters proposed in the given
+// dyn_propose message.
+func DynCommitParamsFromMsg(msg *lnwire.DynPropose) (*DynCommitParams,
+	error) {
+
+	params := &DynCommitParams{
+		DustLimit:        msg.DustLimit,
+		MaxAcceptedHTLCs: msg.MaxAcceptedHTLCs,
+		CsvDelay:         msg.CsvDelay,
+	}
+
+	if msg.ChannelType == nil {
+		return params, nil
+	}
+
+	var commitType CommitmentType
+	chanFeatures := lnwire.RawFeatureVector(*msg.ChannelType)
+	switch {
+	case chanFeatures.OnlyContains(lnwire.StaticRemoteKeyRequired):
+		commitType = CommitmentTypeTweakless
+
+	case chanFeatures.OnlyContains(
+		lnwire.StaticRemoteKeyRequired,
+		lnwire.AnchorsZeroFeeHtlcTxRequired,
+	):
+		commitType = CommitmentTypeAnchorsZeroFeeHtlcTx
+
+	default:
+		return nil, ErrDynCommitUnsupportedType
+	}
+	params.CommitmentType = &commitType
+
+	return params, nil
+}
+
+// dynCommitState computes the channel type and the local and remote
+// constraints that result from applying the given parameters. If local is
+// true, the parameters were proposed by us.
+func (lc *LightningChannel) dynCommitState(params *DynCommitParams,
+	local bool) (channeldb.ChannelType, channeldb.ChannelConstraints,
+	channeldb.ChannelConstraints) {
+
+	var (
+		chanType = lc.channelState.ChanType
+		ours     = lc.channelState.LocalChanCfg.ChannelConstraints
+		theirs   = lc.channelState.RemoteChanCfg.ChannelConstraints
+	)
+
+	// The proposal describes the constraints of the proposer, so the
+	// proposer's dust limit applies to its own commitment, while the
+	// other limits are the ones the proposer imposes on its counterparty.
+	proposer, receiver := &theirs, &ours
+	if local {
+		proposer, receiver = &ours, &theirs
+	}
+
+	if params.DustLimit != nil {
+		proposer.DustLimit = *params.DustLimit
+	}
+	if params.MaxAcceptedHTLCs != nil {
+		receiver.MaxAcceptedHtlcs = *params.MaxAcceptedHTLCs
+	}
+	if params.CsvDelay != nil {
+		receiver.CsvDelay = *params.CsvDelay
+	}
+	if params.CommitmentType != nil {
+		// The type was validated before, so the error can be ignored.
+		format, _ := dynCommitFormat(*params.CommitmentType)
+		chanType = chanType&^dynCommitFormatBits | format
+	}
+
+	return chanType, ours, theirs
+}
+
+// DynCommitParamsApplied returns true if the given parameters are already in
+// effect for the channel. This is the case if a proposal is retransmitted
+// after it was accepted.
+func (lc *LightningChannel) DynCommitParamsApplied(params *DynCommitParams,
+	local bool) bool {
+
+	lc.RLock()
+	defer lc.RUnlock()
+
+	chanType, ours, theirs := lc.dynCommitState(params, local)
+
+	return chanType == lc.channelState.ChanType &&
+		ours == lc.channelState.LocalChanCfg.ChannelConstraints &&
+		theirs == lc.channelState.RemoteChanCfg.ChannelConstraints
+}
+
+// ValidateDynCommitParams checks whether the given parameters of a dynamic
+// commitment proposal can be applied to the channel. If local is true, the
+// parameters are proposed by us, otherwise by the remote party.
+// maxLocalCSVDelay is the maximum delay we accept for our own outputs. If some
+// parameters can't be accepted, a DynCommitRejectError is returned.
+func (lc *LightningChannel) ValidateDynCommitParams(params *DynCommitParams,
+	local bool, maxLocalCSVDelay uint16) error {
+
+	lc.RLock()
+	defer lc.RUnlock()
+
+	if params.IsEmpty() {
+		return ErrDynCommitNoParams
+	}
+
+	if lc.dynCommitPending() {
+		return ErrDynCommitPending
+	}
+
+	rejectErr := &DynCommitRejectError{
+		Rejected: lnwire.NewRawFeatureVector(),
+	}
+
+	// The proposer's dust limit must be within the range we'd also accept
+	// when opening a channel, and must not exceed the reserve the
+	// proposer requires from its counterparty.
+	if params.DustLimit != nil {
+		dustLimit := *params.DustLimit
+		reserve := lc.channelState.LocalChanCfg.ChanReserve
+		if local {
+			reserve = lc.channelState.RemoteChanCfg.ChanReserve
+		}
+
+		maxWitnessLimit := DustLimitForSize(input.UnknownWitnessSize)
+		switch {
+		case dustLimit < maxWitnessLimit ||
+			dustLimit > 3*maxWitnessLimit:
+
+			rejectErr.reject(
+				lnwire.FeatureBit(lnwire.DPDustLimitSatoshis),
+				ErrInvalidDustLimit(dustLimit),
+			)
+
+		case dustLimit > reserve:
+			rejectErr.reject(
+				lnwire.FeatureBit(lnwire.DPDustLimitSatoshis),
+				ErrChanReserveTooSmall(reserve, dustLimit),
+			)
+		}
+	}
+
+	// The number of HTLCs must be within the limits of BOLT-02.
+	if params.MaxAcceptedHTLCs != nil {
+		const minNumHtlc = 5
+		maxHtlcs := *params.MaxAcceptedHTLCs
+
+		switch {
+		case maxHtlcs > uint16(input.MaxHTLCNumber/2):
+			rejectErr.reject(
+				lnwire.FeatureBit(lnwire.DPMaxAcceptedHtlcs),
+				ErrMaxHtlcNumTooLarge(
+					maxHtlcs, uint16(input.MaxHTLCNumber/2),
+				),
+			)
+
+		case maxHtlcs < minNumHtlc:
+			rejectErr.reject(
+				lnwire.FeatureBit(lnwire.DPMaxAcceptedHtlcs),
+				ErrMaxHtlcNumTooSmall(maxHtlcs, minNumHtlc),
+			)
+		}
+	}
+
+	// A delay proposed by the remote party applies to our outputs, so it
+	// must not exceed our maximum.
+	if params.CsvDelay != nil {
+		csvDelay := *params.CsvDelay
+
+		switch {
+		case csvDelay == 0:
+			rejectErr.reject(
+				lnwire.FeatureBit(lnwire.DPToSelfDelay),
+				errors.New("csv delay must be positive"),
+			)
+
+		case !local && csvDelay > maxLocalCSVDelay:
+			rejectErr.reject(
+				lnwire.FeatureBit(lnwire.DPToSelfDelay),
+				ErrCsvDelayTooLarge(csvDelay, maxLocalCSVDelay),
+			)
+		}
+	}
+
+	if params.CommitmentType != nil {
+		err := lc.validateDynCommitType(*params.CommitmentType)
+		if err != nil {
+			rejectErr.reject(
+				lnwire.FeatureBit(lnwire.DPChannelType), err,
+			)
+		}
+	}
+
+	if len(rejectErr.Reasons) != 0 {
+		return rejectErr
+	}
+
+	return nil
+}
+
+// validateDynCommitType checks whether the channel can be moved to the given
+// commitment type. This function expects to be executed with a lock held.
+func (lc *LightningChannel) validateDynCommitType(
+	commitType CommitmentType) error {
+
+	chanType := lc.channelState.ChanType
+	curType, err := dynCommitmentType(chanType)
+	if err != nil {
+		return err
+	}
+
+	format, err := dynCommitFormat(commitType)
+	if err != nil {
+		return err
+	}
+
+	// The commitment types are ordered from oldest to newest.
+	if commitType < curType {
+		return ErrDynCommitDowngrade
+	}
+
+	// The initiator of the channel pays for the commitment fee and the
+	// anchor outputs, so it must be able to afford the new commitment
+	// type while keeping its reserve.
+	newChanType := chanType&^dynCommitFormatBits | format
+	feePerKw := chainfee.SatPerKWeight(
+		lc.channelState.LocalCommitment.FeePerKw,
+	)
+	oldFee := feePerKw.FeeForWeight(CommitWeight(chanType))
+	newFee := feePerKw.FeeForWeight(CommitWeight(newChanType))
+
+	cost := newFee - oldFee
+	if newChanType.HasAnchors() && !chanType.HasAnchors() {
+		cost += 2 * anchorSize
+	}
+
+	balance := lc.channelState.LocalCommitment.RemoteBalance.ToSatoshis()
+	reserve := lc.channelState.RemoteChanCfg.ChanReserve
+	if lc.channelState.IsInitiator {
+		balance = lc.channelState.LocalCommitment.LocalBalance.ToSatoshis()
+		reserve = lc.channelState.LocalChanCfg.ChanReserve
+	}
+
+	if balance < cost+reserve {
+		return fmt.Errorf("channel initiator can't afford commitment "+
+			"type %v: balance=%v, cost=%v, reserve=%v", commitType,
+			balance, cost, reserve)
+	}
+
+	return nil
+}
+
+// ApplyDynCommitParams applies the parameters of an accepted dynamic
+// commitment proposal to the channel. If local is true, the parameters were
+// proposed by us. The current commitments keep using the old parameters, the
+// next commitments of both parties will use the new ones. The parameters must
+// have been checked with ValidateDynCommitParams before.
+func (lc *LightningChannel) ApplyDynCommitParams(params *DynCommitParams,
+	local bool) error {
+
+	lc.Lock()
+	defer lc.Unlock()
+
+	// The new parameters take effect with the next commitment of each
+	// party, so both chains need to be fully in sync.
+	if lc.localCommitChain.hasUnackedCommitment() ||
+		lc.remoteCommitChain.hasUnackedCommitment() {
+
+		return fmt.Errorf("unable to apply channel parameters: " +
+			"channel has pending commitments")
+	}
+
+	chanType, ours, theirs := lc.dynCommitState(params, local)
+	err := lc.channelState.ApplyDynCommitment(
+		chanType, ours, theirs, local,
+	)
+	if err != nil {
+		return err
+	}
+
+	lc.log.Infof("applied new channel parameters: type=%v, "+
+		"local_constraints=%v, remote_constraints=%v, activation "+
+		"height local=%v remote=%v", chanType, ours, theirs,
+		lc.localCommitChain.tip().height+1,
+		lc.remoteCommitChain.tip().height+1)
+
+	return nil
+}
+
+// DynCommitPending returns true if the channel parameters were changed through
+// a dynamic commitment negotiation, but the old commitments of both parties
+// haven't been revoked yet. No new updates should be added to the channel
+// until then.
+func (lc *LightningChannel) DynCommitPending() bool {
+	lc.RLock()
+	defer lc.RUnlock()
+
+	return lc.dynCommitPending()
+}
+
+// dynCommitPending is the internal version of DynCommitPending. This function
+// expects to be executed with a lock held.
+func (lc *LightningChannel) dynCommitPending() bool {
+	dynCommit := lc.channelState.LatestDynCommitment()
+	if dynCommit == nil {
+		return false
+	}
+
+	return lc.localCommitChain.tail().height <
+		dynCommit.LocalCommitHeight ||
+		lc.remoteCommitChain.tail().height <
+			dynCommit.RemoteCommitHeight
+}
+
+// dynCommitOwed returns true if a commitment that activates the parameters of
+// the latest dynamic commitment negotiation is owed to the local party, or to
+// the remote party if local is false. The initiator of the change signs
+// first, while the other party only signs once it received a commitment with
+// the new parameters itself. This function expects to be executed with a lock
+// held.
+func (lc *LightningChannel) dynCommitOwed(local bool) bool {
+	dynCommit := lc.channelState.LatestDynCommitment()
+	if dynCommit == nil {
+		return false
+	}
+
+	var (
+		localActive = lc.localCommitChain.tip().height >=
+			dynCommit.LocalCommitHeight
+		remoteActive = lc.remoteCommitChain.tip().height >=
+			dynCommit.RemoteCommitHeight
+	)
+
+	// From our point of view we owe the remote party a commitment, while
+	// from the remote point of view, they owe us one.
+	if local {
+		return !remoteActive && (dynCommit.Initiator || localActive)
+	}
+
+	return !localActive && (!dynCommit.Initiator || remoteActive)
+}
+
+// dynCommitAnchorCost returns the value of the anchor outputs the channel
+// initiator has to pay for on the commitment at the given height. This is only
+// the case for the first commitment after a dynamic commitment negotiation
+// added anchor outputs to the channel, as the balances of later commitments
+// already account for them. This function expects to be executed with a lock
+// held.
+func (lc *LightningChannel) dynCommitAnchorCost(remoteChain bool,
+	height uint64) lnwire.MilliSatoshi {
+
+	dynCommit := lc.channelState.LatestDynCommitment()
+	if dynCommit == nil {
+		return 0
+	}
+
+	activationHeight := dynCommit.LocalCommitHeight
+	if remoteChain {
+		activationHeight = dynCommit.RemoteCommitHeight
+	}
+
+	if height != activationHeight ||
+		dynCommit.PrevChanType.HasAnchors() ||
+		!lc.channelState.ChanType.HasAnchors() {
+
+		return 0
+	}
+
+	return lnwire.NewMSatFromSatoshis(2 * anchorSize)
+}
@@ -0,0 +1,140 @@
+package lnwallet
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDynCommitmentUpgrade asserts that a tweakless channel can be upgraded to
+// an anchor channel through a dynamic commitment negotiation, and that the
+// revoked commitments created before the upgrade can still be punished.
+func TestDynCommitmentUpgrade(t *testing.T) {
+	t.Parallel()
+
+	aliceChannel, bobChannel, err := CreateTestChannels(
+		t, channeldb.SingleFunderTweaklessBit,
+	)
+	require.NoError(t, err, "unable to create test channels")
+
+	const maxCSV = 10000
+
+	// Invalid parameters should be rejected with the offending fields
+	// marked.
+	legacy := CommitmentType(CommitmentTypeLegacy)
+	dustLimit := btcutil.Amount(1)
+	err = bobChannel.ValidateDynCommitParams(&DynCommitParams{
+		DustLimit:      &dustLimit,
+		CommitmentType: &legacy,
+	}, false, maxCSV)
+
+	var rejectErr *DynCommitRejectError
+	require.True(t, errors.As(err, &rejectErr))
+	require.True(t, rejectErr.Rejected.IsSet(
+		lnwire.FeatureBit(lnwire.DPDustLimitSatoshis),
+	))
+	require.True(t, rejectErr.Rejected.IsSet(
+		lnwire.FeatureBit(lnwire.DPChannelType),
+	))
+	require.False(t, rejectErr.Rejected.IsSet(
+		lnwire.FeatureBit(lnwire.DPToSelfDelay),
+	))
+
+	// Alice now proposes to upgrade the channel to anchors and to use a
+	// larger delay for Bob's outputs. The proposal should survive the
+	// round trip through the wire message.
+	anchors := CommitmentType(CommitmentTypeAnchorsZeroFeeHtlcTx)
+	oldCsv := bobChannel.channelState.LocalChanCfg.CsvDelay
+	newCsv := oldCsv + 10
+	params := &DynCommitParams{
+		CsvDelay:       &newCsv,
+		CommitmentType: &anchors,
+	}
+
+	msg, err := NewDynPropose(
+		lnwire.NewChanIDFromOutPoint(aliceChannel.ChannelPoint()), params,
+	)
+	require.NoError(t, err)
+	received, err := DynCommitParamsFromMsg(msg)
+	require.NoError(t, err)
+	require.Equal(t, params, received)
+
+	require.NoError(t, aliceChannel.ValidateDynCommitParams(
+		params, true, maxCSV,
+	))
+	require.NoError(t, bobChannel.ValidateDynCommitParams(
+		received, false, maxCSV,
+	))
+	require.False(t, bobChannel.DynCommitParamsApplied(received, false))
+
+	oldBreachTx := aliceChannel.channelState.RemoteCommitment.CommitTx
+	oldCommit := aliceChannel.channelState.LocalCommitment
+
+	require.NoError(t, aliceChannel.ApplyDynCommitParams(params, true))
+	require.NoError(t, bobChannel.ApplyDynCommitParams(received, false))
+	require.True(t, bobChannel.DynCommitParamsApplied(received, false))
+
+	// Alice, as the initiator of the change, owes Bob a commitment with
+	// the new parameters, while Bob waits for it. No updates can be added
+	// until the change is active on both commitments.
+	require.True(t, aliceChannel.OweCommitment())
+	require.False(t, bobChannel.OweCommitment())
+	require.True(t, aliceChannel.DynCommitPending())
+	require.True(t, bobChannel.DynCommitPending())
+	require.False(t, aliceChannel.IsChannelClean())
+	require.False(t, bobChannel.IsChannelClean())
+
+	require.NoError(t, ForceStateTransition(aliceChannel, bobChannel))
+
+	for _, c := range []*LightningChannel{aliceChannel, bobChannel} {
+		require.False(t, c.DynCommitPending())
+		require.True(t, c.IsChannelClean())
+		require.True(t, c.channelState.ChanType.HasAnchors())
+		require.True(t, c.channelState.ChanType.ZeroHtlcTxFee())
+
+		// Both commitments should now carry two anchor outputs next
+		// to the two balance outputs.
+		localTx := c.channelState.LocalCommitment.CommitTx
+		remoteTx := c.channelState.RemoteCommitment.CommitTx
+		require.Len(t, localTx.TxOut, 4)
+		require.Len(t, remoteTx.TxOut, 4)
+	}
+	require.Equal(
+		t, newCsv, bobChannel.channelState.LocalChanCfg.CsvDelay,
+	)
+	require.Equal(
+		t, newCsv, aliceChannel.channelState.RemoteChanCfg.CsvDelay,
+	)
+
+	// Alice, as the channel initiator, paid for the anchors and the
+	// larger commitment.
+	newCommit := aliceChannel.channelState.LocalCommitment
+	require.Equal(
+		t, oldCommit.LocalBalance.ToSatoshis()+oldCommit.CommitFee-
+			newCommit.CommitFee-2*anchorSize,
+		newCommit.LocalBalance.ToSatoshis(),
+	)
+	require.Equal(t, oldCommit.RemoteBalance, newCommit.RemoteBalance)
+
+	// The revoked commitment of Bob was created before the upgrade, so the
+	// retribution must be derived using the old parameters.
+	br, err := NewBreachRetribution(
+		aliceChannel.channelState, 0, 101, oldBreachTx,
+	)
+	require.NoError(t, err)
+	require.Equal(t, oldBreachTx.TxHash(), br.BreachTxHash)
+	require.EqualValues(t, oldCsv, br.RemoteDelay)
+	require.Zero(t, br.LocalDelay)
+
+	// The channel should keep working with the new parameters.
+	htlc, _ := createHTLC(0, lnwire.MilliSatoshi(5000000))
+	_, err = aliceChannel.AddHTLC(htlc, nil)
+	require.NoError(t, err)
+	_, err = bobChannel.ReceiveHTLC(htlc)
+	require.NoError(t, err)
+	require.NoError(t, ForceStateTransition(aliceChannel, bobChannel))
+}
@@ -25,3 +25,13 @@ type Rebroadcaster interface {
 	// rebroadcast.
 	MarkAsConfirmed(txid chainhash.Hash)
 }
+
+// PublishTracker is an abstract tracker that records every transaction
+// published through the wallet, along with the outcome of the publication, and
+// keeps track of what happens to the transaction afterwards.
+type PublishTracker interface {
+	// TrackPublish records an attempt to publish the given transaction
+	// with the given label. The passed error is the result of the attempt,
+	// which is nil if the transaction was accepted by the backend.
+	TrackPublish(tx *wire.MsgTx, label string, publishErr error)
+}
@@ -48,6 +48,13 @@ const (
 	// channels that use a musig2 funding output and the tapscript tree
 	// where relevant for the commitment transaction pk scripts.
 	CommitmentTypeSimpleTaproot
+
+	// CommitmentTypeZeroFeeCommitments is a commitment type that builds
+	// upon CommitmentTypeAnchorsZeroFeeHtlcTx, but replaces the two keyed
+	// anchors by a single ephemeral pay-to-anchor output. The commitment
+	// transaction is a zero-fee v3 (TRUC) transaction that is relayed as a
+	// package together with a child spending the anchor.
+	CommitmentTypeZeroFeeCommitments
 )
 
 // HasStaticRemoteKey returns whether the commitment type supports remote
@@ -57,7 +64,8 @@ func (c CommitmentType) HasStaticRemoteKey() bool {
 	case CommitmentTypeTweakless,
 		CommitmentTypeAnchorsZeroFeeHtlcTx,
 		CommitmentTypeScriptEnforcedLease,
-		CommitmentTypeSimpleTaproot:
+		CommitmentTypeSimpleTaproot,
+		CommitmentTypeZeroFeeCommitments:
 		return true
 	default:
 		return false
@@ -69,7 +77,8 @@ func (c CommitmentType) HasAnchors() bool {
 	switch c {
 	case CommitmentTypeAnchorsZeroFeeHtlcTx,
 		CommitmentTypeScriptEnforcedLease,
-		CommitmentTypeSimpleTaproot:
+		CommitmentTypeSimpleTaproot,
+		CommitmentTypeZeroFeeCommitments:
 		return true
 	default:
 		return false
@@ -81,6 +90,12 @@ func (c CommitmentType) IsTaproot() bool {
 	return c == CommitmentTypeSimpleTaproot
 }
 
+// IsZeroFeeCommitment returns true if the commitment type uses zero-fee
+// commitment transactions with an ephemeral anchor.
+func (c CommitmentType) IsZeroFeeCommitment() bool {
+	return c == CommitmentTypeZeroFeeCommitments
+}
+
 // String returns the name of the CommitmentType.
 func (c CommitmentType) String() string {
 	switch c {
@@ -94,6 +109,8 @@ func (c CommitmentType) String() string {
 		return "script-enforced-lease"
 	case CommitmentTypeSimpleTaproot:
 		return "simple-taproot"
+	case CommitmentTypeZeroFeeCommitments:
+		return "zero-fee-commitments"
 	default:
 		return "invalid"
 	}
@@ -244,11 +261,19 @@ func NewChannelReservation(capacity, localFundingAmt btcutil.Amount,
 	commitWeight := int64(input.CommitWeight)
 	if req.CommitType.IsTaproot() {
 		commitWeight = input.TaprootCommitWeight
+	} else if req.CommitType.IsZeroFeeCommitment() {
+		commitWeight = int64(input.ZeroFeeCommitWeight)
 	} else if req.CommitType.HasAnchors() {
 		commitWeight = int64(input.AnchorCommitWeight)
 	}
 	commitFee := req.CommitFeePerKw.FeeForWeight(commitWeight)
 
+	// Zero-fee commitments don't pay any fees, they are bumped through
+	// their ephemeral anchor when broadcast.
+	if req.CommitType.IsZeroFeeCommitment() {
+		commitFee = 0
+	}
+
 	localFundingMSat := lnwire.NewMSatFromSatoshis(localFundingAmt)
 	// TODO(halseth): make method take remote funding amount directly
 	// instead of inferring it from capacity and local amt.
@@ -257,7 +282,9 @@ func NewChannelReservation(capacity, localFundingAmt btcutil.Amount,
 	// The total fee paid by the initiator will be the commitment fee in
 	// addition to the two anchor outputs.
 	feeMSat := lnwire.NewMSatFromSatoshis(commitFee)
-	if req.CommitType.HasAnchors() {
+	if req.CommitType.HasAnchors() &&
+		!req.CommitType.IsZeroFeeCommitment() {
+
 		feeMSat += 2 * lnwire.NewMSatFromSatoshis(anchorSize)
 	}
 
@@ -400,6 +427,10 @@ func NewChannelReservation(capacity, localFundingAmt btcutil.Amount,
 		chanType |= channeldb.SimpleTaprootFeatureBit
 	}
 
+	if req.CommitType == CommitmentTypeZeroFeeCommitments {
+		chanType |= channeldb.ZeroFeeCommitmentBit
+	}
+
 	if req.ZeroConf {
 		chanType |= channeldb.ZeroConfBit
 	}
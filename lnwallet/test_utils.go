@@ -252,10 +252,15 @@ func CreateTestChannels(t *testing.T, chanType channeldb.ChannelType,
 	}
 	commitFee := calcStaticFee(chanType, 0)
 	var anchorAmt btcutil.Amount
-	if chanType.HasAnchors() {
+	if chanType.HasAnchors() && !chanType.HasZeroFeeCommitment() {
 		anchorAmt += 2 * anchorSize
 	}
 
+	// Zero-fee commitments don't carry a fee rate.
+	if chanType.HasZeroFeeCommitment() {
+		feePerKw = 0
+	}
+
 	aliceBalance := lnwire.NewMSatFromSatoshis(
 		channelBal - commitFee - anchorAmt,
 	)
@@ -502,6 +507,10 @@ func txFromHex(txHex string) (*btcutil.Tx, error) {
 //
 // TODO(bvu): Refactor when dynamic fee estimation is added.
 func calcStaticFee(chanType channeldb.ChannelType, numHTLCs int) btcutil.Amount {
+	if chanType.HasZeroFeeCommitment() {
+		return 0
+	}
+
 	const (
 		htlcWeight = 172
 		feePerKw   = btcutil.Amount(24/4) * 1000
@@ -0,0 +1,415 @@
+package txtracker
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightningnetwork/lnd/labels"
+	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
+	"github.com/lightningnetwork/lnd/tlv"
+)
+
+// TxStatus describes what is known about a published transaction.
+type TxStatus uint8
+
+const (
+	// TxStatusPending means the transaction was accepted by the backend
+	// and is waiting to be confirmed.
+	TxStatusPending TxStatus = iota
+
+	// TxStatusRejected means the backend refused to accept the
+	// transaction into its mempool. The rejection reason is recorded with
+	// the transaction.
+	TxStatusRejected
+
+	// TxStatusReplaced means the transaction was replaced by another
+	// transaction we published that spends some of the same inputs.
+	TxStatusReplaced
+
+	// TxStatusDoubleSpent means one of the inputs of the transaction was
+	// spent by a confirmed transaction that we didn't publish.
+	TxStatusDoubleSpent
+
+	// TxStatusEvicted means the transaction was accepted by the backend
+	// before, but has since disappeared from its mempool without being
+	// confirmed. This can happen if it was evicted because of a low fee
+	// rate, expired, or was replaced by a transaction we don't know of.
+	TxStatusEvicted
+
+	// TxStatusConfirmed means the transaction was confirmed.
+	TxStatusConfirmed
+)
+
+// String returns a human readable version of the status.
+func (s TxStatus) String() string {
+	switch s {
+	case TxStatusPending:
+		return "Pending"
+
+	case TxStatusRejected:
+		return "Rejected"
+
+	case TxStatusReplaced:
+		return "Replaced"
+
+	case TxStatusDoubleSpent:
+		return "DoubleSpent"
+
+	case TxStatusEvicted:
+		return "Evicted"
+
+	case TxStatusConfirmed:
+		return "Confirmed"
+
+	default:
+		return fmt.Sprintf("Unknown(%d)", uint8(s))
+	}
+}
+
+// TxPurpose describes why lnd published a transaction. It is derived from the
+// label the transaction was published with.
+type TxPurpose uint8
+
+const (
+	// TxPurposeUnknown is used for transactions that were published
+	// without a label.
+	TxPurposeUnknown TxPurpose = iota
+
+	// TxPurposeUser is used for transactions published on behalf of the
+	// user, for example through SendCoins or PublishTransaction.
+	TxPurposeUser
+
+	// TxPurposeChannelOpen is used for channel funding transactions.
+	TxPurposeChannelOpen
+
+	// TxPurposeChannelClose is used for cooperative and force close
+	// transactions, as well as second level HTLC transactions.
+	TxPurposeChannelClose
+
+	// TxPurposeSweep is used for sweep transactions.
+	TxPurposeSweep
+
+	// TxPurposeJustice is used for justice transactions.
+	TxPurposeJustice
+)
+
+// String returns a human readable version of the purpose.
+func (p TxPurpose) String() string {
+	switch p {
+	case TxPurposeUnknown:
+		return "Unknown"
+
+	case TxPurposeUser:
+		return "User"
+
+	case TxPurposeChannelOpen:
+		return "ChannelOpen"
+
+	case TxPurposeChannelClose:
+		return "ChannelClose"
+
+	case TxPurposeSweep:
+		return "Sweep"
+
+	case TxPurposeJustice:
+		return "Justice"
+
+	default:
+		return fmt.Sprintf("Unknown(%d)", uint8(p))
+	}
+}
+
+// PurposeFromLabel derives the purpose of a transaction from the label it was
+// published with.
+func PurposeFromLabel(label string) TxPurpose {
+	if label == "" {
+		return TxPurposeUnknown
+	}
+
+	labelType, ok := labels.ParseLabelType(label)
+	if !ok {
+		return TxPurposeUser
+	}
+
+	switch labelType {
+	case labels.LabelTypeChannelOpen:
+		return TxPurposeChannelOpen
+
+	case labels.LabelTypeChannelClose:
+		return TxPurposeChannelClose
+
+	case labels.LabelTypeSweepTransaction:
+		return TxPurposeSweep
+
+	case labels.LabelTypeJusticeTransaction:
+		return TxPurposeJustice
+
+	default:
+		return TxPurposeUnknown
+	}
+}
+
+// PublishedTx is the record of a transaction published by lnd.
+type PublishedTx struct {
+	// Tx is the published transaction.
+	Tx *wire.MsgTx
+
+	// Label is the label the transaction was published with.
+	Label string
+
+	// Status is the current status of the transaction.
+	Status TxStatus
+
+	// Reason is the error returned by the backend the last time the
+	// transaction was published, if any. For rejected transactions this
+	// is the reason of the rejection.
+	Reason string
+
+	// Fee is the absolute fee paid by the transaction. It is zero if the
+	// value of some of the inputs could not be determined.
+	Fee btcutil.Amount
+
+	// FeeRate is the fee rate of the transaction. It is zero if the fee is
+	// unknown.
+	FeeRate chainfee.SatPerKWeight
+
+	// FirstPublished is the time the transaction was first published.
+	FirstPublished time.Time
+
+	// LastPublished is the time the transaction was last published.
+	LastPublished time.Time
+
+	// PublishAttempts is the number of times the transaction was
+	// published.
+	PublishAttempts uint32
+
+	// PublishHeight is the best block height at the time the transaction
+	// was accepted by the backend.
+	PublishHeight uint32
+
+	// ConfirmHeight is the height the transaction confirmed at, if it is
+	// confirmed.
+	ConfirmHeight uint32
+
+	// ReplacedBy is the hash of the transaction that replaced this one,
+	// if it was replaced.
+	ReplacedBy chainhash.Hash
+
+	// Replaces is the list of transactions that this transaction
+	// replaced.
+	Replaces []chainhash.Hash
+
+	// ConflictingTx is the hash of the confirmed transaction that spent
+	// an input of this transaction, if any. Once set, this transaction can
+	// no longer confirm.
+	ConflictingTx chainhash.Hash
+}
+
+// TxHash returns the hash of the published transaction.
+func (p *PublishedTx) TxHash() chainhash.Hash {
+	return p.Tx.TxHash()
+}
+
+// Purpose returns the purpose of the transaction.
+func (p *PublishedTx) Purpose() TxPurpose {
+	return PurposeFromLabel(p.Label)
+}
+
+// IsActive returns true if the fate of the transaction isn't known yet and the
+// transaction therefore needs to be watched.
+func (p *PublishedTx) IsActive() bool {
+	switch p.Status {
+	case TxStatusPending, TxStatusEvicted:
+		return true
+
+	// A replaced transaction can still confirm until the replacement
+	// itself confirms.
+	case TxStatusReplaced:
+		return p.ConflictingTx == chainhash.Hash{}
+
+	default:
+		return false
+	}
+}
+
+// Copy returns a deep copy of the record.
+func (p *PublishedTx) Copy() *PublishedTx {
+	cp := *p
+	cp.Tx = p.Tx.Copy()
+	cp.Replaces = append([]chainhash.Hash(nil), p.Replaces...)
+
+	return &cp
+}
+
+const (
+	txRecordType              tlv.Type = 0
+	labelRecordType           tlv.Type = 1
+	statusRecordType          tlv.Type = 2
+	reasonRecordType          tlv.Type = 3
+	feeRecordType             tlv.Type = 4
+	feeRateRecordType         tlv.Type = 5
+	firstPublishedRecordType  tlv.Type = 6
+	lastPublishedRecordType   tlv.Type = 7
+	publishAttemptsRecordType tlv.Type = 8
+	publishHeightRecordType   tlv.Type = 9
+	confirmHeightRecordType   tlv.Type = 10
+	replacedByRecordType      tlv.Type = 11
+	replacesRecordType        tlv.Type = 12
+	conflictingTxRecordType   tlv.Type = 13
+)
+
+// serializePublishedTx encodes the record as a TLV stream.
+func serializePublishedTx(w io.Writer, p *PublishedTx) error {
+	var txBuf bytes.Buffer
+	if err := p.Tx.Serialize(&txBuf); err != nil {
+		return err
+	}
+
+	var (
+		txBytes         = txBuf.Bytes()
+		label           = []byte(p.Label)
+		status          = uint8(p.Status)
+		reason          = []byte(p.Reason)
+		fee             = uint64(p.Fee)
+		feeRate         = uint64(p.FeeRate)
+		firstPublished  = uint64(p.FirstPublished.UnixNano())
+		lastPublished   = uint64(p.LastPublished.UnixNano())
+		publishAttempts = p.PublishAttempts
+		publishHeight   = p.PublishHeight
+		confirmHeight   = p.ConfirmHeight
+		replacedBy      = [32]byte(p.ReplacedBy)
+		replaces        = make([]byte, 0, len(p.Replaces)*32)
+		conflictingTx   = [32]byte(p.ConflictingTx)
+	)
+	for _, txid := range p.Replaces {
+		replaces = append(replaces, txid[:]...)
+	}
+
+	tlvStream, err := tlv.NewStream(
+		tlv.MakePrimitiveRecord(txRecordType, &txBytes),
+		tlv.MakePrimitiveRecord(labelRecordType, &label),
+		tlv.MakePrimitiveRecord(statusRecordType, &status),
+		tlv.MakePrimitiveRecord(reasonRecordType, &reason),
+		tlv.MakePrimitiveRecord(feeRecordType, &fee),
+		tlv.MakePrimitiveRecord(feeRateRecordType, &feeRate),
+		tlv.MakePrimitiveRecord(
+			firstPublishedRecordType, &firstPublished,
+		),
+		tlv.MakePrimitiveRecord(
+			lastPublishedRecordType, &lastPublished,
+		),
+		tlv.MakePrimitiveRecord(
+			publishAttemptsRecordType, &publishAttempts,
+		),
+		tlv.MakePrimitiveRecord(
+			publishHeightRecordType, &publishHeight,
+		),
+		tlv.MakePrimitiveRecord(
+			confirmHeightRecordType, &confirmHeight,
+		),
+		tlv.MakePrimitiveRecord(replacedByRecordType, &replacedBy),
+		tlv.MakePrimitiveRecord(replacesRecordType, &replaces),
+		tlv.MakePrimitiveRecord(
+			conflictingTxRecordType, &conflictingTx,
+		),
+	)
+	if err != nil {
+		return err
+	}
+
+	return tlvStream.Encode(w)
+}
+
+// deserializePublishedTx decodes a record from a TLV stream.
+func deserializePublishedTx(r io.Reader) (*PublishedTx, error) {
+	var (
+		txBytes         []byte
+		label           []byte
+		status          uint8
+		reason          []byte
+		fee             uint64
+		feeRate         uint64
+		firstPublished  uint64
+		lastPublished   uint64
+		publishAttempts uint32
+		publishHeight   uint32
+		confirmHeight   uint32
+		replacedBy      [32]byte
+		replaces        []byte
+		conflictingTx   [32]byte
+	)
+
+	tlvStream, err := tlv.NewStream(
+		tlv.MakePrimitiveRecord(txRecordType, &txBytes),
+		tlv.MakePrimitiveRecord(labelRecordType, &label),
+		tlv.MakePrimitiveRecord(statusRecordType, &status),
+		tlv.MakePrimitiveRecord(reasonRecordType, &reason),
+		tlv.MakePrimitiveRecord(feeRecordType, &fee),
+		tlv.MakePrimitiveRecord(feeRateRecordType, &feeRate),
+		tlv.MakePrimitiveRecord(
+			firstPublishedRecordType, &firstPublished,
+		),
+		tlv.MakePrimitiveRecord(
+			lastPublishedRecordType, &lastPublished,
+		),
+		tlv.MakePrimitiveRecord(
+			publishAttemptsRecordType, &publishAttempts,
+		),
+		tlv.MakePrimitiveRecord(
+			publishHeightRecordType, &publishHeight,
+		),
+		tlv.MakePrimitiveRecord(
+			confirmHeightRecordType, &confirmHeight,
+		),
+		tlv.MakePrimitiveRecord(replacedByRecordType, &replacedBy),
+		tlv.MakePrimitiveRecord(replacesRecordType, &replaces),
+		tlv.MakePrimitiveRecord(
+			conflictingTxRecordType, &conflictingTx,
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tlvStream.Decode(r); err != nil {
+		return nil, err
+	}
+
+	tx := &wire.MsgTx{}
+	if err := tx.Deserialize(bytes.NewReader(txBytes)); err != nil {
+		return nil, err
+	}
+
+	if len(replaces)%chainhash.HashSize != 0 {
+		return nil, fmt.Errorf("invalid replaced tx list length %d",
+			len(replaces))
+	}
+
+	p := &PublishedTx{
+		Tx:              tx,
+		Label:           string(label),
+		Status:          TxStatus(status),
+		Reason:          string(reason),
+		Fee:             btcutil.Amount(fee),
+		FeeRate:         chainfee.SatPerKWeight(feeRate),
+		FirstPublished:  time.Unix(0, int64(firstPublished)),
+		LastPublished:   time.Unix(0, int64(lastPublished)),
+		PublishAttempts: publishAttempts,
+		PublishHeight:   publishHeight,
+		ConfirmHeight:   confirmHeight,
+		ReplacedBy:      replacedBy,
+		ConflictingTx:   conflictingTx,
+	}
+	for i := 0; i < len(replaces); i += chainhash.HashSize {
+		var txid chainhash.Hash
+		copy(txid[:], replaces[i:i+chainhash.HashSize])
+		p.Replaces = append(p.Replaces, txid)
+	}
+
+	return p, nil
+}
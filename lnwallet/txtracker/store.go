@@ -0,0 +1,167 @@
+package txtracker
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/lightningnetwork/lnd/kvdb"
+)
+
+var (
+	// publishedTxBucketKey is the key of the top level bucket that holds
+	// the records of all transactions published by lnd.
+	//
+	// maps: txid -> TLV encoded PublishedTx
+	publishedTxBucketKey = []byte("published-txs")
+
+	// ErrTxNotFound is returned when a transaction isn't known to the
+	// store.
+	ErrTxNotFound = errors.New("published transaction not found")
+
+	// errNoPublishedTxBucket is returned when the published transactions
+	// bucket doesn't exist.
+	errNoPublishedTxBucket = errors.New("published transactions bucket " +
+		"does not exist")
+)
+
+// Store persists the records of published transactions.
+type Store interface {
+	// PutTx adds or updates the record of a published transaction.
+	PutTx(p *PublishedTx) error
+
+	// FetchTx returns the record of the transaction with the given hash.
+	// ErrTxNotFound is returned if the transaction is unknown.
+	FetchTx(txid chainhash.Hash) (*PublishedTx, error)
+
+	// FetchTxs returns the records of all published transactions.
+	FetchTxs() ([]*PublishedTx, error)
+
+	// DeleteTxs removes the records of the transactions with the given
+	// hashes. Unknown transactions are ignored.
+	DeleteTxs(txids ...chainhash.Hash) error
+}
+
+// kvStore is a Store that is backed by a kvdb backend.
+type kvStore struct {
+	db kvdb.Backend
+}
+
+// A compile-time check to ensure kvStore implements the Store interface.
+var _ Store = (*kvStore)(nil)
+
+// NewStore returns a new Store backed by the given database.
+func NewStore(db kvdb.Backend) (Store, error) {
+	err := kvdb.Update(db, func(tx kvdb.RwTx) error {
+		_, err := tx.CreateTopLevelBucket(publishedTxBucketKey)
+		return err
+	}, func() {})
+	if err != nil {
+		return nil, err
+	}
+
+	return &kvStore{
+		db: db,
+	}, nil
+}
+
+// PutTx adds or updates the record of a published transaction.
+//
+// NOTE: Part of the Store interface.
+func (s *kvStore) PutTx(p *PublishedTx) error {
+	var b bytes.Buffer
+	if err := serializePublishedTx(&b, p); err != nil {
+		return err
+	}
+	txid := p.TxHash()
+
+	return kvdb.Update(s.db, func(tx kvdb.RwTx) error {
+		bucket := tx.ReadWriteBucket(publishedTxBucketKey)
+		if bucket == nil {
+			return errNoPublishedTxBucket
+		}
+
+		return bucket.Put(txid[:], b.Bytes())
+	}, func() {})
+}
+
+// FetchTx returns the record of the transaction with the given hash.
+//
+// NOTE: Part of the Store interface.
+func (s *kvStore) FetchTx(txid chainhash.Hash) (*PublishedTx, error) {
+	var p *PublishedTx
+	err := kvdb.View(s.db, func(tx kvdb.RTx) error {
+		bucket := tx.ReadBucket(publishedTxBucketKey)
+		if bucket == nil {
+			return errNoPublishedTxBucket
+		}
+
+		v := bucket.Get(txid[:])
+		if v == nil {
+			return ErrTxNotFound
+		}
+
+		var err error
+		p, err = deserializePublishedTx(bytes.NewReader(v))
+
+		return err
+	}, func() {
+		p = nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// FetchTxs returns the records of all published transactions.
+//
+// NOTE: Part of the Store interface.
+func (s *kvStore) FetchTxs() ([]*PublishedTx, error) {
+	var txs []*PublishedTx
+	err := kvdb.View(s.db, func(tx kvdb.RTx) error {
+		bucket := tx.ReadBucket(publishedTxBucketKey)
+		if bucket == nil {
+			return errNoPublishedTxBucket
+		}
+
+		return bucket.ForEach(func(_, v []byte) error {
+			p, err := deserializePublishedTx(bytes.NewReader(v))
+			if err != nil {
+				return err
+			}
+
+			txs = append(txs, p)
+
+			return nil
+		})
+	}, func() {
+		txs = nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return txs, nil
+}
+
+// DeleteTxs removes the records of the transactions with the given hashes.
+//
+// NOTE: Part of the Store interface.
+func (s *kvStore) DeleteTxs(txids ...chainhash.Hash) error {
+	return kvdb.Update(s.db, func(tx kvdb.RwTx) error {
+		bucket := tx.ReadWriteBucket(publishedTxBucketKey)
+		if bucket == nil {
+			return errNoPublishedTxBucket
+		}
+
+		for _, txid := range txids {
+			if err := bucket.Delete(txid[:]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, func() {})
+}
@@ -0,0 +1,857 @@
+package txtracker
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightningnetwork/lnd/chainntnfs"
+	"github.com/lightningnetwork/lnd/clock"
+	"github.com/lightningnetwork/lnd/lnwallet"
+	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
+	"github.com/lightningnetwork/lnd/subscribe"
+)
+
+// DefaultPruneAfter is the default time after its last publication that the
+// record of a confirmed, replaced or double spent transaction is kept.
+const DefaultPruneAfter = 30 * 24 * time.Hour
+
+// Config holds the dependencies of the Tracker.
+type Config struct {
+	// Store persists the records of the published transactions.
+	Store Store
+
+	// Notifier is used to watch for confirmations and conflicting spends
+	// of the published transactions.
+	Notifier chainntnfs.ChainNotifier
+
+	// BestHeight returns the current best block height.
+	BestHeight func() (uint32, error)
+
+	// FetchPrevOutput returns the output spent by the given outpoint. It
+	// is used to determine the fee of a published transaction and to watch
+	// its inputs for conflicting spends. Outputs of transactions that were
+	// published through the tracker don't need to be returned, as they are
+	// already known to the tracker.
+	FetchPrevOutput func(op wire.OutPoint) (*wire.TxOut, error)
+
+	// InMempool returns true if the transaction with the given hash is in
+	// the mempool of the backend. If nil, mempool evictions aren't
+	// tracked.
+	InMempool func(txid chainhash.Hash) (bool, error)
+
+	// CancelRebroadcast is called to stop rebroadcasting a transaction
+	// once it was replaced or double spent. It is optional.
+	CancelRebroadcast func(txid chainhash.Hash)
+
+	// Clock is the clock used to timestamp publications.
+	Clock clock.Clock
+
+	// PruneAfter is the time after its last publication that the record
+	// of a transaction that confirmed, was replaced or was double spent is
+	// removed from the store. Zero disables pruning.
+	PruneAfter time.Duration
+}
+
+// publishEvent is a publication attempt that is yet to be recorded.
+type publishEvent struct {
+	tx         *wire.MsgTx
+	label      string
+	publishErr error
+	timestamp  time.Time
+}
+
+// Tracker records every transaction published through the wallet, together
+// with the outcome of the publication, and follows what happens to it
+// afterwards: whether it confirms, is replaced by another transaction we
+// publish, is double spent by a transaction we don't know of or disappears
+// from the mempool of the backend.
+type Tracker struct {
+	started sync.Once
+	stopped sync.Once
+
+	cfg *Config
+
+	ntfnServer *subscribe.Server
+
+	mu sync.Mutex
+
+	// running is true once the tracker was started. Transactions
+	// published before that are only queued, and recorded and watched
+	// once the tracker starts.
+	running bool
+
+	// active holds the records of all transactions whose fate isn't known
+	// yet.
+	active map[chainhash.Hash]*PublishedTx
+
+	// spenders maps the inputs of the active transactions to the hash of
+	// the transaction that most recently spent them.
+	spenders map[wire.OutPoint]chainhash.Hash
+
+	// missing holds the pending transactions that weren't found in the
+	// mempool at the last check. A transaction is only considered evicted
+	// if it is still missing at the next check, which avoids flagging
+	// transactions that confirmed in the block that triggered the check.
+	missing map[chainhash.Hash]struct{}
+
+	// watched holds the transactions for which a goroutine watches for
+	// confirmation and conflicting spends.
+	watched map[chainhash.Hash]struct{}
+
+	// queueMtx guards pendingPublishes.
+	queueMtx sync.Mutex
+
+	// pendingPublishes holds the publication attempts that are yet to be
+	// recorded. They are recorded by the publish handler, so publishing a
+	// transaction never waits for the tracker or its store.
+	pendingPublishes []*publishEvent
+
+	// publishSignal is signaled whenever a publication attempt is queued.
+	publishSignal chan struct{}
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// A compile-time check to ensure Tracker implements the PublishTracker
+// interface.
+var _ lnwallet.PublishTracker = (*Tracker)(nil)
+
+// New creates a new transaction tracker.
+func New(cfg *Config) *Tracker {
+	return &Tracker{
+		cfg:           cfg,
+		ntfnServer:    subscribe.NewServer(),
+		active:        make(map[chainhash.Hash]*PublishedTx),
+		spenders:      make(map[wire.OutPoint]chainhash.Hash),
+		missing:       make(map[chainhash.Hash]struct{}),
+		watched:       make(map[chainhash.Hash]struct{}),
+		publishSignal: make(chan struct{}, 1),
+		quit:          make(chan struct{}),
+	}
+}
+
+// Start loads the transactions whose fate isn't known yet from the store and
+// starts watching them.
+func (t *Tracker) Start() error {
+	var startErr error
+	t.started.Do(func() {
+		startErr = t.start()
+	})
+
+	return startErr
+}
+
+// start launches the goroutines of the tracker.
+func (t *Tracker) start() error {
+	log.Info("Transaction tracker starting")
+
+	if err := t.ntfnServer.Start(); err != nil {
+		return err
+	}
+
+	txs, err := t.cfg.Store.FetchTxs()
+	if err != nil {
+		return err
+	}
+
+	blockEpochs, err := t.cfg.Notifier.RegisterBlockEpochNtfn(nil)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	for _, p := range txs {
+		txid := p.TxHash()
+		if _, ok := t.active[txid]; ok || !p.IsActive() {
+			continue
+		}
+
+		t.active[txid] = p
+		for _, txIn := range p.Tx.TxIn {
+			t.spenders[txIn.PreviousOutPoint] = txid
+		}
+	}
+
+	t.running = true
+	for _, p := range t.active {
+		t.watchTx(p)
+	}
+	t.mu.Unlock()
+
+	// Record the transactions that were published before we started, and
+	// drop the records that are no longer needed.
+	t.recordPublishes()
+	t.prune()
+
+	t.wg.Add(2)
+	go t.blockHandler(blockEpochs)
+	go t.publishHandler()
+
+	return nil
+}
+
+// Stop stops all goroutines of the tracker.
+func (t *Tracker) Stop() error {
+	var stopErr error
+	t.stopped.Do(func() {
+		log.Info("Transaction tracker shutting down...")
+		defer log.Debug("Transaction tracker shutdown complete")
+
+		close(t.quit)
+		t.wg.Wait()
+
+		stopErr = t.ntfnServer.Stop()
+	})
+
+	return stopErr
+}
+
+// TrackPublish records an attempt to publish the given transaction with the
+// given label. The attempt is queued and recorded asynchronously, so the
+// caller isn't held up by the store.
+//
+// NOTE: Part of the lnwallet.PublishTracker interface.
+func (t *Tracker) TrackPublish(tx *wire.MsgTx, label string,
+	publishErr error) {
+
+	select {
+	case <-t.quit:
+		return
+	default:
+	}
+
+	t.queueMtx.Lock()
+	t.pendingPublishes = append(t.pendingPublishes, &publishEvent{
+		tx:         tx.Copy(),
+		label:      label,
+		publishErr: publishErr,
+		timestamp:  t.cfg.Clock.Now(),
+	})
+	t.queueMtx.Unlock()
+
+	select {
+	case t.publishSignal <- struct{}{}:
+	default:
+	}
+}
+
+// publishHandler records the queued publication attempts.
+//
+// NOTE: MUST be run as a goroutine.
+func (t *Tracker) publishHandler() {
+	defer t.wg.Done()
+
+	for {
+		select {
+		case <-t.publishSignal:
+			t.recordPublishes()
+
+		case <-t.quit:
+			// Record what was published up to now, so we don't
+			// lose track of it over a restart.
+			t.recordPublishes()
+
+			return
+		}
+	}
+}
+
+// recordPublishes records all queued publication attempts in the order they
+// were made.
+func (t *Tracker) recordPublishes() {
+	t.queueMtx.Lock()
+	events := t.pendingPublishes
+	t.pendingPublishes = nil
+	t.queueMtx.Unlock()
+
+	for _, event := range events {
+		t.recordPublish(event)
+	}
+}
+
+// recordPublish records a single publication attempt.
+func (t *Tracker) recordPublish(event *publishEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tx, label, publishErr := event.tx, event.label, event.publishErr
+	txid := tx.TxHash()
+	now := event.timestamp
+
+	p, ok := t.active[txid]
+	if !ok {
+		var err error
+		p, err = t.cfg.Store.FetchTx(txid)
+		switch {
+		case errors.Is(err, ErrTxNotFound):
+			p = &PublishedTx{
+				Tx:             tx,
+				Status:         TxStatusRejected,
+				FirstPublished: now,
+			}
+			p.Fee, p.FeeRate = t.txFee(tx)
+
+		case err != nil:
+			log.Errorf("Unable to fetch published tx %v: %v", txid,
+				err)
+			return
+		}
+	}
+
+	if p.Label == "" {
+		p.Label = label
+	}
+	p.PublishAttempts++
+	p.LastPublished = now
+
+	// If the backend refused the transaction, we record the reason. A
+	// transaction that was accepted before keeps its status, as this is
+	// usually a rebroadcast that was refused because the transaction is
+	// already known.
+	if publishErr != nil {
+		log.Debugf("Publishing tx %v (%v) failed: %v", txid,
+			p.Purpose(), publishErr)
+
+		p.Reason = publishErr.Error()
+		t.updateTx(p)
+
+		return
+	}
+
+	p.Reason = ""
+	switch p.Status {
+	case TxStatusRejected, TxStatusEvicted, TxStatusReplaced:
+		p.Status = TxStatusPending
+		p.ReplacedBy = chainhash.Hash{}
+		p.ConflictingTx = chainhash.Hash{}
+		p.PublishHeight = t.bestHeight()
+
+	// The transaction is already confirmed or can't confirm anymore,
+	// there's nothing left to track.
+	case TxStatusConfirmed, TxStatusDoubleSpent:
+		t.updateTx(p)
+		return
+	}
+
+	// Any active transaction that spends one of our inputs was replaced
+	// by this transaction.
+	for _, txIn := range tx.TxIn {
+		op := txIn.PreviousOutPoint
+		otherID, ok := t.spenders[op]
+		if ok && otherID != txid {
+			t.markReplaced(otherID, p)
+		}
+
+		t.spenders[op] = txid
+	}
+
+	log.Debugf("Tracking published tx %v (%v) with fee rate %v", txid,
+		p.Purpose(), p.FeeRate)
+
+	t.active[txid] = p
+	delete(t.missing, txid)
+	if t.running {
+		t.watchTx(p)
+	}
+
+	t.updateTx(p)
+}
+
+// markReplaced marks the active transaction with the given hash as replaced
+// by the given transaction.
+//
+// NOTE: The mutex MUST be held when calling this method.
+func (t *Tracker) markReplaced(txid chainhash.Hash, replacement *PublishedTx) {
+	p, ok := t.active[txid]
+	if !ok || p.Status == TxStatusReplaced {
+		return
+	}
+
+	replacementID := replacement.TxHash()
+
+	log.Infof("Published tx %v (%v) was replaced by %v", txid,
+		p.Purpose(), replacementID)
+
+	p.Status = TxStatusReplaced
+	p.ReplacedBy = replacementID
+	delete(t.missing, txid)
+
+	var known bool
+	for _, replaced := range replacement.Replaces {
+		if replaced == txid {
+			known = true
+			break
+		}
+	}
+	if !known {
+		replacement.Replaces = append(replacement.Replaces, txid)
+	}
+
+	if t.cfg.CancelRebroadcast != nil {
+		t.cfg.CancelRebroadcast(txid)
+	}
+
+	t.updateTx(p)
+}
+
+// updateTx persists the given record and notifies the subscribers.
+//
+// NOTE: The mutex MUST be held when calling this method.
+func (t *Tracker) updateTx(p *PublishedTx) {
+	if err := t.cfg.Store.PutTx(p); err != nil {
+		log.Errorf("Unable to store published tx %v: %v", p.TxHash(),
+			err)
+	}
+
+	// The notification server only runs once we're started.
+	if !t.running {
+		return
+	}
+
+	if err := t.ntfnServer.SendUpdate(p.Copy()); err != nil {
+		log.Warnf("Unable to send published tx update: %v", err)
+	}
+}
+
+// bestHeight returns the current best height, or zero if it's unknown.
+func (t *Tracker) bestHeight() uint32 {
+	height, err := t.cfg.BestHeight()
+	if err != nil {
+		log.Warnf("Unable to fetch best height: %v", err)
+		return 0
+	}
+
+	return height
+}
+
+// prevOutput returns the output spent by the given outpoint.
+//
+// NOTE: The mutex MUST be held when calling this method.
+func (t *Tracker) prevOutput(op wire.OutPoint) (*wire.TxOut, error) {
+	// The output may belong to a transaction we published ourselves, for
+	// example a sweep of a commitment output.
+	prevTx, ok := t.active[op.Hash]
+	if !ok {
+		var err error
+		prevTx, err = t.cfg.Store.FetchTx(op.Hash)
+		if err != nil && !errors.Is(err, ErrTxNotFound) {
+			return nil, err
+		}
+	}
+	if prevTx != nil && int(op.Index) < len(prevTx.Tx.TxOut) {
+		return prevTx.Tx.TxOut[op.Index], nil
+	}
+
+	return t.cfg.FetchPrevOutput(op)
+}
+
+// txFee returns the fee and fee rate of the given transaction. Zero is
+// returned if the value of some of its inputs is unknown.
+//
+// NOTE: The mutex MUST be held when calling this method.
+func (t *Tracker) txFee(tx *wire.MsgTx) (btcutil.Amount,
+	chainfee.SatPerKWeight) {
+
+	var inputTotal, outputTotal btcutil.Amount
+	for _, txIn := range tx.TxIn {
+		prevOut, err := t.prevOutput(txIn.PreviousOutPoint)
+		if err != nil {
+			log.Debugf("Unable to determine fee of tx %v: %v",
+				tx.TxHash(), err)
+
+			return 0, 0
+		}
+
+		inputTotal += btcutil.Amount(prevOut.Value)
+	}
+	for _, txOut := range tx.TxOut {
+		outputTotal += btcutil.Amount(txOut.Value)
+	}
+
+	fee := inputTotal - outputTotal
+	weight := blockchain.GetTransactionWeight(btcutil.NewTx(tx))
+	if fee < 0 || weight == 0 {
+		return 0, 0
+	}
+
+	return fee, chainfee.SatPerKWeight(int64(fee) * 1000 / weight)
+}
+
+// watchTx launches a goroutine that watches the given transaction for its
+// confirmation and for conflicting spends of its inputs.
+//
+// NOTE: The mutex MUST be held when calling this method.
+func (t *Tracker) watchTx(p *PublishedTx) {
+	txid := p.TxHash()
+	if _, ok := t.watched[txid]; ok {
+		return
+	}
+	t.watched[txid] = struct{}{}
+
+	heightHint := p.PublishHeight
+	if heightHint == 0 {
+		heightHint = t.bestHeight()
+	}
+
+	// We watch all inputs whose script we know for conflicting spends.
+	prevOuts := make(map[wire.OutPoint]*wire.TxOut, len(p.Tx.TxIn))
+	for _, txIn := range p.Tx.TxIn {
+		op := txIn.PreviousOutPoint
+		prevOut, err := t.prevOutput(op)
+		if err != nil {
+			log.Debugf("Unable to watch input %v of tx %v for "+
+				"conflicts: %v", op, txid, err)
+			continue
+		}
+
+		prevOuts[op] = prevOut
+	}
+
+	t.wg.Add(1)
+	go t.monitorTx(txid, p.Tx, heightHint, prevOuts)
+}
+
+// monitorTx waits for the given transaction to confirm or for any of its
+// inputs to be spent by another transaction.
+//
+// NOTE: MUST be run as a goroutine.
+func (t *Tracker) monitorTx(txid chainhash.Hash, tx *wire.MsgTx,
+	heightHint uint32, prevOuts map[wire.OutPoint]*wire.TxOut) {
+
+	defer t.wg.Done()
+	defer func() {
+		t.mu.Lock()
+		delete(t.watched, txid)
+		t.mu.Unlock()
+	}()
+
+	var pkScript []byte
+	if len(tx.TxOut) > 0 {
+		pkScript = tx.TxOut[0].PkScript
+	}
+	confNtfn, err := t.cfg.Notifier.RegisterConfirmationsNtfn(
+		&txid, pkScript, 1, heightHint,
+	)
+	if err != nil {
+		log.Errorf("Unable to register confirmation notification for "+
+			"tx %v: %v", txid, err)
+		return
+	}
+	defer confNtfn.Cancel()
+
+	done := make(chan struct{})
+	defer close(done)
+
+	spends := make(chan *chainntnfs.SpendDetail)
+	for op, prevOut := range prevOuts {
+		op := op
+
+		spendNtfn, err := t.cfg.Notifier.RegisterSpendNtfn(
+			&op, prevOut.PkScript, heightHint,
+		)
+		if err != nil {
+			log.Errorf("Unable to register spend notification "+
+				"for input %v of tx %v: %v", op, txid, err)
+			continue
+		}
+		defer spendNtfn.Cancel()
+
+		t.wg.Add(1)
+		go func() {
+			defer t.wg.Done()
+
+			select {
+			case spend, ok := <-spendNtfn.Spend:
+				if !ok {
+					return
+				}
+
+				select {
+				case spends <- spend:
+				case <-done:
+				}
+
+			case <-done:
+			}
+		}()
+	}
+
+	for {
+		select {
+		case conf, ok := <-confNtfn.Confirmed:
+			if !ok {
+				return
+			}
+
+			t.markConfirmed(txid, conf.BlockHeight)
+
+			return
+
+		case spend := <-spends:
+			// Our own transaction spending the input will be
+			// handled by the confirmation notification.
+			if *spend.SpenderTxHash == txid {
+				continue
+			}
+
+			t.markConflict(txid, *spend.SpenderTxHash)
+
+			return
+
+		case <-t.quit:
+			return
+		}
+	}
+}
+
+// markConfirmed marks the transaction with the given hash as confirmed.
+func (t *Tracker) markConfirmed(txid chainhash.Hash, height uint32) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p, ok := t.active[txid]
+	if !ok {
+		return
+	}
+
+	log.Infof("Published tx %v (%v) confirmed at height %v", txid,
+		p.Purpose(), height)
+
+	p.Status = TxStatusConfirmed
+	p.ConfirmHeight = height
+	t.removeActive(p)
+	t.updateTx(p)
+}
+
+// markConflict marks the transaction with the given hash as conflicted by the
+// given confirmed transaction. If the conflicting transaction was published
+// by us, the transaction was replaced, otherwise it was double spent.
+func (t *Tracker) markConflict(txid, conflictID chainhash.Hash) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p, ok := t.active[txid]
+	if !ok {
+		return
+	}
+
+	_, ours := t.active[conflictID]
+	if !ours {
+		_, err := t.cfg.Store.FetchTx(conflictID)
+		ours = err == nil
+	}
+
+	if ours {
+		log.Infof("Published tx %v (%v) was replaced by confirmed "+
+			"tx %v", txid, p.Purpose(), conflictID)
+
+		p.Status = TxStatusReplaced
+		p.ReplacedBy = conflictID
+	} else {
+		log.Warnf("Published tx %v (%v) was double spent by "+
+			"confirmed tx %v", txid, p.Purpose(), conflictID)
+
+		p.Status = TxStatusDoubleSpent
+	}
+	p.ConflictingTx = conflictID
+
+	if t.cfg.CancelRebroadcast != nil {
+		t.cfg.CancelRebroadcast(txid)
+	}
+
+	t.removeActive(p)
+	t.updateTx(p)
+}
+
+// removeActive stops tracking the given transaction.
+//
+// NOTE: The mutex MUST be held when calling this method.
+func (t *Tracker) removeActive(p *PublishedTx) {
+	txid := p.TxHash()
+
+	delete(t.active, txid)
+	delete(t.missing, txid)
+	for _, txIn := range p.Tx.TxIn {
+		op := txIn.PreviousOutPoint
+		if t.spenders[op] == txid {
+			delete(t.spenders, op)
+		}
+	}
+}
+
+// blockHandler checks on every new block whether the pending transactions are
+// still in the mempool of the backend.
+//
+// NOTE: MUST be run as a goroutine.
+func (t *Tracker) blockHandler(blockEpochs *chainntnfs.BlockEpochEvent) {
+	defer t.wg.Done()
+	defer blockEpochs.Cancel()
+
+	for {
+		select {
+		case _, ok := <-blockEpochs.Epochs:
+			if !ok {
+				return
+			}
+
+			t.checkMempool()
+			t.prune()
+
+		case <-t.quit:
+			return
+		}
+	}
+}
+
+// checkMempool updates the status of the pending and evicted transactions
+// depending on whether they are in the mempool of the backend.
+func (t *Tracker) checkMempool() {
+	if t.cfg.InMempool == nil {
+		return
+	}
+
+	t.mu.Lock()
+	var candidates []chainhash.Hash
+	for txid, p := range t.active {
+		switch p.Status {
+		case TxStatusPending, TxStatusEvicted:
+			candidates = append(candidates, txid)
+		}
+	}
+	t.mu.Unlock()
+
+	for _, txid := range candidates {
+		inMempool, err := t.cfg.InMempool(txid)
+		if err != nil {
+			log.Warnf("Unable to look up tx %v in mempool: %v",
+				txid, err)
+			continue
+		}
+
+		t.updateMempoolStatus(txid, inMempool)
+	}
+}
+
+// updateMempoolStatus updates the status of the transaction with the given
+// hash depending on whether it is in the mempool of the backend.
+func (t *Tracker) updateMempoolStatus(txid chainhash.Hash, inMempool bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p, ok := t.active[txid]
+	if !ok {
+		return
+	}
+
+	switch {
+	case inMempool:
+		delete(t.missing, txid)
+		if p.Status == TxStatusEvicted {
+			log.Infof("Published tx %v (%v) is back in the "+
+				"mempool", txid, p.Purpose())
+
+			p.Status = TxStatusPending
+			t.updateTx(p)
+		}
+
+	case p.Status == TxStatusPending:
+		if _, ok := t.missing[txid]; !ok {
+			t.missing[txid] = struct{}{}
+			return
+		}
+
+		log.Warnf("Published tx %v (%v) was evicted from the mempool",
+			txid, p.Purpose())
+
+		delete(t.missing, txid)
+		p.Status = TxStatusEvicted
+		t.updateTx(p)
+	}
+}
+
+// prune removes the records of the transactions that confirmed, were
+// replaced or were double spent, once their last publication is older than
+// the configured retention.
+func (t *Tracker) prune() {
+	if t.cfg.PruneAfter == 0 {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	txs, err := t.cfg.Store.FetchTxs()
+	if err != nil {
+		log.Errorf("Unable to fetch published txs for pruning: %v", err)
+		return
+	}
+
+	cutoff := t.cfg.Clock.Now().Add(-t.cfg.PruneAfter)
+
+	var stale []chainhash.Hash
+	for _, p := range txs {
+		// Rejected transactions may still be published again, and
+		// active ones are still watched.
+		if p.IsActive() || p.Status == TxStatusRejected ||
+			!p.LastPublished.Before(cutoff) {
+
+			continue
+		}
+
+		stale = append(stale, p.TxHash())
+	}
+
+	if len(stale) == 0 {
+		return
+	}
+
+	if err := t.cfg.Store.DeleteTxs(stale...); err != nil {
+		log.Errorf("Unable to prune published txs: %v", err)
+		return
+	}
+
+	log.Debugf("Pruned %d published txs", len(stale))
+}
+
+// PublishedTxs returns the records of the transactions published through the
+// tracker, ordered by the time they were first published. If activeOnly is
+// set, only the transactions whose fate isn't known yet are returned.
+func (t *Tracker) PublishedTxs(activeOnly bool) ([]*PublishedTx, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	txs, err := t.cfg.Store.FetchTxs()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*PublishedTx, 0, len(txs))
+	for _, p := range txs {
+		if activeOnly && !p.IsActive() {
+			continue
+		}
+
+		result = append(result, p)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].FirstPublished.Before(
+			result[j].FirstPublished,
+		)
+	})
+
+	return result, nil
+}
+
+// SubscribeTxUpdates returns a subscription client that receives a
+// *PublishedTx every time the record of a published transaction changes.
+func (t *Tracker) SubscribeTxUpdates() (*subscribe.Client, error) {
+	return t.ntfnServer.Subscribe()
+}
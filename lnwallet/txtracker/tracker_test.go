@@ -0,0 +1,477 @@
+package txtracker
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightningnetwork/lnd/chainntnfs"
+	"github.com/lightningnetwork/lnd/clock"
+	"github.com/lightningnetwork/lnd/kvdb"
+	"github.com/lightningnetwork/lnd/lnwallet"
+	"github.com/lightningnetwork/lnd/subscribe"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testHeight = 100
+
+	// testInputValue is the value of every output returned by the test
+	// FetchPrevOutput.
+	testInputValue = 100_000
+)
+
+var (
+	testTime = time.Unix(1_700_000_000, 0)
+
+	testPkScript = []byte{0x00, 0x14, 0x01, 0x02, 0x03}
+)
+
+// mockNotifier is a chain notifier that hands out a separate notification
+// channel for every confirmation and spend registration.
+type mockNotifier struct {
+	mu     sync.Mutex
+	confs  map[chainhash.Hash]chan *chainntnfs.TxConfirmation
+	spends map[wire.OutPoint][]chan *chainntnfs.SpendDetail
+	epochs chan *chainntnfs.BlockEpoch
+}
+
+func newMockNotifier() *mockNotifier {
+	return &mockNotifier{
+		confs: make(map[chainhash.Hash]chan *chainntnfs.TxConfirmation),
+		spends: make(
+			map[wire.OutPoint][]chan *chainntnfs.SpendDetail,
+		),
+		epochs: make(chan *chainntnfs.BlockEpoch),
+	}
+}
+
+func (m *mockNotifier) RegisterConfirmationsNtfn(txid *chainhash.Hash,
+	_ []byte, _, _ uint32,
+	_ ...chainntnfs.NotifierOption) (*chainntnfs.ConfirmationEvent, error) {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c := make(chan *chainntnfs.TxConfirmation, 1)
+	m.confs[*txid] = c
+
+	return &chainntnfs.ConfirmationEvent{
+		Confirmed: c,
+		Cancel:    func() {},
+	}, nil
+}
+
+func (m *mockNotifier) RegisterSpendNtfn(op *wire.OutPoint, _ []byte,
+	_ uint32) (*chainntnfs.SpendEvent, error) {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c := make(chan *chainntnfs.SpendDetail, 1)
+	m.spends[*op] = append(m.spends[*op], c)
+
+	return &chainntnfs.SpendEvent{
+		Spend:  c,
+		Cancel: func() {},
+	}, nil
+}
+
+func (m *mockNotifier) RegisterBlockEpochNtfn(
+	*chainntnfs.BlockEpoch) (*chainntnfs.BlockEpochEvent, error) {
+
+	return &chainntnfs.BlockEpochEvent{
+		Epochs: m.epochs,
+		Cancel: func() {},
+	}, nil
+}
+
+func (m *mockNotifier) Start() error {
+	return nil
+}
+
+func (m *mockNotifier) Started() bool {
+	return true
+}
+
+func (m *mockNotifier) Stop() error {
+	return nil
+}
+
+// confirm delivers a confirmation of the given transaction once the tracker
+// registered for it.
+func (m *mockNotifier) confirm(t *testing.T, txid chainhash.Hash) {
+	var c chan *chainntnfs.TxConfirmation
+	require.Eventually(t, func() bool {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		var ok bool
+		c, ok = m.confs[txid]
+
+		return ok
+	}, time.Second, 10*time.Millisecond)
+
+	c <- &chainntnfs.TxConfirmation{BlockHeight: testHeight + 1}
+}
+
+// spend delivers a spend of the given outpoint by the given transaction to
+// all registrations, once the tracker registered the given number of times
+// for it.
+func (m *mockNotifier) spend(t *testing.T, op wire.OutPoint,
+	spender *wire.MsgTx, numRegistrations int) {
+
+	var chans []chan *chainntnfs.SpendDetail
+	require.Eventually(t, func() bool {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		chans = m.spends[op]
+
+		return len(chans) == numRegistrations
+	}, time.Second, 10*time.Millisecond)
+
+	spenderHash := spender.TxHash()
+	for _, c := range chans {
+		c <- &chainntnfs.SpendDetail{
+			SpentOutPoint:  &op,
+			SpenderTxHash:  &spenderHash,
+			SpendingTx:     spender,
+			SpendingHeight: testHeight + 1,
+		}
+	}
+}
+
+// trackerHarness bundles a tracker with its mocked dependencies.
+type trackerHarness struct {
+	t        *testing.T
+	tracker  *Tracker
+	store    Store
+	notifier *mockNotifier
+	clock    *clock.TestClock
+
+	mu        sync.Mutex
+	inMempool map[chainhash.Hash]bool
+	cancelled []chainhash.Hash
+}
+
+func newTrackerHarness(t *testing.T, store Store) *trackerHarness {
+	h := &trackerHarness{
+		t:         t,
+		store:     store,
+		notifier:  newMockNotifier(),
+		clock:     clock.NewTestClock(testTime),
+		inMempool: make(map[chainhash.Hash]bool),
+	}
+
+	h.tracker = New(&Config{
+		Store:    store,
+		Notifier: h.notifier,
+		BestHeight: func() (uint32, error) {
+			return testHeight, nil
+		},
+		FetchPrevOutput: func(op wire.OutPoint) (*wire.TxOut, error) {
+			return &wire.TxOut{
+				Value:    testInputValue,
+				PkScript: testPkScript,
+			}, nil
+		},
+		InMempool: func(txid chainhash.Hash) (bool, error) {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+
+			return h.inMempool[txid], nil
+		},
+		CancelRebroadcast: func(txid chainhash.Hash) {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+
+			h.cancelled = append(h.cancelled, txid)
+		},
+		Clock:      h.clock,
+		PruneAfter: DefaultPruneAfter,
+	})
+
+	return h
+}
+
+func newTestStore(t *testing.T) Store {
+	db, cleanUp, err := kvdb.GetTestBackend(t.TempDir(), "txtracker")
+	require.NoError(t, err)
+	t.Cleanup(cleanUp)
+
+	store, err := NewStore(db)
+	require.NoError(t, err)
+
+	return store
+}
+
+// start starts the tracker and returns a subscription to its updates.
+func (h *trackerHarness) start() *subscribe.Client {
+	require.NoError(h.t, h.tracker.Start())
+	h.t.Cleanup(func() {
+		require.NoError(h.t, h.tracker.Stop())
+	})
+
+	sub, err := h.tracker.SubscribeTxUpdates()
+	require.NoError(h.t, err)
+	h.t.Cleanup(sub.Cancel)
+
+	return sub
+}
+
+// setInMempool sets whether the given transaction is reported to be in the
+// mempool.
+func (h *trackerHarness) setInMempool(txid chainhash.Hash, inMempool bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.inMempool[txid] = inMempool
+}
+
+// assertUpdate asserts that the next update received by the given
+// subscription is for the given transaction and has the given status.
+func (h *trackerHarness) assertUpdate(sub *subscribe.Client,
+	txid chainhash.Hash, status TxStatus) *PublishedTx {
+
+	h.t.Helper()
+
+	select {
+	case update := <-sub.Updates():
+		p, ok := update.(*PublishedTx)
+		require.True(h.t, ok)
+		require.Equal(h.t, txid, p.TxHash())
+		require.Equal(h.t, status, p.Status)
+
+		return p
+
+	case <-time.After(time.Second):
+		h.t.Fatalf("no update received for tx %v", txid)
+		return nil
+	}
+}
+
+// fetchTx returns the stored record of the given transaction.
+func (h *trackerHarness) fetchTx(txid chainhash.Hash) *PublishedTx {
+	p, err := h.store.FetchTx(txid)
+	require.NoError(h.t, err)
+
+	return p
+}
+
+// newTestTx creates a transaction spending the given outpoints that pays the
+// given value.
+func newTestTx(value int64, inputs ...wire.OutPoint) *wire.MsgTx {
+	tx := wire.NewMsgTx(2)
+	for _, op := range inputs {
+		tx.AddTxIn(&wire.TxIn{PreviousOutPoint: op})
+	}
+	tx.AddTxOut(&wire.TxOut{Value: value, PkScript: testPkScript})
+
+	return tx
+}
+
+// TestTrackerPublish asserts that published transactions are recorded with
+// the outcome of the publication and that replacements are tracked.
+func TestTrackerPublish(t *testing.T) {
+	t.Parallel()
+
+	h := newTrackerHarness(t, newTestStore(t))
+
+	input1 := wire.OutPoint{Index: 1}
+	input2 := wire.OutPoint{Index: 2}
+
+	// A transaction that is rejected before the tracker is started should
+	// be recorded together with the rejection reason and its fee once the
+	// tracker starts.
+	rejectedTx := newTestTx(99_000, input1)
+	h.tracker.TrackPublish(
+		rejectedTx, "0:sweep", lnwallet.ErrMempoolFee,
+	)
+
+	_, err := h.store.FetchTx(rejectedTx.TxHash())
+	require.ErrorIs(t, err, ErrTxNotFound)
+
+	sub := h.start()
+
+	rejected := h.fetchTx(rejectedTx.TxHash())
+	require.Equal(t, TxStatusRejected, rejected.Status)
+	require.Equal(t, lnwallet.ErrMempoolFee.Error(), rejected.Reason)
+	require.Equal(t, TxPurposeSweep, rejected.Purpose())
+	require.Equal(t, btcutil.Amount(1_000), rejected.Fee)
+	require.NotZero(t, rejected.FeeRate)
+	require.EqualValues(t, 1, rejected.PublishAttempts)
+	require.Equal(t, testTime, rejected.FirstPublished)
+
+	// A replacement with a higher fee is accepted, which makes the
+	// rejected transaction pending.
+	tx1 := newTestTx(98_000, input1)
+	h.tracker.TrackPublish(tx1, "0:sweep", nil)
+	p1 := h.assertUpdate(sub, tx1.TxHash(), TxStatusPending)
+	require.EqualValues(t, testHeight, p1.PublishHeight)
+	require.Empty(t, p1.Replaces)
+
+	// Replacing the transaction with another one that spends the same
+	// input marks the first one as replaced.
+	tx2 := newTestTx(97_000, input1)
+	h.tracker.TrackPublish(tx2, "0:sweep", nil)
+	p1 = h.assertUpdate(sub, tx1.TxHash(), TxStatusReplaced)
+	require.Equal(t, tx2.TxHash(), p1.ReplacedBy)
+	p2 := h.assertUpdate(sub, tx2.TxHash(), TxStatusPending)
+	require.Equal(t, []chainhash.Hash{tx1.TxHash()}, p2.Replaces)
+	require.Equal(t, []chainhash.Hash{tx1.TxHash()}, h.cancelled)
+
+	// The replacement confirms, after which the spend of the input of the
+	// replaced transaction finalizes it.
+	h.notifier.confirm(t, tx2.TxHash())
+	p2 = h.assertUpdate(sub, tx2.TxHash(), TxStatusConfirmed)
+	require.EqualValues(t, testHeight+1, p2.ConfirmHeight)
+
+	h.notifier.spend(t, input1, tx2, 2)
+	p1 = h.assertUpdate(sub, tx1.TxHash(), TxStatusReplaced)
+	require.Equal(t, tx2.TxHash(), p1.ConflictingTx)
+	require.False(t, p1.IsActive())
+
+	// A transaction whose input is spent by a transaction we don't know of
+	// is double spent.
+	tx3 := newTestTx(99_500, input2)
+	h.tracker.TrackPublish(tx3, "external", nil)
+	h.assertUpdate(sub, tx3.TxHash(), TxStatusPending)
+
+	conflict := newTestTx(99_900, input2)
+	h.notifier.spend(t, input2, conflict, 1)
+	p3 := h.assertUpdate(sub, tx3.TxHash(), TxStatusDoubleSpent)
+	require.Equal(t, conflict.TxHash(), p3.ConflictingTx)
+	require.Equal(t, TxPurposeUser, p3.Purpose())
+
+	txs, err := h.tracker.PublishedTxs(false)
+	require.NoError(t, err)
+	require.Len(t, txs, 4)
+
+	active, err := h.tracker.PublishedTxs(true)
+	require.NoError(t, err)
+	require.Empty(t, active)
+}
+
+// TestTrackerEviction asserts that transactions that disappear from the
+// mempool are marked as evicted, and that the tracker resumes watching the
+// pending transactions after a restart.
+func TestTrackerEviction(t *testing.T) {
+	t.Parallel()
+
+	store := newTestStore(t)
+	h := newTrackerHarness(t, store)
+	sub := h.start()
+
+	tx := newTestTx(99_000, wire.OutPoint{Index: 1})
+	txid := tx.TxHash()
+	h.setInMempool(txid, true)
+	h.tracker.TrackPublish(tx, "0:closechannel", nil)
+	h.assertUpdate(sub, txid, TxStatusPending)
+
+	// The transaction is only considered evicted once it's missing from
+	// the mempool at two consecutive blocks.
+	h.setInMempool(txid, false)
+	h.notifier.epochs <- &chainntnfs.BlockEpoch{}
+	h.notifier.epochs <- &chainntnfs.BlockEpoch{}
+	p := h.assertUpdate(sub, txid, TxStatusEvicted)
+	require.Equal(t, TxPurposeChannelClose, p.Purpose())
+
+	// Rebroadcasting the transaction makes it pending again.
+	h.tracker.TrackPublish(tx, "0:closechannel", nil)
+	p = h.assertUpdate(sub, txid, TxStatusPending)
+	require.EqualValues(t, 2, p.PublishAttempts)
+
+	// A new tracker using the same store picks up the pending transaction
+	// and sees it confirm.
+	require.NoError(t, h.tracker.Stop())
+
+	h = newTrackerHarness(t, store)
+	sub = h.start()
+
+	active, err := h.tracker.PublishedTxs(true)
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+
+	h.notifier.confirm(t, txid)
+	h.assertUpdate(sub, txid, TxStatusConfirmed)
+}
+
+// TestTrackerPrune asserts that the records of transactions that confirmed are
+// pruned once they're older than the retention, while rejected transactions
+// are kept.
+func TestTrackerPrune(t *testing.T) {
+	t.Parallel()
+
+	h := newTrackerHarness(t, newTestStore(t))
+	sub := h.start()
+
+	confirmedTx := newTestTx(99_000, wire.OutPoint{Index: 1})
+	h.tracker.TrackPublish(confirmedTx, "0:sweep", nil)
+	h.assertUpdate(sub, confirmedTx.TxHash(), TxStatusPending)
+
+	h.notifier.confirm(t, confirmedTx.TxHash())
+	h.assertUpdate(sub, confirmedTx.TxHash(), TxStatusConfirmed)
+
+	rejectedTx := newTestTx(99_000, wire.OutPoint{Index: 2})
+	h.tracker.TrackPublish(rejectedTx, "0:sweep", lnwallet.ErrMempoolFee)
+	h.assertUpdate(sub, rejectedTx.TxHash(), TxStatusRejected)
+
+	// Nothing is pruned before the retention passed.
+	h.notifier.epochs <- &chainntnfs.BlockEpoch{}
+	h.clock.SetTime(testTime.Add(DefaultPruneAfter))
+	h.notifier.epochs <- &chainntnfs.BlockEpoch{}
+	h.fetchTx(confirmedTx.TxHash())
+
+	// Once it passed, the confirmed transaction is pruned at the next
+	// block.
+	h.clock.SetTime(testTime.Add(DefaultPruneAfter + time.Second))
+	h.notifier.epochs <- &chainntnfs.BlockEpoch{}
+
+	require.Eventually(t, func() bool {
+		_, err := h.store.FetchTx(confirmedTx.TxHash())
+		return errors.Is(err, ErrTxNotFound)
+	}, time.Second, 10*time.Millisecond)
+
+	h.fetchTx(rejectedTx.TxHash())
+}
+
+// TestPublishedTxSerialization asserts that a record survives a round trip
+// through the store.
+func TestPublishedTxSerialization(t *testing.T) {
+	t.Parallel()
+
+	store := newTestStore(t)
+
+	p := &PublishedTx{
+		Tx:              newTestTx(1_000, wire.OutPoint{Index: 3}),
+		Label:           "0:openchannel",
+		Status:          TxStatusReplaced,
+		Reason:          "insufficient fee",
+		Fee:             500,
+		FeeRate:         1_234,
+		FirstPublished:  testTime,
+		LastPublished:   testTime.Add(time.Hour),
+		PublishAttempts: 3,
+		PublishHeight:   testHeight,
+		ConfirmHeight:   testHeight + 5,
+		ReplacedBy:      chainhash.Hash{1},
+		Replaces:        []chainhash.Hash{{2}, {3}},
+		ConflictingTx:   chainhash.Hash{4},
+	}
+	require.NoError(t, store.PutTx(p))
+
+	stored, err := store.FetchTx(p.TxHash())
+	require.NoError(t, err)
+	require.Equal(t, p.TxHash(), stored.TxHash())
+
+	stored.Tx = p.Tx
+	require.Equal(t, p, stored)
+
+	_, err = store.FetchTx(chainhash.Hash{5})
+	require.True(t, errors.Is(err, ErrTxNotFound))
+}
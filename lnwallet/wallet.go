@@ -484,7 +484,12 @@ func (l *LightningWallet) PublishTransaction(tx *wire.MsgTx,
 	label string) error {
 
 	sendTxToWallet := func() error {
-		return l.WalletController.PublishTransaction(tx, label)
+		err := l.WalletController.PublishTransaction(tx, label)
+		if l.Cfg.PublishTracker != nil {
+			l.Cfg.PublishTracker.TrackPublish(tx, label, err)
+		}
+
+		return err
 	}
 
 	// If we don't have rebroadcaster then we can exit early (and send only
@@ -2503,6 +2508,25 @@ func (l *LightningWallet) ValidateChannel(channelState *channeldb.OpenChannel,
 	return nil
 }
 
+// SendOutputs wraps the wallet controller method of the same name so the
+// published transaction is recorded by the publish tracker, if one is
+// configured.
+//
+// NOTE: This method requires the global coin selection lock to be held.
+func (l *LightningWallet) SendOutputs(outputs []*wire.TxOut,
+	feeRate chainfee.SatPerKWeight, minConfs int32,
+	label string) (*wire.MsgTx, error) {
+
+	tx, err := l.WalletController.SendOutputs(
+		outputs, feeRate, minConfs, label,
+	)
+	if tx != nil && l.Cfg.PublishTracker != nil {
+		l.Cfg.PublishTracker.TrackPublish(tx, label, err)
+	}
+
+	return tx, err
+}
+
 // CancelRebroadcast cancels the rebroadcast of the given transaction.
 func (l *LightningWallet) CancelRebroadcast(txid chainhash.Hash) {
 	// For neutrino, we don't config the rebroadcaster for the wallet as it
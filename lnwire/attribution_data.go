@@ -0,0 +1,60 @@
+package lnwire
+
+import (
+	"github.com/lightningnetwork/lnd/tlv"
+)
+
+const (
+	// AttributionDataType is the TLV type of the attribution data carried
+	// in the extra data of update_fail_htlc.
+	AttributionDataType tlv.Type = 1
+)
+
+// AttributionData holds the hold times and HMACs that the hops of a route add
+// to a failure as it travels back to the sender. It allows the sender to
+// verify which hops relayed the failure correctly, even if the failure
+// message itself can't be decrypted.
+type AttributionData []byte
+
+// Record returns a TLV record that can be used to encode/decode the
+// attribution data to/from a TLV stream.
+func (a *AttributionData) Record() tlv.Record {
+	return tlv.MakePrimitiveRecord(AttributionDataType, (*[]byte)(a))
+}
+
+// AttributionData extracts the attribution data from the extra data of the
+// failure. Nil is returned if the failure doesn't carry attribution data,
+// which is the case for failures relayed by nodes that don't support it.
+func (c *UpdateFailHTLC) AttributionData() (AttributionData, error) {
+	var data AttributionData
+	typeMap, err := c.ExtraData.ExtractRecords(&data)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := typeMap[AttributionDataType]; !ok {
+		return nil, nil
+	}
+
+	return data, nil
+}
+
+// SetAttributionData sets the attribution data of the failure. Empty
+// attribution data removes it from the extra data.
+//
+// NOTE: This replaces any other data contained in the extra data of the
+// failure.
+func (c *UpdateFailHTLC) SetAttributionData(data AttributionData) error {
+	if len(data) == 0 {
+		c.ExtraData = nil
+		return nil
+	}
+
+	var extraData ExtraOpaqueData
+	if err := extraData.PackRecords(&data); err != nil {
+		return err
+	}
+	c.ExtraData = extraData
+
+	return nil
+}
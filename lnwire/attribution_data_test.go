@@ -0,0 +1,42 @@
+package lnwire
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestUpdateFailHTLCAttributionData tests that the attribution data of a
+// failure survives a round trip through the wire encoding, and that failures
+// without attribution data are reported as such.
+func TestUpdateFailHTLCAttributionData(t *testing.T) {
+	t.Parallel()
+
+	fail := &UpdateFailHTLC{
+		ID:     1,
+		Reason: bytes.Repeat([]byte{1}, 292),
+	}
+
+	data, err := fail.AttributionData()
+	require.NoError(t, err)
+	require.Nil(t, data)
+
+	attrData := AttributionData(bytes.Repeat([]byte{2}, 920))
+	require.NoError(t, fail.SetAttributionData(attrData))
+
+	var b bytes.Buffer
+	require.NoError(t, fail.Encode(&b, 0))
+
+	decoded := &UpdateFailHTLC{}
+	require.NoError(t, decoded.Decode(&b, 0))
+
+	data, err = decoded.AttributionData()
+	require.NoError(t, err)
+	require.Equal(t, attrData, data)
+
+	// Clearing the attribution data removes it from the extra data
+	// altogether.
+	require.NoError(t, decoded.SetAttributionData(nil))
+	require.Empty(t, decoded.ExtraData)
+}
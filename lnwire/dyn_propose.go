@@ -0,0 +1,220 @@
+package lnwire
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/lightningnetwork/lnd/tlv"
+)
+
+const (
+	// DPDustLimitSatoshis is the TLV type number that identifies the record
+	// for DynPropose.DustLimit.
+	DPDustLimitSatoshis tlv.Type = 0
+
+	// DPMaxAcceptedHtlcs is the TLV type number that identifies the record
+	// for DynPropose.MaxAcceptedHTLCs.
+	DPMaxAcceptedHtlcs tlv.Type = 2
+
+	// DPToSelfDelay is the TLV type number that identifies the record for
+	// DynPropose.CsvDelay.
+	DPToSelfDelay tlv.Type = 4
+
+	// DPChannelType is the TLV type number that identifies the record for
+	// DynPropose.ChannelType.
+	DPChannelType tlv.Type = 6
+)
+
+// DynPropose is a message that is sent during a dynamic commitments
+// negotiation process. It is sent by the initiator of the negotiation, once
+// the channel is quiescent, and lists the channel parameters the sender wants
+// to change. The fields have the same meaning as their counterparts in the
+// open_channel message, so they describe the constraints of the sender. Fields
+// that are not set are left unchanged.
+type DynPropose struct {
+	// ChanID identifies the channel whose parameters we are trying to
+	// re-negotiate.
+	ChanID ChannelID
+
+	// DustLimit, if not nil, proposes a change to the dust_limit_satoshis
+	// for the sender's commitment transaction.
+	DustLimit *btcutil.Amount
+
+	// MaxAcceptedHTLCs, if not nil, proposes a change to the
+	// max_accepted_htlcs limit of the sender.
+	MaxAcceptedHTLCs *uint16
+
+	// CsvDelay, if not nil, proposes a change to the to_self_delay that
+	// the recipient must use for its outputs.
+	CsvDelay *uint16
+
+	// ChannelType, if not nil, proposes a change to the channel_type of
+	// the channel.
+	ChannelType *ChannelType
+
+	// ExtraData is the set of data that was appended to this message to
+	// fill out the full maximum transport message size. These fields can
+	// be used to specify optional data such as custom TLV fields.
+	ExtraData ExtraOpaqueData
+}
+
+// A compile time check to ensure DynPropose implements the lnwire.Message
+// interface.
+var _ Message = (*DynPropose)(nil)
+
+// dynRecordProducer wraps a plain TLV record so it can be handed to the
+// helpers that expect a tlv.RecordProducer.
+type dynRecordProducer struct {
+	record tlv.Record
+}
+
+// Record returns the wrapped TLV record.
+//
+// NOTE: This is part of the tlv.RecordProducer interface.
+func (d *dynRecordProducer) Record() tlv.Record {
+	return d.record
+}
+
+// dynChannelTypeRecord returns a TLV record for the channel type field of the
+// dynamic commitment messages.
+func dynChannelTypeRecord(c *ChannelType) tlv.Record {
+	return tlv.MakeDynamicRecord(
+		DPChannelType, c, c.featureBitLen, channelTypeEncoder,
+		channelTypeDecoder,
+	)
+}
+
+// Encode serializes the target DynPropose into the passed io.Writer.
+// Serialization will observe the rules defined by the passed protocol version.
+//
+// This is a part of the lnwire.Message interface.
+func (dp *DynPropose) Encode(w *bytes.Buffer, _ uint32) error {
+	var recordProducers []tlv.RecordProducer
+
+	if dp.DustLimit != nil {
+		dustLimit := uint64(*dp.DustLimit)
+		recordProducers = append(recordProducers, &dynRecordProducer{
+			record: tlv.MakePrimitiveRecord(
+				DPDustLimitSatoshis, &dustLimit,
+			),
+		})
+	}
+	if dp.MaxAcceptedHTLCs != nil {
+		recordProducers = append(recordProducers, &dynRecordProducer{
+			record: tlv.MakePrimitiveRecord(
+				DPMaxAcceptedHtlcs, dp.MaxAcceptedHTLCs,
+			),
+		})
+	}
+	if dp.CsvDelay != nil {
+		recordProducers = append(recordProducers, &dynRecordProducer{
+			record: tlv.MakePrimitiveRecord(
+				DPToSelfDelay, dp.CsvDelay,
+			),
+		})
+	}
+	if dp.ChannelType != nil {
+		recordProducers = append(recordProducers, &dynRecordProducer{
+			record: dynChannelTypeRecord(dp.ChannelType),
+		})
+	}
+
+	err := EncodeMessageExtraData(&dp.ExtraData, recordProducers...)
+	if err != nil {
+		return err
+	}
+
+	if err := WriteChannelID(w, dp.ChanID); err != nil {
+		return err
+	}
+
+	return WriteBytes(w, dp.ExtraData)
+}
+
+// Decode deserializes the serialized DynPropose stored in the passed
+// io.Reader into the target DynPropose using the deserialization rules
+// defined by the passed protocol version.
+//
+// This is a part of the lnwire.Message interface.
+func (dp *DynPropose) Decode(r io.Reader, _ uint32) error {
+	// Parse out the only required field.
+	if err := ReadElements(r, &dp.ChanID); err != nil {
+		return err
+	}
+
+	// Parse out TLV stream.
+	var tlvRecords ExtraOpaqueData
+	if err := ReadElements(r, &tlvRecords); err != nil {
+		return err
+	}
+
+	// Prepare receiving buffers to be filled by TLV extraction.
+	var (
+		dustLimit uint64
+		maxHtlcs  uint16
+		csvDelay  uint16
+		chanType  ChannelType
+	)
+	typeMap, err := tlvRecords.ExtractRecords(
+		&dynRecordProducer{
+			record: tlv.MakePrimitiveRecord(
+				DPDustLimitSatoshis, &dustLimit,
+			),
+		},
+		&dynRecordProducer{
+			record: tlv.MakePrimitiveRecord(
+				DPMaxAcceptedHtlcs, &maxHtlcs,
+			),
+		},
+		&dynRecordProducer{
+			record: tlv.MakePrimitiveRecord(
+				DPToSelfDelay, &csvDelay,
+			),
+		},
+		&dynRecordProducer{
+			record: dynChannelTypeRecord(&chanType),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	// Check the results of the TLV Stream decoding and appropriately set
+	// message fields.
+	if val, ok := typeMap[DPDustLimitSatoshis]; ok && val == nil {
+		amt := btcutil.Amount(dustLimit)
+		dp.DustLimit = &amt
+	}
+	if val, ok := typeMap[DPMaxAcceptedHtlcs]; ok && val == nil {
+		dp.MaxAcceptedHTLCs = &maxHtlcs
+	}
+	if val, ok := typeMap[DPToSelfDelay]; ok && val == nil {
+		dp.CsvDelay = &csvDelay
+	}
+	if val, ok := typeMap[DPChannelType]; ok && val == nil {
+		dp.ChannelType = &chanType
+	}
+
+	if len(tlvRecords) != 0 {
+		dp.ExtraData = tlvRecords
+	}
+
+	return nil
+}
+
+// MsgType returns the MessageType code which uniquely identifies this message
+// as a DynPropose on the wire.
+//
+// This is part of the lnwire.Message interface.
+func (dp *DynPropose) MsgType() MessageType {
+	return MsgDynPropose
+}
+
+// TargetChanID returns the channel id of the link for which this message is
+// intended.
+//
+// NOTE: Part of peer.LinkUpdater interface.
+func (dp *DynPropose) TargetChanID() ChannelID {
+	return dp.ChanID
+}
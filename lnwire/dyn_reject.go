@@ -0,0 +1,87 @@
+package lnwire
+
+import (
+	"bytes"
+	"io"
+)
+
+// DynReject is a message that is sent during a dynamic commitments negotiation
+// process. It is sent back to the initiator of the negotiation if the
+// recipient can't accept the proposal, either because the channel isn't
+// quiescent or because some of the proposed parameters are unacceptable.
+type DynReject struct {
+	// ChanID identifies the channel whose parameters we are trying to
+	// re-negotiate.
+	ChanID ChannelID
+
+	// UpdateRejections is a bit vector that specifies which of the
+	// DynPropose parameters we wish to reject. The bit index of each
+	// parameter is its TLV type in the DynPropose message. An empty
+	// vector means the proposal was rejected as a whole, for example
+	// because the channel wasn't quiescent.
+	UpdateRejections RawFeatureVector
+
+	// ExtraData is the set of data that was appended to this message to
+	// fill out the full maximum transport message size. These fields can
+	// be used to specify optional data such as custom TLV fields.
+	ExtraData ExtraOpaqueData
+}
+
+// A compile time check to ensure DynReject implements the lnwire.Message
+// interface.
+var _ Message = (*DynReject)(nil)
+
+// Encode serializes the target DynReject into the passed io.Writer.
+// Serialization will observe the rules defined by the passed protocol version.
+//
+// This is a part of the lnwire.Message interface.
+func (dr *DynReject) Encode(w *bytes.Buffer, _ uint32) error {
+	if err := WriteChannelID(w, dr.ChanID); err != nil {
+		return err
+	}
+
+	if err := WriteRawFeatureVector(w, &dr.UpdateRejections); err != nil {
+		return err
+	}
+
+	return WriteBytes(w, dr.ExtraData)
+}
+
+// Decode deserializes the serialized DynReject stored in the passed io.Reader
+// into the target DynReject using the deserialization rules defined by the
+// passed protocol version.
+//
+// This is a part of the lnwire.Message interface.
+func (dr *DynReject) Decode(r io.Reader, _ uint32) error {
+	var (
+		featureVec *RawFeatureVector
+		extra      ExtraOpaqueData
+	)
+	err := ReadElements(r, &dr.ChanID, &featureVec, &extra)
+	if err != nil {
+		return err
+	}
+	dr.UpdateRejections = *featureVec
+
+	if len(extra) != 0 {
+		dr.ExtraData = extra
+	}
+
+	return nil
+}
+
+// MsgType returns the MessageType code which uniquely identifies this message
+// as a DynReject on the wire.
+//
+// This is part of the lnwire.Message interface.
+func (dr *DynReject) MsgType() MessageType {
+	return MsgDynReject
+}
+
+// TargetChanID returns the channel id of the link for which this message is
+// intended.
+//
+// NOTE: Part of peer.LinkUpdater interface.
+func (dr *DynReject) TargetChanID() ChannelID {
+	return dr.ChanID
+}
@@ -0,0 +1,77 @@
+package lnwire
+
+import (
+	"github.com/lightningnetwork/lnd/tlv"
+)
+
+const (
+	// ExperimentalEndorsementType is the TLV type of the experimental
+	// endorsement signal carried in the extra data of update_add_htlc.
+	ExperimentalEndorsementType tlv.Type = 106823
+)
+
+// Endorsement is the experimental signal a node attaches to an HTLC to
+// indicate to the next hop whether it vouches for the HTLC being resolved in a
+// timely manner.
+type Endorsement uint8
+
+const (
+	// ExperimentalUnendorsed means that the HTLC is not endorsed by the
+	// sending node. HTLCs that don't carry the signal are unendorsed.
+	ExperimentalUnendorsed Endorsement = 0
+
+	// ExperimentalEndorsed means that the sending node endorses the HTLC.
+	ExperimentalEndorsed Endorsement = 1
+)
+
+// String returns a human-readable version of the endorsement signal.
+func (e Endorsement) String() string {
+	switch e {
+	case ExperimentalUnendorsed:
+		return "unendorsed"
+
+	case ExperimentalEndorsed:
+		return "endorsed"
+
+	default:
+		return "unknown"
+	}
+}
+
+// Record returns a TLV record that can be used to encode/decode the
+// endorsement signal to/from a TLV stream.
+func (e *Endorsement) Record() tlv.Record {
+	return tlv.MakePrimitiveRecord(ExperimentalEndorsementType, (*uint8)(e))
+}
+
+// Endorsement extracts the experimental endorsement signal from the extra data
+// of the HTLC. HTLCs without the signal are reported as unendorsed.
+func (c *UpdateAddHTLC) Endorsement() (Endorsement, error) {
+	endorsement := ExperimentalUnendorsed
+	_, err := c.ExtraData.ExtractRecords(&endorsement)
+	if err != nil {
+		return ExperimentalUnendorsed, err
+	}
+
+	return endorsement, nil
+}
+
+// SetEndorsement sets the experimental endorsement signal of the HTLC. The
+// signal is only attached to the extra data if the HTLC is endorsed, as an
+// absent signal is interpreted as unendorsed.
+//
+// NOTE: This replaces any other data contained in the extra data of the HTLC.
+func (c *UpdateAddHTLC) SetEndorsement(endorsement Endorsement) error {
+	if endorsement == ExperimentalUnendorsed {
+		c.ExtraData = nil
+		return nil
+	}
+
+	var extraData ExtraOpaqueData
+	if err := extraData.PackRecords(&endorsement); err != nil {
+		return err
+	}
+	c.ExtraData = extraData
+
+	return nil
+}
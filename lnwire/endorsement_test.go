@@ -0,0 +1,42 @@
+package lnwire
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestUpdateAddHTLCEndorsement tests that the endorsement signal of an HTLC
+// survives a round trip through the wire encoding, and that HTLCs without the
+// signal are unendorsed.
+func TestUpdateAddHTLCEndorsement(t *testing.T) {
+	t.Parallel()
+
+	htlc := &UpdateAddHTLC{
+		ID:     1,
+		Amount: 1000,
+		Expiry: 144,
+	}
+
+	endorsement, err := htlc.Endorsement()
+	require.NoError(t, err)
+	require.Equal(t, ExperimentalUnendorsed, endorsement)
+
+	require.NoError(t, htlc.SetEndorsement(ExperimentalEndorsed))
+
+	var b bytes.Buffer
+	require.NoError(t, htlc.Encode(&b, 0))
+
+	decoded := &UpdateAddHTLC{}
+	require.NoError(t, decoded.Decode(&b, 0))
+
+	endorsement, err = decoded.Endorsement()
+	require.NoError(t, err)
+	require.Equal(t, ExperimentalEndorsed, endorsement)
+
+	// Resetting the signal to unendorsed removes it from the extra data
+	// altogether.
+	require.NoError(t, decoded.SetEndorsement(ExperimentalUnendorsed))
+	require.Empty(t, decoded.ExtraData)
+}
@@ -255,6 +255,38 @@ const (
 	// finalized.
 	SimpleTaprootChannelsOptionalStaging = 181
 
+	// ZeroFeeCommitmentsRequired is a required feature bit that signals
+	// that the node requires channels with zero-fee v3 commitment
+	// transactions that carry a single ephemeral pay-to-anchor output
+	// instead of the two keyed anchors. The bit is the one assigned to
+	// option_zero_fee_commitments in the BOLT proposal.
+	ZeroFeeCommitmentsRequired FeatureBit = 40
+
+	// ZeroFeeCommitmentsOptional is an optional feature bit that signals
+	// that the node supports channels with zero-fee v3 commitment
+	// transactions that carry a single ephemeral pay-to-anchor output
+	// instead of the two keyed anchors. The bit is the one assigned to
+	// option_zero_fee_commitments in the BOLT proposal.
+	ZeroFeeCommitmentsOptional FeatureBit = 41
+
+	// DynamicCommitmentsRequired is a required feature bit that signals
+	// that the node requires its peers to understand the dyn_propose,
+	// dyn_ack and dyn_reject messages that allow changing the parameters
+	// of an existing channel without closing it, after making the channel
+	// quiescent through the stfu message.
+	//
+	// TODO: Decide on actual feature bit value.
+	DynamicCommitmentsRequired FeatureBit = 176
+
+	// DynamicCommitmentsOptional is an optional feature bit that signals
+	// that the node understands the dyn_propose, dyn_ack and dyn_reject
+	// messages that allow changing the parameters of an existing channel
+	// without closing it, after making the channel quiescent through the
+	// stfu message.
+	//
+	// TODO: Decide on actual feature bit value.
+	DynamicCommitmentsOptional FeatureBit = 177
+
 	// MaxBolt11Feature is the maximum feature bit value allowed in bolt 11
 	// invoices.
 	//
@@ -319,6 +351,10 @@ var Features = map[FeatureBit]string{
 	SimpleTaprootChannelsOptionalFinal:   "simple-taproot-chans",
 	SimpleTaprootChannelsRequiredStaging: "simple-taproot-chans-x",
 	SimpleTaprootChannelsOptionalStaging: "simple-taproot-chans-x",
+	ZeroFeeCommitmentsRequired:           "zero-fee-commitments",
+	ZeroFeeCommitmentsOptional:           "zero-fee-commitments",
+	DynamicCommitmentsRequired:           "dynamic-commitments",
+	DynamicCommitmentsOptional:           "dynamic-commitments",
 }
 
 // RawFeatureVector represents a set of feature bits as defined in BOLT-09.  A
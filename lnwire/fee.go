@@ -0,0 +1,69 @@
+package lnwire
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/lightningnetwork/lnd/tlv"
+)
+
+const (
+	// FeeRecordType is the type of the inbound fee TLV record that is
+	// carried in the extra opaque data of a channel update.
+	FeeRecordType tlv.Type = 55555
+
+	// feeRecordSize is the encoded size of a fee record.
+	feeRecordSize = 8
+)
+
+// Fee represents a fee schedule consisting of a base fee and a proportional
+// fee rate. Unlike the outbound fees of a channel update, both values are
+// signed, allowing a node to advertise a discount for payments that enter
+// through a channel.
+type Fee struct {
+	// BaseFee is the fixed fee in milli-satoshis.
+	BaseFee int32
+
+	// FeeRate is the proportional fee in parts per million.
+	FeeRate int32
+}
+
+// Record returns a TLV record that can be used to encode/decode the fee from
+// a given TLV stream.
+func (f *Fee) Record() tlv.Record {
+	return tlv.MakeStaticRecord(
+		FeeRecordType, f, feeRecordSize, feeEncoder, feeDecoder,
+	)
+}
+
+// feeEncoder is a custom TLV encoder for the Fee record.
+func feeEncoder(w io.Writer, val interface{}, _ *[8]byte) error {
+	if v, ok := val.(*Fee); ok {
+		var b [feeRecordSize]byte
+		binary.BigEndian.PutUint32(b[:4], uint32(v.BaseFee))
+		binary.BigEndian.PutUint32(b[4:], uint32(v.FeeRate))
+
+		_, err := w.Write(b[:])
+
+		return err
+	}
+
+	return tlv.NewTypeForEncodingErr(val, "lnwire.Fee")
+}
+
+// feeDecoder is a custom TLV decoder for the Fee record.
+func feeDecoder(r io.Reader, val interface{}, _ *[8]byte, l uint64) error {
+	if v, ok := val.(*Fee); ok && l == feeRecordSize {
+		var b [feeRecordSize]byte
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return err
+		}
+
+		v.BaseFee = int32(binary.BigEndian.Uint32(b[:4]))
+		v.FeeRate = int32(binary.BigEndian.Uint32(b[4:]))
+
+		return nil
+	}
+
+	return tlv.NewTypeForDecodingErr(val, "lnwire.Fee", l, feeRecordSize)
+}
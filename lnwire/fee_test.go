@@ -0,0 +1,29 @@
+package lnwire
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestFeeEncodeDecode tests that a fee, including negative values, survives a
+// round trip through the extra opaque data of a message.
+func TestFeeEncodeDecode(t *testing.T) {
+	t.Parallel()
+
+	fee := Fee{
+		BaseFee: -1000,
+		FeeRate: -250,
+	}
+
+	var extraData ExtraOpaqueData
+	require.NoError(t, extraData.PackRecords(&fee))
+
+	var decoded Fee
+	typeMap, err := extraData.ExtractRecords(&decoded)
+	require.NoError(t, err)
+
+	require.Contains(t, typeMap, FeeRecordType)
+	require.Nil(t, typeMap[FeeRecordType])
+	require.Equal(t, fee, decoded)
+}
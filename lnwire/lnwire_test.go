@@ -997,6 +997,65 @@ func TestLightningWireProtocol(t *testing.T) {
 
 			v[0] = reflect.ValueOf(req)
 		},
+		MsgDynPropose: func(v []reflect.Value, r *rand.Rand) {
+			var dp DynPropose
+			if _, err := r.Read(dp.ChanID[:]); err != nil {
+				t.Fatalf("unable to generate chan id: %v", err)
+				return
+			}
+
+			// Each of the optional fields is included with a 50/50
+			// probability.
+			if r.Int31()%2 == 0 {
+				dustLimit := btcutil.Amount(r.Int63())
+				dp.DustLimit = &dustLimit
+			}
+			if r.Int31()%2 == 0 {
+				maxHtlcs := uint16(r.Int31())
+				dp.MaxAcceptedHTLCs = &maxHtlcs
+			}
+			if r.Int31()%2 == 0 {
+				csvDelay := uint16(r.Int31())
+				dp.CsvDelay = &csvDelay
+			}
+			if r.Int31()%2 == 0 {
+				dp.ChannelType = new(ChannelType)
+				*dp.ChannelType = ChannelType(
+					*randRawFeatureVector(r),
+				)
+			}
+
+			v[0] = reflect.ValueOf(dp)
+		},
+		MsgStfu: func(v []reflect.Value, r *rand.Rand) {
+			var s Stfu
+			if _, err := r.Read(s.ChanID[:]); err != nil {
+				t.Fatalf("unable to generate chan id: %v", err)
+				return
+			}
+			s.Initiator = r.Int31()%2 == 0
+
+			v[0] = reflect.ValueOf(s)
+		},
+		MsgDynAck: func(v []reflect.Value, r *rand.Rand) {
+			var da DynAck
+			if _, err := r.Read(da.ChanID[:]); err != nil {
+				t.Fatalf("unable to generate chan id: %v", err)
+				return
+			}
+
+			v[0] = reflect.ValueOf(da)
+		},
+		MsgDynReject: func(v []reflect.Value, r *rand.Rand) {
+			var dr DynReject
+			if _, err := r.Read(dr.ChanID[:]); err != nil {
+				t.Fatalf("unable to generate chan id: %v", err)
+				return
+			}
+			dr.UpdateRejections = *randRawFeatureVector(r)
+
+			v[0] = reflect.ValueOf(dr)
+		},
 		MsgQueryShortChanIDs: func(v []reflect.Value, r *rand.Rand) {
 			req := QueryShortChanIDs{
 				ExtraData: make([]byte, 0),
@@ -1256,6 +1315,30 @@ func TestLightningWireProtocol(t *testing.T) {
 				return mainScenario(&m)
 			},
 		},
+		{
+			msgType: MsgStfu,
+			scenario: func(m Stfu) bool {
+				return mainScenario(&m)
+			},
+		},
+		{
+			msgType: MsgDynPropose,
+			scenario: func(m DynPropose) bool {
+				return mainScenario(&m)
+			},
+		},
+		{
+			msgType: MsgDynAck,
+			scenario: func(m DynAck) bool {
+				return mainScenario(&m)
+			},
+		},
+		{
+			msgType: MsgDynReject,
+			scenario: func(m DynReject) bool {
+				return mainScenario(&m)
+			},
+		},
 	}
 	for _, test := range tests {
 		var config *quick.Config
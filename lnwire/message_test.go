@@ -290,6 +290,10 @@ func makeAllMessages(t testing.TB, r *rand.Rand) []lnwire.Message {
 	msgAll = append(msgAll, newMsgGossipTimestampRange(t, r))
 	msgAll = append(msgAll, newMsgQueryShortChanIDsZlib(t, r))
 	msgAll = append(msgAll, newMsgReplyChannelRangeZlib(t, r))
+	msgAll = append(msgAll, newMsgStfu(t, r))
+	msgAll = append(msgAll, newMsgDynPropose(t, r))
+	msgAll = append(msgAll, newMsgDynAck(t, r))
+	msgAll = append(msgAll, newMsgDynReject(t, r))
 
 	return msgAll
 }
@@ -606,6 +610,74 @@ func newMsgUpdateFee(t testing.TB, r *rand.Rand) *lnwire.UpdateFee {
 	return msg
 }
 
+func newMsgDynPropose(t testing.TB, r *rand.Rand) *lnwire.DynPropose {
+	t.Helper()
+
+	dustLimit := btcutil.Amount(r.Int63())
+	maxHtlcs := uint16(r.Int31())
+	csvDelay := uint16(r.Int31())
+	chanType := lnwire.ChannelType(*lnwire.NewRawFeatureVector(
+		lnwire.StaticRemoteKeyRequired,
+		lnwire.AnchorsZeroFeeHtlcTxRequired,
+	))
+
+	msg := &lnwire.DynPropose{
+		DustLimit:        &dustLimit,
+		MaxAcceptedHTLCs: &maxHtlcs,
+		CsvDelay:         &csvDelay,
+		ChannelType:      &chanType,
+		ExtraData:        make([]byte, 0),
+	}
+
+	_, err := r.Read(msg.ChanID[:])
+	require.NoError(t, err, "unable to generate channel id")
+
+	return msg
+}
+
+func newMsgStfu(t testing.TB, r *rand.Rand) *lnwire.Stfu {
+	t.Helper()
+
+	msg := &lnwire.Stfu{
+		Initiator: r.Int31()%2 == 0,
+		ExtraData: createExtraData(t, r),
+	}
+
+	_, err := r.Read(msg.ChanID[:])
+	require.NoError(t, err, "unable to generate channel id")
+
+	return msg
+}
+
+func newMsgDynAck(t testing.TB, r *rand.Rand) *lnwire.DynAck {
+	t.Helper()
+
+	msg := &lnwire.DynAck{
+		ExtraData: createExtraData(t, r),
+	}
+
+	_, err := r.Read(msg.ChanID[:])
+	require.NoError(t, err, "unable to generate channel id")
+
+	return msg
+}
+
+func newMsgDynReject(t testing.TB, r *rand.Rand) *lnwire.DynReject {
+	t.Helper()
+
+	msg := &lnwire.DynReject{
+		UpdateRejections: *lnwire.NewRawFeatureVector(
+			lnwire.FeatureBit(lnwire.DPDustLimitSatoshis),
+		),
+		ExtraData: createExtraData(t, r),
+	}
+
+	_, err := r.Read(msg.ChanID[:])
+	require.NoError(t, err, "unable to generate channel id")
+
+	return msg
+}
+
 func newMsgUpdateFailMalformedHTLC(t testing.TB,
 	r *rand.Rand) *lnwire.UpdateFailMalformedHTLC {
 
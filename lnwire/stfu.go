@@ -0,0 +1,85 @@
+package lnwire
+
+import (
+	"bytes"
+	"io"
+)
+
+// Stfu is the message used to bring a channel into a quiescent state, in
+// which neither party sends any updates to the channel. It precedes the
+// dynamic commitment negotiation, so that the channel parameters are only
+// changed while no updates are in flight.
+type Stfu struct {
+	// ChanID is the ChannelID of the channel that is being made
+	// quiescent.
+	ChanID ChannelID
+
+	// Initiator is true if the sender wants to make the channel
+	// quiescent, and false if it replies to the stfu of its peer.
+	Initiator bool
+
+	// ExtraData is the set of data that was appended to this message to
+	// fill out the full maximum transport message size. These fields can
+	// be used to specify optional data such as custom TLV fields.
+	ExtraData ExtraOpaqueData
+}
+
+// A compile time check to ensure Stfu implements the lnwire.Message
+// interface.
+var _ Message = (*Stfu)(nil)
+
+// Encode serializes the target Stfu into the passed io.Writer. Serialization
+// will observe the rules defined by the passed protocol version.
+//
+// This is a part of the lnwire.Message interface.
+func (s *Stfu) Encode(w *bytes.Buffer, _ uint32) error {
+	if err := WriteChannelID(w, s.ChanID); err != nil {
+		return err
+	}
+
+	if err := WriteBool(w, s.Initiator); err != nil {
+		return err
+	}
+
+	return WriteBytes(w, s.ExtraData)
+}
+
+// Decode deserializes the serialized Stfu stored in the passed io.Reader into
+// the target Stfu using the deserialization rules defined by the passed
+// protocol version.
+//
+// This is a part of the lnwire.Message interface.
+func (s *Stfu) Decode(r io.Reader, _ uint32) error {
+	// Parse out the required fields.
+	if err := ReadElements(r, &s.ChanID, &s.Initiator); err != nil {
+		return err
+	}
+
+	// Parse out TLV records.
+	var tlvRecords ExtraOpaqueData
+	if err := ReadElements(r, &tlvRecords); err != nil {
+		return err
+	}
+
+	if len(tlvRecords) != 0 {
+		s.ExtraData = tlvRecords
+	}
+
+	return nil
+}
+
+// MsgType returns the MessageType code which uniquely identifies this message
+// as a Stfu on the wire.
+//
+// This is part of the lnwire.Message interface.
+func (s *Stfu) MsgType() MessageType {
+	return MsgStfu
+}
+
+// TargetChanID returns the channel id of the link for which this message is
+// intended.
+//
+// NOTE: Part of peer.LinkUpdater interface.
+func (s *Stfu) TargetChanID() ChannelID {
+	return s.ChanID
+}
@@ -0,0 +1,397 @@
+package lnd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/lightningnetwork/lnd/lnrpc"
+	"github.com/lightningnetwork/lnd/lnrpc/routerrpc"
+	"github.com/lightningnetwork/lnd/lnrpc/walletrpc"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/routing/route"
+	"github.com/lightningnetwork/lnd/rpcperms"
+	"github.com/lightningnetwork/lnd/zpay32"
+	"google.golang.org/protobuf/proto"
+)
+
+// errSendAllLimited is returned when a macaroon with spending limits is used
+// to sweep all wallet funds, since the amount isn't known upfront.
+var errSendAllLimited = errors.New("send_all is not allowed with a " +
+	"spending limited macaroon")
+
+// noSpendMethods are the methods that require a permission to move funds, but
+// don't send any funds themselves. Macaroons with limit caveats can call them
+// without being charged. All other methods that can move funds and have no
+// spend parser are rejected for such macaroons.
+var noSpendMethods = []string{
+	"/lnrpc.Lightning/ChannelAcceptor",
+	"/lnrpc.Lightning/RestoreChannelBackups",
+	"/lnrpc.Lightning/AddWebhook",
+	"/lnrpc.Lightning/RemoveWebhook",
+	"/lnrpc.Lightning/RetryWebhookDelivery",
+	"/lnrpc.Lightning/DeletePayment",
+	"/lnrpc.Lightning/DeleteAllPayments",
+	"/lnrpc.Lightning/SendCustomMessage",
+	"/lnrpc.Lightning/UpdateChannelPolicy",
+	"/lnrpc.Lightning/UpdateFeeManager",
+	"/routerrpc.Router/CancelPayment",
+	"/routerrpc.Router/ResetMissionControl",
+	"/routerrpc.Router/SetMissionControlConfig",
+	"/routerrpc.Router/XImportMissionControl",
+	"/routerrpc.Router/UpdateChanStatus",
+	"/walletrpc.WalletKit/ImportAccount",
+	"/walletrpc.WalletKit/ImportPublicKey",
+	"/walletrpc.WalletKit/ImportTapscript",
+	"/walletrpc.WalletKit/ImportLabels",
+	"/walletrpc.WalletKit/LabelTransaction",
+	"/walletrpc.WalletKit/LeaseOutput",
+	"/walletrpc.WalletKit/ReleaseOutput",
+	"/walletrpc.WalletKit/SignMessageWithAddr",
+	"/walletrpc.WalletKit/VerifyMessageWithAddr",
+	"/signrpc.Signer/SignMessage",
+	"/signrpc.Signer/DeriveSharedKey",
+	"/wtclientrpc.WatchtowerClient/AddTower",
+	"/wtclientrpc.WatchtowerClient/RemoveTower",
+}
+
+// macaroonSpendParsers returns the parsers that extract the funds sent by all
+// RPC methods that are subject to the spending limit caveats of macaroons.
+func macaroonSpendParsers(
+	chainParams *chaincfg.Params) map[string]*rpcperms.SpendParser {
+
+	paymentParser := &rpcperms.SpendParser{
+		ParseRequest: func(req proto.Message) (*rpcperms.SpendRequest,
+			error) {
+
+			r, ok := req.(*lnrpc.SendRequest)
+			if !ok {
+				return nil, fmt.Errorf("unexpected request "+
+					"type %T", req)
+			}
+
+			spend, err := paymentSpend(
+				chainParams, r.Dest, r.Amt, r.AmtMsat,
+				r.PaymentRequest,
+			)
+			if err != nil {
+				return nil, err
+			}
+
+			spend.Amount += lnrpc.CalculateFeeLimit(
+				r.FeeLimit, spend.Amount,
+			)
+
+			return spend, nil
+		},
+		ParseResponse: sendResponseResult,
+	}
+
+	sendToRouteParser := &rpcperms.SpendParser{
+		ParseRequest: func(req proto.Message) (*rpcperms.SpendRequest,
+			error) {
+
+			r, ok := req.(*lnrpc.SendToRouteRequest)
+			if !ok {
+				return nil, fmt.Errorf("unexpected request "+
+					"type %T", req)
+			}
+
+			return routeSpend(r.Route)
+		},
+		ParseResponse: sendResponseResult,
+	}
+
+	routerPaymentRequest := func(req proto.Message) (
+		*rpcperms.SpendRequest, error) {
+
+		r, ok := req.(*routerrpc.SendPaymentRequest)
+		if !ok {
+			return nil, fmt.Errorf("unexpected request type %T",
+				req)
+		}
+
+		spend, err := paymentSpend(
+			chainParams, r.Dest, r.Amt, r.AmtMsat, r.PaymentRequest,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		feeLimit, err := lnrpc.UnmarshallAmt(
+			r.FeeLimitSat, r.FeeLimitMsat,
+		)
+		if err != nil {
+			return nil, err
+		}
+		spend.Amount += feeLimit
+
+		return spend, nil
+	}
+
+	routerRouteRequest := func(req proto.Message) (*rpcperms.SpendRequest,
+		error) {
+
+		r, ok := req.(*routerrpc.SendToRouteRequest)
+		if !ok {
+			return nil, fmt.Errorf("unexpected request type %T",
+				req)
+		}
+
+		return routeSpend(r.Route)
+	}
+
+	parsers := map[string]*rpcperms.SpendParser{
+		"/lnrpc.Lightning/SendPayment":     paymentParser,
+		"/lnrpc.Lightning/SendPaymentSync": paymentParser,
+		"/lnrpc.Lightning/SendToRoute":     sendToRouteParser,
+		"/lnrpc.Lightning/SendToRouteSync": sendToRouteParser,
+		"/routerrpc.Router/SendPaymentV2": {
+			ParseRequest:  routerPaymentRequest,
+			ParseResponse: paymentResult,
+		},
+		"/routerrpc.Router/SendPayment": {
+			ParseRequest:  routerPaymentRequest,
+			ParseResponse: paymentStatusResult,
+		},
+		"/routerrpc.Router/SendToRouteV2": {
+			ParseRequest:  routerRouteRequest,
+			ParseResponse: htlcAttemptResult,
+		},
+		"/routerrpc.Router/SendToRoute": {
+			ParseRequest:  routerRouteRequest,
+			ParseResponse: sendToRouteResult,
+		},
+		"/lnrpc.Lightning/SendCoins": {
+			ParseRequest: func(req proto.Message) (
+				*rpcperms.SpendRequest, error) {
+
+				r, ok := req.(*lnrpc.SendCoinsRequest)
+				if !ok {
+					return nil, fmt.Errorf("unexpected "+
+						"request type %T", req)
+				}
+				if r.SendAll {
+					return nil, errSendAllLimited
+				}
+
+				return onChainSpend(r.Amount)
+			},
+		},
+		"/lnrpc.Lightning/SendMany": {
+			ParseRequest: func(req proto.Message) (
+				*rpcperms.SpendRequest, error) {
+
+				r, ok := req.(*lnrpc.SendManyRequest)
+				if !ok {
+					return nil, fmt.Errorf("unexpected "+
+						"request type %T", req)
+				}
+
+				var total int64
+				for _, amt := range r.AddrToAmount {
+					total += amt
+				}
+
+				return onChainSpend(total)
+			},
+		},
+		"/walletrpc.WalletKit/SendOutputs": {
+			ParseRequest: func(req proto.Message) (
+				*rpcperms.SpendRequest, error) {
+
+				r, ok := req.(*walletrpc.SendOutputsRequest)
+				if !ok {
+					return nil, fmt.Errorf("unexpected "+
+						"request type %T", req)
+				}
+
+				var total int64
+				for _, output := range r.Outputs {
+					total += output.Value
+				}
+
+				return onChainSpend(total)
+			},
+		},
+
+		// Closing a channel only moves funds if they are sent to an
+		// external delivery address instead of our wallet.
+		"/lnrpc.Lightning/CloseChannel": {
+			ParseRequest: func(req proto.Message) (
+				*rpcperms.SpendRequest, error) {
+
+				r, ok := req.(*lnrpc.CloseChannelRequest)
+				if !ok {
+					return nil, fmt.Errorf("unexpected "+
+						"request type %T", req)
+				}
+				if r.DeliveryAddress != "" {
+					return nil, fmt.Errorf("%w: close to "+
+						"delivery address",
+						rpcperms.ErrUnlimitedSpend)
+				}
+
+				return nil, nil
+			},
+		},
+	}
+
+	for _, method := range noSpendMethods {
+		parsers[method] = &rpcperms.SpendParser{
+			ParseRequest: func(proto.Message) (
+				*rpcperms.SpendRequest, error) {
+
+				return nil, nil
+			},
+		}
+	}
+
+	return parsers
+}
+
+// paymentSpend returns the funds sent by a payment to either the given
+// destination or the given payment request, excluding fees.
+func paymentSpend(chainParams *chaincfg.Params, dest []byte, amt,
+	amtMsat int64, payReq string) (*rpcperms.SpendRequest, error) {
+
+	amount, err := lnrpc.UnmarshallAmt(amt, amtMsat)
+	if err != nil {
+		return nil, err
+	}
+
+	if payReq == "" {
+		destination, err := route.NewVertexFromBytes(dest)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rpcperms.SpendRequest{
+			Amount:      amount,
+			Destination: &destination,
+		}, nil
+	}
+
+	invoice, err := zpay32.Decode(payReq, chainParams)
+	if err != nil {
+		return nil, err
+	}
+
+	// The amount of the invoice takes precedence over the amount of the
+	// request, which is only used for zero amount invoices.
+	if invoice.MilliSat != nil {
+		amount = *invoice.MilliSat
+	}
+
+	destination := route.NewVertex(invoice.Destination)
+
+	return &rpcperms.SpendRequest{
+		Amount:      amount,
+		Destination: &destination,
+	}, nil
+}
+
+// routeSpend returns the funds sent along the given route, including the fees
+// paid to the intermediate hops.
+func routeSpend(rt *lnrpc.Route) (*rpcperms.SpendRequest, error) {
+	if rt == nil || len(rt.Hops) == 0 {
+		return nil, errors.New("route without hops")
+	}
+
+	destination, err := route.NewVertexFromStr(
+		rt.Hops[len(rt.Hops)-1].PubKey,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rpcperms.SpendRequest{
+		Amount:      lnwire.MilliSatoshi(rt.TotalAmtMsat),
+		Destination: &destination,
+	}, nil
+}
+
+// onChainSpend returns the funds sent by an on-chain transaction paying the
+// given amount in satoshis.
+func onChainSpend(amt int64) (*rpcperms.SpendRequest, error) {
+	if amt < 0 {
+		return nil, fmt.Errorf("invalid amount: %v", amt)
+	}
+
+	return &rpcperms.SpendRequest{
+		Amount: lnwire.NewMSatFromSatoshis(btcutil.Amount(amt)),
+	}, nil
+}
+
+// sendResponseResult returns the outcome of a payment reported by a
+// lnrpc.SendResponse.
+func sendResponseResult(resp proto.Message) rpcperms.SpendResult {
+	r, ok := resp.(*lnrpc.SendResponse)
+	if ok && r.PaymentError != "" {
+		return rpcperms.SpendFailed
+	}
+
+	return rpcperms.SpendSucceeded
+}
+
+// paymentResult returns the outcome of a payment reported by a lnrpc.Payment.
+func paymentResult(resp proto.Message) rpcperms.SpendResult {
+	payment, ok := resp.(*lnrpc.Payment)
+	if !ok {
+		return rpcperms.SpendPending
+	}
+
+	switch payment.Status {
+	case lnrpc.Payment_SUCCEEDED:
+		return rpcperms.SpendSucceeded
+
+	case lnrpc.Payment_FAILED:
+		return rpcperms.SpendFailed
+
+	default:
+		return rpcperms.SpendPending
+	}
+}
+
+// paymentStatusResult returns the outcome of a payment reported by a
+// routerrpc.PaymentStatus.
+func paymentStatusResult(resp proto.Message) rpcperms.SpendResult {
+	status, ok := resp.(*routerrpc.PaymentStatus)
+	if !ok {
+		return rpcperms.SpendPending
+	}
+
+	switch status.State {
+	case routerrpc.PaymentState_IN_FLIGHT:
+		return rpcperms.SpendPending
+
+	case routerrpc.PaymentState_SUCCEEDED:
+		return rpcperms.SpendSucceeded
+
+	default:
+		return rpcperms.SpendFailed
+	}
+}
+
+// htlcAttemptResult returns the outcome of a payment reported by a
+// lnrpc.HTLCAttempt.
+func htlcAttemptResult(resp proto.Message) rpcperms.SpendResult {
+	attempt, ok := resp.(*lnrpc.HTLCAttempt)
+	if ok && attempt.Status == lnrpc.HTLCAttempt_FAILED {
+		return rpcperms.SpendFailed
+	}
+
+	return rpcperms.SpendSucceeded
+}
+
+// sendToRouteResult returns the outcome of a payment reported by a
+// routerrpc.SendToRouteResponse.
+func sendToRouteResult(resp proto.Message) rpcperms.SpendResult {
+	r, ok := resp.(*routerrpc.SendToRouteResponse)
+	if ok && r.Failure != nil {
+		return rpcperms.SpendFailed
+	}
+
+	return rpcperms.SpendSucceeded
+}
@@ -0,0 +1,136 @@
+package lnd
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/lightningnetwork/lnd/lnrpc"
+	"github.com/lightningnetwork/lnd/lnrpc/routerrpc"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/routing/route"
+	"github.com/lightningnetwork/lnd/rpcperms"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMacaroonSpendParsers tests that the funds sent by payment and on-chain
+// RPCs are extracted correctly.
+func TestMacaroonSpendParsers(t *testing.T) {
+	t.Parallel()
+
+	parsers := macaroonSpendParsers(&chaincfg.RegressionNetParams)
+	dest := route.Vertex{2, 3}
+
+	// A keysend payment sends its amount plus the fee limit to the given
+	// destination.
+	spend, err := parsers["/lnrpc.Lightning/SendPaymentSync"].ParseRequest(
+		&lnrpc.SendRequest{
+			Dest: dest[:],
+			Amt:  10,
+			FeeLimit: &lnrpc.FeeLimit{
+				Limit: &lnrpc.FeeLimit_Fixed{Fixed: 1},
+			},
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, &rpcperms.SpendRequest{
+		Amount:      11_000,
+		Destination: &dest,
+	}, spend)
+
+	spend, err = parsers["/routerrpc.Router/SendPaymentV2"].ParseRequest(
+		&routerrpc.SendPaymentRequest{
+			Dest:         dest[:],
+			AmtMsat:      10_000,
+			FeeLimitMsat: 500,
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, &rpcperms.SpendRequest{
+		Amount:      10_500,
+		Destination: &dest,
+	}, spend)
+
+	// A payment along a route counts the fees of the route.
+	spend, err = parsers["/routerrpc.Router/SendToRouteV2"].ParseRequest(
+		&routerrpc.SendToRouteRequest{
+			Route: &lnrpc.Route{
+				TotalAmtMsat:  1_100,
+				TotalFeesMsat: 100,
+				Hops: []*lnrpc.Hop{{
+					PubKey: route.Vertex{1}.String(),
+				}, {
+					PubKey: dest.String(),
+				}},
+			},
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, &rpcperms.SpendRequest{
+		Amount:      1_100,
+		Destination: &dest,
+	}, spend)
+
+	// On-chain sends have no destination node.
+	spend, err = parsers["/lnrpc.Lightning/SendMany"].ParseRequest(
+		&lnrpc.SendManyRequest{
+			AddrToAmount: map[string]int64{"a": 1, "b": 2},
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, &rpcperms.SpendRequest{
+		Amount: lnwire.NewMSatFromSatoshis(3),
+	}, spend)
+
+	// Sweeping all funds can't be limited, so it is rejected.
+	_, err = parsers["/lnrpc.Lightning/SendCoins"].ParseRequest(
+		&lnrpc.SendCoinsRequest{SendAll: true},
+	)
+	require.ErrorIs(t, err, errSendAllLimited)
+
+	// Methods that move funds the limits can't account for have no spend
+	// parser, so they are rejected for limited macaroons.
+	for _, method := range []string{
+		"/lnrpc.Lightning/OpenChannel",
+		"/lnrpc.Lightning/BatchOpenChannel",
+		"/walletrpc.WalletKit/FundPsbt",
+		"/walletrpc.WalletKit/SignPsbt",
+		"/walletrpc.WalletKit/PublishTransaction",
+		"/walletrpc.WalletKit/BumpFundingFee",
+		"/signrpc.Signer/SignOutputRaw",
+		"/routerrpc.Router/Rebalance",
+	} {
+		require.NotContains(t, parsers, method)
+	}
+
+	// Closing a channel to an external delivery address is rejected.
+	_, err = parsers["/lnrpc.Lightning/CloseChannel"].ParseRequest(
+		&lnrpc.CloseChannelRequest{DeliveryAddress: "bcrt1qexternal"},
+	)
+	require.ErrorIs(t, err, rpcperms.ErrUnlimitedSpend)
+
+	// Methods that don't move funds are allowed without being charged.
+	for _, method := range noSpendMethods {
+		spend, err := parsers[method].ParseRequest(nil)
+		require.NoError(t, err, method)
+		require.Nil(t, spend, method)
+	}
+
+	// Closing a channel to our own wallet doesn't send any funds.
+	spend, err = parsers["/lnrpc.Lightning/CloseChannel"].ParseRequest(
+		&lnrpc.CloseChannelRequest{},
+	)
+	require.NoError(t, err)
+	require.Nil(t, spend)
+
+	// The outcome of a payment is only settled by a final status.
+	paymentParser := parsers["/routerrpc.Router/SendPaymentV2"]
+	require.Equal(t, rpcperms.SpendPending, paymentParser.ParseResponse(
+		&lnrpc.Payment{Status: lnrpc.Payment_IN_FLIGHT},
+	))
+	require.Equal(t, rpcperms.SpendFailed, paymentParser.ParseResponse(
+		&lnrpc.Payment{Status: lnrpc.Payment_FAILED},
+	))
+	require.Equal(t, rpcperms.SpendSucceeded, paymentParser.ParseResponse(
+		&lnrpc.Payment{Status: lnrpc.Payment_SUCCEEDED},
+	))
+}
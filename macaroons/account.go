@@ -0,0 +1,106 @@
+package macaroons
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"gopkg.in/macaroon-bakery.v2/bakery/checkers"
+	macaroon "gopkg.in/macaroon.v2"
+)
+
+const (
+	// CondAccount is the first party caveat condition name that binds a
+	// macaroon to a virtual account. All calls made with such a macaroon
+	// are restricted to the balance, invoices and payments of the account.
+	// The caveat is encoded as "lnd-account <hex-account-id>".
+	CondAccount = "lnd-account"
+
+	// AccountIDLen is the length of an account ID in bytes.
+	AccountIDLen = 8
+)
+
+var (
+	// ErrAccountIDMismatch is returned if a macaroon carries account
+	// caveats for more than one account.
+	ErrAccountIDMismatch = errors.New("macaroon is bound to more than " +
+		"one account")
+)
+
+// AccountConstraint binds the macaroon to the account with the given ID.
+func AccountConstraint(
+	id [AccountIDLen]byte) func(*macaroon.Macaroon) error {
+
+	return func(mac *macaroon.Macaroon) error {
+		caveat := checkers.Condition(
+			CondAccount, hex.EncodeToString(id[:]),
+		)
+		return mac.AddFirstPartyCaveat([]byte(caveat))
+	}
+}
+
+// AccountChecker makes sure account caveats are well-formed. The account
+// itself is enforced by the RPC interceptor chain. It is of the `Checker`
+// type.
+func AccountChecker() (string, checkers.Func) {
+	return CondAccount, func(_ context.Context, _, arg string) error {
+		_, err := parseAccountID(arg)
+		return err
+	}
+}
+
+// ParseAccountID extracts the ID of the account the given macaroon is bound
+// to. The returned boolean is false if the macaroon isn't bound to an
+// account.
+func ParseAccountID(mac *macaroon.Macaroon) ([AccountIDLen]byte, bool,
+	error) {
+
+	var (
+		id    [AccountIDLen]byte
+		found bool
+	)
+	if mac == nil {
+		return id, false, nil
+	}
+
+	for _, caveat := range mac.Caveats() {
+		cond, arg, err := checkers.ParseCaveat(string(caveat.Id))
+		if err != nil || cond != CondAccount {
+			continue
+		}
+
+		caveatID, err := parseAccountID(arg)
+		if err != nil {
+			return id, false, err
+		}
+
+		// Binding a macaroon to a second account must not widen what
+		// the macaroon is allowed to do, so we refuse it altogether.
+		if found && caveatID != id {
+			return id, false, ErrAccountIDMismatch
+		}
+
+		id = caveatID
+		found = true
+	}
+
+	return id, found, nil
+}
+
+// parseAccountID parses the hex encoded account ID of an account caveat.
+func parseAccountID(arg string) ([AccountIDLen]byte, error) {
+	var id [AccountIDLen]byte
+
+	idBytes, err := hex.DecodeString(arg)
+	if err != nil {
+		return id, fmt.Errorf("invalid account ID %v: %w", arg, err)
+	}
+	if len(idBytes) != AccountIDLen {
+		return id, fmt.Errorf("invalid account ID length: %v",
+			len(idBytes))
+	}
+	copy(id[:], idBytes)
+
+	return id, nil
+}
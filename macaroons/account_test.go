@@ -0,0 +1,70 @@
+package macaroons_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lightningnetwork/lnd/macaroons"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseAccountID tests that the account ID is extracted from the account
+// caveat of a macaroon and that a macaroon can't be bound to two accounts.
+func TestParseAccountID(t *testing.T) {
+	t.Parallel()
+
+	var (
+		id1 = [macaroons.AccountIDLen]byte{1, 2, 3}
+		id2 = [macaroons.AccountIDLen]byte{4, 5, 6}
+	)
+
+	// A macaroon without an account caveat isn't bound to an account.
+	mac := createDummyMacaroon(t)
+	_, ok, err := macaroons.ParseAccountID(mac)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	mac, err = macaroons.AddConstraints(
+		mac, macaroons.AccountConstraint(id1),
+	)
+	require.NoError(t, err)
+
+	id, ok, err := macaroons.ParseAccountID(mac)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, id1, id)
+
+	// Adding the same account again is fine.
+	sameMac, err := macaroons.AddConstraints(
+		mac.Clone(), macaroons.AccountConstraint(id1),
+	)
+	require.NoError(t, err)
+
+	id, ok, err = macaroons.ParseAccountID(sameMac)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, id1, id)
+
+	// But binding the macaroon to a second account is not.
+	otherMac, err := macaroons.AddConstraints(
+		mac.Clone(), macaroons.AccountConstraint(id2),
+	)
+	require.NoError(t, err)
+
+	_, _, err = macaroons.ParseAccountID(otherMac)
+	require.ErrorIs(t, err, macaroons.ErrAccountIDMismatch)
+}
+
+// TestAccountChecker tests that the account checker only accepts well-formed
+// account IDs.
+func TestAccountChecker(t *testing.T) {
+	t.Parallel()
+
+	cond, check := macaroons.AccountChecker()
+	require.Equal(t, macaroons.CondAccount, cond)
+
+	ctx := context.Background()
+	require.NoError(t, check(ctx, cond, "0102030405060708"))
+	require.Error(t, check(ctx, cond, "01020304"))
+	require.Error(t, check(ctx, cond, "not hex"))
+}
@@ -0,0 +1,355 @@
+package macaroons
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/routing/route"
+	"gopkg.in/macaroon-bakery.v2/bakery/checkers"
+	macaroon "gopkg.in/macaroon.v2"
+)
+
+const (
+	// CondSpendLimit is the first party caveat condition name that limits
+	// the total amount a macaroon can send within a time window. The
+	// caveat is encoded as "lnd-spend-limit <amount-msat> <window-secs>".
+	CondSpendLimit = "lnd-spend-limit"
+
+	// CondMaxPayment is the first party caveat condition name that limits
+	// the amount of a single payment. The caveat is encoded as
+	// "lnd-max-payment <amount-msat>".
+	CondMaxPayment = "lnd-max-payment"
+
+	// CondDestinations is the first party caveat condition name that
+	// restricts the nodes a macaroon can send payments to. The caveat is
+	// encoded as "lnd-destinations <pubkey>[,<pubkey>...]".
+	CondDestinations = "lnd-destinations"
+
+	// CondRateLimit is the first party caveat condition name that limits
+	// the number of calls a macaroon can make per minute. The caveat is
+	// encoded as "lnd-rate-limit <calls-per-minute>".
+	CondRateLimit = "lnd-rate-limit"
+)
+
+var (
+	// ErrSpendLimitExceeded is returned when a payment would exceed the
+	// spending limit of a macaroon within its current time window.
+	ErrSpendLimitExceeded = errors.New("macaroon spending limit exceeded")
+
+	// ErrMaxPaymentExceeded is returned when a payment exceeds the
+	// maximum payment amount allowed by a macaroon.
+	ErrMaxPaymentExceeded = errors.New("payment amount exceeds the " +
+		"maximum allowed by the macaroon")
+
+	// ErrDestinationNotAllowed is returned when a payment is sent to a
+	// destination that isn't allowed by a macaroon.
+	ErrDestinationNotAllowed = errors.New("payment destination not " +
+		"allowed by the macaroon")
+
+	// ErrRateLimitExceeded is returned when a macaroon made more calls in
+	// the last minute than it is allowed to.
+	ErrRateLimitExceeded = errors.New("macaroon rate limit exceeded")
+)
+
+// Limits are the spending and rate limits encoded in the caveats of a
+// macaroon. If a macaroon carries the same kind of caveat more than once, the
+// most restrictive combination of them is used, since adding a caveat must
+// never widen what a macaroon is allowed to do.
+type Limits struct {
+	// SpendLimit is the total amount that can be sent within SpendWindow.
+	// A zero SpendWindow means there's no spending limit.
+	SpendLimit lnwire.MilliSatoshi
+
+	// SpendWindow is the duration of the window the spending limit
+	// applies to.
+	SpendWindow time.Duration
+
+	// MaxPayment is the maximum amount of a single payment. It is only
+	// enforced if HasMaxPayment is set.
+	MaxPayment lnwire.MilliSatoshi
+
+	// HasMaxPayment is true if the macaroon limits the amount of a single
+	// payment.
+	HasMaxPayment bool
+
+	// Destinations is the set of nodes payments can be sent to. A nil
+	// map means payments can be sent to any node.
+	Destinations map[route.Vertex]struct{}
+
+	// MaxCallsPerMinute is the maximum number of calls per minute. Zero
+	// means there's no rate limit.
+	MaxCallsPerMinute uint32
+}
+
+// HasSpendLimit returns true if the macaroon limits the total amount it can
+// send within a time window.
+func (l *Limits) HasSpendLimit() bool {
+	return l.SpendWindow > 0
+}
+
+// CheckPayment makes sure a single payment of the given amount to the given
+// destination is allowed. A nil destination means the funds aren't sent to a
+// node but on-chain, which is never allowed if the macaroon restricts the
+// payment destinations.
+func (l *Limits) CheckPayment(amt lnwire.MilliSatoshi,
+	dest *route.Vertex) error {
+
+	if l.HasMaxPayment && amt > l.MaxPayment {
+		return fmt.Errorf("%w: %v > %v", ErrMaxPaymentExceeded, amt,
+			l.MaxPayment)
+	}
+
+	if l.Destinations == nil {
+		return nil
+	}
+
+	if dest == nil {
+		return fmt.Errorf("%w: on-chain sends are not allowed",
+			ErrDestinationNotAllowed)
+	}
+	if _, ok := l.Destinations[*dest]; !ok {
+		return fmt.Errorf("%w: %v", ErrDestinationNotAllowed, dest)
+	}
+
+	return nil
+}
+
+// SpendLimitConstraint restricts the total amount the macaroon can send
+// within the given time window.
+func SpendLimitConstraint(amt lnwire.MilliSatoshi,
+	window time.Duration) func(*macaroon.Macaroon) error {
+
+	return func(mac *macaroon.Macaroon) error {
+		if window < time.Second {
+			return fmt.Errorf("spend limit window must be at " +
+				"least one second")
+		}
+
+		caveat := checkers.Condition(CondSpendLimit, fmt.Sprintf(
+			"%d %d", uint64(amt), int64(window/time.Second),
+		))
+		return mac.AddFirstPartyCaveat([]byte(caveat))
+	}
+}
+
+// MaxPaymentConstraint restricts the amount of a single payment made with the
+// macaroon.
+func MaxPaymentConstraint(
+	amt lnwire.MilliSatoshi) func(*macaroon.Macaroon) error {
+
+	return func(mac *macaroon.Macaroon) error {
+		caveat := checkers.Condition(
+			CondMaxPayment, strconv.FormatUint(uint64(amt), 10),
+		)
+		return mac.AddFirstPartyCaveat([]byte(caveat))
+	}
+}
+
+// DestinationsConstraint restricts the nodes the macaroon can send payments
+// to.
+func DestinationsConstraint(
+	dests ...route.Vertex) func(*macaroon.Macaroon) error {
+
+	return func(mac *macaroon.Macaroon) error {
+		if len(dests) == 0 {
+			return fmt.Errorf("at least one destination must be " +
+				"given")
+		}
+
+		hexDests := make([]string, 0, len(dests))
+		for _, dest := range dests {
+			hexDests = append(hexDests, dest.String())
+		}
+
+		caveat := checkers.Condition(
+			CondDestinations, strings.Join(hexDests, ","),
+		)
+		return mac.AddFirstPartyCaveat([]byte(caveat))
+	}
+}
+
+// RateLimitConstraint restricts the number of calls the macaroon can make per
+// minute.
+func RateLimitConstraint(
+	callsPerMinute uint32) func(*macaroon.Macaroon) error {
+
+	return func(mac *macaroon.Macaroon) error {
+		if callsPerMinute == 0 {
+			return fmt.Errorf("rate limit must be greater than 0")
+		}
+
+		caveat := checkers.Condition(
+			CondRateLimit, strconv.FormatUint(
+				uint64(callsPerMinute), 10,
+			),
+		)
+		return mac.AddFirstPartyCaveat([]byte(caveat))
+	}
+}
+
+// limitChecker returns a Checker for the given limit condition. The macaroon
+// bakery only makes sure the caveat is well-formed, the limits themselves are
+// enforced by the RPC interceptor chain, as that is the only place that knows
+// about the content of a request.
+func limitChecker(cond string) Checker {
+	return func() (string, checkers.Func) {
+		return cond, func(_ context.Context, _, arg string) error {
+			return parseLimit(&Limits{}, cond, arg)
+		}
+	}
+}
+
+// SpendLimitChecker makes sure spend limit caveats are well-formed. It is of
+// the `Checker` type.
+func SpendLimitChecker() (string, checkers.Func) {
+	return limitChecker(CondSpendLimit)()
+}
+
+// MaxPaymentChecker makes sure max payment caveats are well-formed. It is of
+// the `Checker` type.
+func MaxPaymentChecker() (string, checkers.Func) {
+	return limitChecker(CondMaxPayment)()
+}
+
+// DestinationsChecker makes sure destination caveats are well-formed. It is of
+// the `Checker` type.
+func DestinationsChecker() (string, checkers.Func) {
+	return limitChecker(CondDestinations)()
+}
+
+// RateLimitChecker makes sure rate limit caveats are well-formed. It is of the
+// `Checker` type.
+func RateLimitChecker() (string, checkers.Func) {
+	return limitChecker(CondRateLimit)()
+}
+
+// ParseLimits extracts the spending and rate limits from the caveats of the
+// given macaroon. If the macaroon doesn't carry any limit caveats, nil is
+// returned.
+func ParseLimits(mac *macaroon.Macaroon) (*Limits, error) {
+	if mac == nil {
+		return nil, nil
+	}
+
+	var (
+		limits    Limits
+		hasLimits bool
+	)
+	for _, caveat := range mac.Caveats() {
+		cond, arg, err := checkers.ParseCaveat(string(caveat.Id))
+		if err != nil {
+			continue
+		}
+
+		switch cond {
+		case CondSpendLimit, CondMaxPayment, CondDestinations,
+			CondRateLimit:
+
+		default:
+			continue
+		}
+
+		if err := parseLimit(&limits, cond, arg); err != nil {
+			return nil, err
+		}
+		hasLimits = true
+	}
+
+	if !hasLimits {
+		return nil, nil
+	}
+
+	return &limits, nil
+}
+
+// parseLimit parses the argument of a single limit caveat and merges it into
+// the given limits, keeping the most restrictive value.
+func parseLimit(limits *Limits, cond, arg string) error {
+	switch cond {
+	case CondSpendLimit:
+		parts := strings.Split(arg, " ")
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid spend limit caveat: %v", arg)
+		}
+		amt, err := strconv.ParseUint(parts[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid spend limit amount: %w", err)
+		}
+		secs, err := strconv.ParseUint(parts[1], 10, 32)
+		if err != nil || secs == 0 {
+			return fmt.Errorf("invalid spend limit window: %v",
+				parts[1])
+		}
+		window := time.Duration(secs) * time.Second
+
+		// The smallest amount over the longest window is at least as
+		// restrictive as each of the individual limits.
+		if !limits.HasSpendLimit() ||
+			lnwire.MilliSatoshi(amt) < limits.SpendLimit {
+
+			limits.SpendLimit = lnwire.MilliSatoshi(amt)
+		}
+		if window > limits.SpendWindow {
+			limits.SpendWindow = window
+		}
+
+	case CondMaxPayment:
+		amt, err := strconv.ParseUint(arg, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid max payment amount: %w", err)
+		}
+
+		if !limits.HasMaxPayment ||
+			lnwire.MilliSatoshi(amt) < limits.MaxPayment {
+
+			limits.MaxPayment = lnwire.MilliSatoshi(amt)
+		}
+		limits.HasMaxPayment = true
+
+	case CondDestinations:
+		dests := make(map[route.Vertex]struct{})
+		for _, hexDest := range strings.Split(arg, ",") {
+			destBytes, err := hex.DecodeString(hexDest)
+			if err != nil {
+				return fmt.Errorf("invalid destination %v: %w",
+					hexDest, err)
+			}
+			dest, err := route.NewVertexFromBytes(destBytes)
+			if err != nil {
+				return fmt.Errorf("invalid destination %v: %w",
+					hexDest, err)
+			}
+
+			// Only keep destinations that are allowed by all
+			// previous destination caveats as well.
+			_, allowed := limits.Destinations[dest]
+			if limits.Destinations == nil || allowed {
+				dests[dest] = struct{}{}
+			}
+		}
+		limits.Destinations = dests
+
+	case CondRateLimit:
+		calls, err := strconv.ParseUint(arg, 10, 32)
+		if err != nil || calls == 0 {
+			return fmt.Errorf("invalid rate limit: %v", arg)
+		}
+
+		if limits.MaxCallsPerMinute == 0 ||
+			uint32(calls) < limits.MaxCallsPerMinute {
+
+			limits.MaxCallsPerMinute = uint32(calls)
+		}
+
+	default:
+		return fmt.Errorf("unknown limit caveat: %v", cond)
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+package macaroons_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/macaroons"
+	"github.com/lightningnetwork/lnd/routing/route"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseLimits tests that the limit caveats of a macaroon are parsed and
+// that multiple caveats of the same kind are merged into the most restrictive
+// limits.
+func TestParseLimits(t *testing.T) {
+	t.Parallel()
+
+	var (
+		dest1 = route.Vertex{1}
+		dest2 = route.Vertex{2}
+		dest3 = route.Vertex{3}
+	)
+
+	// A macaroon without limit caveats has no limits.
+	mac := createDummyMacaroon(t)
+	limits, err := macaroons.ParseLimits(mac)
+	require.NoError(t, err)
+	require.Nil(t, limits)
+
+	mac, err = macaroons.AddConstraints(
+		mac,
+		macaroons.TimeoutConstraint(60),
+		macaroons.SpendLimitConstraint(10_000, time.Hour),
+		macaroons.SpendLimitConstraint(20_000, 24*time.Hour),
+		macaroons.MaxPaymentConstraint(5_000),
+		macaroons.MaxPaymentConstraint(7_000),
+		macaroons.DestinationsConstraint(dest1, dest2),
+		macaroons.DestinationsConstraint(dest2, dest3),
+		macaroons.RateLimitConstraint(10),
+		macaroons.RateLimitConstraint(20),
+	)
+	require.NoError(t, err)
+
+	limits, err = macaroons.ParseLimits(mac)
+	require.NoError(t, err)
+	require.NotNil(t, limits)
+
+	require.True(t, limits.HasSpendLimit())
+	require.Equal(t, lnwire.MilliSatoshi(10_000), limits.SpendLimit)
+	require.Equal(t, 24*time.Hour, limits.SpendWindow)
+	require.True(t, limits.HasMaxPayment)
+	require.Equal(t, lnwire.MilliSatoshi(5_000), limits.MaxPayment)
+	require.Equal(t, map[route.Vertex]struct{}{
+		dest2: {},
+	}, limits.Destinations)
+	require.Equal(t, uint32(10), limits.MaxCallsPerMinute)
+
+	// Only payments to the remaining destination within the maximum
+	// payment amount are allowed.
+	require.NoError(t, limits.CheckPayment(5_000, &dest2))
+	require.ErrorIs(
+		t, limits.CheckPayment(5_001, &dest2),
+		macaroons.ErrMaxPaymentExceeded,
+	)
+	require.ErrorIs(
+		t, limits.CheckPayment(1_000, &dest1),
+		macaroons.ErrDestinationNotAllowed,
+	)
+	require.ErrorIs(
+		t, limits.CheckPayment(1_000, nil),
+		macaroons.ErrDestinationNotAllowed,
+	)
+}
+
+// TestLimitCheckers tests that the bakery checkers of the limit caveats reject
+// malformed caveats.
+func TestLimitCheckers(t *testing.T) {
+	t.Parallel()
+
+	validDests := route.Vertex{1}.String() + "," + route.Vertex{2}.String()
+
+	testCases := []struct {
+		name    string
+		checker macaroons.Checker
+		arg     string
+		valid   bool
+	}{{
+		name:    "valid spend limit",
+		checker: macaroons.SpendLimitChecker,
+		arg:     "1000 3600",
+		valid:   true,
+	}, {
+		name:    "spend limit without window",
+		checker: macaroons.SpendLimitChecker,
+		arg:     "1000",
+	}, {
+		name:    "spend limit with zero window",
+		checker: macaroons.SpendLimitChecker,
+		arg:     "1000 0",
+	}, {
+		name:    "valid max payment",
+		checker: macaroons.MaxPaymentChecker,
+		arg:     "1000",
+		valid:   true,
+	}, {
+		name:    "invalid max payment",
+		checker: macaroons.MaxPaymentChecker,
+		arg:     "-1",
+	}, {
+		name:    "valid destinations",
+		checker: macaroons.DestinationsChecker,
+		arg:     validDests,
+		valid:   true,
+	}, {
+		name:    "invalid destination",
+		checker: macaroons.DestinationsChecker,
+		arg:     "abcd",
+	}, {
+		name:    "valid rate limit",
+		checker: macaroons.RateLimitChecker,
+		arg:     "5",
+		valid:   true,
+	}, {
+		name:    "zero rate limit",
+		checker: macaroons.RateLimitChecker,
+		arg:     "0",
+	}}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			cond, check := tc.checker()
+			err := check(context.Background(), cond, tc.arg)
+			if tc.valid {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package macaroons
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/btcsuite/btcwallet/snacl"
+	"github.com/lightningnetwork/lnd/kvdb"
+)
+
+var (
+	// secretsBucketName is the name of the bucket that stores arbitrary
+	// secrets of other subsystems, encrypted with the same encryption key
+	// as the macaroon root keys.
+	//
+	// maps: secret ID -> encrypted secret
+	secretsBucketName = []byte("macsecrets")
+
+	// ErrSecretNotFound is returned when a secret with the given ID
+	// doesn't exist.
+	ErrSecretNotFound = fmt.Errorf("secret not found")
+
+	// ErrSecretsUnsupported is returned when the root key store of the
+	// macaroon service can't store secrets.
+	ErrSecretsUnsupported = fmt.Errorf("root key store doesn't support " +
+		"storing secrets")
+)
+
+// SecretStore is an interface for storing secrets of other subsystems in the
+// macaroon database, encrypted with the macaroon encryption key. The store
+// must be unlocked before any secrets can be accessed.
+type SecretStore interface {
+	// StoreSecret encrypts the given secret and stores it under the
+	// given ID, replacing any previous secret with the same ID.
+	StoreSecret(id, secret []byte) error
+
+	// FetchSecret returns the decrypted secret stored under the given ID.
+	FetchSecret(id []byte) ([]byte, error)
+
+	// DeleteSecret removes the secret stored under the given ID.
+	DeleteSecret(id []byte) error
+
+	// ListSecrets returns all decrypted secrets whose ID starts with the
+	// given prefix, keyed by their ID.
+	ListSecrets(prefix []byte) (map[string][]byte, error)
+}
+
+// A compile time check to ensure RootKeyStorage implements the SecretStore
+// interface.
+var _ SecretStore = (*RootKeyStorage)(nil)
+
+// StoreSecret encrypts the given secret and stores it under the given ID,
+// replacing any previous secret with the same ID.
+//
+// NOTE: This is part of the SecretStore interface.
+func (r *RootKeyStorage) StoreSecret(id, secret []byte) error {
+	r.encKeyMtx.RLock()
+	defer r.encKeyMtx.RUnlock()
+
+	if r.encKey == nil {
+		return ErrStoreLocked
+	}
+	if len(id) == 0 {
+		return fmt.Errorf("secret ID must not be empty")
+	}
+
+	encryptedSecret, err := r.encKey.Encrypt(secret)
+	if err != nil {
+		return err
+	}
+
+	return kvdb.Update(r.Backend, func(tx kvdb.RwTx) error {
+		bucket, err := tx.CreateTopLevelBucket(secretsBucketName)
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put(id, encryptedSecret)
+	}, func() {})
+}
+
+// FetchSecret returns the decrypted secret stored under the given ID.
+//
+// NOTE: This is part of the SecretStore interface.
+func (r *RootKeyStorage) FetchSecret(id []byte) ([]byte, error) {
+	r.encKeyMtx.RLock()
+	defer r.encKeyMtx.RUnlock()
+
+	if r.encKey == nil {
+		return nil, ErrStoreLocked
+	}
+
+	var secret []byte
+	err := kvdb.View(r.Backend, func(tx kvdb.RTx) error {
+		bucket := tx.ReadBucket(secretsBucketName)
+		if bucket == nil {
+			return ErrSecretNotFound
+		}
+
+		encryptedSecret := bucket.Get(id)
+		if encryptedSecret == nil {
+			return ErrSecretNotFound
+		}
+
+		decrypted, err := r.encKey.Decrypt(encryptedSecret)
+		if err != nil {
+			return err
+		}
+
+		secret = make([]byte, len(decrypted))
+		copy(secret, decrypted)
+
+		return nil
+	}, func() {
+		secret = nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
+
+// DeleteSecret removes the secret stored under the given ID.
+//
+// NOTE: This is part of the SecretStore interface.
+func (r *RootKeyStorage) DeleteSecret(id []byte) error {
+	return kvdb.Update(r.Backend, func(tx kvdb.RwTx) error {
+		bucket := tx.ReadWriteBucket(secretsBucketName)
+		if bucket == nil || bucket.Get(id) == nil {
+			return ErrSecretNotFound
+		}
+
+		return bucket.Delete(id)
+	}, func() {})
+}
+
+// ListSecrets returns all decrypted secrets whose ID starts with the given
+// prefix, keyed by their ID.
+//
+// NOTE: This is part of the SecretStore interface.
+func (r *RootKeyStorage) ListSecrets(prefix []byte) (map[string][]byte,
+	error) {
+
+	r.encKeyMtx.RLock()
+	defer r.encKeyMtx.RUnlock()
+
+	if r.encKey == nil {
+		return nil, ErrStoreLocked
+	}
+
+	secrets := make(map[string][]byte)
+	err := kvdb.View(r.Backend, func(tx kvdb.RTx) error {
+		bucket := tx.ReadBucket(secretsBucketName)
+		if bucket == nil {
+			return nil
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			if !bytes.HasPrefix(k, prefix) {
+				return nil
+			}
+
+			decrypted, err := r.encKey.Decrypt(v)
+			if err != nil {
+				return err
+			}
+
+			secret := make([]byte, len(decrypted))
+			copy(secret, decrypted)
+			secrets[string(k)] = secret
+
+			return nil
+		})
+	}, func() {
+		secrets = make(map[string][]byte)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return secrets, nil
+}
+
+// reencryptSecrets decrypts all secrets with the old encryption key and
+// encrypts them again with the new one.
+func reencryptSecrets(tx kvdb.RwTx, encKeyOld,
+	encKeyNew *snacl.SecretKey) error {
+
+	bucket := tx.ReadWriteBucket(secretsBucketName)
+	if bucket == nil {
+		return nil
+	}
+
+	// We can't modify the bucket while iterating over it, so we collect
+	// the re-encrypted secrets first.
+	reencrypted := make(map[string][]byte)
+	err := bucket.ForEach(func(k, v []byte) error {
+		decrypted, err := encKeyOld.Decrypt(v)
+		if err != nil {
+			return err
+		}
+
+		encrypted, err := encKeyNew.Encrypt(decrypted)
+		if err != nil {
+			return err
+		}
+
+		reencrypted[string(k)] = encrypted
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for k, v := range reencrypted {
+		if err := bucket.Put([]byte(k), v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// StoreSecret encrypts the given secret with the macaroon encryption key and
+// stores it in the macaroon database.
+func (svc *Service) StoreSecret(id, secret []byte) error {
+	secretStore, ok := svc.rks.(SecretStore)
+	if !ok {
+		return ErrSecretsUnsupported
+	}
+
+	return secretStore.StoreSecret(id, secret)
+}
+
+// FetchSecret returns the decrypted secret stored under the given ID.
+func (svc *Service) FetchSecret(id []byte) ([]byte, error) {
+	secretStore, ok := svc.rks.(SecretStore)
+	if !ok {
+		return nil, ErrSecretsUnsupported
+	}
+
+	return secretStore.FetchSecret(id)
+}
+
+// DeleteSecret removes the secret stored under the given ID.
+func (svc *Service) DeleteSecret(id []byte) error {
+	secretStore, ok := svc.rks.(SecretStore)
+	if !ok {
+		return ErrSecretsUnsupported
+	}
+
+	return secretStore.DeleteSecret(id)
+}
+
+// ListSecrets returns all decrypted secrets whose ID starts with the given
+// prefix, keyed by their ID.
+func (svc *Service) ListSecrets(prefix []byte) (map[string][]byte, error) {
+	secretStore, ok := svc.rks.(SecretStore)
+	if !ok {
+		return nil, ErrSecretsUnsupported
+	}
+
+	return secretStore.ListSecrets(prefix)
+}
@@ -0,0 +1,84 @@
+package macaroons_test
+
+import (
+	"testing"
+
+	"github.com/lightningnetwork/lnd/macaroons"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStoreSecrets tests storing, listing and deleting secrets in the root key
+// store.
+func TestStoreSecrets(t *testing.T) {
+	_, store := newTestStore(t)
+
+	// The store must be unlocked to access any secrets.
+	err := store.StoreSecret([]byte("a/1"), []byte("secret"))
+	require.Equal(t, macaroons.ErrStoreLocked, err)
+
+	_, err = store.FetchSecret([]byte("a/1"))
+	require.Equal(t, macaroons.ErrStoreLocked, err)
+
+	pw := []byte("weks")
+	require.NoError(t, store.CreateUnlock(&pw))
+
+	_, err = store.FetchSecret([]byte("a/1"))
+	require.ErrorIs(t, err, macaroons.ErrSecretNotFound)
+
+	require.NoError(t, store.StoreSecret([]byte("a/1"), []byte("one")))
+	require.NoError(t, store.StoreSecret([]byte("a/2"), []byte("two")))
+	require.NoError(t, store.StoreSecret([]byte("b/1"), []byte("three")))
+
+	secret, err := store.FetchSecret([]byte("a/1"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("one"), secret)
+
+	// Only the secrets with the given prefix should be listed.
+	secrets, err := store.ListSecrets([]byte("a/"))
+	require.NoError(t, err)
+	require.Equal(t, map[string][]byte{
+		"a/1": []byte("one"),
+		"a/2": []byte("two"),
+	}, secrets)
+
+	// Replacing a secret should overwrite the old value.
+	require.NoError(t, store.StoreSecret([]byte("a/1"), []byte("new")))
+	secret, err = store.FetchSecret([]byte("a/1"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("new"), secret)
+
+	require.NoError(t, store.DeleteSecret([]byte("a/1")))
+	_, err = store.FetchSecret([]byte("a/1"))
+	require.ErrorIs(t, err, macaroons.ErrSecretNotFound)
+
+	err = store.DeleteSecret([]byte("a/1"))
+	require.ErrorIs(t, err, macaroons.ErrSecretNotFound)
+}
+
+// TestStoreSecretsChangePassword tests that stored secrets can still be read
+// after the password of the store was changed.
+func TestStoreSecretsChangePassword(t *testing.T) {
+	tempDir, store := newTestStore(t)
+
+	pw := []byte("weks")
+	require.NoError(t, store.CreateUnlock(&pw))
+
+	// The default root key must exist to change the password.
+	_, _, err := store.RootKey(defaultRootKeyIDContext)
+	require.NoError(t, err)
+
+	require.NoError(t, store.StoreSecret([]byte("id"), []byte("secret")))
+
+	newPw := []byte("newpassword")
+	require.NoError(t, store.ChangePassword(pw, newPw))
+
+	require.NoError(t, store.Close())
+	require.NoError(t, store.Backend.Close())
+
+	store = openTestStore(t, tempDir)
+	require.NoError(t, store.CreateUnlock(&newPw))
+
+	secret, err := store.FetchSecret([]byte("id"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("secret"), secret)
+}
@@ -144,8 +144,8 @@ func (r *RootKeyStorage) CreateUnlock(password *[]byte) error {
 	}, func() {})
 }
 
-// ChangePassword decrypts all the macaroon root keys with the old password and
-// then encrypts them again with the new password.
+// ChangePassword decrypts all the macaroon root keys and stored secrets with
+// the old password and then encrypts them again with the new password.
 func (r *RootKeyStorage) ChangePassword(oldPw, newPw []byte) error {
 	// We need the store to already be unlocked. With this we can make sure
 	// that there already is a key in the DB.
@@ -229,6 +229,13 @@ func (r *RootKeyStorage) ChangePassword(oldPw, newPw []byte) error {
 			return ErrDefaultRootKeyNotFound
 		}
 
+		// The secrets of other subsystems are encrypted with the same
+		// key, so they need to be re-encrypted as well.
+		err = reencryptSecrets(tx, encKeyOld, encKeyNew)
+		if err != nil {
+			return err
+		}
+
 		// Finally, store the new encryption key parameters in the DB
 		// as well.
 		err = bucket.Put(encryptionKeyID, encKeyNew.Marshal())
@@ -0,0 +1,340 @@
+package macaroons
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/lightningnetwork/lnd/kvdb"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/tlv"
+)
+
+var (
+	// usageBucketName is the name of the bucket that stores the usage of
+	// macaroons that carry spending or rate limit caveats.
+	//
+	// maps: root key ID -> TLV encoded Usage
+	usageBucketName = []byte("macusage")
+
+	// ErrUsageBucketNotFound is returned when the macaroon usage bucket
+	// doesn't exist.
+	ErrUsageBucketNotFound = fmt.Errorf("macaroon usage bucket not found")
+)
+
+const (
+	// rateLimitWindow is the window the rate limit of a macaroon applies
+	// to.
+	rateLimitWindow = time.Minute
+
+	typeWindowStart tlv.Type = 0
+	typeWindowSpent tlv.Type = 1
+	typeTotalSpent  tlv.Type = 2
+	typeNumPayments tlv.Type = 3
+	typeMinuteStart tlv.Type = 4
+	typeMinuteCalls tlv.Type = 5
+	typeTotalCalls  tlv.Type = 6
+	typeLastUsed    tlv.Type = 7
+)
+
+// Usage is the usage of all macaroons derived from the same root key ID that
+// carry spending or rate limit caveats.
+type Usage struct {
+	// WindowStart is the start of the current spend limit window.
+	WindowStart time.Time
+
+	// WindowSpent is the amount sent within the current spend limit
+	// window.
+	WindowSpent lnwire.MilliSatoshi
+
+	// TotalSpent is the total amount sent.
+	TotalSpent lnwire.MilliSatoshi
+
+	// NumPayments is the total number of payments made.
+	NumPayments uint64
+
+	// MinuteStart is the start of the current rate limit window.
+	MinuteStart time.Time
+
+	// MinuteCalls is the number of calls made within the current rate
+	// limit window.
+	MinuteCalls uint32
+
+	// TotalCalls is the total number of calls made.
+	TotalCalls uint64
+
+	// LastUsed is the time of the last call.
+	LastUsed time.Time
+}
+
+// SpendReservation is an amount that was reserved from the spending budget of
+// a root key ID. It can be released again if the payment fails.
+type SpendReservation struct {
+	rootKeyID   []byte
+	amt         lnwire.MilliSatoshi
+	windowStart time.Time
+}
+
+// UsageStore keeps track of the usage of macaroons that carry spending or
+// rate limit caveats, keyed by their root key ID. The usage is persisted so
+// budgets survive restarts.
+type UsageStore struct {
+	db kvdb.Backend
+}
+
+// NewUsageStore creates a new UsageStore backed by the given database.
+func NewUsageStore(db kvdb.Backend) (*UsageStore, error) {
+	err := kvdb.Update(db, func(tx kvdb.RwTx) error {
+		_, err := tx.CreateTopLevelBucket(usageBucketName)
+		return err
+	}, func() {})
+	if err != nil {
+		return nil, err
+	}
+
+	return &UsageStore{
+		db: db,
+	}, nil
+}
+
+// RecordCall records a call made with a macaroon derived from the given root
+// key ID. If maxCallsPerMinute is non-zero and the call would exceed it,
+// ErrRateLimitExceeded is returned and the call isn't recorded.
+func (s *UsageStore) RecordCall(rootKeyID []byte, maxCallsPerMinute uint32,
+	now time.Time) error {
+
+	return s.update(rootKeyID, func(u *Usage) error {
+		if now.Sub(u.MinuteStart) >= rateLimitWindow {
+			u.MinuteStart = now
+			u.MinuteCalls = 0
+		}
+
+		if maxCallsPerMinute != 0 &&
+			u.MinuteCalls >= maxCallsPerMinute {
+
+			return fmt.Errorf("%w: %d calls per minute allowed",
+				ErrRateLimitExceeded, maxCallsPerMinute)
+		}
+
+		u.MinuteCalls++
+		u.TotalCalls++
+		u.LastUsed = now
+
+		return nil
+	})
+}
+
+// ReserveSpend reserves the given amount from the spending budget of the
+// given root key ID. If the spend would exceed the spend limit within the
+// current window, ErrSpendLimitExceeded is returned and nothing is reserved.
+func (s *UsageStore) ReserveSpend(rootKeyID []byte, limits *Limits,
+	amt lnwire.MilliSatoshi, now time.Time) (*SpendReservation, error) {
+
+	var reservation *SpendReservation
+	err := s.update(rootKeyID, func(u *Usage) error {
+		if limits.HasSpendLimit() {
+			if now.Sub(u.WindowStart) >= limits.SpendWindow {
+				u.WindowStart = now
+				u.WindowSpent = 0
+			}
+
+			if u.WindowSpent+amt > limits.SpendLimit {
+				return fmt.Errorf("%w: %v of %v spent in the "+
+					"current window", ErrSpendLimitExceeded,
+					u.WindowSpent, limits.SpendLimit)
+			}
+		}
+
+		u.WindowSpent += amt
+		u.TotalSpent += amt
+		u.NumPayments++
+
+		reservation = &SpendReservation{
+			rootKeyID:   rootKeyID,
+			amt:         amt,
+			windowStart: u.WindowStart,
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return reservation, nil
+}
+
+// ReleaseSpend returns a previously reserved amount to the spending budget,
+// for example because the payment failed.
+func (s *UsageStore) ReleaseSpend(r *SpendReservation) error {
+	return s.update(r.rootKeyID, func(u *Usage) error {
+		// Only give the amount back to the current window if it was
+		// reserved within it.
+		sameWindow := u.WindowStart.Equal(r.windowStart)
+		if sameWindow && u.WindowSpent >= r.amt {
+			u.WindowSpent -= r.amt
+		}
+		if u.TotalSpent >= r.amt {
+			u.TotalSpent -= r.amt
+		}
+		if u.NumPayments > 0 {
+			u.NumPayments--
+		}
+
+		return nil
+	})
+}
+
+// FetchUsage returns the usage of the given root key ID. If no usage was
+// recorded for it yet, an empty Usage is returned.
+func (s *UsageStore) FetchUsage(rootKeyID []byte) (*Usage, error) {
+	usage := &Usage{}
+	err := kvdb.View(s.db, func(tx kvdb.RTx) error {
+		bucket := tx.ReadBucket(usageBucketName)
+		if bucket == nil {
+			return ErrUsageBucketNotFound
+		}
+
+		v := bucket.Get(rootKeyID)
+		if v == nil {
+			return nil
+		}
+
+		return deserializeUsage(bytes.NewReader(v), usage)
+	}, func() {
+		usage = &Usage{}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return usage, nil
+}
+
+// DeleteUsage removes the usage of the given root key ID.
+func (s *UsageStore) DeleteUsage(rootKeyID []byte) error {
+	return kvdb.Update(s.db, func(tx kvdb.RwTx) error {
+		bucket := tx.ReadWriteBucket(usageBucketName)
+		if bucket == nil {
+			return ErrUsageBucketNotFound
+		}
+
+		return bucket.Delete(rootKeyID)
+	}, func() {})
+}
+
+// update applies the given modification to the usage of the given root key ID
+// and persists the result if the modification succeeds. Since the usage is
+// read and written within the same database transaction, concurrent updates
+// can't overwrite each other.
+func (s *UsageStore) update(rootKeyID []byte,
+	modify func(*Usage) error) error {
+
+	return kvdb.Update(s.db, func(tx kvdb.RwTx) error {
+		bucket := tx.ReadWriteBucket(usageBucketName)
+		if bucket == nil {
+			return ErrUsageBucketNotFound
+		}
+
+		usage := &Usage{}
+		if v := bucket.Get(rootKeyID); v != nil {
+			err := deserializeUsage(bytes.NewReader(v), usage)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := modify(usage); err != nil {
+			return err
+		}
+
+		var b bytes.Buffer
+		if err := serializeUsage(&b, usage); err != nil {
+			return err
+		}
+
+		return bucket.Put(rootKeyID, b.Bytes())
+	}, func() {})
+}
+
+// serializeUsage writes the TLV encoding of the given usage to w.
+func serializeUsage(w io.Writer, u *Usage) error {
+	var (
+		windowStart = timeToUnixNano(u.WindowStart)
+		windowSpent = uint64(u.WindowSpent)
+		totalSpent  = uint64(u.TotalSpent)
+		minuteStart = timeToUnixNano(u.MinuteStart)
+		lastUsed    = timeToUnixNano(u.LastUsed)
+	)
+
+	stream, err := tlv.NewStream(
+		tlv.MakePrimitiveRecord(typeWindowStart, &windowStart),
+		tlv.MakePrimitiveRecord(typeWindowSpent, &windowSpent),
+		tlv.MakePrimitiveRecord(typeTotalSpent, &totalSpent),
+		tlv.MakePrimitiveRecord(typeNumPayments, &u.NumPayments),
+		tlv.MakePrimitiveRecord(typeMinuteStart, &minuteStart),
+		tlv.MakePrimitiveRecord(typeMinuteCalls, &u.MinuteCalls),
+		tlv.MakePrimitiveRecord(typeTotalCalls, &u.TotalCalls),
+		tlv.MakePrimitiveRecord(typeLastUsed, &lastUsed),
+	)
+	if err != nil {
+		return err
+	}
+
+	return stream.Encode(w)
+}
+
+// deserializeUsage reads the TLV encoding of a usage from r into u.
+func deserializeUsage(r io.Reader, u *Usage) error {
+	var (
+		windowStart, windowSpent, totalSpent uint64
+		minuteStart, lastUsed                uint64
+	)
+
+	stream, err := tlv.NewStream(
+		tlv.MakePrimitiveRecord(typeWindowStart, &windowStart),
+		tlv.MakePrimitiveRecord(typeWindowSpent, &windowSpent),
+		tlv.MakePrimitiveRecord(typeTotalSpent, &totalSpent),
+		tlv.MakePrimitiveRecord(typeNumPayments, &u.NumPayments),
+		tlv.MakePrimitiveRecord(typeMinuteStart, &minuteStart),
+		tlv.MakePrimitiveRecord(typeMinuteCalls, &u.MinuteCalls),
+		tlv.MakePrimitiveRecord(typeTotalCalls, &u.TotalCalls),
+		tlv.MakePrimitiveRecord(typeLastUsed, &lastUsed),
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := stream.Decode(r); err != nil {
+		return err
+	}
+
+	u.WindowStart = unixNanoToTime(windowStart)
+	u.WindowSpent = lnwire.MilliSatoshi(windowSpent)
+	u.TotalSpent = lnwire.MilliSatoshi(totalSpent)
+	u.MinuteStart = unixNanoToTime(minuteStart)
+	u.LastUsed = unixNanoToTime(lastUsed)
+
+	return nil
+}
+
+// timeToUnixNano converts the given time to a unix timestamp in nanoseconds,
+// encoding the zero time as zero.
+func timeToUnixNano(t time.Time) uint64 {
+	if t.IsZero() {
+		return 0
+	}
+
+	return uint64(t.UnixNano())
+}
+
+// unixNanoToTime converts a unix timestamp in nanoseconds to a time, decoding
+// zero as the zero time.
+func unixNanoToTime(nanos uint64) time.Time {
+	if nanos == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(0, int64(nanos))
+}
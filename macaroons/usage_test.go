@@ -0,0 +1,96 @@
+package macaroons_test
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/lightningnetwork/lnd/kvdb"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/macaroons"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUsageStore tests that the usage store enforces rate and spending limits
+// and persists the usage across restarts.
+func TestUsageStore(t *testing.T) {
+	t.Parallel()
+
+	db, err := kvdb.Create(
+		kvdb.BoltBackendName, path.Join(t.TempDir(), "usage.db"), true,
+		kvdb.DefaultDBTimeout,
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, db.Close())
+	})
+
+	store, err := macaroons.NewUsageStore(db)
+	require.NoError(t, err)
+
+	var (
+		rootKeyID = []byte("1")
+		now       = time.Unix(1_000_000, 0)
+		limits    = &macaroons.Limits{
+			SpendLimit:  1_000,
+			SpendWindow: time.Hour,
+		}
+	)
+
+	// Only two calls are allowed within the same minute.
+	require.NoError(t, store.RecordCall(rootKeyID, 2, now))
+	require.NoError(t, store.RecordCall(rootKeyID, 2, now))
+	require.ErrorIs(
+		t, store.RecordCall(rootKeyID, 2, now.Add(time.Second)),
+		macaroons.ErrRateLimitExceeded,
+	)
+	require.NoError(t, store.RecordCall(rootKeyID, 2, now.Add(time.Minute)))
+
+	// Spend the full budget of the window, any further spend is rejected.
+	_, err = store.ReserveSpend(rootKeyID, limits, 600, now)
+	require.NoError(t, err)
+	reservation, err := store.ReserveSpend(rootKeyID, limits, 400, now)
+	require.NoError(t, err)
+	_, err = store.ReserveSpend(rootKeyID, limits, 1, now)
+	require.ErrorIs(t, err, macaroons.ErrSpendLimitExceeded)
+
+	// Releasing a spend gives its amount back to the budget.
+	require.NoError(t, store.ReleaseSpend(reservation))
+	_, err = store.ReserveSpend(rootKeyID, limits, 300, now)
+	require.NoError(t, err)
+
+	// The usage survives a restart.
+	store, err = macaroons.NewUsageStore(db)
+	require.NoError(t, err)
+
+	usage, err := store.FetchUsage(rootKeyID)
+	require.NoError(t, err)
+	require.True(t, usage.WindowStart.Equal(now))
+	require.Equal(t, lnwire.MilliSatoshi(900), usage.WindowSpent)
+	require.Equal(t, lnwire.MilliSatoshi(900), usage.TotalSpent)
+	require.Equal(t, uint64(2), usage.NumPayments)
+	require.Equal(t, uint64(3), usage.TotalCalls)
+	require.Equal(t, uint32(1), usage.MinuteCalls)
+
+	// Once the window has passed, the full budget is available again.
+	_, err = store.ReserveSpend(
+		rootKeyID, limits, 1_000, now.Add(time.Hour),
+	)
+	require.NoError(t, err)
+
+	usage, err = store.FetchUsage(rootKeyID)
+	require.NoError(t, err)
+	require.Equal(t, lnwire.MilliSatoshi(1_000), usage.WindowSpent)
+	require.Equal(t, lnwire.MilliSatoshi(1_900), usage.TotalSpent)
+
+	// Other root key IDs have their own, empty usage.
+	usage, err = store.FetchUsage([]byte("2"))
+	require.NoError(t, err)
+	require.Equal(t, &macaroons.Usage{}, usage)
+
+	// Deleting the usage resets it.
+	require.NoError(t, store.DeleteUsage(rootKeyID))
+	usage, err = store.FetchUsage(rootKeyID)
+	require.NoError(t, err)
+	require.Equal(t, &macaroons.Usage{}, usage)
+}
@@ -0,0 +1,69 @@
+package payments
+
+import (
+	"time"
+
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/lntypes"
+)
+
+// DB is the database that persists the payments sent by the node and their
+// HTLC attempts.
+type DB interface {
+	// InitPayment checks or records the given PaymentCreationInfo with
+	// the DB, making sure it does not already exist as an in-flight
+	// payment. When this method returns successfully, the payment is
+	// guaranteed to be in the Initiated state.
+	InitPayment(lntypes.Hash, *channeldb.PaymentCreationInfo) error
+
+	// RegisterAttempt atomically records the provided HTLCAttemptInfo.
+	RegisterAttempt(lntypes.Hash,
+		*channeldb.HTLCAttemptInfo) (*channeldb.MPPayment, error)
+
+	// SettleAttempt marks the given attempt settled with the preimage. If
+	// this is a multi shard payment, this might implicitly mean the
+	// full payment succeeded.
+	SettleAttempt(lntypes.Hash, uint64,
+		*channeldb.HTLCSettleInfo) (*channeldb.MPPayment, error)
+
+	// FailAttempt marks the given payment attempt failed.
+	FailAttempt(lntypes.Hash, uint64,
+		*channeldb.HTLCFailInfo) (*channeldb.MPPayment, error)
+
+	// Fail transitions a payment into the Failed state, and records the
+	// ultimate reason the payment failed.
+	Fail(lntypes.Hash, channeldb.FailureReason) (*channeldb.MPPayment,
+		error)
+
+	// FetchPayment fetches the payment corresponding to the given payment
+	// hash.
+	FetchPayment(lntypes.Hash) (*channeldb.MPPayment, error)
+
+	// FetchInFlightPayments returns all payments that haven't reached a
+	// terminal state yet.
+	FetchInFlightPayments() ([]*channeldb.MPPayment, error)
+
+	// DeleteFailedAttempts removes all failed HTLCs of the payment, unless
+	// failed attempts are configured to be kept.
+	DeleteFailedAttempts(lntypes.Hash) error
+
+	// QueryPayments returns the payments that match the given query.
+	QueryPayments(channeldb.PaymentsQuery) (channeldb.PaymentsResponse,
+		error)
+
+	// PaymentStats returns aggregate statistics of the payments that were
+	// created within the given time window. A zero start or end time
+	// leaves the window open on that side.
+	PaymentStats(start, end time.Time) (*channeldb.PaymentStats, error)
+
+	// DeletePayment deletes the payment with the given hash. If
+	// failedHtlcsOnly is set, only the failed HTLC attempts of the payment
+	// are deleted.
+	DeletePayment(hash lntypes.Hash, failedHtlcsOnly bool) error
+
+	// DeletePayments deletes all completed and failed payments. If
+	// failedOnly is set, only failed payments are deleted. If
+	// failedHtlcsOnly is set, the payments themselves aren't deleted, only
+	// their failed HTLC attempts.
+	DeletePayments(failedOnly, failedHtlcsOnly bool) error
+}
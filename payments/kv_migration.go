@@ -0,0 +1,192 @@
+package payments
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/lntypes"
+	"github.com/lightningnetwork/lnd/sqldb/sqlc"
+)
+
+// kvMigrationName is the name under which the migration of the payments from
+// the key-value database is recorded.
+const kvMigrationName = "payments"
+
+// kvTombstoneReason is the reason stored in the tombstone of the key-value
+// payments after they were migrated.
+const kvTombstoneReason = "payments migrated to native SQL tables"
+
+// MigrateFromKV copies all payments and their HTLC attempts from the
+// key-value channel database to the native SQL tables. The migration is
+// recorded in the SQL database, so it only runs once. The payments are kept in
+// the key-value database, but are marked with a tombstone so that they can't
+// be used by accident once native SQL is disabled again.
+func MigrateFromKV(ctx context.Context, kvDB *channeldb.DB,
+	db BatchedSQLQueries) error {
+
+	readOpts := NewSQLQueriesReadTx()
+
+	var migrated bool
+	err := db.ExecTx(ctx, &readOpts, func(db SQLQueries) error {
+		_, err := db.GetKVMigration(ctx, kvMigrationName)
+		switch {
+		case err == nil:
+			migrated = true
+
+		case errors.Is(err, sql.ErrNoRows):
+			migrated = false
+
+		default:
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// The tombstone is added after the migration was committed, so we
+	// make sure it is present in case we didn't get to add it last time.
+	if migrated {
+		return kvDB.TombstonePayments(kvTombstoneReason)
+	}
+
+	log.Infof("Migrating payments from the key-value database to SQL, " +
+		"this might take a while...")
+
+	resp, err := kvDB.QueryPayments(channeldb.PaymentsQuery{
+		MaxPayments:       math.MaxUint64,
+		IncludeIncomplete: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Legacy duplicate payments share the payment hash of a newer payment.
+	// As payments are returned in ascending order, we only keep the most
+	// recent payment of each hash.
+	var (
+		payments = make([]*channeldb.MPPayment, 0, len(resp.Payments))
+		indices  = make(map[lntypes.Hash]int)
+	)
+	for _, payment := range resp.Payments {
+		hash := payment.Info.PaymentIdentifier
+		if i, ok := indices[hash]; ok {
+			log.Debugf("Skipping duplicate payment %v with "+
+				"sequence number %v", hash,
+				payments[i].SequenceNum)
+
+			payments[i] = payment
+			continue
+		}
+
+		indices[hash] = len(payments)
+		payments = append(payments, payment)
+	}
+
+	var writeTxOpts SQLQueriesTxOptions
+	err = db.ExecTx(ctx, &writeTxOpts, func(db SQLQueries) error {
+		var lastSeq uint64
+		for _, payment := range payments {
+			if err := migratePayment(ctx, db, payment); err != nil {
+				return err
+			}
+
+			if payment.SequenceNum > lastSeq {
+				lastSeq = payment.SequenceNum
+			}
+		}
+
+		// New payments continue with the sequence numbers of the
+		// key-value database, so that pagination offsets stay valid.
+		err := db.SetPaymentSequence(ctx, int64(lastSeq))
+		if err != nil {
+			return err
+		}
+
+		return db.InsertKVMigration(ctx, sqlc.InsertKVMigrationParams{
+			Name:       kvMigrationName,
+			MigratedAt: time.Now().UTC(),
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Migrated %d payments to SQL", len(payments))
+
+	return kvDB.TombstonePayments(kvTombstoneReason)
+}
+
+// migratePayment inserts the given payment and all of its HTLC attempts.
+func migratePayment(ctx context.Context, db SQLQueries,
+	payment *channeldb.MPPayment) error {
+
+	var failureReason sql.NullInt16
+	if payment.FailureReason != nil {
+		failureReason = sql.NullInt16{
+			Int16: int16(*payment.FailureReason),
+			Valid: true,
+		}
+	}
+
+	hash := payment.Info.PaymentIdentifier
+	paymentID, err := db.InsertPayment(ctx, sqlc.InsertPaymentParams{
+		SequenceNum:    int64(payment.SequenceNum),
+		Hash:           hash[:],
+		AmountMsat:     int64(payment.Info.Value),
+		PaymentRequest: payment.Info.PaymentRequest,
+		Rebalance:      payment.Info.Rebalance,
+		Status:         int16(payment.Status),
+		FailureReason:  failureReason,
+		CreatedAt:      payment.Info.CreationTime.UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, htlc := range payment.HTLCs {
+		htlc := htlc
+
+		params, err := attemptParams(&htlc.HTLCAttemptInfo)
+		if err != nil {
+			return err
+		}
+		params.PaymentID = paymentID
+
+		if htlc.Settle != nil {
+			params.SettlePreimage = htlc.Settle.Preimage[:]
+			params.SettleTime = sqlTime(htlc.Settle.SettleTime)
+		}
+
+		if htlc.Failure != nil {
+			params.FailMessage, err = encodeFailureMessage(
+				htlc.Failure.Message,
+			)
+			if err != nil {
+				return err
+			}
+
+			params.FailReason = sql.NullInt16{
+				Int16: int16(htlc.Failure.Reason),
+				Valid: true,
+			}
+			params.FailSourceIndex = sql.NullInt64{
+				Int64: int64(htlc.Failure.FailureSourceIndex),
+				Valid: true,
+			}
+			params.FailTime = sqlTime(htlc.Failure.FailTime)
+		}
+
+		if err := db.InsertPaymentHTLCAttempt(ctx, params); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package payments
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMigrateFromKV tests that the payments of the key-value database are
+// copied to the SQL tables exactly once, keeping their sequence numbers.
+func TestMigrateFromKV(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	kvDB, err := channeldb.MakeTestDB(t)
+	require.NoError(t, err)
+	kvStore := NewKVStore(kvDB)
+
+	// We create a succeeded payment with a failed attempt and a failed
+	// payment in the key-value database.
+	succeeded, preimage := genPayment(t, testTime)
+	hash := succeeded.PaymentIdentifier
+	require.NoError(t, kvStore.InitPayment(hash, succeeded))
+
+	_, err = kvStore.RegisterAttempt(hash, genAttempt(t, 1))
+	require.NoError(t, err)
+	_, err = kvStore.FailAttempt(hash, 1, &channeldb.HTLCFailInfo{
+		FailTime:           testTime,
+		Message:            &lnwire.FailTemporaryChannelFailure{},
+		Reason:             channeldb.HTLCFailMessage,
+		FailureSourceIndex: 1,
+	})
+	require.NoError(t, err)
+
+	_, err = kvStore.RegisterAttempt(hash, genAttempt(t, 2))
+	require.NoError(t, err)
+	_, err = kvStore.SettleAttempt(hash, 2, &channeldb.HTLCSettleInfo{
+		Preimage:   preimage,
+		SettleTime: testTime,
+	})
+	require.NoError(t, err)
+
+	failed, _ := genPayment(t, testTime)
+	require.NoError(t, kvStore.InitPayment(failed.PaymentIdentifier, failed))
+	_, err = kvStore.Fail(
+		failed.PaymentIdentifier, channeldb.FailureReasonTimeout,
+	)
+	require.NoError(t, err)
+
+	store, db := newTestStore(t)
+	require.NoError(t, kvDB.EnsureNoPaymentsTombstone())
+	require.NoError(t, MigrateFromKV(ctx, kvDB, db))
+
+	// The key-value payments are marked with a tombstone after the
+	// migration.
+	require.Error(t, kvDB.EnsureNoPaymentsTombstone())
+
+	query := channeldb.PaymentsQuery{
+		MaxPayments:       math.MaxUint64,
+		IncludeIncomplete: true,
+	}
+	kvResp, err := kvStore.QueryPayments(query)
+	require.NoError(t, err)
+	sqlResp, err := store.QueryPayments(query)
+	require.NoError(t, err)
+
+	require.Len(t, sqlResp.Payments, len(kvResp.Payments))
+	for i, kvPayment := range kvResp.Payments {
+		sqlPayment := sqlResp.Payments[i]
+
+		require.Equal(t, kvPayment.SequenceNum, sqlPayment.SequenceNum)
+		require.Equal(t, kvPayment.Info, sqlPayment.Info)
+		require.Equal(t, kvPayment.Status, sqlPayment.Status)
+		require.Equal(t, kvPayment.FailureReason,
+			sqlPayment.FailureReason)
+		require.Equal(t, kvPayment.State, sqlPayment.State)
+
+		require.Len(t, sqlPayment.HTLCs, len(kvPayment.HTLCs))
+		for j, kvHTLC := range kvPayment.HTLCs {
+			sqlHTLC := sqlPayment.HTLCs[j]
+
+			require.Equal(t, kvHTLC.AttemptID, sqlHTLC.AttemptID)
+			require.Equal(t, kvHTLC.Route, sqlHTLC.Route)
+			require.Equal(t, kvHTLC.Settle, sqlHTLC.Settle)
+			require.Equal(t, kvHTLC.Failure, sqlHTLC.Failure)
+			require.Equal(
+				t, kvHTLC.SessionKey().Serialize(),
+				sqlHTLC.SessionKey().Serialize(),
+			)
+		}
+	}
+
+	// Running the migration again doesn't copy the payments twice.
+	require.NoError(t, MigrateFromKV(ctx, kvDB, db))
+	require.Error(t, kvDB.EnsureNoPaymentsTombstone())
+
+	sqlResp, err = store.QueryPayments(query)
+	require.NoError(t, err)
+	require.Len(t, sqlResp.Payments, 2)
+
+	// New payments continue with the sequence numbers of the key-value
+	// database.
+	info, _ := genPayment(t, testTime)
+	require.NoError(t, store.InitPayment(info.PaymentIdentifier, info))
+
+	payment, err := store.FetchPayment(info.PaymentIdentifier)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, payment.SequenceNum)
+}
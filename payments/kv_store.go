@@ -0,0 +1,66 @@
+package payments
+
+import (
+	"time"
+
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/lntypes"
+)
+
+// KVStore is a payments DB that is backed by the key-value buckets of the
+// channel database.
+type KVStore struct {
+	*channeldb.PaymentControl
+
+	db *channeldb.DB
+}
+
+// A compile time check to ensure that KVStore fully implements the DB
+// interface.
+var _ DB = (*KVStore)(nil)
+
+// NewKVStore creates a new payments DB that is backed by the given channel
+// database.
+func NewKVStore(db *channeldb.DB) *KVStore {
+	return &KVStore{
+		PaymentControl: channeldb.NewPaymentControl(db),
+		db:             db,
+	}
+}
+
+// QueryPayments returns the payments that match the given query.
+//
+// NOTE: This is part of the DB interface.
+func (s *KVStore) QueryPayments(
+	query channeldb.PaymentsQuery) (channeldb.PaymentsResponse, error) {
+
+	return s.db.QueryPayments(query)
+}
+
+// PaymentStats returns aggregate statistics of the payments that were created
+// within the given time window.
+//
+// NOTE: This is part of the DB interface.
+func (s *KVStore) PaymentStats(start,
+	end time.Time) (*channeldb.PaymentStats, error) {
+
+	return s.db.PaymentStats(start, end)
+}
+
+// DeletePayment deletes the payment with the given hash, or only its failed
+// HTLC attempts.
+//
+// NOTE: This is part of the DB interface.
+func (s *KVStore) DeletePayment(hash lntypes.Hash,
+	failedHtlcsOnly bool) error {
+
+	return s.db.DeletePayment(hash, failedHtlcsOnly)
+}
+
+// DeletePayments deletes all completed and failed payments, or only their
+// failed HTLC attempts.
+//
+// NOTE: This is part of the DB interface.
+func (s *KVStore) DeletePayments(failedOnly, failedHtlcsOnly bool) error {
+	return s.db.DeletePayments(failedOnly, failedHtlcsOnly)
+}
@@ -0,0 +1,979 @@
+package payments
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/lntypes"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/sqldb"
+	"github.com/lightningnetwork/lnd/sqldb/sqlc"
+)
+
+// SQLQueries is the set of queries the SQL payments store needs.
+type SQLQueries interface {
+	NextPaymentSequence(ctx context.Context) (int64, error)
+
+	SetPaymentSequence(ctx context.Context, lastSeq int64) error
+
+	InsertPayment(ctx context.Context,
+		arg sqlc.InsertPaymentParams) (int32, error)
+
+	GetPaymentByHash(ctx context.Context, hash []byte) (sqlc.Payment,
+		error)
+
+	UpdatePaymentStatus(ctx context.Context,
+		arg sqlc.UpdatePaymentStatusParams) error
+
+	DeletePayment(ctx context.Context, id int32) error
+
+	CountPayments(ctx context.Context) (int64, error)
+
+	FilterPayments(ctx context.Context,
+		arg sqlc.FilterPaymentsParams) ([]sqlc.Payment, error)
+
+	CountPaymentsByStatus(ctx context.Context,
+		arg sqlc.CountPaymentsByStatusParams) (
+		[]sqlc.CountPaymentsByStatusRow, error)
+
+	SumSettledPaymentHTLCAttempts(ctx context.Context,
+		arg sqlc.SumSettledPaymentHTLCAttemptsParams) (
+		sqlc.SumSettledPaymentHTLCAttemptsRow, error)
+
+	InsertPaymentHTLCAttempt(ctx context.Context,
+		arg sqlc.InsertPaymentHTLCAttemptParams) error
+
+	ListPaymentHTLCAttempts(ctx context.Context,
+		paymentID int32) ([]sqlc.PaymentHtlcAttempt, error)
+
+	SettlePaymentHTLCAttempt(ctx context.Context,
+		arg sqlc.SettlePaymentHTLCAttemptParams) error
+
+	FailPaymentHTLCAttempt(ctx context.Context,
+		arg sqlc.FailPaymentHTLCAttemptParams) error
+
+	DeleteFailedPaymentHTLCAttempts(ctx context.Context,
+		paymentID int32) error
+
+	InsertKVMigration(ctx context.Context,
+		arg sqlc.InsertKVMigrationParams) error
+
+	GetKVMigration(ctx context.Context, name string) (sqlc.KvMigration,
+		error)
+}
+
+// SQLQueriesTxOptions defines the set of db txn options the SQLQueries
+// understands.
+type SQLQueriesTxOptions struct {
+	// readOnly governs if a read only transaction is needed or not.
+	readOnly bool
+}
+
+// ReadOnly returns true if the transaction should be read only.
+//
+// NOTE: This implements the TxOptions.
+func (a *SQLQueriesTxOptions) ReadOnly() bool {
+	return a.readOnly
+}
+
+// NewSQLQueriesReadTx creates a new read transaction option set.
+func NewSQLQueriesReadTx() SQLQueriesTxOptions {
+	return SQLQueriesTxOptions{
+		readOnly: true,
+	}
+}
+
+// BatchedSQLQueries is a version of the SQLQueries that's capable of batched
+// database operations.
+type BatchedSQLQueries interface {
+	SQLQueries
+
+	sqldb.BatchedTx[SQLQueries]
+}
+
+// SQLStore is a payments DB that is backed by the native SQL tables of lnd.
+type SQLStore struct {
+	db BatchedSQLQueries
+
+	// keepFailedPaymentAttempts is set if failed HTLC attempts should be
+	// kept when DeleteFailedAttempts is called.
+	keepFailedPaymentAttempts bool
+}
+
+// A compile time check to ensure that SQLStore fully implements the DB
+// interface.
+var _ DB = (*SQLStore)(nil)
+
+// NewSQLStore creates a new SQLStore instance given an open BatchedSQLQueries
+// storage backend.
+func NewSQLStore(db BatchedSQLQueries,
+	keepFailedPaymentAttempts bool) *SQLStore {
+
+	return &SQLStore{
+		db:                        db,
+		keepFailedPaymentAttempts: keepFailedPaymentAttempts,
+	}
+}
+
+// InitPayment checks or records the given PaymentCreationInfo with the DB,
+// making sure it does not already exist as an in-flight payment. A failed
+// payment is replaced, which gives it a new sequence number.
+//
+// NOTE: This is part of the DB interface.
+func (s *SQLStore) InitPayment(hash lntypes.Hash,
+	info *channeldb.PaymentCreationInfo) error {
+
+	var (
+		ctx         = context.TODO()
+		writeTxOpts SQLQueriesTxOptions
+	)
+
+	return s.db.ExecTx(ctx, &writeTxOpts, func(db SQLQueries) error {
+		row, err := db.GetPaymentByHash(ctx, hash[:])
+		switch {
+		// If we already have this payment, its status decides whether
+		// we allow retrying it.
+		case err == nil:
+			status := channeldb.PaymentStatus(row.Status)
+			if err := status.Initializable(); err != nil {
+				return err
+			}
+
+			err = db.DeletePayment(ctx, row.ID)
+			if err != nil {
+				return err
+			}
+
+		case !errors.Is(err, sql.ErrNoRows):
+			return err
+		}
+
+		seqNum, err := db.NextPaymentSequence(ctx)
+		if err != nil {
+			return err
+		}
+
+		_, err = db.InsertPayment(ctx, sqlc.InsertPaymentParams{
+			SequenceNum:    seqNum,
+			Hash:           hash[:],
+			AmountMsat:     int64(info.Value),
+			PaymentRequest: info.PaymentRequest,
+			Rebalance:      info.Rebalance,
+			Status:         int16(channeldb.StatusInitiated),
+			CreatedAt:      info.CreationTime.UTC(),
+		})
+
+		return err
+	})
+}
+
+// RegisterAttempt atomically records the provided HTLCAttemptInfo.
+//
+// NOTE: This is part of the DB interface.
+func (s *SQLStore) RegisterAttempt(hash lntypes.Hash,
+	attempt *channeldb.HTLCAttemptInfo) (*channeldb.MPPayment, error) {
+
+	params, err := attemptParams(attempt)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		ctx         = context.TODO()
+		writeTxOpts SQLQueriesTxOptions
+		payment     *channeldb.MPPayment
+	)
+	err = s.db.ExecTx(ctx, &writeTxOpts, func(db SQLQueries) error {
+		row, err := fetchPaymentRow(ctx, db, hash)
+		if err != nil {
+			return err
+		}
+
+		payment, err = buildPayment(ctx, db, row)
+		if err != nil {
+			return err
+		}
+
+		// Check that the attempt can be added to the payment.
+		if err := payment.ValidateAttempt(attempt); err != nil {
+			return err
+		}
+
+		params.PaymentID = row.ID
+		err = db.InsertPaymentHTLCAttempt(ctx, params)
+		if err != nil {
+			return err
+		}
+
+		payment, err = refreshPayment(ctx, db, row)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+// SettleAttempt marks the given attempt settled with the preimage.
+//
+// NOTE: This is part of the DB interface.
+func (s *SQLStore) SettleAttempt(hash lntypes.Hash, attemptID uint64,
+	settleInfo *channeldb.HTLCSettleInfo) (*channeldb.MPPayment, error) {
+
+	return s.updateAttempt(hash, attemptID, func(db SQLQueries,
+		paymentID int32) error {
+
+		return db.SettlePaymentHTLCAttempt(
+			context.TODO(), sqlc.SettlePaymentHTLCAttemptParams{
+				SettlePreimage: settleInfo.Preimage[:],
+				SettleTime:     sqlTime(settleInfo.SettleTime),
+				PaymentID:      paymentID,
+				AttemptID:      int64(attemptID),
+			},
+		)
+	})
+}
+
+// FailAttempt marks the given payment attempt failed.
+//
+// NOTE: This is part of the DB interface.
+func (s *SQLStore) FailAttempt(hash lntypes.Hash, attemptID uint64,
+	failInfo *channeldb.HTLCFailInfo) (*channeldb.MPPayment, error) {
+
+	message, err := encodeFailureMessage(failInfo.Message)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.updateAttempt(hash, attemptID, func(db SQLQueries,
+		paymentID int32) error {
+
+		return db.FailPaymentHTLCAttempt(
+			context.TODO(), sqlc.FailPaymentHTLCAttemptParams{
+				FailReason: sql.NullInt16{
+					Int16: int16(failInfo.Reason),
+					Valid: true,
+				},
+				FailMessage: message,
+				FailSourceIndex: sql.NullInt64{
+					Int64: int64(
+						failInfo.FailureSourceIndex,
+					),
+					Valid: true,
+				},
+				FailTime:  sqlTime(failInfo.FailTime),
+				PaymentID: paymentID,
+				AttemptID: int64(attemptID),
+			},
+		)
+	})
+}
+
+// updateAttempt resolves the given in-flight HTLC attempt of a payment with
+// the given update function.
+func (s *SQLStore) updateAttempt(hash lntypes.Hash, attemptID uint64,
+	update func(db SQLQueries, paymentID int32) error) (
+	*channeldb.MPPayment, error) {
+
+	var (
+		ctx         = context.TODO()
+		writeTxOpts SQLQueriesTxOptions
+		payment     *channeldb.MPPayment
+	)
+	err := s.db.ExecTx(ctx, &writeTxOpts, func(db SQLQueries) error {
+		row, err := fetchPaymentRow(ctx, db, hash)
+		if err != nil {
+			return err
+		}
+
+		payment, err = buildPayment(ctx, db, row)
+		if err != nil {
+			return err
+		}
+
+		// We can only update attempts of in-flight payments. We allow
+		// updating attempts even if the payment has reached a terminal
+		// condition, since the HTLC outcomes must still be updated.
+		if err := payment.Status.Updatable(); err != nil {
+			return err
+		}
+
+		htlc, err := payment.GetAttempt(attemptID)
+		if err != nil {
+			return fmt.Errorf("HTLC with ID %v not registered",
+				attemptID)
+		}
+
+		// Make sure the shard is not already failed or settled.
+		switch {
+		case htlc.Failure != nil:
+			return channeldb.ErrAttemptAlreadyFailed
+
+		case htlc.Settle != nil:
+			return channeldb.ErrAttemptAlreadySettled
+		}
+
+		if err := update(db, row.ID); err != nil {
+			return err
+		}
+
+		payment, err = refreshPayment(ctx, db, row)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+// Fail transitions a payment into the Failed state, and records the reason the
+// payment failed.
+//
+// NOTE: This is part of the DB interface.
+func (s *SQLStore) Fail(hash lntypes.Hash,
+	reason channeldb.FailureReason) (*channeldb.MPPayment, error) {
+
+	var (
+		ctx         = context.TODO()
+		writeTxOpts SQLQueriesTxOptions
+		payment     *channeldb.MPPayment
+	)
+	err := s.db.ExecTx(ctx, &writeTxOpts, func(db SQLQueries) error {
+		row, err := fetchPaymentRow(ctx, db, hash)
+		if err != nil {
+			return err
+		}
+
+		// We mark the payment as failed as long as it is known. This
+		// lets the last attempt to fail with a terminal write its
+		// failure without synchronizing with other attempts. A payment
+		// canceled by the user keeps its reason, even if the payment
+		// lifecycle fails it for another reason afterwards.
+		canceled := row.FailureReason.Valid &&
+			channeldb.FailureReason(row.FailureReason.Int16) ==
+				channeldb.FailureReasonCanceled
+		if !canceled {
+			row.FailureReason = sql.NullInt16{
+				Int16: int16(reason),
+				Valid: true,
+			}
+		}
+		payment, err = refreshPayment(ctx, db, row)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+// FetchPayment fetches the payment corresponding to the given payment hash.
+//
+// NOTE: This is part of the DB interface.
+func (s *SQLStore) FetchPayment(hash lntypes.Hash) (*channeldb.MPPayment,
+	error) {
+
+	var (
+		ctx      = context.TODO()
+		readOpts = NewSQLQueriesReadTx()
+		payment  *channeldb.MPPayment
+	)
+	err := s.db.ExecTx(ctx, &readOpts, func(db SQLQueries) error {
+		row, err := fetchPaymentRow(ctx, db, hash)
+		if err != nil {
+			return err
+		}
+
+		payment, err = buildPayment(ctx, db, row)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+// FetchInFlightPayments returns all payments that haven't reached a terminal
+// state yet.
+//
+// NOTE: This is part of the DB interface.
+func (s *SQLStore) FetchInFlightPayments() ([]*channeldb.MPPayment, error) {
+	var (
+		ctx      = context.TODO()
+		readOpts = NewSQLQueriesReadTx()
+		payments []*channeldb.MPPayment
+	)
+	err := s.db.ExecTx(ctx, &readOpts, func(db SQLQueries) error {
+		payments = nil
+
+		rows, err := db.FilterPayments(ctx, sqlc.FilterPaymentsParams{
+			StatusMask: statusMask(
+				channeldb.StatusInitiated,
+				channeldb.StatusInFlight,
+			),
+			Reverse:  false,
+			NumLimit: math.MaxInt32,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			payment, err := buildPayment(ctx, db, row)
+			if err != nil {
+				return err
+			}
+
+			payments = append(payments, payment)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return payments, nil
+}
+
+// DeleteFailedAttempts removes all failed HTLCs of the payment, unless failed
+// attempts are configured to be kept.
+//
+// NOTE: This is part of the DB interface.
+func (s *SQLStore) DeleteFailedAttempts(hash lntypes.Hash) error {
+	if s.keepFailedPaymentAttempts {
+		return nil
+	}
+
+	const failedHtlcsOnly = true
+
+	return s.DeletePayment(hash, failedHtlcsOnly)
+}
+
+// QueryPayments returns the payments that match the given query.
+//
+// NOTE: This is part of the DB interface.
+func (s *SQLStore) QueryPayments(
+	query channeldb.PaymentsQuery) (channeldb.PaymentsResponse, error) {
+
+	params := sqlc.FilterPaymentsParams{
+		CreatedAfter:  sqlTime(query.CreationDateStart),
+		CreatedBefore: sqlTime(query.CreationDateEnd),
+		MinAmountMsat: sqlAmount(query.MinAmount),
+		MaxAmountMsat: sqlAmount(query.MaxAmount),
+		Reverse:       query.Reversed,
+		NumLimit:      math.MaxInt32,
+	}
+
+	if query.MaxPayments < math.MaxInt32 {
+		params.NumLimit = int32(query.MaxPayments)
+	}
+
+	// The index offset is exclusive and a zero offset means that the
+	// query starts with the oldest or ends with the most recent payment.
+	if query.IndexOffset != 0 {
+		offset := sql.NullInt64{
+			Int64: int64(query.IndexOffset),
+			Valid: true,
+		}
+		if query.Reversed {
+			params.SeqNumBefore = offset
+		} else {
+			params.SeqNumAfter = offset
+		}
+	}
+
+	// To keep compatibility with the old API, we only return
+	// non-succeeded payments if requested, unless explicit statuses are
+	// requested.
+	switch {
+	case len(query.Statuses) > 0:
+		params.StatusMask = statusMask(query.Statuses...)
+
+	case !query.IncludeIncomplete:
+		params.StatusMask = statusMask(channeldb.StatusSucceeded)
+	}
+
+	if len(query.PaymentHashPrefix) > 0 {
+		params.HashPrefix = query.PaymentHashPrefix
+		params.HashPrefixLen = int32(len(query.PaymentHashPrefix))
+	}
+
+	if query.Destination != nil {
+		params.Destination = query.Destination[:]
+	}
+
+	var (
+		ctx      = context.TODO()
+		readOpts = NewSQLQueriesReadTx()
+		resp     channeldb.PaymentsResponse
+	)
+	err := s.db.ExecTx(ctx, &readOpts, func(db SQLQueries) error {
+		resp = channeldb.PaymentsResponse{}
+
+		rows, err := db.FilterPayments(ctx, params)
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			payment, err := buildPayment(ctx, db, row)
+			if err != nil {
+				return err
+			}
+
+			resp.Payments = append(resp.Payments, payment)
+		}
+
+		if query.CountTotal {
+			total, err := db.CountPayments(ctx)
+			if err != nil {
+				return err
+			}
+
+			resp.TotalCount = uint64(total)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return resp, err
+	}
+
+	// Payments are always returned in ascending order, even if the query
+	// searched backwards.
+	if query.Reversed {
+		for l, r := 0, len(resp.Payments)-1; l < r; l, r = l+1, r-1 {
+			resp.Payments[l], resp.Payments[r] =
+				resp.Payments[r], resp.Payments[l]
+		}
+	}
+
+	// Set the first and last index of the returned payments so that the
+	// caller can resume from this point later on.
+	if len(resp.Payments) > 0 {
+		resp.FirstIndexOffset = resp.Payments[0].SequenceNum
+		resp.LastIndexOffset =
+			resp.Payments[len(resp.Payments)-1].SequenceNum
+	}
+
+	return resp, nil
+}
+
+// PaymentStats returns aggregate statistics of the payments that were created
+// within the given time window.
+//
+// NOTE: This is part of the DB interface.
+func (s *SQLStore) PaymentStats(start,
+	end time.Time) (*channeldb.PaymentStats, error) {
+
+	var (
+		ctx      = context.TODO()
+		readOpts = NewSQLQueriesReadTx()
+		stats    *channeldb.PaymentStats
+	)
+	err := s.db.ExecTx(ctx, &readOpts, func(db SQLQueries) error {
+		stats = &channeldb.PaymentStats{}
+
+		counts, err := db.CountPaymentsByStatus(
+			ctx, sqlc.CountPaymentsByStatusParams{
+				CreatedAfter:  sqlTime(start),
+				CreatedBefore: sqlTime(end),
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		for _, count := range counts {
+			num := uint64(count.NumPayments)
+			stats.NumPayments += num
+
+			switch channeldb.PaymentStatus(count.Status) {
+			case channeldb.StatusSucceeded:
+				stats.NumSucceeded += num
+
+			case channeldb.StatusFailed:
+				stats.NumFailed += num
+
+			default:
+				stats.NumInFlight += num
+			}
+		}
+
+		sums, err := db.SumSettledPaymentHTLCAttempts(
+			ctx, sqlc.SumSettledPaymentHTLCAttemptsParams{
+				Status:        int16(channeldb.StatusSucceeded),
+				CreatedAfter:  sqlTime(start),
+				CreatedBefore: sqlTime(end),
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		stats.TotalSent = lnwire.MilliSatoshi(sums.AmountMsat)
+		stats.TotalFees = lnwire.MilliSatoshi(sums.FeeMsat)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// DeletePayment deletes the payment with the given hash. If failedHtlcsOnly
+// is set, only the failed HTLC attempts of the payment are deleted.
+//
+// NOTE: This is part of the DB interface.
+func (s *SQLStore) DeletePayment(hash lntypes.Hash,
+	failedHtlcsOnly bool) error {
+
+	var (
+		ctx         = context.TODO()
+		writeTxOpts SQLQueriesTxOptions
+	)
+
+	return s.db.ExecTx(ctx, &writeTxOpts, func(db SQLQueries) error {
+		row, err := fetchPaymentRow(ctx, db, hash)
+		if err != nil {
+			return err
+		}
+
+		// If the payment has inflight HTLCs, we cannot safely delete
+		// the payment information, so we return an error.
+		status := channeldb.PaymentStatus(row.Status)
+		if err := status.Removable(); err != nil {
+			return fmt.Errorf("payment '%v' has inflight HTLCs "+
+				"and therefore cannot be deleted: %w", hash,
+				err)
+		}
+
+		return deletePayment(ctx, db, row, failedHtlcsOnly)
+	})
+}
+
+// DeletePayments deletes all completed and failed payments. If failedOnly is
+// set, only failed payments are deleted. If failedHtlcsOnly is set, the
+// payments themselves aren't deleted, only their failed HTLC attempts.
+//
+// NOTE: This is part of the DB interface.
+func (s *SQLStore) DeletePayments(failedOnly, failedHtlcsOnly bool) error {
+	// Payments with inflight HTLCs cannot be deleted safely, so they are
+	// skipped.
+	mask := statusMask(
+		channeldb.StatusInitiated, channeldb.StatusSucceeded,
+		channeldb.StatusFailed,
+	)
+	if failedOnly {
+		mask = statusMask(channeldb.StatusFailed)
+	}
+
+	var (
+		ctx         = context.TODO()
+		writeTxOpts SQLQueriesTxOptions
+	)
+
+	return s.db.ExecTx(ctx, &writeTxOpts, func(db SQLQueries) error {
+		rows, err := db.FilterPayments(ctx, sqlc.FilterPaymentsParams{
+			StatusMask: mask,
+			Reverse:    false,
+			NumLimit:   math.MaxInt32,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			err := deletePayment(ctx, db, row, failedHtlcsOnly)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// deletePayment deletes the given payment, or only its failed HTLC attempts
+// if failedHtlcsOnly is set.
+func deletePayment(ctx context.Context, db SQLQueries, row sqlc.Payment,
+	failedHtlcsOnly bool) error {
+
+	if !failedHtlcsOnly {
+		return db.DeletePayment(ctx, row.ID)
+	}
+
+	if err := db.DeleteFailedPaymentHTLCAttempts(ctx, row.ID); err != nil {
+		return err
+	}
+
+	// The status of the payment is derived from its attempts, so it needs
+	// to be updated after removing some of them.
+	_, err := refreshPayment(ctx, db, row)
+
+	return err
+}
+
+// fetchPaymentRow returns the database row of the payment with the given
+// hash. ErrPaymentNotInitiated is returned if there is no such payment.
+func fetchPaymentRow(ctx context.Context, db SQLQueries,
+	hash lntypes.Hash) (sqlc.Payment, error) {
+
+	row, err := db.GetPaymentByHash(ctx, hash[:])
+	if errors.Is(err, sql.ErrNoRows) {
+		return row, channeldb.ErrPaymentNotInitiated
+	}
+
+	return row, err
+}
+
+// refreshPayment builds the payment of the given row and stores its failure
+// reason and the status derived from its current HTLC attempts.
+func refreshPayment(ctx context.Context, db SQLQueries,
+	row sqlc.Payment) (*channeldb.MPPayment, error) {
+
+	payment, err := buildPayment(ctx, db, row)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.UpdatePaymentStatus(ctx, sqlc.UpdatePaymentStatusParams{
+		Status:        int16(payment.Status),
+		FailureReason: row.FailureReason,
+		ID:            row.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+// buildPayment assembles the payment of the given row and its HTLC attempts,
+// and derives its state and status.
+func buildPayment(ctx context.Context, db SQLQueries,
+	row sqlc.Payment) (*channeldb.MPPayment, error) {
+
+	hash, err := lntypes.MakeHash(row.Hash)
+	if err != nil {
+		return nil, err
+	}
+
+	payment := &channeldb.MPPayment{
+		SequenceNum: uint64(row.SequenceNum),
+		Info: &channeldb.PaymentCreationInfo{
+			PaymentIdentifier: hash,
+			Value:             lnwire.MilliSatoshi(row.AmountMsat),
+			CreationTime:      fromSQLTime(row.CreatedAt),
+			PaymentRequest:    row.PaymentRequest,
+			Rebalance:         row.Rebalance,
+		},
+	}
+
+	if row.FailureReason.Valid {
+		reason := channeldb.FailureReason(row.FailureReason.Int16)
+		payment.FailureReason = &reason
+	}
+
+	attempts, err := db.ListPaymentHTLCAttempts(ctx, row.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, attempt := range attempts {
+		htlc, err := buildHTLCAttempt(attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		payment.HTLCs = append(payment.HTLCs, *htlc)
+	}
+
+	if err := payment.SetState(); err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+// attemptParams returns the parameters to insert the given HTLC attempt. The
+// ID of the payment the attempt belongs to is left for the caller to set.
+func attemptParams(attempt *channeldb.HTLCAttemptInfo) (
+	sqlc.InsertPaymentHTLCAttemptParams, error) {
+
+	var b bytes.Buffer
+	if err := channeldb.SerializeRoute(&b, attempt.Route); err != nil {
+		return sqlc.InsertPaymentHTLCAttemptParams{}, err
+	}
+
+	finalHop := attempt.Route.FinalHop()
+	if finalHop == nil {
+		return sqlc.InsertPaymentHTLCAttemptParams{}, fmt.Errorf(
+			"route of attempt %v has no hops", attempt.AttemptID,
+		)
+	}
+
+	var attemptHash []byte
+	if attempt.Hash != nil {
+		attemptHash = attempt.Hash[:]
+	}
+
+	return sqlc.InsertPaymentHTLCAttemptParams{
+		AttemptID:   int64(attempt.AttemptID),
+		SessionKey:  attempt.SessionKey().Serialize(),
+		Route:       b.Bytes(),
+		Destination: finalHop.PubKeyBytes[:],
+		AmountMsat:  int64(attempt.Route.ReceiverAmt()),
+		FeeMsat:     int64(attempt.Route.TotalFees()),
+		Hash:        attemptHash,
+		AttemptTime: attempt.AttemptTime.UTC(),
+	}, nil
+}
+
+// encodeFailureMessage encodes the given wire failure message of a failed
+// attempt. A nil message is encoded as nil.
+func encodeFailureMessage(msg lnwire.FailureMessage) ([]byte, error) {
+	if msg == nil {
+		return nil, nil
+	}
+
+	var b bytes.Buffer
+	if err := lnwire.EncodeFailureMessage(&b, msg, 0); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// buildHTLCAttempt assembles the HTLC attempt of the given row.
+func buildHTLCAttempt(row sqlc.PaymentHtlcAttempt) (*channeldb.HTLCAttempt,
+	error) {
+
+	sessionKey, _ := btcec.PrivKeyFromBytes(row.SessionKey)
+
+	route, err := channeldb.DeserializeRoute(bytes.NewReader(row.Route))
+	if err != nil {
+		return nil, err
+	}
+
+	var hash *lntypes.Hash
+	if row.Hash != nil {
+		h, err := lntypes.MakeHash(row.Hash)
+		if err != nil {
+			return nil, err
+		}
+		hash = &h
+	}
+
+	htlc := channeldb.NewHtlcAttempt(
+		uint64(row.AttemptID), sessionKey, route,
+		fromSQLTime(row.AttemptTime), hash,
+	)
+
+	if row.SettlePreimage != nil {
+		preimage, err := lntypes.MakePreimage(row.SettlePreimage)
+		if err != nil {
+			return nil, err
+		}
+
+		htlc.Settle = &channeldb.HTLCSettleInfo{
+			Preimage:   preimage,
+			SettleTime: fromSQLNullTime(row.SettleTime),
+		}
+	}
+
+	if row.FailReason.Valid {
+		htlc.Failure = &channeldb.HTLCFailInfo{
+			FailTime: fromSQLNullTime(row.FailTime),
+			Reason: channeldb.HTLCFailReason(
+				row.FailReason.Int16,
+			),
+			FailureSourceIndex: uint32(row.FailSourceIndex.Int64),
+		}
+
+		if len(row.FailMessage) > 0 {
+			htlc.Failure.Message, err = lnwire.DecodeFailureMessage(
+				bytes.NewReader(row.FailMessage), 0,
+			)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return htlc, nil
+}
+
+// statusMask returns the bit field of the given statuses that is used to
+// filter payments by status.
+func statusMask(statuses ...channeldb.PaymentStatus) sql.NullInt32 {
+	var mask int32
+	for _, status := range statuses {
+		mask |= 1 << status
+	}
+
+	return sql.NullInt32{
+		Int32: mask,
+		Valid: true,
+	}
+}
+
+// sqlAmount returns the given amount as nullable SQL integer, where zero is
+// NULL.
+func sqlAmount(amt lnwire.MilliSatoshi) sql.NullInt64 {
+	return sql.NullInt64{
+		Int64: int64(amt),
+		Valid: amt != 0,
+	}
+}
+
+// sqlTime returns the given time as nullable SQL time, where the zero time is
+// NULL.
+func sqlTime(t time.Time) sql.NullTime {
+	if t.IsZero() {
+		return sql.NullTime{}
+	}
+
+	return sql.NullTime{
+		Time:  t.UTC(),
+		Valid: true,
+	}
+}
+
+// fromSQLTime converts a time read from the database to the local time zone,
+// the way the key-value store returns times. The zero time is left as is.
+func fromSQLTime(t time.Time) time.Time {
+	if t.IsZero() {
+		return time.Time{}
+	}
+
+	return time.Unix(0, t.UnixNano())
+}
+
+// fromSQLNullTime converts a nullable time read from the database to the
+// local time zone, where NULL is the zero time.
+func fromSQLNullTime(t sql.NullTime) time.Time {
+	if !t.Valid {
+		return time.Time{}
+	}
+
+	return fromSQLTime(t.Time)
+}
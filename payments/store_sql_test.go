@@ -0,0 +1,383 @@
+package payments
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/lntypes"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/routing/route"
+	"github.com/lightningnetwork/lnd/sqldb"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testTime = time.Unix(1_700_000_000, 0)
+
+	testDest = route.Vertex{2}
+
+	testRoute = route.Route{
+		TotalTimeLock: 123,
+		TotalAmount:   1_010,
+		SourcePubKey:  route.Vertex{1},
+		Hops: []*route.Hop{
+			{
+				PubKeyBytes:      route.Vertex{3},
+				ChannelID:        12345,
+				OutgoingTimeLock: 111,
+				AmtToForward:     1_000,
+			},
+			{
+				PubKeyBytes:      testDest,
+				ChannelID:        54321,
+				OutgoingTimeLock: 111,
+				AmtToForward:     1_000,
+			},
+		},
+	}
+)
+
+// newTestStore creates a new SQL payments store backed by a fresh SQLite
+// database.
+func newTestStore(t *testing.T) (*SQLStore, BatchedSQLQueries) {
+	db := sqldb.NewTestSqliteDB(t).BaseDB
+
+	executor := sqldb.NewTransactionExecutor(
+		db, func(tx *sql.Tx) SQLQueries {
+			return db.WithTx(tx)
+		},
+	)
+
+	return NewSQLStore(executor, false), executor
+}
+
+// genPayment returns the creation info of a new payment over the test route,
+// created at the given time, and the preimage of its payment hash.
+func genPayment(t *testing.T, created time.Time) (
+	*channeldb.PaymentCreationInfo, lntypes.Preimage) {
+
+	var preimage lntypes.Preimage
+	_, err := rand.Read(preimage[:])
+	require.NoError(t, err)
+
+	return &channeldb.PaymentCreationInfo{
+		PaymentIdentifier: sha256.Sum256(preimage[:]),
+		Value:             testRoute.ReceiverAmt(),
+		CreationTime:      created,
+		PaymentRequest:    []byte("lnbc"),
+	}, preimage
+}
+
+// genAttempt returns a new HTLC attempt with the given ID over the test route.
+func genAttempt(t *testing.T, id uint64) *channeldb.HTLCAttemptInfo {
+	sessionKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	attempt := channeldb.NewHtlcAttempt(
+		id, sessionKey, *testRoute.Copy(), testTime, nil,
+	)
+
+	return &attempt.HTLCAttemptInfo
+}
+
+// TestSQLStorePaymentLifecycle tests the state transitions of a payment whose
+// first attempt fails and whose second attempt succeeds.
+func TestSQLStorePaymentLifecycle(t *testing.T) {
+	t.Parallel()
+
+	store, _ := newTestStore(t)
+
+	info, preimage := genPayment(t, testTime)
+	hash := info.PaymentIdentifier
+
+	_, err := store.FetchPayment(hash)
+	require.ErrorIs(t, err, channeldb.ErrPaymentNotInitiated)
+
+	require.NoError(t, store.InitPayment(hash, info))
+	require.ErrorIs(
+		t, store.InitPayment(hash, info),
+		channeldb.ErrPaymentExists,
+	)
+
+	payment, err := store.FetchPayment(hash)
+	require.NoError(t, err)
+	require.Equal(t, channeldb.StatusInitiated, payment.Status)
+	require.EqualValues(t, 1, payment.SequenceNum)
+	require.Equal(t, info.PaymentRequest, payment.Info.PaymentRequest)
+	require.True(t, testTime.Equal(payment.Info.CreationTime))
+
+	// The first attempt fails with a wire message.
+	payment, err = store.RegisterAttempt(hash, genAttempt(t, 1))
+	require.NoError(t, err)
+	require.Equal(t, channeldb.StatusInFlight, payment.Status)
+
+	// The payment amount is already fully in flight.
+	_, err = store.RegisterAttempt(hash, genAttempt(t, 2))
+	require.ErrorIs(t, err, channeldb.ErrValueExceedsAmt)
+
+	payment, err = store.FailAttempt(hash, 1, &channeldb.HTLCFailInfo{
+		FailTime:           testTime,
+		Message:            &lnwire.FailTemporaryNodeFailure{},
+		Reason:             channeldb.HTLCFailMessage,
+		FailureSourceIndex: 1,
+	})
+	require.NoError(t, err)
+	require.Len(t, payment.HTLCs, 1)
+	require.NotNil(t, payment.HTLCs[0].Failure)
+	require.Equal(
+		t, &lnwire.FailTemporaryNodeFailure{},
+		payment.HTLCs[0].Failure.Message,
+	)
+
+	_, err = store.FailAttempt(hash, 1, &channeldb.HTLCFailInfo{})
+	require.ErrorIs(t, err, channeldb.ErrAttemptAlreadyFailed)
+
+	// The second attempt settles the payment.
+	_, err = store.RegisterAttempt(hash, genAttempt(t, 2))
+	require.NoError(t, err)
+
+	payment, err = store.SettleAttempt(hash, 2, &channeldb.HTLCSettleInfo{
+		Preimage:   preimage,
+		SettleTime: testTime,
+	})
+	require.NoError(t, err)
+	require.Equal(t, channeldb.StatusSucceeded, payment.Status)
+
+	inFlight, err := store.FetchInFlightPayments()
+	require.NoError(t, err)
+	require.Empty(t, inFlight)
+
+	require.ErrorIs(
+		t, store.InitPayment(hash, info), channeldb.ErrAlreadyPaid,
+	)
+
+	// Removing the failed attempts keeps the settled one.
+	require.NoError(t, store.DeleteFailedAttempts(hash))
+
+	payment, err = store.FetchPayment(hash)
+	require.NoError(t, err)
+	require.Equal(t, channeldb.StatusSucceeded, payment.Status)
+	require.Len(t, payment.HTLCs, 1)
+	require.EqualValues(t, 2, payment.HTLCs[0].AttemptID)
+
+	require.NoError(t, store.DeletePayment(hash, false))
+	_, err = store.FetchPayment(hash)
+	require.ErrorIs(t, err, channeldb.ErrPaymentNotInitiated)
+}
+
+// TestSQLStoreRetryFailedPayment tests that a failed payment can be retried
+// and gets a new sequence number.
+func TestSQLStoreRetryFailedPayment(t *testing.T) {
+	t.Parallel()
+
+	store, _ := newTestStore(t)
+
+	info, _ := genPayment(t, testTime)
+	hash := info.PaymentIdentifier
+
+	require.NoError(t, store.InitPayment(hash, info))
+
+	payment, err := store.Fail(hash, channeldb.FailureReasonNoRoute)
+	require.NoError(t, err)
+	require.Equal(t, channeldb.StatusFailed, payment.Status)
+	require.Equal(
+		t, channeldb.FailureReasonNoRoute, *payment.FailureReason,
+	)
+
+	_, err = store.RegisterAttempt(hash, genAttempt(t, 1))
+	require.ErrorIs(t, err, channeldb.ErrPaymentAlreadyFailed)
+
+	require.NoError(t, store.InitPayment(hash, info))
+
+	payment, err = store.FetchPayment(hash)
+	require.NoError(t, err)
+	require.Equal(t, channeldb.StatusInitiated, payment.Status)
+	require.Nil(t, payment.FailureReason)
+	require.EqualValues(t, 2, payment.SequenceNum)
+}
+
+// TestSQLStoreCanceledReason tests that the reason of a payment that was
+// canceled by the user isn't overwritten when the payment is failed again.
+func TestSQLStoreCanceledReason(t *testing.T) {
+	t.Parallel()
+
+	store, _ := newTestStore(t)
+
+	info, _ := genPayment(t, testTime)
+	hash := info.PaymentIdentifier
+
+	require.NoError(t, store.InitPayment(hash, info))
+
+	_, err := store.Fail(hash, channeldb.FailureReasonCanceled)
+	require.NoError(t, err)
+
+	payment, err := store.Fail(hash, channeldb.FailureReasonTimeout)
+	require.NoError(t, err)
+	require.Equal(
+		t, channeldb.FailureReasonCanceled, *payment.FailureReason,
+	)
+}
+
+// TestSQLStoreQueryPayments tests the filters and pagination of payment
+// queries and the payment statistics.
+func TestSQLStoreQueryPayments(t *testing.T) {
+	t.Parallel()
+
+	store, _ := newTestStore(t)
+
+	// We create a succeeded, a failed and an in-flight payment, each an
+	// hour apart.
+	var hashes []lntypes.Hash
+	for i := 0; i < 3; i++ {
+		created := testTime.Add(time.Duration(i) * time.Hour)
+		info, preimage := genPayment(t, created)
+		hash := info.PaymentIdentifier
+		hashes = append(hashes, hash)
+
+		require.NoError(t, store.InitPayment(hash, info))
+
+		_, err := store.RegisterAttempt(hash, genAttempt(t, 1))
+		require.NoError(t, err)
+
+		switch i {
+		case 0:
+			_, err = store.SettleAttempt(
+				hash, 1, &channeldb.HTLCSettleInfo{
+					Preimage:   preimage,
+					SettleTime: created,
+				},
+			)
+
+		case 1:
+			_, err = store.FailAttempt(
+				hash, 1, &channeldb.HTLCFailInfo{
+					FailTime: created,
+					Reason:   channeldb.HTLCFailUnreadable,
+				},
+			)
+			require.NoError(t, err)
+
+			_, err = store.Fail(
+				hash, channeldb.FailureReasonNoRoute,
+			)
+		}
+		require.NoError(t, err)
+	}
+
+	query := func(q channeldb.PaymentsQuery) []lntypes.Hash {
+		t.Helper()
+
+		if q.MaxPayments == 0 {
+			q.MaxPayments = math.MaxUint64
+		}
+
+		resp, err := store.QueryPayments(q)
+		require.NoError(t, err)
+
+		var result []lntypes.Hash
+		for _, p := range resp.Payments {
+			result = append(result, p.Info.PaymentIdentifier)
+		}
+
+		return result
+	}
+
+	// Only succeeded payments are returned by default.
+	require.Equal(t, hashes[:1], query(channeldb.PaymentsQuery{}))
+	require.Equal(t, hashes, query(channeldb.PaymentsQuery{
+		IncludeIncomplete: true,
+	}))
+
+	require.Equal(t, hashes[1:], query(channeldb.PaymentsQuery{
+		Statuses: []channeldb.PaymentStatus{
+			channeldb.StatusFailed, channeldb.StatusInFlight,
+		},
+	}))
+
+	require.Equal(t, hashes[1:2], query(channeldb.PaymentsQuery{
+		IncludeIncomplete: true,
+		CreationDateStart: testTime.Add(time.Minute),
+		CreationDateEnd:   testTime.Add(90 * time.Minute),
+	}))
+
+	other := route.Vertex{9}
+	require.Len(t, query(channeldb.PaymentsQuery{
+		IncludeIncomplete: true,
+		Destination:       &testDest,
+	}), 3)
+	require.Empty(t, query(channeldb.PaymentsQuery{
+		IncludeIncomplete: true,
+		Destination:       &other,
+	}))
+
+	amt := testRoute.ReceiverAmt()
+	require.Len(t, query(channeldb.PaymentsQuery{
+		IncludeIncomplete: true,
+		MinAmount:         amt,
+		MaxAmount:         amt,
+	}), 3)
+	require.Empty(t, query(channeldb.PaymentsQuery{
+		IncludeIncomplete: true,
+		MinAmount:         amt + 1,
+	}))
+
+	require.Equal(t, hashes[2:], query(channeldb.PaymentsQuery{
+		IncludeIncomplete: true,
+		PaymentHashPrefix: hashes[2][:4],
+	}))
+
+	// Pagination works in both directions and always returns the
+	// payments in ascending order.
+	resp, err := store.QueryPayments(channeldb.PaymentsQuery{
+		IncludeIncomplete: true,
+		MaxPayments:       2,
+		Reversed:          true,
+		CountTotal:        true,
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Payments, 2)
+	require.EqualValues(t, 2, resp.FirstIndexOffset)
+	require.EqualValues(t, 3, resp.LastIndexOffset)
+	require.EqualValues(t, 3, resp.TotalCount)
+
+	require.Equal(t, hashes[:1], query(channeldb.PaymentsQuery{
+		IncludeIncomplete: true,
+		IndexOffset:       resp.FirstIndexOffset,
+		Reversed:          true,
+	}))
+	require.Equal(t, hashes[2:], query(channeldb.PaymentsQuery{
+		IncludeIncomplete: true,
+		IndexOffset:       resp.FirstIndexOffset,
+	}))
+
+	stats, err := store.PaymentStats(time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Equal(t, &channeldb.PaymentStats{
+		NumPayments:  3,
+		NumSucceeded: 1,
+		NumFailed:    1,
+		NumInFlight:  1,
+		TotalSent:    amt,
+		TotalFees:    testRoute.TotalFees(),
+	}, stats)
+
+	stats, err = store.PaymentStats(testTime.Add(time.Minute), time.Time{})
+	require.NoError(t, err)
+	require.Equal(t, &channeldb.PaymentStats{
+		NumPayments: 2,
+		NumFailed:   1,
+		NumInFlight: 1,
+	}, stats)
+
+	// Only the failed payment is deleted.
+	require.NoError(t, store.DeletePayments(true, false))
+	require.Equal(t, []lntypes.Hash{hashes[0], hashes[2]}, query(
+		channeldb.PaymentsQuery{IncludeIncomplete: true},
+	))
+}
@@ -307,11 +307,20 @@ type Config struct {
 	// initiator for anchor channel commitments.
 	MaxAnchorsCommitFeeRate chainfee.SatPerKWeight
 
+	// MaxFeeExposure is used when creating ChannelLinks and is the limit
+	// on the fee exposure of their commitments.
+	MaxFeeExposure lnwallet.FeeExposureLimit
+
 	// CoopCloseTargetConfs is the confirmation target that will be used
 	// to estimate the fee rate to use during a cooperative channel
 	// closure initiated by the remote peer.
 	CoopCloseTargetConfs uint32
 
+	// MaxLocalCSVDelay is used when creating ChannelLinks and is the
+	// maximum csv delay we accept for our own outputs when the remote peer
+	// proposes new channel parameters.
+	MaxLocalCSVDelay uint16
+
 	// ServerPubKey is the serialized, compressed public key of our lnd node.
 	// It is used to determine which policy (channel edge) to pass to the
 	// ChannelLink.
@@ -880,12 +889,23 @@ func (p *Brontide) loadActiveChannels(chans []*channeldb.OpenChannel) (
 		// routing policy into a forwarding policy.
 		var forwardingPolicy *models.ForwardingPolicy
 		if selfPolicy != nil {
+			// An inbound fee that can't be parsed was never
+			// advertised by us, so we fall back to a zero fee.
+			inboundFee, err := selfPolicy.InboundFee()
+			if err != nil {
+				p.log.Warnf("Unable to extract inbound fee "+
+					"for channel %v: %v", chanPoint, err)
+			}
+
 			forwardingPolicy = &models.ForwardingPolicy{
 				MinHTLCOut:    selfPolicy.MinHTLC,
 				MaxHTLC:       selfPolicy.MaxHTLC,
 				BaseFee:       selfPolicy.FeeBaseMSat,
 				FeeRate:       selfPolicy.FeeProportionalMillionths,
 				TimeLockDelta: uint32(selfPolicy.TimeLockDelta),
+				InboundFee: models.NewInboundFeeFromWire(
+					inboundFee,
+				),
 			}
 		} else {
 			p.log.Warnf("Unable to find our forwarding policy "+
@@ -1026,6 +1046,9 @@ func (p *Brontide) addLink(chanPoint *wire.OutPoint,
 		MaxOutgoingCltvExpiry:   p.cfg.MaxOutgoingCltvExpiry,
 		MaxFeeAllocation:        p.cfg.MaxChannelFeeAllocation,
 		MaxAnchorsCommitFeeRate: p.cfg.MaxAnchorsCommitFeeRate,
+		MaxFeeExposure:          p.cfg.MaxFeeExposure,
+		MaxLocalCSVDelay:        p.cfg.MaxLocalCSVDelay,
+		DynCommitments:          p.hasNegotiatedDynCommitments(),
 		NotifyActiveLink:        p.cfg.ChannelNotifier.NotifyActiveLinkEvent,
 		NotifyActiveChannel:     p.cfg.ChannelNotifier.NotifyActiveChannelEvent,
 		NotifyInactiveChannel:   p.cfg.ChannelNotifier.NotifyInactiveChannelEvent,
@@ -1875,6 +1898,19 @@ func messageSummary(msg lnwire.Message) string {
 		return fmt.Sprintf("chan_id=%v, fee_sat=%v", msg.ChannelID,
 			msg.FeeSatoshis)
 
+	case *lnwire.Stfu:
+		return fmt.Sprintf("chan_id=%v, initiator=%v", msg.ChanID,
+			msg.Initiator)
+
+	case *lnwire.DynPropose:
+		return fmt.Sprintf("chan_id=%v", msg.ChanID)
+
+	case *lnwire.DynAck:
+		return fmt.Sprintf("chan_id=%v", msg.ChanID)
+
+	case *lnwire.DynReject:
+		return fmt.Sprintf("chan_id=%v", msg.ChanID)
+
 	case *lnwire.UpdateAddHTLC:
 		return fmt.Sprintf("chan_id=%v, id=%v, amt=%v, expiry=%v, hash=%x",
 			msg.ChanID, msg.ID, msg.Amount, msg.Expiry, msg.PaymentHash[:])
@@ -3350,6 +3386,18 @@ func (p *Brontide) hasNegotiatedScidAlias() bool {
 	return peerHas && localHas
 }
 
+// hasNegotiatedDynCommitments returns true if we've negotiated the
+// dynamic-commitments feature bit with the peer.
+func (p *Brontide) hasNegotiatedDynCommitments() bool {
+	peerHas := p.remoteFeatures.HasFeature(
+		lnwire.DynamicCommitmentsOptional,
+	)
+	localHas := p.cfg.Features.HasFeature(
+		lnwire.DynamicCommitmentsOptional,
+	)
+	return peerHas && localHas
+}
+
 // sendInitMsg sends the Init message to the remote peer. This message contains
 // our currently supported local and global features.
 func (p *Brontide) sendInitMsg(legacyChan bool) error {
@@ -484,6 +484,11 @@ func (m *mockUpdateHandler) MayAddOutgoingHtlc(lnwire.MilliSatoshi) error { retu
 // ShutdownIfChannelClean currently returns nil.
 func (m *mockUpdateHandler) ShutdownIfChannelClean() error { return nil }
 
+// ProposeDynCommit currently returns nil.
+func (m *mockUpdateHandler) ProposeDynCommit(*lnwallet.DynCommitParams) error {
+	return nil
+}
+
 type mockMessageConn struct {
 	t *testing.T
 
@@ -3,6 +3,7 @@ package peernotifier
 import (
 	"sync"
 
+	"github.com/lightningnetwork/lnd/eventlog"
 	"github.com/lightningnetwork/lnd/subscribe"
 )
 
@@ -14,6 +15,9 @@ type PeerNotifier struct {
 	stopped sync.Once
 
 	ntfnServer *subscribe.Server
+
+	// recorder is an optional recorder that persists the events.
+	recorder eventlog.Recorder
 }
 
 // PeerOnlineEvent represents a new event where a peer comes online.
@@ -66,6 +70,20 @@ func (p *PeerNotifier) SubscribePeerEvents() (*subscribe.Client, error) {
 	return p.ntfnServer.Subscribe()
 }
 
+// SetEventRecorder sets a recorder that all peer events are passed to, in
+// addition to the subscribers. It must be called before the notifier is
+// started.
+func (p *PeerNotifier) SetEventRecorder(recorder eventlog.Recorder) {
+	p.recorder = recorder
+}
+
+// record passes the event to the event recorder, if one is set.
+func (p *PeerNotifier) record(event interface{}) {
+	if p.recorder != nil {
+		p.recorder.Record(event)
+	}
+}
+
 // NotifyPeerOnline sends a peer online event to all clients subscribed to the
 // peer notifier.
 func (p *PeerNotifier) NotifyPeerOnline(pubKey [33]byte) {
@@ -73,6 +91,8 @@ func (p *PeerNotifier) NotifyPeerOnline(pubKey [33]byte) {
 
 	log.Debugf("PeerNotifier notifying peer: %x online", pubKey)
 
+	p.record(event)
+
 	if err := p.ntfnServer.SendUpdate(event); err != nil {
 		log.Warnf("Unable to send peer online update: %v", err)
 	}
@@ -85,6 +105,8 @@ func (p *PeerNotifier) NotifyPeerOffline(pubKey [33]byte) {
 
 	log.Debugf("PeerNotifier notifying peer: %x offline", pubKey)
 
+	p.record(event)
+
 	if err := p.ntfnServer.SendUpdate(event); err != nil {
 		log.Warnf("Unable to send peer offline update: %v", err)
 	}
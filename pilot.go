@@ -1,19 +1,33 @@
 package lnd
 
 import (
+	"bytes"
+	"context"
+	"crypto/rand"
 	"errors"
 	"fmt"
 	"net"
+	"sort"
+	"time"
 
 	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/lightningnetwork/lnd/autopilot"
 	"github.com/lightningnetwork/lnd/chainreg"
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/contractcourt"
+	"github.com/lightningnetwork/lnd/feature"
 	"github.com/lightningnetwork/lnd/funding"
+	"github.com/lightningnetwork/lnd/invoices"
 	"github.com/lightningnetwork/lnd/lncfg"
+	"github.com/lightningnetwork/lnd/lnrpc/invoicesrpc"
+	"github.com/lightningnetwork/lnd/lnrpc/routerrpc"
+	"github.com/lightningnetwork/lnd/lntypes"
 	"github.com/lightningnetwork/lnd/lnwallet"
 	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/routing"
+	"github.com/lightningnetwork/lnd/routing/route"
 	"github.com/lightningnetwork/lnd/tor"
 )
 
@@ -71,6 +85,11 @@ func validateAtplCfg(cfg *lncfg.AutoPilot) ([]*autopilot.WeightedHeuristic,
 	return heuristics, nil
 }
 
+// autopilotChanMemo is the memo stored with the channels opened by the
+// autopilot agent. Only those channels are managed by the channel policies,
+// such that channels opened by the user are never closed or rebalanced.
+var autopilotChanMemo = []byte("autopilot")
+
 // chanController is an implementation of the autopilot.ChannelController
 // interface that's backed by a running lnd instance.
 type chanController struct {
@@ -111,6 +130,7 @@ func (c *chanController) OpenChannel(target *btcec.PublicKey,
 		RemoteCsvDelay:   0,
 		MinConfs:         c.minConfs,
 		MaxValueInFlight: 0,
+		Memo:             autopilotChanMemo,
 	}
 
 	updateStream, errChan := c.server.OpenChannel(req)
@@ -124,8 +144,120 @@ func (c *chanController) OpenChannel(target *btcec.PublicKey,
 	}
 }
 
-func (c *chanController) CloseChannel(chanPoint *wire.OutPoint) error {
-	return nil
+// CloseChannel closes the target channel. A force close is done without the
+// peer's cooperation, a cooperative close is negotiated with the peer. This
+// function un-blocks once the closing transaction has been broadcast.
+func (c *chanController) CloseChannel(chanPoint *wire.OutPoint,
+	force bool) error {
+
+	if force {
+		// As we're force closing this channel, we make sure the switch
+		// doesn't use it for forwarding anymore.
+		chanID := lnwire.NewChanIDFromOutPoint(chanPoint)
+		c.server.htlcSwitch.RemoveLink(chanID)
+
+		_, err := c.server.chainArb.ForceCloseContract(*chanPoint)
+
+		return err
+	}
+
+	feePerKw, err := c.server.cc.FeeEstimator.EstimateFeePerKW(
+		c.confTarget,
+	)
+	if err != nil {
+		return err
+	}
+
+	updateStream, errChan := c.server.htlcSwitch.CloseLink(
+		chanPoint, contractcourt.CloseRegular, feePerKw, 0, nil,
+	)
+	select {
+	case err := <-errChan:
+		return err
+	case <-updateStream:
+		return nil
+	case <-c.server.quit:
+		return nil
+	}
+}
+
+// Rebalance moves amt from the outgoing to the incoming channel by paying an
+// invoice to ourselves over a route that leaves through the outgoing channel
+// and comes back through the incoming one. It blocks until the payment has
+// either succeeded or failed.
+func (c *chanController) Rebalance(outgoing, incoming lnwire.ShortChannelID,
+	amt btcutil.Amount, maxFee btcutil.Amount) error {
+
+	self := route.NewVertex(c.server.identityECDH.PubKey())
+
+	// The route needs to reach us through the peer of the incoming
+	// channel.
+	info, _, _, err := c.server.graphDB.FetchChannelEdgesByID(
+		incoming.ToUint64(),
+	)
+	if err != nil {
+		return fmt.Errorf("unable to fetch incoming channel: %w", err)
+	}
+	lastHop := route.Vertex(info.NodeKey1Bytes)
+	if lastHop == self {
+		lastHop = route.Vertex(info.NodeKey2Bytes)
+	}
+
+	var (
+		preimage    lntypes.Preimage
+		paymentAddr [32]byte
+	)
+	if _, err := rand.Read(preimage[:]); err != nil {
+		return err
+	}
+	if _, err := rand.Read(paymentAddr[:]); err != nil {
+		return err
+	}
+	hash := preimage.Hash()
+
+	amtMsat := lnwire.NewMSatFromSatoshis(amt)
+	finalCltvDelta := uint16(c.server.cfg.Bitcoin.TimeLockDelta)
+	features := c.server.featureMgr.Get(feature.SetInvoice)
+	invoice := &invoices.Invoice{
+		CreationDate: time.Now(),
+		Memo:         []byte("autopilot rebalance"),
+		Terms: invoices.ContractTerm{
+			FinalCltvDelta:  int32(finalCltvDelta),
+			Expiry:          invoicesrpc.DefaultInvoiceExpiry,
+			Value:           amtMsat,
+			PaymentPreimage: &preimage,
+			PaymentAddr:     paymentAddr,
+			Features:        features,
+		},
+	}
+	_, err = c.server.invoices.AddInvoice(
+		context.Background(), invoice, hash,
+	)
+	if err != nil {
+		return fmt.Errorf("unable to add invoice: %w", err)
+	}
+
+	payment := &routing.LightningPayment{
+		Target:             self,
+		Amount:             amtMsat,
+		FeeLimit:           lnwire.NewMSatFromSatoshis(maxFee),
+		CltvLimit:          c.server.cfg.MaxOutgoingCltvExpiry,
+		FinalCLTVDelta:     finalCltvDelta,
+		PayAttemptTimeout:  routing.DefaultPayAttemptTimeout,
+		OutgoingChannelIDs: []uint64{outgoing.ToUint64()},
+		LastHop:            &lastHop,
+		DestFeatures:       features,
+		PaymentAddr:        &paymentAddr,
+		MaxParts:           routerrpc.DefaultMaxParts,
+		Rebalance:          true,
+	}
+	if err := payment.SetPaymentHash(hash); err != nil {
+		return err
+	}
+
+	_, _, err = c.server.chanRouter.SendPayment(payment)
+
+	return err
 }
 
 // A compile time assertion to ensure chanController meets the
@@ -159,6 +291,31 @@ func initAutoPilot(svr *server, cfg *lncfg.AutoPilot,
 		return nil, err
 	}
 
+	// The reliability heuristic scores nodes based on what our node has
+	// learned about them, so we replace the registered instance with one
+	// that has access to the relevant subsystems. The same instance is
+	// used by the agent and when querying the scores of all heuristics.
+	reliability := autopilot.NewReliabilityAttachment(
+		newReliabilityConfig(svr),
+	)
+	for _, h := range heuristics {
+		_, ok := h.AttachmentHeuristic.(*autopilot.ReliabilityAttachment)
+		if ok {
+			h.AttachmentHeuristic = reliability
+		}
+	}
+
+	queryHeuristics := make(
+		[]autopilot.AttachmentHeuristic, 0,
+		len(autopilot.AvailableHeuristics),
+	)
+	for _, h := range autopilot.AvailableHeuristics {
+		if _, ok := h.(*autopilot.ReliabilityAttachment); ok {
+			h = reliability
+		}
+		queryHeuristics = append(queryHeuristics, h)
+	}
+
 	weightedAttachment, err := autopilot.NewWeightedCombAttachment(
 		heuristics...,
 	)
@@ -247,13 +404,26 @@ func initAutoPilot(svr *server, cfg *lncfg.AutoPilot,
 			return false, nil
 		},
 		DisconnectPeer: svr.DisconnectPeer,
+		Policies: autopilot.ChannelPolicies{
+			Interval:            cfg.PolicyInterval,
+			InactiveTimeout:     cfg.CloseInactiveAfter,
+			UnbalancedTimeout:   cfg.CloseUnbalancedAfter,
+			UnbalancedRatio:     cfg.UnbalancedRatio,
+			MaxCloses:           cfg.MaxCloses,
+			RebalanceRatio:      cfg.RebalanceRatio,
+			RebalanceMaxFeeRate: cfg.RebalanceMaxFeeRate,
+		},
+		ChannelStatus: func() ([]autopilot.ChannelStatus, error) {
+			return channelStatus(svr)
+		},
 	}
 
 	// Create and return the autopilot.ManagerCfg that administrates this
 	// agent-pilot instance.
 	return &autopilot.ManagerCfg{
-		Self:     self,
-		PilotCfg: &pilotCfg,
+		Self:       self,
+		PilotCfg:   &pilotCfg,
+		Heuristics: queryHeuristics,
 		ChannelState: func() ([]autopilot.LocalChannel, error) {
 			// We'll fetch the current state of open
 			// channels from the database to use as initial
@@ -298,3 +468,140 @@ func initAutoPilot(svr *server, cfg *lncfg.AutoPilot,
 		SubscribeTopology:     svr.chanRouter.SubscribeTopology,
 	}, nil
 }
+
+// newReliabilityConfig returns the sources of information used by the
+// reliability heuristic: mission control's success probabilities, peer uptime
+// as tracked by the channel event store, the fee rates advertised in the graph
+// and the current block height.
+func newReliabilityConfig(svr *server) *autopilot.ReliabilityConfig {
+	return &autopilot.ReliabilityConfig{
+		SuccessProbability: svr.missionControl.GetProbability,
+		PeerUptime: func(peer route.Vertex) (float64, bool) {
+			return peerUptime(svr, peer)
+		},
+		FeeRates: func() (map[autopilot.NodeID]uint32, error) {
+			return medianFeeRates(svr.graphDB)
+		},
+		BestHeight: func() (uint32, error) {
+			_, height, err := svr.cc.ChainIO.GetBestBlock()
+			if err != nil {
+				return 0, err
+			}
+
+			return uint32(height), nil
+		},
+	}
+}
+
+// peerUptime returns the fraction of time the given peer was online over the
+// monitored lifetime of all our open channels with it. The boolean is false if
+// we don't have any uptime information about the peer.
+func peerUptime(svr *server, peer route.Vertex) (float64, bool) {
+	pubKey, err := btcec.ParsePubKey(peer[:])
+	if err != nil {
+		return 0, false
+	}
+
+	channels, err := svr.chanStateDB.FetchOpenChannels(pubKey)
+	if err != nil {
+		atplLog.Debugf("Unable to fetch channels with %v: %v", peer,
+			err)
+
+		return 0, false
+	}
+
+	var lifetime, uptime time.Duration
+	for _, channel := range channels {
+		info, err := svr.chanEventStore.GetChanInfo(
+			channel.FundingOutpoint, peer,
+		)
+		if err != nil {
+			continue
+		}
+
+		lifetime += info.Lifetime
+		uptime += info.Uptime
+	}
+
+	if lifetime == 0 {
+		return 0, false
+	}
+
+	return float64(uptime) / float64(lifetime), true
+}
+
+// medianFeeRates returns the median proportional fee rate in ppm each node in
+// the graph advertises on its channels.
+func medianFeeRates(graph *channeldb.ChannelGraph) (
+	map[autopilot.NodeID]uint32, error) {
+
+	rates := make(map[autopilot.NodeID][]uint32)
+	err := graph.ForEachChannel(func(info *channeldb.ChannelEdgeInfo,
+		p1, p2 *channeldb.ChannelEdgePolicy) error {
+
+		if p1 != nil {
+			node := autopilot.NodeID(info.NodeKey1Bytes)
+			rate := uint32(p1.FeeProportionalMillionths)
+			rates[node] = append(rates[node], rate)
+		}
+		if p2 != nil {
+			node := autopilot.NodeID(info.NodeKey2Bytes)
+			rate := uint32(p2.FeeProportionalMillionths)
+			rates[node] = append(rates[node], rate)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	medians := make(map[autopilot.NodeID]uint32, len(rates))
+	for node, nodeRates := range rates {
+		sort.Slice(nodeRates, func(i, j int) bool {
+			return nodeRates[i] < nodeRates[j]
+		})
+		medians[node] = nodeRates[len(nodeRates)/2]
+	}
+
+	return medians, nil
+}
+
+// channelStatus returns the status of the open channels the autopilot agent
+// opened for its channel policies. Channels opened by the user, as well as
+// channels that are pending, being closed or otherwise not in their default
+// state are left alone.
+func channelStatus(svr *server) ([]autopilot.ChannelStatus, error) {
+	channels, err := svr.chanStateDB.FetchAllOpenChannels()
+	if err != nil {
+		return nil, err
+	}
+
+	status := make([]autopilot.ChannelStatus, 0, len(channels))
+	for _, channel := range channels {
+		if !bytes.Equal(channel.Memo, autopilotChanMemo) {
+			continue
+		}
+
+		if channel.ChanStatus() != channeldb.ChanStatusDefault {
+			continue
+		}
+
+		chanID := lnwire.NewChanIDFromOutPoint(&channel.FundingOutpoint)
+		link, err := svr.htlcSwitch.GetLink(chanID)
+		active := err == nil && link.EligibleToForward()
+
+		localCommit := channel.LocalCommitment
+		status = append(status, autopilot.ChannelStatus{
+			ChanID:       channel.ShortChanID(),
+			ChanPoint:    channel.FundingOutpoint,
+			Node:         autopilot.NewNodeID(channel.IdentityPub),
+			Capacity:     channel.Capacity,
+			LocalBalance: localCommit.LocalBalance.ToSatoshis(),
+			Active:       active,
+			NumUpdates:   localCommit.CommitHeight,
+		})
+	}
+
+	return status, nil
+}
@@ -35,6 +35,45 @@ type dbMPPayment interface {
 	GetFailureReason() *channeldb.FailureReason
 }
 
+// PaymentsDB is the database the ControlTower persists the payments and their
+// HTLC attempts to.
+type PaymentsDB interface {
+	// InitPayment checks or records the given PaymentCreationInfo with
+	// the DB, making sure it does not already exist as an in-flight
+	// payment.
+	InitPayment(lntypes.Hash, *channeldb.PaymentCreationInfo) error
+
+	// DeleteFailedAttempts removes all failed HTLCs of the payment.
+	DeleteFailedAttempts(lntypes.Hash) error
+
+	// RegisterAttempt atomically records the provided HTLCAttemptInfo.
+	RegisterAttempt(lntypes.Hash,
+		*channeldb.HTLCAttemptInfo) (*channeldb.MPPayment, error)
+
+	// SettleAttempt marks the given attempt settled with the preimage.
+	SettleAttempt(lntypes.Hash, uint64,
+		*channeldb.HTLCSettleInfo) (*channeldb.MPPayment, error)
+
+	// FailAttempt marks the given payment attempt failed.
+	FailAttempt(lntypes.Hash, uint64,
+		*channeldb.HTLCFailInfo) (*channeldb.MPPayment, error)
+
+	// Fail transitions a payment into the Failed state.
+	Fail(lntypes.Hash, channeldb.FailureReason) (*channeldb.MPPayment,
+		error)
+
+	// FetchPayment fetches the payment corresponding to the given payment
+	// hash.
+	FetchPayment(lntypes.Hash) (*channeldb.MPPayment, error)
+
+	// FetchInFlightPayments returns all payments with status InFlight.
+	FetchInFlightPayments() ([]*channeldb.MPPayment, error)
+}
+
+// A compile time check to ensure that the key-value payment control fully
+// implements the PaymentsDB interface.
+var _ PaymentsDB = (*channeldb.PaymentControl)(nil)
+
 // ControlTower tracks all outgoing payments made, whose primary purpose is to
 // prevent duplicate payments to the same payment hash. In production, a
 // persistent implementation is preferred so that tracking can survive across
@@ -80,6 +119,14 @@ type ControlTower interface {
 	// payment.
 	FailPayment(lntypes.Hash, channeldb.FailureReason) error
 
+	// CancelPayment marks the payment as canceled by the user. No new
+	// attempts can be registered for it afterwards, while the attempts in
+	// flight are allowed to resolve. Once they are failed, the payment
+	// fails with FailureReasonCanceled. An error is returned if the
+	// payment can't be canceled anymore, because it already failed or
+	// one of its attempts was settled.
+	CancelPayment(lntypes.Hash) error
+
 	// FetchInFlightPayments returns all payments with status InFlight.
 	FetchInFlightPayments() ([]*channeldb.MPPayment, error)
 
@@ -145,7 +192,7 @@ func (s *controlTowerSubscriberImpl) Updates() <-chan interface{} {
 // controlTower is persistent implementation of ControlTower to restrict
 // double payment sending.
 type controlTower struct {
-	db *channeldb.PaymentControl
+	db PaymentsDB
 
 	// subscriberIndex is used to provide a unique id for each subscriber
 	// to all payments. This is used to easily remove the subscriber when
@@ -162,7 +209,7 @@ type controlTower struct {
 }
 
 // NewControlTower creates a new instance of the controlTower.
-func NewControlTower(db *channeldb.PaymentControl) ControlTower {
+func NewControlTower(db PaymentsDB) ControlTower {
 	return &controlTower{
 		db: db,
 		subscribersAllPayments: make(
@@ -276,6 +323,37 @@ func (p *controlTower) FailPayment(paymentHash lntypes.Hash,
 	return nil
 }
 
+// CancelPayment marks the payment as canceled by the user. No new attempts can
+// be registered for it afterwards, while the attempts in flight are allowed to
+// resolve.
+//
+// NOTE: Part of the ControlTower interface.
+func (p *controlTower) CancelPayment(paymentHash lntypes.Hash) error {
+	p.paymentsMtx.Lock(paymentHash)
+	defer p.paymentsMtx.Unlock(paymentHash)
+
+	payment, err := p.db.FetchPayment(paymentHash)
+	if err != nil {
+		return err
+	}
+
+	// A payment can only be canceled as long as new attempts could be
+	// registered for it.
+	if err := payment.Registrable(); err != nil {
+		return err
+	}
+
+	payment, err = p.db.Fail(paymentHash, channeldb.FailureReasonCanceled)
+	if err != nil {
+		return err
+	}
+
+	// Notify subscribers of the cancellation.
+	p.notifySubscribers(paymentHash, payment)
+
+	return nil
+}
+
 // FetchInFlightPayments returns all payments with status InFlight.
 func (p *controlTower) FetchInFlightPayments() ([]*channeldb.MPPayment, error) {
 	return p.db.FetchInFlightPayments()
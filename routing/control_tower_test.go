@@ -179,6 +179,100 @@ func TestPaymentControlSubscribeFail(t *testing.T) {
 	})
 }
 
+// TestControlTowerCancelPayment tests that a canceled payment doesn't accept
+// new attempts and fails as canceled once its attempts in flight are resolved.
+func TestControlTowerCancelPayment(t *testing.T) {
+	t.Parallel()
+
+	db, err := initDB(t, true)
+	require.NoError(t, err, "unable to init db")
+
+	pControl := NewControlTower(channeldb.NewPaymentControl(db))
+
+	// Canceling an unknown payment fails.
+	info, attempt, preimg, err := genInfo()
+	require.NoError(t, err)
+
+	err = pControl.CancelPayment(info.PaymentIdentifier)
+	require.ErrorIs(t, err, channeldb.ErrPaymentNotInitiated)
+
+	// Initiate a payment with an attempt in flight.
+	err = pControl.InitPayment(info.PaymentIdentifier, info)
+	require.NoError(t, err)
+
+	err = pControl.RegisterAttempt(info.PaymentIdentifier, attempt)
+	require.NoError(t, err)
+
+	subscriber, err := pControl.SubscribePayment(info.PaymentIdentifier)
+	require.NoError(t, err)
+
+	// Cancel the payment. It stays in flight until its attempt is
+	// resolved, and subscribers are notified of the cancellation.
+	err = pControl.CancelPayment(info.PaymentIdentifier)
+	require.NoError(t, err)
+
+	payment, err := pControl.FetchPayment(info.PaymentIdentifier)
+	require.NoError(t, err)
+	require.Equal(t, channeldb.StatusInFlight, payment.GetStatus())
+
+	// Canceling it again fails.
+	err = pControl.CancelPayment(info.PaymentIdentifier)
+	require.ErrorIs(t, err, channeldb.ErrPaymentPendingFailed)
+
+	// No new attempts can be registered.
+	_, attempt2, _, err := genInfo()
+	require.NoError(t, err)
+	attempt2.AttemptID = 2
+
+	err = pControl.RegisterAttempt(info.PaymentIdentifier, attempt2)
+	require.ErrorIs(t, err, channeldb.ErrPaymentPendingFailed)
+
+	// Once the attempt in flight fails, the payment fails as canceled.
+	_, err = pControl.FailAttempt(
+		info.PaymentIdentifier, attempt.AttemptID,
+		&channeldb.HTLCFailInfo{
+			Reason: channeldb.HTLCFailInternal,
+		},
+	)
+	require.NoError(t, err)
+
+	var result *channeldb.MPPayment
+	for result == nil || !result.Terminated() {
+		select {
+		case item := <-subscriber.Updates():
+			result = item.(*channeldb.MPPayment)
+
+		case <-time.After(testTimeout):
+			t.Fatal("timeout waiting for payment result")
+		}
+	}
+
+	require.Equal(t, channeldb.StatusFailed, result.GetStatus())
+	require.NotNil(t, result.FailureReason)
+	require.Equal(t, channeldb.FailureReasonCanceled, *result.FailureReason)
+
+	// A payment with a settled attempt can't be canceled.
+	info, attempt, preimg, err = genInfo()
+	require.NoError(t, err)
+
+	err = pControl.InitPayment(info.PaymentIdentifier, info)
+	require.NoError(t, err)
+
+	err = pControl.RegisterAttempt(info.PaymentIdentifier, attempt)
+	require.NoError(t, err)
+
+	_, err = pControl.SettleAttempt(
+		info.PaymentIdentifier, attempt.AttemptID,
+		&channeldb.HTLCSettleInfo{
+			Preimage: preimg,
+		},
+	)
+	require.NoError(t, err)
+
+	err = pControl.CancelPayment(info.PaymentIdentifier)
+	require.ErrorIs(t, err, channeldb.ErrPaymentAlreadySucceeded)
+}
+
 // TestPaymentControlSubscribeAllSuccess tests that multiple payments are
 // properly sent to subscribers of TrackPayments.
 func TestPaymentControlSubscribeAllSuccess(t *testing.T) {
@@ -45,6 +45,7 @@ type integratedRoutingContext struct {
 
 	amt         lnwire.MilliSatoshi
 	maxShardAmt *lnwire.MilliSatoshi
+	splitMode   SplitMode
 	finalExpiry int32
 
 	mcCfg          MissionControlConfig
@@ -186,6 +187,7 @@ func (c *integratedRoutingContext) testPayment(maxParts uint32,
 		CltvLimit:      math.MaxUint32,
 		MaxParts:       maxParts,
 		RouteHints:     c.routeHints,
+		SplitMode:      c.splitMode,
 	}
 
 	var paymentHash [32]byte
@@ -276,7 +278,7 @@ func (c *integratedRoutingContext) testPayment(maxParts uint32,
 		finalResult, err := mc.ReportPaymentFail(
 			pid, route,
 			getNodeIndex(route, htlcResult.failureSource),
-			htlcResult.failure,
+			htlcResult.failure, nil,
 		)
 		if err != nil {
 			c.t.Fatal(err)
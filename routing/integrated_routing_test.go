@@ -91,6 +91,7 @@ type mppSendTestCase struct {
 	expectedFailure bool
 	maxParts        uint32
 	maxShardSize    btcutil.Amount
+	splitMode       SplitMode
 }
 
 const (
@@ -237,6 +238,112 @@ var mppTestCases = []mppSendTestCase{
 		maxParts:     1000,
 		maxShardSize: 10_000,
 	},
+
+	// Test that a payment split along a min-cost flow sends its parts over
+	// both paths right away, without the failed attempts of halving.
+	{
+		name: "sufficient inbound min-cost flow",
+		graph: func(g *mockGraph) {
+			twoPathGraph(g, 200000, 100000)
+		},
+		amt:              70000,
+		expectedAttempts: 2,
+		expectedSuccesses: []expectedHtlcSuccess{
+			{
+				amt:   35000,
+				chans: []uint64{chanSourceIm1, chanIm1Target},
+			},
+			{
+				amt:   35000,
+				chans: []uint64{chanSourceIm2, chanIm2Target},
+			},
+		},
+		maxParts:  1000,
+		splitMode: SplitModeFlow,
+	},
+
+	// Test that the remaining amount is planned again when a part of a
+	// min-cost flow fails. The first path lacks the liquidity for its part
+	// of the initial flow, after which the full amount is sent over the
+	// second path.
+	{
+		name: "replan min-cost flow",
+		graph: func(g *mockGraph) {
+			twoPathGraph(g, 200000, 100000)
+
+			// Move most of the liquidity of the first path to
+			// the second one.
+			target := createPubkey(targetNodeID)
+			im1 := createPubkey(3)
+			im2 := createPubkey(4)
+			g.nodes[im1].channels[target].balance = 10_000_000
+			g.nodes[im2].channels[target].balance = 90_000_000
+		},
+		amt:              70000,
+		expectedAttempts: 2,
+		expectedSuccesses: []expectedHtlcSuccess{
+			{
+				amt:   70000,
+				chans: []uint64{chanSourceIm2, chanIm2Target},
+			},
+		},
+		maxParts:  1000,
+		splitMode: SplitModeFlow,
+	},
+
+	// Test that the parts of a min-cost flow respect the max shard size.
+	{
+		name:             "max shard size min-cost flow",
+		graph:            onePathGraph,
+		amt:              30_000,
+		expectedAttempts: 3,
+		expectedSuccesses: []expectedHtlcSuccess{
+			{
+				amt:   10_000,
+				chans: []uint64{chanSourceIm1, chanIm1Target},
+			},
+			{
+				amt:   10_000,
+				chans: []uint64{chanSourceIm1, chanIm1Target},
+			},
+			{
+				amt:   10_000,
+				chans: []uint64{chanSourceIm1, chanIm1Target},
+			},
+		},
+		maxParts:     1000,
+		maxShardSize: 10_000,
+		splitMode:    SplitModeFlow,
+	},
+
+	// Test that a min-cost flow that would exceed the maximum number of
+	// parts falls back to halving, which then fails as well.
+	{
+		name: "no splitting min-cost flow",
+		graph: func(g *mockGraph) {
+			twoPathGraph(g, 200000, 100000)
+		},
+		amt:               70000,
+		expectedAttempts:  2,
+		expectedSuccesses: []expectedHtlcSuccess{},
+		expectedFailure:   true,
+		maxParts:          1,
+		splitMode:         SplitModeFlow,
+	},
+
+	// Test that no attempts are made with a min-cost flow if the total
+	// local balance is insufficient.
+	{
+		name: "insufficient total balance min-cost flow",
+		graph: func(g *mockGraph) {
+			twoPathGraph(g, 100000, 500000)
+		},
+		amt:              300000,
+		expectedAttempts: 0,
+		expectedFailure:  true,
+		maxParts:         10,
+		splitMode:        SplitModeFlow,
+	},
 }
 
 // TestBadFirstHopHint tests that a payment with a first hop hint with an
@@ -291,6 +398,16 @@ func testMppSend(t *testing.T, testCase *mppSendTestCase) {
 		ctx.maxShardAmt = &shardAmt
 	}
 
+	// A min-cost flow is computed from the liquidity estimates of the
+	// bimodal estimator, which let the probability drop with the amount.
+	ctx.splitMode = testCase.splitMode
+	if ctx.splitMode == SplitModeFlow {
+		estimator, err := NewBimodalEstimator(DefaultBimodalConfig())
+		require.NoError(t, err)
+
+		ctx.mcCfg.Estimator = estimator
+	}
+
 	attempts, err := ctx.testPayment(testCase.maxParts)
 	switch {
 	case err == nil && testCase.expectedFailure:
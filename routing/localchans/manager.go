@@ -105,6 +105,11 @@ func (r *Manager) UpdatePolicy(newSchema routing.ChannelPolicy,
 			Edge: edge,
 		})
 
+		// Extract the inbound fee to pass it to the switch as well.
+		// An inbound fee that can't be parsed was never advertised by
+		// us, so we fall back to a zero fee.
+		inboundFee, _ := edge.InboundFee()
+
 		// Add updated policy to list of policies to send to switch.
 		policiesToUpdate[info.ChannelPoint] = models.ForwardingPolicy{
 			BaseFee:       edge.FeeBaseMSat,
@@ -112,6 +117,9 @@ func (r *Manager) UpdatePolicy(newSchema routing.ChannelPolicy,
 			TimeLockDelta: uint32(edge.TimeLockDelta),
 			MinHTLCOut:    edge.MinHTLC,
 			MaxHTLC:       edge.MaxHTLC,
+			InboundFee: models.NewInboundFeeFromWire(
+				inboundFee,
+			),
 		}
 
 		return nil
@@ -182,6 +190,14 @@ func (r *Manager) updateEdge(tx kvdb.RTx, chanPoint wire.OutPoint,
 	)
 	edge.TimeLockDelta = uint16(newSchema.TimeLockDelta)
 
+	// Update the inbound fee if one was specified.
+	if newSchema.InboundFee != nil {
+		err := edge.SetInboundFee(newSchema.InboundFee.ToWire())
+		if err != nil {
+			return err
+		}
+	}
+
 	// Retrieve negotiated channel htlc amt limits.
 	amtMin, amtMax, err := r.getHtlcAmtLimits(tx, chanPoint)
 	if err != nil {
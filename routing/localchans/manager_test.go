@@ -32,6 +32,7 @@ func TestManager(t *testing.T) {
 		maxPendingAmount   = lnwire.MilliSatoshi(999000)
 		minHTLC            = lnwire.MilliSatoshi(2000)
 		expectedNumUpdates int
+		expectedInboundFee models.InboundFee
 		channelSet         []channel
 	)
 
@@ -73,6 +74,7 @@ func TestManager(t *testing.T) {
 		if policy.MaxHTLC != newPolicy.MaxHTLC {
 			t.Fatal("unexpected max htlc")
 		}
+		require.Equal(t, expectedInboundFee, policy.InboundFee)
 	}
 
 	propagateChanPolicyUpdate := func(
@@ -101,6 +103,10 @@ func TestManager(t *testing.T) {
 			if policy.MaxHTLC != newPolicy.MaxHTLC {
 				t.Fatal("unexpected max htlc")
 			}
+
+			inboundFee, err := policy.InboundFee()
+			require.NoError(t, err)
+			require.Equal(t, expectedInboundFee.ToWire(), inboundFee)
 		}
 
 		return nil
@@ -150,6 +156,17 @@ func TestManager(t *testing.T) {
 	noMaxHtlcPolicy := newPolicy
 	noMaxHtlcPolicy.MaxHTLC = 0
 
+	// Policy that sets an inbound discount.
+	inboundFee := models.InboundFee{Base: -10, Rate: -100}
+	inboundFeePolicy := newPolicy
+	inboundFeePolicy.InboundFee = &inboundFee
+
+	// Current policy that already advertises the inbound discount.
+	currentInboundFeePolicy := currentPolicy
+	require.NoError(t, currentInboundFeePolicy.SetInboundFee(
+		inboundFee.ToWire(),
+	))
+
 	tests := []struct {
 		name                   string
 		currentPolicy          channeldb.ChannelEdgePolicy
@@ -158,6 +175,7 @@ func TestManager(t *testing.T) {
 		specifiedChanPoints    []wire.OutPoint
 		expectedNumUpdates     int
 		expectedUpdateFailures []lnrpc.UpdateFailure
+		expectedInboundFee     models.InboundFee
 		expectErr              error
 	}{
 		{
@@ -232,6 +250,46 @@ func TestManager(t *testing.T) {
 			expectedUpdateFailures: []lnrpc.UpdateFailure{},
 			expectErr:              nil,
 		},
+		{
+			// Here, an inbound fee is specified, which should be
+			// advertised and applied to the link.
+			name:          "inbound fee specified",
+			currentPolicy: currentPolicy,
+			newPolicy:     inboundFeePolicy,
+			channelSet: []channel{
+				{
+					edgeInfo: &channeldb.ChannelEdgeInfo{
+						Capacity:     chanCap,
+						ChannelPoint: chanPointValid,
+					},
+				},
+			},
+			specifiedChanPoints:    []wire.OutPoint{chanPointValid},
+			expectedNumUpdates:     1,
+			expectedUpdateFailures: []lnrpc.UpdateFailure{},
+			expectedInboundFee:     inboundFee,
+			expectErr:              nil,
+		},
+		{
+			// Here, no inbound fee is specified, the inbound fee
+			// should be kept unchanged.
+			name:          "no inbound fee specified",
+			currentPolicy: currentInboundFeePolicy,
+			newPolicy:     newPolicy,
+			channelSet: []channel{
+				{
+					edgeInfo: &channeldb.ChannelEdgeInfo{
+						Capacity:     chanCap,
+						ChannelPoint: chanPointValid,
+					},
+				},
+			},
+			specifiedChanPoints:    []wire.OutPoint{chanPointValid},
+			expectedNumUpdates:     1,
+			expectedUpdateFailures: []lnrpc.UpdateFailure{},
+			expectedInboundFee:     inboundFee,
+			expectErr:              nil,
+		},
 	}
 
 	for _, test := range tests {
@@ -240,6 +298,7 @@ func TestManager(t *testing.T) {
 			currentPolicy = test.currentPolicy
 			channelSet = test.channelSet
 			expectedNumUpdates = test.expectedNumUpdates
+			expectedInboundFee = test.expectedInboundFee
 
 			failedUpdates, err := manager.UpdatePolicy(test.newPolicy,
 				test.specifiedChanPoints...)
@@ -198,6 +198,10 @@ type paymentResult struct {
 	success            bool
 	failureSourceIdx   *int
 	failure            lnwire.FailureMessage
+
+	// holdTimes are the hold times reported by the hops that attributed
+	// the failure, starting at the first hop.
+	holdTimes []time.Duration
 }
 
 // NewMissionControl returns a new instance of missionControl.
@@ -400,12 +404,13 @@ func (m *MissionControl) GetPairHistorySnapshot(
 
 // ReportPaymentFail reports a failed payment to mission control as input for
 // future probability estimates. The failureSourceIdx argument indicates the
-// failure source. If it is nil, the failure source is unknown. This function
-// returns a reason if this failure is a final failure. In that case no further
-// payment attempts need to be made.
+// failure source. If it is nil, the failure source is unknown. The holdTimes
+// are the hold times reported by the hops that attributed the failure. This
+// function returns a reason if this failure is a final failure. In that case
+// no further payment attempts need to be made.
 func (m *MissionControl) ReportPaymentFail(paymentID uint64, rt *route.Route,
-	failureSourceIdx *int, failure lnwire.FailureMessage) (
-	*channeldb.FailureReason, error) {
+	failureSourceIdx *int, failure lnwire.FailureMessage,
+	holdTimes []time.Duration) (*channeldb.FailureReason, error) {
 
 	timestamp := m.now()
 
@@ -417,6 +422,7 @@ func (m *MissionControl) ReportPaymentFail(paymentID uint64, rt *route.Route,
 		failureSourceIdx: failureSourceIdx,
 		failure:          failure,
 		route:            rt,
+		holdTimes:        holdTimes,
 	}
 
 	return m.processPaymentResult(result)
@@ -467,7 +473,7 @@ func (m *MissionControl) applyPaymentResult(
 	// Interpret result.
 	i := interpretResult(
 		result.route, result.success, result.failureSourceIdx,
-		result.failure,
+		result.failure, result.holdTimes,
 	)
 
 	if i.policyFailure != nil {
@@ -199,6 +199,23 @@ func serializeResult(rp *paymentResult) ([]byte, []byte, error) {
 		return nil, nil, err
 	}
 
+	// Write the hold times reported by the hops, if any. They are
+	// optional, so that results stored before they were added can still
+	// be read.
+	if len(rp.holdTimes) > 0 {
+		err := channeldb.WriteElements(&b, uint16(len(rp.holdTimes)))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, holdTime := range rp.holdTimes {
+			err := channeldb.WriteElements(&b, uint64(holdTime))
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
 	// Compose key that identifies this result.
 	key := getResultKey(rp)
 
@@ -260,6 +277,24 @@ func deserializeResult(k, v []byte) (*paymentResult, error) {
 		}
 	}
 
+	// Read the hold times, if present.
+	if r.Len() > 0 {
+		var numHoldTimes uint16
+		if err := channeldb.ReadElements(r, &numHoldTimes); err != nil {
+			return nil, err
+		}
+
+		result.holdTimes = make([]time.Duration, numHoldTimes)
+		for i := range result.holdTimes {
+			var holdTime uint64
+			err := channeldb.ReadElements(r, &holdTime)
+			if err != nil {
+				return nil, err
+			}
+			result.holdTimes[i] = time.Duration(holdTime)
+		}
+	}
+
 	return &result, nil
 }
 
@@ -82,6 +82,7 @@ func TestMissionControlStore(t *testing.T) {
 	result2.timeReply = result1.timeReply.Add(time.Hour)
 	result2.timeFwd = result1.timeReply.Add(time.Hour)
 	result2.id = 2
+	result2.holdTimes = []time.Duration{time.Second, 0}
 
 	// Store result.
 	store.AddResult(&result2)
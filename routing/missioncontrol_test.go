@@ -132,7 +132,7 @@ func (ctx *mcTestContext) reportFailure(amt lnwire.MilliSatoshi,
 
 	errorSourceIdx := 1
 	ctx.mc.ReportPaymentFail(
-		ctx.pid, mcTestRoute, &errorSourceIdx, failure,
+		ctx.pid, mcTestRoute, &errorSourceIdx, failure, nil,
 	)
 }
 
@@ -3,6 +3,7 @@ package routing
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcutil"
@@ -120,8 +121,8 @@ var _ MissionController = (*mockMissionControlOld)(nil)
 
 func (m *mockMissionControlOld) ReportPaymentFail(
 	paymentID uint64, rt *route.Route,
-	failureSourceIdx *int, failure lnwire.FailureMessage) (
-	*channeldb.FailureReason, error) {
+	failureSourceIdx *int, failure lnwire.FailureMessage,
+	_ []time.Duration) (*channeldb.FailureReason, error) {
 
 	// Report a permanent failure if this is an error caused
 	// by incorrect details.
@@ -490,6 +491,24 @@ func (m *mockControlTowerOld) FailPayment(phash lntypes.Hash,
 	return nil
 }
 
+func (m *mockControlTowerOld) CancelPayment(phash lntypes.Hash) error {
+	m.Lock()
+	defer m.Unlock()
+
+	payment, err := m.fetchPayment(phash)
+	if err != nil {
+		return err
+	}
+
+	if err := payment.Registrable(); err != nil {
+		return err
+	}
+
+	m.failed[phash] = channeldb.FailureReasonCanceled
+
+	return nil
+}
+
 func (m *mockControlTowerOld) FetchPayment(phash lntypes.Hash) (
 	dbMPPayment, error) {
 
@@ -635,8 +654,8 @@ var _ MissionController = (*mockMissionControl)(nil)
 
 func (m *mockMissionControl) ReportPaymentFail(
 	paymentID uint64, rt *route.Route,
-	failureSourceIdx *int, failure lnwire.FailureMessage) (
-	*channeldb.FailureReason, error) {
+	failureSourceIdx *int, failure lnwire.FailureMessage,
+	_ []time.Duration) (*channeldb.FailureReason, error) {
 
 	args := m.Called(paymentID, rt, failureSourceIdx, failure)
 
@@ -749,6 +768,14 @@ func (m *mockControlTower) FailPayment(phash lntypes.Hash,
 	return args.Error(0)
 }
 
+func (m *mockControlTower) CancelPayment(phash lntypes.Hash) error {
+	m.Lock()
+	defer m.Unlock()
+
+	args := m.Called(phash)
+	return args.Error(0)
+}
+
 func (m *mockControlTower) FetchPayment(phash lntypes.Hash) (
 	dbMPPayment, error) {
 
@@ -11,6 +11,7 @@ import (
 	"github.com/btcsuite/btcd/btcutil"
 	sphinx "github.com/lightningnetwork/lightning-onion"
 	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/channeldb/models"
 	"github.com/lightningnetwork/lnd/feature"
 	"github.com/lightningnetwork/lnd/lnwire"
 	"github.com/lightningnetwork/lnd/record"
@@ -49,7 +50,7 @@ const (
 type pathFinder = func(g *graphParams, r *RestrictParams,
 	cfg *PathFindingConfig, source, target route.Vertex,
 	amt lnwire.MilliSatoshi, timePref float64, finalHtlcExpiry int32) (
-	[]*channeldb.CachedEdgePolicy, float64, error)
+	[]*unifiedEdge, float64, error)
 
 var (
 	// DefaultEstimator is the default estimator used for computing
@@ -125,7 +126,7 @@ type finalHopParams struct {
 // NOTE: If a non-nil blinded path is provided it is assumed to have been
 // validated by the caller.
 func newRoute(sourceVertex route.Vertex,
-	pathEdges []*channeldb.CachedEdgePolicy, currentHeight uint32,
+	pathEdges []*unifiedEdge, currentHeight uint32,
 	finalHop finalHopParams, blindedPath *sphinx.BlindedPath) (
 	*route.Route, error) {
 
@@ -148,7 +149,7 @@ func newRoute(sourceVertex route.Vertex,
 	for i := pathLength - 1; i >= 0; i-- {
 		// Now we'll start to calculate the items within the per-hop
 		// payload for the hop this edge is leading to.
-		edge := pathEdges[i]
+		edge := pathEdges[i].policy
 
 		// We'll calculate the amounts, timelocks, and fees for each hop
 		// in the route. The base case is the final hop which includes
@@ -244,13 +245,30 @@ func newRoute(sourceVertex route.Vertex,
 			// and its policy for the outgoing channel. This policy
 			// is stored as part of the incoming channel of
 			// the next hop.
-			fee = pathEdges[i+1].ComputeFee(amtToForward)
+			outboundFee := pathEdges[i+1].policy.ComputeFee(
+				amtToForward,
+			)
+
+			// On top of that, the current hop charges an inbound
+			// fee for the channel the payment arrives through. It
+			// is based on the amount forwarded including the
+			// outbound fee, and may be negative. The total fee is
+			// never negative though.
+			inboundFee := calcCappedInboundFee(
+				pathEdges[i], amtToForward+outboundFee,
+				outboundFee,
+			)
+			fee = lnwire.MilliSatoshi(
+				int64(outboundFee) + inboundFee,
+			)
 
 			// We'll take the total timelock of the preceding hop as
 			// the outgoing timelock or this hop. Then we'll
 			// increment the total timelock incurred by this hop.
 			outgoingTimeLock = totalTimeLock
-			totalTimeLock += uint32(pathEdges[i+1].TimeLockDelta)
+			totalTimeLock += uint32(
+				pathEdges[i+1].policy.TimeLockDelta,
+			)
 		}
 
 		// Since we're traversing the path backwards atm, we prepend
@@ -493,7 +511,7 @@ func getOutgoingBalance(node route.Vertex, outgoingChans map[uint64]struct{},
 // available bandwidth.
 func findPath(g *graphParams, r *RestrictParams, cfg *PathFindingConfig,
 	source, target route.Vertex, amt lnwire.MilliSatoshi, timePref float64,
-	finalHtlcExpiry int32) ([]*channeldb.CachedEdgePolicy, float64, error) {
+	finalHtlcExpiry int32) ([]*unifiedEdge, float64, error) {
 
 	// Pathfinding can be a significant portion of the total payment
 	// latency, especially on low-powered devices. Log several metrics to
@@ -507,58 +525,10 @@ func findPath(g *graphParams, r *RestrictParams, cfg *PathFindingConfig,
 			"time=%v", nodesVisited, edgesExpanded, timeElapsed)
 	}()
 
-	// If no destination features are provided, we will load what features
-	// we have for the target node from our graph.
-	features := r.DestFeatures
-	if features == nil {
-		var err error
-		features, err = g.graph.fetchNodeFeatures(target)
-		if err != nil {
-			return nil, 0, err
-		}
-	}
-
-	// Ensure that the destination's features don't include unknown
-	// required features.
-	err := feature.ValidateRequired(features)
-	if err != nil {
-		log.Warnf("Pathfinding destination node features: %v", err)
-		return nil, 0, errUnknownRequiredFeature
-	}
-
-	// Ensure that all transitive dependencies are set.
-	err = feature.ValidateDeps(features)
+	// Load and validate the features of the destination.
+	features, err := validateDestination(g.graph, r, target)
 	if err != nil {
-		log.Warnf("Pathfinding destination node features: %v", err)
-		return nil, 0, errMissingDependentFeature
-	}
-
-	// Now that we know the feature vector is well formed, we'll proceed in
-	// checking that it supports the features we need, given our
-	// restrictions on the final hop.
-
-	// If the caller needs to send custom records, check that our
-	// destination feature vector supports TLV.
-	if len(r.DestCustomRecords) > 0 &&
-		!features.HasFeature(lnwire.TLVOnionPayloadOptional) {
-
-		return nil, 0, errNoTlvPayload
-	}
-
-	// If the caller has a payment address to attach, check that our
-	// destination feature vector supports them.
-	if r.PaymentAddr != nil &&
-		!features.HasFeature(lnwire.PaymentAddrOptional) {
-
-		return nil, 0, errNoPaymentAddr
-	}
-
-	// If the caller needs to send custom records, check that our
-	// destination feature vector supports TLV.
-	if r.Metadata != nil &&
-		!features.HasFeature(lnwire.TLVOnionPayloadOptional) {
-
-		return nil, 0, errNoTlvPayload
+		return nil, 0, err
 	}
 
 	// Set up outgoing channel map for quicker access.
@@ -603,30 +573,9 @@ func findPath(g *graphParams, r *RestrictParams, cfg *PathFindingConfig,
 	// Holds the current best distance for a given node.
 	distance := make(map[route.Vertex]*nodeWithDist, estimatedNodeCount)
 
-	additionalEdgesWithSrc := make(map[route.Vertex][]*edgePolicyWithSource)
-	for vertex, outgoingEdgePolicies := range g.additionalEdges {
-		// Edges connected to self are always included in the graph,
-		// therefore can be skipped. This prevents us from trying
-		// routes to malformed hop hints.
-		if vertex == self {
-			continue
-		}
-
-		// Build reverse lookup to find incoming edges. Needed because
-		// search is taken place from target to source.
-		for _, outgoingEdgePolicy := range outgoingEdgePolicies {
-			toVertex := outgoingEdgePolicy.ToNodePubKey()
-
-			incomingEdgePolicy := &edgePolicyWithSource{
-				sourceNode: vertex,
-				edge:       outgoingEdgePolicy,
-			}
-
-			additionalEdgesWithSrc[toVertex] =
-				append(additionalEdgesWithSrc[toVertex],
-					incomingEdgePolicy)
-		}
-	}
+	additionalEdgesWithSrc := reverseAdditionalEdges(
+		g.additionalEdges, self,
+	)
 
 	// Build a preliminary destination hop structure to obtain the payload
 	// size.
@@ -669,25 +618,12 @@ func findPath(g *graphParams, r *RestrictParams, cfg *PathFindingConfig,
 	// if the cltv limit is MaxUint32.
 	absoluteCltvLimit := uint64(r.CltvLimit) + uint64(finalHtlcExpiry)
 
-	// Calculate the default attempt cost as configured globally.
-	defaultAttemptCost := float64(
-		cfg.AttemptCost +
-			amt*lnwire.MilliSatoshi(cfg.AttemptCostPPM)/1000000,
-	)
-
-	// Validate time preference value.
-	if math.Abs(timePref) > 1 {
-		return nil, 0, fmt.Errorf("time preference %v out of range "+
-			"[-1, 1]", timePref)
+	// Calculate the attempt cost, taking the time preference into account.
+	absoluteAttemptCost, err := attemptCost(cfg, amt, timePref)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	// Scale to avoid the extremes -1 and 1 which run into infinity issues.
-	timePref *= 0.9
-
-	// Apply time preference. At 0, the default attempt cost will
-	// be used.
-	absoluteAttemptCost := defaultAttemptCost * (1/(0.5-timePref/2) - 1)
-
 	log.Debugf("Pathfinding absolute attempt cost: %v sats",
 		absoluteAttemptCost/1000)
 
@@ -699,9 +635,20 @@ func findPath(g *graphParams, r *RestrictParams, cfg *PathFindingConfig,
 
 		edgesExpanded++
 
+		// Calculate the inbound fee charged by the "to" node. The exit
+		// hop doesn't charge inbound fees, which is already accounted
+		// for by the node edge unifier. A discount never exceeds the
+		// outbound fee of the "to" node, because routing nodes treat a
+		// negative total fee as zero.
+		inboundFee := calcCappedInboundFee(
+			edge, toNodeDist.amountToReceive,
+			toNodeDist.outboundFee,
+		)
+
 		// Calculate amount that the candidate node would have to send
 		// out.
-		amountToSend := toNodeDist.amountToReceive
+		amountToSend := toNodeDist.amountToReceive +
+			lnwire.MilliSatoshi(inboundFee)
 
 		// Request the success probability for this edge.
 		edgeProbability := r.ProbabilitySource(
@@ -738,6 +685,15 @@ func findPath(g *graphParams, r *RestrictParams, cfg *PathFindingConfig,
 			timeLockDelta = edge.policy.TimeLockDelta
 		}
 
+		// The fee that is added to the route by this edge consists of
+		// the outbound fee of the candidate node and the inbound fee of
+		// the "to" node. Negative weights aren't supported by the
+		// search, so a net discount is counted as a zero fee.
+		edgeFee := int64(fee) + inboundFee
+		if edgeFee < 0 {
+			edgeFee = 0
+		}
+
 		incomingCltv := toNodeDist.incomingCltv + int32(timeLockDelta)
 
 		// Check that we are within our CLTV limit.
@@ -775,7 +731,10 @@ func findPath(g *graphParams, r *RestrictParams, cfg *PathFindingConfig,
 		// weight composed of the fee that this node will charge and
 		// the amount that will be locked for timeLockDelta blocks in
 		// the HTLC that is handed out to fromVertex.
-		weight := edgeWeight(amountToReceive, fee, timeLockDelta)
+		weight := edgeWeight(
+			amountToReceive, lnwire.MilliSatoshi(edgeFee),
+			timeLockDelta,
+		)
 
 		// Compute the tentative weight to this new channel/edge
 		// which is the weight from our toNode to the target node
@@ -847,9 +806,10 @@ func findPath(g *graphParams, r *RestrictParams, cfg *PathFindingConfig,
 			weight:          tempWeight,
 			node:            fromVertex,
 			amountToReceive: amountToReceive,
+			outboundFee:     fee,
 			incomingCltv:    incomingCltv,
 			probability:     probability,
-			nextHop:         edge.policy,
+			nextHop:         edge,
 			routingInfoSize: routingInfoSize,
 		}
 		distance[fromVertex] = withDist
@@ -910,8 +870,11 @@ func findPath(g *graphParams, r *RestrictParams, cfg *PathFindingConfig,
 
 		pivot := partialPath.node
 
-		// Create unified edges for all incoming connections.
-		u := newNodeEdgeUnifier(self, pivot, outgoingChanMap)
+		// Create unified edges for all incoming connections. The
+		// target node doesn't charge inbound fees.
+		u := newNodeEdgeUnifier(
+			self, pivot, pivot != target, outgoingChanMap,
+		)
 
 		err := u.addGraphPolicies(g.graph)
 		if err != nil {
@@ -927,11 +890,11 @@ func findPath(g *graphParams, r *RestrictParams, cfg *PathFindingConfig,
 			// not have been added by a wallet.
 			u.addPolicy(
 				reverseEdge.sourceNode, reverseEdge.edge,
-				fakeHopHintCapacity,
+				models.InboundFee{}, fakeHopHintCapacity,
 			)
 		}
 
-		amtToSend := partialPath.amountToReceive
+		netAmountReceived := partialPath.amountToReceive
 
 		// Expand all connections using the optimal policy for each
 		// connection.
@@ -953,7 +916,8 @@ func findPath(g *graphParams, r *RestrictParams, cfg *PathFindingConfig,
 			}
 
 			edge := edgeUnifier.getEdge(
-				amtToSend, g.bandwidthHints,
+				netAmountReceived, g.bandwidthHints,
+				partialPath.outboundFee,
 			)
 
 			if edge == nil {
@@ -994,7 +958,7 @@ func findPath(g *graphParams, r *RestrictParams, cfg *PathFindingConfig,
 
 	// Use the distance map to unravel the forward path from source to
 	// target.
-	var pathEdges []*channeldb.CachedEdgePolicy
+	var pathEdges []*unifiedEdge
 	currentNode := source
 	for {
 		// Determine the next hop forward using the next map.
@@ -1009,7 +973,7 @@ func findPath(g *graphParams, r *RestrictParams, cfg *PathFindingConfig,
 		pathEdges = append(pathEdges, currentNodeWithDist.nextHop)
 
 		// Advance current node.
-		currentNode = currentNodeWithDist.nextHop.ToNodePubKey()
+		currentNode = currentNodeWithDist.nextHop.policy.ToNodePubKey()
 
 		// Check stop condition at the end of this loop. This prevents
 		// breaking out too soon for self-payments that have target set
@@ -1030,7 +994,7 @@ func findPath(g *graphParams, r *RestrictParams, cfg *PathFindingConfig,
 	// route construction does not care where the features are actually
 	// taken from. In the future we may wish to do route construction within
 	// findPath, and avoid using ChannelEdgePolicy altogether.
-	pathEdges[len(pathEdges)-1].ToNodeFeatures = features
+	pathEdges[len(pathEdges)-1].policy.ToNodeFeatures = features
 
 	log.Debugf("Found route: probability=%v, hops=%v, fee=%v",
 		distance[source].probability, len(pathEdges),
@@ -1039,6 +1003,129 @@ func findPath(g *graphParams, r *RestrictParams, cfg *PathFindingConfig,
 	return pathEdges, distance[source].probability, nil
 }
 
+// validateDestination returns the features of the target node, either from
+// the restrictions or, if none are provided, from the graph. It checks that
+// the features are well formed and support what the payment requires on the
+// final hop.
+func validateDestination(g routingGraph, r *RestrictParams,
+	target route.Vertex) (*lnwire.FeatureVector, error) {
+
+	// If no destination features are provided, we will load what features
+	// we have for the target node from our graph.
+	features := r.DestFeatures
+	if features == nil {
+		var err error
+		features, err = g.fetchNodeFeatures(target)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Ensure that the destination's features don't include unknown
+	// required features.
+	err := feature.ValidateRequired(features)
+	if err != nil {
+		log.Warnf("Pathfinding destination node features: %v", err)
+		return nil, errUnknownRequiredFeature
+	}
+
+	// Ensure that all transitive dependencies are set.
+	err = feature.ValidateDeps(features)
+	if err != nil {
+		log.Warnf("Pathfinding destination node features: %v", err)
+		return nil, errMissingDependentFeature
+	}
+
+	// Now that we know the feature vector is well formed, we'll proceed in
+	// checking that it supports the features we need, given our
+	// restrictions on the final hop.
+
+	// If the caller needs to send custom records, check that our
+	// destination feature vector supports TLV.
+	if len(r.DestCustomRecords) > 0 &&
+		!features.HasFeature(lnwire.TLVOnionPayloadOptional) {
+
+		return nil, errNoTlvPayload
+	}
+
+	// If the caller has a payment address to attach, check that our
+	// destination feature vector supports them.
+	if r.PaymentAddr != nil &&
+		!features.HasFeature(lnwire.PaymentAddrOptional) {
+
+		return nil, errNoPaymentAddr
+	}
+
+	// If the caller needs to send custom records, check that our
+	// destination feature vector supports TLV.
+	if r.Metadata != nil &&
+		!features.HasFeature(lnwire.TLVOnionPayloadOptional) {
+
+		return nil, errNoTlvPayload
+	}
+
+	return features, nil
+}
+
+// reverseAdditionalEdges builds a reverse lookup of the additional edges, which
+// maps a node to the edges that lead to it. It is needed because the search is
+// performed from the target to the source.
+func reverseAdditionalEdges(
+	additionalEdges map[route.Vertex][]*channeldb.CachedEdgePolicy,
+	self route.Vertex) map[route.Vertex][]*edgePolicyWithSource {
+
+	additionalEdgesWithSrc := make(map[route.Vertex][]*edgePolicyWithSource)
+	for vertex, outgoingEdgePolicies := range additionalEdges {
+		// Edges connected to self are always included in the graph,
+		// therefore can be skipped. This prevents us from trying
+		// routes to malformed hop hints.
+		if vertex == self {
+			continue
+		}
+
+		// Build reverse lookup to find incoming edges.
+		for _, outgoingEdgePolicy := range outgoingEdgePolicies {
+			toVertex := outgoingEdgePolicy.ToNodePubKey()
+
+			incomingEdgePolicy := &edgePolicyWithSource{
+				sourceNode: vertex,
+				edge:       outgoingEdgePolicy,
+			}
+
+			additionalEdgesWithSrc[toVertex] =
+				append(additionalEdgesWithSrc[toVertex],
+					incomingEdgePolicy)
+		}
+	}
+
+	return additionalEdgesWithSrc
+}
+
+// attemptCost returns the virtual cost of a failed attempt to send the given
+// amount, adjusted to the time preference of the payment.
+func attemptCost(cfg *PathFindingConfig, amt lnwire.MilliSatoshi,
+	timePref float64) (float64, error) {
+
+	// Calculate the default attempt cost as configured globally.
+	defaultAttemptCost := float64(
+		cfg.AttemptCost +
+			amt*lnwire.MilliSatoshi(cfg.AttemptCostPPM)/1000000,
+	)
+
+	// Validate time preference value.
+	if math.Abs(timePref) > 1 {
+		return 0, fmt.Errorf("time preference %v out of range "+
+			"[-1, 1]", timePref)
+	}
+
+	// Scale to avoid the extremes -1 and 1 which run into infinity issues.
+	timePref *= 0.9
+
+	// Apply time preference. At 0, the default attempt cost will
+	// be used.
+	return defaultAttemptCost * (1/(0.5-timePref/2) - 1), nil
+}
+
 // getProbabilityBasedDist converts a weight into a distance that takes into
 // account the success probability and the (virtual) cost of a failed payment
 // attempt.
@@ -437,6 +437,25 @@ type testChannelPolicy struct {
 	LastUpdate  time.Time
 	Disabled    bool
 	Features    *lnwire.FeatureVector
+
+	// InboundFee is the inbound fee that the node charges for payments
+	// arriving through the channel.
+	InboundFee *lnwire.Fee
+}
+
+// extraOpaqueData returns the extra opaque data of the channel update that
+// advertises the policy.
+func (p *testChannelPolicy) extraOpaqueData() (lnwire.ExtraOpaqueData,
+	error) {
+
+	var extraData lnwire.ExtraOpaqueData
+	if p.InboundFee == nil {
+		return extraData, nil
+	}
+
+	err := extraData.PackRecords(p.InboundFee)
+
+	return extraData, err
 }
 
 type testChannelEnd struct {
@@ -689,6 +708,11 @@ func createTestGraphFromChannels(t *testing.T, useCache bool,
 				node2Features = node2.Features
 			}
 
+			extraData, err := node1.extraOpaqueData()
+			if err != nil {
+				return nil, err
+			}
+
 			edgePolicy := &channeldb.ChannelEdgePolicy{
 				SigBytes:                  testSig.Serialize(),
 				MessageFlags:              msgFlags,
@@ -700,6 +724,7 @@ func createTestGraphFromChannels(t *testing.T, useCache bool,
 				MaxHTLC:                   node1.MaxHTLC,
 				FeeBaseMSat:               node1.FeeBaseMsat,
 				FeeProportionalMillionths: node1.FeeRate,
+				ExtraOpaqueData:           extraData,
 				Node: &channeldb.LightningNode{
 					Alias:       node2.Alias,
 					PubKeyBytes: node2Vertex,
@@ -727,6 +752,11 @@ func createTestGraphFromChannels(t *testing.T, useCache bool,
 				node1Features = node1.Features
 			}
 
+			extraData, err := node2.extraOpaqueData()
+			if err != nil {
+				return nil, err
+			}
+
 			edgePolicy := &channeldb.ChannelEdgePolicy{
 				SigBytes:                  testSig.Serialize(),
 				MessageFlags:              msgFlags,
@@ -738,6 +768,7 @@ func createTestGraphFromChannels(t *testing.T, useCache bool,
 				MaxHTLC:                   node2.MaxHTLC,
 				FeeBaseMSat:               node2.FeeBaseMsat,
 				FeeProportionalMillionths: node2.FeeRate,
+				ExtraOpaqueData:           extraData,
 				Node: &channeldb.LightningNode{
 					Alias:       node1.Alias,
 					PubKeyBytes: node1Vertex,
@@ -836,6 +867,9 @@ func TestPathFinding(t *testing.T) {
 	}, {
 		name: "with metadata",
 		fn:   runFindPathWithMetadata,
+	}, {
+		name: "inbound fees",
+		fn:   runInboundFees,
 	}}
 
 	// Run with graph cache enabled.
@@ -971,6 +1005,75 @@ func runFindLowestFeePath(t *testing.T, useCache bool) {
 	}
 }
 
+// runInboundFees tests that inbound fees are taken into account when
+// selecting a path and constructing a route, and that an inbound discount can
+// make an otherwise more expensive path the cheapest one.
+func runInboundFees(t *testing.T, useCache bool) {
+	// Set up a test graph with two paths from roasbeef to target. The
+	// outbound fees of the path through b are higher, but b grants an
+	// inbound discount for payments arriving from first.
+	testChannels := []*testChannel{
+		symmetricTestChannel("roasbeef", "first", 100000, &testChannelPolicy{
+			Expiry:  144,
+			FeeRate: 100,
+			MinHTLC: 1,
+			MaxHTLC: 100000000,
+		}),
+		symmetricTestChannel("first", "a", 100000, &testChannelPolicy{
+			Expiry:  144,
+			FeeRate: 400,
+			MinHTLC: 1,
+			MaxHTLC: 100000000,
+		}),
+		symmetricTestChannel("a", "target", 100000, &testChannelPolicy{
+			Expiry:  144,
+			FeeRate: 400,
+			MinHTLC: 1,
+			MaxHTLC: 100000000,
+		}),
+		symmetricTestChannel("first", "b", 100000, &testChannelPolicy{
+			Expiry:  144,
+			FeeRate: 400,
+			MinHTLC: 1,
+			MaxHTLC: 100000000,
+			InboundFee: &lnwire.Fee{
+				BaseFee: -30,
+			},
+		}),
+		symmetricTestChannel("b", "target", 100000, &testChannelPolicy{
+			Expiry:  144,
+			FeeRate: 600,
+			MinHTLC: 1,
+			MaxHTLC: 100000000,
+		}),
+	}
+
+	ctx := newPathFindingTestContext(t, useCache, testChannels, "roasbeef")
+
+	paymentAmt := lnwire.NewMSatFromSatoshis(100)
+	target := ctx.keyFromAlias("target")
+	path, err := ctx.findPath(target, paymentAmt)
+	require.NoError(t, err, "unable to find path")
+
+	route, err := newRoute(
+		ctx.source, path, 100,
+		finalHopParams{
+			amt:       paymentAmt,
+			cltvDelta: 1,
+		}, nil,
+	)
+	require.NoError(t, err, "unable to create route")
+
+	// The path through b is cheaper because of the inbound discount. b
+	// charges an outbound fee of 60 msat minus a discount of 30 msat, and
+	// first charges its outbound fee on the amount b receives.
+	require.Len(t, route.Hops, 3)
+	require.Equal(t, ctx.keyFromAlias("b"), route.Hops[1].PubKeyBytes)
+	require.Equal(t, paymentAmt+30, route.Hops[0].AmtToForward)
+	require.Equal(t, paymentAmt, route.Hops[1].AmtToForward)
+	require.Equal(t, paymentAmt+30+40, route.TotalAmount)
+}
+
 func getAliasFromPubKey(pubKey route.Vertex,
 	aliases map[string]route.Vertex) string {
 
@@ -1250,7 +1353,7 @@ func runPathFindingWithAdditionalEdges(t *testing.T, useCache bool) {
 	}
 
 	find := func(r *RestrictParams) (
-		[]*channeldb.CachedEdgePolicy, error) {
+		[]*unifiedEdge, error) {
 
 		return dbFindPath(
 			graph.graph, additionalEdges, &mockBandwidthHints{},
@@ -1344,7 +1447,7 @@ func runPathFindingWithRedundantAdditionalEdges(t *testing.T, useCache bool) {
 	require.NoError(t, err, "unable to find path to bob")
 	require.Len(t, path, 1)
 
-	require.Equal(t, realChannelID, path[0].ChannelID,
+	require.Equal(t, realChannelID, path[0].policy.ChannelID,
 		"additional edge for known edge wasn't ignored")
 }
 
@@ -1631,7 +1734,8 @@ func TestNewRoute(t *testing.T) {
 
 		t.Run(testCase.name, func(t *testing.T) {
 			route, err := newRoute(
-				sourceVertex, testCase.hops, startingHeight,
+				sourceVertex, toUnifiedEdges(testCase.hops),
+				startingHeight,
 				finalHopParams{
 					amt:         testCase.paymentAmount,
 					totalAmt:    testCase.paymentAmount,
@@ -1771,7 +1875,7 @@ func runDestTLVGraphFallback(t *testing.T, useCache bool) {
 	require.NoError(t, err, "unable to fetch source node")
 
 	find := func(r *RestrictParams,
-		target route.Vertex) ([]*channeldb.CachedEdgePolicy, error) {
+		target route.Vertex) ([]*unifiedEdge, error) {
 
 		return dbFindPath(
 			ctx.graph, nil, &mockBandwidthHints{},
@@ -2429,16 +2533,17 @@ func TestPathFindSpecExample(t *testing.T) {
 }
 
 func assertExpectedPath(t *testing.T, aliasMap map[string]route.Vertex,
-	path []*channeldb.CachedEdgePolicy, nodeAliases ...string) {
+	path []*unifiedEdge, nodeAliases ...string) {
 
 	if len(path) != len(nodeAliases) {
 		t.Fatal("number of hops and number of aliases do not match")
 	}
 
 	for i, hop := range path {
-		if hop.ToNodePubKey() != aliasMap[nodeAliases[i]] {
+		hopNode := hop.policy.ToNodePubKey()
+		if hopNode != aliasMap[nodeAliases[i]] {
 			t.Fatalf("expected %v to be pos #%v in hop, instead "+
-				"%v was", nodeAliases[i], i, hop.ToNodePubKey())
+				"%v was", nodeAliases[i], i, hopNode)
 		}
 	}
 }
@@ -2512,10 +2617,10 @@ func runRestrictOutgoingChannel(t *testing.T, useCache bool) {
 
 	// Assert that the route starts with channel chanSourceB1, in line with
 	// the specified restriction.
-	if path[0].ChannelID != chanSourceB1 {
+	if path[0].policy.ChannelID != chanSourceB1 {
 		t.Fatalf("expected route to pass through channel %v, "+
 			"but channel %v was selected instead", chanSourceB1,
-			path[0].ChannelID)
+			path[0].policy.ChannelID)
 	}
 
 	// If a direct channel to target is allowed as well, that channel is
@@ -2525,7 +2630,7 @@ func runRestrictOutgoingChannel(t *testing.T, useCache bool) {
 	}
 	path, err = ctx.findPath(target, paymentAmt)
 	require.NoError(t, err, "unable to find path")
-	if path[0].ChannelID != chanSourceTarget {
+	if path[0].policy.ChannelID != chanSourceTarget {
 		t.Fatalf("expected route to pass through channel %v",
 			chanSourceTarget)
 	}
@@ -2564,10 +2669,10 @@ func runRestrictLastHop(t *testing.T, useCache bool) {
 	ctx.restrictParams.LastHop = &lastHop
 	path, err := ctx.findPath(target, paymentAmt)
 	require.NoError(t, err, "unable to find path")
-	if path[0].ChannelID != 3 {
+	if path[0].policy.ChannelID != 3 {
 		t.Fatalf("expected route to pass through channel 3, "+
 			"but channel %v was selected instead",
-			path[0].ChannelID)
+			path[0].policy.ChannelID)
 	}
 }
 
@@ -2847,10 +2952,10 @@ func testProbabilityRouting(t *testing.T, useCache bool,
 	}
 
 	// Assert that the route passes through the expected channel.
-	if path[1].ChannelID != expectedChan {
+	if path[1].policy.ChannelID != expectedChan {
 		t.Fatalf("expected route to pass through channel %v, "+
 			"but channel %v was selected instead", expectedChan,
-			path[1].ChannelID)
+			path[1].policy.ChannelID)
 	}
 }
 
@@ -2911,10 +3016,10 @@ func runEqualCostRouteSelection(t *testing.T, useCache bool) {
 		t.Fatal(err)
 	}
 
-	if path[1].ChannelID != 2 {
+	if path[1].policy.ChannelID != 2 {
 		t.Fatalf("expected route to pass through channel %v, "+
 			"but channel %v was selected instead", 2,
-			path[1].ChannelID)
+			path[1].policy.ChannelID)
 	}
 }
 
@@ -3074,7 +3179,7 @@ func (c *pathFindingTestContext) aliasFromKey(pubKey route.Vertex) string {
 }
 
 func (c *pathFindingTestContext) findPath(target route.Vertex,
-	amt lnwire.MilliSatoshi) ([]*channeldb.CachedEdgePolicy,
+	amt lnwire.MilliSatoshi) ([]*unifiedEdge,
 	error) {
 
 	return dbFindPath(
@@ -3083,7 +3188,7 @@ func (c *pathFindingTestContext) findPath(target route.Vertex,
 	)
 }
 
-func (c *pathFindingTestContext) assertPath(path []*channeldb.CachedEdgePolicy,
+func (c *pathFindingTestContext) assertPath(path []*unifiedEdge,
 	expected []uint64) {
 
 	if len(path) != len(expected) {
@@ -3092,13 +3197,25 @@ func (c *pathFindingTestContext) assertPath(path []*channeldb.CachedEdgePolicy,
 	}
 
 	for i, edge := range path {
-		if edge.ChannelID != expected[i] {
+		if edge.policy.ChannelID != expected[i] {
 			c.t.Fatalf("expected hop %v to be channel %v, "+
-				"but got %v", i, expected[i], edge.ChannelID)
+				"but got %v", i, expected[i],
+				edge.policy.ChannelID)
 		}
 	}
 }
 
+// toUnifiedEdges wraps the given policies into unified edges without inbound
+// fees.
+func toUnifiedEdges(policies []*channeldb.CachedEdgePolicy) []*unifiedEdge {
+	edges := make([]*unifiedEdge, len(policies))
+	for i, policy := range policies {
+		edges[i] = &unifiedEdge{policy: policy}
+	}
+
+	return edges
+}
+
 // dbFindPath calls findPath after getting a db transaction from the database
 // graph.
 func dbFindPath(graph *channeldb.ChannelGraph,
@@ -3106,7 +3223,7 @@ func dbFindPath(graph *channeldb.ChannelGraph,
 	bandwidthHints bandwidthHints,
 	r *RestrictParams, cfg *PathFindingConfig,
 	source, target route.Vertex, amt lnwire.MilliSatoshi, timePref float64,
-	finalHtlcExpiry int32) ([]*channeldb.CachedEdgePolicy, error) {
+	finalHtlcExpiry int32) ([]*unifiedEdge, error) {
 
 	sourceNode, err := graph.SourceNode()
 	if err != nil {
@@ -3351,8 +3468,8 @@ func TestBlindedRouteConstruction(t *testing.T) {
 	}
 
 	route, err := newRoute(
-		sourceVertex, edges, currentHeight, finalHopParams,
-		blindedPath,
+		sourceVertex, toUnifiedEdges(edges), currentHeight,
+		finalHopParams, blindedPath,
 	)
 	require.NoError(t, err)
 	require.Equal(t, expectedRoute, route)
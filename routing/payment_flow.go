@@ -0,0 +1,768 @@
+package routing
+
+import (
+	"container/heap"
+	"math"
+
+	"github.com/btcsuite/btcd/btcutil"
+	sphinx "github.com/lightningnetwork/lightning-onion"
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/channeldb/models"
+	"github.com/lightningnetwork/lnd/feature"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/routing/route"
+)
+
+// SplitMode determines how a payment is split into multiple parts.
+type SplitMode uint8
+
+const (
+	// SplitModeHalving sends the full amount over the best route and
+	// halves the amount of a part whenever no route can be found for it.
+	SplitModeHalving SplitMode = iota
+
+	// SplitModeFlow computes a multi-part flow for the full amount up
+	// front, based on the liquidity estimates of mission control and the
+	// fees of the channels. All parts are sent out in parallel, and the
+	// residual amount is planned again after partial failures.
+	SplitModeFlow
+)
+
+// String returns a human-readable version of the split mode.
+func (s SplitMode) String() string {
+	switch s {
+	case SplitModeHalving:
+		return "halving"
+
+	case SplitModeFlow:
+		return "min_cost_flow"
+
+	default:
+		return "unknown"
+	}
+}
+
+// flowMaxUnits is the maximum number of units a flow is divided into. Every
+// unit requires a search for a shortest path through the network, so this
+// bounds the time it takes to compute a flow.
+const flowMaxUnits = 20
+
+// flowMaxPlans is the maximum number of times a flow is planned for a
+// payment. Mission control may not learn from repeated failures of the same
+// parts, so the payment falls back to halving the amount after that, which
+// is bounded by the minimum shard size.
+const flowMaxPlans = 10
+
+// flowPath is a path of a flow together with the amount that it delivers to
+// the target.
+type flowPath struct {
+	edges []*unifiedEdge
+	amt   lnwire.MilliSatoshi
+}
+
+// flowArc is a channel in the flow network, directed from the node that
+// forwards the payment to the node that receives it.
+type flowArc struct {
+	from, to int
+	edge     *unifiedEdge
+
+	// costs are the marginal costs of the units of flow that the arc can
+	// carry, so their number is the capacity of the arc in units. They
+	// never decrease, which makes the cost of the arc a convex function
+	// of its flow.
+	costs []int64
+
+	// flow is the number of units that currently pass the arc.
+	flow int
+}
+
+// flowNode is a node in the flow network.
+type flowNode struct {
+	vertex route.Vertex
+
+	// expanded indicates whether the arcs leading to the node were loaded
+	// from the graph.
+	expanded bool
+
+	// inArcs and outArcs are the indices of the arcs that lead to and
+	// from the node. The outgoing arcs are only complete for the nodes
+	// the arcs lead to that are expanded.
+	inArcs  []int
+	outArcs []int
+
+	// potential is the offset of the potential of the node from the base
+	// potential of the network.
+	potential int64
+}
+
+// flowNetwork is the residual network used to compute a min-cost flow. It is
+// loaded lazily from the graph while searching backwards from the target, so
+// that only the part of the graph that is relevant for the payment is
+// explored.
+type flowNetwork struct {
+	g   *graphParams
+	r   *RestrictParams
+	cfg *PathFindingConfig
+
+	source, target route.Vertex
+
+	// amt is the amount of the payment, and unit the amount of a single
+	// unit of flow.
+	amt, unit lnwire.MilliSatoshi
+
+	// numUnits is the number of units that the payment is divided into.
+	numUnits int
+
+	// attemptCost is the virtual cost of a failed attempt, which is used
+	// to weigh the success probability of the flow against its fees.
+	attemptCost float64
+
+	outgoingChans   map[uint64]struct{}
+	additionalEdges map[route.Vertex][]*edgePolicyWithSource
+	featureCache    map[route.Vertex]*lnwire.FeatureVector
+
+	nodes     []*flowNode
+	nodeIndex map[route.Vertex]int
+	arcs      []*flowArc
+
+	// basePotential is the potential of all nodes that weren't reached by
+	// a search yet. Potentials keep the reduced costs of the residual
+	// network non-negative, so that Dijkstra's algorithm can be used to
+	// find the shortest paths.
+	basePotential int64
+
+	// lowered holds the nodes whose potential was lowered while loading
+	// arcs during a search, together with the amount it was lowered by.
+	lowered map[int]int64
+}
+
+// node returns the index of the given node, adding it to the network if it is
+// unknown.
+func (n *flowNetwork) node(vertex route.Vertex) int {
+	idx, ok := n.nodeIndex[vertex]
+	if ok {
+		return idx
+	}
+
+	idx = len(n.nodes)
+	n.nodes = append(n.nodes, &flowNode{
+		vertex: vertex,
+	})
+	n.nodeIndex[vertex] = idx
+
+	return idx
+}
+
+// potential returns the potential of the node at the given index.
+func (n *flowNetwork) potential(idx int) int64 {
+	return n.basePotential + n.nodes[idx].potential
+}
+
+// nodeFeatures returns the (cached) features of the given node. Nil is
+// returned for nodes that we can't route through.
+func (n *flowNetwork) nodeFeatures(
+	vertex route.Vertex) (*lnwire.FeatureVector, error) {
+
+	features, ok := n.featureCache[vertex]
+	if ok {
+		return features, nil
+	}
+
+	features, err := n.g.graph.fetchNodeFeatures(vertex)
+	if err != nil {
+		return nil, err
+	}
+
+	// Don't route through nodes with unknown required features or
+	// missing transitive dependencies.
+	if feature.ValidateRequired(features) != nil ||
+		feature.ValidateDeps(features) != nil {
+
+		features = nil
+	}
+	n.featureCache[vertex] = features
+
+	return features, nil
+}
+
+// arcCosts returns the marginal costs of the units of flow that can be sent
+// over the given edge. They consist of the fees and the time lock penalty of
+// the edge, plus the attempt cost weighted by the drop of the logarithm of the
+// success probability that the unit causes.
+func (n *flowNetwork) arcCosts(fromNode, toNode route.Vertex,
+	edge *unifiedEdge) []int64 {
+
+	local := fromNode == n.source
+
+	// The capacity of the arc is limited by the liquidity we know of for
+	// local channels, and by the maximum htlc size otherwise.
+	capMsat := lnwire.NewMSatFromSatoshis(edge.capacity)
+	if local {
+		bandwidth, ok := n.g.bandwidthHints.availableChanBandwidth(
+			edge.policy.ChannelID, 0,
+		)
+		if ok {
+			capMsat = bandwidth
+		}
+	}
+	if edge.policy.MessageFlags.HasMaxHtlc() &&
+		(capMsat == 0 || edge.policy.MaxHTLC < capMsat) {
+
+		capMsat = edge.policy.MaxHTLC
+	}
+
+	units := int(capMsat / n.unit)
+	if units > n.numUnits {
+		units = n.numUnits
+	}
+
+	// Units smaller than the minimum htlc size can't be sent.
+	if edge.policy.MinHTLC > n.unit {
+		return nil
+	}
+
+	// The fee of a unit consists of the proportional fees of the edge
+	// and a share of its base fees. A base fee can't be expressed as a
+	// marginal cost, so it is spread over the units as if the full
+	// amount were sent over the edge. The source doesn't pay itself any
+	// fees, and a net discount is counted as a zero fee.
+	var unitFee float64
+	if !local {
+		feeRate := int64(edge.policy.FeeProportionalMillionths) +
+			int64(edge.inboundFees.Rate)
+		baseFee := int64(edge.policy.FeeBaseMSat) +
+			int64(edge.inboundFees.Base)
+
+		unitFee = float64(n.unit) * float64(feeRate) / feeRateParts
+		unitFee += float64(baseFee) * float64(n.unit) / float64(n.amt)
+		if unitFee < 0 {
+			unitFee = 0
+		}
+
+		unitFee += float64(n.unit) *
+			float64(edge.policy.TimeLockDelta) *
+			RiskFactorBillionths / 1_000_000_000
+	}
+
+	costs := make([]int64, 0, units)
+	prevProbability := 1.0
+	for i := 1; i <= units; i++ {
+		probability := n.r.ProbabilitySource(
+			fromNode, toNode, n.unit*lnwire.MilliSatoshi(i),
+			edge.capacity,
+		)
+		// Units that would push the probability of the edge below
+		// the minimum are considered to exceed its liquidity.
+		if probability <= 0 ||
+			probability < n.cfg.MinProbability {
+
+			break
+		}
+
+		// The probability of an estimator may increase with the
+		// amount, which is treated as no change.
+		cost := unitFee
+		if probability < prevProbability {
+			cost += n.attemptCost * math.Log(
+				prevProbability/probability,
+			)
+		}
+		if math.IsInf(cost, 0) || math.IsNaN(cost) {
+			break
+		}
+
+		// Keep the costs non-decreasing, so that the arc is convex.
+		unitCost := int64(math.Ceil(cost))
+		if len(costs) > 0 && unitCost < costs[len(costs)-1] {
+			unitCost = costs[len(costs)-1]
+		}
+
+		costs = append(costs, unitCost)
+		prevProbability = math.Min(prevProbability, probability)
+	}
+
+	return costs
+}
+
+// expand loads the arcs that lead to the node at the given index.
+func (n *flowNetwork) expand(idx int) error {
+	node := n.nodes[idx]
+	node.expanded = true
+
+	toNode := node.vertex
+
+	addArc := func(fromNode route.Vertex,
+		policy *channeldb.CachedEdgePolicy, inboundFee models.InboundFee,
+		capacity btcutil.Amount) error {
+
+		// Paths don't pass the target, and don't leave the source
+		// through a channel it isn't allowed to use.
+		if fromNode == n.target {
+			return nil
+		}
+
+		if n.r.LastHop != nil && toNode == n.target &&
+			fromNode != *n.r.LastHop {
+
+			return nil
+		}
+
+		local := fromNode == n.source
+		if local && n.outgoingChans != nil {
+			_, ok := n.outgoingChans[policy.ChannelID]
+			if !ok {
+				return nil
+			}
+		}
+
+		// The disabled flag is ignored for local channels, as their
+		// bandwidth already tells whether they can be used.
+		disabled := policy.ChannelFlags&lnwire.ChanUpdateDisabled != 0
+		if !local && disabled {
+			return nil
+		}
+
+		if !local {
+			features, err := n.nodeFeatures(fromNode)
+			if err != nil {
+				return err
+			}
+
+			if features == nil {
+				return nil
+			}
+		}
+
+		// The target doesn't charge inbound fees.
+		if toNode == n.target {
+			inboundFee = models.InboundFee{}
+		}
+
+		edge := &unifiedEdge{
+			policy:      policy,
+			capacity:    capacity,
+			inboundFees: inboundFee,
+		}
+
+		costs := n.arcCosts(fromNode, toNode, edge)
+		if len(costs) == 0 {
+			return nil
+		}
+
+		// A node that wasn't expanded yet has no arcs leading to it,
+		// so its potential can be lowered to keep the reduced cost of
+		// the new arc non-negative.
+		fromIdx := n.node(fromNode)
+		bound := costs[0] + n.potential(idx)
+		if !n.nodes[fromIdx].expanded && n.potential(fromIdx) > bound {
+			delta := n.potential(fromIdx) - bound
+			n.nodes[fromIdx].potential -= delta
+			n.lowered[fromIdx] += delta
+		}
+
+		n.arcs = append(n.arcs, &flowArc{
+			from:  fromIdx,
+			to:    idx,
+			edge:  edge,
+			costs: costs,
+		})
+
+		arcIdx := len(n.arcs) - 1
+		n.nodes[fromIdx].outArcs = append(
+			n.nodes[fromIdx].outArcs, arcIdx,
+		)
+		node.inArcs = append(node.inArcs, arcIdx)
+
+		return nil
+	}
+
+	err := n.g.graph.forEachNodeChannel(toNode,
+		func(channel *channeldb.DirectedChannel) error {
+			if channel.InPolicy == nil {
+				return nil
+			}
+
+			return addArc(
+				channel.OtherNode, channel.InPolicy,
+				models.NewInboundFeeFromWire(
+					channel.InboundFee,
+				),
+				channel.Capacity,
+			)
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	// Hop hints don't contain a capacity, so we assume a high one as in
+	// path finding.
+	for _, reverseEdge := range n.additionalEdges[toNode] {
+		err := addArc(
+			reverseEdge.sourceNode, reverseEdge.edge,
+			models.InboundFee{}, fakeHopHintCapacity,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// flowHeapItem is an entry of the heap used by the shortest path search.
+type flowHeapItem struct {
+	node int
+	dist int64
+}
+
+// flowHeap is a min-heap of nodes ordered by their distance.
+type flowHeap []flowHeapItem
+
+func (h flowHeap) Len() int           { return len(h) }
+func (h flowHeap) Less(i, j int) bool { return h[i].dist < h[j].dist }
+func (h flowHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *flowHeap) Push(x interface{}) {
+	*h = append(*h, x.(flowHeapItem))
+}
+
+func (h *flowHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+
+	return item
+}
+
+// augment searches the path of the lowest cost from the source to the target
+// in the residual network and sends a unit of flow along it. It returns false
+// if the target can't be reached anymore.
+func (n *flowNetwork) augment() (bool, error) {
+	sourceIdx := n.node(n.source)
+	targetIdx := n.node(n.target)
+
+	// The search is performed backwards from the target. For every node,
+	// the arc towards the target and whether it is used forwards or
+	// backwards is recorded.
+	dist := map[int]int64{targetIdx: 0}
+	nextArc := make(map[int]int)
+	forward := make(map[int]bool)
+	settled := make(map[int]struct{})
+
+	h := &flowHeap{{node: targetIdx}}
+	relax := func(idx int, d int64, arcIdx int, fwd bool) {
+		if _, ok := settled[idx]; ok {
+			return
+		}
+
+		current, ok := dist[idx]
+		if ok && current <= d {
+			return
+		}
+
+		dist[idx] = d
+		nextArc[idx] = arcIdx
+		forward[idx] = fwd
+		heap.Push(h, flowHeapItem{node: idx, dist: d})
+	}
+
+	for h.Len() > 0 {
+		item := heap.Pop(h).(flowHeapItem)
+		if _, ok := settled[item.node]; ok {
+			continue
+		}
+
+		// Skip entries that are outdated because the potential of
+		// their node was lowered.
+		if item.dist != dist[item.node] {
+			continue
+		}
+		settled[item.node] = struct{}{}
+
+		if item.node == sourceIdx {
+			break
+		}
+
+		node := n.nodes[item.node]
+		if !node.expanded {
+			if err := n.expand(item.node); err != nil {
+				return false, err
+			}
+
+			// Lowering the potential of a node raises the
+			// reduced costs of all arcs leading to it by the
+			// same amount, so its distance is raised as well.
+			for idx, delta := range n.lowered {
+				d, ok := dist[idx]
+				if !ok {
+					continue
+				}
+
+				dist[idx] = d + delta
+				heap.Push(h, flowHeapItem{
+					node: idx,
+					dist: d + delta,
+				})
+			}
+			n.lowered = make(map[int]int64)
+		}
+
+		// Arcs into the node can carry more flow up to their
+		// capacity.
+		for _, arcIdx := range node.inArcs {
+			arc := n.arcs[arcIdx]
+			if arc.flow >= len(arc.costs) {
+				continue
+			}
+
+			cost := arc.costs[arc.flow] + n.potential(item.node) -
+				n.potential(arc.from)
+			relax(arc.from, item.dist+cost, arcIdx, true)
+		}
+
+		// Flow on arcs out of the node can be sent back.
+		for _, arcIdx := range node.outArcs {
+			arc := n.arcs[arcIdx]
+			if arc.flow == 0 {
+				continue
+			}
+
+			cost := -arc.costs[arc.flow-1] +
+				n.potential(item.node) - n.potential(arc.to)
+			relax(arc.to, item.dist+cost, arcIdx, false)
+		}
+	}
+
+	if _, ok := settled[sourceIdx]; !ok {
+		return false, nil
+	}
+
+	// Update the potentials, so that the reduced costs stay
+	// non-negative. Nodes that weren't settled keep the distance of the
+	// source as their potential, which is tracked in the base potential.
+	sourceDist := dist[sourceIdx]
+	for idx := range settled {
+		n.nodes[idx].potential += dist[idx] - sourceDist
+	}
+	n.basePotential += sourceDist
+
+	// Send a unit of flow along the path.
+	for idx := sourceIdx; idx != targetIdx; {
+		arc := n.arcs[nextArc[idx]]
+		if forward[idx] {
+			arc.flow++
+			idx = arc.to
+		} else {
+			arc.flow--
+			idx = arc.from
+		}
+	}
+
+	return true, nil
+}
+
+// paths decomposes the flow into paths from the source to the target. Each
+// path carries a number of units of flow.
+func (n *flowNetwork) paths() []*flowPath {
+	sourceIdx := n.node(n.source)
+	targetIdx := n.node(n.target)
+
+	var paths []*flowPath
+	for {
+		// Follow the flow from the source until the target is
+		// reached.
+		var (
+			arcs    []int
+			visited = map[int]int{sourceIdx: 0}
+			idx     = sourceIdx
+		)
+		for idx != targetIdx {
+			next := -1
+			for _, arcIdx := range n.nodes[idx].outArcs {
+				if n.arcs[arcIdx].flow > 0 {
+					next = arcIdx
+					break
+				}
+			}
+
+			// All flow was decomposed.
+			if next < 0 {
+				return paths
+			}
+
+			arcs = append(arcs, next)
+			idx = n.arcs[next].to
+
+			// The flow may contain cycles of zero cost, which are
+			// removed as they don't carry any flow to the target.
+			pos, ok := visited[idx]
+			if !ok {
+				visited[idx] = len(arcs)
+				continue
+			}
+
+			n.subtractFlow(arcs[pos:])
+			for _, arcIdx := range arcs[pos:] {
+				delete(visited, n.arcs[arcIdx].to)
+			}
+			visited[idx] = pos
+			arcs = arcs[:pos]
+		}
+
+		units := n.subtractFlow(arcs)
+
+		edges := make([]*unifiedEdge, len(arcs))
+		for i, arcIdx := range arcs {
+			edges[i] = n.arcs[arcIdx].edge
+		}
+
+		paths = append(paths, &flowPath{
+			edges: edges,
+			amt:   n.unit * lnwire.MilliSatoshi(units),
+		})
+	}
+}
+
+// subtractFlow subtracts the minimum flow of the given arcs from all of them
+// and returns it.
+func (n *flowNetwork) subtractFlow(arcs []int) int {
+	units := math.MaxInt32
+	for _, arcIdx := range arcs {
+		if n.arcs[arcIdx].flow < units {
+			units = n.arcs[arcIdx].flow
+		}
+	}
+
+	for _, arcIdx := range arcs {
+		n.arcs[arcIdx].flow -= units
+	}
+
+	return units
+}
+
+// findFlow computes a flow of the given amount from the source to the target
+// that minimizes the fees and maximizes the success probability of the
+// payment. The amount is divided into units of at least the given minimum
+// size, which are sent through the network with a min-cost flow algorithm,
+// using the success probabilities of mission control to estimate the
+// liquidity of the channels. The flow is returned as a set of paths, each
+// with the amount it delivers to the target.
+func findFlow(g *graphParams, r *RestrictParams, cfg *PathFindingConfig,
+	source, target route.Vertex, amt, minUnit lnwire.MilliSatoshi,
+	timePref float64) ([]*flowPath, error) {
+
+	// Payments to self are circular and are sent over a single path.
+	if source == target || amt == 0 {
+		return nil, errNoPathFound
+	}
+
+	// Load and validate the features of the destination.
+	features, err := validateDestination(g.graph, r, target)
+	if err != nil {
+		return nil, err
+	}
+
+	var outgoingChans map[uint64]struct{}
+	if len(r.OutgoingChannelIDs) > 0 {
+		outgoingChans = make(map[uint64]struct{})
+		for _, outChan := range r.OutgoingChannelIDs {
+			outgoingChans[outChan] = struct{}{}
+		}
+	}
+
+	// Check that we have enough local balance to send the payment at all.
+	self := g.graph.sourceNode()
+	if source == self {
+		_, total, err := getOutgoingBalance(
+			self, outgoingChans, g.bandwidthHints, g.graph,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if total < amt {
+			return nil, errInsufficientBalance
+		}
+	}
+
+	absoluteAttemptCost, err := attemptCost(cfg, amt, timePref)
+	if err != nil {
+		return nil, err
+	}
+
+	// Divide the amount into units of equal size.
+	unit := (amt + flowMaxUnits - 1) / flowMaxUnits
+	if unit < minUnit {
+		unit = minUnit
+	}
+	if unit > amt {
+		unit = amt
+	}
+	numUnits := int((amt + unit - 1) / unit)
+
+	n := &flowNetwork{
+		g:               g,
+		r:               r,
+		cfg:             cfg,
+		source:          source,
+		target:          target,
+		amt:             amt,
+		unit:            unit,
+		numUnits:        numUnits,
+		attemptCost:     absoluteAttemptCost,
+		outgoingChans:   outgoingChans,
+		additionalEdges: reverseAdditionalEdges(g.additionalEdges, self),
+		featureCache:    make(map[route.Vertex]*lnwire.FeatureVector),
+		nodeIndex:       make(map[route.Vertex]int),
+		lowered:         make(map[int]int64),
+	}
+
+	// Send the units one by one along the currently cheapest path. As the
+	// costs of the arcs are convex, this results in a flow of minimal
+	// cost.
+	for i := 0; i < numUnits; i++ {
+		ok, err := n.augment()
+		if err != nil {
+			return nil, err
+		}
+
+		if !ok {
+			log.Debugf("Unable to find flow for amt=%v, only %v of "+
+				"%v units could be sent", amt, i, numUnits)
+
+			return nil, errNoPathFound
+		}
+	}
+
+	paths := n.paths()
+
+	// The units may add up to more than the amount, in which case the
+	// excess is removed from the largest path. It is smaller than a
+	// unit, so the path keeps a positive amount.
+	excess := unit*lnwire.MilliSatoshi(numUnits) - amt
+	if excess > 0 {
+		largest := paths[0]
+		for _, path := range paths {
+			if path.amt > largest.amt {
+				largest = path
+			}
+		}
+		largest.amt -= excess
+	}
+
+	for _, path := range paths {
+		if len(path.edges) > sphinx.NumMaxHops {
+			return nil, errNoPathFound
+		}
+
+		// For the final hop, we'll set the node features to those
+		// determined above, as is done in path finding.
+		path.edges[len(path.edges)-1].policy.ToNodeFeatures = features
+	}
+
+	log.Debugf("Found flow: amt=%v, units=%v, paths=%v", amt, numUnits,
+		len(paths))
+
+	return paths, nil
+}
@@ -0,0 +1,165 @@
+package routing
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/routing/route"
+	"github.com/stretchr/testify/require"
+)
+
+// uniformProbabilitySource returns probabilities that assume a uniform
+// distribution of the liquidity of a channel.
+func uniformProbabilitySource(_, _ route.Vertex, amt lnwire.MilliSatoshi,
+	capacity btcutil.Amount) float64 {
+
+	capMsat := lnwire.NewMSatFromSatoshis(capacity)
+	if amt >= capMsat {
+		return 0
+	}
+
+	return 1 - float64(amt)/float64(capMsat)
+}
+
+// twoPathFlowChannels returns a graph with two paths from roasbeef to target,
+// using the given capacity for the channels to the target and the given fee
+// rate on the path through b.
+func twoPathFlowChannels(capacityIn btcutil.Amount,
+	feeRateB lnwire.MilliSatoshi) []*testChannel {
+
+	policy := func(feeRate lnwire.MilliSatoshi) *testChannelPolicy {
+		return &testChannelPolicy{
+			Expiry:  144,
+			FeeRate: feeRate,
+			MinHTLC: 1,
+			MaxHTLC: 100000000,
+		}
+	}
+
+	return []*testChannel{
+		symmetricTestChannel("roasbeef", "a", 100000, policy(0), 1),
+		symmetricTestChannel("a", "target", capacityIn, policy(0), 2),
+		symmetricTestChannel("roasbeef", "b", 100000, policy(0), 3),
+		symmetricTestChannel(
+			"b", "target", capacityIn, policy(feeRateB), 4,
+		),
+	}
+}
+
+// findTestFlow computes a flow in the graph of the given test context.
+func findTestFlow(t *testing.T, ctx *pathFindingTestContext,
+	amt lnwire.MilliSatoshi) ([]*flowPath, error) {
+
+	sourceNode, err := ctx.graph.SourceNode()
+	require.NoError(t, err)
+
+	routingGraph, err := NewCachedGraph(sourceNode, ctx.graph)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, routingGraph.Close())
+	}()
+
+	return findFlow(
+		&graphParams{
+			bandwidthHints: ctx.bandwidthHints,
+			graph:          routingGraph,
+		},
+		&ctx.restrictParams, &ctx.pathFindingConfig, ctx.source,
+		ctx.keyFromAlias("target"), amt, 1, ctx.timePref,
+	)
+}
+
+// TestFindFlow tests that a flow is split over multiple paths according to
+// their liquidity and fees.
+func TestFindFlow(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		capacityIn btcutil.Amount
+		feeRateB   lnwire.MilliSatoshi
+		amt        btcutil.Amount
+
+		// expectedAmts maps the first channel of each expected path
+		// to the amount it carries.
+		expectedAmts map[uint64]btcutil.Amount
+		expectedErr  error
+	}{
+		{
+			// The liquidity of both paths is estimated equally,
+			// so the amount is split evenly.
+			name:       "split evenly",
+			capacityIn: 100000,
+			amt:        100000,
+			expectedAmts: map[uint64]btcutil.Amount{
+				1: 50000,
+				3: 50000,
+			},
+		},
+		{
+			// A small amount barely affects the probability, so
+			// the fees of the path through b make it go through a
+			// only.
+			name:       "avoid fees",
+			capacityIn: 100000,
+			feeRateB:   10000,
+			amt:        1000,
+			expectedAmts: map[uint64]btcutil.Amount{
+				1: 1000,
+			},
+		},
+		{
+			// The channels to the target can't carry the amount,
+			// although the local balance is sufficient.
+			name:        "no flow",
+			capacityIn:  50000,
+			amt:         120000,
+			expectedErr: errNoPathFound,
+		},
+		{
+			// The local balance doesn't cover the amount.
+			name:        "insufficient balance",
+			capacityIn:  200000,
+			amt:         250000,
+			expectedErr: errInsufficientBalance,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := newPathFindingTestContext(
+				t, true, twoPathFlowChannels(
+					testCase.capacityIn, testCase.feeRateB,
+				), "roasbeef",
+			)
+			ctx.restrictParams.ProbabilitySource =
+				uniformProbabilitySource
+			ctx.pathFindingConfig = PathFindingConfig{
+				AttemptCost: lnwire.NewMSatFromSatoshis(100),
+			}
+
+			paths, err := findTestFlow(
+				t, ctx,
+				lnwire.NewMSatFromSatoshis(testCase.amt),
+			)
+			if testCase.expectedErr != nil {
+				require.ErrorIs(t, err, testCase.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+
+			amts := make(map[uint64]btcutil.Amount)
+			for _, path := range paths {
+				require.Len(t, path.edges, 2)
+				amts[path.edges[0].policy.ChannelID] +=
+					path.amt.ToSatoshis()
+			}
+			require.Equal(t, testCase.expectedAmts, amts)
+		})
+	}
+}
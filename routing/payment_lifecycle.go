@@ -283,6 +283,18 @@ lifecycle:
 
 			continue lifecycle
 
+		// The payment may have been canceled while we were
+		// pathfinding, in which case no more attempts can be
+		// registered. The next iteration will wait for the attempts in
+		// flight or report the cancellation.
+		case err == channeldb.ErrPaymentPendingFailed ||
+			err == channeldb.ErrPaymentAlreadyFailed:
+
+			log.Infof("Payment %v is failing, abandoning shard",
+				p.identifier)
+
+			continue lifecycle
+
 		case err != nil:
 			return exitWithErr(err)
 		}
@@ -533,6 +545,7 @@ func (p *shardHandler) collectResult(attempt *channeldb.HTLCAttempt) (
 	// switch.
 	errorDecryptor := &htlcswitch.SphinxErrorDecrypter{
 		OnionErrorDecrypter: sphinx.NewOnionErrorDecrypter(circuit),
+		Circuit:             circuit,
 	}
 
 	// Now ask the switch to return the result of the payment when
@@ -773,10 +786,13 @@ func (p *shardHandler) handleSwitchErr(attempt *channeldb.HTLCAttempt,
 	// mission control, which helps us to decide whether we want to retry
 	// the payment or not. If a non nil reason is returned from mission
 	// control, it will further fail the payment via control tower.
-	reportFail := func(srcIdx *int, msg lnwire.FailureMessage) error {
+	reportFail := func(srcIdx *int, msg lnwire.FailureMessage,
+		holdTimes []time.Duration) error {
+
 		// Report outcome to mission control.
 		reason, err := p.router.cfg.MissionControl.ReportPaymentFail(
 			attempt.AttemptID, &attempt.Route, srcIdx, msg,
+			holdTimes,
 		)
 		if err != nil {
 			log.Errorf("Error reporting payment result to mc: %v",
@@ -793,10 +809,20 @@ func (p *shardHandler) handleSwitchErr(attempt *channeldb.HTLCAttempt,
 		return failPayment(reason, sendErr)
 	}
 
+	// If some hops attributed the unreadable failure, pass on the hold
+	// times they reported so that the failure can be pinned to a hop.
+	unreadableErr, ok := sendErr.(*htlcswitch.UnreadableFailureError)
+	if ok {
+		log.Tracef("Unreadable failure attributed by %v hops when "+
+			"sending htlc", len(unreadableErr.HoldTimes))
+
+		return reportFail(nil, nil, unreadableErr.HoldTimes)
+	}
+
 	if sendErr == htlcswitch.ErrUnreadableFailureMessage {
 		log.Tracef("Unreadable failure when sending htlc")
 
-		return reportFail(nil, nil)
+		return reportFail(nil, nil, nil)
 	}
 
 	// If the error is a ClearTextError, we have received a valid wire
@@ -816,9 +842,11 @@ func (p *shardHandler) handleSwitchErr(attempt *channeldb.HTLCAttempt,
 	// ForwardingError, it did not originate at our node, so we set
 	// failureSourceIdx to the index of the node where the failure occurred.
 	failureSourceIdx := 0
+	var holdTimes []time.Duration
 	source, ok := rtErr.(*htlcswitch.ForwardingError)
 	if ok {
 		failureSourceIdx = source.FailureSourceIdx
+		holdTimes = source.HoldTimes
 	}
 
 	// Extract the wire failure and apply channel update if it contains one.
@@ -835,7 +863,7 @@ func (p *shardHandler) handleSwitchErr(attempt *channeldb.HTLCAttempt,
 	log.Tracef("Node=%v reported failure when sending htlc",
 		failureSourceIdx)
 
-	return reportFail(&failureSourceIdx, failureMessage)
+	return reportFail(&failureSourceIdx, failureMessage, holdTimes)
 }
 
 // handleFailureMessage tries to apply a channel update present in the failure
@@ -942,6 +970,13 @@ func marshallError(sendError error, time time.Time) *channeldb.HTLCFailInfo {
 		FailTime: time,
 	}
 
+	// Unreadable failures that were attributed by some hops are still
+	// unreadable.
+	if _, ok := sendError.(*htlcswitch.UnreadableFailureError); ok {
+		response.Reason = channeldb.HTLCFailUnreadable
+		return response
+	}
+
 	switch sendError {
 	case htlcswitch.ErrPaymentIDNotFound:
 		response.Reason = channeldb.HTLCFailInternal
@@ -186,6 +186,17 @@ type paymentSession struct {
 	// will happen and this value remains unused.
 	minShardAmt lnwire.MilliSatoshi
 
+	// flowPlan holds the routes of the current flow that weren't handed
+	// out yet, if the payment is split with SplitModeFlow.
+	flowPlan []*route.Route
+
+	// flowPlanHeight is the block height at which the flow plan was
+	// computed.
+	flowPlanHeight uint32
+
+	// flowPlans is the number of flows that were planned for the payment.
+	flowPlans int
+
 	// log is a payment session-specific logger.
 	log btclog.Logger
 }
@@ -262,6 +273,22 @@ func (p *paymentSession) RequestRoute(maxAmt, feeLimit lnwire.MilliSatoshi,
 
 	finalHtlcExpiry := int32(height) + int32(finalCltvDelta)
 
+	// If the payment should be split along a min-cost flow, we'll hand
+	// out the parts of the flow. When no flow can be found, we fall back
+	// to halving the amount.
+	if p.payment.SplitMode == SplitModeFlow && p.canSplit(activeShards) {
+		route, err := p.requestFlowRoute(
+			maxAmt, feeLimit, activeShards, height, finalCltvDelta,
+			restrictions,
+		)
+		if err != errNoPathFound {
+			return route, err
+		}
+
+		p.log.Debugf("no flow found for amt=%v, falling back to "+
+			"halving", maxAmt)
+	}
+
 	// Before we enter the loop below, we'll make sure to respect the max
 	// payment shard size (if it's set), which is effectively our
 	// client-side MTU that we'll attempt to respect at all times.
@@ -313,38 +340,7 @@ func (p *paymentSession) RequestRoute(maxAmt, feeLimit lnwire.MilliSatoshi,
 
 		switch {
 		case err == errNoPathFound:
-			// Don't split if this is a legacy payment without mpp
-			// record.
-			if p.payment.PaymentAddr == nil {
-				p.log.Debugf("not splitting because payment " +
-					"address is unspecified")
-
-				return nil, errNoPathFound
-			}
-
-			if p.payment.DestFeatures == nil {
-				p.log.Debug("Not splitting because " +
-					"destination DestFeatures is nil")
-				return nil, errNoPathFound
-			}
-
-			destFeatures := p.payment.DestFeatures
-			if !destFeatures.HasFeature(lnwire.MPPOptional) &&
-				!destFeatures.HasFeature(lnwire.AMPOptional) {
-
-				p.log.Debug("not splitting because " +
-					"destination doesn't declare MPP or AMP")
-
-				return nil, errNoPathFound
-			}
-
-			// No splitting if this is the last shard.
-			isLastShard := activeShards+1 >= p.payment.MaxParts
-			if isLastShard {
-				p.log.Debugf("not splitting because shard "+
-					"limit %v has been reached",
-					p.payment.MaxParts)
-
+			if !p.canSplit(activeShards) {
 				return nil, errNoPathFound
 			}
 
@@ -400,6 +396,192 @@ func (p *paymentSession) RequestRoute(maxAmt, feeLimit lnwire.MilliSatoshi,
 	}
 }
 
+// canSplit returns whether the payment can be split into another part, given
+// the number of parts that are currently in flight.
+func (p *paymentSession) canSplit(activeShards uint32) bool {
+	// Don't split if this is a legacy payment without mpp record.
+	if p.payment.PaymentAddr == nil {
+		p.log.Debugf("not splitting because payment address is " +
+			"unspecified")
+
+		return false
+	}
+
+	if p.payment.DestFeatures == nil {
+		p.log.Debug("Not splitting because destination DestFeatures " +
+			"is nil")
+		return false
+	}
+
+	destFeatures := p.payment.DestFeatures
+	if !destFeatures.HasFeature(lnwire.MPPOptional) &&
+		!destFeatures.HasFeature(lnwire.AMPOptional) {
+
+		p.log.Debug("not splitting because destination doesn't " +
+			"declare MPP or AMP")
+
+		return false
+	}
+
+	// No splitting if this is the last shard.
+	isLastShard := activeShards+1 >= p.payment.MaxParts
+	if isLastShard {
+		p.log.Debugf("not splitting because shard limit %v has been "+
+			"reached", p.payment.MaxParts)
+
+		return false
+	}
+
+	return true
+}
+
+// requestFlowRoute returns the next route of the flow that the payment is
+// split along. The flow is planned again for the remaining amount if the
+// routes that are left don't cover it anymore, which happens when parts of
+// the payment failed.
+func (p *paymentSession) requestFlowRoute(maxAmt,
+	feeLimit lnwire.MilliSatoshi, activeShards, height uint32,
+	finalCltvDelta uint16, restrictions *RestrictParams) (*route.Route,
+	error) {
+
+	if len(p.flowPlan) > 0 && p.flowPlanHeight == height {
+		var amt, fees lnwire.MilliSatoshi
+		for _, rt := range p.flowPlan {
+			amt += rt.ReceiverAmt()
+			fees += rt.TotalFees()
+		}
+
+		if amt == maxAmt && fees <= feeLimit {
+			rt := p.flowPlan[0]
+			p.flowPlan = p.flowPlan[1:]
+
+			return rt, nil
+		}
+	}
+	p.flowPlan = nil
+
+	if p.flowPlans >= flowMaxPlans {
+		p.log.Debugf("not planning flow because plan limit %v has "+
+			"been reached", flowMaxPlans)
+
+		return nil, errNoPathFound
+	}
+	p.flowPlans++
+
+	routes, err := p.planFlow(
+		maxAmt, feeLimit, activeShards, height, finalCltvDelta,
+		restrictions,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	p.flowPlan = routes[1:]
+	p.flowPlanHeight = height
+
+	return routes[0], nil
+}
+
+// planFlow computes a min-cost flow for the given amount and turns it into a
+// set of routes that respects the limits of the payment. An errNoPathFound is
+// returned if no such set exists.
+func (p *paymentSession) planFlow(amt, feeLimit lnwire.MilliSatoshi,
+	activeShards, height uint32, finalCltvDelta uint16,
+	restrictions *RestrictParams) ([]*route.Route, error) {
+
+	routingGraph, cleanup, err := p.getRoutingGraph()
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	bandwidthHints, err := p.getBandwidthHints(routingGraph)
+	if err != nil {
+		return nil, err
+	}
+
+	p.log.Debugf("planning flow for amt=%v", amt)
+
+	sourceVertex := routingGraph.sourceNode()
+	paths, err := findFlow(
+		&graphParams{
+			additionalEdges: p.additionalEdges,
+			bandwidthHints:  bandwidthHints,
+			graph:           routingGraph,
+		},
+		restrictions, &p.pathFindingConfig, sourceVertex,
+		p.payment.Target, amt, p.minShardAmt, p.payment.TimePref,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		routes    []*route.Route
+		totalFees lnwire.MilliSatoshi
+	)
+	cltvLimit := uint64(height) + uint64(p.payment.CltvLimit)
+	for _, path := range paths {
+		// Respect the max shard size by sending the amount of a path
+		// in multiple parts of equal size.
+		parts := lnwire.MilliSatoshi(1)
+		if p.payment.MaxShardAmt != nil {
+			maxShard := *p.payment.MaxShardAmt
+			parts = (path.amt + maxShard - 1) / maxShard
+		}
+
+		remaining := path.amt
+		for i := parts; i > 0; i-- {
+			partAmt := remaining / i
+			remaining -= partAmt
+
+			rt, err := newRoute(
+				sourceVertex, path.edges, height,
+				finalHopParams{
+					amt:         partAmt,
+					totalAmt:    p.payment.Amount,
+					cltvDelta:   finalCltvDelta,
+					records:     p.payment.DestCustomRecords,
+					paymentAddr: p.payment.PaymentAddr,
+					metadata:    p.payment.Metadata,
+				}, nil,
+			)
+			if err != nil {
+				return nil, err
+			}
+
+			if uint64(rt.TotalTimeLock) > cltvLimit {
+				p.log.Debugf("flow exceeds cltv limit %v",
+					p.payment.CltvLimit)
+
+				return nil, errNoPathFound
+			}
+
+			totalFees += rt.TotalFees()
+			routes = append(routes, rt)
+		}
+	}
+
+	if totalFees > feeLimit {
+		p.log.Debugf("flow fees %v exceed fee limit %v", totalFees,
+			feeLimit)
+
+		return nil, errNoPathFound
+	}
+
+	if activeShards+uint32(len(routes)) > p.payment.MaxParts {
+		p.log.Debugf("flow of %v parts exceeds shard limit %v",
+			len(routes), p.payment.MaxParts)
+
+		return nil, errNoPathFound
+	}
+
+	p.log.Debugf("planned flow of %v parts for amt=%v with fees %v",
+		len(routes), amt, totalFees)
+
+	return routes, nil
+}
+
 // UpdateAdditionalEdge updates the channel edge policy for a private edge. It
 // validates the message signature and checks it's up to date, then applies the
 // updates to the supplied policy. It returns a boolean to indicate whether
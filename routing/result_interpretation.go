@@ -2,6 +2,7 @@ package routing
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/lightningnetwork/lnd/channeldb"
 	"github.com/lightningnetwork/lnd/lnwire"
@@ -75,9 +76,12 @@ type interpretedResult struct {
 }
 
 // interpretResult interprets a payment outcome and returns an object that
-// contains information required to update mission control.
+// contains information required to update mission control. The hold times are
+// the ones reported by the hops that attributed the failure, starting at the
+// first hop.
 func interpretResult(rt *route.Route, success bool, failureSrcIdx *int,
-	failure lnwire.FailureMessage) *interpretedResult {
+	failure lnwire.FailureMessage,
+	holdTimes []time.Duration) *interpretedResult {
 
 	i := &interpretedResult{
 		pairResults: make(map[DirectedNodePair]pairResult),
@@ -86,7 +90,7 @@ func interpretResult(rt *route.Route, success bool, failureSrcIdx *int,
 	if success {
 		i.processSuccess(rt)
 	} else {
-		i.processFail(rt, failureSrcIdx, failure)
+		i.processFail(rt, failureSrcIdx, failure, holdTimes)
 	}
 	return i
 }
@@ -101,9 +105,16 @@ func (i *interpretedResult) processSuccess(route *route.Route) {
 // processFail processes a failed payment attempt.
 func (i *interpretedResult) processFail(
 	rt *route.Route, errSourceIdx *int,
-	failure lnwire.FailureMessage) {
+	failure lnwire.FailureMessage, holdTimes []time.Duration) {
 
 	if errSourceIdx == nil {
+		// If some hops attributed the unreadable failure, we know
+		// where it was garbled.
+		if len(holdTimes) > 0 {
+			i.processPaymentOutcomeUnreadable(rt, len(holdTimes))
+			return
+		}
+
 		i.processPaymentOutcomeUnknown(rt)
 		return
 	}
@@ -124,7 +135,7 @@ func (i *interpretedResult) processFail(
 	// and try again.
 	default:
 		i.processPaymentOutcomeIntermediate(
-			rt, *errSourceIdx, failure,
+			rt, *errSourceIdx, failure, holdTimes,
 		)
 	}
 }
@@ -238,7 +249,7 @@ func (i *interpretedResult) processPaymentOutcomeFinal(
 // hop.
 func (i *interpretedResult) processPaymentOutcomeIntermediate(
 	route *route.Route, errorSourceIdx int,
-	failure lnwire.FailureMessage) {
+	failure lnwire.FailureMessage, holdTimes []time.Duration) {
 
 	reportOutgoing := func() {
 		i.failPair(
@@ -386,13 +397,21 @@ func (i *interpretedResult) processPaymentOutcomeIntermediate(
 		reportOutgoingBalance()
 
 	// If FailExpiryTooSoon is received, there must have been some delay
-	// along the path. We can't know which node is causing the delay, so we
+	// along the path. If the hops reported their hold times, we penalize
+	// the outgoing pair of the node that held the HTLC the longest.
+	// Otherwise we can't know which node is causing the delay, so we
 	// penalize all of them up to the error source.
 	//
 	// Alternatively it could also be that we ourselves have fallen behind
 	// somehow. We ignore that case for now.
 	case *lnwire.FailExpiryTooSoon:
-		reportAll()
+		delayIdx, ok := longestDelay(holdTimes, errorSourceIdx)
+		if !ok {
+			reportAll()
+			break
+		}
+
+		i.failPair(route, delayIdx)
 
 	// In all other cases, we penalize the reporting node. These are all
 	// failures that should not happen.
@@ -401,6 +420,63 @@ func (i *interpretedResult) processPaymentOutcomeIntermediate(
 	}
 }
 
+// longestDelay returns the index of the outgoing pair of the node before the
+// given error source that added the largest delay, based on the hold times the
+// nodes reported. The delay a node added is the difference between its own
+// hold time and the hold time of the next node. False is returned if the hold
+// times don't cover all nodes up to the error source, or none of them added a
+// delay.
+func longestDelay(holdTimes []time.Duration, errorSourceIdx int) (int, bool) {
+	if len(holdTimes) < errorSourceIdx {
+		return 0, false
+	}
+
+	var (
+		delayIdx int
+		maxDelay time.Duration
+	)
+	for idx := 1; idx < errorSourceIdx; idx++ {
+		delay := holdTimes[idx-1] - holdTimes[idx]
+		if delay > maxDelay {
+			delayIdx = idx
+			maxDelay = delay
+		}
+	}
+
+	return delayIdx, maxDelay > 0
+}
+
+// processPaymentOutcomeUnreadable processes a payment outcome with a failure
+// message that couldn't be decrypted, but was attributed by the given number
+// of hops. The hop after the last one that attributed the failure either
+// garbled it or received it garbled, so only the pair between the two is
+// penalized.
+func (i *interpretedResult) processPaymentOutcomeUnreadable(rt *route.Route,
+	numAttributed int) {
+
+	n := len(rt.Hops)
+
+	// If all hops attributed the failure, the final node must have sent
+	// the garbled failure.
+	if numAttributed >= n {
+		i.failNode(rt, n)
+
+		// Other channels in the route forwarded correctly.
+		if n >= 2 {
+			i.successPairRange(rt, 0, n-2)
+		}
+
+		i.finalFailureReason = &reasonError
+
+		return
+	}
+
+	i.failPair(rt, numAttributed)
+
+	// All nodes up to the failing pair relayed the failure correctly.
+	i.successPairRange(rt, 0, numAttributed-1)
+}
+
 // processPaymentOutcomeUnknown processes a payment outcome for which no failure
 // message or source is available.
 func (i *interpretedResult) processPaymentOutcomeUnknown(route *route.Route) {
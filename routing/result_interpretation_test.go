@@ -3,6 +3,7 @@ package routing
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/lightningnetwork/lnd/lnwire"
@@ -69,6 +70,14 @@ type resultTestCase struct {
 	failureSrcIdx int
 	failure       lnwire.FailureMessage
 
+	// unreadable indicates that the failure source is unknown, and
+	// failureSrcIdx is ignored.
+	unreadable bool
+
+	// holdTimes are the hold times reported by the hops that attributed
+	// the failure.
+	holdTimes []time.Duration
+
 	expectedResult *interpretedResult
 }
 
@@ -108,6 +117,62 @@ var resultTestCases = []resultTestCase{
 		},
 	},
 
+	// Tests that an expiry too soon failure with hold times only penalizes
+	// the outgoing pair of the node that added the largest delay.
+	{
+		name:          "fail expiry too soon attributed",
+		route:         &routeFourHop,
+		failureSrcIdx: 3,
+		failure:       lnwire.NewExpiryTooSoon(lnwire.ChannelUpdate{}),
+		holdTimes: []time.Duration{
+			5 * time.Minute, 4 * time.Minute, time.Second,
+		},
+
+		expectedResult: &interpretedResult{
+			pairResults: map[DirectedNodePair]pairResult{
+				getTestPair(2, 3): failPairResult(0),
+				getTestPair(3, 2): failPairResult(0),
+			},
+		},
+	},
+
+	// Tests that an unreadable failure that was attributed by the first
+	// two hops penalizes the pair after the last hop that attributed it.
+	{
+		name:       "fail unreadable attributed",
+		route:      &routeFourHop,
+		unreadable: true,
+		holdTimes:  []time.Duration{time.Second, time.Second},
+
+		expectedResult: &interpretedResult{
+			pairResults: map[DirectedNodePair]pairResult{
+				getTestPair(0, 1): successPairResult(100),
+				getTestPair(1, 2): successPairResult(99),
+				getTestPair(2, 3): failPairResult(0),
+				getTestPair(3, 2): failPairResult(0),
+			},
+		},
+	},
+
+	// Tests that an unreadable failure that was attributed by all hops is
+	// blamed on the final node.
+	{
+		name:       "fail unreadable attributed by all hops",
+		route:      &routeTwoHop,
+		unreadable: true,
+		holdTimes:  []time.Duration{time.Second, time.Second},
+
+		expectedResult: &interpretedResult{
+			pairResults: map[DirectedNodePair]pairResult{
+				getTestPair(0, 1): successPairResult(100),
+				getTestPair(1, 2): failPairResult(0),
+				getTestPair(2, 1): failPairResult(0),
+			},
+			nodeFailure:        &hops[2],
+			finalFailureReason: &reasonError,
+		},
+	},
+
 	// Tests an incorrect payment details result. This should be a final
 	// failure, but mark all pairs along the route as successful.
 	{
@@ -375,9 +440,15 @@ func TestResultInterpretation(t *testing.T) {
 
 	for _, testCase := range resultTestCases {
 		t.Run(testCase.name, func(t *testing.T) {
+			failureSrcIdx := &testCase.failureSrcIdx
+			if testCase.unreadable {
+				failureSrcIdx = nil
+			}
+
 			i := interpretResult(
 				testCase.route, testCase.success,
-				&testCase.failureSrcIdx, testCase.failure,
+				failureSrcIdx, testCase.failure,
+				testCase.holdTimes,
 			)
 
 			expected := testCase.expectedResult
@@ -21,6 +21,7 @@ import (
 	"github.com/lightningnetwork/lnd/batch"
 	"github.com/lightningnetwork/lnd/chainntnfs"
 	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/channeldb/models"
 	"github.com/lightningnetwork/lnd/clock"
 	"github.com/lightningnetwork/lnd/htlcswitch"
 	"github.com/lightningnetwork/lnd/input"
@@ -252,12 +253,13 @@ type PaymentSessionSource interface {
 // probability estimation.
 type MissionController interface {
 	// ReportPaymentFail reports a failed payment to mission control as
-	// input for future probability estimates. It returns a bool indicating
-	// whether this error is a final error and no further payment attempts
-	// need to be made.
+	// input for future probability estimates. The hold times are the ones
+	// reported by the hops that attributed the failure, if any. It returns
+	// a bool indicating whether this error is a final error and no further
+	// payment attempts need to be made.
 	ReportPaymentFail(attemptID uint64, rt *route.Route,
-		failureSourceIdx *int, failure lnwire.FailureMessage) (
-		*channeldb.FailureReason, error)
+		failureSourceIdx *int, failure lnwire.FailureMessage,
+		holdTimes []time.Duration) (*channeldb.FailureReason, error)
 
 	// ReportPaymentSuccess reports a successful payment to mission control
 	// as input for future probability estimates.
@@ -283,6 +285,10 @@ type FeeSchema struct {
 	// the effective fee rate charged per mSAT will be: (amount *
 	// FeeRate/1,000,000).
 	FeeRate uint32
+
+	// InboundFee is the fee charged for payments arriving through the
+	// channel. If nil, the current inbound fee is kept.
+	InboundFee *models.InboundFee
 }
 
 // ChannelPolicy holds the parameters that determine the policy we enforce
@@ -2239,6 +2245,10 @@ type LightningPayment struct {
 	// NOTE: This field is _optional_.
 	MaxShardAmt *lnwire.MilliSatoshi
 
+	// SplitMode determines how the payment is split into multiple parts
+	// if it can't be sent in one.
+	SplitMode SplitMode
+
 	// TimePref is the time preference for this payment. Set to -1 to
 	// optimize for fees only, to 1 to optimize for reliability only or a
 	// value in between for a mix.
@@ -2247,6 +2257,11 @@ type LightningPayment struct {
 	// Metadata is additional data that is sent along with the payment to
 	// the payee.
 	Metadata []byte
+
+	// Rebalance indicates that this is a circular payment to ourselves
+	// that moves funds between our own channels. It is recorded as such
+	// in the payments database.
+	Rebalance bool
 }
 
 // AMPOptions houses information that must be known in order to send an AMP
@@ -2385,6 +2400,7 @@ func (r *ChannelRouter) PreparePayment(payment *LightningPayment) (
 		Value:             payment.Amount,
 		CreationTime:      r.cfg.Clock.Now(),
 		PaymentRequest:    payment.PaymentRequest,
+		Rebalance:         payment.Rebalance,
 	}
 
 	// Create a new ShardTracker that we'll use during the life cycle of
@@ -3056,6 +3072,10 @@ func getRouteUnifiers(source route.Vertex, hops []route.Vertex,
 	// Allocate a list that will contain the edge unifiers for this route.
 	unifiers := make([]*edgeUnifier, len(hops))
 
+	// nextOutFee is the outbound fee charged by the node that the current
+	// hop leads to. It caps the inbound discount that node can grant.
+	var nextOutFee lnwire.MilliSatoshi
+
 	// Traverse hops backwards to accumulate fees in the running amounts.
 	for i := len(hops) - 1; i >= 0; i-- {
 		toNode := hops[i]
@@ -3070,8 +3090,11 @@ func getRouteUnifiers(source route.Vertex, hops []route.Vertex,
 		localChan := i == 0
 
 		// Build unified edges for this hop based on the channels known
-		// in the graph.
-		u := newNodeEdgeUnifier(source, toNode, outgoingChans)
+		// in the graph. The final hop doesn't charge inbound fees.
+		useInboundFees := i != len(hops)-1
+		u := newNodeEdgeUnifier(
+			source, toNode, useInboundFees, outgoingChans,
+		)
 
 		err := u.addGraphPolicies(graph)
 		if err != nil {
@@ -3097,7 +3120,9 @@ func getRouteUnifiers(source route.Vertex, hops []route.Vertex,
 		}
 
 		// Get an edge for the specific amount that we want to forward.
-		edge := edgeUnifier.getEdge(runningAmt, bandwidthHints)
+		edge := edgeUnifier.getEdge(
+			runningAmt, bandwidthHints, nextOutFee,
+		)
 		if edge == nil {
 			log.Errorf("Cannot find policy with amt=%v for node %v",
 				runningAmt, fromNode)
@@ -3108,9 +3133,18 @@ func getRouteUnifiers(source route.Vertex, hops []route.Vertex,
 			}
 		}
 
+		// Add the inbound fee charged by the receiving node to get to
+		// the amount that is sent over the channel.
+		inboundFee := calcCappedInboundFee(
+			edge, runningAmt, nextOutFee,
+		)
+		runningAmt += lnwire.MilliSatoshi(inboundFee)
+
 		// Add fee for this hop.
+		nextOutFee = 0
 		if !localChan {
-			runningAmt += edge.policy.ComputeFee(runningAmt)
+			nextOutFee = edge.policy.ComputeFee(runningAmt)
+			runningAmt += nextOutFee
 		}
 
 		log.Tracef("Select channel %v at position %v",
@@ -3126,16 +3160,15 @@ func getRouteUnifiers(source route.Vertex, hops []route.Vertex,
 // including fees, to send the payment.
 func getPathEdges(source route.Vertex, receiverAmt lnwire.MilliSatoshi,
 	unifiers []*edgeUnifier, bandwidthHints *bandwidthManager,
-	hops []route.Vertex) ([]*channeldb.CachedEdgePolicy,
-	lnwire.MilliSatoshi, error) {
+	hops []route.Vertex) ([]*unifiedEdge, lnwire.MilliSatoshi, error) {
 
 	// Now that we arrived at the start of the route and found out the route
 	// total amount, we make a forward pass. Because the amount may have
 	// been increased in the backward pass, fees need to be recalculated and
 	// amount ranges re-checked.
-	var pathEdges []*channeldb.CachedEdgePolicy
+	var pathEdges []*unifiedEdge
 	for i, unifier := range unifiers {
-		edge := unifier.getEdge(receiverAmt, bandwidthHints)
+		edge := unifier.getEdge(receiverAmt, bandwidthHints, 0)
 		if edge == nil {
 			fromNode := source
 			if i > 0 {
@@ -3149,13 +3182,25 @@ func getPathEdges(source route.Vertex, receiverAmt lnwire.MilliSatoshi,
 		}
 
 		if i > 0 {
-			// Decrease the amount to send while going forward.
+			// Decrease the amount to send while going forward. The
+			// forwarding node deducts the inbound fee for the
+			// channel the payment arrives through as well as the
+			// outbound fee for this channel.
+			incomingAmt := receiverAmt
+			receiverAmt = pathEdges[i-1].netAmountFromIncoming(
+				incomingAmt,
+			)
 			receiverAmt -= edge.policy.ComputeFeeFromIncoming(
 				receiverAmt,
 			)
+
+			// The total fee of a node is never negative.
+			if receiverAmt > incomingAmt {
+				receiverAmt = incomingAmt
+			}
 		}
 
-		pathEdges = append(pathEdges, edge.policy)
+		pathEdges = append(pathEdges, edge)
 	}
 
 	return pathEdges, receiverAmt, nil
@@ -1,13 +1,19 @@
 package routing
 
 import (
+	"math"
+
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/channeldb/models"
 	"github.com/lightningnetwork/lnd/lntypes"
 	"github.com/lightningnetwork/lnd/lnwire"
 	"github.com/lightningnetwork/lnd/routing/route"
 )
 
+// feeRateParts is the total number of parts used to express fee rates.
+const feeRateParts = 1_000_000
+
 // nodeEdgeUnifier holds all edge unifiers for connections towards a node.
 type nodeEdgeUnifier struct {
 	// edgeUnifiers contains an edge unifier for every from node.
@@ -20,6 +26,11 @@ type nodeEdgeUnifier struct {
 	// toNode is the node for which the edge unifiers are instantiated.
 	toNode route.Vertex
 
+	// useInboundFees indicates whether the inbound fees of the toNode are
+	// taken into account. The final hop of a route doesn't charge inbound
+	// fees.
+	useInboundFees bool
+
 	// outChanRestr is an optional outgoing channel restriction for the
 	// local channel to use.
 	outChanRestr map[uint64]struct{}
@@ -27,21 +38,24 @@ type nodeEdgeUnifier struct {
 
 // newNodeEdgeUnifier instantiates a new nodeEdgeUnifier object. Channel
 // policies can be added to this object.
-func newNodeEdgeUnifier(sourceNode, toNode route.Vertex,
+func newNodeEdgeUnifier(sourceNode, toNode route.Vertex, useInboundFees bool,
 	outChanRestr map[uint64]struct{}) *nodeEdgeUnifier {
 
 	return &nodeEdgeUnifier{
-		edgeUnifiers: make(map[route.Vertex]*edgeUnifier),
-		toNode:       toNode,
-		sourceNode:   sourceNode,
-		outChanRestr: outChanRestr,
+		edgeUnifiers:   make(map[route.Vertex]*edgeUnifier),
+		toNode:         toNode,
+		useInboundFees: useInboundFees,
+		sourceNode:     sourceNode,
+		outChanRestr:   outChanRestr,
 	}
 }
 
-// addPolicy adds a single channel policy. Capacity may be zero if unknown
-// (light clients).
+// addPolicy adds a single channel policy together with the inbound fee that
+// the toNode charges for payments arriving through the channel. Capacity may
+// be zero if unknown (light clients).
 func (u *nodeEdgeUnifier) addPolicy(fromNode route.Vertex,
-	edge *channeldb.CachedEdgePolicy, capacity btcutil.Amount) {
+	edge *channeldb.CachedEdgePolicy, inboundFee models.InboundFee,
+	capacity btcutil.Amount) {
 
 	localChan := fromNode == u.sourceNode
 
@@ -61,9 +75,14 @@ func (u *nodeEdgeUnifier) addPolicy(fromNode route.Vertex,
 		u.edgeUnifiers[fromNode] = unifier
 	}
 
+	if !u.useInboundFees {
+		inboundFee = models.InboundFee{}
+	}
+
 	unifier.edges = append(unifier.edges, &unifiedEdge{
-		policy:   edge,
-		capacity: capacity,
+		policy:      edge,
+		capacity:    capacity,
+		inboundFees: inboundFee,
 	})
 }
 
@@ -78,9 +97,13 @@ func (u *nodeEdgeUnifier) addGraphPolicies(g routingGraph) error {
 			return nil
 		}
 
-		// Add this policy to the corresponding edgeUnifier.
+		// Add this policy to the corresponding edgeUnifier. The inbound
+		// fee is the fee that the toNode charges for payments arriving
+		// through this channel.
+		inboundFee := models.NewInboundFeeFromWire(channel.InboundFee)
 		u.addPolicy(
-			channel.OtherNode, channel.InPolicy, channel.Capacity,
+			channel.OtherNode, channel.InPolicy, inboundFee,
+			channel.Capacity,
 		)
 
 		return nil
@@ -95,6 +118,53 @@ func (u *nodeEdgeUnifier) addGraphPolicies(g routingGraph) error {
 type unifiedEdge struct {
 	policy   *channeldb.CachedEdgePolicy
 	capacity btcutil.Amount
+
+	// inboundFees are the inbound fees charged by the node that the
+	// policy leads to for payments arriving through this channel.
+	inboundFees models.InboundFee
+}
+
+// calcCappedInboundFee calculates the inbound fee for a channel given the net
+// amount that the receiving node forwards including its outbound fee. A
+// negative inbound fee never exceeds the outbound fee of the receiving node,
+// because routing nodes treat a negative total fee as zero.
+func calcCappedInboundFee(edge *unifiedEdge, amt lnwire.MilliSatoshi,
+	nextOutFee lnwire.MilliSatoshi) int64 {
+
+	inboundFee := edge.inboundFees.CalcFee(amt)
+
+	minFee := -int64(nextOutFee)
+	if inboundFee < minFee {
+		return minFee
+	}
+
+	return inboundFee
+}
+
+// netAmountFromIncoming returns the amount that remains of the given amount
+// arriving through this channel after the receiving node deducted its inbound
+// fee. It is the inverse of adding the inbound fee to the net amount.
+func (u *unifiedEdge) netAmountFromIncoming(
+	incomingAmt lnwire.MilliSatoshi) lnwire.MilliSatoshi {
+
+	// The incoming amount is the net amount plus the inbound fee, which is
+	// base + rate * net / 1e6. Solve this for the net amount.
+	base := int64(u.inboundFees.Base)
+	rate := int64(u.inboundFees.Rate)
+
+	// A discount of the full amount or more leaves the amount unchanged,
+	// as the total fee of a node is never negative.
+	if feeRateParts+rate <= 0 {
+		return incomingAmt
+	}
+
+	net := (int64(incomingAmt) - base) * feeRateParts /
+		(feeRateParts + rate)
+	if net < 0 {
+		return 0
+	}
+
+	return lnwire.MilliSatoshi(net)
 }
 
 // amtInRange checks whether an amount falls within the valid range for a
@@ -136,22 +206,28 @@ type edgeUnifier struct {
 }
 
 // getEdge returns the optimal unified edge to use for this connection given a
-// specific amount to send. It differentiates between local and network
-// channels.
-func (u *edgeUnifier) getEdge(amt lnwire.MilliSatoshi,
-	bandwidthHints bandwidthHints) *unifiedEdge {
+// specific net amount that the receiving node needs to receive and the
+// outbound fee that it charges. The amount sent over the channel also includes
+// the inbound fee of the receiving node. It differentiates between local and
+// network channels.
+func (u *edgeUnifier) getEdge(netAmtReceived lnwire.MilliSatoshi,
+	bandwidthHints bandwidthHints,
+	nextOutFee lnwire.MilliSatoshi) *unifiedEdge {
 
 	if u.localChan {
-		return u.getEdgeLocal(amt, bandwidthHints)
+		return u.getEdgeLocal(
+			netAmtReceived, bandwidthHints, nextOutFee,
+		)
 	}
 
-	return u.getEdgeNetwork(amt)
+	return u.getEdgeNetwork(netAmtReceived, nextOutFee)
 }
 
 // getEdgeLocal returns the optimal unified edge to use for this local
-// connection given a specific amount to send.
-func (u *edgeUnifier) getEdgeLocal(amt lnwire.MilliSatoshi,
-	bandwidthHints bandwidthHints) *unifiedEdge {
+// connection given a specific net amount to be received.
+func (u *edgeUnifier) getEdgeLocal(netAmtReceived lnwire.MilliSatoshi,
+	bandwidthHints bandwidthHints,
+	nextOutFee lnwire.MilliSatoshi) *unifiedEdge {
 
 	var (
 		bestEdge     *unifiedEdge
@@ -159,6 +235,13 @@ func (u *edgeUnifier) getEdgeLocal(amt lnwire.MilliSatoshi,
 	)
 
 	for _, edge := range u.edges {
+		// Add the inbound fee of the receiving node to get to the
+		// amount that is sent over the channel.
+		inboundFee := calcCappedInboundFee(
+			edge, netAmtReceived, nextOutFee,
+		)
+		amt := netAmtReceived + lnwire.MilliSatoshi(inboundFee)
+
 		// Check valid amount range for the channel.
 		if !edge.amtInRange(amt) {
 			log.Debugf("Amount %v not in range for edge %v",
@@ -219,8 +302,9 @@ func (u *edgeUnifier) getEdgeLocal(amt lnwire.MilliSatoshi,
 
 		// Update best edge.
 		bestEdge = &unifiedEdge{
-			policy:   edge.policy,
-			capacity: edge.capacity,
+			policy:      edge.policy,
+			capacity:    edge.capacity,
+			inboundFees: edge.inboundFees,
 		}
 	}
 
@@ -231,15 +315,24 @@ func (u *edgeUnifier) getEdgeLocal(amt lnwire.MilliSatoshi,
 // given a specific amount to send. The goal is to return a unified edge with a
 // policy that maximizes the probability of a successful forward in a non-strict
 // forwarding context.
-func (u *edgeUnifier) getEdgeNetwork(amt lnwire.MilliSatoshi) *unifiedEdge {
+func (u *edgeUnifier) getEdgeNetwork(netAmtReceived lnwire.MilliSatoshi,
+	nextOutFee lnwire.MilliSatoshi) *unifiedEdge {
+
 	var (
-		bestPolicy  *channeldb.CachedEdgePolicy
-		maxFee      lnwire.MilliSatoshi
+		bestPolicy  *unifiedEdge
+		maxFee      int64 = math.MinInt64
 		maxTimelock uint16
 		maxCapMsat  lnwire.MilliSatoshi
 	)
 
 	for _, edge := range u.edges {
+		// Add the inbound fee of the receiving node to get to the
+		// amount that is sent over the channel.
+		inboundFee := calcCappedInboundFee(
+			edge, netAmtReceived, nextOutFee,
+		)
+		amt := netAmtReceived + lnwire.MilliSatoshi(inboundFee)
+
 		// Check valid amount range for the channel.
 		if !edge.amtInRange(amt) {
 			log.Debugf("Amount %v not in range for edge %v",
@@ -275,8 +368,9 @@ func (u *edgeUnifier) getEdgeNetwork(amt lnwire.MilliSatoshi) *unifiedEdge {
 		)
 
 		// Use the policy that results in the highest fee for this
-		// specific amount.
-		fee := edge.policy.ComputeFee(amt)
+		// specific amount. The fee includes the inbound fee of the
+		// receiving node, which depends on the channel used.
+		fee := int64(edge.policy.ComputeFee(amt)) + inboundFee
 		if fee < maxFee {
 			log.Debugf("Skipped edge %v due to it produces less "+
 				"fee: fee=%v, maxFee=%v",
@@ -285,7 +379,7 @@ func (u *edgeUnifier) getEdgeNetwork(amt lnwire.MilliSatoshi) *unifiedEdge {
 		}
 		maxFee = fee
 
-		bestPolicy = edge.policy
+		bestPolicy = edge
 	}
 
 	// Return early if no channel matches.
@@ -303,8 +397,11 @@ func (u *edgeUnifier) getEdgeNetwork(amt lnwire.MilliSatoshi) *unifiedEdge {
 	// get forwarded. Because we penalize pair-wise, there won't be a second
 	// chance for this node pair. But this is all only needed for nodes that
 	// have distinct policies for channels to the same peer.
-	policyCopy := *bestPolicy
-	modifiedEdge := unifiedEdge{policy: &policyCopy}
+	policyCopy := *bestPolicy.policy
+	modifiedEdge := unifiedEdge{
+		policy:      &policyCopy,
+		inboundFees: bestPolicy.inboundFees,
+	}
 	modifiedEdge.policy.TimeLockDelta = maxTimelock
 	modifiedEdge.capacity = maxCapMsat.ToSatoshis()
 
@@ -5,6 +5,7 @@ import (
 
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/channeldb/models"
 	"github.com/lightningnetwork/lnd/lnwire"
 	"github.com/lightningnetwork/lnd/routing/route"
 	"github.com/stretchr/testify/require"
@@ -40,26 +41,44 @@ func TestNodeEdgeUnifier(t *testing.T) {
 	c1 := btcutil.Amount(7)
 	c2 := btcutil.Amount(8)
 
-	unifierFilled := newNodeEdgeUnifier(source, toNode, nil)
-	unifierFilled.addPolicy(fromNode, &p1, c1)
-	unifierFilled.addPolicy(fromNode, &p2, c2)
+	noInbound := models.InboundFee{}
 
-	unifierNoCapacity := newNodeEdgeUnifier(source, toNode, nil)
-	unifierNoCapacity.addPolicy(fromNode, &p1, 0)
-	unifierNoCapacity.addPolicy(fromNode, &p2, 0)
+	unifierFilled := newNodeEdgeUnifier(source, toNode, true, nil)
+	unifierFilled.addPolicy(fromNode, &p1, noInbound, c1)
+	unifierFilled.addPolicy(fromNode, &p2, noInbound, c2)
 
-	unifierNoInfo := newNodeEdgeUnifier(source, toNode, nil)
-	unifierNoInfo.addPolicy(fromNode, &channeldb.CachedEdgePolicy{}, 0)
+	unifierNoCapacity := newNodeEdgeUnifier(source, toNode, true, nil)
+	unifierNoCapacity.addPolicy(fromNode, &p1, noInbound, 0)
+	unifierNoCapacity.addPolicy(fromNode, &p2, noInbound, 0)
+
+	unifierNoInfo := newNodeEdgeUnifier(source, toNode, true, nil)
+	unifierNoInfo.addPolicy(
+		fromNode, &channeldb.CachedEdgePolicy{}, noInbound, 0,
+	)
+
+	// The to node grants an inbound discount on the first channel, which
+	// makes the second channel the one with the highest total fee.
+	inboundFee1 := models.InboundFee{Base: -5}
+	unifierInboundFee := newNodeEdgeUnifier(source, toNode, true, nil)
+	unifierInboundFee.addPolicy(fromNode, &p1, inboundFee1, c1)
+	unifierInboundFee.addPolicy(fromNode, &p2, noInbound, c2)
+
+	// If the to node is the final hop, its inbound fees are ignored.
+	unifierExitHop := newNodeEdgeUnifier(source, toNode, false, nil)
+	unifierExitHop.addPolicy(fromNode, &p1, inboundFee1, c1)
+	unifierExitHop.addPolicy(fromNode, &p2, noInbound, c2)
 
 	tests := []struct {
 		name             string
 		unifier          *nodeEdgeUnifier
 		amount           lnwire.MilliSatoshi
+		nextOutFee       lnwire.MilliSatoshi
 		expectedFeeBase  lnwire.MilliSatoshi
 		expectedFeeRate  lnwire.MilliSatoshi
 		expectedTimeLock uint16
 		expectNoPolicy   bool
 		expectedCapacity btcutil.Amount
+		expectedInbound  models.InboundFee
 	}{
 		{
 			name:           "amount below min htlc",
@@ -114,6 +133,40 @@ func TestNodeEdgeUnifier(t *testing.T) {
 			unifier:          unifierNoInfo,
 			expectedCapacity: 0,
 		},
+		// The inbound discount on p1 lowers its total fee below the
+		// fee of p2.
+		{
+			name:             "inbound discount",
+			unifier:          unifierInboundFee,
+			amount:           200,
+			nextOutFee:       10,
+			expectedFeeBase:  p2.FeeBaseMSat,
+			expectedFeeRate:  p2.FeeProportionalMillionths,
+			expectedTimeLock: p1.TimeLockDelta,
+			expectedCapacity: c2,
+		},
+		// The discount is capped at the outbound fee of the to node.
+		// Without an outbound fee, p1 yields the highest fee again.
+		{
+			name:             "capped inbound discount",
+			unifier:          unifierInboundFee,
+			amount:           200,
+			expectedFeeBase:  p1.FeeBaseMSat,
+			expectedFeeRate:  p1.FeeProportionalMillionths,
+			expectedTimeLock: p1.TimeLockDelta,
+			expectedCapacity: c2,
+			expectedInbound:  inboundFee1,
+		},
+		{
+			name:             "exit hop",
+			unifier:          unifierExitHop,
+			amount:           200,
+			nextOutFee:       10,
+			expectedFeeBase:  p1.FeeBaseMSat,
+			expectedFeeRate:  p1.FeeProportionalMillionths,
+			expectedTimeLock: p1.TimeLockDelta,
+			expectedCapacity: c2,
+		},
 	}
 
 	for _, test := range tests {
@@ -123,7 +176,7 @@ func TestNodeEdgeUnifier(t *testing.T) {
 			t.Parallel()
 
 			edge := test.unifier.edgeUnifiers[fromNode].getEdge(
-				test.amount, bandwidthHints,
+				test.amount, bandwidthHints, test.nextOutFee,
 			)
 
 			if test.expectNoPolicy {
@@ -141,6 +194,8 @@ func TestNodeEdgeUnifier(t *testing.T) {
 				policy.TimeLockDelta, "timelock")
 			require.Equal(t, test.expectedCapacity, edge.capacity,
 				"capacity")
+			require.Equal(t, test.expectedInbound,
+				edge.inboundFees, "inbound fee")
 		})
 	}
 }
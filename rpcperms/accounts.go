@@ -0,0 +1,211 @@
+package rpcperms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/lightningnetwork/lnd/macaroons"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	// ErrNoAccountInterceptor is returned if a macaroon is bound to an
+	// account but there is no account interceptor to enforce it.
+	ErrNoAccountInterceptor = errors.New("macaroon is bound to an " +
+		"account, but accounts are not available")
+)
+
+// AccountInterceptor restricts the calls made with macaroons that are bound to
+// a virtual account to the balance, invoices and payments of the account.
+type AccountInterceptor interface {
+	// NewCall returns the handler of a single call of the given method
+	// made with a macaroon bound to the given account. An error is
+	// returned if the account isn't allowed to call the method.
+	NewCall(ctx context.Context, accountID [macaroons.AccountIDLen]byte,
+		fullMethod string) (AccountCall, error)
+}
+
+// AccountCall checks the messages of a single call made with a macaroon that
+// is bound to an account.
+type AccountCall interface {
+	// HandleRequest checks a request of the call before it is passed to
+	// lnd. If an error is returned, the request is rejected.
+	HandleRequest(ctx context.Context, req proto.Message) error
+
+	// HandleResponse checks a response of the call before it is passed to
+	// the client. The returned message is sent to the client instead of
+	// the original response. A nil message means the response isn't sent
+	// at all, which is only allowed for streaming calls.
+	HandleResponse(ctx context.Context,
+		resp proto.Message) (proto.Message, error)
+
+	// Done is called once lnd finished handling the call, with the error
+	// lnd returned, if any.
+	Done(err error)
+}
+
+// AddAccountInterceptor adds the interceptor that enforces the accounts of
+// account bound macaroons.
+func (r *InterceptorChain) AddAccountInterceptor(
+	interceptor AccountInterceptor) {
+
+	r.Lock()
+	defer r.Unlock()
+
+	r.accountInterceptor = interceptor
+}
+
+// newAccountCall returns the account handler of a call if the macaroon in the
+// given context is bound to an account. If it isn't, nil is returned.
+func (r *InterceptorChain) newAccountCall(ctx context.Context,
+	fullMethod string) (AccountCall, error) {
+
+	if r.noMacaroons {
+		return nil, nil
+	}
+	if _, ok := macaroonWhitelist[fullMethod]; ok {
+		return nil, nil
+	}
+
+	mac, _, err := macaroonFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	accountID, ok, err := macaroons.ParseAccountID(mac)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	r.RLock()
+	interceptor := r.accountInterceptor
+	r.RUnlock()
+
+	if interceptor == nil {
+		return nil, ErrNoAccountInterceptor
+	}
+
+	return interceptor.NewCall(ctx, accountID, fullMethod)
+}
+
+// accountUnaryServerInterceptor is a unary gRPC interceptor that restricts
+// calls made with account bound macaroons to their account.
+func (r *InterceptorChain) accountUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+
+		call, err := r.newAccountCall(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		if call == nil {
+			return handler(ctx, req)
+		}
+
+		msg, ok := req.(proto.Message)
+		if !ok {
+			return nil, fmt.Errorf("unexpected request type %T", req)
+		}
+		if err := call.HandleRequest(ctx, msg); err != nil {
+			call.Done(err)
+			return nil, err
+		}
+
+		resp, lndErr := handler(ctx, req)
+		defer func() {
+			call.Done(lndErr)
+		}()
+		if lndErr != nil {
+			return resp, lndErr
+		}
+
+		msg, ok = resp.(proto.Message)
+		if !ok {
+			return nil, fmt.Errorf("unexpected response type %T",
+				resp)
+		}
+
+		newResp, err := call.HandleResponse(ctx, msg)
+		if err != nil {
+			return nil, err
+		}
+		if newResp == nil {
+			return nil, fmt.Errorf("response of %v rejected by "+
+				"account", info.FullMethod)
+		}
+
+		return newResp, nil
+	}
+}
+
+// accountStreamServerInterceptor is a streaming gRPC interceptor that
+// restricts streams opened with account bound macaroons to their account.
+func (r *InterceptorChain) accountStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream,
+		info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+		call, err := r.newAccountCall(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		if call == nil {
+			return handler(srv, ss)
+		}
+
+		err = handler(srv, &accountServerStream{
+			ServerStream: ss,
+			call:         call,
+		})
+		call.Done(err)
+
+		return err
+	}
+}
+
+// accountServerStream wraps a server stream to pass every message that is
+// sent over it through the account handler of the stream.
+type accountServerStream struct {
+	grpc.ServerStream
+
+	call AccountCall
+}
+
+// SendMsg is called when lnd sends a message to the client.
+func (s *accountServerStream) SendMsg(m interface{}) error {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return fmt.Errorf("unexpected response type %T", m)
+	}
+
+	newMsg, err := s.call.HandleResponse(s.Context(), msg)
+	if err != nil {
+		return err
+	}
+
+	// Responses that don't belong to the account are silently dropped.
+	if newMsg == nil {
+		return nil
+	}
+
+	return s.ServerStream.SendMsg(newMsg)
+}
+
+// RecvMsg is called when lnd wants to receive a message from the client.
+func (s *accountServerStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return fmt.Errorf("unexpected request type %T", m)
+	}
+
+	return s.call.HandleRequest(s.Context(), msg)
+}
@@ -0,0 +1,133 @@
+package rpcperms
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/btcsuite/btclog"
+	"github.com/lightningnetwork/lnd/lnrpc"
+	"github.com/lightningnetwork/lnd/macaroons"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+var errNotAllowed = errors.New("not allowed")
+
+// mockAccountInterceptor only allows SendPaymentSync and replaces the response
+// of every call with a static one.
+type mockAccountInterceptor struct {
+	accountID [macaroons.AccountIDLen]byte
+	requests  []proto.Message
+	doneErrs  []error
+}
+
+func (m *mockAccountInterceptor) NewCall(_ context.Context,
+	accountID [macaroons.AccountIDLen]byte,
+	fullMethod string) (AccountCall, error) {
+
+	if fullMethod != testSendMethod {
+		return nil, errNotAllowed
+	}
+	m.accountID = accountID
+
+	return m, nil
+}
+
+func (m *mockAccountInterceptor) HandleRequest(_ context.Context,
+	req proto.Message) error {
+
+	m.requests = append(m.requests, req)
+
+	if req.(*lnrpc.SendRequest).AmtMsat > 1_000 {
+		return errNotAllowed
+	}
+
+	return nil
+}
+
+func (m *mockAccountInterceptor) HandleResponse(_ context.Context,
+	_ proto.Message) (proto.Message, error) {
+
+	return &lnrpc.SendResponse{PaymentError: "replaced"}, nil
+}
+
+func (m *mockAccountInterceptor) Done(err error) {
+	m.doneErrs = append(m.doneErrs, err)
+}
+
+// TestAccountInterceptor tests that calls made with account bound macaroons
+// are passed through the account interceptor.
+func TestAccountInterceptor(t *testing.T) {
+	t.Parallel()
+
+	chain := NewInterceptorChain(btclog.Disabled, false, nil)
+	interceptor := chain.accountUnaryServerInterceptor()
+
+	var handlerErr error
+	handler := func(context.Context, interface{}) (interface{}, error) {
+		return &lnrpc.SendResponse{}, handlerErr
+	}
+	call := func(ctx context.Context, method string,
+		amt int64) (*lnrpc.SendResponse, error) {
+
+		resp, err := interceptor(
+			ctx, &lnrpc.SendRequest{AmtMsat: amt},
+			&grpc.UnaryServerInfo{FullMethod: method}, handler,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		return resp.(*lnrpc.SendResponse), nil
+	}
+
+	accountID := [macaroons.AccountIDLen]byte{1, 2, 3}
+	accountCtx := newLimitedContext(
+		t, "1", macaroons.AccountConstraint(accountID),
+	)
+
+	// Without an account interceptor, account bound macaroons can't be
+	// used at all, while all other macaroons are unaffected.
+	_, err := call(accountCtx, testSendMethod, 1)
+	require.ErrorIs(t, err, ErrNoAccountInterceptor)
+
+	resp, err := call(newLimitedContext(t, "2"), testSendMethod, 1)
+	require.NoError(t, err)
+	require.Empty(t, resp.PaymentError)
+
+	accounts := &mockAccountInterceptor{}
+	chain.AddAccountInterceptor(accounts)
+
+	// Methods the account isn't allowed to call are rejected.
+	_, err = call(accountCtx, "/lnrpc.Lightning/ListChannels", 1)
+	require.ErrorIs(t, err, errNotAllowed)
+
+	// Rejected requests never reach lnd.
+	_, err = call(accountCtx, testSendMethod, 2_000)
+	require.ErrorIs(t, err, errNotAllowed)
+
+	// Allowed requests reach lnd and the response is replaced by the one
+	// of the account interceptor.
+	resp, err = call(accountCtx, testSendMethod, 1)
+	require.NoError(t, err)
+	require.Equal(t, "replaced", resp.PaymentError)
+	require.Equal(t, accountID, accounts.accountID)
+
+	// The error of lnd is passed to the interceptor once the call is
+	// done.
+	handlerErr = errors.New("lnd error")
+	_, err = call(accountCtx, testSendMethod, 1)
+	require.ErrorIs(t, err, handlerErr)
+
+	require.Len(t, accounts.requests, 3)
+	require.Equal(t, []error{errNotAllowed, nil, handlerErr},
+		accounts.doneErrs)
+
+	// Macaroons without an account caveat still bypass the interceptor.
+	handlerErr = nil
+	resp, err = call(newLimitedContext(t, "2"), testSendMethod, 2_000)
+	require.NoError(t, err)
+	require.Empty(t, resp.PaymentError)
+}
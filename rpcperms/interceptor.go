@@ -119,7 +119,9 @@ var (
 //	  +----------------------------------+--------> +---------------------+
 //	  | RPC Macaroon Middleware Handler  |<-------- | External Middleware |
 //	  +----------------------------------+          |   - modify request |
-//	  | Prometheus Interceptor           |          +---------------------+
+//	  | Macaroon Limits Interceptor      |          +---------------------+
+//	  +----------------------------------+
+//	  | Prometheus Interceptor           |
 //	  +-+--------------------------------+
 //	    | validated gRPC request from client
 //	+---v--------------------------------+
@@ -164,6 +166,17 @@ type InterceptorChain struct {
 	// permissionMap is the permissions to enforce if macaroons are used.
 	permissionMap map[string][]bakery.Op
 
+	// usageStore keeps track of the usage of macaroons that carry spending
+	// or rate limit caveats.
+	usageStore *macaroons.UsageStore
+
+	// spendParsers are used to extract the funds a request sends, keyed by
+	// the full method name of the RPC.
+	spendParsers map[string]*SpendParser
+
+	// accountInterceptor enforces the accounts of account bound macaroons.
+	accountInterceptor AccountInterceptor
+
 	// rpcsLog is the logger used to log calls to the RPCs intercepted.
 	rpcsLog btclog.Logger
 
@@ -206,6 +219,7 @@ func NewInterceptorChain(log btclog.Logger, noMacaroons bool,
 		ntfnServer:                subscribe.NewServer(),
 		noMacaroons:               noMacaroons,
 		permissionMap:             make(map[string][]bakery.Op),
+		spendParsers:              make(map[string]*SpendParser),
 		rpcsLog:                   log,
 		registeredMiddlewareNames: make(map[string]int),
 		mandatoryMiddleware:       mandatoryMiddleware,
@@ -573,6 +587,26 @@ func (r *InterceptorChain) CreateServerOpts() []grpc.ServerOption {
 		strmInterceptors, r.middlewareStreamServerInterceptor(),
 	)
 
+	// The macaroon limits interceptors come after the middleware so they
+	// see the requests as they are passed to lnd, after a middleware
+	// might have modified them.
+	unaryInterceptors = append(
+		unaryInterceptors, r.macaroonLimitsUnaryServerInterceptor(),
+	)
+	strmInterceptors = append(
+		strmInterceptors, r.macaroonLimitsStreamServerInterceptor(),
+	)
+
+	// The account interceptors come last, right before lnd handles a
+	// request, so a payment is only reserved from the balance of an
+	// account once all other checks passed.
+	unaryInterceptors = append(
+		unaryInterceptors, r.accountUnaryServerInterceptor(),
+	)
+	strmInterceptors = append(
+		strmInterceptors, r.accountStreamServerInterceptor(),
+	)
+
 	// Get interceptors for Prometheus to gather gRPC performance metrics.
 	// If monitoring is disabled, GetPromInterceptors() will return empty
 	// slices.
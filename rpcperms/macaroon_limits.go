@@ -0,0 +1,410 @@
+package rpcperms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lightningnetwork/lnd/lnrpc"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/macaroons"
+	"github.com/lightningnetwork/lnd/routing/route"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/macaroon-bakery.v2/bakery"
+	"gopkg.in/macaroon.v2"
+)
+
+var (
+	// ErrNoUsageStore is returned if a macaroon carries spending or rate
+	// limit caveats but there is no usage store to enforce them.
+	ErrNoUsageStore = errors.New("macaroon limits cannot be enforced, " +
+		"usage store not available")
+
+	// ErrUnlimitedSpend is returned if a macaroon with limit caveats is
+	// used to call a method that can move funds, but has no spend parser
+	// to account for them.
+	ErrUnlimitedSpend = errors.New("method is not allowed with a " +
+		"spending limited macaroon")
+
+	// spendOps are the permissions of methods that can move funds. Unless
+	// such a method has a spend parser, it can't be called with a macaroon
+	// that carries limit caveats.
+	spendOps = []bakery.Op{
+		{Entity: "onchain", Action: "write"},
+		{Entity: "offchain", Action: "write"},
+		{Entity: "signer", Action: "generate"},
+	}
+)
+
+// SpendRequest describes the funds an RPC request is about to send.
+type SpendRequest struct {
+	// Amount is the amount that is sent, including the maximum fees that
+	// can be paid for it.
+	Amount lnwire.MilliSatoshi
+
+	// Destination is the node the funds are sent to. It is nil if the
+	// funds are sent on-chain.
+	Destination *route.Vertex
+}
+
+// SpendResult is the outcome of a spend as reported by an RPC response.
+type SpendResult uint8
+
+const (
+	// SpendPending means the response doesn't settle the outcome of the
+	// spend yet.
+	SpendPending SpendResult = iota
+
+	// SpendSucceeded means the funds were sent.
+	SpendSucceeded
+
+	// SpendFailed means the funds were not sent.
+	SpendFailed
+)
+
+// SpendParser extracts the funds an RPC method sends from its requests and the
+// outcome of the spend from its responses.
+type SpendParser struct {
+	// ParseRequest returns the funds the given request sends. A nil
+	// SpendRequest means the request doesn't send any funds.
+	ParseRequest func(req proto.Message) (*SpendRequest, error)
+
+	// ParseResponse returns the outcome of the oldest unsettled spend of
+	// the call. If it is nil, every response marks the spend as
+	// succeeded.
+	ParseResponse func(resp proto.Message) SpendResult
+}
+
+// AddMacaroonUsageStore adds the store that keeps track of the usage of
+// macaroons with spending or rate limit caveats to the interceptor.
+func (r *InterceptorChain) AddMacaroonUsageStore(store *macaroons.UsageStore) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.usageStore = store
+}
+
+// MacaroonUsageStore returns the currently registered macaroon usage store.
+// This might be nil if none was registered (yet).
+func (r *InterceptorChain) MacaroonUsageStore() *macaroons.UsageStore {
+	r.RLock()
+	defer r.RUnlock()
+
+	return r.usageStore
+}
+
+// AddSpendParser adds the parser that is used to enforce the spending limits
+// of macaroons for the given method.
+func (r *InterceptorChain) AddSpendParser(method string,
+	parser *SpendParser) error {
+
+	r.Lock()
+	defer r.Unlock()
+
+	if _, ok := r.spendParsers[method]; ok {
+		return fmt.Errorf("detected duplicate spend parser for path: "+
+			"%v", method)
+	}
+
+	r.spendParsers[method] = parser
+	return nil
+}
+
+// macaroonLimiter enforces the limits of the macaroon of a single call.
+type macaroonLimiter struct {
+	store     *macaroons.UsageStore
+	rootKeyID []byte
+	limits    *macaroons.Limits
+
+	// parser is the spend parser of the called method. It is nil if the
+	// method doesn't send any funds.
+	parser *SpendParser
+
+	// pending are the spend reservations of the call that are not settled
+	// yet, oldest first.
+	pending []*macaroons.SpendReservation
+	mu      sync.Mutex
+}
+
+// newMacaroonLimiter records the call in the usage of the macaroon in the
+// given context and returns a limiter for the rest of the call. If the
+// macaroon doesn't carry any limits, nil is returned.
+func (r *InterceptorChain) newMacaroonLimiter(ctx context.Context,
+	fullMethod string) (*macaroonLimiter, error) {
+
+	if r.noMacaroons {
+		return nil, nil
+	}
+	if _, ok := macaroonWhitelist[fullMethod]; ok {
+		return nil, nil
+	}
+
+	mac, _, err := macaroonFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	limits, err := macaroons.ParseLimits(mac)
+	if err != nil {
+		return nil, err
+	}
+	if limits == nil {
+		return nil, nil
+	}
+
+	r.RLock()
+	store := r.usageStore
+	parser := r.spendParsers[fullMethod]
+	ops := r.permissionMap[fullMethod]
+	r.RUnlock()
+
+	if store == nil {
+		return nil, ErrNoUsageStore
+	}
+
+	// Methods that can move funds are only allowed if their spend parser
+	// tells us how much they send.
+	if parser == nil && movesFunds(ops) {
+		return nil, fmt.Errorf("%w: %v", ErrUnlimitedSpend, fullMethod)
+	}
+
+	rootKeyID, err := rootKeyIDFromMacaroon(mac)
+	if err != nil {
+		return nil, err
+	}
+
+	err = store.RecordCall(rootKeyID, limits.MaxCallsPerMinute, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return &macaroonLimiter{
+		store:     store,
+		rootKeyID: rootKeyID,
+		limits:    limits,
+		parser:    parser,
+	}, nil
+}
+
+// movesFunds returns true if any of the given permissions allows to move
+// funds.
+func movesFunds(ops []bakery.Op) bool {
+	for _, op := range ops {
+		for _, spendOp := range spendOps {
+			if op == spendOp {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// checkRequest makes sure the funds sent by the given request are within the
+// limits of the macaroon and reserves them from its spending budget.
+func (l *macaroonLimiter) checkRequest(req interface{}) error {
+	if l.parser == nil {
+		return nil
+	}
+
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return fmt.Errorf("unexpected request type %T", req)
+	}
+
+	spend, err := l.parser.ParseRequest(msg)
+	if err != nil {
+		return err
+	}
+	if spend == nil {
+		return nil
+	}
+
+	err = l.limits.CheckPayment(spend.Amount, spend.Destination)
+	if err != nil {
+		return err
+	}
+
+	reservation, err := l.store.ReserveSpend(
+		l.rootKeyID, l.limits, spend.Amount, time.Now(),
+	)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	l.pending = append(l.pending, reservation)
+	l.mu.Unlock()
+
+	return nil
+}
+
+// checkResponse settles the oldest pending spend of the call if the given
+// response reports its outcome. The reserved amount is given back to the
+// spending budget if the spend failed.
+func (l *macaroonLimiter) checkResponse(resp interface{}) error {
+	if l.parser == nil {
+		return nil
+	}
+
+	result := SpendSucceeded
+	if l.parser.ParseResponse != nil {
+		msg, ok := resp.(proto.Message)
+		if !ok {
+			return fmt.Errorf("unexpected response type %T", resp)
+		}
+
+		result = l.parser.ParseResponse(msg)
+	}
+
+	if result == SpendPending {
+		return nil
+	}
+
+	l.mu.Lock()
+	if len(l.pending) == 0 {
+		l.mu.Unlock()
+		return nil
+	}
+	reservation := l.pending[0]
+	l.pending = l.pending[1:]
+	l.mu.Unlock()
+
+	if result == SpendFailed {
+		return l.store.ReleaseSpend(reservation)
+	}
+
+	return nil
+}
+
+// releaseAll gives all pending spends of the call back to the spending budget.
+func (l *macaroonLimiter) releaseAll() error {
+	l.mu.Lock()
+	pending := l.pending
+	l.pending = nil
+	l.mu.Unlock()
+
+	for _, reservation := range pending {
+		if err := l.store.ReleaseSpend(reservation); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// macaroonLimitsUnaryServerInterceptor is a unary gRPC interceptor that
+// enforces the spending and rate limit caveats of the macaroon of a request.
+func (r *InterceptorChain) macaroonLimitsUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+
+		limiter, err := r.newMacaroonLimiter(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		if limiter == nil {
+			return handler(ctx, req)
+		}
+
+		if err := limiter.checkRequest(req); err != nil {
+			return nil, err
+		}
+
+		resp, lndErr := handler(ctx, req)
+
+		// A unary call that failed didn't send any funds, so we can
+		// give the reserved amount back.
+		if lndErr != nil {
+			if err := limiter.releaseAll(); err != nil {
+				log.Errorf("Unable to release macaroon spend: "+
+					"%v", err)
+			}
+
+			return resp, lndErr
+		}
+
+		if err := limiter.checkResponse(resp); err != nil {
+			log.Errorf("Unable to settle macaroon spend: %v", err)
+		}
+
+		return resp, nil
+	}
+}
+
+// macaroonLimitsStreamServerInterceptor is a streaming gRPC interceptor that
+// enforces the spending and rate limit caveats of the macaroon of a stream.
+//
+// NOTE: Spends of a stream are only given back to the spending budget if a
+// response explicitly reports them as failed. If the stream terminates early,
+// for example because the client disconnected, the payment might still be in
+// flight, so the reserved amount stays spent.
+func (r *InterceptorChain) macaroonLimitsStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream,
+		info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+		limiter, err := r.newMacaroonLimiter(
+			ss.Context(), info.FullMethod,
+		)
+		if err != nil {
+			return err
+		}
+		if limiter == nil {
+			return handler(srv, ss)
+		}
+
+		return handler(srv, &limitedServerStream{
+			ServerStream: ss,
+			limiter:      limiter,
+		})
+	}
+}
+
+// limitedServerStream wraps a server stream to enforce the limits of a
+// macaroon on every message that is sent over it.
+type limitedServerStream struct {
+	grpc.ServerStream
+
+	limiter *macaroonLimiter
+}
+
+// SendMsg is called when lnd sends a message to the client.
+func (s *limitedServerStream) SendMsg(m interface{}) error {
+	if err := s.limiter.checkResponse(m); err != nil {
+		log.Errorf("Unable to settle macaroon spend: %v", err)
+	}
+
+	return s.ServerStream.SendMsg(m)
+}
+
+// RecvMsg is called when lnd wants to receive a message from the client.
+func (s *limitedServerStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	return s.limiter.checkRequest(m)
+}
+
+// rootKeyIDFromMacaroon extracts the root key ID from the identifier of the
+// given macaroon.
+func rootKeyIDFromMacaroon(mac *macaroon.Macaroon) ([]byte, error) {
+	rawID := mac.Id()
+	if len(rawID) == 0 || rawID[0] != byte(bakery.LatestVersion) {
+		return nil, fmt.Errorf("invalid macaroon version: %x", rawID)
+	}
+
+	decodedID := &lnrpc.MacaroonId{}
+	if err := proto.Unmarshal(rawID[1:], decodedID); err != nil {
+		return nil, fmt.Errorf("unable to decode macaroon ID: %w", err)
+	}
+	if len(decodedID.StorageId) == 0 {
+		return nil, macaroons.ErrMissingRootKeyID
+	}
+
+	return decodedID.StorageId, nil
+}
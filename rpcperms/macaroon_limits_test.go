@@ -0,0 +1,184 @@
+package rpcperms
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btclog"
+	"github.com/lightningnetwork/lnd/kvdb"
+	"github.com/lightningnetwork/lnd/lnrpc"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/macaroons"
+	"github.com/lightningnetwork/lnd/routing/route"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/macaroon-bakery.v2/bakery"
+	"gopkg.in/macaroon.v2"
+)
+
+const testSendMethod = "/lnrpc.Lightning/SendPaymentSync"
+
+// newLimitedContext returns a context carrying a macaroon with the given root
+// key ID and constraints.
+func newLimitedContext(t *testing.T, rootKeyID string,
+	cs ...macaroons.Constraint) context.Context {
+
+	idBytes, err := proto.Marshal(&lnrpc.MacaroonId{
+		StorageId: []byte(rootKeyID),
+	})
+	require.NoError(t, err)
+	id := append([]byte{byte(bakery.LatestVersion)}, idBytes...)
+
+	mac, err := macaroon.New(
+		[]byte("root-key"), id, "lnd", macaroon.LatestVersion,
+	)
+	require.NoError(t, err)
+
+	mac, err = macaroons.AddConstraints(mac, cs...)
+	require.NoError(t, err)
+
+	macBytes, err := mac.MarshalBinary()
+	require.NoError(t, err)
+
+	return metadata.NewIncomingContext(
+		context.Background(),
+		metadata.Pairs("macaroon", hex.EncodeToString(macBytes)),
+	)
+}
+
+// TestMacaroonLimitsInterceptor tests that the spending and rate limit caveats
+// of a macaroon are enforced for unary calls.
+func TestMacaroonLimitsInterceptor(t *testing.T) {
+	t.Parallel()
+
+	db, err := kvdb.Create(
+		kvdb.BoltBackendName, path.Join(t.TempDir(), "usage.db"), true,
+		kvdb.DefaultDBTimeout,
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, db.Close())
+	})
+
+	store, err := macaroons.NewUsageStore(db)
+	require.NoError(t, err)
+
+	chain := NewInterceptorChain(btclog.Disabled, false, nil)
+	chain.AddMacaroonUsageStore(store)
+
+	dest := route.Vertex{1}
+	err = chain.AddSpendParser(testSendMethod, &SpendParser{
+		ParseRequest: func(req proto.Message) (*SpendRequest, error) {
+			r := req.(*lnrpc.SendRequest)
+
+			return &SpendRequest{
+				Amount:      lnwire.MilliSatoshi(r.AmtMsat),
+				Destination: &dest,
+			}, nil
+		},
+		ParseResponse: func(resp proto.Message) SpendResult {
+			if resp.(*lnrpc.SendResponse).PaymentError != "" {
+				return SpendFailed
+			}
+
+			return SpendSucceeded
+		},
+	})
+	require.NoError(t, err)
+
+	interceptor := chain.macaroonLimitsUnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: testSendMethod}
+
+	var (
+		paymentErr string
+		handlerErr error
+	)
+	handler := func(context.Context, interface{}) (interface{}, error) {
+		return &lnrpc.SendResponse{PaymentError: paymentErr}, handlerErr
+	}
+	send := func(ctx context.Context, amt int64) error {
+		_, err := interceptor(
+			ctx, &lnrpc.SendRequest{AmtMsat: amt}, info, handler,
+		)
+		return err
+	}
+
+	ctx := newLimitedContext(
+		t, "1", macaroons.SpendLimitConstraint(1_000, time.Hour),
+		macaroons.MaxPaymentConstraint(600),
+		macaroons.DestinationsConstraint(dest),
+	)
+
+	// A payment above the maximum payment amount is rejected.
+	require.ErrorIs(t, send(ctx, 700), macaroons.ErrMaxPaymentExceeded)
+
+	// Failed payments don't count against the budget, no matter whether
+	// they fail with an error or report the failure in the response.
+	handlerErr = errors.New("payment failed")
+	require.ErrorIs(t, send(ctx, 600), handlerErr)
+	handlerErr = nil
+
+	paymentErr = "no route"
+	require.NoError(t, send(ctx, 600))
+	paymentErr = ""
+
+	// Successful payments are counted until the budget is exhausted.
+	require.NoError(t, send(ctx, 600))
+	require.NoError(t, send(ctx, 400))
+	require.ErrorIs(t, send(ctx, 1), macaroons.ErrSpendLimitExceeded)
+
+	usage, err := store.FetchUsage([]byte("1"))
+	require.NoError(t, err)
+	require.Equal(t, lnwire.MilliSatoshi(1_000), usage.WindowSpent)
+	require.Equal(t, uint64(2), usage.NumPayments)
+	require.Equal(t, uint64(6), usage.TotalCalls)
+
+	// A macaroon with a rate limit is rejected once it made too many calls
+	// within a minute.
+	ctx = newLimitedContext(t, "2", macaroons.RateLimitConstraint(2))
+	require.NoError(t, send(ctx, 1))
+	require.NoError(t, send(ctx, 1))
+	require.ErrorIs(t, send(ctx, 1), macaroons.ErrRateLimitExceeded)
+
+	// Methods that can move funds, but have no spend parser, are rejected
+	// for limited macaroons, while all other methods are allowed.
+	const (
+		testOpenMethod = "/lnrpc.Lightning/OpenChannelSync"
+		testInfoMethod = "/lnrpc.Lightning/GetInfo"
+	)
+	require.NoError(t, chain.AddPermission(testOpenMethod, []bakery.Op{{
+		Entity: "onchain",
+		Action: "write",
+	}}))
+	require.NoError(t, chain.AddPermission(testInfoMethod, []bakery.Op{{
+		Entity: "info",
+		Action: "read",
+	}}))
+
+	call := func(ctx context.Context, method string) error {
+		_, err := interceptor(
+			ctx, &lnrpc.SendRequest{}, &grpc.UnaryServerInfo{
+				FullMethod: method,
+			}, handler,
+		)
+		return err
+	}
+
+	ctx = newLimitedContext(t, "4", macaroons.RateLimitConstraint(10))
+	require.ErrorIs(t, call(ctx, testOpenMethod), ErrUnlimitedSpend)
+	require.NoError(t, call(ctx, testInfoMethod))
+
+	// A macaroon without limits isn't tracked at all.
+	ctx = newLimitedContext(t, "3")
+	require.NoError(t, send(ctx, 1_000_000))
+
+	usage, err = store.FetchUsage([]byte("3"))
+	require.NoError(t, err)
+	require.Equal(t, &macaroons.Usage{}, usage)
+}
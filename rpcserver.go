@@ -31,6 +31,7 @@ import (
 	"github.com/btcsuite/btcwallet/wallet/txauthor"
 	"github.com/davecgh/go-spew/spew"
 	proxy "github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/lightningnetwork/lnd/accounting"
 	"github.com/lightningnetwork/lnd/autopilot"
 	"github.com/lightningnetwork/lnd/build"
 	"github.com/lightningnetwork/lnd/chainreg"
@@ -38,10 +39,13 @@ import (
 	"github.com/lightningnetwork/lnd/chanbackup"
 	"github.com/lightningnetwork/lnd/chanfitness"
 	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/channeldb/models"
 	"github.com/lightningnetwork/lnd/channelnotifier"
 	"github.com/lightningnetwork/lnd/contractcourt"
 	"github.com/lightningnetwork/lnd/discovery"
+	"github.com/lightningnetwork/lnd/eventlog"
 	"github.com/lightningnetwork/lnd/feature"
+	"github.com/lightningnetwork/lnd/feemanager"
 	"github.com/lightningnetwork/lnd/funding"
 	"github.com/lightningnetwork/lnd/htlcswitch"
 	"github.com/lightningnetwork/lnd/htlcswitch/hop"
@@ -73,11 +77,13 @@ import (
 	"github.com/lightningnetwork/lnd/sweep"
 	"github.com/lightningnetwork/lnd/tlv"
 	"github.com/lightningnetwork/lnd/watchtower"
+	"github.com/lightningnetwork/lnd/webhook"
 	"github.com/lightningnetwork/lnd/zpay32"
 	"github.com/tv42/zbase32"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 	"gopkg.in/macaroon-bakery.v2/bakery"
 )
 
@@ -361,6 +367,10 @@ func MainRPCServerPermissions() map[string][]bakery.Op {
 			Entity: "offchain",
 			Action: "read",
 		}},
+		"/lnrpc.Lightning/SubscribeChannelResolutions": {{
+			Entity: "offchain",
+			Action: "read",
+		}},
 		"/lnrpc.Lightning/ClosedChannels": {{
 			Entity: "offchain",
 			Action: "read",
@@ -465,6 +475,48 @@ func MainRPCServerPermissions() map[string][]bakery.Op {
 			Entity: "offchain",
 			Action: "write",
 		}},
+		"/lnrpc.Lightning/UpdateChanParams": {{
+			Entity: "offchain",
+			Action: "write",
+		}},
+		"/lnrpc.Lightning/FeeManagerStatus": {{
+			Entity: "offchain",
+			Action: "read",
+		}},
+		"/lnrpc.Lightning/UpdateFeeManager": {{
+			Entity: "offchain",
+			Action: "write",
+		}},
+		"/lnrpc.Lightning/AddWebhook": {{
+			Entity: "offchain",
+			Action: "write",
+		}, {
+			Entity: "invoices",
+			Action: "read",
+		}},
+		"/lnrpc.Lightning/RemoveWebhook": {{
+			Entity: "offchain",
+			Action: "write",
+		}},
+		"/lnrpc.Lightning/ListWebhooks": {{
+			Entity: "offchain",
+			Action: "read",
+		}},
+		"/lnrpc.Lightning/ListWebhookDeliveries": {{
+			Entity: "offchain",
+			Action: "read",
+		}},
+		"/lnrpc.Lightning/RetryWebhookDelivery": {{
+			Entity: "offchain",
+			Action: "write",
+		}},
+		"/lnrpc.Lightning/AccountingReport": {{
+			Entity: "onchain",
+			Action: "read",
+		}, {
+			Entity: "offchain",
+			Action: "read",
+		}},
 		"/lnrpc.Lightning/ForwardingHistory": {{
 			Entity: "offchain",
 			Action: "read",
@@ -508,6 +560,10 @@ func MainRPCServerPermissions() map[string][]bakery.Op {
 			Entity: "macaroon",
 			Action: "write",
 		}},
+		"/lnrpc.Lightning/GetMacaroonUsage": {{
+			Entity: "macaroon",
+			Action: "read",
+		}},
 		"/lnrpc.Lightning/ListPermissions": {{
 			Entity: "info",
 			Action: "read",
@@ -600,9 +656,26 @@ type rpcServer struct {
 	// interceptor is used to be able to request a shutdown
 	interceptor signal.Interceptor
 
+	// restDialOpts and restProxyDest are the dial options and address the
+	// REST proxy uses to connect to our gRPC server. They allow
+	// sub-servers to call our own RPCs through the full interceptor
+	// chain.
+	restDialOpts  []grpc.DialOption
+	restProxyDest string
+
 	graphCache        sync.RWMutex
 	describeGraphResp *lnrpc.ChannelGraph
 	graphCacheEvictor *time.Timer
+
+	// channelEventLog, htlcEventLog, peerEventLog and txEventLog are the
+	// persistent logs of the events served by the subscription RPCs.
+	// They are nil if the event log is disabled.
+	channelEventLog *eventlog.Log
+	htlcEventLog    *eventlog.Log
+	peerEventLog    *eventlog.Log
+	txEventLog      *eventlog.Log
+
+	wg sync.WaitGroup
 }
 
 // A compile time check to ensure that rpcServer fully implements the
@@ -652,6 +725,13 @@ func (r *rpcServer) addDeps(s *server, macService *macaroons.Service,
 	}
 	graph := s.graphDB
 
+	// Create the event logs of the subscription RPCs, if enabled.
+	if r.cfg.EventLogSize > 0 {
+		if err := r.createEventLogs(s); err != nil {
+			return err
+		}
+	}
+
 	routerBackend := &routerrpc.RouterBackend{
 		SelfNode: selfNode.PubKeyBytes,
 		FetchChannelCapacity: func(chanID uint64) (btcutil.Amount,
@@ -707,6 +787,7 @@ func (r *rpcServer) addDeps(s *server, macService *macaroons.Service,
 		MaxTotalTimelock:       r.cfg.MaxOutgoingCltvExpiry,
 		DefaultFinalCltvDelta:  uint16(r.cfg.Bitcoin.TimeLockDelta),
 		SubscribeHtlcEvents:    s.htlcNotifier.SubscribeHtlcEvents,
+		HtlcEventLog:           r.htlcEventLog,
 		InterceptableForwarder: s.interceptableSwitch,
 		SetChannelEnabled: func(outpoint wire.OutPoint) error {
 			return s.chanStatusMgr.RequestEnable(outpoint, true)
@@ -715,6 +796,10 @@ func (r *rpcServer) addDeps(s *server, macService *macaroons.Service,
 			return s.chanStatusMgr.RequestDisable(outpoint, true)
 		},
 		SetChannelAuto: s.chanStatusMgr.RequestAuto,
+		AddInvoice:     s.invoices.AddInvoice,
+		GenInvoiceFeatures: func() *lnwire.FeatureVector {
+			return s.featureMgr.Get(feature.SetInvoice)
+		},
 	}
 
 	genInvoiceFeatures := func() *lnwire.FeatureVector {
@@ -728,6 +813,17 @@ func (r *rpcServer) addDeps(s *server, macService *macaroons.Service,
 		return parseAddr(addr, r.cfg.net)
 	}
 
+	// dialSelf connects to our own gRPC server the same way the REST
+	// proxy does, so that sub-servers can execute RPCs on behalf of
+	// their clients with macaroon enforcement in place.
+	dialSelf := func() (*grpc.ClientConn, error) {
+		if r.restProxyDest == "" {
+			return nil, fmt.Errorf("RPC server address unknown")
+		}
+
+		return grpc.Dial(r.restProxyDest, r.restDialOpts...)
+	}
+
 	var (
 		subServers     []lnrpc.SubServer
 		subServerPerms []lnrpc.MacaroonPerms
@@ -746,7 +842,8 @@ func (r *rpcServer) addDeps(s *server, macService *macaroons.Service,
 		r.cfg.net.ResolveTCPAddr, genInvoiceFeatures,
 		genAmpInvoiceFeatures, s.getNodeAnnouncement,
 		s.updateAndBrodcastSelfNode, parseAddr, rpcsLog,
-		s.aliasMgr.GetPeerAlias,
+		s.aliasMgr.GetPeerAlias, dialSelf, s.accountStore,
+		s.labelStore,
 	)
 	if err != nil {
 		return err
@@ -788,6 +885,22 @@ func (r *rpcServer) addDeps(s *server, macService *macaroons.Service,
 		}
 	}
 
+	// Macaroons that are bound to an account are restricted to the balance,
+	// invoices and payments of the account.
+	if s.accounts != nil {
+		r.interceptorChain.AddAccountInterceptor(s.accounts)
+	}
+
+	// The spending limit caveats of macaroons are enforced by parsing the
+	// requests of all RPCs that send funds.
+	spendParsers := macaroonSpendParsers(r.cfg.ActiveNetParams.Params)
+	for method, parser := range spendParsers {
+		err := r.interceptorChain.AddSpendParser(method, parser)
+		if err != nil {
+			return err
+		}
+	}
+
 	// External subserver possibly need to register their own permissions
 	// and macaroon validator.
 	for method, ops := range r.implCfg.ExternalValidator.Permissions() {
@@ -837,6 +950,88 @@ func (r *rpcServer) addDeps(s *server, macService *macaroons.Service,
 	return nil
 }
 
+// createEventLogs creates the persistent event logs of the subscription RPCs
+// and lets the notifiers record their events in them.
+func (r *rpcServer) createEventLogs(s *server) error {
+	newEventLog := func(stream string,
+		encode eventlog.Encoder) (*eventlog.Log, error) {
+
+		return eventlog.New(&eventlog.Config{
+			DB:        s.chanStateDB.GetParentDB(),
+			Stream:    stream,
+			MaxEvents: r.cfg.EventLogSize,
+			Encode:    encode,
+		})
+	}
+
+	var err error
+	r.channelEventLog, err = newEventLog(
+		"channel", func(event interface{}) ([]byte, error) {
+			update, err := r.rpcChannelEventUpdate(event)
+			if err != nil || update == nil {
+				return nil, err
+			}
+
+			return proto.Marshal(update)
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	r.htlcEventLog, err = newEventLog("htlc", routerrpc.MarshalHtlcEvent)
+	if err != nil {
+		return err
+	}
+
+	r.peerEventLog, err = newEventLog(
+		"peer", func(event interface{}) ([]byte, error) {
+			peerEvent, err := rpcPeerEvent(event)
+			if err != nil {
+				return nil, err
+			}
+
+			return proto.Marshal(peerEvent)
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	r.txEventLog, err = newEventLog(
+		"transaction", func(event interface{}) ([]byte, error) {
+			tx, ok := event.(*lnwallet.TransactionDetail)
+			if !ok {
+				return nil, fmt.Errorf("unexpected transaction "+
+					"event: %T", event)
+			}
+
+			return proto.Marshal(lnrpc.RPCTransaction(tx))
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	s.channelNotifier.SetEventRecorder(r.channelEventLog)
+	s.htlcNotifier.SetEventRecorder(r.htlcEventLog)
+	s.peerNotifier.SetEventRecorder(r.peerEventLog)
+
+	return nil
+}
+
+// eventLogs returns the event logs of the subscription RPCs, or nil if the
+// event log is disabled.
+func (r *rpcServer) eventLogs() []*eventlog.Log {
+	if r.channelEventLog == nil {
+		return nil
+	}
+
+	return []*eventlog.Log{
+		r.channelEventLog, r.htlcEventLog, r.peerEventLog, r.txEventLog,
+	}
+}
+
 // RegisterWithGrpcServer registers the rpcServer and any subservers with the
 // root gRPC server.
 func (r *rpcServer) RegisterWithGrpcServer(grpcServer *grpc.Server) error {
@@ -875,6 +1070,26 @@ func (r *rpcServer) Start() error {
 		return nil
 	}
 
+	// Start the event logs before the notifiers start recording events
+	// in them.
+	for _, eventLog := range r.eventLogs() {
+		if err := eventLog.Start(); err != nil {
+			return err
+		}
+	}
+
+	// The wallet has no notifier of its own, so we record its
+	// transactions ourselves.
+	if r.txEventLog != nil {
+		txClient, err := r.server.cc.Wallet.SubscribeTransactions()
+		if err != nil {
+			return err
+		}
+
+		r.wg.Add(1)
+		go r.recordTransactions(txClient)
+	}
+
 	// First, we'll start all the sub-servers to ensure that they're ready
 	// to take new requests in.
 	//
@@ -897,6 +1112,9 @@ func (r *rpcServer) RegisterWithRestProxy(restCtx context.Context,
 	restMux *proxy.ServeMux, restDialOpts []grpc.DialOption,
 	restProxyDest string) error {
 
+	r.restDialOpts = restDialOpts
+	r.restProxyDest = restProxyDest
+
 	// With our custom REST proxy mux created, register our main RPC and
 	// give all subservers a chance to register as well.
 	err := lnrpc.RegisterLightningHandlerFromEndpoint(
@@ -962,6 +1180,16 @@ func (r *rpcServer) Stop() error {
 		}
 	}
 
+	r.wg.Wait()
+
+	// The notifiers have been stopped together with the server, so no
+	// more events are recorded.
+	for _, eventLog := range r.eventLogs() {
+		if err := eventLog.Stop(); err != nil {
+			rpcsLog.Errorf("unable to stop event log: %v", err)
+		}
+	}
+
 	return nil
 }
 
@@ -2167,6 +2395,24 @@ func (r *rpcServer) parseOpenChannelReq(in *lnrpc.OpenChannelRequest,
 
 		*channelType = lnwire.ChannelType(*fv)
 
+	case lnrpc.CommitmentType_ZERO_FEE_COMMITMENTS:
+		channelType = new(lnwire.ChannelType)
+		fv := lnwire.NewRawFeatureVector(
+			lnwire.StaticRemoteKeyRequired,
+			lnwire.AnchorsZeroFeeHtlcTxRequired,
+			lnwire.ZeroFeeCommitmentsRequired,
+		)
+
+		if in.ZeroConf {
+			fv.Set(lnwire.ZeroConfRequired)
+		}
+
+		if in.ScidAlias {
+			fv.Set(lnwire.ScidAliasRequired)
+		}
+
+		*channelType = lnwire.ChannelType(*fv)
+
 	default:
 		return nil, fmt.Errorf("unhandled request channel type %v",
 			in.CommitmentType)
@@ -3174,10 +3420,32 @@ func (r *rpcServer) ListPeers(ctx context.Context,
 }
 
 // SubscribePeerEvents returns a uni-directional stream (server -> client)
-// for notifying the client of peer online and offline events.
+// for notifying the client of peer online and offline events. If the event
+// log is enabled, the events are served from the log, which allows the client
+// to replay the events it missed.
 func (r *rpcServer) SubscribePeerEvents(req *lnrpc.PeerEventSubscription,
 	eventStream lnrpc.Lightning_SubscribePeerEventsServer) error {
 
+	if r.peerEventLog != nil {
+		return r.streamEventLog(
+			eventStream.Context(), r.peerEventLog, req.Replay,
+			req.StartAfterSequence, func(e *eventlog.Event) error {
+				event := &lnrpc.PeerEvent{}
+				err := proto.Unmarshal(e.Payload, event)
+				if err != nil {
+					return err
+				}
+				event.Sequence = e.Sequence
+
+				return eventStream.Send(event)
+			},
+		)
+	}
+
+	if req.Replay {
+		return eventlog.ErrLogDisabled
+	}
+
 	peerEventSub, err := r.server.peerNotifier.SubscribePeerEvents()
 	if err != nil {
 		return err
@@ -3190,23 +3458,9 @@ func (r *rpcServer) SubscribePeerEvents(req *lnrpc.PeerEventSubscription,
 		// marshal it into the form expected by the gRPC client, then
 		// send it off to the client.
 		case e := <-peerEventSub.Updates():
-			var event *lnrpc.PeerEvent
-
-			switch peerEvent := e.(type) {
-			case peernotifier.PeerOfflineEvent:
-				event = &lnrpc.PeerEvent{
-					PubKey: hex.EncodeToString(peerEvent.PubKey[:]),
-					Type:   lnrpc.PeerEvent_PEER_OFFLINE,
-				}
-
-			case peernotifier.PeerOnlineEvent:
-				event = &lnrpc.PeerEvent{
-					PubKey: hex.EncodeToString(peerEvent.PubKey[:]),
-					Type:   lnrpc.PeerEvent_PEER_ONLINE,
-				}
-
-			default:
-				return fmt.Errorf("unexpected peer event: %v", event)
+			event, err := rpcPeerEvent(e)
+			if err != nil {
+				return err
 			}
 
 			if err := eventStream.Send(event); err != nil {
@@ -3228,6 +3482,27 @@ func (r *rpcServer) SubscribePeerEvents(req *lnrpc.PeerEventSubscription,
 	}
 }
 
+// rpcPeerEvent converts a peer notifier event into the event sent to peer
+// event subscribers.
+func rpcPeerEvent(e interface{}) (*lnrpc.PeerEvent, error) {
+	switch peerEvent := e.(type) {
+	case peernotifier.PeerOfflineEvent:
+		return &lnrpc.PeerEvent{
+			PubKey: hex.EncodeToString(peerEvent.PubKey[:]),
+			Type:   lnrpc.PeerEvent_PEER_OFFLINE,
+		}, nil
+
+	case peernotifier.PeerOnlineEvent:
+		return &lnrpc.PeerEvent{
+			PubKey: hex.EncodeToString(peerEvent.PubKey[:]),
+			Type:   lnrpc.PeerEvent_PEER_ONLINE,
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unexpected peer event: %v", e)
+	}
+}
+
 // WalletBalance returns total unspent outputs(confirmed and unconfirmed), all
 // confirmed unspent outputs and all unconfirmed unspent outputs under control
 // by the wallet. This method can be modified by having the request specify
@@ -3463,6 +3738,16 @@ func (r *rpcServer) fetchPendingOpenChannels() (pendingOpenChannels, error) {
 		return nil, err
 	}
 
+	// We'll also fetch the inputs the sweeper is currently sweeping, so
+	// we can report any fee bumps of the funding transactions. As this is
+	// only informational, we don't fail the whole call if the sweeper
+	// can't be queried, but leave the fee bump details empty instead.
+	pendingInputs, err := r.server.sweeper.PendingInputs()
+	if err != nil {
+		rpcsLog.Warnf("Unable to fetch pending sweeper inputs: %v",
+			err)
+	}
+
 	result := make(pendingOpenChannels, len(channels))
 	for i, pendingChan := range channels {
 		pub := pendingChan.IdentityPub.SerializeCompressed()
@@ -3510,6 +3795,9 @@ func (r *rpcServer) fetchPendingOpenChannels() (pendingOpenChannels, error) {
 			CommitFee:           int64(localCommitment.CommitFee),
 			FeePerKw:            int64(localCommitment.FeePerKw),
 			FundingExpiryBlocks: fundingExpiryBlocks,
+			FundingFeeBump: rpcFundingFeeBump(
+				pendingChan.FundingOutpoint, pendingInputs,
+			),
 			// TODO(roasbeef): need to track confirmation height
 		}
 	}
@@ -3517,6 +3805,37 @@ func (r *rpcServer) fetchPendingOpenChannels() (pendingOpenChannels, error) {
 	return result, nil
 }
 
+// rpcFundingFeeBump returns the CPFP fee bump of the funding transaction of
+// the channel with the given channel point, if the sweeper is currently
+// sweeping one of the other outputs of the funding transaction. Nil is
+// returned otherwise.
+func rpcFundingFeeBump(chanPoint wire.OutPoint,
+	pendingInputs map[wire.OutPoint]*sweep.PendingInput,
+) *lnrpc.PendingChannelsResponse_FundingFeeBump {
+
+	for op, pendingInput := range pendingInputs {
+		if op.Hash != chanPoint.Hash || op == chanPoint {
+			continue
+		}
+
+		lastFeeRate := pendingInput.LastFeeRate.FeePerKVByte() / 1000
+		requestedFee := pendingInput.Params.Fee
+		requestedFeeRate := requestedFee.FeeRate.FeePerKVByte() / 1000
+
+		return &lnrpc.PendingChannelsResponse_FundingFeeBump{
+			Outpoint:    op.String(),
+			SatPerVbyte: uint64(lastFeeRate),
+			BroadcastAttempts: uint32(
+				pendingInput.BroadcastAttempts,
+			),
+			RequestedSatPerVbyte: uint64(requestedFeeRate),
+			RequestedConfTarget:  requestedFee.ConfTarget,
+		}
+	}
+
+	return nil
+}
+
 // fetchPendingForceCloseChannels queries the database for a list of channels
 // that have their closing transactions confirmed but not fully resolved yet.
 // The returned result is used in the response of the PendingChannels RPC.
@@ -3937,9 +4256,68 @@ func (r *rpcServer) arbitratorPopulateForceCloseResp(chanPoint *wire.OutPoint,
 		forceClose.RecoveredBalance += int64(report.RecoveredBalance)
 	}
 
+	for _, status := range arbitrator.ResolverStatuses() {
+		forceClose.Resolvers = append(
+			forceClose.Resolvers,
+			marshalResolverStatus(status, currentHeight),
+		)
+	}
+
 	return nil
 }
 
+// resolverKinds maps the contract resolver kinds to their rpc counterpart.
+var resolverKinds = map[contractcourt.ResolverKind]lnrpc.ContractResolverStatus_ResolverKind{
+	contractcourt.ResolverKindUnknown:             lnrpc.ContractResolverStatus_UNKNOWN_RESOLVER,
+	contractcourt.ResolverKindHtlcTimeout:         lnrpc.ContractResolverStatus_HTLC_TIMEOUT,
+	contractcourt.ResolverKindHtlcSuccess:         lnrpc.ContractResolverStatus_HTLC_SUCCESS,
+	contractcourt.ResolverKindHtlcOutgoingContest: lnrpc.ContractResolverStatus_HTLC_OUTGOING_CONTEST,
+	contractcourt.ResolverKindHtlcIncomingContest: lnrpc.ContractResolverStatus_HTLC_INCOMING_CONTEST,
+	contractcourt.ResolverKindCommitSweep:         lnrpc.ContractResolverStatus_COMMIT_SWEEP,
+	contractcourt.ResolverKindAnchor:              lnrpc.ContractResolverStatus_ANCHOR,
+	contractcourt.ResolverKindBreach:              lnrpc.ContractResolverStatus_BREACH,
+}
+
+// resolverWaitEvents maps the events a contract resolver can wait for to
+// their rpc counterpart.
+var resolverWaitEvents = map[contractcourt.ResolverWaitEvent]lnrpc.ContractResolverStatus_AwaitingEvent{
+	contractcourt.ResolverWaitNone:            lnrpc.ContractResolverStatus_NONE,
+	contractcourt.ResolverWaitHtlcExpiry:      lnrpc.ContractResolverStatus_HTLC_EXPIRY,
+	contractcourt.ResolverWaitPreimage:        lnrpc.ContractResolverStatus_PREIMAGE,
+	contractcourt.ResolverWaitSecondLevelConf: lnrpc.ContractResolverStatus_SECOND_LEVEL_CONF,
+	contractcourt.ResolverWaitCsvMaturity:     lnrpc.ContractResolverStatus_CSV_MATURITY,
+	contractcourt.ResolverWaitSweepConf:       lnrpc.ContractResolverStatus_SWEEP_CONF,
+	contractcourt.ResolverWaitJustice:         lnrpc.ContractResolverStatus_JUSTICE,
+}
+
+// marshalResolverStatus converts the status of a contract resolver into its
+// rpc representation.
+func marshalResolverStatus(status *contractcourt.ResolverStatus,
+	currentHeight int32) *lnrpc.ContractResolverStatus {
+
+	rpcStatus := &lnrpc.ContractResolverStatus{
+		ChannelPoint:     status.ChanPoint.String(),
+		Kind:             resolverKinds[status.Kind],
+		Outpoint:         status.Outpoint.String(),
+		AmountSat:        int64(status.Amount),
+		Stage:            status.Stage,
+		WaitingFor:       resolverWaitEvents[status.WaitingFor],
+		NextActionHeight: status.NextActionHeight,
+		Resolved:         status.Resolved,
+	}
+
+	if status.SweepTxid != nil {
+		rpcStatus.SweepTxid = status.SweepTxid.String()
+	}
+
+	if rpcStatus.NextActionHeight != 0 {
+		rpcStatus.BlocksTilNextAction =
+			int32(rpcStatus.NextActionHeight) - currentHeight
+	}
+
+	return rpcStatus
+}
+
 // nurseryPopulateForceCloseResp populates the pending channels response
 // message with contract resolution information from utxonursery.
 func (r *rpcServer) nurseryPopulateForceCloseResp(chanPoint *wire.OutPoint,
@@ -4181,6 +4559,9 @@ func rpcCommitmentType(chanType channeldb.ChannelType) lnrpc.CommitmentType {
 	case chanType.IsTaproot():
 		return lnrpc.CommitmentType_SIMPLE_TAPROOT
 
+	case chanType.HasZeroFeeCommitment():
+		return lnrpc.CommitmentType_ZERO_FEE_COMMITMENTS
+
 	case chanType.HasLeaseExpiration():
 		return lnrpc.CommitmentType_SCRIPT_ENFORCED_LEASE
 
@@ -4304,6 +4685,12 @@ func createRPCOpenChannel(r *rpcServer, dbChannel *channeldb.OpenChannel,
 		ZeroConf:              dbChannel.IsZeroConf(),
 		ZeroConfConfirmedScid: dbChannel.ZeroConfRealScid().ToUint64(),
 		Memo:                  string(dbChannel.Memo),
+		LocalFeeExposureMsat: uint64(
+			lnwallet.CommittedFeeExposure(dbChannel, false).Total(),
+		),
+		RemoteFeeExposureMsat: uint64(
+			lnwallet.CommittedFeeExposure(dbChannel, true).Total(),
+		),
 		// TODO: remove the following deprecated fields
 		CsvDelay:             uint32(dbChannel.LocalChanCfg.CsvDelay),
 		LocalChanReserveSat:  int64(dbChannel.LocalChanCfg.ChanReserve),
@@ -4695,10 +5082,32 @@ func (r *rpcServer) getInitiators(chanPoint *wire.OutPoint) (
 }
 
 // SubscribeChannelEvents returns a uni-directional stream (server -> client)
-// for notifying the client of newly active, inactive or closed channels.
+// for notifying the client of newly active, inactive or closed channels. If
+// the event log is enabled, the events are served from the log, which allows
+// the client to replay the events it missed.
 func (r *rpcServer) SubscribeChannelEvents(req *lnrpc.ChannelEventSubscription,
 	updateStream lnrpc.Lightning_SubscribeChannelEventsServer) error {
 
+	if r.channelEventLog != nil {
+		return r.streamEventLog(
+			updateStream.Context(), r.channelEventLog, req.Replay,
+			req.StartAfterSequence, func(e *eventlog.Event) error {
+				update := &lnrpc.ChannelEventUpdate{}
+				err := proto.Unmarshal(e.Payload, update)
+				if err != nil {
+					return err
+				}
+				update.Sequence = e.Sequence
+
+				return updateStream.Send(update)
+			},
+		)
+	}
+
+	if req.Replay {
+		return eventlog.ErrLogDisabled
+	}
+
 	channelEventSub, err := r.server.channelNotifier.SubscribeChannelEvents()
 	if err != nil {
 		return err
@@ -4714,97 +5123,14 @@ func (r *rpcServer) SubscribeChannelEvents(req *lnrpc.ChannelEventSubscription,
 		// marshal it into the form expected by the gRPC client, then
 		// send it off to the client(s).
 		case e := <-channelEventSub.Updates():
-			var update *lnrpc.ChannelEventUpdate
-			switch event := e.(type) {
-			case channelnotifier.PendingOpenChannelEvent:
-				update = &lnrpc.ChannelEventUpdate{
-					Type: lnrpc.ChannelEventUpdate_PENDING_OPEN_CHANNEL,
-					Channel: &lnrpc.ChannelEventUpdate_PendingOpenChannel{
-						PendingOpenChannel: &lnrpc.PendingUpdate{
-							Txid:        event.ChannelPoint.Hash[:],
-							OutputIndex: event.ChannelPoint.Index,
-						},
-					},
-				}
-			case channelnotifier.OpenChannelEvent:
-				channel, err := createRPCOpenChannel(
-					r, event.Channel, true, false,
-				)
-				if err != nil {
-					return err
-				}
-
-				update = &lnrpc.ChannelEventUpdate{
-					Type: lnrpc.ChannelEventUpdate_OPEN_CHANNEL,
-					Channel: &lnrpc.ChannelEventUpdate_OpenChannel{
-						OpenChannel: channel,
-					},
-				}
-
-			case channelnotifier.ClosedChannelEvent:
-				closedChannel, err := r.createRPCClosedChannel(
-					event.CloseSummary,
-				)
-				if err != nil {
-					return err
-				}
-
-				update = &lnrpc.ChannelEventUpdate{
-					Type: lnrpc.ChannelEventUpdate_CLOSED_CHANNEL,
-					Channel: &lnrpc.ChannelEventUpdate_ClosedChannel{
-						ClosedChannel: closedChannel,
-					},
-				}
-
-			case channelnotifier.ActiveChannelEvent:
-				update = &lnrpc.ChannelEventUpdate{
-					Type: lnrpc.ChannelEventUpdate_ACTIVE_CHANNEL,
-					Channel: &lnrpc.ChannelEventUpdate_ActiveChannel{
-						ActiveChannel: &lnrpc.ChannelPoint{
-							FundingTxid: &lnrpc.ChannelPoint_FundingTxidBytes{
-								FundingTxidBytes: event.ChannelPoint.Hash[:],
-							},
-							OutputIndex: event.ChannelPoint.Index,
-						},
-					},
-				}
-
-			case channelnotifier.InactiveChannelEvent:
-				update = &lnrpc.ChannelEventUpdate{
-					Type: lnrpc.ChannelEventUpdate_INACTIVE_CHANNEL,
-					Channel: &lnrpc.ChannelEventUpdate_InactiveChannel{
-						InactiveChannel: &lnrpc.ChannelPoint{
-							FundingTxid: &lnrpc.ChannelPoint_FundingTxidBytes{
-								FundingTxidBytes: event.ChannelPoint.Hash[:],
-							},
-							OutputIndex: event.ChannelPoint.Index,
-						},
-					},
-				}
-
-			// Completely ignore ActiveLinkEvent and
-			// InactiveLinkEvent as this is explicitly not exposed
-			// to the RPC.
-			case channelnotifier.ActiveLinkEvent,
-				channelnotifier.InactiveLinkEvent:
+			update, err := r.rpcChannelEventUpdate(e)
+			if err != nil {
+				return err
+			}
 
+			// Some events aren't exposed to the RPC.
+			if update == nil {
 				continue
-
-			case channelnotifier.FullyResolvedChannelEvent:
-				update = &lnrpc.ChannelEventUpdate{
-					Type: lnrpc.ChannelEventUpdate_FULLY_RESOLVED_CHANNEL,
-					Channel: &lnrpc.ChannelEventUpdate_FullyResolvedChannel{
-						FullyResolvedChannel: &lnrpc.ChannelPoint{
-							FundingTxid: &lnrpc.ChannelPoint_FundingTxidBytes{
-								FundingTxidBytes: event.ChannelPoint.Hash[:],
-							},
-							OutputIndex: event.ChannelPoint.Index,
-						},
-					},
-				}
-
-			default:
-				return fmt.Errorf("unexpected channel event update: %v", event)
 			}
 
 			if err := updateStream.Send(update); err != nil {
@@ -4826,20 +5152,195 @@ func (r *rpcServer) SubscribeChannelEvents(req *lnrpc.ChannelEventSubscription,
 	}
 }
 
-// paymentStream enables different types of payment streams, such as:
-// lnrpc.Lightning_SendPaymentServer and lnrpc.Lightning_SendToRouteServer to
-// execute sendPayment. We use this struct as a sort of bridge to enable code
-// re-use between SendPayment and SendToRoute.
-type paymentStream struct {
-	recv func() (*rpcPaymentRequest, error)
-	send func(*lnrpc.SendResponse) error
-}
-
-// rpcPaymentRequest wraps lnrpc.SendRequest so that routes from
-// lnrpc.SendToRouteRequest can be passed to sendPayment.
-type rpcPaymentRequest struct {
-	*lnrpc.SendRequest
-	route *route.Route
+// rpcChannelEventUpdate converts a channel notifier event into the update sent
+// to channel event subscribers. Nil is returned for events that aren't exposed
+// to the RPC.
+func (r *rpcServer) rpcChannelEventUpdate(
+	e interface{}) (*lnrpc.ChannelEventUpdate, error) {
+
+	var update *lnrpc.ChannelEventUpdate
+	switch event := e.(type) {
+	case channelnotifier.PendingOpenChannelEvent:
+		update = &lnrpc.ChannelEventUpdate{
+			Type: lnrpc.ChannelEventUpdate_PENDING_OPEN_CHANNEL,
+			Channel: &lnrpc.ChannelEventUpdate_PendingOpenChannel{
+				PendingOpenChannel: &lnrpc.PendingUpdate{
+					Txid:        event.ChannelPoint.Hash[:],
+					OutputIndex: event.ChannelPoint.Index,
+				},
+			},
+		}
+	case channelnotifier.OpenChannelEvent:
+		channel, err := createRPCOpenChannel(
+			r, event.Channel, true, false,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		update = &lnrpc.ChannelEventUpdate{
+			Type: lnrpc.ChannelEventUpdate_OPEN_CHANNEL,
+			Channel: &lnrpc.ChannelEventUpdate_OpenChannel{
+				OpenChannel: channel,
+			},
+		}
+
+	case channelnotifier.ClosedChannelEvent:
+		closedChannel, err := r.createRPCClosedChannel(
+			event.CloseSummary,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		update = &lnrpc.ChannelEventUpdate{
+			Type: lnrpc.ChannelEventUpdate_CLOSED_CHANNEL,
+			Channel: &lnrpc.ChannelEventUpdate_ClosedChannel{
+				ClosedChannel: closedChannel,
+			},
+		}
+
+	case channelnotifier.ActiveChannelEvent:
+		update = &lnrpc.ChannelEventUpdate{
+			Type: lnrpc.ChannelEventUpdate_ACTIVE_CHANNEL,
+			Channel: &lnrpc.ChannelEventUpdate_ActiveChannel{
+				ActiveChannel: &lnrpc.ChannelPoint{
+					FundingTxid: &lnrpc.ChannelPoint_FundingTxidBytes{
+						FundingTxidBytes: event.ChannelPoint.Hash[:],
+					},
+					OutputIndex: event.ChannelPoint.Index,
+				},
+			},
+		}
+
+	case channelnotifier.InactiveChannelEvent:
+		update = &lnrpc.ChannelEventUpdate{
+			Type: lnrpc.ChannelEventUpdate_INACTIVE_CHANNEL,
+			Channel: &lnrpc.ChannelEventUpdate_InactiveChannel{
+				InactiveChannel: &lnrpc.ChannelPoint{
+					FundingTxid: &lnrpc.ChannelPoint_FundingTxidBytes{
+						FundingTxidBytes: event.ChannelPoint.Hash[:],
+					},
+					OutputIndex: event.ChannelPoint.Index,
+				},
+			},
+		}
+
+	// Completely ignore ActiveLinkEvent and InactiveLinkEvent as this is
+	// explicitly not exposed to the RPC.
+	case channelnotifier.ActiveLinkEvent,
+		channelnotifier.InactiveLinkEvent:
+
+		return nil, nil
+
+	case channelnotifier.FullyResolvedChannelEvent:
+		update = &lnrpc.ChannelEventUpdate{
+			Type: lnrpc.ChannelEventUpdate_FULLY_RESOLVED_CHANNEL,
+			Channel: &lnrpc.ChannelEventUpdate_FullyResolvedChannel{
+				FullyResolvedChannel: &lnrpc.ChannelPoint{
+					FundingTxid: &lnrpc.ChannelPoint_FundingTxidBytes{
+						FundingTxidBytes: event.ChannelPoint.Hash[:],
+					},
+					OutputIndex: event.ChannelPoint.Index,
+				},
+			},
+		}
+
+	default:
+		return nil, fmt.Errorf("unexpected channel event update: %v",
+			event)
+	}
+
+	return update, nil
+}
+
+// SubscribeChannelResolutions returns a uni-directional stream (server ->
+// client) for notifying the client of progress made by the on-chain contract
+// resolvers of force closed channels.
+func (r *rpcServer) SubscribeChannelResolutions(
+	req *lnrpc.ChannelResolutionSubscription,
+	updateStream lnrpc.Lightning_SubscribeChannelResolutionsServer) error {
+
+	// Subscribe before fetching the snapshot so that we don't miss any
+	// updates in between.
+	resolutionSub, err := r.server.chainArb.SubscribeResolutions()
+	if err != nil {
+		return err
+	}
+
+	// Ensure that the resources for the client is cleaned up once either
+	// the server, or client exits.
+	defer resolutionSub.Cancel()
+
+	sendStatus := func(status *contractcourt.ResolverStatus) error {
+		if req.ChannelPoint != "" &&
+			req.ChannelPoint != status.ChanPoint.String() {
+
+			return nil
+		}
+
+		_, currentHeight, err := r.server.cc.ChainIO.GetBestBlock()
+		if err != nil {
+			return err
+		}
+
+		return updateStream.Send(
+			marshalResolverStatus(status, currentHeight),
+		)
+	}
+
+	// Send the current status of all active resolvers first.
+	for _, status := range r.server.chainArb.ResolverStatuses() {
+		if err := sendStatus(status); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case e := <-resolutionSub.Updates():
+			update, ok := e.(*contractcourt.ResolutionUpdate)
+			if !ok {
+				return fmt.Errorf("unexpected resolution "+
+					"update: %v", e)
+			}
+
+			if err := sendStatus(update.Status); err != nil {
+				return err
+			}
+
+		// The response stream's context for whatever reason has been
+		// closed. If context is closed by an exceeded deadline we will
+		// return an error.
+		case <-updateStream.Context().Done():
+			if errors.Is(updateStream.Context().Err(), context.Canceled) {
+				return nil
+			}
+			return updateStream.Context().Err()
+
+		case <-resolutionSub.Quit():
+			return nil
+
+		case <-r.quit:
+			return nil
+		}
+	}
+}
+
+// paymentStream enables different types of payment streams, such as:
+// lnrpc.Lightning_SendPaymentServer and lnrpc.Lightning_SendToRouteServer to
+// execute sendPayment. We use this struct as a sort of bridge to enable code
+// re-use between SendPayment and SendToRoute.
+type paymentStream struct {
+	recv func() (*rpcPaymentRequest, error)
+	send func(*lnrpc.SendResponse) error
+}
+
+// rpcPaymentRequest wraps lnrpc.SendRequest so that routes from
+// lnrpc.SendToRouteRequest can be passed to sendPayment.
+type rpcPaymentRequest struct {
+	*lnrpc.SendRequest
+	route *route.Route
 }
 
 // SendPayment dispatches a bi-directional streaming RPC for sending payments
@@ -5807,10 +6308,33 @@ func (r *rpcServer) SubscribeInvoices(req *lnrpc.InvoiceSubscription,
 
 // SubscribeTransactions creates a uni-directional stream (server -> client) in
 // which any newly discovered transactions relevant to the wallet are sent
-// over.
+// over. If the event log is enabled, the transactions are served from the log,
+// which allows the client to replay the transactions it missed.
 func (r *rpcServer) SubscribeTransactions(req *lnrpc.GetTransactionsRequest,
 	updateStream lnrpc.Lightning_SubscribeTransactionsServer) error {
 
+	if r.txEventLog != nil {
+		rpcsLog.Infof("New transaction subscription")
+
+		return r.streamEventLog(
+			updateStream.Context(), r.txEventLog, req.Replay,
+			req.StartAfterSequence, func(e *eventlog.Event) error {
+				detail := &lnrpc.Transaction{}
+				err := proto.Unmarshal(e.Payload, detail)
+				if err != nil {
+					return err
+				}
+				detail.Sequence = e.Sequence
+
+				return updateStream.Send(detail)
+			},
+		)
+	}
+
+	if req.Replay {
+		return eventlog.ErrLogDisabled
+	}
+
 	txClient, err := r.server.cc.Wallet.SubscribeTransactions()
 	if err != nil {
 		return err
@@ -5848,6 +6372,73 @@ func (r *rpcServer) SubscribeTransactions(req *lnrpc.GetTransactionsRequest,
 	}
 }
 
+// recordTransactions records the transactions relevant to the wallet in the
+// transaction event log.
+//
+// NOTE: MUST be run as a goroutine.
+func (r *rpcServer) recordTransactions(
+	txClient lnwallet.TransactionSubscription) {
+
+	defer r.wg.Done()
+	defer txClient.Cancel()
+
+	for {
+		select {
+		case tx := <-txClient.ConfirmedTransactions():
+			r.txEventLog.Record(tx)
+
+		case tx := <-txClient.UnconfirmedTransactions():
+			r.txEventLog.Record(tx)
+
+		case <-r.quit:
+			return
+		}
+	}
+}
+
+// streamEventLog sends the events of an event log to a subscription stream
+// until the stream is closed or the server shuts down. If replay is set, the
+// stored events after the given sequence number are sent first.
+func (r *rpcServer) streamEventLog(ctx context.Context,
+	eventLog *eventlog.Log, replay bool, after uint64,
+	send func(*eventlog.Event) error) error {
+
+	sub, err := eventLog.Subscribe(replay, after)
+	if err != nil {
+		return err
+	}
+	defer sub.Cancel()
+
+	for {
+		select {
+		case event := <-sub.Updates():
+			if err := send(event); err != nil {
+				return err
+			}
+
+		// The subscription ends if the event log shuts down or the
+		// stored events can't be read.
+		case <-sub.Done():
+			if errors.Is(sub.Err(), eventlog.ErrLogStopped) {
+				return nil
+			}
+			return sub.Err()
+
+		// The response stream's context for whatever reason has been
+		// closed. If context is closed by an exceeded deadline we will
+		// return an error.
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.Canceled) {
+				return nil
+			}
+			return ctx.Err()
+
+		case <-r.quit:
+			return nil
+		}
+	}
+}
+
 // GetTransactions returns a list of describing all the known transactions
 // relevant to the wallet.
 func (r *rpcServer) GetTransactions(ctx context.Context,
@@ -6027,15 +6618,25 @@ func marshalDBRoutingPolicy(
 
 	customRecords := marshalExtraOpaqueData(policy.ExtraOpaqueData)
 
+	// An inbound fee that fails to parse is reported as zero, which is
+	// also how the rest of the network will interpret it.
+	inboundFee, err := policy.InboundFee()
+	if err != nil {
+		rpcsLog.Debugf("Unable to parse inbound fee of channel %v: %v",
+			policy.ChannelID, err)
+	}
+
 	return &lnrpc.RoutingPolicy{
-		TimeLockDelta:    uint32(policy.TimeLockDelta),
-		MinHtlc:          int64(policy.MinHTLC),
-		MaxHtlcMsat:      uint64(policy.MaxHTLC),
-		FeeBaseMsat:      int64(policy.FeeBaseMSat),
-		FeeRateMilliMsat: int64(policy.FeeProportionalMillionths),
-		Disabled:         disabled,
-		LastUpdate:       uint32(policy.LastUpdate.Unix()),
-		CustomRecords:    customRecords,
+		TimeLockDelta:           uint32(policy.TimeLockDelta),
+		MinHtlc:                 int64(policy.MinHTLC),
+		MaxHtlcMsat:             uint64(policy.MaxHTLC),
+		FeeBaseMsat:             int64(policy.FeeBaseMSat),
+		FeeRateMilliMsat:        int64(policy.FeeProportionalMillionths),
+		Disabled:                disabled,
+		LastUpdate:              uint32(policy.LastUpdate.Unix()),
+		CustomRecords:           customRecords,
+		InboundFeeBaseMsat:      inboundFee.BaseFee,
+		InboundFeeRateMilliMsat: inboundFee.FeeRate,
 	}
 }
 
@@ -6550,12 +7151,48 @@ func (r *rpcServer) ListPayments(ctx context.Context,
 		}
 	}
 
+	if req.MinAmountMsat < 0 || req.MaxAmountMsat < 0 {
+		return nil, fmt.Errorf("amounts must not be negative")
+	}
+
+	if req.MaxAmountMsat != 0 && req.MinAmountMsat > req.MaxAmountMsat {
+		return nil, fmt.Errorf("min amount(%v) must not be greater "+
+			"than max amount(%v)", req.MinAmountMsat,
+			req.MaxAmountMsat)
+	}
+
+	if len(req.PaymentHashPrefix) > lntypes.HashSize {
+		return nil, fmt.Errorf("payment hash prefix must not be "+
+			"longer than %v bytes", lntypes.HashSize)
+	}
+
 	query := channeldb.PaymentsQuery{
 		IndexOffset:       req.IndexOffset,
 		MaxPayments:       req.MaxPayments,
 		Reversed:          req.Reversed,
 		IncludeIncomplete: req.IncludeIncomplete,
 		CountTotal:        req.CountTotalPayments,
+		MinAmount:         lnwire.MilliSatoshi(req.MinAmountMsat),
+		MaxAmount:         lnwire.MilliSatoshi(req.MaxAmountMsat),
+		PaymentHashPrefix: req.PaymentHashPrefix,
+	}
+
+	// Attach the destination if set.
+	if len(req.Destination) > 0 {
+		dest, err := route.NewVertexFromBytes(req.Destination)
+		if err != nil {
+			return nil, err
+		}
+		query.Destination = &dest
+	}
+
+	// Attach the statuses if set.
+	for _, rpcStatus := range req.Statuses {
+		status, err := unmarshallPaymentStatus(rpcStatus)
+		if err != nil {
+			return nil, err
+		}
+		query.Statuses = append(query.Statuses, status)
 	}
 
 	// Attach the start date if set.
@@ -6578,7 +7215,7 @@ func (r *rpcServer) ListPayments(ctx context.Context,
 		query.MaxPayments = math.MaxUint64
 	}
 
-	paymentsQuerySlice, err := r.server.miscDB.QueryPayments(query)
+	paymentsQuerySlice, err := r.server.paymentsDB.QueryPayments(query)
 	if err != nil {
 		return nil, err
 	}
@@ -6589,6 +7226,24 @@ func (r *rpcServer) ListPayments(ctx context.Context,
 		TotalNumPayments: paymentsQuerySlice.TotalCount,
 	}
 
+	if req.IncludeStats {
+		stats, err := r.server.paymentsDB.PaymentStats(
+			query.CreationDateStart, query.CreationDateEnd,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		paymentsResp.Stats = &lnrpc.PaymentStats{
+			NumPayments:   stats.NumPayments,
+			NumSucceeded:  stats.NumSucceeded,
+			NumFailed:     stats.NumFailed,
+			NumInFlight:   stats.NumInFlight,
+			TotalSentMsat: int64(stats.TotalSent),
+			TotalFeesMsat: int64(stats.TotalFees),
+		}
+	}
+
 	for _, payment := range paymentsQuerySlice.Payments {
 		payment := payment
 
@@ -6605,6 +7260,35 @@ func (r *rpcServer) ListPayments(ctx context.Context,
 	return paymentsResp, nil
 }
 
+// unmarshallPaymentStatus converts the given rpc payment status to the status
+// of the payments database.
+func unmarshallPaymentStatus(
+	status lnrpc.Payment_PaymentStatus) (channeldb.PaymentStatus, error) {
+
+	switch status {
+	case lnrpc.Payment_INITIATED:
+		return channeldb.StatusInitiated, nil
+
+	case lnrpc.Payment_IN_FLIGHT:
+		return channeldb.StatusInFlight, nil
+
+	case lnrpc.Payment_SUCCEEDED:
+		return channeldb.StatusSucceeded, nil
+
+	case lnrpc.Payment_FAILED:
+		return channeldb.StatusFailed, nil
+
+	// Cancelling payments are still in flight in the database, so they
+	// are found through the IN_FLIGHT status.
+	case lnrpc.Payment_CANCELLING:
+		return 0, fmt.Errorf("payments can't be filtered by status %v, "+
+			"use %v instead", status, lnrpc.Payment_IN_FLIGHT)
+
+	default:
+		return 0, fmt.Errorf("unknown payment status %v", status)
+	}
+}
+
 // DeletePayment deletes a payment from the DB given its payment hash. If
 // failedHtlcsOnly is set, only failed HTLC attempts of the payment will be
 // deleted.
@@ -6620,7 +7304,7 @@ func (r *rpcServer) DeletePayment(ctx context.Context,
 	rpcsLog.Infof("[DeletePayment] payment_identifier=%v, "+
 		"failed_htlcs_only=%v", hash, req.FailedHtlcsOnly)
 
-	err = r.server.miscDB.DeletePayment(hash, req.FailedHtlcsOnly)
+	err = r.server.paymentsDB.DeletePayment(hash, req.FailedHtlcsOnly)
 	if err != nil {
 		return nil, err
 	}
@@ -6637,7 +7321,7 @@ func (r *rpcServer) DeleteAllPayments(ctx context.Context,
 		"failed_htlcs_only=%v", req.FailedPaymentsOnly,
 		req.FailedHtlcsOnly)
 
-	err := r.server.miscDB.DeletePayments(
+	err := r.server.paymentsDB.DeletePayments(
 		req.FailedPaymentsOnly, req.FailedHtlcsOnly,
 	)
 	if err != nil {
@@ -6780,13 +7464,21 @@ func (r *rpcServer) FeeReport(ctx context.Context,
 		feeRateFixedPoint := edgePolicy.FeeProportionalMillionths
 		feeRate := float64(feeRateFixedPoint) / feeBase
 
+		inboundFee, err := edgePolicy.InboundFee()
+		if err != nil {
+			return fmt.Errorf("unable to parse inbound fee of "+
+				"channel %v: %w", chanInfo.ChannelID, err)
+		}
+
 		// TODO(roasbeef): also add stats for revenue for each channel
 		feeReports = append(feeReports, &lnrpc.ChannelFeeReport{
-			ChanId:       chanInfo.ChannelID,
-			ChannelPoint: chanInfo.ChannelPoint.String(),
-			BaseFeeMsat:  int64(edgePolicy.FeeBaseMSat),
-			FeePerMil:    int64(feeRateFixedPoint),
-			FeeRate:      feeRate,
+			ChanId:             chanInfo.ChannelID,
+			ChannelPoint:       chanInfo.ChannelPoint.String(),
+			BaseFeeMsat:        int64(edgePolicy.FeeBaseMSat),
+			FeePerMil:          int64(feeRateFixedPoint),
+			FeeRate:            feeRate,
+			InboundBaseFeeMsat: inboundFee.BaseFee,
+			InboundFeePerMil:   inboundFee.FeeRate,
 		})
 
 		return nil
@@ -6892,6 +7584,97 @@ func (r *rpcServer) FeeReport(ctx context.Context,
 // 0.000001, or 0.0001%.
 const minFeeRate = 1e-6
 
+// UpdateChanParams changes the parameters of an existing channel, such as the
+// dust limit, the maximum number of accepted HTLCs, the CSV delay or the
+// commitment type, through a dynamic commitment negotiation with the remote
+// peer.
+func (r *rpcServer) UpdateChanParams(_ context.Context,
+	in *lnrpc.UpdateChanParamsRequest) (*lnrpc.UpdateChanParamsResponse,
+	error) {
+
+	if in.ChanPoint == nil {
+		return nil, fmt.Errorf("chan_point must be set")
+	}
+
+	txid, err := lnrpc.GetChanPointFundingTxid(in.ChanPoint)
+	if err != nil {
+		return nil, err
+	}
+	chanPoint := wire.OutPoint{
+		Hash:  *txid,
+		Index: in.ChanPoint.OutputIndex,
+	}
+
+	var params lnwallet.DynCommitParams
+	if in.DustLimitSat != 0 {
+		dustLimit := btcutil.Amount(in.DustLimitSat)
+		params.DustLimit = &dustLimit
+	}
+
+	if in.MaxAcceptedHtlcs != 0 {
+		if in.MaxAcceptedHtlcs > math.MaxUint16 {
+			return nil, fmt.Errorf("max_accepted_htlcs of %v is "+
+				"too large", in.MaxAcceptedHtlcs)
+		}
+
+		maxHtlcs := uint16(in.MaxAcceptedHtlcs)
+		params.MaxAcceptedHTLCs = &maxHtlcs
+	}
+
+	if in.CsvDelay != 0 {
+		if in.CsvDelay > math.MaxUint16 {
+			return nil, fmt.Errorf("csv_delay of %v is too large",
+				in.CsvDelay)
+		}
+
+		csvDelay := uint16(in.CsvDelay)
+		params.CsvDelay = &csvDelay
+	}
+
+	var commitType lnwallet.CommitmentType
+	switch in.CommitmentType {
+	case lnrpc.CommitmentType_UNKNOWN_COMMITMENT_TYPE:
+
+	case lnrpc.CommitmentType_STATIC_REMOTE_KEY:
+		commitType = lnwallet.CommitmentTypeTweakless
+		params.CommitmentType = &commitType
+
+	case lnrpc.CommitmentType_ANCHORS:
+		commitType = lnwallet.CommitmentTypeAnchorsZeroFeeHtlcTx
+		params.CommitmentType = &commitType
+
+	default:
+		return nil, fmt.Errorf("channels can't be moved to commitment "+
+			"type %v", in.CommitmentType)
+	}
+
+	if params.IsEmpty() {
+		return nil, lnwallet.ErrDynCommitNoParams
+	}
+
+	chanID := lnwire.NewChanIDFromOutPoint(&chanPoint)
+	link, err := r.server.htlcSwitch.GetLink(chanID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find active link for "+
+			"channel %v: %w", chanPoint, err)
+	}
+
+	if !link.EligibleToForward() {
+		return nil, fmt.Errorf("channel %v is not active", chanPoint)
+	}
+
+	rpcsLog.Debugf("[updatechanparams] chan_point=%v, dust_limit=%v, "+
+		"max_accepted_htlcs=%v, csv_delay=%v, commitment_type=%v",
+		chanPoint, in.DustLimitSat, in.MaxAcceptedHtlcs, in.CsvDelay,
+		in.CommitmentType)
+
+	if err := link.ProposeDynCommit(&params); err != nil {
+		return nil, err
+	}
+
+	return &lnrpc.UpdateChanParamsResponse{}, nil
+}
+
 // UpdateChannelPolicy allows the caller to update the channel forwarding policy
 // for all channels globally, or a particular channel.
 func (r *rpcServer) UpdateChannelPolicy(ctx context.Context,
@@ -6974,6 +7757,26 @@ func (r *rpcServer) UpdateChannelPolicy(ctx context.Context,
 		FeeRate: feeRateFixed,
 	}
 
+	// The inbound fee is only touched if the caller explicitly set it, so
+	// that existing inbound discounts aren't wiped by plain fee updates.
+	if req.InboundFee != nil {
+		inboundFee := models.InboundFee{
+			Base: req.InboundFee.BaseFeeMsat,
+			Rate: req.InboundFee.FeeRatePpm,
+		}
+
+		// Rates below -100% would let senders extract funds from us
+		// and rates above 100% are rejected by the protocol anyway.
+		maxRate := int32(feeBase)
+		if inboundFee.Rate < -maxRate || inboundFee.Rate > maxRate {
+			return nil, status.Errorf(codes.InvalidArgument,
+				"inbound fee rate of %v ppm out of range",
+				inboundFee.Rate)
+		}
+
+		feeSchema.InboundFee = &inboundFee
+	}
+
 	maxHtlc := lnwire.MilliSatoshi(req.MaxHtlcMsat)
 	var minHtlc *lnwire.MilliSatoshi
 	if req.MinHtlcMsatSpecified {
@@ -6989,10 +7792,10 @@ func (r *rpcServer) UpdateChannelPolicy(ctx context.Context,
 	}
 
 	rpcsLog.Debugf("[updatechanpolicy] updating channel policy "+
-		"base_fee=%v, rate_fixed=%v, time_lock_delta: %v, "+
-		"min_htlc=%v, max_htlc=%v, targets=%v",
-		req.BaseFeeMsat, feeRateFixed, req.TimeLockDelta,
-		minHtlc, maxHtlc,
+		"base_fee=%v, rate_fixed=%v, inbound_fee=%v, "+
+		"time_lock_delta: %v, min_htlc=%v, max_htlc=%v, targets=%v",
+		req.BaseFeeMsat, feeRateFixed, feeSchema.InboundFee,
+		req.TimeLockDelta, minHtlc, maxHtlc,
 		spew.Sdump(targetChans))
 
 	// With the scope resolved, we'll now send this to the local channel
@@ -7008,6 +7811,461 @@ func (r *rpcServer) UpdateChannelPolicy(ctx context.Context,
 	}, nil
 }
 
+// FeeManagerStatus returns the configuration of the automatic fee manager
+// together with the most recent fee updates it made.
+func (r *rpcServer) FeeManagerStatus(_ context.Context,
+	_ *lnrpc.FeeManagerStatusRequest) (*lnrpc.FeeManagerStatusResponse,
+	error) {
+
+	feeStatus := r.server.feeManager.Status()
+	minUpdateInterval := feeStatus.MinUpdateInterval
+
+	return &lnrpc.FeeManagerStatusResponse{
+		Active:               feeStatus.Active,
+		Strategy:             feeStatus.Strategy,
+		Strategies:           feeStatus.Strategies,
+		IntervalSec:          uint64(feeStatus.Interval.Seconds()),
+		MinUpdateIntervalSec: uint64(minUpdateInterval.Seconds()),
+		MinFeeRatePpm:        feeStatus.Bounds.MinFeeRate,
+		MaxFeeRatePpm:        feeStatus.Bounds.MaxFeeRate,
+		MinBaseFeeMsat:       uint64(feeStatus.Bounds.MinBaseFee),
+		MaxBaseFeeMsat:       uint64(feeStatus.Bounds.MaxBaseFee),
+		RecentUpdates:        marshalFeeUpdates(feeStatus.RecentUpdates),
+	}, nil
+}
+
+// UpdateFeeManager enables or disables the periodic fee adjustments of the
+// automatic fee manager, selects the strategy it uses and optionally runs a
+// round of fee adjustments immediately.
+func (r *rpcServer) UpdateFeeManager(_ context.Context,
+	req *lnrpc.UpdateFeeManagerRequest) (*lnrpc.UpdateFeeManagerResponse,
+	error) {
+
+	if req.Enable && req.Disable {
+		return nil, status.Errorf(codes.InvalidArgument, "cannot "+
+			"both enable and disable the fee manager")
+	}
+
+	feeManager := r.server.feeManager
+	if req.Strategy != "" {
+		err := feeManager.SetStrategy(req.Strategy)
+		if errors.Is(err, feemanager.ErrUnknownStrategy) {
+			return nil, status.Error(
+				codes.InvalidArgument, err.Error(),
+			)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case req.Enable:
+		feeManager.SetActive(true)
+
+	case req.Disable:
+		feeManager.SetActive(false)
+	}
+
+	resp := &lnrpc.UpdateFeeManagerResponse{}
+	if req.Run {
+		updates, err := feeManager.Run()
+		if err != nil {
+			return nil, err
+		}
+		resp.FeeUpdates = marshalFeeUpdates(updates)
+	}
+
+	return resp, nil
+}
+
+// marshalFeeUpdates converts the given fee manager updates into their RPC
+// representation.
+func marshalFeeUpdates(
+	updates []feemanager.FeeUpdate) []*lnrpc.FeeManagerFeeUpdate {
+
+	rpcUpdates := make([]*lnrpc.FeeManagerFeeUpdate, 0, len(updates))
+	for _, update := range updates {
+		rpcUpdates = append(rpcUpdates, &lnrpc.FeeManagerFeeUpdate{
+			ChanId:         update.ChanID.ToUint64(),
+			ChannelPoint:   update.ChanPoint.String(),
+			Timestamp:      update.Timestamp.Unix(),
+			OldBaseFeeMsat: uint64(update.OldFee.BaseFee),
+			OldFeeRatePpm:  update.OldFee.FeeRate,
+			NewBaseFeeMsat: uint64(update.NewFee.BaseFee),
+			NewFeeRatePpm:  update.NewFee.FeeRate,
+			Strategy:       update.Strategy,
+			Reason:         update.Reason,
+		})
+	}
+
+	return rpcUpdates
+}
+
+// errWebhooksInactive is returned by the webhook RPCs if the delivery of node
+// events to webhooks isn't active.
+var errWebhooksInactive = status.Error(
+	codes.FailedPrecondition, "webhooks are not active, set "+
+		"webhook.active in lnd.conf",
+)
+
+// webhookManager returns the webhook manager, or an error if webhooks aren't
+// active.
+func (r *rpcServer) webhookManager() (*webhook.Manager, error) {
+	if r.server.webhookManager == nil {
+		return nil, errWebhooksInactive
+	}
+
+	return r.server.webhookManager, nil
+}
+
+// AddWebhook registers an HTTP endpoint that node events are delivered to.
+func (r *rpcServer) AddWebhook(_ context.Context,
+	req *lnrpc.AddWebhookRequest) (*lnrpc.AddWebhookResponse, error) {
+
+	manager, err := r.webhookManager()
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]webhook.EventType, 0, len(req.Events))
+	for _, event := range req.Events {
+		eventType := webhook.EventType(event)
+		if !eventType.Valid() {
+			return nil, status.Errorf(codes.InvalidArgument,
+				"unknown webhook event type %v", event)
+		}
+		events = append(events, eventType)
+	}
+
+	endpoint, err := manager.AddEndpoint(req.Url, events, req.Secret)
+	if errors.Is(err, webhook.ErrInvalidURL) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &lnrpc.AddWebhookResponse{
+		Webhook: marshalWebhook(endpoint, true),
+	}, nil
+}
+
+// RemoveWebhook removes a webhook endpoint together with its pending and
+// failed deliveries.
+func (r *rpcServer) RemoveWebhook(_ context.Context,
+	req *lnrpc.RemoveWebhookRequest) (*lnrpc.RemoveWebhookResponse, error) {
+
+	manager, err := r.webhookManager()
+	if err != nil {
+		return nil, err
+	}
+
+	err = manager.RemoveEndpoint(req.Id)
+	switch {
+	case errors.Is(err, webhook.ErrEndpointNotFound):
+		return nil, status.Error(codes.NotFound, err.Error())
+
+	case errors.Is(err, webhook.ErrConfigEndpoint):
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+
+	case err != nil:
+		return nil, err
+	}
+
+	return &lnrpc.RemoveWebhookResponse{}, nil
+}
+
+// ListWebhooks returns the registered webhook endpoints.
+func (r *rpcServer) ListWebhooks(_ context.Context,
+	_ *lnrpc.ListWebhooksRequest) (*lnrpc.ListWebhooksResponse, error) {
+
+	manager, err := r.webhookManager()
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &lnrpc.ListWebhooksResponse{}
+	for _, endpoint := range manager.Endpoints() {
+		resp.Webhooks = append(
+			resp.Webhooks, marshalWebhook(endpoint, false),
+		)
+	}
+
+	return resp, nil
+}
+
+// ListWebhookDeliveries returns the pending, successful or failed deliveries
+// of node events to the webhook endpoints.
+func (r *rpcServer) ListWebhookDeliveries(_ context.Context,
+	req *lnrpc.ListWebhookDeliveriesRequest) (
+	*lnrpc.ListWebhookDeliveriesResponse, error) {
+
+	manager, err := r.webhookManager()
+	if err != nil {
+		return nil, err
+	}
+
+	var state webhook.DeliveryState
+	switch req.State {
+	case lnrpc.WebhookDeliveryState_WEBHOOK_DELIVERY_PENDING:
+		state = webhook.DeliveryPending
+
+	case lnrpc.WebhookDeliveryState_WEBHOOK_DELIVERY_SUCCEEDED:
+		state = webhook.DeliverySucceeded
+
+	case lnrpc.WebhookDeliveryState_WEBHOOK_DELIVERY_FAILED:
+		state = webhook.DeliveryFailed
+
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown "+
+			"webhook delivery state %v", req.State)
+	}
+
+	deliveries, err := manager.Deliveries(state, req.WebhookId)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &lnrpc.ListWebhookDeliveriesResponse{}
+	for _, d := range deliveries {
+		rpcDelivery := &lnrpc.WebhookDelivery{
+			Id:             d.ID,
+			WebhookId:      d.EndpointID,
+			EventId:        d.EventID,
+			EventType:      lnrpc.WebhookEventType(d.EventType),
+			State:          req.State,
+			CreatedAt:      unixOrZero(d.CreatedAt),
+			Attempts:       d.Attempts,
+			LastAttempt:    unixOrZero(d.LastAttempt),
+			NextAttempt:    unixOrZero(d.NextAttempt),
+			LastStatusCode: d.LastStatusCode,
+			LastError:      d.LastError,
+			Payload:        d.Payload,
+		}
+		resp.Deliveries = append(resp.Deliveries, rpcDelivery)
+	}
+
+	return resp, nil
+}
+
+// RetryWebhookDelivery moves a delivery from the dead-letter queue back to the
+// pending deliveries.
+func (r *rpcServer) RetryWebhookDelivery(_ context.Context,
+	req *lnrpc.RetryWebhookDeliveryRequest) (
+	*lnrpc.RetryWebhookDeliveryResponse, error) {
+
+	manager, err := r.webhookManager()
+	if err != nil {
+		return nil, err
+	}
+
+	err = manager.RetryDelivery(req.DeliveryId)
+	switch {
+	case errors.Is(err, webhook.ErrDeliveryNotFound):
+		return nil, status.Error(codes.NotFound, err.Error())
+
+	case errors.Is(err, webhook.ErrNotDeadLetter):
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+
+	case err != nil:
+		return nil, err
+	}
+
+	return &lnrpc.RetryWebhookDeliveryResponse{}, nil
+}
+
+// marshalWebhook converts a webhook endpoint into its RPC representation. The
+// signing secret is only included if withSecret is set, since it allows
+// forging deliveries to the endpoint.
+func marshalWebhook(endpoint *webhook.Endpoint,
+	withSecret bool) *lnrpc.Webhook {
+
+	var secret []byte
+	if withSecret {
+		secret = endpoint.Secret
+	}
+
+	events := make([]lnrpc.WebhookEventType, 0, len(endpoint.Events))
+	for _, event := range endpoint.Events {
+		events = append(events, lnrpc.WebhookEventType(event))
+	}
+
+	return &lnrpc.Webhook{
+		Id:         endpoint.ID,
+		Url:        endpoint.URL,
+		Events:     events,
+		Secret:     secret,
+		FromConfig: endpoint.FromConfig,
+		CreatedAt:  unixOrZero(endpoint.CreatedAt),
+	}
+}
+
+// unixOrZero returns the unix timestamp in seconds of the given time, or zero
+// for the zero time.
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+
+	return t.Unix()
+}
+
+// AccountingReport returns a ledger of the activity of the node in the
+// requested time range.
+func (r *rpcServer) AccountingReport(ctx context.Context,
+	req *lnrpc.AccountingReportRequest) (*lnrpc.AccountingReportResponse,
+	error) {
+
+	// Flush the pending forwarding events to disk so that the report
+	// includes all forwards up to now.
+	if err := r.server.htlcSwitch.FlushForwardingEvents(); err != nil {
+		return nil, fmt.Errorf("unable to flush forwarding "+
+			"events: %v", err)
+	}
+
+	var startTime, endTime time.Time
+	if req.StartTime != 0 {
+		startTime = time.Unix(req.StartTime, 0)
+	}
+	if req.EndTime != 0 {
+		endTime = time.Unix(req.EndTime, 0)
+	}
+	if !endTime.IsZero() && !startTime.Before(endTime) {
+		return nil, status.Error(codes.InvalidArgument,
+			"start time must be before end time")
+	}
+
+	chainHash := *r.cfg.ActiveNetParams.GenesisHash
+	report, err := accounting.NewReport(&accounting.Config{
+		Transactions: func() ([]*lnwallet.TransactionDetail, error) {
+			return r.server.cc.Wallet.ListTransactionDetails(
+				0, btcwallet.UnconfirmedHeight, "",
+			)
+		},
+		Payments: func() ([]*channeldb.MPPayment, error) {
+			// Only the succeeded payments are returned, as we
+			// don't include the incomplete ones.
+			resp, err := r.server.paymentsDB.QueryPayments(
+				channeldb.PaymentsQuery{
+					MaxPayments: math.MaxUint64,
+				},
+			)
+			if err != nil {
+				return nil, err
+			}
+
+			return resp.Payments, nil
+		},
+		SettledInvoices: func() ([]invoices.Invoice, error) {
+			resp, err := r.server.miscDB.QueryInvoices(
+				ctx, invoices.InvoiceQuery{
+					NumMaxInvoices: math.MaxUint64,
+				},
+			)
+			if err != nil {
+				return nil, err
+			}
+
+			var settled []invoices.Invoice
+			for _, invoice := range resp.Invoices {
+				if invoice.State != invoices.ContractSettled {
+					continue
+				}
+				settled = append(settled, invoice)
+			}
+
+			return settled, nil
+		},
+		Forwards: func(start,
+			end time.Time) ([]channeldb.ForwardingEvent, error) {
+
+			// The forwarding log requires an end time, so we
+			// query up to now if the range has no upper bound.
+			if end.IsZero() {
+				end = time.Now()
+			}
+
+			return r.server.forwardingEvents(start, end)
+		},
+		OpenChannels: r.server.chanStateDB.FetchAllChannels,
+		ClosedChannels: func() ([]*channeldb.ChannelCloseSummary,
+			error) {
+
+			return r.server.chanStateDB.FetchClosedChannels(false)
+		},
+		ResolverReports: func(chanPoint *wire.OutPoint) (
+			[]*channeldb.ResolverReport, error) {
+
+			reports, err := r.server.miscDB.FetchChannelReports(
+				chainHash, chanPoint,
+			)
+			switch {
+			// Channels that were closed before the resolver
+			// outcomes were stored don't have any reports.
+			case errors.Is(err, channeldb.ErrNoChainHashBucket),
+				errors.Is(err, channeldb.ErrNoChannelSummaries):
+
+				return nil, nil
+
+			case err != nil:
+				return nil, err
+			}
+
+			return reports, nil
+		},
+		HistoricalChannel: func(chanPoint *wire.OutPoint) (
+			*channeldb.OpenChannel, error) {
+
+			channel, err := r.server.chanStateDB.
+				FetchHistoricalChannel(chanPoint)
+			switch {
+			// Channels that were closed before the historical
+			// channels were stored aren't known.
+			case errors.Is(err, channeldb.ErrNoHistoricalBucket),
+				errors.Is(err, channeldb.ErrChannelNotFound):
+
+				return nil, nil
+
+			case err != nil:
+				return nil, err
+			}
+
+			return channel, nil
+		},
+	}, startTime, endTime)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create accounting report: %w",
+			err)
+	}
+
+	resp := &lnrpc.AccountingReportResponse{
+		Entries: make([]*lnrpc.LedgerEntry, 0, len(report.Entries)),
+		Totals:  make([]*lnrpc.LedgerTotal, 0, len(report.Totals)),
+	}
+	for _, entry := range report.Entries {
+		resp.Entries = append(resp.Entries, &lnrpc.LedgerEntry{
+			Timestamp:    entry.Timestamp.Unix(),
+			Type:         lnrpc.LedgerEntryType(entry.Type),
+			AmountMsat:   entry.AmountMsat,
+			Txid:         entry.Txid,
+			PaymentHash:  entry.PaymentHash,
+			ChannelPoint: entry.ChannelPoint,
+			ChanId:       entry.ChanID,
+			Note:         entry.Note,
+		})
+	}
+	for _, total := range report.Totals {
+		resp.Totals = append(resp.Totals, &lnrpc.LedgerTotal{
+			Type:       lnrpc.LedgerEntryType(total.Type),
+			AmountMsat: total.AmountMsat,
+			Count:      total.Count,
+		})
+	}
+
+	return resp, nil
+}
+
 // ForwardingHistory allows the caller to query the htlcswitch for a record of
 // all HTLC's forwarded within the target time range, and integer offset within
 // that time range. If no time-range is specified, then the first chunk of the
@@ -7685,6 +8943,14 @@ func (r *rpcServer) DeleteMacaroonID(ctx context.Context,
 		return nil, err
 	}
 
+	// The usage of the deleted macaroons is no longer needed either.
+	usageStore := r.interceptorChain.MacaroonUsageStore()
+	if deletedIDBytes != nil && usageStore != nil {
+		if err := usageStore.DeleteUsage(rootKeyID); err != nil {
+			return nil, err
+		}
+	}
+
 	return &lnrpc.DeleteMacaroonIDResponse{
 		// If the root key ID doesn't exist, it won't be deleted. We
 		// will return a response with deleted = false, otherwise true.
@@ -7692,6 +8958,54 @@ func (r *rpcServer) DeleteMacaroonID(ctx context.Context,
 	}, nil
 }
 
+// GetMacaroonUsage returns the spending and call usage of the macaroons
+// derived from a specific root key ID.
+func (r *rpcServer) GetMacaroonUsage(_ context.Context,
+	req *lnrpc.GetMacaroonUsageRequest) (*lnrpc.GetMacaroonUsageResponse,
+	error) {
+
+	// If the --no-macaroons flag is used to start lnd, the macaroon usage
+	// store is not initialized.
+	usageStore := r.interceptorChain.MacaroonUsageStore()
+	if r.macService == nil || usageStore == nil {
+		return nil, errMacaroonDisabled
+	}
+
+	rootKeyID := []byte(strconv.FormatUint(req.RootKeyId, 10))
+	usage, err := usageStore.FetchUsage(rootKeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The timestamps are only set once something was spent or a call was
+	// made.
+	var windowStart, lastUsed int64
+	if !usage.WindowStart.IsZero() {
+		windowStart = usage.WindowStart.Unix()
+	}
+	if !usage.LastUsed.IsZero() {
+		lastUsed = usage.LastUsed.Unix()
+	}
+
+	// The calls of the current minute are only reported if the minute
+	// hasn't passed yet.
+	var callsLastMinute uint32
+	if time.Since(usage.MinuteStart) < time.Minute {
+		callsLastMinute = usage.MinuteCalls
+	}
+
+	return &lnrpc.GetMacaroonUsageResponse{
+		RootKeyId:       req.RootKeyId,
+		WindowStart:     windowStart,
+		WindowSpentMsat: uint64(usage.WindowSpent),
+		TotalSpentMsat:  uint64(usage.TotalSpent),
+		NumPayments:     usage.NumPayments,
+		CallsLastMinute: callsLastMinute,
+		TotalCalls:      usage.TotalCalls,
+		LastUsed:        lastUsed,
+	}, nil
+}
+
 // ListPermissions lists all RPC method URIs and their required macaroon
 // permissions to access them.
 func (r *rpcServer) ListPermissions(_ context.Context,
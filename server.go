@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"crypto/rand"
+	"database/sql"
 	"encoding/hex"
 	"fmt"
 	"math/big"
@@ -18,12 +19,14 @@ import (
 	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
 	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/connmgr"
 	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/go-errors/errors"
 	sphinx "github.com/lightningnetwork/lightning-onion"
+	"github.com/lightningnetwork/lnd/accounts"
 	"github.com/lightningnetwork/lnd/aliasmgr"
 	"github.com/lightningnetwork/lnd/autopilot"
 	"github.com/lightningnetwork/lnd/brontide"
@@ -37,25 +40,30 @@ import (
 	"github.com/lightningnetwork/lnd/contractcourt"
 	"github.com/lightningnetwork/lnd/discovery"
 	"github.com/lightningnetwork/lnd/feature"
+	"github.com/lightningnetwork/lnd/feemanager"
 	"github.com/lightningnetwork/lnd/funding"
 	"github.com/lightningnetwork/lnd/healthcheck"
 	"github.com/lightningnetwork/lnd/htlcswitch"
 	"github.com/lightningnetwork/lnd/htlcswitch/hop"
+	"github.com/lightningnetwork/lnd/htlcswitch/reputation"
 	"github.com/lightningnetwork/lnd/input"
 	"github.com/lightningnetwork/lnd/invoices"
 	"github.com/lightningnetwork/lnd/keychain"
 	"github.com/lightningnetwork/lnd/kvdb"
+	"github.com/lightningnetwork/lnd/labels"
 	"github.com/lightningnetwork/lnd/lncfg"
 	"github.com/lightningnetwork/lnd/lnencrypt"
 	"github.com/lightningnetwork/lnd/lnpeer"
 	"github.com/lightningnetwork/lnd/lnrpc"
 	"github.com/lightningnetwork/lnd/lnrpc/routerrpc"
+	"github.com/lightningnetwork/lnd/lntypes"
 	"github.com/lightningnetwork/lnd/lnwallet"
 	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
 	"github.com/lightningnetwork/lnd/lnwallet/rpcwallet"
 	"github.com/lightningnetwork/lnd/lnwire"
 	"github.com/lightningnetwork/lnd/nat"
 	"github.com/lightningnetwork/lnd/netann"
+	"github.com/lightningnetwork/lnd/payments"
 	"github.com/lightningnetwork/lnd/peer"
 	"github.com/lightningnetwork/lnd/peernotifier"
 	"github.com/lightningnetwork/lnd/pool"
@@ -63,6 +71,7 @@ import (
 	"github.com/lightningnetwork/lnd/routing"
 	"github.com/lightningnetwork/lnd/routing/localchans"
 	"github.com/lightningnetwork/lnd/routing/route"
+	"github.com/lightningnetwork/lnd/sqldb"
 	"github.com/lightningnetwork/lnd/subscribe"
 	"github.com/lightningnetwork/lnd/sweep"
 	"github.com/lightningnetwork/lnd/ticker"
@@ -72,6 +81,7 @@ import (
 	"github.com/lightningnetwork/lnd/watchtower/wtclient"
 	"github.com/lightningnetwork/lnd/watchtower/wtpolicy"
 	"github.com/lightningnetwork/lnd/watchtower/wtserver"
+	"github.com/lightningnetwork/lnd/webhook"
 )
 
 const (
@@ -269,10 +279,32 @@ type server struct {
 
 	controlTower routing.ControlTower
 
+	// paymentsDB is the database of the payments sent by the node. It uses
+	// the native SQL tables if they are enabled.
+	paymentsDB payments.DB
+
+	// accounts enforces the virtual accounts of account bound macaroons.
+	// It is nil if native SQL tables aren't used.
+	accounts *accounts.InterceptorService
+
+	// accountStore is the persistent storage of the accounts. It is nil if
+	// native SQL tables aren't used.
+	accountStore accounts.Store
+
+	// labelStore holds the BIP-329 labels of the transactions, addresses
+	// and outputs of the wallet.
+	labelStore labels.Store
+
 	authGossiper *discovery.AuthenticatedGossiper
 
 	localChanMgr *localchans.Manager
 
+	feeManager *feemanager.Manager
+
+	// webhookManager delivers node events to the registered webhook
+	// endpoints. It is nil if webhooks aren't active.
+	webhookManager *webhook.Manager
+
 	utxoNursery *contractcourt.UtxoNursery
 
 	sweeper *sweep.UtxoSweeper
@@ -530,6 +562,15 @@ func newServer(cfg *Config, listenAddrs []net.Addr,
 		readBufferPool, cfg.Workers.Read, pool.DefaultWorkerTimeout,
 	)
 
+	// Zero-fee commitments can't be relayed without a child paying for
+	// them, so we refuse to negotiate them if the backend isn't able to
+	// submit both as a package.
+	if cfg.ProtocolOptions.ZeroFeeCommitments && cc.PublishPackage == nil {
+		return nil, fmt.Errorf("zero-fee commitments require a " +
+			"bitcoind backend with package relay support (v28.0 " +
+			"or later)")
+	}
+
 	//nolint:lll
 	featureMgr, err := feature.NewManager(feature.Config{
 		NoTLVOnion:               cfg.ProtocolOptions.LegacyOnion(),
@@ -543,6 +584,8 @@ func newServer(cfg *Config, listenAddrs []net.Addr,
 		NoAnySegwit:              cfg.ProtocolOptions.NoAnySegwit(),
 		CustomFeatures:           cfg.ProtocolOptions.ExperimentalProtocol.CustomFeatures(),
 		NoTaprootChans:           !cfg.ProtocolOptions.TaprootChans,
+		NoZeroFeeCommitments:     !cfg.ProtocolOptions.ZeroFeeCommitments,
+		NoDynamicCommitments:     !cfg.ProtocolOptions.DynamicCommitments,
 	})
 	if err != nil {
 		return nil, err
@@ -626,14 +669,58 @@ func newServer(cfg *Config, listenAddrs []net.Addr,
 
 	s.htlcNotifier = htlcswitch.NewHtlcNotifier(time.Now)
 
-	thresholdSats := btcutil.Amount(cfg.DustThreshold)
-	thresholdMSats := lnwire.NewMSatFromSatoshis(thresholdSats)
+	maxFeeExposure := lnwallet.FeeExposureLimit{
+		Max: lnwire.NewMSatFromSatoshis(
+			btcutil.Amount(cfg.ChannelMaxFeeExposure),
+		),
+		CommitFeeMultiplier: cfg.ChannelMaxFeeExposureMultiplier,
+	}
 
 	s.aliasMgr, err = aliasmgr.NewManager(dbs.ChanStateDB)
 	if err != nil {
 		return nil, err
 	}
 
+	// The resource manager is only created if the experimental HTLC
+	// endorsement is enabled, otherwise HTLCs are forwarded without it.
+	var resourceManager *reputation.Manager
+	if cfg.Htlcswitch.Endorsement != lncfg.EndorsementOff {
+		switchCfg := cfg.Htlcswitch
+		resourceManager = reputation.NewManager(&reputation.Config{
+			RevenueWindow:        switchCfg.RevenueWindow,
+			ReputationMultiplier: switchCfg.ReputationMultiplier,
+			ResolutionPeriod:     switchCfg.ResolutionPeriod,
+			ProtectedShare:       switchCfg.ProtectedShare,
+			Enforce: switchCfg.Endorsement ==
+				lncfg.EndorsementEnforce,
+			Clock: clock.NewDefaultClock(),
+		})
+	}
+
+	var rateLimits *htlcswitch.RateLimiterConfig
+	if cfg.Htlcswitch.RateLimitsActive() {
+		switchCfg := cfg.Htlcswitch
+		rateLimits = &htlcswitch.RateLimiterConfig{
+			Peer: htlcswitch.RateLimits{
+				MaxAddRate: switchCfg.PeerMaxAddRate,
+				MaxInFlight: lnwire.MilliSatoshi(
+					switchCfg.PeerMaxInFlightMsat,
+				),
+				MaxFailRatio: switchCfg.PeerMaxFailRatio,
+			},
+			Channel: htlcswitch.RateLimits{
+				MaxAddRate: switchCfg.ChannelMaxAddRate,
+				MaxInFlight: lnwire.MilliSatoshi(
+					switchCfg.ChannelMaxInFlightMsat,
+				),
+				MaxFailRatio: switchCfg.ChannelMaxFailRatio,
+			},
+			FailRatioWindow:   switchCfg.FailRatioWindow,
+			FailRatioMinHTLCs: switchCfg.FailRatioMinHTLCs,
+			Clock:             clock.NewDefaultClock(),
+		}
+	}
+
 	s.htlcSwitch, err = htlcswitch.New(htlcswitch.Config{
 		DB:                   dbs.ChanStateDB,
 		FetchAllOpenChannels: s.chanStateDB.FetchAllOpenChannels,
@@ -666,9 +753,11 @@ func newServer(cfg *Config, listenAddrs []net.Addr,
 		RejectHTLC:             cfg.RejectHTLC,
 		Clock:                  clock.NewDefaultClock(),
 		MailboxDeliveryTimeout: cfg.Htlcswitch.MailboxDeliveryTimeout,
-		DustThreshold:          thresholdMSats,
+		MaxFeeExposure:         maxFeeExposure,
 		SignAliasUpdate:        s.signAliasUpdate,
 		IsAlias:                aliasmgr.IsAlias,
+		ResourceManager:        resourceManager,
+		RateLimits:             rateLimits,
 	}, uint32(currentHeight))
 	if err != nil {
 		return nil, err
@@ -955,9 +1044,42 @@ func newServer(cfg *Config, listenAddrs []net.Addr,
 		PathFindingConfig: pathFindingConfig,
 	}
 
-	paymentControl := channeldb.NewPaymentControl(dbs.ChanStateDB)
+	// Payments are only stored in the native SQL tables if their
+	// migration was explicitly requested, as it can't be undone. Once
+	// migrated, the key-value payments are outdated, so we refuse to use
+	// them.
+	if dbs.NativeSQLStore != nil && cfg.DB.MigratePaymentsToSQL {
+		s.paymentsDB, err = newSQLPaymentsDB(
+			dbs.NativeSQLStore, dbs.ChanStateDB,
+			cfg.KeepFailedPaymentAttempts,
+		)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		err := dbs.ChanStateDB.EnsureNoPaymentsTombstone()
+		if err != nil {
+			return nil, fmt.Errorf("%w, db.migrate-payments-to-sql "+
+				"must stay enabled", err)
+		}
+
+		s.paymentsDB = payments.NewKVStore(dbs.ChanStateDB)
+	}
+
+	s.controlTower = routing.NewControlTower(s.paymentsDB)
+
+	s.labelStore, err = labels.NewStore(dbs.ChanStateDB)
+	if err != nil {
+		return nil, err
+	}
 
-	s.controlTower = routing.NewControlTower(paymentControl)
+	// Accounts are only available if native SQL tables are used.
+	if dbs.NativeSQLStore != nil {
+		s.accountStore, s.accounts = newAccountService(
+			dbs.NativeSQLStore, s.invoices, s.controlTower,
+			cfg.ActiveNetParams.Params,
+		)
+	}
 
 	strictPruning := (cfg.Bitcoin.Node == "neutrino" ||
 		cfg.Routing.StrictZombiePruning)
@@ -1039,6 +1161,41 @@ func newServer(cfg *Config, listenAddrs []net.Addr,
 		FetchChannel:              s.chanStateDB.FetchChannel,
 	}
 
+	s.feeManager, err = feemanager.New(&feemanager.Config{
+		Active: cfg.FeeManager.Active,
+		Strategies: []feemanager.Strategy{
+			&feemanager.LiquidityStrategy{
+				Exponent: feemanager.DefaultLiquidityExponent,
+			},
+			&feemanager.VelocityStrategy{
+				TargetVolume: cfg.FeeManager.VelocityTarget,
+				Step:         feemanager.DefaultVelocityStep,
+			},
+		},
+		Strategy:          cfg.FeeManager.Strategy,
+		Interval:          cfg.FeeManager.Interval,
+		MinUpdateInterval: cfg.FeeManager.MinUpdateInterval,
+		MinChange:         cfg.FeeManager.MinChange,
+		Bounds: feemanager.Bounds{
+			MinFeeRate: cfg.FeeManager.MinFeeRate,
+			MaxFeeRate: cfg.FeeManager.MaxFeeRate,
+			MinBaseFee: lnwire.MilliSatoshi(
+				cfg.FeeManager.MinBaseFee,
+			),
+			MaxBaseFee: lnwire.MilliSatoshi(
+				cfg.FeeManager.MaxBaseFee,
+			),
+		},
+		VelocityWindow:   cfg.FeeManager.VelocityWindow,
+		Channels:         s.feeManagerChannels,
+		ForwardingEvents: s.forwardingEvents,
+		UpdatePolicy:     s.localChanMgr.UpdatePolicy,
+		Clock:            clock.NewDefaultClock(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	utxnStore, err := contractcourt.NewNurseryStore(
 		s.cfg.ActiveNetParams.GenesisHash, dbs.ChanStateDB,
 	)
@@ -1072,6 +1229,7 @@ func newServer(cfg *Config, listenAddrs []net.Addr,
 		MaxSweepAttempts:     sweep.DefaultMaxSweepAttempts,
 		NextAttemptDeltaFunc: sweep.DefaultNextAttemptDeltaFunc,
 		MaxFeeRate:           cfg.Sweeper.MaxFeeRate,
+		PublishPackage:       cc.PublishPackage,
 		FeeRateBucketSize:    sweep.DefaultFeeRateBucketSize,
 	})
 
@@ -1158,6 +1316,7 @@ func newServer(cfg *Config, listenAddrs []net.Addr,
 		Signer:       cc.Wallet.Cfg.Signer,
 		FeeEstimator: cc.FeeEstimator,
 		ChainIO:      cc.ChainIO,
+		PackageRelay: cc.PublishPackage != nil,
 		MarkLinkInactive: func(chanPoint wire.OutPoint) error {
 			chanID := lnwire.NewChanIDFromOutPoint(&chanPoint)
 			s.htlcSwitch.RemoveLink(chanID)
@@ -1476,6 +1635,13 @@ func newServer(cfg *Config, listenAddrs []net.Addr,
 		return nil, err
 	}
 
+	if cfg.Webhook.Active {
+		s.webhookManager, err = s.newWebhookManager(cfg.Webhook)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Assemble a peer notifier which will provide clients with subscriptions
 	// to peer online and offline events.
 	s.peerNotifier = peernotifier.New()
@@ -1900,6 +2066,14 @@ func (s *server) Start() error {
 		}
 		cleanup = cleanup.add(s.cc.ChainNotifier.Stop)
 
+		if s.cc.TxTracker != nil {
+			if err := s.cc.TxTracker.Start(); err != nil {
+				startErr = err
+				return
+			}
+			cleanup = cleanup.add(s.cc.TxTracker.Stop)
+		}
+
 		if err := s.channelNotifier.Start(); err != nil {
 			startErr = err
 			return
@@ -1997,6 +2171,14 @@ func (s *server) Start() error {
 		}
 		cleanup = cleanup.add(s.invoices.Stop)
 
+		if s.accounts != nil {
+			if err := s.accounts.Start(); err != nil {
+				startErr = err
+				return
+			}
+			cleanup = cleanup.add(s.accounts.Stop)
+		}
+
 		if err := s.sphinx.Start(); err != nil {
 			startErr = err
 			return
@@ -2018,6 +2200,20 @@ func (s *server) Start() error {
 			return nil
 		})
 
+		if err := s.feeManager.Start(); err != nil {
+			startErr = err
+			return
+		}
+		cleanup = cleanup.add(s.feeManager.Stop)
+
+		if s.webhookManager != nil {
+			if err := s.webhookManager.Start(); err != nil {
+				startErr = err
+				return
+			}
+			cleanup = cleanup.add(s.webhookManager.Stop)
+		}
+
 		s.missionControl.RunStoreTicker()
 		cleanup.add(func() error {
 			s.missionControl.StopStoreTicker()
@@ -2242,6 +2438,12 @@ func (s *server) Stop() error {
 		if err := s.sphinx.Stop(); err != nil {
 			srvrLog.Warnf("failed to stop sphinx: %v", err)
 		}
+		if s.accounts != nil {
+			if err := s.accounts.Stop(); err != nil {
+				srvrLog.Warnf("failed to stop accounts: %v",
+					err)
+			}
+		}
 		if err := s.invoices.Stop(); err != nil {
 			srvrLog.Warnf("failed to stop invoices: %v", err)
 		}
@@ -2278,10 +2480,25 @@ func (s *server) Stop() error {
 		if err := s.chanSubSwapper.Stop(); err != nil {
 			srvrLog.Warnf("failed to stop chanSubSwapper: %v", err)
 		}
+		if s.cc.TxTracker != nil {
+			if err := s.cc.TxTracker.Stop(); err != nil {
+				srvrLog.Warnf("Unable to stop TxTracker: %v",
+					err)
+			}
+		}
 		if err := s.cc.ChainNotifier.Stop(); err != nil {
 			srvrLog.Warnf("Unable to stop ChainNotifier: %v", err)
 		}
 		s.chanEventStore.Stop()
+		if err := s.feeManager.Stop(); err != nil {
+			srvrLog.Warnf("failed to stop feeManager: %v", err)
+		}
+		if s.webhookManager != nil {
+			if err := s.webhookManager.Stop(); err != nil {
+				srvrLog.Warnf("failed to stop webhookManager: "+
+					"%v", err)
+			}
+		}
 		s.missionControl.StopStoreTicker()
 
 		// Disconnect from each active peers to ensure that
@@ -2939,6 +3156,154 @@ func (s *server) findChannel(node *btcec.PublicKey, chanID lnwire.ChannelID) (
 	return nil, fmt.Errorf("unable to find channel")
 }
 
+// newWebhookManager creates the manager that delivers node events to the
+// webhook endpoints.
+func (s *server) newWebhookManager(
+	cfg *lncfg.Webhook) (*webhook.Manager, error) {
+
+	endpoints := make([]*webhook.EndpointConfig, 0, len(cfg.Endpoints))
+	for _, endpoint := range cfg.Endpoints {
+		endpointCfg, err := webhook.ParseEndpointConfig(endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid webhook.endpoint %v: %w",
+				endpoint, err)
+		}
+		endpoints = append(endpoints, endpointCfg)
+	}
+
+	store, err := webhook.NewStore(
+		s.chanStateDB.GetParentDB(), cfg.DeliveryHistory,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return webhook.New(&webhook.Config{
+		Store:          store,
+		Endpoints:      endpoints,
+		Secret:         []byte(cfg.Secret),
+		MaxAttempts:    cfg.MaxAttempts,
+		InitialBackoff: cfg.InitialBackoff,
+		MaxBackoff:     cfg.MaxBackoff,
+		Timeout:        cfg.Timeout,
+		SubscribeChannelEvents: func() (subscribe.Subscription, error) {
+			return s.channelNotifier.SubscribeChannelEvents()
+		},
+		SubscribeHtlcEvents: func() (subscribe.Subscription, error) {
+			return s.htlcNotifier.SubscribeHtlcEvents()
+		},
+		SubscribeSettledInvoices: func(ctx context.Context) (
+			<-chan *invoices.Invoice, func(), error) {
+
+			return subscribeSettledInvoices(ctx, s.invoices)
+		},
+		SubscribePayments:  s.controlTower.SubscribeAllPayments,
+		FetchChannelBackup: s.webhookChannelBackup,
+		Clock:              clock.NewDefaultClock(),
+	}), nil
+}
+
+// webhookChannelBackup returns the number of our channels together with the
+// packed multi-channel backup of all of them.
+func (s *server) webhookChannelBackup() (int, []byte, error) {
+	backups, err := chanbackup.FetchStaticChanBackups(
+		s.chanStateDB, s.addrSource,
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var b bytes.Buffer
+	multi := chanbackup.Multi{
+		StaticBackups: backups,
+	}
+	if err := multi.PackToWriter(&b, s.cc.KeyRing); err != nil {
+		return 0, nil, err
+	}
+
+	return len(backups), b.Bytes(), nil
+}
+
+// feeManagerChannels returns our open channels together with the fees
+// currently advertised for them.
+func (s *server) feeManagerChannels() ([]feemanager.Channel, error) {
+	openChans, err := s.chanStateDB.FetchAllOpenChannels()
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make(map[wire.OutPoint]btcutil.Amount, len(openChans))
+	for _, channel := range openChans {
+		balances[channel.FundingOutpoint] =
+			channel.LocalCommitment.LocalBalance.ToSatoshis()
+	}
+
+	var channels []feemanager.Channel
+	err = s.chanRouter.ForAllOutgoingChannels(func(_ kvdb.RTx,
+		info *channeldb.ChannelEdgeInfo,
+		edge *channeldb.ChannelEdgePolicy) error {
+
+		// Skip channels that aren't open anymore or don't have a
+		// policy yet.
+		balance, ok := balances[info.ChannelPoint]
+		if !ok || edge == nil {
+			return nil
+		}
+
+		chanID := lnwire.NewShortChanIDFromInt(info.ChannelID)
+		channels = append(channels, feemanager.Channel{
+			ChanPoint:    info.ChannelPoint,
+			ChanID:       chanID,
+			Capacity:     info.Capacity,
+			LocalBalance: balance,
+			Fee: routing.FeeSchema{
+				BaseFee: edge.FeeBaseMSat,
+				FeeRate: uint32(edge.FeeProportionalMillionths),
+			},
+			TimeLockDelta: uint32(edge.TimeLockDelta),
+		})
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return channels, nil
+}
+
+// forwardingEvents returns all payments forwarded during the given time
+// range.
+func (s *server) forwardingEvents(start,
+	end time.Time) ([]channeldb.ForwardingEvent, error) {
+
+	const maxEvents = 50000
+
+	var (
+		events []channeldb.ForwardingEvent
+		offset uint32
+	)
+	for {
+		timeSlice, err := s.miscDB.ForwardingLog().Query(
+			channeldb.ForwardingEventQuery{
+				StartTime:    start,
+				EndTime:      end,
+				IndexOffset:  offset,
+				NumMaxEvents: maxEvents,
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, timeSlice.ForwardingEvents...)
+		if len(timeSlice.ForwardingEvents) < maxEvents {
+			return events, nil
+		}
+		offset = timeSlice.LastIndexOffset
+	}
+}
+
 // getNodeAnnouncement fetches the current, fully signed node announcement.
 func (s *server) getNodeAnnouncement() lnwire.NodeAnnouncement {
 	s.mu.Lock()
@@ -3795,6 +4160,14 @@ func (s *server) peerConnected(conn net.Conn, connReq *connmgr.ConnReq,
 		}
 	}
 
+	// The links of the peer's channels limit their fee exposure.
+	maxFeeExposure := lnwallet.FeeExposureLimit{
+		Max: lnwire.NewMSatFromSatoshis(
+			btcutil.Amount(s.cfg.ChannelMaxFeeExposure),
+		),
+		CommitFeeMultiplier: s.cfg.ChannelMaxFeeExposureMultiplier,
+	}
+
 	// Now that we've established a connection, create a peer, and it to the
 	// set of currently active peers. Configure the peer with the incoming
 	// and outgoing broadcast deltas to prevent htlcs from being accepted or
@@ -3854,8 +4227,10 @@ func (s *server) peerConnected(conn net.Conn, connReq *connmgr.ConnReq,
 		MaxOutgoingCltvExpiry:   s.cfg.MaxOutgoingCltvExpiry,
 		MaxChannelFeeAllocation: s.cfg.MaxChannelFeeAllocation,
 		CoopCloseTargetConfs:    s.cfg.CoopCloseTargetConfs,
+		MaxLocalCSVDelay:        s.cfg.Bitcoin.MaxLocalDelay,
 		MaxAnchorsCommitFeeRate: chainfee.SatPerKVByte(
 			s.cfg.MaxCommitFeeRateAnchors * 1000).FeePerKWeight(),
+		MaxFeeExposure:         maxFeeExposure,
 		ChannelCommitInterval:  s.cfg.ChannelCommitInterval,
 		PendingCommitInterval:  s.cfg.PendingCommitInterval,
 		ChannelCommitBatchSize: s.cfg.ChannelCommitBatchSize,
@@ -4696,6 +5071,111 @@ func newSweepPkScriptGen(
 	}
 }
 
+// newAccountService creates the account store backed by the given native SQL
+// database and the service that enforces the accounts.
+func newAccountService(db *sqldb.BaseDB, registry *invoices.InvoiceRegistry,
+	controlTower routing.ControlTower,
+	chainParams *chaincfg.Params) (accounts.Store,
+	*accounts.InterceptorService) {
+
+	executor := sqldb.NewTransactionExecutor(
+		db, func(tx *sql.Tx) accounts.SQLQueries {
+			return db.WithTx(tx)
+		},
+	)
+	store := accounts.NewSQLStore(executor, clock.NewDefaultClock())
+
+	service := accounts.NewInterceptorService(&accounts.Config{
+		Store:       store,
+		ChainParams: chainParams,
+		SubscribeSettledInvoices: func(ctx context.Context) (
+			<-chan *invoices.Invoice, func(), error) {
+
+			return subscribeSettledInvoices(ctx, registry)
+		},
+		LookupInvoice: registry.LookupInvoice,
+		SubscribePayment: func(hash lntypes.Hash) (
+			accounts.PaymentSubscriber, error) {
+
+			return controlTower.SubscribePayment(hash)
+		},
+		Clock: clock.NewDefaultClock(),
+	})
+
+	return store, service
+}
+
+// newSQLPaymentsDB creates the payments DB that is backed by the native SQL
+// tables and migrates the payments of the key-value database to it if that
+// hasn't been done yet.
+func newSQLPaymentsDB(db *sqldb.BaseDB, kvDB *channeldb.DB,
+	keepFailedPaymentAttempts bool) (*payments.SQLStore, error) {
+
+	executor := sqldb.NewTransactionExecutor(
+		db, func(tx *sql.Tx) payments.SQLQueries {
+			return db.WithTx(tx)
+		},
+	)
+
+	err := payments.MigrateFromKV(context.Background(), kvDB, executor)
+	if err != nil {
+		return nil, fmt.Errorf("unable to migrate payments to SQL: %w",
+			err)
+	}
+
+	return payments.NewSQLStore(executor, keepFailedPaymentAttempts), nil
+}
+
+// subscribeSettledInvoices subscribes to all invoices that are settled from
+// now on. The returned function cancels the subscription.
+func subscribeSettledInvoices(ctx context.Context,
+	registry *invoices.InvoiceRegistry) (<-chan *invoices.Invoice, func(),
+	error) {
+
+	sub, err := registry.SubscribeNotifications(ctx, 0, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	settled := make(chan *invoices.Invoice)
+	quit := make(chan struct{})
+	go func() {
+		defer close(settled)
+
+		for {
+			select {
+			case invoice, ok := <-sub.SettledInvoices:
+				if !ok {
+					return
+				}
+
+				select {
+				case settled <- invoice:
+				case <-quit:
+					return
+				}
+
+			// New invoices are of no interest, but we need to
+			// receive them to not block the subscription.
+			case <-sub.NewInvoices:
+
+			case <-quit:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			close(quit)
+			sub.Cancel()
+		})
+	}
+
+	return settled, cancel, nil
+}
+
 // shouldPeerBootstrap returns true if we should attempt to perform peer
 // bootstrapping to actively seek our peers using the set of active network
 // bootstrappers.
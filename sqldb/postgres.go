@@ -3,6 +3,7 @@ package sqldb
 import (
 	"database/sql"
 	"fmt"
+	"net/url"
 	"testing"
 	"time"
 
@@ -29,6 +30,7 @@ var (
 //
 //nolint:lll
 type PostgresConfig struct {
+	Dsn                string `long:"dsn" description:"Database connection string. If set, the individual connection options are ignored."`
 	SkipMigrations     bool   `long:"skipmigrations" description:"Skip applying migrations on startup."`
 	Host               string `long:"host" description:"Database server hostname."`
 	Port               int    `long:"port" description:"Database server port."`
@@ -41,6 +43,10 @@ type PostgresConfig struct {
 
 // DSN returns the dns to connect to the database.
 func (s *PostgresConfig) DSN(hidePassword bool) string {
+	if s.Dsn != "" {
+		return dsnWithPassword(s.Dsn, hidePassword)
+	}
+
 	var sslMode = "disable"
 	if s.RequireSSL {
 		sslMode = "require"
@@ -56,6 +62,26 @@ func (s *PostgresConfig) DSN(hidePassword bool) string {
 		s.DBName, sslMode)
 }
 
+// dsnWithPassword returns the given connection string, with the password
+// replaced by a placeholder if hidePassword is set.
+func dsnWithPassword(dsn string, hidePassword bool) string {
+	if !hidePassword {
+		return dsn
+	}
+
+	u, err := url.Parse(dsn)
+	if err != nil {
+		// We can't tell where the password is, so we don't log any of
+		// the connection string.
+		return "****"
+	}
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), "****")
+	}
+
+	return u.String()
+}
+
 // PostgresStore is a database store implementation that uses a Postgres
 // backend.
 type PostgresStore struct {
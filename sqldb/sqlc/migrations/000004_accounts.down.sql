@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS account_ledger_account_id_idx;
+DROP TABLE IF EXISTS account_ledger;
+
+DROP INDEX IF EXISTS account_payments_status_idx;
+DROP INDEX IF EXISTS account_payments_account_id_idx;
+DROP TABLE IF EXISTS account_payments;
+
+DROP INDEX IF EXISTS account_invoices_account_id_idx;
+DROP TABLE IF EXISTS account_invoices;
+
+DROP TABLE IF EXISTS accounts;
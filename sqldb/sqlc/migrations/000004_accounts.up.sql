@@ -0,0 +1,93 @@
+-- accounts stores the virtual accounts that split the off-chain balance of
+-- the node between multiple users.
+CREATE TABLE IF NOT EXISTS accounts (
+    id INTEGER PRIMARY KEY,
+
+    -- alias is the public 8 byte ID of the account, which is also the value
+    -- of the account caveat of all macaroons bound to the account.
+    alias BIGINT NOT NULL UNIQUE,
+
+    -- label is an optional human readable label of the account.
+    label TEXT UNIQUE,
+
+    -- initial_balance_msat is the balance the account was created with.
+    initial_balance_msat BIGINT NOT NULL,
+
+    -- current_balance_msat is the current balance of the account. Amounts
+    -- reserved for payments in flight are not deducted from it yet.
+    current_balance_msat BIGINT NOT NULL,
+
+    -- last_updated is the time the account was last changed.
+    last_updated TIMESTAMP NOT NULL,
+
+    -- expiration is the time after which the account can no longer be used.
+    -- It is null if the account never expires.
+    expiration TIMESTAMP,
+
+    -- created_at is the time the account was created.
+    created_at TIMESTAMP NOT NULL
+);
+
+-- account_invoices stores the hashes of the invoices that were created with
+-- the macaroon of an account.
+CREATE TABLE IF NOT EXISTS account_invoices (
+    -- account_id is the account the invoice belongs to.
+    account_id INTEGER NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+
+    -- hash is the payment hash of the invoice.
+    hash BLOB NOT NULL UNIQUE,
+
+    -- credited is true once the amount paid to the invoice was added to the
+    -- balance of the account.
+    credited BOOLEAN NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS account_invoices_account_id_idx ON account_invoices(account_id);
+
+-- account_payments stores the payments that were sent with the macaroon of an
+-- account.
+CREATE TABLE IF NOT EXISTS account_payments (
+    -- account_id is the account the payment belongs to.
+    account_id INTEGER NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+
+    -- hash is the payment hash of the payment.
+    hash BLOB NOT NULL UNIQUE,
+
+    -- status is the status of the payment.
+    status SMALLINT NOT NULL,
+
+    -- full_amount_msat is the amount that is reserved for the payment,
+    -- including the maximum fee, while it is in flight. Once the payment
+    -- succeeded, it is the amount that was actually paid, including fees.
+    full_amount_msat BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS account_payments_account_id_idx ON account_payments(account_id);
+CREATE INDEX IF NOT EXISTS account_payments_status_idx ON account_payments(status);
+
+-- account_ledger stores every change of the balance of an account.
+CREATE TABLE IF NOT EXISTS account_ledger (
+    id INTEGER PRIMARY KEY,
+
+    -- account_id is the account the entry belongs to.
+    account_id INTEGER NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+
+    -- entry_type is the type of the balance change.
+    entry_type SMALLINT NOT NULL,
+
+    -- amount_msat is the amount the balance changed by. It is negative for
+    -- debits.
+    amount_msat BIGINT NOT NULL,
+
+    -- balance_msat is the balance of the account after the change.
+    balance_msat BIGINT NOT NULL,
+
+    -- hash is the payment hash of the invoice or payment that changed the
+    -- balance, may be null.
+    hash BLOB,
+
+    -- created_at is the time of the balance change.
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS account_ledger_account_id_idx ON account_ledger(account_id);
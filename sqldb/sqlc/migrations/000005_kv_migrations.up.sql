@@ -0,0 +1,10 @@
+-- kv_migrations records the subsystems whose data was migrated from the
+-- key-value database to the native SQL tables, so that each migration only
+-- runs once.
+CREATE TABLE IF NOT EXISTS kv_migrations (
+    -- name is the unique name of the migrated subsystem.
+    name TEXT NOT NULL PRIMARY KEY,
+
+    -- migrated_at is the time the migration was completed.
+    migrated_at TIMESTAMP NOT NULL
+);
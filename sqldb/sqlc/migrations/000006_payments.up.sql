@@ -0,0 +1,110 @@
+-- payment_sequence holds the sequence number that was last assigned to a
+-- payment. It only ever has a single row, which makes sure sequence numbers
+-- aren't reused if the most recent payments are deleted.
+CREATE TABLE IF NOT EXISTS payment_sequence (
+    id INTEGER PRIMARY KEY CHECK (id = 0),
+
+    -- last_seq is the sequence number that was last assigned.
+    last_seq BIGINT NOT NULL
+);
+
+-- payments stores the payments sent by the node.
+CREATE TABLE IF NOT EXISTS payments (
+    id INTEGER PRIMARY KEY,
+
+    -- sequence_num is the unique sequence number of the payment, which is
+    -- used to sort and paginate the payments in the order they were created.
+    -- A payment that is retried after it failed gets a new sequence number.
+    sequence_num BIGINT NOT NULL UNIQUE,
+
+    -- hash is the payment hash, or the payment identifier of AMP payments.
+    hash BLOB NOT NULL UNIQUE,
+
+    -- amount_msat is the amount of the payment, excluding fees.
+    amount_msat BIGINT NOT NULL,
+
+    -- payment_request is the payment request that was paid, may be null.
+    payment_request BLOB,
+
+    -- rebalance is true for circular payments that move funds between our
+    -- own channels.
+    rebalance BOOLEAN NOT NULL,
+
+    -- status is the status of the payment, as derived from its HTLC
+    -- attempts and failure reason. It is kept up to date with every change
+    -- of the payment so that payments can be filtered by it.
+    status SMALLINT NOT NULL,
+
+    -- failure_reason is the reason the payment failed, it is null as long as
+    -- the payment wasn't failed.
+    failure_reason SMALLINT,
+
+    -- created_at is the time the payment was created.
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS payments_status_idx ON payments(status);
+CREATE INDEX IF NOT EXISTS payments_created_at_idx ON payments(created_at);
+
+-- payment_htlc_attempts stores the HTLCs that were sent to complete a
+-- payment.
+CREATE TABLE IF NOT EXISTS payment_htlc_attempts (
+    id INTEGER PRIMARY KEY,
+
+    -- payment_id is the payment the attempt belongs to.
+    payment_id INTEGER NOT NULL REFERENCES payments(id) ON DELETE CASCADE,
+
+    -- attempt_id is the ID the router assigned to the attempt.
+    attempt_id BIGINT NOT NULL,
+
+    -- session_key is the ephemeral key that was used for the onion of the
+    -- attempt.
+    session_key BLOB NOT NULL,
+
+    -- route is the serialized route the attempt was sent along.
+    route BLOB NOT NULL,
+
+    -- destination is the public key of the final hop of the route.
+    destination BLOB NOT NULL,
+
+    -- amount_msat is the amount the attempt delivers to the destination.
+    amount_msat BIGINT NOT NULL,
+
+    -- fee_msat is the amount of fees the attempt pays along the route.
+    fee_msat BIGINT NOT NULL,
+
+    -- hash is the payment hash of the attempt, which differs between the
+    -- attempts of an AMP payment. It is null for attempts of payments that
+    -- were created before per attempt hashes were introduced.
+    hash BLOB,
+
+    -- attempt_time is the time the attempt was sent.
+    attempt_time TIMESTAMP NOT NULL,
+
+    -- settle_preimage is the preimage the attempt was settled with. It is
+    -- null as long as the attempt isn't settled.
+    settle_preimage BLOB,
+
+    -- settle_time is the time the attempt was settled, may be null.
+    settle_time TIMESTAMP,
+
+    -- fail_reason is the reason the attempt failed. It is null as long as
+    -- the attempt didn't fail.
+    fail_reason SMALLINT,
+
+    -- fail_message is the encoded wire failure message of a failed attempt,
+    -- may be null.
+    fail_message BLOB,
+
+    -- fail_source_index is the position in the route of the node that
+    -- generated the failure, may be null.
+    fail_source_index BIGINT,
+
+    -- fail_time is the time the attempt failed, may be null.
+    fail_time TIMESTAMP,
+
+    UNIQUE (payment_id, attempt_id)
+);
+
+CREATE INDEX IF NOT EXISTS payment_htlc_attempts_payment_id_idx ON payment_htlc_attempts(payment_id);
+CREATE INDEX IF NOT EXISTS payment_htlc_attempts_destination_idx ON payment_htlc_attempts(destination);
@@ -0,0 +1,169 @@
+-- graph_nodes stores the nodes of the channel graph. A node without an
+-- announcement only has its public key set.
+CREATE TABLE IF NOT EXISTS graph_nodes (
+    id INTEGER PRIMARY KEY,
+
+    -- pub_key is the compressed identity public key of the node.
+    pub_key BLOB NOT NULL UNIQUE,
+
+    -- have_announcement is true if a node announcement was received for
+    -- the node.
+    have_announcement BOOLEAN NOT NULL,
+
+    -- last_update is the unix timestamp of the last node announcement.
+    last_update BIGINT NOT NULL,
+
+    -- color is the RGB color the node announced, may be null.
+    color BLOB,
+
+    -- alias is the alias the node announced, may be null.
+    alias TEXT,
+
+    -- features is the encoded feature vector of the node, may be null.
+    features BLOB,
+
+    -- addresses is the serialized list of the addresses the node
+    -- announced, may be null.
+    addresses BLOB,
+
+    -- auth_sig is the signature of the node announcement, may be null.
+    auth_sig BLOB,
+
+    -- extra_opaque_data is the data of the node announcement that isn't
+    -- parsed, may be null.
+    extra_opaque_data BLOB
+);
+
+CREATE INDEX IF NOT EXISTS graph_nodes_last_update_idx ON graph_nodes(last_update);
+
+-- graph_source_node holds the public key of our own node, which is the
+-- source of all path finding queries. It only ever has a single row.
+CREATE TABLE IF NOT EXISTS graph_source_node (
+    id INTEGER PRIMARY KEY CHECK (id = 0),
+
+    -- pub_key is the compressed identity public key of our node.
+    pub_key BLOB NOT NULL
+);
+
+-- graph_channels stores the channels of the channel graph.
+CREATE TABLE IF NOT EXISTS graph_channels (
+    id INTEGER PRIMARY KEY,
+
+    -- scid is the short channel ID of the channel, encoded as 8 bytes in
+    -- big endian, so that channels are sorted by their block height.
+    scid BLOB NOT NULL UNIQUE,
+
+    -- chain_hash is the genesis hash of the chain the channel was opened
+    -- on.
+    chain_hash BLOB NOT NULL,
+
+    -- node_key_1 is the public key of the node with the smaller public key.
+    node_key_1 BLOB NOT NULL,
+
+    -- node_key_2 is the public key of the node with the larger public key.
+    node_key_2 BLOB NOT NULL,
+
+    -- bitcoin_key_1 is the funding key of the first node.
+    bitcoin_key_1 BLOB NOT NULL,
+
+    -- bitcoin_key_2 is the funding key of the second node.
+    bitcoin_key_2 BLOB NOT NULL,
+
+    -- features is the raw feature vector of the channel, may be null.
+    features BLOB,
+
+    -- The signatures of the channel announcement. They are null for
+    -- channels that weren't announced.
+    node_sig_1 BLOB,
+    node_sig_2 BLOB,
+    bitcoin_sig_1 BLOB,
+    bitcoin_sig_2 BLOB,
+
+    -- outpoint is the serialized funding outpoint of the channel.
+    outpoint BLOB NOT NULL,
+
+    -- capacity is the capacity of the channel in satoshis.
+    capacity BIGINT NOT NULL,
+
+    -- extra_opaque_data is the data of the channel announcement that isn't
+    -- parsed, may be null.
+    extra_opaque_data BLOB
+);
+
+CREATE INDEX IF NOT EXISTS graph_channels_node_key_1_idx ON graph_channels(node_key_1);
+CREATE INDEX IF NOT EXISTS graph_channels_node_key_2_idx ON graph_channels(node_key_2);
+CREATE INDEX IF NOT EXISTS graph_channels_outpoint_idx ON graph_channels(outpoint);
+
+-- graph_channel_policies stores the routing policies of the channels, one
+-- for each direction.
+CREATE TABLE IF NOT EXISTS graph_channel_policies (
+    id INTEGER PRIMARY KEY,
+
+    -- channel_id is the channel the policy belongs to.
+    channel_id INTEGER NOT NULL REFERENCES graph_channels(id) ON DELETE CASCADE,
+
+    -- direction is 0 for the policy of the first node and 1 for the policy
+    -- of the second node.
+    direction SMALLINT NOT NULL,
+
+    -- last_update is the unix timestamp of the channel update.
+    last_update BIGINT NOT NULL,
+
+    -- message_flags and channel_flags are the flags of the channel update.
+    message_flags SMALLINT NOT NULL,
+    channel_flags SMALLINT NOT NULL,
+
+    -- disabled is true if the channel flags have the disabled bit set.
+    disabled BOOLEAN NOT NULL,
+
+    -- timelock_delta is the CLTV delta the node requires.
+    timelock_delta INTEGER NOT NULL,
+
+    -- min_htlc_msat is the smallest HTLC the node forwards.
+    min_htlc_msat BIGINT NOT NULL,
+
+    -- max_htlc_msat is the largest HTLC the node forwards, it is null if
+    -- the update doesn't have one.
+    max_htlc_msat BIGINT,
+
+    -- fee_base_msat is the base fee the node charges.
+    fee_base_msat BIGINT NOT NULL,
+
+    -- fee_ppm is the proportional fee the node charges in millionths.
+    fee_ppm BIGINT NOT NULL,
+
+    -- signature is the signature of the channel update, may be null.
+    signature BLOB,
+
+    -- extra_opaque_data is the data of the channel update that isn't parsed,
+    -- may be null.
+    extra_opaque_data BLOB,
+
+    UNIQUE (channel_id, direction)
+);
+
+CREATE INDEX IF NOT EXISTS graph_channel_policies_last_update_idx ON graph_channel_policies(last_update);
+CREATE INDEX IF NOT EXISTS graph_channel_policies_disabled_idx ON graph_channel_policies(disabled);
+
+-- graph_zombie_channels stores the channels that were marked as zombies, so
+-- that they aren't added to the graph again.
+CREATE TABLE IF NOT EXISTS graph_zombie_channels (
+    -- scid is the short channel ID of the channel, encoded as 8 bytes in
+    -- big endian.
+    scid BLOB NOT NULL PRIMARY KEY,
+
+    -- node_key_1 and node_key_2 are the public keys of the nodes that can
+    -- resurrect the channel. A key is all zeros if the node can't.
+    node_key_1 BLOB NOT NULL,
+    node_key_2 BLOB NOT NULL
+);
+
+-- graph_prune_log stores the blocks the graph was pruned with, so that the
+-- graph can be rewound if a block is disconnected.
+CREATE TABLE IF NOT EXISTS graph_prune_log (
+    -- block_height is the height of the block.
+    block_height BIGINT NOT NULL PRIMARY KEY,
+
+    -- block_hash is the hash of the block.
+    block_hash BLOB NOT NULL
+);
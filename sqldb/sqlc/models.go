@@ -9,6 +9,40 @@ import (
 	"time"
 )
 
+type Account struct {
+	ID                 int32
+	Alias              int64
+	Label              sql.NullString
+	InitialBalanceMsat int64
+	CurrentBalanceMsat int64
+	LastUpdated        time.Time
+	Expiration         sql.NullTime
+	CreatedAt          time.Time
+}
+
+type AccountInvoice struct {
+	AccountID int32
+	Hash      []byte
+	Credited  bool
+}
+
+type AccountLedger struct {
+	ID          int32
+	AccountID   int32
+	EntryType   int16
+	AmountMsat  int64
+	BalanceMsat int64
+	Hash        []byte
+	CreatedAt   time.Time
+}
+
+type AccountPayment struct {
+	AccountID      int32
+	Hash           []byte
+	Status         int16
+	FullAmountMsat int64
+}
+
 type AmpInvoiceHtlc struct {
 	SetID      []byte
 	HtlcID     int64
@@ -27,6 +61,70 @@ type AmpInvoicePayment struct {
 	InvoiceID    int32
 }
 
+type GraphChannel struct {
+	ID              int32
+	Scid            []byte
+	ChainHash       []byte
+	NodeKey1        []byte
+	NodeKey2        []byte
+	BitcoinKey1     []byte
+	BitcoinKey2     []byte
+	Features        []byte
+	NodeSig1        []byte
+	NodeSig2        []byte
+	BitcoinSig1     []byte
+	BitcoinSig2     []byte
+	Outpoint        []byte
+	Capacity        int64
+	ExtraOpaqueData []byte
+}
+
+type GraphChannelPolicy struct {
+	ID              int32
+	ChannelID       int32
+	Direction       int16
+	LastUpdate      int64
+	MessageFlags    int16
+	ChannelFlags    int16
+	Disabled        bool
+	TimelockDelta   int32
+	MinHtlcMsat     int64
+	MaxHtlcMsat     sql.NullInt64
+	FeeBaseMsat     int64
+	FeePpm          int64
+	Signature       []byte
+	ExtraOpaqueData []byte
+}
+
+type GraphNode struct {
+	ID               int32
+	PubKey           []byte
+	HaveAnnouncement bool
+	LastUpdate       int64
+	Color            []byte
+	Alias            sql.NullString
+	Features         []byte
+	Addresses        []byte
+	AuthSig          []byte
+	ExtraOpaqueData  []byte
+}
+
+type GraphPruneLog struct {
+	BlockHeight int64
+	BlockHash   []byte
+}
+
+type GraphSourceNode struct {
+	ID     int32
+	PubKey []byte
+}
+
+type GraphZombieChannel struct {
+	Scid     []byte
+	NodeKey1 []byte
+	NodeKey2 []byte
+}
+
 type Invoice struct {
 	ID             int32
 	Hash           []byte
@@ -91,3 +189,44 @@ type InvoicePayment struct {
 	AmountPaidMsat int64
 	InvoiceID      int32
 }
+
+type KvMigration struct {
+	Name       string
+	MigratedAt time.Time
+}
+
+type Payment struct {
+	ID             int32
+	SequenceNum    int64
+	Hash           []byte
+	AmountMsat     int64
+	PaymentRequest []byte
+	Rebalance      bool
+	Status         int16
+	FailureReason  sql.NullInt16
+	CreatedAt      time.Time
+}
+
+type PaymentHtlcAttempt struct {
+	ID              int32
+	PaymentID       int32
+	AttemptID       int64
+	SessionKey      []byte
+	Route           []byte
+	Destination     []byte
+	AmountMsat      int64
+	FeeMsat         int64
+	Hash            []byte
+	AttemptTime     time.Time
+	SettlePreimage  []byte
+	SettleTime      sql.NullTime
+	FailReason      sql.NullInt16
+	FailMessage     []byte
+	FailSourceIndex sql.NullInt64
+	FailTime        sql.NullTime
+}
+
+type PaymentSequence struct {
+	ID      int32
+	LastSeq int64
+}
@@ -6,22 +6,50 @@ package sqlc
 
 import (
 	"context"
+	"database/sql"
 )
 
 type Querier interface {
+	CountGraphZombieChannels(ctx context.Context) (int64, error)
+	CountPayments(ctx context.Context) (int64, error)
+	CountPaymentsByStatus(ctx context.Context, arg CountPaymentsByStatusParams) ([]CountPaymentsByStatusRow, error)
 	DeleteAMPHTLCCustomRecords(ctx context.Context, invoiceID int32) error
 	DeleteAMPHTLCs(ctx context.Context, invoiceID int32) error
 	DeleteAMPInvoiceHTLC(ctx context.Context, setID []byte) error
+	DeleteAccount(ctx context.Context, id int32) error
+	DeleteFailedPaymentHTLCAttempts(ctx context.Context, paymentID int32) error
+	DeleteGraphChannel(ctx context.Context, id int32) error
+	DeleteGraphNode(ctx context.Context, pubKey []byte) (int64, error)
+	DeleteGraphPruneLogEntries(ctx context.Context, blockHeight int64) error
+	DeleteGraphZombieChannel(ctx context.Context, scid []byte) error
 	DeleteInvoice(ctx context.Context, arg DeleteInvoiceParams) error
 	DeleteInvoiceEvents(ctx context.Context, invoiceID int32) error
 	DeleteInvoiceFeatures(ctx context.Context, invoiceID int32) error
 	DeleteInvoiceHTLC(ctx context.Context, htlcID int64) error
 	DeleteInvoiceHTLCCustomRecords(ctx context.Context, invoiceID int32) error
 	DeleteInvoiceHTLCs(ctx context.Context, invoiceID int32) error
+	DeletePayment(ctx context.Context, id int32) error
+	DeleteUnconnectedGraphNodes(ctx context.Context) ([][]byte, error)
+	FailPaymentHTLCAttempt(ctx context.Context, arg FailPaymentHTLCAttemptParams) error
 	FilterInvoicePayments(ctx context.Context, arg FilterInvoicePaymentsParams) ([]FilterInvoicePaymentsRow, error)
 	FilterInvoices(ctx context.Context, arg FilterInvoicesParams) ([]Invoice, error)
+	// The status mask is a bit field with the bit 1 << status set for each of
+	// the statuses that should be returned.
+	FilterPayments(ctx context.Context, arg FilterPaymentsParams) ([]Payment, error)
 	GetAMPInvoiceHTLCsByInvoiceID(ctx context.Context, invoiceID int32) ([]AmpInvoiceHtlc, error)
 	GetAMPInvoiceHTLCsBySetID(ctx context.Context, setID []byte) ([]AmpInvoiceHtlc, error)
+	GetAccount(ctx context.Context, id int32) (Account, error)
+	GetAccountByAlias(ctx context.Context, alias int64) (Account, error)
+	GetAccountByLabel(ctx context.Context, label sql.NullString) (Account, error)
+	GetAccountInvoice(ctx context.Context, hash []byte) (AccountInvoice, error)
+	GetAccountPayment(ctx context.Context, hash []byte) (AccountPayment, error)
+	GetGraphChannelByOutpoint(ctx context.Context, outpoint []byte) (GraphChannel, error)
+	GetGraphChannelBySCID(ctx context.Context, scid []byte) (GraphChannel, error)
+	GetGraphNode(ctx context.Context, pubKey []byte) (GraphNode, error)
+	GetGraphPruneTip(ctx context.Context) (GraphPruneLog, error)
+	GetGraphSourceNode(ctx context.Context) ([]byte, error)
+	GetGraphZombieChannel(ctx context.Context, scid []byte) (GraphZombieChannel, error)
+	GetHighestGraphChannelSCID(ctx context.Context) ([]byte, error)
 	// This method may return more than one invoice if filter using multiple fields
 	// from different invoices. It is the caller's responsibility to ensure that
 	// we bubble up an error in those cases.
@@ -30,22 +58,62 @@ type Querier interface {
 	GetInvoiceHTLCCustomRecords(ctx context.Context, invoiceID int32) ([]GetInvoiceHTLCCustomRecordsRow, error)
 	GetInvoiceHTLCs(ctx context.Context, invoiceID int32) ([]InvoiceHtlc, error)
 	GetInvoicePayments(ctx context.Context, invoiceID int32) ([]InvoicePayment, error)
+	GetKVMigration(ctx context.Context, name string) (KvMigration, error)
+	GetPaymentByHash(ctx context.Context, hash []byte) (Payment, error)
 	GetSetIDHTLCsCustomRecords(ctx context.Context, setID []byte) ([]GetSetIDHTLCsCustomRecordsRow, error)
 	InsertAMPInvoiceHTLC(ctx context.Context, arg InsertAMPInvoiceHTLCParams) error
 	InsertAMPInvoicePayment(ctx context.Context, arg InsertAMPInvoicePaymentParams) error
+	InsertAccount(ctx context.Context, arg InsertAccountParams) (int32, error)
+	InsertAccountInvoice(ctx context.Context, arg InsertAccountInvoiceParams) error
+	InsertAccountLedgerEntry(ctx context.Context, arg InsertAccountLedgerEntryParams) error
+	InsertGraphChannel(ctx context.Context, arg InsertGraphChannelParams) (int32, error)
+	InsertGraphNodeShell(ctx context.Context, pubKey []byte) error
 	InsertInvoice(ctx context.Context, arg InsertInvoiceParams) (int32, error)
 	InsertInvoiceEvent(ctx context.Context, arg InsertInvoiceEventParams) error
 	InsertInvoiceFeature(ctx context.Context, arg InsertInvoiceFeatureParams) error
 	InsertInvoiceHTLC(ctx context.Context, arg InsertInvoiceHTLCParams) error
 	InsertInvoiceHTLCCustomRecord(ctx context.Context, arg InsertInvoiceHTLCCustomRecordParams) error
 	InsertInvoicePayment(ctx context.Context, arg InsertInvoicePaymentParams) (int32, error)
+	InsertKVMigration(ctx context.Context, arg InsertKVMigrationParams) error
+	InsertPayment(ctx context.Context, arg InsertPaymentParams) (int32, error)
+	InsertPaymentHTLCAttempt(ctx context.Context, arg InsertPaymentHTLCAttemptParams) error
+	ListAccountInvoices(ctx context.Context, accountID int32) ([]AccountInvoice, error)
+	ListAccountLedgerEntries(ctx context.Context, accountID int32) ([]AccountLedger, error)
+	ListAccountPayments(ctx context.Context, accountID int32) ([]AccountPayment, error)
+	ListAccountPaymentsByStatus(ctx context.Context, status int16) ([]AccountPayment, error)
+	ListAccounts(ctx context.Context) ([]Account, error)
+	ListDisabledGraphChannels(ctx context.Context) ([][]byte, error)
+	ListGraphChannelPolicies(ctx context.Context, channelID int32) ([]GraphChannelPolicy, error)
+	ListGraphChannels(ctx context.Context, arg ListGraphChannelsParams) ([]GraphChannel, error)
+	ListGraphChannelsInRange(ctx context.Context, arg ListGraphChannelsInRangeParams) ([]GraphChannel, error)
+	ListGraphChannelsUpdatedInHorizon(ctx context.Context, arg ListGraphChannelsUpdatedInHorizonParams) ([]GraphChannel, error)
+	ListGraphNodeChannels(ctx context.Context, nodeKey []byte) ([]GraphChannel, error)
+	ListGraphNodes(ctx context.Context, arg ListGraphNodesParams) ([]GraphNode, error)
+	ListGraphNodesInHorizon(ctx context.Context, arg ListGraphNodesInHorizonParams) ([]GraphNode, error)
+	ListPaymentHTLCAttempts(ctx context.Context, paymentID int32) ([]PaymentHtlcAttempt, error)
+	ListUncreditedAccountInvoices(ctx context.Context) ([]AccountInvoice, error)
+	NextPaymentSequence(ctx context.Context) (int64, error)
 	SelectAMPInvoicePayments(ctx context.Context, arg SelectAMPInvoicePaymentsParams) ([]SelectAMPInvoicePaymentsRow, error)
 	SelectInvoiceEvents(ctx context.Context, arg SelectInvoiceEventsParams) ([]InvoiceEvent, error)
+	SetAccountInvoiceCredited(ctx context.Context, hash []byte) error
+	SetGraphSourceNode(ctx context.Context, pubKey []byte) error
+	SetPaymentSequence(ctx context.Context, lastSeq int64) error
+	SettlePaymentHTLCAttempt(ctx context.Context, arg SettlePaymentHTLCAttemptParams) error
+	SumSettledPaymentHTLCAttempts(ctx context.Context, arg SumSettledPaymentHTLCAttemptsParams) (SumSettledPaymentHTLCAttemptsRow, error)
 	UpdateAMPInvoiceHTLC(ctx context.Context, arg UpdateAMPInvoiceHTLCParams) error
 	UpdateAMPPayment(ctx context.Context, arg UpdateAMPPaymentParams) error
+	UpdateAccountBalance(ctx context.Context, arg UpdateAccountBalanceParams) error
+	UpdateAccountExpiration(ctx context.Context, arg UpdateAccountExpirationParams) error
+	UpdateGraphChannel(ctx context.Context, arg UpdateGraphChannelParams) (int64, error)
 	UpdateInvoice(ctx context.Context, arg UpdateInvoiceParams) error
 	UpdateInvoiceHTLC(ctx context.Context, arg UpdateInvoiceHTLCParams) error
 	UpdateInvoiceHTLCs(ctx context.Context, arg UpdateInvoiceHTLCsParams) error
+	UpdatePaymentStatus(ctx context.Context, arg UpdatePaymentStatusParams) error
+	UpsertAccountPayment(ctx context.Context, arg UpsertAccountPaymentParams) error
+	UpsertGraphChannelPolicy(ctx context.Context, arg UpsertGraphChannelPolicyParams) error
+	UpsertGraphNode(ctx context.Context, arg UpsertGraphNodeParams) error
+	UpsertGraphPruneLogEntry(ctx context.Context, arg UpsertGraphPruneLogEntryParams) error
+	UpsertGraphZombieChannel(ctx context.Context, arg UpsertGraphZombieChannelParams) error
 }
 
 var _ Querier = (*Queries)(nil)
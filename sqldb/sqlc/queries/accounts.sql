@@ -0,0 +1,106 @@
+-- name: InsertAccount :one
+INSERT INTO accounts (
+    alias, label, initial_balance_msat, current_balance_msat, last_updated,
+    expiration, created_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING id;
+
+-- name: GetAccount :one
+SELECT *
+FROM accounts
+WHERE id = $1;
+
+-- name: GetAccountByAlias :one
+SELECT *
+FROM accounts
+WHERE alias = $1;
+
+-- name: GetAccountByLabel :one
+SELECT *
+FROM accounts
+WHERE label = $1;
+
+-- name: ListAccounts :many
+SELECT *
+FROM accounts
+ORDER BY id;
+
+-- name: UpdateAccountBalance :exec
+UPDATE accounts
+SET current_balance_msat = $1, last_updated = $2
+WHERE id = $3;
+
+-- name: UpdateAccountExpiration :exec
+UPDATE accounts
+SET expiration = $1, last_updated = $2
+WHERE id = $3;
+
+-- name: DeleteAccount :exec
+DELETE
+FROM accounts
+WHERE id = $1;
+
+-- name: InsertAccountInvoice :exec
+INSERT INTO account_invoices (
+    account_id, hash, credited
+) VALUES (
+    $1, $2, $3
+);
+
+-- name: GetAccountInvoice :one
+SELECT *
+FROM account_invoices
+WHERE hash = $1;
+
+-- name: ListAccountInvoices :many
+SELECT *
+FROM account_invoices
+WHERE account_id = $1;
+
+-- name: ListUncreditedAccountInvoices :many
+SELECT *
+FROM account_invoices
+WHERE credited = FALSE;
+
+-- name: SetAccountInvoiceCredited :exec
+UPDATE account_invoices
+SET credited = TRUE
+WHERE hash = $1;
+
+-- name: UpsertAccountPayment :exec
+INSERT INTO account_payments (
+    account_id, hash, status, full_amount_msat
+) VALUES (
+    $1, $2, $3, $4
+)
+ON CONFLICT (hash) DO UPDATE
+SET status = EXCLUDED.status, full_amount_msat = EXCLUDED.full_amount_msat;
+
+-- name: GetAccountPayment :one
+SELECT *
+FROM account_payments
+WHERE hash = $1;
+
+-- name: ListAccountPayments :many
+SELECT *
+FROM account_payments
+WHERE account_id = $1;
+
+-- name: ListAccountPaymentsByStatus :many
+SELECT *
+FROM account_payments
+WHERE status = $1;
+
+-- name: InsertAccountLedgerEntry :exec
+INSERT INTO account_ledger (
+    account_id, entry_type, amount_msat, balance_msat, hash, created_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+);
+
+-- name: ListAccountLedgerEntries :many
+SELECT *
+FROM account_ledger
+WHERE account_id = $1
+ORDER BY id;
@@ -0,0 +1,225 @@
+-- name: UpsertGraphNode :exec
+INSERT INTO graph_nodes (
+    pub_key, have_announcement, last_update, color, alias, features,
+    addresses, auth_sig, extra_opaque_data
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+)
+ON CONFLICT (pub_key) DO UPDATE SET
+    have_announcement = excluded.have_announcement,
+    last_update = excluded.last_update,
+    color = excluded.color,
+    alias = excluded.alias,
+    features = excluded.features,
+    addresses = excluded.addresses,
+    auth_sig = excluded.auth_sig,
+    extra_opaque_data = excluded.extra_opaque_data;
+
+-- name: InsertGraphNodeShell :exec
+INSERT INTO graph_nodes (
+    pub_key, have_announcement, last_update
+) VALUES (
+    $1, FALSE, 0
+)
+ON CONFLICT (pub_key) DO NOTHING;
+
+-- name: GetGraphNode :one
+SELECT *
+FROM graph_nodes
+WHERE pub_key = $1;
+
+-- name: DeleteGraphNode :execrows
+DELETE
+FROM graph_nodes
+WHERE pub_key = $1;
+
+-- name: ListGraphNodes :many
+SELECT *
+FROM graph_nodes
+WHERE id > $1
+ORDER BY id
+LIMIT $2;
+
+-- name: ListGraphNodesInHorizon :many
+SELECT *
+FROM graph_nodes
+WHERE have_announcement = TRUE AND last_update >= @start_time AND
+    last_update <= @end_time
+ORDER BY last_update, pub_key;
+
+-- name: DeleteUnconnectedGraphNodes :many
+DELETE
+FROM graph_nodes
+WHERE NOT EXISTS (
+    SELECT 1
+    FROM graph_channels c
+    WHERE c.node_key_1 = graph_nodes.pub_key OR
+        c.node_key_2 = graph_nodes.pub_key
+) AND NOT EXISTS (
+    SELECT 1
+    FROM graph_source_node s
+    WHERE s.pub_key = graph_nodes.pub_key
+)
+RETURNING pub_key;
+
+-- name: SetGraphSourceNode :exec
+INSERT INTO graph_source_node (
+    id, pub_key
+) VALUES (
+    0, $1
+)
+ON CONFLICT (id) DO UPDATE SET pub_key = excluded.pub_key;
+
+-- name: GetGraphSourceNode :one
+SELECT pub_key
+FROM graph_source_node
+WHERE id = 0;
+
+-- name: InsertGraphChannel :one
+INSERT INTO graph_channels (
+    scid, chain_hash, node_key_1, node_key_2, bitcoin_key_1, bitcoin_key_2,
+    features, node_sig_1, node_sig_2, bitcoin_sig_1, bitcoin_sig_2, outpoint,
+    capacity, extra_opaque_data
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
+) RETURNING id;
+
+-- name: UpdateGraphChannel :execrows
+UPDATE graph_channels
+SET chain_hash = $1, node_key_1 = $2, node_key_2 = $3, bitcoin_key_1 = $4,
+    bitcoin_key_2 = $5, features = $6, node_sig_1 = $7, node_sig_2 = $8,
+    bitcoin_sig_1 = $9, bitcoin_sig_2 = $10, outpoint = $11, capacity = $12,
+    extra_opaque_data = $13
+WHERE scid = $14;
+
+-- name: GetGraphChannelBySCID :one
+SELECT *
+FROM graph_channels
+WHERE scid = $1;
+
+-- name: GetGraphChannelByOutpoint :one
+SELECT *
+FROM graph_channels
+WHERE outpoint = $1
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: DeleteGraphChannel :exec
+DELETE
+FROM graph_channels
+WHERE id = $1;
+
+-- name: GetHighestGraphChannelSCID :one
+SELECT scid
+FROM graph_channels
+ORDER BY scid DESC
+LIMIT 1;
+
+-- name: ListGraphChannels :many
+SELECT *
+FROM graph_channels
+WHERE id > $1
+ORDER BY id
+LIMIT $2;
+
+-- name: ListGraphChannelsInRange :many
+SELECT *
+FROM graph_channels
+WHERE scid >= @start_scid AND scid <= @end_scid
+ORDER BY scid;
+
+-- name: ListGraphNodeChannels :many
+SELECT *
+FROM graph_channels
+WHERE node_key_1 = @node_key OR node_key_2 = @node_key
+ORDER BY scid;
+
+-- name: ListGraphChannelsUpdatedInHorizon :many
+SELECT c.*
+FROM graph_channels c
+JOIN (
+    SELECT channel_id, MIN(last_update) AS first_update
+    FROM graph_channel_policies
+    WHERE last_update >= @start_time AND last_update <= @end_time
+    GROUP BY channel_id
+) u ON u.channel_id = c.id
+ORDER BY u.first_update, c.scid;
+
+-- name: UpsertGraphChannelPolicy :exec
+INSERT INTO graph_channel_policies (
+    channel_id, direction, last_update, message_flags, channel_flags,
+    disabled, timelock_delta, min_htlc_msat, max_htlc_msat, fee_base_msat,
+    fee_ppm, signature, extra_opaque_data
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+)
+ON CONFLICT (channel_id, direction) DO UPDATE SET
+    last_update = excluded.last_update,
+    message_flags = excluded.message_flags,
+    channel_flags = excluded.channel_flags,
+    disabled = excluded.disabled,
+    timelock_delta = excluded.timelock_delta,
+    min_htlc_msat = excluded.min_htlc_msat,
+    max_htlc_msat = excluded.max_htlc_msat,
+    fee_base_msat = excluded.fee_base_msat,
+    fee_ppm = excluded.fee_ppm,
+    signature = excluded.signature,
+    extra_opaque_data = excluded.extra_opaque_data;
+
+-- name: ListGraphChannelPolicies :many
+SELECT *
+FROM graph_channel_policies
+WHERE channel_id = $1
+ORDER BY direction;
+
+-- name: ListDisabledGraphChannels :many
+SELECT c.scid
+FROM graph_channels c
+JOIN graph_channel_policies p ON p.channel_id = c.id
+WHERE p.disabled = TRUE
+GROUP BY c.scid
+HAVING COUNT(*) = 2
+ORDER BY c.scid;
+
+-- name: UpsertGraphZombieChannel :exec
+INSERT INTO graph_zombie_channels (
+    scid, node_key_1, node_key_2
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (scid) DO UPDATE SET
+    node_key_1 = excluded.node_key_1,
+    node_key_2 = excluded.node_key_2;
+
+-- name: GetGraphZombieChannel :one
+SELECT *
+FROM graph_zombie_channels
+WHERE scid = $1;
+
+-- name: DeleteGraphZombieChannel :exec
+DELETE
+FROM graph_zombie_channels
+WHERE scid = $1;
+
+-- name: CountGraphZombieChannels :one
+SELECT COUNT(*)
+FROM graph_zombie_channels;
+
+-- name: UpsertGraphPruneLogEntry :exec
+INSERT INTO graph_prune_log (
+    block_height, block_hash
+) VALUES (
+    $1, $2
+)
+ON CONFLICT (block_height) DO UPDATE SET block_hash = excluded.block_hash;
+
+-- name: GetGraphPruneTip :one
+SELECT *
+FROM graph_prune_log
+ORDER BY block_height DESC
+LIMIT 1;
+
+-- name: DeleteGraphPruneLogEntries :exec
+DELETE
+FROM graph_prune_log
+WHERE block_height >= $1;
@@ -0,0 +1,11 @@
+-- name: InsertKVMigration :exec
+INSERT INTO kv_migrations (
+    name, migrated_at
+) VALUES (
+    $1, $2
+);
+
+-- name: GetKVMigration :one
+SELECT *
+FROM kv_migrations
+WHERE name = $1;
@@ -0,0 +1,150 @@
+-- name: NextPaymentSequence :one
+INSERT INTO payment_sequence (
+    id, last_seq
+) VALUES (
+    0, 1
+)
+ON CONFLICT (id) DO UPDATE SET last_seq = payment_sequence.last_seq + 1
+RETURNING last_seq;
+
+-- name: SetPaymentSequence :exec
+INSERT INTO payment_sequence (
+    id, last_seq
+) VALUES (
+    0, $1
+)
+ON CONFLICT (id) DO UPDATE SET last_seq = excluded.last_seq;
+
+-- name: InsertPayment :one
+INSERT INTO payments (
+    sequence_num, hash, amount_msat, payment_request, rebalance, status,
+    failure_reason, created_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING id;
+
+-- name: GetPaymentByHash :one
+SELECT *
+FROM payments
+WHERE hash = $1;
+
+-- name: UpdatePaymentStatus :exec
+UPDATE payments
+SET status = $1, failure_reason = $2
+WHERE id = $3;
+
+-- name: DeletePayment :exec
+DELETE
+FROM payments
+WHERE id = $1;
+
+-- name: CountPayments :one
+SELECT COUNT(*)
+FROM payments;
+
+-- The status mask is a bit field with the bit 1 << status set for each of
+-- the statuses that should be returned.
+
+-- name: FilterPayments :many
+SELECT *
+FROM payments
+WHERE (
+    sequence_num > sqlc.narg('seq_num_after') OR
+    sqlc.narg('seq_num_after') IS NULL
+) AND (
+    sequence_num < sqlc.narg('seq_num_before') OR
+    sqlc.narg('seq_num_before') IS NULL
+) AND (
+    (sqlc.narg('status_mask') & (1 << status)) != 0 OR
+    sqlc.narg('status_mask') IS NULL
+) AND (
+    created_at >= sqlc.narg('created_after') OR
+    sqlc.narg('created_after') IS NULL
+) AND (
+    created_at <= sqlc.narg('created_before') OR
+    sqlc.narg('created_before') IS NULL
+) AND (
+    amount_msat >= sqlc.narg('min_amount_msat') OR
+    sqlc.narg('min_amount_msat') IS NULL
+) AND (
+    amount_msat <= sqlc.narg('max_amount_msat') OR
+    sqlc.narg('max_amount_msat') IS NULL
+) AND (
+    substr(hash, 1, @hash_prefix_len) = sqlc.narg('hash_prefix') OR
+    sqlc.narg('hash_prefix') IS NULL
+) AND (
+    EXISTS (
+        SELECT 1
+        FROM payment_htlc_attempts a
+        WHERE a.payment_id = payments.id AND
+            a.destination = sqlc.narg('destination')
+    ) OR
+    sqlc.narg('destination') IS NULL
+)
+ORDER BY
+    CASE
+        WHEN sqlc.narg('reverse') = FALSE THEN sequence_num
+        ELSE NULL
+    END ASC,
+    CASE
+        WHEN sqlc.narg('reverse') = TRUE THEN sequence_num
+        ELSE NULL
+    END DESC
+LIMIT @num_limit;
+
+-- name: CountPaymentsByStatus :many
+SELECT status, COUNT(*) AS num_payments
+FROM payments
+WHERE (
+    created_at >= sqlc.narg('created_after') OR
+    sqlc.narg('created_after') IS NULL
+) AND (
+    created_at <= sqlc.narg('created_before') OR
+    sqlc.narg('created_before') IS NULL
+)
+GROUP BY status;
+
+-- name: SumSettledPaymentHTLCAttempts :one
+SELECT
+    CAST(COALESCE(SUM(a.amount_msat), 0) AS BIGINT) AS amount_msat,
+    CAST(COALESCE(SUM(a.fee_msat), 0) AS BIGINT) AS fee_msat
+FROM payment_htlc_attempts a
+JOIN payments p ON a.payment_id = p.id
+WHERE p.status = @status AND a.settle_preimage IS NOT NULL AND (
+    p.created_at >= sqlc.narg('created_after') OR
+    sqlc.narg('created_after') IS NULL
+) AND (
+    p.created_at <= sqlc.narg('created_before') OR
+    sqlc.narg('created_before') IS NULL
+);
+
+-- name: InsertPaymentHTLCAttempt :exec
+INSERT INTO payment_htlc_attempts (
+    payment_id, attempt_id, session_key, route, destination, amount_msat,
+    fee_msat, hash, attempt_time, settle_preimage, settle_time, fail_reason,
+    fail_message, fail_source_index, fail_time
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
+);
+
+-- name: ListPaymentHTLCAttempts :many
+SELECT *
+FROM payment_htlc_attempts
+WHERE payment_id = $1
+ORDER BY attempt_id;
+
+-- name: SettlePaymentHTLCAttempt :exec
+UPDATE payment_htlc_attempts
+SET settle_preimage = $1, settle_time = $2
+WHERE payment_id = $3 AND attempt_id = $4;
+
+-- name: FailPaymentHTLCAttempt :exec
+UPDATE payment_htlc_attempts
+SET fail_reason = $1, fail_message = $2, fail_source_index = $3,
+    fail_time = $4
+WHERE payment_id = $5 AND attempt_id = $6;
+
+-- name: DeleteFailedPaymentHTLCAttempts :exec
+DELETE
+FROM payment_htlc_attempts
+WHERE payment_id = $1 AND fail_reason IS NOT NULL;
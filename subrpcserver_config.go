@@ -7,17 +7,21 @@ import (
 
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btclog"
+	"github.com/lightningnetwork/lnd/accounts"
 	"github.com/lightningnetwork/lnd/autopilot"
 	"github.com/lightningnetwork/lnd/chainreg"
 	"github.com/lightningnetwork/lnd/channeldb"
 	"github.com/lightningnetwork/lnd/htlcswitch"
 	"github.com/lightningnetwork/lnd/invoices"
+	"github.com/lightningnetwork/lnd/labels"
 	"github.com/lightningnetwork/lnd/lncfg"
+	"github.com/lightningnetwork/lnd/lnrpc/accountsrpc"
 	"github.com/lightningnetwork/lnd/lnrpc/autopilotrpc"
 	"github.com/lightningnetwork/lnd/lnrpc/chainrpc"
 	"github.com/lightningnetwork/lnd/lnrpc/devrpc"
 	"github.com/lightningnetwork/lnd/lnrpc/invoicesrpc"
 	"github.com/lightningnetwork/lnd/lnrpc/neutrinorpc"
+	"github.com/lightningnetwork/lnd/lnrpc/nwcrpc"
 	"github.com/lightningnetwork/lnd/lnrpc/peersrpc"
 	"github.com/lightningnetwork/lnd/lnrpc/routerrpc"
 	"github.com/lightningnetwork/lnd/lnrpc/signrpc"
@@ -31,6 +35,7 @@ import (
 	"github.com/lightningnetwork/lnd/sweep"
 	"github.com/lightningnetwork/lnd/watchtower"
 	"github.com/lightningnetwork/lnd/watchtower/wtclient"
+	"google.golang.org/grpc"
 )
 
 // subRPCServerConfigs is special sub-config in the main configuration that
@@ -91,6 +96,14 @@ type subRPCServerConfigs struct {
 	// developers manipulate LND state that is normally not possible.
 	// Should only be used for development purposes.
 	DevRPC *devrpc.Config `group:"devrpc" namespace:"devrpc"`
+
+	// NWCRPC is a sub-RPC server that bridges Nostr Wallet Connect
+	// (NIP-47) requests received over a nostr relay to lnd's RPCs.
+	NWCRPC *nwcrpc.Config `group:"nwcrpc" namespace:"nwcrpc"`
+
+	// AccountsRPC is a sub-RPC server that manages virtual accounts with
+	// their own balance and account bound macaroons.
+	AccountsRPC *accountsrpc.Config `group:"accountsrpc" namespace:"accountsrpc"`
 }
 
 // PopulateDependencies attempts to iterate through all the sub-server configs
@@ -123,7 +136,9 @@ func (s *subRPCServerConfigs) PopulateDependencies(cfg *Config,
 		modifiers ...netann.NodeAnnModifier) error,
 	parseAddr func(addr string) (net.Addr, error),
 	rpcLogger btclog.Logger,
-	getAlias func(lnwire.ChannelID) (lnwire.ShortChannelID, error)) error {
+	getAlias func(lnwire.ChannelID) (lnwire.ShortChannelID, error),
+	dialSelf func() (*grpc.ClientConn, error),
+	accountStore accounts.Store, labelStore labels.Store) error {
 
 	// First, we'll use reflect to obtain a version of the config struct
 	// that allows us to programmatically inspect its fields.
@@ -198,6 +213,15 @@ func (s *subRPCServerConfigs) PopulateDependencies(cfg *Config,
 			subCfgValue.FieldByName("CurrentNumAnchorChans").Set(
 				reflect.ValueOf(cc.Wallet.CurrentNumAnchorChans),
 			)
+			subCfgValue.FieldByName("ChanStateDB").Set(
+				reflect.ValueOf(chanStateDB),
+			)
+			subCfgValue.FieldByName("TxTracker").Set(
+				reflect.ValueOf(cc.TxTracker),
+			)
+			subCfgValue.FieldByName("LabelStore").Set(
+				reflect.ValueOf(labelStore),
+			)
 
 		case *autopilotrpc.Config:
 			subCfgValue := extractReflectValue(subCfg)
@@ -331,6 +355,35 @@ func (s *subRPCServerConfigs) PopulateDependencies(cfg *Config,
 				reflect.ValueOf(updateNodeAnnouncement),
 			)
 
+		case *nwcrpc.Config:
+			subCfgValue := extractReflectValue(subCfg)
+
+			subCfgValue.FieldByName("MacService").Set(
+				reflect.ValueOf(macService),
+			)
+			subCfgValue.FieldByName("ChainParams").Set(
+				reflect.ValueOf(activeNetParams),
+			)
+			subCfgValue.FieldByName("DialLnd").Set(
+				reflect.ValueOf(dialSelf),
+			)
+
+		case *accountsrpc.Config:
+			subCfgValue := extractReflectValue(subCfg)
+
+			subCfgValue.FieldByName("MacService").Set(
+				reflect.ValueOf(macService),
+			)
+
+			// A nil store can't be passed to reflect.ValueOf, so we
+			// leave the field untouched if accounts aren't
+			// available.
+			if accountStore != nil {
+				subCfgValue.FieldByName("Store").Set(
+					reflect.ValueOf(accountStore),
+				)
+			}
+
 		default:
 			return fmt.Errorf("unknown field: %v, %T", fieldName,
 				cfg)
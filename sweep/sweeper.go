@@ -77,6 +77,13 @@ type Params struct {
 	// ExclusiveGroup is an identifier that, if set, prevents other inputs
 	// with the same identifier from being batched together.
 	ExclusiveGroup *uint64
+
+	// PackageParent is an optional unconfirmed parent transaction of the
+	// input that can't be relayed on its own, such as a zero-fee
+	// commitment transaction. If set, the input is swept in a v3 (TRUC)
+	// transaction of its own that is submitted together with the parent
+	// as a package.
+	PackageParent *wire.MsgTx
 }
 
 // ParamsUpdate contains a new set of parameters to update a pending sweep with.
@@ -93,13 +100,19 @@ type ParamsUpdate struct {
 
 // String returns a human readable interpretation of the sweep parameters.
 func (p Params) String() string {
+	exclusiveGroup := "nil"
 	if p.ExclusiveGroup != nil {
-		return fmt.Sprintf("fee=%v, force=%v, exclusive_group=%v",
-			p.Fee, p.Force, *p.ExclusiveGroup)
+		exclusiveGroup = fmt.Sprintf("%v", *p.ExclusiveGroup)
+	}
+
+	packageParent := "nil"
+	if p.PackageParent != nil {
+		packageParent = p.PackageParent.TxHash().String()
 	}
 
-	return fmt.Sprintf("fee=%v, force=%v, exclusive_group=nil",
-		p.Fee, p.Force)
+	return fmt.Sprintf("fee=%v, force=%v, exclusive_group=%v, "+
+		"package_parent=%v", p.Fee, p.Force, exclusiveGroup,
+		packageParent)
 }
 
 // pendingInput is created when an input reaches the main loop for the first
@@ -148,6 +161,10 @@ type inputCluster struct {
 	lockTime     *uint32
 	sweepFeeRate chainfee.SatPerKWeight
 	inputs       pendingInputs
+
+	// packageParent is the parent transaction the sweep of this cluster
+	// must be relayed with as a package, if any.
+	packageParent *wire.MsgTx
 }
 
 // pendingSweepsReq is an internal message we'll use to represent an external
@@ -285,6 +302,12 @@ type UtxoSweeperConfig struct {
 	// UtxoSweeper.
 	MaxFeeRate chainfee.SatPerVByte
 
+	// PublishPackage submits a parent transaction together with a child
+	// spending one of its outputs as a package. This is used for parents
+	// that can't be relayed on their own, such as zero-fee commitment
+	// transactions. If nil, the child is published on its own.
+	PublishPackage func(parent, child *wire.MsgTx) error
+
 	// FeeRateBucketSize is the default size of fee rate buckets we'll use
 	// when clustering inputs into buckets with similar fee rates within the
 	// UtxoSweeper.
@@ -856,7 +879,10 @@ func (s *UtxoSweeper) sweepCluster(cluster inputCluster,
 
 		// Sweep selected inputs.
 		for _, inputs := range inputLists {
-			err := s.sweep(inputs, cluster.sweepFeeRate, currentHeight)
+			err := s.sweep(
+				inputs, cluster.sweepFeeRate, currentHeight,
+				cluster.packageParent,
+			)
 			if err != nil {
 				return fmt.Errorf("unable to sweep inputs: %v", err)
 			}
@@ -884,10 +910,14 @@ func (s *UtxoSweeper) bucketForFeeRate(
 
 // createInputClusters creates a list of input clusters from the set of pending
 // inputs known by the UtxoSweeper. It clusters inputs by
-// 1) Required tx locktime
-// 2) Similar fee rates.
+// 1) Package parent
+// 2) Required tx locktime
+// 3) Similar fee rates.
 func (s *UtxoSweeper) createInputClusters() []inputCluster {
-	inputs := s.pendingInputs
+	// Inputs that need to be relayed together with their parent can't be
+	// batched with any other inputs, as a TRUC child may only have a
+	// single unconfirmed parent.
+	packageClusters, inputs := s.clusterByPackageParent(s.pendingInputs)
 
 	// We start by getting the inputs clusters by locktime. Since the
 	// inputs commit to the locktime, they can only be clustered together
@@ -900,7 +930,41 @@ func (s *UtxoSweeper) createInputClusters() []inputCluster {
 	// Since the inputs that we clustered by fee rate don't commit to a
 	// specific locktime, we can try to merge a locktime cluster with a fee
 	// cluster.
-	return zipClusters(lockTimeClusters, feeClusters)
+	return append(packageClusters, zipClusters(
+		lockTimeClusters, feeClusters,
+	)...)
+}
+
+// clusterByPackageParent takes the given set of pending inputs and creates a
+// separate cluster for each input that has a package parent set. In addition
+// to the created clusters, inputs that don't have a package parent are
+// returned.
+func (s *UtxoSweeper) clusterByPackageParent(inputs pendingInputs) (
+	[]inputCluster, pendingInputs) {
+
+	var clusters []inputCluster
+	rem := make(pendingInputs)
+	for op, input := range inputs {
+		if input.params.PackageParent == nil {
+			rem[op] = input
+			continue
+		}
+
+		feeRate, err := s.feeRateForPreference(input.params.Fee)
+		if err != nil {
+			log.Warnf("Skipping input %v: %v", op, err)
+			continue
+		}
+		input.lastFeeRate = feeRate
+
+		clusters = append(clusters, inputCluster{
+			sweepFeeRate:  feeRate,
+			inputs:        pendingInputs{op: input},
+			packageParent: input.params.PackageParent,
+		})
+	}
+
+	return clusters, rem
 }
 
 // clusterByLockTime takes the given set of pending inputs and clusters those
@@ -1287,9 +1351,11 @@ func (s *UtxoSweeper) getInputLists(cluster inputCluster,
 }
 
 // sweep takes a set of preselected inputs, creates a sweep tx and publishes the
-// tx. The output address is only marked as used if the publish succeeds.
+// tx. The output address is only marked as used if the publish succeeds. If a
+// package parent is given, the sweep tx is created as a TRUC child and
+// published together with the parent.
 func (s *UtxoSweeper) sweep(inputs inputSet, feeRate chainfee.SatPerKWeight,
-	currentHeight int32) error {
+	currentHeight int32, packageParent *wire.MsgTx) error {
 
 	// Generate an output script if there isn't an unused script available.
 	if s.currentOutputScript == nil {
@@ -1300,10 +1366,16 @@ func (s *UtxoSweeper) sweep(inputs inputSet, feeRate chainfee.SatPerKWeight,
 		s.currentOutputScript = pkScript
 	}
 
-	// Create sweep tx.
+	// Create sweep tx. Children of a package need to be TRUC transactions
+	// as well.
+	txVersion := int32(2)
+	if packageParent != nil {
+		txVersion = 3
+	}
 	tx, err := createSweepTx(
 		inputs, nil, s.currentOutputScript, uint32(currentHeight),
-		feeRate, s.cfg.MaxFeeRate.FeePerKWeight(), s.cfg.Signer,
+		feeRate, s.cfg.MaxFeeRate.FeePerKWeight(), txVersion,
+		s.cfg.Signer,
 	)
 	if err != nil {
 		return fmt.Errorf("create sweep tx: %v", err)
@@ -1329,9 +1401,15 @@ func (s *UtxoSweeper) sweep(inputs inputSet, feeRate chainfee.SatPerKWeight,
 		}),
 	)
 
-	err = s.cfg.Wallet.PublishTransaction(
-		tx, labels.MakeLabel(labels.LabelTypeSweepTransaction, nil),
-	)
+	// Label the sweep with the witness types of its inputs, so that the
+	// purpose of the sweep can be told from the wallet.
+	purposes := make([]string, 0, len(inputs))
+	for _, inp := range inputs {
+		purposes = append(purposes, inp.WitnessType().String())
+	}
+	label := labels.MakeSweepLabel(purposes...)
+
+	err = s.publishSweepTx(tx, packageParent, label)
 
 	// In case of an unexpected error, don't try to recover.
 	if err != nil && err != lnwallet.ErrDoubleSpend {
@@ -1395,6 +1473,32 @@ func (s *UtxoSweeper) sweep(inputs inputSet, feeRate chainfee.SatPerKWeight,
 	return nil
 }
 
+// publishSweepTx publishes the given sweep tx with the given label. If a
+// package parent is given and package relay is available, the parent and the
+// sweep tx are submitted as a package first.
+func (s *UtxoSweeper) publishSweepTx(tx, packageParent *wire.MsgTx,
+	label string) error {
+
+	if packageParent != nil && s.cfg.PublishPackage != nil {
+		log.Debugf("Publishing sweep tx %v as package with parent %v",
+			tx.TxHash(), packageParent.TxHash())
+
+		// If the package can't be submitted, for example because the
+		// parent already confirmed, we'll still try to publish the
+		// child on its own below.
+		err := s.cfg.PublishPackage(packageParent, tx)
+		if err != nil {
+			log.Warnf("Unable to publish package of parent %v "+
+				"and sweep tx %v: %v", packageParent.TxHash(),
+				tx.TxHash(), err)
+		}
+	}
+
+	// Hand the tx to the wallet in any case, so it is tracked and
+	// rebroadcast like any other sweep tx.
+	return s.cfg.Wallet.PublishTransaction(tx, label)
+}
+
 // waitForSpend registers a spend notification with the chain notifier. It
 // returns a cancel function that can be used to cancel the registration.
 func (s *UtxoSweeper) waitForSpend(outpoint wire.OutPoint,
@@ -1612,7 +1716,7 @@ func (s *UtxoSweeper) CreateSweepTx(inputs []input.Input, feePref FeePreference,
 
 	return createSweepTx(
 		inputs, nil, pkScript, currentBlockHeight, feePerKw,
-		s.cfg.MaxFeeRate.FeePerKWeight(), s.cfg.Signer,
+		s.cfg.MaxFeeRate.FeePerKWeight(), 2, s.cfg.Signer,
 	)
 }
 
@@ -1273,6 +1273,75 @@ func TestExclusiveGroup(t *testing.T) {
 	}
 }
 
+// TestPackageParent asserts that inputs with a package parent are swept in
+// their own v3 transaction that is submitted as a package together with the
+// parent.
+func TestPackageParent(t *testing.T) {
+	ctx := createSweeperTestContext(t)
+
+	type pkg struct {
+		parent *wire.MsgTx
+		child  *wire.MsgTx
+	}
+	packages := make(chan pkg, 1)
+	ctx.sweeper.cfg.PublishPackage = func(parent, child *wire.MsgTx) error {
+		packages <- pkg{parent: parent, child: child}
+		return nil
+	}
+
+	parent := wire.NewMsgTx(3)
+	_, err := ctx.sweeper.SweepInput(
+		spendableInputs[0], Params{
+			Fee:           FeePreference{ConfTarget: 6},
+			PackageParent: parent,
+		},
+	)
+	require.NoError(t, err)
+
+	_, err = ctx.sweeper.SweepInput(
+		spendableInputs[1], Params{
+			Fee: FeePreference{ConfTarget: 6},
+		},
+	)
+	require.NoError(t, err)
+
+	// Even though both inputs share the same fee preference, we expect
+	// them to be swept in separate transactions.
+	ctx.tick()
+
+	var packageTx, regularTx wire.MsgTx
+	for i := 0; i < 2; i++ {
+		sweepTx := ctx.receiveTx()
+		require.Len(t, sweepTx.TxIn, 1)
+
+		if sweepTx.TxIn[0].PreviousOutPoint ==
+			*spendableInputs[0].OutPoint() {
+
+			packageTx = sweepTx
+		} else {
+			regularTx = sweepTx
+		}
+	}
+
+	require.EqualValues(t, 3, packageTx.Version)
+	require.EqualValues(t, 2, regularTx.Version)
+
+	// The package sweep should also have been submitted together with
+	// its parent.
+	select {
+	case p := <-packages:
+		require.Equal(t, parent, p.parent)
+		require.Equal(t, packageTx.TxHash(), p.child.TxHash())
+
+	case <-time.After(defaultTestTimeout):
+		t.Fatalf("package not published")
+	}
+
+	ctx.backend.mine()
+
+	ctx.finish(1)
+}
+
 // TestCpfp tests that the sweeper spends cpfp inputs at a fee rate that exceeds
 // the parent tx fee rate.
 func TestCpfp(t *testing.T) {
@@ -139,7 +139,7 @@ func generateInputPartitionings(sweepableInputs []txInput,
 // sending any leftover change to the change script.
 func createSweepTx(inputs []input.Input, outputs []*wire.TxOut,
 	changePkScript []byte, currentBlockHeight uint32,
-	feePerKw, maxFeeRate chainfee.SatPerKWeight,
+	feePerKw, maxFeeRate chainfee.SatPerKWeight, txVersion int32,
 	signer input.Signer) (*wire.MsgTx, error) {
 
 	inputs, estimator, err := getWeightEstimate(
@@ -153,8 +153,8 @@ func createSweepTx(inputs []input.Input, outputs []*wire.TxOut,
 
 	var (
 		// Create the sweep transaction that we will be building. We
-		// use version 2 as it is required for CSV.
-		sweepTx = wire.NewMsgTx(2)
+		// use at least version 2 as it is required for CSV.
+		sweepTx = wire.NewMsgTx(txVersion)
 
 		// Track whether any of the inputs require a certain locktime.
 		locktime = int32(-1)
@@ -321,7 +321,7 @@ func CraftSweepAllTx(feeRate, maxFeeRate chainfee.SatPerKWeight,
 	// respects our fee preference and targets all the UTXOs of the wallet.
 	sweepTx, err := createSweepTx(
 		inputsToSweep, txOuts, changePkScript, blockHeight,
-		feeRate, maxFeeRate, signer,
+		feeRate, maxFeeRate, 2, signer,
 	)
 	if err != nil {
 		unlockOutputs()
@@ -0,0 +1,192 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// EventType is the type of a node event that can be delivered to webhook
+// endpoints.
+type EventType uint8
+
+const (
+	// EventInvoiceSettled is sent when one of our invoices is settled.
+	EventInvoiceSettled EventType = 1
+
+	// EventPaymentSucceeded is sent when one of our payments succeeds.
+	EventPaymentSucceeded EventType = 2
+
+	// EventPaymentFailed is sent when one of our payments fails.
+	EventPaymentFailed EventType = 3
+
+	// EventChannelOpened is sent when a channel is fully opened.
+	EventChannelOpened EventType = 4
+
+	// EventChannelClosed is sent when a channel is closed.
+	EventChannelClosed EventType = 5
+
+	// EventHtlcFailed is sent when a htlc that we send, receive or forward
+	// fails.
+	EventHtlcFailed EventType = 6
+
+	// EventBackupChanged is sent when the set of channels changes, which
+	// changes the static channel backup.
+	EventBackupChanged EventType = 7
+)
+
+// eventTypeNames maps each event type to its name, which is used in the
+// payloads and the configuration.
+var eventTypeNames = map[EventType]string{
+	EventInvoiceSettled:   "invoice_settled",
+	EventPaymentSucceeded: "payment_succeeded",
+	EventPaymentFailed:    "payment_failed",
+	EventChannelOpened:    "channel_opened",
+	EventChannelClosed:    "channel_closed",
+	EventHtlcFailed:       "htlc_failed",
+	EventBackupChanged:    "backup_changed",
+}
+
+// String returns the name of the event type.
+func (t EventType) String() string {
+	name, ok := eventTypeNames[t]
+	if !ok {
+		return fmt.Sprintf("unknown(%d)", uint8(t))
+	}
+
+	return name
+}
+
+// Valid returns true if the event type is known.
+func (t EventType) Valid() bool {
+	_, ok := eventTypeNames[t]
+	return ok
+}
+
+// ParseEventType returns the event type with the given name.
+func ParseEventType(name string) (EventType, error) {
+	for eventType, eventName := range eventTypeNames {
+		if eventName == name {
+			return eventType, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: %v", ErrUnknownEventType, name)
+}
+
+// Event is a node event that is delivered to the webhook endpoints that
+// subscribed to its type.
+type Event struct {
+	// ID uniquely identifies the event. Deliveries of the same event to
+	// different endpoints and retried deliveries share the ID, so
+	// receivers can use it to deduplicate events.
+	ID string
+
+	// Type is the type of the event.
+	Type EventType
+
+	// Timestamp is the time the event occurred at.
+	Timestamp time.Time
+
+	// Data holds the details of the event. It is one of the payload types
+	// below and is encoded as JSON.
+	Data interface{}
+}
+
+// payload is the JSON document that is posted to the endpoints.
+type payload struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	Timestamp int64       `json:"timestamp"`
+	Data      interface{} `json:"data"`
+}
+
+// encode returns the JSON document that is posted for the event.
+func (e *Event) encode() ([]byte, error) {
+	return json.Marshal(&payload{
+		ID:        e.ID,
+		Type:      e.Type.String(),
+		Timestamp: e.Timestamp.Unix(),
+		Data:      e.Data,
+	})
+}
+
+// InvoiceSettled is the payload of an invoice_settled event.
+type InvoiceSettled struct {
+	PaymentHash    string `json:"payment_hash,omitempty"`
+	Memo           string `json:"memo,omitempty"`
+	PaymentRequest string `json:"payment_request,omitempty"`
+	ValueMsat      uint64 `json:"value_msat"`
+	AmtPaidMsat    uint64 `json:"amt_paid_msat"`
+	SettleDate     int64  `json:"settle_date"`
+	AddIndex       uint64 `json:"add_index"`
+	SettleIndex    uint64 `json:"settle_index"`
+	IsKeysend      bool   `json:"is_keysend"`
+	IsAMP          bool   `json:"is_amp"`
+}
+
+// PaymentSucceeded is the payload of a payment_succeeded event.
+type PaymentSucceeded struct {
+	PaymentHash     string `json:"payment_hash"`
+	PaymentPreimage string `json:"payment_preimage"`
+	PaymentRequest  string `json:"payment_request,omitempty"`
+	ValueMsat       uint64 `json:"value_msat"`
+	FeeMsat         uint64 `json:"fee_msat"`
+	CreationDate    int64  `json:"creation_date"`
+	IsRebalance     bool   `json:"is_rebalance"`
+}
+
+// PaymentFailed is the payload of a payment_failed event.
+type PaymentFailed struct {
+	PaymentHash    string `json:"payment_hash"`
+	PaymentRequest string `json:"payment_request,omitempty"`
+	ValueMsat      uint64 `json:"value_msat"`
+	FailureReason  string `json:"failure_reason"`
+	CreationDate   int64  `json:"creation_date"`
+	IsRebalance    bool   `json:"is_rebalance"`
+}
+
+// ChannelOpened is the payload of a channel_opened event.
+type ChannelOpened struct {
+	ChannelPoint string `json:"channel_point"`
+	ChanID       uint64 `json:"chan_id"`
+	RemotePubkey string `json:"remote_pubkey"`
+	CapacitySat  int64  `json:"capacity_sat"`
+	Initiator    bool   `json:"initiator"`
+	Private      bool   `json:"private"`
+}
+
+// ChannelClosed is the payload of a channel_closed event.
+type ChannelClosed struct {
+	ChannelPoint      string `json:"channel_point"`
+	ChanID            uint64 `json:"chan_id"`
+	RemotePubkey      string `json:"remote_pubkey"`
+	CapacitySat       int64  `json:"capacity_sat"`
+	ClosingTxid       string `json:"closing_txid"`
+	CloseType         string `json:"close_type"`
+	SettledBalanceSat int64  `json:"settled_balance_sat"`
+}
+
+// HtlcFailed is the payload of a htlc_failed event.
+type HtlcFailed struct {
+	EventType        string `json:"event_type"`
+	IncomingChanID   uint64 `json:"incoming_chan_id"`
+	IncomingHtlcID   uint64 `json:"incoming_htlc_id"`
+	OutgoingChanID   uint64 `json:"outgoing_chan_id"`
+	OutgoingHtlcID   uint64 `json:"outgoing_htlc_id"`
+	IncomingAmtMsat  uint64 `json:"incoming_amt_msat,omitempty"`
+	OutgoingAmtMsat  uint64 `json:"outgoing_amt_msat,omitempty"`
+	LinkFailure      bool   `json:"link_failure"`
+	FailedIncoming   bool   `json:"failed_incoming"`
+	FailureDetail    string `json:"failure_detail,omitempty"`
+	FailureString    string `json:"failure_string,omitempty"`
+	TimestampNanosec int64  `json:"timestamp_ns"`
+}
+
+// BackupChanged is the payload of a backup_changed event. The backup is the
+// encrypted multi-channel backup of all our channels, as returned by
+// ExportAllChannelBackups, which JSON encodes as base64.
+type BackupChanged struct {
+	NumChannels     int    `json:"num_channels"`
+	MultiChanBackup []byte `json:"multi_chan_backup"`
+}
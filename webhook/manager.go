@@ -0,0 +1,865 @@
+// Package webhook delivers node events to HTTP endpoints. Each event is posted
+// as a JSON document that is signed with the HMAC key of the endpoint. Failed
+// deliveries are retried with an exponential backoff and moved to a
+// persistent dead-letter queue once they failed too often.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lightningnetwork/lnd/clock"
+	"github.com/lightningnetwork/lnd/invoices"
+	"github.com/lightningnetwork/lnd/routing"
+	"github.com/lightningnetwork/lnd/subscribe"
+)
+
+const (
+	// secretSize is the size of the randomly generated endpoint secrets.
+	secretSize = 32
+
+	// maxResponseSize is the number of bytes of a response body that are
+	// read to report it in a failed delivery.
+	maxResponseSize = 256
+
+	// HeaderEventID is the HTTP header that carries the ID of the
+	// delivered event.
+	HeaderEventID = "X-Lnd-Webhook-Id"
+
+	// HeaderEventType is the HTTP header that carries the type of the
+	// delivered event.
+	HeaderEventType = "X-Lnd-Webhook-Event"
+
+	// HeaderDeliveryID is the HTTP header that carries the ID of the
+	// delivery.
+	HeaderDeliveryID = "X-Lnd-Webhook-Delivery"
+
+	// HeaderTimestamp is the HTTP header that carries the unix timestamp
+	// of the delivery attempt, which is part of the signed message.
+	HeaderTimestamp = "X-Lnd-Webhook-Timestamp"
+
+	// HeaderSignature is the HTTP header that carries the signature of
+	// the payload, in the form sha256=<hex encoded HMAC>.
+	HeaderSignature = "X-Lnd-Webhook-Signature"
+)
+
+var (
+	// ErrUnknownEventType is returned when an event type is unknown.
+	ErrUnknownEventType = errors.New("unknown webhook event type")
+
+	// ErrInvalidURL is returned when an endpoint URL isn't an absolute
+	// http or https URL.
+	ErrInvalidURL = errors.New("invalid webhook URL")
+
+	// ErrConfigEndpoint is returned when an endpoint that is configured in
+	// lnd.conf is to be removed through the RPC.
+	ErrConfigEndpoint = errors.New("webhook endpoint is configured in " +
+		"lnd.conf")
+
+	// ErrNotDeadLetter is returned when a delivery is to be retried that
+	// isn't in the dead-letter queue.
+	ErrNotDeadLetter = errors.New("webhook delivery is not in the " +
+		"dead-letter queue")
+)
+
+// EndpointConfig is an endpoint that is configured in lnd.conf.
+type EndpointConfig struct {
+	// URL is the URL the events are posted to.
+	URL string
+
+	// Events are the types of the events delivered to the endpoint. If
+	// empty, all events are delivered.
+	Events []EventType
+}
+
+// ParseEndpointConfig parses an endpoint of the form
+// [<event>,<event>,...@]<url>.
+func ParseEndpointConfig(s string) (*EndpointConfig, error) {
+	endpoint := &EndpointConfig{
+		URL: s,
+	}
+
+	// The event list is separated by the first @, unless the @ is part of
+	// the URL itself.
+	idx := strings.Index(s, "@")
+	if idx > 0 && !strings.Contains(s[:idx], "://") {
+		events, err := parseEventTypes(strings.Split(s[:idx], ","))
+		if err != nil {
+			return nil, err
+		}
+
+		endpoint.URL = s[idx+1:]
+		endpoint.Events = events
+	}
+
+	if err := validateURL(endpoint.URL); err != nil {
+		return nil, err
+	}
+
+	return endpoint, nil
+}
+
+// parseEventTypes parses a list of event type names.
+func parseEventTypes(names []string) ([]EventType, error) {
+	eventTypes := make([]EventType, 0, len(names))
+	for _, name := range names {
+		eventType, err := ParseEventType(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+
+		eventTypes = append(eventTypes, eventType)
+	}
+
+	return eventTypes, nil
+}
+
+// validateURL checks that the given URL is an absolute http or https URL.
+func validateURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: %v is not an absolute http or https "+
+			"URL", ErrInvalidURL, rawURL)
+	}
+
+	return nil
+}
+
+// Sign returns the signature of a payload delivered at the given unix
+// timestamp, as sent in the HeaderSignature header. The signature is the
+// hex encoded HMAC-SHA256 of <timestamp>.<payload> keyed with the secret of
+// the endpoint. Receivers should recompute it and compare it in constant
+// time, and reject deliveries with an old timestamp to prevent replays.
+func Sign(secret []byte, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Config holds the configuration of the webhook manager.
+type Config struct {
+	// Store persists the endpoints and deliveries.
+	Store Store
+
+	// Endpoints are the endpoints configured in lnd.conf. They are added
+	// to the store on startup, and the configured endpoints that were
+	// removed from lnd.conf are removed from the store.
+	Endpoints []*EndpointConfig
+
+	// Secret is the secret of the endpoints configured in lnd.conf. If
+	// empty, a random secret is generated for each endpoint.
+	Secret []byte
+
+	// MaxAttempts is the number of times a delivery is attempted before
+	// it is moved to the dead-letter queue.
+	MaxAttempts uint32
+
+	// InitialBackoff is the time waited before the first retry of a
+	// failed delivery. The backoff doubles with every attempt.
+	InitialBackoff time.Duration
+
+	// MaxBackoff is the upper bound of the time waited between two
+	// delivery attempts.
+	MaxBackoff time.Duration
+
+	// Timeout is the timeout of a single delivery attempt.
+	Timeout time.Duration
+
+	// SubscribeChannelEvents subscribes to the events of the channel
+	// notifier. If nil, no channel events are delivered.
+	SubscribeChannelEvents func() (subscribe.Subscription, error)
+
+	// SubscribeHtlcEvents subscribes to the events of the htlc notifier.
+	// If nil, no htlc events are delivered.
+	SubscribeHtlcEvents func() (subscribe.Subscription, error)
+
+	// SubscribeSettledInvoices subscribes to all invoices that are
+	// settled from now on. The returned function cancels the
+	// subscription. If nil, no invoice events are delivered.
+	SubscribeSettledInvoices func(ctx context.Context) (
+		<-chan *invoices.Invoice, func(), error)
+
+	// SubscribePayments subscribes to the updates of all payments. If nil,
+	// no payment events are delivered.
+	SubscribePayments func() (routing.ControlTowerSubscriber, error)
+
+	// FetchChannelBackup returns the number of channels and the packed
+	// multi-channel backup of all channels. If nil, no backup events are
+	// delivered.
+	FetchChannelBackup func() (int, []byte, error)
+
+	// Clock is the clock used to schedule the delivery attempts.
+	Clock clock.Clock
+}
+
+// Manager delivers node events to the registered webhook endpoints.
+type Manager struct {
+	started sync.Once
+	stopped sync.Once
+
+	cfg *Config
+
+	client *http.Client
+
+	// endpoints holds the registered endpoints by their ID.
+	endpoints map[uint64]*Endpoint
+	mtx       sync.Mutex
+
+	// deliveriesAdded is signaled when new deliveries are pending or a
+	// delivery worker finished.
+	deliveriesAdded chan struct{}
+
+	// busy holds the endpoints a delivery worker is running for, and
+	// pausedUntil the time until which the deliveries to an endpoint are
+	// paused after one of them failed. Both are guarded by workerMtx.
+	busy        map[uint64]struct{}
+	pausedUntil map[uint64]time.Time
+	workerMtx   sync.Mutex
+
+	wg   sync.WaitGroup
+	quit chan struct{}
+}
+
+// New creates a new webhook manager.
+func New(cfg *Config) *Manager {
+	return &Manager{
+		cfg: cfg,
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+		},
+		endpoints:       make(map[uint64]*Endpoint),
+		deliveriesAdded: make(chan struct{}, 1),
+		busy:            make(map[uint64]struct{}),
+		pausedUntil:     make(map[uint64]time.Time),
+		quit:            make(chan struct{}),
+	}
+}
+
+// Start loads the endpoints, starts delivering the pending deliveries and
+// subscribes to the node events.
+func (m *Manager) Start() error {
+	var startErr error
+	m.started.Do(func() {
+		log.Info("Webhook manager starting")
+
+		if err := m.loadEndpoints(); err != nil {
+			startErr = err
+			return
+		}
+
+		if err := m.subscribeEvents(); err != nil {
+			startErr = err
+			return
+		}
+
+		m.wg.Add(1)
+		go m.deliveryLoop()
+	})
+
+	return startErr
+}
+
+// Stop stops delivering events.
+func (m *Manager) Stop() error {
+	m.stopped.Do(func() {
+		log.Info("Webhook manager shutting down...")
+		defer log.Debug("Webhook manager shutdown complete")
+
+		close(m.quit)
+		m.wg.Wait()
+	})
+
+	return nil
+}
+
+// loadEndpoints loads the stored endpoints and synchronizes them with the
+// endpoints configured in lnd.conf.
+func (m *Manager) loadEndpoints() error {
+	stored, err := m.cfg.Store.FetchEndpoints()
+	if err != nil {
+		return err
+	}
+
+	configured := make(map[string]*EndpointConfig, len(m.cfg.Endpoints))
+	for _, endpoint := range m.cfg.Endpoints {
+		configured[endpoint.URL] = endpoint
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	for _, endpoint := range stored {
+		if !endpoint.FromConfig {
+			m.endpoints[endpoint.ID] = endpoint
+			continue
+		}
+
+		endpointCfg, ok := configured[endpoint.URL]
+		if !ok {
+			log.Infof("Removing webhook endpoint %v that is no "+
+				"longer configured", endpoint.URL)
+
+			err := m.cfg.Store.DeleteEndpoint(endpoint.ID)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+		delete(configured, endpoint.URL)
+
+		// Update the event filter and secret of the endpoint in case
+		// they were changed in lnd.conf.
+		endpoint.Events = endpointCfg.Events
+		if len(m.cfg.Secret) > 0 {
+			endpoint.Secret = m.cfg.Secret
+		}
+		if err := m.cfg.Store.UpdateEndpoint(endpoint); err != nil {
+			return err
+		}
+
+		m.endpoints[endpoint.ID] = endpoint
+	}
+
+	// Add the configured endpoints that aren't stored yet, in the order
+	// of the configuration.
+	for _, endpointCfg := range m.cfg.Endpoints {
+		if _, ok := configured[endpointCfg.URL]; !ok {
+			continue
+		}
+		delete(configured, endpointCfg.URL)
+
+		endpoint, err := m.addEndpoint(
+			endpointCfg.URL, endpointCfg.Events, m.cfg.Secret, true,
+		)
+		if err != nil {
+			return err
+		}
+
+		m.endpoints[endpoint.ID] = endpoint
+	}
+
+	log.Infof("Delivering events to %d webhook endpoints",
+		len(m.endpoints))
+
+	return nil
+}
+
+// AddEndpoint registers a new endpoint that the events of the given types are
+// delivered to. If no event types are given, all events are delivered. If the
+// secret is empty, a random secret is generated.
+func (m *Manager) AddEndpoint(rawURL string, events []EventType,
+	secret []byte) (*Endpoint, error) {
+
+	if err := validateURL(rawURL); err != nil {
+		return nil, err
+	}
+
+	for _, eventType := range events {
+		if !eventType.Valid() {
+			return nil, fmt.Errorf("%w: %d", ErrUnknownEventType,
+				uint8(eventType))
+		}
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	endpoint, err := m.addEndpoint(rawURL, events, secret, false)
+	if err != nil {
+		return nil, err
+	}
+
+	m.endpoints[endpoint.ID] = endpoint
+
+	log.Infof("Added webhook endpoint %d: %v", endpoint.ID, rawURL)
+
+	return copyEndpoint(endpoint), nil
+}
+
+// addEndpoint stores a new endpoint.
+//
+// NOTE: The caller must hold the mutex.
+func (m *Manager) addEndpoint(rawURL string, events []EventType,
+	secret []byte, fromConfig bool) (*Endpoint, error) {
+
+	if len(secret) == 0 {
+		secret = make([]byte, secretSize)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+	}
+
+	endpoint := &Endpoint{
+		URL:        rawURL,
+		Events:     events,
+		Secret:     secret,
+		FromConfig: fromConfig,
+		CreatedAt:  m.cfg.Clock.Now(),
+	}
+	if err := m.cfg.Store.AddEndpoint(endpoint); err != nil {
+		return nil, err
+	}
+
+	return endpoint, nil
+}
+
+// RemoveEndpoint removes an endpoint together with all its pending and failed
+// deliveries. Endpoints configured in lnd.conf can't be removed.
+func (m *Manager) RemoveEndpoint(id uint64) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	endpoint, ok := m.endpoints[id]
+	if !ok {
+		return ErrEndpointNotFound
+	}
+
+	if endpoint.FromConfig {
+		return ErrConfigEndpoint
+	}
+
+	if err := m.cfg.Store.DeleteEndpoint(id); err != nil {
+		return err
+	}
+	delete(m.endpoints, id)
+
+	log.Infof("Removed webhook endpoint %d: %v", id, endpoint.URL)
+
+	return nil
+}
+
+// Endpoints returns the registered endpoints, ordered by their ID.
+func (m *Manager) Endpoints() []*Endpoint {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	endpoints := make([]*Endpoint, 0, len(m.endpoints))
+	for _, endpoint := range m.endpoints {
+		endpoints = append(endpoints, copyEndpoint(endpoint))
+	}
+	sortEndpoints(endpoints)
+
+	return endpoints
+}
+
+// Deliveries returns the deliveries in the given state. If the endpoint ID is
+// non-zero, only the deliveries to that endpoint are returned.
+func (m *Manager) Deliveries(state DeliveryState,
+	endpointID uint64) ([]*Delivery, error) {
+
+	deliveries, err := m.cfg.Store.FetchDeliveries(state)
+	if err != nil {
+		return nil, err
+	}
+
+	if endpointID == 0 {
+		return deliveries, nil
+	}
+
+	filtered := deliveries[:0]
+	for _, d := range deliveries {
+		if d.EndpointID == endpointID {
+			filtered = append(filtered, d)
+		}
+	}
+
+	return filtered, nil
+}
+
+// RetryDelivery moves a delivery from the dead-letter queue back to the
+// pending deliveries, to be attempted again right away.
+func (m *Manager) RetryDelivery(id uint64) error {
+	d, err := m.cfg.Store.FetchDelivery(id)
+	if err != nil {
+		return err
+	}
+
+	if d.State != DeliveryFailed {
+		return ErrNotDeadLetter
+	}
+
+	d.State = DeliveryPending
+	d.Attempts = 0
+	d.NextAttempt = m.cfg.Clock.Now()
+	if err := m.cfg.Store.PutDelivery(d); err != nil {
+		return err
+	}
+
+	log.Infof("Retrying webhook delivery %d of event %v", d.ID,
+		d.EventType)
+
+	m.signalDeliveries()
+
+	return nil
+}
+
+// Notify delivers the given event to all endpoints that subscribed to its
+// type. The deliveries are stored before the call returns, so they survive a
+// restart.
+func (m *Manager) Notify(event *Event) error {
+	if event.ID == "" {
+		var id [16]byte
+		if _, err := rand.Read(id[:]); err != nil {
+			return err
+		}
+		event.ID = hex.EncodeToString(id[:])
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = m.cfg.Clock.Now()
+	}
+
+	payload, err := event.encode()
+	if err != nil {
+		return err
+	}
+
+	// We hold the mutex until the deliveries are stored, so an endpoint
+	// can't be removed in the meantime and leave its deliveries behind.
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	now := m.cfg.Clock.Now()
+	var deliveries []*Delivery
+	for id, endpoint := range m.endpoints {
+		if !endpoint.Subscribed(event.Type) {
+			continue
+		}
+
+		deliveries = append(deliveries, &Delivery{
+			EndpointID:  id,
+			EventID:     event.ID,
+			EventType:   event.Type,
+			Payload:     payload,
+			State:       DeliveryPending,
+			CreatedAt:   now,
+			NextAttempt: now,
+		})
+	}
+
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	if err := m.cfg.Store.AddDeliveries(deliveries); err != nil {
+		return err
+	}
+
+	log.Debugf("Queued %d webhook deliveries of event %v (%v)",
+		len(deliveries), event.ID, event.Type)
+
+	m.signalDeliveries()
+
+	return nil
+}
+
+// signalDeliveries wakes up the delivery loop.
+func (m *Manager) signalDeliveries() {
+	select {
+	case m.deliveriesAdded <- struct{}{}:
+	default:
+	}
+}
+
+// deliveryLoop attempts the pending deliveries once they are due.
+//
+// NOTE: This MUST be run as a goroutine.
+func (m *Manager) deliveryLoop() {
+	defer m.wg.Done()
+
+	for {
+		next, err := m.deliverPending()
+		if err != nil {
+			log.Errorf("Unable to deliver webhook events: %v", err)
+		}
+
+		// Wait until the next delivery is due or new deliveries are
+		// added. If the deliveries couldn't be loaded, we try again
+		// after the initial backoff.
+		var retry <-chan time.Time
+		switch {
+		case err != nil:
+			retry = m.cfg.Clock.TickAfter(m.cfg.InitialBackoff)
+
+		case !next.IsZero():
+			retry = m.cfg.Clock.TickAfter(
+				next.Sub(m.cfg.Clock.Now()),
+			)
+		}
+
+		select {
+		case <-m.deliveriesAdded:
+		case <-retry:
+		case <-m.quit:
+			return
+		}
+	}
+}
+
+// deliverPending starts a worker for every endpoint with pending deliveries
+// that are due and returns the time the next pending delivery is due, or the
+// zero time if there are no more pending deliveries. The deliveries to an
+// endpoint are attempted one after the other by its worker, while the workers
+// of different endpoints run independently, such that an unreachable endpoint
+// can't hold up the deliveries to the others.
+func (m *Manager) deliverPending() (time.Time, error) {
+	pending, err := m.cfg.Store.FetchDeliveries(DeliveryPending)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	m.workerMtx.Lock()
+	defer m.workerMtx.Unlock()
+
+	var next time.Time
+	updateNext := func(t time.Time) {
+		if next.IsZero() || t.Before(next) {
+			next = t
+		}
+	}
+
+	now := m.cfg.Clock.Now()
+	due := make(map[uint64][]*Delivery)
+	for _, d := range pending {
+		// The deliveries of endpoints with a running worker are
+		// looked at again once the worker finished.
+		if _, ok := m.busy[d.EndpointID]; ok {
+			continue
+		}
+
+		paused, ok := m.pausedUntil[d.EndpointID]
+		switch {
+		case ok && paused.After(now):
+			updateNext(paused)
+
+		case d.NextAttempt.After(now):
+			updateNext(d.NextAttempt)
+
+		default:
+			due[d.EndpointID] = append(due[d.EndpointID], d)
+		}
+	}
+
+	for endpointID, deliveries := range due {
+		m.mtx.Lock()
+		endpoint, ok := m.endpoints[endpointID]
+		if ok {
+			endpoint = copyEndpoint(endpoint)
+		}
+		m.mtx.Unlock()
+
+		delete(m.pausedUntil, endpointID)
+
+		// The endpoint was removed together with its deliveries in
+		// the meantime.
+		if !ok {
+			continue
+		}
+
+		m.busy[endpointID] = struct{}{}
+
+		m.wg.Add(1)
+		go m.deliverToEndpoint(endpoint, deliveries)
+	}
+
+	return next, nil
+}
+
+// deliverToEndpoint attempts the given due deliveries to an endpoint one after
+// the other. Once a delivery fails, the remaining ones are paused until it is
+// retried, as the endpoint is likely unreachable and every attempt would only
+// run into the timeout.
+//
+// NOTE: This MUST be run as a goroutine.
+func (m *Manager) deliverToEndpoint(endpoint *Endpoint,
+	deliveries []*Delivery) {
+
+	defer m.wg.Done()
+
+	var pausedUntil time.Time
+	defer func() {
+		m.workerMtx.Lock()
+		delete(m.busy, endpoint.ID)
+		if !pausedUntil.IsZero() {
+			m.pausedUntil[endpoint.ID] = pausedUntil
+		}
+		m.workerMtx.Unlock()
+
+		// Let the delivery loop pick up the deliveries that were
+		// added or became due while we were busy.
+		m.signalDeliveries()
+	}()
+
+	for _, d := range deliveries {
+		select {
+		case <-m.quit:
+			return
+		default:
+		}
+
+		err := m.attempt(endpoint, d)
+		switch {
+		// The endpoint was removed while we were delivering to it.
+		case errors.Is(err, ErrEndpointNotFound):
+			return
+
+		case err != nil:
+			log.Errorf("Unable to update webhook delivery %d: %v",
+				d.ID, err)
+
+			continue
+		}
+
+		if d.State == DeliveryPending {
+			log.Debugf("Pausing deliveries to endpoint %d until %v",
+				endpoint.ID, d.NextAttempt)
+
+			pausedUntil = d.NextAttempt
+
+			return
+		}
+	}
+}
+
+// attempt posts the payload of a delivery to its endpoint and records the
+// outcome.
+func (m *Manager) attempt(endpoint *Endpoint, d *Delivery) error {
+	now := m.cfg.Clock.Now()
+	statusCode, postErr := m.post(endpoint, d, now)
+
+	d.Attempts++
+	d.LastAttempt = now
+	d.LastStatusCode = uint32(statusCode)
+	d.LastError = ""
+
+	switch {
+	case postErr == nil:
+		d.State = DeliverySucceeded
+
+		log.Debugf("Delivered webhook event %v (%v) to endpoint %d",
+			d.EventID, d.EventType, endpoint.ID)
+
+	case d.Attempts >= m.cfg.MaxAttempts:
+		d.State = DeliveryFailed
+		d.LastError = postErr.Error()
+
+		log.Warnf("Moving webhook delivery %d of event %v to endpoint "+
+			"%v to the dead-letter queue after %d attempts: %v",
+			d.ID, d.EventType, endpoint.URL, d.Attempts, postErr)
+
+	default:
+		d.LastError = postErr.Error()
+		d.NextAttempt = now.Add(m.backoff(d.Attempts))
+
+		log.Debugf("Webhook delivery %d to endpoint %v failed, "+
+			"retrying at %v: %v", d.ID, endpoint.URL,
+			d.NextAttempt, postErr)
+	}
+
+	return m.cfg.Store.PutDelivery(d)
+}
+
+// post sends the payload of a delivery to the endpoint. The HTTP status code
+// is returned, or zero if no response was received.
+func (m *Manager) post(endpoint *Endpoint, d *Delivery,
+	now time.Time) (int, error) {
+
+	ctx, cancel := context.WithTimeout(
+		context.Background(), m.cfg.Timeout,
+	)
+	defer cancel()
+
+	// Abort the request if we're shutting down.
+	go func() {
+		select {
+		case <-m.quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodPost, endpoint.URL, bytes.NewReader(d.Payload),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := now.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEventID, d.EventID)
+	req.Header.Set(HeaderEventType, d.EventType.String())
+	req.Header.Set(HeaderDeliveryID, strconv.FormatUint(d.ID, 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(
+		HeaderSignature, Sign(endpoint.Secret, timestamp, d.Payload),
+	)
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+
+	return resp.StatusCode, fmt.Errorf("endpoint returned %v: %s",
+		resp.Status, strings.TrimSpace(string(body)))
+}
+
+// backoff returns the time waited after the given number of failed attempts.
+func (m *Manager) backoff(attempts uint32) time.Duration {
+	backoff := m.cfg.InitialBackoff
+	for i := uint32(1); i < attempts; i++ {
+		backoff *= 2
+		if backoff >= m.cfg.MaxBackoff {
+			return m.cfg.MaxBackoff
+		}
+	}
+
+	return backoff
+}
+
+// copyEndpoint returns a copy of the given endpoint that can be handed out
+// without holding the mutex.
+func copyEndpoint(e *Endpoint) *Endpoint {
+	c := *e
+	c.Events = append([]EventType(nil), e.Events...)
+	c.Secret = append([]byte(nil), e.Secret...)
+
+	return &c
+}
+
+// sortEndpoints sorts the given endpoints by their ID.
+func sortEndpoints(endpoints []*Endpoint) {
+	for i := 1; i < len(endpoints); i++ {
+		for j := i; j > 0 && endpoints[j].ID < endpoints[j-1].ID; j-- {
+			endpoints[j], endpoints[j-1] = endpoints[j-1],
+				endpoints[j]
+		}
+	}
+}
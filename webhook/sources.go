@@ -0,0 +1,378 @@
+package webhook
+
+import (
+	"context"
+	"encoding/hex"
+
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/channelnotifier"
+	"github.com/lightningnetwork/lnd/htlcswitch"
+	"github.com/lightningnetwork/lnd/invoices"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/routing"
+	"github.com/lightningnetwork/lnd/subscribe"
+)
+
+// closeTypeNames maps the closure types to the names used in the payloads.
+var closeTypeNames = map[channeldb.ClosureType]string{
+	channeldb.CooperativeClose: "cooperative_close",
+	channeldb.LocalForceClose:  "local_force_close",
+	channeldb.RemoteForceClose: "remote_force_close",
+	channeldb.BreachClose:      "breach_close",
+	channeldb.FundingCanceled:  "funding_canceled",
+	channeldb.Abandoned:        "abandoned",
+}
+
+// subscribeEvents subscribes to the configured event sources and starts a
+// goroutine for each of them that turns their updates into events.
+func (m *Manager) subscribeEvents() error {
+	if m.cfg.SubscribeChannelEvents != nil {
+		sub, err := m.cfg.SubscribeChannelEvents()
+		if err != nil {
+			return err
+		}
+
+		m.wg.Add(1)
+		go m.consumeSubscription(sub, m.channelEvents)
+	}
+
+	if m.cfg.SubscribeHtlcEvents != nil {
+		sub, err := m.cfg.SubscribeHtlcEvents()
+		if err != nil {
+			return err
+		}
+
+		m.wg.Add(1)
+		go m.consumeSubscription(sub, m.htlcEvents)
+	}
+
+	if m.cfg.SubscribeSettledInvoices != nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		settled, cancelSub, err := m.cfg.SubscribeSettledInvoices(ctx)
+		if err != nil {
+			cancel()
+			return err
+		}
+
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			defer cancel()
+			defer cancelSub()
+
+			for {
+				select {
+				case invoice, ok := <-settled:
+					if !ok {
+						return
+					}
+
+					m.notify(invoiceEvent(invoice))
+
+				case <-m.quit:
+					return
+				}
+			}
+		}()
+	}
+
+	if m.cfg.SubscribePayments != nil {
+		sub, err := m.cfg.SubscribePayments()
+		if err != nil {
+			return err
+		}
+
+		m.wg.Add(1)
+		go m.consumePayments(sub)
+	}
+
+	return nil
+}
+
+// consumeSubscription hands the updates of a notifier subscription to the
+// given handler until the subscription or the manager quits.
+//
+// NOTE: This MUST be run as a goroutine.
+func (m *Manager) consumeSubscription(sub subscribe.Subscription,
+	handle func(interface{})) {
+
+	defer m.wg.Done()
+	defer sub.Cancel()
+
+	for {
+		select {
+		case update := <-sub.Updates():
+			handle(update)
+
+		case <-sub.Quit():
+			return
+
+		case <-m.quit:
+			return
+		}
+	}
+}
+
+// consumePayments turns the payment updates into events once the payments
+// reached a final state.
+//
+// NOTE: This MUST be run as a goroutine.
+func (m *Manager) consumePayments(sub routing.ControlTowerSubscriber) {
+	defer m.wg.Done()
+	defer sub.Close()
+
+	for {
+		select {
+		case update, ok := <-sub.Updates():
+			if !ok {
+				return
+			}
+
+			payment, ok := update.(*channeldb.MPPayment)
+			if !ok {
+				continue
+			}
+
+			if event := paymentEvent(payment); event != nil {
+				m.notify(event)
+			}
+
+		case <-m.quit:
+			return
+		}
+	}
+}
+
+// notify delivers an event and logs a failure to queue it.
+func (m *Manager) notify(event *Event) {
+	if err := m.Notify(event); err != nil {
+		log.Errorf("Unable to queue webhook event %v: %v", event.Type,
+			err)
+	}
+}
+
+// channelEvents turns the channel notifier updates into events. Every change
+// to the set of channels also changes the channel backup.
+func (m *Manager) channelEvents(update interface{}) {
+	switch e := update.(type) {
+	case channelnotifier.OpenChannelEvent:
+		m.notify(channelOpenedEvent(e.Channel))
+
+	case channelnotifier.ClosedChannelEvent:
+		m.notify(channelClosedEvent(e.CloseSummary))
+
+	case channelnotifier.PendingOpenChannelEvent,
+		channelnotifier.FullyResolvedChannelEvent:
+
+	default:
+		return
+	}
+
+	m.notifyBackup()
+}
+
+// channelOpenedEvent returns the event of a newly opened channel.
+func channelOpenedEvent(channel *channeldb.OpenChannel) *Event {
+	private := channel.ChannelFlags&lnwire.FFAnnounceChannel == 0
+
+	return &Event{
+		Type: EventChannelOpened,
+		Data: &ChannelOpened{
+			ChannelPoint: channel.FundingOutpoint.String(),
+			ChanID:       channel.ShortChanID().ToUint64(),
+			RemotePubkey: hex.EncodeToString(
+				channel.IdentityPub.SerializeCompressed(),
+			),
+			CapacitySat: int64(channel.Capacity),
+			Initiator:   channel.IsInitiator,
+			Private:     private,
+		},
+	}
+}
+
+// channelClosedEvent returns the event of a closed channel.
+func channelClosedEvent(summary *channeldb.ChannelCloseSummary) *Event {
+	var remotePubkey string
+	if summary.RemotePub != nil {
+		remotePubkey = hex.EncodeToString(
+			summary.RemotePub.SerializeCompressed(),
+		)
+	}
+
+	return &Event{
+		Type: EventChannelClosed,
+		Data: &ChannelClosed{
+			ChannelPoint:      summary.ChanPoint.String(),
+			ChanID:            summary.ShortChanID.ToUint64(),
+			RemotePubkey:      remotePubkey,
+			CapacitySat:       int64(summary.Capacity),
+			ClosingTxid:       summary.ClosingTXID.String(),
+			CloseType:         closeTypeNames[summary.CloseType],
+			SettledBalanceSat: int64(summary.SettledBalance),
+		},
+	}
+}
+
+// notifyBackup delivers the current channel backup.
+func (m *Manager) notifyBackup() {
+	if m.cfg.FetchChannelBackup == nil {
+		return
+	}
+
+	// Don't assemble the backup if nobody is interested in it.
+	if !m.subscribed(EventBackupChanged) {
+		return
+	}
+
+	numChannels, backup, err := m.cfg.FetchChannelBackup()
+	if err != nil {
+		log.Errorf("Unable to fetch channel backup: %v", err)
+		return
+	}
+
+	m.notify(&Event{
+		Type: EventBackupChanged,
+		Data: &BackupChanged{
+			NumChannels:     numChannels,
+			MultiChanBackup: backup,
+		},
+	})
+}
+
+// subscribed returns true if any endpoint subscribed to the event type.
+func (m *Manager) subscribed(eventType EventType) bool {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	for _, endpoint := range m.endpoints {
+		if endpoint.Subscribed(eventType) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// htlcEvents turns the htlc notifier failures into events.
+func (m *Manager) htlcEvents(update interface{}) {
+	var data *HtlcFailed
+	switch e := update.(type) {
+	case *htlcswitch.LinkFailEvent:
+		data = &HtlcFailed{
+			EventType:        e.HtlcEventType.String(),
+			IncomingChanID:   e.IncomingCircuit.ChanID.ToUint64(),
+			IncomingHtlcID:   e.IncomingCircuit.HtlcID,
+			OutgoingChanID:   e.OutgoingCircuit.ChanID.ToUint64(),
+			OutgoingHtlcID:   e.OutgoingCircuit.HtlcID,
+			IncomingAmtMsat:  uint64(e.IncomingAmt),
+			OutgoingAmtMsat:  uint64(e.OutgoingAmt),
+			LinkFailure:      true,
+			FailedIncoming:   e.Incoming,
+			TimestampNanosec: e.Timestamp.UnixNano(),
+		}
+
+		if e.LinkError != nil {
+			data.FailureString = e.LinkError.Error()
+			detail := e.LinkError.FailureDetail
+			if detail != nil {
+				data.FailureDetail = detail.FailureString()
+			}
+		}
+
+	case *htlcswitch.ForwardingFailEvent:
+		data = &HtlcFailed{
+			EventType:        e.HtlcEventType.String(),
+			IncomingChanID:   e.IncomingCircuit.ChanID.ToUint64(),
+			IncomingHtlcID:   e.IncomingCircuit.HtlcID,
+			OutgoingChanID:   e.OutgoingCircuit.ChanID.ToUint64(),
+			OutgoingHtlcID:   e.OutgoingCircuit.HtlcID,
+			TimestampNanosec: e.Timestamp.UnixNano(),
+		}
+
+	default:
+		return
+	}
+
+	m.notify(&Event{
+		Type: EventHtlcFailed,
+		Data: data,
+	})
+}
+
+// invoiceEvent returns the event of a settled invoice.
+func invoiceEvent(invoice *invoices.Invoice) *Event {
+	data := &InvoiceSettled{
+		Memo:           string(invoice.Memo),
+		PaymentRequest: string(invoice.PaymentRequest),
+		ValueMsat:      uint64(invoice.Terms.Value),
+		AmtPaidMsat:    uint64(invoice.AmtPaid),
+		SettleDate:     invoice.SettleDate.Unix(),
+		AddIndex:       invoice.AddIndex,
+		SettleIndex:    invoice.SettleIndex,
+		IsKeysend:      invoice.IsKeysend(),
+		IsAMP:          invoice.IsAMP(),
+	}
+
+	// AMP invoices don't have a single payment hash.
+	if invoice.Terms.PaymentPreimage != nil {
+		hash := invoice.Terms.PaymentPreimage.Hash()
+		data.PaymentHash = hash.String()
+	}
+
+	return &Event{
+		Type: EventInvoiceSettled,
+		Data: data,
+	}
+}
+
+// paymentEvent returns the event of a payment that reached a final state, or
+// nil if the payment is still in flight.
+func paymentEvent(payment *channeldb.MPPayment) *Event {
+	info := payment.Info
+	hash := info.PaymentIdentifier.String()
+
+	switch payment.Status {
+	case channeldb.StatusSucceeded:
+		settle, _ := payment.TerminalInfo()
+		_, fees := payment.SentAmt()
+
+		var preimage string
+		if settle != nil {
+			preimage = settle.Preimage.String()
+		}
+
+		return &Event{
+			Type: EventPaymentSucceeded,
+			Data: &PaymentSucceeded{
+				PaymentHash:     hash,
+				PaymentPreimage: preimage,
+				PaymentRequest:  string(info.PaymentRequest),
+				ValueMsat:       uint64(info.Value),
+				FeeMsat:         uint64(fees),
+				CreationDate:    info.CreationTime.Unix(),
+				IsRebalance:     info.Rebalance,
+			},
+		}
+
+	case channeldb.StatusFailed:
+		var reason string
+		if payment.FailureReason != nil {
+			reason = payment.FailureReason.String()
+		}
+
+		return &Event{
+			Type: EventPaymentFailed,
+			Data: &PaymentFailed{
+				PaymentHash:    hash,
+				PaymentRequest: string(info.PaymentRequest),
+				ValueMsat:      uint64(info.Value),
+				FailureReason:  reason,
+				CreationDate:   info.CreationTime.Unix(),
+				IsRebalance:    info.Rebalance,
+			},
+		}
+
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,828 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/lightningnetwork/lnd/kvdb"
+	"github.com/lightningnetwork/lnd/tlv"
+)
+
+var (
+	// webhookBucket is the top level bucket of the webhook deliveries. It
+	// holds the sub-buckets below.
+	webhookBucket = []byte("webhooks")
+
+	// endpointsBucket holds the registered endpoints.
+	//
+	// maps: endpoint ID -> TLV encoded Endpoint
+	endpointsBucket = []byte("endpoints")
+
+	// pendingBucket holds the deliveries that still have to be attempted.
+	// Its sequence is used to assign the IDs of all deliveries.
+	//
+	// maps: delivery ID -> TLV encoded Delivery
+	pendingBucket = []byte("pending")
+
+	// deliveredBucket holds the most recent successful deliveries.
+	//
+	// maps: delivery ID -> TLV encoded Delivery
+	deliveredBucket = []byte("delivered")
+
+	// deadLetterBucket is the dead-letter queue. It holds the deliveries
+	// that failed too often, until they are retried or their endpoint is
+	// removed.
+	//
+	// maps: delivery ID -> TLV encoded Delivery
+	deadLetterBucket = []byte("dead-letters")
+
+	// ErrEndpointNotFound is returned when an endpoint isn't known to the
+	// store.
+	ErrEndpointNotFound = errors.New("webhook endpoint not found")
+
+	// ErrDeliveryNotFound is returned when a delivery isn't known to the
+	// store.
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+
+	// errNoWebhookBucket is returned when the webhook buckets don't exist.
+	errNoWebhookBucket = errors.New("webhook bucket does not exist")
+)
+
+const (
+	typeEndpointURL        tlv.Type = 0
+	typeEndpointEvents     tlv.Type = 1
+	typeEndpointSecret     tlv.Type = 2
+	typeEndpointFromConfig tlv.Type = 3
+	typeEndpointCreatedAt  tlv.Type = 4
+
+	typeDeliveryEndpointID  tlv.Type = 0
+	typeDeliveryEventID     tlv.Type = 1
+	typeDeliveryEventType   tlv.Type = 2
+	typeDeliveryPayload     tlv.Type = 3
+	typeDeliveryState       tlv.Type = 4
+	typeDeliveryCreatedAt   tlv.Type = 5
+	typeDeliveryAttempts    tlv.Type = 6
+	typeDeliveryLastAttempt tlv.Type = 7
+	typeDeliveryNextAttempt tlv.Type = 8
+	typeDeliveryStatusCode  tlv.Type = 9
+	typeDeliveryLastError   tlv.Type = 10
+)
+
+// Endpoint is an HTTP endpoint that events are delivered to.
+type Endpoint struct {
+	// ID uniquely identifies the endpoint. It is assigned by the store.
+	ID uint64
+
+	// URL is the URL the events are posted to.
+	URL string
+
+	// Events are the types of the events delivered to the endpoint. If
+	// empty, all events are delivered.
+	Events []EventType
+
+	// Secret is the key of the HMAC signatures of the payloads.
+	Secret []byte
+
+	// FromConfig is true if the endpoint is configured in lnd.conf. Such
+	// endpoints can only be removed through the configuration.
+	FromConfig bool
+
+	// CreatedAt is the time the endpoint was registered at.
+	CreatedAt time.Time
+}
+
+// Subscribed returns true if events of the given type are delivered to the
+// endpoint.
+func (e *Endpoint) Subscribed(eventType EventType) bool {
+	if len(e.Events) == 0 {
+		return true
+	}
+
+	for _, t := range e.Events {
+		if t == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DeliveryState is the state of the delivery of an event to an endpoint.
+type DeliveryState uint8
+
+const (
+	// DeliveryPending is the state of a delivery that still has to be
+	// attempted.
+	DeliveryPending DeliveryState = 0
+
+	// DeliverySucceeded is the state of a delivery that the endpoint
+	// accepted.
+	DeliverySucceeded DeliveryState = 1
+
+	// DeliveryFailed is the state of a delivery that failed too often and
+	// was moved to the dead-letter queue.
+	DeliveryFailed DeliveryState = 2
+)
+
+// String returns a human readable delivery state.
+func (s DeliveryState) String() string {
+	switch s {
+	case DeliveryPending:
+		return "pending"
+
+	case DeliverySucceeded:
+		return "succeeded"
+
+	case DeliveryFailed:
+		return "failed"
+
+	default:
+		return "unknown"
+	}
+}
+
+// Delivery is the delivery of an event to an endpoint.
+type Delivery struct {
+	// ID uniquely identifies the delivery. It is assigned by the store.
+	ID uint64
+
+	// EndpointID is the ID of the endpoint the event is delivered to.
+	EndpointID uint64
+
+	// EventID is the ID of the delivered event.
+	EventID string
+
+	// EventType is the type of the delivered event.
+	EventType EventType
+
+	// Payload is the JSON document posted to the endpoint.
+	Payload []byte
+
+	// State is the state of the delivery.
+	State DeliveryState
+
+	// CreatedAt is the time the delivery was created at.
+	CreatedAt time.Time
+
+	// Attempts is the number of delivery attempts made.
+	Attempts uint32
+
+	// LastAttempt is the time of the last delivery attempt.
+	LastAttempt time.Time
+
+	// NextAttempt is the time of the next delivery attempt of a pending
+	// delivery.
+	NextAttempt time.Time
+
+	// LastStatusCode is the HTTP status code returned by the endpoint on
+	// the last attempt, or zero if no response was received.
+	LastStatusCode uint32
+
+	// LastError describes why the last attempt failed.
+	LastError string
+}
+
+// Store persists the webhook endpoints and deliveries.
+type Store interface {
+	// AddEndpoint stores a new endpoint and assigns its ID.
+	AddEndpoint(e *Endpoint) error
+
+	// UpdateEndpoint replaces a stored endpoint.
+	UpdateEndpoint(e *Endpoint) error
+
+	// DeleteEndpoint removes an endpoint together with all its
+	// deliveries.
+	DeleteEndpoint(id uint64) error
+
+	// FetchEndpoints returns all stored endpoints.
+	FetchEndpoints() ([]*Endpoint, error)
+
+	// AddDeliveries stores new pending deliveries and assigns their IDs.
+	AddDeliveries(deliveries []*Delivery) error
+
+	// PutDelivery stores a delivery in the queue of its state, removing it
+	// from the queue of its previous state. If it is a successful
+	// delivery, the oldest successful deliveries beyond the history limit
+	// are pruned. ErrEndpointNotFound is returned if the endpoint of the
+	// delivery was removed in the meantime.
+	PutDelivery(d *Delivery) error
+
+	// FetchDelivery returns the delivery with the given ID.
+	FetchDelivery(id uint64) (*Delivery, error)
+
+	// FetchDeliveries returns all deliveries in the given state, ordered
+	// by their ID.
+	FetchDeliveries(state DeliveryState) ([]*Delivery, error)
+}
+
+// kvStore is a Store that is backed by a kvdb backend.
+type kvStore struct {
+	db kvdb.Backend
+
+	// deliveryHistory is the number of successful deliveries kept.
+	deliveryHistory uint32
+}
+
+// A compile-time check to ensure kvStore implements the Store interface.
+var _ Store = (*kvStore)(nil)
+
+// NewStore returns a new Store backed by the given database that keeps the
+// given number of successful deliveries.
+func NewStore(db kvdb.Backend, deliveryHistory uint32) (Store, error) {
+	err := kvdb.Update(db, func(tx kvdb.RwTx) error {
+		bucket, err := tx.CreateTopLevelBucket(webhookBucket)
+		if err != nil {
+			return err
+		}
+
+		subBuckets := [][]byte{
+			endpointsBucket, pendingBucket, deliveredBucket,
+			deadLetterBucket,
+		}
+		for _, name := range subBuckets {
+			_, err := bucket.CreateBucketIfNotExists(name)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, func() {})
+	if err != nil {
+		return nil, err
+	}
+
+	return &kvStore{
+		db:              db,
+		deliveryHistory: deliveryHistory,
+	}, nil
+}
+
+// AddEndpoint stores a new endpoint and assigns its ID.
+//
+// NOTE: Part of the Store interface.
+func (s *kvStore) AddEndpoint(e *Endpoint) error {
+	var id uint64
+	err := kvdb.Update(s.db, func(tx kvdb.RwTx) error {
+		bucket, err := subBucket(tx, endpointsBucket)
+		if err != nil {
+			return err
+		}
+
+		id, err = bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		return putEndpoint(bucket, id, e)
+	}, func() {
+		id = 0
+	})
+	if err != nil {
+		return err
+	}
+
+	e.ID = id
+
+	return nil
+}
+
+// UpdateEndpoint replaces a stored endpoint.
+//
+// NOTE: Part of the Store interface.
+func (s *kvStore) UpdateEndpoint(e *Endpoint) error {
+	return kvdb.Update(s.db, func(tx kvdb.RwTx) error {
+		bucket, err := subBucket(tx, endpointsBucket)
+		if err != nil {
+			return err
+		}
+
+		if bucket.Get(idKey(e.ID)) == nil {
+			return ErrEndpointNotFound
+		}
+
+		return putEndpoint(bucket, e.ID, e)
+	}, func() {})
+}
+
+// DeleteEndpoint removes an endpoint together with all its deliveries.
+//
+// NOTE: Part of the Store interface.
+func (s *kvStore) DeleteEndpoint(id uint64) error {
+	return kvdb.Update(s.db, func(tx kvdb.RwTx) error {
+		bucket, err := subBucket(tx, endpointsBucket)
+		if err != nil {
+			return err
+		}
+
+		if bucket.Get(idKey(id)) == nil {
+			return ErrEndpointNotFound
+		}
+
+		if err := bucket.Delete(idKey(id)); err != nil {
+			return err
+		}
+
+		deliveryBuckets := [][]byte{
+			pendingBucket, deliveredBucket, deadLetterBucket,
+		}
+		for _, name := range deliveryBuckets {
+			err := deleteEndpointDeliveries(tx, name, id)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, func() {})
+}
+
+// deleteEndpointDeliveries removes the deliveries of the given endpoint from
+// a delivery bucket.
+func deleteEndpointDeliveries(tx kvdb.RwTx, name []byte,
+	endpointID uint64) error {
+
+	bucket, err := subBucket(tx, name)
+	if err != nil {
+		return err
+	}
+
+	// Keys can't be deleted while iterating over the bucket, so we
+	// collect them first.
+	var keys [][]byte
+	err = bucket.ForEach(func(k, v []byte) error {
+		d, err := deserializeDelivery(bytes.NewReader(v))
+		if err != nil {
+			return err
+		}
+
+		if d.EndpointID == endpointID {
+			keys = append(keys, append([]byte(nil), k...))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, k := range keys {
+		if err := bucket.Delete(k); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// FetchEndpoints returns all stored endpoints.
+//
+// NOTE: Part of the Store interface.
+func (s *kvStore) FetchEndpoints() ([]*Endpoint, error) {
+	var endpoints []*Endpoint
+	err := kvdb.View(s.db, func(tx kvdb.RTx) error {
+		bucket, err := readSubBucket(tx, endpointsBucket)
+		if err != nil {
+			return err
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			e, err := deserializeEndpoint(bytes.NewReader(v))
+			if err != nil {
+				return err
+			}
+			e.ID = binary.BigEndian.Uint64(k)
+
+			endpoints = append(endpoints, e)
+
+			return nil
+		})
+	}, func() {
+		endpoints = nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return endpoints, nil
+}
+
+// AddDeliveries stores new pending deliveries and assigns their IDs.
+//
+// NOTE: Part of the Store interface.
+func (s *kvStore) AddDeliveries(deliveries []*Delivery) error {
+	ids := make([]uint64, len(deliveries))
+	err := kvdb.Update(s.db, func(tx kvdb.RwTx) error {
+		bucket, err := subBucket(tx, pendingBucket)
+		if err != nil {
+			return err
+		}
+
+		for i, d := range deliveries {
+			ids[i], err = bucket.NextSequence()
+			if err != nil {
+				return err
+			}
+
+			var b bytes.Buffer
+			if err := serializeDelivery(&b, d); err != nil {
+				return err
+			}
+
+			err = bucket.Put(idKey(ids[i]), b.Bytes())
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, func() {})
+	if err != nil {
+		return err
+	}
+
+	for i, d := range deliveries {
+		d.ID = ids[i]
+	}
+
+	return nil
+}
+
+// PutDelivery stores a delivery in the queue of its state, removing it from
+// the queue of its previous state. If it is a successful delivery, the oldest
+// successful deliveries beyond the history limit are pruned.
+//
+// NOTE: Part of the Store interface.
+func (s *kvStore) PutDelivery(d *Delivery) error {
+	var b bytes.Buffer
+	if err := serializeDelivery(&b, d); err != nil {
+		return err
+	}
+
+	return kvdb.Update(s.db, func(tx kvdb.RwTx) error {
+		// Don't resurrect the deliveries of a removed endpoint.
+		endpoints, err := subBucket(tx, endpointsBucket)
+		if err != nil {
+			return err
+		}
+		if endpoints.Get(idKey(d.EndpointID)) == nil {
+			return ErrEndpointNotFound
+		}
+
+		target := stateBucket(d.State)
+		deliveryBuckets := [][]byte{
+			pendingBucket, deliveredBucket, deadLetterBucket,
+		}
+		for _, name := range deliveryBuckets {
+			bucket, err := subBucket(tx, name)
+			if err != nil {
+				return err
+			}
+
+			if bytes.Equal(name, target) {
+				err = bucket.Put(idKey(d.ID), b.Bytes())
+			} else {
+				err = bucket.Delete(idKey(d.ID))
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		if d.State != DeliverySucceeded {
+			return nil
+		}
+
+		return s.pruneDelivered(tx)
+	}, func() {})
+}
+
+// pruneDelivered removes the oldest successful deliveries beyond the history
+// limit.
+func (s *kvStore) pruneDelivered(tx kvdb.RwTx) error {
+	bucket, err := subBucket(tx, deliveredBucket)
+	if err != nil {
+		return err
+	}
+
+	var keys [][]byte
+	err = bucket.ForEach(func(k, _ []byte) error {
+		keys = append(keys, append([]byte(nil), k...))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Delivery IDs grow monotonically, so the oldest deliveries come
+	// first.
+	for len(keys) > int(s.deliveryHistory) {
+		if err := bucket.Delete(keys[0]); err != nil {
+			return err
+		}
+		keys = keys[1:]
+	}
+
+	return nil
+}
+
+// FetchDelivery returns the delivery with the given ID.
+//
+// NOTE: Part of the Store interface.
+func (s *kvStore) FetchDelivery(id uint64) (*Delivery, error) {
+	var d *Delivery
+	err := kvdb.View(s.db, func(tx kvdb.RTx) error {
+		deliveryBuckets := [][]byte{
+			pendingBucket, deliveredBucket, deadLetterBucket,
+		}
+		for _, name := range deliveryBuckets {
+			bucket, err := readSubBucket(tx, name)
+			if err != nil {
+				return err
+			}
+
+			v := bucket.Get(idKey(id))
+			if v == nil {
+				continue
+			}
+
+			d, err = deserializeDelivery(bytes.NewReader(v))
+			if err != nil {
+				return err
+			}
+			d.ID = id
+
+			return nil
+		}
+
+		return ErrDeliveryNotFound
+	}, func() {
+		d = nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// FetchDeliveries returns all deliveries in the given state, ordered by their
+// ID.
+//
+// NOTE: Part of the Store interface.
+func (s *kvStore) FetchDeliveries(state DeliveryState) ([]*Delivery, error) {
+	var deliveries []*Delivery
+	err := kvdb.View(s.db, func(tx kvdb.RTx) error {
+		bucket, err := readSubBucket(tx, stateBucket(state))
+		if err != nil {
+			return err
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			d, err := deserializeDelivery(bytes.NewReader(v))
+			if err != nil {
+				return err
+			}
+			d.ID = binary.BigEndian.Uint64(k)
+
+			deliveries = append(deliveries, d)
+
+			return nil
+		})
+	}, func() {
+		deliveries = nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// stateBucket returns the name of the bucket that holds the deliveries in the
+// given state.
+func stateBucket(state DeliveryState) []byte {
+	switch state {
+	case DeliverySucceeded:
+		return deliveredBucket
+
+	case DeliveryFailed:
+		return deadLetterBucket
+
+	default:
+		return pendingBucket
+	}
+}
+
+// subBucket returns the given sub-bucket of the webhook bucket for writing.
+func subBucket(tx kvdb.RwTx, name []byte) (kvdb.RwBucket, error) {
+	bucket := tx.ReadWriteBucket(webhookBucket)
+	if bucket == nil {
+		return nil, errNoWebhookBucket
+	}
+
+	sub := bucket.NestedReadWriteBucket(name)
+	if sub == nil {
+		return nil, errNoWebhookBucket
+	}
+
+	return sub, nil
+}
+
+// readSubBucket returns the given sub-bucket of the webhook bucket for
+// reading.
+func readSubBucket(tx kvdb.RTx, name []byte) (kvdb.RBucket, error) {
+	bucket := tx.ReadBucket(webhookBucket)
+	if bucket == nil {
+		return nil, errNoWebhookBucket
+	}
+
+	sub := bucket.NestedReadBucket(name)
+	if sub == nil {
+		return nil, errNoWebhookBucket
+	}
+
+	return sub, nil
+}
+
+// putEndpoint stores the given endpoint under the given ID.
+func putEndpoint(bucket kvdb.RwBucket, id uint64, e *Endpoint) error {
+	var b bytes.Buffer
+	if err := serializeEndpoint(&b, e); err != nil {
+		return err
+	}
+
+	return bucket.Put(idKey(id), b.Bytes())
+}
+
+// idKey returns the database key of the given endpoint or delivery ID. Keys
+// are big endian, so they are ordered by ID.
+func idKey(id uint64) []byte {
+	var key [8]byte
+	binary.BigEndian.PutUint64(key[:], id)
+
+	return key[:]
+}
+
+// serializeEndpoint writes the TLV encoding of the given endpoint to w.
+func serializeEndpoint(w io.Writer, e *Endpoint) error {
+	var (
+		url        = []byte(e.URL)
+		events     = make([]byte, len(e.Events))
+		fromConfig uint8
+		createdAt  = timeToUnixNano(e.CreatedAt)
+	)
+	for i, eventType := range e.Events {
+		events[i] = uint8(eventType)
+	}
+	if e.FromConfig {
+		fromConfig = 1
+	}
+
+	stream, err := tlv.NewStream(
+		tlv.MakePrimitiveRecord(typeEndpointURL, &url),
+		tlv.MakePrimitiveRecord(typeEndpointEvents, &events),
+		tlv.MakePrimitiveRecord(typeEndpointSecret, &e.Secret),
+		tlv.MakePrimitiveRecord(typeEndpointFromConfig, &fromConfig),
+		tlv.MakePrimitiveRecord(typeEndpointCreatedAt, &createdAt),
+	)
+	if err != nil {
+		return err
+	}
+
+	return stream.Encode(w)
+}
+
+// deserializeEndpoint reads the TLV encoding of an endpoint from r.
+func deserializeEndpoint(r io.Reader) (*Endpoint, error) {
+	var (
+		e           = &Endpoint{}
+		url, events []byte
+		fromConfig  uint8
+		createdAt   uint64
+	)
+
+	stream, err := tlv.NewStream(
+		tlv.MakePrimitiveRecord(typeEndpointURL, &url),
+		tlv.MakePrimitiveRecord(typeEndpointEvents, &events),
+		tlv.MakePrimitiveRecord(typeEndpointSecret, &e.Secret),
+		tlv.MakePrimitiveRecord(typeEndpointFromConfig, &fromConfig),
+		tlv.MakePrimitiveRecord(typeEndpointCreatedAt, &createdAt),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := stream.Decode(r); err != nil {
+		return nil, err
+	}
+
+	e.URL = string(url)
+	for _, eventType := range events {
+		e.Events = append(e.Events, EventType(eventType))
+	}
+	e.FromConfig = fromConfig == 1
+	e.CreatedAt = unixNanoToTime(createdAt)
+
+	return e, nil
+}
+
+// serializeDelivery writes the TLV encoding of the given delivery to w.
+func serializeDelivery(w io.Writer, d *Delivery) error {
+	var (
+		eventID     = []byte(d.EventID)
+		eventType   = uint8(d.EventType)
+		state       = uint8(d.State)
+		createdAt   = timeToUnixNano(d.CreatedAt)
+		lastAttempt = timeToUnixNano(d.LastAttempt)
+		nextAttempt = timeToUnixNano(d.NextAttempt)
+		lastError   = []byte(d.LastError)
+	)
+
+	stream, err := tlv.NewStream(
+		tlv.MakePrimitiveRecord(typeDeliveryEndpointID, &d.EndpointID),
+		tlv.MakePrimitiveRecord(typeDeliveryEventID, &eventID),
+		tlv.MakePrimitiveRecord(typeDeliveryEventType, &eventType),
+		tlv.MakePrimitiveRecord(typeDeliveryPayload, &d.Payload),
+		tlv.MakePrimitiveRecord(typeDeliveryState, &state),
+		tlv.MakePrimitiveRecord(typeDeliveryCreatedAt, &createdAt),
+		tlv.MakePrimitiveRecord(typeDeliveryAttempts, &d.Attempts),
+		tlv.MakePrimitiveRecord(typeDeliveryLastAttempt, &lastAttempt),
+		tlv.MakePrimitiveRecord(typeDeliveryNextAttempt, &nextAttempt),
+		tlv.MakePrimitiveRecord(
+			typeDeliveryStatusCode, &d.LastStatusCode,
+		),
+		tlv.MakePrimitiveRecord(typeDeliveryLastError, &lastError),
+	)
+	if err != nil {
+		return err
+	}
+
+	return stream.Encode(w)
+}
+
+// deserializeDelivery reads the TLV encoding of a delivery from r.
+func deserializeDelivery(r io.Reader) (*Delivery, error) {
+	var (
+		d                                   = &Delivery{}
+		eventID, lastError                  []byte
+		eventType, state                    uint8
+		createdAt, lastAttempt, nextAttempt uint64
+	)
+
+	stream, err := tlv.NewStream(
+		tlv.MakePrimitiveRecord(typeDeliveryEndpointID, &d.EndpointID),
+		tlv.MakePrimitiveRecord(typeDeliveryEventID, &eventID),
+		tlv.MakePrimitiveRecord(typeDeliveryEventType, &eventType),
+		tlv.MakePrimitiveRecord(typeDeliveryPayload, &d.Payload),
+		tlv.MakePrimitiveRecord(typeDeliveryState, &state),
+		tlv.MakePrimitiveRecord(typeDeliveryCreatedAt, &createdAt),
+		tlv.MakePrimitiveRecord(typeDeliveryAttempts, &d.Attempts),
+		tlv.MakePrimitiveRecord(typeDeliveryLastAttempt, &lastAttempt),
+		tlv.MakePrimitiveRecord(typeDeliveryNextAttempt, &nextAttempt),
+		tlv.MakePrimitiveRecord(
+			typeDeliveryStatusCode, &d.LastStatusCode,
+		),
+		tlv.MakePrimitiveRecord(typeDeliveryLastError, &lastError),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := stream.Decode(r); err != nil {
+		return nil, err
+	}
+
+	d.EventID = string(eventID)
+	d.EventType = EventType(eventType)
+	d.State = DeliveryState(state)
+	d.CreatedAt = unixNanoToTime(createdAt)
+	d.LastAttempt = unixNanoToTime(lastAttempt)
+	d.NextAttempt = unixNanoToTime(nextAttempt)
+	d.LastError = string(lastError)
+
+	return d, nil
+}
+
+// timeToUnixNano converts the given time to a unix timestamp in nanoseconds,
+// encoding the zero time as zero.
+func timeToUnixNano(t time.Time) uint64 {
+	if t.IsZero() {
+		return 0
+	}
+
+	return uint64(t.UnixNano())
+}
+
+// unixNanoToTime converts a unix timestamp in nanoseconds to a time, decoding
+// zero as the zero time.
+func unixNanoToTime(nanos uint64) time.Time {
+	if nanos == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(0, int64(nanos))
+}
@@ -0,0 +1,149 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lightningnetwork/lnd/kvdb"
+	"github.com/stretchr/testify/require"
+)
+
+// testTime is the start time of the test clocks.
+var testTime = time.Unix(1_700_000_000, 0)
+
+func newTestStore(t *testing.T, deliveryHistory uint32) Store {
+	db, cleanUp, err := kvdb.GetTestBackend(t.TempDir(), "webhook")
+	require.NoError(t, err)
+	t.Cleanup(cleanUp)
+
+	store, err := NewStore(db, deliveryHistory)
+	require.NoError(t, err)
+
+	return store
+}
+
+// TestStoreEndpoints tests that endpoints are stored, updated and deleted.
+func TestStoreEndpoints(t *testing.T) {
+	t.Parallel()
+
+	store := newTestStore(t, 10)
+
+	endpoint := &Endpoint{
+		URL:        "https://example.com/hook",
+		Events:     []EventType{EventInvoiceSettled, EventHtlcFailed},
+		Secret:     []byte("secret"),
+		FromConfig: true,
+		CreatedAt:  testTime,
+	}
+	require.NoError(t, store.AddEndpoint(endpoint))
+	require.EqualValues(t, 1, endpoint.ID)
+
+	endpoints, err := store.FetchEndpoints()
+	require.NoError(t, err)
+	require.Equal(t, []*Endpoint{endpoint}, endpoints)
+
+	endpoint.Events = nil
+	require.NoError(t, store.UpdateEndpoint(endpoint))
+
+	endpoints, err = store.FetchEndpoints()
+	require.NoError(t, err)
+	require.Len(t, endpoints, 1)
+	require.Empty(t, endpoints[0].Events)
+
+	require.NoError(t, store.DeleteEndpoint(endpoint.ID))
+	require.ErrorIs(
+		t, store.DeleteEndpoint(endpoint.ID), ErrEndpointNotFound,
+	)
+	require.ErrorIs(t, store.UpdateEndpoint(endpoint), ErrEndpointNotFound)
+
+	endpoints, err = store.FetchEndpoints()
+	require.NoError(t, err)
+	require.Empty(t, endpoints)
+}
+
+// TestStoreDeliveries tests that deliveries move between the queues of their
+// states, that the successful deliveries are pruned and that the deliveries
+// of a deleted endpoint are removed.
+func TestStoreDeliveries(t *testing.T) {
+	t.Parallel()
+
+	store := newTestStore(t, 2)
+
+	endpoint := &Endpoint{
+		URL:       "https://example.com/hook",
+		Secret:    []byte("secret"),
+		CreatedAt: testTime,
+	}
+	require.NoError(t, store.AddEndpoint(endpoint))
+
+	deliveries := make([]*Delivery, 4)
+	for i := range deliveries {
+		deliveries[i] = &Delivery{
+			EndpointID:  endpoint.ID,
+			EventID:     "event",
+			EventType:   EventPaymentSucceeded,
+			Payload:     []byte(`{"id":"event"}`),
+			State:       DeliveryPending,
+			CreatedAt:   testTime,
+			NextAttempt: testTime,
+		}
+	}
+	require.NoError(t, store.AddDeliveries(deliveries))
+	for i, d := range deliveries {
+		require.EqualValues(t, i+1, d.ID)
+	}
+
+	pending, err := store.FetchDeliveries(DeliveryPending)
+	require.NoError(t, err)
+	require.Equal(t, deliveries, pending)
+
+	// Fail the first delivery for good.
+	failed := deliveries[0]
+	failed.State = DeliveryFailed
+	failed.Attempts = 3
+	failed.LastAttempt = testTime.Add(time.Minute)
+	failed.LastStatusCode = 500
+	failed.LastError = "endpoint returned 500"
+	require.NoError(t, store.PutDelivery(failed))
+
+	stored, err := store.FetchDelivery(failed.ID)
+	require.NoError(t, err)
+	require.Equal(t, failed, stored)
+
+	deadLetters, err := store.FetchDeliveries(DeliveryFailed)
+	require.NoError(t, err)
+	require.Equal(t, []*Delivery{failed}, deadLetters)
+
+	// Deliver the other ones. Only the two most recent successful
+	// deliveries are kept.
+	for _, d := range deliveries[1:] {
+		d.State = DeliverySucceeded
+		d.Attempts = 1
+		require.NoError(t, store.PutDelivery(d))
+	}
+
+	pending, err = store.FetchDeliveries(DeliveryPending)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+
+	delivered, err := store.FetchDeliveries(DeliverySucceeded)
+	require.NoError(t, err)
+	require.Equal(t, deliveries[2:], delivered)
+
+	_, err = store.FetchDelivery(deliveries[1].ID)
+	require.ErrorIs(t, err, ErrDeliveryNotFound)
+
+	// Deleting the endpoint removes its deliveries, and they can't be
+	// stored again afterwards.
+	require.NoError(t, store.DeleteEndpoint(endpoint.ID))
+
+	for _, state := range []DeliveryState{
+		DeliveryPending, DeliverySucceeded, DeliveryFailed,
+	} {
+		deliveries, err := store.FetchDeliveries(state)
+		require.NoError(t, err)
+		require.Empty(t, deliveries)
+	}
+
+	require.ErrorIs(t, store.PutDelivery(failed), ErrEndpointNotFound)
+}